@@ -15,12 +15,16 @@ import (
 	"github.com/rs/zerolog/log"
 
 	"github.com/QTest-hq/qtest/internal/api"
+	"github.com/QTest-hq/qtest/internal/auth"
 	"github.com/QTest-hq/qtest/internal/config"
 	"github.com/QTest-hq/qtest/internal/db"
 	"github.com/QTest-hq/qtest/internal/jobs"
 	qtestnats "github.com/QTest-hq/qtest/internal/nats"
 )
 
+// scheduleInterval is how often due schedules are checked
+const scheduleInterval = time.Minute
+
 func main() {
 	// Setup logging
 	zerolog.TimeFieldFormat = zerolog.TimeFormatUnix
@@ -81,10 +85,58 @@ func main() {
 		log.Fatal().Err(err).Msg("failed to create server")
 	}
 
+	// Configure authentication: GitHub login sessions, API keys and, with
+	// an issuer, OIDC tokens
+	store := db.NewStore(database)
+	sessions := auth.NewSessionStore(auth.SessionStoreConfig{})
+	var github *auth.GitHubProvider
+	var authHandlers *auth.Handlers
+	if cfg.GitHubOAuth.ClientID != "" {
+		github = auth.NewGitHubProvider(auth.GitHubConfig{
+			ClientID:     cfg.GitHubOAuth.ClientID,
+			ClientSecret: cfg.GitHubOAuth.ClientSecret,
+			RedirectURL:  cfg.GitHubOAuth.RedirectURL,
+		})
+		authHandlers = auth.NewHandlersWithStore(github, sessions, store)
+	}
+	authMiddleware := auth.NewMiddleware(sessions, github)
+	authMiddleware.SetAPIKeys(store)
+	if cfg.Auth.OIDCIssuer != "" {
+		verifier, err := auth.NewOIDCVerifier(auth.OIDCConfig{
+			Issuer:        cfg.Auth.OIDCIssuer,
+			Audience:      cfg.Auth.OIDCAudience,
+			JWKSURL:       cfg.Auth.OIDCJWKSURL,
+			UsernameClaim: cfg.Auth.OIDCUsernameClaim,
+		})
+		if err != nil {
+			log.Fatal().Err(err).Msg("invalid OIDC configuration; set OIDC_AUDIENCE to qtest's client ID")
+		}
+		authMiddleware.SetOIDC(verifier, store)
+		log.Info().Str("issuer", cfg.Auth.OIDCIssuer).Msg("oidc tokens accepted")
+	}
+	// API keys act in their organizations, so once any exist requests need
+	// credentials
+	if !cfg.Auth.Required {
+		hasKeys, err := store.HasAPIKeys(ctx)
+		if err != nil {
+			log.Fatal().Err(err).Msg("failed to check api keys")
+		}
+		cfg.Auth.Required = hasKeys
+	}
+	srv.SetAuth(authHandlers, authMiddleware)
+	if cfg.Auth.Required {
+		log.Info().Msg("api requests require credentials")
+	}
+
 	// Configure job system
+	schedCtx, stopSchedules := context.WithCancel(ctx)
+	defer stopSchedules()
 	if jobRepo != nil {
 		srv.SetJobSystem(jobRepo, natsClient)
 		log.Info().Msg("job system enabled")
+
+		// Start the pipelines of repositories' schedules as they come due
+		go srv.RunSchedules(schedCtx, scheduleInterval)
 	}
 
 	// Start server
@@ -104,6 +156,7 @@ func main() {
 	go func() {
 		<-quit
 		log.Info().Msg("server is shutting down...")
+		stopSchedules()
 
 		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 		defer cancel()
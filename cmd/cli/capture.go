@@ -0,0 +1,244 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/QTest-hq/qtest/internal/capture"
+	"github.com/QTest-hq/qtest/pkg/model"
+	"github.com/spf13/cobra"
+)
+
+func captureCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "capture",
+		Short: "Build API tests from recorded traffic",
+		Long: `Records real request/response pairs from a running service (or imports
+a HAR file exported from a browser or proxy) and converts them into API test
+specifications with sanitized payloads. Feed the specs to emit-tests.
+
+Example:
+  qtest capture record --target https://staging.example.com -o traffic.har
+  qtest capture import -i traffic.har -p . -o specs.json
+  qtest emit-tests -s specs.json -o ./tests --emitter supertest`,
+	}
+
+	cmd.AddCommand(captureRecordCmd())
+	cmd.AddCommand(captureImportCmd())
+
+	return cmd
+}
+
+func captureRecordCmd() *cobra.Command {
+	var (
+		target     string
+		listenAddr string
+		outputFile string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "record",
+		Short: "Run a recording proxy in front of a service",
+		Long: `Starts a reverse proxy that forwards to --target and records every
+exchange. Send traffic through the proxy (point a test client, browser or
+mirrored load balancer at it), then press Ctrl+C to write the HAR file.
+
+The HAR holds raw traffic, including credentials, so it is written readable
+only by you. Payloads are sanitized when the HAR is imported.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			targetURL, err := url.Parse(target)
+			if err != nil || targetURL.Scheme == "" || targetURL.Host == "" {
+				return fmt.Errorf("invalid target URL: %s", target)
+			}
+
+			listener, err := net.Listen("tcp", listenAddr)
+			if err != nil {
+				return fmt.Errorf("failed to listen on %s: %w", listenAddr, err)
+			}
+
+			recorder := capture.NewRecorder(targetURL)
+			server := &http.Server{Handler: recorder, ReadHeaderTimeout: 10 * time.Second}
+
+			ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+			defer stop()
+
+			errCh := make(chan error, 1)
+			go func() { errCh <- server.Serve(listener) }()
+
+			fmt.Printf("🎙️  Recording http://%s -> %s\n", listener.Addr(), targetURL)
+			fmt.Println("   Press Ctrl+C to stop and save")
+
+			select {
+			case <-ctx.Done():
+			case err := <-errCh:
+				if !errors.Is(err, http.ErrServerClosed) {
+					return fmt.Errorf("proxy failed: %w", err)
+				}
+			}
+
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			server.Shutdown(shutdownCtx)
+
+			exchanges := recorder.Exchanges()
+			if err := capture.WriteHAR(outputFile, exchanges); err != nil {
+				return fmt.Errorf("failed to write HAR: %w", err)
+			}
+			fmt.Printf("\n💾 Recorded %d exchanges to: %s\n", len(exchanges), outputFile)
+			fmt.Printf("   Next: qtest capture import -i %s -o specs.json\n", outputFile)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&target, "target", "t", "", "Base URL of the service to record (required)")
+	cmd.Flags().StringVarP(&listenAddr, "listen", "l", "127.0.0.1:8089", "Address for the proxy to listen on")
+	cmd.Flags().StringVarP(&outputFile, "output", "o", "traffic.har", "HAR file to write")
+	cmd.MarkFlagRequired("target")
+
+	return cmd
+}
+
+func captureImportCmd() *cobra.Command {
+	var (
+		inputFile      string
+		outputFile     string
+		modelFile      string
+		repoPath       string
+		host           string
+		maxPerEndpoint int
+		seed           int64
+	)
+
+	cmd := &cobra.Command{
+		Use:   "import",
+		Short: "Convert a HAR file into API test specifications",
+		Long: `Converts recorded traffic into API test specs. Requests are matched to the
+system model's endpoints (from --model or by analyzing --path) so specs use
+the route's path template; without a model, numeric and UUID path segments
+become parameters.
+
+Payloads are sanitized: credentials are redacted, personal data (emails,
+names, phones, addresses, ...) is replaced with consistent fake values, and
+only the Content-Type and Accept headers are kept. Responses are asserted on
+status and top-level fields, not on staging data values.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			exchanges, err := capture.LoadHAR(inputFile)
+			if err != nil {
+				return err
+			}
+
+			var endpoints []model.Endpoint
+			repository := ""
+			switch {
+			case modelFile != "":
+				data, err := os.ReadFile(modelFile)
+				if err != nil {
+					return fmt.Errorf("failed to read model: %w", err)
+				}
+				var sysModel model.SystemModel
+				if err := json.Unmarshal(data, &sysModel); err != nil {
+					return cliErrorf(exitParse, "failed to parse model: %w", err)
+				}
+				endpoints, repository = sysModel.Endpoints, sysModel.Repository
+			case repoPath != "":
+				validPath, err := validateDirPath(repoPath)
+				if err != nil {
+					return fmt.Errorf("invalid path: %w", err)
+				}
+				sysModel, _, err := buildSystemModel(context.Background(), validPath, false)
+				if err != nil {
+					return err
+				}
+				endpoints, repository = sysModel.Endpoints, sysModel.Repository
+			}
+
+			sanitizer, err := newSanitizer(seed)
+			if err != nil {
+				return err
+			}
+
+			converter := capture.NewConverter(endpoints, capture.Options{
+				Host:           host,
+				MaxPerEndpoint: maxPerEndpoint,
+				Sanitizer:      sanitizer,
+			})
+			specSet := &model.TestSpecSet{
+				Repository: repository,
+				Specs:      converter.Convert(exchanges),
+			}
+
+			matched := 0
+			for _, spec := range specSet.Specs {
+				if spec.TargetID != "" {
+					matched++
+				}
+			}
+
+			data, err := json.MarshalIndent(specSet, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to marshal specs: %w", err)
+			}
+			if outputFile == "" {
+				fmt.Println(string(data))
+				return nil
+			}
+			if err := os.WriteFile(outputFile, data, 0644); err != nil {
+				return fmt.Errorf("failed to write specs: %w", err)
+			}
+
+			fmt.Printf("📥 Imported %d exchanges from %s\n", len(exchanges), inputFile)
+			fmt.Printf("📝 Generated %d API specs", len(specSet.Specs))
+			if len(endpoints) > 0 {
+				fmt.Printf(" (%d matched to model endpoints)", matched)
+			}
+			fmt.Println()
+			fmt.Printf("💾 Specs saved to: %s\n", outputFile)
+			if len(specSet.Specs) > 0 {
+				fmt.Printf("   Next: qtest emit-tests -s %s -o ./tests\n", outputFile)
+			} else {
+				fmt.Println("   No API traffic found. Static assets, HTML pages and preflight")
+				fmt.Println("   requests are skipped; check --host matches the service.")
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&inputFile, "input", "i", "", "HAR file to import (required)")
+	cmd.Flags().StringVarP(&outputFile, "output", "o", "", "Output file for specs JSON (default stdout)")
+	cmd.Flags().StringVarP(&modelFile, "model", "m", "", "System model JSON file to match endpoints against")
+	cmd.Flags().StringVarP(&repoPath, "path", "p", "", "Analyze this repository to match endpoints against")
+	cmd.Flags().StringVar(&host, "host", "", "Only import traffic to this host (e.g. staging.example.com)")
+	cmd.Flags().IntVar(&maxPerEndpoint, "max-per-endpoint", capture.DefaultMaxPerEndpoint, "Distinct requests kept per endpoint and status code")
+	cmd.Flags().Int64Var(&seed, "seed", 0, "Seed for reproducible fake values (default: datagen.seed from .qtest.yaml)")
+	cmd.MarkFlagRequired("input")
+
+	return cmd
+}
+
+// newSanitizer creates a payload sanitizer, falling back to the datagen
+// section of .qtest.yaml for the seed and locale
+func newSanitizer(seed int64) (*capture.Sanitizer, error) {
+	settings := resolveDatagenSettings()
+	if seed == 0 {
+		seed = settings.Seed
+	}
+	sanitizer := capture.NewSanitizer()
+	if seed != 0 {
+		sanitizer = capture.NewSeededSanitizer(seed)
+	}
+	if settings.Locale != "" {
+		if err := sanitizer.SetLocale(settings.Locale); err != nil {
+			return nil, err
+		}
+	}
+	return sanitizer, nil
+}
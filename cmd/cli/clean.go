@@ -0,0 +1,407 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/QTest-hq/qtest/internal/adapters"
+	"github.com/QTest-hq/qtest/internal/github"
+	"github.com/QTest-hq/qtest/pkg/model"
+	"github.com/spf13/cobra"
+)
+
+// Reasons a generated test file is orphaned or stale
+const (
+	orphanSourceRemoved  = "source file removed"
+	orphanTargetsRemoved = "tested functions removed"
+	orphanTargetsPartial = "some tested functions removed"
+)
+
+// orphanedFile is a generated test file whose code under test is gone
+type orphanedFile struct {
+	generatedFile
+	Source  string   `json:"source,omitempty"` // resolved source file, if it still exists
+	Reason  string   `json:"reason"`
+	Missing []string `json:"missing,omitempty"` // targets no longer in the model
+}
+
+func cleanCmd() *cobra.Command {
+	var (
+		dirPath       string
+		orphaned      bool
+		includeEdited bool
+		dryRun        bool
+		jsonOut       bool
+		verbose       bool
+		openPR        bool
+		owner         string
+		repo          string
+		branch        string
+		base          string
+		draft         bool
+		token         string
+		settings      github.PRSettings
+	)
+
+	cmd := &cobra.Command{
+		Use:   "clean",
+		Short: "Remove generated tests for code that no longer exists",
+		Long: `Finds QTest-generated test files whose code under test was deleted and
+removes them, so test suites don't rot as code is removed.
+
+A generated file is orphaned when git shows its source file was deleted
+since the tests were generated, or when the source was parsed and none of
+the functions it tests exist in the system model any more. Tests of
+sources that weren't parsed, because they failed to parse or are in
+skipped directories like vendor, are kept, as are tests of sources that
+can't be found on disk or in git, like stdin or URLs. Files where only
+some tested functions are gone are reported but kept. Files edited by hand
+are kept unless --include-edited is set.
+
+With --pr the files are removed in a cleanup pull request instead of
+locally.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !orphaned {
+				return fmt.Errorf("nothing to clean: use --orphaned (or 'qtest generated clean' to remove all generated files)")
+			}
+
+			validPath, err := validateDirPath(dirPath)
+			if err != nil {
+				return fmt.Errorf("invalid directory: %w", err)
+			}
+
+			files, err := findGeneratedFiles(validPath, "")
+			if err != nil {
+				return err
+			}
+
+			ctx := context.Background()
+			sysModel, _, err := buildSystemModel(ctx, validPath, verbose)
+			if err != nil {
+				return err
+			}
+
+			orphans, stale := findOrphanedFiles(validPath, files, sysModel)
+
+			var remove []orphanedFile
+			kept := 0
+			for _, f := range orphans {
+				if f.Ownership == adapters.OwnershipEdited && !includeEdited {
+					kept++
+					continue
+				}
+				remove = append(remove, f)
+			}
+
+			if jsonOut {
+				data, _ := json.MarshalIndent(map[string]interface{}{
+					"orphaned": orphans,
+					"stale":    stale,
+				}, "", "  ")
+				fmt.Println(string(data))
+				return nil
+			}
+
+			printOrphanedFiles(validPath, orphans, stale, includeEdited)
+			if len(remove) == 0 {
+				return nil
+			}
+
+			if openPR {
+				if dryRun {
+					fmt.Printf("\nWould open a PR removing %d orphaned test files\n", len(remove))
+					return nil
+				}
+				if token == "" {
+					token = os.Getenv("GITHUB_TOKEN")
+				}
+				if token == "" {
+					return fmt.Errorf("GitHub token required. Set GITHUB_TOKEN env var or use --token flag")
+				}
+				if owner == "" || repo == "" {
+					detected, err := detectGitHubRepo()
+					if err != nil {
+						return fmt.Errorf("could not detect repo, please specify --owner and --repo")
+					}
+					owner = detected.Owner
+					repo = detected.Name
+				}
+				return openCleanupPR(ctx, github.NewPRService(token), owner, repo, branch, base, draft, settings, validPath, remove)
+			}
+
+			if dryRun {
+				fmt.Printf("\nWould remove %d orphaned test files, kept %d edited\n", len(remove), kept)
+				return nil
+			}
+
+			for _, f := range remove {
+				if err := os.Remove(f.Path); err != nil {
+					return fmt.Errorf("failed to remove %s: %w", relativePath(validPath, f.Path), err)
+				}
+				if f.MergeFile != "" {
+					os.Remove(f.MergeFile)
+				}
+			}
+			fmt.Printf("\nRemoved %d orphaned test files, kept %d edited\n", len(remove), kept)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&dirPath, "path", "p", ".", "Repository root to clean")
+	cmd.Flags().BoolVar(&orphaned, "orphaned", false, "Remove generated tests whose tested code no longer exists")
+	cmd.Flags().BoolVar(&includeEdited, "include-edited", false, "Also remove orphaned files edited by hand")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would be removed")
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Print orphaned files as JSON without removing them")
+	cmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Show files as they're parsed")
+	cmd.Flags().BoolVar(&openPR, "pr", false, "Open a cleanup pull request instead of removing files locally")
+	cmd.Flags().StringVar(&owner, "owner", "", "GitHub repository owner")
+	cmd.Flags().StringVar(&repo, "repo", "", "GitHub repository name")
+	cmd.Flags().StringVarP(&branch, "branch", "b", "qtest/remove-orphaned-tests", "Branch name for the cleanup")
+	cmd.Flags().StringVar(&base, "base", "", "Base branch (default: repo default)")
+	cmd.Flags().BoolVar(&draft, "draft", false, "Create as draft PR")
+	cmd.Flags().StringSliceVar(&settings.Labels, "label", nil, "Label to add (repeatable)")
+	cmd.Flags().StringSliceVar(&settings.Reviewers, "reviewer", nil, "Reviewer login or org/team (repeatable)")
+	cmd.Flags().StringVar(&token, "token", "", "GitHub token (or set GITHUB_TOKEN)")
+
+	return cmd
+}
+
+// findOrphanedFiles checks generated test files against the system model
+// built from root. It returns the files whose code under test is gone, and
+// the files that still test something but lost some of their targets. A
+// source the model has no record of parsing tells nothing about its
+// targets, so its tests are neither.
+func findOrphanedFiles(root string, files []generatedFile, m *model.SystemModel) (orphaned, stale []orphanedFile) {
+	modelPath := func(file string) string {
+		file = filepath.Clean(file)
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(root, file)
+		}
+		return file
+	}
+	parsed := make(map[string]bool)
+	for _, mod := range m.Modules {
+		for _, file := range mod.Files {
+			parsed[modelPath(file)] = true
+		}
+	}
+	functions := make(map[string]map[string]bool) // source file -> target names
+	for _, fn := range m.Functions {
+		file := modelPath(fn.File)
+		parsed[file] = true
+		if functions[file] == nil {
+			functions[file] = make(map[string]bool)
+		}
+		functions[file][adapters.TargetName(fn.Class, fn.Name)] = true
+	}
+
+	for _, f := range files {
+		p := f.Provenance
+		if p == nil || p.Source == "" {
+			continue // can't tell what it tests
+		}
+
+		source := resolveSource(root, f.Path, p.Source)
+		if source == "" {
+			// A source that can't be found isn't proof of anything; only
+			// one git shows was deleted orphans its tests
+			if sourceRemoved(root, p) {
+				orphaned = append(orphaned, orphanedFile{generatedFile: f, Reason: orphanSourceRemoved})
+			}
+			continue
+		}
+		if len(p.Targets) == 0 || !parsed[source] {
+			continue
+		}
+
+		var missing []string
+		for _, target := range p.Targets {
+			if !functions[source][target] {
+				missing = append(missing, target)
+			}
+		}
+
+		file := orphanedFile{generatedFile: f, Source: source, Missing: missing}
+		switch {
+		case len(missing) == len(p.Targets):
+			file.Reason = orphanTargetsRemoved
+			orphaned = append(orphaned, file)
+		case len(missing) > 0:
+			file.Reason = orphanTargetsPartial
+			stale = append(stale, file)
+		}
+	}
+
+	sort.Slice(orphaned, func(i, j int) bool { return orphaned[i].Path < orphaned[j].Path })
+	sort.Slice(stale, func(i, j int) bool { return stale[i].Path < stale[j].Path })
+	return orphaned, stale
+}
+
+// resolveSource finds the source file a provenance header points to. Paths
+// are recorded relative to the git repository; older files may have them
+// relative to the cleaned directory or the test file. It returns "" if the
+// source can't be found, or isn't a file in the repository at all, like
+// "stdin" or a URL.
+func resolveSource(root, testFile, source string) string {
+	if !isRepoSource(source) {
+		return ""
+	}
+	source = filepath.FromSlash(source)
+	var candidates []string
+	if filepath.IsAbs(source) {
+		candidates = append(candidates, source)
+	} else {
+		if top := repoRoot(root); top != "" {
+			candidates = append(candidates, filepath.Join(top, source))
+		}
+		candidates = append(candidates, filepath.Join(root, source), filepath.Join(filepath.Dir(testFile), source))
+	}
+	for _, c := range candidates {
+		if info, err := os.Stat(c); err == nil && !info.IsDir() {
+			return filepath.Clean(c)
+		}
+	}
+	return ""
+}
+
+// isRepoSource reports whether a provenance source names a file, rather
+// than standard input or a remote URL
+func isRepoSource(source string) bool {
+	return source != "" && source != "stdin" && !strings.Contains(source, "://")
+}
+
+// sourceRemoved reports whether a provenance's source was deleted from the
+// repository: it's in the commit the tests were generated from and not on
+// disk now. Sources outside git, or that the commit doesn't have, are never
+// taken as removed.
+func sourceRemoved(root string, p *adapters.Provenance) bool {
+	if p.SourceCommit == "" || !isRepoSource(p.Source) {
+		return false
+	}
+	top := repoRoot(root)
+	if top == "" {
+		return false
+	}
+	rel := filepath.FromSlash(p.Source)
+	if filepath.IsAbs(rel) {
+		var err error
+		if rel, err = filepath.Rel(top, rel); err != nil {
+			return false
+		}
+	}
+	rel = filepath.Clean(rel)
+	if rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return false
+	}
+	if _, err := os.Stat(filepath.Join(top, rel)); !os.IsNotExist(err) {
+		return false
+	}
+	return exec.Command("git", "-C", top, "cat-file", "-e", p.SourceCommit+":"+filepath.ToSlash(rel)).Run() == nil
+}
+
+// printOrphanedFiles lists orphaned and stale generated test files
+func printOrphanedFiles(root string, orphaned, stale []orphanedFile, includeEdited bool) {
+	if len(orphaned) == 0 && len(stale) == 0 {
+		fmt.Println("No orphaned generated tests found")
+		return
+	}
+
+	if len(orphaned) > 0 {
+		fmt.Printf("🗑  Orphaned (%d):\n", len(orphaned))
+		for _, f := range orphaned {
+			status := ""
+			if f.Ownership == adapters.OwnershipEdited && !includeEdited {
+				status = " ✎ kept, edited by hand"
+			}
+			fmt.Printf("  %s: %s%s\n", relativePath(root, f.Path), f.Reason, status)
+			if len(f.Missing) > 0 {
+				fmt.Printf("      missing: %s\n", strings.Join(f.Missing, ", "))
+			}
+		}
+	}
+
+	if len(stale) > 0 {
+		fmt.Printf("⚠️  Partly stale, kept (%d):\n", len(stale))
+		for _, f := range stale {
+			fmt.Printf("  %s: missing %s\n", relativePath(root, f.Path), strings.Join(f.Missing, ", "))
+		}
+	}
+}
+
+// openCleanupPR removes orphaned test files on a new branch and opens a
+// pull request for it, leaving the local files alone
+func openCleanupPR(ctx context.Context, prService *github.PRService, owner, repo, branch, base string, draft bool, settings github.PRSettings, root string, files []orphanedFile) error {
+	if base == "" {
+		defaultBranch, err := prService.GetDefaultBranch(ctx, owner, repo)
+		if err != nil {
+			base = "main"
+		} else {
+			base = defaultBranch
+		}
+	}
+
+	baseSHA, err := prService.GetLatestCommitSHA(ctx, owner, repo, base)
+	if err != nil {
+		return fmt.Errorf("failed to get base branch SHA: %w", err)
+	}
+
+	fmt.Printf("\nCreating branch %s from %s...\n", branch, base)
+	if err := prService.CreateBranch(ctx, github.BranchRequest{
+		Owner:  owner,
+		Repo:   repo,
+		Branch: branch,
+		SHA:    baseSHA,
+	}); err != nil {
+		return fmt.Errorf("failed to create branch: %w", err)
+	}
+
+	var body strings.Builder
+	body.WriteString("## Remove orphaned generated tests\n\n")
+	body.WriteString("The code these QTest-generated tests cover no longer exists.\n\n")
+	body.WriteString("| File | Reason |\n|------|--------|\n")
+
+	deleted := 0
+	for _, f := range files {
+		repoPath := filepath.ToSlash(relativePath(root, f.Path))
+		if err := prService.DeleteFile(ctx, owner, repo, branch, repoPath,
+			fmt.Sprintf("Remove orphaned generated test: %s", filepath.Base(repoPath))); err != nil {
+			fmt.Printf("  Warning: could not remove %s: %v\n", repoPath, err)
+			continue
+		}
+		fmt.Printf("  🗑  %s\n", repoPath)
+		fmt.Fprintf(&body, "| `%s` | %s |\n", repoPath, f.Reason)
+		deleted++
+	}
+	if deleted == 0 {
+		return fmt.Errorf("no files were removed")
+	}
+
+	pr, err := prService.CreatePR(ctx, github.PRRequest{
+		Owner:      owner,
+		Repo:       repo,
+		Title:      fmt.Sprintf("Remove %d orphaned generated tests", deleted),
+		Body:       body.String(),
+		Head:       branch,
+		Base:       base,
+		Draft:      draft,
+		Maintainer: true,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create PR: %w", err)
+	}
+
+	fmt.Printf("\n✅ Pull request created!\n")
+	fmt.Printf("   PR #%d: %s\n", pr.Number, pr.Title)
+	fmt.Printf("   URL: %s\n", pr.HTMLURL)
+
+	if err := prService.Configure(ctx, owner, repo, pr, settings); err != nil {
+		fmt.Printf("   Warning: %v\n", err)
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/QTest-hq/qtest/internal/adapters"
+	"github.com/QTest-hq/qtest/pkg/model"
+)
+
+// commitAll commits everything in dir to a new git repository and returns
+// the commit
+func commitAll(t *testing.T, dir string) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", "-A"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "init"},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+	return sourceCommit(dir)
+}
+
+func TestFindOrphanedFiles(t *testing.T) {
+	root := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(root, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	mathGo := write("math.go", "package app\n")
+	write("gone.go", "package app\n")
+	emptyGo := write("empty.go", "package app\n")
+	write("broken.go", "package app\nfunc Broken( {\n")
+	write("pkg/util.go", "package pkg\n")
+	commit := commitAll(t, root)
+	os.Remove(filepath.Join(root, "gone.go"))
+
+	generated := func(name, source string, targets ...string) string {
+		return write(name, adapters.StampProvenance("package app\n", name, adapters.Provenance{
+			SourceCommit: commit,
+			Source:       source,
+			Targets:      targets,
+		}))
+	}
+	generated("math_test.go", "math.go", "Add", "Sub")
+	generated("calc_test.go", "math.go", "Calculator.Mul")
+	generated("gone_test.go", "gone.go", "Gone")
+	generated("old_test.go", "math.go") // no targets recorded
+	generated("empty_test.go", "empty.go", "Removed")
+	generated("broken_test.go", "broken.go", "Broken") // source failed to parse
+	generated("stdin_test.go", "stdin", "Parse")
+	generated("remote_test.go", "https://example.com/app/remote.go", "Fetch")
+	generated("lost_test.go", "elsewhere/lost.go", "Lost") // never in the repository
+	// Recorded relative to pkg/, where QTest ran, before sources were
+	// recorded relative to the repository
+	generated("tests/util_test.go", "util.go", "Util")
+
+	m := &model.SystemModel{
+		Functions: []model.Function{
+			{Name: "Add", File: mathGo},
+			{Name: "Div", Class: "Calculator", File: mathGo},
+		},
+		Modules: []model.Module{
+			{ID: "mod:app", Files: []string{mathGo, emptyGo}},
+		},
+	}
+
+	files, err := findGeneratedFiles(root, "")
+	if err != nil {
+		t.Fatalf("findGeneratedFiles() error = %v", err)
+	}
+	orphaned, stale := findOrphanedFiles(root, files, m)
+
+	if len(orphaned) != 3 {
+		t.Fatalf("len(orphaned) = %d, want 3: %+v", len(orphaned), orphaned)
+	}
+	if filepath.Base(orphaned[0].Path) != "calc_test.go" || orphaned[0].Reason != orphanTargetsRemoved {
+		t.Errorf("orphaned[0] = %s (%s), want calc_test.go (%s)", filepath.Base(orphaned[0].Path), orphaned[0].Reason, orphanTargetsRemoved)
+	}
+	if filepath.Base(orphaned[1].Path) != "empty_test.go" || orphaned[1].Reason != orphanTargetsRemoved {
+		t.Errorf("orphaned[1] = %s (%s), want empty_test.go (%s)", filepath.Base(orphaned[1].Path), orphaned[1].Reason, orphanTargetsRemoved)
+	}
+	if filepath.Base(orphaned[2].Path) != "gone_test.go" || orphaned[2].Reason != orphanSourceRemoved {
+		t.Errorf("orphaned[2] = %s (%s), want gone_test.go (%s)", filepath.Base(orphaned[2].Path), orphaned[2].Reason, orphanSourceRemoved)
+	}
+
+	if len(stale) != 1 || filepath.Base(stale[0].Path) != "math_test.go" {
+		t.Fatalf("stale = %+v, want math_test.go", stale)
+	}
+	if len(stale[0].Missing) != 1 || stale[0].Missing[0] != "Sub" {
+		t.Errorf("Missing = %v, want [Sub]", stale[0].Missing)
+	}
+}
+
+func TestResolveSource(t *testing.T) {
+	root := t.TempDir()
+	os.MkdirAll(filepath.Join(root, "pkg"), 0755)
+	source := filepath.Join(root, "pkg", "math.go")
+	os.WriteFile(source, []byte("package pkg\n"), 0644)
+	testFile := filepath.Join(root, "pkg", "math_test.go")
+
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{"repo relative", "pkg/math.go", source},
+		{"test file relative", "math.go", source},
+		{"absolute", source, source},
+		{"removed", "pkg/gone.go", ""},
+		{"stdin", "stdin", ""},
+		{"url", "https://example.com/pkg/math.go", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resolveSource(root, testFile, tt.source); got != tt.want {
+				t.Errorf("resolveSource(%s) = %q, want %q", tt.source, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTestProvenance_RecordsRepoRelativeSource(t *testing.T) {
+	root := t.TempDir()
+	os.MkdirAll(filepath.Join(root, "pkg"), 0755)
+	os.WriteFile(filepath.Join(root, "pkg", "math.go"), []byte("package pkg\n"), 0644)
+	commitAll(t, root)
+	root, _ = filepath.EvalSymlinks(root)
+
+	t.Chdir(filepath.Join(root, "pkg"))
+	prov := testProvenance("math.go", nil)
+	if prov.Source != "pkg/math.go" {
+		t.Fatalf("Source = %q, want pkg/math.go", prov.Source)
+	}
+
+	// clean run from another directory still finds the source
+	t.Chdir(t.TempDir())
+	testFile := filepath.Join(root, "tests", "math_test.go")
+	if got := resolveSource(root, testFile, prov.Source); got != filepath.Join(root, "pkg", "math.go") {
+		t.Errorf("resolveSource() = %q, want pkg/math.go in the repository", got)
+	}
+	if sourceRemoved(root, &prov) {
+		t.Error("sourceRemoved() = true for a source that exists")
+	}
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/QTest-hq/qtest/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// configGetCmd shows settings and where their values come from
+func configGetCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "get [KEY]",
+		Short: "Show a setting, or all of them",
+		Long: `Show a setting's effective value. Without a key, every setting is listed
+with its value and where it comes from: the environment, the active profile,
+.qtest.yaml or the default. Secrets are masked.
+
+Examples:
+  qtest config get llm.tier2
+  qtest config get`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.Load()
+			if err != nil {
+				return cliErrorf(exitConfig, "failed to load config: %w", err)
+			}
+			project, err := config.LoadProjectConfig(".")
+			if err != nil {
+				return cliErrorf(exitConfig, "failed to load project config: %w", err)
+			}
+			profile, err := config.LoadActiveProfile()
+			if err != nil {
+				return err
+			}
+
+			if len(args) == 1 {
+				setting, ok := config.LookupSetting(args[0])
+				if !ok {
+					return cliErrorf(exitConfig, "unknown setting %s (see qtest config get)", args[0])
+				}
+				fmt.Println(settingValue(setting, cfg, project))
+				return nil
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "KEY\tVALUE\tSOURCE")
+			for _, setting := range config.Settings {
+				fmt.Fprintf(w, "%s\t%s\t%s\n", setting.Key, settingValue(setting, cfg, project), setting.Source(profile, project))
+			}
+			return w.Flush()
+		},
+	}
+}
+
+// configSetCmd sets a setting in a profile
+func configSetCmd() *cobra.Command {
+	var profileName string
+
+	cmd := &cobra.Command{
+		Use:   "set KEY VALUE",
+		Short: "Set a setting in a profile",
+		Long: `Set a setting in a profile: the one given with --profile, else the active
+profile. With neither, the setting goes in the "default" profile, which is
+made active.
+
+Examples:
+  qtest config set llm.tier2 deepseek-coder-v2:16b
+  qtest config set --profile work llm.provider anthropic`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			profile, activate, err := targetProfile(profileName)
+			if err != nil {
+				return err
+			}
+			if err := profile.Set(args[0], args[1]); err != nil {
+				return err
+			}
+			if err := profile.Save(); err != nil {
+				return err
+			}
+			if activate {
+				if err := config.UseProfile(profile.Name); err != nil {
+					return err
+				}
+			}
+			fmt.Printf("✓ Set %s in profile %s\n", args[0], profile.Name)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&profileName, "profile", "", "Profile to change (default: the active profile)")
+
+	return cmd
+}
+
+// configUnsetCmd removes a setting from a profile
+func configUnsetCmd() *cobra.Command {
+	var profileName string
+
+	cmd := &cobra.Command{
+		Use:   "unset KEY",
+		Short: "Remove a setting from a profile",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			profile, _, err := targetProfile(profileName)
+			if err != nil {
+				return err
+			}
+			if _, ok := profile.Values[args[0]]; !ok {
+				return fmt.Errorf("%s isn't set in profile %s", args[0], profile.Name)
+			}
+			delete(profile.Values, args[0])
+			if err := profile.Save(); err != nil {
+				return err
+			}
+			fmt.Printf("✓ Unset %s in profile %s\n", args[0], profile.Name)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&profileName, "profile", "", "Profile to change (default: the active profile)")
+
+	return cmd
+}
+
+// configUseProfileCmd switches the active profile
+func configUseProfileCmd() *cobra.Command {
+	var clearProfile bool
+
+	cmd := &cobra.Command{
+		Use:   "use-profile NAME",
+		Short: "Switch the active profile",
+		Long: `Make a profile the active one for later commands. QTEST_PROFILE overrides
+it for a single command.
+
+Examples:
+  qtest config use-profile work
+  QTEST_PROFILE=local qtest generate -r .
+  qtest config use-profile --clear`,
+		Args: cobra.RangeArgs(0, 1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			switch {
+			case clearProfile && len(args) == 0:
+				if err := config.UseProfile(""); err != nil {
+					return err
+				}
+				fmt.Println("✓ No profile in use")
+				return nil
+			case clearProfile || len(args) == 0:
+				return fmt.Errorf("give a profile name or --clear")
+			}
+
+			if err := config.UseProfile(args[0]); err != nil {
+				return err
+			}
+			fmt.Printf("✓ Using profile %s\n", args[0])
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&clearProfile, "clear", false, "Stop using a profile")
+
+	return cmd
+}
+
+// configProfilesCmd lists the saved profiles
+func configProfilesCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "profiles",
+		Short: "List profiles",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			names, err := config.ListProfiles()
+			if err != nil {
+				return err
+			}
+			active, err := config.ActiveProfileName()
+			if err != nil {
+				return err
+			}
+			if len(names) == 0 {
+				fmt.Println("No profiles. Create one with: qtest config set --profile NAME KEY VALUE")
+				return nil
+			}
+			for _, name := range names {
+				marker := " "
+				if name == active {
+					marker = "*"
+				}
+				fmt.Printf("%s %s\n", marker, name)
+			}
+			return nil
+		},
+	}
+}
+
+// targetProfile loads the profile a set or unset changes: the named one,
+// else the active one, else "default", which is then to be activated
+func targetProfile(name string) (*config.Profile, bool, error) {
+	activate := false
+	if name == "" {
+		active, err := config.ActiveProfileName()
+		if err != nil {
+			return nil, false, err
+		}
+		name = active
+		if name == "" {
+			name, activate = "default", true
+		}
+	}
+	profile, err := config.LoadProfile(name)
+	return profile, activate, err
+}
+
+// settingValue formats a setting's value for display, masking secrets
+func settingValue(setting config.Setting, cfg *config.Config, project *config.ProjectConfig) string {
+	value := setting.Value(cfg, project)
+	switch {
+	case value == "":
+		return "-"
+	case !setting.Secret:
+		return value
+	case strings.Contains(value, "://"):
+		return maskConnectionString(value)
+	case len(value) > 8:
+		return value[:4] + "..." + value[len(value)-4:]
+	default:
+		return "****"
+	}
+}
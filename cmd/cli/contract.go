@@ -43,7 +43,7 @@ func contractGenerateCmd() *cobra.Command {
 
 			var sysModel model.SystemModel
 			if err := json.Unmarshal(data, &sysModel); err != nil {
-				return fmt.Errorf("failed to parse model: %w", err)
+				return cliErrorf(exitParse, "failed to parse model: %w", err)
 			}
 
 			// Generate contract
@@ -95,7 +95,7 @@ func contractTestsCmd() *cobra.Command {
 
 			var apiContract contract.Contract
 			if err := json.Unmarshal(data, &apiContract); err != nil {
-				return fmt.Errorf("failed to parse contract: %w", err)
+				return cliErrorf(exitParse, "failed to parse contract: %w", err)
 			}
 
 			// Generate tests
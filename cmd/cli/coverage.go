@@ -6,9 +6,11 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/QTest-hq/qtest/internal/codecov"
 	"github.com/QTest-hq/qtest/internal/config"
+	"github.com/QTest-hq/qtest/internal/github"
 	"github.com/QTest-hq/qtest/internal/llm"
 	"github.com/QTest-hq/qtest/internal/workspace"
 	"github.com/QTest-hq/qtest/pkg/model"
@@ -39,6 +41,7 @@ func coverageCollectCmd() *cobra.Command {
 		outputFile string
 		jsonOut    bool
 		htmlOut    string
+		format     string
 	)
 
 	cmd := &cobra.Command{
@@ -51,14 +54,24 @@ Examples:
   qtest coverage collect -d ./myproject            # Collect for specific directory
   qtest coverage collect -o coverage.json          # Save JSON report
   qtest coverage collect --json                    # Output as JSON to stdout
-  qtest coverage collect --html ./reports          # Generate HTML report`,
+  qtest coverage collect --html ./reports          # Generate HTML report
+  qtest coverage collect --format cobertura -o coverage.xml  # Cobertura XML for CI
+  qtest coverage collect --format lcov             # LCOV tracefile to stdout`,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			switch format {
+			case "json", workspace.FormatCobertura, workspace.FormatLCOV:
+			default:
+				return fmt.Errorf("unknown format %q (use json, cobertura or lcov)", format)
+			}
+
 			// Auto-detect language if not specified
 			if language == "" {
 				language = detectProjectLanguage(workDir)
 			}
+			// A CI format without an output file goes to stdout on its own
+			quiet := jsonOut || (format != "json" && outputFile == "")
 
-			if !jsonOut {
+			if !quiet {
 				fmt.Printf("Collecting coverage for %s project...\n", language)
 				fmt.Printf("Working directory: %s\n\n", workDir)
 			}
@@ -77,12 +90,31 @@ Examples:
 				return nil
 			}
 
+			// A CI format without an output file is written to stdout
+			var exported []byte
+			if format != "json" {
+				absDir, _ := filepath.Abs(workDir)
+				exported, err = workspace.ExportCoverage(workspace.CoverageReportFromCodecov(report, absDir), format)
+				if err != nil {
+					return fmt.Errorf("failed to export coverage: %w", err)
+				}
+				if outputFile == "" {
+					os.Stdout.Write(exported)
+					return nil
+				}
+			}
+
 			// Display summary
 			displayCoverageReport(report)
 
-			// Save JSON report if output specified
+			// Save the report if output specified
 			if outputFile != "" {
-				if err := collector.SaveReport(report, outputFile); err != nil {
+				if exported != nil {
+					err = os.WriteFile(outputFile, exported, 0644)
+				} else {
+					err = collector.SaveReport(report, outputFile)
+				}
+				if err != nil {
 					return fmt.Errorf("failed to save report: %w", err)
 				}
 				fmt.Printf("\n📄 Report saved to: %s\n", outputFile)
@@ -103,9 +135,10 @@ Examples:
 
 	cmd.Flags().StringVarP(&workDir, "dir", "d", ".", "Working directory")
 	cmd.Flags().StringVarP(&language, "language", "l", "", "Language (auto-detected if not specified)")
-	cmd.Flags().StringVarP(&outputFile, "output", "o", "", "Output file for JSON coverage report")
+	cmd.Flags().StringVarP(&outputFile, "output", "o", "", "Output file for the coverage report")
 	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output as JSON to stdout")
 	cmd.Flags().StringVar(&htmlOut, "html", "", "Output directory for HTML report")
+	cmd.Flags().StringVar(&format, "format", "json", "Report format: json, cobertura, or lcov")
 
 	return cmd
 }
@@ -136,7 +169,7 @@ func coverageAnalyzeCmd() *cobra.Command {
 				}
 				sysModel = &model.SystemModel{}
 				if err := json.Unmarshal(data, sysModel); err != nil {
-					return fmt.Errorf("failed to parse model: %w", err)
+					return cliErrorf(exitParse, "failed to parse model: %w", err)
 				}
 			}
 
@@ -244,18 +277,18 @@ func coverageGenerateCmd() *cobra.Command {
 			// Load config
 			cfg, err := config.Load()
 			if err != nil {
-				return fmt.Errorf("failed to load config: %w", err)
+				return cliErrorf(exitConfig, "failed to load config: %w", err)
 			}
 
 			// Create LLM router
 			router, err := llm.NewRouter(cfg)
 			if err != nil {
-				return fmt.Errorf("failed to create LLM router: %w", err)
+				return cliErrorf(exitConfig, "failed to create LLM router: %w", err)
 			}
 
 			// Check LLM health
 			if err := router.HealthCheck(); err != nil {
-				return fmt.Errorf("LLM not available: %w\nMake sure Ollama is running: ollama serve", err)
+				return cliErrorf(exitLLM, "LLM not available: %w\nMake sure Ollama is running: ollama serve", err)
 			}
 
 			// Parse tier
@@ -361,7 +394,7 @@ func coverageGapsCmd() *cobra.Command {
 				}
 				sysModel = &model.SystemModel{}
 				if err := json.Unmarshal(data, sysModel); err != nil {
-					return fmt.Errorf("failed to parse model: %w", err)
+					return cliErrorf(exitParse, "failed to parse model: %w", err)
 				}
 			}
 
@@ -500,6 +533,9 @@ func coverageCICmd() *cobra.Command {
 		threshold float64
 		quiet     bool
 		jsonOut   bool
+		checkRun  bool
+		repo      string
+		sha       string
 	)
 
 	cmd := &cobra.Command{
@@ -507,13 +543,19 @@ func coverageCICmd() *cobra.Command {
 		Short: "CI-friendly coverage check with threshold enforcement",
 		Long: `Run coverage collection and check against a threshold.
 
-Returns exit code 1 if coverage is below the threshold.
+Returns exit code 6 (threshold_not_met) if coverage is below the threshold.
 Useful for CI pipelines to enforce minimum coverage.
 
+With --check-run the result is also published as the "QTest Quality Gate"
+check run on the commit, annotated with each file's uncovered lines, so
+branch protection can require it. It uses GITHUB_TOKEN, GITHUB_REPOSITORY
+and GITHUB_SHA, as set in GitHub Actions.
+
 Examples:
   qtest coverage ci -t 80                    # Fail if coverage < 80%
   qtest coverage ci -t 70 --quiet            # Quiet mode for scripts
-  qtest coverage ci -t 80 --json             # Output as JSON`,
+  qtest coverage ci -t 80 --json             # Output as JSON
+  qtest coverage ci -t 80 --check-run        # Publish a GitHub check run`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// Auto-detect language if not specified
 			if language == "" {
@@ -560,9 +602,19 @@ Examples:
 				}
 			}
 
+			if checkRun {
+				check, err := publishCoverageGate(context.Background(), report, workDir, threshold, repo, sha)
+				if err != nil {
+					return err
+				}
+				if !quiet && !jsonOut {
+					fmt.Printf("Published %s: %s\n", github.QualityGateName, check.HTMLURL)
+				}
+			}
+
 			// Exit with error if below threshold
 			if !passed {
-				return fmt.Errorf("coverage %.1f%% is below threshold %.1f%%", report.Percentage, threshold)
+				return cliErrorf(exitThreshold, "coverage %.1f%% is below threshold %.1f%%", report.Percentage, threshold)
 			}
 
 			return nil
@@ -574,10 +626,58 @@ Examples:
 	cmd.Flags().Float64VarP(&threshold, "threshold", "t", 80.0, "Minimum coverage threshold")
 	cmd.Flags().BoolVarP(&quiet, "quiet", "q", false, "Quiet mode (only exit code)")
 	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output as JSON")
+	cmd.Flags().BoolVar(&checkRun, "check-run", false, "Publish the result as a GitHub check run")
+	cmd.Flags().StringVar(&repo, "repo", os.Getenv("GITHUB_REPOSITORY"), "Repository for the check run, as owner/name")
+	cmd.Flags().StringVar(&sha, "sha", os.Getenv("GITHUB_SHA"), "Commit for the check run")
 
 	return cmd
 }
 
+// publishCoverageGate publishes a coverage report as the QTest Quality Gate
+// check run on a commit
+func publishCoverageGate(ctx context.Context, report *codecov.CoverageReport, workDir string, threshold float64, repo, sha string) (*github.CheckRunResponse, error) {
+	token := os.Getenv("GITHUB_TOKEN")
+	if token == "" {
+		return nil, fmt.Errorf("GitHub token required for --check-run. Set GITHUB_TOKEN env var")
+	}
+	owner, name, ok := strings.Cut(repo, "/")
+	if !ok || owner == "" || name == "" {
+		return nil, fmt.Errorf("invalid --repo %q: want owner/name", repo)
+	}
+	if sha == "" {
+		return nil, fmt.Errorf("commit required for --check-run. Set GITHUB_SHA env var or use --sha")
+	}
+
+	gate := github.QualityGate{MinCoverage: threshold}
+	check, err := github.NewPRService(token).PublishQualityGate(ctx, owner, name, sha, "", gate, coverageQualityReport(report, workDir))
+	if err != nil {
+		return nil, fmt.Errorf("failed to publish check run: %w", err)
+	}
+	return check, nil
+}
+
+// coverageQualityReport converts a coverage report for the quality gate,
+// with file paths relative to the repository
+func coverageQualityReport(report *codecov.CoverageReport, workDir string) github.QualityReport {
+	quality := github.QualityReport{Coverage: report.Percentage, CoverageMeasured: true}
+	root, _ := filepath.Abs(workDir)
+	for _, f := range report.Files {
+		path := f.Path
+		if filepath.IsAbs(path) {
+			if rel, err := filepath.Rel(root, path); err == nil && !strings.HasPrefix(rel, "..") {
+				path = rel
+			}
+		}
+		quality.Files = append(quality.Files, github.FileQuality{
+			Path:             filepath.ToSlash(path),
+			Coverage:         f.Percentage,
+			CoverageMeasured: true,
+			UncoveredLines:   f.UncoveredLines,
+		})
+	}
+	return quality
+}
+
 // displayCoverageReport displays a coverage report in text format
 func displayCoverageReport(report *codecov.CoverageReport) {
 	fmt.Printf("📊 Coverage Report\n")
@@ -217,3 +217,37 @@ func TestGenerateCoverageHTML_NestedDir(t *testing.T) {
 		t.Error("HTML file should be created in nested directory")
 	}
 }
+
+func TestCoverageQualityReport(t *testing.T) {
+	dir := t.TempDir()
+	report := &codecov.CoverageReport{
+		Percentage: 62.5,
+		Files: []codecov.FileCoverage{
+			{Path: filepath.Join(dir, "pkg", "calc.go"), Percentage: 50, UncoveredLines: []int{3, 4}},
+			{Path: "util.go", Percentage: 75},
+		},
+	}
+
+	quality := coverageQualityReport(report, dir)
+	if !quality.CoverageMeasured || quality.Coverage != 62.5 || quality.MutationMeasured {
+		t.Errorf("quality = %+v", quality)
+	}
+	if len(quality.Files) != 2 || quality.Files[0].Path != "pkg/calc.go" || quality.Files[1].Path != "util.go" {
+		t.Fatalf("files = %+v, want paths relative to the repository", quality.Files)
+	}
+	if len(quality.Files[0].UncoveredLines) != 2 {
+		t.Errorf("uncovered lines = %v", quality.Files[0].UncoveredLines)
+	}
+}
+
+func TestPublishCoverageGate_NeedsRepoAndCommit(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "test-token")
+	report := &codecov.CoverageReport{}
+
+	if _, err := publishCoverageGate(t.Context(), report, ".", 80, "no-slash", "abc"); err == nil || !strings.Contains(err.Error(), "owner/name") {
+		t.Errorf("bad repo error = %v", err)
+	}
+	if _, err := publishCoverageGate(t.Context(), report, ".", 80, "o/r", ""); err == nil || !strings.Contains(err.Error(), "GITHUB_SHA") {
+		t.Errorf("missing sha error = %v", err)
+	}
+}
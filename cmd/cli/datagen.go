@@ -4,21 +4,38 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
 
+	"github.com/QTest-hq/qtest/internal/config"
 	"github.com/QTest-hq/qtest/internal/datagen"
 	"github.com/spf13/cobra"
 )
 
+// Flags shared by all datagen subcommands
+var (
+	datagenSeed   int64
+	datagenLocale string
+)
+
 func datagenCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "datagen",
 		Short: "Generate test data",
-		Long:  `Generate realistic test data for your tests`,
+		Long: `Generate realistic test data for your tests.
+
+Pass --seed (or set datagen.seed in .qtest.yaml) to make every generated
+value, including IDs and dates, reproducible across runs. Pass --locale
+(or datagen.locale) to generate region-specific names, addresses and phone
+numbers. Available locales: ` + strings.Join(datagen.Locales(), ", "),
 	}
 
+	cmd.PersistentFlags().Int64Var(&datagenSeed, "seed", 0, "Seed for reproducible data (0 = random, falls back to datagen.seed in .qtest.yaml)")
+	cmd.PersistentFlags().StringVar(&datagenLocale, "locale", "", "Locale for names, addresses and phones (falls back to datagen.locale in .qtest.yaml)")
+
 	cmd.AddCommand(datagenSampleCmd())
 	cmd.AddCommand(datagenSchemaCmd())
 	cmd.AddCommand(datagenFieldCmd())
+	cmd.AddCommand(datagenAnonymizeCmd())
 
 	return cmd
 }
@@ -30,7 +47,10 @@ func datagenSampleCmd() *cobra.Command {
 		Use:   "sample",
 		Short: "Generate sample data for common types",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			gen := datagen.NewDataGenerator()
+			gen, err := newDataGenerator()
+			if err != nil {
+				return err
+			}
 
 			fmt.Println("Sample Test Data:")
 			fmt.Println("=================")
@@ -91,11 +111,20 @@ func datagenSchemaCmd() *cobra.Command {
 				return fmt.Errorf("failed to read schema: %w", err)
 			}
 
+			settings := resolveDatagenSettings()
 			gen := datagen.NewSchemaGenerator()
+			if settings.Seed != 0 {
+				gen = datagen.NewSeededSchemaGenerator(settings.Seed)
+			}
+			if settings.Locale != "" {
+				if err := gen.SetLocale(settings.Locale); err != nil {
+					return err
+				}
+			}
 
 			var schema datagen.Schema
 			if err := json.Unmarshal(data, &schema); err != nil {
-				return fmt.Errorf("failed to parse schema: %w", err)
+				return cliErrorf(exitParse, "failed to parse schema: %w", err)
 			}
 
 			var results []interface{}
@@ -144,7 +173,10 @@ func datagenFieldCmd() *cobra.Command {
 				typeName = args[1]
 			}
 
-			gen := datagen.NewDataGenerator()
+			gen, err := newDataGenerator()
+			if err != nil {
+				return err
+			}
 			value := gen.GenerateForType(typeName, fieldName)
 
 			// Pretty print based on type
@@ -162,3 +194,100 @@ func datagenFieldCmd() *cobra.Command {
 
 	return cmd
 }
+
+func datagenAnonymizeCmd() *cobra.Command {
+	var (
+		inputFile   string
+		count       int
+		outputFile  string
+		profileFile string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "anonymize",
+		Short: "Generate anonymized fixtures shaped like a production sample",
+		Long: `Profiles a sample dataset (CSV with header row, or JSON array of objects)
+and generates statistically similar fixtures. Numeric fields follow the sampled
+range and spread, enum-like fields keep their value frequencies, and personal
+data (names, emails, phones, IDs, ...) is replaced with fake values.
+
+Example:
+  qtest datagen anonymize -i prod_users.csv -n 100 -o fixtures/users.json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rows, err := datagen.LoadSamples(inputFile)
+			if err != nil {
+				return err
+			}
+
+			profile := datagen.ProfileSamples(rows)
+			if profileFile != "" {
+				data, _ := json.MarshalIndent(profile, "", "  ")
+				if err := os.WriteFile(profileFile, data, 0644); err != nil {
+					return fmt.Errorf("failed to write profile: %w", err)
+				}
+			}
+
+			settings := resolveDatagenSettings()
+			anonymizer := datagen.NewAnonymizer()
+			if settings.Seed != 0 {
+				anonymizer = datagen.NewSeededAnonymizer(settings.Seed)
+			}
+			if settings.Locale != "" {
+				if err := anonymizer.SetLocale(settings.Locale); err != nil {
+					return err
+				}
+			}
+			fixtures := anonymizer.Generate(profile, count)
+			output, _ := json.MarshalIndent(fixtures, "", "  ")
+
+			if outputFile != "" {
+				if err := os.WriteFile(outputFile, output, 0644); err != nil {
+					return fmt.Errorf("failed to write output: %w", err)
+				}
+				fmt.Printf("Generated %d anonymized record(s) from %d sample row(s) to: %s\n", count, profile.Rows, outputFile)
+			} else {
+				fmt.Println(string(output))
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&inputFile, "input", "i", "", "Sample dataset (.csv or .json) (required)")
+	cmd.Flags().IntVarP(&count, "count", "n", 10, "Number of records to generate")
+	cmd.Flags().StringVarP(&outputFile, "output", "o", "", "Output file")
+	cmd.Flags().StringVar(&profileFile, "profile", "", "Also write the field profile (no raw values) to this file")
+	cmd.MarkFlagRequired("input")
+
+	return cmd
+}
+
+// resolveDatagenSettings returns the --seed/--locale flags, falling back to
+// the project config. A zero seed means unseeded.
+func resolveDatagenSettings() config.DatagenConfig {
+	settings := config.DatagenConfig{}
+	if cfg, err := config.LoadProjectConfig("."); err == nil {
+		settings = cfg.Datagen
+	}
+	if datagenSeed != 0 {
+		settings.Seed = datagenSeed
+	}
+	if datagenLocale != "" {
+		settings.Locale = datagenLocale
+	}
+	return settings
+}
+
+func newDataGenerator() (*datagen.DataGenerator, error) {
+	settings := resolveDatagenSettings()
+	gen := datagen.NewDataGenerator()
+	if settings.Seed != 0 {
+		gen = datagen.NewSeededDataGenerator(settings.Seed)
+	}
+	if settings.Locale != "" {
+		if err := gen.SetLocale(settings.Locale); err != nil {
+			return nil, err
+		}
+	}
+	return gen, nil
+}
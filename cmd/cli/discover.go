@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/QTest-hq/qtest/internal/config"
+	"github.com/QTest-hq/qtest/internal/discovery"
+	"github.com/QTest-hq/qtest/pkg/model"
+)
+
+// discoveryFlags are analyze's --discover-* overrides for the discovery
+// section of .qtest.yaml
+type discoveryFlags struct {
+	image   string
+	command string
+	port    int
+	timeout time.Duration
+}
+
+// discoveryConfig combines the project's discovery settings with flag
+// overrides, scraping routes for the frameworks detected statically
+func discoveryConfig(project config.DiscoveryConfig, flags discoveryFlags, frameworks []model.FrameworkDetection) (discovery.Config, error) {
+	cfg := discovery.Config{
+		Image:          project.Image,
+		Command:        project.Command,
+		Port:           project.Port,
+		Env:            project.Env,
+		StartupTimeout: time.Duration(project.StartupTimeout) * time.Second,
+	}
+	if flags.image != "" {
+		cfg.Image = flags.image
+	}
+	if flags.command != "" {
+		cfg.Command = flags.command
+	}
+	if flags.port != 0 {
+		cfg.Port = flags.port
+	}
+	if flags.timeout != 0 {
+		cfg.StartupTimeout = flags.timeout
+	}
+	for _, d := range frameworks {
+		cfg.Frameworks = append(cfg.Frameworks, d.Framework)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return cfg, fmt.Errorf("%w (set it under discovery in .qtest.yaml or with --discover-* flags)", err)
+	}
+	return cfg, nil
+}
+
+// discoverRoutes boots the service in root and merges the routes it
+// reports into the model
+func discoverRoutes(ctx context.Context, root string, flags discoveryFlags, sysModel *model.SystemModel) error {
+	project, err := config.LoadProjectConfig(root)
+	if err != nil {
+		return cliErrorf(exitConfig, "failed to load project config: %w", err)
+	}
+	cfg, err := discoveryConfig(project.Discovery, flags, sysModel.GetFrameworks())
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("🐳 Booting service in %s for route discovery...\n", cfg.Image)
+	result, err := discovery.NewDiscoverer(discovery.NewDockerRuntime()).Discover(ctx, root, cfg)
+	if err != nil {
+		return fmt.Errorf("route discovery failed: %w", err)
+	}
+
+	added := discovery.MergeEndpoints(sysModel, result.Endpoints)
+	fmt.Printf("   Found %d routes at runtime (%d new)\n", len(result.Endpoints), added)
+	return nil
+}
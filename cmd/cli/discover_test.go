@@ -0,0 +1,44 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/QTest-hq/qtest/internal/config"
+	"github.com/QTest-hq/qtest/pkg/model"
+)
+
+func TestDiscoveryConfig(t *testing.T) {
+	project := config.DiscoveryConfig{
+		Image:          "node:20",
+		Command:        "npm start",
+		Port:           3000,
+		Env:            map[string]string{"NODE_ENV": "test"},
+		StartupTimeout: 90,
+	}
+	frameworks := []model.FrameworkDetection{{Framework: "express"}, {Framework: "nestjs"}}
+
+	cfg, err := discoveryConfig(project, discoveryFlags{}, frameworks)
+	if err != nil {
+		t.Fatalf("discoveryConfig() error = %v", err)
+	}
+	if cfg.Image != "node:20" || cfg.Port != 3000 || cfg.StartupTimeout != 90*time.Second || cfg.Env["NODE_ENV"] != "test" {
+		t.Errorf("cfg = %+v", cfg)
+	}
+	if len(cfg.Frameworks) != 2 || cfg.Frameworks[1] != "nestjs" {
+		t.Errorf("Frameworks = %v", cfg.Frameworks)
+	}
+
+	// Flags override the project config
+	cfg, err = discoveryConfig(project, discoveryFlags{command: "node dist/main.js", port: 8080, timeout: time.Minute}, nil)
+	if err != nil {
+		t.Fatalf("discoveryConfig() error = %v", err)
+	}
+	if cfg.Image != "node:20" || cfg.Command != "node dist/main.js" || cfg.Port != 8080 || cfg.StartupTimeout != time.Minute {
+		t.Errorf("cfg = %+v", cfg)
+	}
+
+	if _, err := discoveryConfig(config.DiscoveryConfig{}, discoveryFlags{port: 3000}, nil); err == nil {
+		t.Error("expected error without an image and command")
+	}
+}
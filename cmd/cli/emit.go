@@ -5,9 +5,13 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
+	"github.com/QTest-hq/qtest/internal/buildsys"
+	"github.com/QTest-hq/qtest/internal/config"
 	"github.com/QTest-hq/qtest/internal/emitter"
+	"github.com/QTest-hq/qtest/internal/lineage"
 	"github.com/QTest-hq/qtest/pkg/model"
 	"github.com/spf13/cobra"
 )
@@ -18,6 +22,11 @@ func emitTestsCmd() *cobra.Command {
 		outputDir   string
 		emitterName string
 		language    string
+		allure      bool
+		tags        []string
+		assertions  string
+		lineagePath string
+		addDevDeps  bool
 	)
 
 	cmd := &cobra.Command{
@@ -29,9 +38,14 @@ Supported emitters:
   - supertest: Jest + Supertest for Express/Node.js
   - pytest: pytest + httpx for FastAPI/Python
   - go-http: Go net/http testing
+  - junit: JUnit 5 + MockMvc for Spring Boot
+  - xunit: xUnit + WebApplicationFactory for ASP.NET Core
+  - cucumber, godog, behave: Gherkin .feature files plus step definitions
+  - grpc-go, pytest-grpc: gRPC client tests against a bufconn, in-process or running server
 
 Example:
-  qtest emit-tests -s specs.json -o ./tests --emitter supertest`,
+  qtest emit-tests -s specs.json -o ./tests --emitter supertest
+  qtest emit-tests -s specs.json -o ./features --emitter behave`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// Load specs
 			data, err := os.ReadFile(specsFile)
@@ -41,7 +55,7 @@ Example:
 
 			var specSet model.TestSpecSet
 			if err := json.Unmarshal(data, &specSet); err != nil {
-				return fmt.Errorf("failed to parse specs: %w", err)
+				return cliErrorf(exitParse, "failed to parse specs: %w", err)
 			}
 
 			fmt.Printf("📝 Loaded %d test specifications\n", len(specSet.Specs))
@@ -65,18 +79,108 @@ Example:
 				em, _ = registry.Get("supertest") // Default
 			}
 
-			fmt.Printf("🔧 Using emitter: %s (%s)\n\n", em.Name(), em.Framework())
+			if allure {
+				switch e := em.(type) {
+				case *emitter.PytestEmitter:
+					e.Allure = true
+				case *emitter.SupertestEmitter:
+					e.Allure = true
+				default:
+					fmt.Printf("⚠️  Allure annotations not supported by %s emitter\n", em.Name())
+				}
+			}
+
+			// Fall back to the tags section of .qtest.yaml
+			if len(tags) == 0 {
+				if projectCfg, err := config.LoadProjectConfig("."); err == nil && projectCfg.Tags.Enabled {
+					tags = projectCfg.Tags.Default
+					if len(tags) == 0 {
+						tags = []string{emitter.TagGenerated}
+					}
+				}
+			}
+
+			if len(tags) > 0 {
+				tagCfg := emitter.TagConfig{Enabled: true, Default: tags}
+				switch e := em.(type) {
+				case *emitter.GoHTTPEmitter:
+					e.Tags = tagCfg
+				case *emitter.PytestEmitter:
+					e.Tags = tagCfg
+				case *emitter.SupertestEmitter:
+					e.Tags = tagCfg
+				default:
+					fmt.Printf("⚠️  Test tags not supported by %s emitter\n", em.Name())
+				}
+			}
+
+			// Assertion library: the flag, then .qtest.yaml, then whatever
+			// the project's existing tests use
+			if assertions != "" {
+				if err := emitter.SetAssertionLibrary(em, assertions); err != nil {
+					return err
+				}
+			} else {
+				if projectCfg, err := config.LoadProjectConfig("."); err == nil {
+					assertions = projectCfg.Framework.Assertions[em.Language()]
+				}
+				if assertions != "" {
+					if err := emitter.SetAssertionLibrary(em, assertions); err != nil {
+						fmt.Printf("⚠️  %v\n", err)
+						assertions = ""
+					}
+				} else if lib := emitter.DetectAssertionLibrary(".", em.Language()); emitter.SetAssertionLibrary(em, lib) == nil {
+					// Emitters without assertion options keep their own style
+					assertions = lib
+				}
+			}
+
+			// JUnit tests only run from the Maven or Gradle test source set
+			if junit, ok := em.(*emitter.JUnitEmitter); ok {
+				outputDir = placeJUnitTests(junit, outputDir, cmd.Flags().Changed("output"))
+			}
+
+			fmt.Printf("🔧 Using emitter: %s (%s)\n", em.Name(), em.Framework())
+			if assertions != "" {
+				fmt.Printf("   Assertions: %s\n", assertions)
+			}
+			fmt.Println()
 
 			// Group specs by level
 			apiSpecs := specSet.FilterByLevel(model.LevelAPI)
 			unitSpecs := specSet.FilterByLevel(model.LevelUnit)
 
+			// Response time budgets from .qtest.yaml
+			switch em.(type) {
+			case *emitter.GoHTTPEmitter, *emitter.SupertestEmitter, *emitter.PytestEmitter:
+				if projectCfg, err := config.LoadProjectConfig("."); err == nil {
+					if n := addLatencyChecks(apiSpecs, projectCfg.Latency); n > 0 {
+						fmt.Printf("⏱️  Checking response times of %d endpoint(s)\n\n", n)
+					}
+				}
+			}
+
 			// Create output directory
 			if err := os.MkdirAll(outputDir, 0755); err != nil {
 				return fmt.Errorf("failed to create output directory: %w", err)
 			}
 
 			filesWritten := 0
+			emitted := make(map[string][]model.TestSpec)
+
+			// BDD emitters cover API and E2E specs in one feature file
+			if bdd, ok := em.(emitter.StepDefinitionEmitter); ok {
+				bddSpecs := append(apiSpecs, specSet.FilterByLevel(model.LevelE2E)...)
+				paths, err := writeBDDFiles(bdd, bddSpecs, outputDir)
+				if err != nil {
+					return err
+				}
+				for _, p := range paths {
+					emitted[p] = bddSpecs
+				}
+				filesWritten += len(paths)
+				apiSpecs = nil
+			}
 
 			// Emit API tests
 			if len(apiSpecs) > 0 {
@@ -86,6 +190,9 @@ Example:
 				}
 
 				filename := "api" + em.FileExtension()
+				if junit, ok := em.(*emitter.JUnitEmitter); ok {
+					filename = junit.FileName()
+				}
 				filepath := filepath.Join(outputDir, filename)
 
 				if err := os.WriteFile(filepath, []byte(code), 0644); err != nil {
@@ -93,6 +200,7 @@ Example:
 				}
 
 				fmt.Printf("✅ Written: %s (%d API tests)\n", filepath, len(apiSpecs))
+				emitted[filepath] = apiSpecs
 				filesWritten++
 			}
 
@@ -107,15 +215,205 @@ Example:
 			fmt.Printf("📦 Generated %d test file(s) in %s\n", filesWritten, outputDir)
 			fmt.Printf("   Framework: %s\n", em.Framework())
 
+			paths := make([]string, 0, len(emitted))
+			for path := range emitted {
+				paths = append(paths, path)
+			}
+			sort.Strings(paths)
+			checkTestSetup(paths, addDevDeps)
+
+			if lineagePath != "" {
+				err := updateLineage(lineagePath, func(g *lineage.Graph) {
+					g.AddSpecs(&specSet)
+					for path, specs := range emitted {
+						g.AddEmittedFile(path, specs)
+					}
+				})
+				if err != nil {
+					return err
+				}
+				fmt.Printf("   Lineage:   %s\n", lineagePath)
+			}
+
 			return nil
 		},
 	}
 
 	cmd.Flags().StringVarP(&specsFile, "specs", "s", "", "Test specifications JSON file (required)")
 	cmd.Flags().StringVarP(&outputDir, "output", "o", "./tests", "Output directory for test files")
-	cmd.Flags().StringVarP(&emitterName, "emitter", "e", "", "Emitter name (supertest, pytest, go-http)")
+	cmd.Flags().StringVarP(&emitterName, "emitter", "e", "", "Emitter name (supertest, pytest, go-http, junit, grpc-go, pytest-grpc, cucumber, godog, behave)")
 	cmd.Flags().StringVarP(&language, "language", "l", "", "Target language (javascript, python, go)")
+	cmd.Flags().BoolVar(&allure, "allure", false, "Annotate tests with Allure labels (pytest, supertest)")
+	cmd.Flags().StringSliceVar(&tags, "tags", nil, "Tag tests with their level plus these tags, e.g. --tags generated (go-http, pytest, supertest)")
+	cmd.Flags().StringVar(&assertions, "assertions", "", "Assertion library: testify or require (go-http), chai (supertest), assertpy (pytest); detected from existing tests if unset")
+	cmd.Flags().StringVar(&lineagePath, "lineage", "", "Record spec -> file lineage in this graph file (e.g. "+lineage.DefaultPath+")")
+	cmd.Flags().BoolVar(&addDevDeps, "add-dev-deps", false, "Declare the dependencies the tests need in package.json, pyproject.toml or requirements-dev.txt")
 	cmd.MarkFlagRequired("specs")
 
 	return cmd
 }
+
+// addLatencyChecks adds a latency assertion with the budget cfg sets for
+// each spec's endpoint, unless the spec already has one, and returns how
+// many it added
+func addLatencyChecks(specs []model.TestSpec, cfg config.LatencyConfig) int {
+	added := 0
+	for i := range specs {
+		spec := &specs[i]
+		if spec.Method == "" || hasLatencyCheck(spec) {
+			continue
+		}
+		budget, ok := cfg.Budget(spec.Method, spec.Path)
+		if !ok {
+			continue
+		}
+		expected := map[string]interface{}{}
+		if budget.P50 > 0 {
+			expected["p50"] = budget.P50
+		}
+		if budget.P95 > 0 {
+			expected["p95"] = budget.P95
+		}
+		if budget.Samples > 0 {
+			expected["samples"] = float64(budget.Samples)
+		}
+		// Copy so the spec set's assertions are left as they were
+		assertions := make([]model.Assertion, len(spec.Assertions), len(spec.Assertions)+1)
+		copy(assertions, spec.Assertions)
+		spec.Assertions = append(assertions, model.Assertion{Kind: model.AssertLatency, Actual: "response_time", Expected: expected})
+		added++
+	}
+	return added
+}
+
+// hasLatencyCheck reports whether a spec already checks its response time
+func hasLatencyCheck(spec *model.TestSpec) bool {
+	for _, a := range spec.Assertions {
+		if a.Kind == model.AssertLatency {
+			return true
+		}
+	}
+	return false
+}
+
+// placeJUnitTests returns the directory JUnit tests go in: the test source
+// set of the Maven or Gradle project in the current directory, unless -o
+// was given. The emitter takes the package of the directory under
+// src/test/java, and the project gets the test dependencies it lacks.
+func placeJUnitTests(em *emitter.JUnitEmitter, outputDir string, explicit bool) string {
+	project := buildsys.DetectJava(".")
+	if project != nil && !explicit {
+		outputDir = project.TestDir()
+	}
+	if pkg, ok := buildsys.JavaTestPackage(outputDir); ok && pkg != "" {
+		em.Package = pkg
+	}
+	if project == nil {
+		return outputDir
+	}
+
+	added, err := project.AddTestDependencies()
+	if err != nil {
+		fmt.Printf("⚠️  %v\n", err)
+	} else if len(added) > 0 {
+		fmt.Printf("📦 Added test dependencies to %s: %s\n", project.BuildFile, strings.Join(added, ", "))
+	}
+	return outputDir
+}
+
+// checkTestSetup reports what the projects holding testFiles lack to run
+// them. JS/TS packages need a jest or vitest devDependency, the tooling the
+// tests import and a test script. Python projects need pytest and the
+// plugins the tests use. With apply set, the projects get them.
+func checkTestSetup(testFiles []string, apply bool) {
+	nodeSetups, err := buildsys.CheckNode(testFiles)
+	if err != nil {
+		fmt.Printf("⚠️  %v\n", err)
+	}
+	pythonSetups, err := buildsys.CheckPython(testFiles)
+	if err != nil {
+		fmt.Printf("⚠️  %v\n", err)
+	}
+
+	var setups []testSetup
+	for _, s := range nodeSetups {
+		install := ""
+		if len(s.DevDependencies) > 0 {
+			install = s.Manager + " install"
+		}
+		setups = append(setups, testSetup{s, s.InstallCommand(), install})
+	}
+	for _, s := range pythonSetups {
+		install := "pip install"
+		for _, req := range s.Requirements() {
+			install += fmt.Sprintf(" %q", req)
+		}
+		setups = append(setups, testSetup{s, install, s.InstallCommand()})
+	}
+
+	for _, setup := range setups {
+		fmt.Println()
+		if !apply {
+			fmt.Printf("⚠️  Tests need setup to run: %s", setup.project.Report())
+			if setup.install != "" {
+				fmt.Printf("   Run: %s\n", setup.install)
+			}
+			fmt.Println("   or pass --add-dev-deps to declare the dependencies")
+			continue
+		}
+		if err := setup.project.Apply(); err != nil {
+			fmt.Printf("⚠️  %v\n", err)
+			continue
+		}
+		fmt.Printf("📦 Added test setup to %s", setup.project.Report())
+		if setup.installApplied != "" {
+			fmt.Printf("   Run: %s\n", setup.installApplied)
+		}
+	}
+}
+
+// testSetup is a project's missing test setup, and the commands that
+// install it before and after it's declared
+type testSetup struct {
+	project interface {
+		Report() string
+		Apply() error
+	}
+	install        string
+	installApplied string
+}
+
+// writeBDDFiles writes a Gherkin feature file and its step definitions,
+// returning the paths written
+func writeBDDFiles(em emitter.StepDefinitionEmitter, specs []model.TestSpec, outputDir string) ([]string, error) {
+	if len(specs) == 0 {
+		return nil, nil
+	}
+
+	feature, err := em.Emit(specs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to emit features: %w", err)
+	}
+
+	featurePath := filepath.Join(outputDir, "qtest"+em.FileExtension())
+	if err := os.WriteFile(featurePath, []byte(feature), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", featurePath, err)
+	}
+	fmt.Printf("✅ Written: %s (%d scenarios)\n", featurePath, len(specs))
+
+	steps, err := em.EmitSteps(specs)
+	if err != nil {
+		return []string{featurePath}, fmt.Errorf("failed to emit step definitions: %w", err)
+	}
+
+	stepsPath := filepath.Join(outputDir, em.StepsFileName())
+	if err := os.MkdirAll(filepath.Dir(stepsPath), 0755); err != nil {
+		return []string{featurePath}, fmt.Errorf("failed to create steps directory: %w", err)
+	}
+	if err := os.WriteFile(stepsPath, []byte(steps), 0644); err != nil {
+		return []string{featurePath}, fmt.Errorf("failed to write %s: %w", stepsPath, err)
+	}
+	fmt.Printf("✅ Written: %s (step definitions)\n", stepsPath)
+
+	return []string{featurePath, stepsPath}, nil
+}
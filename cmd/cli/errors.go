@@ -0,0 +1,87 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/QTest-hq/qtest/internal/llm"
+)
+
+// Exit codes by kind of failure, so CI scripts can branch on them
+const (
+	exitError      = 1 // any failure not classified below
+	exitConfig     = 2
+	exitLLM        = 3
+	exitParse      = 4
+	exitValidation = 5
+	exitThreshold  = 6
+)
+
+// errorKinds names each exit code in machine-readable errors
+var errorKinds = map[int]string{
+	exitError:      "error",
+	exitConfig:     "config_error",
+	exitLLM:        "llm_unavailable",
+	exitParse:      "parse_failure",
+	exitValidation: "validation_failure",
+	exitThreshold:  "threshold_not_met",
+}
+
+// cliError is a failure of a known kind, exiting with its code
+type cliError struct {
+	code int
+	err  error
+}
+
+func (e *cliError) Error() string {
+	return e.err.Error()
+}
+
+func (e *cliError) Unwrap() error {
+	return e.err
+}
+
+// cliErrorf formats an error that exits with code
+func cliErrorf(code int, format string, args ...interface{}) error {
+	return &cliError{code: code, err: fmt.Errorf(format, args...)}
+}
+
+// exitCode returns the exit code for a command's error. Errors from LLM
+// providers whose circuits are open count as the LLM being unavailable.
+func exitCode(err error) int {
+	var cliErr *cliError
+	switch {
+	case errors.As(err, &cliErr):
+		return cliErr.code
+	case errors.Is(err, llm.ErrCircuitOpen):
+		return exitLLM
+	default:
+		return exitError
+	}
+}
+
+// reportError writes a command's error to w, as text or, with format
+// "json", as {"error": {"kind", "exit_code", "message"}}, and returns the
+// exit code
+func reportError(w io.Writer, err error, format string) int {
+	code := exitCode(err)
+	if format != "json" {
+		fmt.Fprintf(w, "Error: %s\n", err)
+		return code
+	}
+
+	data, _ := json.Marshal(map[string]interface{}{
+		"error": map[string]interface{}{
+			"kind":      errorKinds[code],
+			"exit_code": code,
+			"message":   err.Error(),
+		},
+	})
+	fmt.Fprintln(w, string(data))
+	return code
+}
+
+// errorFormat is the --error-format flag: "text" or "json"
+var errorFormat string
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/QTest-hq/qtest/internal/llm"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"plain", errors.New("boom"), exitError},
+		{"classified", cliErrorf(exitParse, "failed to parse file: %w", errors.New("bad")), exitParse},
+		{"wrapped", fmt.Errorf("generation failed: %w", cliErrorf(exitConfig, "no key")), exitConfig},
+		{"circuit open", fmt.Errorf("tier 2: %w", llm.ErrCircuitOpen), exitLLM},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exitCode(tt.err); got != tt.want {
+				t.Errorf("exitCode() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReportError(t *testing.T) {
+	err := cliErrorf(exitThreshold, "coverage %.1f%% is below threshold %.1f%%", 42.0, 80.0)
+
+	var text bytes.Buffer
+	if code := reportError(&text, err, ""); code != exitThreshold {
+		t.Errorf("text exit code = %d, want %d", code, exitThreshold)
+	}
+	if got := text.String(); got != "Error: coverage 42.0% is below threshold 80.0%\n" {
+		t.Errorf("text output = %q", got)
+	}
+
+	var out bytes.Buffer
+	if code := reportError(&out, err, "json"); code != exitThreshold {
+		t.Errorf("json exit code = %d, want %d", code, exitThreshold)
+	}
+	var parsed struct {
+		Error struct {
+			Kind     string `json:"kind"`
+			ExitCode int    `json:"exit_code"`
+			Message  string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &parsed); err != nil {
+		t.Fatalf("json output %q: %v", out.String(), err)
+	}
+	if parsed.Error.Kind != "threshold_not_met" || parsed.Error.ExitCode != exitThreshold || !strings.Contains(parsed.Error.Message, "below threshold") {
+		t.Errorf("json error = %+v", parsed.Error)
+	}
+}
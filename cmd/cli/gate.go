@@ -0,0 +1,421 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/QTest-hq/qtest/internal/baseline"
+	"github.com/QTest-hq/qtest/internal/codecov"
+	"github.com/QTest-hq/qtest/internal/github"
+	"github.com/QTest-hq/qtest/internal/mutation"
+	"github.com/QTest-hq/qtest/internal/validator"
+	"github.com/spf13/cobra"
+)
+
+// gateResult is the consolidated outcome of qtest gate
+type gateResult struct {
+	Passed     bool                    `json:"passed"`
+	Failures   []string                `json:"failures,omitempty"`
+	Coverage   *codecov.CoverageReport `json:"coverage,omitempty"`
+	Mutation   []*mutation.Result      `json:"mutation,omitempty"`
+	Flakiness  *validator.FlakeReport  `json:"flakiness,omitempty"`
+	Conclusion string                  `json:"conclusion"`
+	Summary    string                  `json:"summary"`
+	Baseline   *baseline.Entry         `json:"baseline,omitempty"` // compared against when ratcheting
+	Recorded   bool                    `json:"recorded,omitempty"` // whether this run was recorded as a baseline
+}
+
+func gateCmd() *cobra.Command {
+	var (
+		workDir         string
+		language        string
+		minCoverage     float64
+		minMutation     float64
+		maxFlakeRate    float64
+		coverageReport  string
+		mutationReports []string
+		flakeReport     string
+		runs            int
+		jsonOut         bool
+		checkRun        bool
+		repo            string
+		sha             string
+		ratchet         bool
+		record          bool
+		baselinePath    string
+		baselineBranch  string
+		baselineCommit  string
+		branch          string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "gate",
+		Short: "Check coverage, mutation score and flake rate against thresholds",
+		Long: `Measure coverage, mutation score and flakiness, or load earlier results,
+and fail with one report if any threshold is missed. Only the metrics with a
+threshold are measured.
+
+Mutation testing runs on the sources QTest generated tests for, found by
+their provenance headers. Flakiness is measured by running the whole suite
+--runs times: a test that both passes and fails is flaky, and the flake rate
+is the percent of tests that are.
+
+With --ratchet the gate compares against the base branch's baseline, stored
+in .qtest/baselines.json: a metric fails only when it's worse than both its
+threshold and the baseline. --record saves a passing run's metrics as the
+current branch's baseline, typically on main after merging.
+
+Examples:
+  qtest gate --coverage 80 --mutation 60 --flake-rate 1
+  qtest gate --coverage 80 --coverage-report coverage.json
+  qtest gate --mutation 60 --mutation-report a.json --mutation-report b.json
+  qtest gate --flake-rate 0 --runs 5 --json
+  qtest gate --coverage 80 --mutation 60 --ratchet            # on pull requests
+  qtest gate --coverage 80 --mutation 60 --ratchet --record   # on main`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			gate := github.QualityGate{
+				MinCoverage:      minCoverage,
+				MinMutationScore: minMutation,
+				MaxFlakeRate:     maxFlakeRate,
+				CheckFlakeRate:   cmd.Flags().Changed("flake-rate"),
+			}
+			if gate.MinCoverage <= 0 && gate.MinMutationScore <= 0 && !gate.CheckFlakeRate {
+				return fmt.Errorf("no thresholds set: use --coverage, --mutation or --flake-rate")
+			}
+			if language == "" {
+				language = detectProjectLanguage(workDir)
+			}
+			if !cmd.Flags().Changed("baseline") {
+				baselinePath = filepath.Join(workDir, baseline.DefaultPath)
+			}
+			var baselines *baseline.Store
+			if ratchet || record {
+				var err error
+				if baselines, err = baseline.Load(baselinePath); err != nil {
+					return cliErrorf(exitParse, "failed to load baselines: %w", err)
+				}
+			}
+
+			ctx := context.Background()
+			progress := func(format string, args ...interface{}) {
+				if !jsonOut {
+					fmt.Printf(format, args...)
+				}
+			}
+			result := &gateResult{}
+			var quality github.QualityReport
+
+			if ratchet {
+				result.Baseline = baselines.Find(baselineBranch, baselineCommit)
+				if result.Baseline != nil {
+					gate.Baseline = result.Baseline.Report()
+				} else {
+					progress("No baseline recorded for %s, using the thresholds alone\n", baselineBranch)
+				}
+			}
+
+			if gate.MinCoverage > 0 {
+				report, err := loadOrCollectCoverage(ctx, coverageReport, workDir, language, progress)
+				if err != nil {
+					result.Failures = append(result.Failures, fmt.Sprintf("coverage not measured: %v", err))
+				} else {
+					result.Coverage = report
+					quality = coverageQualityReport(report, workDir)
+				}
+			}
+
+			if gate.MinMutationScore > 0 {
+				results, err := loadOrRunMutation(ctx, mutationReports, workDir, progress)
+				if err != nil {
+					result.Failures = append(result.Failures, fmt.Sprintf("mutation score not measured: %v", err))
+				} else {
+					result.Mutation = results
+					mergeMutationQuality(&quality, results, workDir)
+				}
+			}
+
+			if gate.CheckFlakeRate {
+				report, err := loadOrDetectFlakes(ctx, flakeReport, workDir, language, runs, progress)
+				if err != nil {
+					result.Failures = append(result.Failures, fmt.Sprintf("flake rate not measured: %v", err))
+				} else {
+					result.Flakiness = report
+					quality.FlakeRate = report.Rate
+					quality.FlakeMeasured = true
+				}
+			}
+
+			conclusion, output := gate.Evaluate(quality)
+			if conclusion == github.ConclusionFailure {
+				result.Failures = append(result.Failures, strings.TrimPrefix(output.Title, "Quality gate failed: "))
+			}
+			result.Passed = len(result.Failures) == 0
+			result.Conclusion = conclusion
+			if !result.Passed {
+				result.Conclusion = github.ConclusionFailure
+			}
+			result.Summary = output.Summary
+
+			if jsonOut {
+				data, _ := json.MarshalIndent(result, "", "  ")
+				fmt.Println(string(data))
+			} else {
+				displayGateResult(result)
+			}
+
+			if record && result.Passed {
+				if branch == "" {
+					branch = currentBranch(workDir)
+				}
+				if branch == "" {
+					return fmt.Errorf("branch required for --record. Set GITHUB_REF_NAME env var or use --branch")
+				}
+				commit := sha
+				if commit == "" {
+					commit = sourceCommit(workDir)
+				}
+				baselines.Record(branch, baseline.FromReport(commit, quality))
+				if err := baselines.Save(baselinePath); err != nil {
+					return err
+				}
+				result.Recorded = true
+				progress("Recorded baseline for %s at %s\n", branch, orDash(shortSHA(commit)))
+			}
+
+			if checkRun {
+				owner, name, ok := strings.Cut(repo, "/")
+				if !ok || owner == "" || name == "" {
+					return fmt.Errorf("invalid --repo %q: want owner/name", repo)
+				}
+				if sha == "" {
+					return fmt.Errorf("commit required for --check-run. Set GITHUB_SHA env var or use --sha")
+				}
+				token := os.Getenv("GITHUB_TOKEN")
+				if token == "" {
+					return fmt.Errorf("GitHub token required for --check-run. Set GITHUB_TOKEN env var")
+				}
+				check, err := github.NewPRService(token).PublishQualityGate(ctx, owner, name, sha, "", gate, quality)
+				if err != nil {
+					return fmt.Errorf("failed to publish check run: %w", err)
+				}
+				progress("Published %s: %s\n", github.QualityGateName, check.HTMLURL)
+			}
+
+			if !result.Passed {
+				return cliErrorf(exitThreshold, "quality gate failed: %s", strings.Join(result.Failures, "; "))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&workDir, "dir", "d", ".", "Working directory")
+	cmd.Flags().StringVarP(&language, "language", "l", "", "Language (auto-detected if not specified)")
+	cmd.Flags().Float64Var(&minCoverage, "coverage", 0, "Minimum line coverage percent (0 to skip)")
+	cmd.Flags().Float64Var(&minMutation, "mutation", 0, "Minimum mutation score percent (0 to skip)")
+	cmd.Flags().Float64Var(&maxFlakeRate, "flake-rate", 0, "Maximum percent of flaky tests (checked only when set)")
+	cmd.Flags().StringVar(&coverageReport, "coverage-report", "", "Load coverage from a JSON report (qtest coverage collect -o) instead of running tests")
+	cmd.Flags().StringArrayVar(&mutationReports, "mutation-report", nil, "Load mutation results from JSON reports (qtest mutation run -o) instead of running (repeatable)")
+	cmd.Flags().StringVar(&flakeReport, "flake-report", "", "Load flakiness from a JSON report (the flakiness of qtest gate --json) instead of running tests")
+	cmd.Flags().IntVar(&runs, "runs", 3, "Times to run the suite when measuring flakiness")
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output as JSON")
+	cmd.Flags().BoolVar(&checkRun, "check-run", false, "Publish the result as a GitHub check run")
+	cmd.Flags().StringVar(&repo, "repo", os.Getenv("GITHUB_REPOSITORY"), "Repository for the check run, as owner/name")
+	cmd.Flags().StringVar(&sha, "sha", os.Getenv("GITHUB_SHA"), "Commit for the check run and a recorded baseline")
+	cmd.Flags().BoolVar(&ratchet, "ratchet", false, "Only fail metrics that are also worse than the baseline")
+	cmd.Flags().BoolVar(&record, "record", false, "Record a passing run's metrics as the branch's baseline")
+	cmd.Flags().StringVar(&baselinePath, "baseline", baseline.DefaultPath, "Baselines file, relative to the working directory by default")
+	cmd.Flags().StringVar(&baselineBranch, "baseline-branch", "main", "Branch whose baseline --ratchet compares against")
+	cmd.Flags().StringVar(&baselineCommit, "baseline-commit", "", "Commit of the baseline, e.g. the merge base (default: the branch's latest)")
+	cmd.Flags().StringVar(&branch, "branch", os.Getenv("GITHUB_REF_NAME"), "Branch to --record the baseline for (default: the checked out branch)")
+
+	return cmd
+}
+
+// currentBranch returns the branch checked out in dir, or "" outside git or
+// on a detached HEAD
+func currentBranch(dir string) string {
+	out, err := exec.Command("git", "-C", dir, "rev-parse", "--abbrev-ref", "HEAD").Output()
+	if err != nil {
+		return ""
+	}
+	branch := strings.TrimSpace(string(out))
+	if branch == "HEAD" {
+		return ""
+	}
+	return branch
+}
+
+// loadOrCollectCoverage reads a saved coverage report, or runs the tests
+// with coverage when there is none
+func loadOrCollectCoverage(ctx context.Context, path, workDir, language string, progress func(string, ...interface{})) (*codecov.CoverageReport, error) {
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read coverage report: %w", err)
+		}
+		var report codecov.CoverageReport
+		if err := json.Unmarshal(data, &report); err != nil {
+			return nil, fmt.Errorf("failed to parse coverage report: %w", err)
+		}
+		return &report, nil
+	}
+
+	progress("Collecting coverage for %s project...\n", language)
+	report, err := codecov.NewCollector(workDir, language).Collect(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect coverage: %w", err)
+	}
+	return report, nil
+}
+
+// loadOrRunMutation reads saved mutation results, or mutates each source
+// that has generated tests when there are none
+func loadOrRunMutation(ctx context.Context, paths []string, workDir string, progress func(string, ...interface{})) ([]*mutation.Result, error) {
+	var results []*mutation.Result
+	if len(paths) > 0 {
+		for _, path := range paths {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read mutation report: %w", err)
+			}
+			var result mutation.Result
+			if err := json.Unmarshal(data, &result); err != nil {
+				return nil, fmt.Errorf("failed to parse mutation report %s: %w", path, err)
+			}
+			results = append(results, &result)
+		}
+		return results, nil
+	}
+
+	files, err := findGeneratedFiles(workDir, "")
+	if err != nil {
+		return nil, err
+	}
+	runner := mutation.NewRunner(
+		mutation.NewGoMutestingTool(),
+		mutation.NewSimpleMutationTool(),
+		mutation.NewRustMutationTool(),
+	)
+	for _, f := range files {
+		if f.Provenance.Source == "" {
+			continue
+		}
+		source := resolveSource(workDir, f.Path, f.Provenance.Source)
+		if source == "" || runner.ToolFor(ctx, source) == nil {
+			continue
+		}
+		progress("Mutation testing %s...\n", source)
+		result, err := runner.Run(ctx, source, f.Path, mutation.DefaultConfig())
+		if err != nil {
+			return nil, fmt.Errorf("mutation testing of %s failed: %w", source, err)
+		}
+		results = append(results, result)
+	}
+	if len(results) == 0 {
+		return nil, fmt.Errorf("no generated tests with a mutable source found")
+	}
+	return results, nil
+}
+
+// loadOrDetectFlakes reads a saved flakiness report, or runs the suite
+// repeatedly when there is none
+func loadOrDetectFlakes(ctx context.Context, path, workDir, language string, runs int, progress func(string, ...interface{})) (*validator.FlakeReport, error) {
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read flakiness report: %w", err)
+		}
+		var report validator.FlakeReport
+		if err := json.Unmarshal(data, &report); err != nil {
+			return nil, fmt.Errorf("failed to parse flakiness report: %w", err)
+		}
+		return &report, nil
+	}
+
+	progress("Running the %s suite %d times to find flaky tests...\n", language, runs)
+	return validator.DetectFlakes(ctx, workDir, language, runs)
+}
+
+// mergeMutationQuality totals mutation results per source file into a
+// quality report
+func mergeMutationQuality(quality *github.QualityReport, results []*mutation.Result, workDir string) {
+	root, _ := filepath.Abs(workDir)
+	index := make(map[string]int)
+	for i, f := range quality.Files {
+		index[f.Path] = i
+	}
+	totals := make(map[string][2]int) // killed, total
+	var killed, total int
+	for _, r := range results {
+		if r.Total == 0 {
+			continue
+		}
+		path := r.SourceFile
+		if filepath.IsAbs(path) {
+			if rel, err := filepath.Rel(root, path); err == nil && !strings.HasPrefix(rel, "..") {
+				path = rel
+			}
+		}
+		path = filepath.ToSlash(path)
+		i, ok := index[path]
+		if !ok {
+			i = len(quality.Files)
+			index[path] = i
+			quality.Files = append(quality.Files, github.FileQuality{Path: path})
+		}
+		t := totals[path]
+		totals[path] = [2]int{t[0] + r.Killed, t[1] + r.Total}
+		killed += r.Killed
+		total += r.Total
+		for _, m := range r.Mutants {
+			if m.Status == "survived" {
+				quality.Files[i].Survivors = append(quality.Files[i].Survivors, github.ReviewComment{
+					Path: path,
+					Line: m.Line,
+					Body: fmt.Sprintf("%s: %s", m.Type, m.Description),
+				})
+			}
+		}
+	}
+	for path, t := range totals {
+		f := &quality.Files[index[path]]
+		f.MutationMeasured = true
+		f.MutationScore = 100 * float64(t[0]) / float64(t[1])
+	}
+	if total > 0 {
+		quality.MutationMeasured = true
+		quality.MutationScore = 100 * float64(killed) / float64(total)
+	}
+}
+
+// displayGateResult prints the consolidated gate report
+func displayGateResult(result *gateResult) {
+	fmt.Printf("\n🚦 Quality Gate\n")
+	fmt.Printf("===============\n")
+	fmt.Print(result.Summary)
+	if !strings.HasSuffix(result.Summary, "\n") {
+		fmt.Println()
+	}
+
+	if result.Flakiness != nil && len(result.Flakiness.Flaky) > 0 {
+		fmt.Printf("\nFlaky tests (%d of %d, %d runs):\n", len(result.Flakiness.Flaky), result.Flakiness.Tests, result.Flakiness.Runs)
+		for _, f := range result.Flakiness.Flaky {
+			fmt.Printf("  - %s (passed %d, failed %d)\n", f.Name, f.Passed, f.Failed)
+		}
+	}
+
+	fmt.Println()
+	if result.Passed {
+		fmt.Println("✅ Quality gate passed")
+		return
+	}
+	fmt.Println("❌ Quality gate failed:")
+	for _, f := range result.Failures {
+		fmt.Printf("  - %s\n", f)
+	}
+}
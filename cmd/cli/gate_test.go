@@ -0,0 +1,149 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/QTest-hq/qtest/internal/baseline"
+	"github.com/QTest-hq/qtest/internal/codecov"
+	"github.com/QTest-hq/qtest/internal/github"
+	"github.com/QTest-hq/qtest/internal/mutation"
+	"github.com/QTest-hq/qtest/internal/validator"
+)
+
+func writeGateReport(t *testing.T, dir, name string, v interface{}) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestGateCmd_LoadedReports(t *testing.T) {
+	dir := t.TempDir()
+	coverage := writeGateReport(t, dir, "coverage.json", codecov.CoverageReport{
+		Percentage: 85,
+		Files:      []codecov.FileCoverage{{Path: "calc.go", Percentage: 85}},
+	})
+	mutants := writeGateReport(t, dir, "mutation.json", mutation.Result{
+		SourceFile: "calc.go", Total: 10, Killed: 5, Survived: 5, Score: 0.5,
+	})
+	flakes := writeGateReport(t, dir, "flakes.json", validator.FlakeReport{Runs: 3, Tests: 50, Rate: 2,
+		Flaky: []validator.FlakyTest{{Name: "TestRetry", Passed: 2, Failed: 1}}})
+
+	run := func(args ...string) error {
+		cmd := gateCmd()
+		cmd.SetArgs(append([]string{"-d", dir, "--coverage-report", coverage, "--mutation-report", mutants, "--flake-report", flakes}, args...))
+		cmd.SilenceUsage = true
+		return cmd.Execute()
+	}
+
+	if err := run("--coverage", "80", "--mutation", "40", "--flake-rate", "5"); err != nil {
+		t.Errorf("gate within thresholds failed: %v", err)
+	}
+
+	err := run("--coverage", "80", "--mutation", "60", "--flake-rate", "1")
+	if exitCode(err) != exitThreshold {
+		t.Fatalf("err = %v, want a threshold failure", err)
+	}
+	for _, want := range []string{"mutation score 50.0% is below 60.0%", "flake rate 2.0% is above 1.0%"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("err = %q, want it to mention %q", err, want)
+		}
+	}
+	if strings.Contains(err.Error(), "coverage") {
+		t.Errorf("err = %q, coverage met its threshold", err)
+	}
+
+	if err := run(); err == nil || exitCode(err) == exitThreshold {
+		t.Errorf("no thresholds = %v, want a usage error", err)
+	}
+}
+
+func TestGateCmd_UnmeasuredFails(t *testing.T) {
+	dir := t.TempDir()
+	cmd := gateCmd()
+	cmd.SetArgs([]string{"-d", dir, "--mutation", "60", "--mutation-report", filepath.Join(dir, "missing.json")})
+	cmd.SilenceUsage = true
+
+	err := cmd.Execute()
+	if exitCode(err) != exitThreshold || !strings.Contains(err.Error(), "mutation score not measured") {
+		t.Errorf("err = %v, want the gate to fail on an unmeasured metric", err)
+	}
+}
+
+func TestMergeMutationQuality(t *testing.T) {
+	quality := github.QualityReport{Files: []github.FileQuality{{Path: "calc.go", Coverage: 90, CoverageMeasured: true}}}
+	mergeMutationQuality(&quality, []*mutation.Result{
+		{SourceFile: "calc.go", Total: 4, Killed: 3, Mutants: []mutation.Mutant{{Line: 7, Type: "comparison", Description: "< to <=", Status: "survived"}}},
+		{SourceFile: "calc.go", Total: 4, Killed: 4},
+		{SourceFile: "util.go", Total: 2, Killed: 1},
+		{SourceFile: "empty.go"},
+	}, ".")
+
+	if !quality.MutationMeasured || quality.MutationScore != 80 {
+		t.Errorf("score = %v (measured %v), want 80", quality.MutationScore, quality.MutationMeasured)
+	}
+	if len(quality.Files) != 2 {
+		t.Fatalf("files = %+v, want calc.go and util.go", quality.Files)
+	}
+	calc := quality.Files[0]
+	if !calc.CoverageMeasured || calc.MutationScore != 87.5 || len(calc.Survivors) != 1 || calc.Survivors[0].Line != 7 {
+		t.Errorf("calc.go = %+v", calc)
+	}
+	if quality.Files[1].MutationScore != 50 {
+		t.Errorf("util.go = %+v", quality.Files[1])
+	}
+}
+
+func TestGateCmd_RatchetAndRecord(t *testing.T) {
+	dir := t.TempDir()
+	report := func(percent float64) string {
+		return writeGateReport(t, dir, "coverage.json", codecov.CoverageReport{Percentage: percent})
+	}
+	run := func(args ...string) error {
+		cmd := gateCmd()
+		cmd.SetArgs(append([]string{"-d", dir, "--coverage", "80", "--json"}, args...))
+		cmd.SilenceUsage = true
+		return cmd.Execute()
+	}
+
+	// Below the threshold with no baseline: fails, and isn't recorded
+	if err := run("--coverage-report", report(60), "--ratchet", "--record", "--branch", "main", "--sha", "c1"); exitCode(err) != exitThreshold {
+		t.Fatalf("err = %v, want a threshold failure without a baseline", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, baseline.DefaultPath)); !os.IsNotExist(err) {
+		t.Error("a failing run should not be recorded")
+	}
+
+	// Seed main's baseline from a run meeting a lower bar
+	cmd := gateCmd()
+	cmd.SetArgs([]string{"-d", dir, "--coverage", "50", "--json", "--coverage-report", report(60), "--record", "--branch", "main", "--sha", "c2"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("recording run: %v", err)
+	}
+
+	if err := run("--coverage-report", report(62), "--ratchet"); err != nil {
+		t.Errorf("improvement below the threshold = %v, want a pass", err)
+	}
+	err := run("--coverage-report", report(58), "--ratchet")
+	if exitCode(err) != exitThreshold || !strings.Contains(err.Error(), "baseline 60.0%") {
+		t.Errorf("regression = %v, want a failure against the baseline", err)
+	}
+
+	store, err := baseline.Load(filepath.Join(dir, baseline.DefaultPath))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := store.Find("main", ""); e == nil || e.Commit != "c2" || *e.Coverage != 60 {
+		t.Errorf("main baseline = %+v, want c2 at 60%%", e)
+	}
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/QTest-hq/qtest/internal/adapters"
+	"github.com/spf13/cobra"
+)
+
+// generatedFile is a test file with a QTest provenance header
+type generatedFile struct {
+	Path       string               `json:"path"`
+	Ownership  adapters.Ownership   `json:"ownership"`
+	Provenance *adapters.Provenance `json:"provenance"`
+	MergeFile  string               `json:"merge_file,omitempty"` // pending merge proposal
+}
+
+func generatedCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "generated",
+		Short: "List and clean up test files QTest generated",
+		Long: `QTest stamps every test file it writes with a provenance header (run ID,
+model, prompt hash and source commit). These commands find those files,
+show whether they were edited by hand since, and remove them safely.
+
+QTest never overwrites a generated file that was edited by hand. When it
+regenerates tests for one, it writes a three-way merge of the edits and the
+new tests next to it (<file>.qtest-merge) for review.`,
+	}
+
+	cmd.AddCommand(generatedListCmd())
+	cmd.AddCommand(generatedCleanCmd())
+
+	return cmd
+}
+
+func generatedListCmd() *cobra.Command {
+	var (
+		dirPath string
+		runID   string
+		jsonOut bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List generated test files and whether they were edited",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			validPath, err := validateDirPath(dirPath)
+			if err != nil {
+				return fmt.Errorf("invalid directory: %w", err)
+			}
+
+			files, err := findGeneratedFiles(validPath, runID)
+			if err != nil {
+				return err
+			}
+
+			if jsonOut {
+				data, _ := json.MarshalIndent(files, "", "  ")
+				fmt.Println(string(data))
+				return nil
+			}
+
+			if len(files) == 0 {
+				fmt.Println("No QTest-generated test files found")
+				return nil
+			}
+
+			edited := 0
+			for _, f := range files {
+				status := "✓"
+				if f.Ownership == adapters.OwnershipEdited {
+					status = "✎"
+					edited++
+				}
+				p := f.Provenance
+				fmt.Printf("%s %s\n", status, relativePath(validPath, f.Path))
+				fmt.Printf("    run %s, model %s, commit %s, %s\n",
+					orDash(p.RunID), orDash(p.Model), orDash(shortSHA(p.SourceCommit)), p.GeneratedAt.Format("2006-01-02 15:04"))
+				if f.MergeFile != "" {
+					fmt.Printf("    🔀 regenerated tests waiting to be merged: %s\n", relativePath(validPath, f.MergeFile))
+				}
+			}
+			fmt.Printf("\n%d generated files, %d edited by hand\n", len(files), edited)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&dirPath, "path", "p", ".", "Directory to search")
+	cmd.Flags().StringVar(&runID, "run", "", "Only files from this generation run")
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output as JSON")
+
+	return cmd
+}
+
+func generatedCleanCmd() *cobra.Command {
+	var (
+		dirPath       string
+		runID         string
+		includeEdited bool
+		dryRun        bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "clean",
+		Short: "Delete generated test files that weren't edited by hand",
+		Long: `Deletes test files QTest generated. Files edited by hand since they were
+generated are kept unless --include-edited is set; files without a QTest
+header are never touched.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			validPath, err := validateDirPath(dirPath)
+			if err != nil {
+				return fmt.Errorf("invalid directory: %w", err)
+			}
+
+			files, err := findGeneratedFiles(validPath, runID)
+			if err != nil {
+				return err
+			}
+
+			removed, kept := 0, 0
+			for _, f := range files {
+				rel := relativePath(validPath, f.Path)
+				if f.Ownership == adapters.OwnershipEdited && !includeEdited {
+					fmt.Printf("  ✎ keep %s (edited by hand)\n", rel)
+					kept++
+					continue
+				}
+				if dryRun {
+					fmt.Printf("  would remove %s\n", rel)
+					removed++
+					continue
+				}
+				if err := os.Remove(f.Path); err != nil {
+					return fmt.Errorf("failed to remove %s: %w", rel, err)
+				}
+				fmt.Printf("  🗑  removed %s\n", rel)
+				removed++
+			}
+
+			verb := "Removed"
+			if dryRun {
+				verb = "Would remove"
+			}
+			fmt.Printf("\n%s %d generated files, kept %d edited\n", verb, removed, kept)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&dirPath, "path", "p", ".", "Directory to clean")
+	cmd.Flags().StringVar(&runID, "run", "", "Only files from this generation run")
+	cmd.Flags().BoolVar(&includeEdited, "include-edited", false, "Also delete files edited by hand")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would be deleted")
+
+	return cmd
+}
+
+// findGeneratedFiles finds test files under dir with a QTest provenance
+// header, optionally from one run only
+func findGeneratedFiles(dir, runID string) ([]generatedFile, error) {
+	var files []generatedFile
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() {
+			name := info.Name()
+			if path != dir && (strings.HasPrefix(name, ".") || name == "node_modules" || name == "vendor" || name == "__pycache__") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !isSupportedSourceFile(strings.ToLower(filepath.Ext(path))) {
+			return nil
+		}
+
+		ownership, prov, err := adapters.FileOwnership(path)
+		if err != nil || prov == nil {
+			return nil
+		}
+		if runID != "" && prov.RunID != runID {
+			return nil
+		}
+		file := generatedFile{Path: path, Ownership: ownership, Provenance: prov}
+		if _, err := os.Stat(path + adapters.MergeSuffix); err == nil {
+			file.MergeFile = path + adapters.MergeSuffix
+		}
+		files = append(files, file)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan directory: %w", err)
+	}
+	return files, nil
+}
+
+// shortSHA abbreviates a commit SHA for display
+func shortSHA(sha string) string {
+	if len(sha) > 8 {
+		return sha[:8]
+	}
+	return sha
+}
+
+// orDash shows a dash for empty values
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/QTest-hq/qtest/internal/adapters"
+	"github.com/QTest-hq/qtest/internal/emitter"
+	"github.com/QTest-hq/qtest/internal/incident"
+	"github.com/QTest-hq/qtest/internal/parser"
+	"github.com/QTest-hq/qtest/pkg/model"
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+)
+
+func incidentCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "incident",
+		Short: "Turn production errors into regression tests",
+		Long: `Reads error events from Sentry or OpenTelemetry and generates tests that
+reproduce them, so each incident leaves a regression test behind.
+
+Example:
+  qtest incident repro -e sentry-event.json -p .
+  qtest incident repro -e trace.json -p . -o ./tests --emitter pytest`,
+	}
+
+	cmd.AddCommand(incidentReproCmd())
+
+	return cmd
+}
+
+func incidentReproCmd() *cobra.Command {
+	var (
+		eventFile   string
+		repoPath    string
+		modelFile   string
+		outputDir   string
+		emitterName string
+		specsFile   string
+		seed        int64
+	)
+
+	cmd := &cobra.Command{
+		Use:   "repro",
+		Short: "Generate a failing test that reproduces an error event",
+		Long: `Generates a reproduction test from an error event: a Sentry event (the
+JSON from the event page, or an issue alert webhook payload) or an OTLP/JSON
+trace export containing a span with an exception event.
+
+When the event records the HTTP request, the test replays it against the
+matching endpoint and asserts the response isn't a server error. Otherwise
+the test calls the function the stack trace implicates with the arguments
+captured in its stack frame (this needs local variable capture enabled in
+the SDK), and fails by raising the same error.
+
+Either way the test fails until the bug is fixed. Payloads from production
+are sanitized like captured traffic: credentials are redacted and personal
+data is replaced with fake values.
+
+API tests are written to --output; unit tests are written next to the
+implicated source file.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			data, err := os.ReadFile(eventFile)
+			if err != nil {
+				return fmt.Errorf("failed to read event: %w", err)
+			}
+			event, err := incident.Parse(data)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("🚨 %s event %s: %s\n", event.Source, event.ID, event.Title())
+
+			root, err := validateDirPath(repoPath)
+			if err != nil {
+				return fmt.Errorf("invalid path: %w", err)
+			}
+			var sysModel *model.SystemModel
+			if modelFile != "" {
+				data, err := os.ReadFile(modelFile)
+				if err != nil {
+					return fmt.Errorf("failed to read model: %w", err)
+				}
+				sysModel = &model.SystemModel{}
+				if err := json.Unmarshal(data, sysModel); err != nil {
+					return cliErrorf(exitParse, "failed to parse model: %w", err)
+				}
+			} else {
+				sysModel, _, err = buildSystemModel(context.Background(), root, false)
+				if err != nil {
+					return err
+				}
+			}
+
+			sanitizer, err := newSanitizer(seed)
+			if err != nil {
+				return err
+			}
+			repro, err := incident.Reproduce(event, sysModel, sanitizer)
+			if err != nil {
+				return err
+			}
+
+			if repro.Function != nil {
+				fmt.Printf("🎯 Implicated: %s (%s:%d)\n", adapters.TargetName(repro.Function.Class, repro.Function.Name),
+					relativePath(root, repro.Frame.File), repro.Frame.Line)
+			}
+			fmt.Printf("🔁 Reproducing via: %s\n", repro.TargetName())
+
+			if specsFile != "" {
+				specSet := &model.TestSpecSet{Repository: sysModel.Repository, Specs: []model.TestSpec{repro.Spec}}
+				data, err := json.MarshalIndent(specSet, "", "  ")
+				if err != nil {
+					return fmt.Errorf("failed to marshal specs: %w", err)
+				}
+				if err := os.WriteFile(specsFile, data, 0644); err != nil {
+					return fmt.Errorf("failed to write specs: %w", err)
+				}
+				fmt.Printf("💾 Spec saved to: %s\n", specsFile)
+			}
+
+			var testFile, code string
+			if repro.Spec.Level == model.LevelAPI {
+				testFile, code, err = emitIncidentAPITest(repro, emitterName, outputDir)
+			} else {
+				testFile, code, err = emitIncidentUnitTest(repro, root)
+			}
+			if err != nil {
+				return err
+			}
+
+			prov := adapters.Provenance{
+				RunID:        uuid.New().String(),
+				SourceCommit: sourceCommit(root),
+			}
+			// API tests have no source under test; the event file isn't one
+			if repro.Function != nil {
+				prov.Source = repoSourcePath(incidentSourceFile(root, repro.Function.File))
+				prov.Targets = []string{adapters.TargetName(repro.Function.Class, repro.Function.Name)}
+			}
+			if err := os.MkdirAll(filepath.Dir(testFile), 0755); err != nil {
+				return fmt.Errorf("failed to create output directory: %w", err)
+			}
+			written, err := adapters.WriteGeneratedFile(testFile, code, prov, adapters.WriteOptions{})
+			if err != nil {
+				return fmt.Errorf("failed to write test file: %w", err)
+			}
+			if written.Path != testFile {
+				fmt.Printf("⚠️  %s was not written by QTest, keeping it\n", testFile)
+				return nil
+			}
+
+			fmt.Printf("📝 Written: %s\n", written.Path)
+			fmt.Println("   The test fails until the bug is fixed; keep it as a regression test.")
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&eventFile, "event", "e", "", "Sentry event or OTLP/JSON trace file (required)")
+	cmd.Flags().StringVarP(&repoPath, "path", "p", ".", "Repository the error came from")
+	cmd.Flags().StringVarP(&modelFile, "model", "m", "", "System model JSON file (default: analyze --path)")
+	cmd.Flags().StringVarP(&outputDir, "output", "o", "./tests", "Output directory for API reproduction tests")
+	cmd.Flags().StringVar(&emitterName, "emitter", "", "Emitter for API tests (default: from the endpoint's language, else supertest)")
+	cmd.Flags().StringVarP(&specsFile, "specs", "s", "", "Also save the reproduction spec to this JSON file")
+	cmd.Flags().Int64Var(&seed, "seed", 0, "Seed for reproducible fake values (default: datagen.seed from .qtest.yaml)")
+	cmd.MarkFlagRequired("event")
+
+	return cmd
+}
+
+// apiEmitters are the API test emitters for each service language
+var apiEmitters = map[parser.Language]string{
+	parser.LanguageJavaScript: "supertest",
+	parser.LanguageTypeScript: "supertest",
+	parser.LanguagePython:     "pytest",
+	parser.LanguageGo:         "go-http",
+}
+
+// emitIncidentAPITest renders an API reproduction with the named emitter,
+// or the one for the endpoint's language
+func emitIncidentAPITest(repro *incident.Reproduction, emitterName, outputDir string) (string, string, error) {
+	registry := emitter.NewRegistry()
+
+	var em emitter.Emitter
+	var err error
+	if emitterName != "" {
+		em, err = registry.Get(emitterName)
+		if err != nil {
+			return "", "", fmt.Errorf("emitter not found: %s\nAvailable: %v", emitterName, registry.List())
+		}
+	} else {
+		name := "supertest"
+		if repro.Endpoint != nil {
+			if n, ok := apiEmitters[parser.DetectLanguage(repro.Endpoint.File)]; ok {
+				name = n
+			}
+		}
+		em, _ = registry.Get(name)
+	}
+
+	code, err := em.Emit([]model.TestSpec{repro.Spec})
+	if err != nil {
+		return "", "", fmt.Errorf("failed to emit reproduction test: %w", err)
+	}
+	return filepath.Join(outputDir, incidentFileName(repro)+em.FileExtension()), code, nil
+}
+
+// emitIncidentUnitTest renders a unit reproduction with the spec adapter
+// for the implicated function's language, next to its source file
+func emitIncidentUnitTest(repro *incident.Reproduction, root string) (string, string, error) {
+	sourceFile := incidentSourceFile(root, repro.Function.File)
+	lang := parser.DetectLanguage(sourceFile)
+	specAdapter, err := adapters.NewRegistry().GetSpecForLanguage(lang)
+	if err != nil {
+		return "", "", fmt.Errorf("no test adapter for %s: %w", lang, err)
+	}
+
+	code, err := specAdapter.GenerateFromSpecs([]model.TestSpec{repro.Spec}, sourceFile)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate reproduction test: %w", err)
+	}
+
+	name := strings.TrimSuffix(filepath.Base(sourceFile), filepath.Ext(sourceFile))
+	testFile := filepath.Join(filepath.Dir(sourceFile),
+		name+"_"+incidentFileName(repro)+specAdapter.TestFileSuffix()+specAdapter.FileExtension())
+	return testFile, code, nil
+}
+
+// incidentFileName names a reproduction test after its event, e.g.
+// incident_9a1b2c3d4e5f
+func incidentFileName(repro *incident.Reproduction) string {
+	return strings.ReplaceAll(strings.TrimPrefix(repro.Spec.ID, "spec:"), ":", "_")
+}
+
+func incidentSourceFile(root, file string) string {
+	if filepath.IsAbs(file) {
+		return file
+	}
+	return filepath.Join(root, file)
+}
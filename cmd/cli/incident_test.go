@@ -0,0 +1,49 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/QTest-hq/qtest/internal/incident"
+	"github.com/QTest-hq/qtest/pkg/model"
+)
+
+func TestEmitIncidentAPITest(t *testing.T) {
+	repro := &incident.Reproduction{
+		Spec: model.TestSpec{
+			ID:          "spec:incident:9a1b2c3d4e5f",
+			Level:       model.LevelAPI,
+			Description: "reproduces KeyError: email",
+			Method:      "POST",
+			Path:        "/users",
+			Assertions:  []model.Assertion{{Kind: "less_than", Actual: "status", Expected: 500}},
+		},
+		Endpoint: &model.Endpoint{File: "app/routes.py"},
+	}
+
+	tests := []struct {
+		emitter  string
+		wantFile string
+		wantCode string
+	}{
+		{"", "incident_9a1b2c3d4e5f_test.py", "assert response.status_code < 500"},
+		{"supertest", "incident_9a1b2c3d4e5f.test.js", "toBeLessThan(500)"},
+	}
+	for _, tt := range tests {
+		file, code, err := emitIncidentAPITest(repro, tt.emitter, "tests")
+		if err != nil {
+			t.Fatalf("emitIncidentAPITest(%q) error: %v", tt.emitter, err)
+		}
+		if file != filepath.Join("tests", tt.wantFile) {
+			t.Errorf("emitIncidentAPITest(%q) file = %q, want %q", tt.emitter, file, tt.wantFile)
+		}
+		if !strings.Contains(code, tt.wantCode) {
+			t.Errorf("emitIncidentAPITest(%q) code missing %q:\n%s", tt.emitter, tt.wantCode, code)
+		}
+	}
+
+	if _, _, err := emitIncidentAPITest(repro, "nope", "tests"); err == nil {
+		t.Error("expected error for an unknown emitter")
+	}
+}
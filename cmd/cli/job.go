@@ -1,7 +1,6 @@
 package main
 
 import (
-	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -11,6 +10,8 @@ import (
 	"text/tabwriter"
 	"time"
 
+	"github.com/QTest-hq/qtest/internal/jobs"
+	"github.com/QTest-hq/qtest/internal/vcs"
 	"github.com/spf13/cobra"
 )
 
@@ -29,6 +30,7 @@ func jobCmd() *cobra.Command {
 	}
 
 	cmd.PersistentFlags().StringVar(&apiURL, "api-url", "http://localhost:8080", "API server URL")
+	cmd.PersistentFlags().StringVar(&apiToken, "token", os.Getenv("QTEST_API_TOKEN"), "API key or session token")
 	cmd.PersistentFlags().BoolVar(&jsonOutput, "json", false, "Output as JSON")
 
 	cmd.AddCommand(jobSubmitCmd())
@@ -36,6 +38,7 @@ func jobCmd() *cobra.Command {
 	cmd.AddCommand(jobStatusCmd())
 	cmd.AddCommand(jobCancelCmd())
 	cmd.AddCommand(jobRetryCmd())
+	cmd.AddCommand(jobCostCmd())
 
 	return cmd
 }
@@ -45,10 +48,13 @@ func jobSubmitCmd() *cobra.Command {
 	var (
 		repoURL  string
 		branch   string
+		source   string
 		maxTests int
 		llmTier  int
 		createPR bool
 		jobType  string
+		lane     string
+		prOpts   jobs.PROptions
 	)
 
 	cmd := &cobra.Command{
@@ -63,12 +69,47 @@ Examples:
   # With options
   qtest job submit --repo https://github.com/user/repo --max-tests 50 --tier 2
 
+  # Open a draft PR with labels and reviewers
+  qtest job submit --repo https://github.com/user/repo --create-pr --draft \
+    --label tests --reviewer alice --reviewer my-org/qa
+
+  # Merge the PR automatically once required checks pass
+  qtest job submit --repo https://github.com/user/repo --create-pr --auto-merge --merge-method squash
+
+  # A commit per package, for easier review and bisecting
+  qtest job submit --repo https://github.com/user/repo --create-pr --commit-strategy per-package
+
+  # A Mercurial repository or a release tarball
+  qtest job submit --repo https://hg.example.com/project --vcs hg
+  qtest job submit --repo https://example.com/releases/project-1.2.tar.gz
+
   # Submit specific job type
-  qtest job submit --type generation --repo https://github.com/user/repo`,
+  qtest job submit --type generation --repo https://github.com/user/repo
+
+  # Ahead of batch pipelines, for a developer waiting on the result
+  qtest job submit --type generation --repo https://github.com/user/repo --lane interactive`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if repoURL == "" {
 				return fmt.Errorf("--repo is required")
 			}
+			if _, err := jobs.ParseLane(lane); err != nil {
+				return err
+			}
+			if source != "" && !vcs.Valid(source) {
+				return fmt.Errorf("--vcs must be git, hg or archive")
+			}
+
+			var pr *jobs.PROptions
+			if cmd.Flags().Changed("draft") || len(prOpts.Labels) > 0 || len(prOpts.Assignees) > 0 ||
+				len(prOpts.Reviewers) > 0 || prOpts.AutoMerge || prOpts.MergeMethod != "" || prOpts.CommitStrategy != "" || prOpts.TestDependencies {
+				if !createPR {
+					return fmt.Errorf("PR options require --create-pr")
+				}
+				if err := prOpts.Validate(); err != nil {
+					return err
+				}
+				pr = &prOpts
+			}
 
 			var endpoint string
 			var payload interface{}
@@ -78,21 +119,28 @@ Examples:
 				endpoint = "/api/v1/jobs"
 				payload = map[string]interface{}{
 					"type": jobType,
+					"lane": lane,
 					"payload": map[string]interface{}{
-						"repository_url": repoURL,
+						"repository_url": qualifyURL(source, repoURL),
 						"branch":         branch,
 					},
 				}
 			} else {
 				// Start full pipeline
 				endpoint = "/api/v1/jobs/pipeline"
-				payload = map[string]interface{}{
+				req := map[string]interface{}{
 					"repository_url": repoURL,
 					"branch":         branch,
 					"max_tests":      maxTests,
 					"llm_tier":       llmTier,
 					"create_pr":      createPR,
+					"lane":           lane,
+					"vcs":            source,
+				}
+				if pr != nil {
+					req["pr"] = pr
 				}
+				payload = req
 			}
 
 			resp, err := postJSON(apiURL+endpoint, payload)
@@ -121,15 +169,34 @@ Examples:
 	}
 
 	cmd.Flags().StringVar(&repoURL, "repo", "", "Repository URL (required)")
-	cmd.Flags().StringVar(&branch, "branch", "", "Git branch")
+	cmd.Flags().StringVar(&branch, "branch", "", "Branch, tag or revision")
+	cmd.Flags().StringVar(&source, "vcs", "", "Kind of source: git, hg or archive (default: detected from the URL)")
 	cmd.Flags().IntVar(&maxTests, "max-tests", 0, "Maximum tests to generate")
-	cmd.Flags().IntVar(&llmTier, "tier", 1, "LLM tier (1=fast, 2=balanced, 3=thorough)")
+	cmd.Flags().IntVar(&llmTier, "tier", 0, "LLM tier (1=fast, 2=balanced, 3=thorough; default: repository policy, else 1)")
 	cmd.Flags().BoolVar(&createPR, "create-pr", false, "Create PR when done")
+	cmd.Flags().StringVar(&lane, "lane", "", "Scheduling lane: interactive, default or batch")
+	cmd.Flags().BoolVar(&prOpts.Draft, "draft", false, "Open the PR as a draft")
+	cmd.Flags().StringSliceVar(&prOpts.Labels, "label", nil, "Label to add to the PR (repeatable)")
+	cmd.Flags().StringSliceVar(&prOpts.Assignees, "assignee", nil, "User to assign to the PR (repeatable)")
+	cmd.Flags().StringSliceVar(&prOpts.Reviewers, "reviewer", nil, "Reviewer login or org/team (repeatable)")
+	cmd.Flags().BoolVar(&prOpts.AutoMerge, "auto-merge", false, "Merge the PR once checks pass (only if tests passed)")
+	cmd.Flags().StringVar(&prOpts.MergeMethod, "merge-method", "", "Auto-merge method: merge, squash or rebase")
+	cmd.Flags().StringVar(&prOpts.CommitStrategy, "commit-strategy", "", "How to commit the tests: single or per-package")
+	cmd.Flags().BoolVar(&prOpts.TestDependencies, "add-dev-deps", false, "Declare the dependencies the tests need in package.json, pyproject.toml or requirements-dev.txt on the PR's branch")
 	cmd.Flags().StringVar(&jobType, "type", "", "Specific job type (ingestion, modeling, etc.)")
 
 	return cmd
 }
 
+// qualifyURL prefixes a repository URL with the kind of source, if one was
+// given, as the pipeline endpoint does for its vcs field
+func qualifyURL(kind, url string) string {
+	if kind == "" {
+		return url
+	}
+	return vcs.Qualify(kind, url)
+}
+
 // jobListCmd lists jobs
 func jobListCmd() *cobra.Command {
 	var (
@@ -244,8 +311,10 @@ Examples:
 func jobCancelCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "cancel <job-id>",
-		Short: "Cancel a pending job",
-		Args:  cobra.ExactArgs(1),
+		Short: "Cancel a pending or running job",
+		Long: `Cancel a job. A running job's worker is told to stop at once, which
+aborts its git clone, LLM calls or test runs, and the job's lock is released.`,
+		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			jobID := args[0]
 			endpoint := fmt.Sprintf("/api/v1/jobs/%s/cancel", jobID)
@@ -303,7 +372,55 @@ func jobRetryCmd() *cobra.Command {
 	return cmd
 }
 
+// jobCostCmd shows the LLM spend of a generation run
+func jobCostCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cost <run-id>",
+		Short: "Show the LLM tokens and estimated cost of a generation run",
+		Long: `Show the tokens the LLM calls of a generation run used, by provider,
+model and tier, with their estimated cost. Generation and auto-fix calls are
+counted. Only Anthropic models are priced; local and OpenAI-compatible models
+show tokens only.
+
+Examples:
+  qtest job cost 550e8400-e29b-41d4-a716-446655440000`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			runID := args[0]
+			resp, err := getJSON(fmt.Sprintf("%s/api/v1/runs/%s", apiURL, runID))
+			if err != nil {
+				return err
+			}
+
+			var run runResponse
+			if err := json.Unmarshal(resp, &run); err != nil {
+				return fmt.Errorf("failed to parse response: %w", err)
+			}
+
+			if jsonOutput {
+				data, err := json.MarshalIndent(run.Summary.LLMUsage, "", "  ")
+				if err != nil {
+					return fmt.Errorf("failed to marshal usage: %w", err)
+				}
+				fmt.Println(string(data))
+				return nil
+			}
+
+			printRunCost(os.Stdout, &run)
+			return nil
+		},
+	}
+
+	return cmd
+}
+
 // Response types
+type runResponse struct {
+	ID      string          `json:"id"`
+	Status  string          `json:"status"`
+	Summary jobs.RunSummary `json:"summary"`
+}
+
 type jobResponse struct {
 	ID           string  `json:"id"`
 	Type         string  `json:"type"`
@@ -327,71 +444,11 @@ type jobStatusResponse struct {
 
 // HTTP helpers
 func getJSON(url string) ([]byte, error) {
-	client := &http.Client{Timeout: 30 * time.Second}
-	resp, err := client.Get(url)
-	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
-	}
-
-	if resp.StatusCode >= 400 {
-		var errResp map[string]string
-		if json.Unmarshal(body, &errResp) == nil {
-			if msg, ok := errResp["error"]; ok {
-				return nil, fmt.Errorf("API error: %s", msg)
-			}
-		}
-		return nil, fmt.Errorf("API error: %s", resp.Status)
-	}
-
-	return body, nil
+	return apiRequest(http.MethodGet, url, nil)
 }
 
 func postJSON(url string, data interface{}) ([]byte, error) {
-	client := &http.Client{Timeout: 30 * time.Second}
-
-	var body io.Reader
-	if data != nil {
-		jsonData, err := json.Marshal(data)
-		if err != nil {
-			return nil, fmt.Errorf("failed to encode request: %w", err)
-		}
-		body = bytes.NewReader(jsonData)
-	}
-
-	req, err := http.NewRequest("POST", url, body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
-	}
-
-	if resp.StatusCode >= 400 {
-		var errResp map[string]string
-		if json.Unmarshal(respBody, &errResp) == nil {
-			if msg, ok := errResp["error"]; ok {
-				return nil, fmt.Errorf("API error: %s", msg)
-			}
-		}
-		return nil, fmt.Errorf("API error: %s", resp.Status)
-	}
-
-	return respBody, nil
+	return apiRequest(http.MethodPost, url, data)
 }
 
 // Output helpers
@@ -433,6 +490,37 @@ func printJobDetail(j *jobResponse) {
 	}
 }
 
+func printRunCost(out io.Writer, run *runResponse) {
+	fmt.Fprintf(out, "Run: %s (%s)\n", run.ID, run.Status)
+
+	usage := run.Summary.LLMUsage
+	if usage == nil || usage.Calls == 0 {
+		fmt.Fprintln(out, "  No LLM calls recorded.")
+		return
+	}
+
+	fmt.Fprintf(out, "  LLM calls:      %d", usage.Calls)
+	if usage.CachedCalls > 0 {
+		fmt.Fprintf(out, " (%d from cache)", usage.CachedCalls)
+	}
+	fmt.Fprintln(out)
+	fmt.Fprintf(out, "  Input tokens:   %d\n", usage.InputTokens)
+	fmt.Fprintf(out, "  Output tokens:  %d\n", usage.OutputTokens)
+	fmt.Fprintf(out, "  Estimated cost: $%.4f\n", usage.CostUSD)
+
+	if len(usage.Models) == 0 {
+		return
+	}
+	fmt.Fprintln(out)
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PROVIDER\tMODEL\tTIER\tCALLS\tINPUT\tOUTPUT\tCOST")
+	for _, m := range usage.Models {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%d\t%d\t$%.4f\n",
+			m.Provider, m.Model, m.Tier, m.Calls, m.InputTokens, m.OutputTokens, m.CostUSD)
+	}
+	w.Flush()
+}
+
 func formatTime(t string) string {
 	parsed, err := time.Parse("2006-01-02T15:04:05Z", t)
 	if err != nil {
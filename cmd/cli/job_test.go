@@ -0,0 +1,43 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestPrintRunCost(t *testing.T) {
+	var run runResponse
+	err := json.Unmarshal([]byte(`{
+		"id": "run-1",
+		"status": "completed",
+		"summary": {"llm_usage": {
+			"calls": 3, "cached_calls": 1, "input_tokens": 15000, "output_tokens": 3000, "cost_usd": 0.09,
+			"models": [{"provider": "anthropic", "model": "claude-3-5-sonnet-20241022", "tier": 3,
+				"calls": 2, "input_tokens": 15000, "output_tokens": 3000, "cost_usd": 0.09}]
+		}}
+	}`), &run)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	printRunCost(&out, &run)
+	for _, want := range []string{
+		"Run: run-1 (completed)",
+		"LLM calls:      3 (1 from cache)",
+		"Estimated cost: $0.0900",
+		"anthropic  claude-3-5-sonnet-20241022  3     2      15000  3000    $0.0900",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("printRunCost() missing %q:\n%s", want, out.String())
+		}
+	}
+
+	out.Reset()
+	printRunCost(&out, &runResponse{ID: "run-2", Status: "running"})
+	if !strings.Contains(out.String(), "No LLM calls recorded") {
+		t.Errorf("printRunCost() without usage = %q", out.String())
+	}
+}
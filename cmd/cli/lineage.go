@@ -0,0 +1,176 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/QTest-hq/qtest/internal/lineage"
+	"github.com/QTest-hq/qtest/internal/workspace"
+	"github.com/QTest-hq/qtest/pkg/model"
+	"github.com/spf13/cobra"
+)
+
+func lineageCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "lineage",
+		Short: "Trace generated tests back to the plan that produced them",
+		Long: `The lineage graph links each plan intent to its spec, the test file it
+was emitted to, and execution/mutation results. Use it to see why a test
+exists and which gap it was meant to close.
+
+The graph is updated by 'plan generate', 'generate-specs' and 'emit-tests'
+when --lineage is passed, and by 'lineage record' for results.`,
+	}
+
+	cmd.AddCommand(lineageRecordCmd())
+	cmd.AddCommand(lineageTraceCmd())
+
+	return cmd
+}
+
+func lineageRecordCmd() *cobra.Command {
+	var (
+		graphPath     string
+		planFile      string
+		specsFile     string
+		executionFile string
+		mutationFile  string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "record",
+		Short: "Add plan, spec, execution or mutation artifacts to the lineage graph",
+		Long: `Example:
+  qtest lineage record --plan plan.json --specs specs.json
+  qtest lineage record -e artifacts/execution.json -m artifacts/mutation.json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var (
+				plan      model.TestPlan
+				specSet   model.TestSpecSet
+				execution workspace.ExecutionReport
+				mutation  workspace.MutationReport
+			)
+
+			inputs := []struct {
+				path string
+				v    interface{}
+			}{
+				{planFile, &plan},
+				{specsFile, &specSet},
+				{executionFile, &execution},
+				{mutationFile, &mutation},
+			}
+			for _, in := range inputs {
+				if in.path == "" {
+					continue
+				}
+				data, err := os.ReadFile(in.path)
+				if err != nil {
+					return fmt.Errorf("failed to read %s: %w", in.path, err)
+				}
+				if err := json.Unmarshal(data, in.v); err != nil {
+					return cliErrorf(exitParse, "failed to parse %s: %w", in.path, err)
+				}
+			}
+
+			err := updateLineage(graphPath, func(g *lineage.Graph) {
+				if planFile != "" {
+					g.AddPlan(&plan)
+				}
+				if specsFile != "" {
+					g.AddSpecs(&specSet)
+				}
+				if executionFile != "" {
+					g.AddExecution(&execution)
+				}
+				if mutationFile != "" {
+					g.AddMutation(&mutation)
+				}
+			})
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("✅ Lineage updated: %s\n", graphPath)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&graphPath, "graph", "g", lineage.DefaultPath, "Lineage graph file")
+	cmd.Flags().StringVarP(&planFile, "plan", "p", "", "Test plan JSON file")
+	cmd.Flags().StringVarP(&specsFile, "specs", "s", "", "Test specifications JSON file")
+	cmd.Flags().StringVarP(&executionFile, "execution", "e", "", "Execution report (artifacts/execution.json)")
+	cmd.Flags().StringVarP(&mutationFile, "mutation", "m", "", "Mutation report (artifacts/mutation.json)")
+
+	return cmd
+}
+
+func lineageTraceCmd() *cobra.Command {
+	var (
+		graphPath  string
+		jsonOutput bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "trace <lineage-id|intent-id|spec-id|file|test-name>",
+		Short: "Show the plan -> spec -> code -> results chain for a test",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			g, err := lineage.Load(graphPath)
+			if err != nil {
+				return err
+			}
+
+			nodes, err := g.Trace(args[0])
+			if err != nil {
+				return err
+			}
+
+			if jsonOutput {
+				data, _ := json.MarshalIndent(nodes, "", "  ")
+				fmt.Println(string(data))
+				return nil
+			}
+
+			fmt.Printf("🔗 Lineage for %s\n", args[0])
+			fmt.Println(strings.Repeat("─", 40))
+			for _, n := range nodes {
+				fmt.Printf("[%s] %s\n", n.Kind, n.Ref)
+				if n.Label != "" && n.Label != n.Ref {
+					fmt.Printf("    %s\n", n.Label)
+				}
+				if n.LineageID != "" {
+					fmt.Printf("    lineage: %s\n", n.LineageID)
+				}
+				keys := make([]string, 0, len(n.Attrs))
+				for k := range n.Attrs {
+					keys = append(keys, k)
+				}
+				sort.Strings(keys)
+				for _, k := range keys {
+					fmt.Printf("    %s: %s\n", k, n.Attrs[k])
+				}
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&graphPath, "graph", "g", lineage.DefaultPath, "Lineage graph file")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output nodes as JSON")
+
+	return cmd
+}
+
+// updateLineage loads the graph at path, applies update and saves it
+func updateLineage(path string, update func(g *lineage.Graph)) error {
+	g, err := lineage.Load(path)
+	if err != nil {
+		return err
+	}
+	update(g)
+	return g.Save(path)
+}
@@ -5,7 +5,9 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strconv"
 	"strings"
@@ -15,13 +17,14 @@ import (
 	"github.com/QTest-hq/qtest/internal/codecov"
 	"github.com/QTest-hq/qtest/internal/config"
 	"github.com/QTest-hq/qtest/internal/generator"
+	"github.com/QTest-hq/qtest/internal/github"
 	"github.com/QTest-hq/qtest/internal/llm"
 	"github.com/QTest-hq/qtest/internal/mutation"
 	"github.com/QTest-hq/qtest/internal/parser"
-	"github.com/QTest-hq/qtest/internal/supplements"
 	"github.com/QTest-hq/qtest/internal/workspace"
 	"github.com/QTest-hq/qtest/pkg/dsl"
 	"github.com/QTest-hq/qtest/pkg/model"
+	"github.com/google/uuid"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
@@ -106,11 +109,37 @@ func main() {
 	log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
 
 	rootCmd := &cobra.Command{
-		Use:     "qtest",
-		Short:   "QTest - AI-powered test generation",
-		Long:    `QTest generates comprehensive test suites for your codebase using AI.`,
-		Version: version,
+		Use:   "qtest",
+		Short: "QTest - AI-powered test generation",
+		Long: `QTest generates comprehensive test suites for your codebase using AI.
+
+Failures exit with a code for their kind, so scripts can branch on them:
+  1  error               any other failure
+  2  config_error        configuration missing or invalid
+  3  llm_unavailable     no LLM provider reachable
+  4  parse_failure       a source file, model, plan or report couldn't be parsed
+  5  validation_failure  generated or existing tests failed
+  6  threshold_not_met   coverage below the required threshold
+
+With --error-format json (or QTEST_ERROR_FORMAT=json) the error is written
+to stderr as {"error": {"kind": ..., "exit_code": ..., "message": ...}}.
+
+Output uses emoji and Unicode symbols. --plain (or QTEST_PLAIN=1) prints
+ASCII tags such as [ok], [fail] and [warn] instead, for CI log viewers and
+terminals that can't show them; it's the default for TERM=dumb and non-UTF-8
+locales.`,
+		Version:       version,
+		SilenceErrors: true,
+		// Usage is for flag and argument mistakes, not failures while running
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			cmd.SilenceUsage = true
+			if plainOutput {
+				startPlainOutput()
+			}
+		},
 	}
+	rootCmd.PersistentFlags().StringVar(&errorFormat, "error-format", os.Getenv("QTEST_ERROR_FORMAT"), "Error output format: text or json (default text)")
+	rootCmd.PersistentFlags().BoolVar(&plainOutput, "plain", plainDefault(), "ASCII output without emoji or box drawing (default from QTEST_PLAIN, TERM=dumb or a non-UTF-8 locale)")
 
 	// Add subcommands
 	rootCmd.AddCommand(generateCmd())
@@ -122,19 +151,35 @@ func main() {
 	rootCmd.AddCommand(planCmd())
 	rootCmd.AddCommand(generateSpecsCmd())
 	rootCmd.AddCommand(emitTestsCmd())
+	rootCmd.AddCommand(reportCmd())
+	rootCmd.AddCommand(lineageCmd())
 	rootCmd.AddCommand(validateCmd())
+	rootCmd.AddCommand(revalidateCmd())
 	rootCmd.AddCommand(contractCmd())
 	rootCmd.AddCommand(datagenCmd())
+	rootCmd.AddCommand(captureCmd())
+	rootCmd.AddCommand(incidentCmd())
 	rootCmd.AddCommand(coverageCmd())
 	rootCmd.AddCommand(mutationCmd())
+	rootCmd.AddCommand(gateCmd())
+	rootCmd.AddCommand(optimizeCmd())
+	rootCmd.AddCommand(testabilityCmd())
+	rootCmd.AddCommand(generatedCmd())
+	rootCmd.AddCommand(cleanCmd())
 	rootCmd.AddCommand(prCmd())
 	rootCmd.AddCommand(jobCmd())
+	rootCmd.AddCommand(policyCmd())
+	rootCmd.AddCommand(scheduleCmd())
+	rootCmd.AddCommand(watchCmd())
 	rootCmd.AddCommand(configCmd())
 
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+	err := rootCmd.Execute()
+	code := 0
+	if err != nil {
+		code = reportError(os.Stderr, err, errorFormat)
 	}
+	restoreOutput()
+	os.Exit(code)
 }
 
 func generateCmd() *cobra.Command {
@@ -145,6 +190,13 @@ func generateCmd() *cobra.Command {
 		dryRun      bool
 		validate    bool
 		runMutation bool
+		debug       bool
+		reposFile   string
+		parallel    int
+		llmLimit    int
+		serial      bool
+		testTimeout time.Duration
+		chaos       bool
 	)
 
 	cmd := &cobra.Command{
@@ -159,13 +211,96 @@ This command will:
 4. Optionally validate generated tests
 5. Optionally run mutation testing to evaluate test quality
 
+With --repos, every local repo listed in the file (one path per line,
+# comments allowed) is generated concurrently in one run. The repos share
+one LLM router, so --llm-concurrency caps their requests together, and a
+summary table of all repos is printed at the end.
+
 Examples:
   qtest generate -r https://github.com/user/repo
   qtest generate -r ./local/path -t 1 --dry-run
-  qtest generate -r ./local/path --mutation`,
+  qtest generate -r ./local/path --mutation
+  qtest generate --repos repos.txt --parallel 3 --llm-concurrency 4`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := cmd.Context()
 
+			if (repoURL == "") == (reposFile == "") {
+				return errors.New("exactly one of --repo and --repos is required")
+			}
+
+			if reposFile != "" {
+				repos, err := readRepoList(reposFile)
+				if err != nil {
+					return err
+				}
+
+				cfg, err := config.Load()
+				if err != nil {
+					return cliErrorf(exitConfig, "failed to load config: %w", err)
+				}
+				if llmLimit > 0 {
+					cfg.Lanes.LLMConcurrency = llmLimit
+				}
+
+				// One router for every repo, so they share its request
+				// limit and circuit breakers
+				router, err := llm.NewRouter(cfg)
+				if err != nil {
+					return cliErrorf(exitConfig, "failed to create LLM router: %w", err)
+				}
+				if err := router.HealthCheck(); err != nil {
+					return cliErrorf(exitLLM, "LLM not available: %w\nMake sure Ollama is running: ollama serve", err)
+				}
+
+				tierNum, _ := strconv.Atoi(tier)
+				runCfg := workspace.DefaultRunConfig()
+				runCfg.Tier = llm.Tier(tierNum)
+				runCfg.DryRun = dryRun
+				runCfg.ValidateTests = validate
+				runCfg.MaxTests = maxTests
+				runCfg.DebugPrompts = debug
+				runCfg.ParallelTests = !serial
+				runCfg.TestTimeout = testTimeout
+				runCfg.Chaos = chaos
+
+				if parallel <= 0 || parallel > len(repos) {
+					parallel = len(repos)
+				}
+				fmt.Printf("🚀 Generating tests for %d repos (%d at a time)\n\n", len(repos), parallel)
+
+				labels := repoLabels(repos)
+				out := &linePrinter{out: os.Stdout}
+				results := runRepos(ctx, repos, parallel, func(ctx context.Context, i int, repo string) repoResult {
+					return generateRepo(ctx, repo, labels[i], router, cfg.GitHubToken, *runCfg, out)
+				})
+
+				fmt.Println("\n" + strings.Repeat("=", 50))
+				printMultiRepoSummary(os.Stdout, results)
+
+				if runMutation && !dryRun {
+					for i, r := range results {
+						if r.Err != nil || r.Workspace == "" {
+							continue
+						}
+						fmt.Printf("\n🧬 Running mutation testing for %s...\n", labels[i])
+						if err := runRepoMutationTesting(ctx, r.Workspace); err != nil {
+							fmt.Printf("⚠️  Mutation testing warning: %v\n", err)
+						}
+					}
+				}
+
+				failed := 0
+				for _, r := range results {
+					if r.Err != nil {
+						failed++
+					}
+				}
+				if failed > 0 {
+					return fmt.Errorf("%d of %d repos failed", failed, len(results))
+				}
+				return nil
+			}
+
 			// Determine if local path or remote URL
 			isLocal := !strings.HasPrefix(repoURL, "http")
 
@@ -181,17 +316,17 @@ Examples:
 			// Load config
 			cfg, err := config.Load()
 			if err != nil {
-				return fmt.Errorf("failed to load config: %w", err)
+				return cliErrorf(exitConfig, "failed to load config: %w", err)
 			}
 
 			// Create LLM router
 			router, err := llm.NewRouter(cfg)
 			if err != nil {
-				return fmt.Errorf("failed to create LLM router: %w", err)
+				return cliErrorf(exitConfig, "failed to create LLM router: %w", err)
 			}
 
 			if err := router.HealthCheck(); err != nil {
-				return fmt.Errorf("LLM not available: %w\nMake sure Ollama is running: ollama serve", err)
+				return cliErrorf(exitLLM, "LLM not available: %w\nMake sure Ollama is running: ollama serve", err)
 			}
 
 			// Create temporary workspace
@@ -211,6 +346,10 @@ Examples:
 			runCfg.DryRun = dryRun
 			runCfg.ValidateTests = validate
 			runCfg.MaxTests = maxTests
+			runCfg.DebugPrompts = debug
+			runCfg.ParallelTests = !serial
+			runCfg.TestTimeout = testTimeout
+			runCfg.Chaos = chaos
 
 			// Create v2 runner (uses SystemModel pipeline)
 			runner := workspace.NewRunnerV2(ws, router, cfg.GitHubToken, runCfg)
@@ -248,6 +387,9 @@ Examples:
 			fmt.Printf("   Completed: %d\n", summary["completed"])
 			fmt.Printf("   Failed:    %d\n", summary["failed"])
 			fmt.Printf("   Output:    %s\n", ws.Path())
+			if debug {
+				fmt.Printf("   Prompts:   %s\n", filepath.Join(ws.Path(), "artifacts", workspace.PromptDebugDir))
+			}
 
 			// Run mutation testing if requested
 			if runMutation && !dryRun {
@@ -267,7 +409,13 @@ Examples:
 	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Don't write test files")
 	cmd.Flags().BoolVar(&validate, "validate", false, "Run tests after generation")
 	cmd.Flags().BoolVar(&runMutation, "mutation", false, "Run mutation testing after generation")
-	cmd.MarkFlagRequired("repo")
+	cmd.Flags().BoolVar(&debug, "debug-prompts", false, "Save each target's prompt, raw response, parsed specs and errors to the workspace artifacts")
+	cmd.Flags().StringVar(&reposFile, "repos", "", "File listing local repo paths to generate for concurrently, one per line")
+	cmd.Flags().IntVar(&parallel, "parallel", 4, "Repos to generate for at once with --repos")
+	cmd.Flags().IntVar(&llmLimit, "llm-concurrency", 0, "LLM requests in flight at once across all repos (0 = LLM_MAX_CONCURRENCY)")
+	cmd.Flags().BoolVar(&serial, "serial-tests", false, "Don't mark generated Go tests of pure functions t.Parallel()")
+	cmd.Flags().DurationVar(&testTimeout, "test-timeout", workspace.DefaultRunConfig().TestTimeout, "Deadline for each generated Go test's API calls (0 = none)")
+	cmd.Flags().BoolVar(&chaos, "chaos", false, "Also test code calling HTTP APIs against downstream timeouts, 503s and malformed responses")
 
 	return cmd
 }
@@ -281,71 +429,184 @@ func generateFileCmd() *cobra.Command {
 		write       bool
 		runMutation bool
 		useIRSpec   bool // Use new IRSpec JSON mode
+		fromStdin   bool
+		langName    string
+		emit        string
+		addDevDeps  bool
+		chaos       bool
 	)
 
 	cmd := &cobra.Command{
 		Use:   "generate-file",
 		Short: "Generate tests for a single source file",
+		Long: `Generate tests for a single source file.
+
+The file can be a local path, a file in a GitHub repository as
+owner/repo:path@ref (the ref defaults to the default branch), a github.com
+file link or any other URL. Only that file is downloaded, not the whole
+repository. Files from GitHub come with the repository's root manifests
+(go.mod, package.json, ...) and the other source files in their directory,
+through the GitHub API (authenticated with GITHUB_TOKEN when set). With
+--write, tests for a remote file go to the current directory unless
+--output is given.
+
+For scripting, --stdin reads the source from standard input, in the
+language given by --lang, and --emit - prints the test code to standard
+output instead of writing it next to the source. Progress then goes to
+standard error. --emit FILE writes the test code to FILE as is.
+
+Examples:
+  qtest generate-file -f ./pkg/calc.go --write
+  qtest generate-file -f octo/calc:pkg/calc.go@v1.2.0
+  qtest generate-file -f https://github.com/octo/calc/blob/main/pkg/calc.go --write -o ./tests
+  cat calc.go | qtest generate-file --stdin --lang go --emit - > calc_test.go`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := context.Background()
 
-			// Validate file path
-			validPath, err := validateFilePath(filePath)
-			if err != nil {
-				return fmt.Errorf("invalid file path: %w", err)
+			switch {
+			case fromStdin && filePath != "":
+				return fmt.Errorf("use either --file or --stdin")
+			case !fromStdin && filePath == "":
+				return fmt.Errorf("--file or --stdin is required")
+			case fromStdin && langName == "":
+				return fmt.Errorf("--stdin needs --lang")
+			case emit != "" && (write || runMutation):
+				return fmt.Errorf("--emit can't be combined with --write or --mutation")
+			case fromStdin && emit == "":
+				return fmt.Errorf("--stdin needs --emit: - for stdout, or a file")
+			}
+
+			// Keep stdout for the test code when it's emitted there
+			out := io.Writer(os.Stdout)
+			if emit == "-" {
+				out = os.Stderr
 			}
-			filePath = validPath
 
 			// Load config
 			cfg, err := config.Load()
 			if err != nil {
-				return fmt.Errorf("failed to load config: %w", err)
+				return cliErrorf(exitConfig, "failed to load config: %w", err)
+			}
+
+			// The parser and adapters read the source from a file, so stdin
+			// is kept in a temporary directory until the tests are emitted
+			if fromStdin {
+				ext := parser.Language(strings.ToLower(langName)).Extension()
+				if ext == "" {
+					return fmt.Errorf("unsupported language: %s", langName)
+				}
+				source, err := io.ReadAll(os.Stdin)
+				if err != nil {
+					return fmt.Errorf("failed to read stdin: %w", err)
+				}
+				dir, err := os.MkdirTemp("", "qtest-stdin-")
+				if err != nil {
+					return fmt.Errorf("failed to create temporary directory: %w", err)
+				}
+				defer os.RemoveAll(dir)
+
+				filePath = filepath.Join(dir, "stdin"+ext)
+				if err := os.WriteFile(filePath, source, 0600); err != nil {
+					return fmt.Errorf("failed to stage stdin: %w", err)
+				}
+			}
+
+			// Download a remote file instead of cloning its repository
+			remote, err := remoteSourceFile(filePath)
+			if err != nil {
+				return fmt.Errorf("invalid remote file: %w", err)
+			}
+			if remote != nil {
+				if runMutation {
+					return fmt.Errorf("--mutation needs a local file")
+				}
+				dir, err := os.MkdirTemp("", "qtest-remote-")
+				if err != nil {
+					return fmt.Errorf("failed to create download directory: %w", err)
+				}
+				defer os.RemoveAll(dir)
+
+				filePath, err = github.NewPRService(cfg.GitHubToken).FetchRemoteFile(ctx, remote, dir)
+				if err != nil {
+					return fmt.Errorf("failed to fetch remote file: %w", err)
+				}
+				if outputDir == "" {
+					outputDir = "."
+				}
+				fmt.Fprintf(out, "📥 Fetched %s\n", remote)
+			}
+
+			// Validate file path
+			validPath, err := validateFilePath(filePath)
+			if err != nil {
+				return fmt.Errorf("invalid file path: %w", err)
 			}
+			filePath = validPath
 
 			// Create LLM router
 			router, err := llm.NewRouter(cfg)
 			if err != nil {
-				return fmt.Errorf("failed to create LLM router: %w", err)
+				return cliErrorf(exitConfig, "failed to create LLM router: %w", err)
 			}
 
 			// Check LLM health
 			if err := router.HealthCheck(); err != nil {
-				return fmt.Errorf("LLM not available: %w\nMake sure Ollama is running: ollama serve", err)
+				return cliErrorf(exitLLM, "LLM not available: %w\nMake sure Ollama is running: ollama serve", err)
 			}
 
 			// Create generator
 			gen := generator.NewGenerator(router)
 
-			// Parse tier
+			// Parse tier; "auto" picks one per function from tier 1
+			selectTier := tier == "auto"
 			tierNum, _ := strconv.Atoi(tier)
 			llmTier := llm.Tier(tierNum)
-			if llmTier < 1 || llmTier > 3 {
+			if selectTier {
+				llmTier = llm.Tier1
+			} else if llmTier < 1 || llmTier > 3 {
 				llmTier = llm.Tier2
 			}
 
 			log.Info().
 				Str("file", filePath).
 				Int("tier", int(llmTier)).
+				Bool("select_tier", selectTier).
 				Msg("generating tests")
 
 			// Generate tests
 			tests, err := gen.GenerateForFile(ctx, filePath, generator.GenerateOptions{
-				Tier:      llmTier,
-				TestType:  dsl.TestTypeUnit,
-				MaxTests:  maxTests,
-				UseIRSpec: useIRSpec,
+				Tier:           llmTier,
+				SelectTier:     selectTier,
+				TestType:       dsl.TestTypeUnit,
+				MaxTests:       maxTests,
+				UseIRSpec:      useIRSpec,
+				MaxEscalations: cfg.LLM.EscalationRetries,
 			})
 			if err != nil {
 				return fmt.Errorf("failed to generate tests: %w", err)
 			}
 
-			fmt.Printf("\n✅ Generated %d tests:\n\n", len(tests))
+			fmt.Fprintf(out, "\n✅ Generated %d tests:\n\n", len(tests))
+
+			if emit != "" {
+				prov := testProvenance(filePath, tests)
+				switch {
+				case fromStdin:
+					prov.Source = "stdin"
+				case remote != nil:
+					prov.Source = remote.String()
+				}
+				return emitTestCode(filePath, tests, emit, prov, chaos)
+			}
 
 			// Write test files if requested
 			if write {
-				if err := writeTestFiles(filePath, tests, outputDir); err != nil {
+				if err := writeTestFiles(filePath, tests, outputDir, chaos); err != nil {
 					return err
 				}
+				if testFile, err := testFilePath(filePath, outputDir); err == nil {
+					checkTestSetup([]string{testFile}, addDevDeps)
+				}
 
 				// Run mutation testing if requested
 				if runMutation {
@@ -366,26 +627,45 @@ func generateFileCmd() *cobra.Command {
 		},
 	}
 
-	cmd.Flags().StringVarP(&filePath, "file", "f", "", "Source file to generate tests for")
-	cmd.Flags().StringVarP(&outputDir, "output", "o", "", "Output directory (default: same as source)")
-	cmd.Flags().StringVarP(&tier, "tier", "t", "2", "LLM tier (1=fast, 2=balanced, 3=thorough)")
+	cmd.Flags().StringVarP(&filePath, "file", "f", "", "Source file to generate tests for: a path, owner/repo:path@ref or a URL")
+	cmd.Flags().StringVarP(&outputDir, "output", "o", "", "Output directory (default: same as source, or the current directory for remote files)")
+	cmd.Flags().StringVarP(&tier, "tier", "t", "2", "LLM tier (1=fast, 2=balanced, 3=thorough, auto=per function by size)")
 	cmd.Flags().IntVarP(&maxTests, "max", "m", 5, "Maximum number of tests to generate")
 	cmd.Flags().BoolVarP(&write, "write", "w", false, "Write test files to disk")
 	cmd.Flags().BoolVar(&runMutation, "mutation", false, "Run mutation testing after generating tests (requires --write)")
 	cmd.Flags().BoolVar(&useIRSpec, "irspec", false, "Use IRSpec JSON mode (structured output)")
-	cmd.MarkFlagRequired("file")
+	cmd.Flags().BoolVar(&fromStdin, "stdin", false, "Read the source from stdin (requires --lang and --emit)")
+	cmd.Flags().StringVar(&langName, "lang", "", "Language of the source read from stdin: go, python, javascript, typescript or rust")
+	cmd.Flags().StringVar(&emit, "emit", "", "Write the test code to this file instead, or to stdout with -")
+	cmd.Flags().BoolVar(&addDevDeps, "add-dev-deps", false, "Declare the dependencies the tests need in package.json, pyproject.toml or requirements-dev.txt (with --write)")
+	cmd.Flags().BoolVar(&chaos, "chaos", false, "Also test code calling HTTP APIs against downstream timeouts, 503s and malformed responses")
 
 	return cmd
 }
 
+// remoteSourceFile parses a generate-file source given as owner/repo:path@ref
+// or a URL. Paths that exist locally are never remote.
+func remoteSourceFile(spec string) (*github.RemoteFile, error) {
+	if _, err := os.Stat(spec); err == nil {
+		return nil, nil
+	}
+	return github.ParseRemoteFile(spec)
+}
+
 func analyzeCmd() *cobra.Command {
 	var (
-		repoPath    string
-		outputFile  string
-		verbose     bool
-		jsonOut     bool
-		withCoverage bool
-		showAll     bool
+		repoPath        string
+		outputFile      string
+		verbose         bool
+		jsonOut         bool
+		withCoverage    bool
+		showAll         bool
+		forceFrameworks []string
+		skipFrameworks  []string
+		minConfidence   float64
+		discover        bool
+		discoverFlags   discoveryFlags
+		openAPIPath     string
 	)
 
 	cmd := &cobra.Command{
@@ -398,17 +678,36 @@ This command scans the repository, parses all source files, detects frameworks
 
 The output shows:
 - File and function counts by language
+- Detected frameworks, with a confidence score and the evidence for each
 - Detected API endpoints
 - Prioritized test targets
 - Code complexity metrics
 
+Several frameworks can be detected in one repository or module. If one is
+misdetected, force it on with --framework or off with --no-framework.
+
+Routes registered dynamically (in loops, by plugins) are invisible to static
+analysis. With --discover, the service is booted in a sandboxed Docker
+container (read-only source, no capabilities, loopback-only port) and its
+route table is scraped: OpenAPI specs (FastAPI, springdoc, NestJS), Gin's
+debug route log, and Express router introspection. Configure the image,
+command and port under discovery in .qtest.yaml or with --discover-* flags.
+
+With --openapi (or openapi in .qtest.yaml), an OpenAPI 3 or Swagger 2 spec
+is merged into the model: its operations fill in the parameters and body
+types of detected endpoints and add the ones no framework supplement found,
+and its schemas add types.
+
 Examples:
   qtest analyze                        # Analyze current directory
   qtest analyze -p ./my-project        # Analyze specific path
   qtest analyze -p . -o model.json     # Save model to file
   qtest analyze --json                 # Output as JSON
   qtest analyze --coverage             # Include coverage analysis
-  qtest analyze --all                  # Show all test targets`,
+  qtest analyze --all                  # Show all test targets
+  qtest analyze --no-framework nestjs  # Ignore a misdetected framework
+  qtest analyze --openapi openapi.yaml # Merge routes from an OpenAPI spec
+  qtest analyze --discover --discover-image node:20 --discover-cmd "npm start" --discover-port 3000`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := context.Background()
 
@@ -424,11 +723,27 @@ Examples:
 			repoName := filepath.Base(validPath)
 			adapter := model.NewParserAdapter(repoName, "main", "")
 
-			// Register framework supplements
-			registry := supplements.NewRegistry()
+			// Register framework supplements, including ones declared in .qtest.yaml
+			registry, err := newSupplementRegistry(validPath)
+			if err != nil {
+				return err
+			}
 			for _, supp := range registry.GetAll() {
 				adapter.RegisterSupplement(supp)
 			}
+			if err := registerOpenAPI(adapter, validPath, openAPIPath); err != nil {
+				return err
+			}
+			if err := disableSupplements(adapter, validPath); err != nil {
+				return err
+			}
+			adapter.SetMinConfidence(minConfidence)
+			for _, name := range forceFrameworks {
+				adapter.OverrideFramework(name, true)
+			}
+			for _, name := range skipFrameworks {
+				adapter.OverrideFramework(name, false)
+			}
 
 			// Parse all source files
 			p := parser.NewParser()
@@ -489,6 +804,12 @@ Examples:
 				return fmt.Errorf("failed to build model: %w", err)
 			}
 
+			if discover {
+				if err := discoverRoutes(ctx, validPath, discoverFlags, sysModel); err != nil {
+					return err
+				}
+			}
+
 			// Build stats
 			stats := sysModel.Stats()
 
@@ -497,6 +818,7 @@ Examples:
 				result := map[string]interface{}{
 					"path":        validPath,
 					"languages":   sysModel.Languages,
+					"frameworks":  sysModel.Frameworks,
 					"stats":       stats,
 					"endpoints":   sysModel.Endpoints,
 					"testTargets": sysModel.TestTargets,
@@ -518,13 +840,29 @@ Examples:
 			fmt.Printf("   Endpoints:    %d\n", stats["endpoints"])
 			fmt.Printf("   Test Targets: %d\n", stats["test_targets"])
 
+			if len(sysModel.Frameworks) > 0 {
+				fmt.Println()
+				fmt.Println("🔌 Frameworks:")
+				printFrameworkDetections(validPath, sysModel.Frameworks, verbose)
+			}
+
 			// Show endpoints with method colors
 			if len(sysModel.Endpoints) > 0 {
 				fmt.Println()
 				fmt.Println("🌐 API Endpoints:")
 				for _, ep := range sysModel.Endpoints {
 					methodIcon := getMethodIcon(ep.Method)
-					fmt.Printf("   %s %-6s %s → %s\n", methodIcon, ep.Method, ep.Path, ep.Handler)
+					source := ""
+					if ep.Source != "" {
+						source = " (" + ep.Source + ")"
+					}
+					if ep.GraphQL != nil {
+						source = " [" + ep.GraphQL.Kind + " " + ep.GraphQL.Field + "]" + source
+					}
+					if ep.GRPC != nil {
+						source = " [gRPC]" + source
+					}
+					fmt.Printf("   %s %-6s %s → %s%s\n", methodIcon, ep.Method, ep.Path, ep.Handler, source)
 				}
 			}
 
@@ -613,10 +951,49 @@ Examples:
 	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output as JSON")
 	cmd.Flags().BoolVar(&withCoverage, "coverage", false, "Include coverage analysis")
 	cmd.Flags().BoolVar(&showAll, "all", false, "Show all test targets")
+	cmd.Flags().StringSliceVar(&forceFrameworks, "framework", nil, "Run a framework's analysis even if not detected (repeatable)")
+	cmd.Flags().StringSliceVar(&skipFrameworks, "no-framework", nil, "Skip a misdetected framework (repeatable)")
+	cmd.Flags().Float64Var(&minConfidence, "min-confidence", model.DefaultMinConfidence, "Confidence (0-1) a framework needs to be used")
+	cmd.Flags().BoolVar(&discover, "discover", false, "Boot the service in a container and discover routes at runtime")
+	cmd.Flags().StringVar(&discoverFlags.image, "discover-image", "", "Container image for --discover (overrides .qtest.yaml)")
+	cmd.Flags().StringVar(&discoverFlags.command, "discover-cmd", "", "Command that starts the service for --discover")
+	cmd.Flags().IntVar(&discoverFlags.port, "discover-port", 0, "Port the service listens on for --discover")
+	cmd.Flags().DurationVar(&discoverFlags.timeout, "discover-timeout", 0, "How long to wait for the service to start (default 60s)")
+	cmd.Flags().StringVar(&openAPIPath, "openapi", "", "OpenAPI/Swagger spec (YAML or JSON) to merge into the model (overrides .qtest.yaml)")
 
 	return cmd
 }
 
+// printFrameworkDetections shows detected frameworks with their confidence
+// and the strongest evidence, or all of it when verbose
+func printFrameworkDetections(root string, detections []model.FrameworkDetection, verbose bool) {
+	for _, d := range detections {
+		status := ""
+		switch {
+		case d.Forced:
+			status = " (forced)"
+		case d.Skipped:
+			status = " (skipped)"
+		}
+		fmt.Printf("   %-12s %3.0f%%%s\n", d.Framework, d.Confidence*100, status)
+
+		evidence := d.Evidence
+		if !verbose && len(evidence) > 3 {
+			evidence = evidence[:3]
+		}
+		for _, e := range evidence {
+			location := relativePath(root, e.File)
+			if e.Line > 0 {
+				location = fmt.Sprintf("%s:%d", location, e.Line)
+			}
+			fmt.Printf("      %s (%s)\n", location, e.Signal)
+		}
+		if more := len(d.Evidence) - len(evidence); more > 0 {
+			fmt.Printf("      ... and %d more (use -v to show all)\n", more)
+		}
+	}
+}
+
 // getMethodIcon returns an icon for HTTP method
 func getMethodIcon(method string) string {
 	switch method {
@@ -666,7 +1043,7 @@ func formatTargetKind(kind string) string {
 // isSupportedExt checks if file extension is supported for parsing
 func isSupportedExt(ext string) bool {
 	switch ext {
-	case ".go", ".py", ".js", ".jsx", ".ts", ".tsx", ".java":
+	case ".go", ".py", ".js", ".jsx", ".ts", ".tsx", ".java", ".rs", ".cs":
 		return true
 	}
 	return false
@@ -726,7 +1103,7 @@ func parseCmd() *cobra.Command {
 			p := parser.NewParser()
 			parsed, err := p.ParseFile(ctx, filePath)
 			if err != nil {
-				return fmt.Errorf("failed to parse file: %w", err)
+				return cliErrorf(exitParse, "failed to parse file: %w", err)
 			}
 
 			fmt.Printf("📄 File: %s\n", parsed.Path)
@@ -766,36 +1143,121 @@ func parseCmd() *cobra.Command {
 	return cmd
 }
 
-// writeTestFiles writes generated tests to disk using the appropriate adapter
-func writeTestFiles(sourceFile string, tests []generator.GeneratedTest, outputDir string) error {
+// writeTestFiles writes generated tests to disk using the appropriate
+// adapter, with chaos tests when chaos is set
+func writeTestFiles(sourceFile string, tests []generator.GeneratedTest, outputDir string, chaos bool) error {
 	if len(tests) == 0 {
 		return nil
 	}
 
-	// Get adapter for source language
+	testFile, err := testFilePath(sourceFile, outputDir)
+	if err != nil {
+		return err
+	}
+
+	// Create output directory if needed
+	if err := os.MkdirAll(filepath.Dir(testFile), 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	code, err := renderTestCode(sourceFile, tests, chaos)
+	if err != nil {
+		return err
+	}
+	prov := testProvenance(sourceFile, tests)
+
+	// Write to file, leaving test files a human wrote or edited alone
+	written, err := adapters.WriteGeneratedFile(testFile, code, prov, adapters.WriteOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to write test file: %w", err)
+	}
+	if written.MergePath != "" {
+		fmt.Printf("✎  %s was edited by hand, keeping it\n", testFile)
+		fmt.Printf("🔀 Merge proposal: %s (%d conflicts)\n\n", written.MergePath, written.Conflicts)
+		fmt.Print(written.Diff)
+		return nil
+	}
+	if written.Path != testFile {
+		fmt.Printf("⚠️  %s was not written by QTest, keeping it\n", testFile)
+	}
+
+	fmt.Printf("📝 Written: %s\n", written.Path)
+
+	// Count steps for display
+	stepCount := 0
+	for _, test := range tests {
+		if len(test.TestSpecs) > 0 {
+			stepCount += len(test.TestSpecs)
+		} else if test.DSL != nil {
+			stepCount += len(test.DSL.Steps)
+		}
+	}
+	fmt.Printf("   Tests: %d steps\n", stepCount)
+
+	return nil
+}
+
+// emitTestCode writes generated tests, stamped with their provenance, to
+// dest, or to stdout when dest is "-". Unlike writeTestFiles it doesn't
+// check who owns dest: the caller named it.
+func emitTestCode(sourceFile string, tests []generator.GeneratedTest, dest string, prov adapters.Provenance, chaos bool) error {
+	if len(tests) == 0 {
+		return fmt.Errorf("no tests were generated")
+	}
+	testFile, err := testFilePath(sourceFile, "")
+	if err != nil {
+		return err
+	}
+	code, err := renderTestCode(sourceFile, tests, chaos)
+	if err != nil {
+		return err
+	}
+
+	if dest == "-" {
+		_, err := fmt.Fprint(os.Stdout, adapters.StampProvenance(code, testFile, prov))
+		return err
+	}
+	if err := os.WriteFile(dest, []byte(adapters.StampProvenance(code, dest, prov)), 0644); err != nil {
+		return fmt.Errorf("failed to write test file: %w", err)
+	}
+	return nil
+}
+
+// testFilePath is where the tests of a source file go, in outputDir or
+// next to the source
+func testFilePath(sourceFile, outputDir string) (string, error) {
 	lang := parser.DetectLanguage(sourceFile)
-	registry := adapters.NewRegistry()
-	adapter, err := registry.GetForLanguage(lang)
+	adapter, err := adapters.NewRegistry().GetForLanguage(lang)
 	if err != nil {
-		return fmt.Errorf("no adapter for language %s: %w", lang, err)
+		return "", fmt.Errorf("no adapter for language %s: %w", lang, err)
 	}
 
-	// Determine output directory
 	dir := outputDir
 	if dir == "" {
 		dir = filepath.Dir(sourceFile)
 	}
-
-	// Create output directory if needed
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return fmt.Errorf("failed to create output directory: %w", err)
+	if lang == parser.LanguageRust && outputDir == "" {
+		// Integration tests live in the crate's tests/ directory
+		return adapters.RustTestPath(sourceFile), nil
 	}
 
-	// Generate test file name
 	base := filepath.Base(sourceFile)
-	ext := filepath.Ext(base)
-	name := strings.TrimSuffix(base, ext)
-	testFile := filepath.Join(dir, name+adapter.TestFileSuffix()+adapter.FileExtension())
+	name := strings.TrimSuffix(base, filepath.Ext(base))
+	return filepath.Join(dir, name+adapter.TestFileSuffix()+adapter.FileExtension()), nil
+}
+
+// renderTestCode turns generated tests into one test file's code, from
+// their TestSpecs when the language has a spec adapter, else their DSL.
+// With chaos, spec adapters add tests of downstream HTTP failures.
+func renderTestCode(sourceFile string, tests []generator.GeneratedTest, chaos bool) (string, error) {
+	lang := parser.DetectLanguage(sourceFile)
+	registry := adapters.NewRegistry()
+	adapter, err := registry.GetForLanguage(lang)
+	if err != nil {
+		return "", fmt.Errorf("no adapter for language %s: %w", lang, err)
+	}
+	base := filepath.Base(sourceFile)
+	name := strings.TrimSuffix(base, filepath.Ext(base))
 
 	var code string
 
@@ -809,6 +1271,9 @@ func writeTestFiles(sourceFile string, tests []generator.GeneratedTest, outputDi
 	if len(allSpecs) > 0 {
 		specAdapter, specErr := registry.GetSpecForLanguage(lang)
 		if specErr == nil {
+			if chaos {
+				specAdapter = adapters.WithChaos(specAdapter)
+			}
 			code, err = specAdapter.GenerateFromSpecs(allSpecs, sourceFile)
 			if err != nil {
 				log.Warn().Err(err).Str("language", string(lang)).Msg("TestSpec generation failed, falling back to DSL")
@@ -841,35 +1306,75 @@ func writeTestFiles(sourceFile string, tests []generator.GeneratedTest, outputDi
 		}
 
 		if len(combinedDSL.Steps) == 0 {
-			return fmt.Errorf("no test steps could be generated")
+			return "", fmt.Errorf("no test steps could be generated")
 		}
 
 		// Generate combined test code
 		code, err = adapter.Generate(combinedDSL)
 		if err != nil {
-			return fmt.Errorf("failed to generate test code: %w", err)
+			return "", fmt.Errorf("failed to generate test code: %w", err)
 		}
 	}
+	return code, nil
+}
 
-	// Write to file
-	if err := os.WriteFile(testFile, []byte(code), 0644); err != nil {
-		return fmt.Errorf("failed to write test file: %w", err)
+// testProvenance is the provenance stamped on a source file's generated
+// tests, so later runs know the file is QTest's
+func testProvenance(sourceFile string, tests []generator.GeneratedTest) adapters.Provenance {
+	prov := adapters.Provenance{
+		RunID:        uuid.New().String(),
+		SourceCommit: sourceCommit(filepath.Dir(sourceFile)),
+		Source:       repoSourcePath(sourceFile),
 	}
-
-	fmt.Printf("📝 Written: %s\n", testFile)
-
-	// Count steps for display
-	stepCount := 0
 	for _, test := range tests {
-		if len(test.TestSpecs) > 0 {
-			stepCount += len(test.TestSpecs)
-		} else if test.DSL != nil {
-			stepCount += len(test.DSL.Steps)
+		if test.Model != "" && prov.Model == "" {
+			prov.Model = test.Model
+			prov.PromptHash = test.PromptHash
+			prov.Attempts = test.Attempts
+		}
+		if test.Function != nil {
+			prov.Targets = append(prov.Targets, adapters.TargetName(test.Function.Class, test.Function.Name))
 		}
 	}
-	fmt.Printf("   Tests: %d steps\n", stepCount)
+	return prov
+}
 
-	return nil
+// sourceCommit returns the commit checked out in dir, or "" outside git
+func sourceCommit(dir string) string {
+	out, err := exec.Command("git", "-C", dir, "rev-parse", "HEAD").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// repoRoot returns the top directory of the git repository dir is in, or ""
+// outside git
+func repoRoot(dir string) string {
+	out, err := exec.Command("git", "-C", dir, "rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// repoSourcePath is how provenance records a source file: relative to its
+// repository, so it doesn't depend on the directory QTest ran in. Files
+// outside git are recorded by absolute path.
+func repoSourcePath(sourceFile string) string {
+	abs, err := filepath.Abs(sourceFile)
+	if err != nil {
+		return sourceFile
+	}
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		abs = resolved
+	}
+	if top := repoRoot(filepath.Dir(abs)); top != "" {
+		if rel, err := filepath.Rel(top, abs); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return filepath.ToSlash(rel)
+		}
+	}
+	return abs
 }
 
 // runMutationTesting runs mutation testing on a source file after test generation
@@ -894,6 +1399,9 @@ func runMutationTesting(ctx context.Context, sourceFile, outputDir string) error
 	ext := filepath.Ext(base)
 	name := strings.TrimSuffix(base, ext)
 	testFile := filepath.Join(dir, name+adapter.TestFileSuffix()+adapter.FileExtension())
+	if lang == parser.LanguageRust && outputDir == "" {
+		testFile = adapters.RustTestPath(sourceFile)
+	}
 
 	// Check test file exists
 	if _, err := os.Stat(testFile); os.IsNotExist(err) {
@@ -904,6 +1412,7 @@ func runMutationTesting(ctx context.Context, sourceFile, outputDir string) error
 	runner := mutation.NewRunner(
 		mutation.NewGoMutestingTool(),
 		mutation.NewSimpleMutationTool(),
+		mutation.NewRustMutationTool(),
 	)
 
 	// Run mutation testing
@@ -942,6 +1451,7 @@ func runRepoMutationTesting(ctx context.Context, repoPath string) error {
 	runner := mutation.NewRunner(
 		mutation.NewGoMutestingTool(),
 		mutation.NewSimpleMutationTool(),
+		mutation.NewRustMutationTool(),
 	)
 
 	// Find all source files with corresponding test files
@@ -1027,8 +1537,8 @@ func runRepoMutationTesting(ctx context.Context, repoPath string) error {
 func configCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "config",
-		Short: "Show current configuration",
-		Long: `Display current QTest configuration settings.
+		Short: "Show and manage configuration",
+		Long: `Display current QTest configuration settings, and manage profiles.
 
 Configuration is loaded from environment variables. Set these to customize behavior:
 
@@ -1039,15 +1549,29 @@ Configuration is loaded from environment variables. Set these to customize behav
   OLLAMA_TIER1_MODEL   Fast model (default: qwen2.5-coder:7b)
   OLLAMA_TIER2_MODEL   Balanced model (default: deepseek-coder-v2:16b)
   ANTHROPIC_API_KEY    Anthropic API key for Tier 3
-  GITHUB_TOKEN         GitHub token for private repos`,
+  GITHUB_TOKEN         GitHub token for private repos
+
+Settings can also be kept in profiles (~/.config/qtest/profiles/*.yaml) with
+qtest config set, and switched with qtest config use-profile. A setting is
+taken from, in order: command-line flags, environment variables, the active
+profile, the repository's .qtest.yaml, and the default.
+
+Examples:
+  qtest config set llm.tier2 deepseek-coder-v2:16b
+  qtest config set --profile work llm.provider anthropic
+  qtest config use-profile work
+  qtest config get llm.tier2`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			cfg, err := config.Load()
 			if err != nil {
-				return fmt.Errorf("failed to load config: %w", err)
+				return cliErrorf(exitConfig, "failed to load config: %w", err)
 			}
 
 			fmt.Println("⚙️  QTest Configuration")
 			fmt.Println(strings.Repeat("─", 50))
+			if profile, err := config.ActiveProfileName(); err == nil && profile != "" {
+				fmt.Printf("   Profile: %s\n", profile)
+			}
 
 			// Server
 			fmt.Println("\n📡 Server:")
@@ -1090,6 +1614,12 @@ Configuration is loaded from environment variables. Set these to customize behav
 		},
 	}
 
+	cmd.AddCommand(configGetCmd())
+	cmd.AddCommand(configSetCmd())
+	cmd.AddCommand(configUnsetCmd())
+	cmd.AddCommand(configUseProfileCmd())
+	cmd.AddCommand(configProfilesCmd())
+
 	return cmd
 }
 
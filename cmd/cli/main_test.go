@@ -2,7 +2,15 @@ package main
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/QTest-hq/qtest/internal/adapters"
+	"github.com/QTest-hq/qtest/internal/generator"
+	"github.com/QTest-hq/qtest/internal/parser"
+	"github.com/QTest-hq/qtest/pkg/model"
 )
 
 func TestIsSupportedExt(t *testing.T) {
@@ -21,7 +29,8 @@ func TestIsSupportedExt(t *testing.T) {
 		{".php", false},
 		{".c", false},
 		{".cpp", false},
-		{".rs", false},
+		{".rs", true},
+		{".cs", true},
 		{"", false},
 		{".txt", false},
 		{".md", false},
@@ -192,3 +201,42 @@ func TestFormatTargetKind(t *testing.T) {
 		})
 	}
 }
+
+func TestEmitTestCode(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "stdin.go")
+	os.WriteFile(source, []byte("package calc\n\nfunc Add(a, b int) int { return a + b }\n"), 0600)
+
+	tests := []generator.GeneratedTest{{
+		TestSpecs: []model.TestSpec{{
+			FunctionName: "Add",
+			Description:  "adds",
+			Inputs:       map[string]interface{}{"a": 1, "b": 2},
+			ArgOrder:     []string{"a", "b"},
+			Assertions:   []model.Assertion{{Kind: "equality", Actual: "result", Expected: 3}},
+		}},
+		Function: &parser.Function{Name: "Add"},
+	}}
+
+	dest := filepath.Join(dir, "out_test.go")
+	if err := emitTestCode(source, tests, dest, adapters.Provenance{Source: "stdin"}, false); err != nil {
+		t.Fatalf("emitTestCode() error = %v", err)
+	}
+	data, err := os.ReadFile(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code := string(data)
+	for _, want := range []string{"package calc", "func TestAdd(t *testing.T)", "result := Add(a, b)"} {
+		if !strings.Contains(code, want) {
+			t.Errorf("emitted code missing %q:\n%s", want, code)
+		}
+	}
+	if prov, err := adapters.ParseProvenance(code); err != nil || prov == nil || prov.Source != "stdin" {
+		t.Errorf("ParseProvenance() = %+v, %v, want source stdin", prov, err)
+	}
+
+	if err := emitTestCode(source, nil, dest, adapters.Provenance{}, false); err == nil {
+		t.Error("emitTestCode() with no tests succeeded")
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/QTest-hq/qtest/internal/config"
 	"github.com/QTest-hq/qtest/internal/parser"
 	"github.com/QTest-hq/qtest/internal/supplements"
 	"github.com/QTest-hq/qtest/pkg/model"
@@ -52,75 +53,12 @@ This is the foundation for generating the complete test pyramid.`,
 
 			fmt.Printf("🔍 Scanning directory: %s\n\n", validPath)
 
-			// Create parser adapter
-			repoName := filepath.Base(validPath)
-			adapter := model.NewParserAdapter(repoName, "main", "")
-
-			// Register all supplements
-			registry := supplements.NewRegistry()
-			for _, supp := range registry.GetAll() {
-				adapter.RegisterSupplement(supp)
-			}
-
-			// Create tree-sitter parser
-			p := parser.NewParser()
-
-			// Walk directory and parse files
-			fileCount := 0
-			err = filepath.Walk(validPath, func(path string, info os.FileInfo, err error) error {
-				if err != nil {
-					return nil
-				}
-
-				// Skip hidden and vendor directories
-				if info.IsDir() {
-					name := info.Name()
-					if strings.HasPrefix(name, ".") || name == "node_modules" || name == "vendor" || name == "__pycache__" {
-						return filepath.SkipDir
-					}
-					return nil
-				}
-
-				// Only parse supported source files
-				ext := strings.ToLower(filepath.Ext(path))
-				if !isSupportedSourceFile(ext) {
-					return nil
-				}
-
-				// Parse file
-				parsed, err := p.ParseFile(ctx, path)
-				if err != nil {
-					if verbose {
-						fmt.Printf("  ⚠️  Skip %s: %v\n", path, err)
-					}
-					return nil
-				}
-
-				// Convert to model format
-				pf := convertParsedFile(parsed)
-				adapter.AddFile(pf)
-				fileCount++
-
-				if verbose {
-					fmt.Printf("  ✓ %s (%d functions)\n", path, len(parsed.Functions))
-				}
-
-				return nil
-			})
-
+			sysModel, fileCount, err := buildSystemModel(ctx, validPath, verbose)
 			if err != nil {
-				return fmt.Errorf("failed to scan directory: %w", err)
+				return err
 			}
-
 			fmt.Printf("📄 Parsed %d source files\n", fileCount)
 
-			// Build model (runs supplements)
-			fmt.Println("🔌 Running framework supplements...")
-			sysModel, err := adapter.Build()
-			if err != nil {
-				return fmt.Errorf("failed to build model: %w", err)
-			}
-
 			// Print summary
 			stats := sysModel.Stats()
 			fmt.Println()
@@ -182,6 +120,137 @@ This is the foundation for generating the complete test pyramid.`,
 	return cmd
 }
 
+// buildSystemModel parses the supported source files under dir and builds
+// a system model from them, running framework supplements. It returns the
+// model and the number of files parsed.
+func buildSystemModel(ctx context.Context, dir string, verbose bool) (*model.SystemModel, int, error) {
+	// Create parser adapter
+	repoName := filepath.Base(dir)
+	adapter := model.NewParserAdapter(repoName, "main", "")
+
+	// Register all supplements, including ones declared in .qtest.yaml
+	registry, err := newSupplementRegistry(dir)
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, supp := range registry.GetAll() {
+		adapter.RegisterSupplement(supp)
+	}
+	if err := registerOpenAPI(adapter, dir, ""); err != nil {
+		return nil, 0, err
+	}
+	if err := disableSupplements(adapter, dir); err != nil {
+		return nil, 0, err
+	}
+
+	// Create tree-sitter parser
+	p := parser.NewParser()
+
+	// Walk directory and parse files
+	fileCount := 0
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+
+		// Skip hidden and vendor directories
+		if info.IsDir() {
+			name := info.Name()
+			if path != dir && (strings.HasPrefix(name, ".") || name == "node_modules" || name == "vendor" || name == "__pycache__") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		// Only parse supported source files
+		ext := strings.ToLower(filepath.Ext(path))
+		if !isSupportedSourceFile(ext) {
+			return nil
+		}
+
+		// Parse file
+		parsed, err := p.ParseFile(ctx, path)
+		if err != nil {
+			if verbose {
+				fmt.Fprintf(os.Stderr, "  ⚠️  Skip %s: %v\n", path, err)
+			}
+			return nil
+		}
+
+		// Convert to model format
+		adapter.AddFile(convertParsedFile(parsed))
+		fileCount++
+
+		if verbose {
+			fmt.Fprintf(os.Stderr, "  ✓ %s (%d functions)\n", path, len(parsed.Functions))
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to scan directory: %w", err)
+	}
+
+	// Build model (runs supplements)
+	sysModel, err := adapter.Build()
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to build model: %w", err)
+	}
+	return sysModel, fileCount, nil
+}
+
+// registerOpenAPI merges an OpenAPI spec into the model adapter builds:
+// specPath, or else the openapi file named in dir's .qtest.yaml. It's
+// registered last so it fills in what the framework supplements found.
+func registerOpenAPI(adapter *model.ParserAdapter, dir, specPath string) error {
+	if specPath == "" {
+		cfg, err := config.LoadProjectConfig(dir)
+		if err != nil {
+			return cliErrorf(exitConfig, "failed to load project config: %w", err)
+		}
+		if cfg.OpenAPI == "" {
+			return nil
+		}
+		specPath = cfg.OpenAPI
+		if !filepath.IsAbs(specPath) {
+			specPath = filepath.Join(dir, specPath)
+		}
+	}
+
+	importer, err := model.LoadOpenAPI(specPath)
+	if err != nil {
+		return err
+	}
+	adapter.RegisterSupplement(importer)
+	return nil
+}
+
+// disableSupplements turns off the supplements named in dir's .qtest.yaml
+func disableSupplements(adapter *model.ParserAdapter, dir string) error {
+	cfg, err := config.LoadProjectConfig(dir)
+	if err != nil {
+		return cliErrorf(exitConfig, "failed to load project config: %w", err)
+	}
+	for _, name := range cfg.DisableSupplements {
+		adapter.OverrideFramework(name, false)
+	}
+	return nil
+}
+
+// newSupplementRegistry returns the built-in framework supplements plus
+// the ones declared in dir's .qtest.yaml
+func newSupplementRegistry(dir string) (*supplements.Registry, error) {
+	registry := supplements.NewRegistry()
+	cfg, err := config.LoadProjectConfig(dir)
+	if err != nil {
+		return nil, cliErrorf(exitConfig, "failed to load project config: %w", err)
+	}
+	if err := registry.RegisterRules(cfg.Supplements); err != nil {
+		return nil, err
+	}
+	return registry, nil
+}
+
 func modelShowCmd() *cobra.Command {
 	var modelFile string
 
@@ -196,7 +265,7 @@ func modelShowCmd() *cobra.Command {
 
 			var sysModel model.SystemModel
 			if err := json.Unmarshal(data, &sysModel); err != nil {
-				return fmt.Errorf("failed to parse model: %w", err)
+				return cliErrorf(exitParse, "failed to parse model: %w", err)
 			}
 
 			// Pretty print the model
@@ -224,7 +293,7 @@ func modelShowCmd() *cobra.Command {
 // isSupportedSourceFile checks if a file extension is supported
 func isSupportedSourceFile(ext string) bool {
 	switch ext {
-	case ".go", ".py", ".js", ".jsx", ".ts", ".tsx", ".java":
+	case ".go", ".py", ".js", ".jsx", ".ts", ".tsx", ".java", ".rs", ".cs":
 		return true
 	default:
 		return false
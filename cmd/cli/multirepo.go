@@ -0,0 +1,232 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/QTest-hq/qtest/internal/llm"
+	"github.com/QTest-hq/qtest/internal/workspace"
+)
+
+// repoResult is the outcome of generating tests for one repo of a
+// multi-repo run
+type repoResult struct {
+	Repo      string
+	Workspace string // workspace directory, "" if none was created
+	Targets   int
+	Completed int
+	Failed    int
+	Duration  time.Duration
+	Err       error
+}
+
+// readRepoList reads the repos to generate for, one local path per line.
+// Blank lines and # comments are skipped, and relative paths are relative
+// to the list file.
+func readRepoList(listPath string) ([]string, error) {
+	f, err := os.Open(listPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open repo list: %w", err)
+	}
+	defer f.Close()
+
+	base := filepath.Dir(listPath)
+	seen := make(map[string]bool)
+	var repos []string
+
+	scanner := bufio.NewScanner(f)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if !filepath.IsAbs(line) {
+			line = filepath.Join(base, line)
+		}
+		repo, err := validateDirPath(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", listPath, lineNum, err)
+		}
+		if seen[repo] {
+			continue
+		}
+		seen[repo] = true
+		repos = append(repos, repo)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read repo list: %w", err)
+	}
+	if len(repos) == 0 {
+		return nil, fmt.Errorf("no repos listed in %s", listPath)
+	}
+
+	return repos, nil
+}
+
+// repoLabels names repos for output: their directory names, or their full
+// paths where two share a directory name
+func repoLabels(repos []string) []string {
+	count := make(map[string]int)
+	for _, repo := range repos {
+		count[filepath.Base(repo)]++
+	}
+
+	labels := make([]string, len(repos))
+	for i, repo := range repos {
+		labels[i] = filepath.Base(repo)
+		if count[labels[i]] > 1 {
+			labels[i] = repo
+		}
+	}
+	return labels
+}
+
+// runRepos calls run for every repo, at most parallel at a time, and
+// returns the results in the order the repos were given
+func runRepos(ctx context.Context, repos []string, parallel int, run func(ctx context.Context, i int, repo string) repoResult) []repoResult {
+	if parallel <= 0 || parallel > len(repos) {
+		parallel = len(repos)
+	}
+
+	results := make([]repoResult, len(repos))
+	sem := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+
+	for i, repo := range repos {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			results[i] = repoResult{Repo: repo, Err: ctx.Err()}
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, repo string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = run(ctx, i, repo)
+		}(i, repo)
+	}
+
+	wg.Wait()
+	return results
+}
+
+// linePrinter serializes the output of repos generating concurrently, one
+// whole line at a time
+type linePrinter struct {
+	mu  sync.Mutex
+	out io.Writer
+}
+
+func (p *linePrinter) printf(format string, args ...interface{}) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	fmt.Fprintf(p.out, format, args...)
+}
+
+// generateRepo runs one repo through the generation pipeline. Every repo
+// shares router, so its concurrency limit and circuit breakers apply to
+// the run as a whole.
+func generateRepo(ctx context.Context, repo, label string, router *llm.Router, gitToken string, runCfg workspace.RunConfig, out *linePrinter) repoResult {
+	start := time.Now()
+	result := repoResult{Repo: repo}
+
+	wsName := fmt.Sprintf("gen-%s-%s", filepath.Base(repo), time.Now().Format("20060102-150405"))
+	ws, err := workspace.New(wsName, repo, nil)
+	if err != nil {
+		result.Err = fmt.Errorf("failed to create workspace: %w", err)
+		result.Duration = time.Since(start)
+		return result
+	}
+	result.Workspace = ws.Path()
+
+	runner := workspace.NewRunnerV2(ws, router, gitToken, &runCfg)
+
+	// Only phase changes are printed; per-target progress from several
+	// repos at once would be unreadable
+	lastPhase := ""
+	runner.OnProgress = func(phase string, current, total int, message string) {
+		if phase != lastPhase {
+			lastPhase = phase
+			out.printf("[%s] %s\n", label, message)
+		}
+	}
+	runner.OnComplete = func(testFile string, count int) {
+		out.printf("[%s] ✓ Written: %s (%d tests)\n", label, testFile, count)
+	}
+
+	err = runner.Initialize(ctx)
+	if err != nil {
+		err = fmt.Errorf("initialization failed: %w", err)
+	} else if err = runner.Run(ctx); err != nil {
+		err = fmt.Errorf("generation failed: %w", err)
+	}
+
+	summary := ws.Summary()
+	result.Targets, _ = summary["total"].(int)
+	result.Completed, _ = summary["completed"].(int)
+	result.Failed, _ = summary["failed"].(int)
+	result.Duration = time.Since(start)
+	result.Err = err
+
+	if err != nil {
+		out.printf("[%s] ❌ %v\n", label, err)
+	} else {
+		out.printf("[%s] ✅ Done: %d completed, %d failed\n", label, result.Completed, result.Failed)
+	}
+	return result
+}
+
+// printMultiRepoSummary prints a table of every repo's results with totals
+func printMultiRepoSummary(w io.Writer, results []repoResult) {
+	labels := make([]string, len(results))
+	for i, r := range results {
+		labels[i] = r.Repo
+	}
+	labels = repoLabels(labels)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "REPO\tSTATUS\tTARGETS\tCOMPLETED\tFAILED\tDURATION\tWORKSPACE")
+
+	var total repoResult
+	for i, r := range results {
+		status := "ok"
+		if r.Err != nil {
+			status = "error"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%d\t%s\t%s\n",
+			labels[i], status, r.Targets, r.Completed, r.Failed, r.Duration.Round(time.Second), orDash(r.Workspace))
+
+		total.Targets += r.Targets
+		total.Completed += r.Completed
+		total.Failed += r.Failed
+		if r.Duration > total.Duration {
+			total.Duration = r.Duration // repos ran concurrently
+		}
+	}
+
+	fmt.Fprintf(tw, "TOTAL\t\t%d\t%d\t%d\t%s\n",
+		total.Targets, total.Completed, total.Failed, total.Duration.Round(time.Second))
+	tw.Flush()
+
+	var errs []string
+	for i, r := range results {
+		if r.Err != nil {
+			errs = append(errs, fmt.Sprintf("  %s: %v", labels[i], r.Err))
+		}
+	}
+	if len(errs) > 0 {
+		fmt.Fprintf(w, "\nErrors:\n%s\n", strings.Join(errs, "\n"))
+	}
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestReadRepoList(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"api", "web"} {
+		if err := os.Mkdir(filepath.Join(dir, name), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	other := t.TempDir()
+
+	list := filepath.Join(dir, "repos.txt")
+	content := "# services\napi\n\n  web  \n" + other + "\napi\n"
+	if err := os.WriteFile(list, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	repos, err := readRepoList(list)
+	if err != nil {
+		t.Fatalf("readRepoList() error: %v", err)
+	}
+	want := []string{filepath.Join(dir, "api"), filepath.Join(dir, "web"), other}
+	if strings.Join(repos, ",") != strings.Join(want, ",") {
+		t.Errorf("readRepoList() = %v, want %v", repos, want)
+	}
+}
+
+func TestReadRepoList_Errors(t *testing.T) {
+	dir := t.TempDir()
+
+	missing := filepath.Join(dir, "missing.txt")
+	if err := os.WriteFile(missing, []byte("api\nnope\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	os.Mkdir(filepath.Join(dir, "api"), 0755)
+	if _, err := readRepoList(missing); err == nil || !strings.Contains(err.Error(), "missing.txt:2") {
+		t.Errorf("readRepoList() error = %v, want one naming line 2", err)
+	}
+
+	empty := filepath.Join(dir, "empty.txt")
+	if err := os.WriteFile(empty, []byte("# nothing yet\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readRepoList(empty); err == nil {
+		t.Error("readRepoList() of a list without repos should fail")
+	}
+}
+
+func TestRepoLabels(t *testing.T) {
+	got := repoLabels([]string{"/src/a/api", "/src/web", "/src/b/api"})
+	want := []string{"/src/a/api", "web", "/src/b/api"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("repoLabels() = %v, want %v", got, want)
+	}
+}
+
+func TestRunRepos_Parallel(t *testing.T) {
+	repos := []string{"a", "b", "c", "d", "e"}
+	var running, peak int32
+
+	results := runRepos(context.Background(), repos, 2, func(ctx context.Context, i int, repo string) repoResult {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		return repoResult{Repo: repo, Completed: i}
+	})
+
+	if peak > 2 {
+		t.Errorf("%d repos ran at once, want at most 2", peak)
+	}
+	for i, r := range results {
+		if r.Repo != repos[i] || r.Completed != i {
+			t.Errorf("results[%d] = %+v, want repo %s in order", i, r, repos[i])
+		}
+	}
+}
+
+func TestRunRepos_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	results := runRepos(ctx, []string{"a", "b", "c"}, 1, func(ctx context.Context, i int, repo string) repoResult {
+		return repoResult{Repo: repo, Err: ctx.Err()}
+	})
+	for _, r := range results {
+		if !errors.Is(r.Err, context.Canceled) {
+			t.Errorf("%s: Err = %v, want context.Canceled", r.Repo, r.Err)
+		}
+	}
+}
+
+func TestPrintMultiRepoSummary(t *testing.T) {
+	var buf bytes.Buffer
+	printMultiRepoSummary(&buf, []repoResult{
+		{Repo: "/src/api", Workspace: "/ws/1", Targets: 10, Completed: 8, Failed: 2, Duration: 90 * time.Second},
+		{Repo: "/src/web", Targets: 3, Completed: 0, Failed: 0, Duration: 30 * time.Second, Err: errors.New("modeling failed")},
+	})
+	out := buf.String()
+
+	rows := make(map[string]string)
+	for _, line := range strings.Split(out, "\n") {
+		if fields := strings.Fields(line); len(fields) > 0 {
+			rows[fields[0]] = strings.Join(fields, " ")
+		}
+	}
+
+	for label, want := range map[string]string{
+		"api": "api ok 10 8 2 1m30s /ws/1",
+		"web": "web error 3 0 0 30s -",
+		// The longest repo's duration, as they ran concurrently
+		"TOTAL": "TOTAL 13 8 2 1m30s",
+	} {
+		if rows[label] != want {
+			t.Errorf("row %s = %q, want %q\n%s", label, rows[label], want, out)
+		}
+	}
+	if !strings.Contains(out, "Errors:\n  web: modeling failed") {
+		t.Errorf("summary doesn't list the failed repo's error:\n%s", out)
+	}
+}
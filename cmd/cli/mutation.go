@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/QTest-hq/qtest/internal/adapters"
 	"github.com/QTest-hq/qtest/internal/mutation"
 	"github.com/spf13/cobra"
 )
@@ -51,7 +52,8 @@ func mutationRunCmd() *cobra.Command {
 Examples:
   qtest mutation run -s calculator.go -t calculator_test.go
   qtest mutation run -s ./pkg/math/math.go -t ./pkg/math/math_test.go --mode thorough
-  qtest mutation run -s main.go -t main_test.go -o report.json`,
+  qtest mutation run -s main.go -t main_test.go -o report.json
+  qtest mutation run -s src/lib.rs -t tests/lib_test.rs`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := context.Background()
 
@@ -98,14 +100,15 @@ Examples:
 			runner := mutation.NewRunner(
 				mutation.NewGoMutestingTool(),
 				mutation.NewSimpleMutationTool(),
+				mutation.NewRustMutationTool(),
 			)
 
 			// Check available tools
-			tools := runner.GetAvailableTools(ctx)
-			if len(tools) == 0 {
-				return fmt.Errorf("no mutation testing tools available")
+			tool := runner.ToolFor(ctx, sourceAbs)
+			if tool == nil {
+				return fmt.Errorf("no mutation testing tools available for %s", filepath.Base(sourceAbs))
 			}
-			fmt.Printf("Using: %s\n\n", tools[0].Name())
+			fmt.Printf("Using: %s\n\n", tool.Name())
 
 			// Run mutation testing
 			fmt.Println("Running mutation testing...")
@@ -195,7 +198,7 @@ Examples:
 
 			var result mutation.Result
 			if err := json.Unmarshal(data, &result); err != nil {
-				return fmt.Errorf("failed to parse report: %w", err)
+				return cliErrorf(exitParse, "failed to parse report: %w", err)
 			}
 
 			switch format {
@@ -314,6 +317,13 @@ func deriveTestPath(sourcePath string) string {
 		testName = name + ".test.ts"
 	case ".js":
 		testName = name + ".test.js"
+	case ".rs":
+		// Integration tests live in the crate's tests/ directory
+		testPath := adapters.RustTestPath(sourcePath)
+		if _, err := os.Stat(testPath); err == nil {
+			return testPath
+		}
+		return ""
 	default:
 		return ""
 	}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/QTest-hq/qtest/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+func optimizeCmd() *cobra.Command {
+	var (
+		workDir        string
+		artifactsDir   string
+		executionFile  string
+		sourceViewFile string
+		budget         time.Duration
+		coverageTarget float64
+		outputDir      string
+		reportFile     string
+		jsonOut        bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "optimize",
+		Short: "Pick the generated tests that keep coverage within a runtime budget",
+		Long: `Select a small subset of the generated tests covering nearly the same
+lines, using the timings in execution.json and the lines source-view.json
+credits to each test. Test files are picked by new lines covered per
+millisecond until --coverage-target percent of the suite's lines are
+covered or nothing else fits the budget.
+
+With --output the kept test files are copied there, under their paths in
+the repository, next to optimize-report.json listing what was kept and
+dropped and why.
+
+Examples:
+  qtest optimize --budget 5m
+  qtest optimize --budget 90s --coverage-target 98 -o pruned
+  qtest optimize -a .qtest/workspaces/abc/artifacts --json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if budget < 0 {
+				return fmt.Errorf("invalid --budget %s", budget)
+			}
+			if coverageTarget <= 0 || coverageTarget > 100 {
+				return fmt.Errorf("invalid --coverage-target %.1f: want a percent above 0", coverageTarget)
+			}
+			if executionFile == "" {
+				executionFile = filepath.Join(artifactsDir, "execution.json")
+			}
+			if sourceViewFile == "" {
+				sourceViewFile = filepath.Join(artifactsDir, "source-view.json")
+			}
+
+			var view workspace.SourceView
+			if err := readJSONFile(sourceViewFile, &view); err != nil {
+				return cliErrorf(exitParse, "failed to load source view: %w", err)
+			}
+			var exec workspace.ExecutionReport
+			if err := readJSONFile(executionFile, &exec); err != nil {
+				return cliErrorf(exitParse, "failed to load execution report: %w", err)
+			}
+
+			result, err := workspace.OptimizeSuite(&view, &exec, workspace.OptimizeOptions{
+				Budget:         budget,
+				CoverageTarget: coverageTarget,
+			})
+			if err != nil {
+				return err
+			}
+			if result.Lines == 0 {
+				return fmt.Errorf("%s credits no covered lines to generated tests", sourceViewFile)
+			}
+
+			if outputDir != "" {
+				for _, t := range result.Kept {
+					if err := copyTestFile(filepath.Join(workDir, t.File), filepath.Join(outputDir, t.File)); err != nil {
+						return err
+					}
+				}
+				if reportFile == "" {
+					reportFile = filepath.Join(outputDir, "optimize-report.json")
+				}
+			}
+			data, _ := json.MarshalIndent(result, "", "  ")
+			if reportFile != "" {
+				if err := os.WriteFile(reportFile, append(data, '\n'), 0644); err != nil {
+					return fmt.Errorf("failed to write report: %w", err)
+				}
+			}
+
+			if jsonOut {
+				fmt.Println(string(data))
+				return nil
+			}
+			displayOptimization(result)
+			if outputDir != "" {
+				fmt.Printf("\n✅ Wrote %d test files to %s\n", len(result.Kept), outputDir)
+			}
+			if reportFile != "" {
+				fmt.Printf("Report: %s\n", reportFile)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&workDir, "dir", "d", ".", "Repository the test files are relative to")
+	cmd.Flags().StringVarP(&artifactsDir, "artifacts", "a", "artifacts", "Artifacts directory with execution.json and source-view.json")
+	cmd.Flags().StringVarP(&executionFile, "execution", "e", "", "Execution report JSON file (default: <artifacts>/execution.json)")
+	cmd.Flags().StringVar(&sourceViewFile, "source-view", "", "Source view JSON file (default: <artifacts>/source-view.json)")
+	cmd.Flags().DurationVar(&budget, "budget", 0, "Maximum runtime of the kept tests, e.g. 5m (0 for no budget)")
+	cmd.Flags().Float64Var(&coverageTarget, "coverage-target", 100, "Percent of the suite's covered lines to keep")
+	cmd.Flags().StringVarP(&outputDir, "output", "o", "", "Directory to copy the kept test files into")
+	cmd.Flags().StringVar(&reportFile, "report", "", "Report JSON file (default: <output>/optimize-report.json)")
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output as JSON")
+
+	return cmd
+}
+
+// readJSONFile decodes a JSON file into v
+func readJSONFile(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// copyTestFile copies a kept test file into the pruned suite
+func copyTestFile(src, dst string) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return fmt.Errorf("failed to read test file: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := os.WriteFile(dst, data, 0644); err != nil {
+		return fmt.Errorf("failed to write test file: %w", err)
+	}
+	return nil
+}
+
+func displayOptimization(result *workspace.SuiteOptimization) {
+	fmt.Printf("\n✂️  Suite Optimization\n")
+	fmt.Printf("=====================\n")
+	budget := "none"
+	if result.BudgetMs > 0 {
+		budget = formatMs(result.BudgetMs)
+	}
+	fmt.Printf("Budget:   %s\n", budget)
+	fmt.Printf("Runtime:  %s → %s\n", formatMs(result.RuntimeMs), formatMs(result.KeptRuntimeMs))
+	fmt.Printf("Tests:    %d of %d files kept\n", len(result.Kept), len(result.Kept)+len(result.Dropped))
+	fmt.Printf("Coverage: %d of %d lines kept (%.1f%%)\n", result.KeptLines, result.Lines, result.CoverageRetained)
+
+	if len(result.Kept) > 0 {
+		fmt.Printf("\nKept:\n")
+		for _, t := range result.Kept {
+			fmt.Printf("  + %s (%s, %d lines, %d only here)\n", t.File, formatTestRuntime(t), t.Lines, t.Unique)
+		}
+	}
+	if len(result.Dropped) > 0 {
+		fmt.Printf("\nDropped:\n")
+		for _, t := range result.Dropped {
+			lost := ""
+			if t.Unique > 0 {
+				lost = fmt.Sprintf(", loses %d lines", t.Unique)
+			}
+			fmt.Printf("  - %s (%s, %s%s)\n", t.File, formatTestRuntime(t), t.Reason, lost)
+		}
+	}
+}
+
+// formatTestRuntime formats a test file's runtime, marking estimates
+func formatTestRuntime(t workspace.OptimizedTest) string {
+	if t.Estimated {
+		return "~" + formatMs(t.DurationMs)
+	}
+	return formatMs(t.DurationMs)
+}
+
+// formatMs formats milliseconds as a duration
+func formatMs(ms int64) string {
+	return (time.Duration(ms) * time.Millisecond).String()
+}
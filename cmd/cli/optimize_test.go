@@ -0,0 +1,55 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/QTest-hq/qtest/internal/workspace"
+)
+
+func TestOptimizeCmd(t *testing.T) {
+	repo := t.TempDir()
+	artifacts := filepath.Join(repo, "artifacts")
+	os.MkdirAll(artifacts, 0755)
+	for _, name := range []string{"fast_test.go", "slow_test.go"} {
+		os.WriteFile(filepath.Join(repo, name), []byte("package calc\n"), 0644)
+	}
+	writeGateReport(t, artifacts, "source-view.json", workspace.SourceView{
+		Tests: []workspace.SourceViewTest{{File: "fast_test.go", Target: "Add"}, {File: "slow_test.go", Target: "Add"}},
+		Files: []workspace.SourceFileView{{Path: "calc.go", Lines: []workspace.SourceLine{
+			{Number: 3, CoveredBy: []int{0, 1}},
+			{Number: 4, CoveredBy: []int{1}},
+		}}},
+	})
+	writeGateReport(t, artifacts, "execution.json", workspace.ExecutionReport{Tests: []workspace.TestResult{
+		{Name: "TestAdd", File: "fast_test.go", DurationMs: 200},
+		{Name: "TestAddSlow", File: "slow_test.go", DurationMs: 90000},
+	}})
+	out := filepath.Join(t.TempDir(), "pruned")
+
+	cmd := optimizeCmd()
+	cmd.SetArgs([]string{"-d", repo, "-a", artifacts, "--budget", "1m", "-o", out, "--json"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(filepath.Join(out, "fast_test.go")); err != nil {
+		t.Errorf("fast_test.go not in the pruned suite: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(out, "slow_test.go")); !os.IsNotExist(err) {
+		t.Error("slow_test.go is over budget and should be dropped")
+	}
+	data, err := os.ReadFile(filepath.Join(out, "optimize-report.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var result workspace.SuiteOptimization
+	if err := json.Unmarshal(data, &result); err != nil {
+		t.Fatal(err)
+	}
+	if result.KeptLines != 1 || len(result.Dropped) != 1 || result.Dropped[0].Reason != workspace.DropOverBudget {
+		t.Errorf("report = %+v", result)
+	}
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// plainSymbols are the symbols the CLI's output uses, with what each
+// becomes in plain mode. Messages themselves aren't translated; this only
+// maps symbols. Status symbols get an ASCII tag; decorative
+// ones, with an empty form, are dropped along with the space after them.
+var plainSymbols = []struct {
+	symbol string
+	plain  string
+}{
+	// Status
+	{"✅", "[ok]"},
+	{"✓", "[ok]"},
+	{"❌", "[fail]"},
+	{"✗", "[fail]"},
+	{"✕", "[fail]"},
+	{"⚠", "[warn]"},
+	{"ℹ", "[info]"},
+	{"💡", "[tip]"},
+	{"🚨", "[alert]"},
+	{"⏳", "[wait]"},
+	{"⏱", "[time]"},
+	{"✎", "[edited]"},
+	{"↻", "[regenerated]"},
+	{"📈", "[up]"},
+	{"📉", "[down]"},
+
+	// Levels, from bad to good
+	{"🔴", "[red]"},
+	{"🟠", "[orange]"},
+	{"🟡", "[yellow]"},
+	{"🟢", "[green]"},
+	{"🔵", "[blue]"},
+	{"⚪", "[white]"},
+	{"⬜", "[ ]"},
+	{"●", "*"},
+
+	// Punctuation and box drawing
+	{"→", "->"},
+	{"↪", "->"},
+	{"›", ">"},
+	{"•", "*"},
+	{"×", "x"},
+	{"─", "-"},
+	{"═", "="},
+	{"│", "|"},
+
+	// Decoration
+	{"📊", ""}, {"📄", ""}, {"💾", ""}, {"🔍", ""}, {"📝", ""}, {"🎯", ""},
+	{"🧬", ""}, {"🚀", ""}, {"🗑", ""}, {"📦", ""}, {"📋", ""}, {"🔧", ""},
+	{"🔌", ""}, {"🔄", ""}, {"🔁", ""}, {"🔀", ""}, {"📥", ""}, {"🌐", ""},
+	{"🤖", ""}, {"🗄", ""}, {"🔤", ""}, {"🔗", ""}, {"🔎", ""}, {"📡", ""},
+	{"👋", ""}, {"👀", ""}, {"🐳", ""}, {"🐙", ""}, {"🏛", ""}, {"🎙", ""},
+	{"⚡", ""}, {"⚙", ""},
+}
+
+// plainReplacer rewrites output with the plain forms of plainSymbols
+var plainReplacer = newPlainReplacer()
+
+func newPlainReplacer() *strings.Replacer {
+	// Emoji variation selectors follow some symbols, as in ⚠️
+	pairs := []string{"\ufe0f", ""}
+	for _, s := range plainSymbols {
+		if s.plain == "" {
+			pairs = append(pairs, s.symbol+"\ufe0f  ", "", s.symbol+"\ufe0f ", "", s.symbol+"  ", "", s.symbol+" ", "")
+		}
+		pairs = append(pairs, s.symbol, s.plain)
+	}
+	return strings.NewReplacer(pairs...)
+}
+
+// plainText returns s with plainSymbols in their plain forms
+func plainText(s string) string {
+	return plainReplacer.Replace(s)
+}
+
+// plainWriter writes plain text to w. A multi-byte character split between
+// writes is held back until it is complete.
+type plainWriter struct {
+	w       io.Writer
+	pending []byte
+}
+
+func (p *plainWriter) Write(b []byte) (int, error) {
+	data := append(p.pending, b...)
+	cut := len(data)
+	for i := len(data) - 1; i >= 0 && i >= len(data)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(data[i]) {
+			if !utf8.FullRune(data[i:]) {
+				cut = i
+			}
+			break
+		}
+	}
+	p.pending = append([]byte(nil), data[cut:]...)
+	if _, err := io.WriteString(p.w, plainText(string(data[:cut]))); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+// Flush writes what was held back, as is
+func (p *plainWriter) Flush() error {
+	_, err := p.w.Write(p.pending)
+	p.pending = nil
+	return err
+}
+
+// plainOutput is the --plain flag
+var plainOutput bool
+
+// plainDefault decides plain mode without the flag: QTEST_PLAIN when set,
+// else plain for a dumb terminal or a locale that isn't UTF-8
+func plainDefault() bool {
+	if v := os.Getenv("QTEST_PLAIN"); v != "" {
+		plain, err := strconv.ParseBool(v)
+		return err == nil && plain
+	}
+	if os.Getenv("TERM") == "dumb" {
+		return true
+	}
+	for _, key := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if locale := os.Getenv(key); locale != "" {
+			locale = strings.ToLower(locale)
+			return !strings.Contains(locale, "utf-8") && !strings.Contains(locale, "utf8")
+		}
+	}
+	return false
+}
+
+// restoreOutput undoes startPlainOutput, once the command's output is
+// written
+var restoreOutput = func() {}
+
+// startPlainOutput routes stdout and stderr, and the log, through
+// plainWriters, so every command's output is plain without each printing
+// it differently
+func startPlainOutput() {
+	stdout, stderr := os.Stdout, os.Stderr
+	outW, outDone, err := pipeThrough(stdout)
+	if err != nil {
+		return
+	}
+	errW, errDone, err := pipeThrough(stderr)
+	if err != nil {
+		outW.Close()
+		<-outDone
+		return
+	}
+
+	os.Stdout, os.Stderr = outW, errW
+	log.Logger = log.Output(zerolog.ConsoleWriter{Out: errW, NoColor: true})
+	restoreOutput = func() {
+		outW.Close()
+		errW.Close()
+		<-outDone
+		<-errDone
+		os.Stdout, os.Stderr = stdout, stderr
+		restoreOutput = func() {}
+	}
+}
+
+// pipeThrough returns a pipe whose output is written plain to dst, and a
+// channel closed when the pipe has been drained after closing
+func pipeThrough(dst *os.File) (*os.File, chan struct{}, error) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, nil, err
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		pw := &plainWriter{w: dst}
+		io.Copy(pw, r)
+		pw.Flush()
+		r.Close()
+	}()
+	return w, done, nil
+}
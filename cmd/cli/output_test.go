@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPlainText(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"✅ Coverage 85.0% meets threshold", "[ok] Coverage 85.0% meets threshold"},
+		{"⚠️  Warning: no key", "[warn]  Warning: no key"},
+		{"📊 Average Mutation Score: 70.0%", "Average Mutation Score: 70.0%"},
+		{"⚙️  QTest Configuration", "QTest Configuration"},
+		{"   GET /users → handler", "   GET /users -> handler"},
+		{"─────", "-----"},
+		{"plain ascii", "plain ascii"},
+		{"Müller", "Müller"},
+	}
+
+	for _, tt := range tests {
+		if got := plainText(tt.in); got != tt.want {
+			t.Errorf("plainText(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestPlainWriter_SplitCharacter(t *testing.T) {
+	var out bytes.Buffer
+	pw := &plainWriter{w: &out}
+
+	// ✅ is three bytes; split it between writes
+	msg := []byte("✅ done\n")
+	if _, err := pw.Write(msg[:1]); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pw.Write(msg[1:]); err != nil {
+		t.Fatal(err)
+	}
+	pw.Flush()
+
+	if got := out.String(); got != "[ok] done\n" {
+		t.Errorf("output = %q, want %q", got, "[ok] done\n")
+	}
+}
+
+func TestPlainDefault(t *testing.T) {
+	tests := []struct {
+		name  string
+		plain string
+		term  string
+		lang  string
+		want  bool
+	}{
+		{"utf-8 locale", "", "xterm", "en_US.UTF-8", false},
+		{"no locale", "", "xterm", "", false},
+		{"posix locale", "", "xterm", "C", true},
+		{"dumb terminal", "", "dumb", "en_US.UTF-8", true},
+		{"forced on", "1", "xterm", "en_US.UTF-8", true},
+		{"forced off", "0", "dumb", "C", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("QTEST_PLAIN", tt.plain)
+			t.Setenv("TERM", tt.term)
+			t.Setenv("LC_ALL", "")
+			t.Setenv("LC_CTYPE", "")
+			t.Setenv("LANG", tt.lang)
+			if got := plainDefault(); got != tt.want {
+				t.Errorf("plainDefault() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/QTest-hq/qtest/internal/config"
+	"github.com/QTest-hq/qtest/internal/lineage"
 	"github.com/QTest-hq/qtest/internal/llm"
 	"github.com/QTest-hq/qtest/internal/specgen"
 	"github.com/QTest-hq/qtest/pkg/model"
@@ -23,15 +24,17 @@ func planCmd() *cobra.Command {
 
 	cmd.AddCommand(planGenerateCmd())
 	cmd.AddCommand(planShowCmd())
+	cmd.AddCommand(planExplainCmd())
 
 	return cmd
 }
 
 func planGenerateCmd() *cobra.Command {
 	var (
-		modelFile  string
-		outputFile string
-		maxTests   int
+		modelFile   string
+		outputFile  string
+		maxTests    int
+		lineagePath string
 	)
 
 	cmd := &cobra.Command{
@@ -53,7 +56,7 @@ Test intents are prioritized based on:
 
 			var sysModel model.SystemModel
 			if err := json.Unmarshal(data, &sysModel); err != nil {
-				return fmt.Errorf("failed to parse model: %w", err)
+				return cliErrorf(exitParse, "failed to parse model: %w", err)
 			}
 
 			fmt.Printf("📊 Loaded model: %s\n", sysModel.Repository)
@@ -114,6 +117,12 @@ Test intents are prioritized based on:
 				fmt.Printf("\n💾 Plan saved to: %s\n", outputFile)
 			}
 
+			if lineagePath != "" {
+				if err := updateLineage(lineagePath, func(g *lineage.Graph) { g.AddPlan(plan) }); err != nil {
+					return err
+				}
+			}
+
 			return nil
 		},
 	}
@@ -121,6 +130,7 @@ Test intents are prioritized based on:
 	cmd.Flags().StringVarP(&modelFile, "model", "m", "", "System model JSON file (required)")
 	cmd.Flags().StringVarP(&outputFile, "output", "o", "", "Output file for plan JSON")
 	cmd.Flags().IntVar(&maxTests, "max", 0, "Maximum number of test intents")
+	cmd.Flags().StringVar(&lineagePath, "lineage", "", "Record intents in this lineage graph file (e.g. "+lineage.DefaultPath+")")
 	cmd.MarkFlagRequired("model")
 
 	return cmd
@@ -140,7 +150,7 @@ func planShowCmd() *cobra.Command {
 
 			var plan model.TestPlan
 			if err := json.Unmarshal(data, &plan); err != nil {
-				return fmt.Errorf("failed to parse plan: %w", err)
+				return cliErrorf(exitParse, "failed to parse plan: %w", err)
 			}
 
 			fmt.Printf("📋 Test Plan: %s\n\n", plan.Repository)
@@ -151,6 +161,7 @@ func planShowCmd() *cobra.Command {
 			fmt.Printf("   API:   %d\n", stats["api"])
 			fmt.Printf("   Unit:  %d\n", stats["unit"])
 			fmt.Printf("   E2E:   %d\n", stats["e2e"])
+			fmt.Printf("   Priority: %d high, %d medium, %d low\n", stats["high"], stats["medium"], stats["low"])
 
 			fmt.Println("\nAll intents:")
 			for _, intent := range plan.Intents {
@@ -167,13 +178,106 @@ func planShowCmd() *cobra.Command {
 	return cmd
 }
 
+func planExplainCmd() *cobra.Command {
+	var planFile string
+
+	cmd := &cobra.Command{
+		Use:   "explain <intent-id>",
+		Short: "Explain why an intent got its priority",
+		Long: `Shows the factors the planner weighed for a test intent: its risk score
+and how complexity, centrality and churn contributed to it, whether existing
+tests cover the target, and the rule that decided its priority.
+
+The intent can be given by ID, lineage ID, or any part of its ID that
+matches only one intent.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			data, err := os.ReadFile(planFile)
+			if err != nil {
+				return fmt.Errorf("failed to read plan: %w", err)
+			}
+
+			var plan model.TestPlan
+			if err := json.Unmarshal(data, &plan); err != nil {
+				return cliErrorf(exitParse, "failed to parse plan: %w", err)
+			}
+
+			intent, err := plan.FindIntent(args[0])
+			if err != nil {
+				return err
+			}
+
+			fmt.Print(explainIntent(&plan, intent))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&planFile, "file", "f", "", "Plan JSON file (required)")
+	cmd.MarkFlagRequired("file")
+
+	return cmd
+}
+
+// explainIntent renders the factors behind an intent's priority
+func explainIntent(plan *model.TestPlan, intent *model.TestIntent) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "🔎 %s\n", intent.ID)
+	fmt.Fprintf(&b, "   Level:    %s (%s %s)\n", intent.Level, intent.TargetKind, intent.TargetID)
+	fmt.Fprintf(&b, "   Reason:   %s\n", intent.Reason)
+	if intent.LineageID != "" {
+		fmt.Fprintf(&b, "   Lineage:  %s\n", intent.LineageID)
+	}
+	if len(intent.Tags) > 0 {
+		fmt.Fprintf(&b, "   Tags:     %s\n", strings.Join(intent.Tags, ", "))
+	}
+
+	r := intent.Rationale
+	if r == nil {
+		fmt.Fprintf(&b, "   Priority: %s\n\n", intent.Priority)
+		b.WriteString("This plan has no rationale for the intent; regenerate it with `qtest plan generate`.\n")
+		return b.String()
+	}
+	fmt.Fprintf(&b, "   Priority: %s (rank %d of %d)\n", intent.Priority, r.Rank, len(plan.Intents))
+
+	scoring := plan.Scoring
+	if scoring == nil {
+		cfg := model.DefaultPlannerConfig()
+		scoring = &model.PlanScoring{
+			ComplexityWeight:    model.RiskWeightComplexity,
+			CentralityWeight:    model.RiskWeightCentrality,
+			ChurnWeight:         model.RiskWeightChurn,
+			HighRiskThreshold:   cfg.HighRiskThreshold,
+			MediumRiskThreshold: cfg.MediumRiskThreshold,
+		}
+	}
+
+	b.WriteString("\nRisk factors:\n")
+	fmt.Fprintf(&b, "   Complexity  %.2f × %.1f = %.2f  (%d lines)\n",
+		r.Complexity, scoring.ComplexityWeight, r.Complexity*scoring.ComplexityWeight, r.LOC)
+	fmt.Fprintf(&b, "   Centrality  %.2f × %.1f = %.2f  (callers: %d)\n",
+		r.Centrality, scoring.CentralityWeight, r.Centrality*scoring.CentralityWeight, r.Callers)
+	fmt.Fprintf(&b, "   Churn       %.2f × %.1f = %.2f\n",
+		r.Churn, scoring.ChurnWeight, r.Churn*scoring.ChurnWeight)
+	fmt.Fprintf(&b, "   Risk score  %.2f  (high >= %.2f, medium >= %.2f)\n",
+		r.RiskScore, scoring.HighRiskThreshold, scoring.MediumRiskThreshold)
+	if r.CoverageGap {
+		b.WriteString("   Coverage    no existing tests\n")
+	} else {
+		b.WriteString("   Coverage    covered by existing tests\n")
+	}
+
+	fmt.Fprintf(&b, "\nPriority rule: %s\n", r.Rule)
+	return b.String()
+}
+
 func generateSpecsCmd() *cobra.Command {
 	var (
-		modelFile  string
-		planFile   string
-		outputFile string
-		tier       string
-		maxSpecs   int
+		modelFile   string
+		planFile    string
+		outputFile  string
+		tier        string
+		maxSpecs    int
+		lineagePath string
 	)
 
 	cmd := &cobra.Command{
@@ -193,7 +297,7 @@ This requires an LLM (Ollama or Anthropic) to be available.`,
 			}
 			var sysModel model.SystemModel
 			if err := json.Unmarshal(modelData, &sysModel); err != nil {
-				return fmt.Errorf("failed to parse model: %w", err)
+				return cliErrorf(exitParse, "failed to parse model: %w", err)
 			}
 
 			// Load plan
@@ -203,7 +307,7 @@ This requires an LLM (Ollama or Anthropic) to be available.`,
 			}
 			var plan model.TestPlan
 			if err := json.Unmarshal(planData, &plan); err != nil {
-				return fmt.Errorf("failed to parse plan: %w", err)
+				return cliErrorf(exitParse, "failed to parse plan: %w", err)
 			}
 
 			fmt.Printf("📊 Model: %s\n", sysModel.Repository)
@@ -212,16 +316,16 @@ This requires an LLM (Ollama or Anthropic) to be available.`,
 			// Load config and create LLM router
 			cfg, err := config.Load()
 			if err != nil {
-				return fmt.Errorf("failed to load config: %w", err)
+				return cliErrorf(exitConfig, "failed to load config: %w", err)
 			}
 
 			router, err := llm.NewRouter(cfg)
 			if err != nil {
-				return fmt.Errorf("failed to create LLM router: %w", err)
+				return cliErrorf(exitConfig, "failed to create LLM router: %w", err)
 			}
 
 			if err := router.HealthCheck(); err != nil {
-				return fmt.Errorf("LLM not available: %w\nMake sure Ollama is running", err)
+				return cliErrorf(exitLLM, "LLM not available: %w\nMake sure Ollama is running", err)
 			}
 
 			// Parse tier
@@ -283,6 +387,16 @@ This requires an LLM (Ollama or Anthropic) to be available.`,
 				fmt.Printf("\n💾 Specs saved to: %s\n", outputFile)
 			}
 
+			if lineagePath != "" {
+				err := updateLineage(lineagePath, func(g *lineage.Graph) {
+					g.AddPlan(&plan)
+					g.AddSpecs(specSet)
+				})
+				if err != nil {
+					return err
+				}
+			}
+
 			return nil
 		},
 	}
@@ -292,6 +406,7 @@ This requires an LLM (Ollama or Anthropic) to be available.`,
 	cmd.Flags().StringVarP(&outputFile, "output", "o", "", "Output file for specs JSON")
 	cmd.Flags().StringVarP(&tier, "tier", "t", "1", "LLM tier (1=fast, 2=balanced, 3=thorough)")
 	cmd.Flags().IntVar(&maxSpecs, "max", 0, "Maximum number of specs to generate")
+	cmd.Flags().StringVar(&lineagePath, "lineage", "", "Record intent -> spec lineage in this graph file (e.g. "+lineage.DefaultPath+")")
 	cmd.MarkFlagRequired("model")
 	cmd.MarkFlagRequired("plan")
 
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/QTest-hq/qtest/pkg/model"
+)
+
+func TestExplainIntent(t *testing.T) {
+	plan, err := model.NewPlanner(model.DefaultPlannerConfig()).Plan(&model.SystemModel{
+		Functions:  []model.Function{{ID: "fn1", Name: "Parse", Exported: true, LOC: 30}},
+		CallGraph:  []model.CallEdge{{Caller: "fn2", Callee: "fn1"}},
+		RiskScores: map[string]model.RiskScore{"fn1": {Score: 0.39, Complexity: 0.6, Centrality: 0.3}},
+	})
+	if err != nil {
+		t.Fatalf("Plan() error: %v", err)
+	}
+
+	out := explainIntent(plan, &plan.Intents[0])
+	for _, want := range []string{
+		"Priority: low (rank 1 of 1)",
+		"Complexity  0.60 × 0.5 = 0.30  (30 lines)",
+		"Centrality  0.30 × 0.3 = 0.09  (callers: 1)",
+		"Risk score  0.39  (high >= 0.70, medium >= 0.40)",
+		"Coverage    no existing tests",
+		"Priority rule: risk 0.39 < medium threshold 0.40",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("explainIntent() missing %q:\n%s", want, out)
+		}
+	}
+
+	// Plans saved before rationales were recorded say how to get one
+	old := model.TestIntent{ID: "intent:unit:fn1", Priority: "high"}
+	if out := explainIntent(&model.TestPlan{}, &old); !strings.Contains(out, "qtest plan generate") {
+		t.Errorf("explainIntent() without a rationale = %q", out)
+	}
+}
@@ -0,0 +1,243 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/QTest-hq/qtest/internal/jobs"
+	"github.com/spf13/cobra"
+)
+
+var (
+	policyOrg  string
+	policyRepo string
+	apiToken   string
+)
+
+// policyCmd returns the policy parent command
+func policyCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "policy",
+		Short: "Manage organization and repository pipeline policies",
+		Long: `Pipeline policies set the LLM tier, coverage target, allowed LLM providers
+and PR options for pipelines started through the API server.
+
+An organization's policy is the default for all of its repositories. A
+repository's policy overrides it field by field, and options given when a
+pipeline starts override both. Organization policies need an API session
+token (--token or QTEST_API_TOKEN).`,
+	}
+
+	cmd.PersistentFlags().StringVar(&apiURL, "api-url", "http://localhost:8080", "API server URL")
+	cmd.PersistentFlags().StringVar(&policyOrg, "org", "", "Organization ID")
+	cmd.PersistentFlags().StringVar(&policyRepo, "repo", "", "Repository ID")
+	cmd.PersistentFlags().StringVar(&apiToken, "token", os.Getenv("QTEST_API_TOKEN"), "API key or session token")
+
+	cmd.AddCommand(policyGetCmd())
+	cmd.AddCommand(policySetCmd())
+
+	return cmd
+}
+
+// policyGetCmd shows a policy
+func policyGetCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "get",
+		Short: "Show a policy",
+		Long: `Show an organization's default policy, or a repository's overrides and the
+effective policy its pipelines use.
+
+Examples:
+  qtest policy get --org 6f1c...
+  qtest policy get --repo 2b9e...`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			endpoint, err := policyEndpoint()
+			if err != nil {
+				return err
+			}
+			resp, err := apiRequest(http.MethodGet, endpoint, nil)
+			if err != nil {
+				return err
+			}
+
+			var out bytes.Buffer
+			if err := json.Indent(&out, resp, "", "  "); err != nil {
+				return fmt.Errorf("failed to parse response: %w", err)
+			}
+			fmt.Println(out.String())
+			return nil
+		},
+	}
+}
+
+// policySetCmd changes the fields of a policy given as flags
+func policySetCmd() *cobra.Command {
+	var (
+		tier      int
+		coverage  float64
+		providers []string
+		prOpts    jobs.PROptions
+		replace   bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "set",
+		Short: "Change a policy",
+		Long: `Change the policy fields given as flags, keeping the others. PR flags
+replace the policy's PR options as a whole. With --replace fields not given
+are unset, so a repository inherits them from its organization.
+
+Examples:
+  # Default every repository of an organization to tier 2 and local models
+  qtest policy set --org 6f1c... --tier 2 --provider ollama
+
+  # PR options for repositories that open PRs
+  qtest policy set --org 6f1c... --label qtest --reviewer my-org/qa
+
+  # A repository that needs a higher coverage target
+  qtest policy set --repo 2b9e... --coverage 90`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			endpoint, err := policyEndpoint()
+			if err != nil {
+				return err
+			}
+
+			policy := &jobs.Policy{}
+			if !replace {
+				if policy, err = currentPolicy(endpoint); err != nil {
+					return err
+				}
+			}
+
+			flags := cmd.Flags()
+			if flags.Changed("tier") {
+				policy.LLMTier = tier
+			}
+			if flags.Changed("coverage") {
+				policy.CoverageTarget = coverage
+			}
+			if flags.Changed("provider") {
+				policy.Providers = providers
+			}
+			if flags.Changed("draft") || flags.Changed("label") || flags.Changed("assignee") ||
+				flags.Changed("reviewer") || flags.Changed("auto-merge") || flags.Changed("merge-method") ||
+				flags.Changed("commit-strategy") {
+				policy.PR = &prOpts
+			}
+			if err := policy.Validate(); err != nil {
+				return err
+			}
+
+			if _, err := apiRequest(http.MethodPut, endpoint, policy); err != nil {
+				return err
+			}
+			fmt.Println("Policy updated.")
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&tier, "tier", 0, "Default LLM tier (1=fast, 2=balanced, 3=thorough)")
+	cmd.Flags().Float64Var(&coverage, "coverage", 0, "Coverage target percentage")
+	cmd.Flags().StringSliceVar(&providers, "provider", nil, "LLM provider code may be sent to: ollama, anthropic, openai (repeatable)")
+	cmd.Flags().BoolVar(&prOpts.Draft, "draft", false, "Open PRs as drafts")
+	cmd.Flags().StringSliceVar(&prOpts.Labels, "label", nil, "Label to add to PRs (repeatable)")
+	cmd.Flags().StringSliceVar(&prOpts.Assignees, "assignee", nil, "User to assign to PRs (repeatable)")
+	cmd.Flags().StringSliceVar(&prOpts.Reviewers, "reviewer", nil, "Reviewer login or org/team (repeatable)")
+	cmd.Flags().BoolVar(&prOpts.AutoMerge, "auto-merge", false, "Merge PRs once checks pass (only if tests passed)")
+	cmd.Flags().StringVar(&prOpts.MergeMethod, "merge-method", "", "Auto-merge method: merge, squash or rebase")
+	cmd.Flags().StringVar(&prOpts.CommitStrategy, "commit-strategy", "", "How to commit tests to PR branches: single or per-package")
+	cmd.Flags().BoolVar(&replace, "replace", false, "Replace the policy instead of changing it")
+
+	return cmd
+}
+
+// policyEndpoint is the API path of the policy --org or --repo names
+func policyEndpoint() (string, error) {
+	switch {
+	case policyOrg != "" && policyRepo != "":
+		return "", fmt.Errorf("use either --org or --repo, not both")
+	case policyOrg != "":
+		return apiURL + "/api/v1/organizations/" + policyOrg + "/policy", nil
+	case policyRepo != "":
+		return apiURL + "/api/v1/repos/" + policyRepo + "/policy", nil
+	default:
+		return "", fmt.Errorf("--org or --repo is required")
+	}
+}
+
+// currentPolicy fetches the policy at endpoint; for a repository that's its
+// own overrides, not the effective policy
+func currentPolicy(endpoint string) (*jobs.Policy, error) {
+	resp, err := apiRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if policyRepo != "" {
+		var repoPolicy struct {
+			Repository *jobs.Policy `json:"repository"`
+		}
+		if err := json.Unmarshal(resp, &repoPolicy); err != nil {
+			return nil, fmt.Errorf("failed to parse response: %w", err)
+		}
+		if repoPolicy.Repository == nil {
+			return &jobs.Policy{}, nil
+		}
+		return repoPolicy.Repository, nil
+	}
+	return jobs.ParsePolicy(resp)
+}
+
+// apiRequest calls the API with the session token, if any
+func apiRequest(method, url string, data interface{}) ([]byte, error) {
+	client := &http.Client{Timeout: 30 * time.Second}
+
+	var body io.Reader
+	if data != nil {
+		jsonData, err := json.Marshal(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode request: %w", err)
+		}
+		body = bytes.NewReader(jsonData)
+	}
+
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if data != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token := strings.TrimSpace(apiToken); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode >= 400 {
+		var errResp map[string]string
+		if json.Unmarshal(respBody, &errResp) == nil {
+			if msg, ok := errResp["error"]; ok {
+				return nil, fmt.Errorf("API error: %s", msg)
+			}
+		}
+		return nil, fmt.Errorf("API error: %s", resp.Status)
+	}
+
+	return respBody, nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/QTest-hq/qtest/internal/jobs"
+)
+
+func TestPolicySet_KeepsOtherFields(t *testing.T) {
+	var put jobs.Policy
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/repos/repo-1/policy" {
+			http.NotFound(w, r)
+			return
+		}
+		auth = r.Header.Get("Authorization")
+		switch r.Method {
+		case http.MethodGet:
+			io.WriteString(w, `{"repository": {"llm_tier": 2, "providers": ["ollama"]}, "effective": {"llm_tier": 2, "coverage_target": 80}}`)
+		case http.MethodPut:
+			json.NewDecoder(r.Body).Decode(&put)
+			io.WriteString(w, `{}`)
+		}
+	}))
+	defer server.Close()
+
+	cmd := policyCmd()
+	cmd.SetArgs([]string{"set", "--api-url", server.URL, "--repo", "repo-1", "--token", "abc", "--coverage", "90"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("policy set: %v", err)
+	}
+
+	// The repository's own fields are kept; inherited ones aren't copied in
+	if put.LLMTier != 2 || put.CoverageTarget != 90 || len(put.Providers) != 1 || put.PR != nil {
+		t.Errorf("PUT policy = %+v", put)
+	}
+	if auth != "Bearer abc" {
+		t.Errorf("Authorization = %q", auth)
+	}
+}
+
+func TestPolicyEndpoint(t *testing.T) {
+	defer func() { policyOrg, policyRepo, apiURL = "", "", "" }()
+	apiURL = "http://api"
+
+	policyOrg, policyRepo = "org-1", ""
+	if got, _ := policyEndpoint(); got != "http://api/api/v1/organizations/org-1/policy" {
+		t.Errorf("policyEndpoint() = %q", got)
+	}
+	policyOrg, policyRepo = "", ""
+	if _, err := policyEndpoint(); err == nil {
+		t.Error("policyEndpoint() accepted neither --org nor --repo")
+	}
+	policyOrg, policyRepo = "org-1", "repo-1"
+	if _, err := policyEndpoint(); err == nil {
+		t.Error("policyEndpoint() accepted both --org and --repo")
+	}
+}
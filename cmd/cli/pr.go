@@ -6,8 +6,11 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/QTest-hq/qtest/internal/config"
 	"github.com/QTest-hq/qtest/internal/github"
+	"github.com/QTest-hq/qtest/internal/jobs"
 	"github.com/spf13/cobra"
 )
 
@@ -34,6 +37,7 @@ func prCreateCmd() *cobra.Command {
 		testDir   string
 		draft     bool
 		token     string
+		settings  github.PRSettings
 	)
 
 	cmd := &cobra.Command{
@@ -51,6 +55,26 @@ func prCreateCmd() *cobra.Command {
 				return fmt.Errorf("GitHub token required. Set GITHUB_TOKEN env var or use --token flag")
 			}
 
+			if err := (&jobs.PROptions{
+				Draft:       draft,
+				Labels:      settings.Labels,
+				Assignees:   settings.Assignees,
+				Reviewers:   settings.Reviewers,
+				AutoMerge:   settings.AutoMerge,
+				MergeMethod: settings.MergeMethod,
+			}).Validate(); err != nil {
+				return err
+			}
+
+			// Check the PR body template before committing anything
+			project, err := config.LoadProjectConfig(".")
+			if err != nil {
+				return cliErrorf(exitConfig, "failed to load project config: %w", err)
+			}
+			if err := github.ValidatePRBodyTemplate(project.PR.BodyTemplate); err != nil {
+				return err
+			}
+
 			// Validate owner and repo
 			if owner == "" || repo == "" {
 				// Try to detect from git remote
@@ -160,13 +184,16 @@ func prCreateCmd() *cobra.Command {
 				title = fmt.Sprintf("Add %d generated tests", len(committedFiles))
 			}
 
-			// Generate PR body
-			body := github.GeneratePRBody(github.PRTemplate{
+			// Generate PR body, with the template in .qtest.yaml if there is one
+			body, err := github.RenderPRBody(github.PRTemplate{
 				TestCount: len(committedFiles),
 				Files:     committedFiles,
 				Framework: detectTestFramework(committedFiles),
 				Language:  detectTestLanguage(committedFiles),
-			})
+			}, project.PR.BodyTemplate)
+			if err != nil {
+				return err
+			}
 
 			// Create PR
 			fmt.Printf("\nCreating pull request...\n")
@@ -188,6 +215,18 @@ func prCreateCmd() *cobra.Command {
 			fmt.Printf("   PR #%d: %s\n", pr.Number, pr.Title)
 			fmt.Printf("   URL: %s\n", pr.HTMLURL)
 
+			if err := prService.Configure(ctx, owner, repo, pr, settings); err != nil {
+				fmt.Printf("   Warning: %v\n", err)
+			} else if settings.AutoMerge {
+				fmt.Printf("   Auto-merge enabled\n")
+			}
+
+			if stats := prService.RateLimitStats(); stats.Retries > 0 {
+				fmt.Printf("   GitHub API: %d requests, %d retried, waited %s for rate limits (%d/%d remaining)\n",
+					stats.Requests, stats.Retries, stats.Waited.Round(time.Second),
+					stats.RateLimit.Remaining, stats.RateLimit.Limit)
+			}
+
 			return nil
 		},
 	}
@@ -200,6 +239,11 @@ func prCreateCmd() *cobra.Command {
 	cmd.Flags().StringSliceVarP(&testFiles, "files", "f", nil, "Test files to include")
 	cmd.Flags().StringVarP(&testDir, "dir", "d", "", "Directory containing test files")
 	cmd.Flags().BoolVar(&draft, "draft", false, "Create as draft PR")
+	cmd.Flags().StringSliceVar(&settings.Labels, "label", nil, "Label to add (repeatable)")
+	cmd.Flags().StringSliceVar(&settings.Assignees, "assignee", nil, "User to assign (repeatable)")
+	cmd.Flags().StringSliceVar(&settings.Reviewers, "reviewer", nil, "Reviewer login or org/team (repeatable)")
+	cmd.Flags().BoolVar(&settings.AutoMerge, "auto-merge", false, "Merge once required checks pass")
+	cmd.Flags().StringVar(&settings.MergeMethod, "merge-method", "", "Auto-merge method: merge, squash or rebase")
 	cmd.Flags().StringVar(&token, "token", "", "GitHub token (or set GITHUB_TOKEN)")
 
 	return cmd
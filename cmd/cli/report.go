@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/QTest-hq/qtest/internal/reporting"
+	"github.com/QTest-hq/qtest/internal/workspace"
+	"github.com/QTest-hq/qtest/pkg/model"
+	"github.com/spf13/cobra"
+)
+
+func reportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "report",
+		Short: "Export test execution results to reporting tools",
+		Long: `Convert QTest execution reports (artifacts/execution.json) into formats
+consumed by external test-reporting stacks.`,
+	}
+
+	cmd.AddCommand(reportAllureCmd())
+	cmd.AddCommand(reportPortalCmd())
+
+	return cmd
+}
+
+func reportAllureCmd() *cobra.Command {
+	var (
+		executionFile string
+		specsFile     string
+		outputDir     string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "allure",
+		Short: "Write Allure result files from an execution report",
+		Long: `Writes one <uuid>-result.json per test into an allure-results directory.
+
+Example:
+  qtest report allure -e artifacts/execution.json -s specs.json -o allure-results
+  allure generate allure-results`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			report, specs, err := loadReportInputs(executionFile, specsFile)
+			if err != nil {
+				return err
+			}
+
+			results := reporting.ToAllureResults(report, specs)
+			if err := reporting.WriteAllureResults(outputDir, results); err != nil {
+				return err
+			}
+
+			fmt.Printf("✅ Wrote %d Allure results to %s\n", len(results), outputDir)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&executionFile, "execution", "e", "", "Execution report JSON file (required)")
+	cmd.Flags().StringVarP(&specsFile, "specs", "s", "", "Test specifications JSON file for labels")
+	cmd.Flags().StringVarP(&outputDir, "output", "o", "allure-results", "Allure results directory")
+	cmd.MarkFlagRequired("execution")
+
+	return cmd
+}
+
+func reportPortalCmd() *cobra.Command {
+	var (
+		executionFile string
+		specsFile     string
+		endpoint      string
+		project       string
+		launchName    string
+		outputFile    string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "reportportal",
+		Short: "Publish an execution report as a ReportPortal launch",
+		Long: `Publishes results to ReportPortal, or writes the launch as JSON with --output.
+
+The API token is read from RP_API_KEY.
+
+Example:
+  RP_API_KEY=... qtest report reportportal -e execution.json --endpoint https://rp.example.com --project qa`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			report, specs, err := loadReportInputs(executionFile, specsFile)
+			if err != nil {
+				return err
+			}
+
+			launch := reporting.ToReportPortalLaunch(launchName, report, specs)
+
+			if outputFile != "" {
+				data, err := json.MarshalIndent(launch, "", "  ")
+				if err != nil {
+					return fmt.Errorf("failed to marshal launch: %w", err)
+				}
+				if err := os.WriteFile(outputFile, data, 0644); err != nil {
+					return fmt.Errorf("failed to write launch: %w", err)
+				}
+				fmt.Printf("✅ Wrote ReportPortal launch (%d items) to %s\n", len(launch.Items), outputFile)
+				return nil
+			}
+
+			token := os.Getenv("RP_API_KEY")
+			if endpoint == "" || project == "" || token == "" {
+				return fmt.Errorf("--endpoint, --project and RP_API_KEY are required to publish (or use --output)")
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+			defer cancel()
+
+			client := reporting.NewReportPortalClient(endpoint, project, token)
+			launchID, err := client.Publish(ctx, launch)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("✅ Published launch %s (%d items) to %s\n", launchID, len(launch.Items), endpoint)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&executionFile, "execution", "e", "", "Execution report JSON file (required)")
+	cmd.Flags().StringVarP(&specsFile, "specs", "s", "", "Test specifications JSON file for attributes")
+	cmd.Flags().StringVar(&endpoint, "endpoint", "", "ReportPortal base URL")
+	cmd.Flags().StringVar(&project, "project", "", "ReportPortal project name")
+	cmd.Flags().StringVar(&launchName, "launch", "QTest generated tests", "Launch name")
+	cmd.Flags().StringVarP(&outputFile, "output", "o", "", "Write launch JSON instead of publishing")
+	cmd.MarkFlagRequired("execution")
+
+	return cmd
+}
+
+// loadReportInputs reads an execution report and, optionally, the spec set
+// used to label its results
+func loadReportInputs(executionFile, specsFile string) (*workspace.ExecutionReport, reporting.SpecIndex, error) {
+	data, err := os.ReadFile(executionFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read execution report: %w", err)
+	}
+
+	var report workspace.ExecutionReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, nil, cliErrorf(exitParse, "failed to parse execution report: %w", err)
+	}
+
+	if specsFile == "" {
+		return &report, reporting.NewSpecIndex(nil), nil
+	}
+
+	data, err = os.ReadFile(specsFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read specs: %w", err)
+	}
+
+	var specSet model.TestSpecSet
+	if err := json.Unmarshal(data, &specSet); err != nil {
+		return nil, nil, cliErrorf(exitParse, "failed to parse specs: %w", err)
+	}
+
+	return &report, reporting.NewSpecIndex(&specSet), nil
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/QTest-hq/qtest/internal/config"
+	"github.com/QTest-hq/qtest/internal/llm"
+	"github.com/QTest-hq/qtest/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+func revalidateCmd() *cobra.Command {
+	var (
+		regenerate bool
+		tier       int
+		commit     bool
+		remodel    bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "revalidate <workspace-id>",
+		Short: "Re-run accepted generated tests on the current HEAD",
+		Long: `Re-runs every previously accepted test in a workspace against the
+current checkout, e.g. after a dependency upgrade or refactor, and reports:
+
+  passed       still green
+  broken       fails, but its target function still exists
+  orphaned     its target function no longer exists
+  regenerated  was broken and has been regenerated (--regenerate)
+
+With --remodel, the checkout is re-parsed first so tests of renamed or moved
+functions are re-linked (and their references updated) instead of orphaned.
+With --regenerate, broken tests are regenerated for the same target so the
+original intent is preserved. Results are saved to artifacts/revalidation.json.
+Exits non-zero while broken or orphaned tests remain.
+
+Example:
+  qtest revalidate ws-abc123
+  qtest revalidate ws-abc123 --remodel --regenerate --commit`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ws, err := workspace.LoadByID(args[0], nil)
+			if err != nil {
+				return fmt.Errorf("workspace not found: %w", err)
+			}
+
+			cfg, err := config.Load()
+			if err != nil {
+				return cliErrorf(exitConfig, "failed to load config: %w", err)
+			}
+
+			// Only regeneration needs an LLM
+			var router *llm.Router
+			if regenerate {
+				router, err = llm.NewRouter(cfg)
+				if err != nil {
+					return cliErrorf(exitConfig, "failed to create LLM router: %w", err)
+				}
+				if err := router.HealthCheck(); err != nil {
+					return cliErrorf(exitLLM, "LLM not available: %w\nMake sure Ollama is running", err)
+				}
+			}
+
+			runCfg := workspace.DefaultRunConfig()
+			runCfg.Tier = llm.Tier(tier)
+			runner := workspace.NewRunner(ws, router, cfg.GitHubToken, runCfg)
+
+			ctx := context.Background()
+			var renames []workspace.Rename
+			if remodel {
+				renames, err = runner.Remodel(ctx)
+				if err != nil {
+					return fmt.Errorf("re-modeling failed: %w", err)
+				}
+				printRenames(renames)
+			}
+
+			fmt.Printf("🔁 Revalidating accepted tests in %s\n\n", ws.Name)
+
+			report, err := workspace.NewRevalidator(runner, regenerate).Run(ctx)
+			if err != nil {
+				return err
+			}
+
+			icons := map[workspace.RevalidationStatus]string{
+				workspace.RevalidationPassed:      "✓",
+				workspace.RevalidationBroken:      "✗",
+				workspace.RevalidationOrphaned:    "?",
+				workspace.RevalidationRegenerated: "↻",
+			}
+			for _, r := range report.Results {
+				fmt.Printf("  %s %-12s %s (%s)\n", icons[r.Status], r.Status, r.Target, r.TestFile)
+				if r.Error != "" && r.Status != workspace.RevalidationPassed {
+					fmt.Printf("      %s\n", r.Error)
+				}
+			}
+
+			s := report.Summary
+			fmt.Println()
+			fmt.Println(strings.Repeat("─", 40))
+			fmt.Printf("Total: %d  Passed: %d  Broken: %d  Orphaned: %d  Regenerated: %d\n",
+				s.Total, s.Passed, s.Broken, s.Orphaned, s.Regenerated)
+
+			if commit && (s.Regenerated > 0 || len(renames) > 0) {
+				sha, err := workspace.NewGitManager(ws, cfg.GitHubToken).
+					CommitAll(fmt.Sprintf("Revalidate tests: %d re-linked, %d regenerated", len(renames), s.Regenerated))
+				if err != nil {
+					return fmt.Errorf("failed to commit tests: %w", err)
+				}
+				if sha != "" {
+					fmt.Printf("📦 Committed updated tests: %s\n", sha[:8])
+				}
+			}
+
+			if s.Broken+s.Orphaned > 0 {
+				return fmt.Errorf("%d broken and %d orphaned test(s)", s.Broken, s.Orphaned)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&regenerate, "regenerate", false, "Regenerate broken tests for their original targets")
+	cmd.Flags().IntVarP(&tier, "tier", "t", 2, "LLM tier for regeneration (1=fast, 2=balanced, 3=thorough)")
+	cmd.Flags().BoolVar(&commit, "commit", false, "Commit re-linked and regenerated tests")
+	cmd.Flags().BoolVar(&remodel, "remodel", false, "Re-parse first and re-link tests of renamed or moved functions")
+
+	return cmd
+}
@@ -0,0 +1,377 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/QTest-hq/qtest/internal/jobs"
+	"github.com/spf13/cobra"
+)
+
+var scheduleRepo string
+
+// scheduleOptions are the pipeline options of a schedule, as the API
+// takes them
+type scheduleOptions struct {
+	Branch      string          `json:"branch,omitempty"`
+	MaxTests    int             `json:"max_tests,omitempty"`
+	LLMTier     int             `json:"llm_tier,omitempty"`
+	RunMutation bool            `json:"run_mutation,omitempty"`
+	CreatePR    bool            `json:"create_pr,omitempty"`
+	PR          *jobs.PROptions `json:"pr,omitempty"`
+	Lane        string          `json:"lane,omitempty"`
+}
+
+// scheduleRequest creates or replaces a schedule
+type scheduleRequest struct {
+	Cron     string          `json:"cron"`
+	Timezone string          `json:"timezone,omitempty"`
+	Enabled  *bool           `json:"enabled,omitempty"`
+	Options  scheduleOptions `json:"options"`
+}
+
+// scheduleResponse is a schedule as the API returns it
+type scheduleResponse struct {
+	ID          string          `json:"id"`
+	Cron        string          `json:"cron"`
+	Timezone    string          `json:"timezone"`
+	Enabled     bool            `json:"enabled"`
+	Options     scheduleOptions `json:"options"`
+	NextRunAt   string          `json:"next_run_at"`
+	LastRunAt   *string         `json:"last_run_at,omitempty"`
+	LastJobID   *string         `json:"last_job_id,omitempty"`
+	LastOutcome *string         `json:"last_outcome,omitempty"`
+	LastError   *string         `json:"last_error,omitempty"`
+}
+
+// scheduleCmd returns the schedule parent command
+func scheduleCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "schedule",
+		Aliases: []string{"schedules"},
+		Short:   "Manage recurring pipeline runs of a repository",
+		Long: `Schedules re-run a repository's pipeline on a cron expression, such as
+nightly or weekly, through the API server.
+
+Scheduled runs only generate tests for functions that don't have a
+generated test yet, so a PR is only opened when new untested code turned
+up. A run is skipped while the repository's previous pipeline is still
+going. Runs go in the batch lane unless --lane says otherwise.`,
+	}
+
+	cmd.PersistentFlags().StringVar(&apiURL, "api-url", "http://localhost:8080", "API server URL")
+	cmd.PersistentFlags().StringVar(&scheduleRepo, "repo", "", "Repository ID (required)")
+	cmd.PersistentFlags().StringVar(&apiToken, "token", os.Getenv("QTEST_API_TOKEN"), "API key or session token")
+	cmd.PersistentFlags().BoolVar(&jsonOutput, "json", false, "Output as JSON")
+
+	cmd.AddCommand(scheduleListCmd())
+	cmd.AddCommand(scheduleCreateCmd())
+	cmd.AddCommand(scheduleUpdateCmd())
+	cmd.AddCommand(scheduleDeleteCmd())
+
+	return cmd
+}
+
+// scheduleListCmd lists a repository's schedules
+func scheduleListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List a repository's schedules",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			endpoint, err := schedulesEndpoint("")
+			if err != nil {
+				return err
+			}
+			resp, err := apiRequest(http.MethodGet, endpoint, nil)
+			if err != nil {
+				return err
+			}
+
+			if jsonOutput {
+				fmt.Println(string(resp))
+				return nil
+			}
+
+			var schedules []scheduleResponse
+			if err := json.Unmarshal(resp, &schedules); err != nil {
+				return fmt.Errorf("failed to parse response: %w", err)
+			}
+			if len(schedules) == 0 {
+				fmt.Println("No schedules found.")
+				return nil
+			}
+			printScheduleTable(os.Stdout, schedules)
+			return nil
+		},
+	}
+}
+
+// scheduleCreateCmd adds a schedule to a repository
+func scheduleCreateCmd() *cobra.Command {
+	var (
+		req      scheduleRequest
+		disabled bool
+		prOpts   jobs.PROptions
+	)
+
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Schedule recurring pipeline runs",
+		Long: `Schedule recurring pipeline runs of a repository. --cron takes five fields
+(minute, hour, day of month, month, day of week) or a macro such as @daily
+or @weekly, in --timezone.
+
+Examples:
+  # Every night at 2am New York time, opening a PR for new untested code
+  qtest schedule create --repo 2b9e... --cron "0 2 * * *" --timezone America/New_York --create-pr
+
+  # Weekly on Monday morning, with mutation testing, as a draft PR
+  qtest schedule create --repo 2b9e... --cron "0 6 * * mon" --mutation --create-pr --draft`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if req.Cron == "" {
+				return fmt.Errorf("--cron is required")
+			}
+			if err := setScheduleFlags(cmd, &req, disabled, &prOpts); err != nil {
+				return err
+			}
+
+			endpoint, err := schedulesEndpoint("")
+			if err != nil {
+				return err
+			}
+			resp, err := apiRequest(http.MethodPost, endpoint, req)
+			if err != nil {
+				return err
+			}
+			return printSchedule(resp, "Schedule created.")
+		},
+	}
+
+	addScheduleFlags(cmd, &req, &disabled, &prOpts)
+	return cmd
+}
+
+// scheduleUpdateCmd changes the fields of a schedule given as flags
+func scheduleUpdateCmd() *cobra.Command {
+	var (
+		req      scheduleRequest
+		disabled bool
+		prOpts   jobs.PROptions
+	)
+
+	cmd := &cobra.Command{
+		Use:   "update <schedule-id>",
+		Short: "Change a schedule",
+		Long: `Change the schedule fields given as flags, keeping the others. PR flags
+replace the schedule's PR options as a whole.
+
+Examples:
+  qtest schedule update 7c4d... --repo 2b9e... --cron @weekly
+  qtest schedule update 7c4d... --repo 2b9e... --disable
+  qtest schedule update 7c4d... --repo 2b9e... --enable`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			endpoint, err := schedulesEndpoint(args[0])
+			if err != nil {
+				return err
+			}
+			resp, err := apiRequest(http.MethodGet, endpoint, nil)
+			if err != nil {
+				return err
+			}
+			var current scheduleResponse
+			if err := json.Unmarshal(resp, &current); err != nil {
+				return fmt.Errorf("failed to parse response: %w", err)
+			}
+
+			enabled := current.Enabled
+			update := scheduleRequest{
+				Cron:     current.Cron,
+				Timezone: current.Timezone,
+				Enabled:  &enabled,
+				Options:  current.Options,
+			}
+			flags := cmd.Flags()
+			if flags.Changed("cron") {
+				update.Cron = req.Cron
+			}
+			if flags.Changed("timezone") {
+				update.Timezone = req.Timezone
+			}
+			if flags.Changed("enable") {
+				enabled = true
+			}
+			if flags.Changed("branch") {
+				update.Options.Branch = req.Options.Branch
+			}
+			if flags.Changed("max-tests") {
+				update.Options.MaxTests = req.Options.MaxTests
+			}
+			if flags.Changed("tier") {
+				update.Options.LLMTier = req.Options.LLMTier
+			}
+			if flags.Changed("mutation") {
+				update.Options.RunMutation = req.Options.RunMutation
+			}
+			if flags.Changed("create-pr") {
+				update.Options.CreatePR = req.Options.CreatePR
+				if !update.Options.CreatePR {
+					update.Options.PR = nil
+				}
+			}
+			if flags.Changed("lane") {
+				update.Options.Lane = req.Options.Lane
+			}
+			if err := setScheduleFlags(cmd, &update, disabled, &prOpts); err != nil {
+				return err
+			}
+
+			resp, err = apiRequest(http.MethodPut, endpoint, update)
+			if err != nil {
+				return err
+			}
+			return printSchedule(resp, "Schedule updated.")
+		},
+	}
+
+	addScheduleFlags(cmd, &req, &disabled, &prOpts)
+	cmd.Flags().Bool("enable", false, "Enable the schedule")
+	cmd.MarkFlagsMutuallyExclusive("enable", "disable")
+	return cmd
+}
+
+// scheduleDeleteCmd removes a schedule
+func scheduleDeleteCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete <schedule-id>",
+		Short: "Delete a schedule",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			endpoint, err := schedulesEndpoint(args[0])
+			if err != nil {
+				return err
+			}
+			if _, err := apiRequest(http.MethodDelete, endpoint, nil); err != nil {
+				return err
+			}
+			fmt.Println("Schedule deleted.")
+			return nil
+		},
+	}
+}
+
+// addScheduleFlags adds the flags of a schedule and its pipeline options
+func addScheduleFlags(cmd *cobra.Command, req *scheduleRequest, disabled *bool, prOpts *jobs.PROptions) {
+	cmd.Flags().StringVar(&req.Cron, "cron", "", `Cron expression, e.g. "0 2 * * *" or @weekly`)
+	cmd.Flags().StringVar(&req.Timezone, "timezone", "", "Time zone of the cron expression, e.g. Europe/Berlin (default: UTC)")
+	cmd.Flags().BoolVar(disabled, "disable", false, "Keep the schedule without running it")
+	cmd.Flags().StringVar(&req.Options.Branch, "branch", "", "Git branch")
+	cmd.Flags().IntVar(&req.Options.MaxTests, "max-tests", 0, "Maximum tests to generate per run")
+	cmd.Flags().IntVar(&req.Options.LLMTier, "tier", 0, "LLM tier (1=fast, 2=balanced, 3=thorough; default: repository policy, else 1)")
+	cmd.Flags().BoolVar(&req.Options.RunMutation, "mutation", false, "Run mutation testing on the generated tests")
+	cmd.Flags().BoolVar(&req.Options.CreatePR, "create-pr", false, "Open a PR when a run generates tests")
+	cmd.Flags().StringVar(&req.Options.Lane, "lane", "", "Scheduling lane: interactive, default or batch (default: batch)")
+	cmd.Flags().BoolVar(&prOpts.Draft, "draft", false, "Open PRs as drafts")
+	cmd.Flags().StringSliceVar(&prOpts.Labels, "label", nil, "Label to add to PRs (repeatable)")
+	cmd.Flags().StringSliceVar(&prOpts.Assignees, "assignee", nil, "User to assign to PRs (repeatable)")
+	cmd.Flags().StringSliceVar(&prOpts.Reviewers, "reviewer", nil, "Reviewer login or org/team (repeatable)")
+	cmd.Flags().BoolVar(&prOpts.AutoMerge, "auto-merge", false, "Merge PRs once checks pass (only if tests passed)")
+	cmd.Flags().StringVar(&prOpts.MergeMethod, "merge-method", "", "Auto-merge method: merge, squash or rebase")
+	cmd.Flags().StringVar(&prOpts.CommitStrategy, "commit-strategy", "", "How to commit tests to PR branches: single or per-package")
+}
+
+// setScheduleFlags applies --disable, the lane and the PR flags to req,
+// validating them
+func setScheduleFlags(cmd *cobra.Command, req *scheduleRequest, disabled bool, prOpts *jobs.PROptions) error {
+	flags := cmd.Flags()
+	if disabled {
+		enabled := false
+		req.Enabled = &enabled
+	}
+	if _, err := jobs.ParseLane(req.Options.Lane); err != nil {
+		return err
+	}
+	if flags.Changed("draft") || flags.Changed("label") || flags.Changed("assignee") ||
+		flags.Changed("reviewer") || flags.Changed("auto-merge") || flags.Changed("merge-method") ||
+		flags.Changed("commit-strategy") {
+		if err := prOpts.Validate(); err != nil {
+			return err
+		}
+		req.Options.PR = prOpts
+	}
+	if req.Options.PR != nil && !req.Options.CreatePR {
+		return fmt.Errorf("PR options require --create-pr")
+	}
+	return nil
+}
+
+// schedulesEndpoint is the API path of the --repo's schedules, or of one
+// of them
+func schedulesEndpoint(id string) (string, error) {
+	if scheduleRepo == "" {
+		return "", fmt.Errorf("--repo is required")
+	}
+	endpoint := apiURL + "/api/v1/repos/" + scheduleRepo + "/schedules"
+	if id != "" {
+		endpoint += "/" + id
+	}
+	return endpoint, nil
+}
+
+// printSchedule prints a schedule response after msg, or as JSON
+func printSchedule(resp []byte, msg string) error {
+	if jsonOutput {
+		fmt.Println(string(resp))
+		return nil
+	}
+
+	var sched scheduleResponse
+	if err := json.Unmarshal(resp, &sched); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	fmt.Println(msg)
+	fmt.Printf("  ID:       %s\n", sched.ID)
+	fmt.Printf("  Cron:     %s (%s)\n", sched.Cron, sched.Timezone)
+	if sched.Enabled {
+		fmt.Printf("  Next run: %s\n", formatScheduleTime(sched.NextRunAt))
+	} else {
+		fmt.Printf("  Disabled\n")
+	}
+	return nil
+}
+
+func printScheduleTable(out io.Writer, schedules []scheduleResponse) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tCRON\tTIMEZONE\tNEXT RUN\tLAST RUN\tLAST OUTCOME")
+
+	for _, s := range schedules {
+		next := formatScheduleTime(s.NextRunAt)
+		if !s.Enabled {
+			next = "disabled"
+		}
+		last, outcome := "-", "-"
+		if s.LastRunAt != nil {
+			last = formatScheduleTime(*s.LastRunAt)
+		}
+		if s.LastOutcome != nil {
+			outcome = *s.LastOutcome
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			truncateJobID(s.ID, 8), s.Cron, s.Timezone, next, last, outcome)
+	}
+	w.Flush()
+}
+
+// formatScheduleTime formats an API timestamp in local time
+func formatScheduleTime(t string) string {
+	parsed, err := time.Parse(time.RFC3339Nano, t)
+	if err != nil {
+		return t
+	}
+	return parsed.Local().Format("Jan 02 15:04 MST")
+}
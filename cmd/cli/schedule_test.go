@@ -0,0 +1,62 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestScheduleUpdate_KeepsOtherFields(t *testing.T) {
+	var put scheduleRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/repos/repo-1/schedules/sched-1" {
+			http.NotFound(w, r)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			io.WriteString(w, `{"id": "sched-1", "cron": "0 2 * * *", "timezone": "Europe/Berlin", "enabled": true,
+				"options": {"max_tests": 20, "create_pr": true, "pr": {"labels": ["qtest"]}}, "next_run_at": "2025-03-15T01:00:00Z"}`)
+		case http.MethodPut:
+			json.NewDecoder(r.Body).Decode(&put)
+			io.WriteString(w, `{"id": "sched-1", "cron": "@weekly", "timezone": "Europe/Berlin", "enabled": false}`)
+		}
+	}))
+	defer server.Close()
+	defer func() { scheduleRepo, apiURL = "", "" }()
+
+	cmd := scheduleCmd()
+	cmd.SetArgs([]string{"update", "sched-1", "--api-url", server.URL, "--repo", "repo-1", "--cron", "@weekly", "--disable", "--tier", "2"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("schedule update: %v", err)
+	}
+
+	if put.Cron != "@weekly" || put.Timezone != "Europe/Berlin" || put.Enabled == nil || *put.Enabled {
+		t.Errorf("PUT schedule = %+v", put)
+	}
+	opts := put.Options
+	if opts.MaxTests != 20 || opts.LLMTier != 2 || !opts.CreatePR || opts.PR == nil || len(opts.PR.Labels) != 1 {
+		t.Errorf("PUT options = %+v, want the current ones with tier 2", opts)
+	}
+}
+
+func TestScheduleCreate_Validates(t *testing.T) {
+	defer func() { scheduleRepo, apiURL = "", "" }()
+
+	for name, args := range map[string][]string{
+		"no cron":          {"create", "--repo", "repo-1"},
+		"no repo":          {"create", "--cron", "@daily"},
+		"pr without pr":    {"create", "--repo", "repo-1", "--cron", "@daily", "--draft"},
+		"unknown lane":     {"create", "--repo", "repo-1", "--cron", "@daily", "--lane", "urgent"},
+		"enable + disable": {"update", "s", "--repo", "repo-1", "--enable", "--disable"},
+	} {
+		cmd := scheduleCmd()
+		cmd.SetArgs(append(args, "--api-url", "http://127.0.0.1:0"))
+		cmd.SilenceUsage, cmd.SilenceErrors = true, true
+		if err := cmd.Execute(); err == nil {
+			t.Errorf("%s: succeeded, want an error", name)
+		}
+	}
+}
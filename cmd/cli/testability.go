@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/QTest-hq/qtest/pkg/model"
+	"github.com/spf13/cobra"
+)
+
+func testabilityCmd() *cobra.Command {
+	var (
+		dirPath       string
+		outputFile    string
+		jsonOut       bool
+		limit         int
+		maxLOC        int
+		maxBranches   int
+		maxParameters int
+		verbose       bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "testability",
+		Short: "Report code that is hard to test, with refactoring suggestions",
+		Long: `Builds the system model for a directory and looks for testability smells:
+long or branch-heavy functions, I/O in constructors, global state, hidden
+dependencies on the clock, environment, network or disk, and concrete
+dependencies that should be interfaces.
+
+Targets are ranked by their smells and risk, worst first, each with concrete
+refactoring suggestions.`,
+		Example: `  qtest testability -p .
+  qtest testability -p ./internal --limit 10
+  qtest testability -p . --json > testability.json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+
+			validPath, err := validateDirPath(dirPath)
+			if err != nil {
+				return fmt.Errorf("invalid directory: %w", err)
+			}
+
+			if !jsonOut {
+				fmt.Printf("🔍 Analyzing testability: %s\n\n", validPath)
+			}
+
+			sysModel, _, err := buildSystemModel(ctx, validPath, verbose)
+			if err != nil {
+				return err
+			}
+
+			cfg := model.DefaultTestabilityConfig()
+			cfg.MaxLOC = maxLOC
+			cfg.MaxBranches = maxBranches
+			cfg.MaxParameters = maxParameters
+
+			report := model.AnalyzeTestability(sysModel, cfg)
+			for i := range report.Targets {
+				report.Targets[i].File = relativePath(validPath, report.Targets[i].File)
+			}
+
+			if outputFile != "" {
+				data, err := json.MarshalIndent(report, "", "  ")
+				if err != nil {
+					return fmt.Errorf("failed to marshal report: %w", err)
+				}
+				if err := os.WriteFile(outputFile, data, 0644); err != nil {
+					return fmt.Errorf("failed to write output: %w", err)
+				}
+			}
+
+			if jsonOut {
+				data, _ := json.MarshalIndent(report, "", "  ")
+				fmt.Println(string(data))
+				return nil
+			}
+
+			printTestabilityReport(report, limit)
+			if outputFile != "" {
+				fmt.Printf("\n💾 Report saved to: %s\n", outputFile)
+			}
+			return nil
+		},
+	}
+
+	defaults := model.DefaultTestabilityConfig()
+	cmd.Flags().StringVarP(&dirPath, "path", "p", ".", "Directory to analyze")
+	cmd.Flags().StringVarP(&outputFile, "output", "o", "", "Output file for JSON report")
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output as JSON to stdout")
+	cmd.Flags().IntVarP(&limit, "limit", "n", 20, "Maximum targets to show (0 = all)")
+	cmd.Flags().IntVar(&maxLOC, "max-lines", defaults.MaxLOC, "Flag functions longer than this")
+	cmd.Flags().IntVar(&maxBranches, "max-branches", defaults.MaxBranches, "Flag functions with more branches than this")
+	cmd.Flags().IntVar(&maxParameters, "max-params", defaults.MaxParameters, "Flag functions with more parameters than this")
+	cmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Show verbose output")
+
+	return cmd
+}
+
+// printTestabilityReport prints the worst targets with their suggestions
+func printTestabilityReport(report *model.TestabilityReport, limit int) {
+	fmt.Printf("📊 Analyzed %d functions and types, %d hard to test\n", report.Analyzed, len(report.Targets))
+	if len(report.Targets) == 0 {
+		fmt.Println("\n✅ No testability smells found")
+		return
+	}
+
+	kinds := make([]string, 0, len(report.Summary))
+	for kind := range report.Summary {
+		kinds = append(kinds, kind)
+	}
+	sort.Slice(kinds, func(i, j int) bool {
+		if report.Summary[kinds[i]] != report.Summary[kinds[j]] {
+			return report.Summary[kinds[i]] > report.Summary[kinds[j]]
+		}
+		return kinds[i] < kinds[j]
+	})
+	fmt.Println()
+	for _, kind := range kinds {
+		fmt.Printf("   %-20s %d\n", smellLabel(kind), report.Summary[kind])
+	}
+
+	shown := report.Targets
+	if limit > 0 && len(shown) > limit {
+		shown = shown[:limit]
+	}
+
+	fmt.Println()
+	fmt.Println("🎯 Refactoring Priorities")
+	fmt.Println(strings.Repeat("─", 60))
+	for i, t := range shown {
+		fmt.Printf("\n%d. [%s] %s (%s:%d) score %.2f\n", i+1, strings.ToUpper(t.Priority), t.Name, t.File, t.Line, t.Score)
+		for _, s := range t.Smells {
+			if s.Evidence != "" {
+				fmt.Printf("   • %s: %s\n", smellLabel(s.Kind), s.Evidence)
+			} else {
+				fmt.Printf("   • %s\n", smellLabel(s.Kind))
+			}
+			fmt.Printf("     → %s\n", s.Suggestion)
+		}
+	}
+
+	if len(report.Targets) > len(shown) {
+		fmt.Printf("\n   ... and %d more (use --limit 0 to show all)\n", len(report.Targets)-len(shown))
+	}
+}
+
+// smellLabel turns a smell kind into a readable label
+func smellLabel(kind string) string {
+	return strings.ReplaceAll(kind, "_", " ")
+}
+
+// relativePath shows a path relative to root when it's inside it
+func relativePath(root, path string) string {
+	if !filepath.IsAbs(path) {
+		return path
+	}
+	if rel, err := filepath.Rel(root, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return path
+}
@@ -5,10 +5,13 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/QTest-hq/qtest/internal/config"
 	"github.com/QTest-hq/qtest/internal/llm"
 	"github.com/QTest-hq/qtest/internal/validator"
+	"github.com/QTest-hq/qtest/internal/workspace"
 	"github.com/spf13/cobra"
 )
 
@@ -27,15 +30,26 @@ func validateCmd() *cobra.Command {
 
 func validateRunCmd() *cobra.Command {
 	var (
-		language string
+		language   string
+		format     string
+		outputFile string
 	)
 
 	cmd := &cobra.Command{
 		Use:   "run <test-file>",
 		Short: "Run tests and show results",
-		Args:  cobra.ExactArgs(1),
+		Long: `Run a test file and show the results.
+
+Examples:
+  qtest validate run api_test.go                          # Show results
+  qtest validate run api_test.go --format junit -o junit.xml  # Also save JUnit XML for CI
+  qtest validate run api_test.go --format junit           # JUnit XML to stdout`,
+		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			testFile := args[0]
+			if format != "text" && format != workspace.FormatJUnit {
+				return fmt.Errorf("unknown format %q (use text or junit)", format)
+			}
 
 			// Auto-detect language
 			if language == "" {
@@ -45,14 +59,36 @@ func validateRunCmd() *cobra.Command {
 			workDir := filepath.Dir(testFile)
 			v := validator.NewValidator(workDir, language)
 
-			fmt.Printf("Running tests: %s\n", testFile)
-			fmt.Printf("Language: %s\n\n", language)
+			// JUnit XML without an output file is written to stdout on its own
+			quiet := format == workspace.FormatJUnit && outputFile == ""
+			if !quiet {
+				fmt.Printf("Running tests: %s\n", testFile)
+				fmt.Printf("Language: %s\n\n", language)
+			}
 
 			result, err := v.RunTests(context.Background(), testFile)
 			if err != nil {
 				return fmt.Errorf("failed to run tests: %w", err)
 			}
 
+			if format == workspace.FormatJUnit {
+				data, err := workspace.ExportJUnit(executionReport(result))
+				if err != nil {
+					return fmt.Errorf("failed to export results: %w", err)
+				}
+				if quiet {
+					os.Stdout.Write(data)
+					if !result.Passed {
+						return cliErrorf(exitValidation, "tests failed")
+					}
+					return nil
+				}
+				if err := os.WriteFile(outputFile, data, 0644); err != nil {
+					return fmt.Errorf("failed to save results: %w", err)
+				}
+				fmt.Printf("📄 JUnit XML saved to: %s\n\n", outputFile)
+			}
+
 			// Display results
 			if result.Passed {
 				fmt.Println("✅ All tests passed!")
@@ -76,17 +112,69 @@ func validateRunCmd() *cobra.Command {
 			fmt.Printf("Duration: %s\n", result.Duration)
 
 			if !result.Passed {
-				os.Exit(1)
+				return cliErrorf(exitValidation, "tests failed")
 			}
 			return nil
 		},
 	}
 
 	cmd.Flags().StringVarP(&language, "language", "l", "", "Language (auto-detected if not specified)")
+	cmd.Flags().StringVar(&format, "format", "text", "Output format: text or junit")
+	cmd.Flags().StringVarP(&outputFile, "output", "o", "", "Output file for the JUnit XML report")
 
 	return cmd
 }
 
+// executionReport converts a test run's result to an execution report.
+// Runners only report the tests that failed, so a run with none is one
+// passing test case for the file.
+func executionReport(result *validator.TestResult) *workspace.ExecutionReport {
+	var tests []workspace.TestResult
+	for _, e := range result.Errors {
+		msg := e.Message
+		if e.Expected != "" || e.Actual != "" {
+			msg += fmt.Sprintf("\nexpected: %s\nactual: %s", e.Expected, e.Actual)
+		}
+		tests = append(tests, workspace.TestResult{
+			Name:       e.TestName,
+			File:       result.TestFile,
+			Status:     "failed",
+			Error:      strings.TrimSpace(msg),
+			StackTrace: e.StackTrace,
+		})
+	}
+	switch {
+	case len(tests) == 0 && result.Passed:
+		tests = append(tests, workspace.TestResult{Name: filepath.Base(result.TestFile), File: result.TestFile, Status: "passed"})
+	case len(tests) == 0:
+		// The run failed without naming a test, e.g. it didn't compile
+		tests = append(tests, workspace.TestResult{
+			Name: filepath.Base(result.TestFile), File: result.TestFile, Status: "failed",
+			Error: strings.TrimSpace(result.Output),
+		})
+	}
+	if len(tests) == 1 {
+		tests[0].DurationMs = int(result.Duration.Milliseconds())
+	}
+
+	report := &workspace.ExecutionReport{
+		Version:         "1.0",
+		ExecutedAt:      time.Now().Add(-result.Duration),
+		DurationSeconds: int(result.Duration.Seconds()),
+		Summary:         workspace.ExecutionSummary{Total: len(tests)},
+		Tests:           tests,
+	}
+	for _, t := range tests {
+		if t.Status == "passed" {
+			report.Summary.Passed++
+		} else {
+			report.Summary.Failed++
+		}
+	}
+	report.Summary.PassRate = float64(report.Summary.Passed) / float64(report.Summary.Total) * 100
+	return report
+}
+
 func validateFixCmd() *cobra.Command {
 	var (
 		language   string
@@ -111,12 +199,12 @@ func validateFixCmd() *cobra.Command {
 			// Load config and create LLM router
 			cfg, err := config.Load()
 			if err != nil {
-				return fmt.Errorf("failed to load config: %w", err)
+				return cliErrorf(exitConfig, "failed to load config: %w", err)
 			}
 
 			router, err := llm.NewRouter(cfg)
 			if err != nil {
-				return fmt.Errorf("failed to create LLM router: %w", err)
+				return cliErrorf(exitConfig, "failed to create LLM router: %w", err)
 			}
 
 			// Create validator
@@ -153,7 +241,7 @@ func validateFixCmd() *cobra.Command {
 			} else {
 				fmt.Printf("❌ Could not fix tests after %d attempts\n", fixResult.Attempts)
 				fmt.Println("Manual intervention required.")
-				os.Exit(1)
+				return cliErrorf(exitValidation, "tests still fail after %d fix attempts", fixResult.Attempts)
 			}
 
 			return nil
@@ -178,6 +266,8 @@ func detectLanguage(filename string) string {
 		return "python"
 	case ".go":
 		return "go"
+	case ".rs":
+		return "rust"
 	default:
 		return "javascript"
 	}
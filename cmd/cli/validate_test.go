@@ -0,0 +1,35 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/QTest-hq/qtest/internal/validator"
+)
+
+func TestExecutionReport(t *testing.T) {
+	report := executionReport(&validator.TestResult{
+		TestFile: "tests/calc_test.go",
+		Duration: 2 * time.Second,
+		Errors: []validator.TestError{
+			{TestName: "TestAdd", Message: "wrong sum", Expected: "5", Actual: "6"},
+			{TestName: "TestDiv", Message: "panic"},
+		},
+	})
+	if report.Summary.Total != 2 || report.Summary.Failed != 2 || report.DurationSeconds != 2 {
+		t.Errorf("summary = %+v", report.Summary)
+	}
+	if got := report.Tests[0].Error; got != "wrong sum\nexpected: 5\nactual: 6" {
+		t.Errorf("error = %q", got)
+	}
+
+	report = executionReport(&validator.TestResult{TestFile: "tests/calc_test.go", Passed: true, Duration: time.Second})
+	if len(report.Tests) != 1 || report.Tests[0].Status != "passed" || report.Tests[0].Name != "calc_test.go" || report.Summary.PassRate != 100 {
+		t.Errorf("a passing run should be one passing case, got %+v", report)
+	}
+
+	report = executionReport(&validator.TestResult{TestFile: "tests/calc_test.go", Output: "syntax error\n"})
+	if len(report.Tests) != 1 || report.Tests[0].Status != "failed" || report.Tests[0].Error != "syntax error" {
+		t.Errorf("a failed run naming no test should be one failing case, got %+v", report.Tests)
+	}
+}
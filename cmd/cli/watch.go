@@ -0,0 +1,278 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/QTest-hq/qtest/internal/config"
+	"github.com/QTest-hq/qtest/internal/generator"
+	"github.com/QTest-hq/qtest/internal/llm"
+	"github.com/QTest-hq/qtest/internal/parser"
+	"github.com/QTest-hq/qtest/internal/watch"
+	"github.com/QTest-hq/qtest/internal/workspace"
+	"github.com/QTest-hq/qtest/pkg/dsl"
+	"github.com/spf13/cobra"
+)
+
+func watchCmd() *cobra.Command {
+	var (
+		repoPath  string
+		outputDir string
+		tier      string
+		maxTests  int
+		debounce  time.Duration
+		initial   bool
+		useIRSpec bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "watch",
+		Short: "Regenerate tests as source files change",
+		Long: `Watches a local repository and regenerates the tests of each source file
+when its content changes, so tests keep up while you develop.
+
+Edits are debounced, so saving a file several times in a row regenerates its
+tests once. The content hash tests were generated for is kept in a workspace
+for the repository, so restarting watch picks up files changed while it was
+stopped without regenerating the rest. With --initial, files without tests
+are generated on start too.
+
+Examples:
+  qtest watch
+  qtest watch -r ./local/path -t auto --irspec
+  qtest watch -r ./local/path --initial --debounce 2s`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			root, err := validateDirPath(repoPath)
+			if err != nil {
+				return fmt.Errorf("invalid path: %w", err)
+			}
+			root, err = filepath.Abs(root)
+			if err != nil {
+				return fmt.Errorf("invalid path: %w", err)
+			}
+
+			cfg, err := config.Load()
+			if err != nil {
+				return cliErrorf(exitConfig, "failed to load config: %w", err)
+			}
+
+			router, err := llm.NewRouter(cfg)
+			if err != nil {
+				return cliErrorf(exitConfig, "failed to create LLM router: %w", err)
+			}
+			if err := router.HealthCheck(); err != nil {
+				return cliErrorf(exitLLM, "LLM not available: %w\nMake sure Ollama is running: ollama serve", err)
+			}
+
+			ws, err := watchWorkspace(root)
+			if err != nil {
+				return err
+			}
+
+			// Parse tier; "auto" picks one per function from tier 1
+			selectTier := tier == "auto"
+			tierNum, _ := strconv.Atoi(tier)
+			llmTier := llm.Tier(tierNum)
+			if selectTier {
+				llmTier = llm.Tier1
+			} else if llmTier < 1 || llmTier > 3 {
+				llmTier = llm.Tier2
+			}
+
+			w := &watcher{
+				ws:        ws,
+				parser:    parser.NewParser(),
+				gen:       generator.NewGenerator(router),
+				outputDir: outputDir,
+				opts: generator.GenerateOptions{
+					Tier:           llmTier,
+					SelectTier:     selectTier,
+					TestType:       dsl.TestTypeUnit,
+					MaxTests:       maxTests,
+					UseIRSpec:      useIRSpec,
+					MaxEscalations: cfg.LLM.EscalationRetries,
+				},
+			}
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), syscall.SIGINT, syscall.SIGTERM)
+			defer stop()
+
+			fw, err := watch.New(root, debounce, isWatchedSource)
+			if err != nil {
+				return err
+			}
+			defer fw.Close()
+
+			fmt.Printf("👀 Watching: %s\n", root)
+			fmt.Printf("   Workspace: %s\n\n", ws.ID)
+
+			// Catch up on files changed since the last session
+			if err := w.catchUp(ctx, root, initial); err != nil {
+				return err
+			}
+
+			fmt.Println("⏳ Waiting for changes (Ctrl+C to stop)...")
+			if err := fw.Run(ctx, w.regenerate); err != nil {
+				return err
+			}
+
+			fmt.Println("\n👋 Stopped watching")
+			return ws.Save()
+		},
+	}
+
+	cmd.Flags().StringVarP(&repoPath, "repo", "r", ".", "Local repository path")
+	cmd.Flags().StringVarP(&outputDir, "output", "o", "", "Output directory (default: next to each source file)")
+	cmd.Flags().StringVarP(&tier, "tier", "t", "2", "LLM tier (1=fast, 2=balanced, 3=thorough, auto=per function by size)")
+	cmd.Flags().IntVarP(&maxTests, "max", "m", 5, "Maximum number of tests to generate per file")
+	cmd.Flags().DurationVar(&debounce, "debounce", watch.DefaultDebounce, "How long edits must settle before regenerating")
+	cmd.Flags().BoolVar(&initial, "initial", false, "Generate tests on start for files that have none yet")
+	cmd.Flags().BoolVar(&useIRSpec, "irspec", false, "Use IRSpec JSON mode (structured output)")
+
+	return cmd
+}
+
+// watcher regenerates the tests of changed files
+type watcher struct {
+	ws        *workspace.Workspace
+	parser    *parser.Parser
+	gen       *generator.Generator
+	outputDir string
+	opts      generator.GenerateOptions
+}
+
+// catchUp regenerates files changed since the workspace last saw them.
+// Files it has never seen are only generated with initial; otherwise their
+// current content becomes the baseline.
+func (w *watcher) catchUp(ctx context.Context, root string, initial bool) error {
+	var changed []string
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() {
+			if path != root && watch.SkipDir(info.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !isWatchedSource(path) {
+			return nil
+		}
+
+		hash, isChanged, err := w.ws.FileChanged(path)
+		if err != nil || !isChanged {
+			return nil
+		}
+		if !initial && !w.ws.HasFile(path) {
+			w.ws.MarkFileGenerated(path, hash)
+			return nil
+		}
+		changed = append(changed, path)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to scan repository: %w", err)
+	}
+
+	if len(changed) > 0 {
+		fmt.Printf("🔄 %d files changed since the last run\n", len(changed))
+		w.regenerate(ctx, changed)
+	}
+	return w.ws.Save()
+}
+
+// regenerate re-runs parse → generate → write for the files in a batch whose
+// content changed since tests were last generated for it
+func (w *watcher) regenerate(ctx context.Context, files []string) {
+	for i, path := range files {
+		if ctx.Err() != nil {
+			return
+		}
+
+		hash, changed, err := w.ws.FileChanged(path)
+		if err != nil || !changed {
+			continue // deleted since, or saved without changes
+		}
+
+		rel := path
+		if r, err := filepath.Rel(w.ws.RepoPath, path); err == nil {
+			rel = r
+		}
+		fmt.Printf("\n[%d/%d] %s\n", i+1, len(files), rel)
+
+		parsed, err := w.parser.ParseFile(ctx, path)
+		if err != nil {
+			fmt.Printf("⚠️  Parse failed: %v\n", err)
+			continue
+		}
+		if len(w.ws.AddTargets(parsed.Functions, path)) == 0 {
+			fmt.Println("   No exported functions, skipping")
+			w.ws.MarkFileGenerated(path, hash)
+			continue
+		}
+
+		start := time.Now()
+		tests, err := w.gen.GenerateForFile(ctx, path, w.opts)
+		if err != nil {
+			fmt.Printf("⚠️  Generation failed: %v\n", err)
+			continue
+		}
+		if len(tests) == 0 {
+			fmt.Println("⚠️  No tests generated")
+			continue
+		}
+		if err := writeTestFiles(path, tests, w.outputDir, false); err != nil {
+			fmt.Printf("⚠️  %v\n", err)
+			continue
+		}
+
+		fmt.Printf("✓ %d tests in %s\n", len(tests), time.Since(start).Round(100*time.Millisecond))
+		w.ws.MarkFileGenerated(path, hash)
+		if err := w.ws.Save(); err != nil {
+			fmt.Printf("⚠️  Failed to save workspace: %v\n", err)
+		}
+	}
+}
+
+// watchWorkspace returns the workspace watch keeps root's state in,
+// creating it the first time root is watched
+func watchWorkspace(root string) (*workspace.Workspace, error) {
+	workspaces, err := workspace.ListWorkspaces(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list workspaces: %w", err)
+	}
+	for _, ws := range workspaces {
+		if ws.RepoURL == root && ws.RepoPath == root {
+			return ws, nil
+		}
+	}
+
+	ws, err := workspace.New("watch-"+filepath.Base(root), root, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create workspace: %w", err)
+	}
+	// Watch works on the repository in place rather than on a copy
+	ws.RepoPath = root
+	if err := ws.Save(); err != nil {
+		return nil, err
+	}
+	return ws, nil
+}
+
+// isWatchedSource reports whether path is a source file tests are
+// generated for
+func isWatchedSource(path string) bool {
+	base := filepath.Base(path)
+	if strings.Contains(base, "_test.") || strings.Contains(base, ".test.") || strings.HasPrefix(base, "test_") {
+		return false
+	}
+	return isSupportedExt(strings.ToLower(filepath.Ext(path)))
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestIsWatchedSource(t *testing.T) {
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"pkg/calc.go", true},
+		{"src/app.ts", true},
+		{"lib/util.py", true},
+		{"pkg/calc_test.go", false},
+		{"src/app.test.ts", false},
+		{"tests/test_util.py", false},
+		{"README.md", false},
+		{"pkg/calc.go.qtest-merge", false},
+	}
+
+	for _, tt := range tests {
+		if got := isWatchedSource(tt.path); got != tt.want {
+			t.Errorf("isWatchedSource(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestWatchWorkspace_Reused(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	root := t.TempDir()
+
+	ws, err := watchWorkspace(root)
+	if err != nil {
+		t.Fatalf("watchWorkspace() error: %v", err)
+	}
+	if ws.RepoPath != root {
+		t.Errorf("RepoPath = %q, want the watched directory %q", ws.RepoPath, root)
+	}
+	if ws.Name != "watch-"+filepath.Base(root) {
+		t.Errorf("Name = %q", ws.Name)
+	}
+
+	again, err := watchWorkspace(root)
+	if err != nil {
+		t.Fatalf("watchWorkspace() error: %v", err)
+	}
+	if again.ID != ws.ID {
+		t.Errorf("second watch got workspace %s, want %s reused", again.ID, ws.ID)
+	}
+
+	other, err := watchWorkspace(t.TempDir())
+	if err != nil {
+		t.Fatalf("watchWorkspace() error: %v", err)
+	}
+	if other.ID == ws.ID {
+		t.Error("a different directory reused the workspace")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"strings"
 	"syscall"
 
@@ -30,6 +31,7 @@ func workspaceCmd() *cobra.Command {
 	cmd.AddCommand(workspaceResumeCmd())
 	cmd.AddCommand(workspaceValidateCmd())
 	cmd.AddCommand(workspaceCoverageCmd())
+	cmd.AddCommand(workspaceCacheCmd())
 
 	return cmd
 }
@@ -148,6 +150,9 @@ func workspaceRunCmd() *cobra.Command {
 		validate   bool
 		coverage   bool
 		parallel   int
+		changelog  bool
+		remodel    bool
+		debug      bool
 	)
 
 	cmd := &cobra.Command{
@@ -164,17 +169,17 @@ func workspaceRunCmd() *cobra.Command {
 			// Load config
 			cfg, err := config.Load()
 			if err != nil {
-				return fmt.Errorf("failed to load config: %w", err)
+				return cliErrorf(exitConfig, "failed to load config: %w", err)
 			}
 
 			// Create LLM router
 			router, err := llm.NewRouter(cfg)
 			if err != nil {
-				return fmt.Errorf("failed to create LLM router: %w", err)
+				return cliErrorf(exitConfig, "failed to create LLM router: %w", err)
 			}
 
 			if err := router.HealthCheck(); err != nil {
-				return fmt.Errorf("LLM not available: %w\nMake sure Ollama is running", err)
+				return cliErrorf(exitLLM, "LLM not available: %w\nMake sure Ollama is running", err)
 			}
 
 			// Create runner
@@ -183,6 +188,7 @@ func workspaceRunCmd() *cobra.Command {
 			runCfg.CommitEach = commitEach
 			runCfg.DryRun = dryRun
 			runCfg.ValidateTests = validate
+			runCfg.DebugPrompts = debug
 			if parallel > 0 {
 				runCfg.MaxConcurrent = parallel
 			}
@@ -226,6 +232,14 @@ func workspaceRunCmd() *cobra.Command {
 					return fmt.Errorf("initialization failed: %w", err)
 				}
 				fmt.Printf("Found %d testable functions\n\n", ws.State.TotalTargets)
+			} else if remodel {
+				fmt.Println("Re-modeling workspace...")
+				renames, err := runner.Remodel(ctx)
+				if err != nil {
+					return fmt.Errorf("re-modeling failed: %w", err)
+				}
+				printRenames(renames)
+				fmt.Printf("Found %d testable functions\n\n", ws.State.TotalTargets)
 			}
 
 			// Run generation
@@ -247,6 +261,9 @@ func workspaceRunCmd() *cobra.Command {
 			fmt.Printf("Generation complete!\n")
 			fmt.Printf("  Completed: %d\n", summary["completed"])
 			fmt.Printf("  Failed:    %d\n", summary["failed"])
+			if debug {
+				fmt.Printf("  Prompts:   %s\n", filepath.Join(ws.Path(), "artifacts", workspace.PromptDebugDir))
+			}
 
 			// Collect coverage if requested
 			if coverage && !dryRun && ws.Language != "" {
@@ -263,6 +280,10 @@ func workspaceRunCmd() *cobra.Command {
 				}
 			}
 
+			if changelog {
+				printChangelogUpdate(runner.UpdateChangelog())
+			}
+
 			return nil
 		},
 	}
@@ -273,6 +294,9 @@ func workspaceRunCmd() *cobra.Command {
 	cmd.Flags().BoolVar(&validate, "validate", false, "Run tests after generation to verify they pass")
 	cmd.Flags().BoolVar(&coverage, "coverage", false, "Collect code coverage after generation")
 	cmd.Flags().IntVarP(&parallel, "parallel", "p", 1, "Number of parallel workers (1=sequential)")
+	cmd.Flags().BoolVar(&changelog, "changelog", true, "Record the run in "+workspace.ChangelogFile)
+	cmd.Flags().BoolVar(&remodel, "remodel", false, "Re-parse the repository and re-link tests of renamed or moved functions")
+	cmd.Flags().BoolVar(&debug, "debug-prompts", false, "Save each target's prompt, raw response, parsed DSL and errors to artifacts/"+workspace.PromptDebugDir)
 
 	return cmd
 }
@@ -283,6 +307,8 @@ func workspaceRunV2Cmd() *cobra.Command {
 		commitEach bool
 		dryRun     bool
 		maxTests   int
+		changelog  bool
+		debug      bool
 	)
 
 	cmd := &cobra.Command{
@@ -308,17 +334,17 @@ This is the recommended command for generating complete test suites.`,
 			// Load config
 			cfg, err := config.Load()
 			if err != nil {
-				return fmt.Errorf("failed to load config: %w", err)
+				return cliErrorf(exitConfig, "failed to load config: %w", err)
 			}
 
 			// Create LLM router
 			router, err := llm.NewRouter(cfg)
 			if err != nil {
-				return fmt.Errorf("failed to create LLM router: %w", err)
+				return cliErrorf(exitConfig, "failed to create LLM router: %w", err)
 			}
 
 			if err := router.HealthCheck(); err != nil {
-				return fmt.Errorf("LLM not available: %w\nMake sure Ollama is running", err)
+				return cliErrorf(exitLLM, "LLM not available: %w\nMake sure Ollama is running", err)
 			}
 
 			// Create runner config
@@ -327,6 +353,7 @@ This is the recommended command for generating complete test suites.`,
 			runCfg.CommitEach = commitEach
 			runCfg.DryRun = dryRun
 			runCfg.MaxTests = maxTests
+			runCfg.DebugPrompts = debug
 
 			// Create v2 runner
 			runner := workspace.NewRunnerV2(ws, router, cfg.GitHubToken, runCfg)
@@ -385,6 +412,13 @@ This is the recommended command for generating complete test suites.`,
 			fmt.Printf("  Completed: %d\n", summary["completed"])
 			fmt.Printf("  Failed:    %d\n", summary["failed"])
 			fmt.Printf("  Artifacts: %s/artifacts/\n", ws.Path())
+			if debug {
+				fmt.Printf("  Prompts:   %s\n", filepath.Join(ws.Path(), "artifacts", workspace.PromptDebugDir))
+			}
+
+			if changelog {
+				printChangelogUpdate(runner.UpdateChangelog())
+			}
 
 			return nil
 		},
@@ -394,6 +428,8 @@ This is the recommended command for generating complete test suites.`,
 	cmd.Flags().BoolVar(&commitEach, "commit", true, "Commit after each batch")
 	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Don't write test files")
 	cmd.Flags().IntVar(&maxTests, "max", 0, "Maximum tests to generate (0=all)")
+	cmd.Flags().BoolVar(&changelog, "changelog", true, "Record the run in "+workspace.ChangelogFile)
+	cmd.Flags().BoolVar(&debug, "debug-prompts", false, "Save each target's prompt, raw response, parsed specs and errors to artifacts/"+workspace.PromptDebugDir)
 
 	return cmd
 }
@@ -530,12 +566,38 @@ func workspaceCoverageCmd() *cobra.Command {
 				report.Summary.CoveragePercent)
 
 			fmt.Printf("\nCoverage report saved to: %s/artifacts/coverage.json\n", ws.Path())
+			fmt.Printf("Annotated source saved to: %s/artifacts/source-view.html\n", ws.Path())
 
 			return nil
 		},
 	}
 }
 
+func workspaceCacheCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Manage the cache of parsed source files",
+		Long: `Workspaces share a cache of parsed source files, keyed by the SHA256 of
+each file's path and content, so repeated runs only parse files that changed.`,
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "clear",
+		Short: "Remove all cached parses",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir := workspace.ParseCacheDir(nil)
+			if err := workspace.ClearParseCache(nil); err != nil {
+				return err
+			}
+			fmt.Printf("Cleared parse cache: %s\n", dir)
+			return nil
+		},
+	})
+
+	return cmd
+}
+
 // Helper functions
 
 func extractRepoName(url string) string {
@@ -572,3 +634,36 @@ func truncate(s string, n int) string {
 func repeatStr(s string, n int) string {
 	return strings.Repeat(s, n)
 }
+
+// printChangelogUpdate reports the result of UpdateChangelog
+func printChangelogUpdate(entry *workspace.ChangelogEntry, err error) {
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to update tests changelog")
+		return
+	}
+	if entry == nil {
+		return
+	}
+	fmt.Printf("  Changelog: %s (%d added, %d regenerated, %d removed)\n",
+		workspace.ChangelogFile, len(entry.Added), len(entry.Regenerated), len(entry.Removed))
+}
+
+// printRenames lists targets whose tests were re-linked during re-modeling
+func printRenames(renames []workspace.Rename) {
+	if len(renames) == 0 {
+		return
+	}
+	fmt.Printf("Re-linked %d test(s) to renamed or moved functions:\n", len(renames))
+	for _, rn := range renames {
+		from, to := rn.OldName, rn.NewName
+		if rn.OldFile != rn.NewFile {
+			from = rn.OldFile + ":" + from
+			to = rn.NewFile + ":" + to
+		}
+		updated := ""
+		if rn.TestUpdated {
+			updated = ", test updated"
+		}
+		fmt.Printf("  ↪ %s -> %s (similarity %.2f%s)\n", from, to, rn.Score, updated)
+	}
+}
@@ -87,6 +87,10 @@ func main() {
 		log.Info().Msg("LLM router initialized")
 	}
 
+	// Detect installed toolchains so workers only take jobs they can run
+	caps := worker.DetectCapabilities(context.Background())
+	log.Info().Interface("tools", caps.Tools).Msg("detected worker capabilities")
+
 	// Create worker pool
 	poolCfg := worker.PoolConfig{
 		Config:     cfg,
@@ -95,6 +99,8 @@ func main() {
 		NATS:       natsClient,
 		Store:      store,
 		LLMRouter:  llmRouter,
+
+		Capabilities: caps,
 	}
 
 	pool, err := worker.NewPool(poolCfg)
@@ -0,0 +1,68 @@
+package adapters
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/QTest-hq/qtest/pkg/model"
+)
+
+// approxExpected formats the expected value of an "approx" assertion as a
+// float literal valid in JavaScript, Python, Go and Rust
+func approxExpected(assertion model.Assertion) string {
+	var f float64
+	switch v := assertion.Expected.(type) {
+	case float64:
+		f = v
+	case float32:
+		f = float64(v)
+	case int:
+		f = float64(v)
+	case int64:
+		f = float64(v)
+	case string:
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return v
+		}
+		f = parsed
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+	return formatFloatLiteral(f)
+}
+
+// approxEpsilon formats the tolerance of an "approx" assertion
+func approxEpsilon(assertion model.Assertion) string {
+	return formatFloatLiteral(assertion.Epsilon())
+}
+
+// formatFloatLiteral formats f so it reads as a float, not an integer
+func formatFloatLiteral(f float64) string {
+	s := strconv.FormatFloat(f, 'g', -1, 64)
+	if !strings.ContainsAny(s, ".eEn") {
+		s += ".0"
+	}
+	return s
+}
+
+// matchesPattern returns the regular expression of a "matches" assertion
+func matchesPattern(assertion model.Assertion) string {
+	if assertion.Expected == nil {
+		return ""
+	}
+	return fmt.Sprintf("%v", assertion.Expected)
+}
+
+// usesAssertion reports whether any of specs has an assertion of kind
+func usesAssertion(specs []model.TestSpec, kind string) bool {
+	for _, spec := range specs {
+		for _, a := range spec.Assertions {
+			if a.Kind == kind {
+				return true
+			}
+		}
+	}
+	return false
+}
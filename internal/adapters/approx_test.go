@@ -0,0 +1,127 @@
+package adapters
+
+import (
+	"go/format"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/QTest-hq/qtest/pkg/model"
+)
+
+// approxSpecs has a float result checked within a tolerance and a
+// timestamp checked against a pattern
+func approxSpecs() []model.TestSpec {
+	return []model.TestSpec{
+		{
+			FunctionName: "Average",
+			Description:  "averages thirds",
+			Inputs:       map[string]interface{}{"n": float64(3)},
+			ArgOrder:     []string{"n"},
+			Assertions:   []model.Assertion{{Kind: model.AssertApprox, Actual: "result", Expected: float64(1), Tolerance: 0.001}},
+		},
+		{
+			FunctionName: "Stamp",
+			Description:  "formats the time",
+			Inputs:       map[string]interface{}{"n": float64(0)},
+			ArgOrder:     []string{"n"},
+			Assertions:   []model.Assertion{{Kind: model.AssertMatches, Actual: "result", Expected: `^\d{4}-\d{2}-\d{2}`}},
+		},
+	}
+}
+
+func TestJestSpecAdapter_ApproxAndMatches(t *testing.T) {
+	code, err := NewJestSpecAdapter().GenerateFromSpecs(approxSpecs(), "stats.js")
+	if err != nil {
+		t.Fatalf("GenerateFromSpecs() error = %v", err)
+	}
+	for _, want := range []string{
+		"expect(Math.abs(result - 1.0)).toBeLessThanOrEqual(0.001);",
+		`expect(String(result)).toMatch(new RegExp("^\\d{4}-\\d{2}-\\d{2}"));`,
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("generated code missing %q\n%s", want, code)
+		}
+	}
+}
+
+func TestPytestSpecAdapter_ApproxAndMatches(t *testing.T) {
+	code, err := NewPytestSpecAdapter().GenerateFromSpecs(approxSpecs(), "stats.py")
+	if err != nil {
+		t.Fatalf("GenerateFromSpecs() error = %v", err)
+	}
+	for _, want := range []string{
+		"import re\n",
+		"assert result == pytest.approx(1.0, abs=0.001)",
+		`assert re.search("^\\d{4}-\\d{2}-\\d{2}", str(result))`,
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("generated code missing %q\n%s", want, code)
+		}
+	}
+}
+
+func TestGoSpecAdapter_ApproxAndMatches(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "stats.go")
+	os.WriteFile(source, []byte(`package stats
+
+func Average(n int) float64 { return 1 }
+
+func Stamp(n int) string { return "" }
+`), 0644)
+
+	code, err := NewGoSpecAdapter().GenerateFromSpecs(approxSpecs(), source)
+	if err != nil {
+		t.Fatalf("GenerateFromSpecs() error = %v", err)
+	}
+	for _, want := range []string{
+		`"math"`,
+		`"regexp"`,
+		"if math.Abs(float64(result)-1.0) > 0.001 {",
+		"if !regexp.MustCompile(\"^\\\\d{4}-\\\\d{2}-\\\\d{2}\").MatchString(fmt.Sprint(result)) {",
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("generated code missing %q\n%s", want, code)
+		}
+	}
+	if _, err := format.Source([]byte(code)); err != nil {
+		t.Errorf("generated code doesn't parse: %v\n%s", err, code)
+	}
+}
+
+func TestRustSpecAdapter_ApproxAndMatches(t *testing.T) {
+	code, err := NewRustSpecAdapter().GenerateFromSpecs(approxSpecs(), "src/lib.rs")
+	if err != nil {
+		t.Fatalf("GenerateFromSpecs() error = %v", err)
+	}
+	for _, want := range []string{
+		"assert!(((result as f64) - 1.0).abs() <= 0.001);",
+		`assert!(regex::Regex::new(r#"^\d{4}-\d{2}-\d{2}"#).unwrap().is_match(&result.to_string()));`,
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("generated code missing %q\n%s", want, code)
+		}
+	}
+}
+
+func TestApproxExpected(t *testing.T) {
+	tests := []struct {
+		expected interface{}
+		want     string
+	}{
+		{float64(2), "2.0"},
+		{0.5, "0.5"},
+		{"3.25", "3.25"},
+		{1e-12, "1e-12"},
+	}
+	for _, tt := range tests {
+		if got := approxExpected(model.Assertion{Expected: tt.expected}); got != tt.want {
+			t.Errorf("approxExpected(%v) = %q, want %q", tt.expected, got, tt.want)
+		}
+	}
+	if got := approxEpsilon(model.Assertion{}); got != "1e-09" {
+		t.Errorf("approxEpsilon() default = %q, want 1e-09", got)
+	}
+}
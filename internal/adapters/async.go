@@ -0,0 +1,40 @@
+package adapters
+
+import (
+	"github.com/QTest-hq/qtest/pkg/dsl"
+	"github.com/QTest-hq/qtest/pkg/model"
+)
+
+// expectsRejection reports whether an async spec expects its call to fail,
+// in which case the pending result is asserted on rather than awaited
+func expectsRejection(spec model.TestSpec) bool {
+	return spec.Async && expectsError(spec)
+}
+
+// awaitedStep returns a DSL step with its function call awaited when the
+// test's target is async
+func awaitedStep(test *dsl.TestDSL, step dsl.TestStep) dsl.TestStep {
+	if test.Target.Async && step.Action.Type == dsl.ActionCall {
+		step.Action.Target = "await " + step.Action.Target
+	}
+	return step
+}
+
+// goAwaitImports are the imports goAwaitHelper needs
+var goAwaitImports = []string{"time"}
+
+// goAwaitHelper receives the result of a function that returns a channel,
+// failing the test instead of hanging when nothing arrives
+const goAwaitHelper = `// awaitResult waits for the value a function sends on its result channel
+func awaitResult[T any](t *testing.T, ch <-chan T) T {
+	t.Helper()
+	select {
+	case v := <-ch:
+		return v
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for a result")
+	}
+	var zero T
+	return zero
+}
+`
@@ -0,0 +1,57 @@
+package adapters
+
+import (
+	"go/format"
+	"strings"
+	"testing"
+
+	"github.com/QTest-hq/qtest/pkg/model"
+)
+
+func TestGoSpecAdapter_ChannelResult(t *testing.T) {
+	specs := []model.TestSpec{{
+		FunctionName: "Square",
+		Description:  "squares in the background",
+		Async:        true,
+		Inputs:       map[string]interface{}{"n": float64(3)},
+		InputTypes:   map[string]string{"n": "int"},
+		ArgOrder:     []string{"n"},
+		Assertions:   []model.Assertion{{Kind: "equality", Actual: "result", Expected: float64(9)}},
+	}}
+	code, err := NewGoSpecAdapter().GenerateFromSpecs(specs, "square.go")
+	if err != nil {
+		t.Fatalf("GenerateFromSpecs() error = %v", err)
+	}
+
+	for _, want := range []string{
+		"result := awaitResult(t, Square(n))",
+		"func awaitResult[T any](t *testing.T, ch <-chan T) T {",
+		`"time"`,
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("generated code missing %q\n%s", want, code)
+		}
+	}
+	if _, err := format.Source([]byte(code)); err != nil {
+		t.Errorf("generated code doesn't parse: %v\n%s", err, code)
+	}
+
+	specs[0].Async = false
+	code, _ = NewGoSpecAdapter().GenerateFromSpecs(specs, "square.go")
+	if strings.Contains(code, "awaitResult") || strings.Contains(code, `"time"`) {
+		t.Errorf("await helper generated for a synchronous function\n%s", code)
+	}
+}
+
+func TestExpectsRejection(t *testing.T) {
+	throws := []model.Assertion{{Kind: "throws"}}
+	if !expectsRejection(model.TestSpec{Async: true, Assertions: throws}) {
+		t.Error("async spec expecting an error should expect a rejection")
+	}
+	if expectsRejection(model.TestSpec{Assertions: throws}) {
+		t.Error("sync spec can't expect a rejection")
+	}
+	if expectsRejection(model.TestSpec{Async: true, Assertions: []model.Assertion{{Kind: "equals"}}}) {
+		t.Error("async spec without an error assertion shouldn't expect a rejection")
+	}
+}
@@ -0,0 +1,54 @@
+package adapters
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/QTest-hq/qtest/pkg/model"
+)
+
+// goSortedCheck returns the less function a "sorted" assertion checks the
+// result with. Keys name fields of the items' struct type.
+func goSortedCheck(actual string, assertion model.Assertion) string {
+	key, desc := assertion.SortKey()
+	a, b := actual+"[i]", actual+"[j]"
+	if key != "" {
+		field := strings.ToUpper(key[:1]) + key[1:]
+		a, b = a+"."+field, b+"."+field
+	}
+	op := "<"
+	if desc {
+		op = ">"
+	}
+	return fmt.Sprintf("func(i, j int) bool { return %s %s %s }", a, op, b)
+}
+
+// rustSortedCheck returns the check that consecutive items, or their key
+// field, are in the order a "sorted" assertion expects
+func rustSortedCheck(actual string, assertion model.Assertion) string {
+	key, desc := assertion.SortKey()
+	a, b := "w[0]", "w[1]"
+	if key != "" {
+		a, b = a+"."+key, b+"."+key
+	}
+	op := "<="
+	if desc {
+		op = ">="
+	}
+	return fmt.Sprintf("assert!(%s.windows(2).all(|w| %s %s %s));", actual, a, op, b)
+}
+
+// formatItems formats the items a collection assertion lists with format,
+// dropping duplicates
+func formatItems(assertion model.Assertion, format func(interface{}) string) []string {
+	var items []string
+	seen := make(map[string]bool)
+	for _, item := range assertion.ExpectedItems() {
+		s := format(item)
+		if !seen[s] {
+			seen[s] = true
+			items = append(items, s)
+		}
+	}
+	return items
+}
@@ -0,0 +1,143 @@
+package adapters
+
+import (
+	"go/format"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/QTest-hq/qtest/pkg/model"
+)
+
+// collectionSpecs checks a list result's length, items and order
+func collectionSpecs() []model.TestSpec {
+	return []model.TestSpec{
+		{
+			FunctionName: "Tags",
+			Description:  "lists tags",
+			Inputs:       map[string]interface{}{"n": float64(3)},
+			ArgOrder:     []string{"n"},
+			Assertions: []model.Assertion{
+				{Kind: model.AssertLength, Actual: "result", Expected: float64(3)},
+				{Kind: model.AssertContainsAll, Actual: "result", Expected: []interface{}{"go", "rust"}},
+				{Kind: model.AssertSubset, Actual: "result", Expected: []interface{}{"go", "rust", "zig", "go"}},
+				{Kind: model.AssertSorted, Actual: "result"},
+			},
+		},
+		{
+			FunctionName: "Users",
+			Description:  "lists the newest users first",
+			Inputs:       map[string]interface{}{"n": float64(2)},
+			ArgOrder:     []string{"n"},
+			Assertions:   []model.Assertion{{Kind: model.AssertSorted, Actual: "result", Expected: "-age"}},
+		},
+	}
+}
+
+func TestJestSpecAdapter_Collections(t *testing.T) {
+	code, err := NewJestSpecAdapter().GenerateFromSpecs(collectionSpecs(), "tags.js")
+	if err != nil {
+		t.Fatalf("GenerateFromSpecs() error = %v", err)
+	}
+	for _, want := range []string{
+		"expect(result).toHaveLength(3);",
+		"expect(result).toEqual(expect.arrayContaining(['go', 'rust']));",
+		"expect(['go', 'rust', 'zig']).toEqual(expect.arrayContaining(result));",
+		"expect(result).toEqual([...result].sort((a, b) => (a > b) - (a < b)));",
+		"expect(result).toEqual([...result].sort((a, b) => (a.age < b.age) - (a.age > b.age)));",
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("generated code missing %q\n%s", want, code)
+		}
+	}
+}
+
+func TestPytestSpecAdapter_Collections(t *testing.T) {
+	code, err := NewPytestSpecAdapter().GenerateFromSpecs(collectionSpecs(), "tags.py")
+	if err != nil {
+		t.Fatalf("GenerateFromSpecs() error = %v", err)
+	}
+	for _, want := range []string{
+		"assert len(result) == 3",
+		`assert all(item in result for item in ["go", "rust"])`,
+		`assert all(item in ["go", "rust", "zig"] for item in result)`,
+		"assert result == sorted(result)",
+		`assert result == sorted(result, key=lambda item: item["age"], reverse=True)`,
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("generated code missing %q\n%s", want, code)
+		}
+	}
+}
+
+func TestGoSpecAdapter_Collections(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "tags.go")
+	os.WriteFile(source, []byte(`package tags
+
+type User struct{ Age int }
+
+func Tags(n int) []string { return nil }
+
+func Users(n int) []User { return nil }
+`), 0644)
+
+	code, err := NewGoSpecAdapter().GenerateFromSpecs(collectionSpecs(), source)
+	if err != nil {
+		t.Fatalf("GenerateFromSpecs() error = %v", err)
+	}
+	for _, want := range []string{
+		`"slices"`,
+		`"sort"`,
+		"if len(result) != 3 {",
+		`if !slices.Contains(result, "rust") {`,
+		`case "go", "rust", "zig":`,
+		"sort.SliceIsSorted(result, func(i, j int) bool { return result[i] < result[j] })",
+		"sort.SliceIsSorted(result, func(i, j int) bool { return result[i].Age > result[j].Age })",
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("generated code missing %q\n%s", want, code)
+		}
+	}
+	if _, err := format.Source([]byte(code)); err != nil {
+		t.Errorf("generated code doesn't parse: %v\n%s", err, code)
+	}
+}
+
+func TestRustSpecAdapter_Collections(t *testing.T) {
+	code, err := NewRustSpecAdapter().GenerateFromSpecs(collectionSpecs(), "src/lib.rs")
+	if err != nil {
+		t.Fatalf("GenerateFromSpecs() error = %v", err)
+	}
+	for _, want := range []string{
+		"assert_eq!(result.len(), 3);",
+		`assert!(["go", "rust"].iter().all(|want| result.iter().any(|got| got == want)));`,
+		`assert!(result.iter().all(|got| ["go", "rust", "zig"].iter().any(|want| got == want)));`,
+		"assert!(result.windows(2).all(|w| w[0] <= w[1]));",
+		"assert!(result.windows(2).all(|w| w[0].age >= w[1].age));",
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("generated code missing %q\n%s", want, code)
+		}
+	}
+}
+
+func TestAssertion_SortKey(t *testing.T) {
+	tests := []struct {
+		expected interface{}
+		key      string
+		desc     bool
+	}{
+		{nil, "", false},
+		{"desc", "", true},
+		{"name", "name", false},
+		{"-created_at", "created_at", true},
+	}
+	for _, tt := range tests {
+		key, desc := model.Assertion{Kind: model.AssertSorted, Expected: tt.expected}.SortKey()
+		if key != tt.key || desc != tt.desc {
+			t.Errorf("SortKey(%v) = %q, %v, want %q, %v", tt.expected, key, desc, tt.key, tt.desc)
+		}
+	}
+}
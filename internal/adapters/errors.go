@@ -0,0 +1,29 @@
+package adapters
+
+import "github.com/QTest-hq/qtest/pkg/model"
+
+// expectsError reports whether a spec expects its call to fail
+func expectsError(spec model.TestSpec) bool {
+	for _, a := range spec.Assertions {
+		if a.ExpectsError() {
+			return true
+		}
+	}
+	return false
+}
+
+// renderedAssertions returns the assertions of a spec to render. When the
+// spec expects its call to fail only the error assertions are kept: a
+// failed call has no result to assert on.
+func renderedAssertions(spec model.TestSpec) []model.Assertion {
+	if !expectsError(spec) {
+		return spec.Assertions
+	}
+	var kept []model.Assertion
+	for _, a := range spec.Assertions {
+		if a.ExpectsError() {
+			kept = append(kept, a)
+		}
+	}
+	return kept
+}
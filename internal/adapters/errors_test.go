@@ -0,0 +1,182 @@
+package adapters
+
+import (
+	"go/format"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/QTest-hq/qtest/pkg/model"
+)
+
+func TestJestSpecAdapter_Throws(t *testing.T) {
+	specs := []model.TestSpec{
+		{
+			FunctionName: "parseAge",
+			Description:  "rejects text",
+			Inputs:       map[string]interface{}{"value": "abc"},
+			ArgOrder:     []string{"value"},
+			Assertions: []model.Assertion{
+				{Kind: "throws", ErrorType: "TypeError", ErrorMessage: "not a number"},
+				{Kind: "equality", Actual: "result", Expected: float64(0)},
+			},
+		},
+		{
+			FunctionName: "loadUser",
+			Description:  "rejects a missing user",
+			Async:        true,
+			Inputs:       map[string]interface{}{"id": float64(-1)},
+			ArgOrder:     []string{"id"},
+			Assertions:   []model.Assertion{{Kind: "raises", ErrorType: "NotFoundError"}},
+		},
+	}
+	code, err := NewJestSpecAdapter().GenerateFromSpecs(specs, "users.ts")
+	if err != nil {
+		t.Fatalf("GenerateFromSpecs() error = %v", err)
+	}
+
+	for _, want := range []string{
+		"const result = () => parseAge(value);",
+		"expect(result).toThrow(TypeError);",
+		`expect(result).toThrow("not a number");`,
+		"const result = loadUser(id);",
+		"await expect(result).rejects.toThrow(NotFoundError);",
+		"NotFoundError } from './users'",
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("generated code missing %q\n%s", want, code)
+		}
+	}
+	if strings.Contains(code, "toBe(0)") || strings.Contains(code, "TypeError }") {
+		t.Errorf("generated code asserts on a thrown call's result or imports a builtin\n%s", code)
+	}
+}
+
+func TestPytestSpecAdapter_Raises(t *testing.T) {
+	specs := []model.TestSpec{
+		{
+			FunctionName: "divide",
+			Description:  "divides by zero",
+			Inputs:       map[string]interface{}{"a": float64(1), "b": float64(0)},
+			ArgOrder:     []string{"a", "b"},
+			Assertions:   []model.Assertion{{Kind: "raises", ErrorType: "ZeroDivisionError", ErrorMessage: "division by zero"}},
+		},
+		{
+			FunctionName: "withdraw",
+			Description:  "overdraws",
+			Async:        true,
+			Inputs:       map[string]interface{}{"amount": float64(500)},
+			ArgOrder:     []string{"amount"},
+			Assertions:   []model.Assertion{{Kind: "throws", ErrorType: "InsufficientFunds"}},
+		},
+	}
+	code, err := NewPytestSpecAdapter().GenerateFromSpecs(specs, "bank.py")
+	if err != nil {
+		t.Fatalf("GenerateFromSpecs() error = %v", err)
+	}
+
+	for _, want := range []string{
+		"from bank import divide, withdraw, InsufficientFunds",
+		"with pytest.raises(ZeroDivisionError) as excinfo:\n            divide(a, b)",
+		`assert "division by zero" in str(excinfo.value)`,
+		"with pytest.raises(InsufficientFunds):\n            await withdraw(amount)",
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("generated code missing %q\n%s", want, code)
+		}
+	}
+	if strings.Contains(code, "TODO") {
+		t.Errorf("pytest.raises should be the assertion\n%s", code)
+	}
+}
+
+func TestGoSpecAdapter_Errors(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "store.go")
+	os.WriteFile(source, []byte(`package store
+
+func Get(key string) (string, error) { return "", nil }
+
+func Delete(key string) error { return nil }
+
+func MustGet(key string) string { return "" }
+`), 0644)
+
+	specs := []model.TestSpec{
+		{
+			FunctionName: "Get",
+			Description:  "missing key",
+			Inputs:       map[string]interface{}{"key": "nope"},
+			ArgOrder:     []string{"key"},
+			Assertions: []model.Assertion{
+				{Kind: "throws", ErrorType: "ErrNotFound", ErrorMessage: "nope"},
+				{Kind: "equality", Actual: "result", Expected: ""},
+			},
+		},
+		{
+			FunctionName: "Delete",
+			Description:  "bad key",
+			Inputs:       map[string]interface{}{"key": ""},
+			ArgOrder:     []string{"key"},
+			Assertions:   []model.Assertion{{Kind: "throws", ErrorType: "*KeyError"}},
+		},
+		{
+			FunctionName: "MustGet",
+			Description:  "panics on a missing key",
+			Inputs:       map[string]interface{}{"key": "nope"},
+			ArgOrder:     []string{"key"},
+			Assertions:   []model.Assertion{{Kind: "throws", ErrorMessage: "missing"}},
+		},
+	}
+	code, err := NewGoSpecAdapter().GenerateFromSpecs(specs, source)
+	if err != nil {
+		t.Fatalf("GenerateFromSpecs() error = %v", err)
+	}
+
+	for _, want := range []string{
+		`_, err := Get(key)`,
+		`if !errors.Is(err, ErrNotFound) {`,
+		`if !strings.Contains(err.Error(), "nope") {`,
+		`err := Delete(key)`,
+		`var wantErr *KeyError`,
+		`if !errors.As(err, &wantErr) {`,
+		`r := recover()`,
+		`if !strings.Contains(fmt.Sprint(r), "missing") {`,
+		`"errors"`,
+		`"fmt"`,
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("generated code missing %q\n%s", want, code)
+		}
+	}
+	if strings.Contains(code, "result") {
+		t.Errorf("generated code uses the result of a failing call\n%s", code)
+	}
+	if _, err := format.Source([]byte(code)); err != nil {
+		t.Errorf("generated code doesn't parse: %v\n%s", err, code)
+	}
+}
+
+func TestRustSpecAdapter_Errors(t *testing.T) {
+	specs := []model.TestSpec{{
+		FunctionName: "parse",
+		Description:  "rejects empty input",
+		Inputs:       map[string]interface{}{"input": ""},
+		InputTypes:   map[string]string{"input": "string"},
+		Assertions:   []model.Assertion{{Kind: "throws", ErrorType: "ParseError::Empty", ErrorMessage: "empty"}},
+	}}
+	code, err := NewRustSpecAdapter().GenerateFromSpecs(specs, "src/lib.rs")
+	if err != nil {
+		t.Fatalf("GenerateFromSpecs() error = %v", err)
+	}
+
+	for _, want := range []string{
+		"assert!(matches!(result, Err(ParseError::Empty { .. })));",
+		`assert!(matches!(&result, Err(e) if e.to_string().contains("empty")));`,
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("generated code missing %q\n%s", want, code)
+		}
+	}
+}
@@ -39,9 +39,13 @@ import (
 {{end}}
 )
 
+{{if .Helpers}}
+{{.Helpers}}{{end}}{{if .Golden}}
+{{.Golden}}{{end}}
 {{range .Tests}}
 func Test{{.FunctionName}}(t *testing.T) {
-	{{if .Setup}}// Setup
+	{{if $.Helpers}}stubHTTP(t)
+	{{end}}{{if .Setup}}// Setup
 	{{.Setup}}
 	{{end}}
 	var result interface{}
@@ -60,6 +64,8 @@ func Test{{.FunctionName}}(t *testing.T) {
 type goTemplateData struct {
 	Package string
 	Imports []string
+	Helpers string
+	Golden  string // the golden-file helper, for snapshot steps
 	Tests   []goTestData
 }
 
@@ -83,6 +89,17 @@ func (a *GoAdapter) Generate(test *dsl.TestDSL) (string, error) {
 		Tests:   make([]goTestData, 0),
 	}
 
+	// Replay the code's outbound HTTP calls from a local server
+	if source, err := os.ReadFile(test.Target.File); err == nil {
+		helper, imports := goHTTPFixture(string(source))
+		data.Helpers = helper
+		data.Imports = append(data.Imports, imports...)
+	}
+	if hasSnapshotSteps(test) {
+		data.Golden = goGoldenHelper
+		data.Imports = uniqueImports(append(data.Imports, goGoldenImports...))
+	}
+
 	// Convert test name to function name
 	funcName := toGoFunctionName(test.Name)
 	if funcName == "" {
@@ -101,15 +118,46 @@ func (a *GoAdapter) Generate(test *dsl.TestDSL) (string, error) {
 		}
 	}
 
+	// Methods are called on a value of their type, built once per test
+	var callTargets []string
+	for _, step := range test.Steps {
+		if step.Action.Type == dsl.ActionCall {
+			callTargets = append(callTargets, step.Action.Target)
+		}
+	}
+	receivers := goMethodReceivers(test.Target.File, callTargets)
+	recvVars := make(map[string]string) // receiver type to variable
+	usedVars := make(map[string]bool)
+	var recvSetup strings.Builder
+
 	// Process steps
 	for _, step := range test.Steps {
+		if recv := receivers[step.Action.Target]; recv != nil && step.Action.Type == dsl.ActionCall {
+			name, ok := recvVars[recv.Type]
+			if !ok {
+				name = recv.varName(nil, "")
+				if usedVars[name] {
+					name = fmt.Sprintf("%s%d", name, len(recvVars)+1)
+				}
+				recvVars[recv.Type] = name
+				usedVars[name] = true
+				recvSetup.WriteString(strings.ReplaceAll(recv.setup(name, ""), "\n\t\t", "\n\t") + "\n\t")
+			}
+			step.Action.Target = name + "." + recv.Method
+		}
+
 		stepData := goStepData{
 			Description: step.Description,
 			Assertions:  make([]string, 0),
 		}
 
 		// Generate action
-		if step.Action.Type != "" {
+		switch step.Action.Type {
+		case "":
+		case dsl.ActionSnapshot:
+			stepData.Assertions = append(stepData.Assertions,
+				fmt.Sprintf("assertGolden(t, %s)", snapshotSubject(step.Action)))
+		default:
 			stepData.Action = generateGoStepAction(step)
 		}
 
@@ -122,6 +170,8 @@ func (a *GoAdapter) Generate(test *dsl.TestDSL) (string, error) {
 		testData.Steps = append(testData.Steps, stepData)
 	}
 
+	testData.Setup = recvSetup.String() + testData.Setup
+
 	data.Tests = append(data.Tests, testData)
 
 	// Execute template
@@ -153,8 +203,8 @@ func extractPackageName(filePath string) string {
 		}
 	}
 
-	// Fallback: extract from directory name
-	parts := strings.Split(filePath, "/")
+	// Fallback: extract from directory name, with either separator
+	parts := strings.FieldsFunc(filePath, func(r rune) bool { return r == '/' || r == '\\' })
 	if len(parts) > 0 {
 		for i := len(parts) - 1; i >= 0; i-- {
 			if parts[i] != "" && !strings.Contains(parts[i], ".") {
@@ -0,0 +1,345 @@
+package adapters
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// goMock is a hand-rolled mock of an interface the package declares
+type goMock struct {
+	Interface string
+	Name      string // mock type, e.g. mockStore
+	Methods   []goMockMethod
+}
+
+// goMockMethod is a method of a mocked interface, with its types as source
+type goMockMethod struct {
+	Name    string
+	Params  []string
+	Results []string
+}
+
+// goMocks are the mocks a test file needs for its functions' interface
+// parameters
+type goMocks struct {
+	Mocks   []goMock
+	Imports []string
+	// Params maps a function to its mocked parameters, by name and by
+	// position (arg0, arg1, ...), and the mock type each gets
+	Params map[string]map[string]string
+}
+
+// goInterface is an interface declared in the package under test
+type goInterface struct {
+	Type    *ast.InterfaceType
+	Imports map[string]string // local name to path, in the declaring file
+}
+
+// detectGoInterfaceParams finds the parameters of funcs, in sourceFile,
+// whose type is an interface declared in the package, and builds a mock for
+// each such interface. Interfaces embedding ones from other packages, or
+// with type parameters, aren't mocked: their methods aren't known here.
+func detectGoInterfaceParams(sourceFile string, funcs []string) *goMocks {
+	dir := filepath.Dir(sourceFile)
+	fset := token.NewFileSet()
+	source, err := parser.ParseFile(fset, sourceFile, nil, 0)
+	if err != nil {
+		return nil
+	}
+
+	interfaces := make(map[string]goInterface)
+	declared := make(map[string]bool)
+	entries, _ := os.ReadDir(dir)
+	ownTest := strings.TrimSuffix(filepath.Base(sourceFile), ".go") + "_test.go"
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, ".go") || name == ownTest {
+			continue
+		}
+		file := source
+		if name != filepath.Base(sourceFile) {
+			if file, err = parser.ParseFile(fset, filepath.Join(dir, name), nil, 0); err != nil {
+				continue
+			}
+			if file.Name.Name != source.Name.Name && file.Name.Name != source.Name.Name+"_test" {
+				continue
+			}
+		}
+		collectGoDecls(file, interfaces, declared, !strings.HasSuffix(name, "_test.go"))
+	}
+
+	wanted := make(map[string]bool, len(funcs))
+	for _, fn := range funcs {
+		wanted[fn] = true
+	}
+
+	mocks := &goMocks{Params: make(map[string]map[string]string)}
+	byInterface := make(map[string]string)
+	imports := make(map[string]bool)
+	for _, decl := range source.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || !wanted[fn.Name.Name] {
+			continue
+		}
+		pos := 0
+		for _, field := range fn.Type.Params.List {
+			names := []string{""}
+			if len(field.Names) > 0 {
+				names = names[:0]
+				for _, n := range field.Names {
+					names = append(names, n.Name)
+				}
+			}
+			ident, isIdent := field.Type.(*ast.Ident)
+			for _, param := range names {
+				pos++
+				if !isIdent {
+					continue
+				}
+				mockName, ok := byInterface[ident.Name]
+				if !ok {
+					mock, deps, ok := buildGoMock(ident.Name, interfaces, declared)
+					if !ok {
+						byInterface[ident.Name] = ""
+						continue
+					}
+					mockName = mock.Name
+					byInterface[ident.Name] = mockName
+					mocks.Mocks = append(mocks.Mocks, mock)
+					for _, imp := range deps {
+						imports[imp] = true
+					}
+				}
+				if mockName == "" {
+					continue
+				}
+				if mocks.Params[fn.Name.Name] == nil {
+					mocks.Params[fn.Name.Name] = make(map[string]string)
+				}
+				if param != "" && param != "_" {
+					mocks.Params[fn.Name.Name][param] = mockName
+				}
+				mocks.Params[fn.Name.Name]["arg"+strconv.Itoa(pos-1)] = mockName
+			}
+		}
+	}
+	if len(mocks.Mocks) == 0 {
+		return nil
+	}
+
+	for imp := range imports {
+		mocks.Imports = append(mocks.Imports, imp)
+	}
+	sort.Strings(mocks.Imports)
+	return mocks
+}
+
+// collectGoDecls records a file's interfaces, and the names it declares so
+// mocks don't collide with them. Interfaces are only taken from non-test
+// files.
+func collectGoDecls(file *ast.File, interfaces map[string]goInterface, declared map[string]bool, withInterfaces bool) {
+	fileImports := make(map[string]string)
+	for _, imp := range file.Imports {
+		p, _ := strconv.Unquote(imp.Path.Value)
+		name := goImportName(p)
+		if imp.Name != nil {
+			name = imp.Name.Name
+		}
+		fileImports[name] = p
+	}
+
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if d.Recv == nil {
+				declared[d.Name.Name] = true
+			}
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					declared[s.Name.Name] = true
+					if it, ok := s.Type.(*ast.InterfaceType); ok && withInterfaces && s.TypeParams == nil {
+						interfaces[s.Name.Name] = goInterface{Type: it, Imports: fileImports}
+					}
+				case *ast.ValueSpec:
+					for _, n := range s.Names {
+						declared[n.Name] = true
+					}
+				}
+			}
+		}
+	}
+}
+
+// buildGoMock builds the mock of a package interface, with the import paths
+// its method signatures use
+func buildGoMock(iface string, interfaces map[string]goInterface, declared map[string]bool) (goMock, []string, bool) {
+	mock := goMock{Interface: iface, Name: "mock" + strings.ToUpper(iface[:1]) + iface[1:]}
+	for declared[mock.Name] {
+		mock.Name = "qtest" + strings.ToUpper(mock.Name[:1]) + mock.Name[1:]
+	}
+	declared[mock.Name] = true
+
+	var imports []string
+	seen := make(map[string]bool)
+	ok := addGoMockMethods(&mock, iface, interfaces, seen, &imports)
+	if !ok || len(mock.Methods) == 0 {
+		return goMock{}, nil, false
+	}
+	sort.Slice(mock.Methods, func(i, j int) bool { return mock.Methods[i].Name < mock.Methods[j].Name })
+	return mock, imports, true
+}
+
+// addGoMockMethods adds an interface's methods, and those of the package
+// interfaces it embeds, to a mock
+func addGoMockMethods(mock *goMock, iface string, interfaces map[string]goInterface, seen map[string]bool, imports *[]string) bool {
+	if seen[iface] {
+		return true
+	}
+	seen[iface] = true
+	decl, ok := interfaces[iface]
+	if !ok {
+		return false
+	}
+
+	for _, field := range decl.Type.Methods.List {
+		switch t := field.Type.(type) {
+		case *ast.FuncType:
+			method := goMockMethod{Name: field.Names[0].Name}
+			for _, p := range fieldTypes(t.Params) {
+				method.Params = append(method.Params, types.ExprString(p))
+			}
+			for _, r := range fieldTypes(t.Results) {
+				method.Results = append(method.Results, types.ExprString(r))
+			}
+			for _, pkg := range goSelectorPackages(t) {
+				p, ok := decl.Imports[pkg]
+				if !ok || goImportName(p) != pkg {
+					return false // an aliased or unknown import the test can't name
+				}
+				*imports = append(*imports, p)
+			}
+			mock.Methods = append(mock.Methods, method)
+		case *ast.Ident:
+			if !addGoMockMethods(mock, t.Name, interfaces, seen, imports) {
+				return false
+			}
+		default:
+			return false // embeds another package's interface
+		}
+	}
+	return true
+}
+
+// fieldTypes returns the type of each parameter or result in a list
+func fieldTypes(list *ast.FieldList) []ast.Expr {
+	if list == nil {
+		return nil
+	}
+	var out []ast.Expr
+	for _, f := range list.List {
+		n := len(f.Names)
+		if n == 0 {
+			n = 1
+		}
+		for i := 0; i < n; i++ {
+			out = append(out, f.Type)
+		}
+	}
+	return out
+}
+
+// goSelectorPackages returns the packages a function type refers to
+func goSelectorPackages(t *ast.FuncType) []string {
+	var pkgs []string
+	ast.Inspect(t, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if x, ok := sel.X.(*ast.Ident); ok {
+				pkgs = append(pkgs, x.Name)
+			}
+		}
+		return true
+	})
+	return pkgs
+}
+
+// goImportName is the name a package is imported as by default, skipping
+// a major version suffix
+func goImportName(importPath string) string {
+	name := path.Base(importPath)
+	if len(name) > 1 && name[0] == 'v' && strings.Trim(name[1:], "0123456789") == "" {
+		name = path.Base(path.Dir(importPath))
+	}
+	return strings.ReplaceAll(name, "-", "_")
+}
+
+// Code returns the mock's Go source: a struct with a func field per method,
+// which the method calls when set, and a record of the calls made. Methods
+// whose func field is nil return zero values.
+func (m goMock) Code() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "// %s mocks %s. Set a method's Func field to stub it; Calls records the\n", m.Name, m.Interface)
+	sb.WriteString("// methods called.\n")
+	fmt.Fprintf(&sb, "type %s struct {\n", m.Name)
+	for _, method := range m.Methods {
+		fmt.Fprintf(&sb, "\t%sFunc func(%s)%s\n", method.Name, strings.Join(method.Params, ", "), resultList(method.Results, false))
+	}
+	sb.WriteString("\tCalls []string\n}\n")
+
+	for _, method := range m.Methods {
+		params := make([]string, len(method.Params))
+		args := make([]string, len(method.Params))
+		for i, p := range method.Params {
+			params[i] = fmt.Sprintf("a%d %s", i, p)
+			args[i] = fmt.Sprintf("a%d", i)
+			if strings.HasPrefix(p, "...") {
+				args[i] += "..."
+			}
+		}
+		call := fmt.Sprintf("m.%sFunc(%s)", method.Name, strings.Join(args, ", "))
+
+		fmt.Fprintf(&sb, "\nfunc (m *%s) %s(%s)%s {\n", m.Name, method.Name, strings.Join(params, ", "), resultList(method.Results, true))
+		fmt.Fprintf(&sb, "\tm.Calls = append(m.Calls, %q)\n", method.Name)
+		fmt.Fprintf(&sb, "\tif m.%sFunc != nil {\n", method.Name)
+		if len(method.Results) > 0 {
+			fmt.Fprintf(&sb, "\t\treturn %s\n", call)
+		} else {
+			fmt.Fprintf(&sb, "\t\t%s\n", call)
+		}
+		sb.WriteString("\t}\n")
+		if len(method.Results) > 0 {
+			sb.WriteString("\treturn\n")
+		}
+		sb.WriteString("}\n")
+	}
+	return sb.String()
+}
+
+// resultList formats a method's results, named r0, r1, ... so a bare
+// return gives zero values
+func resultList(results []string, named bool) string {
+	if len(results) == 0 {
+		return ""
+	}
+	if !named && len(results) == 1 {
+		return " " + results[0]
+	}
+	out := make([]string, len(results))
+	for i, r := range results {
+		out[i] = r
+		if named {
+			out[i] = fmt.Sprintf("r%d %s", i, r)
+		}
+	}
+	return " (" + strings.Join(out, ", ") + ")"
+}
@@ -0,0 +1,139 @@
+package adapters
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/QTest-hq/qtest/pkg/model"
+)
+
+const goMocksSource = `package users
+
+import (
+	"context"
+	"io"
+)
+
+type User struct{ Name string }
+
+type Logger interface {
+	Log(format string, args ...interface{})
+}
+
+type Store interface {
+	Logger
+	Get(ctx context.Context, id string) (*User, error)
+	Save(u *User) error
+}
+
+type Source interface {
+	io.Reader
+}
+
+func Register(s Store, name string) error {
+	s.Log("saving %s", name)
+	return s.Save(&User{Name: name})
+}
+
+func Import(src Source, l Logger) error { return nil }
+`
+
+func writeGoMocksPackage(t *testing.T, extra map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{"users.go": goMocksSource}
+	for name, content := range extra {
+		files[name] = content
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return filepath.Join(dir, "users.go")
+}
+
+func TestDetectGoInterfaceParams(t *testing.T) {
+	sourceFile := writeGoMocksPackage(t, nil)
+
+	mocks := detectGoInterfaceParams(sourceFile, []string{"Register", "Import"})
+	if mocks == nil {
+		t.Fatal("detectGoInterfaceParams() = nil")
+	}
+
+	// Source embeds io.Reader, whose methods aren't known, so only Store
+	// and Logger are mocked
+	var names []string
+	for _, m := range mocks.Mocks {
+		names = append(names, m.Name)
+	}
+	if got := strings.Join(names, ","); got != "mockStore,mockLogger" {
+		t.Errorf("mocks = %s, want mockStore,mockLogger", got)
+	}
+	if got := mocks.Params["Register"]; got["s"] != "mockStore" || got["arg0"] != "mockStore" || got["name"] != "" {
+		t.Errorf("Register params = %v", got)
+	}
+	if got := mocks.Params["Import"]; got["src"] != "" || got["l"] != "mockLogger" || got["arg1"] != "mockLogger" {
+		t.Errorf("Import params = %v", got)
+	}
+	if got := strings.Join(mocks.Imports, ","); got != "context" {
+		t.Errorf("imports = %s, want context", got)
+	}
+
+	// Store's methods include the embedded Logger's
+	var methods []string
+	for _, m := range mocks.Mocks[0].Methods {
+		methods = append(methods, m.Name)
+	}
+	if got := strings.Join(methods, ","); got != "Get,Log,Save" {
+		t.Errorf("mockStore methods = %s, want Get,Log,Save", got)
+	}
+}
+
+func TestDetectGoInterfaceParams_NameTaken(t *testing.T) {
+	sourceFile := writeGoMocksPackage(t, map[string]string{
+		"helpers_test.go": "package users\n\ntype mockStore struct{}\n",
+	})
+
+	mocks := detectGoInterfaceParams(sourceFile, []string{"Register"})
+	if mocks == nil || mocks.Mocks[0].Name != "qtestMockStore" {
+		t.Fatalf("mocks = %+v, want qtestMockStore", mocks)
+	}
+}
+
+func TestGoSpecAdapter_InterfaceMocks(t *testing.T) {
+	sourceFile := writeGoMocksPackage(t, nil)
+
+	code, err := NewGoSpecAdapter().GenerateFromSpecs([]model.TestSpec{{
+		FunctionName: "Register",
+		Description:  "saves the user",
+		Inputs:       map[string]interface{}{"s": nil, "name": "bob"},
+		ArgOrder:     []string{"s", "name"},
+		Assertions:   []model.Assertion{{Kind: "equals", Actual: "result", Expected: nil}},
+	}}, sourceFile)
+	if err != nil {
+		t.Fatalf("GenerateFromSpecs() error = %v", err)
+	}
+
+	for _, want := range []string{
+		`"context"`,
+		"type mockStore struct {",
+		"GetFunc func(context.Context, string) (*User, error)",
+		"func (m *mockStore) Log(a0 string, a1 ...interface{}) {",
+		"m.LogFunc(a0, a1...)",
+		"func (m *mockStore) Save(a0 *User) (r0 error) {",
+		"s := &mockStore{}",
+		"result := Register(s, name)",
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("generated code missing %q:\n%s", want, code)
+		}
+	}
+	if _, err := parser.ParseFile(token.NewFileSet(), "users_test.go", code, 0); err != nil {
+		t.Errorf("generated code doesn't parse: %v\n%s", err, code)
+	}
+}
@@ -0,0 +1,346 @@
+package adapters
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// goReceiver is the value a test calls a method on, and how the test
+// builds it: with the type's constructor when one can be called with zero
+// values, or else as the type's zero value
+type goReceiver struct {
+	Type    string // the receiver's type, e.g. Store
+	Method  string
+	Pointer bool // the method has a pointer receiver
+	Struct  bool
+	Ctor    *goConstructor
+	Unset   []string // fields the zero value leaves nil, such as maps
+}
+
+// goConstructor is a function building a receiver type, e.g. NewStore
+type goConstructor struct {
+	Name   string
+	Params []ast.Expr
+	Err    bool // the last result is an error
+	types  map[string]goTypeDecl
+}
+
+// goTypeDecl is a type the package under test declares
+type goTypeDecl struct {
+	Kind  string   // struct, interface or other
+	Unset []string // struct fields whose zero value is unusable
+}
+
+// goMethodReceivers finds which of funcs are methods in sourceFile's
+// package, named by the method, e.g. Get, or by type and method, e.g.
+// Store.Get, and how a test gets a value to call each on. A bare name is
+// only taken for a method when no function has it and one type, preferring
+// those in sourceFile, does.
+func goMethodReceivers(sourceFile string, funcs []string) map[string]*goReceiver {
+	dir := filepath.Dir(sourceFile)
+	fset := token.NewFileSet()
+	source, err := parser.ParseFile(fset, sourceFile, nil, 0)
+	if err != nil {
+		return nil
+	}
+
+	type method struct {
+		recv   string
+		ptr    bool
+		inFile bool
+	}
+	methods := make(map[string][]method) // by method name
+	functions := make(map[string]*ast.FuncDecl)
+	typeDecls := make(map[string]goTypeDecl)
+
+	entries, _ := os.ReadDir(dir)
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		file := source
+		if name != filepath.Base(sourceFile) {
+			if file, err = parser.ParseFile(fset, filepath.Join(dir, name), nil, 0); err != nil || file.Name.Name != source.Name.Name {
+				continue
+			}
+		}
+
+		for _, decl := range file.Decls {
+			switch d := decl.(type) {
+			case *ast.FuncDecl:
+				if d.Recv == nil {
+					functions[d.Name.Name] = d
+					continue
+				}
+				recv, ptr, ok := goReceiverType(d.Recv)
+				if ok {
+					methods[d.Name.Name] = append(methods[d.Name.Name], method{recv, ptr, file == source})
+				}
+			case *ast.GenDecl:
+				for _, spec := range d.Specs {
+					if s, ok := spec.(*ast.TypeSpec); ok && s.TypeParams == nil {
+						typeDecls[s.Name.Name] = goTypeDeclOf(s)
+					}
+				}
+			}
+		}
+	}
+
+	receivers := make(map[string]*goReceiver)
+	for _, fn := range funcs {
+		typeName, methodName, qualified := strings.Cut(fn, ".")
+		if !qualified {
+			methodName, typeName = fn, ""
+			if functions[fn] != nil {
+				continue
+			}
+		}
+
+		var found []method
+		for _, m := range methods[methodName] {
+			if typeName == "" || m.recv == typeName {
+				found = append(found, m)
+			}
+		}
+		if len(found) > 1 {
+			var inFile []method
+			for _, m := range found {
+				if m.inFile {
+					inFile = append(inFile, m)
+				}
+			}
+			found = inFile
+		}
+		if len(found) != 1 {
+			continue
+		}
+
+		m := found[0]
+		decl, known := typeDecls[m.recv]
+		if !known {
+			continue // a generic type, or one the package doesn't declare
+		}
+		receivers[fn] = &goReceiver{
+			Type:    m.recv,
+			Method:  methodName,
+			Pointer: m.ptr,
+			Struct:  decl.Kind == "struct",
+			Ctor:    goFindConstructor(m.recv, functions, typeDecls),
+			Unset:   decl.Unset,
+		}
+	}
+	return receivers
+}
+
+// goReceiverType returns the type name of a method's receiver, and whether
+// it's a pointer. Receivers of generic types aren't supported.
+func goReceiverType(recv *ast.FieldList) (string, bool, bool) {
+	if recv == nil || len(recv.List) != 1 {
+		return "", false, false
+	}
+	t := recv.List[0].Type
+	star, ptr := t.(*ast.StarExpr)
+	if ptr {
+		t = star.X
+	}
+	ident, ok := t.(*ast.Ident)
+	if !ok {
+		return "", false, false
+	}
+	return ident.Name, ptr, true
+}
+
+// goTypeDeclOf describes a type declaration, noting the struct fields a
+// zero value leaves unusable: maps panic when written, channels block
+func goTypeDeclOf(s *ast.TypeSpec) goTypeDecl {
+	switch t := s.Type.(type) {
+	case *ast.StructType:
+		decl := goTypeDecl{Kind: "struct"}
+		for _, field := range t.Fields.List {
+			switch field.Type.(type) {
+			case *ast.MapType, *ast.ChanType:
+				for _, n := range field.Names {
+					decl.Unset = append(decl.Unset, n.Name)
+				}
+			}
+		}
+		return decl
+	case *ast.InterfaceType:
+		return goTypeDecl{Kind: "interface"}
+	default:
+		return goTypeDecl{Kind: "other"}
+	}
+}
+
+// goFindConstructor finds the function building a type, NewT or New, whose
+// parameters can all be given zero values. It returns nil when there's
+// none.
+func goFindConstructor(typeName string, functions map[string]*ast.FuncDecl, typeDecls map[string]goTypeDecl) *goConstructor {
+	for _, name := range []string{"New" + strings.ToUpper(typeName[:1]) + typeName[1:], "New"} {
+		fn := functions[name]
+		if fn == nil || fn.Type.TypeParams != nil {
+			continue
+		}
+
+		results := fieldTypes(fn.Type.Results)
+		hasErr := false
+		if n := len(results); n == 2 {
+			last, ok := results[1].(*ast.Ident)
+			if !ok || last.Name != "error" {
+				continue
+			}
+			hasErr = true
+		} else if n != 1 {
+			continue
+		}
+		built := results[0]
+		if star, ok := built.(*ast.StarExpr); ok {
+			built = star.X
+		}
+		if ident, ok := built.(*ast.Ident); !ok || ident.Name != typeName {
+			continue
+		}
+
+		params := fieldTypes(fn.Type.Params)
+		viable := true
+		for _, p := range params {
+			if _, ok := goZeroValue(p, typeDecls, ""); !ok {
+				viable = false
+				break
+			}
+		}
+		if viable {
+			return &goConstructor{Name: name, Params: params, Err: hasErr, types: typeDecls}
+		}
+	}
+	return nil
+}
+
+// goBasicZeroValues are the zero values of the predeclared types
+var goBasicZeroValues = map[string]string{
+	"string": `""`, "bool": "false", "error": "nil", "any": "nil",
+	"int": "0", "int8": "0", "int16": "0", "int32": "0", "int64": "0",
+	"uint": "0", "uint8": "0", "uint16": "0", "uint32": "0", "uint64": "0", "uintptr": "0",
+	"float32": "0", "float64": "0", "complex64": "0", "complex128": "0",
+	"byte": "0", "rune": "0",
+}
+
+// goZeroValue renders the zero value of a parameter type, qualifying the
+// package's types with pkg. Types of other packages that aren't nillable
+// can't be rendered without their import.
+func goZeroValue(t ast.Expr, typeDecls map[string]goTypeDecl, pkg string) (string, bool) {
+	switch t := t.(type) {
+	case *ast.Ident:
+		if zero, ok := goBasicZeroValues[t.Name]; ok {
+			return zero, true
+		}
+		decl, ok := typeDecls[t.Name]
+		if !ok {
+			return "", false
+		}
+		name := t.Name
+		if pkg != "" {
+			name = pkg + "." + name
+		}
+		switch decl.Kind {
+		case "interface":
+			return "nil", true
+		case "struct":
+			return name + "{}", true
+		default:
+			return "*new(" + name + ")", true
+		}
+	case *ast.StarExpr, *ast.MapType, *ast.FuncType, *ast.ChanType, *ast.InterfaceType:
+		return "nil", true
+	case *ast.ArrayType:
+		return "nil", t.Len == nil
+	case *ast.Ellipsis:
+		return "", true // variadic: no argument
+	default:
+		return "", false
+	}
+}
+
+// varName picks the variable a test holds the receiver in, named for its
+// type unless that would shadow something the test uses
+func (r *goReceiver) varName(inputs map[string]interface{}, pkg string) string {
+	name := strings.ToLower(r.Type[:1]) + r.Type[1:]
+	_, isInput := inputs[name]
+	switch {
+	case isInput, token.IsKeyword(name), name == pkg,
+		name == "t", name == "result", name == "err", name == "setupErr":
+		return "recv"
+	}
+	return name
+}
+
+// setup renders the statements building the receiver in name, qualifying
+// the package's identifiers with pkg for tests outside it
+func (r *goReceiver) setup(name, pkg string) string {
+	qualify := func(ident string) string {
+		if pkg == "" {
+			return ident
+		}
+		return pkg + "." + ident
+	}
+
+	if r.Ctor != nil {
+		var args []string
+		for _, p := range r.Ctor.Params {
+			if zero, _ := goZeroValue(p, r.Ctor.types, pkg); zero != "" {
+				args = append(args, zero)
+			}
+		}
+		call := fmt.Sprintf("%s(%s)", qualify(r.Ctor.Name), strings.Join(args, ", "))
+		if !r.Ctor.Err {
+			return fmt.Sprintf("%s := %s", name, call)
+		}
+		return fmt.Sprintf(`%s, setupErr := %s
+		if setupErr != nil {
+			t.Fatalf("%s failed: %%v", setupErr)
+		}`, name, call, r.Ctor.Name)
+	}
+
+	var b strings.Builder
+	if len(r.Unset) > 0 {
+		fields := make([]string, len(r.Unset))
+		copy(fields, r.Unset)
+		sort.Strings(fields)
+		fmt.Fprintf(&b, "// TODO: Initialize %s, which the zero %s leaves nil\n\t\t", strings.Join(fields, ", "), r.Type)
+	}
+	switch {
+	case r.Struct && r.Pointer:
+		fmt.Fprintf(&b, "%s := &%s{}", name, qualify(r.Type))
+	case r.Struct:
+		fmt.Fprintf(&b, "%s := %s{}", name, qualify(r.Type))
+	default:
+		fmt.Fprintf(&b, "var %s %s", name, qualify(r.Type))
+	}
+	return b.String()
+}
+
+// exported reports whether a test outside the package can build the
+// receiver and call the method
+func (r *goReceiver) exported() bool {
+	if !ast.IsExported(r.Type) || !ast.IsExported(r.Method) {
+		return false
+	}
+	if r.Ctor == nil {
+		return true
+	}
+	for _, p := range r.Ctor.Params {
+		if ident, ok := p.(*ast.Ident); ok {
+			if _, local := r.Ctor.types[ident.Name]; local && !ast.IsExported(ident.Name) {
+				return false
+			}
+		}
+	}
+	return true
+}
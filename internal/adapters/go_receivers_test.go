@@ -0,0 +1,167 @@
+package adapters
+
+import (
+	"go/format"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/QTest-hq/qtest/pkg/dsl"
+	"github.com/QTest-hq/qtest/pkg/model"
+)
+
+// writeGoReceiverPackage writes a package whose types are built in each
+// way a test can get a receiver
+func writeGoReceiverPackage(t *testing.T) string {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "store.go"), []byte(`package store
+
+import "errors"
+
+var ErrNotFound = errors.New("not found")
+
+type Options struct{ Size int }
+
+type Store struct{ items map[string]string }
+
+func NewStore(opts Options, tags []string, name string) (*Store, error) { return &Store{}, nil }
+
+func (s *Store) Get(key string) (string, error) { return "", nil }
+
+type Counter struct {
+	n     int
+	marks map[string]bool
+}
+
+func (c *Counter) Inc() int { return 0 }
+
+type Celsius float64
+
+func (c Celsius) Fahrenheit() float64 { return 0 }
+`), 0644)
+	// A method of the same name in another file of the package
+	os.WriteFile(filepath.Join(dir, "cache.go"), []byte(`package store
+
+type Cache struct{}
+
+func (c Cache) Get(key string) (string, error) { return "", nil }
+`), 0644)
+	return filepath.Join(dir, "store.go")
+}
+
+func receiverSpecs() []model.TestSpec {
+	return []model.TestSpec{
+		{
+			FunctionName: "Get",
+			Description:  "finds a key",
+			Inputs:       map[string]interface{}{"key": "a"},
+			ArgOrder:     []string{"key"},
+			Assertions:   []model.Assertion{{Kind: "equality", Actual: "result", Expected: "one"}},
+		},
+		{
+			FunctionName: "Store.Get",
+			Description:  "misses a key",
+			Inputs:       map[string]interface{}{"key": "b"},
+			ArgOrder:     []string{"key"},
+			Assertions:   []model.Assertion{{Kind: "throws", ErrorType: "ErrNotFound"}},
+		},
+		{FunctionName: "Inc", Description: "counts", Assertions: []model.Assertion{{Kind: "equality", Actual: "result", Expected: float64(1)}}},
+		{FunctionName: "Fahrenheit", Description: "freezing", Assertions: []model.Assertion{{Kind: "equality", Actual: "result", Expected: float64(32)}}},
+	}
+}
+
+func TestGoSpecAdapter_Methods(t *testing.T) {
+	source := writeGoReceiverPackage(t)
+
+	code, err := NewGoSpecAdapter().GenerateFromSpecs(receiverSpecs(), source)
+	if err != nil {
+		t.Fatalf("GenerateFromSpecs() error = %v", err)
+	}
+	for _, want := range []string{
+		"func TestStore_Get(t *testing.T) {",
+		`store, setupErr := NewStore(Options{}, nil, "")`,
+		"result, err := store.Get(key)",
+		"_, err := store.Get(key)",
+		"// TODO: Initialize marks, which the zero Counter leaves nil",
+		"counter := &Counter{}",
+		"result := counter.Inc()",
+		"var celsius Celsius",
+		"result := celsius.Fahrenheit()",
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("generated code missing %q\n%s", want, code)
+		}
+	}
+	if n := strings.Count(code, "func TestStore_Get("); n != 1 {
+		t.Errorf("Get and Store.Get should be one test, got %d", n)
+	}
+	if _, err := format.Source([]byte(code)); err != nil {
+		t.Errorf("generated code doesn't parse: %v\n%s", err, code)
+	}
+
+	// From outside the package, the receiver's type and constructor are
+	// qualified, and the receiver isn't named after the package
+	code, err = (&GoSpecAdapter{ImportPath: "example.com/store"}).GenerateFromSpecs(receiverSpecs(), source)
+	if err != nil {
+		t.Fatalf("GenerateFromSpecs() error = %v", err)
+	}
+	for _, want := range []string{
+		"package store_test",
+		`recv, setupErr := store.NewStore(store.Options{}, nil, "")`,
+		"_, err := recv.Get(key)",
+		"errors.Is(err, store.ErrNotFound)",
+		"counter := &store.Counter{}",
+		"var celsius store.Celsius",
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("generated code missing %q\n%s", want, code)
+		}
+	}
+}
+
+func TestGoMethodReceivers(t *testing.T) {
+	source := writeGoReceiverPackage(t)
+
+	receivers := goMethodReceivers(source, []string{"Get", "Cache.Get", "Inc", "NewStore", "Missing"})
+	if recv := receivers["Get"]; recv == nil || recv.Type != "Store" {
+		t.Errorf("bare Get should prefer the source file's Store, got %+v", recv)
+	}
+	if recv := receivers["Cache.Get"]; recv == nil || recv.Type != "Cache" || recv.Pointer || recv.Ctor != nil {
+		t.Errorf("Cache.Get = %+v, want a value receiver without a constructor", recv)
+	}
+	if recv := receivers["Inc"]; recv == nil || !recv.Pointer || recv.Ctor != nil || len(recv.Unset) != 1 {
+		t.Errorf("Inc = %+v, want a pointer receiver with an unset map", recv)
+	}
+	if recv := receivers["Get"]; recv == nil || recv.Ctor == nil || recv.Ctor.Name != "NewStore" || !recv.Ctor.Err {
+		t.Errorf("Store should be built by NewStore, got %+v", recv)
+	}
+	for _, name := range []string{"NewStore", "Missing"} {
+		if recv, ok := receivers[name]; ok {
+			t.Errorf("%s isn't a method, got %+v", name, recv)
+		}
+	}
+}
+
+func TestGoAdapter_Methods(t *testing.T) {
+	source := writeGoReceiverPackage(t)
+	test := &dsl.TestDSL{
+		Name:   "counter counts",
+		Target: dsl.TestTarget{File: source, Function: "Inc"},
+		Steps: []dsl.TestStep{
+			{Description: "first", Action: dsl.StepAction{Type: dsl.ActionCall, Target: "Inc"}},
+			{Description: "second", Action: dsl.StepAction{Type: dsl.ActionCall, Target: "Inc"}},
+		},
+	}
+
+	code, err := NewGoAdapter().Generate(test)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if strings.Count(code, "counter := &Counter{}") != 1 || strings.Count(code, "result = counter.Inc()") != 2 {
+		t.Errorf("expected one counter called twice:\n%s", code)
+	}
+	if _, err := format.Source([]byte(code)); err != nil {
+		t.Errorf("generated code doesn't parse: %v\n%s", err, code)
+	}
+}
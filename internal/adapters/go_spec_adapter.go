@@ -3,6 +3,9 @@ package adapters
 import (
 	"bytes"
 	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
 	"os"
 	"sort"
 	"strconv"
@@ -13,7 +16,20 @@ import (
 )
 
 // GoSpecAdapter generates Go test code from model.TestSpec
-type GoSpecAdapter struct{}
+type GoSpecAdapter struct {
+	// ImportPath is the import path of the package under test. When it is
+	// set and the functions under test are exported, the tests go in the
+	// external _test package and call the functions through an import.
+	ImportPath string
+
+	// Chaos adds tests of functions making HTTP calls whose downstream
+	// times out, returns a 503 or a malformed body
+	Chaos bool
+
+	// Pure holds the target IDs of functions without side effects. Tests
+	// of them call t.Parallel(), unless they replace http.DefaultTransport.
+	Pure map[string]bool
+}
 
 func NewGoSpecAdapter() *GoSpecAdapter {
 	return &GoSpecAdapter{}
@@ -40,11 +56,21 @@ import (
 {{end}}
 )
 
-{{range .Tests}}
+{{if .Helpers}}
+{{.Helpers}}{{end}}{{if .Faults}}
+{{.Faults}}{{end}}{{if .Golden}}
+{{.Golden}}{{end}}{{if .Await}}
+{{.Await}}{{end}}{{range .Mocks}}
+{{.}}{{end}}
+{{range .Tests}}{{$parallel := and .Parallel (not $.Helpers)}}
 func Test{{.TestName}}(t *testing.T) {
-{{range .Cases}}
+{{if $parallel}}	t.Parallel()
+{{end}}{{if and $.Helpers (not .Faults)}}	stubHTTP(t)
+{{end}}{{range .Cases}}
 	t.Run("{{.Name}}", func(t *testing.T) {
-		{{if .Setup}}// Setup
+		{{if $parallel}}t.Parallel()
+
+		{{end}}{{if .Setup}}// Setup
 		{{.Setup}}
 		{{end}}
 		// Act
@@ -63,11 +89,18 @@ func Test{{.TestName}}(t *testing.T) {
 type goSpecTemplateData struct {
 	Package string
 	Imports []string
+	Helpers string
+	Faults  string   // the HTTP fault helper, when there are chaos tests
+	Golden  string   // the golden-file helper, when a test uses snapshots
+	Await   string   // the channel-receiving helper, when a function returns a channel
+	Mocks   []string // mocks of the package interfaces the functions take
 	Tests   []goSpecTestData
 }
 
 type goSpecTestData struct {
 	TestName string
+	Parallel bool // every spec targets a pure function
+	Faults   bool // the cases make HTTP calls fail
 	Cases    []goSpecCaseData
 }
 
@@ -84,12 +117,29 @@ func (a *GoSpecAdapter) GenerateFromSpecs(specs []model.TestSpec, sourceFile str
 		return "", fmt.Errorf("no test specs provided")
 	}
 
+	// Methods may be named bare, e.g. Get, or with their type, e.g.
+	// Store.Get; both are grouped under the latter
+	var named []string
+	seen := make(map[string]bool)
+	for _, spec := range specs {
+		if name := goSpecFuncName(spec); !seen[name] {
+			seen[name] = true
+			named = append(named, name)
+		}
+	}
+	receivers := make(map[string]*goReceiver)
+	canonical := make(map[string]string)
+	for name, recv := range goMethodReceivers(sourceFile, named) {
+		canonical[name] = recv.Type + "." + recv.Method
+		receivers[canonical[name]] = recv
+	}
+
 	// Group specs by target function
 	specsByFunc := make(map[string][]model.TestSpec)
 	for _, spec := range specs {
-		funcName := spec.FunctionName
-		if funcName == "" {
-			funcName = spec.TargetID
+		funcName := goSpecFuncName(spec)
+		if name, ok := canonical[funcName]; ok {
+			funcName = name
 		}
 		specsByFunc[funcName] = append(specsByFunc[funcName], spec)
 	}
@@ -100,43 +150,129 @@ func (a *GoSpecAdapter) GenerateFromSpecs(specs []model.TestSpec, sourceFile str
 		Tests:   make([]goSpecTestData, 0),
 	}
 
-	// Track if we need strings import
-	needsStrings := false
-	needsReflect := false
+	// Interface parameters get a mock instead of a literal. Mocks are found
+	// by the declared name, which for a method is the bare name.
+	funcNames := make([]string, 0, len(specsByFunc))
+	declNames := make([]string, 0, len(specsByFunc))
+	for funcName := range specsByFunc {
+		funcNames = append(funcNames, funcName)
+		declNames = append(declNames, goDeclName(funcName, receivers))
+	}
+	mocks := detectGoInterfaceParams(sourceFile, declNames)
+	errResults := goErrorResults(sourceFile, funcNames)
+
+	// Exported functions are tested from outside their package, as callers
+	// see them; unexported ones and those needing mocks are tested inside it
+	pkg := ""
+	if a.ImportPath != "" && data.Package != "main" && (mocks == nil || len(mocks.Mocks) == 0) && allExported(funcNames, receivers) {
+		pkg = data.Package
+		data.Package += "_test"
+		data.Imports = append(data.Imports, a.ImportPath)
+	}
+
+	// Functions whose HTTP calls chaos tests make fail
+	var callers map[string]string
+	source, readErr := os.ReadFile(sourceFile)
+	if a.Chaos && readErr == nil {
+		callers = goHTTPCallers(string(source))
+	}
+
+	// Packages the assertions use
+	used := make(map[string]bool)
+	needsGolden := false
+	needsAwait := false
 
 	// Build tests grouped by function
 	for funcName, funcSpecs := range specsByFunc {
 		testData := goSpecTestData{
 			TestName: toGoFunctionName(funcName),
+			Parallel: a.allPure(funcSpecs),
 			Cases:    make([]goSpecCaseData, 0),
 		}
+		// Methods are called on a value of their type, e.g. TestStore_Get
+		recv := receivers[funcName]
+		if recv != nil {
+			testData.TestName = toGoFunctionName(recv.Type) + "_" + toGoFunctionName(recv.Method)
+		}
+
+		// prepare names the function as a test calls it, and sets up its
+		// receiver and inputs
+		prepare := func(spec model.TestSpec) (model.TestSpec, string) {
+			var recvSetup string
+			switch {
+			case recv != nil:
+				name := recv.varName(spec.Inputs, pkg)
+				recvSetup = recv.setup(name, pkg)
+				spec.FunctionName = name + "." + recv.Method
+			case pkg != "":
+				spec.FunctionName = pkg + "." + funcName
+			}
+
+			// Generate setup from inputs with type hints
+			var setup string
+			var mocked map[string]string
+			if mocks != nil {
+				mocked = mocks.Params[goDeclName(funcName, receivers)]
+			}
+			if len(spec.Inputs) > 0 || len(mocked) > 0 {
+				setup = a.generateSetup(spec, mocked)
+			}
+			if recvSetup != "" {
+				setup = strings.TrimSuffix(recvSetup+"\n\t\t"+setup, "\n\t\t")
+			}
+			return spec, setup
+		}
 
 		for _, spec := range funcSpecs {
+			spec, setup := prepare(spec)
 			caseData := goSpecCaseData{
 				Name:       sanitizeTestName(spec.Description),
+				Setup:      setup,
 				Assertions: make([]string, 0),
 			}
 
-			// Generate setup from inputs with type hints
-			if len(spec.Inputs) > 0 {
-				caseData.Setup = a.generateSetup(spec)
-			}
-
 			// Generate action (function call)
 			caseData.Action = a.generateAction(spec)
+			if spec.Async {
+				needsAwait = true
+			}
+
+			// A call expected to fail returns an error, or panics when the
+			// function has no error result
+			results, known := errResults[funcName]
+			if !known {
+				results = 2
+			}
+			if expectsError(spec) && results == 0 {
+				var imports []string
+				caseData.Action, imports = a.generatePanicAction(spec)
+				markUsed(used, imports)
+				testData.Cases = append(testData.Cases, caseData)
+				continue
+			}
+			if expectsError(spec) {
+				caseData.Action = a.generateErrorAction(spec, results)
+			} else if known && results > 1 && !spec.Async {
+				caseData.Action = a.generateResultAction(spec, results)
+			}
 
 			// Generate assertions from spec.Assertions
-			for _, assertion := range spec.Assertions {
-				assertCode, usesStrings, usesReflect := a.generateAssertion(assertion)
-				if assertCode != "" {
+			for _, assertion := range renderedAssertions(spec) {
+				if assertion.ExpectsError() {
+					assertion.ErrorType = qualifyGoType(assertion.ErrorType, pkg)
+					assertCode, imports := goErrorAssertion(assertion)
 					caseData.Assertions = append(caseData.Assertions, assertCode)
+					markUsed(used, imports)
+					continue
 				}
-				if usesStrings {
-					needsStrings = true
+				if isSnapshotKind(assertion.Kind) {
+					needsGolden = true
 				}
-				if usesReflect {
-					needsReflect = true
+				assertCode, imports := a.generateAssertion(assertion)
+				if assertCode != "" {
+					caseData.Assertions = append(caseData.Assertions, assertCode)
 				}
+				markUsed(used, imports)
 			}
 
 			// If no assertions were generated, add a placeholder
@@ -148,15 +284,56 @@ func (a *GoSpecAdapter) GenerateFromSpecs(specs []model.TestSpec, sourceFile str
 		}
 
 		data.Tests = append(data.Tests, testData)
+
+		// Chaos tests make the function's HTTP calls fail, expecting an
+		// error result
+		if body, ok := callers[funcName]; ok && errResults[funcName] > 0 {
+			faultData := goSpecTestData{TestName: testData.TestName + "_DownstreamFaults", Faults: true}
+			for _, fault := range httpFaultsFor(body, goParsesJSON) {
+				spec, _ := faultSpec(funcSpecs, fault)
+				spec, setup := prepare(spec)
+				action := a.generateErrorAction(spec, errResults[funcName])
+				faultData.Cases = append(faultData.Cases, goFaultCase(fault, body, setup, action))
+			}
+			data.Tests = append(data.Tests, faultData)
+		}
 	}
 
 	// Add required imports
-	if needsStrings {
-		data.Imports = append(data.Imports, "strings")
+	for pkg := range used {
+		data.Imports = append(data.Imports, pkg)
+	}
+	sort.Strings(data.Imports)
+	if needsGolden {
+		data.Golden = goGoldenHelper
+		data.Imports = append(data.Imports, goGoldenImports...)
 	}
-	if needsReflect {
-		data.Imports = append(data.Imports, "reflect")
+	if needsAwait {
+		data.Await = goAwaitHelper
+		data.Imports = append(data.Imports, goAwaitImports...)
+	}
+
+	if mocks != nil {
+		for _, mock := range mocks.Mocks {
+			data.Mocks = append(data.Mocks, mock.Code())
+		}
+		data.Imports = append(data.Imports, mocks.Imports...)
+	}
+
+	// Replay the code's outbound HTTP calls from a local server
+	if readErr == nil {
+		helper, imports := goHTTPFixture(string(source))
+		data.Helpers = helper
+		data.Imports = append(data.Imports, imports...)
+	}
+	for _, test := range data.Tests {
+		if test.Faults {
+			data.Faults = goFaultHelper
+			data.Imports = append(data.Imports, goFaultImports...)
+			break
+		}
 	}
+	data.Imports = uniqueImports(data.Imports)
 
 	// Execute template
 	tmpl, err := template.New("gospec").Parse(goSpecTemplate)
@@ -172,8 +349,20 @@ func (a *GoSpecAdapter) GenerateFromSpecs(specs []model.TestSpec, sourceFile str
 	return buf.String(), nil
 }
 
-// generateSetup generates setup code from inputs with type hints
-func (a *GoSpecAdapter) generateSetup(spec model.TestSpec) string {
+// allPure reports whether every spec targets a pure function
+func (a *GoSpecAdapter) allPure(specs []model.TestSpec) bool {
+	for _, spec := range specs {
+		if !a.Pure[spec.TargetID] {
+			return false
+		}
+	}
+	return len(specs) > 0
+}
+
+// generateSetup generates setup code from inputs with type hints. Inputs
+// in mocked, and call arguments missing from the inputs, are set to a new
+// instance of their mock type.
+func (a *GoSpecAdapter) generateSetup(spec model.TestSpec, mocked map[string]string) string {
 	var setup strings.Builder
 
 	// Use ArgOrder if available, otherwise sort keys
@@ -208,6 +397,10 @@ func (a *GoSpecAdapter) generateSetup(spec model.TestSpec) string {
 
 	for _, key := range keys {
 		value, ok := spec.Inputs[key]
+		if mock, isMocked := mocked[key]; isMocked {
+			setup.WriteString(fmt.Sprintf("%s := &%s{}\n\t\t", key, mock))
+			continue
+		}
 		if !ok {
 			continue
 		}
@@ -222,6 +415,57 @@ func (a *GoSpecAdapter) generateSetup(spec model.TestSpec) string {
 
 // generateAction generates the function call
 func (a *GoSpecAdapter) generateAction(spec model.TestSpec) string {
+	// A function returning a channel is received from with a timeout
+	if spec.Async {
+		return fmt.Sprintf("result := awaitResult(t, %s)", a.generateCall(spec))
+	}
+	return "result := " + a.generateCall(spec)
+}
+
+// generateErrorAction generates the call of a function expected to return
+// an error as its last of results
+func (a *GoSpecAdapter) generateErrorAction(spec model.TestSpec, results int) string {
+	vars := strings.Repeat("_, ", max(results-1, 0)) + "err"
+	return fmt.Sprintf("%s := %s", vars, a.generateCall(spec))
+}
+
+// generateResultAction generates the call of a function expected to
+// succeed, whose first result is checked and last is an error
+func (a *GoSpecAdapter) generateResultAction(spec model.TestSpec, results int) string {
+	vars := "result, " + strings.Repeat("_, ", results-2) + "err"
+	return fmt.Sprintf(`%s := %s
+		if err != nil {
+			t.Fatalf("unexpected error: %%v", err)
+		}`, vars, a.generateCall(spec))
+}
+
+// generatePanicAction generates the call of a function expected to panic,
+// recovering to check it did, and the packages the check uses
+func (a *GoSpecAdapter) generatePanicAction(spec model.TestSpec) (string, []string) {
+	var checks strings.Builder
+	for _, assertion := range spec.Assertions {
+		if assertion.ExpectsError() && assertion.ErrorMessage != "" {
+			checks.WriteString(fmt.Sprintf(`
+			if !strings.Contains(fmt.Sprint(r), %q) {
+				t.Errorf("expected a panic containing %%q, got %%v", %q, r)
+			}`, assertion.ErrorMessage, assertion.ErrorMessage))
+		}
+	}
+	code := fmt.Sprintf(`defer func() {
+			r := recover()
+			if r == nil {
+				t.Fatal("expected a panic")
+			}%s
+		}()
+		%s`, checks.String(), a.generateCall(spec))
+	if checks.Len() == 0 {
+		return code, nil
+	}
+	return code, []string{"fmt", "strings"}
+}
+
+// generateCall generates the call expression of the function under test
+func (a *GoSpecAdapter) generateCall(spec model.TestSpec) string {
 	funcName := spec.FunctionName
 	if funcName == "" {
 		funcName = spec.TargetID
@@ -260,7 +504,7 @@ func (a *GoSpecAdapter) generateAction(spec model.TestSpec) string {
 		}
 	}
 
-	return fmt.Sprintf("result := %s(%s)", funcName, strings.Join(args, ", "))
+	return fmt.Sprintf("%s(%s)", funcName, strings.Join(args, ", "))
 }
 
 // formatGoValueWithType formats a value for Go code using type hints
@@ -312,8 +556,9 @@ func formatGoValueWithType(val interface{}, typeHint string) string {
 	}
 }
 
-// generateAssertion generates Go assertion code from model.Assertion
-func (a *GoSpecAdapter) generateAssertion(assertion model.Assertion) (code string, usesStrings, usesReflect bool) {
+// generateAssertion generates Go assertion code from model.Assertion, and
+// the packages it uses
+func (a *GoSpecAdapter) generateAssertion(assertion model.Assertion) (code string, imports []string) {
 	actual := assertion.Actual
 	if actual == "" {
 		actual = "result"
@@ -327,63 +572,113 @@ func (a *GoSpecAdapter) generateAssertion(assertion model.Assertion) (code strin
 		expected := formatGoValue(assertion.Expected)
 		return fmt.Sprintf(`if result != %s {
 			t.Errorf("%s: expected %%v, got %%v", %s, result)
-		}`, expected, escapedActual, expected), false, false
+		}`, expected, escapedActual, expected), nil
 
 	case "not_equal", "not_equals":
 		expected := formatGoValue(assertion.Expected)
 		return fmt.Sprintf(`if result == %s {
 			t.Errorf("%s: expected not %%v, but got %%v", %s, result)
-		}`, expected, escapedActual, expected), false, false
+		}`, expected, escapedActual, expected), nil
 
 	case "not_null", "not_nil", "is_not_nil":
 		return fmt.Sprintf(`if result == nil {
 			t.Error("%s: expected non-nil value, got nil")
-		}`, escapedActual), false, false
+		}`, escapedActual), nil
 
 	case "null", "nil", "is_nil":
 		return fmt.Sprintf(`if result != nil {
 			t.Errorf("%s: expected nil, got %%v", result)
-		}`, escapedActual), false, false
+		}`, escapedActual), nil
 
 	case "contains":
 		expected := formatGoValue(assertion.Expected)
 		// String contains check
 		return fmt.Sprintf(`if !strings.Contains(fmt.Sprintf("%%v", result), %s) {
 			t.Errorf("%s: expected to contain %%v, got %%v", %s, result)
-		}`, expected, escapedActual, expected), true, false
+		}`, expected, escapedActual, expected), []string{"fmt", "strings"}
 
 	case "greater_than":
 		expected := formatGoValue(assertion.Expected)
 		return fmt.Sprintf(`if result <= %s {
 			t.Errorf("%s: expected > %%v, got %%v", %s, result)
-		}`, expected, escapedActual, expected), false, false
+		}`, expected, escapedActual, expected), nil
 
 	case "less_than":
 		expected := formatGoValue(assertion.Expected)
 		return fmt.Sprintf(`if result >= %s {
 			t.Errorf("%s: expected < %%v, got %%v", %s, result)
-		}`, expected, escapedActual, expected), false, false
+		}`, expected, escapedActual, expected), nil
 
 	case "truthy":
 		return fmt.Sprintf(`if !result {
 			t.Error("%s: expected truthy value")
-		}`, escapedActual), false, false
+		}`, escapedActual), nil
 
 	case "falsy":
 		return fmt.Sprintf(`if result {
 			t.Error("%s: expected falsy value")
-		}`, escapedActual), false, false
+		}`, escapedActual), nil
 
-	case "throws", "error":
+	case "throws", "raises", "error":
 		return `if err == nil {
 			t.Error("expected error, got nil")
-		}`, false, false
+		}`, nil
+
+	case "snapshot", "matches_snapshot", "golden":
+		return "assertGolden(t, result)", nil
+
+	case model.AssertLength:
+		expected := formatGoValue(assertion.Expected)
+		return fmt.Sprintf(`if len(result) != %s {
+			t.Errorf("%s: expected length %%v, got %%d", %s, len(result))
+		}`, expected, escapedActual, expected), nil
+
+	case model.AssertContainsAll:
+		var checks []string
+		for _, item := range formatItems(assertion, formatGoValue) {
+			checks = append(checks, fmt.Sprintf(`if !slices.Contains(result, %s) {
+			t.Errorf("%s: expected to contain %%v", %s)
+		}`, item, escapedActual, item))
+		}
+		return strings.Join(checks, "\n\t\t"), []string{"slices"}
+
+	case model.AssertSubset:
+		items := strings.Join(formatItems(assertion, formatGoValue), ", ")
+		if items == "" {
+			return fmt.Sprintf(`if len(result) != 0 {
+			t.Errorf("%s: expected no items, got %%v", result)
+		}`, escapedActual), nil
+		}
+		return fmt.Sprintf(`for _, item := range result {
+			switch item {
+			case %s:
+			default:
+				t.Errorf("%s: unexpected item %%v", item)
+			}
+		}`, items, escapedActual), nil
+
+	case model.AssertSorted:
+		return fmt.Sprintf(`if !sort.SliceIsSorted(result, %s) {
+			t.Errorf("%s: expected sorted, got %%v", result)
+		}`, goSortedCheck("result", assertion), escapedActual), []string{"sort"}
+
+	case model.AssertApprox:
+		expected, eps := approxExpected(assertion), approxEpsilon(assertion)
+		return fmt.Sprintf(`if math.Abs(float64(result)-%s) > %s {
+			t.Errorf("%s: expected %%v ± %%v, got %%v", %s, %s, result)
+		}`, expected, eps, escapedActual, expected, eps), []string{"math"}
+
+	case model.AssertMatches:
+		pattern := strconv.Quote(matchesPattern(assertion))
+		return fmt.Sprintf(`if !regexp.MustCompile(%s).MatchString(fmt.Sprint(result)) {
+			t.Errorf("%s: expected to match %%s, got %%v", %s, result)
+		}`, pattern, escapedActual, pattern), []string{"fmt", "regexp"}
 
 	case "type", "type_is":
 		expected := assertion.Expected
 		return fmt.Sprintf(`if reflect.TypeOf(result).String() != %q {
 			t.Errorf("%s: expected type %%s, got %%s", %q, reflect.TypeOf(result).String())
-		}`, expected, escapedActual, expected), false, true
+		}`, expected, escapedActual, expected), []string{"reflect"}
 
 	default:
 		// For unknown kinds, generate a generic equality check
@@ -391,12 +686,25 @@ func (a *GoSpecAdapter) generateAssertion(assertion model.Assertion) (code strin
 			expected := formatGoValue(assertion.Expected)
 			return fmt.Sprintf(`if result != %s {
 			t.Errorf("%s: expected %%v, got %%v", %s, result)
-		}`, expected, escapedActual, expected), false, false
+		}`, expected, escapedActual, expected), nil
 		}
-		return "", false, false
+		return "", nil
 	}
 }
 
+// uniqueImports drops repeated imports, keeping the first of each
+func uniqueImports(imports []string) []string {
+	seen := make(map[string]bool, len(imports))
+	unique := imports[:0]
+	for _, imp := range imports {
+		if !seen[imp] {
+			seen[imp] = true
+			unique = append(unique, imp)
+		}
+	}
+	return unique
+}
+
 // escapeStringForErrorMsg escapes quotes and special characters for use in Go error message strings
 func escapeStringForErrorMsg(s string) string {
 	// Replace backslashes first, then quotes
@@ -483,3 +791,126 @@ func extractPackageNameFromFile(filePath string) string {
 	}
 	return "main"
 }
+
+// goErrorAssertion generates the check that a call returned the expected
+// error, and the packages it uses
+func goErrorAssertion(assertion model.Assertion) (code string, imports []string) {
+	var b strings.Builder
+	b.WriteString(`if err == nil {
+			t.Fatal("expected an error, got nil")
+		}`)
+
+	if errType := assertion.ErrorType; errType != "" {
+		imports = append(imports, "errors")
+		name := errType[strings.LastIndex(errType, ".")+1:]
+		if strings.HasSuffix(name, "Error") {
+			// An error type, e.g. *fs.PathError
+			b.WriteString(fmt.Sprintf(`
+		var wantErr %s
+		if !errors.As(err, &wantErr) {
+			t.Errorf("expected a %%s error, got %%T: %%v", %q, err, err)
+		}`, errType, errType))
+		} else {
+			// A sentinel error, e.g. ErrNotFound or io.EOF
+			b.WriteString(fmt.Sprintf(`
+		if !errors.Is(err, %s) {
+			t.Errorf("expected %%v, got %%v", %s, err)
+		}`, errType, errType))
+		}
+	}
+
+	if msg := assertion.ErrorMessage; msg != "" {
+		imports = append(imports, "strings")
+		b.WriteString(fmt.Sprintf(`
+		if !strings.Contains(err.Error(), %q) {
+			t.Errorf("expected an error containing %%q, got %%q", %q, err.Error())
+		}`, msg, msg))
+	}
+	return b.String(), imports
+}
+
+// goSpecFuncName is the function a spec targets, as it names it
+func goSpecFuncName(spec model.TestSpec) string {
+	if spec.FunctionName != "" {
+		return spec.FunctionName
+	}
+	return spec.TargetID
+}
+
+// goDeclName is the name a function or method is declared with
+func goDeclName(funcName string, receivers map[string]*goReceiver) string {
+	if recv := receivers[funcName]; recv != nil {
+		return recv.Method
+	}
+	return funcName
+}
+
+// allExported reports whether every one of funcs is an exported function,
+// or an exported method a test outside the package can call
+func allExported(funcs []string, receivers map[string]*goReceiver) bool {
+	for _, fn := range funcs {
+		if recv := receivers[fn]; recv != nil {
+			if !recv.exported() {
+				return false
+			}
+			continue
+		}
+		if strings.Contains(fn, ".") || !ast.IsExported(fn) {
+			return false
+		}
+	}
+	return len(funcs) > 0
+}
+
+// qualifyGoType qualifies an exported type or value of the package under
+// test with its package name, for tests outside the package: ErrNotFound
+// becomes pkg.ErrNotFound and *ParseError becomes *pkg.ParseError
+func qualifyGoType(name, pkg string) string {
+	bare := strings.TrimPrefix(name, "*")
+	if pkg == "" || strings.Contains(bare, ".") || !ast.IsExported(bare) {
+		return name
+	}
+	return name[:len(name)-len(bare)] + pkg + "." + bare
+}
+
+// markUsed adds imports to a set of used packages
+func markUsed(used map[string]bool, imports []string) {
+	for _, imp := range imports {
+		used[imp] = true
+	}
+}
+
+// goErrorResults finds how many results each of funcs, in sourceFile,
+// returns when its last result is an error, and 0 when it has no error
+// result. Functions not found are left out.
+func goErrorResults(sourceFile string, funcs []string) map[string]int {
+	file, err := parser.ParseFile(token.NewFileSet(), sourceFile, nil, 0)
+	if err != nil {
+		return nil
+	}
+
+	// Specs may name methods by their type, e.g. Store.Get
+	wanted := make(map[string][]string, len(funcs))
+	for _, fn := range funcs {
+		name := fn[strings.LastIndex(fn, ".")+1:]
+		wanted[name] = append(wanted[name], fn)
+	}
+
+	results := make(map[string]int)
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || len(wanted[fn.Name.Name]) == 0 {
+			continue
+		}
+		n := 0
+		if types := fieldTypes(fn.Type.Results); len(types) > 0 {
+			if last, ok := types[len(types)-1].(*ast.Ident); ok && last.Name == "error" {
+				n = len(types)
+			}
+		}
+		for _, name := range wanted[fn.Name.Name] {
+			results[name] = n
+		}
+	}
+	return results
+}
@@ -0,0 +1,167 @@
+package adapters
+
+import (
+	"go/format"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/QTest-hq/qtest/pkg/model"
+)
+
+func TestGoSpecAdapter_ImportPath(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "calc.go")
+	os.WriteFile(source, []byte(`package calc
+
+import "errors"
+
+var ErrDivByZero = errors.New("division by zero")
+
+type RangeError struct{ N int }
+
+func (e *RangeError) Error() string { return "out of range" }
+
+func Add(a, b int) int { return a + b }
+
+func Div(a, b int) (int, error) { return a / b, nil }
+
+func clamp(n int) int { return n }
+`), 0644)
+
+	specs := []model.TestSpec{
+		{
+			FunctionName: "Add",
+			Description:  "adds two numbers",
+			Inputs:       map[string]interface{}{"a": float64(2), "b": float64(3)},
+			ArgOrder:     []string{"a", "b"},
+			Assertions:   []model.Assertion{{Kind: "equality", Actual: "result", Expected: float64(5)}},
+		},
+		{
+			FunctionName: "Div",
+			Description:  "divides",
+			Inputs:       map[string]interface{}{"a": float64(6), "b": float64(3)},
+			ArgOrder:     []string{"a", "b"},
+			Assertions:   []model.Assertion{{Kind: "equality", Actual: "result", Expected: float64(2)}},
+		},
+		{
+			FunctionName: "Div",
+			Description:  "rejects a zero divisor",
+			Inputs:       map[string]interface{}{"a": float64(1), "b": float64(0)},
+			ArgOrder:     []string{"a", "b"},
+			Assertions:   []model.Assertion{{Kind: model.AssertThrows, ErrorType: "ErrDivByZero"}},
+		},
+		{
+			FunctionName: "Div",
+			Description:  "rejects a large divisor",
+			Inputs:       map[string]interface{}{"a": float64(1), "b": float64(1000)},
+			ArgOrder:     []string{"a", "b"},
+			Assertions:   []model.Assertion{{Kind: model.AssertThrows, ErrorType: "*RangeError"}},
+		},
+	}
+
+	adapter := &GoSpecAdapter{ImportPath: "example.com/calc"}
+	code, err := adapter.GenerateFromSpecs(specs, source)
+	if err != nil {
+		t.Fatalf("GenerateFromSpecs() error = %v", err)
+	}
+	for _, want := range []string{
+		"package calc_test",
+		`"example.com/calc"`,
+		"result := calc.Add(a, b)",
+		"result, err := calc.Div(a, b)",
+		`t.Fatalf("unexpected error: %v", err)`,
+		"_, err := calc.Div(a, b)",
+		"errors.Is(err, calc.ErrDivByZero)",
+		"var wantErr *calc.RangeError",
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("generated code missing %q\n%s", want, code)
+		}
+	}
+	if _, err := format.Source([]byte(code)); err != nil {
+		t.Errorf("generated code doesn't parse: %v\n%s", err, code)
+	}
+
+	// Unexported functions can only be tested inside their package
+	specs = append(specs, model.TestSpec{
+		FunctionName: "clamp",
+		Description:  "keeps small numbers",
+		Inputs:       map[string]interface{}{"n": float64(1)},
+		ArgOrder:     []string{"n"},
+		Assertions:   []model.Assertion{{Kind: "equality", Actual: "result", Expected: float64(1)}},
+	})
+	code, err = adapter.GenerateFromSpecs(specs, source)
+	if err != nil {
+		t.Fatalf("GenerateFromSpecs() error = %v", err)
+	}
+	if !strings.HasPrefix(code, "package calc\n") || strings.Contains(code, "calc.Add(") {
+		t.Errorf("expected an in-package test with an unexported target:\n%s", code)
+	}
+}
+
+func TestQualifyGoType(t *testing.T) {
+	tests := []struct {
+		name, want string
+	}{
+		{"ErrNotFound", "store.ErrNotFound"},
+		{"*ParseError", "*store.ParseError"},
+		{"io.EOF", "io.EOF"},
+		{"*fs.PathError", "*fs.PathError"},
+		{"errInternal", "errInternal"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := qualifyGoType(tt.name, "store"); got != tt.want {
+			t.Errorf("qualifyGoType(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+	if got := qualifyGoType("ErrNotFound", ""); got != "ErrNotFound" {
+		t.Errorf("qualifyGoType() without a package = %q, want it unchanged", got)
+	}
+}
+
+func TestGoSpecAdapter_Parallel(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "calc.go")
+	os.WriteFile(source, []byte(`package calc
+
+func Add(a, b int) int { return a + b }
+
+func Tick() int { return 0 }
+`), 0644)
+
+	specs := []model.TestSpec{
+		{TargetID: "calc.go:3:Add", FunctionName: "Add", Description: "adds"},
+		{TargetID: "calc.go:3:Add", FunctionName: "Add", Description: "adds zero"},
+		{TargetID: "calc.go:5:Tick", FunctionName: "Tick", Description: "ticks"},
+	}
+	adapter := &GoSpecAdapter{Pure: map[string]bool{"calc.go:3:Add": true}}
+	code, err := adapter.GenerateFromSpecs(specs, source)
+	if err != nil {
+		t.Fatalf("GenerateFromSpecs() error = %v", err)
+	}
+	if !strings.Contains(code, "func TestAdd(t *testing.T) {\n\tt.Parallel()") {
+		t.Errorf("tests of a pure function should run in parallel:\n%s", code)
+	}
+	if n := strings.Count(code, "t.Parallel()"); n != 3 {
+		t.Errorf("t.Parallel() called %d times, want 3 for TestAdd and its cases:\n%s", n, code)
+	}
+	if _, err := format.Source([]byte(code)); err != nil {
+		t.Errorf("generated code doesn't parse: %v\n%s", err, code)
+	}
+
+	// Tests replacing http.DefaultTransport can't share the process
+	weather := filepath.Join(dir, "weather", "weather.go")
+	os.MkdirAll(filepath.Dir(weather), 0755)
+	os.WriteFile(weather, []byte(goWeatherSource), 0644)
+	adapter.Pure["weather.go:1:Today"] = true
+	code, err = adapter.GenerateFromSpecs([]model.TestSpec{{TargetID: "weather.go:1:Today", FunctionName: "Today", Description: "gets the forecast"}}, weather)
+	if err != nil {
+		t.Fatalf("GenerateFromSpecs() error = %v", err)
+	}
+	if strings.Contains(code, "t.Parallel()") {
+		t.Errorf("tests stubbing HTTP shouldn't run in parallel:\n%s", code)
+	}
+}
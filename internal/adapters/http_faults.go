@@ -0,0 +1,359 @@
+package adapters
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"regexp"
+	"strings"
+
+	"github.com/QTest-hq/qtest/pkg/model"
+)
+
+// httpFault is a downstream failure chaos tests make the fakes of a
+// function's HTTP calls return
+type httpFault struct {
+	Name        string // passed to the generated helper
+	Description string // what the test handles
+	Transient   bool   // worth retrying
+}
+
+var (
+	faultTimeout     = httpFault{Name: "timeout", Description: "handles a downstream timeout", Transient: true}
+	faultServerError = httpFault{Name: "server_error", Description: "handles a downstream 503", Transient: true}
+	faultMalformed   = httpFault{Name: "malformed", Description: "handles a malformed downstream response"}
+)
+
+var (
+	// Code that retries failed calls mentions it
+	retryPattern = regexp.MustCompile(`(?i)retr(?:y|ies)|backoff|attempt`)
+
+	// Code that parses response bodies, which a malformed one must fail
+	goParsesJSON = regexp.MustCompile(`json\.(?:NewDecoder|Unmarshal)\(`)
+	jsParsesJSON = regexp.MustCompile(`\.json\(\)|JSON\.parse\(`)
+	pyParsesJSON = regexp.MustCompile(`\.json\(\)|json\.loads\(`)
+)
+
+// WithChaos turns on chaos tests in the adapters that support them: Go,
+// Jest and pytest
+func WithChaos(adapter SpecAdapter) SpecAdapter {
+	switch a := adapter.(type) {
+	case *GoSpecAdapter:
+		a.Chaos = true
+	case *JestSpecAdapter:
+		a.Chaos = true
+	case *PytestSpecAdapter:
+		a.Chaos = true
+	}
+	return adapter
+}
+
+// httpFaultsFor returns the faults to test a function's body with: a
+// malformed response only when it parses one
+func httpFaultsFor(body string, parses *regexp.Regexp) []httpFault {
+	faults := []httpFault{faultTimeout, faultServerError}
+	if parses.MatchString(body) {
+		faults = append(faults, faultMalformed)
+	}
+	return faults
+}
+
+// checksRetries reports whether a test of fault should expect the call to
+// be retried: the fault is transient and the body looks like it retries
+func checksRetries(fault httpFault, body string) bool {
+	return fault.Transient && retryPattern.MatchString(body)
+}
+
+// faultSpec derives the spec a chaos test runs from a function's specs:
+// the inputs of one expected to succeed, expecting the call to fail. It
+// returns false when there are no specs.
+func faultSpec(specs []model.TestSpec, fault httpFault) (model.TestSpec, bool) {
+	if len(specs) == 0 {
+		return model.TestSpec{}, false
+	}
+	spec := specs[0]
+	for _, s := range specs {
+		if !expectsError(s) {
+			spec = s
+			break
+		}
+	}
+	spec.Description = fault.Description
+	spec.Assertions = []model.Assertion{{Kind: model.AssertThrows}}
+	return spec, true
+}
+
+// goHTTPCallers returns the bodies of the functions in source making HTTP
+// calls to fixed URLs, by name, with methods named Type.Method
+func goHTTPCallers(source string) map[string]string {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", source, 0)
+	if err != nil {
+		return nil
+	}
+	callers := make(map[string]string)
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Body == nil {
+			continue
+		}
+		body := source[fset.Position(fn.Body.Pos()).Offset:fset.Position(fn.Body.End()).Offset]
+		if len(detectGoHTTPCalls(body)) == 0 {
+			continue
+		}
+		name := fn.Name.Name
+		if recv, _, ok := goReceiverType(fn.Recv); ok {
+			name = recv + "." + name
+		}
+		callers[name] = body
+	}
+	return callers
+}
+
+// goFaultImports are the imports goFaultHelper needs
+var goFaultImports = []string{"io", "net/http", "net/http/httptest", "net/url", "os", "sync/atomic"}
+
+// goFaultHelper makes the code's HTTP calls fail. It uses the
+// roundTripFunc of the stubHTTP helper, which files with chaos tests have.
+const goFaultHelper = `// failHTTP makes the code's outbound HTTP calls fail with fault: a timeout,
+// a 503 or a malformed body. It returns the number of requests made, to
+// check retries.
+func failHTTP(t *testing.T, fault string) *atomic.Int32 {
+	t.Helper()
+	calls := new(atomic.Int32)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if fault == "server_error" {
+			http.Error(w, "service unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, ` + "`" + `{"id": 1, "name": ` + "`" + `)
+	}))
+	t.Cleanup(srv.Close)
+
+	target, _ := url.Parse(srv.URL)
+	orig := http.DefaultTransport
+	http.DefaultTransport = roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		if fault == "timeout" {
+			calls.Add(1)
+			return nil, os.ErrDeadlineExceeded
+		}
+		r = r.Clone(r.Context())
+		r.URL.Scheme, r.URL.Host = target.Scheme, target.Host
+		return orig.RoundTrip(r)
+	})
+	t.Cleanup(func() { http.DefaultTransport = orig })
+	return calls
+}
+`
+
+// goFaultCase is a subtest calling a function while its HTTP calls fail
+// with fault, expecting an error, and a retry when the body retries
+func goFaultCase(fault httpFault, body, setup, action string) goSpecCaseData {
+	retry := checksRetries(fault, body)
+	failing := fmt.Sprintf("failHTTP(t, %q)", fault.Name)
+	if retry {
+		failing = "calls := " + failing
+	}
+	if setup != "" {
+		failing += "\n\t\t" + setup
+	}
+
+	assertions := []string{fmt.Sprintf(`if err == nil {
+			t.Fatal("expected an error on %s")
+		}`, strings.TrimPrefix(fault.Description, "handles "))}
+	if retry {
+		assertions = append(assertions, `if n := calls.Load(); n < 2 {
+			t.Errorf("expected the call to be retried, got %d requests", n)
+		}`)
+	}
+	return goSpecCaseData{
+		Name:       sanitizeTestName(fault.Description),
+		Setup:      failing,
+		Action:     action,
+		Assertions: assertions,
+	}
+}
+
+// jsFunctionBody returns the source of the function, arrow function or
+// method named name, or "" when it isn't defined in source
+func jsFunctionBody(source, name string) string {
+	q := regexp.QuoteMeta(name)
+	def := regexp.MustCompile(`\bfunction\s*\*?\s*` + q + `\s*\(|\b(?:const|let|var)\s+` + q + `\s*=|(?m)^[ \t]*(?:static\s+)?(?:async\s+)?` + q + `\s*\([^)]*\)\s*\{`)
+	loc := def.FindStringIndex(source)
+	if loc == nil {
+		return ""
+	}
+	rest := source[loc[0]:]
+
+	// The body follows the parameters; an arrow function's may be an
+	// expression ending its line
+	params := strings.Index(rest, ")")
+	if params < 0 {
+		return rest
+	}
+	after := strings.TrimLeft(rest[params+1:], " \t\r\n")
+	after = strings.TrimLeft(strings.TrimPrefix(after, "=>"), " \t\r\n")
+	open := len(rest) - len(after)
+	if !strings.HasPrefix(after, "{") {
+		if nl := strings.Index(after, "\n"); nl >= 0 {
+			return rest[:open+nl]
+		}
+		return rest
+	}
+	depth := 0
+	for i := open; i < len(rest); i++ {
+		switch rest[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return rest[:i+1]
+			}
+		}
+	}
+	return rest
+}
+
+// jsHTTPCallers returns the bodies of funcs making HTTP calls to fixed
+// URLs, by name
+func jsHTTPCallers(source string, funcs []string) map[string]string {
+	callers := make(map[string]string)
+	for _, fn := range funcs {
+		if body := jsFunctionBody(source, fn); len(detectJSHTTPCalls(body)) > 0 {
+			callers[fn] = body
+		}
+	}
+	return callers
+}
+
+// jsFaultHelper returns a failHTTP function replacing the nock
+// interceptors of the source's HTTP calls with ones failing with a fault,
+// counting the requests made
+func jsFaultHelper(source string) string {
+	var sb strings.Builder
+	sb.WriteString(`// failHTTP makes the code's outbound HTTP calls fail with fault: a
+// timeout, a 503 or a malformed body. calls.count is the number of
+// requests made, to check retries.
+function failHTTP(fault: string): { count: number } {
+  nock.cleanAll();
+  const calls = { count: 0 };
+  const fail = (interceptor: nock.Interceptor) => {
+    if (fault === 'timeout') {
+      return interceptor.replyWithError({ code: 'ETIMEDOUT', message: 'timeout' });
+    }
+    if (fault === 'server_error') {
+      return interceptor.reply(503, { error: 'unavailable' });
+    }
+    return interceptor.reply(200, '{"id": 1, "name": ', { 'Content-Type': 'application/json' });
+  };
+`)
+	for _, call := range detectJSHTTPCalls(source) {
+		path := fmt.Sprintf("'%s'", call.Path)
+		if call.Prefix {
+			path = fmt.Sprintf("(path) => path.startsWith('%s')", call.Path)
+		}
+		fmt.Fprintf(&sb, "  fail(nock('%s').persist().%s(%s)).on('request', () => {\n    calls.count += 1;\n  });\n",
+			call.Origin, strings.ToLower(call.Method), path)
+	}
+	sb.WriteString("  return calls;\n}")
+	return sb.String()
+}
+
+// jestFaultCase is a test calling a function while its HTTP calls fail
+// with fault, expecting it to reject, and a retry when the body retries
+func jestFaultCase(fault httpFault, body, setup, action string) jestSpecCaseData {
+	retry := checksRetries(fault, body)
+	failing := fmt.Sprintf("    failHTTP('%s');\n", fault.Name)
+	if retry {
+		failing = "    const calls = " + strings.TrimPrefix(failing, "    ")
+	}
+
+	assertions := []string{"await expect(result).rejects.toThrow();"}
+	if retry {
+		assertions = append(assertions, "expect(calls.count).toBeGreaterThan(1);")
+	}
+	return jestSpecCaseData{
+		Name:       fault.Description,
+		Async:      true,
+		Setup:      failing + setup,
+		Action:     action,
+		Assertions: assertions,
+	}
+}
+
+// pythonHTTPCallers returns the bodies of funcs making HTTP calls to fixed
+// URLs, by name
+func pythonHTTPCallers(source string, funcs []string) map[string]string {
+	callers := make(map[string]string)
+	for _, fn := range funcs {
+		if len(detectPythonHTTPCalls(source, []string{fn})) > 0 {
+			callers[fn] = pythonFunctionBody(source, fn)
+		}
+	}
+	return callers
+}
+
+// pythonFaultHelper returns a fail_http function patching the funcs' HTTP
+// calls in module with a mock failing with a fault, and the imports it
+// needs. The failure is the one of the first call's library, requests or
+// httpx. It returns "" when the functions make no HTTP calls.
+func pythonFaultHelper(source, module string, funcs []string) (string, []string) {
+	calls := detectPythonHTTPCalls(source, funcs)
+	if len(calls) == 0 || module == "" {
+		return "", nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString("def fail_http(monkeypatch, fault):\n")
+	sb.WriteString(`    """Make the code's outbound HTTP calls fail with fault: a timeout, a 503` + "\n")
+	sb.WriteString(`    or a malformed body. Returns the mock, to count retries."""` + "\n")
+	sb.WriteString(`    status, body = (503, b'{"error": "unavailable"}') if fault == "server_error" else (200, b'{"id": 1, "name": ')` + "\n")
+
+	library := strings.SplitN(calls[0].Client, ".", 2)[0]
+	if library == "httpx" {
+		sb.WriteString("    if fault == \"timeout\":\n")
+		sb.WriteString("        failing = mock.MagicMock(side_effect=httpx.TimeoutException(\"timed out\"))\n")
+		sb.WriteString("    else:\n")
+		sb.WriteString("        request = httpx.Request(\"GET\", \"http://downstream\")\n")
+		sb.WriteString("        failing = mock.MagicMock(return_value=httpx.Response(\n")
+		sb.WriteString("            status, content=body, headers={\"Content-Type\": \"application/json\"}, request=request))\n")
+	} else {
+		sb.WriteString("    if fault == \"timeout\":\n")
+		sb.WriteString("        failing = mock.MagicMock(side_effect=requests.exceptions.Timeout(\"timed out\"))\n")
+		sb.WriteString("    else:\n")
+		sb.WriteString("        response = requests.models.Response()\n")
+		sb.WriteString("        response.status_code, response._content = status, body\n")
+		sb.WriteString("        response.headers[\"Content-Type\"] = \"application/json\"\n")
+		sb.WriteString("        failing = mock.MagicMock(return_value=response)\n")
+	}
+	seen := make(map[string]bool)
+	for _, call := range calls {
+		if !seen[call.Client] {
+			seen[call.Client] = true
+			fmt.Fprintf(&sb, "    monkeypatch.setattr(%q, failing)\n", module+"."+call.Client)
+		}
+	}
+	sb.WriteString("    return failing\n")
+	return sb.String(), []string{"import " + library, "from unittest import mock"}
+}
+
+// pytestFaultCase is a test calling a function while its HTTP calls fail
+// with fault, expecting it to raise, and a retry when the body retries
+func pytestFaultCase(fault httpFault, body, setup, action string, async bool) pytestSpecCaseData {
+	caseData := pytestSpecCaseData{
+		Name:        toPythonTestName(fault.Description),
+		Description: strings.ToUpper(fault.Description[:1]) + fault.Description[1:],
+		Async:       async,
+		Setup:       fmt.Sprintf("        failing = fail_http(monkeypatch, %q)\n", fault.Name) + setup,
+		Action:      action,
+		Fixtures:    []string{"monkeypatch"},
+	}
+	if checksRetries(fault, body) {
+		caseData.Assertions = append(caseData.Assertions, "assert failing.call_count > 1, \"expected the call to be retried\"")
+	}
+	return caseData
+}
@@ -0,0 +1,198 @@
+package adapters
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/QTest-hq/qtest/pkg/model"
+)
+
+// goRetryingSource retries Fetch's call; Today and Report come from
+// goWeatherSource
+const goRetryingSource = `package weather
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+type Forecast struct {
+	City string ` + "`json:\"city\"`" + `
+}
+
+func Fetch(city string) (*Forecast, error) {
+	var lastErr error
+	for attempt := 0; attempt < 3; attempt++ {
+		resp, err := http.Get("https://api.weather.test/v1/today/" + city)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			lastErr = fmt.Errorf("status %d", resp.StatusCode)
+			continue
+		}
+		var f Forecast
+		err = json.NewDecoder(resp.Body).Decode(&f)
+		resp.Body.Close()
+		return &f, err
+	}
+	return nil, lastErr
+}
+
+func Add(a, b int) int { return a + b }
+`
+
+func TestHTTPCallers(t *testing.T) {
+	callers := goHTTPCallers(goWeatherSource)
+	if len(callers) != 2 || callers["Today"] == "" || callers["Report"] == "" {
+		t.Errorf("Go callers = %v, want Today and Report", callers)
+	}
+	if got := goHTTPCallers("package p\n\ntype S struct{}\n\nfunc (s *S) Get() { http.Get(\"https://a.test/x\") }\n"); got["S.Get"] == "" {
+		t.Errorf("Go method callers = %v, want S.Get", got)
+	}
+
+	js := jsHTTPCallers(jsWeatherSource+"\nexport const ping = () => fetch('https://a.test/ping');\nexport function local(x) { return x; }\n",
+		[]string{"today", "report", "ping", "local"})
+	if len(js) != 3 || js["local"] != "" || !strings.Contains(js["today"], "format(data.city") {
+		t.Errorf("JS callers = %v, want today, report and ping", js)
+	}
+
+	py := pythonHTTPCallers(pythonWeatherSource, []string{"today", "report"})
+	if len(py) != 2 || !strings.Contains(py["today"], "resp.json()") {
+		t.Errorf("Python callers = %v", py)
+	}
+
+	if faults := httpFaultsFor(py["report"], pyParsesJSON); len(faults) != 2 {
+		t.Errorf("report doesn't parse its response, faults = %v", faults)
+	}
+	if !checksRetries(faultTimeout, "for attempt := 0; attempt < 3; attempt++") || checksRetries(faultMalformed, "retry") {
+		t.Error("only transient faults of retrying code should check retries")
+	}
+}
+
+func TestGoSpecAdapter_Chaos(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "weather.go")
+	if err := os.WriteFile(src, []byte(goRetryingSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+	specs := []model.TestSpec{
+		{FunctionName: "Fetch", Description: "gets the forecast", Inputs: map[string]interface{}{"city": "paris"}, ArgOrder: []string{"city"},
+			Assertions: []model.Assertion{{Kind: "not_nil", Actual: "result"}}},
+		{FunctionName: "Add", Description: "adds", Inputs: map[string]interface{}{"a": 1, "b": 2}, ArgOrder: []string{"a", "b"},
+			Assertions: []model.Assertion{{Kind: "equals", Actual: "result", Expected: 3}}},
+	}
+
+	adapter := NewGoSpecAdapter()
+	code, err := adapter.GenerateFromSpecs(specs, src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(code, "failHTTP") {
+		t.Errorf("chaos tests need Chaos:\n%s", code)
+	}
+
+	adapter.Chaos = true
+	code, err = adapter.GenerateFromSpecs(specs, src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"func failHTTP(t *testing.T, fault string) *atomic.Int32 {",
+		`"sync/atomic"`,
+		"func TestFetch_DownstreamFaults(t *testing.T) {\n\n\tt.Run(",
+		`calls := failHTTP(t, "timeout")`,
+		`calls := failHTTP(t, "server_error")`,
+		`failHTTP(t, "malformed")`,
+		"expected the call to be retried",
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("output missing %q:\n%s", want, code)
+		}
+	}
+	if strings.Contains(code, "TestAdd_DownstreamFaults") || strings.Contains(code, `calls := failHTTP(t, "malformed")`) {
+		t.Errorf("only Fetch's transient faults should be tested for retries:\n%s", code)
+	}
+
+	if testing.Short() {
+		return
+	}
+	// The generated tests compile and pass against code handling faults
+	os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/weather\n\ngo 1.21\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "weather_test.go"), []byte(code), 0644)
+	cmd := exec.Command("go", "test", "-run", "DownstreamFaults", "-v", "./...")
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("generated tests failed: %v\n%s\n%s", err, out, code)
+	}
+}
+
+func TestJestSpecAdapter_Chaos(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "weather.js")
+	if err := os.WriteFile(src, []byte(jsWeatherSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+	adapter := &JestSpecAdapter{Chaos: true}
+	code, err := adapter.GenerateFromSpecs([]model.TestSpec{
+		{FunctionName: "today", Description: "gets the forecast", Async: true, Inputs: map[string]interface{}{"city": "paris"}, ArgOrder: []string{"city"}},
+	}, src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"function failHTTP(fault: string): { count: number } {",
+		"fail(nock('https://api.weather.test').persist().get((path) => path.startsWith('/v1/today/'))).on('request', () => {",
+		"fail(nock('https://api.weather.test').persist().post('/v1/reports'))",
+		"describe('today downstream faults', () => {",
+		"test('handles a downstream timeout', async () => {",
+		"failHTTP('server_error');",
+		"const result = today(city);",
+		"await expect(result).rejects.toThrow();",
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("output missing %q:\n%s", want, code)
+		}
+	}
+	// today reads fields, not the raw body, and doesn't retry
+	if strings.Contains(code, "failHTTP('malformed')") || strings.Contains(code, "calls.count).toBeGreaterThan") {
+		t.Errorf("unexpected malformed or retry test:\n%s", code)
+	}
+}
+
+func TestPytestSpecAdapter_Chaos(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "weather.py")
+	if err := os.WriteFile(src, []byte(pythonWeatherSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+	adapter := &PytestSpecAdapter{Chaos: true}
+	code, err := adapter.GenerateFromSpecs([]model.TestSpec{
+		{FunctionName: "today", Description: "gets the forecast", Inputs: map[string]interface{}{"city": "paris"}, ArgOrder: []string{"city"}},
+	}, src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"import requests",
+		"from unittest import mock",
+		"def fail_http(monkeypatch, fault):",
+		`failing = mock.MagicMock(side_effect=requests.exceptions.Timeout("timed out"))`,
+		`monkeypatch.setattr("weather.requests.get", failing)`,
+		"class TestTodayDownstreamFaults:",
+		"def test_handles_a_malformed_downstream_response(self, monkeypatch):",
+		`failing = fail_http(monkeypatch, "server_error")`,
+		"with pytest.raises(Exception):\n            today(city)",
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("output missing %q:\n%s", want, code)
+		}
+	}
+	if strings.Contains(code, "weather.requests.post") {
+		t.Errorf("report isn't tested, so its calls shouldn't be patched:\n%s", code)
+	}
+}
@@ -0,0 +1,358 @@
+package adapters
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/QTest-hq/qtest/internal/datagen"
+)
+
+// httpCall is an outbound request the code under test makes to a URL
+// written in its source. Tests replay a recorded response for it instead
+// of reaching the real service.
+type httpCall struct {
+	Client string // what makes the call, e.g. requests.get or axios
+	Method string // upper case
+	Origin string // scheme://host[:port]
+	Path   string
+	// Prefix is set when the URL continues with values built at runtime,
+	// so Path only begins the requested path
+	Prefix bool
+}
+
+var (
+	goHTTPCallPattern    = regexp.MustCompile(`\bhttp\.(Get|Head|Post|PostForm)\(\s*(?:fmt\.Sprintf\(\s*)?"([^"]*)"`)
+	goHTTPRequestPattern = regexp.MustCompile(`\bhttp\.NewRequest(?:WithContext)?\(\s*(?:\w+,\s*)?(?:http\.Method(\w+)|"(\w+)"),\s*(?:fmt\.Sprintf\(\s*)?"([^"]*)"`)
+	jsHTTPCallPattern    = regexp.MustCompile("\\b(axios|got|superagent)\\.(get|post|put|patch|delete|head)\\(\\s*['\"`]([^'\"`]*)")
+	jsFetchPattern       = regexp.MustCompile("\\bfetch\\(\\s*['\"`]([^'\"`]*)['\"`](?:\\s*,\\s*\\{[^}]*?\\bmethod:\\s*['\"](\\w+)['\"])?")
+	pyHTTPCallPattern    = regexp.MustCompile(`\b((?:requests|httpx)\.(get|post|put|patch|delete|head))\(\s*f?["']([^"']*)`)
+
+	// Response fields the code reads, to fill recorded payloads with
+	goJSONFieldPattern = regexp.MustCompile("(?m)^\\s*\\w+\\s+(\\*?[\\w.\\[\\]]+)\\s+`json:\"(\\w+)")
+	jsFieldPattern     = regexp.MustCompile(`\b(?:data|body|json|payload)\.(\w+)\b`)
+	pyFieldPattern     = regexp.MustCompile(`\[\s*["'](\w+)["']\s*\]|\.get\(\s*["'](\w+)["']`)
+
+	// jsNonFields are properties of responses and arrays rather than fields
+	jsNonFields = map[string]bool{"map": true, "filter": true, "length": true, "forEach": true, "then": true, "json": true, "data": true}
+)
+
+// parseHTTPCall builds a call from a URL literal. Only absolute URLs are
+// recorded; the URL is cut where a runtime value is interpolated.
+func parseHTTPCall(client, method, raw string) (httpCall, bool) {
+	cut := false
+	for _, marker := range []string{"${", "{", "%", "?"} {
+		if i := strings.Index(raw, marker); i >= 0 {
+			raw, cut = raw[:i], true
+		}
+	}
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return httpCall{}, false
+	}
+	path := u.Path
+	if path == "" {
+		path = "/"
+	}
+	return httpCall{
+		Client: client,
+		Method: strings.ToUpper(method),
+		Origin: u.Scheme + "://" + u.Host,
+		Path:   path,
+		Prefix: cut || strings.HasSuffix(path, "/"),
+	}, true
+}
+
+// addHTTPCall appends call unless an equal one is already there
+func addHTTPCall(calls []httpCall, call httpCall) []httpCall {
+	for _, c := range calls {
+		if c == call {
+			return calls
+		}
+	}
+	return append(calls, call)
+}
+
+// detectGoHTTPCalls finds requests Go source makes through net/http
+func detectGoHTTPCalls(source string) []httpCall {
+	var calls []httpCall
+	for _, m := range goHTTPCallPattern.FindAllStringSubmatch(source, -1) {
+		method := "GET"
+		switch m[1] {
+		case "Head":
+			method = "HEAD"
+		case "Post", "PostForm":
+			method = "POST"
+		}
+		if call, ok := parseHTTPCall("http."+m[1], method, m[2]); ok {
+			calls = addHTTPCall(calls, call)
+		}
+	}
+	for _, m := range goHTTPRequestPattern.FindAllStringSubmatch(source, -1) {
+		if call, ok := parseHTTPCall("http.NewRequest", m[1]+m[2], m[3]); ok {
+			calls = addHTTPCall(calls, call)
+		}
+	}
+	return calls
+}
+
+// detectJSHTTPCalls finds requests JS source makes through axios, got,
+// superagent or fetch
+func detectJSHTTPCalls(source string) []httpCall {
+	var calls []httpCall
+	for _, m := range jsHTTPCallPattern.FindAllStringSubmatch(source, -1) {
+		if call, ok := parseHTTPCall(m[1], m[2], m[3]); ok {
+			calls = addHTTPCall(calls, call)
+		}
+	}
+	for _, m := range jsFetchPattern.FindAllStringSubmatch(source, -1) {
+		method := m[2]
+		if method == "" {
+			method = "GET"
+		}
+		if call, ok := parseHTTPCall("fetch", method, m[1]); ok {
+			calls = addHTTPCall(calls, call)
+		}
+	}
+	return calls
+}
+
+// detectPythonHTTPCalls finds requests the bodies of funcs make through
+// requests or httpx
+func detectPythonHTTPCalls(source string, funcs []string) []httpCall {
+	var calls []httpCall
+	for _, fn := range funcs {
+		for _, m := range pyHTTPCallPattern.FindAllStringSubmatch(pythonFunctionBody(source, fn), -1) {
+			if call, ok := parseHTTPCall(m[1], m[2], m[3]); ok {
+				calls = addHTTPCall(calls, call)
+			}
+		}
+	}
+	return calls
+}
+
+// responseFields returns the fields of a response the source reads, in
+// order, or id and name when it reads none
+func responseFields(pattern *regexp.Regexp, source string, skip map[string]bool) []string {
+	var fields []string
+	seen := make(map[string]bool)
+	for _, m := range pattern.FindAllStringSubmatch(source, -1) {
+		field := strings.Join(m[1:], "")
+		if field != "" && !seen[field] && !skip[field] {
+			seen[field] = true
+			fields = append(fields, field)
+		}
+	}
+	if len(fields) == 0 {
+		return []string{"id", "name"}
+	}
+	return fields
+}
+
+// goResponseFields returns the JSON fields of the structs Go source
+// declares with their datagen types. Fields of other types, such as
+// nested structs and slices, are left out of payloads.
+func goResponseFields(source string) ([]string, map[string]string) {
+	var fields []string
+	types := make(map[string]string)
+	for _, m := range goJSONFieldPattern.FindAllStringSubmatch(source, -1) {
+		goType, field := strings.TrimPrefix(m[1], "*"), m[2]
+		var typ string
+		switch {
+		case goType == "string":
+			typ = "string"
+		case goType == "bool":
+			typ = "bool"
+		case strings.HasPrefix(goType, "int") || strings.HasPrefix(goType, "uint"):
+			typ = "int"
+		case strings.HasPrefix(goType, "float"):
+			typ = "float"
+		case goType == "time.Time":
+			typ = "datetime"
+		default:
+			continue
+		}
+		if _, ok := types[field]; !ok {
+			types[field] = typ
+			fields = append(fields, field)
+		}
+	}
+	if len(fields) == 0 {
+		return []string{"id", "name"}, nil
+	}
+	return fields, types
+}
+
+// httpPayload returns the JSON body recorded for a call: datagen values
+// for each field, seeded by the URL so regenerated tests don't churn.
+// Fields without a type in types are strings.
+func httpPayload(call httpCall, fields []string, types map[string]string) string {
+	h := fnv.New64a()
+	h.Write([]byte(call.Method + " " + call.Origin + call.Path))
+	gen := datagen.NewSeededDataGenerator(int64(h.Sum64()))
+
+	payload := make(map[string]interface{}, len(fields))
+	for _, field := range fields {
+		// Names only pick realistic strings, so typed fields keep their type
+		typ, name := types[field], ""
+		if typ == "" || typ == "string" {
+			typ, name = "string", field
+		}
+		payload[field] = gen.GenerateForType(typ, name)
+	}
+	data, _ := json.Marshal(payload)
+	return string(data)
+}
+
+// goHTTPFixture returns a stubHTTP helper serving the recorded responses
+// from an httptest server that http.DefaultTransport is routed to, and
+// the imports it needs. Tests call stubHTTP(t) first. It returns "" when
+// source makes no HTTP calls to fixed URLs.
+func goHTTPFixture(source string) (string, []string) {
+	calls := detectGoHTTPCalls(source)
+	if len(calls) == 0 {
+		return "", nil
+	}
+	fields, types := goResponseFields(source)
+
+	var cases strings.Builder
+	usesStrings := false
+	for _, call := range calls {
+		host := strings.TrimPrefix(strings.TrimPrefix(call.Origin, "https://"), "http://")
+		path := fmt.Sprintf("r.URL.Path == %q", call.Path)
+		if call.Prefix {
+			path = fmt.Sprintf("strings.HasPrefix(r.URL.Path, %q)", call.Path)
+			usesStrings = true
+		}
+		fmt.Fprintf(&cases, "\t\tcase r.Host == %q && r.Method == %q && %s:\n", host, call.Method, path)
+		fmt.Fprintf(&cases, "\t\t\tio.WriteString(w, `%s`)\n", httpPayload(call, fields, types))
+	}
+
+	helper := fmt.Sprintf(`// stubHTTP replays recorded responses for the code's outbound HTTP calls
+// from a local server, routing http.DefaultTransport to it for the test
+func stubHTTP(t *testing.T) {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+%s		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	target, _ := url.Parse(srv.URL)
+	orig := http.DefaultTransport
+	http.DefaultTransport = roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		r = r.Clone(r.Context())
+		r.URL.Scheme, r.URL.Host = target.Scheme, target.Host
+		return orig.RoundTrip(r)
+	})
+	t.Cleanup(func() { http.DefaultTransport = orig })
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+`, cases.String())
+
+	imports := []string{"io", "net/http", "net/http/httptest", "net/url"}
+	if usesStrings {
+		imports = append(imports, "strings")
+	}
+	return helper, imports
+}
+
+// jsHTTPInterceptors returns nock setup replaying the recorded responses
+// for the code's outbound HTTP calls, with the network otherwise disabled.
+// It returns nil when source makes no HTTP calls to fixed URLs.
+func jsHTTPInterceptors(source string) []string {
+	calls := detectJSHTTPCalls(source)
+	if len(calls) == 0 {
+		return nil
+	}
+	fields := responseFields(jsFieldPattern, source, jsNonFields)
+
+	var sb strings.Builder
+	sb.WriteString("beforeEach(() => {\n  nock.disableNetConnect();\n")
+	for _, call := range calls {
+		path := fmt.Sprintf("'%s'", call.Path)
+		if call.Prefix {
+			path = fmt.Sprintf("(path) => path.startsWith('%s')", call.Path)
+		}
+		fmt.Fprintf(&sb, "  nock('%s')\n    .persist()\n    .%s(%s)\n    .reply(200, %s);\n",
+			call.Origin, strings.ToLower(call.Method), path, httpPayload(call, fields, nil))
+	}
+	sb.WriteString("});")
+
+	return []string{sb.String(), "afterEach(() => {\n  nock.cleanAll();\n  nock.enableNetConnect();\n});"}
+}
+
+// jsHTTPClientModules are the HTTP clients nock intercepts, which aren't
+// mocked when their calls are replayed
+var jsHTTPClientModules = map[string]bool{"axios": true, "node-fetch": true, "got": true, "superagent": true}
+
+// pythonHTTPCassette returns an autouse fixture replaying the recorded
+// responses for the functions' outbound HTTP calls from a vcrpy cassette,
+// or "" when they make no HTTP calls to fixed URLs
+func pythonHTTPCassette(source string, funcs []string) string {
+	calls := detectPythonHTTPCalls(source, funcs)
+	if len(calls) == 0 {
+		return ""
+	}
+
+	var bodies []string
+	for _, fn := range funcs {
+		bodies = append(bodies, pythonFunctionBody(source, fn))
+	}
+	fields := responseFields(pyFieldPattern, strings.Join(bodies, "\n"), nil)
+
+	// Dynamic paths can't be matched exactly
+	matchOn := []string{"method", "scheme", "host", "port", "path"}
+	var interactions []map[string]interface{}
+	for _, call := range calls {
+		if call.Prefix {
+			matchOn = []string{"method", "host"}
+		}
+		interactions = append(interactions, map[string]interface{}{
+			"request": map[string]interface{}{
+				"method": call.Method, "uri": call.Origin + call.Path, "body": nil, "headers": map[string]interface{}{},
+			},
+			"response": map[string]interface{}{
+				"status":  map[string]interface{}{"code": 200, "message": "OK"},
+				"headers": map[string]interface{}{"Content-Type": []string{"application/json"}},
+				"body":    map[string]interface{}{"string": httpPayload(call, fields, nil)},
+			},
+		})
+	}
+	cassette, _ := json.MarshalIndent(map[string]interface{}{"version": 1, "interactions": interactions}, "", "  ")
+
+	quoted := make([]string, len(matchOn))
+	for i, m := range matchOn {
+		quoted[i] = fmt.Sprintf("%q", m)
+	}
+
+	var sb strings.Builder
+	sb.WriteString("HTTP_CASSETTE = r'''" + string(cassette) + "'''\n\n\n")
+	sb.WriteString("@pytest.fixture(autouse=True)\n")
+	sb.WriteString("def recorded_http(tmp_path):\n")
+	sb.WriteString(`    """Replay recorded responses for the code's outbound HTTP calls"""` + "\n")
+	sb.WriteString("    cassette = tmp_path / \"http.json\"\n")
+	sb.WriteString("    cassette.write_text(HTTP_CASSETTE)\n")
+	fmt.Fprintf(&sb, "    with vcr.use_cassette(str(cassette), serializer=\"json\", record_mode=\"none\",\n")
+	fmt.Fprintf(&sb, "                          allow_playback_repeats=True, match_on=[%s]):\n", strings.Join(quoted, ", "))
+	sb.WriteString("        yield\n")
+	return sb.String()
+}
+
+// recordedClients returns the clients whose calls are replayed
+func recordedClients(calls []httpCall) map[string]bool {
+	clients := make(map[string]bool, len(calls))
+	for _, call := range calls {
+		clients[call.Client] = true
+	}
+	return clients
+}
@@ -0,0 +1,258 @@
+package adapters
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/QTest-hq/qtest/pkg/dsl"
+	"github.com/QTest-hq/qtest/pkg/model"
+)
+
+const goWeatherSource = `package weather
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+type Forecast struct {
+	City string ` + "`json:\"city\"`" + `
+	Temp int    ` + "`json:\"temp,omitempty\"`" + `
+}
+
+func Today(city string) (*Forecast, error) {
+	resp, err := http.Get(fmt.Sprintf("https://api.weather.test/v1/today/%s", city))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var f Forecast
+	return &f, json.NewDecoder(resp.Body).Decode(&f)
+}
+
+func Report(body string) error {
+	req, _ := http.NewRequest(http.MethodPost, "https://api.weather.test/v1/reports", nil)
+	_, err := http.DefaultClient.Do(req)
+	return err
+}
+`
+
+const jsWeatherSource = `import axios from 'axios';
+import { format } from './format';
+
+export async function today(city) {
+  const { data } = await axios.get(` + "`https://api.weather.test/v1/today/${city}`" + `);
+  return format(data.city, data.temp);
+}
+
+export async function report(body) {
+  return fetch('https://api.weather.test/v1/reports', { method: 'POST', body });
+}
+`
+
+const pythonWeatherSource = `import requests
+
+
+def today(city):
+    resp = requests.get(f"https://api.weather.test/v1/today/{city}")
+    data = resp.json()
+    return data["city"], data.get("temp")
+
+
+def report(body):
+    return requests.post("https://api.weather.test/v1/reports", json=body)
+`
+
+func TestParseHTTPCall(t *testing.T) {
+	tests := []struct {
+		raw        string
+		ok         bool
+		origin     string
+		path       string
+		wantPrefix bool
+	}{
+		{"https://api.test/users", true, "https://api.test", "/users", false},
+		{"https://api.test/users/${id}", true, "https://api.test", "/users/", true},
+		{"http://localhost:8080/items/%d", true, "http://localhost:8080", "/items/", true},
+		{"https://api.test/search?q=", true, "https://api.test", "/search", true},
+		{"https://api.test", true, "https://api.test", "/", true},
+		{"/relative/path", false, "", "", false},
+		{"{base}/users", false, "", "", false},
+	}
+	for _, tt := range tests {
+		call, ok := parseHTTPCall("fetch", "get", tt.raw)
+		if ok != tt.ok {
+			t.Errorf("parseHTTPCall(%q) ok = %v, want %v", tt.raw, ok, tt.ok)
+			continue
+		}
+		if ok && (call.Origin != tt.origin || call.Path != tt.path || call.Prefix != tt.wantPrefix || call.Method != "GET") {
+			t.Errorf("parseHTTPCall(%q) = %+v", tt.raw, call)
+		}
+	}
+}
+
+func TestDetectHTTPCalls(t *testing.T) {
+	describe := func(calls []httpCall) string {
+		var out []string
+		for _, c := range calls {
+			out = append(out, c.Method+" "+c.Origin+c.Path)
+		}
+		return strings.Join(out, ",")
+	}
+
+	want := "GET https://api.weather.test/v1/today/,POST https://api.weather.test/v1/reports"
+	if got := describe(detectGoHTTPCalls(goWeatherSource)); got != want {
+		t.Errorf("Go calls = %s, want %s", got, want)
+	}
+	if got := describe(detectJSHTTPCalls(jsWeatherSource)); got != want {
+		t.Errorf("JS calls = %s, want %s", got, want)
+	}
+	if got := describe(detectPythonHTTPCalls(pythonWeatherSource, []string{"today", "report"})); got != want {
+		t.Errorf("Python calls = %s, want %s", got, want)
+	}
+	if got := describe(detectPythonHTTPCalls(pythonWeatherSource, []string{"report"})); got != "POST https://api.weather.test/v1/reports" {
+		t.Errorf("Python calls of report = %s", got)
+	}
+}
+
+func TestHTTPPayload(t *testing.T) {
+	call := httpCall{Method: "GET", Origin: "https://api.test", Path: "/users/"}
+	payload := httpPayload(call, []string{"id", "email"}, nil)
+	if !strings.Contains(payload, `"id":"`) || !strings.Contains(payload, `"email":"`) {
+		t.Errorf("payload = %s, want id and email", payload)
+	}
+	if again := httpPayload(call, []string{"id", "email"}, nil); again != payload {
+		t.Errorf("payload isn't stable: %s then %s", payload, again)
+	}
+	if fields, types := goResponseFields(goWeatherSource); strings.Join(fields, ",") != "city,temp" || types["temp"] != "int" {
+		t.Errorf("Go fields = %v %v, want city and an int temp", fields, types)
+	}
+	if got := responseFields(jsFieldPattern, "return data.map(x => x)", jsNonFields); strings.Join(got, ",") != "id,name" {
+		t.Errorf("default fields = %v, want id,name", got)
+	}
+}
+
+func TestGoAdapters_StubHTTP(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "weather.go")
+	if err := os.WriteFile(src, []byte(goWeatherSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	spec := model.TestSpec{FunctionName: "Today", Description: "gets the forecast"}
+	code, err := NewGoSpecAdapter().GenerateFromSpecs([]model.TestSpec{spec}, src)
+	if err != nil {
+		t.Fatalf("GenerateFromSpecs() error: %v", err)
+	}
+	for _, want := range []string{
+		`"net/http/httptest"`,
+		"func stubHTTP(t *testing.T) {",
+		`case r.Host == "api.weather.test" && r.Method == "GET" && strings.HasPrefix(r.URL.Path, "/v1/today/"):`,
+		`case r.Host == "api.weather.test" && r.Method == "POST" && r.URL.Path == "/v1/reports":`,
+		`"city":"`,
+		"http.DefaultTransport = roundTripFunc(",
+		"func TestToday(t *testing.T) {\n\tstubHTTP(t)",
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("spec adapter output missing %q:\n%s", want, code)
+		}
+	}
+	if strings.Count(code, `"strings"`) != 1 {
+		t.Errorf("strings should be imported once:\n%s", code)
+	}
+
+	code, err = NewGoAdapter().Generate(&dsl.TestDSL{Name: "today", Target: dsl.TestTarget{File: src, Function: "Today"}})
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+	if !strings.Contains(code, "stubHTTP(t)") || !strings.Contains(code, "func stubHTTP(") {
+		t.Errorf("DSL adapter output missing stubHTTP:\n%s", code)
+	}
+
+	// Code without HTTP calls gets no server
+	pure := filepath.Join(t.TempDir(), "math.go")
+	os.WriteFile(pure, []byte("package math\n\nfunc Add(a, b int) int { return a + b }\n"), 0644)
+	code, _ = NewGoSpecAdapter().GenerateFromSpecs([]model.TestSpec{{FunctionName: "Add", Description: "adds"}}, pure)
+	if strings.Contains(code, "stubHTTP") {
+		t.Errorf("pure code shouldn't get an HTTP stub:\n%s", code)
+	}
+}
+
+func TestJSAdapters_NockInterceptors(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "weather.js")
+	if err := os.WriteFile(src, []byte(jsWeatherSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	spec := model.TestSpec{FunctionName: "today", Description: "gets the forecast"}
+	code, err := NewJestSpecAdapter().GenerateFromSpecs([]model.TestSpec{spec}, src)
+	if err != nil {
+		t.Fatalf("GenerateFromSpecs() error: %v", err)
+	}
+	for _, want := range []string{
+		"import nock from 'nock';",
+		"nock.disableNetConnect();",
+		"nock('https://api.weather.test')",
+		".get((path) => path.startsWith('/v1/today/'))",
+		".post('/v1/reports')",
+		`.reply(200, {"city":`,
+		"nock.cleanAll();",
+		"jest.mock('./format'",
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("spec adapter output missing %q:\n%s", want, code)
+		}
+	}
+	if strings.Contains(code, "jest.mock('axios'") {
+		t.Errorf("axios calls are replayed by nock, so it shouldn't be mocked:\n%s", code)
+	}
+
+	code, err = NewJestAdapter().Generate(&dsl.TestDSL{Name: "today", Target: dsl.TestTarget{File: src, Function: "today"}})
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+	if !strings.Contains(code, "import nock from 'nock'") || !strings.Contains(code, "nock('https://api.weather.test')") {
+		t.Errorf("DSL adapter output missing nock:\n%s", code)
+	}
+}
+
+func TestPytestAdapters_VCRCassette(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "weather.py")
+	if err := os.WriteFile(src, []byte(pythonWeatherSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	spec := model.TestSpec{FunctionName: "today", Description: "gets the forecast"}
+	code, err := NewPytestSpecAdapter().GenerateFromSpecs([]model.TestSpec{spec}, src)
+	if err != nil {
+		t.Fatalf("GenerateFromSpecs() error: %v", err)
+	}
+	for _, want := range []string{
+		"import vcr",
+		"HTTP_CASSETTE = r'''",
+		`"uri": "https://api.weather.test/v1/today/"`,
+		`\"city\":`,
+		"def recorded_http(tmp_path):",
+		`with vcr.use_cassette(str(cassette), serializer="json", record_mode="none",`,
+		`match_on=["method", "host"]`,
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("spec adapter output missing %q:\n%s", want, code)
+		}
+	}
+	if strings.Contains(code, "unittest") || strings.Contains(code, "/v1/reports") {
+		t.Errorf("only today's calls should be replayed, and not patched:\n%s", code)
+	}
+
+	code, err = NewPytestAdapter().Generate(&dsl.TestDSL{Name: "report", Target: dsl.TestTarget{File: src, Function: "report"}})
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+	for _, want := range []string{`"uri": "https://api.weather.test/v1/reports"`, `"method", "scheme", "host", "port", "path"`} {
+		if !strings.Contains(code, want) {
+			t.Errorf("DSL adapter output missing %q:\n%s", want, code)
+		}
+	}
+}
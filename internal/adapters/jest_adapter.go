@@ -31,6 +31,9 @@ func (a *JestAdapter) TestFileSuffix() string {
 const jestTestTemplate = `{{range .Imports}}
 import {{.}}
 {{end}}
+{{range .Mocks}}
+{{.}}
+{{end}}
 
 describe('{{.DescribeName}}', () => {
 {{range .BeforeEach}}
@@ -56,6 +59,7 @@ describe('{{.DescribeName}}', () => {
 
 type jestTemplateData struct {
 	Imports      []string
+	Mocks        []string
 	DescribeName string
 	BeforeEach   []jestHook
 	AfterEach    []jestHook
@@ -87,6 +91,11 @@ func (a *JestAdapter) Generate(test *dsl.TestDSL) (string, error) {
 		Tests:        make([]jestTestData, 0),
 	}
 
+	// Stub the module's own imports so tests don't reach real services
+	imports, mocks := jsTestSetup(test.Target.File)
+	data.Imports = append(data.Imports, imports...)
+	data.Mocks = mocks
+
 	// Add default imports
 	if test.Target.File != "" {
 		modulePath := strings.TrimSuffix(test.Target.File, ".ts")
@@ -118,7 +127,7 @@ func (a *JestAdapter) Generate(test *dsl.TestDSL) (string, error) {
 	}
 
 	for _, step := range test.Steps {
-		code := generateJestStepCode(step)
+		code := generateJestStepCode(awaitedStep(test, step))
 		testData.Steps = append(testData.Steps, jestStep{
 			Description: step.Description,
 			Code:        code,
@@ -142,6 +151,9 @@ func (a *JestAdapter) Generate(test *dsl.TestDSL) (string, error) {
 }
 
 func hasAsyncSteps(test *dsl.TestDSL) bool {
+	if test.Target.Async {
+		return true
+	}
 	for _, step := range test.Steps {
 		if step.Action.Type == dsl.ActionHTTP || step.Action.Type == dsl.ActionWait {
 			return true
@@ -189,6 +201,9 @@ func generateJestStepCode(step dsl.TestStep) string {
 	case dsl.ActionAssert:
 		// Just assertions, no action needed
 
+	case dsl.ActionSnapshot:
+		code.WriteString(fmt.Sprintf("expect(%s).toMatchSnapshot();\n", snapshotSubject(step.Action)))
+
 	default:
 		code.WriteString(fmt.Sprintf("// %s: %s\n", step.Action.Type, step.Action.Target))
 	}
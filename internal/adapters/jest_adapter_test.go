@@ -181,6 +181,22 @@ func TestHasAsyncSteps(t *testing.T) {
 			t.Error("should return false for empty steps")
 		}
 	})
+
+	t.Run("async target", func(t *testing.T) {
+		testDSL := &dsl.TestDSL{
+			Target: dsl.TestTarget{Function: "fetchUser", Async: true},
+			Steps: []dsl.TestStep{
+				{Action: dsl.StepAction{Type: dsl.ActionCall, Target: "fetchUser", Args: []interface{}{1}}, Expected: &dsl.Expected{Value: "ada"}},
+			},
+		}
+		if !hasAsyncSteps(testDSL) {
+			t.Error("should return true for an async target")
+		}
+		code := generateJestStepCode(awaitedStep(testDSL, testDSL.Steps[0]))
+		if !strings.Contains(code, "const result = await fetchUser(1);") {
+			t.Errorf("call of an async target should be awaited: %q", code)
+		}
+	})
 }
 
 func TestFormatJSArgs(t *testing.T) {
@@ -3,16 +3,22 @@ package adapters
 import (
 	"bytes"
 	"fmt"
+	"os"
 	"sort"
 	"strconv"
 	"strings"
 	"text/template"
 
+	"github.com/QTest-hq/qtest/internal/emitter"
 	"github.com/QTest-hq/qtest/pkg/model"
 )
 
 // JestSpecAdapter generates Jest test code from model.TestSpec
-type JestSpecAdapter struct{}
+type JestSpecAdapter struct {
+	// Chaos adds tests of functions making HTTP calls whose downstream
+	// times out, returns a 503 or a malformed body
+	Chaos bool
+}
 
 func NewJestSpecAdapter() *JestSpecAdapter {
 	return &JestSpecAdapter{}
@@ -33,11 +39,14 @@ func (a *JestSpecAdapter) TestFileSuffix() string {
 const jestSpecTemplate = `{{range .Imports}}
 import {{.}};
 {{end}}
+{{range .Mocks}}
+{{.}}
+{{end}}
 
 {{range .Tests}}
 describe('{{.DescribeName}}', () => {
 {{range .Cases}}
-  test('{{.Name}}', () => {
+  test('{{.Name}}', {{if .Async}}async {{end}}() => {
     // Arrange
 {{if .Setup}}{{.Setup}}{{end}}
     // Act
@@ -53,6 +62,7 @@ describe('{{.DescribeName}}', () => {
 
 type jestSpecTemplateData struct {
 	Imports []string
+	Mocks   []string
 	Tests   []jestSpecTestData
 }
 
@@ -63,6 +73,7 @@ type jestSpecTestData struct {
 
 type jestSpecCaseData struct {
 	Name       string
+	Async      bool // the function returns a promise
 	Setup      string
 	Action     string
 	Assertions []string
@@ -89,17 +100,38 @@ func (a *JestSpecAdapter) GenerateFromSpecs(specs []model.TestSpec, sourceFile s
 		Tests:   make([]jestSpecTestData, 0),
 	}
 
+	// Stub the module's own imports so tests don't reach real services
+	imports, mocks := jsTestSetup(sourceFile)
+	data.Imports = append(data.Imports, imports...)
+	data.Mocks = mocks
+
 	// Add import for the module being tested
 	moduleName := extractJSModuleName(sourceFile)
 	if moduleName != "" {
-		// Collect all function names for import
+		// Collect all function names for import, and the module's error
+		// classes tests expect
 		funcNames := make([]string, 0, len(specsByFunc))
 		for funcName := range specsByFunc {
 			funcNames = append(funcNames, funcName)
 		}
+		funcNames = append(funcNames, jsErrorClassImports(specs)...)
 		data.Imports = append(data.Imports, fmt.Sprintf("{ %s } from '%s'", strings.Join(funcNames, ", "), moduleName))
 	}
 
+	// Functions whose HTTP calls chaos tests make fail
+	var callers map[string]string
+	var chaosSource string
+	if a.Chaos && sourceFile != "" {
+		if source, err := os.ReadFile(sourceFile); err == nil {
+			chaosSource = string(source)
+			funcNames := make([]string, 0, len(specsByFunc))
+			for funcName := range specsByFunc {
+				funcNames = append(funcNames, funcName)
+			}
+			callers = jsHTTPCallers(chaosSource, funcNames)
+		}
+	}
+
 	// Build tests grouped by function
 	for funcName, funcSpecs := range specsByFunc {
 		testData := jestSpecTestData{
@@ -110,6 +142,7 @@ func (a *JestSpecAdapter) GenerateFromSpecs(specs []model.TestSpec, sourceFile s
 		for _, spec := range funcSpecs {
 			caseData := jestSpecCaseData{
 				Name:       spec.Description,
+				Async:      spec.Async,
 				Assertions: make([]string, 0),
 			}
 
@@ -122,9 +155,12 @@ func (a *JestSpecAdapter) GenerateFromSpecs(specs []model.TestSpec, sourceFile s
 			caseData.Action = a.generateAction(spec)
 
 			// Generate assertions from spec.Assertions
-			for _, assertion := range spec.Assertions {
-				assertCode := a.generateAssertion(assertion)
-				if assertCode != "" {
+			for _, assertion := range renderedAssertions(spec) {
+				if assertion.ExpectsError() {
+					caseData.Assertions = append(caseData.Assertions, jestErrorAssertions(assertion, spec.Async)...)
+					continue
+				}
+				if assertCode := a.generateAssertion(assertion); assertCode != "" {
 					caseData.Assertions = append(caseData.Assertions, assertCode)
 				}
 			}
@@ -138,6 +174,25 @@ func (a *JestSpecAdapter) GenerateFromSpecs(specs []model.TestSpec, sourceFile s
 		}
 
 		data.Tests = append(data.Tests, testData)
+
+		// Chaos tests make the function's HTTP calls fail, expecting it
+		// to reject
+		if body, ok := callers[funcName]; ok {
+			faultData := jestSpecTestData{DescribeName: funcName + " downstream faults"}
+			for _, fault := range httpFaultsFor(body, jsParsesJSON) {
+				spec, _ := faultSpec(funcSpecs, fault)
+				spec.Async = true
+				var setup string
+				if len(spec.Inputs) > 0 {
+					setup = a.generateSetup(spec)
+				}
+				faultData.Cases = append(faultData.Cases, jestFaultCase(fault, body, setup, a.generateAction(spec)))
+			}
+			data.Tests = append(data.Tests, faultData)
+		}
+	}
+	if len(callers) > 0 {
+		data.Mocks = append(data.Mocks, jsFaultHelper(chaosSource))
 	}
 
 	// Execute template
@@ -264,7 +319,18 @@ func (a *JestSpecAdapter) generateAction(spec model.TestSpec) string {
 		sanitizedArgs[i] = sanitizeJSVarName(arg)
 	}
 
-	return fmt.Sprintf("const result = %s(%s);", funcName, strings.Join(sanitizedArgs, ", "))
+	// Await async results, except a promise expected to reject, which the
+	// assertion awaits. A call expected to throw is wrapped for the
+	// assertion to make.
+	call := fmt.Sprintf("%s(%s)", funcName, strings.Join(sanitizedArgs, ", "))
+	switch {
+	case expectsRejection(spec):
+	case spec.Async:
+		call = "await " + call
+	case expectsError(spec):
+		call = "() => " + call
+	}
+	return fmt.Sprintf("const result = %s;", call)
 }
 
 // formatJSValueWithType formats a value for JavaScript code using type hints
@@ -368,8 +434,11 @@ func (a *JestSpecAdapter) generateAssertion(assertion model.Assertion) string {
 	case "falsy":
 		return fmt.Sprintf("expect(%s).toBeFalsy();", actual)
 
-	case "throws", "error":
-		return "expect(() => result).toThrow();"
+	case "throws", "raises", "error":
+		return strings.Join(jestErrorAssertions(assertion, false), "\n    ")
+
+	case "snapshot", "matches_snapshot", "golden":
+		return fmt.Sprintf("expect(%s).toMatchSnapshot();", actual)
 
 	case "type", "type_is":
 		expected := assertion.Expected
@@ -379,6 +448,24 @@ func (a *JestSpecAdapter) generateAssertion(assertion model.Assertion) string {
 		expected := formatJSValue(assertion.Expected)
 		return fmt.Sprintf("expect(%s).toHaveLength(%s);", actual, expected)
 
+	case model.AssertContainsAll:
+		items := strings.Join(formatItems(assertion, formatJSValue), ", ")
+		return fmt.Sprintf("expect(%s).toEqual(expect.arrayContaining([%s]));", actual, items)
+
+	case model.AssertSubset:
+		items := strings.Join(formatItems(assertion, formatJSValue), ", ")
+		return fmt.Sprintf("expect([%s]).toEqual(expect.arrayContaining(%s));", items, actual)
+
+	case model.AssertSorted:
+		return fmt.Sprintf("expect(%s).toEqual([...%s].sort(%s));", actual, actual, emitter.JSSortComparator(assertion))
+
+	case model.AssertApprox:
+		return fmt.Sprintf("expect(Math.abs(%s - %s)).toBeLessThanOrEqual(%s);",
+			actual, approxExpected(assertion), approxEpsilon(assertion))
+
+	case model.AssertMatches:
+		return fmt.Sprintf("expect(String(%s)).toMatch(new RegExp(%s));", actual, strconv.Quote(matchesPattern(assertion)))
+
 	default:
 		if assertion.Expected != nil {
 			expected := formatJSValue(assertion.Expected)
@@ -457,3 +544,49 @@ func extractJSModuleName(filePath string) string {
 	// Make it a relative import
 	return "./" + name
 }
+
+// jsBuiltinErrors are the error classes JavaScript defines globally
+var jsBuiltinErrors = map[string]bool{
+	"Error": true, "TypeError": true, "RangeError": true, "ReferenceError": true,
+	"SyntaxError": true, "EvalError": true, "URIError": true, "AggregateError": true,
+}
+
+// jestErrorAssertions renders an assertion that the call throws, or for an
+// async call rejects, with the expected error class and message
+func jestErrorAssertions(assertion model.Assertion, async bool) []string {
+	expect := "expect(result).toThrow"
+	if async {
+		expect = "await expect(result).rejects.toThrow"
+	}
+
+	var lines []string
+	if assertion.ErrorType != "" {
+		lines = append(lines, fmt.Sprintf("%s(%s);", expect, assertion.ErrorType))
+	}
+	if assertion.ErrorMessage != "" {
+		lines = append(lines, fmt.Sprintf("%s(%s);", expect, strconv.Quote(assertion.ErrorMessage)))
+	}
+	if len(lines) == 0 {
+		lines = append(lines, expect+"();")
+	}
+	return lines
+}
+
+// jsErrorClassImports lists the error classes specs expect that aren't
+// built in, which are imported from the module under test
+func jsErrorClassImports(specs []model.TestSpec) []string {
+	var classes []string
+	seen := make(map[string]bool)
+	for _, spec := range specs {
+		for _, a := range spec.Assertions {
+			name := a.ErrorType
+			if !a.ExpectsError() || name == "" || jsBuiltinErrors[name] || strings.Contains(name, ".") || seen[name] {
+				continue
+			}
+			seen[name] = true
+			classes = append(classes, name)
+		}
+	}
+	sort.Strings(classes)
+	return classes
+}
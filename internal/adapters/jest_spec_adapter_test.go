@@ -179,6 +179,50 @@ func TestJestSpecAdapter_MultipleTestCases(t *testing.T) {
 	}
 }
 
+func TestJestSpecAdapter_Async(t *testing.T) {
+	adapter := NewJestSpecAdapter()
+
+	specs := []model.TestSpec{
+		{
+			FunctionName: "fetchUser",
+			Description:  "Finds an existing user",
+			Async:        true,
+			Inputs:       map[string]interface{}{"id": float64(1)},
+			ArgOrder:     []string{"id"},
+			Assertions: []model.Assertion{
+				{Kind: "not_null", Actual: "result"},
+			},
+		},
+		{
+			FunctionName: "fetchUser",
+			Description:  "Rejects a missing user",
+			Async:        true,
+			Inputs:       map[string]interface{}{"id": float64(-1)},
+			ArgOrder:     []string{"id"},
+			Assertions: []model.Assertion{
+				{Kind: "throws", Actual: "result"},
+			},
+		},
+	}
+
+	code, err := adapter.GenerateFromSpecs(specs, "users.ts")
+	if err != nil {
+		t.Fatalf("GenerateFromSpecs failed: %v", err)
+	}
+
+	for _, want := range []string{
+		"test('Finds an existing user', async () => {",
+		"const result = await fetchUser(id);",
+		"test('Rejects a missing user', async () => {",
+		"const result = fetchUser(id);",
+		"await expect(result).rejects.toThrow();",
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("expected %q in output:\n%s", want, code)
+		}
+	}
+}
+
 func TestJestSpecAdapter_StringValues(t *testing.T) {
 	adapter := NewJestSpecAdapter()
 
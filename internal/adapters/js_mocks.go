@@ -0,0 +1,295 @@
+package adapters
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// JS test runners: Vitest shares Jest's API but names its mock object vi
+// and doesn't provide globals by default
+const (
+	jsRunnerJest   = "jest"
+	jsRunnerVitest = "vitest"
+)
+
+// jsImport is a module the code under test imports and the names it binds
+type jsImport struct {
+	Module string
+	// Named maps exported names to the local names they're bound to
+	Named map[string]string
+	// Whole is set for default and namespace imports, which bind the
+	// module itself
+	Whole bool
+}
+
+var (
+	jsImportPattern  = regexp.MustCompile(`(?m)^\s*import\s+(type\s+)?([\w$*{}\s,]+?)\s+from\s+['"]([^'"]+)['"]`)
+	jsRequirePattern = regexp.MustCompile(`(?:const|let|var)\s+([\w$]+|\{[^}]*\})\s*=\s*require\(\s*['"]([^'"]+)['"]\s*\)`)
+)
+
+// jsUnmockedModules are never mocked: Node built-ins, test runners, and
+// pure libraries whose real behavior tests rely on
+var jsUnmockedModules = map[string]bool{
+	"assert": true, "buffer": true, "crypto": true, "events": true, "fs": true, "fs/promises": true,
+	"os": true, "path": true, "querystring": true, "stream": true, "url": true, "util": true,
+	"jest": true, "@jest/globals": true, "vitest": true,
+	"lodash": true, "ramda": true, "zod": true, "yup": true, "joi": true,
+	"date-fns": true, "dayjs": true, "moment": true, "react": true,
+}
+
+// detectJSImports returns the modules source imports that a unit test
+// should mock, in import order
+func detectJSImports(source string) []jsImport {
+	var imports []jsImport
+	index := make(map[string]int)
+	add := func(module, bindings string) {
+		if !shouldMockJSModule(module) {
+			return
+		}
+		i, ok := index[module]
+		if !ok {
+			i = len(imports)
+			index[module] = i
+			imports = append(imports, jsImport{Module: module, Named: make(map[string]string)})
+		}
+		parseJSBindings(&imports[i], bindings)
+	}
+
+	for _, m := range jsImportPattern.FindAllStringSubmatch(source, -1) {
+		if m[1] != "" {
+			continue // type-only imports vanish at runtime
+		}
+		add(m[3], m[2])
+	}
+	for _, m := range jsRequirePattern.FindAllStringSubmatch(source, -1) {
+		add(m[2], m[1])
+	}
+	return imports
+}
+
+// parseJSBindings records the names an import or require binds
+func parseJSBindings(imp *jsImport, bindings string) {
+	bindings = strings.TrimSpace(bindings)
+	named := ""
+	if open := strings.Index(bindings, "{"); open >= 0 {
+		end := strings.Index(bindings, "}")
+		if end < open {
+			end = len(bindings)
+		}
+		named = bindings[open+1 : end]
+		bindings = bindings[:open] + bindings[min(end+1, len(bindings)):]
+	}
+	if strings.Trim(bindings, " \t\n,") != "" {
+		imp.Whole = true // default or * as ns
+	}
+
+	for _, part := range strings.Split(named, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" || strings.HasPrefix(part, "type ") {
+			continue
+		}
+		exported, local := part, part
+		if i := strings.Index(part, " as "); i >= 0 {
+			exported, local = strings.TrimSpace(part[:i]), strings.TrimSpace(part[i+4:])
+		} else if i := strings.Index(part, ":"); i >= 0 {
+			// require destructuring: { name: local }
+			exported, local = strings.TrimSpace(part[:i]), strings.TrimSpace(part[i+1:])
+		}
+		imp.Named[exported] = local
+	}
+}
+
+func shouldMockJSModule(module string) bool {
+	if strings.HasPrefix(module, "node:") || jsUnmockedModules[module] {
+		return false
+	}
+	// Styles, data and assets aren't code to stub
+	switch filepath.Ext(module) {
+	case ".css", ".scss", ".less", ".json", ".svg", ".png", ".jpg", ".gif":
+		return false
+	}
+	return true
+}
+
+// jsMockFactories give well-known I/O libraries mocks shaped like their
+// real clients, with resolved values tests can read. fn is jest.fn or vi.fn.
+var jsMockFactories = map[string]func(fn string) string{
+	"axios": func(fn string) string {
+		return fmt.Sprintf(`() => {
+  const client = {
+    get: %[1]s().mockResolvedValue({ status: 200, data: {} }),
+    post: %[1]s().mockResolvedValue({ status: 200, data: {} }),
+    put: %[1]s().mockResolvedValue({ status: 200, data: {} }),
+    patch: %[1]s().mockResolvedValue({ status: 200, data: {} }),
+    delete: %[1]s().mockResolvedValue({ status: 200, data: {} }),
+    request: %[1]s().mockResolvedValue({ status: 200, data: {} }),
+  };
+  return { __esModule: true, default: { ...client, create: %[1]s(() => client) }, ...client };
+}`, fn)
+	},
+	"node-fetch": func(fn string) string {
+		return fmt.Sprintf(`() => ({
+  __esModule: true,
+  default: %[1]s().mockResolvedValue({ ok: true, status: 200, json: %[1]s().mockResolvedValue({}), text: %[1]s().mockResolvedValue('') }),
+})`, fn)
+	},
+	"pg": func(fn string) string {
+		return fmt.Sprintf(`() => {
+  const client = () => ({
+    query: %[1]s().mockResolvedValue({ rows: [], rowCount: 0 }),
+    connect: %[1]s().mockResolvedValue({ query: %[1]s().mockResolvedValue({ rows: [], rowCount: 0 }), release: %[1]s() }),
+    end: %[1]s().mockResolvedValue(undefined),
+  });
+  return { Pool: %[1]s(client), Client: %[1]s(client) };
+}`, fn)
+	},
+	"mysql2/promise": func(fn string) string {
+		return fmt.Sprintf(`() => {
+  const conn = () => ({
+    query: %[1]s().mockResolvedValue([[], []]),
+    execute: %[1]s().mockResolvedValue([[], []]),
+    end: %[1]s().mockResolvedValue(undefined),
+  });
+  const mysql = { createConnection: %[1]s().mockImplementation(async () => conn()), createPool: %[1]s(conn) };
+  return { __esModule: true, default: mysql, ...mysql };
+}`, fn)
+	},
+	"ioredis": func(fn string) string {
+		return fmt.Sprintf(`() => ({
+  __esModule: true,
+  default: %[1]s(() => ({
+    get: %[1]s().mockResolvedValue(null),
+    set: %[1]s().mockResolvedValue('OK'),
+    del: %[1]s().mockResolvedValue(1),
+    quit: %[1]s().mockResolvedValue('OK'),
+  })),
+})`, fn)
+	},
+	"redis": func(fn string) string {
+		return fmt.Sprintf(`() => ({
+  createClient: %[1]s(() => ({
+    connect: %[1]s().mockResolvedValue(undefined),
+    get: %[1]s().mockResolvedValue(null),
+    set: %[1]s().mockResolvedValue('OK'),
+    del: %[1]s().mockResolvedValue(1),
+    quit: %[1]s().mockResolvedValue('OK'),
+    on: %[1]s(),
+  })),
+})`, fn)
+	},
+}
+
+// jsListPrefixes mark functions resolving to collections
+var jsListPrefixes = []string{"find", "list", "query", "search", "select", "getAll", "fetchAll"}
+
+// jsModuleMocks returns a jest.mock or vi.mock call per import. Known
+// libraries get realistic factories; named imports the source awaits
+// resolve to an empty value; anything else is automocked. HTTP clients
+// are left to nock when the source calls fixed URLs.
+func jsModuleMocks(source, runner string) []string {
+	mocker, fn := "jest", "jest.fn"
+	if runner == jsRunnerVitest {
+		mocker, fn = "vi", "vi.fn"
+	}
+
+	// Clients whose calls are replayed by nock are left real
+	replayed := len(detectJSHTTPCalls(source)) > 0
+
+	var mocks []string
+	for _, imp := range detectJSImports(source) {
+		if replayed && jsHTTPClientModules[imp.Module] {
+			continue
+		}
+		if factory, ok := jsMockFactories[imp.Module]; ok {
+			mocks = append(mocks, fmt.Sprintf("%s.mock('%s', %s);", mocker, imp.Module, factory(fn)))
+			continue
+		}
+		if imp.Whole || len(imp.Named) == 0 {
+			mocks = append(mocks, fmt.Sprintf("%s.mock('%s');", mocker, imp.Module))
+			continue
+		}
+
+		names := make([]string, 0, len(imp.Named))
+		for name := range imp.Named {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "%s.mock('%s', () => ({\n", mocker, imp.Module)
+		for _, name := range names {
+			fmt.Fprintf(&sb, "  %s: %s,\n", name, jsMockFunction(source, imp.Named[name], fn))
+		}
+		sb.WriteString("}));")
+		mocks = append(mocks, sb.String())
+	}
+	return mocks
+}
+
+// jsMockFunction stubs one imported function. Ones the source awaits
+// resolve to [] or {} by name, so code under test can read the result.
+func jsMockFunction(source, local, fn string) string {
+	awaited := regexp.MustCompile(`await\s+` + regexp.QuoteMeta(local) + `\s*\(`)
+	if !awaited.MatchString(source) {
+		return fn + "()"
+	}
+	for _, prefix := range jsListPrefixes {
+		if strings.HasPrefix(local, prefix) && !strings.Contains(local, "One") && !strings.Contains(local, "ById") {
+			return fn + "().mockResolvedValue([])"
+		}
+	}
+	return fn + "().mockResolvedValue({})"
+}
+
+// detectJSRunner reports whether the project containing sourceFile tests
+// with Vitest, from the nearest package.json
+func detectJSRunner(sourceFile string) string {
+	dir := filepath.Dir(sourceFile)
+	for {
+		data, err := os.ReadFile(filepath.Join(dir, "package.json"))
+		if err == nil {
+			if strings.Contains(string(data), `"vitest"`) {
+				return jsRunnerVitest
+			}
+			return jsRunnerJest
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return jsRunnerJest
+		}
+		dir = parent
+	}
+}
+
+// jsTestSetup reads sourceFile and returns the imports and mocks that keep
+// its dependencies out of a unit test: the runner's API when it's Vitest,
+// module mocks, and nock interceptors replaying its HTTP calls. An
+// unreadable file gets neither.
+func jsTestSetup(sourceFile string) (imports, mocks []string) {
+	if sourceFile == "" {
+		return nil, nil
+	}
+	runner := detectJSRunner(sourceFile)
+	if runner == jsRunnerVitest {
+		imports = append(imports, vitestImport)
+	}
+	source, err := os.ReadFile(sourceFile)
+	if err != nil {
+		return imports, nil
+	}
+
+	mocks = jsModuleMocks(string(source), runner)
+	if interceptors := jsHTTPInterceptors(string(source)); len(interceptors) > 0 {
+		imports = append(imports, "nock from 'nock'")
+		mocks = append(mocks, interceptors...)
+	}
+	return imports, mocks
+}
+
+// vitestImport brings Vitest's test API into scope, since it doesn't
+// provide globals by default
+const vitestImport = "{ describe, test, it, expect, beforeEach, afterEach, vi } from 'vitest'"
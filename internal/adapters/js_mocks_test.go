@@ -0,0 +1,120 @@
+package adapters
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/QTest-hq/qtest/pkg/dsl"
+	"github.com/QTest-hq/qtest/pkg/model"
+)
+
+const jsServiceSource = `import axios from 'axios';
+import path from 'path';
+import type { User } from './types';
+import { findUsers, saveUser as persist, formatName } from './db';
+import * as cache from '../cache';
+import './styles.css';
+const { Pool } = require('pg');
+
+export async function syncUsers() {
+  const users = await findUsers();
+  await persist(users[0]);
+  return formatName(users[0]);
+}
+`
+
+func TestDetectJSImports(t *testing.T) {
+	imports := detectJSImports(jsServiceSource)
+
+	var modules []string
+	for _, imp := range imports {
+		modules = append(modules, imp.Module)
+	}
+	if got := strings.Join(modules, ","); got != "axios,./db,../cache,pg" {
+		t.Fatalf("modules = %s, want axios,./db,../cache,pg", got)
+	}
+
+	db := imports[1]
+	if db.Whole || db.Named["saveUser"] != "persist" || db.Named["findUsers"] != "findUsers" || len(db.Named) != 3 {
+		t.Errorf("./db import = %+v", db)
+	}
+	if !imports[0].Whole || !imports[2].Whole {
+		t.Error("default and namespace imports should bind the whole module")
+	}
+	if imports[3].Named["Pool"] != "Pool" {
+		t.Errorf("require destructuring = %+v, want Pool", imports[3])
+	}
+}
+
+func TestJSModuleMocks(t *testing.T) {
+	mocks := strings.Join(jsModuleMocks(jsServiceSource, jsRunnerJest), "\n")
+
+	for _, want := range []string{
+		"jest.mock('axios', () => {",
+		"get: jest.fn().mockResolvedValue({ status: 200, data: {} })",
+		"findUsers: jest.fn().mockResolvedValue([]),",
+		"saveUser: jest.fn().mockResolvedValue({}),",
+		"formatName: jest.fn(),",
+		"jest.mock('../cache');",
+		"return { Pool: jest.fn(client), Client: jest.fn(client) };",
+	} {
+		if !strings.Contains(mocks, want) {
+			t.Errorf("mocks missing %q:\n%s", want, mocks)
+		}
+	}
+	for _, unwanted := range []string{"'path'", "'./types'", "styles.css"} {
+		if strings.Contains(mocks, unwanted) {
+			t.Errorf("mocks shouldn't stub %s:\n%s", unwanted, mocks)
+		}
+	}
+
+	vitest := strings.Join(jsModuleMocks(jsServiceSource, jsRunnerVitest), "\n")
+	if strings.Contains(vitest, "jest.") || !strings.Contains(vitest, "vi.mock('./db', () => ({") {
+		t.Errorf("vitest mocks should use vi:\n%s", vitest)
+	}
+}
+
+func TestDetectJSRunner(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src", "service.ts")
+	os.MkdirAll(filepath.Dir(src), 0755)
+
+	if got := detectJSRunner(src); got != jsRunnerJest {
+		t.Errorf("detectJSRunner() without package.json = %s, want jest", got)
+	}
+	os.WriteFile(filepath.Join(dir, "package.json"), []byte(`{"devDependencies": {"vitest": "^1.0.0"}}`), 0644)
+	if got := detectJSRunner(src); got != jsRunnerVitest {
+		t.Errorf("detectJSRunner() = %s, want vitest", got)
+	}
+}
+
+func TestJSAdapters_MockImports(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "service.ts")
+	if err := os.WriteFile(src, []byte(jsServiceSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(dir, "package.json"), []byte(`{"devDependencies": {"vitest": "^1.0.0"}}`), 0644)
+
+	spec := model.TestSpec{FunctionName: "syncUsers", Description: "syncs users"}
+	code, err := NewJestSpecAdapter().GenerateFromSpecs([]model.TestSpec{spec}, src)
+	if err != nil {
+		t.Fatalf("GenerateFromSpecs() error: %v", err)
+	}
+	if !strings.Contains(code, "import "+vitestImport+";") || !strings.Contains(code, "vi.mock('axios'") {
+		t.Errorf("spec adapter output missing vitest import or mocks:\n%s", code)
+	}
+	if strings.Index(code, "vi.mock(") > strings.Index(code, "describe(") {
+		t.Error("mocks should come before the tests")
+	}
+
+	code, err = NewJestAdapter().Generate(&dsl.TestDSL{Name: "sync", Target: dsl.TestTarget{File: src, Function: "syncUsers"}})
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+	if !strings.Contains(code, "vi.mock('./db'") {
+		t.Errorf("DSL adapter output missing mocks:\n%s", code)
+	}
+}
@@ -0,0 +1,141 @@
+package adapters
+
+import (
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// Conflict markers labels in a merge proposal
+const (
+	labelEdited      = "edited"
+	labelGenerated   = "generated"
+	labelRegenerated = "regenerated"
+)
+
+// MergeResult is a three-way merge of a human-edited test file with newly
+// generated tests
+type MergeResult struct {
+	Content   string `json:"-"`
+	Conflicts int    `json:"conflicts"` // hunks changed on both sides differently
+}
+
+// Merge3 merges the changes from base to ours and from base to theirs line
+// by line, like diff3. Where both sides changed the same lines differently
+// the hunk is kept with git-style conflict markers showing all three
+// versions. With an empty base every difference is a conflict.
+func Merge3(base, ours, theirs string) MergeResult {
+	b, o, t := splitLines(base), splitLines(ours), splitLines(theirs)
+	toOurs := matchLines(b, o)
+	toTheirs := matchLines(b, t)
+
+	var sb strings.Builder
+	result := MergeResult{}
+	i, j, k := 0, 0, 0
+	for {
+		// Next base line both sides kept, where the versions sync up again
+		next := i
+		for next < len(b) && (toOurs[next] < j || toTheirs[next] < k) {
+			next++
+		}
+
+		ni, nj, nk := len(b), len(o), len(t)
+		if next < len(b) {
+			ni, nj, nk = next, toOurs[next], toTheirs[next]
+		}
+
+		if ni == i && nj == j && nk == k {
+			if next >= len(b) {
+				break
+			}
+			// Unchanged line
+			sb.WriteString(b[i])
+			i, j, k = i+1, j+1, k+1
+			continue
+		}
+
+		baseHunk, ourHunk, theirHunk := b[i:ni], o[j:nj], t[k:nk]
+		switch {
+		case equalLines(ourHunk, baseHunk):
+			writeLines(&sb, theirHunk)
+		case equalLines(theirHunk, baseHunk), equalLines(ourHunk, theirHunk):
+			writeLines(&sb, ourHunk)
+		default:
+			result.Conflicts++
+			sb.WriteString("<<<<<<< " + labelEdited + "\n")
+			writeLines(&sb, ourHunk)
+			sb.WriteString("||||||| " + labelGenerated + "\n")
+			writeLines(&sb, baseHunk)
+			sb.WriteString("=======\n")
+			writeLines(&sb, theirHunk)
+			sb.WriteString(">>>>>>> " + labelRegenerated + "\n")
+		}
+		i, j, k = ni, nj, nk
+	}
+
+	result.Content = sb.String()
+	return result
+}
+
+// UnifiedDiff returns a unified diff from a to b, or "" if they're equal
+func UnifiedDiff(a, b, fromName, toName string) string {
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        splitLines(a),
+		B:        splitLines(b),
+		FromFile: fromName,
+		ToFile:   toName,
+		Context:  3,
+	})
+	if err != nil {
+		return ""
+	}
+	return diff
+}
+
+// matchLines maps each line of a to the line of b it matches, or -1
+func matchLines(a, b []string) []int {
+	matched := make([]int, len(a))
+	for i := range matched {
+		matched[i] = -1
+	}
+	// Without autojunk, common lines like "}" still anchor the match
+	m := difflib.NewMatcherWithJunk(a, b, false, nil)
+	for _, block := range m.GetMatchingBlocks() {
+		for n := 0; n < block.Size; n++ {
+			matched[block.A+n] = block.B + n
+		}
+	}
+	return matched
+}
+
+// splitLines splits text into lines that each end in a newline
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	} else {
+		lines[len(lines)-1] += "\n"
+	}
+	return lines
+}
+
+func equalLines(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func writeLines(sb *strings.Builder, lines []string) {
+	for _, line := range lines {
+		sb.WriteString(line)
+	}
+}
@@ -0,0 +1,61 @@
+package adapters
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMerge3(t *testing.T) {
+	base := "a\nb\nc\nd\ne\n"
+
+	tests := []struct {
+		name      string
+		ours      string
+		theirs    string
+		want      string
+		conflicts int
+	}{
+		{"unchanged", base, base, base, 0},
+		{"only ours", "a\nB\nc\nd\ne\n", base, "a\nB\nc\nd\ne\n", 0},
+		{"only theirs", base, "a\nb\nc\nD\ne\n", "a\nb\nc\nD\ne\n", 0},
+		{"both, apart", "a\nB\nc\nd\ne\n", "a\nb\nc\nD\ne\n", "a\nB\nc\nD\ne\n", 0},
+		{"both, same change", "a\nX\nc\nd\ne\n", "a\nX\nc\nd\ne\n", "a\nX\nc\nd\ne\n", 0},
+		{"insert and delete", "a\nb\nc\nd\ne\nf\n", "a\nc\nd\ne\n", "a\nc\nd\ne\nf\n", 0},
+		{"conflict", "a\nOURS\nc\nd\ne\n", "a\nTHEIRS\nc\nd\ne\n",
+			"a\n<<<<<<< edited\nOURS\n||||||| generated\nb\n=======\nTHEIRS\n>>>>>>> regenerated\nc\nd\ne\n", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Merge3(base, tt.ours, tt.theirs)
+			if got.Content != tt.want {
+				t.Errorf("Content =\n%s\nwant\n%s", got.Content, tt.want)
+			}
+			if got.Conflicts != tt.conflicts {
+				t.Errorf("Conflicts = %d, want %d", got.Conflicts, tt.conflicts)
+			}
+		})
+	}
+}
+
+func TestMerge3_NoBase(t *testing.T) {
+	got := Merge3("", "mine\n", "theirs\n")
+	if got.Conflicts != 1 {
+		t.Errorf("Conflicts = %d, want 1", got.Conflicts)
+	}
+	if !strings.Contains(got.Content, "mine\n") || !strings.Contains(got.Content, "theirs\n") {
+		t.Errorf("both sides should be kept:\n%s", got.Content)
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	if diff := UnifiedDiff("a\n", "a\n", "x", "y"); diff != "" {
+		t.Errorf("UnifiedDiff of equal text = %q, want empty", diff)
+	}
+
+	diff := UnifiedDiff("a\nb\n", "a\nc\n", "old", "new")
+	for _, want := range []string{"--- old", "+++ new", "-b", "+c"} {
+		if !strings.Contains(diff, want) {
+			t.Errorf("diff missing %q:\n%s", want, diff)
+		}
+	}
+}
@@ -0,0 +1,307 @@
+package adapters
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/QTest-hq/qtest/internal/llm"
+)
+
+// generatedBanner marks files QTest wrote; Go tooling treats files with this
+// comment as generated
+const generatedBanner = "Code generated by QTest. DO NOT EDIT."
+
+// provenanceMarker starts the header line holding a file's provenance
+const provenanceMarker = "qtest:provenance "
+
+// headerLines is the length of the header: the banner and the provenance
+const headerLines = 2
+
+// Provenance records where a generated test file came from. It's written as
+// a header comment so QTest can recognise its own files later.
+type Provenance struct {
+	RunID        string    `json:"run_id,omitempty"`
+	Model        string    `json:"model,omitempty"`
+	PromptHash   string    `json:"prompt_hash,omitempty"`
+	SourceCommit string    `json:"source_commit,omitempty"`
+	Source       string    `json:"source,omitempty"`  // source file under test
+	Targets      []string  `json:"targets,omitempty"` // functions under test, Class.Method for methods
+	GeneratedAt  time.Time `json:"generated_at"`
+	Checksum     string    `json:"checksum,omitempty"` // of the code below the header
+
+	// Attempts lists the providers generation failed over through, ending
+	// with the one that answered; empty when the first answered
+	Attempts []llm.Attempt `json:"attempts,omitempty"`
+}
+
+// TargetName names a function under test as recorded in Provenance.Targets
+func TargetName(class, name string) string {
+	if class == "" {
+		return name
+	}
+	return class + "." + name
+}
+
+// Ownership describes who a test file belongs to
+type Ownership string
+
+const (
+	OwnershipNone      Ownership = "none"      // file doesn't exist
+	OwnershipGenerated Ownership = "generated" // written by QTest, unchanged
+	OwnershipEdited    Ownership = "edited"    // written by QTest, edited since
+	OwnershipHuman     Ownership = "human"     // no QTest header
+)
+
+// ErrNotOwned is returned when QTest won't overwrite a test file because a
+// human wrote or edited it
+var ErrNotOwned = errors.New("test file is not owned by QTest")
+
+// StampProvenance prepends a provenance header to generated code, replacing
+// any header already there. The header is commented for the language of
+// path.
+func StampProvenance(code, path string, p Provenance) string {
+	body := stripProvenance(code)
+	if p.GeneratedAt.IsZero() {
+		p.GeneratedAt = time.Now().UTC()
+	}
+	p.Checksum = checksum(body)
+
+	data, _ := json.Marshal(p)
+	prefix := commentPrefix(path)
+
+	var sb strings.Builder
+	sb.WriteString(prefix + " " + generatedBanner + "\n")
+	sb.WriteString(prefix + " " + provenanceMarker + string(data) + "\n")
+	sb.WriteString(body)
+	return sb.String()
+}
+
+// ParseProvenance reads the provenance header of a file's content, or
+// returns nil if it has none
+func ParseProvenance(content string) (*Provenance, error) {
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 4096), 1024*1024)
+	for i := 0; i < headerLines && scanner.Scan(); i++ {
+		text, ok := headerComment(scanner.Text())
+		if !ok || !strings.HasPrefix(text, provenanceMarker) {
+			continue
+		}
+		var p Provenance
+		if err := json.Unmarshal([]byte(strings.TrimPrefix(text, provenanceMarker)), &p); err != nil {
+			return nil, fmt.Errorf("invalid provenance header: %w", err)
+		}
+		return &p, nil
+	}
+	return nil, nil
+}
+
+// Edited reports whether the code below the header changed since QTest
+// wrote it
+func (p *Provenance) Edited(content string) bool {
+	return p.Checksum != "" && checksum(stripProvenance(content)) != p.Checksum
+}
+
+// FileOwnership reads a test file and reports who owns it, with its
+// provenance if QTest wrote it
+func FileOwnership(path string) (Ownership, *Provenance, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return OwnershipNone, nil, nil
+		}
+		return "", nil, err
+	}
+
+	content := string(data)
+	p, err := ParseProvenance(content)
+	if err != nil || p == nil {
+		// A mangled header is treated as a human edit
+		return OwnershipHuman, nil, nil
+	}
+	if p.Edited(content) {
+		return OwnershipEdited, p, nil
+	}
+	return OwnershipGenerated, p, nil
+}
+
+// MergeSuffix is appended to an edited test file's name for the merge
+// proposal written next to it
+const MergeSuffix = ".qtest-merge"
+
+// WriteOptions tell WriteGeneratedFile what QTest last wrote to a file, as
+// recorded outside the file (e.g. in the database)
+type WriteOptions struct {
+	Base     string // content last generated, including its header
+	BaseHash string // ContentHash of Base; computed from Base if empty
+}
+
+// WriteResult describes what WriteGeneratedFile did
+type WriteResult struct {
+	Path      string // file the generated code was written to, "" if it wasn't
+	Previous  string // generated code that was at Path before, "" for a new file
+	MergePath string // merge proposal, when the file had been edited
+	Conflicts int    // conflicting hunks in the merge proposal
+	Diff      string // unified diff from the edited file to the proposal
+}
+
+// WriteGeneratedFile stamps code with its provenance and writes it to path,
+// unless a human wrote or edited the file there:
+//
+//   - a file QTest generated that was edited since is never overwritten.
+//     Instead a three-way merge of the edits and the new code is written to
+//     path+MergeSuffix for the user to review.
+//   - a file without a QTest header is left alone and the code goes to a
+//     QTest-specific file next to it (see AlternateTestPath).
+//
+// A file whose header was removed but whose content matches opts.Base
+// still counts as QTest's.
+func WriteGeneratedFile(path, code string, p Provenance, opts WriteOptions) (*WriteResult, error) {
+	ownership, current, err := fileOwnership(path, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	switch ownership {
+	case OwnershipEdited:
+		return writeMergeProposal(path, current, code, opts.Base)
+	case OwnershipHuman:
+		alt := AlternateTestPath(path)
+		altOwnership, _, err := FileOwnership(alt)
+		if err != nil {
+			return nil, err
+		}
+		if altOwnership != OwnershipNone && altOwnership != OwnershipGenerated {
+			return nil, fmt.Errorf("%w: %s", ErrNotOwned, path)
+		}
+		path = alt
+		current = ""
+		if altOwnership == OwnershipGenerated {
+			data, err := os.ReadFile(alt)
+			if err != nil {
+				return nil, err
+			}
+			current = string(data)
+		}
+	}
+
+	if err := os.WriteFile(path, []byte(StampProvenance(code, path, p)), 0644); err != nil {
+		return nil, err
+	}
+	return &WriteResult{Path: path, Previous: current}, nil
+}
+
+// ContentHash hashes a test file's code, ignoring its provenance header
+func ContentHash(content string) string {
+	return checksum(stripProvenance(content))
+}
+
+// fileOwnership is FileOwnership, also using what QTest last wrote to the
+// file to recognise it. It returns the file's current content.
+func fileOwnership(path string, opts WriteOptions) (Ownership, string, error) {
+	ownership, _, err := FileOwnership(path)
+	if err != nil || ownership == OwnershipNone {
+		return ownership, "", err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", "", err
+	}
+	current := string(data)
+
+	if opts.Base != "" || opts.BaseHash != "" {
+		baseHash := opts.BaseHash
+		if baseHash == "" {
+			baseHash = ContentHash(opts.Base)
+		}
+		if ContentHash(current) == baseHash {
+			ownership = OwnershipGenerated
+		} else if ownership == OwnershipHuman {
+			// QTest wrote this file; someone changed it and dropped the header
+			ownership = OwnershipEdited
+		}
+	}
+	return ownership, current, nil
+}
+
+// writeMergeProposal merges regenerated code into an edited test file and
+// writes the result next to it, leaving the file itself untouched
+func writeMergeProposal(path, current, code, base string) (*WriteResult, error) {
+	merged := Merge3(stripProvenance(base), stripProvenance(current), code)
+
+	mergePath := path + MergeSuffix
+	if err := os.WriteFile(mergePath, []byte(merged.Content), 0644); err != nil {
+		return nil, err
+	}
+	return &WriteResult{
+		MergePath: mergePath,
+		Conflicts: merged.Conflicts,
+		Diff:      UnifiedDiff(stripProvenance(current), merged.Content, filepath.Base(path), filepath.Base(mergePath)),
+	}, nil
+}
+
+// AlternateTestPath returns the file QTest writes to when a human wrote
+// path: foo_test.go becomes foo_qtest_test.go, test_foo.py becomes
+// test_foo_qtest.py and foo.test.ts becomes foo.qtest.test.ts.
+func AlternateTestPath(path string) string {
+	dir, base := filepath.Split(path)
+	for _, suffix := range []string{"_test.go", ".test.ts", ".test.js", ".spec.ts", ".spec.js"} {
+		if strings.HasSuffix(base, suffix) {
+			sep := suffix[:1]
+			return dir + strings.TrimSuffix(base, suffix) + sep + "qtest" + suffix
+		}
+	}
+	ext := filepath.Ext(base)
+	return dir + strings.TrimSuffix(base, ext) + "_qtest" + ext
+}
+
+// stripProvenance removes a leading provenance header from content
+func stripProvenance(content string) string {
+	for i := 0; i < headerLines; i++ {
+		line, rest, found := strings.Cut(content, "\n")
+		text, ok := headerComment(line)
+		if !ok || (text != generatedBanner && !strings.HasPrefix(text, provenanceMarker)) {
+			break
+		}
+		if !found {
+			return ""
+		}
+		content = rest
+	}
+	return content
+}
+
+// headerComment returns the text of a line comment
+func headerComment(line string) (string, bool) {
+	line = strings.TrimSpace(line)
+	for _, prefix := range []string{"//", "#"} {
+		if strings.HasPrefix(line, prefix) {
+			return strings.TrimSpace(strings.TrimPrefix(line, prefix)), true
+		}
+	}
+	return "", false
+}
+
+// commentPrefix returns the line comment syntax for a file
+func commentPrefix(path string) string {
+	switch filepath.Ext(path) {
+	case ".py", ".rb", ".feature", ".yaml", ".yml":
+		return "#"
+	}
+	return "//"
+}
+
+// checksum hashes code, ignoring line ending and trailing whitespace changes
+// an editor may make on save
+func checksum(code string) string {
+	code = strings.ReplaceAll(code, "\r\n", "\n")
+	sum := sha256.Sum256([]byte(strings.TrimRight(code, " \t\n")))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
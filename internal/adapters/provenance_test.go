@@ -0,0 +1,303 @@
+package adapters
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/QTest-hq/qtest/internal/llm"
+)
+
+func testProvenance() Provenance {
+	return Provenance{
+		RunID:        "run-1",
+		Model:        "qwen2.5-coder:7b",
+		PromptHash:   "abc123",
+		SourceCommit: "deadbeef",
+		Source:       "pkg/math.go",
+		Targets:      []string{"Add", "Calculator.Sub"},
+		GeneratedAt:  time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+}
+
+func TestStampProvenance_RoundTrip(t *testing.T) {
+	code := "package math\n\nfunc TestAdd(t *testing.T) {}\n"
+	stamped := StampProvenance(code, "math_test.go", testProvenance())
+
+	if !strings.HasPrefix(stamped, "// Code generated by QTest. DO NOT EDIT.\n// qtest:provenance {") {
+		t.Errorf("unexpected header:\n%s", stamped)
+	}
+	if !strings.HasSuffix(stamped, code) {
+		t.Error("code should follow the header unchanged")
+	}
+
+	p, err := ParseProvenance(stamped)
+	if err != nil {
+		t.Fatalf("ParseProvenance() error = %v", err)
+	}
+	if p == nil {
+		t.Fatal("ParseProvenance() = nil")
+	}
+	want := testProvenance()
+	if p.RunID != want.RunID || p.Model != want.Model || p.PromptHash != want.PromptHash ||
+		p.SourceCommit != want.SourceCommit || p.Source != want.Source || !p.GeneratedAt.Equal(want.GeneratedAt) {
+		t.Errorf("ParseProvenance() = %+v, want %+v", p, want)
+	}
+	if strings.Join(p.Targets, ",") != "Add,Calculator.Sub" {
+		t.Errorf("Targets = %v, want %v", p.Targets, want.Targets)
+	}
+	if p.Edited(stamped) {
+		t.Error("Edited() = true for untouched file")
+	}
+}
+
+func TestStampProvenance_Attempts(t *testing.T) {
+	prov := testProvenance()
+	prov.Attempts = []llm.Attempt{
+		{Provider: llm.ProviderOllama, Model: "deepseek-coder-v2:16b", Tier: llm.Tier2, Error: "circuit open"},
+		{Provider: llm.ProviderOpenAI, Model: "gpt-4o-mini", Tier: llm.Tier2},
+	}
+	stamped := StampProvenance("package math\n", "math_test.go", prov)
+
+	p, err := ParseProvenance(stamped)
+	if err != nil || p == nil {
+		t.Fatalf("ParseProvenance() = %v, %v", p, err)
+	}
+	if len(p.Attempts) != 2 || p.Attempts[0].Error != "circuit open" || p.Attempts[1].Provider != llm.ProviderOpenAI {
+		t.Errorf("Attempts = %+v, want %+v", p.Attempts, prov.Attempts)
+	}
+	if strings.Contains(StampProvenance("package math\n", "math_test.go", testProvenance()), "attempts") {
+		t.Error("attempts should be left out when generation didn't fail over")
+	}
+}
+
+func TestStampProvenance_Python(t *testing.T) {
+	stamped := StampProvenance("def test_add():\n    pass\n", "test_math.py", testProvenance())
+	if !strings.HasPrefix(stamped, "# Code generated by QTest. DO NOT EDIT.\n# qtest:provenance ") {
+		t.Errorf("unexpected header:\n%s", stamped)
+	}
+	if p, _ := ParseProvenance(stamped); p == nil || p.RunID != "run-1" {
+		t.Errorf("ParseProvenance() = %+v", p)
+	}
+}
+
+func TestStampProvenance_Restamp(t *testing.T) {
+	code := "package math\n"
+	first := StampProvenance(code, "math_test.go", testProvenance())
+
+	p := testProvenance()
+	p.RunID = "run-2"
+	second := StampProvenance(first, "math_test.go", p)
+
+	if strings.Count(second, "qtest:provenance") != 1 {
+		t.Errorf("restamping should replace the header:\n%s", second)
+	}
+	if got, _ := ParseProvenance(second); got == nil || got.RunID != "run-2" {
+		t.Errorf("ParseProvenance() = %+v, want run-2", got)
+	}
+}
+
+func TestProvenance_Edited(t *testing.T) {
+	stamped := StampProvenance("package math\n\nfunc TestAdd(t *testing.T) {}\n", "math_test.go", testProvenance())
+	p, _ := ParseProvenance(stamped)
+
+	tests := []struct {
+		name    string
+		content string
+		want    bool
+	}{
+		{"unchanged", stamped, false},
+		{"trailing newline", stamped + "\n\n", false},
+		{"crlf", strings.ReplaceAll(stamped, "\n", "\r\n"), false},
+		{"new test", stamped + "\nfunc TestSub(t *testing.T) {}\n", true},
+		{"changed", strings.Replace(stamped, "TestAdd", "TestAddition", 1), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := p.Edited(tt.content); got != tt.want {
+				t.Errorf("Edited() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseProvenance_None(t *testing.T) {
+	p, err := ParseProvenance("package math\n\n// qtest:provenance {} deep in the file is ignored\n")
+	if err != nil || p != nil {
+		t.Errorf("ParseProvenance() = %+v, %v, want nil, nil", p, err)
+	}
+
+	if _, err := ParseProvenance("// qtest:provenance {not json\n"); err == nil {
+		t.Error("expected error for malformed header")
+	}
+}
+
+func TestFileOwnership(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	stamped := StampProvenance("package math\n", "a_test.go", testProvenance())
+	tests := []struct {
+		path string
+		want Ownership
+	}{
+		{filepath.Join(dir, "missing_test.go"), OwnershipNone},
+		{write("generated_test.go", stamped), OwnershipGenerated},
+		{write("edited_test.go", stamped+"func TestMore(t *testing.T) {}\n"), OwnershipEdited},
+		{write("human_test.go", "package math\n"), OwnershipHuman},
+		{write("mangled_test.go", "// qtest:provenance {oops\npackage math\n"), OwnershipHuman},
+	}
+	for _, tt := range tests {
+		got, _, err := FileOwnership(tt.path)
+		if err != nil {
+			t.Fatalf("FileOwnership(%s) error = %v", tt.path, err)
+		}
+		if got != tt.want {
+			t.Errorf("FileOwnership(%s) = %s, want %s", filepath.Base(tt.path), got, tt.want)
+		}
+	}
+}
+
+func TestWriteGeneratedFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "math_test.go")
+
+	// New file
+	res, err := WriteGeneratedFile(path, "package math\n", testProvenance(), WriteOptions{})
+	if err != nil || res.Path != path || res.Previous != "" {
+		t.Fatalf("WriteGeneratedFile() = %+v, %v", res, err)
+	}
+	first, _ := os.ReadFile(path)
+
+	// Regenerating over our own file is fine
+	if res, err = WriteGeneratedFile(path, "package math\n\n// v2\n", testProvenance(), WriteOptions{}); err != nil || res.Path != path {
+		t.Fatalf("regenerate = %+v, %v", res, err)
+	}
+	if res.Previous != string(first) {
+		t.Errorf("Previous = %q, want %q", res.Previous, first)
+	}
+
+	// Files a human wrote are left alone; QTest writes next to them
+	human := filepath.Join(dir, "human_test.go")
+	os.WriteFile(human, []byte("package math\n"), 0644)
+
+	res, err = WriteGeneratedFile(human, "package math\n", testProvenance(), WriteOptions{})
+	if err != nil {
+		t.Fatalf("WriteGeneratedFile() error = %v", err)
+	}
+	if want := filepath.Join(dir, "human_qtest_test.go"); res.Path != want {
+		t.Errorf("Path = %s, want %s", res.Path, want)
+	}
+	if data, _ := os.ReadFile(human); string(data) != "package math\n" {
+		t.Error("human test file was overwritten")
+	}
+
+	// Both taken by humans
+	os.WriteFile(res.Path, []byte("package math\n"), 0644)
+	if _, err := WriteGeneratedFile(human, "package math\n", testProvenance(), WriteOptions{}); !errors.Is(err, ErrNotOwned) {
+		t.Errorf("error = %v, want ErrNotOwned", err)
+	}
+}
+
+func TestWriteGeneratedFile_Edited(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "math_test.go")
+
+	base := "package math\n\nfunc TestAdd(t *testing.T) {\n\tcheck(1)\n}\n"
+	res, _ := WriteGeneratedFile(path, base, testProvenance(), WriteOptions{})
+	stamped, _ := os.ReadFile(path)
+
+	// A human adds a test at the end
+	edited := string(stamped) + "\nfunc TestMine(t *testing.T) {}\n"
+	os.WriteFile(path, []byte(edited), 0644)
+
+	// Regeneration changes TestAdd
+	regenerated := "package math\n\nfunc TestAdd(t *testing.T) {\n\tcheck(2)\n}\n"
+	res, err := WriteGeneratedFile(path, regenerated, testProvenance(), WriteOptions{Base: string(stamped)})
+	if err != nil {
+		t.Fatalf("WriteGeneratedFile() error = %v", err)
+	}
+
+	if res.Path != "" {
+		t.Errorf("Path = %s, edited file should not be written", res.Path)
+	}
+	if data, _ := os.ReadFile(path); string(data) != edited {
+		t.Error("edited test file was overwritten")
+	}
+	if res.MergePath != path+MergeSuffix {
+		t.Fatalf("MergePath = %s, want %s", res.MergePath, path+MergeSuffix)
+	}
+	if res.Conflicts != 0 {
+		t.Errorf("Conflicts = %d, want 0", res.Conflicts)
+	}
+
+	merged, _ := os.ReadFile(res.MergePath)
+	want := regenerated + "\nfunc TestMine(t *testing.T) {}\n"
+	if string(merged) != want {
+		t.Errorf("merge proposal =\n%s\nwant\n%s", merged, want)
+	}
+	if !strings.Contains(res.Diff, "-\tcheck(1)") || !strings.Contains(res.Diff, "+\tcheck(2)") {
+		t.Errorf("Diff should show the regenerated change:\n%s", res.Diff)
+	}
+}
+
+func TestWriteGeneratedFile_HeaderRemoved(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "math_test.go")
+
+	WriteGeneratedFile(path, "package math\n", testProvenance(), WriteOptions{})
+	base, _ := os.ReadFile(path)
+
+	// Header dropped, code unchanged: still QTest's to overwrite
+	os.WriteFile(path, []byte("package math\n"), 0644)
+	res, err := WriteGeneratedFile(path, "package math\n\n// v2\n", testProvenance(), WriteOptions{Base: string(base)})
+	if err != nil || res.Path != path {
+		t.Fatalf("WriteGeneratedFile() = %+v, %v, want written to %s", res, err, path)
+	}
+
+	// Header dropped and code changed: the stored hash shows it's an edit
+	os.WriteFile(path, []byte("package math\n\n// mine\n"), 0644)
+	res, err = WriteGeneratedFile(path, "package math\n\n// v3\n", testProvenance(), WriteOptions{BaseHash: ContentHash("package math\n\n// v2\n")})
+	if err != nil {
+		t.Fatalf("WriteGeneratedFile() error = %v", err)
+	}
+	if res.Path != "" || res.MergePath == "" {
+		t.Errorf("result = %+v, want a merge proposal", res)
+	}
+}
+
+func TestTargetName(t *testing.T) {
+	if got := TargetName("", "Add"); got != "Add" {
+		t.Errorf("TargetName() = %s, want Add", got)
+	}
+	if got := TargetName("Calculator", "Sub"); got != "Calculator.Sub" {
+		t.Errorf("TargetName() = %s, want Calculator.Sub", got)
+	}
+}
+
+func TestAlternateTestPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"pkg/math_test.go", "pkg/math_qtest_test.go"},
+		{"tests/test_math.py", "tests/test_math_qtest.py"},
+		{"src/math.test.ts", "src/math.qtest.test.ts"},
+		{"src/math.spec.js", "src/math.qtest.spec.js"},
+		{"MathTest.java", "MathTest_qtest.java"},
+	}
+	for _, tt := range tests {
+		if got := AlternateTestPath(tt.path); got != tt.want {
+			t.Errorf("AlternateTestPath(%s) = %s, want %s", tt.path, got, tt.want)
+		}
+	}
+}
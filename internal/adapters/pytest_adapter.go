@@ -32,6 +32,9 @@ const pytestTemplate = `{{range .Imports}}
 {{.}}
 {{end}}
 import pytest
+{{if .Patches}}
+
+{{.Patches}}{{end}}
 {{if .HasFixtures}}
 
 {{range .Fixtures}}
@@ -57,6 +60,7 @@ async {{end}}def test_{{.FunctionName}}({{.FixtureArgs}}):
 
 type pytestTemplateData struct {
 	Imports     []string
+	Patches     string
 	HasFixtures bool
 	Fixtures    []pytestFixture
 	Tests       []pytestTestData
@@ -97,6 +101,11 @@ func (a *PytestAdapter) Generate(test *dsl.TestDSL) (string, error) {
 		moduleName = strings.ReplaceAll(moduleName, "/", ".")
 		data.Imports = append(data.Imports,
 			fmt.Sprintf("from %s import %s", moduleName, test.Target.Function))
+
+		// Stub or replay the function's network and database calls
+		patches, imports := pythonTestPatches(test.Target.File, moduleName, []string{test.Target.Function})
+		data.Patches = patches
+		data.Imports = append(data.Imports, imports...)
 	}
 
 	// Process resources as fixtures
@@ -107,6 +116,10 @@ func (a *PytestAdapter) Generate(test *dsl.TestDSL) (string, error) {
 		data.HasFixtures = true
 		fixtures = append(fixtures, fixture.Name)
 	}
+	// syrupy provides the snapshot fixture
+	if hasSnapshotSteps(test) {
+		fixtures = append(fixtures, "snapshot")
+	}
 
 	// Create test
 	funcName := toPythonFunctionName(test.Name)
@@ -133,7 +146,7 @@ func (a *PytestAdapter) Generate(test *dsl.TestDSL) (string, error) {
 
 	// Process steps
 	for _, step := range test.Steps {
-		code := generatePytestStepCode(step)
+		code := generatePytestStepCode(awaitedStep(test, step))
 		testData.Steps = append(testData.Steps, pytestStep{
 			Description: step.Description,
 			Code:        code,
@@ -174,6 +187,9 @@ func toPythonFunctionName(name string) string {
 }
 
 func hasAsyncPythonSteps(test *dsl.TestDSL) bool {
+	if test.Target.Async {
+		return true
+	}
 	for _, step := range test.Steps {
 		if step.Action.Type == dsl.ActionHTTP || step.Action.Type == dsl.ActionWait {
 			return true
@@ -227,6 +243,9 @@ func generatePytestStepCode(step dsl.TestStep) string {
 	case dsl.ActionAssert:
 		// Just assertions
 
+	case dsl.ActionSnapshot:
+		code.WriteString(fmt.Sprintf("    assert %s == snapshot\n", snapshotSubject(step.Action)))
+
 	default:
 		code.WriteString(fmt.Sprintf("    # %s: %s\n", step.Action.Type, step.Action.Target))
 	}
@@ -284,6 +284,22 @@ func TestHasAsyncPythonSteps(t *testing.T) {
 			t.Error("should return true for wait action")
 		}
 	})
+
+	t.Run("async target", func(t *testing.T) {
+		testDSL := &dsl.TestDSL{
+			Target: dsl.TestTarget{Function: "fetch_user", Async: true},
+			Steps: []dsl.TestStep{
+				{Action: dsl.StepAction{Type: dsl.ActionCall, Target: "fetch_user"}},
+			},
+		}
+		if !hasAsyncPythonSteps(testDSL) {
+			t.Error("should return true for an async target")
+		}
+		code := generatePytestStepCode(awaitedStep(testDSL, testDSL.Steps[0]))
+		if !strings.Contains(code, "await fetch_user()") {
+			t.Errorf("call of an async target should be awaited: %q", code)
+		}
+	})
 }
 
 func TestResourceToFixture(t *testing.T) {
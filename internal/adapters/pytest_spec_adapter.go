@@ -3,16 +3,23 @@ package adapters
 import (
 	"bytes"
 	"fmt"
+	"os"
 	"sort"
 	"strconv"
 	"strings"
 	"text/template"
+	"unicode"
 
+	"github.com/QTest-hq/qtest/internal/emitter"
 	"github.com/QTest-hq/qtest/pkg/model"
 )
 
 // PytestSpecAdapter generates pytest code from model.TestSpec
-type PytestSpecAdapter struct{}
+type PytestSpecAdapter struct {
+	// Chaos adds tests of functions making HTTP calls whose downstream
+	// times out, returns a 503 or a malformed body
+	Chaos bool
+}
 
 func NewPytestSpecAdapter() *PytestSpecAdapter {
 	return &PytestSpecAdapter{}
@@ -34,12 +41,16 @@ const pytestSpecTemplate = `import pytest
 {{if .Imports}}{{range .Imports}}
 {{.}}{{end}}
 {{end}}
+{{if .Patches}}
+
+{{.Patches}}{{end}}
 
 {{range .Tests}}
 class Test{{.ClassName}}:
     """Tests for {{.ClassName}}"""
 {{range .Cases}}
-    def test_{{.Name}}(self):
+    {{if .Async}}@pytest.mark.asyncio
+    async {{end}}def test_{{.Name}}(self{{range .Fixtures}}, {{.}}{{end}}):
         """{{.Description}}"""
         # Arrange
 {{if .Setup}}{{.Setup}}{{end}}
@@ -55,6 +66,7 @@ class Test{{.ClassName}}:
 
 type pytestSpecTemplateData struct {
 	Imports []string
+	Patches string
 	Tests   []pytestSpecTestData
 }
 
@@ -66,9 +78,11 @@ type pytestSpecTestData struct {
 type pytestSpecCaseData struct {
 	Name        string
 	Description string
+	Async       bool // the function is a coroutine, run by pytest-asyncio
 	Setup       string
 	Action      string
 	Assertions  []string
+	Fixtures    []string // pytest fixtures the test takes, like syrupy's snapshot
 }
 
 // GenerateFromSpecs generates pytest code from TestSpec slice
@@ -92,7 +106,12 @@ func (a *PytestSpecAdapter) GenerateFromSpecs(specs []model.TestSpec, sourceFile
 		Tests:   make([]pytestSpecTestData, 0),
 	}
 
+	if usesAssertion(specs, model.AssertMatches) {
+		data.Imports = append(data.Imports, "import re")
+	}
+
 	// Add import for the module being tested
+	var callers map[string]string
 	moduleName := extractPythonModuleName(sourceFile)
 	if moduleName != "" {
 		// Collect all function names for import
@@ -101,7 +120,28 @@ func (a *PytestSpecAdapter) GenerateFromSpecs(specs []model.TestSpec, sourceFile
 			funcNames = append(funcNames, funcName)
 		}
 		sort.Strings(funcNames) // Deterministic order
-		data.Imports = append(data.Imports, fmt.Sprintf("from %s import %s", moduleName, strings.Join(funcNames, ", ")))
+		imported := append(append([]string(nil), funcNames...), pythonExceptionImports(specs)...)
+		data.Imports = append(data.Imports, fmt.Sprintf("from %s import %s", moduleName, strings.Join(imported, ", ")))
+
+		// Stub or replay the functions' network and database calls
+		patches, imports := pythonTestPatches(sourceFile, moduleName, funcNames)
+		data.Patches = patches
+		data.Imports = append(data.Imports, imports...)
+
+		// Functions whose HTTP calls chaos tests make fail
+		if source, err := os.ReadFile(sourceFile); err == nil && a.Chaos {
+			callers = pythonHTTPCallers(string(source), funcNames)
+			failing := make([]string, 0, len(callers))
+			for _, fn := range funcNames {
+				if _, ok := callers[fn]; ok {
+					failing = append(failing, fn)
+				}
+			}
+			if helper, imports := pythonFaultHelper(string(source), moduleName, failing); helper != "" {
+				data.Patches = strings.TrimPrefix(data.Patches+"\n\n"+helper, "\n\n")
+				data.Imports = uniqueImports(append(data.Imports, imports...))
+			}
+		}
 	}
 
 	// Build tests grouped by function
@@ -115,6 +155,7 @@ func (a *PytestSpecAdapter) GenerateFromSpecs(specs []model.TestSpec, sourceFile
 			caseData := pytestSpecCaseData{
 				Name:        toPythonTestName(spec.Description),
 				Description: spec.Description,
+				Async:       spec.Async,
 				Assertions:  make([]string, 0),
 			}
 
@@ -127,15 +168,20 @@ func (a *PytestSpecAdapter) GenerateFromSpecs(specs []model.TestSpec, sourceFile
 			caseData.Action = a.generateAction(spec)
 
 			// Generate assertions from spec.Assertions
-			for _, assertion := range spec.Assertions {
+			for _, assertion := range renderedAssertions(spec) {
 				assertCode := a.generateAssertion(assertion)
 				if assertCode != "" {
 					caseData.Assertions = append(caseData.Assertions, assertCode)
 				}
+				// syrupy provides the snapshot fixture
+				if isSnapshotKind(assertion.Kind) && len(caseData.Fixtures) == 0 {
+					caseData.Fixtures = append(caseData.Fixtures, "snapshot")
+				}
 			}
 
-			// If no assertions were generated, add a placeholder
-			if len(caseData.Assertions) == 0 {
+			// If no assertions were generated, add a placeholder; pytest.raises
+			// is the assertion of a call expected to fail
+			if len(caseData.Assertions) == 0 && !expectsError(spec) {
 				caseData.Assertions = append(caseData.Assertions, "# TODO: Add assertions")
 			}
 
@@ -143,6 +189,21 @@ func (a *PytestSpecAdapter) GenerateFromSpecs(specs []model.TestSpec, sourceFile
 		}
 
 		data.Tests = append(data.Tests, testData)
+
+		// Chaos tests make the function's HTTP calls fail, expecting it
+		// to raise
+		if body, ok := callers[funcName]; ok {
+			faultData := pytestSpecTestData{ClassName: testData.ClassName + "DownstreamFaults"}
+			for _, fault := range httpFaultsFor(body, pyParsesJSON) {
+				spec, _ := faultSpec(funcSpecs, fault)
+				var setup string
+				if len(spec.Inputs) > 0 {
+					setup = a.generateSetup(spec)
+				}
+				faultData.Cases = append(faultData.Cases, pytestFaultCase(fault, body, setup, a.generateAction(spec), spec.Async))
+			}
+			data.Tests = append(data.Tests, faultData)
+		}
 	}
 
 	// Execute template
@@ -245,7 +306,38 @@ func (a *PytestSpecAdapter) generateAction(spec model.TestSpec) string {
 		}
 	}
 
-	return fmt.Sprintf("result = %s(%s)", funcName, strings.Join(args, ", "))
+	call := fmt.Sprintf("%s(%s)", funcName, strings.Join(args, ", "))
+	if spec.Async {
+		call = "await " + call
+	}
+	if expectsError(spec) {
+		return pytestRaises(spec, call)
+	}
+	return "result = " + call
+}
+
+// pytestRaises wraps a call expected to fail in pytest.raises, capturing
+// the exception when its message is asserted on
+func pytestRaises(spec model.TestSpec, call string) string {
+	exception := "Exception"
+	withMessage := false
+	for _, a := range spec.Assertions {
+		if !a.ExpectsError() {
+			continue
+		}
+		if a.ErrorType != "" && exception == "Exception" {
+			exception = a.ErrorType
+		}
+		if a.ErrorMessage != "" {
+			withMessage = true
+		}
+	}
+
+	capture := ""
+	if withMessage {
+		capture = " as excinfo"
+	}
+	return fmt.Sprintf("with pytest.raises(%s)%s:\n            %s", exception, capture, call)
 }
 
 // formatPythonValueWithType formats a value for Python code using type hints
@@ -349,8 +441,15 @@ func (a *PytestSpecAdapter) generateAssertion(assertion model.Assertion) string
 	case "falsy":
 		return fmt.Sprintf("assert not %s", actual)
 
-	case "throws", "error":
-		return "# Exception is expected - wrap call in pytest.raises()"
+	case "throws", "raises", "error":
+		// The call is wrapped in pytest.raises, which checks the type
+		if assertion.ErrorMessage != "" {
+			return fmt.Sprintf("assert %s in str(excinfo.value)", strconv.Quote(assertion.ErrorMessage))
+		}
+		return ""
+
+	case "snapshot", "matches_snapshot", "golden":
+		return fmt.Sprintf("assert %s == snapshot", actual)
 
 	case "type", "type_is":
 		expected := assertion.Expected
@@ -360,6 +459,23 @@ func (a *PytestSpecAdapter) generateAssertion(assertion model.Assertion) string
 		expected := formatPythonValue(assertion.Expected)
 		return fmt.Sprintf("assert len(%s) == %s", actual, expected)
 
+	case model.AssertContainsAll:
+		items := strings.Join(formatItems(assertion, formatPythonValue), ", ")
+		return fmt.Sprintf("assert all(item in %s for item in [%s])", actual, items)
+
+	case model.AssertSubset:
+		items := strings.Join(formatItems(assertion, formatPythonValue), ", ")
+		return fmt.Sprintf("assert all(item in [%s] for item in %s)", items, actual)
+
+	case model.AssertSorted:
+		return fmt.Sprintf("assert %s == sorted(%s%s)", actual, actual, emitter.PythonSortArgs(assertion))
+
+	case model.AssertApprox:
+		return fmt.Sprintf("assert %s == pytest.approx(%s, abs=%s)", actual, approxExpected(assertion), approxEpsilon(assertion))
+
+	case model.AssertMatches:
+		return fmt.Sprintf("assert re.search(%s, str(%s))", strconv.Quote(matchesPattern(assertion)), actual)
+
 	default:
 		if assertion.Expected != nil {
 			expected := formatPythonValue(assertion.Expected)
@@ -455,6 +571,14 @@ func toPythonTestName(name string) string {
 	name = strings.ReplaceAll(name, "(", "")
 	name = strings.ReplaceAll(name, ")", "")
 	name = strings.ReplaceAll(name, ",", "_")
+	// Drop anything else that can't appear in an identifier, e.g. the colon
+	// in "reproduces KeyError: email"
+	name = strings.Map(func(r rune) rune {
+		if r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, name)
 	return name
 }
 
@@ -482,3 +606,34 @@ func extractPythonModuleName(filePath string) string {
 
 	return name
 }
+
+// pythonBuiltinExceptions are the common exceptions Python defines as
+// builtins
+var pythonBuiltinExceptions = map[string]bool{
+	"Exception": true, "BaseException": true, "ArithmeticError": true, "AssertionError": true,
+	"AttributeError": true, "ConnectionError": true, "FileExistsError": true, "FileNotFoundError": true,
+	"ImportError": true, "IndexError": true, "IOError": true, "KeyError": true, "LookupError": true,
+	"ModuleNotFoundError": true, "NameError": true, "NotImplementedError": true, "OSError": true,
+	"OverflowError": true, "PermissionError": true, "RecursionError": true, "RuntimeError": true,
+	"StopIteration": true, "TimeoutError": true, "TypeError": true, "UnicodeDecodeError": true,
+	"UnicodeEncodeError": true, "UnicodeError": true, "ValueError": true, "ZeroDivisionError": true,
+}
+
+// pythonExceptionImports lists the exceptions specs expect that aren't
+// builtins, which are imported from the module under test
+func pythonExceptionImports(specs []model.TestSpec) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, spec := range specs {
+		for _, a := range spec.Assertions {
+			name := a.ErrorType
+			if !a.ExpectsError() || name == "" || pythonBuiltinExceptions[name] || strings.Contains(name, ".") || seen[name] {
+				continue
+			}
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
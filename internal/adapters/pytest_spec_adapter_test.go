@@ -55,6 +55,36 @@ func TestPytestSpecAdapter_GenerateFromSpecs(t *testing.T) {
 	}
 }
 
+func TestPytestSpecAdapter_Async(t *testing.T) {
+	adapter := NewPytestSpecAdapter()
+
+	specs := []model.TestSpec{
+		{
+			FunctionName: "fetch_user",
+			Description:  "Finds an existing user",
+			Async:        true,
+			Inputs:       map[string]interface{}{"user_id": float64(1)},
+			ArgOrder:     []string{"user_id"},
+			Assertions: []model.Assertion{
+				{Kind: "not_null", Actual: "result"},
+			},
+		},
+	}
+
+	code, err := adapter.GenerateFromSpecs(specs, "users.py")
+	if err != nil {
+		t.Fatalf("GenerateFromSpecs failed: %v", err)
+	}
+
+	want := "    @pytest.mark.asyncio\n    async def test_finds_an_existing_user(self):"
+	if !strings.Contains(code, want) {
+		t.Errorf("expected %q in output:\n%s", want, code)
+	}
+	if !strings.Contains(code, "result = await fetch_user(user_id)") {
+		t.Errorf("expected awaited call in output:\n%s", code)
+	}
+}
+
 func TestPytestSpecAdapter_GenerateAssertions(t *testing.T) {
 	adapter := NewPytestSpecAdapter()
 
@@ -139,6 +169,7 @@ func TestToPythonTestName(t *testing.T) {
 	}{
 		{"Adding two numbers", "adding_two_numbers"},
 		{"Test case with (parentheses)", "test_case_with_parentheses"},
+		{"reproduces KeyError: email [3]", "reproduces_keyerror_email_3"},
 		{"", "test_case"},
 	}
 
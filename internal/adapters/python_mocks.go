@@ -0,0 +1,224 @@
+package adapters
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// pythonExternalModules are libraries whose calls leave the process, by
+// the kind of stub they need
+var pythonExternalModules = map[string]string{
+	"requests":        "http",
+	"httpx":           "http",
+	"boto3":           "client",
+	"botocore":        "client",
+	"psycopg2":        "db",
+	"pymysql":         "db",
+	"sqlite3":         "db",
+	"mysql.connector": "db",
+	"redis":           "client",
+	"smtplib":         "client",
+	"urllib.request":  "http",
+}
+
+// pythonPatch is one name the generated tests replace with a MagicMock
+type pythonPatch struct {
+	Target string // dotted name the code under test looks up, e.g. requests.get
+	Kind   string // http, db, session or client
+}
+
+var (
+	pythonImportPattern     = regexp.MustCompile(`(?m)^[ \t]*import\s+([\w.]+)(?:\s+as\s+(\w+))?\s*$`)
+	pythonFromImportPattern = regexp.MustCompile(`(?m)^[ \t]*from\s+([\w.]+)\s+import\s+(?:\(([^)]*)\)|([\w \t,]+))`)
+	pythonAttrCallPattern   = regexp.MustCompile(`(?:^|[^\w.])([A-Za-z_][\w.]*)\.(\w+)\s*\(`)
+	pythonCallPattern       = regexp.MustCompile(`(?:^|[^\w.])([A-Za-z_]\w*)\s*\(`)
+	pythonDBSessionPattern  = regexp.MustCompile(`(?:^|[^\w.])(db\.session)\.\w+\s*\(`)
+	pythonSessionFactory    = regexp.MustCompile(`(?:^|[^\w.])(\w*Session(?:Local)?|get_db)\s*\(`)
+)
+
+// pythonFunctionBody returns the source of the function named name, or ""
+// when it isn't defined in source
+func pythonFunctionBody(source, name string) string {
+	def := regexp.MustCompile(`(?m)^([ \t]*)(?:async\s+)?def\s+` + regexp.QuoteMeta(name) + `\s*\(`)
+	loc := def.FindStringSubmatchIndex(source)
+	if loc == nil {
+		return ""
+	}
+	indent := loc[3] - loc[2]
+
+	lines := strings.Split(source[loc[0]:], "\n")
+	end := 1
+	for end < len(lines) {
+		line := lines[end]
+		if trimmed := strings.TrimSpace(line); trimmed != "" && len(line)-len(strings.TrimLeft(line, " \t")) <= indent && !strings.HasPrefix(trimmed, ")") {
+			break
+		}
+		end++
+	}
+	return strings.Join(lines[:end], "\n")
+}
+
+// detectPythonExternalCalls finds the calls in the bodies of funcs that
+// reach the network or a database: methods of known client libraries,
+// functions imported from them, and database sessions
+func detectPythonExternalCalls(source string, funcs []string) []pythonPatch {
+	// Local names bound to external modules and their functions
+	modules := make(map[string]string)
+	functions := make(map[string]string)
+	for _, m := range pythonImportPattern.FindAllStringSubmatch(source, -1) {
+		if kind, ok := pythonExternalModules[m[1]]; ok {
+			local := m[1]
+			if m[2] != "" {
+				local = m[2]
+			}
+			modules[local] = kind
+		}
+	}
+	for _, m := range pythonFromImportPattern.FindAllStringSubmatch(source, -1) {
+		kind, ok := pythonExternalModules[m[1]]
+		if !ok {
+			continue
+		}
+		for _, name := range strings.Split(m[2]+m[3], ",") {
+			fields := strings.Fields(name)
+			if len(fields) == 0 {
+				continue
+			}
+			// from x import y as z binds z
+			functions[fields[len(fields)-1]] = kind
+		}
+	}
+
+	var patches []pythonPatch
+	seen := make(map[string]bool)
+	add := func(target, kind string) {
+		if !seen[target] {
+			seen[target] = true
+			patches = append(patches, pythonPatch{Target: target, Kind: kind})
+		}
+	}
+
+	for _, fn := range funcs {
+		body := pythonFunctionBody(source, fn)
+		if body == "" {
+			continue
+		}
+		// Skip the def line so the function's own name isn't a call
+		if i := strings.Index(body, "\n"); i >= 0 {
+			body = body[i:]
+		} else {
+			continue
+		}
+
+		for _, m := range pythonAttrCallPattern.FindAllStringSubmatch(body, -1) {
+			if kind, ok := modules[m[1]]; ok {
+				if kind == "client" {
+					add(m[1], kind) // clients are built then used, so stub the module
+				} else {
+					add(m[1]+"."+m[2], kind)
+				}
+			}
+		}
+		for _, m := range pythonCallPattern.FindAllStringSubmatch(body, -1) {
+			if kind, ok := functions[m[1]]; ok {
+				add(m[1], kind)
+			}
+		}
+		for _, m := range pythonDBSessionPattern.FindAllStringSubmatch(body, -1) {
+			add(m[1], "session")
+		}
+		for _, m := range pythonSessionFactory.FindAllStringSubmatch(body, -1) {
+			if _, ok := functions[m[1]]; !ok {
+				add(m[1], "session")
+			}
+		}
+	}
+	return patches
+}
+
+// pythonPatchDefaults sets return values a stub's callers can read, such
+// as a 200 response with an empty JSON body
+func pythonPatchDefaults(target, kind string) []string {
+	ref := fmt.Sprintf("mocks[%q]", target)
+	switch kind {
+	case "http":
+		return []string{
+			ref + ".return_value.status_code = 200",
+			ref + ".return_value.ok = True",
+			ref + ".return_value.json.return_value = {}",
+			ref + `.return_value.text = ""`,
+		}
+	case "db":
+		return []string{
+			ref + ".return_value.cursor.return_value.fetchall.return_value = []",
+			ref + ".return_value.cursor.return_value.fetchone.return_value = None",
+		}
+	case "session":
+		return []string{
+			ref + ".query.return_value.all.return_value = []",
+			ref + ".return_value.query.return_value.all.return_value = []",
+		}
+	}
+	return nil
+}
+
+// pythonPatchFixture returns an autouse fixture replacing every external
+// call the functions make with a MagicMock, so tests stay off the network
+// and database. HTTP calls to fixed URLs are replayed by
+// pythonHTTPCassette instead. Tests can take the fixture to reach the
+// mocks by name. It returns "" when there's nothing to patch.
+func pythonPatchFixture(source, module string, funcs []string) string {
+	// Calls replayed from a cassette are left real
+	replayed := recordedClients(detectPythonHTTPCalls(source, funcs))
+	var patches []pythonPatch
+	for _, p := range detectPythonExternalCalls(source, funcs) {
+		if !replayed[p.Target] {
+			patches = append(patches, p)
+		}
+	}
+	if len(patches) == 0 || module == "" {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("@pytest.fixture(autouse=True)\n")
+	sb.WriteString("def external_calls(monkeypatch):\n")
+	sb.WriteString(`    """Stub network and database calls made by the code under test"""` + "\n")
+	sb.WriteString("    mocks = {}\n")
+	for _, p := range patches {
+		fmt.Fprintf(&sb, "    mocks[%q] = mock.MagicMock(name=%q)\n", p.Target, p.Target)
+		for _, line := range pythonPatchDefaults(p.Target, p.Kind) {
+			sb.WriteString("    " + line + "\n")
+		}
+		fmt.Fprintf(&sb, "    monkeypatch.setattr(%q, mocks[%q])\n", module+"."+p.Target, p.Target)
+	}
+	sb.WriteString("    return mocks\n")
+	return sb.String()
+}
+
+// pythonTestPatches reads sourceFile and returns the fixtures keeping tests
+// of funcs off the network and database, with the imports they need. It
+// returns "" if the file can't be read or the functions need none.
+func pythonTestPatches(sourceFile, module string, funcs []string) (string, []string) {
+	if sourceFile == "" {
+		return "", nil
+	}
+	data, err := os.ReadFile(sourceFile)
+	if err != nil {
+		return "", nil
+	}
+	source := string(data)
+
+	var fixtures, imports []string
+	if cassette := pythonHTTPCassette(source, funcs); cassette != "" {
+		fixtures = append(fixtures, cassette)
+		imports = append(imports, "import vcr")
+	}
+	if patches := pythonPatchFixture(source, module, funcs); patches != "" {
+		fixtures = append(fixtures, patches)
+		imports = append(imports, "from unittest import mock")
+	}
+	return strings.Join(fixtures, "\n\n"), imports
+}
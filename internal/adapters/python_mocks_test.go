@@ -0,0 +1,123 @@
+package adapters
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/QTest-hq/qtest/pkg/dsl"
+	"github.com/QTest-hq/qtest/pkg/model"
+)
+
+const pythonServiceSource = `import requests
+import boto3 as aws
+from psycopg2 import connect
+from app.db import SessionLocal
+
+
+def fetch_user(user_id):
+    resp = requests.get(
+        f"{API_URL}/users/{user_id}",
+    )
+    aws.client("s3").put_object(Bucket="b", Key=str(user_id))
+    return resp.json()
+
+
+def count_rows():
+    conn = connect("dbname=app")
+    with SessionLocal() as session:
+        session.query(User).all()
+    return conn.cursor().fetchall()
+
+
+def slugify(name):
+    return name.lower().replace(" ", "-")
+`
+
+func TestPythonFunctionBody(t *testing.T) {
+	body := pythonFunctionBody(pythonServiceSource, "fetch_user")
+	if !strings.HasPrefix(body, "def fetch_user(") || !strings.Contains(body, "return resp.json()") {
+		t.Errorf("body = %q", body)
+	}
+	if strings.Contains(body, "count_rows") {
+		t.Error("body runs into the next function")
+	}
+	if pythonFunctionBody(pythonServiceSource, "missing") != "" {
+		t.Error("body of an undefined function should be empty")
+	}
+}
+
+func TestDetectPythonExternalCalls(t *testing.T) {
+	targets := func(funcs ...string) string {
+		var names []string
+		for _, p := range detectPythonExternalCalls(pythonServiceSource, funcs) {
+			names = append(names, p.Target+":"+p.Kind)
+		}
+		return strings.Join(names, ",")
+	}
+
+	if got := targets("fetch_user"); got != "requests.get:http,aws:client" {
+		t.Errorf("fetch_user calls = %s", got)
+	}
+	if got := targets("count_rows"); got != "connect:db,SessionLocal:session" {
+		t.Errorf("count_rows calls = %s", got)
+	}
+	if got := targets("slugify"); got != "" {
+		t.Errorf("slugify calls = %s, want none", got)
+	}
+}
+
+func TestPythonPatchFixture(t *testing.T) {
+	fixture := pythonPatchFixture(pythonServiceSource, "service", []string{"fetch_user"})
+
+	for _, want := range []string{
+		"@pytest.fixture(autouse=True)\ndef external_calls(monkeypatch):",
+		`mocks["requests.get"] = mock.MagicMock(name="requests.get")`,
+		`mocks["requests.get"].return_value.status_code = 200`,
+		`mocks["requests.get"].return_value.json.return_value = {}`,
+		`monkeypatch.setattr("service.requests.get", mocks["requests.get"])`,
+		`monkeypatch.setattr("service.aws", mocks["aws"])`,
+		"    return mocks",
+	} {
+		if !strings.Contains(fixture, want) {
+			t.Errorf("fixture missing %q:\n%s", want, fixture)
+		}
+	}
+
+	if got := pythonPatchFixture(pythonServiceSource, "service", []string{"slugify"}); got != "" {
+		t.Errorf("fixture for a pure function = %q, want none", got)
+	}
+}
+
+func TestPytestAdapters_PatchExternalCalls(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "service.py")
+	if err := os.WriteFile(src, []byte(pythonServiceSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	spec := model.TestSpec{FunctionName: "fetch_user", Description: "fetches a user"}
+	code, err := NewPytestSpecAdapter().GenerateFromSpecs([]model.TestSpec{spec}, src)
+	if err != nil {
+		t.Fatalf("GenerateFromSpecs() error: %v", err)
+	}
+	if !strings.Contains(code, "from unittest import mock") || !strings.Contains(code, `monkeypatch.setattr("service.requests.get"`) {
+		t.Errorf("spec adapter output missing patches:\n%s", code)
+	}
+	if strings.Index(code, "def external_calls") > strings.Index(code, "class TestFetchUser") {
+		t.Error("patch fixture should come before the tests")
+	}
+
+	code, err = NewPytestAdapter().Generate(&dsl.TestDSL{Name: "count", Target: dsl.TestTarget{File: src, Function: "count_rows"}})
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+	if !strings.Contains(code, `mocks["SessionLocal"]`) {
+		t.Errorf("DSL adapter output missing patches:\n%s", code)
+	}
+
+	code, _ = NewPytestSpecAdapter().GenerateFromSpecs([]model.TestSpec{{FunctionName: "slugify", Description: "slugs"}}, src)
+	if strings.Contains(code, "unittest") {
+		t.Errorf("pure functions shouldn't get patches:\n%s", code)
+	}
+}
@@ -23,11 +23,13 @@ func NewRegistry() *Registry {
 	r.Register(NewGoAdapter())
 	r.Register(NewJestAdapter())
 	r.Register(NewPytestAdapter())
+	r.Register(NewRustAdapter())
 
 	// Register spec-based adapters (IRSpec)
 	r.RegisterSpec(NewGoSpecAdapter())
 	r.RegisterSpec(NewJestSpecAdapter())
 	r.RegisterSpec(NewPytestSpecAdapter())
+	r.RegisterSpec(NewRustSpecAdapter())
 
 	return r
 }
@@ -69,6 +71,8 @@ func (r *Registry) GetForLanguage(lang parser.Language) (Adapter, error) {
 		return r.Get(FrameworkJest)
 	case parser.LanguagePython:
 		return r.Get(FrameworkPytest)
+	case parser.LanguageRust:
+		return r.Get(FrameworkCargo)
 	case parser.LanguageJava:
 		return r.Get(FrameworkJUnit) // Not implemented yet
 	default:
@@ -85,6 +89,8 @@ func (r *Registry) GetSpecForLanguage(lang parser.Language) (SpecAdapter, error)
 		return r.GetSpec(FrameworkJest)
 	case parser.LanguagePython:
 		return r.GetSpec(FrameworkPytest)
+	case parser.LanguageRust:
+		return r.GetSpec(FrameworkCargo)
 	default:
 		return nil, fmt.Errorf("no spec adapter for language: %s", lang)
 	}
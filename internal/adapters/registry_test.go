@@ -70,6 +70,7 @@ func TestRegistry_GetForLanguage(t *testing.T) {
 		{"javascript", parser.LanguageJavaScript, FrameworkJest, false},
 		{"typescript", parser.LanguageTypeScript, FrameworkJest, false},
 		{"python", parser.LanguagePython, FrameworkPytest, false},
+		{"rust", parser.LanguageRust, FrameworkCargo, false},
 		{"java", parser.LanguageJava, FrameworkJUnit, true}, // Not implemented
 		{"unknown", parser.LanguageUnknown, "", true},
 	}
@@ -148,7 +149,7 @@ func TestNewRegistry_SpecAdapters(t *testing.T) {
 	}
 
 	// Should have all spec adapters registered
-	specAdapters := []Framework{FrameworkGoTest, FrameworkJest, FrameworkPytest}
+	specAdapters := []Framework{FrameworkGoTest, FrameworkJest, FrameworkPytest, FrameworkCargo}
 	for _, fw := range specAdapters {
 		adapter, err := r.GetSpec(fw)
 		if err != nil {
@@ -237,6 +238,7 @@ func TestRegistry_GetSpecForLanguage(t *testing.T) {
 		{"javascript", parser.LanguageJavaScript, FrameworkJest, false},
 		{"typescript", parser.LanguageTypeScript, FrameworkJest, false},
 		{"python", parser.LanguagePython, FrameworkPytest, false},
+		{"rust", parser.LanguageRust, FrameworkCargo, false},
 		{"java", parser.LanguageJava, "", true},    // Not implemented for spec
 		{"unknown", parser.LanguageUnknown, "", true},
 	}
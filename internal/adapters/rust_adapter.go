@@ -0,0 +1,294 @@
+package adapters
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/QTest-hq/qtest/pkg/dsl"
+)
+
+// RustAdapter generates Rust integration tests, which cargo runs from the
+// crate's tests/ directory and which see only its pub items
+type RustAdapter struct{}
+
+func NewRustAdapter() *RustAdapter {
+	return &RustAdapter{}
+}
+
+func (a *RustAdapter) Framework() Framework {
+	return FrameworkCargo
+}
+
+func (a *RustAdapter) FileExtension() string {
+	return ".rs"
+}
+
+func (a *RustAdapter) TestFileSuffix() string {
+	return "_test"
+}
+
+const rustTestTemplate = `{{range .Uses}}use {{.}};
+{{end}}
+{{range .Tests}}
+#[test]
+fn {{.FunctionName}}() {
+{{range .Steps}}    // {{.Description}}
+{{.Code}}{{end}}}
+{{end}}`
+
+type rustTemplateData struct {
+	Uses  []string
+	Tests []rustTestData
+}
+
+type rustTestData struct {
+	FunctionName string
+	Steps        []rustStep
+}
+
+type rustStep struct {
+	Description string
+	Code        string
+}
+
+func (a *RustAdapter) Generate(test *dsl.TestDSL) (string, error) {
+	data := rustTemplateData{
+		Uses:  []string{RustUsePath(test.Target.File)},
+		Tests: make([]rustTestData, 0),
+	}
+
+	funcName := toRustTestName(test.Name)
+	if funcName == "" {
+		funcName = toRustTestName(test.Target.Function)
+	}
+
+	testData := rustTestData{
+		FunctionName: funcName,
+		Steps:        make([]rustStep, 0),
+	}
+	for _, step := range test.Steps {
+		testData.Steps = append(testData.Steps, rustStep{
+			Description: step.Description,
+			Code:        generateRustStepCode(step),
+		})
+	}
+	data.Tests = append(data.Tests, testData)
+
+	tmpl, err := template.New("rust").Parse(rustTestTemplate)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to execute template: %w", err)
+	}
+
+	return buf.String(), nil
+}
+
+func generateRustStepCode(step dsl.TestStep) string {
+	var code strings.Builder
+
+	switch step.Action.Type {
+	case dsl.ActionCall:
+		args := make([]string, len(step.Action.Args))
+		for i, arg := range step.Action.Args {
+			args[i] = formatRustValue(arg)
+		}
+		call := fmt.Sprintf("%s(%s)", rustPath(step.Action.Target), strings.Join(args, ", "))
+		if step.Expected != nil {
+			code.WriteString(fmt.Sprintf("    let result = %s;\n", call))
+		} else {
+			code.WriteString(fmt.Sprintf("    %s;\n", call))
+		}
+
+	case dsl.ActionAssert:
+		// Just assertions
+
+	default:
+		code.WriteString(fmt.Sprintf("    // TODO: %s: %s\n", step.Action.Type, step.Action.Target))
+	}
+
+	if step.Expected != nil {
+		code.WriteString(generateRustAssertions(step.Expected))
+	}
+
+	return code.String()
+}
+
+func generateRustAssertions(expected *dsl.Expected) string {
+	var assertions strings.Builder
+
+	if expected.Error != nil {
+		assertions.WriteString("    assert!(result.is_err());\n")
+		return assertions.String()
+	}
+	if expected.Value != nil {
+		assertions.WriteString(fmt.Sprintf("    assert_eq!(result, %s);\n", formatRustValue(expected.Value)))
+	}
+	if expected.Contains != nil {
+		assertions.WriteString(fmt.Sprintf("    assert!(result.contains(%s));\n", rustContainsArg(expected.Contains)))
+	}
+
+	return assertions.String()
+}
+
+// RustTestPath returns where the integration test for a Rust source file
+// goes: <crate>/tests/<module path>_test.rs, so src/net/http.rs is tested
+// by tests/net_http_test.rs
+func RustTestPath(sourceFile string) string {
+	root := rustCrateRoot(sourceFile)
+	name := strings.Join(rustModulePath(sourceFile, root), "_")
+	if name == "" {
+		name = strings.TrimSuffix(filepath.Base(sourceFile), ".rs")
+	}
+	return filepath.Join(root, "tests", name+"_test.rs")
+}
+
+// RustUsePath returns the use path that brings a source file's pub items
+// into an integration test, e.g. my_crate::net::http::*
+func RustUsePath(sourceFile string) string {
+	root := rustCrateRoot(sourceFile)
+	parts := append([]string{rustCrateName(root)}, rustModulePath(sourceFile, root)...)
+	return strings.Join(parts, "::") + "::*"
+}
+
+// rustCrateRoot returns the directory holding the source file's Cargo.toml,
+// or the parent of its src/ directory when there isn't one on disk
+func rustCrateRoot(sourceFile string) string {
+	for dir := filepath.Dir(sourceFile); ; dir = filepath.Dir(dir) {
+		if _, err := os.Stat(filepath.Join(dir, "Cargo.toml")); err == nil {
+			return dir
+		}
+		if filepath.Dir(dir) == dir {
+			break
+		}
+	}
+
+	slashed := filepath.ToSlash(sourceFile)
+	if i := strings.LastIndex(slashed, "/src/"); i >= 0 {
+		return filepath.FromSlash(slashed[:i])
+	}
+	if strings.HasPrefix(slashed, "src/") {
+		return "."
+	}
+	return filepath.Dir(sourceFile)
+}
+
+// rustCrateName reads the package name from a crate's Cargo.toml, with
+// dashes replaced as rustc does
+func rustCrateName(root string) string {
+	name := ""
+	if f, err := os.Open(filepath.Join(root, "Cargo.toml")); err == nil {
+		defer f.Close()
+		section := ""
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if strings.HasPrefix(line, "[") {
+				section = line
+				continue
+			}
+			if section != "[package]" && section != "[lib]" {
+				continue
+			}
+			key, value, ok := strings.Cut(line, "=")
+			if ok && strings.TrimSpace(key) == "name" {
+				name = strings.Trim(strings.TrimSpace(value), `"'`)
+				if section == "[lib]" {
+					break // a [lib] name overrides the package's
+				}
+			}
+		}
+	}
+	if name == "" {
+		name = filepath.Base(root)
+	}
+	if name == "" || name == "." || name == string(filepath.Separator) {
+		return "crate"
+	}
+	return strings.ReplaceAll(name, "-", "_")
+}
+
+// rustModulePath returns a source file's module path within its crate:
+// src/lib.rs is the root, src/net/mod.rs is net
+func rustModulePath(sourceFile, root string) []string {
+	rel, err := filepath.Rel(filepath.Join(root, "src"), sourceFile)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		rel = filepath.Base(sourceFile)
+	}
+	parts := strings.Split(filepath.ToSlash(strings.TrimSuffix(rel, ".rs")), "/")
+
+	switch last := parts[len(parts)-1]; {
+	case len(parts) == 1 && (last == "lib" || last == "main"):
+		return nil
+	case last == "mod":
+		parts = parts[:len(parts)-1]
+	}
+	return parts
+}
+
+// rustPath turns a method target like Stack.push into Stack::push
+func rustPath(target string) string {
+	return strings.ReplaceAll(target, ".", "::")
+}
+
+func toRustTestName(name string) string {
+	if name == "" {
+		return ""
+	}
+
+	// snake_case, as rustc warns about anything else
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return !(r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9'))
+	})
+	for i := range words {
+		words[i] = strings.ToLower(words[i])
+	}
+	result := strings.Trim(strings.Join(words, "_"), "_")
+	if result != "" && result[0] >= '0' && result[0] <= '9' {
+		result = "test_" + result
+	}
+	return result
+}
+
+// formatRustValue formats a value for Rust code
+func formatRustValue(val interface{}) string {
+	switch v := val.(type) {
+	case nil:
+		return "None"
+	case string:
+		return fmt.Sprintf("%q", v)
+	case bool:
+		return fmt.Sprintf("%t", v)
+	case float32, float64:
+		// Whole numbers from JSON are most likely integers, so they stay
+		// without a decimal point
+		return fmt.Sprintf("%v", v)
+	case []interface{}:
+		elements := make([]string, len(v))
+		for i, elem := range v {
+			elements[i] = formatRustValue(elem)
+		}
+		return fmt.Sprintf("vec![%s]", strings.Join(elements, ", "))
+	case map[string]interface{}:
+		return "Default::default() /* TODO: build struct */"
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// rustContainsArg formats the argument to contains(): a pattern for
+// strings, a reference for slices
+func rustContainsArg(val interface{}) string {
+	if s, ok := val.(string); ok {
+		return fmt.Sprintf("%q", s)
+	}
+	return "&" + formatRustValue(val)
+}
@@ -0,0 +1,131 @@
+package adapters
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/QTest-hq/qtest/pkg/dsl"
+	"github.com/QTest-hq/qtest/pkg/model"
+)
+
+func TestRustAdapter_Generate(t *testing.T) {
+	adapter := NewRustAdapter()
+	if adapter.Framework() != FrameworkCargo || adapter.FileExtension() != ".rs" {
+		t.Fatalf("Framework() = %s, FileExtension() = %s", adapter.Framework(), adapter.FileExtension())
+	}
+
+	code, err := adapter.Generate(&dsl.TestDSL{
+		Name:   "Test Add",
+		Target: dsl.TestTarget{File: "calc/src/math.rs", Function: "add"},
+		Steps: []dsl.TestStep{{
+			Description: "Add two numbers",
+			Action:      dsl.StepAction{Type: dsl.ActionCall, Target: "add", Args: []interface{}{2, 3}},
+			Expected:    &dsl.Expected{Value: 5},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("Generate() error: %v", err)
+	}
+
+	for _, want := range []string{
+		"use calc::math::*;",
+		"#[test]\nfn test_add() {",
+		"let result = add(2, 3);",
+		"assert_eq!(result, 5);",
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("code missing %q:\n%s", want, code)
+		}
+	}
+}
+
+func TestRustSpecAdapter_GenerateFromSpecs(t *testing.T) {
+	adapter := NewRustSpecAdapter()
+
+	specs := []model.TestSpec{
+		{
+			FunctionName: "Stack.push",
+			Description:  "Pushes an item",
+			Inputs:       map[string]interface{}{"item": float64(3)},
+			InputTypes:   map[string]string{"item": "int"},
+			Assertions:   []model.Assertion{{Kind: "length", Actual: "result", Expected: float64(1)}},
+		},
+		{
+			FunctionName: "scale",
+			Description:  "Scales by a factor",
+			Inputs:       map[string]interface{}{"name": "x", "factor": float64(2)},
+			InputTypes:   map[string]string{"name": "string", "factor": "float"},
+			ArgOrder:     []string{"name", "factor"},
+			Assertions: []model.Assertion{
+				{Kind: "equals", Actual: "$result", Expected: float64(4)},
+				{Kind: "error"},
+			},
+		},
+		{
+			FunctionName: "scale",
+			Description:  "Scales by a factor",
+		},
+	}
+
+	code, err := adapter.GenerateFromSpecs(specs, "src/geometry/mod.rs")
+	if err != nil {
+		t.Fatalf("GenerateFromSpecs() error: %v", err)
+	}
+
+	for _, want := range []string{
+		"use crate::geometry::*;",
+		"fn stack_push_pushes_an_item() {",
+		"let item = 3;",
+		"let result = Stack::push(item);",
+		"assert_eq!(result.len(), 1);",
+		`let name = "x";`,
+		"let factor = 2.0;",
+		"let result = scale(name, factor);",
+		"assert_eq!(result, 4);",
+		"assert!(result.is_err());",
+		"fn scale_scales_by_a_factor_2() {",
+		"// TODO: Add assertions",
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("code missing %q:\n%s", want, code)
+		}
+	}
+	if strings.Index(code, "stack_push") > strings.Index(code, "fn scale") {
+		t.Error("tests should keep the specs' order")
+	}
+
+	if _, err := adapter.GenerateFromSpecs(nil, "src/lib.rs"); err == nil {
+		t.Error("GenerateFromSpecs(nil) should return error")
+	}
+}
+
+func TestRustTestPath(t *testing.T) {
+	root := t.TempDir()
+	cargo := "[package]\nname = \"my-crate\"\nversion = \"0.1.0\"\n\n[dependencies]\nname = \"ignored\"\n"
+	if err := os.WriteFile(filepath.Join(root, "Cargo.toml"), []byte(cargo), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		source   string
+		wantPath string
+		wantUse  string
+	}{
+		{"src/lib.rs", "tests/lib_test.rs", "my_crate::*"},
+		{"src/net/http.rs", "tests/net_http_test.rs", "my_crate::net::http::*"},
+		{"src/net/mod.rs", "tests/net_test.rs", "my_crate::net::*"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			source := filepath.Join(root, tt.source)
+			if got, want := RustTestPath(source), filepath.Join(root, tt.wantPath); got != want {
+				t.Errorf("RustTestPath() = %s, want %s", got, want)
+			}
+			if got := RustUsePath(source); got != tt.wantUse {
+				t.Errorf("RustUsePath() = %s, want %s", got, tt.wantUse)
+			}
+		})
+	}
+}
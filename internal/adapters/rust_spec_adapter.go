@@ -0,0 +1,290 @@
+package adapters
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/QTest-hq/qtest/pkg/model"
+)
+
+// RustSpecAdapter generates Rust integration tests from model.TestSpec
+type RustSpecAdapter struct{}
+
+func NewRustSpecAdapter() *RustSpecAdapter {
+	return &RustSpecAdapter{}
+}
+
+func (a *RustSpecAdapter) Framework() Framework {
+	return FrameworkCargo
+}
+
+func (a *RustSpecAdapter) FileExtension() string {
+	return ".rs"
+}
+
+func (a *RustSpecAdapter) TestFileSuffix() string {
+	return "_test"
+}
+
+const rustSpecTemplate = `use {{.Use}};
+{{range .Tests}}
+// Tests for {{.FuncName}}{{range .Cases}}
+#[test]
+fn {{.Name}}() {
+    // {{.Description}}
+{{range .Setup}}    {{.}}
+{{end}}    {{.Action}}
+{{range .Assertions}}    {{.}}
+{{end}}}
+{{end}}{{end}}`
+
+type rustSpecTemplateData struct {
+	Use   string
+	Tests []rustSpecTestData
+}
+
+type rustSpecTestData struct {
+	FuncName string
+	Cases    []rustSpecCaseData
+}
+
+type rustSpecCaseData struct {
+	Name        string
+	Description string
+	Setup       []string
+	Action      string
+	Assertions  []string
+}
+
+// GenerateFromSpecs generates a Rust integration test file from TestSpecs
+func (a *RustSpecAdapter) GenerateFromSpecs(specs []model.TestSpec, sourceFile string) (string, error) {
+	if len(specs) == 0 {
+		return "", fmt.Errorf("no test specs provided")
+	}
+
+	// Group specs by target function, keeping their order
+	var funcNames []string
+	specsByFunc := make(map[string][]model.TestSpec)
+	for _, spec := range specs {
+		funcName := spec.FunctionName
+		if funcName == "" {
+			funcName = spec.TargetID
+		}
+		if _, ok := specsByFunc[funcName]; !ok {
+			funcNames = append(funcNames, funcName)
+		}
+		specsByFunc[funcName] = append(specsByFunc[funcName], spec)
+	}
+
+	data := rustSpecTemplateData{
+		Use:   RustUsePath(sourceFile),
+		Tests: make([]rustSpecTestData, 0, len(funcNames)),
+	}
+
+	// Test fns share the file's namespace, so names must be unique across it
+	used := make(map[string]int)
+	for _, funcName := range funcNames {
+		testData := rustSpecTestData{
+			FuncName: rustPath(funcName),
+			Cases:    make([]rustSpecCaseData, 0),
+		}
+
+		for _, spec := range specsByFunc[funcName] {
+			name := toRustTestName(funcName + "_" + spec.Description)
+			if n := used[name]; n > 0 {
+				used[name]++
+				name = fmt.Sprintf("%s_%d", name, n+1)
+			} else {
+				used[name] = 1
+			}
+
+			caseData := rustSpecCaseData{
+				Name:        name,
+				Description: spec.Description,
+				Assertions:  make([]string, 0),
+			}
+
+			args := rustArgOrder(spec)
+			for _, arg := range args {
+				value, ok := spec.Inputs[arg]
+				if !ok {
+					continue
+				}
+				caseData.Setup = append(caseData.Setup,
+					fmt.Sprintf("let %s = %s;", toRustTestName(arg), formatRustValueWithType(value, spec.InputTypes[arg])))
+			}
+			for i := range args {
+				args[i] = toRustTestName(args[i])
+			}
+			caseData.Action = fmt.Sprintf("let result = %s(%s);", rustPath(funcName), strings.Join(args, ", "))
+
+			for _, assertion := range spec.Assertions {
+				if code := a.generateAssertion(assertion); code != "" {
+					caseData.Assertions = append(caseData.Assertions, code)
+				}
+			}
+			if len(caseData.Assertions) == 0 {
+				caseData.Assertions = append(caseData.Assertions, "// TODO: Add assertions")
+			}
+
+			testData.Cases = append(testData.Cases, caseData)
+		}
+
+		data.Tests = append(data.Tests, testData)
+	}
+
+	tmpl, err := template.New("rustspec").Parse(rustSpecTemplate)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to execute template: %w", err)
+	}
+
+	return buf.String(), nil
+}
+
+// rustArgOrder returns a spec's argument names in call order
+func rustArgOrder(spec model.TestSpec) []string {
+	if len(spec.ArgOrder) > 0 {
+		return append([]string(nil), spec.ArgOrder...)
+	}
+
+	var namedArgs, indexedArgs []string
+	for key := range spec.Inputs {
+		if strings.HasPrefix(key, "arg") {
+			indexedArgs = append(indexedArgs, key)
+		} else {
+			namedArgs = append(namedArgs, key)
+		}
+	}
+	if len(namedArgs) > 0 {
+		sort.Strings(namedArgs)
+		return namedArgs
+	}
+	sort.Slice(indexedArgs, func(i, j int) bool {
+		numI, _ := strconv.Atoi(strings.TrimPrefix(indexedArgs[i], "arg"))
+		numJ, _ := strconv.Atoi(strings.TrimPrefix(indexedArgs[j], "arg"))
+		return numI < numJ
+	})
+	return indexedArgs
+}
+
+// formatRustValueWithType formats a value for Rust code using type hints
+func formatRustValueWithType(val interface{}, typeHint string) string {
+	switch typeHint {
+	case "int":
+		if f, ok := val.(float64); ok {
+			return strconv.FormatInt(int64(f), 10)
+		}
+	case "float":
+		if f, ok := val.(float64); ok {
+			s := strconv.FormatFloat(f, 'f', -1, 64)
+			if !strings.Contains(s, ".") {
+				s += ".0"
+			}
+			return s
+		}
+	case "string":
+		if s, ok := val.(string); ok {
+			return fmt.Sprintf("%q", s)
+		}
+		return fmt.Sprintf("%q", fmt.Sprintf("%v", val))
+	case "null":
+		return "None"
+	}
+	return formatRustValue(val)
+}
+
+// generateAssertion generates a Rust assertion from model.Assertion
+func (a *RustSpecAdapter) generateAssertion(assertion model.Assertion) string {
+	actual := stripDollarPrefix(assertion.Actual)
+	if actual == "" {
+		actual = "result"
+	}
+
+	switch assertion.Kind {
+	case "equality", "equals":
+		return fmt.Sprintf("assert_eq!(%s, %s);", actual, formatRustValue(assertion.Expected))
+
+	case "not_equal", "not_equals":
+		return fmt.Sprintf("assert_ne!(%s, %s);", actual, formatRustValue(assertion.Expected))
+
+	case "not_null", "not_nil", "is_not_nil":
+		return fmt.Sprintf("assert!(%s.is_some());", actual)
+
+	case "null", "nil", "is_nil":
+		return fmt.Sprintf("assert!(%s.is_none());", actual)
+
+	case "contains":
+		return fmt.Sprintf("assert!(%s.contains(%s));", actual, rustContainsArg(assertion.Expected))
+
+	case "greater_than":
+		return fmt.Sprintf("assert!(%s > %s);", actual, formatRustValue(assertion.Expected))
+
+	case "less_than":
+		return fmt.Sprintf("assert!(%s < %s);", actual, formatRustValue(assertion.Expected))
+
+	case "truthy":
+		return fmt.Sprintf("assert!(%s);", actual)
+
+	case "falsy":
+		return fmt.Sprintf("assert!(!%s);", actual)
+
+	case "throws", "raises", "error":
+		return rustErrorAssertion(actual, assertion)
+
+	case "type", "type_is":
+		return "// Type is checked by the compiler"
+
+	case "length":
+		return fmt.Sprintf("assert_eq!(%s.len(), %s);", actual, formatRustValue(assertion.Expected))
+
+	case model.AssertContainsAll:
+		items := strings.Join(formatItems(assertion, formatRustValue), ", ")
+		return fmt.Sprintf("assert!([%s].iter().all(|want| %s.iter().any(|got| got == want)));", items, actual)
+
+	case model.AssertSubset:
+		items := strings.Join(formatItems(assertion, formatRustValue), ", ")
+		return fmt.Sprintf("assert!(%s.iter().all(|got| [%s].iter().any(|want| got == want)));", actual, items)
+
+	case model.AssertSorted:
+		return rustSortedCheck(actual, assertion)
+
+	case model.AssertApprox:
+		return fmt.Sprintf("assert!(((%s as f64) - %s).abs() <= %s);", actual, approxExpected(assertion), approxEpsilon(assertion))
+
+	case model.AssertMatches:
+		// Needs the regex crate as a dev-dependency
+		return fmt.Sprintf("assert!(regex::Regex::new(r#\"%s\"#).unwrap().is_match(&%s.to_string()));", matchesPattern(assertion), actual)
+
+	default:
+		if assertion.Expected != nil {
+			return fmt.Sprintf("assert_eq!(%s, %s);", actual, formatRustValue(assertion.Expected))
+		}
+		return ""
+	}
+}
+
+// rustErrorAssertion checks a Result is an error, matching an expected enum
+// variant, e.g. ParseError::Empty, and message. An error type that isn't a
+// variant is checked by the compiler.
+func rustErrorAssertion(actual string, assertion model.Assertion) string {
+	var checks []string
+	if strings.Contains(assertion.ErrorType, "::") {
+		checks = append(checks, fmt.Sprintf("assert!(matches!(%s, Err(%s { .. })));", actual, assertion.ErrorType))
+	}
+	if msg := assertion.ErrorMessage; msg != "" {
+		checks = append(checks, fmt.Sprintf("assert!(matches!(&%s, Err(e) if e.to_string().contains(%q)));", actual, msg))
+	}
+	if len(checks) == 0 {
+		return fmt.Sprintf("assert!(%s.is_err());", actual)
+	}
+	return strings.Join(checks, "\n    ")
+}
@@ -0,0 +1,76 @@
+package adapters
+
+import "github.com/QTest-hq/qtest/pkg/dsl"
+
+// isSnapshotKind reports whether an assertion kind compares the result with
+// a stored snapshot rather than an expected value
+func isSnapshotKind(kind string) bool {
+	switch kind {
+	case "snapshot", "matches_snapshot", "golden":
+		return true
+	}
+	return false
+}
+
+// hasSnapshotSteps reports whether a DSL test has snapshot steps
+func hasSnapshotSteps(test *dsl.TestDSL) bool {
+	for _, step := range test.Steps {
+		if step.Action.Type == dsl.ActionSnapshot {
+			return true
+		}
+	}
+	return false
+}
+
+// snapshotSubject is the value a snapshot step compares: its target, else
+// the result of the steps before it
+func snapshotSubject(action dsl.StepAction) string {
+	if action.Target != "" {
+		return action.Target
+	}
+	return "result"
+}
+
+// goGoldenImports are the imports goGoldenHelper needs
+var goGoldenImports = []string{"encoding/json", "flag", "os", "path/filepath", "strings"}
+
+// goGoldenHelper compares results with golden files in testdata. The
+// -update flag is registered only when the package doesn't define its own.
+const goGoldenHelper = `func init() {
+	if flag.Lookup("update") == nil {
+		flag.Bool("update", false, "update golden files")
+	}
+}
+
+// assertGolden compares got, as indented JSON, with the test's golden file
+// in testdata. The file is written when it is missing, or with -update.
+func assertGolden(t *testing.T, got interface{}) {
+	t.Helper()
+	data, err := json.MarshalIndent(got, "", "  ")
+	if err != nil {
+		t.Fatalf("failed to marshal result: %v", err)
+	}
+	data = append(data, '\n')
+
+	path := filepath.Join("testdata", strings.ReplaceAll(t.Name(), "/", "__")+".golden")
+	update := flag.Lookup("update")
+	if _, err := os.Stat(path); os.IsNotExist(err) || (update != nil && update.Value.String() == "true") {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create testdata: %v", err)
+		}
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatalf("failed to write golden file: %v", err)
+		}
+		t.Logf("wrote golden file %s", path)
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read golden file: %v", err)
+	}
+	if string(data) != string(want) {
+		t.Errorf("result doesn't match %s (run go test -update to accept it)\ngot:\n%s\nwant:\n%s", path, data, want)
+	}
+}
+`
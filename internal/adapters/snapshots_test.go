@@ -0,0 +1,145 @@
+package adapters
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/QTest-hq/qtest/pkg/dsl"
+	"github.com/QTest-hq/qtest/pkg/model"
+)
+
+var snapshotSpecs = []model.TestSpec{
+	{
+		FunctionName: "render",
+		Description:  "renders a page",
+		Inputs:       map[string]interface{}{"name": "home"},
+		ArgOrder:     []string{"name"},
+		Assertions: []model.Assertion{
+			{Kind: "not_null", Actual: "result"},
+			{Kind: "snapshot", Actual: "result"},
+		},
+	},
+}
+
+func TestGoSpecAdapter_Snapshot(t *testing.T) {
+	code, err := NewGoSpecAdapter().GenerateFromSpecs(snapshotSpecs, "render.go")
+	if err != nil {
+		t.Fatalf("GenerateFromSpecs() error = %v", err)
+	}
+
+	for _, want := range []string{
+		"assertGolden(t, result)",
+		"func assertGolden(t *testing.T, got interface{})",
+		`flag.Bool("update", false, "update golden files")`,
+		`"encoding/json"`,
+		`"path/filepath"`,
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("generated code missing %q\n%s", want, code)
+		}
+	}
+	if strings.Count(code, `"strings"`) != 1 {
+		t.Errorf("expected one strings import\n%s", code)
+	}
+}
+
+func TestGoSpecAdapter_NoSnapshotNoGoldenHelper(t *testing.T) {
+	specs := []model.TestSpec{{
+		FunctionName: "Add",
+		Description:  "adds",
+		Assertions:   []model.Assertion{{Kind: "equality", Actual: "result", Expected: 3}},
+	}}
+	code, err := NewGoSpecAdapter().GenerateFromSpecs(specs, "math.go")
+	if err != nil {
+		t.Fatalf("GenerateFromSpecs() error = %v", err)
+	}
+	if strings.Contains(code, "assertGolden") || strings.Contains(code, `"flag"`) {
+		t.Errorf("golden helper generated without snapshot assertions\n%s", code)
+	}
+}
+
+func TestJestSpecAdapter_Snapshot(t *testing.T) {
+	code, err := NewJestSpecAdapter().GenerateFromSpecs(snapshotSpecs, "render.js")
+	if err != nil {
+		t.Fatalf("GenerateFromSpecs() error = %v", err)
+	}
+	if !strings.Contains(code, "expect(result).toMatchSnapshot();") {
+		t.Errorf("expected toMatchSnapshot()\n%s", code)
+	}
+}
+
+func TestPytestSpecAdapter_Snapshot(t *testing.T) {
+	code, err := NewPytestSpecAdapter().GenerateFromSpecs(snapshotSpecs, "render.py")
+	if err != nil {
+		t.Fatalf("GenerateFromSpecs() error = %v", err)
+	}
+	if !strings.Contains(code, "def test_renders_a_page(self, snapshot):") {
+		t.Errorf("expected the snapshot fixture\n%s", code)
+	}
+	if !strings.Contains(code, "assert result == snapshot") {
+		t.Errorf("expected a snapshot assertion\n%s", code)
+	}
+}
+
+func snapshotDSL(file string) *dsl.TestDSL {
+	return &dsl.TestDSL{
+		Name:   "render home",
+		Target: dsl.TestTarget{File: file, Function: "render"},
+		Steps: []dsl.TestStep{
+			{
+				Description: "render the page",
+				Action:      dsl.StepAction{Type: dsl.ActionCall, Target: "render", Args: []interface{}{"home"}},
+				Expected:    &dsl.Expected{Type: "string"},
+			},
+			{
+				Description: "matches the snapshot",
+				Action:      dsl.StepAction{Type: dsl.ActionSnapshot},
+			},
+		},
+	}
+}
+
+func TestGoAdapter_Generate_Snapshot(t *testing.T) {
+	code, err := NewGoAdapter().Generate(snapshotDSL("render.go"))
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if !strings.Contains(code, "assertGolden(t, result)") || !strings.Contains(code, "func assertGolden(") {
+		t.Errorf("expected a golden-file comparison\n%s", code)
+	}
+	if strings.Contains(code, "// snapshot") {
+		t.Errorf("snapshot step generated as an action\n%s", code)
+	}
+}
+
+func TestJestAdapter_Generate_Snapshot(t *testing.T) {
+	code, err := NewJestAdapter().Generate(snapshotDSL("render.js"))
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if !strings.Contains(code, "expect(result).toMatchSnapshot();") {
+		t.Errorf("expected toMatchSnapshot()\n%s", code)
+	}
+}
+
+func TestPytestAdapter_Generate_Snapshot(t *testing.T) {
+	code, err := NewPytestAdapter().Generate(snapshotDSL("render.py"))
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if !strings.Contains(code, "def test_render_home(snapshot):") {
+		t.Errorf("expected the snapshot fixture\n%s", code)
+	}
+	if !strings.Contains(code, "assert result == snapshot") {
+		t.Errorf("expected a snapshot assertion\n%s", code)
+	}
+}
+
+func TestSnapshotSubject(t *testing.T) {
+	if got := snapshotSubject(dsl.StepAction{Type: dsl.ActionSnapshot}); got != "result" {
+		t.Errorf("snapshotSubject() = %s, want result", got)
+	}
+	if got := snapshotSubject(dsl.StepAction{Type: dsl.ActionSnapshot, Target: "response"}); got != "response" {
+		t.Errorf("snapshotSubject() = %s, want response", got)
+	}
+}
@@ -13,6 +13,7 @@ const (
 	FrameworkJest   Framework = "jest"
 	FrameworkPytest Framework = "pytest"
 	FrameworkJUnit  Framework = "junit"
+	FrameworkCargo  Framework = "cargo"
 )
 
 // Adapter converts DSL tests to framework-specific code
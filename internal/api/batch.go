@@ -0,0 +1,391 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	"github.com/QTest-hq/qtest/internal/db"
+	gh "github.com/QTest-hq/qtest/internal/github"
+	"github.com/QTest-hq/qtest/internal/jobs"
+	"github.com/QTest-hq/qtest/internal/vcs"
+)
+
+// maxBatchSize caps the repositories in one batch request
+const maxBatchSize = 500
+
+// BatchRequest is the request body for submitting many repositories with
+// shared pipeline options
+type BatchRequest struct {
+	URLs []string `json:"urls"`
+	StartPipelineRequest
+}
+
+// batchEntry is a submitted URL and the repository it names
+type batchEntry struct {
+	item        jobs.BatchItem
+	info        *gh.RepoInfo // nil unless a pipeline should be started
+	duplicateOf int          // position of the first entry for the same repository
+}
+
+// createBatch creates repositories for a list of URLs and starts a pipeline
+// for each, skipping duplicates and repositories whose pipeline is already
+// running:
+//
+//	POST /repos/batch {"urls": [...], "max_tests": 20, ...}
+//
+// Every other field is a StartPipelineRequest option shared by the batch.
+func (s *Server) createBatch(w http.ResponseWriter, r *http.Request) {
+	if s.pipeline == nil || s.batchRepo == nil {
+		respondError(w, http.StatusServiceUnavailable, "job system not available")
+		return
+	}
+	if s.store == nil {
+		respondError(w, http.StatusServiceUnavailable, "database not available")
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	var req BatchRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if len(req.URLs) == 0 {
+		respondError(w, http.StatusBadRequest, "urls is required")
+		return
+	}
+	if len(req.URLs) > maxBatchSize {
+		respondError(w, http.StatusBadRequest, fmt.Sprintf("at most %d urls per batch", maxBatchSize))
+		return
+	}
+	if req.RepositoryURL != "" {
+		respondError(w, http.StatusBadRequest, "use urls, not repository_url, in a batch")
+		return
+	}
+	if req.VCS != "" && req.VCS != vcs.KindGit {
+		respondError(w, http.StatusBadRequest, "a batch only takes git repositories")
+		return
+	}
+
+	options, err := req.pipelineOptions()
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if options.Lane == jobs.LaneInteractive {
+		respondError(w, http.StatusBadRequest, "a batch can't use the interactive lane")
+		return
+	}
+	// Batch pipelines yield to other work unless they ask for the default lane
+	if req.Lane == "" {
+		options.Lane = jobs.LaneBatch
+	}
+
+	entries := planBatch(req.URLs)
+	if !hasRepositories(entries) {
+		respondError(w, http.StatusBadRequest, "no valid repository urls")
+		return
+	}
+
+	batch := &jobs.Batch{ID: uuid.New(), Options: batchOptions(body)}
+	var orgID uuid.UUID
+	if p := principal(r); p != nil {
+		var status int
+		orgID, status, err = s.targetOrg(r.Context(), p, req.OrganizationID)
+		if err != nil {
+			log.Error().Err(err).Msg("failed to check access")
+			respondError(w, http.StatusInternalServerError, "failed to check access")
+			return
+		}
+		if status != 0 {
+			respondError(w, status, "can't add repositories to the organization")
+			return
+		}
+		batch.OrganizationID = &orgID
+	}
+	if err := s.batchRepo.CreateBatch(r.Context(), batch); err != nil {
+		log.Error().Err(err).Msg("failed to create batch")
+		respondError(w, http.StatusInternalServerError, "failed to create batch")
+		return
+	}
+	options.BatchID = &batch.ID
+
+	ids := make(map[int]*uuid.UUID) // repository of each entry, by position
+	for i := range entries {
+		e := &entries[i]
+		if e.item.Outcome == jobs.BatchDuplicate {
+			e.item.RepositoryID = ids[e.duplicateOf]
+			continue
+		}
+		if e.info == nil {
+			continue
+		}
+
+		repo, err := s.batchRepository(r, orgID, e.item.URL, e.info)
+		if errors.Is(err, errOtherOrganization) {
+			e.fail(err.Error())
+			continue
+		}
+		if err != nil {
+			log.Error().Err(err).Str("url", e.item.URL).Msg("failed to create repository")
+			e.fail("failed to create repository")
+			continue
+		}
+		e.item.RepositoryID = &repo.ID
+		ids[e.item.Position] = &repo.ID
+
+		if active, err := s.activeJob(r.Context(), repo.ID); err != nil {
+			log.Error().Err(err).Str("url", e.item.URL).Msg("failed to check repository jobs")
+		} else if active != nil {
+			e.item.Outcome = jobs.BatchAlreadyRunning
+			e.item.JobID = &active.ID
+			continue
+		}
+
+		opts := options
+		opts.RepositoryID = &repo.ID
+		if err := s.applyPolicy(r.Context(), repo.ID, &opts); err != nil {
+			log.Error().Err(err).Str("url", e.item.URL).Msg("failed to load repository policy")
+			e.fail("failed to load repository policy")
+			continue
+		}
+		job, err := s.pipeline.StartFullPipeline(r.Context(), repo.URL, opts)
+		if err != nil {
+			log.Error().Err(err).Str("url", e.item.URL).Msg("failed to start pipeline")
+			e.fail("failed to start pipeline")
+			continue
+		}
+		e.item.JobID = &job.ID
+	}
+
+	batch.Items = make([]jobs.BatchItem, len(entries))
+	for i, e := range entries {
+		batch.Items[i] = e.item
+	}
+	if err := s.batchRepo.AddBatchItems(r.Context(), batch.ID, batch.Items); err != nil {
+		// The pipelines are running; only the batch's record of them is lost
+		log.Error().Err(err).Str("batch_id", batch.ID.String()).Msg("failed to record batch items")
+		respondError(w, http.StatusInternalServerError, "pipelines started but the batch could not be recorded")
+		return
+	}
+
+	log.Info().
+		Str("batch_id", batch.ID.String()).
+		Int("urls", len(req.URLs)).
+		Msg("started batch")
+
+	created, err := s.batchRepo.GetBatch(r.Context(), batch.ID)
+	if err != nil || created == nil {
+		log.Error().Err(err).Str("batch_id", batch.ID.String()).Msg("failed to get batch")
+		respondJSON(w, http.StatusCreated, batch)
+		return
+	}
+	respondJSON(w, http.StatusCreated, created)
+}
+
+// getBatch returns a batch with the pipeline status of each repository
+func (s *Server) getBatch(w http.ResponseWriter, r *http.Request) {
+	if s.batchRepo == nil {
+		respondError(w, http.StatusServiceUnavailable, "job system not available")
+		return
+	}
+
+	batchID, err := uuid.Parse(chi.URLParam(r, "batchID"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid batch ID")
+		return
+	}
+
+	batch, err := s.batchRepo.GetBatch(r.Context(), batchID)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to get batch")
+		respondError(w, http.StatusInternalServerError, "failed to get batch")
+		return
+	}
+	if batch == nil {
+		respondError(w, http.StatusNotFound, "batch not found")
+		return
+	}
+	if !s.authorizeOrg(w, r, batch.OrganizationID, "batch not found") {
+		return
+	}
+
+	respondJSON(w, http.StatusOK, batch)
+}
+
+// listBatches lists recent batches with their item counts
+func (s *Server) listBatches(w http.ResponseWriter, r *http.Request) {
+	if s.batchRepo == nil {
+		respondError(w, http.StatusServiceUnavailable, "job system not available")
+		return
+	}
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	var batches []*jobs.Batch
+	var err error
+	if p := principal(r); p != nil {
+		var orgIDs []uuid.UUID
+		orgIDs, err = s.principalOrgs(r.Context(), p)
+		if err == nil {
+			batches, err = s.batchRepo.ListBatchesForOrganizations(r.Context(), orgIDs, limit)
+		}
+	} else {
+		batches, err = s.batchRepo.ListBatchesOutsideOrganizations(r.Context(), limit)
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("failed to list batches")
+		respondError(w, http.StatusInternalServerError, "failed to list batches")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, batches)
+}
+
+// planBatch parses and deduplicates submitted URLs. Different spellings of
+// the same GitHub repository (SSH, .git suffix, letter case) count as
+// duplicates of the first.
+func planBatch(urls []string) []batchEntry {
+	entries := make([]batchEntry, len(urls))
+	first := make(map[string]int)
+	for i, raw := range urls {
+		raw = strings.TrimSpace(raw)
+		e := &entries[i]
+		e.item = jobs.BatchItem{Position: i, URL: raw, Outcome: jobs.BatchQueued}
+
+		info, err := gh.ParseRepoURL(raw)
+		if err != nil || raw == "" {
+			msg := "url is empty"
+			if err != nil {
+				msg = err.Error()
+			}
+			e.fail(msg)
+			e.item.Outcome = jobs.BatchInvalid
+			continue
+		}
+
+		key := strings.ToLower(info.Owner + "/" + info.Name)
+		if pos, ok := first[key]; ok {
+			e.item.Outcome = jobs.BatchDuplicate
+			e.duplicateOf = pos
+			continue
+		}
+		first[key] = i
+		e.info = info
+	}
+	return entries
+}
+
+func hasRepositories(entries []batchEntry) bool {
+	for _, e := range entries {
+		if e.info != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// fail records why no pipeline was started for the entry
+func (e *batchEntry) fail(msg string) {
+	e.item.Outcome = jobs.BatchFailed
+	e.item.Error = &msg
+	e.info = nil
+}
+
+// batchRepository finds the repository a URL names, by the URL as given or
+// its canonical https form, creating it if it doesn't exist. The ingestion
+// worker clones it. With credentials, it's created in orgID, and an
+// existing one must be in an organization the caller can write to.
+func (s *Server) batchRepository(r *http.Request, orgID uuid.UUID, url string, info *gh.RepoInfo) (*db.Repository, error) {
+	p := principal(r)
+	// GitHub repositories are registered under their canonical URL
+	canonical := url
+	if info.CloneURL != "" {
+		canonical = fmt.Sprintf("https://github.com/%s/%s", info.Owner, info.Name)
+	}
+	for _, u := range []string{url, canonical} {
+		repo, err := s.store.GetRepositoryByURL(r.Context(), u)
+		if err != nil {
+			return nil, err
+		}
+		if repo == nil {
+			continue
+		}
+		status, err := s.orgAccess(r.Context(), p, repo.OrganizationID, true)
+		if err != nil {
+			return nil, err
+		}
+		if status != 0 {
+			return nil, errOtherOrganization
+		}
+		return repo, nil
+	}
+
+	repo := &db.Repository{
+		URL:           canonical,
+		Name:          info.Name,
+		Owner:         info.Owner,
+		DefaultBranch: info.Branch,
+	}
+	var err error
+	if p != nil {
+		err = s.store.CreateRepositoryForOrg(r.Context(), repo, orgID, p.UserID)
+	} else {
+		err = s.store.CreateRepository(r.Context(), repo)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return repo, nil
+}
+
+// activeJob returns an unfinished job of the repository's, if any
+func (s *Server) activeJob(ctx context.Context, repoID uuid.UUID) (*jobs.Job, error) {
+	if s.jobRepo == nil {
+		return nil, nil
+	}
+	recent, err := s.jobRepo.ListByRepository(ctx, repoID, 20)
+	if err != nil {
+		return nil, err
+	}
+	for _, j := range recent {
+		switch j.Status {
+		case jobs.StatusPending, jobs.StatusRunning, jobs.StatusRetrying:
+			return j, nil
+		}
+	}
+	return nil, nil
+}
+
+// batchOptions keeps the shared options of a batch request body, without
+// its URLs, to record with the batch
+func batchOptions(body []byte) json.RawMessage {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return nil
+	}
+	delete(fields, "urls")
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return nil
+	}
+	return data
+}
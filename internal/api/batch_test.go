@@ -0,0 +1,90 @@
+package api
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/QTest-hq/qtest/internal/jobs"
+)
+
+func TestPlanBatch(t *testing.T) {
+	entries := planBatch([]string{
+		"https://github.com/acme/api",
+		"git@github.com:acme/web.git",
+		"https://github.com/Acme/API.git",
+		"https://gitlab.com/acme/api",
+		"  ",
+		"https://github.com/acme/web",
+	})
+
+	want := []struct {
+		outcome     string
+		duplicateOf int
+		hasError    bool
+	}{
+		{jobs.BatchQueued, 0, false},
+		{jobs.BatchQueued, 0, false},
+		{jobs.BatchDuplicate, 0, false},
+		{jobs.BatchInvalid, 0, true},
+		{jobs.BatchInvalid, 0, true},
+		{jobs.BatchDuplicate, 1, false},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("len(entries) = %d, want %d", len(entries), len(want))
+	}
+	for i, w := range want {
+		e := entries[i]
+		if e.item.Position != i {
+			t.Errorf("entries[%d].Position = %d", i, e.item.Position)
+		}
+		if e.item.Outcome != w.outcome {
+			t.Errorf("entries[%d].Outcome = %s, want %s", i, e.item.Outcome, w.outcome)
+		}
+		if e.item.Outcome == jobs.BatchDuplicate && e.duplicateOf != w.duplicateOf {
+			t.Errorf("entries[%d].duplicateOf = %d, want %d", i, e.duplicateOf, w.duplicateOf)
+		}
+		if (e.item.Error != nil) != w.hasError {
+			t.Errorf("entries[%d].Error = %v, want error %v", i, e.item.Error, w.hasError)
+		}
+		if (e.info != nil) != (w.outcome == jobs.BatchQueued) {
+			t.Errorf("entries[%d] starts a pipeline = %v", i, e.info != nil)
+		}
+	}
+	if !hasRepositories(entries) {
+		t.Error("hasRepositories() = false")
+	}
+	if hasRepositories(planBatch([]string{"not a url"})) {
+		t.Error("hasRepositories() = true for only invalid urls")
+	}
+}
+
+func TestBatchOptions(t *testing.T) {
+	got := batchOptions([]byte(`{"urls": ["https://github.com/acme/api"], "max_tests": 20, "run_mutation": true}`))
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(got, &fields); err != nil {
+		t.Fatalf("batchOptions() = %s: %v", got, err)
+	}
+	if _, ok := fields["urls"]; ok {
+		t.Error("batchOptions() kept urls")
+	}
+	if fields["max_tests"] != float64(20) || fields["run_mutation"] != true {
+		t.Errorf("batchOptions() = %s", got)
+	}
+}
+
+func TestStartPipelineRequest_Lane(t *testing.T) {
+	req := &StartPipelineRequest{Lane: "interactive"}
+	opts, err := req.pipelineOptions()
+	if err != nil {
+		t.Fatalf("pipelineOptions() error = %v", err)
+	}
+	if opts.Lane != jobs.LaneInteractive {
+		t.Errorf("Lane = %q, want interactive", opts.Lane)
+	}
+
+	req = &StartPipelineRequest{Lane: "urgent"}
+	if _, err := req.pipelineOptions(); err == nil {
+		t.Error("pipelineOptions() should reject an unknown lane")
+	}
+}
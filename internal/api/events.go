@@ -0,0 +1,176 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/nats-io/nats.go"
+	"github.com/rs/zerolog/log"
+
+	"github.com/QTest-hq/qtest/internal/jobs"
+	qtestnats "github.com/QTest-hq/qtest/internal/nats"
+)
+
+// eventPollInterval is how often a job event stream rereads the job's
+// status, which covers events missed without NATS. A ": keepalive"
+// comment is sent when it hasn't changed, keeping the connection alive
+// through proxies.
+const eventPollInterval = 5 * time.Second
+
+// eventBuffer is how many progress events a slow client may fall behind
+// before newer ones are dropped
+const eventBuffer = 64
+
+// progressSubscriber delivers a job's progress events to fn until the
+// returned func is called
+type progressSubscriber func(jobID uuid.UUID, fn func(jobs.ProgressEvent)) (func(), error)
+
+// natsProgressSubscriber subscribes to the progress events workers publish
+// on NATS
+func natsProgressSubscriber(client *qtestnats.Client) progressSubscriber {
+	return func(jobID uuid.UUID, fn func(jobs.ProgressEvent)) (func(), error) {
+		conn := client.Conn()
+		if conn == nil {
+			return nil, fmt.Errorf("not connected to NATS")
+		}
+		sub, err := conn.Subscribe(qtestnats.ProgressSubject(jobID.String()), func(msg *nats.Msg) {
+			var event jobs.ProgressEvent
+			if err := json.Unmarshal(msg.Data, &event); err != nil {
+				log.Debug().Err(err).Msg("invalid job progress event")
+				return
+			}
+			fn(event)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to subscribe to job progress: %w", err)
+		}
+		return func() { sub.Unsubscribe() }, nil
+	}
+}
+
+// streamJobEvents streams a job's progress as server-sent events until the
+// job finishes or the client goes away. The first event is the job's
+// current status; "progress" events follow as workers report them, and
+// "status" events when the job's status changes.
+func (s *Server) streamJobEvents(w http.ResponseWriter, r *http.Request) {
+	if s.jobRepo == nil {
+		respondError(w, http.StatusServiceUnavailable, "job system not available")
+		return
+	}
+
+	jobID, err := uuid.Parse(chi.URLParam(r, "jobID"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid job ID")
+		return
+	}
+
+	job, err := s.jobRepo.GetByID(r.Context(), jobID)
+	if err != nil || job == nil {
+		respondError(w, http.StatusNotFound, "job not found")
+		return
+	}
+	if !s.authorizeJob(w, r, job, "job not found") {
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		respondError(w, http.StatusInternalServerError, "streaming not supported")
+		return
+	}
+
+	// The server's write timeout would cut the stream off mid-job
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		log.Debug().Err(err).Msg("failed to clear event stream write deadline")
+	}
+
+	// Subscribe before sending the status, so no change falls in between
+	events := make(chan jobs.ProgressEvent, eventBuffer)
+	if s.subscribeProgress != nil {
+		unsubscribe, err := s.subscribeProgress(jobID, func(e jobs.ProgressEvent) {
+			select {
+			case events <- e:
+			default:
+			}
+		})
+		if err != nil {
+			log.Warn().Err(err).Str("job_id", jobID.String()).Msg("job events fall back to polling")
+		} else {
+			defer unsubscribe()
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	status := job.Status
+	if writeEvent(w, flusher, jobs.NewStatusEvent(job)) != nil || status.Finished() {
+		return
+	}
+
+	poll := s.eventPoll
+	if poll == 0 {
+		poll = eventPollInterval
+	}
+	ticker := time.NewTicker(poll)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case e := <-events:
+			if writeEvent(w, flusher, e) != nil {
+				return
+			}
+			if e.Phase == "" {
+				status = e.Status
+				if status.Finished() {
+					return
+				}
+			}
+		case <-ticker.C:
+			current, err := s.jobRepo.GetByID(r.Context(), jobID)
+			if err != nil || current == nil {
+				return
+			}
+			if current.Status != status {
+				status = current.Status
+				if writeEvent(w, flusher, jobs.NewStatusEvent(current)) != nil || status.Finished() {
+					return
+				}
+				continue
+			}
+			if _, err := fmt.Fprint(w, ": keepalive\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// writeEvent writes a progress event as a server-sent event: "status" for
+// status changes, "progress" otherwise
+func writeEvent(w http.ResponseWriter, flusher http.Flusher, e jobs.ProgressEvent) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	name := "progress"
+	if e.Phase == "" {
+		name = "status"
+	}
+	if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", name, data); err != nil {
+		return err
+	}
+	flusher.Flush()
+	return nil
+}
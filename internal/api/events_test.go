@@ -0,0 +1,127 @@
+package api
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/QTest-hq/qtest/internal/config"
+	"github.com/QTest-hq/qtest/internal/jobs"
+)
+
+func eventsServer(repo *MockJobRepository) *Server {
+	s := &Server{cfg: &config.Config{}, router: chi.NewRouter(), jobRepo: repo}
+	s.setupRoutes()
+	return s
+}
+
+func TestStreamJobEvents_RelaysProgress(t *testing.T) {
+	repo := NewMockJobRepository()
+	job, _ := jobs.NewJob(jobs.JobTypeGeneration, nil)
+	job.Status = jobs.StatusRunning
+	repo.AddJob(job)
+
+	s := eventsServer(repo)
+	unsubscribed := false
+	s.subscribeProgress = func(jobID uuid.UUID, fn func(jobs.ProgressEvent)) (func(), error) {
+		if jobID != job.ID {
+			t.Errorf("subscribed to %s, want %s", jobID, job.ID)
+		}
+		fn(jobs.ProgressEvent{JobID: jobID, Status: jobs.StatusRunning, Phase: "generation", Current: 1, Total: 3, Message: "src/app.js"})
+		fn(jobs.ProgressEvent{JobID: jobID, Status: jobs.StatusCompleted})
+		fn(jobs.ProgressEvent{JobID: jobID, Status: jobs.StatusRunning, Phase: "generation", Current: 2, Total: 3})
+		return func() { unsubscribed = true }, nil
+	}
+
+	rec := httptest.NewRecorder()
+	s.router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/jobs/"+job.ID.String()+"/events", nil))
+
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("response = %d %s", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	body := rec.Body.String()
+	events := strings.Split(strings.TrimSpace(body), "\n\n")
+	if len(events) != 3 {
+		t.Fatalf("got %d events, want running status, progress and completed status:\n%s", len(events), body)
+	}
+	for i, want := range []string{`event: status` + "\n" + `data: {"job_id"`, `event: progress`, `event: status`} {
+		if !strings.HasPrefix(events[i], want) {
+			t.Errorf("event %d = %q, want prefix %q", i, events[i], want)
+		}
+	}
+	if !strings.Contains(events[1], `"phase":"generation","current":1,"total":3,"message":"src/app.js"`) {
+		t.Errorf("progress event = %q", events[1])
+	}
+	if !strings.Contains(events[2], `"status":"completed"`) {
+		t.Errorf("last event = %q, want the completed status", events[2])
+	}
+	if !unsubscribed {
+		t.Error("stream should unsubscribe when it ends")
+	}
+}
+
+func TestStreamJobEvents_FinishedJob(t *testing.T) {
+	repo := NewMockJobRepository()
+	job, _ := jobs.NewJob(jobs.JobTypeValidation, nil)
+	job.Status = jobs.StatusFailed
+	msg := "tests did not compile"
+	job.ErrorMessage = &msg
+	repo.AddJob(job)
+
+	rec := httptest.NewRecorder()
+	eventsServer(repo).router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/jobs/"+job.ID.String()+"/events", nil))
+
+	body := rec.Body.String()
+	if strings.Count(body, "event: ") != 1 || !strings.Contains(body, `"status":"failed"`) || !strings.Contains(body, msg) {
+		t.Errorf("stream of a finished job should be its status alone:\n%s", body)
+	}
+
+	rec = httptest.NewRecorder()
+	eventsServer(repo).router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/jobs/"+uuid.NewString()+"/events", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("unknown job = %d, want 404", rec.Code)
+	}
+}
+
+func TestStreamJobEvents_OutlivesWriteTimeout(t *testing.T) {
+	repo := NewMockJobRepository()
+	job, _ := jobs.NewJob(jobs.JobTypeGeneration, nil)
+	job.Status = jobs.StatusRunning
+	repo.AddJob(job)
+
+	s := eventsServer(repo)
+	s.eventPoll = 50 * time.Millisecond
+	s.subscribeProgress = func(jobID uuid.UUID, fn func(jobs.ProgressEvent)) (func(), error) {
+		timer := time.AfterFunc(500*time.Millisecond, func() {
+			fn(jobs.ProgressEvent{JobID: jobID, Status: jobs.StatusCompleted})
+		})
+		return func() { timer.Stop() }, nil
+	}
+
+	srv := httptest.NewUnstartedServer(s.router)
+	srv.Config.WriteTimeout = 200 * time.Millisecond
+	srv.Start()
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/api/v1/jobs/" + job.ID.String() + "/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("stream cut off: %v\n%s", err, body)
+	}
+	if !strings.Contains(string(body), ": keepalive") {
+		t.Errorf("stream sent no heartbeat:\n%s", body)
+	}
+	if !strings.Contains(string(body), `"status":"completed"`) {
+		t.Errorf("stream ended before the job finished:\n%s", body)
+	}
+}
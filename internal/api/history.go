@@ -0,0 +1,92 @@
+package api
+
+import (
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	"github.com/QTest-hq/qtest/internal/db"
+)
+
+// historySuffix ends file history URLs; the file path before it may contain
+// slashes, so the route matches the rest of the URL
+const historySuffix = "/history"
+
+// getFileHistory returns every generation attempt for a file, with statuses,
+// scores and diffs between attempts:
+//
+//	GET /repos/{repoID}/files/{path}/history?function=name&limit=50
+//
+// The path is relative to the repository root and may be the source file
+// under test or the generated test file.
+func (s *Server) getFileHistory(w http.ResponseWriter, r *http.Request) {
+	if s.store == nil {
+		respondError(w, http.StatusServiceUnavailable, "database not available")
+		return
+	}
+
+	repoID, err := uuid.Parse(chi.URLParam(r, "repoID"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid repo ID")
+		return
+	}
+
+	filePath, ok := historyFilePath(chi.URLParam(r, "*"))
+	if !ok {
+		respondError(w, http.StatusNotFound, "not found")
+		return
+	}
+
+	q := r.URL.Query()
+	function := q.Get("function")
+	limit := 50
+	if v := q.Get("limit"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			limit = n
+			if limit > 200 {
+				limit = 200
+			}
+		}
+	}
+
+	attempts, err := s.store.ListGenerationAttempts(r.Context(), repoID, filePath, function, limit)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to list generation attempts")
+		respondError(w, http.StatusInternalServerError, "failed to get file history")
+		return
+	}
+
+	var mutationRuns []db.MutationRun
+	if function == "" {
+		mutationRuns, err = s.store.ListMutationRunsByFile(r.Context(), repoID, filePath, limit)
+		if err != nil {
+			log.Error().Err(err).Msg("failed to list mutation runs")
+			respondError(w, http.StatusInternalServerError, "failed to get file history")
+			return
+		}
+	}
+
+	respondJSON(w, http.StatusOK, db.NewFileHistory(repoID, filePath, function, attempts, mutationRuns))
+}
+
+// historyFilePath extracts the file path from the wildcard part of a file
+// history URL, rejecting paths that escape the repository
+func historyFilePath(wildcard string) (string, bool) {
+	if !strings.HasSuffix(wildcard, historySuffix) {
+		return "", false
+	}
+	p := strings.TrimSuffix(wildcard, historySuffix)
+	if p == "" {
+		return "", false
+	}
+	p = path.Clean(p)
+	if p == "." || p == ".." || strings.HasPrefix(p, "../") || strings.HasPrefix(p, "/") {
+		return "", false
+	}
+	return p, true
+}
@@ -0,0 +1,25 @@
+package api
+
+import "testing"
+
+func TestHistoryFilePath(t *testing.T) {
+	tests := []struct {
+		wildcard string
+		want     string
+		ok       bool
+	}{
+		{"src/users.go/history", "src/users.go", true},
+		{"main.go/history", "main.go", true},
+		{"src/./users.go/history", "src/users.go", true},
+		{"src/users.go", "", false},
+		{"/history", "", false},
+		{"../etc/passwd/history", "", false},
+		{"src/../../x/history", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := historyFilePath(tt.wildcard)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("historyFilePath(%q) = %q, %v, want %q, %v", tt.wildcard, got, ok, tt.want, tt.ok)
+		}
+	}
+}
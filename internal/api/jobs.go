@@ -2,6 +2,8 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 
@@ -9,25 +11,42 @@ import (
 	"github.com/google/uuid"
 	"github.com/rs/zerolog/log"
 
+	"github.com/QTest-hq/qtest/internal/db"
 	"github.com/QTest-hq/qtest/internal/jobs"
+	"github.com/QTest-hq/qtest/internal/vcs"
 )
 
 // CreateJobRequest is the request body for creating a job
 type CreateJobRequest struct {
 	Type     string                 `json:"type"`               // ingestion, modeling, planning, generation, mutation, integration
 	Priority int                    `json:"priority,omitempty"` // Higher = more urgent
+	Lane     string                 `json:"lane,omitempty"`     // interactive, default or batch; sets the priority
 	Payload  map[string]interface{} `json:"payload"`
+
+	// RepositoryID is the repository the job works on; required with
+	// credentials, so the job belongs to its organization
+	RepositoryID *uuid.UUID `json:"repository_id,omitempty"`
 }
 
 // StartPipelineRequest is the request body for starting a full pipeline
 type StartPipelineRequest struct {
-	RepositoryURL string   `json:"repository_url"`
-	Branch        string   `json:"branch,omitempty"`
-	MaxTests      int      `json:"max_tests,omitempty"`
-	LLMTier       int      `json:"llm_tier,omitempty"` // 1=fast, 2=balanced, 3=thorough
-	TestLevels    []string `json:"test_levels,omitempty"`
-	RunMutation   bool     `json:"run_mutation,omitempty"`
-	CreatePR      bool     `json:"create_pr,omitempty"`
+	RepositoryURL string          `json:"repository_url"`
+	Branch        string          `json:"branch,omitempty"`
+	MaxTests      int             `json:"max_tests,omitempty"`
+	LLMTier       int             `json:"llm_tier,omitempty"` // 1=fast, 2=balanced, 3=thorough
+	TestLevels    []string        `json:"test_levels,omitempty"`
+	RunMutation   bool            `json:"run_mutation,omitempty"`
+	CreatePR      bool            `json:"create_pr,omitempty"`
+	PR            *jobs.PROptions `json:"pr,omitempty"`   // draft, labels, assignees, reviewers, auto-merge
+	Lane          string          `json:"lane,omitempty"` // interactive, default or batch
+
+	// VCS is the kind of source the URL names: git, hg or archive; detected
+	// from the URL when empty
+	VCS string `json:"vcs,omitempty"`
+
+	// OrganizationID is the organization to register a new repository in;
+	// an API key's or the caller's personal organization when empty
+	OrganizationID *uuid.UUID `json:"organization_id,omitempty"`
 }
 
 // JobResponse is the API response for a job
@@ -36,6 +55,7 @@ type JobResponse struct {
 	Type            string          `json:"type"`
 	Status          string          `json:"status"`
 	Priority        int             `json:"priority"`
+	Lane            string          `json:"lane"`
 	RepositoryID    *uuid.UUID      `json:"repository_id,omitempty"`
 	GenerationRunID *uuid.UUID      `json:"generation_run_id,omitempty"`
 	ParentJobID     *uuid.UUID      `json:"parent_job_id,omitempty"`
@@ -68,6 +88,7 @@ func jobToResponse(j *jobs.Job) *JobResponse {
 		Type:            string(j.Type),
 		Status:          string(j.Status),
 		Priority:        j.Priority,
+		Lane:            string(j.Lane()),
 		RepositoryID:    j.RepositoryID,
 		GenerationRunID: j.GenerationRunID,
 		ParentJobID:     j.ParentJobID,
@@ -97,39 +118,115 @@ func jobToResponse(j *jobs.Job) *JobResponse {
 
 // startPipeline starts a full test generation pipeline
 func (s *Server) startPipeline(w http.ResponseWriter, r *http.Request) {
+	job, ok := s.launchPipeline(w, r)
+	if !ok {
+		return
+	}
+	respondJSON(w, http.StatusCreated, jobToResponse(job))
+}
+
+// launchPipeline starts the pipeline a StartPipelineRequest asks for and
+// returns its root job, responding with an error when it can't
+func (s *Server) launchPipeline(w http.ResponseWriter, r *http.Request) (*jobs.Job, bool) {
 	if s.pipeline == nil {
 		respondError(w, http.StatusServiceUnavailable, "job system not available")
-		return
+		return nil, false
 	}
 
 	var req StartPipelineRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		respondError(w, http.StatusBadRequest, "invalid request body")
-		return
+		return nil, false
 	}
 
 	if req.RepositoryURL == "" {
 		respondError(w, http.StatusBadRequest, "repository_url is required")
-		return
+		return nil, false
+	}
+	// The URL keeps the kind of source, for workers and scheduled runs
+	if req.VCS != "" {
+		if !vcs.Valid(req.VCS) {
+			respondError(w, http.StatusBadRequest, "vcs must be git, hg or archive")
+			return nil, false
+		}
+		req.RepositoryURL = vcs.Qualify(req.VCS, req.RepositoryURL)
+	}
+	if req.CreatePR && vcs.Detect(req.RepositoryURL) != vcs.KindGit {
+		respondError(w, http.StatusBadRequest, "create_pr needs a git repository")
+		return nil, false
+	}
+	if _, err := repoInfo(r.Context(), req.RepositoryURL); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return nil, false
 	}
 
-	options := jobs.PipelineOptions{
-		Branch:      req.Branch,
-		MaxTests:    req.MaxTests,
-		LLMTier:     req.LLMTier,
-		TestLevels:  req.TestLevels,
-		RunMutation: req.RunMutation,
-		CreatePR:    req.CreatePR,
+	options, err := req.pipelineOptions()
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return nil, false
+	}
+
+	// Known repositories inherit their policy's defaults. With credentials,
+	// pipelines run in a repository of the caller's organizations,
+	// registered if the URL is new.
+	if s.store != nil {
+		var repo *db.Repository
+		if p := principal(r); p != nil {
+			var ok bool
+			if repo, ok = s.pipelineRepository(w, r, p, &req); !ok {
+				return nil, false
+			}
+			options.RepositoryID = &repo.ID
+		} else if repo, err = s.store.GetRepositoryByURL(r.Context(), req.RepositoryURL); err != nil {
+			log.Error().Err(err).Msg("failed to get repository")
+			respondError(w, http.StatusInternalServerError, "failed to get repository")
+			return nil, false
+		} else if repo != nil && repo.OrganizationID != nil {
+			// Without credentials, an organization's repository is another's
+			respondError(w, http.StatusConflict, errOtherOrganization.Error())
+			return nil, false
+		}
+		if repo != nil {
+			if err := s.applyPolicy(r.Context(), repo.ID, &options); err != nil {
+				log.Error().Err(err).Msg("failed to load repository policy")
+				respondError(w, http.StatusInternalServerError, "failed to load repository policy")
+				return nil, false
+			}
+		}
 	}
 
 	job, err := s.pipeline.StartFullPipeline(r.Context(), req.RepositoryURL, options)
 	if err != nil {
 		log.Error().Err(err).Msg("failed to start pipeline")
 		respondError(w, http.StatusInternalServerError, "failed to start pipeline")
-		return
+		return nil, false
 	}
+	return job, true
+}
 
-	respondJSON(w, http.StatusCreated, jobToResponse(job))
+// pipelineOptions validates the request's pipeline options
+func (req *StartPipelineRequest) pipelineOptions() (jobs.PipelineOptions, error) {
+	if err := req.PR.Validate(); err != nil {
+		return jobs.PipelineOptions{}, fmt.Errorf("invalid pr options: %w", err)
+	}
+	if req.PR != nil && !req.CreatePR {
+		return jobs.PipelineOptions{}, errors.New("pr options require create_pr")
+	}
+	lane, err := jobs.ParseLane(req.Lane)
+	if err != nil {
+		return jobs.PipelineOptions{}, err
+	}
+
+	return jobs.PipelineOptions{
+		Branch:      req.Branch,
+		MaxTests:    req.MaxTests,
+		LLMTier:     req.LLMTier,
+		TestLevels:  req.TestLevels,
+		RunMutation: req.RunMutation,
+		CreatePR:    req.CreatePR,
+		PR:          req.PR,
+		Lane:        lane,
+	}, nil
 }
 
 // createJob creates a new job
@@ -156,12 +253,29 @@ func (s *Server) createJob(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if req.RepositoryID == nil && principal(r) != nil {
+		respondError(w, http.StatusBadRequest, "repository_id is required")
+		return
+	}
+	if req.RepositoryID != nil && !s.authorizeRepo(w, r, *req.RepositoryID, "repository not found") {
+		return
+	}
+
 	job, err := jobs.NewJob(jobType, req.Payload)
 	if err != nil {
 		respondError(w, http.StatusBadRequest, "invalid payload")
 		return
 	}
+	job.RepositoryID = req.RepositoryID
 	job.Priority = req.Priority
+	if req.Lane != "" {
+		lane, err := jobs.ParseLane(req.Lane)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		job.Priority = lane.Priority()
+	}
 
 	if err := s.jobRepo.Create(r.Context(), job); err != nil {
 		log.Error().Err(err).Msg("failed to create job")
@@ -169,9 +283,11 @@ func (s *Server) createJob(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Publish to NATS if available
+	// Publish to NATS if available; otherwise workers poll for it
 	if s.pipeline != nil {
-		// Pipeline handles publishing internally
+		if err := s.pipeline.Publish(r.Context(), job); err != nil {
+			log.Error().Err(err).Str("job_id", job.ID.String()).Msg("failed to publish job")
+		}
 	}
 
 	respondJSON(w, http.StatusCreated, jobToResponse(job))
@@ -195,13 +311,14 @@ func (s *Server) listJobs(w http.ResponseWriter, r *http.Request) {
 	var jobList []*jobs.Job
 	var err error
 
-	if status != "" {
-		jobList, err = s.jobRepo.ListByStatus(r.Context(), jobs.JobStatus(status), limit)
-	} else if jobType != "" {
-		jobList, err = s.jobRepo.ListPendingByType(r.Context(), jobs.JobType(jobType), limit)
+	if p := principal(r); p != nil {
+		var orgIDs []uuid.UUID
+		orgIDs, err = s.principalOrgs(r.Context(), p)
+		if err == nil {
+			jobList, err = s.jobRepo.ListForOrganizations(r.Context(), orgIDs, jobs.JobStatus(status), jobs.JobType(jobType), limit)
+		}
 	} else {
-		// List all recent jobs by default
-		jobList, err = s.jobRepo.ListRecent(r.Context(), limit)
+		jobList, err = s.jobRepo.ListOutsideOrganizations(r.Context(), jobs.JobStatus(status), jobs.JobType(jobType), limit)
 	}
 
 	if err != nil {
@@ -236,6 +353,9 @@ func (s *Server) getJob(w http.ResponseWriter, r *http.Request) {
 		respondError(w, http.StatusNotFound, "job not found")
 		return
 	}
+	if !s.authorizeJob(w, r, report.Job, "job not found") {
+		return
+	}
 
 	children := make([]*JobResponse, len(report.Children))
 	for i, c := range report.Children {
@@ -250,7 +370,8 @@ func (s *Server) getJob(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, http.StatusOK, resp)
 }
 
-// cancelJob cancels a pending job
+// cancelJob cancels a pending, retrying or running job. The worker running
+// it is told to stop at once.
 func (s *Server) cancelJob(w http.ResponseWriter, r *http.Request) {
 	if s.jobRepo == nil {
 		respondError(w, http.StatusServiceUnavailable, "job system not available")
@@ -262,12 +383,18 @@ func (s *Server) cancelJob(w http.ResponseWriter, r *http.Request) {
 		respondError(w, http.StatusBadRequest, "invalid job ID")
 		return
 	}
+	if !s.authorizeJobID(w, r, jobID) {
+		return
+	}
 
 	if err := s.jobRepo.Cancel(r.Context(), jobID); err != nil {
 		log.Error().Err(err).Str("job_id", jobID.String()).Msg("failed to cancel job")
 		respondError(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	if s.pipeline != nil {
+		s.pipeline.PublishCancel(jobID)
+	}
 
 	respondJSON(w, http.StatusOK, map[string]string{"status": "cancelled"})
 }
@@ -284,6 +411,9 @@ func (s *Server) retryJob(w http.ResponseWriter, r *http.Request) {
 		respondError(w, http.StatusBadRequest, "invalid job ID")
 		return
 	}
+	if !s.authorizeJobID(w, r, jobID) {
+		return
+	}
 
 	if err := s.jobRepo.Retry(r.Context(), jobID); err != nil {
 		log.Error().Err(err).Str("job_id", jobID.String()).Msg("failed to retry job")
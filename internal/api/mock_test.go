@@ -100,6 +100,47 @@ func (m *MockJobRepository) ListByRepository(ctx context.Context, repoID uuid.UU
 	return result, nil
 }
 
+func (m *MockJobRepository) ListRecent(ctx context.Context, limit int) ([]*jobs.Job, error) {
+	if m.listErr != nil {
+		return nil, m.listErr
+	}
+	var result []*jobs.Job
+	for _, j := range m.jobs {
+		result = append(result, j)
+		if len(result) >= limit {
+			break
+		}
+	}
+	return result, nil
+}
+
+// ListForOrganizations finds no jobs: the mock doesn't know repositories'
+// organizations
+func (m *MockJobRepository) ListForOrganizations(ctx context.Context, orgIDs []uuid.UUID, status jobs.JobStatus, jobType jobs.JobType, limit int) ([]*jobs.Job, error) {
+	if m.listErr != nil {
+		return nil, m.listErr
+	}
+	return nil, nil
+}
+
+// ListOutsideOrganizations finds every job matching the filters: the mock
+// doesn't know repositories' organizations, so all of them are outside one
+func (m *MockJobRepository) ListOutsideOrganizations(ctx context.Context, status jobs.JobStatus, jobType jobs.JobType, limit int) ([]*jobs.Job, error) {
+	if m.listErr != nil {
+		return nil, m.listErr
+	}
+	var result []*jobs.Job
+	for _, j := range m.jobs {
+		if (status == "" || j.Status == status) && (jobType == "" || j.Type == jobType) {
+			result = append(result, j)
+			if len(result) >= limit {
+				break
+			}
+		}
+	}
+	return result, nil
+}
+
 func (m *MockJobRepository) Cancel(ctx context.Context, jobID uuid.UUID) error {
 	job, ok := m.jobs[jobID]
 	if !ok {
@@ -95,6 +95,13 @@ func (s *Server) createMutationRun(w http.ResponseWriter, r *http.Request) {
 		}
 		runID = &id
 	}
+	if repoID == nil && principal(r) != nil {
+		respondError(w, http.StatusBadRequest, "repository_id is required")
+		return
+	}
+	if repoID != nil && !s.authorizeRepo(w, r, *repoID, "repository not found") {
+		return
+	}
 
 	// Create mutation job payload
 	payload := jobs.MutationPayload{
@@ -173,6 +180,9 @@ func (s *Server) getMutationRun(w http.ResponseWriter, r *http.Request) {
 		respondError(w, http.StatusNotFound, "not a mutation job")
 		return
 	}
+	if !s.authorizeJob(w, r, job, "mutation run not found") {
+		return
+	}
 
 	resp := mutationJobToResponse(job)
 	respondJSON(w, http.StatusOK, resp)
@@ -195,32 +205,14 @@ func (s *Server) listMutationRuns(w http.ResponseWriter, r *http.Request) {
 	var jobList []*jobs.Job
 	var err error
 
-	if status != "" {
-		// Get mutation jobs with specific status
-		allJobs, err := s.jobRepo.ListByStatus(r.Context(), jobs.JobStatus(status), limit*2)
+	if p := principal(r); p != nil {
+		var orgIDs []uuid.UUID
+		orgIDs, err = s.principalOrgs(r.Context(), p)
 		if err == nil {
-			for _, j := range allJobs {
-				if j.Type == jobs.JobTypeMutation {
-					jobList = append(jobList, j)
-					if len(jobList) >= limit {
-						break
-					}
-				}
-			}
+			jobList, err = s.jobRepo.ListForOrganizations(r.Context(), orgIDs, jobs.JobStatus(status), jobs.JobTypeMutation, limit)
 		}
 	} else {
-		// Get all recent jobs and filter to mutation jobs
-		allJobs, err := s.jobRepo.ListRecent(r.Context(), limit*5)
-		if err == nil {
-			for _, j := range allJobs {
-				if j.Type == jobs.JobTypeMutation {
-					jobList = append(jobList, j)
-					if len(jobList) >= limit {
-						break
-					}
-				}
-			}
-		}
+		jobList, err = s.jobRepo.ListOutsideOrganizations(r.Context(), jobs.JobStatus(status), jobs.JobTypeMutation, limit)
 	}
 
 	if err != nil {
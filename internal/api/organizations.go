@@ -3,6 +3,7 @@ package api
 import (
 	"encoding/json"
 	"net/http"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
@@ -10,6 +11,7 @@ import (
 
 	"github.com/QTest-hq/qtest/internal/auth"
 	"github.com/QTest-hq/qtest/internal/db"
+	"github.com/QTest-hq/qtest/internal/jobs"
 )
 
 // OrganizationHandlers handles organization-related API endpoints
@@ -46,16 +48,28 @@ type UpdateMemberRoleRequest struct {
 	Role db.MemberRole `json:"role"`
 }
 
+// CreateAPIKeyRequest is the request body for creating an API key
+type CreateAPIKeyRequest struct {
+	Name          string   `json:"name"`
+	Scopes        []string `json:"scopes,omitempty"`          // read and write when empty
+	ExpiresInDays int      `json:"expires_in_days,omitempty"` // never expires when zero
+}
+
+// CreateAPIKeyResponse is a new API key; the key itself is only shown here
+type CreateAPIKeyResponse struct {
+	db.APIKey
+	Key string `json:"key"`
+}
+
 // ListOrganizations returns all organizations the user belongs to
 // GET /api/v1/organizations
 func (h *OrganizationHandlers) ListOrganizations(w http.ResponseWriter, r *http.Request) {
-	session, ok := auth.GetSessionFromContext(r.Context())
+	user, ok := orgUser(w, r)
 	if !ok {
-		writeError(w, http.StatusUnauthorized, "authentication required")
 		return
 	}
 
-	orgs, err := h.store.ListUserOrganizations(r.Context(), session.UserID)
+	orgs, err := h.store.ListUserOrganizations(r.Context(), user.UserID)
 	if err != nil {
 		log.Error().Err(err).Msg("failed to list organizations")
 		writeError(w, http.StatusInternalServerError, "failed to list organizations")
@@ -68,9 +82,8 @@ func (h *OrganizationHandlers) ListOrganizations(w http.ResponseWriter, r *http.
 // GetOrganization returns a single organization
 // GET /api/v1/organizations/{orgID}
 func (h *OrganizationHandlers) GetOrganization(w http.ResponseWriter, r *http.Request) {
-	session, ok := auth.GetSessionFromContext(r.Context())
+	user, ok := orgUser(w, r)
 	if !ok {
-		writeError(w, http.StatusUnauthorized, "authentication required")
 		return
 	}
 
@@ -81,7 +94,7 @@ func (h *OrganizationHandlers) GetOrganization(w http.ResponseWriter, r *http.Re
 	}
 
 	// Check membership
-	isMember, err := h.store.IsMember(r.Context(), orgID, session.UserID)
+	isMember, err := h.store.IsMember(r.Context(), orgID, user.UserID)
 	if err != nil {
 		log.Error().Err(err).Msg("failed to check membership")
 		writeError(w, http.StatusInternalServerError, "internal error")
@@ -104,7 +117,7 @@ func (h *OrganizationHandlers) GetOrganization(w http.ResponseWriter, r *http.Re
 	}
 
 	// Get user's role
-	role, _ := h.store.GetMemberRole(r.Context(), orgID, session.UserID)
+	role, _ := h.store.GetMemberRole(r.Context(), orgID, user.UserID)
 
 	writeJSON(w, http.StatusOK, map[string]interface{}{
 		"organization": org,
@@ -115,9 +128,8 @@ func (h *OrganizationHandlers) GetOrganization(w http.ResponseWriter, r *http.Re
 // CreateOrganization creates a new organization
 // POST /api/v1/organizations
 func (h *OrganizationHandlers) CreateOrganization(w http.ResponseWriter, r *http.Request) {
-	session, ok := auth.GetSessionFromContext(r.Context())
+	user, ok := orgUser(w, r)
 	if !ok {
-		writeError(w, http.StatusUnauthorized, "authentication required")
 		return
 	}
 
@@ -148,7 +160,7 @@ func (h *OrganizationHandlers) CreateOrganization(w http.ResponseWriter, r *http
 		Name:        req.Name,
 		Slug:        req.Slug,
 		Description: req.Description,
-		OwnerID:     session.UserID,
+		OwnerID:     user.UserID,
 		IsPersonal:  false,
 	}
 
@@ -161,7 +173,7 @@ func (h *OrganizationHandlers) CreateOrganization(w http.ResponseWriter, r *http
 	log.Info().
 		Str("org_id", org.ID.String()).
 		Str("slug", org.Slug).
-		Str("owner", session.UserID.String()).
+		Str("owner", user.UserID.String()).
 		Msg("organization created")
 
 	writeJSON(w, http.StatusCreated, org)
@@ -170,9 +182,8 @@ func (h *OrganizationHandlers) CreateOrganization(w http.ResponseWriter, r *http
 // UpdateOrganization updates an organization
 // PATCH /api/v1/organizations/{orgID}
 func (h *OrganizationHandlers) UpdateOrganization(w http.ResponseWriter, r *http.Request) {
-	session, ok := auth.GetSessionFromContext(r.Context())
+	user, ok := orgUser(w, r)
 	if !ok {
-		writeError(w, http.StatusUnauthorized, "authentication required")
 		return
 	}
 
@@ -183,7 +194,7 @@ func (h *OrganizationHandlers) UpdateOrganization(w http.ResponseWriter, r *http
 	}
 
 	// Check admin permission
-	canManage, err := h.store.CanManageOrg(r.Context(), orgID, session.UserID)
+	canManage, err := h.store.CanManageOrg(r.Context(), orgID, user.UserID)
 	if err != nil {
 		log.Error().Err(err).Msg("failed to check permissions")
 		writeError(w, http.StatusInternalServerError, "internal error")
@@ -225,9 +236,8 @@ func (h *OrganizationHandlers) UpdateOrganization(w http.ResponseWriter, r *http
 // DeleteOrganization deletes an organization
 // DELETE /api/v1/organizations/{orgID}
 func (h *OrganizationHandlers) DeleteOrganization(w http.ResponseWriter, r *http.Request) {
-	session, ok := auth.GetSessionFromContext(r.Context())
+	user, ok := orgUser(w, r)
 	if !ok {
-		writeError(w, http.StatusUnauthorized, "authentication required")
 		return
 	}
 
@@ -244,7 +254,7 @@ func (h *OrganizationHandlers) DeleteOrganization(w http.ResponseWriter, r *http
 	}
 
 	// Only owner can delete
-	if org.OwnerID != session.UserID {
+	if org.OwnerID != user.UserID {
 		writeError(w, http.StatusForbidden, "only the owner can delete the organization")
 		return
 	}
@@ -270,9 +280,8 @@ func (h *OrganizationHandlers) DeleteOrganization(w http.ResponseWriter, r *http
 // ListMembers returns all members of an organization
 // GET /api/v1/organizations/{orgID}/members
 func (h *OrganizationHandlers) ListMembers(w http.ResponseWriter, r *http.Request) {
-	session, ok := auth.GetSessionFromContext(r.Context())
+	user, ok := orgUser(w, r)
 	if !ok {
-		writeError(w, http.StatusUnauthorized, "authentication required")
 		return
 	}
 
@@ -283,7 +292,7 @@ func (h *OrganizationHandlers) ListMembers(w http.ResponseWriter, r *http.Reques
 	}
 
 	// Check membership
-	isMember, err := h.store.IsMember(r.Context(), orgID, session.UserID)
+	isMember, err := h.store.IsMember(r.Context(), orgID, user.UserID)
 	if err != nil {
 		log.Error().Err(err).Msg("failed to check membership")
 		writeError(w, http.StatusInternalServerError, "internal error")
@@ -307,9 +316,8 @@ func (h *OrganizationHandlers) ListMembers(w http.ResponseWriter, r *http.Reques
 // AddMember adds a user to an organization
 // POST /api/v1/organizations/{orgID}/members
 func (h *OrganizationHandlers) AddMember(w http.ResponseWriter, r *http.Request) {
-	session, ok := auth.GetSessionFromContext(r.Context())
+	user, ok := orgUser(w, r)
 	if !ok {
-		writeError(w, http.StatusUnauthorized, "authentication required")
 		return
 	}
 
@@ -320,7 +328,7 @@ func (h *OrganizationHandlers) AddMember(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Check admin permission
-	canManage, err := h.store.CanManageOrg(r.Context(), orgID, session.UserID)
+	canManage, err := h.store.CanManageOrg(r.Context(), orgID, user.UserID)
 	if err != nil {
 		log.Error().Err(err).Msg("failed to check permissions")
 		writeError(w, http.StatusInternalServerError, "internal error")
@@ -349,7 +357,7 @@ func (h *OrganizationHandlers) AddMember(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	if err := h.store.AddOrganizationMember(r.Context(), orgID, userID, req.Role, &session.UserID); err != nil {
+	if err := h.store.AddOrganizationMember(r.Context(), orgID, userID, req.Role, &user.UserID); err != nil {
 		log.Error().Err(err).Msg("failed to add member")
 		writeError(w, http.StatusInternalServerError, "failed to add member")
 		return
@@ -367,9 +375,8 @@ func (h *OrganizationHandlers) AddMember(w http.ResponseWriter, r *http.Request)
 // UpdateMemberRole updates a member's role
 // PATCH /api/v1/organizations/{orgID}/members/{userID}
 func (h *OrganizationHandlers) UpdateMemberRole(w http.ResponseWriter, r *http.Request) {
-	session, ok := auth.GetSessionFromContext(r.Context())
+	user, ok := orgUser(w, r)
 	if !ok {
-		writeError(w, http.StatusUnauthorized, "authentication required")
 		return
 	}
 
@@ -386,7 +393,7 @@ func (h *OrganizationHandlers) UpdateMemberRole(w http.ResponseWriter, r *http.R
 	}
 
 	// Check admin permission
-	canManage, err := h.store.CanManageOrg(r.Context(), orgID, session.UserID)
+	canManage, err := h.store.CanManageOrg(r.Context(), orgID, user.UserID)
 	if err != nil {
 		log.Error().Err(err).Msg("failed to check permissions")
 		writeError(w, http.StatusInternalServerError, "internal error")
@@ -422,9 +429,8 @@ func (h *OrganizationHandlers) UpdateMemberRole(w http.ResponseWriter, r *http.R
 // RemoveMember removes a user from an organization
 // DELETE /api/v1/organizations/{orgID}/members/{userID}
 func (h *OrganizationHandlers) RemoveMember(w http.ResponseWriter, r *http.Request) {
-	session, ok := auth.GetSessionFromContext(r.Context())
+	user, ok := orgUser(w, r)
 	if !ok {
-		writeError(w, http.StatusUnauthorized, "authentication required")
 		return
 	}
 
@@ -441,8 +447,8 @@ func (h *OrganizationHandlers) RemoveMember(w http.ResponseWriter, r *http.Reque
 	}
 
 	// Users can remove themselves, admins can remove others
-	if userID != session.UserID {
-		canManage, err := h.store.CanManageOrg(r.Context(), orgID, session.UserID)
+	if userID != user.UserID {
+		canManage, err := h.store.CanManageOrg(r.Context(), orgID, user.UserID)
 		if err != nil {
 			log.Error().Err(err).Msg("failed to check permissions")
 			writeError(w, http.StatusInternalServerError, "internal error")
@@ -475,6 +481,260 @@ func (h *OrganizationHandlers) RemoveMember(w http.ResponseWriter, r *http.Reque
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// GetPolicy returns the default pipeline policy of an organization's
+// repositories
+// GET /api/v1/organizations/{orgID}/policy
+func (h *OrganizationHandlers) GetPolicy(w http.ResponseWriter, r *http.Request) {
+	user, ok := orgUser(w, r)
+	if !ok {
+		return
+	}
+
+	orgID, err := uuid.Parse(chi.URLParam(r, "orgID"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid organization ID")
+		return
+	}
+
+	// Check membership
+	isMember, err := h.store.IsMember(r.Context(), orgID, user.UserID)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to check membership")
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	if !isMember {
+		writeError(w, http.StatusForbidden, "not a member of this organization")
+		return
+	}
+
+	stored, err := h.store.GetOrganizationPolicy(r.Context(), orgID)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to get organization policy")
+		writeError(w, http.StatusInternalServerError, "failed to get policy")
+		return
+	}
+	if stored == nil {
+		writeError(w, http.StatusNotFound, "organization not found")
+		return
+	}
+
+	policy, err := jobs.ParsePolicy(stored)
+	if err != nil {
+		log.Error().Err(err).Str("org_id", orgID.String()).Msg("stored policy is invalid")
+		writeError(w, http.StatusInternalServerError, "failed to get policy")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, policy)
+}
+
+// UpdatePolicy replaces the default pipeline policy of an organization's
+// repositories
+// PUT /api/v1/organizations/{orgID}/policy
+func (h *OrganizationHandlers) UpdatePolicy(w http.ResponseWriter, r *http.Request) {
+	user, ok := orgUser(w, r)
+	if !ok {
+		return
+	}
+
+	orgID, err := uuid.Parse(chi.URLParam(r, "orgID"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid organization ID")
+		return
+	}
+
+	// Check admin permission
+	canManage, err := h.store.CanManageOrg(r.Context(), orgID, user.UserID)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to check permissions")
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	if !canManage {
+		writeError(w, http.StatusForbidden, "insufficient permissions")
+		return
+	}
+
+	data, err := decodePolicy(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.store.UpdateOrganizationPolicy(r.Context(), orgID, data); err != nil {
+		log.Error().Err(err).Msg("failed to update organization policy")
+		writeError(w, http.StatusInternalServerError, "failed to update policy")
+		return
+	}
+
+	log.Info().
+		Str("org_id", orgID.String()).
+		RawJSON("policy", data).
+		Msg("organization policy updated")
+
+	writeJSON(w, http.StatusOK, json.RawMessage(data))
+}
+
+// ListAPIKeys returns an organization's active API keys, without the keys
+// GET /api/v1/organizations/{orgID}/api-keys
+func (h *OrganizationHandlers) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := h.manageOrg(w, r)
+	if !ok {
+		return
+	}
+
+	keys, err := h.store.ListAPIKeys(r.Context(), orgID)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to list api keys")
+		writeError(w, http.StatusInternalServerError, "failed to list api keys")
+		return
+	}
+	if keys == nil {
+		keys = []db.APIKey{}
+	}
+
+	writeJSON(w, http.StatusOK, keys)
+}
+
+// CreateAPIKey creates an API key acting for the caller in an organization
+// POST /api/v1/organizations/{orgID}/api-keys
+func (h *OrganizationHandlers) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := h.manageOrg(w, r)
+	if !ok {
+		return
+	}
+	user, _ := auth.GetPrincipalFromContext(r.Context())
+
+	var req CreateAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.Name == "" {
+		writeError(w, http.StatusBadRequest, "name is required")
+		return
+	}
+	for _, scope := range req.Scopes {
+		if scope != db.ScopeRead && scope != db.ScopeWrite {
+			writeError(w, http.StatusBadRequest, "scopes must be read or write")
+			return
+		}
+	}
+	if req.ExpiresInDays < 0 {
+		writeError(w, http.StatusBadRequest, "expires_in_days must be positive")
+		return
+	}
+
+	key, prefix, hash, err := auth.GenerateAPIKey()
+	if err != nil {
+		log.Error().Err(err).Msg("failed to generate api key")
+		writeError(w, http.StatusInternalServerError, "failed to create api key")
+		return
+	}
+	k := db.APIKey{
+		OrganizationID: orgID,
+		UserID:         user.UserID,
+		Name:           req.Name,
+		KeyPrefix:      prefix,
+		KeyHash:        hash,
+		Scopes:         req.Scopes,
+	}
+	if req.ExpiresInDays > 0 {
+		expires := time.Now().AddDate(0, 0, req.ExpiresInDays)
+		k.ExpiresAt = &expires
+	}
+	if err := h.store.CreateAPIKey(r.Context(), &k); err != nil {
+		log.Error().Err(err).Msg("failed to create api key")
+		writeError(w, http.StatusInternalServerError, "failed to create api key")
+		return
+	}
+
+	log.Info().
+		Str("org_id", orgID.String()).
+		Str("key", prefix).
+		Strs("scopes", k.Scopes).
+		Msg("api key created")
+
+	writeJSON(w, http.StatusCreated, CreateAPIKeyResponse{APIKey: k, Key: key})
+}
+
+// RevokeAPIKey revokes an organization's API key
+// DELETE /api/v1/organizations/{orgID}/api-keys/{keyID}
+func (h *OrganizationHandlers) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := h.manageOrg(w, r)
+	if !ok {
+		return
+	}
+
+	keyID, err := uuid.Parse(chi.URLParam(r, "keyID"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid key ID")
+		return
+	}
+
+	revoked, err := h.store.RevokeAPIKey(r.Context(), orgID, keyID)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to revoke api key")
+		writeError(w, http.StatusInternalServerError, "failed to revoke api key")
+		return
+	}
+	if !revoked {
+		writeError(w, http.StatusNotFound, "api key not found")
+		return
+	}
+
+	log.Info().
+		Str("org_id", orgID.String()).
+		Str("key_id", keyID.String()).
+		Msg("api key revoked")
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// manageOrg returns the organization in the URL, responding with an error
+// unless the caller is one of its owners or admins
+func (h *OrganizationHandlers) manageOrg(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	user, ok := orgUser(w, r)
+	if !ok {
+		return uuid.Nil, false
+	}
+
+	orgID, err := uuid.Parse(chi.URLParam(r, "orgID"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid organization ID")
+		return uuid.Nil, false
+	}
+
+	canManage, err := h.store.CanManageOrg(r.Context(), orgID, user.UserID)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to check permissions")
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return uuid.Nil, false
+	}
+	if !canManage {
+		writeError(w, http.StatusForbidden, "insufficient permissions")
+		return uuid.Nil, false
+	}
+	return orgID, true
+}
+
+// orgUser returns the user a request acts for, responding with an error
+// when there's none. API keys act in their organization and can't manage
+// organizations.
+func orgUser(w http.ResponseWriter, r *http.Request) (*auth.Principal, bool) {
+	p, ok := auth.GetPrincipalFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "authentication required")
+		return nil, false
+	}
+	if p.Method == auth.MethodAPIKey {
+		writeError(w, http.StatusForbidden, "api keys can't manage organizations")
+		return nil, false
+	}
+	return p, true
+}
+
 // Helper functions
 func writeJSON(w http.ResponseWriter, status int, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
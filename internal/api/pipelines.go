@@ -0,0 +1,461 @@
+package api
+
+import (
+	"archive/zip"
+	"fmt"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	"github.com/QTest-hq/qtest/internal/db"
+	"github.com/QTest-hq/qtest/internal/jobs"
+)
+
+// Statuses of a pipeline's targets
+const (
+	TargetPending   = "pending"   // generation hasn't reached its file
+	TargetGenerated = "generated" // a test was generated for it
+	TargetFailed    = "failed"    // generation failed
+	TargetSkipped   = "skipped"   // its file was done without a test for it
+)
+
+// PipelineResponse is the API response for a pipeline, the chain of jobs
+// started from one repository URL
+type PipelineResponse struct {
+	ID              uuid.UUID        `json:"id"` // the root (ingestion) job's ID
+	RepositoryURL   string           `json:"repository_url"`
+	Status          string           `json:"status"` // pending, running, completed, failed or cancelled
+	Stage           string           `json:"stage"`  // type of the latest job
+	RepositoryID    *uuid.UUID       `json:"repository_id,omitempty"`
+	GenerationRunID *uuid.UUID       `json:"generation_run_id,omitempty"`
+	Progress        PipelineProgress `json:"progress"`
+	PRURL           string           `json:"pr_url,omitempty"`
+	Error           *string          `json:"error,omitempty"` // of the first failed job
+	CreatedAt       string           `json:"created_at"`
+	UpdatedAt       string           `json:"updated_at"`
+	Jobs            []*JobResponse   `json:"jobs"`
+}
+
+// PipelineProgress counts a pipeline's jobs, targets and tests
+type PipelineProgress struct {
+	JobsTotal        int `json:"jobs_total"`
+	JobsCompleted    int `json:"jobs_completed"`
+	TargetsTotal     int `json:"targets_total"`
+	TargetsGenerated int `json:"targets_generated"`
+	TargetsFailed    int `json:"targets_failed"`
+	TestsGenerated   int `json:"tests_generated"`
+}
+
+// TargetProgress is the generation status of one planned target
+type TargetProgress struct {
+	IntentID string `json:"intent_id"`
+	Level    string `json:"level"`
+	Priority string `json:"priority"`
+	File     string `json:"file"`
+	Function string `json:"function"`
+	Endpoint string `json:"endpoint,omitempty"`
+	Status   string `json:"status"` // pending, generated, failed or skipped
+}
+
+// PipelineTargetsResponse lists a pipeline's targets in plan order
+type PipelineTargetsResponse struct {
+	PipelineID uuid.UUID        `json:"pipeline_id"`
+	Status     string           `json:"status"`
+	Targets    []TargetProgress `json:"targets"`
+}
+
+// PipelineFile is a test file a pipeline generated
+type PipelineFile struct {
+	Path        string `json:"path"` // relative to the repository root
+	Framework   string `json:"framework,omitempty"`
+	Tests       int    `json:"tests"`
+	Size        int    `json:"size"`
+	DownloadURL string `json:"download_url"`
+}
+
+// PipelineFilesResponse lists the test files a pipeline generated
+type PipelineFilesResponse struct {
+	PipelineID uuid.UUID      `json:"pipeline_id"`
+	Status     string         `json:"status"`
+	Files      []PipelineFile `json:"files"`
+	ArchiveURL string         `json:"archive_url"`
+}
+
+// submitPipeline starts a pipeline for a repository URL. It takes the body
+// of POST /jobs/pipeline and answers with the pipeline to poll.
+func (s *Server) submitPipeline(w http.ResponseWriter, r *http.Request) {
+	job, ok := s.launchPipeline(w, r)
+	if !ok {
+		return
+	}
+
+	resp := pipelineToResponse(&jobs.PipelineReport{
+		Root:   job,
+		Jobs:   []*jobs.Job{job},
+		Status: job.Status,
+		Stage:  job.Type,
+	})
+	w.Header().Set("Location", pipelinePath(job.ID))
+	respondJSON(w, http.StatusAccepted, resp)
+}
+
+// getPipeline reports a pipeline's status, for polling
+func (s *Server) getPipeline(w http.ResponseWriter, r *http.Request) {
+	report, ok := s.loadPipeline(w, r)
+	if !ok {
+		return
+	}
+	respondJSON(w, http.StatusOK, pipelineToResponse(report))
+}
+
+// getPipelineTargets lists the generation status of each planned target
+func (s *Server) getPipelineTargets(w http.ResponseWriter, r *http.Request) {
+	report, ok := s.loadPipeline(w, r)
+	if !ok {
+		return
+	}
+
+	plan, gen, genJob := pipelineResults(report.Jobs)
+	resp := PipelineTargetsResponse{
+		PipelineID: report.Root.ID,
+		Status:     string(report.Status),
+		Targets:    targetProgress(plan, gen, genJob),
+	}
+	respondJSON(w, http.StatusOK, resp)
+}
+
+// listPipelineFiles lists the test files a pipeline generated
+func (s *Server) listPipelineFiles(w http.ResponseWriter, r *http.Request) {
+	report, files, ok := s.loadPipelineFiles(w, r)
+	if !ok {
+		return
+	}
+
+	resp := PipelineFilesResponse{
+		PipelineID: report.Root.ID,
+		Status:     string(report.Status),
+		Files:      make([]PipelineFile, 0, len(files)),
+		ArchiveURL: pipelinePath(report.Root.ID) + "/archive",
+	}
+	for _, f := range files {
+		resp.Files = append(resp.Files, PipelineFile{
+			Path:        f.path,
+			Framework:   f.framework,
+			Tests:       f.tests,
+			Size:        len(f.content),
+			DownloadURL: pipelinePath(report.Root.ID) + "/files/" + f.path,
+		})
+	}
+	respondJSON(w, http.StatusOK, resp)
+}
+
+// downloadPipelineFile serves one generated test file
+func (s *Server) downloadPipelineFile(w http.ResponseWriter, r *http.Request) {
+	_, files, ok := s.loadPipelineFiles(w, r)
+	if !ok {
+		return
+	}
+
+	want := path.Clean(strings.TrimPrefix(chi.URLParam(r, "*"), "/"))
+	for _, f := range files {
+		if f.path == want {
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", path.Base(f.path)))
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(f.content))
+			return
+		}
+	}
+	respondError(w, http.StatusNotFound, "file not found")
+}
+
+// downloadPipelineArchive serves every generated test file in a zip
+// archive, at their paths in the repository
+func (s *Server) downloadPipelineArchive(w http.ResponseWriter, r *http.Request) {
+	report, files, ok := s.loadPipelineFiles(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "qtest-"+report.Root.ID.String()[:8]+".zip"))
+	w.WriteHeader(http.StatusOK)
+
+	archive := zip.NewWriter(w)
+	for _, f := range files {
+		fw, err := archive.Create(f.path)
+		if err != nil {
+			log.Error().Err(err).Str("file", f.path).Msg("failed to add file to archive")
+			return
+		}
+		if _, err := fw.Write([]byte(f.content)); err != nil {
+			log.Error().Err(err).Str("file", f.path).Msg("failed to add file to archive")
+			return
+		}
+	}
+	if err := archive.Close(); err != nil {
+		log.Error().Err(err).Msg("failed to write archive")
+	}
+}
+
+// cancelPipeline cancels a pipeline's pending and running jobs. Workers
+// running them are told to stop.
+func (s *Server) cancelPipeline(w http.ResponseWriter, r *http.Request) {
+	report, ok := s.loadPipeline(w, r)
+	if !ok {
+		return
+	}
+
+	switch report.Status {
+	case jobs.StatusCompleted, jobs.StatusFailed, jobs.StatusCancelled:
+		respondError(w, http.StatusConflict, fmt.Sprintf("pipeline already %s", report.Status))
+		return
+	}
+
+	if _, err := s.pipeline.CancelPipeline(r.Context(), report.Root.ID); err != nil {
+		log.Error().Err(err).Str("pipeline_id", report.Root.ID.String()).Msg("failed to cancel pipeline")
+		respondError(w, http.StatusInternalServerError, "failed to cancel pipeline")
+		return
+	}
+
+	report, err := s.pipeline.GetPipeline(r.Context(), report.Root.ID)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to reload pipeline")
+		respondError(w, http.StatusInternalServerError, "failed to reload pipeline")
+		return
+	}
+	respondJSON(w, http.StatusOK, pipelineToResponse(report))
+}
+
+// loadPipeline loads the pipeline named in the URL, responding with an
+// error when it can't
+func (s *Server) loadPipeline(w http.ResponseWriter, r *http.Request) (*jobs.PipelineReport, bool) {
+	if s.pipeline == nil {
+		respondError(w, http.StatusServiceUnavailable, "job system not available")
+		return nil, false
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "pipelineID"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid pipeline ID")
+		return nil, false
+	}
+
+	report, err := s.pipeline.GetPipeline(r.Context(), id)
+	if err != nil {
+		respondError(w, http.StatusNotFound, "pipeline not found")
+		return nil, false
+	}
+	if !s.authorizeJob(w, r, report.Root, "pipeline not found") {
+		return nil, false
+	}
+	return report, true
+}
+
+// generatedFile is the latest content of a generated test file
+type generatedFile struct {
+	path      string
+	framework string
+	content   string
+	tests     int
+}
+
+// loadPipelineFiles loads the pipeline named in the URL and the test files
+// its generation run wrote
+func (s *Server) loadPipelineFiles(w http.ResponseWriter, r *http.Request) (*jobs.PipelineReport, []generatedFile, bool) {
+	report, ok := s.loadPipeline(w, r)
+	if !ok {
+		return nil, nil, false
+	}
+	if s.store == nil {
+		respondError(w, http.StatusServiceUnavailable, "database not available")
+		return nil, nil, false
+	}
+
+	runID := pipelineRunID(report.Jobs)
+	if runID == nil {
+		return report, nil, true
+	}
+	tests, err := s.store.ListTestsByRun(r.Context(), *runID)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to list tests")
+		respondError(w, http.StatusInternalServerError, "failed to list tests")
+		return nil, nil, false
+	}
+	return report, generatedFiles(tests), true
+}
+
+// generatedFiles returns the files a run's tests were written to, sorted by
+// path, each with the content written for its latest test
+func generatedFiles(tests []db.GeneratedTest) []generatedFile {
+	byPath := make(map[string]*generatedFile)
+	for _, t := range tests {
+		if t.TestFile == nil || t.GeneratedCode == nil {
+			continue // rejected, nothing was kept
+		}
+		p := path.Clean(strings.ReplaceAll(*t.TestFile, "\\", "/"))
+		f, ok := byPath[p]
+		if !ok {
+			f = &generatedFile{path: p}
+			byPath[p] = f
+		}
+		f.content = *t.GeneratedCode // tests come oldest first
+		f.tests++
+		if t.Framework != nil {
+			f.framework = *t.Framework
+		}
+	}
+
+	files := make([]generatedFile, 0, len(byPath))
+	for _, f := range byPath {
+		files = append(files, *f)
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].path < files[j].path })
+	return files
+}
+
+// pipelineToResponse converts a pipeline to API response format
+func pipelineToResponse(report *jobs.PipelineReport) *PipelineResponse {
+	root := report.Root
+	resp := &PipelineResponse{
+		ID:              root.ID,
+		Status:          string(report.Status),
+		Stage:           string(report.Stage),
+		RepositoryID:    root.RepositoryID,
+		GenerationRunID: pipelineRunID(report.Jobs),
+		CreatedAt:       root.CreatedAt.Format("2006-01-02T15:04:05Z"),
+		UpdatedAt:       root.UpdatedAt.Format("2006-01-02T15:04:05Z"),
+		Jobs:            make([]*JobResponse, len(report.Jobs)),
+	}
+
+	var payload jobs.IngestionPayload
+	if err := root.GetPayload(&payload); err == nil {
+		resp.RepositoryURL = payload.RepositoryURL
+	}
+
+	resp.Progress.JobsTotal = len(report.Jobs)
+	for i, j := range report.Jobs {
+		resp.Jobs[i] = jobToResponse(j)
+		if j.UpdatedAt.After(root.UpdatedAt) {
+			resp.UpdatedAt = j.UpdatedAt.Format("2006-01-02T15:04:05Z")
+		}
+		if resp.RepositoryID == nil {
+			resp.RepositoryID = j.RepositoryID
+		}
+		switch j.Status {
+		case jobs.StatusCompleted:
+			resp.Progress.JobsCompleted++
+		case jobs.StatusFailed:
+			if resp.Error == nil {
+				resp.Error = j.ErrorMessage
+			}
+		}
+		if j.Type == jobs.JobTypeIntegration {
+			var result jobs.IntegrationResult
+			if j.GetResult(&result) == nil && result.PRURL != "" {
+				resp.PRURL = result.PRURL
+			}
+		}
+	}
+
+	plan, gen, genJob := pipelineResults(report.Jobs)
+	for _, t := range targetProgress(plan, gen, genJob) {
+		switch t.Status {
+		case TargetGenerated:
+			resp.Progress.TargetsGenerated++
+		case TargetFailed:
+			resp.Progress.TargetsFailed++
+		}
+	}
+	resp.Progress.TargetsTotal = len(plan)
+	if gen != nil {
+		resp.Progress.TestsGenerated = gen.TestsGenerated
+	}
+
+	return resp
+}
+
+// pipelineResults returns the plan targets of a pipeline's planning job and
+// the (possibly checkpointed) result of its generation job, if it has them
+func pipelineResults(chain []*jobs.Job) ([]jobs.PlanTarget, *jobs.GenerationResult, *jobs.Job) {
+	var plan []jobs.PlanTarget
+	var gen *jobs.GenerationResult
+	var genJob *jobs.Job
+	for _, j := range chain {
+		switch j.Type {
+		case jobs.JobTypePlanning:
+			var result jobs.PlanningResult
+			if j.GetResult(&result) == nil {
+				plan = result.Targets
+			}
+		case jobs.JobTypeGeneration:
+			var result jobs.GenerationResult
+			if j.GetResult(&result) == nil {
+				gen, genJob = &result, j
+			}
+		}
+	}
+	return plan, gen, genJob
+}
+
+// targetProgress returns each planned target's generation status. Running
+// generation jobs checkpoint their progress after each source file.
+func targetProgress(plan []jobs.PlanTarget, gen *jobs.GenerationResult, genJob *jobs.Job) []TargetProgress {
+	covered := make(map[string]bool)
+	failed := make(map[string]bool)
+	done := make(map[string]bool)
+	if gen != nil {
+		for _, id := range gen.CoveredIntents {
+			covered[id] = true
+		}
+		for _, id := range gen.FailedIntents {
+			failed[id] = true
+		}
+		for _, f := range gen.CompletedFiles {
+			done[f] = true
+		}
+	}
+	finished := genJob != nil && (genJob.Status == jobs.StatusCompleted || genJob.Status == jobs.StatusFailed)
+
+	targets := make([]TargetProgress, 0, len(plan))
+	for _, t := range plan {
+		status := TargetPending
+		switch {
+		case covered[t.IntentID]:
+			status = TargetGenerated
+		case failed[t.IntentID]:
+			status = TargetFailed
+		case finished || done[t.File]:
+			status = TargetSkipped
+		}
+		targets = append(targets, TargetProgress{
+			IntentID: t.IntentID,
+			Level:    t.Level,
+			Priority: t.Priority,
+			File:     t.File,
+			Function: t.Function,
+			Endpoint: t.Endpoint,
+			Status:   status,
+		})
+	}
+	return targets
+}
+
+// pipelineRunID returns the generation run of a pipeline, once it has one
+func pipelineRunID(chain []*jobs.Job) *uuid.UUID {
+	for _, j := range chain {
+		if j.GenerationRunID != nil {
+			return j.GenerationRunID
+		}
+	}
+	return nil
+}
+
+func pipelinePath(id uuid.UUID) string {
+	return "/api/v1/pipelines/" + id.String()
+}
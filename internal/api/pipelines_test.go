@@ -0,0 +1,130 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/QTest-hq/qtest/internal/config"
+	"github.com/QTest-hq/qtest/internal/db"
+	"github.com/QTest-hq/qtest/internal/jobs"
+)
+
+func TestTargetProgress(t *testing.T) {
+	plan := []jobs.PlanTarget{
+		{IntentID: "i1", File: "a.go", Function: "A"},
+		{IntentID: "i2", File: "a.go", Function: "B"},
+		{IntentID: "i3", File: "b.go", Function: "C"},
+		{IntentID: "i4", File: "c.go", Function: "D"},
+	}
+	gen := &jobs.GenerationResult{
+		CoveredIntents: []string{"i1"},
+		FailedIntents:  []string{"i3"},
+		CompletedFiles: []string{"a.go", "b.go"},
+	}
+	running := &jobs.Job{Type: jobs.JobTypeGeneration, Status: jobs.StatusRunning}
+
+	got := targetProgress(plan, gen, running)
+	want := []string{TargetGenerated, TargetSkipped, TargetFailed, TargetPending}
+	for i, w := range want {
+		if got[i].Status != w {
+			t.Errorf("target %s status = %s, want %s", got[i].IntentID, got[i].Status, w)
+		}
+	}
+
+	// Once generation is finished, targets it didn't reach were skipped
+	running.Status = jobs.StatusCompleted
+	if got := targetProgress(plan, gen, running); got[3].Status != TargetSkipped {
+		t.Errorf("target i4 status = %s, want skipped", got[3].Status)
+	}
+
+	// Before generation starts, everything is pending
+	for _, tp := range targetProgress(plan, nil, nil) {
+		if tp.Status != TargetPending {
+			t.Errorf("target %s status = %s, want pending", tp.IntentID, tp.Status)
+		}
+	}
+}
+
+func TestGeneratedFiles(t *testing.T) {
+	tests := []db.GeneratedTest{
+		{TestFile: strPtr("pkg/b_test.go"), GeneratedCode: strPtr("v1"), Framework: strPtr("go")},
+		{TestFile: strPtr("pkg/a_test.go"), GeneratedCode: strPtr("a")},
+		{TestFile: strPtr("pkg/b_test.go"), GeneratedCode: strPtr("v2")},
+		{TestFile: strPtr("pkg/c_test.go")}, // rejected
+	}
+
+	files := generatedFiles(tests)
+	if len(files) != 2 {
+		t.Fatalf("generatedFiles() = %+v, want 2 files", files)
+	}
+	if files[0].path != "pkg/a_test.go" || files[1].path != "pkg/b_test.go" {
+		t.Errorf("files not sorted by path: %+v", files)
+	}
+	if files[1].content != "v2" || files[1].tests != 2 || files[1].framework != "go" {
+		t.Errorf("b_test.go = %+v, want its latest content and 2 tests", files[1])
+	}
+}
+
+func TestPipelineToResponse(t *testing.T) {
+	now := time.Now()
+	runID := uuid.New()
+	root, _ := jobs.NewJob(jobs.JobTypeIngestion, jobs.IngestionPayload{RepositoryURL: "https://github.com/o/r"})
+	root.Status = jobs.StatusCompleted
+	root.CreatedAt, root.UpdatedAt = now, now
+
+	planning, _ := jobs.NewJob(jobs.JobTypePlanning, nil)
+	planning.Status = jobs.StatusCompleted
+	planResult, _ := json.Marshal(jobs.PlanningResult{Targets: []jobs.PlanTarget{{IntentID: "i1"}, {IntentID: "i2"}}})
+	planning.Result = (*json.RawMessage)(&planResult)
+
+	generation, _ := jobs.NewJob(jobs.JobTypeGeneration, nil)
+	generation.Status = jobs.StatusRunning
+	generation.GenerationRunID = &runID
+	generation.UpdatedAt = now.Add(time.Minute)
+	genResult, _ := json.Marshal(jobs.GenerationResult{TestsGenerated: 3, CoveredIntents: []string{"i1"}})
+	generation.Result = (*json.RawMessage)(&genResult)
+
+	chain := []*jobs.Job{root, planning, generation}
+	status, stage := jobs.PipelineStatus(chain)
+	resp := pipelineToResponse(&jobs.PipelineReport{Root: root, Jobs: chain, Status: status, Stage: stage})
+
+	if resp.ID != root.ID || resp.RepositoryURL != "https://github.com/o/r" {
+		t.Errorf("resp = %+v", resp)
+	}
+	if resp.Status != "running" || resp.Stage != "generation" {
+		t.Errorf("Status, Stage = %s, %s, want running, generation", resp.Status, resp.Stage)
+	}
+	if resp.GenerationRunID == nil || *resp.GenerationRunID != runID {
+		t.Errorf("GenerationRunID = %v, want %v", resp.GenerationRunID, runID)
+	}
+	want := PipelineProgress{JobsTotal: 3, JobsCompleted: 2, TargetsTotal: 2, TargetsGenerated: 1, TestsGenerated: 3}
+	if resp.Progress != want {
+		t.Errorf("Progress = %+v, want %+v", resp.Progress, want)
+	}
+	if resp.UpdatedAt != generation.UpdatedAt.Format("2006-01-02T15:04:05Z") {
+		t.Errorf("UpdatedAt = %s, want the latest job's", resp.UpdatedAt)
+	}
+}
+
+func TestPipelineRoutes_NoJobSystem(t *testing.T) {
+	s := &Server{cfg: &config.Config{}, router: chi.NewRouter()}
+	s.setupRoutes()
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/api/v1/pipelines/"+uuid.NewString(), nil),
+		httptest.NewRequest(http.MethodGet, "/api/v1/pipelines/"+uuid.NewString()+"/targets", nil),
+		httptest.NewRequest(http.MethodPost, "/api/v1/pipelines/"+uuid.NewString()+"/cancel", nil),
+	} {
+		rec := httptest.NewRecorder()
+		s.router.ServeHTTP(rec, req)
+		if rec.Code != http.StatusServiceUnavailable {
+			t.Errorf("%s %s = %d, want 503", req.Method, req.URL.Path, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	"github.com/QTest-hq/qtest/internal/db"
+	"github.com/QTest-hq/qtest/internal/jobs"
+)
+
+// PolicyResponse is a repository's pipeline policy and where it comes from
+type PolicyResponse struct {
+	RepositoryID   uuid.UUID    `json:"repository_id"`
+	OrganizationID *uuid.UUID   `json:"organization_id,omitempty"`
+	Organization   *jobs.Policy `json:"organization,omitempty"` // inherited defaults
+	Repository     *jobs.Policy `json:"repository"`             // the repository's overrides
+	Effective      *jobs.Policy `json:"effective"`
+}
+
+// getRepoPolicy returns a repository's policy, with the organization
+// defaults it inherits:
+//
+//	GET /repos/{repoID}/policy
+func (s *Server) getRepoPolicy(w http.ResponseWriter, r *http.Request) {
+	if s.store == nil {
+		respondError(w, http.StatusServiceUnavailable, "database not available")
+		return
+	}
+
+	repoID, err := uuid.Parse(chi.URLParam(r, "repoID"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid repo ID")
+		return
+	}
+
+	s.respondPolicy(w, r, repoID)
+}
+
+// updateRepoPolicy replaces a repository's policy overrides; fields left
+// out are inherited from its organization:
+//
+//	PUT /repos/{repoID}/policy {"llm_tier": 3, "providers": ["ollama"]}
+func (s *Server) updateRepoPolicy(w http.ResponseWriter, r *http.Request) {
+	if s.store == nil {
+		respondError(w, http.StatusServiceUnavailable, "database not available")
+		return
+	}
+
+	repoID, err := uuid.Parse(chi.URLParam(r, "repoID"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid repo ID")
+		return
+	}
+
+	data, err := decodePolicy(r.Body)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	repo, err := s.store.GetRepository(r.Context(), repoID)
+	if err != nil || repo == nil {
+		respondError(w, http.StatusNotFound, "repository not found")
+		return
+	}
+
+	if err := s.store.UpdateRepositoryPolicy(r.Context(), repoID, data); err != nil {
+		log.Error().Err(err).Msg("failed to update repository policy")
+		respondError(w, http.StatusInternalServerError, "failed to update policy")
+		return
+	}
+
+	s.respondPolicy(w, r, repoID)
+}
+
+func (s *Server) respondPolicy(w http.ResponseWriter, r *http.Request, repoID uuid.UUID) {
+	stored, err := s.store.GetRepositoryPolicies(r.Context(), repoID)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to get repository policies")
+		respondError(w, http.StatusInternalServerError, "failed to get policy")
+		return
+	}
+	if stored == nil {
+		respondError(w, http.StatusNotFound, "repository not found")
+		return
+	}
+
+	resp, err := policyResponse(stored)
+	if err != nil {
+		log.Error().Err(err).Str("repo_id", repoID.String()).Msg("stored policy is invalid")
+		respondError(w, http.StatusInternalServerError, "failed to get policy")
+		return
+	}
+	respondJSON(w, http.StatusOK, resp)
+}
+
+// applyPolicy fills in the pipeline options left unset from the policy of
+// a known repository. A policy that can't be read fails the pipeline, since
+// it may restrict where the code is sent.
+func (s *Server) applyPolicy(ctx context.Context, repoID uuid.UUID, opts *jobs.PipelineOptions) error {
+	if s.store == nil {
+		return nil
+	}
+	stored, err := s.store.GetRepositoryPolicies(ctx, repoID)
+	if err != nil {
+		return err
+	}
+	if stored == nil {
+		return nil
+	}
+	resp, err := policyResponse(stored)
+	if err != nil {
+		return err
+	}
+	resp.Effective.Apply(opts)
+	return nil
+}
+
+// policyResponse merges a repository's stored policies
+func policyResponse(stored *db.RepositoryPolicies) (*PolicyResponse, error) {
+	resp := &PolicyResponse{
+		RepositoryID:   stored.RepositoryID,
+		OrganizationID: stored.OrganizationID,
+	}
+
+	inherited := &jobs.Policy{}
+	if stored.Organization != nil {
+		org, err := jobs.ParsePolicy(stored.Organization)
+		if err != nil {
+			return nil, err
+		}
+		resp.Organization = org
+		inherited = org
+	}
+
+	repo, err := jobs.ParsePolicy(stored.Repository)
+	if err != nil {
+		return nil, err
+	}
+	resp.Repository = repo
+	resp.Effective = inherited.Merge(repo)
+	return resp, nil
+}
+
+// decodePolicy reads and validates a policy request body, returning it
+// re-encoded for storage. Unknown fields are rejected so a misspelled
+// setting isn't silently ignored.
+func decodePolicy(body io.Reader) (json.RawMessage, error) {
+	dec := json.NewDecoder(body)
+	dec.DisallowUnknownFields()
+	policy := &jobs.Policy{}
+	if err := dec.Decode(policy); err != nil {
+		return nil, fmt.Errorf("invalid policy: %v", err)
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(policy)
+}
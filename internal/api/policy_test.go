@@ -0,0 +1,57 @@
+package api
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/QTest-hq/qtest/internal/db"
+)
+
+func TestPolicyResponse(t *testing.T) {
+	orgID := uuid.New()
+	resp, err := policyResponse(&db.RepositoryPolicies{
+		RepositoryID:   uuid.New(),
+		OrganizationID: &orgID,
+		Organization:   json.RawMessage(`{"llm_tier": 2, "providers": ["ollama"], "coverage_target": 80}`),
+		Repository:     json.RawMessage(`{"coverage_target": 90}`),
+	})
+	if err != nil {
+		t.Fatalf("policyResponse() error = %v", err)
+	}
+	if resp.Effective.LLMTier != 2 || resp.Effective.CoverageTarget != 90 || len(resp.Effective.Providers) != 1 {
+		t.Errorf("Effective = %+v", resp.Effective)
+	}
+	if resp.Repository.LLMTier != 0 {
+		t.Errorf("Repository = %+v, want only its overrides", resp.Repository)
+	}
+
+	// Repositories outside an organization only have their own policy
+	resp, err = policyResponse(&db.RepositoryPolicies{RepositoryID: uuid.New(), Repository: json.RawMessage(`{}`)})
+	if err != nil || resp.Organization != nil || resp.Effective.LLMTier != 0 {
+		t.Errorf("policyResponse() = %+v, %v", resp, err)
+	}
+}
+
+func TestDecodePolicy(t *testing.T) {
+	data, err := decodePolicy(strings.NewReader(`{"llm_tier": 3, "pr": {"labels": ["qtest"]}}`))
+	if err != nil {
+		t.Fatalf("decodePolicy() error = %v", err)
+	}
+	if string(data) != `{"llm_tier":3,"pr":{"labels":["qtest"]}}` {
+		t.Errorf("decodePolicy() = %s", data)
+	}
+
+	for _, body := range []string{
+		`{"llm_teir": 3}`,
+		`{"providers": ["gemini"]}`,
+		`{"coverage_target": -1}`,
+		`not json`,
+	} {
+		if _, err := decodePolicy(strings.NewReader(body)); err == nil {
+			t.Errorf("decodePolicy(%s) accepted an invalid policy", body)
+		}
+	}
+}
@@ -0,0 +1,329 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	"github.com/QTest-hq/qtest/internal/db"
+	"github.com/QTest-hq/qtest/internal/jobs"
+	"github.com/QTest-hq/qtest/internal/schedule"
+)
+
+// maxDueSchedules caps the schedules started per check
+const maxDueSchedules = 50
+
+// ScheduleRequest creates or replaces a repository's schedule
+type ScheduleRequest struct {
+	Cron     string                `json:"cron"`               // e.g. "0 2 * * *" or @weekly
+	Timezone string                `json:"timezone,omitempty"` // IANA name; UTC when empty
+	Enabled  *bool                 `json:"enabled,omitempty"`  // true when left out
+	Options  *StartPipelineRequest `json:"options,omitempty"`  // without repository_url
+}
+
+// listSchedules lists a repository's schedules:
+//
+//	GET /repos/{repoID}/schedules
+func (s *Server) listSchedules(w http.ResponseWriter, r *http.Request) {
+	repoID, ok := s.urlRepo(w, r)
+	if !ok {
+		return
+	}
+
+	schedules, err := s.store.ListSchedules(r.Context(), repoID)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to list schedules")
+		respondError(w, http.StatusInternalServerError, "failed to list schedules")
+		return
+	}
+	if schedules == nil {
+		schedules = []db.Schedule{}
+	}
+	respondJSON(w, http.StatusOK, schedules)
+}
+
+// createSchedule adds a schedule re-running the repository's pipeline.
+// Scheduled runs only generate tests for targets without one:
+//
+//	POST /repos/{repoID}/schedules {"cron": "0 2 * * *", "options": {"create_pr": true}}
+func (s *Server) createSchedule(w http.ResponseWriter, r *http.Request) {
+	repoID, ok := s.urlRepo(w, r)
+	if !ok {
+		return
+	}
+
+	sched := &db.Schedule{RepositoryID: repoID}
+	if !decodeSchedule(w, r, sched) {
+		return
+	}
+	if err := s.store.CreateSchedule(r.Context(), sched); err != nil {
+		log.Error().Err(err).Msg("failed to create schedule")
+		respondError(w, http.StatusInternalServerError, "failed to create schedule")
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, sched)
+}
+
+// getSchedule returns a repository's schedule:
+//
+//	GET /repos/{repoID}/schedules/{scheduleID}
+func (s *Server) getSchedule(w http.ResponseWriter, r *http.Request) {
+	sched, ok := s.loadSchedule(w, r)
+	if !ok {
+		return
+	}
+	respondJSON(w, http.StatusOK, sched)
+}
+
+// updateSchedule replaces a schedule's expression, time zone, options and
+// whether it's enabled:
+//
+//	PUT /repos/{repoID}/schedules/{scheduleID} {"cron": "@weekly", "enabled": false}
+func (s *Server) updateSchedule(w http.ResponseWriter, r *http.Request) {
+	sched, ok := s.loadSchedule(w, r)
+	if !ok {
+		return
+	}
+	if !decodeSchedule(w, r, sched) {
+		return
+	}
+
+	updated, err := s.store.UpdateSchedule(r.Context(), sched)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to update schedule")
+		respondError(w, http.StatusInternalServerError, "failed to update schedule")
+		return
+	}
+	if !updated {
+		respondError(w, http.StatusNotFound, "schedule not found")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, sched)
+}
+
+// deleteSchedule removes a repository's schedule:
+//
+//	DELETE /repos/{repoID}/schedules/{scheduleID}
+func (s *Server) deleteSchedule(w http.ResponseWriter, r *http.Request) {
+	repoID, ok := s.urlRepo(w, r)
+	if !ok {
+		return
+	}
+
+	scheduleID, err := uuid.Parse(chi.URLParam(r, "scheduleID"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid schedule ID")
+		return
+	}
+
+	deleted, err := s.store.DeleteSchedule(r.Context(), repoID, scheduleID)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to delete schedule")
+		respondError(w, http.StatusInternalServerError, "failed to delete schedule")
+		return
+	}
+	if !deleted {
+		respondError(w, http.StatusNotFound, "schedule not found")
+		return
+	}
+
+	respondJSON(w, http.StatusNoContent, nil)
+}
+
+// loadSchedule loads the schedule named in the URL, responding with an
+// error when it can't
+func (s *Server) loadSchedule(w http.ResponseWriter, r *http.Request) (*db.Schedule, bool) {
+	repoID, ok := s.urlRepo(w, r)
+	if !ok {
+		return nil, false
+	}
+
+	scheduleID, err := uuid.Parse(chi.URLParam(r, "scheduleID"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid schedule ID")
+		return nil, false
+	}
+
+	sched, err := s.store.GetSchedule(r.Context(), repoID, scheduleID)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to get schedule")
+		respondError(w, http.StatusInternalServerError, "failed to get schedule")
+		return nil, false
+	}
+	if sched == nil {
+		respondError(w, http.StatusNotFound, "schedule not found")
+		return nil, false
+	}
+	return sched, true
+}
+
+// decodeSchedule reads a ScheduleRequest into sched, working out when it
+// next runs, and responds with an error when the request is invalid
+func decodeSchedule(w http.ResponseWriter, r *http.Request, sched *db.Schedule) bool {
+	var req ScheduleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return false
+	}
+	if err := applyScheduleRequest(&req, sched, time.Now()); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return false
+	}
+	return true
+}
+
+// applyScheduleRequest validates a schedule request and sets its fields on
+// sched, with the next run after now
+func applyScheduleRequest(req *ScheduleRequest, sched *db.Schedule, now time.Time) error {
+	if req.Cron == "" {
+		return errors.New("cron is required")
+	}
+	if req.Timezone == "" {
+		req.Timezone = "UTC"
+	}
+	next, err := schedule.NextRun(req.Cron, req.Timezone, now)
+	if err != nil {
+		return err
+	}
+
+	options := req.Options
+	if options == nil {
+		options = &StartPipelineRequest{}
+	}
+	if options.RepositoryURL != "" {
+		return errors.New("options can't set repository_url; schedules run their own repository")
+	}
+	if options.VCS != "" {
+		return errors.New("options can't set vcs; schedules run their own repository")
+	}
+	if _, err := options.pipelineOptions(); err != nil {
+		return err
+	}
+	data, err := json.Marshal(options)
+	if err != nil {
+		return err
+	}
+
+	sched.Cron = req.Cron
+	sched.Timezone = req.Timezone
+	sched.Enabled = req.Enabled == nil || *req.Enabled
+	sched.Options = data
+	sched.NextRunAt = next
+	return nil
+}
+
+// RunSchedules starts the pipelines of due schedules every interval until
+// ctx is done. Several API servers may run it; each due run is claimed by
+// one of them.
+func (s *Server) RunSchedules(ctx context.Context, interval time.Duration) {
+	if s.store == nil || s.pipeline == nil {
+		log.Warn().Msg("scheduled runs need the database and job system, not starting them")
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		s.runDueSchedules(ctx, time.Now())
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runDueSchedules starts a pipeline for each schedule due at now. A
+// schedule that missed runs, say while no API server was up, runs once and
+// moves on to its next time after now.
+func (s *Server) runDueSchedules(ctx context.Context, now time.Time) {
+	due, err := s.store.ListDueSchedules(ctx, now, maxDueSchedules)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to list due schedules")
+		return
+	}
+
+	for _, sched := range due {
+		next, err := schedule.NextRun(sched.Cron, sched.Timezone, now)
+		if err != nil {
+			log.Error().Err(err).Str("schedule_id", sched.ID.String()).Msg("invalid schedule")
+			continue
+		}
+		claimed, err := s.store.ClaimSchedule(ctx, sched.ID, sched.NextRunAt, next)
+		if err != nil {
+			log.Error().Err(err).Str("schedule_id", sched.ID.String()).Msg("failed to claim schedule")
+			continue
+		}
+		if !claimed {
+			continue // another server got it
+		}
+
+		outcome, jobID, runErr := s.runSchedule(ctx, &sched)
+		var errMsg *string
+		if runErr != nil {
+			msg := runErr.Error()
+			errMsg = &msg
+			log.Error().Err(runErr).Str("schedule_id", sched.ID.String()).Msg("scheduled run failed")
+		} else {
+			log.Info().
+				Str("schedule_id", sched.ID.String()).
+				Str("repository_id", sched.RepositoryID.String()).
+				Str("outcome", outcome).
+				Time("next_run_at", next).
+				Msg("ran schedule")
+		}
+		if err := s.store.RecordScheduleRun(ctx, sched.ID, outcome, jobID, errMsg); err != nil {
+			log.Error().Err(err).Str("schedule_id", sched.ID.String()).Msg("failed to record schedule run")
+		}
+	}
+}
+
+// runSchedule starts a schedule's pipeline, unless the repository's last
+// one is still running. Scheduled pipelines only generate tests for targets
+// without one, and run in the batch lane unless their options say otherwise.
+func (s *Server) runSchedule(ctx context.Context, sched *db.Schedule) (string, *uuid.UUID, error) {
+	repo, err := s.store.GetRepository(ctx, sched.RepositoryID)
+	if err != nil {
+		return db.ScheduleFailed, nil, err
+	}
+	if repo == nil {
+		return db.ScheduleFailed, nil, errors.New("repository not found")
+	}
+
+	if active, err := s.activeJob(ctx, repo.ID); err != nil {
+		return db.ScheduleFailed, nil, err
+	} else if active != nil {
+		return db.ScheduleAlreadyRunning, &active.ID, nil
+	}
+
+	var req StartPipelineRequest
+	if err := json.Unmarshal(sched.Options, &req); err != nil {
+		return db.ScheduleFailed, nil, err
+	}
+	if req.Lane == "" {
+		req.Lane = string(jobs.LaneBatch)
+	}
+	options, err := req.pipelineOptions()
+	if err != nil {
+		return db.ScheduleFailed, nil, err
+	}
+	options.RepositoryID = &repo.ID
+	options.OnlyUntested = true
+	if err := s.applyPolicy(ctx, repo.ID, &options); err != nil {
+		return db.ScheduleFailed, nil, err
+	}
+
+	job, err := s.pipeline.StartFullPipeline(ctx, repo.URL, options)
+	if err != nil {
+		return db.ScheduleFailed, nil, err
+	}
+	return db.ScheduleQueued, &job.ID, nil
+}
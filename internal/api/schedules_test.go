@@ -0,0 +1,51 @@
+package api
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/QTest-hq/qtest/internal/db"
+)
+
+func TestApplyScheduleRequest(t *testing.T) {
+	now := time.Date(2025, 3, 14, 10, 17, 0, 0, time.UTC)
+	disabled := false
+
+	sched := &db.Schedule{}
+	req := &ScheduleRequest{
+		Cron:     "0 2 * * *",
+		Timezone: "America/New_York",
+		Enabled:  &disabled,
+		Options:  &StartPipelineRequest{MaxTests: 20, CreatePR: true},
+	}
+	if err := applyScheduleRequest(req, sched, now); err != nil {
+		if strings.Contains(err.Error(), "time zone") {
+			t.Skip("time zone database not available")
+		}
+		t.Fatalf("applyScheduleRequest: %v", err)
+	}
+	if want := time.Date(2025, 3, 15, 6, 0, 0, 0, time.UTC); !sched.NextRunAt.Equal(want) {
+		t.Errorf("NextRunAt = %v, want %v", sched.NextRunAt, want)
+	}
+	if sched.Enabled {
+		t.Error("schedule should be disabled")
+	}
+	var options StartPipelineRequest
+	if err := json.Unmarshal(sched.Options, &options); err != nil || options.MaxTests != 20 || !options.CreatePR {
+		t.Errorf("Options = %s", sched.Options)
+	}
+
+	for _, bad := range []*ScheduleRequest{
+		{},
+		{Cron: "every night"},
+		{Cron: "@daily", Timezone: "Nowhere/Special"},
+		{Cron: "@daily", Options: &StartPipelineRequest{RepositoryURL: "https://github.com/other/repo"}},
+		{Cron: "@daily", Options: &StartPipelineRequest{Lane: "urgent"}},
+	} {
+		if err := applyScheduleRequest(bad, &db.Schedule{}, now); err == nil {
+			t.Errorf("applyScheduleRequest(%+v) succeeded, want an error", bad)
+		}
+	}
+}
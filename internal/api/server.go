@@ -4,7 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
+	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/QTest-hq/qtest/internal/auth"
@@ -27,10 +30,22 @@ type JobRepository interface {
 	ListPendingByType(ctx context.Context, jobType jobs.JobType, limit int) ([]*jobs.Job, error)
 	ListByRepository(ctx context.Context, repoID uuid.UUID, limit int) ([]*jobs.Job, error)
 	ListRecent(ctx context.Context, limit int) ([]*jobs.Job, error)
+	ListForOrganizations(ctx context.Context, orgIDs []uuid.UUID, status jobs.JobStatus, jobType jobs.JobType, limit int) ([]*jobs.Job, error)
+	ListOutsideOrganizations(ctx context.Context, status jobs.JobStatus, jobType jobs.JobType, limit int) ([]*jobs.Job, error)
 	Cancel(ctx context.Context, jobID uuid.UUID) error
 	Retry(ctx context.Context, jobID uuid.UUID) error
 }
 
+// BatchRepository defines the interface for batch storage operations
+type BatchRepository interface {
+	CreateBatch(ctx context.Context, batch *jobs.Batch) error
+	AddBatchItems(ctx context.Context, batchID uuid.UUID, items []jobs.BatchItem) error
+	GetBatch(ctx context.Context, id uuid.UUID) (*jobs.Batch, error)
+	ListBatches(ctx context.Context, limit int) ([]*jobs.Batch, error)
+	ListBatchesForOrganizations(ctx context.Context, orgIDs []uuid.UUID, limit int) ([]*jobs.Batch, error)
+	ListBatchesOutsideOrganizations(ctx context.Context, limit int) ([]*jobs.Batch, error)
+}
+
 // Server represents the API server
 type Server struct {
 	cfg         *config.Config
@@ -39,8 +54,14 @@ type Server struct {
 	repoService *gh.RepoService
 	nats        *qtestnats.Client
 	jobRepo     JobRepository
+	batchRepo   BatchRepository
 	pipeline    *jobs.Pipeline
 
+	// subscribeProgress follows jobs' progress for event streams; nil
+	// without NATS. eventPoll overrides eventPollInterval.
+	subscribeProgress progressSubscriber
+	eventPoll         time.Duration
+
 	// Auth components
 	authHandlers   *auth.Handlers
 	authMiddleware *auth.Middleware
@@ -56,7 +77,7 @@ func NewServer(cfg *config.Config, database *db.DB) (*Server, error) {
 		cfg:         cfg,
 		router:      chi.NewRouter(),
 		store:       store,
-		repoService: gh.NewRepoService("/tmp/qtest-repos", cfg.GitHubToken),
+		repoService: gh.NewRepoService(filepath.Join(os.TempDir(), "qtest-repos"), cfg.GitHubToken),
 		orgHandlers: NewOrganizationHandlers(store),
 	}
 
@@ -71,8 +92,12 @@ func (s *Server) SetJobSystem(jobRepo *jobs.Repository, natsClient *qtestnats.Cl
 	s.nats = natsClient
 	s.jobRepo = jobRepo
 	if jobRepo != nil {
+		s.batchRepo = jobRepo
 		s.pipeline = jobs.NewPipeline(jobRepo, natsClient)
 	}
+	if natsClient != nil {
+		s.subscribeProgress = natsProgressSubscriber(natsClient)
+	}
 }
 
 // SetAuth configures the authentication system
@@ -92,10 +117,25 @@ func (s *Server) setupMiddleware() {
 	s.router.Use(middleware.RealIP)
 	s.router.Use(middleware.Logger)
 	s.router.Use(middleware.Recoverer)
-	s.router.Use(middleware.Timeout(60 * time.Second))
+	s.router.Use(timeoutMiddleware(60 * time.Second))
 	s.router.Use(corsMiddleware)
 }
 
+// timeoutMiddleware bounds requests to timeout, except event streams, which
+// stay open until the client leaves or the job finishes
+func timeoutMiddleware(timeout time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		bounded := middleware.Timeout(timeout)(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if strings.HasSuffix(r.URL.Path, "/events") {
+				next.ServeHTTP(w, r)
+				return
+			}
+			bounded.ServeHTTP(w, r)
+		})
+	}
+}
+
 func corsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
@@ -126,6 +166,8 @@ func (s *Server) setupRoutes() {
 
 	// API v1
 	s.router.Route("/api/v1", func(r chi.Router) {
+		r.Use(s.authenticate)
+
 		// Auth - user info (requires auth)
 		r.Route("/auth", func(r chi.Router) {
 			r.Get("/me", s.handleMe)
@@ -137,18 +179,41 @@ func (s *Server) setupRoutes() {
 		r.Route("/repos", func(r chi.Router) {
 			r.Post("/", s.createRepo)
 			r.Get("/", s.listRepos)
-			r.Get("/{repoID}", s.getRepo)
-			r.Delete("/{repoID}", s.deleteRepo)
-			r.Get("/{repoID}/jobs", s.listRepoJobs)
+			r.Post("/batch", s.createBatch)
+			r.Get("/batch/{batchID}", s.getBatch)
+			r.Get("/batches", s.listBatches)
+
+			// A repository's routes, in organizations the caller is in
+			r.Route("/{repoID}", func(r chi.Router) {
+				r.Use(s.repoAccess)
+				r.Get("/", s.getRepo)
+				r.Delete("/", s.deleteRepo)
+				r.Get("/jobs", s.listRepoJobs)
+				r.Get("/untestable", s.listUntestableTargets)
+				r.Get("/files/*", s.getFileHistory)
+				r.Get("/policy", s.getRepoPolicy)
+				r.Put("/policy", s.updateRepoPolicy)
+				r.Get("/webhooks", s.listWebhooks)
+				r.Post("/webhooks", s.createWebhook)
+				r.Delete("/webhooks/{webhookID}", s.deleteWebhook)
+				r.Get("/schedules", s.listSchedules)
+				r.Post("/schedules", s.createSchedule)
+				r.Get("/schedules/{scheduleID}", s.getSchedule)
+				r.Put("/schedules/{scheduleID}", s.updateSchedule)
+				r.Delete("/schedules/{scheduleID}", s.deleteSchedule)
+				r.Get("/mutation", s.listRepoMutationRuns)
+
+				// Generation runs
+				r.Route("/runs", func(r chi.Router) {
+					r.Post("/", s.createRun)
+					r.Get("/", s.listRuns)
+					r.Get("/{runID}", s.getRun)
+					r.Get("/{runID}/tests", s.getRunTests)
+				})
+			})
 		})
 
-		// Generation runs
-		r.Route("/repos/{repoID}/runs", func(r chi.Router) {
-			r.Post("/", s.createRun)
-			r.Get("/", s.listRuns)
-			r.Get("/{runID}", s.getRun)
-			r.Get("/{runID}/tests", s.getRunTests)
-		})
+		r.Get("/runs/{runID}", s.getRun) // for clients that only know the run
 
 		// Jobs
 		r.Route("/jobs", func(r chi.Router) {
@@ -156,10 +221,22 @@ func (s *Server) setupRoutes() {
 			r.Post("/pipeline", s.startPipeline)
 			r.Get("/", s.listJobs)
 			r.Get("/{jobID}", s.getJob)
+			r.Get("/{jobID}/events", s.streamJobEvents)
 			r.Post("/{jobID}/cancel", s.cancelJob)
 			r.Post("/{jobID}/retry", s.retryJob)
 		})
 
+		// Pipelines: a repository URL's whole job chain, for polling
+		r.Route("/pipelines", func(r chi.Router) {
+			r.Post("/", s.submitPipeline)
+			r.Get("/{pipelineID}", s.getPipeline)
+			r.Get("/{pipelineID}/targets", s.getPipelineTargets)
+			r.Get("/{pipelineID}/files", s.listPipelineFiles)
+			r.Get("/{pipelineID}/files/*", s.downloadPipelineFile)
+			r.Get("/{pipelineID}/archive", s.downloadPipelineArchive)
+			r.Post("/{pipelineID}/cancel", s.cancelPipeline)
+		})
+
 		// Tests
 		r.Route("/tests", func(r chi.Router) {
 			r.Get("/", s.listTests)
@@ -175,9 +252,6 @@ func (s *Server) setupRoutes() {
 			r.Get("/{mutationID}", s.getMutationRun)
 		})
 
-		// Repo-specific mutation runs
-		r.Get("/repos/{repoID}/mutation", s.listRepoMutationRuns)
-
 		// Organizations (requires auth)
 		r.Route("/organizations", func(r chi.Router) {
 			r.Use(s.requireAuth)
@@ -186,24 +260,36 @@ func (s *Server) setupRoutes() {
 			r.Get("/{orgID}", s.getOrganization)
 			r.Patch("/{orgID}", s.updateOrganization)
 			r.Delete("/{orgID}", s.deleteOrganization)
+			r.Get("/{orgID}/policy", s.getOrganizationPolicy)
+			r.Put("/{orgID}/policy", s.updateOrganizationPolicy)
 
 			// Organization members
 			r.Get("/{orgID}/members", s.listOrgMembers)
 			r.Post("/{orgID}/members", s.addOrgMember)
 			r.Patch("/{orgID}/members/{userID}", s.updateMemberRole)
 			r.Delete("/{orgID}/members/{userID}", s.removeOrgMember)
+
+			// Organization API keys
+			r.Get("/{orgID}/api-keys", s.listOrgAPIKeys)
+			r.Post("/{orgID}/api-keys", s.createOrgAPIKey)
+			r.Delete("/{orgID}/api-keys/{keyID}", s.revokeOrgAPIKey)
 		})
 	})
 }
 
-// requireAuth is middleware that requires authentication
+// requireAuth is middleware that requires authentication, after
+// authenticate has checked the request's credentials
 func (s *Server) requireAuth(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if s.authMiddleware == nil {
 			respondError(w, http.StatusServiceUnavailable, "auth not configured")
 			return
 		}
-		s.authMiddleware.RequireAuth(next).ServeHTTP(w, r)
+		if principal(r) == nil {
+			respondError(w, http.StatusUnauthorized, "authentication required")
+			return
+		}
+		next.ServeHTTP(w, r)
 	})
 }
 
@@ -263,6 +349,10 @@ func (s *Server) readyCheck(w http.ResponseWriter, r *http.Request) {
 type CreateRepoRequest struct {
 	URL    string `json:"url"`
 	Branch string `json:"branch,omitempty"`
+
+	// OrganizationID is the organization to add the repository to; an API
+	// key's or the caller's personal organization when empty
+	OrganizationID *uuid.UUID `json:"organization_id,omitempty"`
 }
 
 func (s *Server) createRepo(w http.ResponseWriter, r *http.Request) {
@@ -288,9 +378,29 @@ func (s *Server) createRepo(w http.ResponseWriter, r *http.Request) {
 		repoInfo.Branch = req.Branch
 	}
 
+	p := principal(r)
+	var orgID uuid.UUID
+	if p != nil {
+		var status int
+		orgID, status, err = s.targetOrg(r.Context(), p, req.OrganizationID)
+		if err != nil {
+			log.Error().Err(err).Msg("failed to check access")
+			respondError(w, http.StatusInternalServerError, "failed to check access")
+			return
+		}
+		if status != 0 {
+			respondError(w, status, "can't add repositories to the organization")
+			return
+		}
+	}
+
 	// Check if repo already exists
 	existing, _ := s.store.GetRepositoryByURL(r.Context(), req.URL)
 	if existing != nil {
+		if status, _ := s.orgAccess(r.Context(), p, existing.OrganizationID, false); status != 0 {
+			respondError(w, http.StatusConflict, errOtherOrganization.Error())
+			return
+		}
 		respondJSON(w, http.StatusOK, existing)
 		return
 	}
@@ -303,7 +413,12 @@ func (s *Server) createRepo(w http.ResponseWriter, r *http.Request) {
 		DefaultBranch: repoInfo.Branch,
 	}
 
-	if err := s.store.CreateRepository(r.Context(), repo); err != nil {
+	if p != nil {
+		err = s.store.CreateRepositoryForOrg(r.Context(), repo, orgID, p.UserID)
+	} else {
+		err = s.store.CreateRepository(r.Context(), repo)
+	}
+	if err != nil {
 		log.Error().Err(err).Msg("failed to create repository")
 		respondError(w, http.StatusInternalServerError, "failed to create repository")
 		return
@@ -342,7 +457,16 @@ func (s *Server) listRepos(w http.ResponseWriter, r *http.Request) {
 		limit = 20
 	}
 
-	repos, err := s.store.ListRepositories(r.Context(), limit, offset)
+	var repos []db.Repository
+	var err error
+	switch p := principal(r); {
+	case p == nil:
+		repos, err = s.store.ListRepositoriesOutsideOrganizations(r.Context(), limit, offset)
+	case p.OrganizationID != nil:
+		repos, err = s.store.ListRepositoriesByOrg(r.Context(), *p.OrganizationID, limit, offset)
+	default:
+		repos, err = s.store.ListRepositoriesForUser(r.Context(), p.UserID, limit, offset)
+	}
 	if err != nil {
 		log.Error().Err(err).Msg("failed to list repositories")
 		respondError(w, http.StatusInternalServerError, "failed to list repositories")
@@ -475,6 +599,40 @@ func (s *Server) listRuns(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, http.StatusOK, runs)
 }
 
+// listUntestableTargets lists the functions test generation keeps failing
+// for, with the testability problems found in them
+func (s *Server) listUntestableTargets(w http.ResponseWriter, r *http.Request) {
+	if s.store == nil {
+		respondError(w, http.StatusServiceUnavailable, "database not available")
+		return
+	}
+
+	repoID, err := uuid.Parse(chi.URLParam(r, "repoID"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid repo ID")
+		return
+	}
+
+	minFailures := 1
+	if v := r.URL.Query().Get("min_failures"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			minFailures = n
+		}
+	}
+
+	targets, err := s.store.ListUntestableTargets(r.Context(), repoID, minFailures)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to list untestable targets")
+		respondError(w, http.StatusInternalServerError, "failed to list untestable targets")
+		return
+	}
+	if targets == nil {
+		targets = []db.UntestableTarget{}
+	}
+
+	respondJSON(w, http.StatusOK, targets)
+}
+
 func (s *Server) getRun(w http.ResponseWriter, r *http.Request) {
 	runID, err := uuid.Parse(chi.URLParam(r, "runID"))
 	if err != nil {
@@ -489,10 +647,13 @@ func (s *Server) getRun(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if run == nil {
+	if run == nil || !runInScope(r, run) {
 		respondError(w, http.StatusNotFound, "run not found")
 		return
 	}
+	if !s.authorizeRepo(w, r, run.RepositoryID, "run not found") {
+		return
+	}
 
 	respondJSON(w, http.StatusOK, run)
 }
@@ -503,6 +664,9 @@ func (s *Server) getRunTests(w http.ResponseWriter, r *http.Request) {
 		respondError(w, http.StatusBadRequest, "invalid run ID")
 		return
 	}
+	if !s.authorizeRun(w, r, runID, "run not found") {
+		return
+	}
 
 	tests, err := s.store.ListTestsByRun(r.Context(), runID)
 	if err != nil {
@@ -518,7 +682,7 @@ func (s *Server) getRunTests(w http.ResponseWriter, r *http.Request) {
 func (s *Server) listTests(w http.ResponseWriter, r *http.Request) {
 	q := r.URL.Query()
 
-	// Parse optional run_id filter
+	// Parse the run_id filter
 	var runID *uuid.UUID
 	if runIDStr := q.Get("run_id"); runIDStr != "" {
 		parsed, err := uuid.Parse(runIDStr)
@@ -528,9 +692,18 @@ func (s *Server) listTests(w http.ResponseWriter, r *http.Request) {
 		}
 		runID = &parsed
 	}
+	// Tests are only listed a run at a time, in runs the caller can see
+	if runID == nil {
+		respondError(w, http.StatusBadRequest, "run_id is required")
+		return
+	}
+	if !s.authorizeRun(w, r, *runID, "run not found") {
+		return
+	}
 
-	// Parse status filter
+	// Parse status and static check filters
 	status := q.Get("status")
+	staticCheck := q.Get("static_check")
 
 	// Parse limit (default 50)
 	limit := 50
@@ -543,7 +716,7 @@ func (s *Server) listTests(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	tests, err := s.store.ListTests(r.Context(), runID, status, limit)
+	tests, err := s.store.ListTests(r.Context(), runID, status, staticCheck, limit)
 	if err != nil {
 		log.Error().Err(err).Msg("failed to list tests")
 		respondError(w, http.StatusInternalServerError, "failed to list tests")
@@ -571,6 +744,9 @@ func (s *Server) getTest(w http.ResponseWriter, r *http.Request) {
 		respondError(w, http.StatusNotFound, "test not found")
 		return
 	}
+	if !s.authorizeRun(w, r, test.RunID, "test not found") {
+		return
+	}
 
 	respondJSON(w, http.StatusOK, test)
 }
@@ -598,6 +774,9 @@ func (s *Server) acceptTest(w http.ResponseWriter, r *http.Request) {
 		respondError(w, http.StatusNotFound, "test not found")
 		return
 	}
+	if !s.authorizeRun(w, r, test.RunID, "test not found") {
+		return
+	}
 
 	// Update status to accepted
 	if err := s.store.UpdateTestStatus(r.Context(), testID, "accepted", nil); err != nil {
@@ -630,6 +809,9 @@ func (s *Server) rejectTest(w http.ResponseWriter, r *http.Request) {
 		respondError(w, http.StatusNotFound, "test not found")
 		return
 	}
+	if !s.authorizeRun(w, r, test.RunID, "test not found") {
+		return
+	}
 
 	// Parse rejection reason
 	var req RejectTestRequest
@@ -680,6 +862,13 @@ func (s *Server) handleLogout(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) handleMe(w http.ResponseWriter, r *http.Request) {
+	// API keys and OIDC tokens have no session; describe the caller
+	if _, ok := auth.GetSessionFromContext(r.Context()); !ok {
+		if p := principal(r); p != nil {
+			respondJSON(w, http.StatusOK, p)
+			return
+		}
+	}
 	if s.authHandlers == nil {
 		respondError(w, http.StatusServiceUnavailable, "auth not configured")
 		return
@@ -738,6 +927,14 @@ func (s *Server) deleteOrganization(w http.ResponseWriter, r *http.Request) {
 	s.orgHandlers.DeleteOrganization(w, r)
 }
 
+func (s *Server) getOrganizationPolicy(w http.ResponseWriter, r *http.Request) {
+	s.orgHandlers.GetPolicy(w, r)
+}
+
+func (s *Server) updateOrganizationPolicy(w http.ResponseWriter, r *http.Request) {
+	s.orgHandlers.UpdatePolicy(w, r)
+}
+
 func (s *Server) listOrgMembers(w http.ResponseWriter, r *http.Request) {
 	s.orgHandlers.ListMembers(w, r)
 }
@@ -753,3 +950,15 @@ func (s *Server) updateMemberRole(w http.ResponseWriter, r *http.Request) {
 func (s *Server) removeOrgMember(w http.ResponseWriter, r *http.Request) {
 	s.orgHandlers.RemoveMember(w, r)
 }
+
+func (s *Server) listOrgAPIKeys(w http.ResponseWriter, r *http.Request) {
+	s.orgHandlers.ListAPIKeys(w, r)
+}
+
+func (s *Server) createOrgAPIKey(w http.ResponseWriter, r *http.Request) {
+	s.orgHandlers.CreateAPIKey(w, r)
+}
+
+func (s *Server) revokeOrgAPIKey(w http.ResponseWriter, r *http.Request) {
+	s.orgHandlers.RevokeAPIKey(w, r)
+}
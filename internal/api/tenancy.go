@@ -0,0 +1,334 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	"github.com/QTest-hq/qtest/internal/auth"
+	"github.com/QTest-hq/qtest/internal/db"
+	gh "github.com/QTest-hq/qtest/internal/github"
+	"github.com/QTest-hq/qtest/internal/jobs"
+	"github.com/QTest-hq/qtest/internal/netguard"
+	"github.com/QTest-hq/qtest/internal/vcs"
+)
+
+// errOtherOrganization is returned for a repository URL another
+// organization has registered
+var errOtherOrganization = errors.New("repository is registered by another organization")
+
+// principal returns the user a request acts for, or nil for requests
+// without credentials, which are only let through when auth isn't required.
+// They act in no organization, so only see resources outside them.
+func principal(r *http.Request) *auth.Principal {
+	p, _ := auth.GetPrincipalFromContext(r.Context())
+	return p
+}
+
+// authRequired reports whether API requests need credentials
+func (s *Server) authRequired() bool {
+	return s.cfg != nil && s.cfg.Auth.Required
+}
+
+// authenticate sets the principal of API requests. Credentials are
+// optional unless auth is required, but those given must be valid.
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case s.authMiddleware == nil && s.authRequired():
+			respondError(w, http.StatusServiceUnavailable, "auth not configured")
+		case s.authMiddleware == nil:
+			next.ServeHTTP(w, r)
+		case s.authRequired():
+			s.authMiddleware.RequireAuth(next).ServeHTTP(w, r)
+		default:
+			s.authMiddleware.OptionalAuth(next).ServeHTTP(w, r)
+		}
+	})
+}
+
+// isWrite reports whether a request changes something
+func isWrite(r *http.Request) bool {
+	return r.Method != http.MethodGet && r.Method != http.MethodHead
+}
+
+// orgAccess returns 0 when the principal may act in an organization, or
+// the status to answer with when it can't: not found for organizations it
+// isn't in, forbidden for writes by viewers and read-only API keys.
+// Resources without an organization are only seen without credentials.
+func (s *Server) orgAccess(ctx context.Context, p *auth.Principal, orgID *uuid.UUID, write bool) (int, error) {
+	if p == nil {
+		if orgID != nil {
+			return http.StatusNotFound, nil
+		}
+		return 0, nil
+	}
+	if orgID == nil || (p.OrganizationID != nil && *p.OrganizationID != *orgID) {
+		return http.StatusNotFound, nil
+	}
+	if s.store == nil {
+		return 0, errors.New("database not available")
+	}
+
+	// API keys act with their creator's current role
+	role, err := s.store.GetMemberRole(ctx, *orgID, p.UserID)
+	if err != nil {
+		return 0, err
+	}
+	if role == "" {
+		return http.StatusNotFound, nil
+	}
+	if write && (role == db.RoleViewer || p.ReadOnly) {
+		return http.StatusForbidden, nil
+	}
+	return 0, nil
+}
+
+// authorizeOrg checks the request's principal may act in an organization,
+// responding with an error when it can't; notFound describes what the
+// request is for
+func (s *Server) authorizeOrg(w http.ResponseWriter, r *http.Request, orgID *uuid.UUID, notFound string) bool {
+	status, err := s.orgAccess(r.Context(), principal(r), orgID, isWrite(r))
+	if err != nil {
+		log.Error().Err(err).Msg("failed to check access")
+		respondError(w, http.StatusInternalServerError, "failed to check access")
+		return false
+	}
+	switch status {
+	case http.StatusNotFound:
+		respondError(w, status, notFound)
+	case http.StatusForbidden:
+		respondError(w, status, "insufficient permissions")
+	}
+	return status == 0
+}
+
+// authorizeRepo checks the request's principal may act in a repository's
+// organization, like authorizeOrg
+func (s *Server) authorizeRepo(w http.ResponseWriter, r *http.Request, repoID uuid.UUID, notFound string) bool {
+	if s.store == nil {
+		return true // without a database there are no organizations
+	}
+	repo, err := s.store.GetRepository(r.Context(), repoID)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to get repository")
+		respondError(w, http.StatusInternalServerError, "failed to get repository")
+		return false
+	}
+	if repo == nil {
+		respondError(w, http.StatusNotFound, notFound)
+		return false
+	}
+	return s.authorizeOrg(w, r, repo.OrganizationID, notFound)
+}
+
+// authorizeJob checks the request's principal may act on a job, through
+// its repository
+func (s *Server) authorizeJob(w http.ResponseWriter, r *http.Request, job *jobs.Job, notFound string) bool {
+	if job.RepositoryID == nil {
+		// Jobs outside repositories are outside organizations too
+		if principal(r) == nil {
+			return true
+		}
+		respondError(w, http.StatusNotFound, notFound)
+		return false
+	}
+	return s.authorizeRepo(w, r, *job.RepositoryID, notFound)
+}
+
+// authorizeJobID is authorizeJob for a job not loaded yet
+func (s *Server) authorizeJobID(w http.ResponseWriter, r *http.Request, jobID uuid.UUID) bool {
+	job, err := s.jobRepo.GetByID(r.Context(), jobID)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to get job")
+		respondError(w, http.StatusInternalServerError, "failed to get job")
+		return false
+	}
+	if job == nil {
+		respondError(w, http.StatusNotFound, "job not found")
+		return false
+	}
+	return s.authorizeJob(w, r, job, "job not found")
+}
+
+// pipelineRepository returns the repository a pipeline request's URL names
+// in the caller's organizations, registering it in the requested one if
+// it's new
+func (s *Server) pipelineRepository(w http.ResponseWriter, r *http.Request, p *auth.Principal, req *StartPipelineRequest) (*db.Repository, bool) {
+	info, err := repoInfo(r.Context(), req.RepositoryURL)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return nil, false
+	}
+	if req.Branch != "" {
+		info.Branch = req.Branch
+	}
+
+	orgID, status, err := s.targetOrg(r.Context(), p, req.OrganizationID)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to check access")
+		respondError(w, http.StatusInternalServerError, "failed to check access")
+		return nil, false
+	}
+	if status != 0 {
+		respondError(w, status, "can't add repositories to the organization")
+		return nil, false
+	}
+
+	repo, err := s.batchRepository(r, orgID, req.RepositoryURL, info)
+	if errors.Is(err, errOtherOrganization) {
+		respondError(w, http.StatusConflict, err.Error())
+		return nil, false
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("failed to get repository")
+		respondError(w, http.StatusInternalServerError, "failed to get repository")
+		return nil, false
+	}
+	return repo, true
+}
+
+// repoInfo names the repository a URL is for. Mercurial repositories and
+// archives, which aren't on GitHub, are named after their URL's last two
+// path elements. Workers hand the URL to git, hg or an http client, so only
+// http(s) and ssh URLs with a host are accepted, and archives only over
+// http(s). Hosts other than GitHub mustn't be at loopback, private or
+// link-local addresses.
+func repoInfo(ctx context.Context, rawURL string) (*gh.RepoInfo, error) {
+	kind := vcs.Detect(rawURL)
+	trimmed := vcs.TrimURL(rawURL)
+	if strings.HasPrefix(trimmed, "-") {
+		return nil, fmt.Errorf("invalid repository URL: %s", rawURL)
+	}
+	if kind == vcs.KindGit && strings.HasPrefix(trimmed, "git@") {
+		host, _, _ := strings.Cut(strings.TrimPrefix(trimmed, "git@"), ":")
+		if err := checkRepoHost(ctx, host); err != nil {
+			return nil, err
+		}
+		return gh.ParseRepoURL(rawURL)
+	}
+
+	u, err := url.Parse(trimmed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse URL: %w", err)
+	}
+	switch {
+	case u.Host == "":
+		return nil, fmt.Errorf("repository URL has no host: %s", rawURL)
+	case u.Scheme == "http" || u.Scheme == "https":
+	case u.Scheme == "ssh" && kind != vcs.KindArchive:
+	default:
+		return nil, fmt.Errorf("unsupported %s URL scheme %q", kind, u.Scheme)
+	}
+	if err := checkRepoHost(ctx, u.Hostname()); err != nil {
+		return nil, err
+	}
+	if kind == vcs.KindGit {
+		return gh.ParseRepoURL(rawURL)
+	}
+
+	repoPath := strings.Trim(u.Path, "/")
+	if repoPath == "" {
+		return nil, fmt.Errorf("invalid repo path: %s", u.Path)
+	}
+	info := &gh.RepoInfo{Owner: u.Host, Name: path.Base(repoPath), URL: rawURL}
+	if dir := path.Dir(repoPath); dir != "." {
+		info.Owner = path.Base(dir)
+	}
+	if kind == vcs.KindHg {
+		info.Branch = "default"
+	}
+	return info, nil
+}
+
+// checkRepoHost refuses repository hosts workers mustn't be sent to
+func checkRepoHost(ctx context.Context, host string) error {
+	if host == "github.com" {
+		return nil
+	}
+	if err := netguard.CheckHost(ctx, host); err != nil {
+		return fmt.Errorf("invalid repository host: %w", err)
+	}
+	return nil
+}
+
+// authorizeRun checks the request's principal may act on a generation run,
+// or a test generated in it, through the run's repository
+func (s *Server) authorizeRun(w http.ResponseWriter, r *http.Request, runID uuid.UUID, notFound string) bool {
+	if s.store == nil {
+		return true // without a database there are no organizations
+	}
+	run, err := s.store.GetGenerationRun(r.Context(), runID)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to get run")
+		respondError(w, http.StatusInternalServerError, "failed to get run")
+		return false
+	}
+	if run == nil || !runInScope(r, run) {
+		respondError(w, http.StatusNotFound, notFound)
+		return false
+	}
+	return s.authorizeRepo(w, r, run.RepositoryID, notFound)
+}
+
+// runInScope reports whether a run is in the repository the request's URL
+// names, if it names one
+func runInScope(r *http.Request, run *db.GenerationRun) bool {
+	repoID := chi.URLParam(r, "repoID")
+	return repoID == "" || repoID == run.RepositoryID.String()
+}
+
+// repoAccess lets requests through to a repository's routes only when
+// their principal may act in its organization
+func (s *Server) repoAccess(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		repoID, err := uuid.Parse(chi.URLParam(r, "repoID"))
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "invalid repo ID")
+			return
+		}
+		if !s.authorizeRepo(w, r, repoID, "repository not found") {
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// principalOrgs returns the organizations a principal acts in
+func (s *Server) principalOrgs(ctx context.Context, p *auth.Principal) ([]uuid.UUID, error) {
+	if p.OrganizationID != nil {
+		return []uuid.UUID{*p.OrganizationID}, nil
+	}
+	return s.store.ListUserAccessibleOrgs(ctx, p.UserID)
+}
+
+// targetOrg returns the organization a principal adds repositories to: the
+// one requested, an API key's, or the user's personal one. It answers
+// with a status when the principal can't write to it.
+func (s *Server) targetOrg(ctx context.Context, p *auth.Principal, requested *uuid.UUID) (uuid.UUID, int, error) {
+	orgID := requested
+	if orgID == nil {
+		orgID = p.OrganizationID
+	}
+	if orgID == nil {
+		personal, err := s.store.GetPersonalOrganization(ctx, p.UserID)
+		if err != nil {
+			return uuid.Nil, 0, err
+		}
+		if personal == nil {
+			return uuid.Nil, http.StatusBadRequest, nil
+		}
+		orgID = &personal.ID
+	}
+
+	status, err := s.orgAccess(ctx, p, orgID, true)
+	return *orgID, status, err
+}
@@ -0,0 +1,161 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/QTest-hq/qtest/internal/auth"
+	"github.com/QTest-hq/qtest/internal/config"
+	"github.com/QTest-hq/qtest/internal/db"
+	"github.com/QTest-hq/qtest/internal/jobs"
+)
+
+func TestAuthenticate_RequiredWithoutAuthConfigured(t *testing.T) {
+	s := &Server{cfg: &config.Config{Auth: config.AuthConfig{Required: true}}}
+	handler := s.authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request let through")
+	}))
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("GET", "/api/v1/repos", nil))
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want 503", rr.Code)
+	}
+}
+
+func TestAuthenticate_RejectsAnonymousWhenRequired(t *testing.T) {
+	s := &Server{
+		cfg:            &config.Config{Auth: config.AuthConfig{Required: true}},
+		authMiddleware: auth.NewMiddleware(auth.NewSessionStore(auth.SessionStoreConfig{}), nil),
+	}
+	handler := s.authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request let through")
+	}))
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("GET", "/api/v1/repos", nil))
+	if rr.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want 401", rr.Code)
+	}
+}
+
+func TestOrgAccess_APIKeyOutsideItsOrganization(t *testing.T) {
+	s := &Server{}
+	keyOrg, otherOrg := uuid.New(), uuid.New()
+	key := &auth.Principal{UserID: uuid.New(), Method: auth.MethodAPIKey, OrganizationID: &keyOrg}
+
+	// Decided before the database is asked for the user's role
+	for name, orgID := range map[string]*uuid.UUID{"other organization": &otherOrg, "no organization": nil} {
+		status, err := s.orgAccess(context.Background(), key, orgID, false)
+		if err != nil || status != http.StatusNotFound {
+			t.Errorf("%s: status %d, err %v; want 404", name, status, err)
+		}
+	}
+
+}
+
+func TestOrgAccess_AnonymousInNoOrganization(t *testing.T) {
+	s := &Server{}
+	otherOrg := uuid.New()
+
+	for _, write := range []bool{false, true} {
+		if status, err := s.orgAccess(context.Background(), nil, &otherOrg, write); err != nil || status != http.StatusNotFound {
+			t.Errorf("anonymous in an organization (write %v): status %d, err %v; want 404", write, status, err)
+		}
+		if status, err := s.orgAccess(context.Background(), nil, nil, write); err != nil || status != 0 {
+			t.Errorf("anonymous outside organizations (write %v): status %d, err %v; want access", write, status, err)
+		}
+	}
+
+	// Another organization's repository is hidden from requests without
+	// credentials
+	repo := &db.Repository{ID: uuid.New(), OrganizationID: &otherOrg}
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/v1/repos/"+repo.ID.String(), nil)
+	if s.authorizeOrg(rr, req, repo.OrganizationID, "repository not found") || rr.Code != http.StatusNotFound {
+		t.Errorf("anonymous request saw another organization's repository, status %d", rr.Code)
+	}
+}
+
+func TestAuthorizeJob_WithoutRepository(t *testing.T) {
+	s := &Server{}
+	job := &jobs.Job{ID: uuid.New()}
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/v1/jobs/"+job.ID.String(), nil)
+	if !s.authorizeJob(rr, req, job, "job not found") {
+		t.Error("anonymous request denied")
+	}
+
+	ctx := context.WithValue(req.Context(), auth.PrincipalKey, &auth.Principal{UserID: uuid.New()})
+	if s.authorizeJob(rr, req.WithContext(ctx), job, "job not found") || rr.Code != http.StatusNotFound {
+		t.Errorf("user allowed a job without a repository, status %d", rr.Code)
+	}
+}
+
+func TestRunInScope(t *testing.T) {
+	run := &db.GenerationRun{ID: uuid.New(), RepositoryID: uuid.New()}
+	inScope := func(repoID string) bool {
+		req := httptest.NewRequest("GET", "/", nil)
+		rctx := chi.NewRouteContext()
+		if repoID != "" {
+			rctx.URLParams.Add("repoID", repoID)
+		}
+		return runInScope(req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx)), run)
+	}
+
+	if !inScope("") || !inScope(run.RepositoryID.String()) {
+		t.Error("run out of scope of its own repository")
+	}
+	if inScope(uuid.New().String()) {
+		t.Error("run in scope of another repository")
+	}
+}
+
+func TestRepoInfo(t *testing.T) {
+	tests := []struct {
+		url, owner, name, branch string
+	}{
+		{"https://github.com/owner/repo", "owner", "repo", "main"},
+		{"hg+https://203.0.113.10/team/project", "team", "project", "default"},
+		{"https://203.0.113.10/project-1.2.tar.gz", "203.0.113.10", "project-1.2", ""},
+		{"hg+ssh://203.0.113.10/team/project", "team", "project", "default"},
+	}
+	ctx := context.Background()
+	for _, tt := range tests {
+		info, err := repoInfo(ctx, tt.url)
+		if err != nil {
+			t.Errorf("repoInfo(%q) error = %v", tt.url, err)
+			continue
+		}
+		if info.Owner != tt.owner || info.Name != tt.name || info.Branch != tt.branch {
+			t.Errorf("repoInfo(%q) = %s/%s@%s, want %s/%s@%s", tt.url, info.Owner, info.Name, info.Branch, tt.owner, tt.name, tt.branch)
+		}
+	}
+
+	if _, err := repoInfo(ctx, "https://gitlab.com/owner/repo"); err == nil {
+		t.Error("git repository outside GitHub accepted")
+	}
+	for _, url := range []string{
+		"hg+--config=alias.clone=!touch /tmp/pwned",
+		"hg+file:///etc/project",
+		"hg+https:///project",
+		"archive+file:///etc/passwd.tar",
+		"ssh://example.com/releases/project.tar.gz",
+		"git+ext::sh -c touch% /tmp/pwned",
+		"hg+https://127.0.0.1/team/project",
+		"hg+http://169.254.169.254/latest/meta-data",
+		"https://10.0.0.5/project.tar.gz",
+		"archive+https://[fe80::1]/download",
+		"git@192.168.1.2:owner/repo.git",
+	} {
+		if _, err := repoInfo(ctx, url); err == nil {
+			t.Errorf("repoInfo(%q) accepted", url)
+		}
+	}
+}
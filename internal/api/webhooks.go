@@ -0,0 +1,171 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	"github.com/QTest-hq/qtest/internal/db"
+	"github.com/QTest-hq/qtest/internal/netguard"
+	"github.com/QTest-hq/qtest/internal/webhook"
+)
+
+// CreateWebhookRequest registers a webhook for a repository
+type CreateWebhookRequest struct {
+	URL    string   `json:"url"`
+	Secret string   `json:"secret,omitempty"` // generated when empty
+	Events []string `json:"events,omitempty"` // e.g. generation.failed; empty for all
+}
+
+// CreateWebhookResponse is a registered webhook with the secret that signs
+// its deliveries, which isn't shown again
+type CreateWebhookResponse struct {
+	db.Webhook
+	Secret string `json:"secret"`
+}
+
+// listWebhooks lists a repository's webhooks:
+//
+//	GET /repos/{repoID}/webhooks
+func (s *Server) listWebhooks(w http.ResponseWriter, r *http.Request) {
+	repoID, ok := s.urlRepo(w, r)
+	if !ok {
+		return
+	}
+
+	hooks, err := s.store.ListWebhooks(r.Context(), repoID)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to list webhooks")
+		respondError(w, http.StatusInternalServerError, "failed to list webhooks")
+		return
+	}
+	if hooks == nil {
+		hooks = []db.Webhook{}
+	}
+	respondJSON(w, http.StatusOK, hooks)
+}
+
+// createWebhook registers a webhook POSTed when the repository's ingestion,
+// generation and mutation jobs complete or fail:
+//
+//	POST /repos/{repoID}/webhooks {"url": "https://ci.example.com/qtest", "events": ["generation.completed"]}
+func (s *Server) createWebhook(w http.ResponseWriter, r *http.Request) {
+	repoID, ok := s.urlRepo(w, r)
+	if !ok {
+		return
+	}
+
+	var req CreateWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := validateWebhook(r.Context(), &req); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if req.Secret == "" {
+		secret, err := webhook.NewSecret()
+		if err != nil {
+			log.Error().Err(err).Msg("failed to generate webhook secret")
+			respondError(w, http.StatusInternalServerError, "failed to create webhook")
+			return
+		}
+		req.Secret = secret
+	}
+
+	hook := &db.Webhook{
+		RepositoryID: repoID,
+		URL:          req.URL,
+		Secret:       req.Secret,
+		Events:       req.Events,
+	}
+	if err := s.store.CreateWebhook(r.Context(), hook); err != nil {
+		log.Error().Err(err).Msg("failed to create webhook")
+		respondError(w, http.StatusInternalServerError, "failed to create webhook")
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, CreateWebhookResponse{Webhook: *hook, Secret: hook.Secret})
+}
+
+// deleteWebhook removes a repository's webhook:
+//
+//	DELETE /repos/{repoID}/webhooks/{webhookID}
+func (s *Server) deleteWebhook(w http.ResponseWriter, r *http.Request) {
+	repoID, ok := s.urlRepo(w, r)
+	if !ok {
+		return
+	}
+
+	webhookID, err := uuid.Parse(chi.URLParam(r, "webhookID"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid webhook ID")
+		return
+	}
+
+	deleted, err := s.store.DeleteWebhook(r.Context(), repoID, webhookID)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to delete webhook")
+		respondError(w, http.StatusInternalServerError, "failed to delete webhook")
+		return
+	}
+	if !deleted {
+		respondError(w, http.StatusNotFound, "webhook not found")
+		return
+	}
+
+	respondJSON(w, http.StatusNoContent, nil)
+}
+
+// urlRepo resolves the repository named in a request's URL, responding
+// with an error when it can't
+func (s *Server) urlRepo(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	if s.store == nil {
+		respondError(w, http.StatusServiceUnavailable, "database not available")
+		return uuid.Nil, false
+	}
+
+	repoID, err := uuid.Parse(chi.URLParam(r, "repoID"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid repo ID")
+		return uuid.Nil, false
+	}
+
+	repo, err := s.store.GetRepository(r.Context(), repoID)
+	if err != nil || repo == nil {
+		respondError(w, http.StatusNotFound, "repository not found")
+		return uuid.Nil, false
+	}
+	return repoID, true
+}
+
+// validateWebhook checks a webhook's URL is absolute http(s) with a public
+// address and its events are ones that are delivered
+func validateWebhook(ctx context.Context, req *CreateWebhookRequest) error {
+	u, err := url.Parse(req.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("url must be an absolute http or https URL")
+	}
+
+	known := webhook.Events()
+	for _, event := range req.Events {
+		found := false
+		for _, k := range known {
+			if event == k {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("unknown event %q, expected one of %v", event, known)
+		}
+	}
+	return netguard.CheckURL(ctx, req.URL)
+}
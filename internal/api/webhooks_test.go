@@ -0,0 +1,54 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/QTest-hq/qtest/internal/db"
+)
+
+func TestValidateWebhook(t *testing.T) {
+	valid := []CreateWebhookRequest{
+		{URL: "https://203.0.113.10/qtest"},
+		{URL: "http://203.0.113.10:9000/hook", Events: []string{"generation.completed", "mutation.failed"}},
+	}
+	for _, req := range valid {
+		if err := validateWebhook(context.Background(), &req); err != nil {
+			t.Errorf("validateWebhook(%+v) error = %v", req, err)
+		}
+	}
+
+	invalid := []CreateWebhookRequest{
+		{URL: ""},
+		{URL: "/relative/path"},
+		{URL: "ftp://example.com/hook"},
+		{URL: "https://ci.example.com/qtest", Events: []string{"generation.started"}},
+		{URL: "https://ci.example.com/qtest", Events: []string{"planning.completed"}},
+		{URL: "http://localhost:9000/hook"},
+		{URL: "http://169.254.169.254/latest/meta-data"},
+		{URL: "http://10.0.0.5/hook"},
+	}
+	for _, req := range invalid {
+		if err := validateWebhook(context.Background(), &req); err == nil {
+			t.Errorf("validateWebhook(%+v) accepted an invalid webhook", req)
+		}
+	}
+}
+
+func TestCreateWebhookResponse_ShowsSecret(t *testing.T) {
+	hook := db.Webhook{ID: uuid.New(), URL: "https://ci.example.com/qtest", Secret: "s3cret"}
+
+	listed, _ := json.Marshal(hook)
+	if strings.Contains(string(listed), "s3cret") {
+		t.Errorf("listed webhook exposes its secret: %s", listed)
+	}
+
+	created, _ := json.Marshal(CreateWebhookResponse{Webhook: hook, Secret: hook.Secret})
+	if !strings.Contains(string(created), `"secret":"s3cret"`) {
+		t.Errorf("created webhook response missing its secret: %s", created)
+	}
+}
@@ -0,0 +1,87 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	"github.com/QTest-hq/qtest/internal/db"
+)
+
+// APIKeyPrefix starts every API key, telling them apart from session IDs
+// and JWTs
+const APIKeyPrefix = "qtk_"
+
+// apiKeyPrefixLen is how much of a key is stored in the clear to identify it
+const apiKeyPrefixLen = 12
+
+var (
+	// ErrAPIKeyRevoked indicates the API key was revoked
+	ErrAPIKeyRevoked = errors.New("api key revoked")
+	// ErrAPIKeyExpired indicates the API key has expired
+	ErrAPIKeyExpired = errors.New("api key expired")
+)
+
+// APIKeyStore looks up API keys by hash
+type APIKeyStore interface {
+	GetAPIKeyByHash(ctx context.Context, hash string) (*db.APIKey, error)
+	TouchAPIKey(ctx context.Context, id uuid.UUID) error
+}
+
+// GenerateAPIKey returns a new API key, the prefix identifying it and the
+// hash to store. The key itself is only shown once.
+func GenerateAPIKey() (key, prefix, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", "", err
+	}
+	key = APIKeyPrefix + base64.RawURLEncoding.EncodeToString(b)
+	return key, key[:apiKeyPrefixLen], HashAPIKey(key), nil
+}
+
+// HashAPIKey returns the hex SHA-256 of an API key
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// IsAPIKey reports whether a bearer token is an API key
+func IsAPIKey(token string) bool {
+	return strings.HasPrefix(token, APIKeyPrefix)
+}
+
+// authenticateAPIKey returns the principal of an active API key
+func authenticateAPIKey(ctx context.Context, store APIKeyStore, key string) (*Principal, error) {
+	k, err := store.GetAPIKeyByHash(ctx, HashAPIKey(key))
+	if err != nil {
+		return nil, err
+	}
+	if k == nil {
+		return nil, ErrInvalidToken
+	}
+	if !k.IsActive {
+		return nil, ErrAPIKeyRevoked
+	}
+	if k.IsExpired() {
+		return nil, ErrAPIKeyExpired
+	}
+
+	if err := store.TouchAPIKey(ctx, k.ID); err != nil {
+		log.Warn().Err(err).Str("key", k.KeyPrefix).Msg("failed to record api key use")
+	}
+
+	return &Principal{
+		UserID:         k.UserID,
+		Method:         MethodAPIKey,
+		OrganizationID: &k.OrganizationID,
+		APIKeyID:       &k.ID,
+		ReadOnly:       !k.HasScope(db.ScopeWrite),
+	}, nil
+}
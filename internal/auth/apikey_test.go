@@ -0,0 +1,88 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/QTest-hq/qtest/internal/db"
+)
+
+// fakeAPIKeys holds API keys by hash
+type fakeAPIKeys map[string]*db.APIKey
+
+func (f fakeAPIKeys) GetAPIKeyByHash(ctx context.Context, hash string) (*db.APIKey, error) {
+	return f[hash], nil
+}
+
+func (f fakeAPIKeys) TouchAPIKey(ctx context.Context, id uuid.UUID) error {
+	return nil
+}
+
+func TestGenerateAPIKey(t *testing.T) {
+	key, prefix, hash, err := GenerateAPIKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsAPIKey(key) || !strings.HasPrefix(key, prefix) || IsJWT(key) {
+		t.Errorf("key %q with prefix %q", key, prefix)
+	}
+	if hash != HashAPIKey(key) || strings.Contains(hash, key) {
+		t.Errorf("hash = %q", hash)
+	}
+
+	other, _, _, _ := GenerateAPIKey()
+	if other == key {
+		t.Error("generated the same key twice")
+	}
+}
+
+func TestMiddleware_APIKey(t *testing.T) {
+	orgID := uuid.New()
+	past := time.Now().Add(-time.Hour)
+	keys := fakeAPIKeys{}
+	add := func(k db.APIKey) string {
+		key, _, hash, _ := GenerateAPIKey()
+		k.ID, k.OrganizationID, k.UserID = uuid.New(), orgID, uuid.New()
+		keys[hash] = &k
+		return key
+	}
+	readWrite := add(db.APIKey{IsActive: true, Scopes: []string{db.ScopeRead, db.ScopeWrite}})
+	readOnly := add(db.APIKey{IsActive: true, Scopes: []string{db.ScopeRead}})
+	revoked := add(db.APIKey{IsActive: false, Scopes: []string{db.ScopeRead}})
+	expired := add(db.APIKey{IsActive: true, Scopes: []string{db.ScopeRead}, ExpiresAt: &past})
+
+	middleware := NewMiddleware(NewSessionStore(SessionStoreConfig{}), nil)
+	middleware.SetAPIKeys(keys)
+
+	var got *Principal
+	handler := middleware.OptionalAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, _ = GetPrincipalFromContext(r.Context())
+	}))
+	call := func(key string) int {
+		got = nil
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("Authorization", "Bearer "+key)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if code := call(readWrite); code != http.StatusOK || got == nil || got.Method != MethodAPIKey || got.ReadOnly ||
+		got.OrganizationID == nil || *got.OrganizationID != orgID {
+		t.Errorf("read-write key: %d, principal %+v", code, got)
+	}
+	if code := call(readOnly); code != http.StatusOK || got == nil || !got.ReadOnly {
+		t.Errorf("read-only key: %d, principal %+v", code, got)
+	}
+	for name, key := range map[string]string{"revoked": revoked, "expired": expired, "unknown": APIKeyPrefix + "nope"} {
+		if code := call(key); code != http.StatusUnauthorized {
+			t.Errorf("%s key: status %d, want 401", name, code)
+		}
+	}
+}
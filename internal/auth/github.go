@@ -13,6 +13,8 @@ import (
 	"time"
 
 	"github.com/rs/zerolog/log"
+
+	"github.com/QTest-hq/qtest/internal/github"
 )
 
 const (
@@ -247,29 +249,39 @@ func (p *GitHubProvider) ValidateToken(ctx context.Context, accessToken string)
 	return resp.StatusCode == http.StatusOK
 }
 
-// ListUserRepos lists repositories the user has access to
+// ListUserRepos lists repositories the user has access to, across every page
 func (p *GitHubProvider) ListUserRepos(ctx context.Context, accessToken string) ([]GitHubRepo, error) {
-	req, err := http.NewRequestWithContext(ctx, "GET", "https://api.github.com/user/repos?per_page=100", nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
+	var repos []GitHubRepo
+	url := "https://api.github.com/user/repos?per_page=100"
 
-	req.Header.Set("Authorization", "Bearer "+accessToken)
-	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	for url != "" {
+		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
 
-	resp, err := p.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch repos: %w", err)
-	}
-	defer resp.Body.Close()
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+		req.Header.Set("Accept", "application/vnd.github.v3+json")
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to list repos with status: %d", resp.StatusCode)
-	}
+		resp, err := p.httpClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch repos: %w", err)
+		}
 
-	var repos []GitHubRepo
-	if err := json.NewDecoder(resp.Body).Decode(&repos); err != nil {
-		return nil, fmt.Errorf("failed to decode repos: %w", err)
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, fmt.Errorf("failed to list repos with status: %d", resp.StatusCode)
+		}
+
+		var page []GitHubRepo
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode repos: %w", err)
+		}
+
+		repos = append(repos, page...)
+		url = github.NextPageURL(resp.Header)
 	}
 
 	return repos, nil
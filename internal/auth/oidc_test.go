@@ -0,0 +1,233 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/QTest-hq/qtest/internal/db"
+)
+
+// testIssuer serves an OIDC discovery document and JWKS for an RSA key
+func testIssuer(t *testing.T) (*httptest.Server, *rsa.PrivateKey) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/.well-known/openid-configuration":
+			json.NewEncoder(w).Encode(map[string]string{"issuer": server.URL, "jwks_uri": server.URL + "/keys"})
+		case "/keys":
+			json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{{
+				"kty": "RSA", "kid": "key-1", "use": "sig",
+				"n": b64(key.N.Bytes()),
+				"e": b64(big.NewInt(int64(key.E)).Bytes()),
+			}}})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, key
+}
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// signRS256 returns a JWT of claims signed with key
+func signRS256(t *testing.T, key *rsa.PrivateKey, kid string, claims map[string]interface{}) string {
+	t.Helper()
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "kid": kid, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signed := b64(header) + "." + b64(payload)
+	digest := sha256.Sum256([]byte(signed))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed + "." + b64(sig)
+}
+
+// newTestVerifier creates a verifier, failing the test on a bad config
+func newTestVerifier(t *testing.T, cfg OIDCConfig) *OIDCVerifier {
+	t.Helper()
+	v, err := NewOIDCVerifier(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestNewOIDCVerifier_RequiresAudience(t *testing.T) {
+	if _, err := NewOIDCVerifier(OIDCConfig{Issuer: "https://idp.example.com"}); err == nil {
+		t.Error("verifier created without an audience")
+	}
+}
+
+func TestOIDCVerifier_Verify(t *testing.T) {
+	server, key := testIssuer(t)
+	v := newTestVerifier(t, OIDCConfig{Issuer: server.URL, Audience: "qtest"})
+
+	exp := float64(time.Now().Add(time.Hour).Unix())
+	valid := map[string]interface{}{
+		"iss": server.URL, "aud": []string{"qtest", "other"}, "sub": "user-1", "exp": exp,
+		"preferred_username": "jdoe", "email": "jdoe@example.com",
+	}
+
+	claims, err := v.Verify(context.Background(), signRS256(t, key, "key-1", valid))
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if claims.Subject != "user-1" || claims.Username != "jdoe" || claims.Email != "jdoe@example.com" {
+		t.Errorf("claims = %+v", claims)
+	}
+
+	with := func(name string, value interface{}) map[string]interface{} {
+		c := make(map[string]interface{})
+		for k, v := range valid {
+			c[k] = v
+		}
+		if value == nil {
+			delete(c, name)
+		} else {
+			c[name] = value
+		}
+		return c
+	}
+	for name, token := range map[string]string{
+		"other issuer":   signRS256(t, key, "key-1", with("iss", "https://evil.example.com")),
+		"other audience": signRS256(t, key, "key-1", with("aud", "someone-else")),
+		"no audience":    signRS256(t, key, "key-1", with("aud", nil)),
+		"expired":        signRS256(t, key, "key-1", with("exp", float64(time.Now().Add(-time.Hour).Unix()))),
+		"no expiry":      signRS256(t, key, "key-1", with("exp", nil)),
+		"no subject":     signRS256(t, key, "key-1", with("sub", nil)),
+		"unknown key":    signRS256(t, key, "key-2", valid),
+		"tampered":       signRS256(t, key, "key-1", valid)[:40] + "x" + signRS256(t, key, "key-1", valid)[41:],
+	} {
+		if _, err := v.Verify(context.Background(), token); err == nil {
+			t.Errorf("%s: verified, want an error", name)
+		}
+	}
+}
+
+func TestOIDCVerifier_RejectsSymmetricAlgorithms(t *testing.T) {
+	server, _ := testIssuer(t)
+	v := newTestVerifier(t, OIDCConfig{Issuer: server.URL, Audience: "qtest"})
+
+	for _, alg := range []string{"HS256", "none"} {
+		header, _ := json.Marshal(map[string]string{"alg": alg, "kid": "key-1"})
+		payload, _ := json.Marshal(map[string]interface{}{"iss": server.URL, "sub": "u", "exp": time.Now().Add(time.Hour).Unix()})
+		token := b64(header) + "." + b64(payload) + "." + b64([]byte("signature"))
+		if _, err := v.Verify(context.Background(), token); err == nil {
+			t.Errorf("%s token verified, want an error", alg)
+		}
+	}
+}
+
+func TestVerifySignature_ES256(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest := sha256.Sum256([]byte("header.payload"))
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig := make([]byte, 64)
+	r.FillBytes(sig[:32])
+	s.FillBytes(sig[32:])
+
+	if err := verifySignature("ES256", &key.PublicKey, "header.payload", sig); err != nil {
+		t.Errorf("verifySignature: %v", err)
+	}
+	if err := verifySignature("ES256", &key.PublicKey, "header.other", sig); err == nil {
+		t.Error("signature over other content verified")
+	}
+}
+
+// fakeUsers creates a user per OIDC subject
+type fakeUsers struct {
+	upserts int
+	users   map[string]*db.User
+}
+
+func (f *fakeUsers) UpsertUserFromOIDC(ctx context.Context, issuer, subject, login, email, name string) (*db.User, error) {
+	f.upserts++
+	if u, ok := f.users[subject]; ok {
+		return u, nil
+	}
+	u := &db.User{ID: uuid.New(), GitHubLogin: login, IsActive: true}
+	f.users[subject] = u
+	return u, nil
+}
+
+func TestMiddleware_OIDC(t *testing.T) {
+	server, key := testIssuer(t)
+	users := &fakeUsers{users: make(map[string]*db.User)}
+	middleware := NewMiddleware(NewSessionStore(SessionStoreConfig{}), nil)
+	middleware.SetOIDC(newTestVerifier(t, OIDCConfig{Issuer: server.URL, Audience: "qtest"}), users)
+
+	var got *Principal
+	handler := middleware.RequireAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, _ = GetPrincipalFromContext(r.Context())
+	}))
+
+	token := signRS256(t, key, "key-1", map[string]interface{}{
+		"iss": server.URL, "aud": "qtest", "sub": "user-1", "exp": time.Now().Add(time.Hour).Unix(), "email": "jdoe@example.com",
+	})
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200", rec.Code)
+		}
+	}
+
+	if got == nil || got.Method != MethodOIDC || got.UserID != users.users["user-1"].ID || got.Login != "jdoe@example.com" {
+		t.Errorf("principal = %+v", got)
+	}
+	if users.upserts != 1 {
+		t.Errorf("user upserted %d times, want once", users.upserts)
+	}
+}
+
+func TestOIDCVerifier_UnknownKeyDoesNotRefetchAtOnce(t *testing.T) {
+	fetches := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		w.Write([]byte(`{"keys": []}`))
+	}))
+	defer server.Close()
+
+	v := newTestVerifier(t, OIDCConfig{Issuer: "https://idp.example.com", Audience: "qtest", JWKSURL: server.URL})
+	for i := 0; i < 3; i++ {
+		if _, err := v.key(context.Background(), "missing"); !errors.Is(err, ErrUnknownKey) {
+			t.Fatalf("key: %v, want ErrUnknownKey", err)
+		}
+	}
+	if fetches != 1 {
+		t.Errorf("keys fetched %d times, want once", fetches)
+	}
+}
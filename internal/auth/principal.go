@@ -0,0 +1,49 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Ways a request can authenticate
+const (
+	MethodSession = "session" // GitHub login session
+	MethodAPIKey  = "api_key"
+	MethodOIDC    = "oidc" // token from the configured identity provider
+)
+
+// PrincipalKey is the context key for the principal
+const PrincipalKey contextKey = "principal"
+
+// Principal is the user a request acts for, however it authenticated
+type Principal struct {
+	UserID uuid.UUID `json:"user_id"`
+	Login  string    `json:"login,omitempty"`
+	Method string    `json:"method"`
+
+	// OrganizationID is the only organization an API key acts in; nil for
+	// users, who act in every organization they're a member of
+	OrganizationID *uuid.UUID `json:"organization_id,omitempty"`
+
+	// APIKeyID is the key the request authenticated with
+	APIKeyID *uuid.UUID `json:"api_key_id,omitempty"`
+
+	// ReadOnly is set for API keys without the write scope
+	ReadOnly bool `json:"read_only,omitempty"`
+}
+
+// GetPrincipalFromContext retrieves the principal from context
+func GetPrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(PrincipalKey).(*Principal)
+	return p, ok
+}
+
+// sessionPrincipal is the principal of a GitHub login session
+func sessionPrincipal(s *Session) *Principal {
+	p := &Principal{UserID: s.UserID, Method: MethodSession}
+	if s.GitHubUser != nil {
+		p.Login = s.GitHubUser.Login
+	}
+	return p
+}
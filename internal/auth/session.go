@@ -234,59 +234,159 @@ func GetUserFromContext(ctx context.Context) (*GitHubUser, bool) {
 type Middleware struct {
 	sessions *SessionStore
 	github   *GitHubProvider
+	apiKeys  APIKeyStore
+	oidc     *OIDCVerifier
+	users    OIDCUserStore
+
+	// oidcUsers caches the user IDs of OIDC subjects, so verified tokens
+	// don't write to the database on every request
+	mu        sync.Mutex
+	oidcUsers map[string]cachedUser
 }
 
+// cachedUser is the user of an OIDC subject, until expires
+type cachedUser struct {
+	id      uuid.UUID
+	expires time.Time
+}
+
+// oidcUserTTL is how long an OIDC subject's user is cached
+const oidcUserTTL = 10 * time.Minute
+
 // NewMiddleware creates a new auth middleware
 func NewMiddleware(sessions *SessionStore, github *GitHubProvider) *Middleware {
 	return &Middleware{
-		sessions: sessions,
-		github:   github,
+		sessions:  sessions,
+		github:    github,
+		oidcUsers: make(map[string]cachedUser),
 	}
 }
 
+// SetAPIKeys accepts API keys from store as bearer tokens
+func (m *Middleware) SetAPIKeys(store APIKeyStore) {
+	m.apiKeys = store
+}
+
+// SetOIDC accepts the identity provider's tokens as bearer tokens, creating
+// their users in users
+func (m *Middleware) SetOIDC(verifier *OIDCVerifier, users OIDCUserStore) {
+	m.oidc = verifier
+	m.users = users
+}
+
 // RequireAuth is middleware that requires authentication
 func (m *Middleware) RequireAuth(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		session, err := m.extractSession(r)
+		ctx, err := m.authenticate(r)
 		if err != nil {
 			writeAuthError(w, http.StatusUnauthorized, "authentication required")
 			return
 		}
-
-		// Add session and user to context
-		ctx := context.WithValue(r.Context(), SessionKey, session)
-		if session.GitHubUser != nil {
-			ctx = context.WithValue(ctx, UserKey, session.GitHubUser)
-		}
-
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
-// OptionalAuth is middleware that adds auth info if present but doesn't require it
+// OptionalAuth is middleware that adds auth info if present but doesn't
+// require it. Credentials that are present must be valid.
 func (m *Middleware) OptionalAuth(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		session, err := m.extractSession(r)
-		if err == nil && session != nil {
-			ctx := context.WithValue(r.Context(), SessionKey, session)
-			if session.GitHubUser != nil {
-				ctx = context.WithValue(ctx, UserKey, session.GitHubUser)
-			}
-			r = r.WithContext(ctx)
+		ctx, err := m.authenticate(r)
+		if err == errNoCredentials {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if err != nil {
+			writeAuthError(w, http.StatusUnauthorized, "invalid credentials")
+			return
 		}
-		next.ServeHTTP(w, r)
+		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
-func (m *Middleware) extractSession(r *http.Request) (*Session, error) {
-	// Try Authorization header first (Bearer token)
-	authHeader := r.Header.Get("Authorization")
-	if authHeader != "" {
+// errNoCredentials indicates a request has no bearer token or session cookie
+var errNoCredentials = errors.New("no credentials")
+
+// authenticate returns the request's context with its principal and, for
+// login sessions, session and user. Bearer tokens can be API keys, the
+// identity provider's JWTs or session IDs.
+func (m *Middleware) authenticate(r *http.Request) (context.Context, error) {
+	ctx := r.Context()
+
+	token := ""
+	if authHeader := r.Header.Get("Authorization"); authHeader != "" {
 		const prefix = "Bearer "
 		if len(authHeader) > len(prefix) && authHeader[:len(prefix)] == prefix {
-			sessionID := authHeader[len(prefix):]
-			return m.sessions.Get(sessionID)
+			token = authHeader[len(prefix):]
+		}
+	}
+
+	var principal *Principal
+	switch {
+	case token != "" && IsAPIKey(token) && m.apiKeys != nil:
+		p, err := authenticateAPIKey(ctx, m.apiKeys, token)
+		if err != nil {
+			log.Debug().Err(err).Msg("api key rejected")
+			return nil, err
 		}
+		principal = p
+	case token != "" && IsJWT(token) && m.oidc != nil:
+		p, err := m.authenticateOIDC(ctx, token)
+		if err != nil {
+			log.Debug().Err(err).Msg("oidc token rejected")
+			return nil, err
+		}
+		principal = p
+	default:
+		session, err := m.extractSession(r, token)
+		if err != nil {
+			return nil, err
+		}
+		ctx = context.WithValue(ctx, SessionKey, session)
+		if session.GitHubUser != nil {
+			ctx = context.WithValue(ctx, UserKey, session.GitHubUser)
+		}
+		principal = sessionPrincipal(session)
+	}
+
+	return context.WithValue(ctx, PrincipalKey, principal), nil
+}
+
+// authenticateOIDC returns the principal of a token from the identity
+// provider, creating its user the first time they're seen
+func (m *Middleware) authenticateOIDC(ctx context.Context, token string) (*Principal, error) {
+	claims, err := m.oidc.Verify(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	key := claims.Issuer + "|" + claims.Subject
+	m.mu.Lock()
+	cached, ok := m.oidcUsers[key]
+	m.mu.Unlock()
+	if !ok || time.Now().After(cached.expires) {
+		if m.users == nil {
+			return nil, errors.New("oidc users can't be stored")
+		}
+		user, err := m.users.UpsertUserFromOIDC(ctx, claims.Issuer, claims.Subject, claims.Username, claims.Email, claims.Name)
+		if err != nil {
+			return nil, err
+		}
+		if !user.IsActive {
+			return nil, errors.New("user is deactivated")
+		}
+		cached = cachedUser{id: user.ID, expires: time.Now().Add(oidcUserTTL)}
+		m.mu.Lock()
+		m.oidcUsers[key] = cached
+		m.mu.Unlock()
+	}
+
+	return &Principal{UserID: cached.id, Login: claims.Username, Method: MethodOIDC}, nil
+}
+
+func (m *Middleware) extractSession(r *http.Request, token string) (*Session, error) {
+	// Try Authorization header first (Bearer token)
+	if token != "" {
+		return m.sessions.Get(token)
 	}
 
 	// Try cookie
@@ -295,7 +395,7 @@ func (m *Middleware) extractSession(r *http.Request) (*Session, error) {
 		return m.sessions.Get(cookie.Value)
 	}
 
-	return nil, ErrInvalidToken
+	return nil, errNoCredentials
 }
 
 func writeAuthError(w http.ResponseWriter, status int, message string) {
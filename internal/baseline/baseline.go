@@ -0,0 +1,140 @@
+// Package baseline records the quality metrics each branch measured, per
+// commit, so gates can compare a change against its base branch instead of
+// fixed numbers. The history is a JSON file meant to be committed with the
+// repository.
+package baseline
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/QTest-hq/qtest/internal/github"
+)
+
+// DefaultPath is where baselines are stored relative to a repository
+const DefaultPath = ".qtest/baselines.json"
+
+// MaxEntries is how many commits a branch's history keeps
+const MaxEntries = 50
+
+// Entry is what one commit measured. A metric that wasn't measured is
+// omitted.
+type Entry struct {
+	Commit        string    `json:"commit,omitempty"`
+	RecordedAt    time.Time `json:"recorded_at"`
+	Coverage      *float64  `json:"coverage,omitempty"`       // percent
+	MutationScore *float64  `json:"mutation_score,omitempty"` // percent
+	FlakeRate     *float64  `json:"flake_rate,omitempty"`     // percent
+}
+
+// Store holds each branch's entries, oldest first
+type Store struct {
+	Version  string             `json:"version"`
+	Branches map[string][]Entry `json:"branches"`
+}
+
+// New creates an empty store
+func New() *Store {
+	return &Store{Version: "1.0", Branches: make(map[string][]Entry)}
+}
+
+// Load reads a store from disk. A missing file yields an empty store.
+func Load(path string) (*Store, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return New(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read baselines: %w", err)
+	}
+
+	s := New()
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("invalid baselines: %w", err)
+	}
+	if s.Branches == nil {
+		s.Branches = make(map[string][]Entry)
+	}
+	return s, nil
+}
+
+// Save writes the store to disk, creating parent directories
+func (s *Store) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create baselines directory: %w", err)
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal baselines: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write baselines: %w", err)
+	}
+	return nil
+}
+
+// Record adds an entry to a branch. Recording a commit again replaces its
+// entry; only the latest MaxEntries are kept.
+func (s *Store) Record(branch string, e Entry) {
+	entries := s.Branches[branch]
+	if e.Commit != "" {
+		kept := entries[:0]
+		for _, old := range entries {
+			if old.Commit != e.Commit {
+				kept = append(kept, old)
+			}
+		}
+		entries = kept
+	}
+	entries = append(entries, e)
+	if len(entries) > MaxEntries {
+		entries = entries[len(entries)-MaxEntries:]
+	}
+	s.Branches[branch] = entries
+}
+
+// Find returns a branch's entry for a commit, or its latest entry when
+// commit is empty. It returns nil if there is none.
+func (s *Store) Find(branch, commit string) *Entry {
+	entries := s.Branches[branch]
+	for i := len(entries) - 1; i >= 0; i-- {
+		if commit == "" || entries[i].Commit == commit {
+			e := entries[i]
+			return &e
+		}
+	}
+	return nil
+}
+
+// FromReport records what a quality report measured
+func FromReport(commit string, report github.QualityReport) Entry {
+	e := Entry{Commit: commit, RecordedAt: time.Now().UTC()}
+	if report.CoverageMeasured {
+		e.Coverage = &report.Coverage
+	}
+	if report.MutationMeasured {
+		e.MutationScore = &report.MutationScore
+	}
+	if report.FlakeMeasured {
+		e.FlakeRate = &report.FlakeRate
+	}
+	return e
+}
+
+// Report returns the entry as a quality report, for a gate's baseline
+func (e Entry) Report() *github.QualityReport {
+	report := &github.QualityReport{}
+	if e.Coverage != nil {
+		report.Coverage, report.CoverageMeasured = *e.Coverage, true
+	}
+	if e.MutationScore != nil {
+		report.MutationScore, report.MutationMeasured = *e.MutationScore, true
+	}
+	if e.FlakeRate != nil {
+		report.FlakeRate, report.FlakeMeasured = *e.FlakeRate, true
+	}
+	return report
+}
@@ -0,0 +1,63 @@
+package baseline
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/QTest-hq/qtest/internal/github"
+)
+
+func TestStore_RecordAndFind(t *testing.T) {
+	s := New()
+	if s.Find("main", "") != nil {
+		t.Error("an empty store should have no baseline")
+	}
+
+	s.Record("main", FromReport("aaa", github.QualityReport{Coverage: 70, CoverageMeasured: true}))
+	s.Record("main", FromReport("bbb", github.QualityReport{Coverage: 72, CoverageMeasured: true, FlakeMeasured: true}))
+	s.Record("main", FromReport("aaa", github.QualityReport{Coverage: 71, CoverageMeasured: true}))
+
+	latest := s.Find("main", "")
+	if latest == nil || latest.Commit != "aaa" || *latest.Coverage != 71 {
+		t.Fatalf("latest = %+v, want the re-recorded aaa", latest)
+	}
+	if len(s.Branches["main"]) != 2 {
+		t.Errorf("entries = %d, want 2 after re-recording a commit", len(s.Branches["main"]))
+	}
+
+	report := s.Find("main", "bbb").Report()
+	if !report.CoverageMeasured || report.Coverage != 72 || report.MutationMeasured || !report.FlakeMeasured || report.FlakeRate != 0 {
+		t.Errorf("bbb report = %+v", report)
+	}
+
+	for i := 0; i < MaxEntries+5; i++ {
+		s.Record("dev", Entry{Commit: fmt.Sprint(i)})
+	}
+	if n := len(s.Branches["dev"]); n != MaxEntries || s.Branches["dev"][0].Commit != "5" {
+		t.Errorf("dev keeps %d entries from %s, want the latest %d", n, s.Branches["dev"][0].Commit, MaxEntries)
+	}
+}
+
+func TestStore_SaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), DefaultPath)
+
+	s, err := Load(path)
+	if err != nil || len(s.Branches) != 0 {
+		t.Fatalf("Load(missing) = %+v, %v, want an empty store", s, err)
+	}
+
+	s.Record("main", FromReport("abc", github.QualityReport{MutationScore: 64.5, MutationMeasured: true}))
+	if err := s.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := loaded.Find("main", "abc")
+	if e == nil || e.Coverage != nil || e.MutationScore == nil || *e.MutationScore != 64.5 {
+		t.Errorf("loaded = %+v", e)
+	}
+}
@@ -0,0 +1,340 @@
+package buildsys
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// bazelBuildFiles are the files a Bazel package's rules are read from, in
+// the order Bazel prefers them
+var bazelBuildFiles = []string{"BUILD.bazel", "BUILD"}
+
+// registerBazel adds go_test and py_test rules for the tests to the BUILD
+// files of their packages
+func registerBazel(root string, testFiles []string, res *Result) error {
+	byBuild := make(map[string][]string)
+	var order []string
+	for _, file := range testFiles {
+		dir, name := findUp(root, filepath.Dir(file), bazelBuildFiles...)
+		lang := testLanguage(file)
+		// A Go test belongs to its directory's package, which Bazel must build
+		if dir == "" || lang == "js" || lang == "" || (lang == "go" && dir != filepath.Dir(file)) {
+			res.Skipped = append(res.Skipped, file)
+			continue
+		}
+		build := filepath.Join(dir, name)
+		if _, ok := byBuild[build]; !ok {
+			order = append(order, build)
+		}
+		byBuild[build] = append(byBuild[build], file)
+	}
+
+	goLoad := "@io_bazel_rules_go//go:def.bzl"
+	if fileExists(filepath.Join(root, "MODULE.bazel")) {
+		goLoad = "@rules_go//go:def.bzl"
+	}
+
+	for _, build := range order {
+		data, err := os.ReadFile(build)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", build, err)
+		}
+		content := string(data)
+		dir := filepath.Dir(build)
+
+		var goSrcs []string
+		for _, file := range byBuild[build] {
+			src := filepath.ToSlash(mustRel(dir, file))
+			switch testLanguage(file) {
+			case "go":
+				goSrcs = append(goSrcs, src)
+			case "python":
+				var ok bool
+				if content, ok = addPyTest(content, src); !ok {
+					res.Skipped = append(res.Skipped, file)
+				}
+			}
+		}
+		if len(goSrcs) > 0 {
+			content = addGoTest(content, filepath.Base(dir), goSrcs, goLoad)
+		}
+
+		if content == string(data) {
+			continue
+		}
+		if err := os.WriteFile(build, []byte(content), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", build, err)
+		}
+		res.Changed = append(res.Changed, build)
+	}
+	return nil
+}
+
+// addGoTest adds srcs to the package's go_test rule, creating one that
+// embeds the package's go_library when there is none
+func addGoTest(content, pkgName string, srcs []string, defaultLoad string) string {
+	if start, end, ok := findRule(content, "go_test"); ok {
+		return content[:start] + addToList(content[start:end], "srcs", srcs) + content[end:]
+	}
+
+	var rule strings.Builder
+	fmt.Fprintf(&rule, "go_test(\n    name = %q,\n    srcs = %s,\n", pkgName+"_test", formatList(srcs, "    "))
+	if start, end, ok := findRule(content, "go_library"); ok {
+		if lib := ruleName(content[start:end]); lib != "" {
+			fmt.Fprintf(&rule, "    embed = [%q],\n", ":"+lib)
+		}
+	}
+	rule.WriteString(")\n")
+
+	content = appendRule(content, rule.String())
+	return ensureLoad(content, "//go:def.bzl", "go_test", defaultLoad)
+}
+
+// addPyTest adds a py_test rule running src. It reports false when src
+// can't be registered because another rule already has its name.
+func addPyTest(content, src string) (string, bool) {
+	for _, rule := range findRules(content, "py_test") {
+		if containsString(listItems(rule, "srcs"), src) {
+			return content, true
+		}
+	}
+	name := strings.TrimSuffix(filepath.Base(src), ".py")
+	if regexp.MustCompile(`\bname\s*=\s*"` + regexp.QuoteMeta(name) + `"`).MatchString(content) {
+		return content, false
+	}
+
+	var rule strings.Builder
+	fmt.Fprintf(&rule, "py_test(\n    name = %q,\n    srcs = [%q],\n", name, src)
+	if start, end, ok := findRule(content, "py_library"); ok {
+		if lib := ruleName(content[start:end]); lib != "" {
+			fmt.Fprintf(&rule, "    deps = [%q],\n", ":"+lib)
+		}
+	}
+	rule.WriteString(")\n")
+	content = appendRule(content, rule.String())
+
+	// py_test comes from rules_python when the file loads its rules from
+	// there, else it's native
+	switch {
+	case loadPattern("python:defs.bzl").MatchString(content):
+		content = ensureLoad(content, "python:defs.bzl", "py_test", "")
+	case loadPattern("python:py_library.bzl").MatchString(content):
+		label := loadPattern("python:py_library.bzl").FindStringSubmatch(content)[1]
+		content = ensureLoad(content, "python:py_test.bzl", "py_test", strings.TrimSuffix(label, "py_library.bzl")+"py_test.bzl")
+	}
+	return content, true
+}
+
+// findRule returns the bounds of the first call of kind starting a line
+func findRule(content, kind string) (int, int, bool) {
+	loc := regexp.MustCompile(`(?m)^` + regexp.QuoteMeta(kind) + `\(`).FindStringIndex(content)
+	if loc == nil {
+		return 0, 0, false
+	}
+	end := matchClose(content, loc[1]-1)
+	if end < 0 {
+		return 0, 0, false
+	}
+	return loc[0], end + 1, true
+}
+
+// findRules returns every call of kind starting a line
+func findRules(content, kind string) []string {
+	var rules []string
+	for {
+		start, end, ok := findRule(content, kind)
+		if !ok {
+			return rules
+		}
+		rules = append(rules, content[start:end])
+		content = content[end:]
+	}
+}
+
+// matchClose returns the index of the bracket closing the one at open,
+// skipping strings and comments, or -1
+func matchClose(s string, open int) int {
+	depth := 0
+	for i := open; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		case '"', '\'':
+			for i++; i < len(s) && s[i] != c; i++ {
+				if s[i] == '\\' {
+					i++
+				}
+			}
+		case '#':
+			for i < len(s) && s[i] != '\n' {
+				i++
+			}
+		}
+	}
+	return -1
+}
+
+var namePattern = regexp.MustCompile(`\bname\s*=\s*"([^"]+)"`)
+
+// ruleName returns a rule's name attribute
+func ruleName(rule string) string {
+	if m := namePattern.FindStringSubmatch(rule); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+var stringPattern = regexp.MustCompile(`"([^"]*)"`)
+
+// listBounds returns the bounds of a rule's list attribute. ok is false
+// when the attribute is missing or isn't a plain list, as with glob().
+func listBounds(rule, attr string) (int, int, bool) {
+	loc := regexp.MustCompile(`\b` + attr + `\s*=\s*`).FindStringIndex(rule)
+	if loc == nil || loc[1] >= len(rule) || rule[loc[1]] != '[' {
+		return 0, 0, false
+	}
+	end := matchClose(rule, loc[1])
+	if end < 0 || strings.HasPrefix(strings.TrimSpace(rule[end+1:]), "+") {
+		return 0, 0, false
+	}
+	return loc[1], end + 1, true
+}
+
+// listItems returns the strings in a rule's list attribute
+func listItems(rule, attr string) []string {
+	start, end, ok := listBounds(rule, attr)
+	if !ok {
+		return nil
+	}
+	var items []string
+	for _, m := range stringPattern.FindAllStringSubmatch(rule[start:end], -1) {
+		items = append(items, m[1])
+	}
+	return items
+}
+
+// addToList adds items to a rule's list attribute, sorted. A computed
+// attribute, such as a glob, is assumed to cover them already.
+func addToList(rule, attr string, items []string) string {
+	start, end, ok := listBounds(rule, attr)
+	if !ok {
+		return rule
+	}
+	existing := listItems(rule, attr)
+	merged := append([]string(nil), existing...)
+	for _, item := range items {
+		if !containsString(merged, item) {
+			merged = append(merged, item)
+		}
+	}
+	if len(merged) == len(existing) {
+		return rule
+	}
+	sort.Strings(merged)
+
+	lineStart := strings.LastIndex(rule[:start], "\n") + 1
+	indent := rule[lineStart : lineStart+len(rule[lineStart:])-len(strings.TrimLeft(rule[lineStart:], " \t"))]
+	return rule[:start] + formatList(merged, indent) + rule[end:]
+}
+
+// formatList renders a list of strings as buildifier does: one item on the
+// attribute's line, more one per line
+func formatList(items []string, indent string) string {
+	if len(items) == 1 {
+		return fmt.Sprintf("[%q]", items[0])
+	}
+	var b strings.Builder
+	b.WriteString("[\n")
+	for _, item := range items {
+		fmt.Fprintf(&b, "%s    %q,\n", indent, item)
+	}
+	b.WriteString(indent + "]")
+	return b.String()
+}
+
+// appendRule adds a rule at the end of a BUILD file
+func appendRule(content, rule string) string {
+	content = strings.TrimRight(content, "\n")
+	if content == "" {
+		return rule
+	}
+	return content + "\n\n" + rule
+}
+
+// loadPattern matches a load of a .bzl file whose label ends in suffix
+func loadPattern(suffix string) *regexp.Regexp {
+	return regexp.MustCompile(`load\(\s*"([^"]*` + regexp.QuoteMeta(suffix) + `)"`)
+}
+
+// ensureLoad makes a BUILD file load symbol from the .bzl file ending in
+// suffix, adding it to an existing load or, when there is none and
+// defaultLabel is set, adding a load of defaultLabel
+func ensureLoad(content, suffix, symbol, defaultLabel string) string {
+	if loc := loadPattern(suffix).FindStringIndex(content); loc != nil {
+		end := matchClose(content, loc[0]+len("load"))
+		if end < 0 {
+			return content
+		}
+		call := content[loc[0] : end+1]
+		if strings.Contains(call, fmt.Sprintf("%q", symbol)) {
+			return content
+		}
+		if strings.Contains(call, "=") {
+			// Aliased symbols; leave the load alone and add another
+			return addLoad(content, loadPattern(suffix).FindStringSubmatch(call)[1], symbol)
+		}
+		args := stringPattern.FindAllString(call, -1)
+		args = append(args, fmt.Sprintf("%q", symbol))
+		sort.Strings(args[1:])
+		return content[:loc[0]] + "load(" + strings.Join(args, ", ") + ")" + content[end+1:]
+	}
+	if defaultLabel == "" {
+		return content
+	}
+	return addLoad(content, defaultLabel, symbol)
+}
+
+// addLoad adds a load statement after the file's leading comments
+func addLoad(content, label, symbol string) string {
+	stmt := fmt.Sprintf("load(%q, %q)\n", label, symbol)
+	offset := 0
+	for offset < len(content) && strings.HasPrefix(content[offset:], "#") {
+		next := strings.IndexByte(content[offset:], '\n')
+		if next < 0 {
+			offset = len(content)
+			break
+		}
+		offset += next + 1
+	}
+	rest := content[offset:]
+	if !strings.HasPrefix(rest, "load(") && !strings.HasPrefix(rest, "\n") {
+		stmt += "\n"
+	}
+	return content[:offset] + stmt + rest
+}
+
+func containsString(items []string, s string) bool {
+	for _, item := range items {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// mustRel returns path relative to base, or path when it can't be
+func mustRel(base, path string) string {
+	if rel, err := filepath.Rel(base, path); err == nil {
+		return rel
+	}
+	return path
+}
@@ -0,0 +1,92 @@
+// Package buildsys registers generated tests with monorepo build systems,
+// Bazel and Nx, which only run tests their build files declare, and places
+// JUnit tests where Maven and Gradle run them
+package buildsys
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// System is a build system tests must be registered with
+type System string
+
+const (
+	SystemNone  System = ""
+	SystemBazel System = "bazel"
+	SystemNx    System = "nx"
+)
+
+// Result is what registering tests changed
+type Result struct {
+	System  System
+	Changed []string // build files written
+	Skipped []string // tests left unregistered: outside any package, or in a language without rules
+}
+
+// Detect returns the build system of the repository at root, Bazel before
+// Nx when a repository has both
+func Detect(root string) System {
+	for _, name := range []string{"MODULE.bazel", "WORKSPACE.bazel", "WORKSPACE"} {
+		if fileExists(filepath.Join(root, name)) {
+			return SystemBazel
+		}
+	}
+	if fileExists(filepath.Join(root, "nx.json")) {
+		return SystemNx
+	}
+	return SystemNone
+}
+
+// Register adds the build targets that run testFiles to the build files of
+// the repository at root. Tests already covered by a target are left alone.
+func Register(root string, testFiles []string) (*Result, error) {
+	res := &Result{System: Detect(root)}
+	switch res.System {
+	case SystemBazel:
+		return res, registerBazel(root, testFiles, res)
+	case SystemNx:
+		return res, registerNx(root, testFiles, res)
+	default:
+		return res, nil
+	}
+}
+
+// testLanguage returns the language of a test file: go, python or js
+func testLanguage(file string) string {
+	switch filepath.Ext(file) {
+	case ".go":
+		return "go"
+	case ".py":
+		return "python"
+	case ".js", ".jsx", ".ts", ".tsx", ".mjs", ".cjs":
+		return "js"
+	}
+	return ""
+}
+
+// findUp returns the nearest directory from dir up to root holding one of
+// names, and the name found. It returns "" outside root or when none does.
+func findUp(root, dir string, names ...string) (string, string) {
+	root = filepath.Clean(root)
+	for dir = filepath.Clean(dir); ; dir = filepath.Dir(dir) {
+		rel, err := filepath.Rel(root, dir)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return "", ""
+		}
+		for _, name := range names {
+			if fileExists(filepath.Join(dir, name)) {
+				return dir, name
+			}
+		}
+		if dir == root {
+			return "", ""
+		}
+	}
+}
+
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
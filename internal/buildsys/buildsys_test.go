@@ -0,0 +1,292 @@
+package buildsys
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeFiles creates files under dir, by relative path
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  System
+	}{
+		{"bazel module", map[string]string{"MODULE.bazel": ""}, SystemBazel},
+		{"bazel workspace", map[string]string{"WORKSPACE": ""}, SystemBazel},
+		{"nx", map[string]string{"nx.json": "{}"}, SystemNx},
+		{"both", map[string]string{"WORKSPACE.bazel": "", "nx.json": "{}"}, SystemBazel},
+		{"neither", map[string]string{"go.mod": "module x"}, SystemNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, tt.files)
+			if got := Detect(dir); got != tt.want {
+				t.Errorf("Detect() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegister_BazelNewGoTest(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"MODULE.bazel": "",
+		"calc/BUILD.bazel": `load("@rules_go//go:def.bzl", "go_library")
+
+go_library(
+    name = "calc",
+    srcs = ["calc.go"],
+)
+`,
+		"calc/calc_test.go":  "package calc",
+		"calc/extra_test.go": "package calc",
+	})
+
+	res, err := Register(root, []string{
+		filepath.Join(root, "calc", "calc_test.go"),
+		filepath.Join(root, "calc", "extra_test.go"),
+	})
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if res.System != SystemBazel || len(res.Changed) != 1 {
+		t.Fatalf("Register() = %+v", res)
+	}
+
+	want := `load("@rules_go//go:def.bzl", "go_library", "go_test")
+
+go_library(
+    name = "calc",
+    srcs = ["calc.go"],
+)
+
+go_test(
+    name = "calc_test",
+    srcs = [
+        "calc_test.go",
+        "extra_test.go",
+    ],
+    embed = [":calc"],
+)
+`
+	if got := readFile(t, res.Changed[0]); got != want {
+		t.Errorf("BUILD.bazel =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRegister_BazelExistingGoTest(t *testing.T) {
+	root := t.TempDir()
+	build := `load("@io_bazel_rules_go//go:def.bzl", "go_library", "go_test")
+
+go_test(
+    name = "calc_test",
+    srcs = ["calc_test.go"],
+    embed = [":calc"],
+)
+`
+	writeFiles(t, root, map[string]string{
+		"WORKSPACE":          "",
+		"calc/BUILD":         build,
+		"calc/calc_test.go":  "package calc",
+		"calc/sum_test.go":   "package calc",
+		"other/none_test.go": "package other",
+	})
+
+	res, err := Register(root, []string{
+		filepath.Join(root, "calc", "calc_test.go"),
+		filepath.Join(root, "calc", "sum_test.go"),
+		filepath.Join(root, "other", "none_test.go"),
+	})
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	got := readFile(t, filepath.Join(root, "calc", "BUILD"))
+	if !strings.Contains(got, "    srcs = [\n        \"calc_test.go\",\n        \"sum_test.go\",\n    ],") {
+		t.Errorf("srcs not extended:\n%s", got)
+	}
+	if strings.Count(got, "go_test(") != 1 {
+		t.Errorf("expected the existing go_test to be reused:\n%s", got)
+	}
+	// other/ isn't a Bazel package
+	if len(res.Skipped) != 1 || !strings.HasSuffix(res.Skipped[0], "none_test.go") {
+		t.Errorf("Skipped = %v", res.Skipped)
+	}
+
+	// Registering again changes nothing
+	res, err = Register(root, []string{filepath.Join(root, "calc", "sum_test.go")})
+	if err != nil || len(res.Changed) != 0 {
+		t.Errorf("second Register() = %+v, %v, want no changes", res, err)
+	}
+}
+
+func TestRegister_BazelGlobCoversTests(t *testing.T) {
+	root := t.TempDir()
+	build := "go_test(\n    name = \"calc_test\",\n    srcs = glob([\"*_test.go\"]),\n)\n"
+	writeFiles(t, root, map[string]string{
+		"WORKSPACE":         "",
+		"calc/BUILD.bazel":  build,
+		"calc/calc_test.go": "package calc",
+	})
+
+	res, err := Register(root, []string{filepath.Join(root, "calc", "calc_test.go")})
+	if err != nil || len(res.Changed) != 0 {
+		t.Errorf("Register() = %+v, %v, want no changes", res, err)
+	}
+}
+
+func TestRegister_BazelPyTest(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"WORKSPACE": "",
+		"app/BUILD.bazel": `load("@rules_python//python:defs.bzl", "py_library")
+
+py_library(
+    name = "app",
+    srcs = ["app.py"],
+)
+`,
+		"app/tests/test_app.py": "",
+	})
+
+	res, err := Register(root, []string{filepath.Join(root, "app", "tests", "test_app.py")})
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if len(res.Changed) != 1 {
+		t.Fatalf("Register() = %+v", res)
+	}
+	got := readFile(t, res.Changed[0])
+	for _, want := range []string{
+		`load("@rules_python//python:defs.bzl", "py_library", "py_test")`,
+		"py_test(\n    name = \"test_app\",\n    srcs = [\"tests/test_app.py\"],\n    deps = [\":app\"],\n)",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("BUILD.bazel missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestRegister_NxAddsTestTarget(t *testing.T) {
+	root := t.TempDir()
+	project := `{
+  "name": "api",
+  "sourceRoot": "apps/api/src",
+  "targets": {
+    "build": {
+      "executor": "@nx/js:tsc"
+    }
+  }
+}
+`
+	writeFiles(t, root, map[string]string{
+		"nx.json":                       "{}",
+		"apps/api/project.json":         project,
+		"apps/api/jest.config.ts":       "",
+		"apps/api/src/users.test.ts":    "",
+		"apps/api/src/accounts.test.ts": "",
+	})
+
+	res, err := Register(root, []string{
+		filepath.Join(root, "apps", "api", "src", "users.test.ts"),
+		filepath.Join(root, "apps", "api", "src", "accounts.test.ts"),
+	})
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if res.System != SystemNx || len(res.Changed) != 1 {
+		t.Fatalf("Register() = %+v", res)
+	}
+
+	got := readFile(t, res.Changed[0])
+	var parsed struct {
+		Targets map[string]nxTarget `json:"targets"`
+	}
+	if err := json.Unmarshal([]byte(got), &parsed); err != nil {
+		t.Fatalf("project.json isn't valid JSON: %v\n%s", err, got)
+	}
+	test := parsed.Targets["test"]
+	if test.Executor != "@nx/jest:jest" || test.Options["jestConfig"] != "apps/api/jest.config.ts" {
+		t.Errorf("test target = %+v", test)
+	}
+	if _, ok := parsed.Targets["build"]; !ok {
+		t.Error("build target lost")
+	}
+	// Keys keep their order
+	if strings.Index(got, `"name"`) > strings.Index(got, `"targets"`) {
+		t.Errorf("key order changed:\n%s", got)
+	}
+
+	res, err = Register(root, []string{filepath.Join(root, "apps", "api", "src", "users.test.ts")})
+	if err != nil || len(res.Changed) != 0 {
+		t.Errorf("second Register() = %+v, %v, want no changes", res, err)
+	}
+}
+
+func TestRegister_NxWithoutTargets(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"nx.json":                     "{}",
+		"libs/geo/project.json":       "{\n    \"name\": \"geo\"\n}\n",
+		"libs/geo/geo_test.go":        "package geo",
+		"libs/plain/plain.test.js":    "",
+		"libs/plain/package.json":     "{}",
+		"libs/geo/internal/x_test.go": "package x",
+	})
+
+	res, err := Register(root, []string{
+		filepath.Join(root, "libs", "geo", "geo_test.go"),
+		filepath.Join(root, "libs", "plain", "plain.test.js"),
+	})
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if len(res.Changed) != 1 {
+		t.Fatalf("Register() = %+v, want only geo's project.json changed", res)
+	}
+
+	got := readFile(t, res.Changed[0])
+	want := `{
+    "name": "geo",
+    "targets": {
+        "test": {
+            "executor": "nx:run-commands",
+            "options": {
+                "command": "go test ./...",
+                "cwd": "libs/geo"
+            }
+        }
+    }
+}
+`
+	if got != want {
+		t.Errorf("project.json =\n%s\nwant\n%s", got, want)
+	}
+}
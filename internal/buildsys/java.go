@@ -0,0 +1,372 @@
+package buildsys
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// javaBuildFiles are the build files of a Maven or Gradle project
+var javaBuildFiles = []string{"pom.xml", "build.gradle.kts", "build.gradle"}
+
+// javaSkipDirs are directories never searched for sources
+var javaSkipDirs = map[string]bool{
+	".git": true, ".gradle": true, ".idea": true, "node_modules": true,
+	"target": true, "build": true, "out": true,
+}
+
+// JavaProject is a Maven or Gradle project whose tests Maven and Gradle
+// only run from src/test/java
+type JavaProject struct {
+	Root      string // directory holding the build file
+	BuildFile string // pom.xml, build.gradle or build.gradle.kts
+	Package   string // package of the application class, "" when unknown
+}
+
+// DetectJava returns the Maven or Gradle project under root, nil when there
+// is none. In a multi-module build it's the module of the Spring Boot
+// application class.
+func DetectJava(root string) *JavaProject {
+	var app *JavaProject
+	filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if app != nil {
+			return filepath.SkipAll
+		}
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if path != root && javaSkipDirs[d.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		i := strings.LastIndex(filepath.ToSlash(path), "/src/main/java/")
+		if filepath.Ext(path) != ".java" || i < 0 {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil || !strings.Contains(string(data), "@SpringBootApplication") {
+			return nil
+		}
+		if build := javaBuildFile(path[:i]); build != "" {
+			app = &JavaProject{Root: path[:i], BuildFile: build, Package: javaPackage(string(data))}
+		}
+		return nil
+	})
+	if app != nil {
+		return app
+	}
+
+	if build := javaBuildFile(root); build != "" {
+		return &JavaProject{
+			Root:      root,
+			BuildFile: build,
+			Package:   commonPackage(filepath.Join(root, "src", "main", "java")),
+		}
+	}
+	return nil
+}
+
+// javaBuildFile returns the path of the Maven or Gradle build file in dir,
+// "" when it has none
+func javaBuildFile(dir string) string {
+	for _, name := range javaBuildFiles {
+		if fileExists(filepath.Join(dir, name)) {
+			return filepath.Join(dir, name)
+		}
+	}
+	return ""
+}
+
+// TestDir returns the directory the project's tests in its package go in
+func (p *JavaProject) TestDir() string {
+	dir := filepath.Join(p.Root, "src", "test", "java")
+	if p.Package == "" {
+		return dir
+	}
+	return filepath.Join(dir, filepath.FromSlash(strings.ReplaceAll(p.Package, ".", "/")))
+}
+
+// JavaTestPackage returns the package of tests in dir, from its path under
+// src/test/java. ok is false outside a test source set.
+func JavaTestPackage(dir string) (string, bool) {
+	slashed := filepath.ToSlash(filepath.Clean(dir)) + "/"
+	i := strings.LastIndex(slashed, "src/test/java/")
+	if i < 0 {
+		return "", false
+	}
+	return strings.ReplaceAll(strings.Trim(slashed[i+len("src/test/java/"):], "/"), "/", "."), true
+}
+
+var javaPackagePattern = regexp.MustCompile(`(?m)^\s*package\s+([\w.]+)\s*;`)
+
+// javaPackage returns the package a Java source file declares
+func javaPackage(source string) string {
+	if m := javaPackagePattern.FindStringSubmatch(source); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// commonPackage returns the longest package enclosing every source under
+// dir, the one a test must be in to see them all
+func commonPackage(dir string) string {
+	var common []string
+	first := true
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || filepath.Ext(path) != ".java" {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+		parts := strings.Split(javaPackage(string(data)), ".")
+		if first {
+			common, first = parts, false
+			return nil
+		}
+		n := 0
+		for n < len(common) && n < len(parts) && common[n] == parts[n] {
+			n++
+		}
+		common = common[:n]
+		return nil
+	})
+	return strings.Join(common, ".")
+}
+
+// javaDependency is a test dependency, with an empty version when the
+// Spring Boot plugin manages it
+type javaDependency struct {
+	Group, Artifact, Version string
+}
+
+func (d javaDependency) coordinates() string {
+	if d.Version == "" {
+		return d.Group + ":" + d.Artifact
+	}
+	return d.Group + ":" + d.Artifact + ":" + d.Version
+}
+
+var (
+	springBootTest = javaDependency{"org.springframework.boot", "spring-boot-starter-test", ""}
+	junitJupiter   = javaDependency{"org.junit.jupiter", "junit-jupiter", "5.10.2"}
+)
+
+// AddTestDependencies adds the dependencies generated JUnit tests need to
+// the project's build file when it lacks them: spring-boot-starter-test,
+// which brings JUnit Jupiter and MockMvc, in a Spring Boot project, else
+// junit-jupiter. Gradle builds also get useJUnitPlatform(). It returns the
+// coordinates added.
+func (p *JavaProject) AddTestDependencies() ([]string, error) {
+	data, err := os.ReadFile(p.BuildFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", p.BuildFile, err)
+	}
+	content := string(data)
+
+	var missing []javaDependency
+	if strings.Contains(content, "spring-boot") || strings.Contains(content, "org.springframework.boot") {
+		if !strings.Contains(content, springBootTest.Artifact) {
+			missing = append(missing, springBootTest)
+		}
+	} else if !strings.Contains(content, junitJupiter.Artifact) {
+		missing = append(missing, junitJupiter)
+	}
+
+	var added []string
+	gradle := filepath.Base(p.BuildFile) != "pom.xml"
+	for _, dep := range missing {
+		if gradle {
+			content = addGradleDependency(content, dep, strings.HasSuffix(p.BuildFile, ".kts"))
+		} else {
+			content = addMavenDependency(content, dep)
+		}
+		added = append(added, dep.coordinates())
+	}
+	if gradle && !strings.Contains(content, "useJUnitPlatform") {
+		content = addJUnitPlatform(content, strings.HasSuffix(p.BuildFile, ".kts"))
+	}
+
+	if content == string(data) {
+		return nil, nil
+	}
+	if err := os.WriteFile(p.BuildFile, []byte(content), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", p.BuildFile, err)
+	}
+	return added, nil
+}
+
+// pomSections are the pom.xml elements whose <dependencies> aren't the
+// project's own
+var pomSections = []string{"dependencyManagement", "build", "profiles", "reporting"}
+
+// addMavenDependency adds a test-scoped dependency to a pom.xml's project
+// dependencies, creating the element before <build> when there is none
+func addMavenDependency(content string, dep javaDependency) string {
+	masked := []byte(content)
+	for _, section := range pomSections {
+		open, close := "<"+section+">", "</"+section+">"
+		for offset := 0; ; {
+			start := strings.Index(content[offset:], open)
+			if start < 0 {
+				break
+			}
+			start += offset
+			end := strings.Index(content[start:], close)
+			if end < 0 {
+				break
+			}
+			end += start + len(close)
+			for i := start; i < end; i++ {
+				masked[i] = ' '
+			}
+			offset = end
+		}
+	}
+
+	unit := xmlIndent(content)
+	if start := strings.Index(string(masked), "<dependencies>"); start >= 0 {
+		if end := strings.Index(content[start:], "</dependencies>"); end >= 0 {
+			end += start
+			lineStart := strings.LastIndex(content[:end], "\n") + 1
+			indent := content[lineStart:end]
+			if strings.TrimSpace(indent) != "" {
+				// The closing tag shares a line with other content
+				return content[:end] + "\n" + mavenDependency(dep, unit+unit, unit) + unit + content[end:]
+			}
+			if indent != "" {
+				unit = indent
+			}
+			return content[:lineStart] + mavenDependency(dep, indent+unit, unit) + content[lineStart:]
+		}
+	}
+
+	block := unit + "<dependencies>\n" + mavenDependency(dep, unit+unit, unit) + unit + "</dependencies>\n"
+	at := strings.Index(string(masked), "</project>")
+	if build := strings.Index(content, "<build>"); build >= 0 && (at < 0 || build < at) {
+		at = build
+	}
+	if at < 0 {
+		return content
+	}
+	lineStart := strings.LastIndex(content[:at], "\n") + 1
+	if strings.TrimSpace(content[lineStart:at]) != "" {
+		lineStart = at
+		block = "\n" + block
+	}
+	return content[:lineStart] + block + "\n" + content[lineStart:]
+}
+
+// xmlIndent returns the indentation of an XML file's first indented
+// element, four spaces when it has none
+func xmlIndent(content string) string {
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimLeft(line, " \t")
+		if strings.HasPrefix(trimmed, "<") && len(trimmed) < len(line) {
+			return line[:len(line)-len(trimmed)]
+		}
+	}
+	return "    "
+}
+
+// mavenDependency renders a test-scoped <dependency> element
+func mavenDependency(dep javaDependency, indent, unit string) string {
+	var b strings.Builder
+	b.WriteString(indent + "<dependency>\n")
+	fmt.Fprintf(&b, "%s%s<groupId>%s</groupId>\n", indent, unit, dep.Group)
+	fmt.Fprintf(&b, "%s%s<artifactId>%s</artifactId>\n", indent, unit, dep.Artifact)
+	if dep.Version != "" {
+		fmt.Fprintf(&b, "%s%s<version>%s</version>\n", indent, unit, dep.Version)
+	}
+	fmt.Fprintf(&b, "%s%s<scope>test</scope>\n", indent, unit)
+	b.WriteString(indent + "</dependency>\n")
+	return b.String()
+}
+
+var gradleDependenciesPattern = regexp.MustCompile(`(?m)^dependencies\s*\{`)
+
+// addGradleDependency adds a testImplementation dependency to a Gradle
+// build's top-level dependencies block, creating one when there is none
+func addGradleDependency(content string, dep javaDependency, kotlin bool) string {
+	line := fmt.Sprintf("testImplementation '%s'", dep.coordinates())
+	if kotlin {
+		line = fmt.Sprintf("testImplementation(%q)", dep.coordinates())
+	}
+
+	if loc := gradleDependenciesPattern.FindStringIndex(content); loc != nil {
+		if end := gradleClose(content, loc[1]-1); end >= 0 {
+			indent := "    "
+			for _, l := range strings.Split(content[loc[1]:end], "\n") {
+				if trimmed := strings.TrimLeft(l, " \t"); trimmed != "" {
+					indent = l[:len(l)-len(trimmed)]
+					break
+				}
+			}
+			lineStart := strings.LastIndex(content[:end], "\n") + 1
+			if strings.TrimSpace(content[lineStart:end]) != "" {
+				// The block's brace shares a line with its content
+				return content[:end] + "\n" + indent + line + "\n" + content[end:]
+			}
+			return content[:lineStart] + indent + line + "\n" + content[lineStart:]
+		}
+	}
+	return appendBlock(content, "dependencies {\n    "+line+"\n}\n")
+}
+
+// addJUnitPlatform makes a Gradle build run tests on the JUnit Platform,
+// which JUnit 5 tests need
+func addJUnitPlatform(content string, kotlin bool) string {
+	if kotlin {
+		return appendBlock(content, "tasks.named<Test>(\"test\") {\n    useJUnitPlatform()\n}\n")
+	}
+	return appendBlock(content, "tasks.named('test') {\n    useJUnitPlatform()\n}\n")
+}
+
+// appendBlock adds a block at the end of a Gradle build, after a blank line
+func appendBlock(content, block string) string {
+	content = strings.TrimRight(content, "\n")
+	if content == "" {
+		return block
+	}
+	return content + "\n\n" + block
+}
+
+// gradleClose returns the index of the brace closing the one at open,
+// skipping strings and comments, or -1
+func gradleClose(s string, open int) int {
+	depth := 0
+	for i := open; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '{':
+			depth++
+		case c == '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		case c == '"' || c == '\'':
+			for i++; i < len(s) && s[i] != c && s[i] != '\n'; i++ {
+				if s[i] == '\\' {
+					i++
+				}
+			}
+		case strings.HasPrefix(s[i:], "//"):
+			for i < len(s) && s[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(s[i:], "/*"):
+			end := strings.Index(s[i+2:], "*/")
+			if end < 0 {
+				return -1
+			}
+			i += end + 3
+		}
+	}
+	return -1
+}
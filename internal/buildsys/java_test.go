@@ -0,0 +1,206 @@
+package buildsys
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDetectJava_SpringBootModule(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"pom.xml":     "<project><modules><module>api</module></modules></project>",
+		"api/pom.xml": "<project></project>",
+		"api/src/main/java/com/acme/shop/ShopApplication.java": "package com.acme.shop;\n\n@SpringBootApplication\npublic class ShopApplication {}\n",
+		"api/src/main/java/com/acme/shop/web/Orders.java":      "package com.acme.shop.web;\n",
+	})
+
+	project := DetectJava(root)
+	if project == nil {
+		t.Fatal("DetectJava() = nil")
+	}
+	if project.Root != filepath.Join(root, "api") || project.Package != "com.acme.shop" {
+		t.Errorf("DetectJava() = %+v", project)
+	}
+	want := filepath.Join(root, "api", "src", "test", "java", "com", "acme", "shop")
+	if got := project.TestDir(); got != want {
+		t.Errorf("TestDir() = %q, want %q", got, want)
+	}
+}
+
+func TestDetectJava_CommonPackage(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"build.gradle":                    "",
+		"src/main/java/org/demo/a/A.java": "package org.demo.a;\n",
+		"src/main/java/org/demo/b/B.java": "package org.demo.b;\n",
+	})
+
+	project := DetectJava(root)
+	if project == nil || project.BuildFile != filepath.Join(root, "build.gradle") || project.Package != "org.demo" {
+		t.Errorf("DetectJava() = %+v", project)
+	}
+
+	if DetectJava(t.TempDir()) != nil {
+		t.Error("DetectJava() of a directory without a build file should be nil")
+	}
+}
+
+func TestJavaTestPackage(t *testing.T) {
+	tests := []struct {
+		dir    string
+		want   string
+		wantOK bool
+	}{
+		{"src/test/java/com/acme", "com.acme", true},
+		{filepath.Join("svc", "src", "test", "java", "org", "x"), "org.x", true},
+		{"src/test/java", "", true},
+		{"tests", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := JavaTestPackage(tt.dir)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("JavaTestPackage(%q) = %q, %v, want %q, %v", tt.dir, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestAddTestDependencies_Maven(t *testing.T) {
+	root := t.TempDir()
+	pom := `<project>
+    <parent>
+        <groupId>org.springframework.boot</groupId>
+        <artifactId>spring-boot-starter-parent</artifactId>
+    </parent>
+    <dependencyManagement>
+        <dependencies>
+        </dependencies>
+    </dependencyManagement>
+    <dependencies>
+        <dependency>
+            <groupId>org.springframework.boot</groupId>
+            <artifactId>spring-boot-starter-web</artifactId>
+        </dependency>
+    </dependencies>
+</project>
+`
+	writeFiles(t, root, map[string]string{"pom.xml": pom})
+	project := &JavaProject{Root: root, BuildFile: filepath.Join(root, "pom.xml")}
+
+	added, err := project.AddTestDependencies()
+	if err != nil {
+		t.Fatalf("AddTestDependencies() error = %v", err)
+	}
+	if len(added) != 1 || added[0] != "org.springframework.boot:spring-boot-starter-test" {
+		t.Errorf("added = %v", added)
+	}
+
+	want := `        <dependency>
+            <groupId>org.springframework.boot</groupId>
+            <artifactId>spring-boot-starter-web</artifactId>
+        </dependency>
+        <dependency>
+            <groupId>org.springframework.boot</groupId>
+            <artifactId>spring-boot-starter-test</artifactId>
+            <scope>test</scope>
+        </dependency>
+    </dependencies>
+</project>
+`
+	got := readFile(t, project.BuildFile)
+	if !strings.HasSuffix(got, want) {
+		t.Errorf("pom.xml =\n%s", got)
+	}
+	// dependencyManagement is left alone
+	if !strings.Contains(got, "        <dependencies>\n        </dependencies>\n    </dependencyManagement>") {
+		t.Errorf("dependencyManagement changed:\n%s", got)
+	}
+
+	added, err = project.AddTestDependencies()
+	if err != nil || len(added) != 0 {
+		t.Errorf("second AddTestDependencies() = %v, %v, want nothing added", added, err)
+	}
+}
+
+func TestAddTestDependencies_MavenWithoutDependencies(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"pom.xml": "<project>\n  <artifactId>lib</artifactId>\n\n  <build>\n  </build>\n</project>\n"})
+	project := &JavaProject{Root: root, BuildFile: filepath.Join(root, "pom.xml")}
+
+	if _, err := project.AddTestDependencies(); err != nil {
+		t.Fatalf("AddTestDependencies() error = %v", err)
+	}
+	want := `<project>
+  <artifactId>lib</artifactId>
+
+  <dependencies>
+    <dependency>
+      <groupId>org.junit.jupiter</groupId>
+      <artifactId>junit-jupiter</artifactId>
+      <version>5.10.2</version>
+      <scope>test</scope>
+    </dependency>
+  </dependencies>
+
+  <build>
+  </build>
+</project>
+`
+	if got := readFile(t, project.BuildFile); got != want {
+		t.Errorf("pom.xml =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestAddTestDependencies_Gradle(t *testing.T) {
+	root := t.TempDir()
+	build := `plugins {
+    id 'org.springframework.boot' version '3.2.0'
+}
+
+dependencies {
+    // the web starter's {tomcat} isn't needed
+    implementation 'org.springframework.boot:spring-boot-starter-web'
+}
+`
+	writeFiles(t, root, map[string]string{"build.gradle": build})
+	project := &JavaProject{Root: root, BuildFile: filepath.Join(root, "build.gradle")}
+
+	if _, err := project.AddTestDependencies(); err != nil {
+		t.Fatalf("AddTestDependencies() error = %v", err)
+	}
+	want := `plugins {
+    id 'org.springframework.boot' version '3.2.0'
+}
+
+dependencies {
+    // the web starter's {tomcat} isn't needed
+    implementation 'org.springframework.boot:spring-boot-starter-web'
+    testImplementation 'org.springframework.boot:spring-boot-starter-test'
+}
+
+tasks.named('test') {
+    useJUnitPlatform()
+}
+`
+	if got := readFile(t, project.BuildFile); got != want {
+		t.Errorf("build.gradle =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestAddTestDependencies_GradleKotlin(t *testing.T) {
+	root := t.TempDir()
+	build := "plugins {\n    id(\"org.springframework.boot\") version \"3.2.0\"\n}\n\ntasks.withType<Test> {\n    useJUnitPlatform()\n}\n"
+	writeFiles(t, root, map[string]string{"build.gradle.kts": build})
+	project := &JavaProject{Root: root, BuildFile: filepath.Join(root, "build.gradle.kts")}
+
+	if _, err := project.AddTestDependencies(); err != nil {
+		t.Fatalf("AddTestDependencies() error = %v", err)
+	}
+	got := readFile(t, project.BuildFile)
+	if !strings.HasSuffix(got, "\n\ndependencies {\n    testImplementation(\"org.springframework.boot:spring-boot-starter-test\")\n}\n") {
+		t.Errorf("build.gradle.kts =\n%s", got)
+	}
+	if strings.Count(got, "useJUnitPlatform") != 1 {
+		t.Errorf("useJUnitPlatform added twice:\n%s", got)
+	}
+}
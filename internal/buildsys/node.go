@@ -0,0 +1,482 @@
+package buildsys
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// nodeDevDependencies are the versions added for the test tooling generated
+// JS/TS tests use. Other packages they import are reported, not added.
+var nodeDevDependencies = map[string]string{
+	"jest":             "^29.7.0",
+	"@jest/globals":    "^29.7.0",
+	"ts-jest":          "^29.1.2",
+	"@types/jest":      "^29.5.12",
+	"typescript":       "^5.4.5",
+	"vitest":           "^1.6.0",
+	"supertest":        "^6.3.4",
+	"@types/supertest": "^6.0.2",
+	"chai":             "^4.4.1",
+	"@types/chai":      "^4.3.16",
+	"nock":             "^13.5.4",
+}
+
+// npmPlaceholderTest is the test script npm init writes
+const npmPlaceholderTest = "no test specified"
+
+// NodeSetup is what a package must add before its generated JS/TS tests
+// run: the test runner, the tooling the tests import and a test script
+type NodeSetup struct {
+	PackageJSON     string
+	Runner          string            // jest or vitest
+	DevDependencies map[string]string // missing packages and the versions to add
+	TestScript      string            // the test script to add, "" when the package has one
+	TSJestConfig    bool              // jest needs a ts-jest preset to run TypeScript
+	Unresolved      []string          // imported packages neither declared nor known tooling
+	Manager         string            // npm, yarn, pnpm or bun
+}
+
+// CheckNode returns the setup each package holding JS/TS testFiles lacks,
+// leaving out packages that need nothing. Tests outside any package are
+// skipped.
+func CheckNode(testFiles []string) ([]*NodeSetup, error) {
+	byPackage := make(map[string][]string)
+	var dirs []string
+	for _, file := range testFiles {
+		if testLanguage(file) != "js" {
+			continue
+		}
+		abs, err := filepath.Abs(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve %s: %w", file, err)
+		}
+		dir, _ := findUp(filepath.VolumeName(abs)+string(filepath.Separator), filepath.Dir(abs), "package.json")
+		if dir == "" {
+			continue
+		}
+		if _, ok := byPackage[dir]; !ok {
+			dirs = append(dirs, dir)
+		}
+		byPackage[dir] = append(byPackage[dir], abs)
+	}
+
+	var setups []*NodeSetup
+	for _, dir := range dirs {
+		setup, err := checkNodePackage(dir, byPackage[dir])
+		if err != nil {
+			return nil, err
+		}
+		if !setup.Empty() {
+			setups = append(setups, setup)
+		}
+	}
+	return setups, nil
+}
+
+// packageJSON is the part of a package.json the setup check reads
+type packageJSON struct {
+	Name                 string            `json:"name"`
+	Scripts              map[string]string `json:"scripts"`
+	Dependencies         map[string]string `json:"dependencies"`
+	DevDependencies      map[string]string `json:"devDependencies"`
+	PeerDependencies     map[string]string `json:"peerDependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+	Jest                 json.RawMessage   `json:"jest"`
+}
+
+func readPackageJSON(path string) (*packageJSON, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	var pkg packageJSON
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return &pkg, nil
+}
+
+// checkNodePackage works out what the package in dir lacks to run tests.
+// Packages declared by an enclosing package.json count, as workspaces
+// hoist them.
+func checkNodePackage(dir string, testFiles []string) (*NodeSetup, error) {
+	setup := &NodeSetup{
+		PackageJSON:     filepath.Join(dir, "package.json"),
+		DevDependencies: make(map[string]string),
+		Manager:         nodeManager(dir),
+	}
+	pkg, err := readPackageJSON(setup.PackageJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	declared := map[string]bool{}
+	for d := dir; ; d = filepath.Dir(d) {
+		if p, err := readPackageJSON(filepath.Join(d, "package.json")); err == nil {
+			for _, deps := range []map[string]string{p.Dependencies, p.DevDependencies, p.PeerDependencies, p.OptionalDependencies} {
+				for name := range deps {
+					declared[name] = true
+				}
+			}
+		}
+		if filepath.Dir(d) == d {
+			break
+		}
+	}
+
+	imports := map[string]bool{}
+	typescript := false
+	for _, file := range testFiles {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		for _, name := range jsImports(string(data)) {
+			imports[name] = true
+		}
+		if ext := filepath.Ext(file); ext == ".ts" || ext == ".tsx" {
+			typescript = true
+		}
+	}
+
+	setup.Runner = "jest"
+	if declared["vitest"] || imports["vitest"] {
+		setup.Runner = "vitest"
+	}
+
+	need := []string{setup.Runner}
+	if setup.Runner == "jest" && typescript {
+		need = append(need, "ts-jest", "@types/jest", "typescript")
+		setup.TSJestConfig = len(pkg.Jest) == 0 && !hasJestConfig(dir) && !hasBabelConfig(dir)
+	}
+	for name := range imports {
+		if name == pkg.Name {
+			continue
+		}
+		need = append(need, name)
+		if typescript && name == "supertest" {
+			need = append(need, "@types/supertest")
+		}
+	}
+
+	for _, name := range need {
+		if declared[name] {
+			continue
+		}
+		if version, ok := nodeDevDependencies[name]; ok {
+			setup.DevDependencies[name] = version
+		} else if !containsString(setup.Unresolved, name) {
+			setup.Unresolved = append(setup.Unresolved, name)
+		}
+	}
+	sort.Strings(setup.Unresolved)
+
+	if test := pkg.Scripts["test"]; test == "" || strings.Contains(test, npmPlaceholderTest) {
+		setup.TestScript = "jest"
+		if setup.Runner == "vitest" {
+			setup.TestScript = "vitest run"
+		}
+	}
+	return setup, nil
+}
+
+// Empty reports whether the package has everything its tests need
+func (s *NodeSetup) Empty() bool {
+	return len(s.DevDependencies) == 0 && s.TestScript == "" && !s.TSJestConfig && len(s.Unresolved) == 0
+}
+
+// Report describes what the package lacks, one item per line
+func (s *NodeSetup) Report() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s (%s)\n", s.PackageJSON, s.Runner)
+	if len(s.DevDependencies) > 0 {
+		var deps []string
+		for _, name := range sortedKeys(s.DevDependencies) {
+			deps = append(deps, name+"@"+s.DevDependencies[name])
+		}
+		fmt.Fprintf(&sb, "   devDependencies: %s\n", strings.Join(deps, ", "))
+	}
+	if s.TestScript != "" {
+		fmt.Fprintf(&sb, "   test script: %s\n", s.TestScript)
+	}
+	if s.TSJestConfig {
+		sb.WriteString("   jest config: ts-jest preset\n")
+	}
+	if len(s.Unresolved) > 0 {
+		fmt.Fprintf(&sb, "   undeclared imports, not added: %s\n", strings.Join(s.Unresolved, ", "))
+	}
+	return sb.String()
+}
+
+// InstallCommand returns the command installing the missing packages, or
+// "" when none are
+func (s *NodeSetup) InstallCommand() string {
+	if len(s.DevDependencies) == 0 {
+		return ""
+	}
+	var args []string
+	for _, name := range sortedKeys(s.DevDependencies) {
+		args = append(args, name+"@"+s.DevDependencies[name])
+	}
+	add := map[string]string{"yarn": "yarn add -D", "pnpm": "pnpm add -D", "bun": "bun add -d"}[s.Manager]
+	if add == "" {
+		add = "npm install --save-dev"
+	}
+	return add + " " + strings.Join(args, " ")
+}
+
+// Summary is a one-line summary of the change Apply makes, for PR bodies
+func (s *NodeSetup) Summary(root string) string {
+	var changes []string
+	for _, name := range sortedKeys(s.DevDependencies) {
+		changes = append(changes, name+"@"+s.DevDependencies[name])
+	}
+	if s.TestScript != "" {
+		changes = append(changes, fmt.Sprintf("test script %q", s.TestScript))
+	}
+	if s.TSJestConfig {
+		changes = append(changes, "ts-jest preset")
+	}
+	return fmt.Sprintf("%s: %s", filepath.ToSlash(mustRel(root, s.PackageJSON)), strings.Join(changes, ", "))
+}
+
+// Apply adds the missing devDependencies, test script and jest config to
+// package.json, keeping its key order and indentation. Undeclared imports
+// are left for the user. Packages still have to be installed.
+func (s *NodeSetup) Apply() error {
+	data, err := os.ReadFile(s.PackageJSON)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", s.PackageJSON, err)
+	}
+	content := string(data)
+	indent := jsonIndent(content)
+
+	if len(s.DevDependencies) > 0 {
+		entries := make(map[string]string, len(s.DevDependencies))
+		for name, version := range s.DevDependencies {
+			entries[name] = quoteJSON(version)
+		}
+		content = setJSONMembers(content, "devDependencies", entries, true, indent)
+	}
+	if s.TestScript != "" {
+		content = setJSONMembers(content, "scripts", map[string]string{"test": quoteJSON(s.TestScript)}, false, indent)
+	}
+	if s.TSJestConfig {
+		config := "{\n" + strings.Repeat(indent, 2) + `"preset": "ts-jest",` + "\n" +
+			strings.Repeat(indent, 2) + `"testEnvironment": "node"` + "\n" + indent + "}"
+		content = setJSONMembers(content, "", map[string]string{"jest": config}, false, indent)
+	}
+
+	if !json.Valid([]byte(content)) {
+		return fmt.Errorf("failed to add test setup to %s", s.PackageJSON)
+	}
+	if err := os.WriteFile(s.PackageJSON, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", s.PackageJSON, err)
+	}
+	return nil
+}
+
+// hasJestConfig reports whether dir has a jest config file
+func hasJestConfig(dir string) bool {
+	for _, name := range jestConfigs {
+		if fileExists(filepath.Join(dir, name)) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasBabelConfig reports whether dir has a babel config, which babel-jest
+// compiles TypeScript with instead of ts-jest
+func hasBabelConfig(dir string) bool {
+	for _, name := range []string{"babel.config.js", "babel.config.cjs", "babel.config.json", ".babelrc"} {
+		if fileExists(filepath.Join(dir, name)) {
+			return true
+		}
+	}
+	return false
+}
+
+// nodeManager returns the package manager of the package in dir, from the
+// nearest lockfile
+func nodeManager(dir string) string {
+	locks := map[string]string{"pnpm-lock.yaml": "pnpm", "yarn.lock": "yarn", "bun.lockb": "bun", "package-lock.json": "npm"}
+	for d := dir; ; d = filepath.Dir(d) {
+		for _, name := range []string{"pnpm-lock.yaml", "yarn.lock", "bun.lockb", "package-lock.json"} {
+			if fileExists(filepath.Join(d, name)) {
+				return locks[name]
+			}
+		}
+		if filepath.Dir(d) == d {
+			return "npm"
+		}
+	}
+}
+
+var jsImportPattern = regexp.MustCompile(`(?:\bfrom\s*|\bimport\s*|\brequire\s*\(\s*|\bimport\s*\(\s*)['"]([^'"]+)['"]`)
+
+// nodeBuiltins are the core modules tests may import without a prefix
+var nodeBuiltins = map[string]bool{
+	"assert": true, "buffer": true, "child_process": true, "crypto": true, "events": true,
+	"fs": true, "http": true, "https": true, "net": true, "os": true, "path": true,
+	"querystring": true, "stream": true, "timers": true, "url": true, "util": true, "zlib": true,
+}
+
+// jsImports returns the packages a JS/TS file imports, leaving out relative
+// imports and Node's core modules
+func jsImports(source string) []string {
+	var names []string
+	for _, m := range jsImportPattern.FindAllStringSubmatch(source, -1) {
+		spec := m[1]
+		if strings.HasPrefix(spec, ".") || strings.HasPrefix(spec, "/") || strings.HasPrefix(spec, "node:") {
+			continue
+		}
+		parts := strings.SplitN(spec, "/", 3)
+		name := parts[0]
+		if strings.HasPrefix(name, "@") && len(parts) > 1 {
+			name += "/" + parts[1]
+		}
+		if nodeBuiltins[name] || containsString(names, name) {
+			continue
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// jsonMember is a key of a JSON object and the bounds of its entry
+type jsonMember struct {
+	Key        string
+	Start      int // the key's opening quote
+	ValueStart int
+	End        int // just past the value
+}
+
+// jsonMembers returns the members of the valid JSON object opening at open
+func jsonMembers(s string, open int) []jsonMember {
+	var members []jsonMember
+	i := open + 1
+	skip := func() {
+		for i < len(s) && strings.ContainsRune(" \t\r\n,", rune(s[i])) {
+			i++
+		}
+	}
+	for skip(); i < len(s) && s[i] == '"'; skip() {
+		m := jsonMember{Start: i}
+		i = jsonStringEnd(s, i)
+		json.Unmarshal([]byte(s[m.Start:i]), &m.Key)
+		for i < len(s) && (s[i] == ':' || strings.ContainsRune(" \t\r\n", rune(s[i]))) {
+			i++
+		}
+		m.ValueStart = i
+		switch s[i] {
+		case '{', '[':
+			i = matchClose(s, i) + 1
+		case '"':
+			i = jsonStringEnd(s, i)
+		default:
+			for i < len(s) && !strings.ContainsRune(",} \t\r\n", rune(s[i])) {
+				i++
+			}
+		}
+		m.End = i
+		members = append(members, m)
+	}
+	return members
+}
+
+// jsonStringEnd returns the index just past the string opening at i
+func jsonStringEnd(s string, i int) int {
+	for i++; i < len(s) && s[i] != '"'; i++ {
+		if s[i] == '\\' {
+			i++
+		}
+	}
+	return i + 1
+}
+
+// setJSONMembers sets members of the object under key in a JSON document's
+// top-level object, or of the top-level object itself when key is "",
+// creating the object when missing. values are raw JSON. Existing members
+// are replaced in place; new ones go in key order when sorted is set, else
+// at the end.
+func setJSONMembers(content, key string, values map[string]string, sorted bool, indent string) string {
+	root := strings.Index(content, "{")
+	open, depth := root, 1
+	if key != "" {
+		open = -1
+		for _, m := range jsonMembers(content, root) {
+			if m.Key == key && content[m.ValueStart] == '{' {
+				open = m.ValueStart
+			}
+		}
+		if open < 0 {
+			// Add the object empty, then fill it in
+			content = setJSONMembers(content, "", map[string]string{key: "{}"}, false, indent)
+			return setJSONMembers(content, key, values, sorted, indent)
+		}
+		depth = 2
+	}
+	inner := strings.Repeat(indent, depth)
+
+	for _, name := range sortedKeys(values) {
+		value := values[name]
+		members := jsonMembers(content, open)
+		entry := quoteJSON(name) + ": " + value
+
+		var at *jsonMember
+		for i := range members {
+			if members[i].Key == name {
+				content = content[:members[i].ValueStart] + value + content[members[i].End:]
+				at = &members[i]
+				break
+			}
+		}
+		if at != nil {
+			continue
+		}
+		if len(members) == 0 {
+			end := matchClose(content, open)
+			content = content[:open] + "{\n" + inner + entry + "\n" + strings.Repeat(indent, depth-1) + "}" + content[end+1:]
+			continue
+		}
+		if sorted {
+			for i := range members {
+				if members[i].Key > name {
+					at = &members[i]
+					break
+				}
+			}
+		}
+		if at != nil {
+			content = content[:at.Start] + entry + ",\n" + inner + content[at.Start:]
+		} else {
+			last := members[len(members)-1]
+			rest := content[last.End:]
+			if !strings.Contains(rest[:strings.Index(rest, "}")], "\n") {
+				rest = "\n" + strings.Repeat(indent, depth-1) + strings.TrimLeft(rest, " \t")
+			}
+			content = content[:last.End] + ",\n" + inner + entry + rest
+		}
+	}
+	return content
+}
+
+func quoteJSON(s string) string {
+	data, _ := json.Marshal(s)
+	return string(data)
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
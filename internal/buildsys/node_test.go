@@ -0,0 +1,146 @@
+package buildsys
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCheckNode_TypeScriptJest(t *testing.T) {
+	root := t.TempDir()
+	pkg := `{
+  "name": "shop",
+  "scripts": {
+    "build": "tsc",
+    "test": "echo \"Error: no test specified\" && exit 1"
+  },
+  "dependencies": {
+    "express": "^4.19.2"
+  },
+  "devDependencies": {
+    "@types/express": "^4.17.21",
+    "typescript": "^5.4.5"
+  }
+}
+`
+	writeFiles(t, root, map[string]string{
+		"package.json": pkg,
+		"yarn.lock":    "",
+		"tests/api.test.ts": "import request from 'supertest';\nimport { expect } from 'chai';\nimport app from '../src/app';\n" +
+			"import * as path from 'path';\nimport dayjs from 'dayjs/esm';\n",
+	})
+
+	setups, err := CheckNode([]string{filepath.Join(root, "tests", "api.test.ts"), filepath.Join(root, "main_test.go")})
+	if err != nil {
+		t.Fatalf("CheckNode() error = %v", err)
+	}
+	if len(setups) != 1 {
+		t.Fatalf("CheckNode() = %d setups, want 1", len(setups))
+	}
+	setup := setups[0]
+	wantDeps := map[string]string{
+		"jest": "^29.7.0", "ts-jest": "^29.1.2", "@types/jest": "^29.5.12",
+		"supertest": "^6.3.4", "@types/supertest": "^6.0.2", "chai": "^4.4.1",
+	}
+	if !reflect.DeepEqual(setup.DevDependencies, wantDeps) {
+		t.Errorf("DevDependencies = %v, want %v", setup.DevDependencies, wantDeps)
+	}
+	if setup.Runner != "jest" || setup.TestScript != "jest" || !setup.TSJestConfig {
+		t.Errorf("setup = %+v", setup)
+	}
+	if !reflect.DeepEqual(setup.Unresolved, []string{"dayjs"}) {
+		t.Errorf("Unresolved = %v, want [dayjs]", setup.Unresolved)
+	}
+	if cmd := setup.InstallCommand(); !strings.HasPrefix(cmd, "yarn add -D @types/jest@^29.5.12 ") {
+		t.Errorf("InstallCommand() = %q", cmd)
+	}
+
+	if err := setup.Apply(); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	want := `{
+  "name": "shop",
+  "scripts": {
+    "build": "tsc",
+    "test": "jest"
+  },
+  "dependencies": {
+    "express": "^4.19.2"
+  },
+  "devDependencies": {
+    "@types/express": "^4.17.21",
+    "@types/jest": "^29.5.12",
+    "@types/supertest": "^6.0.2",
+    "chai": "^4.4.1",
+    "jest": "^29.7.0",
+    "supertest": "^6.3.4",
+    "ts-jest": "^29.1.2",
+    "typescript": "^5.4.5"
+  },
+  "jest": {
+    "preset": "ts-jest",
+    "testEnvironment": "node"
+  }
+}
+`
+	if got := readFile(t, filepath.Join(root, "package.json")); got != want {
+		t.Errorf("package.json =\n%s\nwant\n%s", got, want)
+	}
+
+	// Only the undeclared import is left
+	setups, err = CheckNode([]string{filepath.Join(root, "tests", "api.test.ts")})
+	if err != nil || len(setups) != 1 || len(setups[0].DevDependencies) != 0 || setups[0].TestScript != "" || setups[0].TSJestConfig {
+		t.Errorf("CheckNode() after Apply() = %+v, %v", setups, err)
+	}
+}
+
+func TestCheckNode_Vitest(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"package.json":              "{\n\t\"name\": \"web\",\n\t\"workspaces\": [\"packages/*\"],\n\t\"devDependencies\": {\n\t\t\"vitest\": \"^1.6.0\"\n\t}\n}\n",
+		"packages/api/package.json": "{\"name\": \"api\", \"scripts\": {\"test\": \"vitest\"}}",
+		"packages/api/api.test.js":  "import { describe, it } from 'vitest';\nconst request = require('supertest');\n",
+	})
+
+	setups, err := CheckNode([]string{filepath.Join(root, "packages", "api", "api.test.js")})
+	if err != nil {
+		t.Fatalf("CheckNode() error = %v", err)
+	}
+	if len(setups) != 1 {
+		t.Fatalf("CheckNode() = %d setups, want 1", len(setups))
+	}
+	setup := setups[0]
+	if setup.Runner != "vitest" || setup.TestScript != "" || setup.TSJestConfig {
+		t.Errorf("setup = %+v", setup)
+	}
+	if !reflect.DeepEqual(setup.DevDependencies, map[string]string{"supertest": "^6.3.4"}) {
+		t.Errorf("DevDependencies = %v, want only supertest", setup.DevDependencies)
+	}
+
+	if err := setup.Apply(); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	want := "{\"name\": \"api\", \"scripts\": {\"test\": \"vitest\"},\n  \"devDependencies\": {\n    \"supertest\": \"^6.3.4\"\n  }\n}"
+	if got := readFile(t, setup.PackageJSON); got != want {
+		t.Errorf("package.json =\n%s\nwant\n%s", got, want)
+	}
+
+	if setups, _ := CheckNode([]string{filepath.Join(root, "packages", "api", "api.test.js")}); len(setups) != 0 {
+		t.Errorf("CheckNode() after Apply() = %+v, want nothing", setups[0])
+	}
+}
+
+func TestJSImports(t *testing.T) {
+	source := `import request from 'supertest';
+import { z } from "@scope/pkg/sub";
+import './setup';
+const fs = require('fs');
+const x = require( "lodash/merge" );
+const y = await import('node:path');
+`
+	want := []string{"supertest", "@scope/pkg", "lodash"}
+	if got := jsImports(source); !reflect.DeepEqual(got, want) {
+		t.Errorf("jsImports() = %v, want %v", got, want)
+	}
+}
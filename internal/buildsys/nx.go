@@ -0,0 +1,149 @@
+package buildsys
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// nxTarget is a target in a project.json
+type nxTarget struct {
+	Executor string            `json:"executor"`
+	Outputs  []string          `json:"outputs,omitempty"`
+	Options  map[string]string `json:"options,omitempty"`
+}
+
+// jestConfigs are the config files that give a project a jest target
+var jestConfigs = []string{"jest.config.ts", "jest.config.js", "jest.config.cjs", "jest.config.mjs"}
+
+// registerNx adds a test target to the project.json of each project with
+// tests and none. Its runner finds the project's tests itself, so projects
+// with a test target, or without a project.json for Nx to infer targets
+// from plugins, are left alone.
+func registerNx(root string, testFiles []string, res *Result) error {
+	done := make(map[string]bool)
+	for _, file := range testFiles {
+		dir, _ := findUp(root, filepath.Dir(file), "project.json")
+		if dir == "" {
+			continue
+		}
+		project := filepath.Join(dir, "project.json")
+		if done[project] {
+			continue
+		}
+		done[project] = true
+
+		target := nxTestTarget(root, dir, testLanguage(file))
+		if target == nil {
+			res.Skipped = append(res.Skipped, file)
+			continue
+		}
+		changed, err := addNxTarget(project, "test", target)
+		if err != nil {
+			return err
+		}
+		if changed {
+			res.Changed = append(res.Changed, project)
+		}
+	}
+	return nil
+}
+
+// nxTestTarget returns the target running a project's tests: Nx's jest
+// executor for projects with a jest config, else the language's runner
+func nxTestTarget(root, dir, lang string) *nxTarget {
+	projectRoot := filepath.ToSlash(mustRel(root, dir))
+	command := func(cmd string) *nxTarget {
+		return &nxTarget{
+			Executor: "nx:run-commands",
+			Options:  map[string]string{"command": cmd, "cwd": projectRoot},
+		}
+	}
+
+	switch lang {
+	case "js":
+		for _, name := range jestConfigs {
+			if fileExists(filepath.Join(dir, name)) {
+				return &nxTarget{
+					Executor: "@nx/jest:jest",
+					Outputs:  []string{"{workspaceRoot}/coverage/{projectRoot}"},
+					Options:  map[string]string{"jestConfig": projectRoot + "/" + name},
+				}
+			}
+		}
+		return command("npx jest")
+	case "go":
+		return command("go test ./...")
+	case "python":
+		return command("python -m pytest")
+	}
+	return nil
+}
+
+var targetsPattern = regexp.MustCompile(`"targets"\s*:\s*\{`)
+
+// addNxTarget adds a target to a project.json unless it has one by that
+// name. The file is edited in place, keeping its key order and indentation.
+func addNxTarget(project, name string, target *nxTarget) (bool, error) {
+	data, err := os.ReadFile(project)
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s: %w", project, err)
+	}
+	var parsed struct {
+		Targets map[string]json.RawMessage `json:"targets"`
+	}
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		return false, fmt.Errorf("failed to parse %s: %w", project, err)
+	}
+	if _, ok := parsed.Targets[name]; ok {
+		return false, nil
+	}
+
+	content := string(data)
+	indent := jsonIndent(content)
+	body, err := json.MarshalIndent(target, indent+indent, indent)
+	if err != nil {
+		return false, err
+	}
+	entry := fmt.Sprintf("%s%s%q: %s", indent, indent, name, body)
+
+	if loc := targetsPattern.FindStringIndex(content); loc != nil {
+		rest := content[loc[1]:]
+		if strings.HasPrefix(strings.TrimSpace(rest), "}") {
+			content = content[:loc[1]] + "\n" + entry + "\n" + indent + strings.TrimLeft(rest, " \t\r\n")
+		} else {
+			content = content[:loc[1]] + "\n" + entry + "," + rest
+		}
+	} else {
+		end := strings.LastIndex(content, "}")
+		head := strings.TrimRight(content[:end], " \t\r\n")
+		sep := ","
+		if strings.HasSuffix(head, "{") {
+			sep = ""
+		}
+		content = head + sep + "\n" + indent + `"targets": {` + "\n" + entry + "\n" + indent + "}\n" + content[end:]
+	}
+
+	if !json.Valid([]byte(content)) {
+		return false, fmt.Errorf("failed to add a %s target to %s", name, project)
+	}
+	if err := os.WriteFile(project, []byte(content), 0644); err != nil {
+		return false, fmt.Errorf("failed to write %s: %w", project, err)
+	}
+	return true, nil
+}
+
+// jsonIndent returns the indentation of a JSON file's first key, two
+// spaces when it has none
+func jsonIndent(content string) string {
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimLeft(line, " \t")
+		if strings.HasPrefix(trimmed, `"`) && len(trimmed) < len(line) {
+			return line[:len(line)-len(trimmed)]
+		}
+	}
+	return "  "
+}
@@ -0,0 +1,473 @@
+package buildsys
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// pythonRequirement is a distribution generated tests need and the version
+// specifier added for it
+type pythonRequirement struct {
+	Name    string
+	Version string
+}
+
+// pythonTestModules are the modules generated pytest tests import, and the
+// distributions providing them
+var pythonTestModules = map[string]pythonRequirement{
+	"pytest":         {"pytest", ">=8.0"},
+	"httpx":          {"httpx", ">=0.27"},
+	"pytest_asyncio": {"pytest-asyncio", ">=0.23"},
+	"assertpy":       {"assertpy", ">=1.1"},
+	"syrupy":         {"syrupy", ">=4.6"},
+	"requests":       {"requests", ">=2.31"},
+	"respx":          {"respx", ">=0.21"},
+	"responses":      {"responses", ">=0.25"},
+	"grpc":           {"grpcio", ">=1.62"},
+}
+
+// pythonProjectFiles mark the root of a Python project
+var pythonProjectFiles = []string{"pyproject.toml", "setup.py", "setup.cfg", "Pipfile", "requirements.txt", "requirements-dev.txt"}
+
+// pythonDevRequirements are the requirements files test dependencies are
+// added to when a project has one
+var pythonDevRequirements = []string{
+	"requirements-dev.txt", "requirements-test.txt", "dev-requirements.txt", "test-requirements.txt",
+	filepath.Join("requirements", "dev.txt"), filepath.Join("requirements", "test.txt"),
+}
+
+// Where Python test dependencies are declared
+const (
+	PythonRequirements = "requirements" // a requirements file
+	PythonPoetry       = "poetry"       // a Poetry dependency group
+	PythonPipenv       = "pipenv"       // a Pipfile's dev-packages
+	PythonExtras       = "extras"       // a [project.optional-dependencies] extra
+	PythonGroup        = "group"        // a PEP 735 [dependency-groups] group
+)
+
+// PythonSetup is what a Python project must declare before its generated
+// pytest tests run
+type PythonSetup struct {
+	Root         string
+	File         string // the file the dependencies go in, created if missing
+	Format       string
+	Group        string // the extra, group or table they go in
+	Dependencies map[string]string
+}
+
+// CheckPython returns the test dependencies each Python project holding
+// testFiles lacks, leaving out projects that need nothing. Tests outside
+// any project are skipped.
+func CheckPython(testFiles []string) ([]*PythonSetup, error) {
+	byProject := make(map[string][]string)
+	var dirs []string
+	for _, file := range testFiles {
+		if testLanguage(file) != "python" {
+			continue
+		}
+		abs, err := filepath.Abs(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve %s: %w", file, err)
+		}
+		dir, _ := findUp(filepath.VolumeName(abs)+string(filepath.Separator), filepath.Dir(abs), pythonProjectFiles...)
+		if dir == "" {
+			continue
+		}
+		if _, ok := byProject[dir]; !ok {
+			dirs = append(dirs, dir)
+		}
+		byProject[dir] = append(byProject[dir], abs)
+	}
+
+	var setups []*PythonSetup
+	for _, dir := range dirs {
+		setup, err := checkPythonProject(dir, byProject[dir])
+		if err != nil {
+			return nil, err
+		}
+		if len(setup.Dependencies) > 0 {
+			setups = append(setups, setup)
+		}
+	}
+	return setups, nil
+}
+
+var (
+	pyImportPattern    = regexp.MustCompile(`(?m)^\s*(?:from\s+([A-Za-z_][\w.]*)\s+import|import\s+([A-Za-z_][\w.]*))`)
+	pySnapshotPattern  = regexp.MustCompile(`def test_\w+\([^)]*\bsnapshot\b`)
+	requirementPattern = regexp.MustCompile(`^\s*([A-Za-z0-9][A-Za-z0-9._-]*)`)
+	quotedPattern      = regexp.MustCompile(`["']([A-Za-z0-9][A-Za-z0-9._-]*)[^"'\n]*["']`)
+	tomlKeyPattern     = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9._-]*)\s*=`)
+	tomlStringPattern  = regexp.MustCompile(`"[^"]*"|'[^']*'`)
+	depArrayPattern    = regexp.MustCompile(`^[\w.-]*(?:dependencies|requires?)\w*\s*=\s*\[`)
+)
+
+// checkPythonProject works out which test dependencies the project in dir
+// doesn't declare, and where to declare them
+func checkPythonProject(dir string, testFiles []string) (*PythonSetup, error) {
+	setup := &PythonSetup{Root: dir, Dependencies: make(map[string]string)}
+
+	need := map[string]bool{"pytest": true}
+	for _, file := range testFiles {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		for _, module := range pythonImports(string(data)) {
+			need[module] = true
+		}
+	}
+
+	declared := pythonDeclared(dir)
+	for module := range need {
+		req, ok := pythonTestModules[module]
+		if ok && !declared[normalizePythonName(req.Name)] {
+			setup.Dependencies[req.Name] = req.Version
+		}
+	}
+
+	setup.File, setup.Format, setup.Group = pythonTarget(dir)
+	return setup, nil
+}
+
+// pythonImports returns the test tooling modules a pytest file uses,
+// including those it needs without importing them
+func pythonImports(source string) []string {
+	var modules []string
+	add := func(m string) {
+		if !containsString(modules, m) {
+			modules = append(modules, m)
+		}
+	}
+	for _, m := range pyImportPattern.FindAllStringSubmatch(source, -1) {
+		name := m[1] + m[2]
+		switch {
+		case strings.HasPrefix(name, "fastapi.testclient"), strings.HasPrefix(name, "starlette.testclient"):
+			add("httpx") // TestClient is built on httpx
+		default:
+			add(strings.SplitN(name, ".", 2)[0])
+		}
+	}
+	if strings.Contains(source, "@pytest.mark.asyncio") {
+		add("pytest_asyncio")
+	}
+	if pySnapshotPattern.MatchString(source) {
+		add("syrupy")
+	}
+	return modules
+}
+
+// pythonDeclared returns the normalized names of the distributions the
+// project in dir declares anywhere. Reading loosely errs towards declared,
+// so nothing is added twice.
+func pythonDeclared(dir string) map[string]bool {
+	declared := make(map[string]bool)
+	var reqFiles []string
+	if matches, err := filepath.Glob(filepath.Join(dir, "*requirements*.txt")); err == nil {
+		reqFiles = append(reqFiles, matches...)
+	}
+	if matches, err := filepath.Glob(filepath.Join(dir, "requirements", "*.txt")); err == nil {
+		reqFiles = append(reqFiles, matches...)
+	}
+	for _, file := range reqFiles {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			if m := requirementPattern.FindStringSubmatch(line); m != nil {
+				declared[normalizePythonName(m[1])] = true
+			}
+		}
+	}
+
+	for _, name := range []string{"pyproject.toml", "Pipfile", "setup.cfg", "setup.py"} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+		// Requirements are quoted in dependency tables and arrays, and
+		// anywhere in setup.py
+		inDeps, inArray := false, false
+		for _, line := range strings.Split(string(data), "\n") {
+			trimmed := strings.TrimSpace(line)
+			if strings.HasPrefix(trimmed, "[") && !strings.Contains(trimmed, "=") {
+				inDeps = strings.Contains(trimmed, "dependencies") || strings.Contains(trimmed, "packages") || strings.Contains(trimmed, "options.extras_require")
+				continue
+			}
+			if depArrayPattern.MatchString(trimmed) {
+				inArray = true
+			}
+			if inDeps || inArray || name == "setup.py" {
+				for _, m := range quotedPattern.FindAllStringSubmatch(line, -1) {
+					declared[normalizePythonName(m[1])] = true
+				}
+			}
+			if inArray && strings.Contains(tomlStringPattern.ReplaceAllString(trimmed, ""), "]") {
+				inArray = false
+			}
+			if inDeps {
+				if m := tomlKeyPattern.FindStringSubmatch(trimmed); m != nil {
+					declared[normalizePythonName(m[1])] = true
+				}
+			}
+			// setup.cfg lists install_requires one per indented line
+			if name == "setup.cfg" && line != trimmed {
+				if m := requirementPattern.FindStringSubmatch(trimmed); m != nil {
+					declared[normalizePythonName(m[1])] = true
+				}
+			}
+		}
+	}
+	return declared
+}
+
+// normalizePythonName normalizes a distribution name as PEP 503 does
+func normalizePythonName(name string) string {
+	name = strings.ToLower(name)
+	return strings.NewReplacer("_", "-", ".", "-").Replace(name)
+}
+
+// pythonTarget picks where the project in dir declares test dependencies:
+// an existing dev requirements file, Poetry's dev group, the Pipfile's
+// dev-packages, a dependency group or extra in pyproject.toml, else a new
+// requirements-dev.txt
+func pythonTarget(dir string) (file, format, group string) {
+	for _, name := range pythonDevRequirements {
+		if fileExists(filepath.Join(dir, name)) {
+			return filepath.Join(dir, name), PythonRequirements, ""
+		}
+	}
+
+	pyproject := filepath.Join(dir, "pyproject.toml")
+	data, _ := os.ReadFile(pyproject)
+	content := string(data)
+	switch {
+	case tomlHasTable(content, "tool.poetry.dev-dependencies"):
+		return pyproject, PythonPoetry, "tool.poetry.dev-dependencies"
+	case tomlHasTable(content, "tool.poetry"):
+		return pyproject, PythonPoetry, "tool.poetry.group.dev.dependencies"
+	}
+	if fileExists(filepath.Join(dir, "Pipfile")) {
+		return filepath.Join(dir, "Pipfile"), PythonPipenv, "dev-packages"
+	}
+	switch {
+	case tomlHasTable(content, "dependency-groups"):
+		return pyproject, PythonGroup, tomlArrayKey(content, "dependency-groups")
+	case tomlHasTable(content, "project"):
+		return pyproject, PythonExtras, tomlArrayKey(content, "project.optional-dependencies")
+	}
+	return filepath.Join(dir, "requirements-dev.txt"), PythonRequirements, ""
+}
+
+// tomlArrayKey returns the test or dev key of a table of arrays, "test"
+// when it has neither
+func tomlArrayKey(content, table string) string {
+	start, end, ok := tomlTable(content, table)
+	if !ok {
+		return "test"
+	}
+	for _, key := range []string{"test", "tests", "dev"} {
+		if regexp.MustCompile(`(?m)^` + key + `\s*=\s*\[`).MatchString(content[start:end]) {
+			return key
+		}
+	}
+	return "test"
+}
+
+// Report describes what the project lacks
+func (s *PythonSetup) Report() string {
+	where := filepath.Base(s.File)
+	if s.Format != PythonRequirements {
+		where += " " + s.section()
+	}
+	return fmt.Sprintf("%s (%s)\n   dependencies: %s\n", s.Root, where, strings.Join(s.Requirements(), ", "))
+}
+
+// Summary is a one-line summary of the change Apply makes, for PR bodies
+func (s *PythonSetup) Summary(root string) string {
+	return fmt.Sprintf("%s: %s", filepath.ToSlash(mustRel(root, s.File)), strings.Join(s.Requirements(), ", "))
+}
+
+// section names where in File the dependencies go
+func (s *PythonSetup) section() string {
+	switch s.Format {
+	case PythonPoetry, PythonPipenv:
+		return "[" + s.Group + "]"
+	case PythonExtras:
+		return "[project.optional-dependencies] " + s.Group
+	case PythonGroup:
+		return "[dependency-groups] " + s.Group
+	}
+	return ""
+}
+
+// InstallCommand returns the command installing the project's test
+// dependencies once they are declared
+func (s *PythonSetup) InstallCommand() string {
+	switch s.Format {
+	case PythonPoetry:
+		return "poetry install --with dev"
+	case PythonPipenv:
+		return "pipenv install --dev"
+	case PythonExtras:
+		return fmt.Sprintf("pip install -e \".[%s]\"", s.Group)
+	case PythonGroup:
+		if fileExists(filepath.Join(s.Root, "uv.lock")) {
+			return "uv sync --group " + s.Group
+		}
+		return "pip install --group " + s.Group
+	}
+	return "pip install -r " + filepath.ToSlash(mustRel(s.Root, s.File))
+}
+
+// Requirements returns the missing dependencies as requirement specifiers
+func (s *PythonSetup) Requirements() []string {
+	var reqs []string
+	for _, name := range sortedKeys(s.Dependencies) {
+		reqs = append(reqs, name+s.Dependencies[name])
+	}
+	return reqs
+}
+
+// Apply declares the missing dependencies in File, creating it when
+// needed. Packages still have to be installed.
+func (s *PythonSetup) Apply() error {
+	data, err := os.ReadFile(s.File)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %w", s.File, err)
+	}
+	content := string(data)
+
+	switch s.Format {
+	case PythonRequirements:
+		if content == "" && fileExists(filepath.Join(s.Root, "requirements.txt")) && filepath.Dir(s.File) == s.Root {
+			content = "-r requirements.txt\n"
+		}
+		if content != "" && !strings.HasSuffix(content, "\n") {
+			content += "\n"
+		}
+		content += strings.Join(s.Requirements(), "\n") + "\n"
+	case PythonPoetry, PythonPipenv:
+		var lines []string
+		for _, name := range sortedKeys(s.Dependencies) {
+			lines = append(lines, fmt.Sprintf("%s = %q", name, s.Dependencies[name]))
+		}
+		content = tomlAppendToTable(content, s.Group, lines)
+	case PythonExtras, PythonGroup:
+		table := "project.optional-dependencies"
+		if s.Format == PythonGroup {
+			table = "dependency-groups"
+		}
+		content = tomlAddToArray(content, table, s.Group, s.Requirements())
+	default:
+		return fmt.Errorf("unknown Python dependency format %q", s.Format)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.File), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(s.File), err)
+	}
+	if err := os.WriteFile(s.File, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", s.File, err)
+	}
+	return nil
+}
+
+// tomlTable returns the bounds of a TOML table's body, from after its
+// header to the next table's
+func tomlTable(content, table string) (int, int, bool) {
+	header := regexp.MustCompile(`(?m)^\[` + regexp.QuoteMeta(table) + `\][ \t]*(?:#.*)?$`)
+	loc := header.FindStringIndex(content)
+	if loc == nil {
+		return 0, 0, false
+	}
+	start := loc[1]
+	if start < len(content) && content[start] == '\n' {
+		start++
+	}
+	end := len(content)
+	if next := regexp.MustCompile(`(?m)^\[`).FindStringIndex(content[start:]); next != nil {
+		end = start + next[0]
+	}
+	return start, end, true
+}
+
+func tomlHasTable(content, table string) bool {
+	_, _, ok := tomlTable(content, table)
+	return ok
+}
+
+// tomlAppendToTable adds lines at the end of a table, creating it at the
+// end of the file when missing
+func tomlAppendToTable(content, table string, lines []string) string {
+	start, end, ok := tomlTable(content, table)
+	if !ok {
+		if content != "" {
+			content = strings.TrimRight(content, "\n") + "\n\n"
+		}
+		return content + "[" + table + "]\n" + strings.Join(lines, "\n") + "\n"
+	}
+	body := strings.TrimRight(content[start:end], " \t\n")
+	if body != "" {
+		body += "\n"
+	}
+	rest := content[end:]
+	if rest != "" {
+		rest = "\n" + rest
+	}
+	return content[:start] + body + strings.Join(lines, "\n") + "\n" + rest
+}
+
+// tomlAddToArray adds strings to the array under key in a table of arrays,
+// adding the key, or the table, when missing
+func tomlAddToArray(content, table, key string, items []string) string {
+	quoted := make([]string, len(items))
+	for i, item := range items {
+		quoted[i] = fmt.Sprintf("%q", item)
+	}
+
+	start, end, ok := tomlTable(content, table)
+	var loc []int
+	if ok {
+		loc = regexp.MustCompile(`(?m)^` + regexp.QuoteMeta(key) + `\s*=\s*\[`).FindStringIndex(content[start:end])
+	}
+	if loc == nil {
+		lines := []string{key + " = ["}
+		for _, q := range quoted {
+			lines = append(lines, "    "+q+",")
+		}
+		return tomlAppendToTable(content, table, append(lines, "]"))
+	}
+
+	open := start + loc[1] - 1
+	end = matchClose(content, open)
+	inner := content[open+1 : end]
+	if !strings.Contains(inner, "\n") {
+		// One line: ["a", "b"]
+		sep := ", "
+		if strings.TrimSpace(inner) == "" {
+			sep = ""
+		}
+		return content[:open+1] + strings.TrimRight(inner, " ,") + sep + strings.Join(quoted, ", ") + content[end:]
+	}
+
+	indent := "    "
+	for _, line := range strings.Split(inner, "\n") {
+		if trimmed := strings.TrimLeft(line, " \t"); trimmed != "" && len(trimmed) < len(line) {
+			indent = line[:len(line)-len(trimmed)]
+			break
+		}
+	}
+	body := strings.TrimRight(inner, " \t\n")
+	if strings.TrimSpace(body) != "" && !strings.HasSuffix(body, ",") && !strings.HasSuffix(body, "[") {
+		body += ","
+	}
+	for _, q := range quoted {
+		body += "\n" + indent + q + ","
+	}
+	closeIndent := inner[strings.LastIndex(inner, "\n")+1:]
+	return content[:open+1] + body + "\n" + closeIndent + content[end:]
+}
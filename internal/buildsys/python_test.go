@@ -0,0 +1,179 @@
+package buildsys
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const fastAPITest = `import pytest
+from fastapi.testclient import TestClient
+from assertpy import assert_that
+
+from app.main import app
+
+
+@pytest.mark.asyncio
+async def test_get_items(snapshot):
+    assert_that(TestClient(app).get("/items").status_code).is_equal_to(200)
+`
+
+func TestCheckPython_Extras(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"pyproject.toml": `[project]
+name = "shop"
+description = "An httpx-free shop"
+dependencies = [
+    "fastapi>=0.110",
+    "uvicorn[standard]>=0.29",
+]
+
+[project.optional-dependencies]
+test = [
+    "pytest>=7",
+    "assertpy"
+]
+
+[tool.ruff]
+line-length = 100
+`,
+		"tests/test_items.py": fastAPITest,
+	})
+
+	setups, err := CheckPython([]string{filepath.Join(root, "tests", "test_items.py"), filepath.Join(root, "web.test.js")})
+	if err != nil {
+		t.Fatalf("CheckPython() error = %v", err)
+	}
+	if len(setups) != 1 {
+		t.Fatalf("CheckPython() = %d setups, want 1", len(setups))
+	}
+	setup := setups[0]
+	want := map[string]string{"httpx": ">=0.27", "pytest-asyncio": ">=0.23", "syrupy": ">=4.6"}
+	if !reflect.DeepEqual(setup.Dependencies, want) {
+		t.Errorf("Dependencies = %v, want %v", setup.Dependencies, want)
+	}
+	if setup.Format != PythonExtras || setup.Group != "test" || setup.InstallCommand() != `pip install -e ".[test]"` {
+		t.Errorf("setup = %+v, install %q", setup, setup.InstallCommand())
+	}
+
+	if err := setup.Apply(); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	wantFile := `[project.optional-dependencies]
+test = [
+    "pytest>=7",
+    "assertpy",
+    "httpx>=0.27",
+    "pytest-asyncio>=0.23",
+    "syrupy>=4.6",
+]
+
+[tool.ruff]
+`
+	if got := readFile(t, setup.File); !strings.Contains(got, wantFile) {
+		t.Errorf("pyproject.toml =\n%s", got)
+	}
+	if got := setup.Summary(root); got != "pyproject.toml: httpx>=0.27, pytest-asyncio>=0.23, syrupy>=4.6" {
+		t.Errorf("Summary() = %q", got)
+	}
+
+	if setups, _ := CheckPython([]string{filepath.Join(root, "tests", "test_items.py")}); len(setups) != 0 {
+		t.Errorf("CheckPython() after Apply() = %+v, want nothing", setups[0])
+	}
+}
+
+func TestCheckPython_Targets(t *testing.T) {
+	tests := []struct {
+		name   string
+		files  map[string]string
+		file   string
+		format string
+		want   string
+	}{
+		{
+			name:   "requirements",
+			files:  map[string]string{"requirements.txt": "fastapi\nrequests==2.31\n"},
+			file:   "requirements-dev.txt",
+			format: PythonRequirements,
+			want:   "-r requirements.txt\npytest>=8.0\n",
+		},
+		{
+			name:   "dev requirements",
+			files:  map[string]string{"requirements-dev.txt": "black"},
+			file:   "requirements-dev.txt",
+			format: PythonRequirements,
+			want:   "black\npytest>=8.0\n",
+		},
+		{
+			name:   "poetry",
+			files:  map[string]string{"pyproject.toml": "[tool.poetry]\nname = \"shop\"\n\n[tool.poetry.dependencies]\npython = \"^3.11\"\n"},
+			file:   "pyproject.toml",
+			format: PythonPoetry,
+			want:   "[tool.poetry]\nname = \"shop\"\n\n[tool.poetry.dependencies]\npython = \"^3.11\"\n\n[tool.poetry.group.dev.dependencies]\npytest = \">=8.0\"\n",
+		},
+		{
+			name:   "pipenv",
+			files:  map[string]string{"Pipfile": "[packages]\nflask = \"*\"\n\n[dev-packages]\nblack = \"*\"\n\n[requires]\npython_version = \"3.11\"\n"},
+			file:   "Pipfile",
+			format: PythonPipenv,
+			want:   "[packages]\nflask = \"*\"\n\n[dev-packages]\nblack = \"*\"\npytest = \">=8.0\"\n\n[requires]\npython_version = \"3.11\"\n",
+		},
+		{
+			name:   "dependency group",
+			files:  map[string]string{"pyproject.toml": "[project]\nname = \"shop\"\n\n[dependency-groups]\ndev = [\"ruff\"]\n"},
+			file:   "pyproject.toml",
+			format: PythonGroup,
+			want:   "[project]\nname = \"shop\"\n\n[dependency-groups]\ndev = [\"ruff\", \"pytest>=8.0\"]\n",
+		},
+		{
+			name:   "new extra",
+			files:  map[string]string{"pyproject.toml": "[project]\nname = \"shop\"\n"},
+			file:   "pyproject.toml",
+			format: PythonExtras,
+			want:   "[project]\nname = \"shop\"\n\n[project.optional-dependencies]\ntest = [\n    \"pytest>=8.0\",\n]\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			tt.files["tests/test_app.py"] = "def test_ok():\n    assert True\n"
+			writeFiles(t, root, tt.files)
+
+			setups, err := CheckPython([]string{filepath.Join(root, "tests", "test_app.py")})
+			if err != nil || len(setups) != 1 {
+				t.Fatalf("CheckPython() = %v, %v", setups, err)
+			}
+			setup := setups[0]
+			if setup.File != filepath.Join(root, tt.file) || setup.Format != tt.format {
+				t.Errorf("target = %s (%s), want %s (%s)", setup.File, setup.Format, tt.file, tt.format)
+			}
+			if err := setup.Apply(); err != nil {
+				t.Fatalf("Apply() error = %v", err)
+			}
+			if got := readFile(t, setup.File); got != tt.want {
+				t.Errorf("%s =\n%s\nwant\n%s", tt.file, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPythonDeclared(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"setup.cfg":             "[options]\ninstall_requires =\n    Requests>=2\n\n[options.extras_require]\ntest =\n    pytest_asyncio\n",
+		"requirements/test.txt": "httpx==0.27.0  # pinned\n",
+	})
+
+	declared := pythonDeclared(root)
+	for _, name := range []string{"requests", "pytest-asyncio", "httpx"} {
+		if !declared[name] {
+			t.Errorf("%s should be declared: %v", name, declared)
+		}
+	}
+	if declared["pytest"] {
+		t.Error("pytest isn't declared")
+	}
+}
@@ -0,0 +1,244 @@
+// Package capture turns recorded HTTP traffic into API test specs. Traffic
+// comes from a HAR file (exported from a browser, Charles, mitmproxy, ...)
+// or from qtest's own recording proxy. Payloads are sanitized before they
+// reach a spec, so tests built from staging traffic don't carry personal
+// data or credentials.
+package capture
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+// Exchange is one recorded request/response pair
+type Exchange struct {
+	Method          string
+	URL             *url.URL
+	RequestHeaders  http.Header
+	RequestBody     []byte
+	Status          int
+	ResponseHeaders http.Header
+	ResponseBody    []byte
+	StartedAt       time.Time
+	Duration        time.Duration
+}
+
+// HAR is an HTTP Archive (HAR 1.2) document. Only the fields qtest reads
+// or writes are declared.
+type HAR struct {
+	Log HARLog `json:"log"`
+}
+
+// HARLog is the root of a HAR document
+type HARLog struct {
+	Version string     `json:"version"`
+	Creator HARCreator `json:"creator"`
+	Entries []HAREntry `json:"entries"`
+}
+
+// HARCreator names the tool that wrote the archive
+type HARCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// HAREntry is one request/response pair
+type HAREntry struct {
+	StartedDateTime string      `json:"startedDateTime"`
+	Time            float64     `json:"time"` // milliseconds
+	Request         HARRequest  `json:"request"`
+	Response        HARResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         HARTimings  `json:"timings"`
+}
+
+// HARRequest is a recorded request
+type HARRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Headers     []HARNameValue `json:"headers"`
+	QueryString []HARNameValue `json:"queryString"`
+	Cookies     []HARNameValue `json:"cookies"`
+	PostData    *HARPostData   `json:"postData,omitempty"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+// HARResponse is a recorded response
+type HARResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Headers     []HARNameValue `json:"headers"`
+	Cookies     []HARNameValue `json:"cookies"`
+	Content     HARContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+// HARNameValue is a header, query parameter or cookie
+type HARNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// HARPostData is a request body
+type HARPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+// HARContent is a response body, base64-encoded when binary
+type HARContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+// HARTimings breaks down an entry's time; qtest only records the wait
+type HARTimings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+// LoadHAR reads the exchanges from a HAR file. Entries that can't be
+// interpreted (bad URLs, undecodable bodies) are skipped.
+func LoadHAR(path string) ([]Exchange, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read HAR: %w", err)
+	}
+	var har HAR
+	if err := json.Unmarshal(data, &har); err != nil {
+		return nil, fmt.Errorf("failed to parse HAR: %w", err)
+	}
+	return har.Exchanges(), nil
+}
+
+// Exchanges converts the archive's entries
+func (h *HAR) Exchanges() []Exchange {
+	exchanges := make([]Exchange, 0, len(h.Log.Entries))
+	for _, e := range h.Log.Entries {
+		u, err := url.Parse(e.Request.URL)
+		if err != nil {
+			continue
+		}
+		ex := Exchange{
+			Method:          e.Request.Method,
+			URL:             u,
+			RequestHeaders:  toHeader(e.Request.Headers),
+			Status:          e.Response.Status,
+			ResponseHeaders: toHeader(e.Response.Headers),
+			Duration:        time.Duration(e.Time * float64(time.Millisecond)),
+		}
+		ex.StartedAt, _ = time.Parse(time.RFC3339Nano, e.StartedDateTime)
+		if e.Request.PostData != nil {
+			ex.RequestBody = []byte(e.Request.PostData.Text)
+		}
+		body := []byte(e.Response.Content.Text)
+		if e.Response.Content.Encoding == "base64" {
+			if body, err = base64.StdEncoding.DecodeString(e.Response.Content.Text); err != nil {
+				continue
+			}
+		}
+		ex.ResponseBody = body
+		exchanges = append(exchanges, ex)
+	}
+	return exchanges
+}
+
+// NewHAR builds an archive from recorded exchanges
+func NewHAR(exchanges []Exchange) *HAR {
+	har := &HAR{Log: HARLog{
+		Version: "1.2",
+		Creator: HARCreator{Name: "qtest", Version: "1.0"},
+		Entries: make([]HAREntry, 0, len(exchanges)),
+	}}
+	for _, ex := range exchanges {
+		ms := float64(ex.Duration) / float64(time.Millisecond)
+		entry := HAREntry{
+			StartedDateTime: ex.StartedAt.Format(time.RFC3339Nano),
+			Time:            ms,
+			Request: HARRequest{
+				Method:      ex.Method,
+				URL:         ex.URL.String(),
+				HTTPVersion: "HTTP/1.1",
+				Headers:     fromHeader(ex.RequestHeaders),
+				QueryString: fromQuery(ex.URL.Query()),
+				Cookies:     []HARNameValue{},
+				HeadersSize: -1,
+				BodySize:    len(ex.RequestBody),
+			},
+			Response: HARResponse{
+				Status:      ex.Status,
+				StatusText:  http.StatusText(ex.Status),
+				HTTPVersion: "HTTP/1.1",
+				Headers:     fromHeader(ex.ResponseHeaders),
+				Cookies:     []HARNameValue{},
+				Content: HARContent{
+					Size:     len(ex.ResponseBody),
+					MimeType: ex.ResponseHeaders.Get("Content-Type"),
+					Text:     string(ex.ResponseBody),
+				},
+				HeadersSize: -1,
+				BodySize:    len(ex.ResponseBody),
+			},
+			Timings: HARTimings{Wait: ms},
+		}
+		if len(ex.RequestBody) > 0 {
+			entry.Request.PostData = &HARPostData{
+				MimeType: ex.RequestHeaders.Get("Content-Type"),
+				Text:     string(ex.RequestBody),
+			}
+		}
+		har.Log.Entries = append(har.Log.Entries, entry)
+	}
+	return har
+}
+
+// WriteHAR saves exchanges as a HAR file. The archive holds raw traffic,
+// so it's only readable by the current user.
+func WriteHAR(path string, exchanges []Exchange) error {
+	data, err := json.MarshalIndent(NewHAR(exchanges), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+func toHeader(pairs []HARNameValue) http.Header {
+	h := make(http.Header, len(pairs))
+	for _, p := range pairs {
+		h.Add(p.Name, p.Value)
+	}
+	return h
+}
+
+func fromHeader(h http.Header) []HARNameValue {
+	pairs := make([]HARNameValue, 0, len(h))
+	for name, values := range h {
+		for _, v := range values {
+			pairs = append(pairs, HARNameValue{Name: name, Value: v})
+		}
+	}
+	return pairs
+}
+
+func fromQuery(q url.Values) []HARNameValue {
+	pairs := make([]HARNameValue, 0, len(q))
+	for name, values := range q {
+		for _, v := range values {
+			pairs = append(pairs, HARNameValue{Name: name, Value: v})
+		}
+	}
+	return pairs
+}
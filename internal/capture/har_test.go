@@ -0,0 +1,110 @@
+package capture
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const sampleHAR = `{
+  "log": {
+    "version": "1.2",
+    "creator": {"name": "browser", "version": "1"},
+    "entries": [
+      {
+        "startedDateTime": "2026-01-02T03:04:05.000Z",
+        "time": 12.5,
+        "request": {
+          "method": "POST",
+          "url": "https://staging.example.com/api/users?invite=true",
+          "headers": [{"name": "Content-Type", "value": "application/json"}, {"name": "Authorization", "value": "Bearer abc"}],
+          "postData": {"mimeType": "application/json", "text": "{\"email\":\"ann@corp.com\",\"plan\":\"pro\"}"}
+        },
+        "response": {
+          "status": 201,
+          "headers": [{"name": "Content-Type", "value": "application/json"}],
+          "content": {"mimeType": "application/json", "text": "%s", "encoding": "base64"}
+        }
+      },
+      {
+        "request": {"method": "GET", "url": "://bad"},
+        "response": {"status": 200, "content": {}}
+      }
+    ]
+  }
+}`
+
+func writeHAR(t *testing.T) string {
+	t.Helper()
+	body := base64.StdEncoding.EncodeToString([]byte(`{"id":7,"email":"ann@corp.com"}`))
+	path := filepath.Join(t.TempDir(), "traffic.har")
+	if err := os.WriteFile(path, []byte(fmt.Sprintf(sampleHAR, body)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadHAR(t *testing.T) {
+	exchanges, err := LoadHAR(writeHAR(t))
+	if err != nil {
+		t.Fatalf("LoadHAR() error = %v", err)
+	}
+	if len(exchanges) != 1 {
+		t.Fatalf("len(exchanges) = %d, want 1 (bad URL skipped)", len(exchanges))
+	}
+	ex := exchanges[0]
+	if ex.Method != "POST" || ex.URL.Path != "/api/users" || ex.Status != 201 {
+		t.Errorf("exchange = %s %s -> %d", ex.Method, ex.URL, ex.Status)
+	}
+	if ex.RequestHeaders.Get("Authorization") != "Bearer abc" {
+		t.Errorf("RequestHeaders = %v", ex.RequestHeaders)
+	}
+	if string(ex.ResponseBody) != `{"id":7,"email":"ann@corp.com"}` {
+		t.Errorf("ResponseBody = %s, want base64-decoded", ex.ResponseBody)
+	}
+	if ex.Duration != 12500*time.Microsecond || ex.StartedAt.Year() != 2026 {
+		t.Errorf("timing = %v at %v", ex.Duration, ex.StartedAt)
+	}
+
+	if _, err := LoadHAR(filepath.Join(t.TempDir(), "missing.har")); err == nil {
+		t.Error("expected error for a missing file")
+	}
+}
+
+func TestWriteHAR_RoundTrip(t *testing.T) {
+	u, _ := url.Parse("http://localhost:8080/items?page=2")
+	original := []Exchange{{
+		Method:          "PUT",
+		URL:             u,
+		RequestHeaders:  http.Header{"Content-Type": {"application/json"}},
+		RequestBody:     []byte(`{"name":"x"}`),
+		Status:          200,
+		ResponseHeaders: http.Header{"Content-Type": {"application/json"}},
+		ResponseBody:    []byte(`{"ok":true}`),
+		StartedAt:       time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		Duration:        3 * time.Millisecond,
+	}}
+
+	path := filepath.Join(t.TempDir(), "out.har")
+	if err := WriteHAR(path, original); err != nil {
+		t.Fatalf("WriteHAR() error = %v", err)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0600 {
+		t.Errorf("HAR mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	loaded, err := LoadHAR(path)
+	if err != nil || len(loaded) != 1 {
+		t.Fatalf("LoadHAR() = %d exchanges, err %v", len(loaded), err)
+	}
+	got := loaded[0]
+	if got.Method != "PUT" || got.URL.String() != u.String() || string(got.RequestBody) != `{"name":"x"}` ||
+		string(got.ResponseBody) != `{"ok":true}` || got.Duration != 3*time.Millisecond {
+		t.Errorf("round trip = %+v", got)
+	}
+}
@@ -0,0 +1,140 @@
+package capture
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// DefaultMaxBodySize is the largest body recorded per request or response;
+// larger bodies are truncated in the recording but passed through intact
+const DefaultMaxBodySize = 1 << 20
+
+// Recorder is a reverse proxy to a target service that records every
+// exchange passing through it. Point clients (or a load balancer mirror)
+// at it instead of the service.
+type Recorder struct {
+	MaxBodySize int
+
+	target    *url.URL
+	proxy     *httputil.ReverseProxy
+	mu        sync.Mutex
+	exchanges []Exchange
+}
+
+// NewRecorder creates a recording proxy for the target base URL
+func NewRecorder(target *url.URL) *Recorder {
+	r := &Recorder{MaxBodySize: DefaultMaxBodySize, target: target}
+	r.proxy = httputil.NewSingleHostReverseProxy(target)
+	r.proxy.ModifyResponse = r.record
+
+	// Rewrite Host so virtual-hosted staging services route correctly
+	director := r.proxy.Director
+	r.proxy.Director = func(req *http.Request) {
+		director(req)
+		req.Host = target.Host
+	}
+	return r
+}
+
+type recordingKey struct{}
+
+// pending holds what's known about an exchange before its response arrives
+type pending struct {
+	url       *url.URL
+	headers   http.Header
+	body      []byte
+	startedAt time.Time
+}
+
+func withPending(ctx context.Context, p *pending) context.Context {
+	return context.WithValue(ctx, recordingKey{}, p)
+}
+
+func pendingFrom(ctx context.Context) (*pending, bool) {
+	p, ok := ctx.Value(recordingKey{}).(*pending)
+	return p, ok
+}
+
+// ServeHTTP forwards the request to the target and records the exchange
+func (r *Recorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	p := &pending{
+		url:       r.publicURL(req),
+		headers:   req.Header.Clone(),
+		startedAt: time.Now(),
+	}
+	if req.Body != nil {
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			http.Error(w, "failed to read request body", http.StatusBadGateway)
+			return
+		}
+		p.body = r.truncate(body)
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	r.proxy.ServeHTTP(w, req.WithContext(withPending(req.Context(), p)))
+}
+
+func (r *Recorder) record(resp *http.Response) error {
+	p, ok := pendingFrom(resp.Request.Context())
+	if !ok {
+		return nil
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.exchanges = append(r.exchanges, Exchange{
+		Method:          resp.Request.Method,
+		URL:             p.url,
+		RequestHeaders:  p.headers,
+		RequestBody:     p.body,
+		Status:          resp.StatusCode,
+		ResponseHeaders: resp.Header.Clone(),
+		ResponseBody:    r.truncate(body),
+		StartedAt:       p.startedAt,
+		Duration:        time.Since(p.startedAt),
+	})
+	return nil
+}
+
+// Exchanges returns the exchanges recorded so far
+func (r *Recorder) Exchanges() []Exchange {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Exchange(nil), r.exchanges...)
+}
+
+// Len returns the number of exchanges recorded so far
+func (r *Recorder) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.exchanges)
+}
+
+// publicURL is the URL as the target sees it, so recordings show the
+// service's paths rather than the proxy's address
+func (r *Recorder) publicURL(req *http.Request) *url.URL {
+	u := *req.URL
+	u.Scheme = r.target.Scheme
+	u.Host = r.target.Host
+	return &u
+}
+
+func (r *Recorder) truncate(body []byte) []byte {
+	if r.MaxBodySize > 0 && len(body) > r.MaxBodySize {
+		return body[:r.MaxBodySize]
+	}
+	return body
+}
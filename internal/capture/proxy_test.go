@@ -0,0 +1,81 @@
+package capture
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestRecorder(t *testing.T) {
+	var gotBody, gotHost string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		gotBody, gotHost = string(body), r.Host
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id":1}`))
+	}))
+	defer upstream.Close()
+
+	target, _ := url.Parse(upstream.URL)
+	recorder := NewRecorder(target)
+	proxy := httptest.NewServer(recorder)
+	defer proxy.Close()
+
+	resp, err := http.Post(proxy.URL+"/users?x=1", "application/json", strings.NewReader(`{"name":"a"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	// The client and service see the traffic untouched
+	if resp.StatusCode != http.StatusCreated || string(body) != `{"id":1}` {
+		t.Errorf("proxied response = %d %s", resp.StatusCode, body)
+	}
+	if gotBody != `{"name":"a"}` || gotHost != target.Host {
+		t.Errorf("upstream got body %q host %q", gotBody, gotHost)
+	}
+
+	exchanges := recorder.Exchanges()
+	if recorder.Len() != 1 || len(exchanges) != 1 {
+		t.Fatalf("recorded %d exchanges, want 1", len(exchanges))
+	}
+	ex := exchanges[0]
+	if ex.Method != "POST" || ex.URL.Host != target.Host || ex.URL.Path != "/users" || ex.URL.RawQuery != "x=1" {
+		t.Errorf("recorded %s %s", ex.Method, ex.URL)
+	}
+	if string(ex.RequestBody) != `{"name":"a"}` || string(ex.ResponseBody) != `{"id":1}` || ex.Status != 201 {
+		t.Errorf("recorded exchange = %+v", ex)
+	}
+}
+
+func TestRecorder_TruncatesLargeBodies(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(strings.Repeat("x", 100)))
+	}))
+	defer upstream.Close()
+
+	target, _ := url.Parse(upstream.URL)
+	recorder := NewRecorder(target)
+	recorder.MaxBodySize = 10
+	proxy := httptest.NewServer(recorder)
+	defer proxy.Close()
+
+	resp, err := http.Get(proxy.URL + "/big")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if len(body) != 100 {
+		t.Errorf("client got %d bytes, want the full 100", len(body))
+	}
+	if n := len(recorder.Exchanges()[0].ResponseBody); n != 10 {
+		t.Errorf("recorded %d bytes, want 10", n)
+	}
+}
@@ -0,0 +1,137 @@
+package capture
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/QTest-hq/qtest/internal/datagen"
+)
+
+// Redacted replaces secrets, which have no meaningful fake
+const Redacted = "redacted"
+
+var (
+	embeddedEmail = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	uuidValue     = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+)
+
+// secretHints are field-name fragments for credentials, which are redacted
+// rather than faked
+var secretHints = []string{"password", "passwd", "secret", "token", "apikey", "api_key", "authorization", "credential", "session", "cookie"}
+
+// keptHeaders are the request headers carried into specs; everything else
+// (Authorization, Cookie, tracing and proxy headers) is dropped
+var keptHeaders = []string{"Content-Type", "Accept"}
+
+// Sanitizer replaces personal data and secrets in captured payloads.
+// Replacements are consistent: a value seen twice (say a user ID in a
+// request and the response that echoes it) gets the same fake both times.
+type Sanitizer struct {
+	gen   *datagen.DataGenerator
+	fakes map[string]string // original -> replacement
+}
+
+// NewSanitizer creates a sanitizer
+func NewSanitizer() *Sanitizer {
+	return &Sanitizer{gen: datagen.NewDataGenerator(), fakes: make(map[string]string)}
+}
+
+// NewSeededSanitizer creates a sanitizer with reproducible replacements
+func NewSeededSanitizer(seed int64) *Sanitizer {
+	return &Sanitizer{gen: datagen.NewSeededDataGenerator(seed), fakes: make(map[string]string)}
+}
+
+// SetLocale switches the fake personal data to the given locale
+func (s *Sanitizer) SetLocale(code string) error {
+	return s.gen.SetLocale(code)
+}
+
+// Value sanitizes a decoded JSON value (or query or path parameter) named
+// name, recursing into objects and arrays. Numbers and booleans are kept:
+// they're rarely personal and tests usually depend on them.
+func (s *Sanitizer) Value(name string, v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(val))
+		for k, child := range val {
+			out[k] = s.Value(k, child)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(val))
+		for i, child := range val {
+			out[i] = s.Value(name, child)
+		}
+		return out
+	case string:
+		return s.String(name, val)
+	default:
+		return v
+	}
+}
+
+// String sanitizes a single string value
+func (s *Sanitizer) String(name, v string) string {
+	if v == "" {
+		return v
+	}
+	if isSecret(name) {
+		return Redacted
+	}
+	if _, err := strconv.ParseFloat(v, 64); err == nil {
+		return v // numeric IDs, amounts
+	}
+	if datagen.IsPII(name, v) {
+		return s.fake(name, v)
+	}
+	// Free text can still mention an email address
+	return embeddedEmail.ReplaceAllStringFunc(v, func(email string) string {
+		return s.fake("email", email)
+	})
+}
+
+// Headers returns the request headers safe to keep in a spec
+func (s *Sanitizer) Headers(h http.Header) map[string]string {
+	var kept map[string]string
+	for _, name := range keptHeaders {
+		if v := h.Get(name); v != "" && v != "*/*" {
+			if kept == nil {
+				kept = make(map[string]string)
+			}
+			kept[name] = v
+		}
+	}
+	return kept
+}
+
+// fake returns the replacement for a value, generating one shaped like the
+// original on first sight
+func (s *Sanitizer) fake(name, original string) string {
+	if f, ok := s.fakes[original]; ok {
+		return f
+	}
+	var f string
+	switch {
+	case embeddedEmail.MatchString(original) && !strings.Contains(original, " "):
+		f = s.gen.Email()
+	case uuidValue.MatchString(original):
+		f = s.gen.UUID()
+	default:
+		f = fmt.Sprint(s.gen.GenerateForType("string", name))
+	}
+	s.fakes[original] = f
+	return f
+}
+
+func isSecret(name string) bool {
+	lower := strings.ToLower(name)
+	for _, hint := range secretHints {
+		if strings.Contains(lower, hint) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,60 @@
+package capture
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestSanitizer_Value(t *testing.T) {
+	s := NewSeededSanitizer(42)
+	in := map[string]interface{}{
+		"email":    "ann@corp.com",
+		"password": "hunter2",
+		"plan":     "pro",
+		"age":      34.0,
+		"order_id": "1001",
+		"note":     "call ann@corp.com after 5",
+		"contacts": []interface{}{map[string]interface{}{"phone": "+1 555-010-0199"}},
+	}
+
+	out := s.Value("", in).(map[string]interface{})
+	if out["email"] == "ann@corp.com" || !strings.Contains(out["email"].(string), "@") {
+		t.Errorf("email = %v, want a fake email", out["email"])
+	}
+	if out["password"] != Redacted {
+		t.Errorf("password = %v, want %s", out["password"], Redacted)
+	}
+	if out["plan"] != "pro" || out["age"] != 34.0 || out["order_id"] != "1001" {
+		t.Errorf("non-personal values changed: %v", out)
+	}
+	if note := out["note"].(string); strings.Contains(note, "ann@corp.com") || !strings.HasPrefix(note, "call ") {
+		t.Errorf("note = %q, want the embedded email replaced", note)
+	}
+	phone := out["contacts"].([]interface{})[0].(map[string]interface{})["phone"]
+	if phone == "+1 555-010-0199" {
+		t.Error("nested phone not sanitized")
+	}
+
+	// The same value gets the same replacement everywhere
+	if s.String("owner_email", "ann@corp.com") != out["email"] {
+		t.Error("replacement for a repeated value is not consistent")
+	}
+}
+
+func TestSanitizer_Headers(t *testing.T) {
+	h := http.Header{
+		"Content-Type":  {"application/json"},
+		"Accept":        {"*/*"},
+		"Authorization": {"Bearer abc"},
+		"Cookie":        {"session=1"},
+		"X-Request-Id":  {"r1"},
+	}
+	got := NewSanitizer().Headers(h)
+	if len(got) != 1 || got["Content-Type"] != "application/json" {
+		t.Errorf("Headers() = %v, want only Content-Type (wildcard Accept dropped)", got)
+	}
+	if NewSanitizer().Headers(http.Header{}) != nil {
+		t.Error("Headers() of no headers should be nil")
+	}
+}
@@ -0,0 +1,300 @@
+package capture
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/QTest-hq/qtest/pkg/model"
+)
+
+// TagCaptured marks specs built from recorded traffic
+const TagCaptured = "captured"
+
+// DefaultMaxPerEndpoint is how many distinct captured requests become specs
+// for each endpoint and status code
+const DefaultMaxPerEndpoint = 3
+
+// maxBodyAssertions caps the field assertions made on a response body
+const maxBodyAssertions = 10
+
+var (
+	// routeParam matches path parameters in the common styles: :id, {id},
+	// {id:int} and <int:id>
+	routeParam = regexp.MustCompile(`:(\w+)|\{(\w+)[^}]*\}|<(?:\w+:)?(\w+)>`)
+	identifier = regexp.MustCompile(`^[A-Za-z_]\w*$`)
+	numericID  = regexp.MustCompile(`^\d+$`)
+)
+
+// staticExtensions are assets that aren't API traffic
+var staticExtensions = map[string]bool{
+	".js": true, ".css": true, ".map": true, ".html": true, ".htm": true,
+	".png": true, ".jpg": true, ".jpeg": true, ".gif": true, ".svg": true, ".ico": true, ".webp": true,
+	".woff": true, ".woff2": true, ".ttf": true, ".eot": true,
+}
+
+// Options control how exchanges become specs
+type Options struct {
+	Host           string     // only convert traffic to this host (empty = all)
+	MaxPerEndpoint int        // distinct requests kept per endpoint and status
+	Sanitizer      *Sanitizer // defaults to an unseeded sanitizer
+}
+
+// Converter turns captured exchanges into API test specs, matching them to
+// the model's endpoints where it can
+type Converter struct {
+	routes []route
+	opts   Options
+}
+
+// route is a model endpoint compiled for matching request paths
+type route struct {
+	endpoint model.Endpoint
+	pattern  *regexp.Regexp
+	params   []string
+}
+
+// NewConverter creates a converter. Endpoints may be nil, in which case
+// numeric and UUID path segments are turned into parameters.
+func NewConverter(endpoints []model.Endpoint, opts Options) *Converter {
+	if opts.MaxPerEndpoint <= 0 {
+		opts.MaxPerEndpoint = DefaultMaxPerEndpoint
+	}
+	if opts.Sanitizer == nil {
+		opts.Sanitizer = NewSanitizer()
+	}
+
+	c := &Converter{opts: opts}
+	for _, ep := range endpoints {
+		c.routes = append(c.routes, compileRoute(ep))
+	}
+	// Prefer literal routes over parameterized ones: /users/me before /users/:id
+	sort.SliceStable(c.routes, func(i, j int) bool {
+		return len(c.routes[i].params) < len(c.routes[j].params)
+	})
+	return c
+}
+
+// Convert builds one spec per distinct request, up to MaxPerEndpoint for
+// each endpoint and status code. Non-API traffic (static assets, HTML
+// pages, preflight requests) is skipped.
+func (c *Converter) Convert(exchanges []Exchange) []model.TestSpec {
+	var specs []model.TestSpec
+	perEndpoint := make(map[string]int)
+	seen := make(map[string]bool)
+
+	for _, ex := range exchanges {
+		if !c.isAPI(ex) {
+			continue
+		}
+		spec := c.toSpec(ex)
+
+		group := fmt.Sprintf("%s %s %d", spec.Method, spec.Path, ex.Status)
+		signature := group + " " + requestSignature(spec)
+		if seen[signature] || perEndpoint[group] >= c.opts.MaxPerEndpoint {
+			continue
+		}
+		seen[signature] = true
+		perEndpoint[group]++
+
+		spec.ID = fmt.Sprintf("spec:captured:%s:%s:%d:%d", spec.Method, spec.Path, ex.Status, perEndpoint[group])
+		specs = append(specs, spec)
+	}
+	return specs
+}
+
+func (c *Converter) isAPI(ex Exchange) bool {
+	if ex.URL == nil || ex.Status == 0 {
+		return false // aborted or unparseable
+	}
+	switch ex.Method {
+	case "OPTIONS", "CONNECT", "TRACE":
+		return false
+	}
+	if c.opts.Host != "" && ex.URL.Host != c.opts.Host {
+		return false
+	}
+	if staticExtensions[strings.ToLower(path.Ext(ex.URL.Path))] {
+		return false
+	}
+	if len(ex.ResponseBody) > 0 && !isJSON(ex.ResponseHeaders.Get("Content-Type")) {
+		return false
+	}
+	return true
+}
+
+func (c *Converter) toSpec(ex Exchange) model.TestSpec {
+	s := c.opts.Sanitizer
+	spec := model.TestSpec{
+		Level:      model.LevelAPI,
+		TargetKind: "endpoint",
+		Method:     strings.ToUpper(ex.Method),
+		Headers:    s.Headers(ex.RequestHeaders),
+		Expected:   map[string]interface{}{"status": ex.Status},
+		Tags:       []string{TagCaptured},
+		Priority:   "high",
+	}
+
+	template, params, endpointID := c.matchPath(spec.Method, ex.URL.Path)
+	spec.Path = template
+	spec.TargetID = endpointID
+	for name, value := range params {
+		if spec.PathParams == nil {
+			spec.PathParams = make(map[string]interface{})
+		}
+		spec.PathParams[name] = s.String(name, value)
+	}
+
+	for name, values := range ex.URL.Query() {
+		if spec.QueryParams == nil {
+			spec.QueryParams = make(map[string]interface{})
+		}
+		spec.QueryParams[name] = s.String(name, values[0])
+	}
+
+	spec.Body = c.requestBody(ex)
+	spec.Description = fmt.Sprintf("%s %s returns %d (captured)", spec.Method, spec.Path, ex.Status)
+	spec.Assertions = responseAssertions(ex)
+	return spec
+}
+
+// matchPath finds the model endpoint for a request path, returning its
+// path template, the parameter values and the endpoint ID. Paths with no
+// matching endpoint are templated by guessing which segments are IDs.
+func (c *Converter) matchPath(method, requestPath string) (string, map[string]string, string) {
+	for _, r := range c.routes {
+		if r.endpoint.Method != "" && !strings.EqualFold(r.endpoint.Method, method) &&
+			!strings.EqualFold(r.endpoint.Method, "ALL") && !strings.EqualFold(r.endpoint.Method, "ANY") {
+			continue
+		}
+		m := r.pattern.FindStringSubmatch(requestPath)
+		if m == nil {
+			continue
+		}
+		params := make(map[string]string, len(r.params))
+		for i, name := range r.params {
+			params[name] = m[i+1]
+		}
+		return r.endpoint.Path, params, r.endpoint.ID
+	}
+	template, params := templatePath(requestPath)
+	return template, params, ""
+}
+
+// MatchEndpoint finds the endpoint serving a request, returning its path
+// template, the parameter values and the endpoint ID ("" when no endpoint
+// matches and the path was templated instead)
+func MatchEndpoint(endpoints []model.Endpoint, method, requestPath string) (string, map[string]string, string) {
+	return NewConverter(endpoints, Options{}).matchPath(strings.ToUpper(method), requestPath)
+}
+
+// requestBody decodes a JSON or form request body and sanitizes it
+func (c *Converter) requestBody(ex Exchange) interface{} {
+	if len(ex.RequestBody) == 0 {
+		return nil
+	}
+	contentType := ex.RequestHeaders.Get("Content-Type")
+	switch {
+	case strings.Contains(contentType, "application/x-www-form-urlencoded"):
+		form, err := url.ParseQuery(string(ex.RequestBody))
+		if err != nil {
+			return nil
+		}
+		body := make(map[string]interface{}, len(form))
+		for name, values := range form {
+			body[name] = c.opts.Sanitizer.String(name, values[0])
+		}
+		return body
+	default:
+		var body interface{}
+		if err := json.Unmarshal(ex.RequestBody, &body); err != nil {
+			return nil // truncated or not JSON
+		}
+		return c.opts.Sanitizer.Value("", body)
+	}
+}
+
+// responseAssertions checks the captured status and that the response has
+// the same top-level fields. Field values come from staging data, so they
+// aren't asserted.
+func responseAssertions(ex Exchange) []model.Assertion {
+	assertions := []model.Assertion{{Kind: "status_code", Actual: "status", Expected: ex.Status}}
+
+	var body map[string]interface{}
+	if json.Unmarshal(ex.ResponseBody, &body) != nil {
+		return assertions
+	}
+	keys := make([]string, 0, len(body))
+	for k := range body {
+		if identifier.MatchString(k) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	if len(keys) > maxBodyAssertions {
+		keys = keys[:maxBodyAssertions]
+	}
+	for _, k := range keys {
+		assertions = append(assertions, model.Assertion{Kind: "not_null", Actual: "body." + k})
+	}
+	return assertions
+}
+
+// compileRoute turns an endpoint path into a regexp with one group per
+// parameter
+func compileRoute(ep model.Endpoint) route {
+	r := route{endpoint: ep}
+	var sb strings.Builder
+	sb.WriteString("^")
+	last := 0
+	for _, loc := range routeParam.FindAllStringSubmatchIndex(ep.Path, -1) {
+		sb.WriteString(regexp.QuoteMeta(ep.Path[last:loc[0]]))
+		sb.WriteString("([^/]+)")
+		for g := 1; g <= 3; g++ {
+			if loc[2*g] >= 0 {
+				r.params = append(r.params, ep.Path[loc[2*g]:loc[2*g+1]])
+			}
+		}
+		last = loc[1]
+	}
+	sb.WriteString(regexp.QuoteMeta(strings.TrimSuffix(ep.Path[last:], "/")))
+	sb.WriteString("/?$")
+	r.pattern = regexp.MustCompile(sb.String())
+	return r
+}
+
+// templatePath replaces numeric and UUID segments with :id parameters
+// (:id2, :id3, ... when there are several)
+func templatePath(requestPath string) (string, map[string]string) {
+	segments := strings.Split(requestPath, "/")
+	var params map[string]string
+	for i, seg := range segments {
+		if !numericID.MatchString(seg) && !uuidValue.MatchString(seg) {
+			continue
+		}
+		if params == nil {
+			params = make(map[string]string)
+		}
+		name := "id"
+		if n := len(params); n > 0 {
+			name = fmt.Sprintf("id%d", n+1)
+		}
+		params[name] = seg
+		segments[i] = ":" + name
+	}
+	return strings.Join(segments, "/"), params
+}
+
+// requestSignature identifies a request's inputs, to skip repeats
+func requestSignature(spec model.TestSpec) string {
+	data, _ := json.Marshal([]interface{}{spec.PathParams, spec.QueryParams, spec.Body})
+	return string(data)
+}
+
+func isJSON(contentType string) bool {
+	return strings.Contains(strings.ToLower(contentType), "json")
+}
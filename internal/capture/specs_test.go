@@ -0,0 +1,135 @@
+package capture
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/QTest-hq/qtest/pkg/model"
+)
+
+func exchange(method, rawURL string, status int, reqBody, respBody string) Exchange {
+	u, _ := url.Parse(rawURL)
+	ex := Exchange{
+		Method:          method,
+		URL:             u,
+		RequestHeaders:  http.Header{},
+		Status:          status,
+		ResponseHeaders: http.Header{},
+		RequestBody:     []byte(reqBody),
+		ResponseBody:    []byte(respBody),
+	}
+	if reqBody != "" {
+		ex.RequestHeaders.Set("Content-Type", "application/json")
+	}
+	if respBody != "" {
+		ex.ResponseHeaders.Set("Content-Type", "application/json; charset=utf-8")
+	}
+	return ex
+}
+
+func TestConverter_Convert(t *testing.T) {
+	endpoints := []model.Endpoint{
+		{ID: "ep:get-user", Method: "GET", Path: "/users/:id"},
+		{ID: "ep:me", Method: "GET", Path: "/users/me"},
+		{ID: "ep:create-user", Method: "POST", Path: "/users"},
+	}
+	exchanges := []Exchange{
+		exchange("GET", "https://staging.example.com/users/42?fields=email", 200, "", `{"id":42,"email":"ann@corp.com","full-name":"Ann"}`),
+		exchange("GET", "https://staging.example.com/users/42?fields=email", 200, "", `{"id":42}`), // repeat
+		exchange("GET", "https://staging.example.com/users/me", 200, "", `{"id":1}`),
+		exchange("POST", "https://staging.example.com/users", 201, `{"email":"bob@corp.com","plan":"pro"}`, `{"id":43}`),
+		exchange("GET", "https://staging.example.com/app.js", 200, "", ""),
+		exchange("OPTIONS", "https://staging.example.com/users", 204, "", ""),
+		exchange("GET", "https://cdn.example.com/users/1", 200, "", `{}`),
+	}
+	html := exchange("GET", "https://staging.example.com/", 200, "", "<html></html>")
+	html.ResponseHeaders.Set("Content-Type", "text/html")
+	exchanges = append(exchanges, html)
+
+	specs := NewConverter(endpoints, Options{Host: "staging.example.com", Sanitizer: NewSeededSanitizer(1)}).Convert(exchanges)
+	if len(specs) != 3 {
+		for _, s := range specs {
+			t.Logf("%s %s", s.Method, s.Path)
+		}
+		t.Fatalf("len(specs) = %d, want 3", len(specs))
+	}
+
+	get := specs[0]
+	if get.TargetID != "ep:get-user" || get.Path != "/users/:id" || get.PathParams["id"] != "42" {
+		t.Errorf("GET spec = %s %s %v", get.TargetID, get.Path, get.PathParams)
+	}
+	if get.Level != model.LevelAPI || get.Tags[0] != TagCaptured || get.ID == "" {
+		t.Errorf("GET spec metadata = %+v", get)
+	}
+	if get.QueryParams["fields"] != "email" {
+		t.Errorf("QueryParams = %v", get.QueryParams)
+	}
+	// status, then not_null for each identifier-like field
+	if len(get.Assertions) != 3 || get.Assertions[0].Expected != 200 || get.Assertions[1].Actual != "body.email" {
+		t.Errorf("Assertions = %+v", get.Assertions)
+	}
+
+	if specs[1].TargetID != "ep:me" {
+		t.Errorf("/users/me matched %s, want the literal route", specs[1].TargetID)
+	}
+
+	post := specs[2]
+	body := post.Body.(map[string]interface{})
+	if body["email"] == "bob@corp.com" || body["plan"] != "pro" {
+		t.Errorf("POST body = %v, want email sanitized", body)
+	}
+	if post.Headers["Content-Type"] != "application/json" || post.Expected["status"] != 201 {
+		t.Errorf("POST spec = %+v", post)
+	}
+}
+
+func TestConverter_MaxPerEndpoint(t *testing.T) {
+	var exchanges []Exchange
+	for _, id := range []string{"1", "2", "3", "4"} {
+		exchanges = append(exchanges, exchange("GET", "http://api/orders/"+id, 200, "", `{}`))
+	}
+	exchanges = append(exchanges, exchange("GET", "http://api/orders/5", 404, "", `{}`))
+
+	specs := NewConverter(nil, Options{MaxPerEndpoint: 2}).Convert(exchanges)
+	if len(specs) != 3 {
+		t.Fatalf("len(specs) = %d, want 2 for 200 plus 1 for 404", len(specs))
+	}
+	if specs[0].Path != "/orders/:id" || specs[0].TargetID != "" {
+		t.Errorf("unmatched path templated as %s", specs[0].Path)
+	}
+}
+
+func TestTemplatePath(t *testing.T) {
+	tests := []struct {
+		path   string
+		want   string
+		params map[string]string
+	}{
+		{"/users", "/users", nil},
+		{"/users/42/orders/7", "/users/:id/orders/:id2", map[string]string{"id": "42", "id2": "7"}},
+		{"/files/5f0c6b3e-2a51-4c1d-9d7e-0f6a1c2b3d4e", "/files/:id", map[string]string{"id": "5f0c6b3e-2a51-4c1d-9d7e-0f6a1c2b3d4e"}},
+	}
+	for _, tt := range tests {
+		got, params := templatePath(tt.path)
+		if got != tt.want || len(params) != len(tt.params) {
+			t.Errorf("templatePath(%s) = %s %v, want %s %v", tt.path, got, params, tt.want, tt.params)
+		}
+		for k, v := range tt.params {
+			if params[k] != v {
+				t.Errorf("templatePath(%s) param %s = %s, want %s", tt.path, k, params[k], v)
+			}
+		}
+	}
+}
+
+func TestCompileRoute(t *testing.T) {
+	r := compileRoute(model.Endpoint{Path: "/orgs/{org_id:int}/members/<int:member>"})
+	m := r.pattern.FindStringSubmatch("/orgs/3/members/9/")
+	if m == nil || len(r.params) != 2 || r.params[0] != "org_id" || m[2] != "9" {
+		t.Errorf("compileRoute() params %v, match %v", r.params, m)
+	}
+	if r.pattern.MatchString("/orgs/3/members") {
+		t.Error("route matched a shorter path")
+	}
+}
@@ -9,9 +9,11 @@ import (
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strconv"
 	"time"
 
+	"github.com/QTest-hq/qtest/internal/platform"
 	"github.com/rs/zerolog/log"
 )
 
@@ -33,6 +35,7 @@ type FileCoverage struct {
 	CoveredLines   int     `json:"covered_lines"`
 	Percentage     float64 `json:"percentage"`
 	UncoveredLines []int   `json:"uncovered_lines"`
+	HitLines       []int   `json:"hit_lines,omitempty"` // lines run at least once, when the tool reports them
 }
 
 // UncoveredItem represents an uncovered code section
@@ -152,6 +155,9 @@ func (c *Collector) parseGoCoverage(coverFile string) (*CoverageReport, error) {
 		fc.TotalLines += numStmt
 		if count > 0 {
 			fc.CoveredLines += numStmt
+			for l := startLine; l <= endLine; l++ {
+				fc.HitLines = append(fc.HitLines, l)
+			}
 		} else {
 			// Track uncovered lines
 			for l := startLine; l <= endLine; l++ {
@@ -168,6 +174,7 @@ func (c *Collector) parseGoCoverage(coverFile string) (*CoverageReport, error) {
 
 	// Calculate totals
 	for _, fc := range fileMap {
+		fc.HitLines = uniqueLines(fc.HitLines)
 		if fc.TotalLines > 0 {
 			fc.Percentage = float64(fc.CoveredLines) / float64(fc.TotalLines) * 100
 		}
@@ -225,7 +232,8 @@ type PytestCoverageJSON struct {
 			NumStatements  int     `json:"num_statements"`
 			PercentCovered float64 `json:"percent_covered"`
 		} `json:"summary"`
-		MissingLines []int `json:"missing_lines"`
+		MissingLines  []int `json:"missing_lines"`
+		ExecutedLines []int `json:"executed_lines"`
 	} `json:"files"`
 }
 
@@ -257,6 +265,7 @@ func (c *Collector) parsePythonCoverage(coverFile string) (*CoverageReport, erro
 			CoveredLines:   fileCov.Summary.CoveredLines,
 			Percentage:     fileCov.Summary.PercentCovered,
 			UncoveredLines: fileCov.MissingLines,
+			HitLines:       fileCov.ExecutedLines,
 		}
 		report.Files = append(report.Files, fc)
 
@@ -279,7 +288,7 @@ func (c *Collector) collectJSCoverage(ctx context.Context) (*CoverageReport, err
 	coverDir := filepath.Join(c.workDir, "coverage")
 
 	// Run jest with coverage
-	cmd := exec.CommandContext(ctx, "npx", "jest", "--coverage", "--coverageReporters=json-summary", "--coverageDirectory="+coverDir)
+	cmd := platform.Command(ctx, "npx", "jest", "--coverage", "--coverageReporters=json-summary", "--coverageDirectory="+coverDir)
 	cmd.Dir = c.workDir
 	output, err := cmd.CombinedOutput()
 
@@ -467,3 +476,15 @@ func ParseGoCoverageFile(coverFile string) ([]FileCoverage, error) {
 
 	return result, nil
 }
+
+// uniqueLines sorts line numbers and drops repeats
+func uniqueLines(lines []int) []int {
+	sort.Ints(lines)
+	unique := lines[:0]
+	for i, l := range lines {
+		if i == 0 || l != lines[i-1] {
+			unique = append(unique, l)
+		}
+	}
+	return unique
+}
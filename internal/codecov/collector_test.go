@@ -1,6 +1,7 @@
 package codecov
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
@@ -147,6 +148,13 @@ github.com/test/util.go:5.1,10.1 4 2
 	if len(report.Uncovered) == 0 {
 		t.Error("Uncovered should not be empty")
 	}
+
+	// Lines of blocks that ran are recorded once each
+	for _, f := range report.Files {
+		if f.Path == "github.com/test/util.go" && fmt.Sprint(f.HitLines) != "[5 6 7 8 9 10]" {
+			t.Errorf("util.go HitLines = %v, want lines 5-10", f.HitLines)
+		}
+	}
 }
 
 func TestParseGoCoverage_EmptyFile(t *testing.T) {
@@ -3,7 +3,10 @@ package config
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
+	"time"
 )
 
 // Config holds all application configuration
@@ -27,8 +30,89 @@ type Config struct {
 	// GitHub
 	GitHubToken string
 
+	// Web dashboard base URL, linked from generated PRs
+	DashboardURL string
+
 	// GitHub OAuth
 	GitHubOAuth GitHubOAuthConfig
+
+	// API authentication and tenant scoping
+	Auth AuthConfig
+
+	// RepairIterations is how many times generation sends a test that
+	// fails to compile or pass back to the LLM with its errors; 0 leaves
+	// failing tests to validation
+	RepairIterations int
+
+	// StaticChecks compiles or type-checks each generated test before it's
+	// kept, rejecting ones that fail and can't be repaired
+	StaticChecks bool
+
+	// Validation stage
+	Validation ValidationConfig
+
+	// Targets generation keeps failing for
+	Untestable UntestableConfig
+
+	// Interactive requests ahead of batch pipelines
+	Lanes LanesConfig
+}
+
+// LanesConfig limits how far interactive jobs, such as single-file
+// generation requested from the API or an IDE, get ahead of other work
+type LanesConfig struct {
+	// InteractiveStreak is how many interactive jobs in a row a worker
+	// takes before running a waiting job from another lane, so pipelines
+	// aren't starved; 0 is no limit
+	InteractiveStreak int
+
+	// LLMConcurrency caps the LLM requests a process makes at once; 0 is
+	// no limit, and no lanes
+	LLMConcurrency int
+
+	// LLMInteractiveReserve is how many of those requests only interactive
+	// jobs may make, so they don't wait for pipelines' requests to finish
+	LLMInteractiveReserve int
+
+	// RepositoryConcurrency caps the jobs of one repository running at once
+	// across all workers, so a large monorepo can't fill the pool; 0 is no
+	// limit
+	RepositoryConcurrency int
+
+	// InteractiveConcurrency, DefaultConcurrency and BatchConcurrency cap
+	// each lane's running jobs across all workers; 0 is no limit
+	InteractiveConcurrency int
+	DefaultConcurrency     int
+	BatchConcurrency       int
+}
+
+// ValidationConfig tunes the pipeline's validation stage
+type ValidationConfig struct {
+	// Shards is the maximum number of validation jobs a generation run is
+	// split into, so worker replicas can validate in parallel
+	Shards int
+
+	// MinShardSize is the minimum number of tests per shard
+	MinShardSize int
+
+	// CacheDir holds validation results keyed by test and source file
+	// hashes, so unchanged tests aren't re-run
+	CacheDir string
+}
+
+// UntestableConfig controls how targets that generation repeatedly fails
+// for are reported
+type UntestableConfig struct {
+	// Threshold is the number of failed generations after which a target
+	// is considered untestable
+	Threshold int
+
+	// CreateIssues opens a GitHub issue for each untestable target, with
+	// suggested refactorings. Without it targets are only listed in the API.
+	CreateIssues bool
+
+	// IssueLabel is applied to the issues opened
+	IssueLabel string
 }
 
 // GitHubOAuthConfig holds GitHub OAuth configuration
@@ -38,6 +122,25 @@ type GitHubOAuthConfig struct {
 	RedirectURL  string
 }
 
+// AuthConfig controls how the API server authenticates requests. API keys
+// and GitHub sessions are always accepted; OIDC tokens when an issuer is
+// set.
+type AuthConfig struct {
+	// Required rejects API requests without credentials. Without it they
+	// act for no one and only see resources outside organizations. It's
+	// set whenever users can sign in, through GitHub or OIDC, and by the
+	// API server once API keys exist.
+	Required bool
+
+	// OIDC identity provider whose JWTs are accepted as bearer tokens
+	OIDCIssuer   string
+	OIDCAudience string // required with an issuer
+	OIDCJWKSURL  string // empty discovers it from the issuer
+
+	// OIDCUsernameClaim is the token claim used as a user's login
+	OIDCUsernameClaim string
+}
+
 // LLMConfig holds LLM-related configuration
 type LLMConfig struct {
 	// Default provider: ollama, anthropic, openai
@@ -48,16 +151,90 @@ type LLMConfig struct {
 	OllamaTier1 string
 	OllamaTier2 string
 
+	// OllamaKeepAlive is how long Ollama keeps a model loaded after a
+	// request, e.g. "30m" or "-1" for as long as it runs
+	OllamaKeepAlive string
+
+	// OllamaPreload loads the tier models when workers start, so the first
+	// generation doesn't wait minutes for them
+	OllamaPreload bool
+
+	// OllamaPingInterval is how often workers ping the tier models to keep
+	// them loaded while idle; 0 doesn't ping
+	OllamaPingInterval time.Duration
+
 	// Anthropic settings
 	AnthropicKey   string
 	AnthropicTier3 string
 
-	// OpenAI settings (fallback)
-	OpenAIKey string
+	// OpenAI-compatible settings, for OpenAI, vLLM, LM Studio, OpenRouter
+	// or Azure OpenAI. Each tier can use its own endpoint; a tier without a
+	// model isn't served by it.
+	OpenAIKey   string
+	OpenAIURL   string
+	OpenAITier1 OpenAIEndpoint
+	OpenAITier2 OpenAIEndpoint
+	OpenAITier3 OpenAIEndpoint
+
+	// Provider each tier is sent to first: ollama, anthropic or openai.
+	// Empty tries DefaultProvider first.
+	Tier1Provider string
+	Tier2Provider string
+	Tier3Provider string
+
+	// Ordered failover chain per tier, as comma-separated provider:model
+	// steps, e.g. "ollama:deepseek-coder-v2:16b,openai:gpt-4o-mini". A
+	// chain replaces the tier's other providers; see ParseChain.
+	Tier1Chain string
+	Tier2Chain string
+	Tier3Chain string
+
+	// Context windows of the tier models, in tokens. Targets too large
+	// for a run's tier are generated at the lowest tier they fit.
+	Tier1Context int
+	Tier2Context int
+	Tier3Context int
+
+	// SmallTargetTokens is the size of a target's prompt, without the
+	// system prompt, up to which it is generated at tier 1 whatever the
+	// run's tier; 0 keeps the run's tier
+	SmallTargetTokens int
+
+	// BreakerThreshold is how many failed attempts in a row open a
+	// provider's circuit, so requests skip it until BreakerCooldown has
+	// passed and a probe request succeeds; 0 disables the breakers
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+
+	// RetryBudget is the percentage of a provider's requests that may be
+	// retried; 0 doesn't limit retries
+	RetryBudget int
+
+	// EscalationRetries is how many times a target whose output was
+	// malformed or empty is retried at the next tier up; 0 doesn't retry
+	EscalationRetries int
+}
+
+// OpenAIEndpoint is the OpenAI-compatible chat completions API a tier uses
+type OpenAIEndpoint struct {
+	URL    string // base URL, e.g. https://api.openai.com/v1
+	Model  string
+	APIKey string
+
+	// APIVersion is the api-version of an Azure OpenAI deployment, whose
+	// URL is https://<resource>.openai.azure.com/openai/deployments/<name>
+	APIVersion string
 }
 
-// Load loads configuration from environment variables
+// Load loads configuration from environment variables, falling back to the
+// active profile's settings
 func Load() (*Config, error) {
+	profile, err := LoadActiveProfile()
+	if err != nil {
+		return nil, err
+	}
+	setProfileEnv(profile)
+
 	cfg := &Config{
 		Port:        getEnvInt("PORT", 8080),
 		Env:         getEnv("ENV", "development"),
@@ -66,26 +243,137 @@ func Load() (*Config, error) {
 		NATSURL:     getEnv("NATS_URL", "nats://localhost:4222"),
 		GitHubToken: getEnv("GITHUB_TOKEN", ""),
 
+		DashboardURL: getEnv("DASHBOARD_URL", ""),
+
+		RepairIterations: getEnvInt("GENERATION_REPAIR_ITERATIONS", 2),
+		StaticChecks:     getEnvBool("GENERATION_STATIC_CHECKS", true),
+
 		GitHubOAuth: GitHubOAuthConfig{
 			ClientID:     getEnv("GITHUB_OAUTH_CLIENT_ID", ""),
 			ClientSecret: getEnv("GITHUB_OAUTH_CLIENT_SECRET", ""),
 			RedirectURL:  getEnv("GITHUB_OAUTH_REDIRECT_URL", "http://localhost:8080/auth/callback"),
 		},
 
+		Auth: AuthConfig{
+			Required:          getEnvBool("AUTH_REQUIRED", false),
+			OIDCIssuer:        getEnv("OIDC_ISSUER", ""),
+			OIDCAudience:      getEnv("OIDC_AUDIENCE", ""),
+			OIDCJWKSURL:       getEnv("OIDC_JWKS_URL", ""),
+			OIDCUsernameClaim: getEnv("OIDC_USERNAME_CLAIM", "preferred_username"),
+		},
+
 		LLM: LLMConfig{
-			DefaultProvider: getEnv("LLM_DEFAULT_PROVIDER", "ollama"),
-			OllamaURL:       getEnv("OLLAMA_URL", "http://localhost:11434"),
-			OllamaTier1:     getEnv("OLLAMA_TIER1_MODEL", "qwen2.5-coder:7b"),
-			OllamaTier2:     getEnv("OLLAMA_TIER2_MODEL", "deepseek-coder-v2:16b"),
-			AnthropicKey:    getEnv("ANTHROPIC_API_KEY", ""),
-			AnthropicTier3:  getEnv("ANTHROPIC_TIER3_MODEL", "claude-3-5-sonnet-20241022"),
-			OpenAIKey:       getEnv("OPENAI_API_KEY", ""),
+			DefaultProvider:    getEnv("LLM_DEFAULT_PROVIDER", "ollama"),
+			OllamaURL:          getEnv("OLLAMA_URL", "http://localhost:11434"),
+			OllamaTier1:        getEnv("OLLAMA_TIER1_MODEL", "qwen2.5-coder:7b"),
+			OllamaTier2:        getEnv("OLLAMA_TIER2_MODEL", "deepseek-coder-v2:16b"),
+			OllamaKeepAlive:    getEnv("OLLAMA_KEEP_ALIVE", "30m"),
+			OllamaPreload:      getEnvBool("OLLAMA_PRELOAD", true),
+			OllamaPingInterval: getEnvDuration("OLLAMA_PING_INTERVAL", 0),
+			AnthropicKey:       getEnv("ANTHROPIC_API_KEY", ""),
+			AnthropicTier3:     getEnv("ANTHROPIC_TIER3_MODEL", "claude-3-5-sonnet-20241022"),
+			OpenAIKey:          getEnv("OPENAI_API_KEY", ""),
+			OpenAIURL:          getEnv("OPENAI_URL", "https://api.openai.com/v1"),
+			Tier1Provider:      getEnv("LLM_TIER1_PROVIDER", ""),
+			Tier2Provider:      getEnv("LLM_TIER2_PROVIDER", ""),
+			Tier3Provider:      getEnv("LLM_TIER3_PROVIDER", ""),
+			Tier1Chain:         getEnv("LLM_TIER1_CHAIN", ""),
+			Tier2Chain:         getEnv("LLM_TIER2_CHAIN", ""),
+			Tier3Chain:         getEnv("LLM_TIER3_CHAIN", ""),
+			Tier1Context:       getEnvInt("LLM_TIER1_CONTEXT", 8192),
+			Tier2Context:       getEnvInt("LLM_TIER2_CONTEXT", 16384),
+			Tier3Context:       getEnvInt("LLM_TIER3_CONTEXT", 200000),
+			SmallTargetTokens:  getEnvInt("LLM_SMALL_TARGET_TOKENS", 300),
+			BreakerThreshold:   getEnvInt("LLM_BREAKER_THRESHOLD", 5),
+			BreakerCooldown:    getEnvDuration("LLM_BREAKER_COOLDOWN", 30*time.Second),
+			RetryBudget:        getEnvInt("LLM_RETRY_BUDGET", 20),
+			EscalationRetries:  getEnvInt("LLM_ESCALATION_RETRIES", 2),
+		},
+
+		Validation: ValidationConfig{
+			Shards:       getEnvInt("VALIDATION_SHARDS", 1),
+			MinShardSize: getEnvInt("VALIDATION_MIN_SHARD_SIZE", 10),
+			CacheDir:     getEnv("VALIDATION_CACHE_DIR", filepath.Join(os.TempDir(), "qtest", "validation-cache")),
+		},
+
+		Untestable: UntestableConfig{
+			Threshold:    getEnvInt("UNTESTABLE_THRESHOLD", 3),
+			CreateIssues: getEnvBool("UNTESTABLE_CREATE_ISSUES", false),
+			IssueLabel:   getEnv("UNTESTABLE_ISSUE_LABEL", "qtest:untestable"),
 		},
+
+		Lanes: LanesConfig{
+			InteractiveStreak:     getEnvInt("LANE_INTERACTIVE_STREAK", 5),
+			LLMConcurrency:        getEnvInt("LLM_MAX_CONCURRENCY", 0),
+			LLMInteractiveReserve: getEnvInt("LLM_INTERACTIVE_RESERVE", 1),
+
+			RepositoryConcurrency:  getEnvInt("REPOSITORY_MAX_CONCURRENCY", 0),
+			InteractiveConcurrency: getEnvInt("LANE_INTERACTIVE_CONCURRENCY", 0),
+			DefaultConcurrency:     getEnvInt("LANE_DEFAULT_CONCURRENCY", 0),
+			BatchConcurrency:       getEnvInt("LANE_BATCH_CONCURRENCY", 0),
+		},
+	}
+
+	// Users signing in have organizations, which requests without
+	// credentials mustn't reach
+	if cfg.Auth.OIDCIssuer != "" || cfg.GitHubOAuth.ClientID != "" {
+		cfg.Auth.Required = true
 	}
 
+	cfg.LLM.OpenAITier1 = getOpenAIEndpoint(1, cfg.LLM.OpenAIURL, cfg.LLM.OpenAIKey)
+	cfg.LLM.OpenAITier2 = getOpenAIEndpoint(2, cfg.LLM.OpenAIURL, cfg.LLM.OpenAIKey)
+	cfg.LLM.OpenAITier3 = getOpenAIEndpoint(3, cfg.LLM.OpenAIURL, cfg.LLM.OpenAIKey)
+
 	return cfg, nil
 }
 
+// getOpenAIEndpoint reads a tier's OPENAI_TIER<n>_* variables, defaulting
+// its URL and key to the shared ones
+func getOpenAIEndpoint(tier int, url, apiKey string) OpenAIEndpoint {
+	prefix := fmt.Sprintf("OPENAI_TIER%d_", tier)
+	return OpenAIEndpoint{
+		URL:        getEnv(prefix+"URL", url),
+		Model:      getEnv(prefix+"MODEL", ""),
+		APIKey:     getEnv(prefix+"API_KEY", apiKey),
+		APIVersion: getEnv(prefix+"API_VERSION", getEnv("OPENAI_API_VERSION", "")),
+	}
+}
+
+// ChainStep is a step of a tier's failover chain
+type ChainStep struct {
+	Provider string
+	Model    string // empty uses the provider's model for the tier
+}
+
+// ParseChain parses a failover chain of comma-separated provider:model
+// steps. The model is everything after the first colon, so Ollama tags
+// such as qwen2.5-coder:7b are kept whole.
+func ParseChain(chain string) ([]ChainStep, error) {
+	var steps []ChainStep
+	for _, part := range strings.Split(chain, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		provider, model, _ := strings.Cut(part, ":")
+		step := ChainStep{Provider: strings.TrimSpace(provider), Model: strings.TrimSpace(model)}
+		if !knownProvider(step.Provider) {
+			return nil, fmt.Errorf("unknown provider %q in chain %q", step.Provider, chain)
+		}
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
+
+// knownProvider reports whether name is an LLM provider
+func knownProvider(name string) bool {
+	switch name {
+	case "ollama", "anthropic", "openai":
+		return true
+	}
+	return false
+}
+
 // Validate checks if required configuration is present
 func (c *Config) Validate() error {
 	// LLM validation - need at least one provider
@@ -100,21 +388,64 @@ func (c *Config) Validate() error {
 		}
 	}
 
+	tiers := []struct {
+		provider string
+		openai   OpenAIEndpoint
+		chain    string
+	}{
+		{c.LLM.Tier1Provider, c.LLM.OpenAITier1, c.LLM.Tier1Chain},
+		{c.LLM.Tier2Provider, c.LLM.OpenAITier2, c.LLM.Tier2Chain},
+		{c.LLM.Tier3Provider, c.LLM.OpenAITier3, c.LLM.Tier3Chain},
+	}
+	for i, tier := range tiers {
+		if _, err := ParseChain(tier.chain); err != nil {
+			return fmt.Errorf("LLM_TIER%d_CHAIN: %w", i+1, err)
+		}
+
+		switch tier.provider {
+		case "", "ollama", "anthropic":
+		case "openai":
+			if tier.openai.Model == "" {
+				return fmt.Errorf("OPENAI_TIER%d_MODEL required when tier %d uses openai", i+1, i+1)
+			}
+		default:
+			return fmt.Errorf("LLM_TIER%d_PROVIDER: unknown provider %q", i+1, tier.provider)
+		}
+	}
+
 	return nil
 }
 
 func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
+	if value := lookupEnv(key); value != "" {
 		return value
 	}
 	return defaultValue
 }
 
 func getEnvInt(key string, defaultValue int) int {
-	if value := os.Getenv(key); value != "" {
+	if value := lookupEnv(key); value != "" {
 		if i, err := strconv.Atoi(value); err == nil {
 			return i
 		}
 	}
 	return defaultValue
 }
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := lookupEnv(key); value != "" {
+		if d, err := time.ParseDuration(value); err == nil {
+			return d
+		}
+	}
+	return defaultValue
+}
+
+func getEnvBool(key string, defaultValue bool) bool {
+	if value := lookupEnv(key); value != "" {
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return defaultValue
+}
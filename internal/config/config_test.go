@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"testing"
+	"time"
 )
 
 func TestLoad_Defaults(t *testing.T) {
@@ -65,6 +66,18 @@ func TestLoad_LLMDefaults(t *testing.T) {
 	if cfg.LLM.AnthropicTier3 != "claude-3-5-sonnet-20241022" {
 		t.Errorf("LLM.AnthropicTier3 = %s, want claude-3-5-sonnet-20241022", cfg.LLM.AnthropicTier3)
 	}
+	if cfg.LLM.BreakerThreshold != 5 || cfg.LLM.BreakerCooldown != 30*time.Second || cfg.LLM.RetryBudget != 20 {
+		t.Errorf("breaker = %d/%v/%d%%, want 5/30s/20%%", cfg.LLM.BreakerThreshold, cfg.LLM.BreakerCooldown, cfg.LLM.RetryBudget)
+	}
+	if cfg.LLM.EscalationRetries != 2 {
+		t.Errorf("LLM.EscalationRetries = %d, want 2", cfg.LLM.EscalationRetries)
+	}
+	if cfg.RepairIterations != 2 {
+		t.Errorf("RepairIterations = %d, want 2", cfg.RepairIterations)
+	}
+	if !cfg.StaticChecks {
+		t.Error("StaticChecks = false, want true")
+	}
 }
 
 func TestLoad_FromEnv(t *testing.T) {
@@ -179,6 +192,94 @@ func TestValidate_OtherProvider(t *testing.T) {
 	}
 }
 
+func TestLoad_OpenAITiers(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "sk-shared")
+	t.Setenv("OPENAI_URL", "https://openrouter.ai/api/v1")
+	t.Setenv("OPENAI_TIER1_MODEL", "qwen/qwen-2.5-coder-32b-instruct")
+	t.Setenv("OPENAI_TIER2_URL", "http://localhost:1234/v1")
+	t.Setenv("OPENAI_TIER2_MODEL", "local-model")
+	t.Setenv("OPENAI_TIER2_API_KEY", "lm-studio")
+	t.Setenv("OPENAI_API_VERSION", "2024-06-01")
+	t.Setenv("LLM_TIER1_PROVIDER", "openai")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	want1 := OpenAIEndpoint{URL: "https://openrouter.ai/api/v1", Model: "qwen/qwen-2.5-coder-32b-instruct", APIKey: "sk-shared", APIVersion: "2024-06-01"}
+	if cfg.LLM.OpenAITier1 != want1 {
+		t.Errorf("OpenAITier1 = %+v, want %+v", cfg.LLM.OpenAITier1, want1)
+	}
+	if cfg.LLM.OpenAITier2.URL != "http://localhost:1234/v1" || cfg.LLM.OpenAITier2.APIKey != "lm-studio" {
+		t.Errorf("OpenAITier2 = %+v, want its own URL and key", cfg.LLM.OpenAITier2)
+	}
+	if cfg.LLM.OpenAITier3.Model != "" {
+		t.Errorf("OpenAITier3.Model = %q, want empty", cfg.LLM.OpenAITier3.Model)
+	}
+	if cfg.LLM.Tier1Provider != "openai" || cfg.LLM.Tier2Provider != "" {
+		t.Errorf("tier providers = %q/%q, want openai/empty", cfg.LLM.Tier1Provider, cfg.LLM.Tier2Provider)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+}
+
+func TestValidate_TierProviders(t *testing.T) {
+	tests := []struct {
+		name    string
+		llm     LLMConfig
+		wantErr bool
+	}{
+		{"unknown provider", LLMConfig{OllamaURL: "x", Tier2Provider: "bard"}, true},
+		{"openai without model", LLMConfig{OllamaURL: "x", Tier3Provider: "openai"}, true},
+		{"openai with model", LLMConfig{OllamaURL: "x", Tier3Provider: "openai", OpenAITier3: OpenAIEndpoint{Model: "gpt-4o"}}, false},
+		{"anthropic", LLMConfig{OllamaURL: "x", Tier3Provider: "anthropic"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{LLM: tt.llm}
+			if err := cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseChain(t *testing.T) {
+	steps, err := ParseChain(" ollama:deepseek-coder-v2:16b, openai:gpt-4o-mini ,anthropic:claude-3-haiku-20240307,ollama")
+	if err != nil {
+		t.Fatalf("ParseChain() error = %v", err)
+	}
+	want := []ChainStep{
+		{Provider: "ollama", Model: "deepseek-coder-v2:16b"},
+		{Provider: "openai", Model: "gpt-4o-mini"},
+		{Provider: "anthropic", Model: "claude-3-haiku-20240307"},
+		{Provider: "ollama"},
+	}
+	if len(steps) != len(want) {
+		t.Fatalf("steps = %+v, want %+v", steps, want)
+	}
+	for i := range want {
+		if steps[i] != want[i] {
+			t.Errorf("steps[%d] = %+v, want %+v", i, steps[i], want[i])
+		}
+	}
+
+	if steps, err := ParseChain(""); err != nil || len(steps) != 0 {
+		t.Errorf("ParseChain(\"\") = %v, %v, want no steps", steps, err)
+	}
+	if _, err := ParseChain("ollama:qwen,bard:x"); err == nil {
+		t.Error("expected error for an unknown provider")
+	}
+
+	cfg := &Config{LLM: LLMConfig{OllamaURL: "x", Tier2Chain: "gemini:pro"}}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validate() should reject a chain with an unknown provider")
+	}
+}
+
 func TestGetEnv(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -289,3 +390,124 @@ func TestLLMConfig_Fields(t *testing.T) {
 		t.Errorf("OpenAIKey mismatch")
 	}
 }
+
+func TestLoad_ValidationConfig(t *testing.T) {
+	t.Setenv("VALIDATION_SHARDS", "4")
+	t.Setenv("VALIDATION_MIN_SHARD_SIZE", "")
+	t.Setenv("VALIDATION_CACHE_DIR", "/var/cache/qtest")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Validation.Shards != 4 {
+		t.Errorf("Validation.Shards = %d, want 4", cfg.Validation.Shards)
+	}
+	if cfg.Validation.MinShardSize != 10 {
+		t.Errorf("Validation.MinShardSize = %d, want 10", cfg.Validation.MinShardSize)
+	}
+	if cfg.Validation.CacheDir != "/var/cache/qtest" {
+		t.Errorf("Validation.CacheDir = %s, want /var/cache/qtest", cfg.Validation.CacheDir)
+	}
+}
+
+func TestLoad_UntestableConfig(t *testing.T) {
+	t.Setenv("UNTESTABLE_THRESHOLD", "5")
+	t.Setenv("UNTESTABLE_CREATE_ISSUES", "true")
+	t.Setenv("UNTESTABLE_ISSUE_LABEL", "")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Untestable.Threshold != 5 {
+		t.Errorf("Untestable.Threshold = %d, want 5", cfg.Untestable.Threshold)
+	}
+	if !cfg.Untestable.CreateIssues {
+		t.Error("Untestable.CreateIssues = false, want true")
+	}
+	if cfg.Untestable.IssueLabel != "qtest:untestable" {
+		t.Errorf("Untestable.IssueLabel = %s, want qtest:untestable", cfg.Untestable.IssueLabel)
+	}
+}
+
+func TestLoad_AuthRequiredWithSignIn(t *testing.T) {
+	t.Setenv("AUTH_REQUIRED", "false")
+	t.Setenv("GITHUB_OAUTH_CLIENT_ID", "")
+	t.Setenv("OIDC_ISSUER", "")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Auth.Required {
+		t.Error("Auth.Required = true without a way to sign in")
+	}
+
+	t.Setenv("OIDC_ISSUER", "https://idp.example.com")
+	if cfg, err = Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !cfg.Auth.Required {
+		t.Error("Auth.Required = false with an OIDC issuer")
+	}
+}
+
+func TestLoad_LanesConfig(t *testing.T) {
+	t.Setenv("LANE_INTERACTIVE_STREAK", "")
+	t.Setenv("LLM_MAX_CONCURRENCY", "4")
+	t.Setenv("LLM_INTERACTIVE_RESERVE", "2")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Lanes.InteractiveStreak != 5 {
+		t.Errorf("Lanes.InteractiveStreak = %d, want 5", cfg.Lanes.InteractiveStreak)
+	}
+	if cfg.Lanes.LLMConcurrency != 4 {
+		t.Errorf("Lanes.LLMConcurrency = %d, want 4", cfg.Lanes.LLMConcurrency)
+	}
+	if cfg.Lanes.LLMInteractiveReserve != 2 {
+		t.Errorf("Lanes.LLMInteractiveReserve = %d, want 2", cfg.Lanes.LLMInteractiveReserve)
+	}
+}
+
+func TestLoad_OllamaWarmConfig(t *testing.T) {
+	t.Setenv("OLLAMA_KEEP_ALIVE", "-1")
+	t.Setenv("OLLAMA_PRELOAD", "false")
+	t.Setenv("OLLAMA_PING_INTERVAL", "5m")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.LLM.OllamaKeepAlive != "-1" {
+		t.Errorf("LLM.OllamaKeepAlive = %s, want -1", cfg.LLM.OllamaKeepAlive)
+	}
+	if cfg.LLM.OllamaPreload {
+		t.Error("LLM.OllamaPreload = true, want false")
+	}
+	if cfg.LLM.OllamaPingInterval != 5*time.Minute {
+		t.Errorf("LLM.OllamaPingInterval = %v, want 5m", cfg.LLM.OllamaPingInterval)
+	}
+}
+
+func TestLoad_TierContextConfig(t *testing.T) {
+	t.Setenv("LLM_TIER1_CONTEXT", "32768")
+	t.Setenv("LLM_SMALL_TARGET_TOKENS", "0")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.LLM.Tier1Context != 32768 {
+		t.Errorf("LLM.Tier1Context = %d, want 32768", cfg.LLM.Tier1Context)
+	}
+	if cfg.LLM.Tier2Context != 16384 {
+		t.Errorf("LLM.Tier2Context = %d, want 16384", cfg.LLM.Tier2Context)
+	}
+	if cfg.LLM.SmallTargetTokens != 0 {
+		t.Errorf("LLM.SmallTargetTokens = %d, want 0", cfg.LLM.SmallTargetTokens)
+	}
+}
@@ -0,0 +1,387 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/QTest-hq/qtest/internal/platform"
+	"gopkg.in/yaml.v3"
+)
+
+// activeProfileFile, in the config dir, names the profile in use
+const activeProfileFile = "active-profile"
+
+// Setting is a configuration key a profile can set. Settings with an
+// environment variable are read by Load, beneath the environment; the
+// others are repository settings, applied over .qtest.yaml by
+// LoadProjectConfig.
+type Setting struct {
+	Key         string // section.name, e.g. llm.tier2
+	Env         string
+	Kind        string // "", "int" or "bool"
+	Secret      bool   // masked when shown
+	Description string
+
+	value func(*Config, *ProjectConfig) string
+	apply func(*ProjectConfig, string)
+}
+
+// Settings are the keys qtest config get and set accept
+var Settings = []Setting{
+	{Key: "server.port", Env: "PORT", Kind: "int", Description: "API server port",
+		value: func(c *Config, _ *ProjectConfig) string { return strconv.Itoa(c.Port) }},
+	{Key: "server.env", Env: "ENV", Description: "Environment name",
+		value: func(c *Config, _ *ProjectConfig) string { return c.Env }},
+	{Key: "database.url", Env: "DATABASE_URL", Secret: true, Description: "PostgreSQL connection string",
+		value: func(c *Config, _ *ProjectConfig) string { return c.DatabaseURL }},
+	{Key: "nats.url", Env: "NATS_URL", Description: "NATS server URL",
+		value: func(c *Config, _ *ProjectConfig) string { return c.NATSURL }},
+	{Key: "redis.url", Env: "REDIS_URL", Description: "Redis URL",
+		value: func(c *Config, _ *ProjectConfig) string { return c.RedisURL }},
+	{Key: "github.token", Env: "GITHUB_TOKEN", Secret: true, Description: "GitHub token for private repos and PRs",
+		value: func(c *Config, _ *ProjectConfig) string { return c.GitHubToken }},
+	{Key: "llm.provider", Env: "LLM_DEFAULT_PROVIDER", Description: "Default LLM provider: ollama, anthropic or openai",
+		value: func(c *Config, _ *ProjectConfig) string { return c.LLM.DefaultProvider }},
+	{Key: "llm.ollama_url", Env: "OLLAMA_URL", Description: "Ollama server URL",
+		value: func(c *Config, _ *ProjectConfig) string { return c.LLM.OllamaURL }},
+	{Key: "llm.tier1", Env: "OLLAMA_TIER1_MODEL", Description: "Tier 1 (fast) Ollama model",
+		value: func(c *Config, _ *ProjectConfig) string { return c.LLM.OllamaTier1 }},
+	{Key: "llm.tier2", Env: "OLLAMA_TIER2_MODEL", Description: "Tier 2 (balanced) Ollama model",
+		value: func(c *Config, _ *ProjectConfig) string { return c.LLM.OllamaTier2 }},
+	{Key: "llm.tier3", Env: "ANTHROPIC_TIER3_MODEL", Description: "Tier 3 (thorough) Anthropic model",
+		value: func(c *Config, _ *ProjectConfig) string { return c.LLM.AnthropicTier3 }},
+	{Key: "llm.tier1_chain", Env: "LLM_TIER1_CHAIN", Description: "Tier 1 failover chain of provider:model steps",
+		value: func(c *Config, _ *ProjectConfig) string { return c.LLM.Tier1Chain }},
+	{Key: "llm.tier2_chain", Env: "LLM_TIER2_CHAIN", Description: "Tier 2 failover chain of provider:model steps",
+		value: func(c *Config, _ *ProjectConfig) string { return c.LLM.Tier2Chain }},
+	{Key: "llm.tier3_chain", Env: "LLM_TIER3_CHAIN", Description: "Tier 3 failover chain of provider:model steps",
+		value: func(c *Config, _ *ProjectConfig) string { return c.LLM.Tier3Chain }},
+	{Key: "llm.anthropic_key", Env: "ANTHROPIC_API_KEY", Secret: true, Description: "Anthropic API key",
+		value: func(c *Config, _ *ProjectConfig) string { return c.LLM.AnthropicKey }},
+	{Key: "llm.openai_key", Env: "OPENAI_API_KEY", Secret: true, Description: "OpenAI-compatible API key",
+		value: func(c *Config, _ *ProjectConfig) string { return c.LLM.OpenAIKey }},
+	{Key: "llm.openai_url", Env: "OPENAI_URL", Description: "OpenAI-compatible API base URL",
+		value: func(c *Config, _ *ProjectConfig) string { return c.LLM.OpenAIURL }},
+	{Key: "generation.repair_iterations", Env: "GENERATION_REPAIR_ITERATIONS", Kind: "int", Description: "Repair prompts per failing generated test",
+		value: func(c *Config, _ *ProjectConfig) string { return strconv.Itoa(c.RepairIterations) }},
+	{Key: "generation.static_checks", Env: "GENERATION_STATIC_CHECKS", Kind: "bool", Description: "Compile or type-check generated tests before keeping them",
+		value: func(c *Config, _ *ProjectConfig) string { return strconv.FormatBool(c.StaticChecks) }},
+	{Key: "generation.tier", Kind: "int", Description: "Default generation tier (1, 2 or 3)",
+		value: func(_ *Config, p *ProjectConfig) string { return strconv.Itoa(p.Generation.Tier) },
+		apply: func(p *ProjectConfig, v string) { p.Generation.Tier, _ = strconv.Atoi(v) }},
+	{Key: "generation.max_tests_per_function", Kind: "int", Description: "Maximum tests generated per function",
+		value: func(_ *Config, p *ProjectConfig) string { return strconv.Itoa(p.Generation.MaxTestsPerFunction) },
+		apply: func(p *ProjectConfig, v string) { p.Generation.MaxTestsPerFunction, _ = strconv.Atoi(v) }},
+	{Key: "generation.style", Description: "Test style: table-driven, standard or bdd",
+		value: func(_ *Config, p *ProjectConfig) string { return p.Generation.Style },
+		apply: func(p *ProjectConfig, v string) { p.Generation.Style = v }},
+}
+
+// LookupSetting returns the setting with key
+func LookupSetting(key string) (Setting, bool) {
+	for _, s := range Settings {
+		if s.Key == key {
+			return s, true
+		}
+	}
+	return Setting{}, false
+}
+
+// Check reports whether value is valid for the setting
+func (s Setting) Check(value string) error {
+	switch s.Kind {
+	case "int":
+		if _, err := strconv.Atoi(value); err != nil {
+			return fmt.Errorf("%s must be an integer, got %q", s.Key, value)
+		}
+	case "bool":
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("%s must be true or false, got %q", s.Key, value)
+		}
+	}
+	return nil
+}
+
+// Value returns the setting's value in a loaded configuration
+func (s Setting) Value(cfg *Config, project *ProjectConfig) string {
+	return s.value(cfg, project)
+}
+
+// Source returns where the setting's value comes from, with profile the
+// active one (or nil): the environment, the profile, .qtest.yaml or the
+// default. Command-line flags, which override all of them, aren't known here.
+func (s Setting) Source(profile *Profile, project *ProjectConfig) string {
+	if s.Env != "" && os.Getenv(s.Env) != "" {
+		return "env " + s.Env
+	}
+	if profile != nil {
+		if _, ok := profile.Values[s.Key]; ok {
+			return "profile " + profile.Name
+		}
+	}
+	if s.Env == "" && s.value(nil, project) != s.value(nil, DefaultProjectConfig()) {
+		return ".qtest.yaml"
+	}
+	return "default"
+}
+
+// Profile is a named set of settings, kept in
+// ~/.config/qtest/profiles/<name>.yaml. The active profile is chosen with
+// qtest config use-profile, or for one command with QTEST_PROFILE.
+type Profile struct {
+	Name   string
+	Values map[string]string // by setting key
+}
+
+// ConfigDir is qtest's user configuration directory: $QTEST_CONFIG_DIR,
+// else qtest under $XDG_CONFIG_HOME, %AppData% on Windows, or ~/.config
+func ConfigDir() (string, error) {
+	if dir := os.Getenv("QTEST_CONFIG_DIR"); dir != "" {
+		return dir, nil
+	}
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "qtest"), nil
+	}
+	if dir := os.Getenv("APPDATA"); dir != "" && platform.IsWindows() {
+		return filepath.Join(dir, "qtest"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find home directory: %w", err)
+	}
+	return filepath.Join(home, ".config", "qtest"), nil
+}
+
+// profilePath is the file of the named profile
+func profilePath(name string) (string, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) || strings.HasPrefix(name, ".") {
+		return "", fmt.Errorf("invalid profile name %q", name)
+	}
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "profiles", name+".yaml"), nil
+}
+
+// ActiveProfileName returns the profile in use: $QTEST_PROFILE, else the
+// one chosen with UseProfile, else "" for none
+func ActiveProfileName() (string, error) {
+	if name := os.Getenv("QTEST_PROFILE"); name != "" {
+		return name, nil
+	}
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", nil // no home directory, so no profiles
+	}
+	data, err := os.ReadFile(filepath.Join(dir, activeProfileFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read active profile: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// UseProfile makes the named profile the active one, which must exist; ""
+// stops using a profile
+func UseProfile(name string) error {
+	dir, err := ConfigDir()
+	if err != nil {
+		return err
+	}
+	file := filepath.Join(dir, activeProfileFile)
+	if name == "" {
+		if err := os.Remove(file); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to clear active profile: %w", err)
+		}
+		return nil
+	}
+
+	path, err := profilePath(name)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("profile %s not found: create it with qtest config set --profile %s KEY VALUE", name, name)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.WriteFile(file, []byte(name+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to set active profile: %w", err)
+	}
+	return nil
+}
+
+// ListProfiles returns the names of the saved profiles
+func ListProfiles() ([]string, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(filepath.Join(dir, "profiles"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list profiles: %w", err)
+	}
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ".yaml") {
+			names = append(names, strings.TrimSuffix(e.Name(), ".yaml"))
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// LoadProfile reads the named profile. A profile that doesn't exist yet is
+// empty, so settings can be added to it.
+func LoadProfile(name string) (*Profile, error) {
+	path, err := profilePath(name)
+	if err != nil {
+		return nil, err
+	}
+	profile := &Profile{Name: name, Values: make(map[string]string)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return profile, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read profile %s: %w", name, err)
+	}
+
+	// Sections of settings, as set keys are section.name
+	var sections map[string]map[string]string
+	if err := yaml.Unmarshal(data, &sections); err != nil {
+		return nil, fmt.Errorf("failed to parse profile %s: %w", name, err)
+	}
+	for section, values := range sections {
+		for key, value := range values {
+			full := section + "." + key
+			setting, ok := LookupSetting(full)
+			if !ok {
+				return nil, fmt.Errorf("profile %s: unknown setting %s", name, full)
+			}
+			if err := setting.Check(value); err != nil {
+				return nil, fmt.Errorf("profile %s: %w", name, err)
+			}
+			profile.Values[full] = value
+		}
+	}
+	return profile, nil
+}
+
+// LoadActiveProfile reads the active profile, or returns nil when none is
+// in use
+func LoadActiveProfile() (*Profile, error) {
+	name, err := ActiveProfileName()
+	if err != nil || name == "" {
+		return nil, err
+	}
+	path, err := profilePath(name)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("active profile %s not found", name)
+	}
+	return LoadProfile(name)
+}
+
+// Set sets a setting in the profile, checking its key and value
+func (p *Profile) Set(key, value string) error {
+	setting, ok := LookupSetting(key)
+	if !ok {
+		return fmt.Errorf("unknown setting %s", key)
+	}
+	if err := setting.Check(value); err != nil {
+		return err
+	}
+	p.Values[key] = value
+	return nil
+}
+
+// Save writes the profile to its file, readable only by its owner as it
+// may hold API keys
+func (p *Profile) Save() error {
+	path, err := profilePath(p.Name)
+	if err != nil {
+		return err
+	}
+	sections := make(map[string]map[string]string)
+	for key, value := range p.Values {
+		section, name, _ := strings.Cut(key, ".")
+		if sections[section] == nil {
+			sections[section] = make(map[string]string)
+		}
+		sections[section][name] = value
+	}
+	data, err := yaml.Marshal(sections)
+	if err != nil {
+		return fmt.Errorf("failed to encode profile: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create profiles directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write profile %s: %w", p.Name, err)
+	}
+	return nil
+}
+
+// profileEnv holds the active profile's settings by environment variable,
+// for getEnv to fall back to. Load sets it.
+var profileEnv struct {
+	sync.RWMutex
+	values map[string]string
+}
+
+// setProfileEnv makes a profile's environment settings the fallback for
+// getEnv; nil clears them
+func setProfileEnv(p *Profile) {
+	values := make(map[string]string)
+	if p != nil {
+		for key, value := range p.Values {
+			if setting, ok := LookupSetting(key); ok && setting.Env != "" {
+				values[setting.Env] = value
+			}
+		}
+	}
+	profileEnv.Lock()
+	profileEnv.values = values
+	profileEnv.Unlock()
+}
+
+// lookupEnv returns an environment variable, else the active profile's
+// value for it
+func lookupEnv(key string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	profileEnv.RLock()
+	defer profileEnv.RUnlock()
+	return profileEnv.values[key]
+}
+
+// applyProfile sets the repository settings of a profile on a project
+// config, over what .qtest.yaml had
+func applyProfile(cfg *ProjectConfig, p *Profile) {
+	if p == nil {
+		return
+	}
+	for key, value := range p.Values {
+		if setting, ok := LookupSetting(key); ok && setting.apply != nil {
+			setting.apply(cfg, value)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// useConfigDir points profiles at a temporary directory, with no profile
+// or settings from the environment
+func useConfigDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("QTEST_CONFIG_DIR", dir)
+	t.Setenv("QTEST_PROFILE", "")
+	t.Setenv("OLLAMA_TIER2_MODEL", "")
+	t.Cleanup(func() { setProfileEnv(nil) })
+	return dir
+}
+
+func TestProfile_SaveAndLoad(t *testing.T) {
+	useConfigDir(t)
+
+	p, err := LoadProfile("work")
+	if err != nil {
+		t.Fatalf("LoadProfile() error = %v", err)
+	}
+	if len(p.Values) != 0 {
+		t.Errorf("new profile values = %v, want none", p.Values)
+	}
+	if err := p.Set("llm.tier2", "deepseek-coder-v2:16b"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if err := p.Set("generation.tier", "3"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if err := p.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := LoadProfile("work")
+	if err != nil {
+		t.Fatalf("LoadProfile() error = %v", err)
+	}
+	if loaded.Values["llm.tier2"] != "deepseek-coder-v2:16b" || loaded.Values["generation.tier"] != "3" {
+		t.Errorf("loaded values = %v", loaded.Values)
+	}
+
+	names, err := ListProfiles()
+	if err != nil || len(names) != 1 || names[0] != "work" {
+		t.Errorf("ListProfiles() = %v, %v, want [work]", names, err)
+	}
+}
+
+func TestProfile_SetRejectsBadSettings(t *testing.T) {
+	p := &Profile{Name: "work", Values: make(map[string]string)}
+
+	if err := p.Set("llm.nope", "x"); err == nil {
+		t.Error("Set() of an unknown key succeeded")
+	}
+	if err := p.Set("server.port", "abc"); err == nil {
+		t.Error("Set() of a non-integer port succeeded")
+	}
+	if err := p.Set("generation.static_checks", "maybe"); err == nil {
+		t.Error("Set() of a non-boolean succeeded")
+	}
+	if len(p.Values) != 0 {
+		t.Errorf("values = %v, want none", p.Values)
+	}
+}
+
+func TestLoadProfile_InvalidName(t *testing.T) {
+	useConfigDir(t)
+
+	for _, name := range []string{"", "../work", ".hidden"} {
+		if _, err := LoadProfile(name); err == nil {
+			t.Errorf("LoadProfile(%q) succeeded", name)
+		}
+	}
+}
+
+func TestUseProfile(t *testing.T) {
+	useConfigDir(t)
+
+	if err := UseProfile("work"); err == nil {
+		t.Error("UseProfile() of a missing profile succeeded")
+	}
+
+	p := &Profile{Name: "work", Values: map[string]string{"llm.tier2": "big"}}
+	if err := p.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if err := UseProfile("work"); err != nil {
+		t.Fatalf("UseProfile() error = %v", err)
+	}
+	if name, _ := ActiveProfileName(); name != "work" {
+		t.Errorf("ActiveProfileName() = %q, want work", name)
+	}
+
+	// QTEST_PROFILE overrides the active profile for one command
+	t.Setenv("QTEST_PROFILE", "other")
+	if name, _ := ActiveProfileName(); name != "other" {
+		t.Errorf("ActiveProfileName() = %q, want other", name)
+	}
+	t.Setenv("QTEST_PROFILE", "")
+
+	if err := UseProfile(""); err != nil {
+		t.Fatalf("UseProfile(\"\") error = %v", err)
+	}
+	if name, _ := ActiveProfileName(); name != "" {
+		t.Errorf("ActiveProfileName() = %q after clearing, want none", name)
+	}
+}
+
+func TestLoad_ProfilePrecedence(t *testing.T) {
+	useConfigDir(t)
+
+	p := &Profile{Name: "work", Values: map[string]string{
+		"llm.tier1": "profile-small",
+		"llm.tier2": "profile-big",
+	}}
+	if err := p.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if err := UseProfile("work"); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("OLLAMA_TIER1_MODEL", "env-small")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	// The environment beats the profile, which beats the default
+	if cfg.LLM.OllamaTier1 != "env-small" {
+		t.Errorf("OllamaTier1 = %s, want env-small", cfg.LLM.OllamaTier1)
+	}
+	if cfg.LLM.OllamaTier2 != "profile-big" {
+		t.Errorf("OllamaTier2 = %s, want profile-big", cfg.LLM.OllamaTier2)
+	}
+
+	setting, _ := LookupSetting("llm.tier1")
+	if src := setting.Source(p, nil); src != "env OLLAMA_TIER1_MODEL" {
+		t.Errorf("Source(llm.tier1) = %s", src)
+	}
+	setting, _ = LookupSetting("llm.tier2")
+	if src := setting.Source(p, nil); src != "profile work" {
+		t.Errorf("Source(llm.tier2) = %s", src)
+	}
+}
+
+func TestLoad_MissingActiveProfile(t *testing.T) {
+	useConfigDir(t)
+	t.Setenv("QTEST_PROFILE", "gone")
+
+	if _, err := Load(); err == nil {
+		t.Error("Load() with a missing active profile succeeded")
+	}
+}
+
+func TestLoadProjectConfig_ProfileOverRepoFile(t *testing.T) {
+	useConfigDir(t)
+
+	repo := t.TempDir()
+	yaml := "generation:\n  tier: 1\n  style: bdd\n"
+	if err := os.WriteFile(filepath.Join(repo, ".qtest.yaml"), []byte(yaml), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	p := &Profile{Name: "work", Values: map[string]string{"generation.tier": "3"}}
+	if err := p.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if err := UseProfile("work"); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadProjectConfig(repo)
+	if err != nil {
+		t.Fatalf("LoadProjectConfig() error = %v", err)
+	}
+	if cfg.Generation.Tier != 3 {
+		t.Errorf("Generation.Tier = %d, want the profile's 3", cfg.Generation.Tier)
+	}
+	if cfg.Generation.Style != "bdd" {
+		t.Errorf("Generation.Style = %s, want .qtest.yaml's bdd", cfg.Generation.Style)
+	}
+
+	tier, _ := LookupSetting("generation.tier")
+	style, _ := LookupSetting("generation.style")
+	if src := tier.Source(p, cfg); src != "profile work" {
+		t.Errorf("Source(generation.tier) = %s", src)
+	}
+	if src := style.Source(p, cfg); src != ".qtest.yaml" {
+		t.Errorf("Source(generation.style) = %s", src)
+	}
+}
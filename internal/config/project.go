@@ -1,8 +1,11 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 )
@@ -26,6 +29,33 @@ type ProjectConfig struct {
 
 	// Coverage settings
 	Coverage CoverageConfig `yaml:"coverage,omitempty"`
+
+	// Mutation testing settings
+	Mutation MutationConfig `yaml:"mutation,omitempty"`
+
+	// Response time budgets for generated API tests
+	Latency LatencyConfig `yaml:"latency,omitempty"`
+
+	// Test tagging settings
+	Tags TagsConfig `yaml:"tags,omitempty"`
+
+	// Test data generation settings
+	Datagen DatagenConfig `yaml:"datagen,omitempty"`
+
+	// Custom framework supplements
+	Supplements []SupplementConfig `yaml:"supplements,omitempty"`
+
+	// Supplements not to run, by name, e.g. a misdetected framework
+	DisableSupplements []string `yaml:"disable_supplements,omitempty"`
+
+	// Runtime route discovery settings
+	Discovery DiscoveryConfig `yaml:"discovery,omitempty"`
+
+	// OpenAPI or Swagger spec merged into the model, relative to the repo
+	OpenAPI string `yaml:"openapi,omitempty"`
+
+	// Pull request settings
+	PR PRConfig `yaml:"pr,omitempty"`
 }
 
 // GenerationConfig holds test generation preferences
@@ -56,6 +86,10 @@ type FrameworkConfig struct {
 
 	// Custom test directory
 	TestDir string `yaml:"test_dir,omitempty"`
+
+	// Assertion library per language (go: require, javascript: chai,
+	// python: assertpy); unset languages are detected from existing tests
+	Assertions map[string]string `yaml:"assertions,omitempty"`
 }
 
 // CoverageConfig holds coverage settings
@@ -67,6 +101,169 @@ type CoverageConfig struct {
 	Exclude []string `yaml:"exclude,omitempty"`
 }
 
+// MutationConfig holds mutation testing settings
+type MutationConfig struct {
+	// Minimum mutation score (0-100); 0 doesn't check it
+	Threshold float64 `yaml:"threshold,omitempty"`
+}
+
+// LatencyConfig sets the response time budgets generated API tests check
+type LatencyConfig struct {
+	// Default budgets in milliseconds; 0 doesn't check that percentile
+	P50 float64 `yaml:"p50,omitempty"`
+	P95 float64 `yaml:"p95,omitempty"`
+
+	// Requests timed per endpoint (default 20)
+	Samples int `yaml:"samples,omitempty"`
+
+	// Budgets for particular endpoints, keyed by "METHOD /path" as in
+	// "GET /reports"; unset fields fall back to the defaults
+	Endpoints map[string]LatencyBudgetConfig `yaml:"endpoints,omitempty"`
+}
+
+// LatencyBudgetConfig is one endpoint's response time budget
+type LatencyBudgetConfig struct {
+	P50     float64 `yaml:"p50,omitempty"`
+	P95     float64 `yaml:"p95,omitempty"`
+	Samples int     `yaml:"samples,omitempty"`
+}
+
+// Budget returns the budget for an endpoint, and false when neither it nor
+// the defaults set one
+func (c LatencyConfig) Budget(method, path string) (LatencyBudgetConfig, bool) {
+	budget := LatencyBudgetConfig{P50: c.P50, P95: c.P95, Samples: c.Samples}
+	if override, ok := c.Endpoints[strings.ToUpper(method)+" "+path]; ok {
+		if override.P50 != 0 {
+			budget.P50 = override.P50
+		}
+		if override.P95 != 0 {
+			budget.P95 = override.P95
+		}
+		if override.Samples != 0 {
+			budget.Samples = override.Samples
+		}
+	}
+	return budget, budget.P50 > 0 || budget.P95 > 0
+}
+
+// TagsConfig controls the categories emitted into generated tests
+// (Go build tags, pytest markers, Jest @tags)
+type TagsConfig struct {
+	// Whether to emit tags at all
+	Enabled bool `yaml:"enabled,omitempty"`
+
+	// Tags added to every generated test, in addition to its level (unit/api/e2e)
+	Default []string `yaml:"default,omitempty"`
+}
+
+// DatagenConfig holds test data generation settings
+type DatagenConfig struct {
+	// Seed for reproducible fixtures (0 = random each run)
+	Seed int64 `yaml:"seed,omitempty"`
+
+	// Locale for names, addresses and phone formats (e.g. en, de_DE, ja_JP)
+	Locale string `yaml:"locale,omitempty"`
+}
+
+// DiscoveryConfig describes how to boot the service for runtime route
+// discovery (qtest analyze --discover)
+type DiscoveryConfig struct {
+	// Container image with the service's toolchain, e.g. node:20
+	Image string `yaml:"image,omitempty"`
+
+	// Command that starts the service, e.g. "npm start". It runs without
+	// network access, so dependencies must already be in the repository or
+	// image
+	Command string `yaml:"command,omitempty"`
+
+	// Port the service listens on inside the container
+	Port int `yaml:"port,omitempty"`
+
+	// Extra environment variables, e.g. a throwaway DATABASE_URL
+	Env map[string]string `yaml:"env,omitempty"`
+
+	// Seconds to wait for the service to answer (default 60)
+	StartupTimeout int `yaml:"startup_timeout,omitempty"`
+}
+
+// PRConfig holds settings for the pull requests QTest opens
+type PRConfig struct {
+	// Go template for the PR description, executed with the PR's details
+	// and the run's metrics; it replaces the organization's template
+	BodyTemplate string `yaml:"body_template,omitempty"`
+
+	// QualityGate publishes a "QTest Quality Gate" check run on the PR's
+	// commit, failing when coverage or the mutation score is below its
+	// threshold, so branch protection can require it. Creating check runs
+	// needs a GitHub App token.
+	QualityGate bool `yaml:"quality_gate,omitempty"`
+}
+
+// SupplementConfig declares a framework supplement without Go code, for
+// internal frameworks and custom routers
+type SupplementConfig struct {
+	// Framework name, shown in analyze output and on endpoints
+	Name string `yaml:"name"`
+
+	// Globs of the files to read, e.g. "**/*.go" or "routes/*.ts"
+	Files []string `yaml:"files"`
+
+	// Regexes that show the framework is used; a match in any file enables
+	// the supplement. Without any, it runs whenever Files match.
+	Detect []string `yaml:"detect,omitempty"`
+
+	// Rules for finding routes
+	Routes []RouteRule `yaml:"routes"`
+}
+
+// RouteRule finds route definitions with a regex matched against each line.
+// The named groups "path" (required), "method" and "handler" pick out the
+// parts of the route.
+type RouteRule struct {
+	Pattern string `yaml:"pattern"`
+
+	// HTTP method when the pattern has no "method" group (default GET)
+	Method string `yaml:"method,omitempty"`
+
+	// Maps captured method text to HTTP methods, e.g. {Fetch: GET}. Unmapped
+	// text is upper-cased.
+	Methods map[string]string `yaml:"methods,omitempty"`
+}
+
+// Validate checks that a supplement's rules are complete and its regexes
+// compile
+func (s *SupplementConfig) Validate() error {
+	if s.Name == "" {
+		return fmt.Errorf("supplement name is required")
+	}
+	if len(s.Files) == 0 {
+		return fmt.Errorf("supplement %s: files is required", s.Name)
+	}
+	for _, glob := range s.Files {
+		if _, err := filepath.Match(glob, ""); err != nil {
+			return fmt.Errorf("supplement %s: invalid files glob %q: %w", s.Name, glob, err)
+		}
+	}
+	for _, pattern := range s.Detect {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("supplement %s: invalid detect pattern: %w", s.Name, err)
+		}
+	}
+	if len(s.Routes) == 0 {
+		return fmt.Errorf("supplement %s: at least one route rule is required", s.Name)
+	}
+	for i, route := range s.Routes {
+		re, err := regexp.Compile(route.Pattern)
+		if err != nil {
+			return fmt.Errorf("supplement %s: route %d: invalid pattern: %w", s.Name, i+1, err)
+		}
+		if re.SubexpIndex("path") < 0 {
+			return fmt.Errorf("supplement %s: route %d: pattern needs a (?P<path>...) group", s.Name, i+1)
+		}
+	}
+	return nil
+}
+
 // DefaultProjectConfig returns sensible defaults
 func DefaultProjectConfig() *ProjectConfig {
 	return &ProjectConfig{
@@ -90,11 +287,23 @@ func DefaultProjectConfig() *ProjectConfig {
 		Coverage: CoverageConfig{
 			Threshold: 80.0,
 		},
+		Mutation: MutationConfig{
+			Threshold: 50.0,
+		},
+		Tags: TagsConfig{
+			Default: []string{"generated"},
+		},
 	}
 }
 
-// LoadProjectConfig loads a .qtest.yaml from the given directory
+// LoadProjectConfig loads a .qtest.yaml from the given directory, with the
+// active profile's repository settings applied over it
 func LoadProjectConfig(repoPath string) (*ProjectConfig, error) {
+	profile, err := LoadActiveProfile()
+	if err != nil {
+		return nil, err
+	}
+
 	configPath := filepath.Join(repoPath, ".qtest.yaml")
 
 	// Check if config exists
@@ -102,7 +311,9 @@ func LoadProjectConfig(repoPath string) (*ProjectConfig, error) {
 		// Also try .qtest.yml
 		configPath = filepath.Join(repoPath, ".qtest.yml")
 		if _, err := os.Stat(configPath); os.IsNotExist(err) {
-			return DefaultProjectConfig(), nil
+			cfg := DefaultProjectConfig()
+			applyProfile(cfg, profile)
+			return cfg, nil
 		}
 	}
 
@@ -116,6 +327,13 @@ func LoadProjectConfig(repoPath string) (*ProjectConfig, error) {
 		return nil, err
 	}
 
+	for i := range cfg.Supplements {
+		if err := cfg.Supplements[i].Validate(); err != nil {
+			return nil, fmt.Errorf("%s: %w", filepath.Base(configPath), err)
+		}
+	}
+	applyProfile(cfg, profile)
+
 	return cfg, nil
 }
 
@@ -169,7 +387,65 @@ func (c *ProjectConfig) Merge(other *ProjectConfig) {
 		c.Framework.TestDir = other.Framework.TestDir
 	}
 
+	for language, lib := range other.Framework.Assertions {
+		if c.Framework.Assertions == nil {
+			c.Framework.Assertions = make(map[string]string)
+		}
+		c.Framework.Assertions[language] = lib
+	}
+
 	if other.Coverage.Threshold != 0 {
 		c.Coverage.Threshold = other.Coverage.Threshold
 	}
+
+	if other.Mutation.Threshold != 0 {
+		c.Mutation.Threshold = other.Mutation.Threshold
+	}
+
+	if other.Latency.P50 != 0 {
+		c.Latency.P50 = other.Latency.P50
+	}
+
+	if other.Latency.P95 != 0 {
+		c.Latency.P95 = other.Latency.P95
+	}
+
+	if other.Latency.Samples != 0 {
+		c.Latency.Samples = other.Latency.Samples
+	}
+
+	for endpoint, budget := range other.Latency.Endpoints {
+		if c.Latency.Endpoints == nil {
+			c.Latency.Endpoints = make(map[string]LatencyBudgetConfig)
+		}
+		c.Latency.Endpoints[endpoint] = budget
+	}
+
+	if other.Tags.Enabled {
+		c.Tags.Enabled = true
+	}
+
+	if len(other.Tags.Default) > 0 {
+		c.Tags.Default = other.Tags.Default
+	}
+
+	if other.OpenAPI != "" {
+		c.OpenAPI = other.OpenAPI
+	}
+
+	if other.Datagen.Seed != 0 {
+		c.Datagen.Seed = other.Datagen.Seed
+	}
+
+	if other.Datagen.Locale != "" {
+		c.Datagen.Locale = other.Datagen.Locale
+	}
+
+	if other.PR.BodyTemplate != "" {
+		c.PR.BodyTemplate = other.PR.BodyTemplate
+	}
+
+	if other.PR.QualityGate {
+		c.PR.QualityGate = true
+	}
 }
@@ -249,6 +249,12 @@ include:
   - "src/**/*.ts"
 coverage:
   threshold: 85.0
+mutation:
+  threshold: 70
+pr:
+  body_template: |
+    Adds {{.TestCount}} tests
+  quality_gate: true
 `
 
 	if err := os.WriteFile(configPath, []byte(yamlContent), 0644); err != nil {
@@ -275,6 +281,12 @@ coverage:
 	if cfg.Coverage.Threshold != 85.0 {
 		t.Errorf("Coverage.Threshold = %f, want 85.0", cfg.Coverage.Threshold)
 	}
+	if cfg.PR.BodyTemplate != "Adds {{.TestCount}} tests\n" {
+		t.Errorf("PR.BodyTemplate = %q", cfg.PR.BodyTemplate)
+	}
+	if cfg.Mutation.Threshold != 70 || !cfg.PR.QualityGate {
+		t.Errorf("Mutation.Threshold = %v, PR.QualityGate = %v, want 70, true", cfg.Mutation.Threshold, cfg.PR.QualityGate)
+	}
 }
 
 func TestLoadProjectConfig_YmlFile(t *testing.T) {
@@ -406,3 +418,215 @@ func TestCoverageConfig_Defaults(t *testing.T) {
 		t.Error("default Exclude should be nil")
 	}
 }
+
+func TestProjectConfig_Merge_Tags(t *testing.T) {
+	base := DefaultProjectConfig()
+	if base.Tags.Enabled {
+		t.Error("tags should be disabled by default")
+	}
+
+	base.Merge(&ProjectConfig{Tags: TagsConfig{Enabled: true, Default: []string{"generated", "nightly"}}})
+
+	if !base.Tags.Enabled {
+		t.Error("Tags.Enabled should be true after merge")
+	}
+	if len(base.Tags.Default) != 2 || base.Tags.Default[1] != "nightly" {
+		t.Errorf("Tags.Default = %v, want [generated nightly]", base.Tags.Default)
+	}
+}
+
+func TestProjectConfig_Merge_DatagenSeed(t *testing.T) {
+	base := DefaultProjectConfig()
+	if base.Datagen.Seed != 0 {
+		t.Error("datagen seed should be unset by default")
+	}
+
+	base.Merge(&ProjectConfig{Datagen: DatagenConfig{Seed: 1234}})
+
+	if base.Datagen.Seed != 1234 {
+		t.Errorf("Datagen.Seed = %d, want 1234", base.Datagen.Seed)
+	}
+}
+
+func TestProjectConfig_Merge_DatagenLocale(t *testing.T) {
+	base := DefaultProjectConfig()
+	base.Merge(&ProjectConfig{Datagen: DatagenConfig{Locale: "de_DE"}})
+
+	if base.Datagen.Locale != "de_DE" {
+		t.Errorf("Datagen.Locale = %q, want de_DE", base.Datagen.Locale)
+	}
+}
+
+func TestProjectConfig_Merge_Assertions(t *testing.T) {
+	base := DefaultProjectConfig()
+	base.Merge(&ProjectConfig{Framework: FrameworkConfig{Assertions: map[string]string{"go": "require"}}})
+	base.Merge(&ProjectConfig{Framework: FrameworkConfig{Assertions: map[string]string{"python": "assertpy"}}})
+
+	if base.Framework.Assertions["go"] != "require" || base.Framework.Assertions["python"] != "assertpy" {
+		t.Errorf("Framework.Assertions = %v, want go: require and python: assertpy", base.Framework.Assertions)
+	}
+}
+
+func TestProjectConfig_Merge_OpenAPI(t *testing.T) {
+	base := DefaultProjectConfig()
+	base.Merge(&ProjectConfig{OpenAPI: "api/openapi.yaml"})
+
+	if base.OpenAPI != "api/openapi.yaml" {
+		t.Errorf("OpenAPI = %q, want api/openapi.yaml", base.OpenAPI)
+	}
+}
+
+func TestLoadProjectConfig_Supplements(t *testing.T) {
+	tmpDir := t.TempDir()
+	yamlContent := `
+supplements:
+  - name: acme-router
+    files: ["**/*.go"]
+    detect: ['"acme.io/router"']
+    routes:
+      - pattern: 'r\.(?P<method>Fetch|Store)\("(?P<path>[^"]+)",\s*(?P<handler>\w+)'
+        methods:
+          Fetch: GET
+          Store: POST
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, ".qtest.yaml"), []byte(yamlContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadProjectConfig(tmpDir)
+	if err != nil {
+		t.Fatalf("LoadProjectConfig() error = %v", err)
+	}
+	if len(cfg.Supplements) != 1 {
+		t.Fatalf("len(Supplements) = %d, want 1", len(cfg.Supplements))
+	}
+	s := cfg.Supplements[0]
+	if s.Name != "acme-router" || len(s.Routes) != 1 || s.Routes[0].Methods["Store"] != "POST" {
+		t.Errorf("Supplements[0] = %+v", s)
+	}
+}
+
+func TestSupplementConfig_Validate(t *testing.T) {
+	valid := func() SupplementConfig {
+		return SupplementConfig{
+			Name:   "custom",
+			Files:  []string{"*.go"},
+			Routes: []RouteRule{{Pattern: `route\("(?P<path>[^"]+)"`}},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(*SupplementConfig)
+		wantErr bool
+	}{
+		{"valid", func(s *SupplementConfig) {}, false},
+		{"no name", func(s *SupplementConfig) { s.Name = "" }, true},
+		{"no files", func(s *SupplementConfig) { s.Files = nil }, true},
+		{"bad glob", func(s *SupplementConfig) { s.Files = []string{"[a"} }, true},
+		{"bad detect", func(s *SupplementConfig) { s.Detect = []string{"("} }, true},
+		{"no routes", func(s *SupplementConfig) { s.Routes = nil }, true},
+		{"bad route", func(s *SupplementConfig) { s.Routes[0].Pattern = "(" }, true},
+		{"no path group", func(s *SupplementConfig) { s.Routes[0].Pattern = `route\("[^"]+"` }, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := valid()
+			tt.modify(&s)
+			if err := s.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadProjectConfig_InvalidSupplement(t *testing.T) {
+	tmpDir := t.TempDir()
+	yamlContent := "supplements:\n  - name: broken\n    files: [\"*.go\"]\n"
+	if err := os.WriteFile(filepath.Join(tmpDir, ".qtest.yaml"), []byte(yamlContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadProjectConfig(tmpDir); err == nil {
+		t.Error("expected error for a supplement without routes")
+	}
+}
+
+func TestLoadProjectConfig_Discovery(t *testing.T) {
+	tmpDir := t.TempDir()
+	yamlContent := `
+discovery:
+  image: node:20
+  command: npm ci && npm start
+  port: 3000
+  env:
+    DATABASE_URL: postgres://localhost/test
+  startup_timeout: 120
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, ".qtest.yaml"), []byte(yamlContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadProjectConfig(tmpDir)
+	if err != nil {
+		t.Fatalf("LoadProjectConfig() error = %v", err)
+	}
+	d := cfg.Discovery
+	if d.Image != "node:20" || d.Command != "npm ci && npm start" || d.Port != 3000 || d.StartupTimeout != 120 {
+		t.Errorf("Discovery = %+v", d)
+	}
+	if d.Env["DATABASE_URL"] != "postgres://localhost/test" {
+		t.Errorf("Discovery.Env = %v", d.Env)
+	}
+}
+
+func TestLoadProjectConfig_Assertions(t *testing.T) {
+	tmpDir := t.TempDir()
+	yamlContent := `
+framework:
+  assertions:
+    go: testify
+    javascript: chai
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, ".qtest.yaml"), []byte(yamlContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadProjectConfig(tmpDir)
+	if err != nil {
+		t.Fatalf("LoadProjectConfig() error = %v", err)
+	}
+	if cfg.Framework.Assertions["go"] != "testify" || cfg.Framework.Assertions["javascript"] != "chai" {
+		t.Errorf("Framework.Assertions = %v", cfg.Framework.Assertions)
+	}
+}
+
+func TestLoadProjectConfig_Latency(t *testing.T) {
+	tmpDir := t.TempDir()
+	yamlContent := `
+latency:
+  p95: 300
+  samples: 10
+  endpoints:
+    "GET /reports":
+      p50: 800
+      p95: 2000
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, ".qtest.yaml"), []byte(yamlContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadProjectConfig(tmpDir)
+	if err != nil {
+		t.Fatalf("LoadProjectConfig() error = %v", err)
+	}
+	if budget, ok := cfg.Latency.Budget("get", "/users"); !ok || budget != (LatencyBudgetConfig{P95: 300, Samples: 10}) {
+		t.Errorf("Budget(GET /users) = %+v, %v", budget, ok)
+	}
+	if budget, ok := cfg.Latency.Budget("GET", "/reports"); !ok || budget != (LatencyBudgetConfig{P50: 800, P95: 2000, Samples: 10}) {
+		t.Errorf("Budget(GET /reports) = %+v, %v", budget, ok)
+	}
+	if _, ok := (LatencyConfig{}).Budget("GET", "/users"); ok {
+		t.Error("Budget() without budgets should report none")
+	}
+}
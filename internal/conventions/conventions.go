@@ -0,0 +1,384 @@
+// Package conventions summarises how a repository's existing tests are
+// written: the assertion libraries they use, their shared fixtures and
+// helpers, how tests are named and the base URLs they call. The summary is
+// built once per run and prepended to every generation prompt, so tests for
+// hundreds of files follow the same conventions without each prompt
+// rediscovering them.
+package conventions
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	// maxTestFiles bounds how many test files are read in large repositories
+	maxTestFiles = 500
+
+	// maxItems bounds each list in the summary
+	maxItems = 5
+)
+
+// Report is the testing conventions found in a repository
+type Report struct {
+	TestFiles  int      `json:"test_files"`
+	Assertions []string `json:"assertions,omitempty"` // assertion libraries, most used first
+	Fixtures   []string `json:"fixtures,omitempty"`   // shared fixtures and test helpers
+	Naming     []string `json:"naming,omitempty"`     // test naming and structure patterns
+	BaseURLs   []string `json:"base_urls,omitempty"`  // base URLs and the variables holding them
+}
+
+// counter tallies how many test files something appears in
+type counter map[string]int
+
+func (c counter) top(n int) []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if c[keys[i]] != c[keys[j]] {
+			return c[keys[i]] > c[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	if len(keys) > n {
+		keys = keys[:n]
+	}
+	return keys
+}
+
+var (
+	goTestFunc    = regexp.MustCompile(`(?m)^func (Test\w+)\(\w+ \*testing\.T\)`)
+	goHelperFunc  = regexp.MustCompile(`(?m)^func ([a-z]\w*)\([^)]*\*testing\.(?:T|TB|B)\b`)
+	goTableDriven = regexp.MustCompile(`for _, (?:tt|tc|test) := range`)
+
+	pyTestFunc  = regexp.MustCompile(`(?m)^\s*(?:async )?def (test_\w+)\(`)
+	pyTestClass = regexp.MustCompile(`(?m)^class (Test\w+)`)
+	pyFixture   = regexp.MustCompile(`(?m)^@pytest\.fixture[^\n]*\n(?:@[^\n]*\n)*(?:async )?def (\w+)\(`)
+	pyAssert    = regexp.MustCompile(`(?m)^\s+assert\s`)
+
+	jsDescribe  = regexp.MustCompile(`\bdescribe\(`)
+	jsIt        = regexp.MustCompile(`\bit\(`)
+	jsTest      = regexp.MustCompile(`\btest\(`)
+	jsHelper    = regexp.MustCompile(`(?m)^(?:export )?(?:async )?function (\w+)\(|^(?:export )?const (\w+) = (?:async )?(?:\([^)]*\)|\w+) =>`)
+	jsSetupHook = regexp.MustCompile(`\b(beforeEach|beforeAll)\(`)
+	jsTestName  = regexp.MustCompile(`\b(?:it|test)\(\s*['"\x60]([^'"\x60]+)`)
+
+	callPattern = regexp.MustCompile(`\b(\w+)\(`)
+
+	urlLiteral = regexp.MustCompile(`https?://[A-Za-z0-9.\-]+(?::\d+)?`)
+	urlEnvVar  = regexp.MustCompile(`(?:os\.Getenv\(|process\.env\.|os\.environ(?:\.get)?[\[(]|os\.getenv\()["']?(\w*(?:URL|HOST|ENDPOINT|ADDR)\w*)`)
+)
+
+// testFile is a test file read for detection
+type testFile struct {
+	path    string
+	lang    string
+	content string
+}
+
+// Detect reads the test files under root and reports their conventions
+func Detect(root string) *Report {
+	files := findTestFiles(root)
+
+	assertions := counter{}
+	fixtures := counter{}
+	naming := counter{}
+	examples := make(map[string]string)
+	urls := counter{}
+
+	// Helpers count when they're shared, so references are tallied across
+	// files after every file's definitions are known
+	helpers := make(map[string]string) // name -> language
+	for _, f := range files {
+		detectAssertions(f, assertions)
+		detectNaming(f, naming, examples)
+		detectBaseURLs(f, urls)
+
+		switch f.lang {
+		case "go":
+			for _, m := range goHelperFunc.FindAllStringSubmatch(f.content, -1) {
+				helpers[m[1]] = f.lang
+			}
+		case "python":
+			for _, m := range pyFixture.FindAllStringSubmatch(f.content, -1) {
+				fixtures[m[1]+" (pytest fixture)"]++
+			}
+		case "javascript":
+			for _, m := range jsHelper.FindAllStringSubmatch(f.content, -1) {
+				if name := m[1] + m[2]; name != "" {
+					helpers[name] = f.lang
+				}
+			}
+			if m := jsSetupHook.FindStringSubmatch(f.content); m != nil {
+				fixtures[m[1]+" setup"]++
+			}
+		}
+	}
+	calls := make(map[string]counter) // language -> files calling each name
+	for _, f := range files {
+		if calls[f.lang] == nil {
+			calls[f.lang] = counter{}
+		}
+		seen := make(map[string]bool)
+		for _, m := range callPattern.FindAllStringSubmatch(f.content, -1) {
+			if !seen[m[1]] {
+				seen[m[1]] = true
+				calls[f.lang][m[1]]++
+			}
+		}
+	}
+	for name, lang := range helpers {
+		// JS helpers are only shared once a second file uses them
+		if uses := calls[lang][name]; lang == "go" || uses > 1 {
+			fixtures[name+"()"] = uses
+		}
+	}
+
+	return &Report{
+		TestFiles:  len(files),
+		Assertions: withCounts(assertions),
+		Fixtures:   fixtures.top(maxItems),
+		Naming:     withExamples(naming, examples),
+		BaseURLs:   urls.top(maxItems),
+	}
+}
+
+// withCounts lists the most used entries with the number of files using them
+func withCounts(c counter) []string {
+	var out []string
+	for _, k := range c.top(maxItems) {
+		files := "files"
+		if c[k] == 1 {
+			files = "file"
+		}
+		out = append(out, fmt.Sprintf("%s (%d %s)", k, c[k], files))
+	}
+	return out
+}
+
+// withExamples lists the most used naming patterns with an example of each
+func withExamples(c counter, examples map[string]string) []string {
+	var out []string
+	for _, k := range c.top(maxItems) {
+		if examples[k] != "" {
+			k += " (e.g. " + examples[k] + ")"
+		}
+		out = append(out, k)
+	}
+	return out
+}
+
+func detectAssertions(f testFile, c counter) {
+	has := func(s string) bool { return strings.Contains(f.content, s) }
+
+	switch f.lang {
+	case "go":
+		found := false
+		for lib, name := range map[string]string{
+			`"github.com/stretchr/testify/assert"`:  "testify/assert",
+			`"github.com/stretchr/testify/require"`: "testify/require",
+			`"github.com/onsi/gomega"`:              "gomega",
+			`"github.com/google/go-cmp/cmp"`:        "go-cmp",
+			`"gotest.tools/v3/assert"`:              "gotest.tools/assert",
+		} {
+			if has(lib) {
+				c[name]++
+				found = true
+			}
+		}
+		if !found && (has("t.Errorf(") || has("t.Fatalf(")) {
+			c["testing t.Errorf/t.Fatalf"]++
+		}
+
+	case "python":
+		if has("self.assert") {
+			c["unittest self.assert*"]++
+		}
+		if has("assert_that(") {
+			c["PyHamcrest assert_that"]++
+		}
+		if pyAssert.MatchString(f.content) {
+			c["pytest plain assert"]++
+		}
+
+	case "javascript":
+		switch {
+		case has("from 'chai'") || has(`from "chai"`) || has("require('chai')"):
+			c["chai expect"]++
+		case has("expect("):
+			if has("from 'vitest'") || has(`from "vitest"`) {
+				c["vitest expect"]++
+			} else {
+				c["jest expect"]++
+			}
+		}
+		if has("'node:assert'") || has("require('assert')") || has("from 'assert'") {
+			c["node:assert"]++
+		}
+	}
+}
+
+func detectNaming(f testFile, c counter, examples map[string]string) {
+	add := func(pattern, example string) {
+		c[pattern]++
+		if examples[pattern] == "" {
+			examples[pattern] = example
+		}
+	}
+
+	switch f.lang {
+	case "go":
+		if m := goTestFunc.FindStringSubmatch(f.content); m != nil {
+			if strings.Contains(m[1], "_") {
+				add("TestFunction_Scenario", m[1])
+			} else {
+				add("TestFunctionScenario", m[1])
+			}
+		}
+		if goTableDriven.MatchString(f.content) && strings.Contains(f.content, "t.Run(") {
+			add("table-driven tests with t.Run subtests", "")
+		}
+
+	case "python":
+		if m := pyTestClass.FindStringSubmatch(f.content); m != nil {
+			add("tests grouped in Test* classes", m[1])
+		} else if m := pyTestFunc.FindStringSubmatch(f.content); m != nil {
+			add("module-level test_<function>_<case> functions", m[1])
+		}
+		if strings.Contains(f.content, "@pytest.mark.parametrize") {
+			add("@pytest.mark.parametrize for cases", "")
+		}
+
+	case "javascript":
+		example := ""
+		if m := jsTestName.FindStringSubmatch(f.content); m != nil {
+			example = "'" + m[1] + "'"
+		}
+		switch {
+		case jsDescribe.MatchString(f.content) && jsIt.MatchString(f.content):
+			add("describe/it blocks", example)
+		case jsDescribe.MatchString(f.content):
+			add("describe/test blocks", example)
+		case jsTest.MatchString(f.content):
+			add("top-level test() calls", example)
+		}
+	}
+}
+
+func detectBaseURLs(f testFile, c counter) {
+	seen := make(map[string]bool)
+	for _, raw := range urlLiteral.FindAllString(f.content, -1) {
+		u, err := url.Parse(raw)
+		if err != nil || seen[raw] {
+			continue
+		}
+		// Skip fragments such as http://api that aren't a host
+		if host := u.Hostname(); host != "localhost" && !strings.Contains(host, ".") {
+			continue
+		}
+		seen[raw] = true
+		c[raw]++
+	}
+	for _, m := range urlEnvVar.FindAllStringSubmatch(f.content, -1) {
+		if key := "$" + m[1]; !seen[key] {
+			seen[key] = true
+			c[key]++
+		}
+	}
+}
+
+// findTestFiles returns up to maxTestFiles test files under root
+func findTestFiles(root string) []testFile {
+	var files []testFile
+	filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() {
+			name := info.Name()
+			if path != root && (strings.HasPrefix(name, ".") || name == "node_modules" || name == "vendor" || name == "__pycache__") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if len(files) >= maxTestFiles {
+			return filepath.SkipAll
+		}
+
+		lang := testLanguage(info.Name())
+		if lang == "" {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+		files = append(files, testFile{path: path, lang: lang, content: string(data)})
+		return nil
+	})
+	return files
+}
+
+// testLanguage returns the language of a test file, or "" for other files
+func testLanguage(name string) string {
+	ext := filepath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	switch ext {
+	case ".go":
+		if strings.HasSuffix(base, "_test") {
+			return "go"
+		}
+	case ".py":
+		if strings.HasPrefix(base, "test_") || strings.HasSuffix(base, "_test") || base == "conftest" {
+			return "python"
+		}
+	case ".js", ".jsx", ".ts", ".tsx", ".mjs", ".cjs":
+		if strings.HasSuffix(base, ".test") || strings.HasSuffix(base, ".spec") {
+			return "javascript"
+		}
+	}
+	return ""
+}
+
+// Empty reports whether nothing was found to summarise
+func (r *Report) Empty() bool {
+	return r == nil || len(r.Assertions)+len(r.Fixtures)+len(r.Naming)+len(r.BaseURLs) == 0
+}
+
+// Summary renders the report as a compact prompt section, or "" when
+// there's nothing to follow
+func (r *Report) Summary() string {
+	if r.Empty() {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("## Repository Testing Conventions\n")
+	sb.WriteString(fmt.Sprintf("The repository's existing tests (%d files) follow these conventions. Match them.\n", r.TestFiles))
+	line := func(label string, items []string) {
+		if len(items) > 0 {
+			sb.WriteString(fmt.Sprintf("- %s: %s\n", label, strings.Join(items, "; ")))
+		}
+	}
+	line("Assertions", r.Assertions)
+	line("Fixtures and helpers", r.Fixtures)
+	line("Naming", r.Naming)
+	line("Base URLs", r.BaseURLs)
+	return sb.String()
+}
+
+// Prepend puts the summary ahead of a prompt
+func (r *Report) Prepend(prompt string) string {
+	summary := r.Summary()
+	if summary == "" {
+		return prompt
+	}
+	return summary + "\n" + prompt
+}
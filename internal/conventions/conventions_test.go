@@ -0,0 +1,195 @@
+package conventions
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestDetect_Go(t *testing.T) {
+	root := writeFiles(t, map[string]string{
+		"users/users_test.go": `package users
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newServer(t *testing.T) *Server {
+	t.Helper()
+	return NewServer(os.Getenv("API_BASE_URL"))
+}
+
+func TestCreate_Valid(t *testing.T) {
+	tests := []struct{ name string }{{"ok"}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, newServer(t).Create("http://localhost:8080/users"))
+		})
+	}
+}
+`,
+		"users/get_test.go": `package users
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGet_Missing(t *testing.T) {
+	_, err := newServer(t).Get("http://localhost:8080/users/1")
+	require.Error(t, err)
+}
+`,
+		"users/users.go":                "package users\n\nfunc helper(t *testing.T) {}\n",
+		"vendor/lib/lib_test.go":        "package lib\n\nimport \"github.com/onsi/gomega\"\n",
+		"node_modules/x/index.test.js":  "expect(1).toBe(1)",
+		".git/hooks/something_test.go":  "package hooks",
+		"users/testdata/fixture.golden": "ignored",
+	})
+
+	r := Detect(root)
+	if r.TestFiles != 2 {
+		t.Errorf("TestFiles = %d, want 2", r.TestFiles)
+	}
+	if len(r.Assertions) != 1 || r.Assertions[0] != "testify/require (2 files)" {
+		t.Errorf("Assertions = %v", r.Assertions)
+	}
+	if len(r.Fixtures) != 1 || r.Fixtures[0] != "newServer()" {
+		t.Errorf("Fixtures = %v, want newServer()", r.Fixtures)
+	}
+	wantNaming := []string{"TestFunction_Scenario (e.g. TestGet_Missing)", "table-driven tests with t.Run subtests"}
+	if strings.Join(r.Naming, "|") != strings.Join(wantNaming, "|") {
+		t.Errorf("Naming = %v, want %v", r.Naming, wantNaming)
+	}
+	wantURLs := []string{"http://localhost:8080", "$API_BASE_URL"}
+	if strings.Join(r.BaseURLs, "|") != strings.Join(wantURLs, "|") {
+		t.Errorf("BaseURLs = %v, want %v", r.BaseURLs, wantURLs)
+	}
+}
+
+func TestDetect_PythonAndJS(t *testing.T) {
+	root := writeFiles(t, map[string]string{
+		"tests/conftest.py": `import os
+import pytest
+
+BASE_URL = os.environ.get("SERVICE_URL", "https://staging.example.com")
+
+
+@pytest.fixture
+def client():
+    return Client(BASE_URL)
+
+
+@pytest.fixture(scope="session")
+async def db_session():
+    yield Session()
+`,
+		"tests/test_orders.py": `import pytest
+
+
+@pytest.mark.parametrize("qty", [1, 2])
+def test_create_order_valid(client, qty):
+    assert client.create(qty).ok
+`,
+		"web/cart.test.ts": `import { describe, it, expect } from 'vitest';
+import { renderCart } from './helpers';
+
+describe('cart', () => {
+  beforeEach(() => reset());
+  it('adds an item', () => {
+    expect(renderCart()).toBeTruthy();
+  });
+});
+`,
+		"web/helpers.test.ts": `export function renderCart() { return mount(); }
+
+describe('helpers', () => {
+  it('mounts', () => expect(renderCart()).toBeDefined());
+});
+`,
+	})
+
+	r := Detect(root)
+	for _, want := range []string{"pytest plain assert (1 file)", "jest expect (1 file)", "vitest expect (1 file)"} {
+		if !contains(r.Assertions, want) {
+			t.Errorf("Assertions = %v, missing %s", r.Assertions, want)
+		}
+	}
+	for _, want := range []string{"client (pytest fixture)", "db_session (pytest fixture)", "beforeEach setup", "renderCart()"} {
+		if !contains(r.Fixtures, want) {
+			t.Errorf("Fixtures = %v, missing %s", r.Fixtures, want)
+		}
+	}
+	for _, want := range []string{
+		"describe/it blocks (e.g. 'adds an item')",
+		"module-level test_<function>_<case> functions (e.g. test_create_order_valid)",
+		"@pytest.mark.parametrize for cases",
+	} {
+		if !contains(r.Naming, want) {
+			t.Errorf("Naming = %v, missing %s", r.Naming, want)
+		}
+	}
+	for _, want := range []string{"https://staging.example.com", "$SERVICE_URL"} {
+		if !contains(r.BaseURLs, want) {
+			t.Errorf("BaseURLs = %v, missing %s", r.BaseURLs, want)
+		}
+	}
+}
+
+func TestReport_Summary(t *testing.T) {
+	if s := Detect(t.TempDir()).Summary(); s != "" {
+		t.Errorf("repository without tests should have no summary, got %q", s)
+	}
+	var empty *Report
+	if got := empty.Prepend("prompt"); got != "prompt" {
+		t.Errorf("nil report Prepend() = %q", got)
+	}
+
+	r := &Report{TestFiles: 3, Assertions: []string{"testify/require (3 files)"}, BaseURLs: []string{"$BASE_URL"}}
+	summary := r.Summary()
+	for _, want := range []string{
+		"## Repository Testing Conventions\n",
+		"(3 files)",
+		"- Assertions: testify/require (3 files)\n",
+		"- Base URLs: $BASE_URL\n",
+	} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("Summary() missing %q:\n%s", want, summary)
+		}
+	}
+	if strings.Contains(summary, "Naming") {
+		t.Errorf("Summary() shouldn't list empty sections:\n%s", summary)
+	}
+	if got := r.Prepend("Generate a test"); !strings.HasPrefix(got, summary) || !strings.HasSuffix(got, "Generate a test") {
+		t.Errorf("Prepend() = %q", got)
+	}
+}
+
+func contains(items []string, want string) bool {
+	for _, item := range items {
+		if item == want {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,373 @@
+package datagen
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// FieldKind classifies a column in a sample dataset
+type FieldKind string
+
+const (
+	FieldNumeric     FieldKind = "numeric"
+	FieldBoolean     FieldKind = "boolean"
+	FieldCategorical FieldKind = "categorical"
+	FieldText        FieldKind = "text"
+	FieldPII         FieldKind = "pii"
+)
+
+// FieldProfile captures the distribution of a single field in sample data.
+// Profiles never retain raw PII values, only aggregate statistics.
+type FieldProfile struct {
+	Name     string    `json:"name"`
+	Kind     FieldKind `json:"kind"`
+	NullRate float64   `json:"null_rate"`
+
+	// Numeric fields
+	Min     float64 `json:"min,omitempty"`
+	Max     float64 `json:"max,omitempty"`
+	Mean    float64 `json:"mean,omitempty"`
+	StdDev  float64 `json:"std_dev,omitempty"`
+	Integer bool    `json:"integer,omitempty"`
+
+	// Categorical fields: value -> relative frequency
+	Categories map[string]float64 `json:"categories,omitempty"`
+
+	// Text fields
+	AvgLength int `json:"avg_length,omitempty"`
+}
+
+// DatasetProfile describes every field of a sample dataset
+type DatasetProfile struct {
+	Rows   int             `json:"rows"`
+	Fields []*FieldProfile `json:"fields"`
+}
+
+// maxCategories is the largest number of distinct values treated as an enum
+const maxCategories = 12
+
+var (
+	emailPattern = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[a-zA-Z]{2,}$`)
+	phonePattern = regexp.MustCompile(`^\+?[\d\s().-]{7,}$`)
+	uuidPattern  = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+)
+
+// piiFieldHints are field-name fragments that always indicate personal data
+var piiFieldHints = []string{
+	"name", "email", "phone", "address", "street", "city", "zip", "postal",
+	"ssn", "passport", "birth", "dob", "ip", "password", "token", "card", "iban",
+	"user", "login", "id",
+}
+
+// LoadSamples reads a sample dataset from a CSV (with header row) or JSON
+// (array of objects) file
+func LoadSamples(path string) ([]map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read samples: %w", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return parseCSVSamples(string(data))
+	case ".json":
+		var rows []map[string]interface{}
+		if err := json.Unmarshal(data, &rows); err != nil {
+			return nil, fmt.Errorf("invalid JSON samples (expected array of objects): %w", err)
+		}
+		return rows, nil
+	default:
+		return nil, fmt.Errorf("unsupported sample format: %s (use .csv or .json)", filepath.Ext(path))
+	}
+}
+
+func parseCSVSamples(data string) ([]map[string]interface{}, error) {
+	records, err := csv.NewReader(strings.NewReader(data)).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV samples: %w", err)
+	}
+	if len(records) < 2 {
+		return nil, fmt.Errorf("CSV samples need a header row and at least one data row")
+	}
+
+	header := records[0]
+	rows := make([]map[string]interface{}, 0, len(records)-1)
+	for _, rec := range records[1:] {
+		row := make(map[string]interface{}, len(header))
+		for i, col := range header {
+			if i >= len(rec) || rec[i] == "" {
+				row[col] = nil
+				continue
+			}
+			row[col] = parseCSVValue(rec[i])
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// parseCSVValue converts CSV cells to numbers/booleans where possible so
+// they profile like JSON values
+func parseCSVValue(s string) interface{} {
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f
+	}
+	if b, err := strconv.ParseBool(s); err == nil {
+		return b
+	}
+	return s
+}
+
+// ProfileSamples builds a statistical profile of a sample dataset
+func ProfileSamples(rows []map[string]interface{}) *DatasetProfile {
+	profile := &DatasetProfile{Rows: len(rows)}
+
+	names := make(map[string]bool)
+	for _, row := range rows {
+		for k := range row {
+			names[k] = true
+		}
+	}
+	sorted := make([]string, 0, len(names))
+	for k := range names {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+
+	for _, name := range sorted {
+		values := make([]interface{}, 0, len(rows))
+		nulls := 0
+		for _, row := range rows {
+			v, ok := row[name]
+			if !ok || v == nil {
+				nulls++
+				continue
+			}
+			values = append(values, v)
+		}
+
+		fp := profileField(name, values)
+		if len(rows) > 0 {
+			fp.NullRate = float64(nulls) / float64(len(rows))
+		}
+		profile.Fields = append(profile.Fields, fp)
+	}
+
+	return profile
+}
+
+func profileField(name string, values []interface{}) *FieldProfile {
+	fp := &FieldProfile{Name: name}
+
+	if isPIIField(name, values) {
+		fp.Kind = FieldPII
+		return fp
+	}
+
+	var nums []float64
+	bools, strs := 0, 0
+	counts := make(map[string]int)
+	totalLen := 0
+
+	for _, v := range values {
+		switch val := v.(type) {
+		case float64:
+			nums = append(nums, val)
+		case bool:
+			bools++
+			counts[strconv.FormatBool(val)]++
+		case string:
+			strs++
+			counts[val]++
+			totalLen += len(val)
+		default:
+			strs++
+		}
+	}
+
+	switch {
+	case len(nums) > 0 && len(nums) >= bools+strs:
+		fp.Kind = FieldNumeric
+		fp.Integer = true
+		fp.Min, fp.Max = nums[0], nums[0]
+		sum := 0.0
+		for _, n := range nums {
+			sum += n
+			fp.Min = math.Min(fp.Min, n)
+			fp.Max = math.Max(fp.Max, n)
+			if n != math.Trunc(n) {
+				fp.Integer = false
+			}
+		}
+		fp.Mean = sum / float64(len(nums))
+		variance := 0.0
+		for _, n := range nums {
+			variance += (n - fp.Mean) * (n - fp.Mean)
+		}
+		fp.StdDev = math.Sqrt(variance / float64(len(nums)))
+
+	case bools > 0 && bools >= strs:
+		fp.Kind = FieldBoolean
+		fp.Categories = frequencies(counts, bools)
+
+	case len(counts) > 0 && len(counts) <= maxCategories && len(counts) < strs:
+		// Repeated values from a small set behave like an enum
+		fp.Kind = FieldCategorical
+		fp.Categories = frequencies(counts, strs)
+
+	default:
+		fp.Kind = FieldText
+		if strs > 0 {
+			fp.AvgLength = totalLen / strs
+		}
+	}
+
+	return fp
+}
+
+func frequencies(counts map[string]int, total int) map[string]float64 {
+	freq := make(map[string]float64, len(counts))
+	for k, c := range counts {
+		freq[k] = float64(c) / float64(total)
+	}
+	return freq
+}
+
+// IsPII reports whether a single named value holds personal data, using the
+// same name and value-shape checks as dataset profiling
+func IsPII(name string, value interface{}) bool {
+	return isPIIField(name, []interface{}{value})
+}
+
+// isPIIField reports whether a field holds personal data, judged by its
+// name or by the shape of its values
+func isPIIField(name string, values []interface{}) bool {
+	lower := strings.ToLower(name)
+	for _, hint := range piiFieldHints {
+		if lower == hint || strings.HasPrefix(lower, hint+"_") || strings.HasSuffix(lower, "_"+hint) ||
+			strings.Contains(lower, hint) && len(hint) > 3 {
+			return true
+		}
+	}
+
+	for _, v := range values {
+		s, ok := v.(string)
+		if !ok {
+			continue
+		}
+		if emailPattern.MatchString(s) || uuidPattern.MatchString(s) {
+			return true
+		}
+		if phonePattern.MatchString(s) && strings.ContainsAny(s, "-+ ") {
+			return true
+		}
+	}
+	return false
+}
+
+// Anonymizer generates fixtures that follow the distribution of a sample
+// dataset without copying any personal data from it
+type Anonymizer struct {
+	gen *DataGenerator
+}
+
+// NewAnonymizer creates an anonymizer
+func NewAnonymizer() *Anonymizer {
+	return &Anonymizer{gen: NewDataGenerator()}
+}
+
+// NewSeededAnonymizer creates an anonymizer with reproducible output
+func NewSeededAnonymizer(seed int64) *Anonymizer {
+	return &Anonymizer{gen: NewSeededDataGenerator(seed)}
+}
+
+// SetLocale switches the fake personal data to the given locale
+func (a *Anonymizer) SetLocale(code string) error {
+	return a.gen.SetLocale(code)
+}
+
+// Generate produces count anonymized records matching the profile
+func (a *Anonymizer) Generate(profile *DatasetProfile, count int) []map[string]interface{} {
+	rows := make([]map[string]interface{}, 0, count)
+	for i := 0; i < count; i++ {
+		row := make(map[string]interface{}, len(profile.Fields))
+		for _, fp := range profile.Fields {
+			row[fp.Name] = a.generateValue(fp)
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func (a *Anonymizer) generateValue(fp *FieldProfile) interface{} {
+	if fp.NullRate > 0 && a.gen.rng.Float64() < fp.NullRate {
+		return nil
+	}
+
+	switch fp.Kind {
+	case FieldNumeric:
+		v := fp.Mean + a.gen.rng.NormFloat64()*fp.StdDev
+		v = math.Max(fp.Min, math.Min(fp.Max, v))
+		if fp.Integer {
+			return math.Round(v)
+		}
+		return math.Round(v*100) / 100
+
+	case FieldBoolean:
+		return a.gen.rng.Float64() < fp.Categories["true"]
+
+	case FieldCategorical:
+		return sampleCategory(fp.Categories, a.gen.rng.Float64())
+
+	case FieldPII:
+		return a.gen.GenerateForType("string", fp.Name)
+
+	default:
+		return a.fakeText(fp)
+	}
+}
+
+// fakeText produces filler text of roughly the sampled length
+func (a *Anonymizer) fakeText(fp *FieldProfile) string {
+	if fp.AvgLength == 0 {
+		return a.gen.Word()
+	}
+	var sb strings.Builder
+	for sb.Len() < fp.AvgLength {
+		if sb.Len() > 0 {
+			sb.WriteString(" ")
+		}
+		sb.WriteString(a.gen.Word())
+	}
+	return sb.String()
+}
+
+// sampleCategory picks a category by cumulative frequency, iterating in
+// sorted order so results are stable for a given roll
+func sampleCategory(categories map[string]float64, roll float64) string {
+	keys := make([]string, 0, len(categories))
+	for k := range categories {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	cumulative := 0.0
+	for _, k := range keys {
+		cumulative += categories[k]
+		if roll < cumulative {
+			return k
+		}
+	}
+	if len(keys) == 0 {
+		return ""
+	}
+	return keys[len(keys)-1]
+}
@@ -0,0 +1,137 @@
+package datagen
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const sampleCSV = `id,email,full_name,plan,age,active,notes
+1,ann@corp.com,Ann Lee,pro,34,true,called about billing
+2,bob@corp.com,Bob Ray,free,41,false,
+3,cy@corp.com,Cy Dunn,pro,29,true,asked for refund
+4,di@corp.com,Di Moss,team,52,true,renewal pending
+`
+
+func writeSamples(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadSamples(t *testing.T) {
+	rows, err := LoadSamples(writeSamples(t, "users.csv", sampleCSV))
+	if err != nil {
+		t.Fatalf("LoadSamples(csv) error: %v", err)
+	}
+	if len(rows) != 4 {
+		t.Fatalf("len(rows) = %d, want 4", len(rows))
+	}
+	if rows[0]["age"] != 34.0 || rows[0]["active"] != true || rows[1]["notes"] != nil {
+		t.Errorf("CSV values not typed correctly: %v", rows[0])
+	}
+
+	rows, err = LoadSamples(writeSamples(t, "users.json", `[{"plan": "pro"}, {"plan": "free"}]`))
+	if err != nil || len(rows) != 2 {
+		t.Errorf("LoadSamples(json) = %d rows, err %v", len(rows), err)
+	}
+
+	if _, err := LoadSamples(writeSamples(t, "users.xml", "<x/>")); err == nil {
+		t.Error("LoadSamples should reject unknown formats")
+	}
+}
+
+func TestProfileSamples(t *testing.T) {
+	rows, _ := parseCSVSamples(sampleCSV)
+	profile := ProfileSamples(rows)
+
+	kinds := map[string]FieldKind{}
+	for _, fp := range profile.Fields {
+		kinds[fp.Name] = fp.Kind
+		if fp.Name == "age" && (fp.Min != 29 || fp.Max != 52 || !fp.Integer) {
+			t.Errorf("age profile = %+v", fp)
+		}
+		if fp.Name == "notes" && fp.NullRate != 0.25 {
+			t.Errorf("notes NullRate = %v, want 0.25", fp.NullRate)
+		}
+	}
+
+	want := map[string]FieldKind{
+		"id":        FieldPII,
+		"email":     FieldPII,
+		"full_name": FieldPII,
+		"plan":      FieldCategorical,
+		"age":       FieldNumeric,
+		"active":    FieldBoolean,
+		"notes":     FieldText,
+	}
+	for name, kind := range want {
+		if kinds[name] != kind {
+			t.Errorf("%s kind = %s, want %s", name, kinds[name], kind)
+		}
+	}
+}
+
+func TestAnonymizer_Generate(t *testing.T) {
+	rows, _ := parseCSVSamples(sampleCSV)
+	profile := ProfileSamples(rows)
+
+	originals := map[interface{}]bool{}
+	for _, row := range rows {
+		originals[row["email"]] = true
+		originals[row["full_name"]] = true
+	}
+
+	out := NewAnonymizer().Generate(profile, 50)
+	if len(out) != 50 {
+		t.Fatalf("len(out) = %d, want 50", len(out))
+	}
+
+	for _, row := range out {
+		if originals[row["email"]] || originals[row["full_name"]] {
+			t.Errorf("anonymized row leaked sample PII: %v", row)
+		}
+		age, ok := row["age"].(float64)
+		if !ok || age < 29 || age > 52 {
+			t.Errorf("age %v outside sampled range", row["age"])
+		}
+		if plan := row["plan"]; plan != "pro" && plan != "free" && plan != "team" {
+			t.Errorf("plan %v not from sampled categories", plan)
+		}
+	}
+}
+
+func TestNewSeededAnonymizer_Reproducible(t *testing.T) {
+	rows, _ := parseCSVSamples(sampleCSV)
+	profile := ProfileSamples(rows)
+
+	a, _ := json.Marshal(NewSeededAnonymizer(5).Generate(profile, 10))
+	b, _ := json.Marshal(NewSeededAnonymizer(5).Generate(profile, 10))
+	if string(a) != string(b) {
+		t.Error("same seed produced different fixtures")
+	}
+}
+
+func TestIsPII(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  bool
+	}{
+		{"email", "x", true},
+		{"contact", "ann@corp.com", true},
+		{"billing_address", "1 Main St", true},
+		{"ref", "5f0c6b3e-2a51-4c1d-9d7e-0f6a1c2b3d4e", true},
+		{"plan", "pro", false},
+		{"quantity", 3.0, false},
+	}
+	for _, tt := range tests {
+		if got := IsPII(tt.name, tt.value); got != tt.want {
+			t.Errorf("IsPII(%q, %v) = %v, want %v", tt.name, tt.value, got, tt.want)
+		}
+	}
+}
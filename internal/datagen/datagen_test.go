@@ -518,7 +518,7 @@ func TestGenerateForType_TypeFallback_Bool(t *testing.T) {
 
 func TestPick(t *testing.T) {
 	items := []string{"a", "b", "c"}
-	result := pick(items)
+	result := NewDataGenerator().pick(items)
 
 	found := false
 	for _, item := range items {
@@ -540,3 +540,139 @@ func TestContains(t *testing.T) {
 		t.Error("Should not contain 'world'")
 	}
 }
+
+func TestNewSeededDataGenerator_Reproducible(t *testing.T) {
+	sample := func(g *DataGenerator) []interface{} {
+		return []interface{}{
+			g.UUID(), g.Email(), g.FullName(), g.Phone(), g.DateTime(),
+			g.Date(), g.Price(), g.Float(0, 1), g.Paragraph(),
+			g.GenerateForType("string", "username"),
+		}
+	}
+
+	a := sample(NewSeededDataGenerator(42))
+	b := sample(NewSeededDataGenerator(42))
+	for i := range a {
+		if a[i] != b[i] {
+			t.Errorf("value %d differs for same seed: %v vs %v", i, a[i], b[i])
+		}
+	}
+
+	c := sample(NewSeededDataGenerator(43))
+	if a[0] == c[0] {
+		t.Error("different seeds should produce different UUIDs")
+	}
+}
+
+func TestNewSeededDataGenerator_FixedDates(t *testing.T) {
+	date := NewSeededDataGenerator(7).Date()
+	if !strings.HasPrefix(date, "2023-") && !strings.HasPrefix(date, "2024-01-01") {
+		t.Errorf("seeded Date() = %s, want within the year before the seeded epoch", date)
+	}
+}
+
+func TestLookupLocale(t *testing.T) {
+	tests := []struct {
+		code string
+		want string
+	}{
+		{"en", "en_US"},
+		{"de", "de_DE"},
+		{"fr-FR", "fr_FR"},
+		{"JA_jp", "ja_JP"},
+		{"uk", "en_GB"},
+	}
+	for _, tt := range tests {
+		loc, err := LookupLocale(tt.code)
+		if err != nil {
+			t.Errorf("LookupLocale(%q) error = %v", tt.code, err)
+			continue
+		}
+		if loc.Code != tt.want {
+			t.Errorf("LookupLocale(%q) = %s, want %s", tt.code, loc.Code, tt.want)
+		}
+	}
+
+	if _, err := LookupLocale("xx_YY"); err == nil {
+		t.Error("expected error for unknown locale")
+	}
+}
+
+func TestSetLocale(t *testing.T) {
+	gen := NewSeededDataGenerator(1)
+	if err := gen.SetLocale("de"); err != nil {
+		t.Fatalf("SetLocale() error = %v", err)
+	}
+
+	if !strings.HasPrefix(gen.Phone(), "+49 ") {
+		t.Errorf("de phone = %s, want +49 prefix", gen.Phone())
+	}
+	if gen.Country() != "Deutschland" || gen.Currency() != "EUR" || gen.CountryCode() != "DE" {
+		t.Errorf("unexpected de locale data: %s %s %s", gen.Country(), gen.Currency(), gen.CountryCode())
+	}
+	if !regexp.MustCompile(`^\D+ \d+$`).MatchString(gen.Street()) {
+		t.Errorf("de street = %q, want name before number", gen.Street())
+	}
+
+	emailRe := regexp.MustCompile(`^[a-z]+\.[a-z]+@[a-z.]+$`)
+	for i := 0; i < 50; i++ {
+		if email := gen.Email(); !emailRe.MatchString(email) {
+			t.Fatalf("email %q is not ASCII-safe", email)
+		}
+	}
+
+	gen.SetLocale("en_GB")
+	if !regexp.MustCompile(`^[A-Z]{2}\d [0-9][A-Z]{2}$`).MatchString(gen.ZipCode()) {
+		t.Errorf("en_GB postcode = %s", gen.ZipCode())
+	}
+}
+
+func TestGenerateForFormat(t *testing.T) {
+	gen := NewDataGenerator()
+
+	tests := []struct {
+		format  string
+		pattern string
+	}{
+		{"email", `^[^@]+@[^@]+\.[a-z]+$`},
+		{"uuid", `^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`},
+		{"date", `^\d{4}-\d{2}-\d{2}$`},
+		{"date-time", `^\d{4}-\d{2}-\d{2}T`},
+		{"ipv4", `^\d{1,3}\.\d{1,3}\.\d{1,3}\.\d{1,3}$`},
+		{"ipv6", `^[0-9a-f]{1,4}(:[0-9a-f]{1,4}){7}$`},
+		{"currency", `^[A-Z]{3}$`},
+		{"country-code", `^[A-Z]{2}$`},
+		{"language", `^[a-z]{2}-[A-Z]{2}$`},
+	}
+	for _, tt := range tests {
+		v, ok := gen.GenerateForFormat(tt.format)
+		if !ok {
+			t.Errorf("format %s not supported", tt.format)
+			continue
+		}
+		if !regexp.MustCompile(tt.pattern).MatchString(v) {
+			t.Errorf("format %s produced %q", tt.format, v)
+		}
+	}
+
+	if _, ok := gen.GenerateForFormat("nonsense"); ok {
+		t.Error("unknown format should not be supported")
+	}
+}
+
+func TestGenerateForType_InferredFormats(t *testing.T) {
+	gen := NewDataGenerator()
+
+	if v := gen.GenerateForType("string", "client_ip").(string); strings.Count(v, ".") != 3 {
+		t.Errorf("client_ip = %q, want IPv4", v)
+	}
+	if v := gen.GenerateForType("string", "expiresAt").(string); !strings.Contains(v, "T") {
+		t.Errorf("expiresAt = %q, want RFC3339 timestamp", v)
+	}
+	if v := gen.GenerateForType("string", "birth_date").(string); len(v) != 10 {
+		t.Errorf("birth_date = %q, want ISO date", v)
+	}
+	if v := gen.GenerateForType("date", "unknown").(string); len(v) != 10 {
+		t.Errorf("date type = %q, want ISO date", v)
+	}
+}
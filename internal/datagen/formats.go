@@ -0,0 +1,104 @@
+package datagen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// GenerateForFormat generates a string satisfying a well-known format
+// (JSON Schema formats plus a few common API ones). It reports false for
+// unknown formats.
+func (g *DataGenerator) GenerateForFormat(format string) (string, bool) {
+	switch strings.ToLower(format) {
+	case "email":
+		return g.Email(), true
+	case "uuid":
+		return g.UUID(), true
+	case "date":
+		return g.Date(), true
+	case "date-time", "datetime", "iso8601", "timestamp":
+		return g.DateTime(), true
+	case "time":
+		return g.Time(), true
+	case "uri", "url":
+		return g.URL(), true
+	case "hostname":
+		return g.Hostname(), true
+	case "ipv4", "ip":
+		return g.IPv4(), true
+	case "ipv6":
+		return g.IPv6(), true
+	case "phone":
+		return g.Phone(), true
+	case "password":
+		return g.Password(), true
+	case "currency":
+		return g.Currency(), true
+	case "country-code":
+		return g.CountryCode(), true
+	case "language", "locale":
+		return g.LanguageTag(), true
+	case "postal-code", "zip":
+		return g.ZipCode(), true
+	case "slug":
+		return g.Slug(), true
+	}
+	return "", false
+}
+
+// formatForField infers a strict format from a field name, for fields where
+// free text would fail validation. Returns "" when nothing applies.
+func formatForField(fieldName string) string {
+	lower := strings.ToLower(fieldName)
+	switch {
+	case lower == "ip" || strings.HasSuffix(lower, "_ip") || strings.Contains(lower, "ipaddress") ||
+		strings.Contains(lower, "ip_address") || strings.Contains(lower, "ipv4"):
+		return "ipv4"
+	case strings.Contains(lower, "ipv6"):
+		return "ipv6"
+	case lower == "host" || strings.Contains(lower, "hostname") || strings.Contains(lower, "domain"):
+		return "hostname"
+	case strings.Contains(lower, "country_code") || strings.Contains(lower, "countrycode"):
+		return "country-code"
+	case strings.Contains(lower, "locale") || strings.Contains(lower, "language"):
+		return "language"
+	case strings.Contains(lower, "birth") || lower == "dob":
+		return "date"
+	case strings.HasSuffix(lower, "_at") || strings.HasSuffix(fieldName, "At"):
+		return "date-time"
+	}
+	return ""
+}
+
+// Network Generators
+
+func (g *DataGenerator) IPv4() string {
+	return fmt.Sprintf("%d.%d.%d.%d", g.rng.Intn(223)+1, g.rng.Intn(256), g.rng.Intn(256), g.rng.Intn(254)+1)
+}
+
+func (g *DataGenerator) IPv6() string {
+	parts := make([]string, 8)
+	for i := range parts {
+		parts[i] = fmt.Sprintf("%x", g.rng.Intn(0x10000))
+	}
+	return strings.Join(parts, ":")
+}
+
+func (g *DataGenerator) Hostname() string {
+	return fmt.Sprintf("%s.%s", g.Word(), g.pick(g.loc.EmailDomains))
+}
+
+// Locale Generators
+
+// CountryCode returns the ISO 3166-1 alpha-2 code of the current locale
+func (g *DataGenerator) CountryCode() string {
+	if i := strings.Index(g.loc.Code, "_"); i >= 0 {
+		return g.loc.Code[i+1:]
+	}
+	return strings.ToUpper(g.loc.Code)
+}
+
+// LanguageTag returns the BCP 47 tag of the current locale (e.g. "de-DE")
+func (g *DataGenerator) LanguageTag() string {
+	return strings.ReplaceAll(g.loc.Code, "_", "-")
+}
@@ -7,28 +7,64 @@ import (
 	"time"
 )
 
-func init() {
-	rand.Seed(time.Now().UnixNano())
-}
+// seededEpoch is the reference "now" for seeded generators, so relative
+// dates don't drift between runs
+var seededEpoch = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
 
 // DataGenerator generates realistic test data
 type DataGenerator struct {
 	locale string
+	loc    *Locale
+	rng    *rand.Rand
+	now    time.Time
 }
 
-// NewDataGenerator creates a new data generator
+// NewDataGenerator creates a new data generator with a random seed
 func NewDataGenerator() *DataGenerator {
 	return &DataGenerator{
-		locale: "en",
+		locale: DefaultLocale,
+		loc:    enUS,
+		rng:    rand.New(rand.NewSource(time.Now().UnixNano())),
+		now:    time.Now(),
+	}
+}
+
+// NewSeededDataGenerator creates a data generator whose output is fully
+// determined by seed, including IDs and dates
+func NewSeededDataGenerator(seed int64) *DataGenerator {
+	return &DataGenerator{
+		locale: DefaultLocale,
+		loc:    enUS,
+		rng:    rand.New(rand.NewSource(seed)),
+		now:    seededEpoch,
 	}
 }
 
+// SetLocale switches the names, addresses and phone/postal formats to the
+// given locale (e.g. "de", "fr_FR", "ja-JP")
+func (g *DataGenerator) SetLocale(code string) error {
+	loc, err := LookupLocale(code)
+	if err != nil {
+		return err
+	}
+	g.locale = loc.Code
+	g.loc = loc
+	return nil
+}
+
 // GenerateForType generates data based on type/field name
 func (g *DataGenerator) GenerateForType(typeName string, fieldName string) interface{} {
 	// Normalize for matching
 	typeNameLower := strings.ToLower(typeName)
 	fieldNameLower := strings.ToLower(fieldName)
 
+	// Fields with a strict wire format (IPs, hosts, timestamps, ...)
+	if format := formatForField(fieldName); format != "" {
+		if v, ok := g.GenerateForFormat(format); ok {
+			return v
+		}
+	}
+
 	// Check field name first for semantic matching
 	switch {
 	// Personal info
@@ -126,8 +162,12 @@ func (g *DataGenerator) GenerateForType(typeName string, fieldName string) inter
 		return g.Float(0, 1000)
 	case "bool", "boolean":
 		return g.Bool()
-	case "date", "datetime", "timestamp":
+	case "date":
+		return g.Date()
+	case "datetime", "timestamp":
 		return g.DateTime()
+	case "time":
+		return g.Time()
 	case "email":
 		return g.Email()
 	case "url", "uri":
@@ -142,18 +182,18 @@ func (g *DataGenerator) GenerateForType(typeName string, fieldName string) inter
 // Personal Info Generators
 
 func (g *DataGenerator) Email() string {
-	return fmt.Sprintf("%s.%s@%s.com",
-		strings.ToLower(g.FirstName()),
-		strings.ToLower(g.LastName()),
-		pick([]string{"gmail", "yahoo", "outlook", "example", "test"}))
+	return fmt.Sprintf("%s.%s@%s",
+		emailLocalPart(g.FirstName()),
+		emailLocalPart(g.LastName()),
+		g.pick(g.loc.EmailDomains))
 }
 
 func (g *DataGenerator) FirstName() string {
-	return pick(firstNames)
+	return g.pick(g.loc.FirstNames)
 }
 
 func (g *DataGenerator) LastName() string {
-	return pick(lastNames)
+	return g.pick(g.loc.LastNames)
 }
 
 func (g *DataGenerator) FullName() string {
@@ -161,66 +201,67 @@ func (g *DataGenerator) FullName() string {
 }
 
 func (g *DataGenerator) Username() string {
-	return strings.ToLower(g.FirstName()) + fmt.Sprintf("%d", rand.Intn(999))
+	return emailLocalPart(g.FirstName()) + fmt.Sprintf("%d", g.rng.Intn(999))
 }
 
 func (g *DataGenerator) Password() string {
 	chars := "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789!@#$%"
 	b := make([]byte, 12)
 	for i := range b {
-		b[i] = chars[rand.Intn(len(chars))]
+		b[i] = chars[g.rng.Intn(len(chars))]
 	}
 	return string(b)
 }
 
 func (g *DataGenerator) Phone() string {
-	return fmt.Sprintf("+1-%03d-%03d-%04d", rand.Intn(999), rand.Intn(999), rand.Intn(9999))
+	return g.fillPattern(g.loc.PhoneFormat)
 }
 
 // Address Generators
 
 func (g *DataGenerator) Street() string {
-	return fmt.Sprintf("%d %s %s",
-		rand.Intn(9999)+1,
-		pick(streetNames),
-		pick([]string{"St", "Ave", "Blvd", "Dr", "Ln", "Way"}))
+	name := g.pick(g.loc.Streets)
+	if len(g.loc.StreetSuffixes) > 0 {
+		name += " " + g.pick(g.loc.StreetSuffixes)
+	}
+	return fmt.Sprintf(g.loc.StreetFormat, g.rng.Intn(9999)+1, name)
 }
 
 func (g *DataGenerator) City() string {
-	return pick(cities)
+	return g.pick(g.loc.Cities)
 }
 
 func (g *DataGenerator) State() string {
-	return pick(states)
+	return g.pick(g.loc.States)
 }
 
 func (g *DataGenerator) Country() string {
-	return pick(countries)
+	return g.loc.Country
 }
 
 func (g *DataGenerator) ZipCode() string {
-	return fmt.Sprintf("%05d", rand.Intn(99999))
+	return g.fillPattern(g.loc.PostalFormat)
 }
 
 // Business Generators
 
 func (g *DataGenerator) Company() string {
-	return pick(companies)
+	return g.pick(companies)
 }
 
 func (g *DataGenerator) JobTitle() string {
-	return pick(jobTitles)
+	return g.pick(jobTitles)
 }
 
 func (g *DataGenerator) Department() string {
-	return pick(departments)
+	return g.pick(departments)
 }
 
 // ID Generators
 
 func (g *DataGenerator) UUID() string {
 	b := make([]byte, 16)
-	rand.Read(b)
+	g.rng.Read(b)
 	return fmt.Sprintf("%08x-%04x-%04x-%04x-%012x",
 		b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
 }
@@ -232,49 +273,49 @@ func (g *DataGenerator) Slug() string {
 // Date/Time Generators
 
 func (g *DataGenerator) DateTime() string {
-	t := time.Now().AddDate(0, 0, -rand.Intn(365))
+	t := g.now.AddDate(0, 0, -g.rng.Intn(365))
 	return t.Format(time.RFC3339)
 }
 
 func (g *DataGenerator) Date() string {
-	t := time.Now().AddDate(0, 0, -rand.Intn(365))
+	t := g.now.AddDate(0, 0, -g.rng.Intn(365))
 	return t.Format("2006-01-02")
 }
 
 func (g *DataGenerator) Time() string {
-	return fmt.Sprintf("%02d:%02d:%02d", rand.Intn(24), rand.Intn(60), rand.Intn(60))
+	return fmt.Sprintf("%02d:%02d:%02d", g.rng.Intn(24), g.rng.Intn(60), g.rng.Intn(60))
 }
 
 // URL Generators
 
 func (g *DataGenerator) URL() string {
 	return fmt.Sprintf("https://%s.com/%s",
-		strings.ToLower(pick(companies)),
+		strings.ToLower(g.pick(companies)),
 		g.Slug())
 }
 
 func (g *DataGenerator) ImageURL() string {
-	return fmt.Sprintf("https://picsum.photos/seed/%d/200/200", rand.Intn(1000))
+	return fmt.Sprintf("https://picsum.photos/seed/%d/200/200", g.rng.Intn(1000))
 }
 
 // Money Generators
 
 func (g *DataGenerator) Price() float64 {
-	return float64(rand.Intn(10000)) / 100
+	return float64(g.rng.Intn(10000)) / 100
 }
 
 func (g *DataGenerator) Currency() string {
-	return pick([]string{"USD", "EUR", "GBP", "JPY", "CAD", "AUD"})
+	return g.loc.Currency
 }
 
 // Text Generators
 
 func (g *DataGenerator) Word() string {
-	return pick(words)
+	return g.pick(words)
 }
 
 func (g *DataGenerator) Sentence() string {
-	wordCount := rand.Intn(10) + 5
+	wordCount := g.rng.Intn(10) + 5
 	var w []string
 	for i := 0; i < wordCount; i++ {
 		w = append(w, g.Word())
@@ -284,7 +325,7 @@ func (g *DataGenerator) Sentence() string {
 }
 
 func (g *DataGenerator) Paragraph() string {
-	sentenceCount := rand.Intn(3) + 2
+	sentenceCount := g.rng.Intn(3) + 2
 	var sentences []string
 	for i := 0; i < sentenceCount; i++ {
 		sentences = append(sentences, g.Sentence())
@@ -293,7 +334,7 @@ func (g *DataGenerator) Paragraph() string {
 }
 
 func (g *DataGenerator) Title() string {
-	wordCount := rand.Intn(3) + 2
+	wordCount := g.rng.Intn(3) + 2
 	var w []string
 	for i := 0; i < wordCount; i++ {
 		word := g.Word()
@@ -305,29 +346,49 @@ func (g *DataGenerator) Title() string {
 // Number Generators
 
 func (g *DataGenerator) Int(min, max int) int {
-	return rand.Intn(max-min+1) + min
+	return g.rng.Intn(max-min+1) + min
 }
 
 func (g *DataGenerator) Float(min, max float64) float64 {
-	return min + rand.Float64()*(max-min)
+	return min + g.rng.Float64()*(max-min)
 }
 
 func (g *DataGenerator) Bool() bool {
-	return rand.Intn(2) == 1
+	return g.rng.Intn(2) == 1
 }
 
 func (g *DataGenerator) Age() int {
-	return rand.Intn(60) + 18
+	return g.rng.Intn(60) + 18
 }
 
 func (g *DataGenerator) Status() string {
-	return pick([]string{"active", "inactive", "pending", "completed", "cancelled"})
+	return g.pick([]string{"active", "inactive", "pending", "completed", "cancelled"})
 }
 
 // Helper functions
 
-func pick(items []string) string {
-	return items[rand.Intn(len(items))]
+func (g *DataGenerator) pick(items []string) string {
+	return items[g.rng.Intn(len(items))]
+}
+
+// fillPattern replaces '#' with random digits and '?' with random uppercase
+// letters
+func (g *DataGenerator) fillPattern(pattern string) string {
+	b := []byte(pattern)
+	for i, c := range b {
+		switch c {
+		case '#':
+			b[i] = byte('0' + g.rng.Intn(10))
+		case '?':
+			b[i] = byte('A' + g.rng.Intn(26))
+		}
+	}
+	return string(b)
+}
+
+// emailLocalPart lowercases and transliterates a name for use in emails
+func emailLocalPart(name string) string {
+	return strings.ReplaceAll(asciiFold.Replace(strings.ToLower(name)), " ", "")
 }
 
 func contains(s, substr string) bool {
@@ -364,11 +425,6 @@ var states = []string{
 	"Ohio", "Georgia", "North Carolina", "Michigan", "New Jersey", "Virginia",
 }
 
-var countries = []string{
-	"United States", "Canada", "United Kingdom", "Germany", "France", "Australia",
-	"Japan", "Brazil", "India", "Mexico", "Spain", "Italy", "Netherlands", "Sweden",
-}
-
 var companies = []string{
 	"Acme", "Globex", "Initech", "Umbrella", "Stark", "Wayne", "Oscorp", "Cyberdyne",
 	"Aperture", "Weyland", "Tyrell", "Massive", "Dynamic", "Infinite", "Quantum",
@@ -0,0 +1,174 @@
+package datagen
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Locale holds the region-specific data sets and formats used to generate
+// personal info, addresses and phone numbers
+type Locale struct {
+	Code       string
+	FirstNames []string
+	LastNames  []string
+	Streets    []string
+	Cities     []string
+	States     []string
+	Country    string
+	Currency   string // ISO 4217 code
+
+	// StreetFormat is a fmt template taking (number, street name); names get
+	// a random suffix when StreetSuffixes is set
+	StreetFormat   string
+	StreetSuffixes []string
+
+	// PhoneFormat and PostalFormat are templates where '#' is replaced by a
+	// random digit and '?' by a random uppercase letter
+	PhoneFormat  string
+	PostalFormat string
+
+	EmailDomains []string
+}
+
+// DefaultLocale is used when no locale is configured
+const DefaultLocale = "en"
+
+var enUS = &Locale{
+	Code:           "en_US",
+	FirstNames:     firstNames,
+	LastNames:      lastNames,
+	Streets:        streetNames,
+	Cities:         cities,
+	States:         states,
+	Country:        "United States",
+	Currency:       "USD",
+	StreetFormat:   "%d %s",
+	StreetSuffixes: []string{"St", "Ave", "Blvd", "Dr", "Ln", "Way"},
+	PhoneFormat:    "+1-###-###-####",
+	PostalFormat:   "#####",
+	EmailDomains:   []string{"gmail.com", "yahoo.com", "outlook.com", "example.com", "test.com"},
+}
+
+var locales = map[string]*Locale{
+	"en_US": enUS,
+	"en_GB": {
+		Code:         "en_GB",
+		FirstNames:   []string{"Oliver", "George", "Harry", "Jack", "Amelia", "Isla", "Ava", "Emily", "Sophie", "Thomas", "James", "Charlotte"},
+		LastNames:    []string{"Smith", "Jones", "Taylor", "Brown", "Williams", "Wilson", "Evans", "Davies", "Hughes", "Wright", "Walker", "Roberts"},
+		Streets:      []string{"High Street", "Station Road", "Church Lane", "Victoria Road", "Green Lane", "Manor Road", "Park Road", "Queens Road"},
+		Cities:       []string{"London", "Manchester", "Birmingham", "Leeds", "Glasgow", "Bristol", "Liverpool", "Edinburgh", "Cardiff"},
+		States:       []string{"England", "Scotland", "Wales", "Northern Ireland"},
+		Country:      "United Kingdom",
+		Currency:     "GBP",
+		StreetFormat: "%d %s",
+		PhoneFormat:  "+44 7### ######",
+		PostalFormat: "??# #??",
+		EmailDomains: []string{"example.co.uk", "mail.co.uk", "test.co.uk"},
+	},
+	"de_DE": {
+		Code:         "de_DE",
+		FirstNames:   []string{"Lukas", "Leon", "Finn", "Jonas", "Paul", "Marie", "Sophie", "Hannah", "Emma", "Lena", "Jürgen", "Anna"},
+		LastNames:    []string{"Müller", "Schmidt", "Schneider", "Fischer", "Weber", "Meyer", "Wagner", "Becker", "Schulz", "Hoffmann", "Koch", "Richter"},
+		Streets:      []string{"Hauptstraße", "Schulstraße", "Gartenstraße", "Bahnhofstraße", "Dorfstraße", "Bergstraße", "Lindenstraße", "Kirchweg"},
+		Cities:       []string{"Berlin", "Hamburg", "München", "Köln", "Frankfurt am Main", "Stuttgart", "Düsseldorf", "Leipzig", "Dresden"},
+		States:       []string{"Bayern", "Berlin", "Hamburg", "Hessen", "Nordrhein-Westfalen", "Sachsen", "Baden-Württemberg"},
+		Country:      "Deutschland",
+		Currency:     "EUR",
+		StreetFormat: "%[2]s %[1]d",
+		PhoneFormat:  "+49 1## #######",
+		PostalFormat: "#####",
+		EmailDomains: []string{"example.de", "web.de", "test.de"},
+	},
+	"fr_FR": {
+		Code:         "fr_FR",
+		FirstNames:   []string{"Gabriel", "Louis", "Raphaël", "Jules", "Adam", "Jade", "Louise", "Emma", "Chloé", "Léa", "Hugo", "Camille"},
+		LastNames:    []string{"Martin", "Bernard", "Dubois", "Thomas", "Robert", "Richard", "Petit", "Durand", "Leroy", "Moreau", "Lefèvre", "Girard"},
+		Streets:      []string{"rue de la Paix", "rue Victor Hugo", "avenue Jean Jaurès", "rue de la République", "boulevard Voltaire", "rue Pasteur"},
+		Cities:       []string{"Paris", "Marseille", "Lyon", "Toulouse", "Nice", "Nantes", "Strasbourg", "Montpellier", "Bordeaux", "Lille"},
+		States:       []string{"Île-de-France", "Occitanie", "Bretagne", "Normandie", "Grand Est", "Nouvelle-Aquitaine"},
+		Country:      "France",
+		Currency:     "EUR",
+		StreetFormat: "%d %s",
+		PhoneFormat:  "+33 6 ## ## ## ##",
+		PostalFormat: "#####",
+		EmailDomains: []string{"example.fr", "orange.fr", "test.fr"},
+	},
+	"es_ES": {
+		Code:         "es_ES",
+		FirstNames:   []string{"Hugo", "Martín", "Lucas", "Mateo", "Leo", "Lucía", "Sofía", "Martina", "María", "Julia", "Pablo", "Carmen"},
+		LastNames:    []string{"García", "Rodríguez", "González", "Fernández", "López", "Martínez", "Sánchez", "Pérez", "Gómez", "Martín", "Ruiz", "Díaz"},
+		Streets:      []string{"Calle Mayor", "Calle Real", "Avenida de la Constitución", "Calle de Alcalá", "Gran Vía", "Paseo del Prado"},
+		Cities:       []string{"Madrid", "Barcelona", "Valencia", "Sevilla", "Zaragoza", "Málaga", "Bilbao", "Granada", "Alicante"},
+		States:       []string{"Madrid", "Cataluña", "Andalucía", "Comunidad Valenciana", "Galicia", "País Vasco"},
+		Country:      "España",
+		Currency:     "EUR",
+		StreetFormat: "%[2]s, %[1]d",
+		PhoneFormat:  "+34 6## ### ###",
+		PostalFormat: "#####",
+		EmailDomains: []string{"example.es", "correo.es", "test.es"},
+	},
+	"ja_JP": {
+		Code:         "ja_JP",
+		FirstNames:   []string{"Haruto", "Yuto", "Sota", "Riku", "Ren", "Yui", "Hina", "Sakura", "Aoi", "Mei", "Kenji", "Yuki"},
+		LastNames:    []string{"Sato", "Suzuki", "Takahashi", "Tanaka", "Watanabe", "Ito", "Yamamoto", "Nakamura", "Kobayashi", "Kato"},
+		Streets:      []string{"Chuo", "Shibuya", "Shinjuku", "Minato", "Ginza", "Ueno", "Asakusa", "Roppongi"},
+		Cities:       []string{"Tokyo", "Yokohama", "Osaka", "Nagoya", "Sapporo", "Fukuoka", "Kobe", "Kyoto", "Sendai"},
+		States:       []string{"Tokyo", "Osaka", "Kanagawa", "Aichi", "Hokkaido", "Fukuoka", "Kyoto"},
+		Country:      "Japan",
+		Currency:     "JPY",
+		StreetFormat: "%[2]s %[1]d-chome",
+		PhoneFormat:  "+81 90-####-####",
+		PostalFormat: "###-####",
+		EmailDomains: []string{"example.jp", "mail.jp", "test.jp"},
+	},
+}
+
+// localeAliases maps short or default codes to a full locale
+var localeAliases = map[string]string{
+	"en": "en_US",
+	"us": "en_US",
+	"gb": "en_GB",
+	"uk": "en_GB",
+	"de": "de_DE",
+	"fr": "fr_FR",
+	"es": "es_ES",
+	"ja": "ja_JP",
+	"jp": "ja_JP",
+}
+
+// LookupLocale resolves a locale code such as "de", "de_DE" or "de-DE"
+func LookupLocale(code string) (*Locale, error) {
+	norm := strings.ReplaceAll(strings.TrimSpace(code), "-", "_")
+	if parts := strings.SplitN(norm, "_", 2); len(parts) == 2 {
+		norm = strings.ToLower(parts[0]) + "_" + strings.ToUpper(parts[1])
+	} else {
+		norm = strings.ToLower(norm)
+	}
+
+	if alias, ok := localeAliases[norm]; ok {
+		norm = alias
+	}
+	if loc, ok := locales[norm]; ok {
+		return loc, nil
+	}
+	return nil, fmt.Errorf("unsupported locale %q (available: %s)", code, strings.Join(Locales(), ", "))
+}
+
+// Locales returns the supported locale codes
+func Locales() []string {
+	codes := make([]string, 0, len(locales))
+	for code := range locales {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// asciiFold transliterates accented characters so names can be used in
+// emails and usernames
+var asciiFold = strings.NewReplacer(
+	"ä", "ae", "ö", "oe", "ü", "ue", "ß", "ss",
+	"à", "a", "á", "a", "â", "a", "ç", "c", "è", "e", "é", "e", "ê", "e", "ë", "e",
+	"í", "i", "î", "i", "ï", "i", "ñ", "n", "ó", "o", "ô", "o", "ú", "u", "û", "u",
+)
@@ -3,6 +3,7 @@ package datagen
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
 )
 
@@ -18,6 +19,20 @@ func NewSchemaGenerator() *SchemaGenerator {
 	}
 }
 
+// NewSeededSchemaGenerator creates a schema-based generator with
+// reproducible output for the given seed
+func NewSeededSchemaGenerator(seed int64) *SchemaGenerator {
+	return &SchemaGenerator{
+		gen: NewSeededDataGenerator(seed),
+	}
+}
+
+// SetLocale switches generated names, addresses and phone formats to the
+// given locale
+func (sg *SchemaGenerator) SetLocale(code string) error {
+	return sg.gen.SetLocale(code)
+}
+
 // Schema represents a simplified JSON schema
 type Schema struct {
 	Type       string             `json:"type"`
@@ -72,8 +87,15 @@ func (sg *SchemaGenerator) GenerateFromSchema(schema *Schema, fieldName string)
 func (sg *SchemaGenerator) generateObject(schema *Schema) map[string]interface{} {
 	result := make(map[string]interface{})
 
-	for propName, propSchema := range schema.Properties {
-		result[propName] = sg.GenerateFromSchema(propSchema, propName)
+	// Visit properties in a stable order so seeded output is reproducible
+	names := make([]string, 0, len(schema.Properties))
+	for propName := range schema.Properties {
+		names = append(names, propName)
+	}
+	sort.Strings(names)
+
+	for _, propName := range names {
+		result[propName] = sg.GenerateFromSchema(schema.Properties[propName], propName)
 	}
 
 	return result
@@ -104,26 +126,28 @@ func (sg *SchemaGenerator) generateArray(schema *Schema, fieldName string) []int
 }
 
 func (sg *SchemaGenerator) generateString(schema *Schema, fieldName string) string {
-	// Check format first
-	switch schema.Format {
-	case "email":
-		return sg.gen.Email()
-	case "date":
-		return sg.gen.Date()
-	case "date-time":
-		return sg.gen.DateTime()
-	case "uri", "url":
-		return sg.gen.URL()
-	case "uuid":
-		return sg.gen.UUID()
-	case "phone":
-		return sg.gen.Phone()
-	case "password":
-		return sg.gen.Password()
+	// Check format first; formatted values are never resized
+	if v, ok := sg.gen.GenerateForFormat(schema.Format); ok {
+		return v
 	}
 
 	// Fall back to field name inference
-	return sg.gen.GenerateForType("string", fieldName).(string)
+	return sg.constrainLength(fmt.Sprint(sg.gen.GenerateForType("string", fieldName)), schema)
+}
+
+// constrainLength pads or truncates s to satisfy minLength/maxLength
+func (sg *SchemaGenerator) constrainLength(s string, schema *Schema) string {
+	if schema.MinLength != nil {
+		for len([]rune(s)) < *schema.MinLength {
+			s += sg.gen.Word()
+		}
+	}
+	if schema.MaxLength != nil {
+		if r := []rune(s); len(r) > *schema.MaxLength {
+			s = string(r[:*schema.MaxLength])
+		}
+	}
+	return s
 }
 
 func (sg *SchemaGenerator) generateNumber(schema *Schema, fieldName string) interface{} {
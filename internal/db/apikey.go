@@ -0,0 +1,143 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// API key scopes
+const (
+	ScopeRead  = "read"
+	ScopeWrite = "write"
+)
+
+// APIKey lets programs call the API on behalf of the user who created it,
+// within one organization. Only the key's hash is stored.
+type APIKey struct {
+	ID             uuid.UUID  `json:"id"`
+	OrganizationID uuid.UUID  `json:"organization_id"`
+	UserID         uuid.UUID  `json:"user_id"`
+	Name           string     `json:"name"`
+	KeyPrefix      string     `json:"key_prefix"` // identifies the key in listings
+	KeyHash        string     `json:"-"`
+	Scopes         []string   `json:"scopes"`
+	LastUsedAt     *time.Time `json:"last_used_at,omitempty"`
+	ExpiresAt      *time.Time `json:"expires_at,omitempty"`
+	IsActive       bool       `json:"is_active"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// HasScope reports whether the key was granted scope
+func (k *APIKey) HasScope(scope string) bool {
+	for _, s := range k.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// IsExpired checks if the key has expired
+func (k *APIKey) IsExpired() bool {
+	return k.ExpiresAt != nil && time.Now().After(*k.ExpiresAt)
+}
+
+const apiKeyColumns = `id, organization_id, user_id, name, key_prefix, key_hash, scopes, last_used_at, expires_at, is_active, created_at`
+
+func scanAPIKey(row interface{ Scan(...any) error }, k *APIKey) error {
+	return row.Scan(&k.ID, &k.OrganizationID, &k.UserID, &k.Name, &k.KeyPrefix, &k.KeyHash,
+		&k.Scopes, &k.LastUsedAt, &k.ExpiresAt, &k.IsActive, &k.CreatedAt)
+}
+
+// CreateAPIKey stores a new API key
+func (s *Store) CreateAPIKey(ctx context.Context, k *APIKey) error {
+	if k.Scopes == nil {
+		k.Scopes = []string{ScopeRead, ScopeWrite}
+	}
+	k.IsActive = true
+	err := s.pool.QueryRow(ctx, `
+		INSERT INTO api_keys (organization_id, user_id, name, key_prefix, key_hash, scopes, expires_at, is_active)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at
+	`, k.OrganizationID, k.UserID, k.Name, k.KeyPrefix, k.KeyHash, k.Scopes, k.ExpiresAt, k.IsActive).Scan(&k.ID, &k.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create api key: %w", err)
+	}
+	return nil
+}
+
+// GetAPIKeyByHash retrieves an API key by the hash of the full key, or nil
+// if there's none
+func (s *Store) GetAPIKeyByHash(ctx context.Context, hash string) (*APIKey, error) {
+	k := &APIKey{}
+	err := scanAPIKey(s.pool.QueryRow(ctx, `
+		SELECT `+apiKeyColumns+` FROM api_keys WHERE key_hash = $1
+	`, hash), k)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get api key: %w", err)
+	}
+	return k, nil
+}
+
+// HasAPIKeys reports whether any active API key exists
+func (s *Store) HasAPIKeys(ctx context.Context) (bool, error) {
+	var exists bool
+	err := s.pool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM api_keys WHERE is_active = true)`).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check api keys: %w", err)
+	}
+	return exists, nil
+}
+
+// ListAPIKeys lists an organization's active API keys, newest first
+func (s *Store) ListAPIKeys(ctx context.Context, orgID uuid.UUID) ([]APIKey, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT `+apiKeyColumns+`
+		FROM api_keys
+		WHERE organization_id = $1 AND is_active = true
+		ORDER BY created_at DESC
+	`, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []APIKey
+	for rows.Next() {
+		var k APIKey
+		if err := scanAPIKey(rows, &k); err != nil {
+			return nil, fmt.Errorf("failed to scan api key: %w", err)
+		}
+		keys = append(keys, k)
+	}
+	return keys, rows.Err()
+}
+
+// RevokeAPIKey deactivates an organization's API key, reporting whether it
+// was active
+func (s *Store) RevokeAPIKey(ctx context.Context, orgID, id uuid.UUID) (bool, error) {
+	tag, err := s.pool.Exec(ctx, `
+		UPDATE api_keys SET is_active = false
+		WHERE id = $1 AND organization_id = $2 AND is_active = true
+	`, id, orgID)
+	if err != nil {
+		return false, fmt.Errorf("failed to revoke api key: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// TouchAPIKey records that an API key was just used
+func (s *Store) TouchAPIKey(ctx context.Context, id uuid.UUID) error {
+	_, err := s.pool.Exec(ctx, `UPDATE api_keys SET last_used_at = $2 WHERE id = $1`, id, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to touch api key: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,237 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// GenerationAttempt is one test generated for a file, with the run that
+// generated it
+type GenerationAttempt struct {
+	TestID          uuid.UUID `json:"test_id"`
+	RunID           uuid.UUID `json:"run_id"`
+	RunStatus       string    `json:"run_status"`
+	Name            string    `json:"name"`
+	TargetFile      string    `json:"target_file"`
+	TargetFunction  *string   `json:"target_function,omitempty"`
+	TestFile        *string   `json:"test_file,omitempty"`
+	Framework       *string   `json:"framework,omitempty"`
+	Status          string    `json:"status"`
+	RejectionReason *string   `json:"rejection_reason,omitempty"`
+	MutationScore   *float64  `json:"mutation_score,omitempty"`
+	QualityScore    *float64  `json:"quality_score,omitempty"`
+	QualityGrade    *string   `json:"quality_grade,omitempty"`
+	GeneratedCode   *string   `json:"-"`              // served by GET /tests/{id}; the history carries diffs
+	Diff            string    `json:"diff,omitempty"` // from the previous attempt at the same test
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// FileHistory is every generation attempt for a source or test file,
+// newest first, with a summary for editor annotations
+type FileHistory struct {
+	RepositoryID uuid.UUID           `json:"repository_id"`
+	Path         string              `json:"path"`
+	Function     string              `json:"function,omitempty"`
+	Summary      FileHistorySummary  `json:"summary"`
+	Attempts     []GenerationAttempt `json:"attempts"`
+	MutationRuns []MutationRun       `json:"mutation_runs"`
+}
+
+// FileHistorySummary answers "when was this last generated and how good
+// are its tests"
+type FileHistorySummary struct {
+	Attempts        int        `json:"attempts"`
+	Accepted        int        `json:"accepted"`
+	Rejected        int        `json:"rejected"`
+	LastGeneratedAt *time.Time `json:"last_generated_at,omitempty"`
+	LastStatus      string     `json:"last_status,omitempty"`
+	MutationScore   *float64   `json:"mutation_score,omitempty"` // latest known, 0-1
+	QualityScore    *float64   `json:"quality_score,omitempty"`  // latest known
+	QualityGrade    *string    `json:"quality_grade,omitempty"`
+}
+
+// ListGenerationAttempts lists the tests generated for a repository file,
+// matched as either the source file under test or the test file written,
+// newest first. A non-empty function limits them to tests of that function.
+func (s *Store) ListGenerationAttempts(ctx context.Context, repoID uuid.UUID, path, function string, limit int) ([]GenerationAttempt, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT t.id, t.run_id, r.status, t.name, t.target_file, t.target_function, t.test_file, t.framework,
+		       t.status, t.rejection_reason, t.mutation_score, t.quality_score, t.quality_grade, t.generated_code, t.created_at
+		FROM generated_tests t
+		JOIN generation_runs r ON r.id = t.run_id
+		WHERE r.repository_id = $1 AND (t.target_file = $2 OR t.test_file = $2)
+		  AND ($3 = '' OR t.target_function = $3)
+		ORDER BY t.created_at DESC
+		LIMIT $4
+	`, repoID, path, function, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list generation attempts: %w", err)
+	}
+	defer rows.Close()
+
+	attempts := make([]GenerationAttempt, 0)
+	for rows.Next() {
+		var a GenerationAttempt
+		if err := rows.Scan(&a.TestID, &a.RunID, &a.RunStatus, &a.Name, &a.TargetFile, &a.TargetFunction, &a.TestFile, &a.Framework,
+			&a.Status, &a.RejectionReason, &a.MutationScore, &a.QualityScore, &a.QualityGrade, &a.GeneratedCode, &a.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan generation attempt: %w", err)
+		}
+		attempts = append(attempts, a)
+	}
+	return attempts, rows.Err()
+}
+
+// ListMutationRunsByFile lists a repository's mutation runs for a source or
+// test file, newest first
+func (s *Store) ListMutationRunsByFile(ctx context.Context, repoID uuid.UUID, path string, limit int) ([]MutationRun, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT id, job_id, repository_id, generation_run_id, source_file, test_file,
+			total_mutants, killed, survived, timeout, score, quality, report_data, report_file_path,
+			duration_ms, started_at, completed_at, created_at
+		FROM mutation_runs
+		WHERE repository_id = $1 AND (source_file = $2 OR test_file = $2)
+		ORDER BY created_at DESC
+		LIMIT $3
+	`, repoID, path, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list mutation runs: %w", err)
+	}
+	defer rows.Close()
+
+	runs := make([]MutationRun, 0)
+	for rows.Next() {
+		var run MutationRun
+		if err := rows.Scan(&run.ID, &run.JobID, &run.RepositoryID, &run.GenerationRunID, &run.SourceFile, &run.TestFile,
+			&run.TotalMutants, &run.Killed, &run.Survived, &run.Timeout, &run.Score, &run.Quality,
+			&run.ReportData, &run.ReportFilePath, &run.DurationMs, &run.StartedAt, &run.CompletedAt, &run.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan mutation run: %w", err)
+		}
+		runs = append(runs, run)
+	}
+	return runs, rows.Err()
+}
+
+// TestedTarget is a function a repository has a generated test for
+type TestedTarget struct {
+	File     string // as generated_tests.target_file records it
+	Function string
+}
+
+// ListTestedTargets lists the functions of a repository with a generated
+// test that wasn't rejected
+func (s *Store) ListTestedTargets(ctx context.Context, repoID uuid.UUID) ([]TestedTarget, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT DISTINCT t.target_file, t.target_function
+		FROM generated_tests t
+		JOIN generation_runs r ON r.id = t.run_id
+		WHERE r.repository_id = $1 AND t.status <> 'rejected' AND t.target_function IS NOT NULL
+	`, repoID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tested targets: %w", err)
+	}
+	defer rows.Close()
+
+	var targets []TestedTarget
+	for rows.Next() {
+		var t TestedTarget
+		if err := rows.Scan(&t.File, &t.Function); err != nil {
+			return nil, fmt.Errorf("failed to scan tested target: %w", err)
+		}
+		targets = append(targets, t)
+	}
+	return targets, rows.Err()
+}
+
+// NewFileHistory assembles a file's history from its attempts and mutation
+// runs, both newest first. Each attempt gets a diff from the previous code
+// generated for the same test file (or, failing that, the same function).
+func NewFileHistory(repoID uuid.UUID, path, function string, attempts []GenerationAttempt, mutationRuns []MutationRun) *FileHistory {
+	h := &FileHistory{
+		RepositoryID: repoID,
+		Path:         path,
+		Function:     function,
+		Attempts:     attempts,
+		MutationRuns: mutationRuns,
+	}
+	if h.Attempts == nil {
+		h.Attempts = []GenerationAttempt{}
+	}
+	if h.MutationRuns == nil {
+		h.MutationRuns = []MutationRun{}
+	}
+
+	// Walk oldest to newest so each attempt diffs against the one before it
+	previous := make(map[string]*string)
+	for i := len(h.Attempts) - 1; i >= 0; i-- {
+		a := &h.Attempts[i]
+		if a.GeneratedCode == nil {
+			continue
+		}
+		key := attemptKey(*a)
+		if prev, ok := previous[key]; ok {
+			a.Diff = unifiedDiff(*prev, *a.GeneratedCode, key)
+		}
+		previous[key] = a.GeneratedCode
+	}
+
+	sum := &h.Summary
+	sum.Attempts = len(h.Attempts)
+	for i := range h.Attempts {
+		a := &h.Attempts[i]
+		switch a.Status {
+		case "accepted":
+			sum.Accepted++
+		case "rejected":
+			sum.Rejected++
+		}
+		if i == 0 {
+			sum.LastGeneratedAt = &a.CreatedAt
+			sum.LastStatus = a.Status
+		}
+		if sum.MutationScore == nil && a.MutationScore != nil {
+			sum.MutationScore = a.MutationScore
+		}
+		if sum.QualityScore == nil && a.QualityScore != nil {
+			sum.QualityScore, sum.QualityGrade = a.QualityScore, a.QualityGrade
+		}
+	}
+
+	// A mutation run newer than the latest scored test is more current
+	if len(h.MutationRuns) > 0 {
+		run := &h.MutationRuns[0]
+		if run.TotalMutants > 0 && (sum.MutationScore == nil || sum.LastGeneratedAt == nil || run.CreatedAt.After(*sum.LastGeneratedAt)) {
+			sum.MutationScore = &run.Score
+		}
+	}
+	return h
+}
+
+// attemptKey groups attempts at the same test, to diff their code
+func attemptKey(a GenerationAttempt) string {
+	if a.TestFile != nil && *a.TestFile != "" {
+		return *a.TestFile
+	}
+	if a.TargetFunction != nil {
+		return a.TargetFile + ":" + *a.TargetFunction
+	}
+	return a.TargetFile
+}
+
+func unifiedDiff(a, b, name string) string {
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(strings.TrimSuffix(a, "\n") + "\n"),
+		B:        difflib.SplitLines(strings.TrimSuffix(b, "\n") + "\n"),
+		FromFile: "a/" + name,
+		ToFile:   "b/" + name,
+		Context:  3,
+	})
+	if err != nil {
+		return ""
+	}
+	return diff
+}
@@ -0,0 +1,113 @@
+package db
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func strPtr(s string) *string     { return &s }
+func floatPtr(f float64) *float64 { return &f }
+
+func TestNewFileHistory(t *testing.T) {
+	repoID := uuid.New()
+	base := time.Date(2026, 9, 1, 12, 0, 0, 0, time.UTC)
+
+	// Newest first, as the store returns them
+	attempts := []GenerationAttempt{
+		{
+			Name: "third", TargetFile: "src/users.go", TargetFunction: strPtr("GetUser"), TestFile: strPtr("src/users_test.go"),
+			Status: "pending", GeneratedCode: strPtr("package users\n\nfunc TestGetUser(t *testing.T) {\n\tcheck(2)\n}\n"),
+			CreatedAt: base.Add(72 * time.Hour),
+		},
+		{
+			Name: "other", TargetFile: "src/users.go", TargetFunction: strPtr("DeleteUser"),
+			Status: "rejected", GeneratedCode: strPtr("func TestDeleteUser() {}\n"),
+			CreatedAt: base.Add(48 * time.Hour),
+		},
+		{
+			Name: "second", TargetFile: "src/users.go", TargetFunction: strPtr("GetUser"), TestFile: strPtr("src/users_test.go"),
+			Status: "accepted", MutationScore: floatPtr(0.62), QualityScore: floatPtr(81), QualityGrade: strPtr("B"),
+			GeneratedCode: strPtr("package users\n\nfunc TestGetUser(t *testing.T) {\n\tcheck(1)\n}\n"),
+			CreatedAt:     base.Add(24 * time.Hour),
+		},
+		{
+			Name: "first", TargetFile: "src/users.go", TargetFunction: strPtr("GetUser"), TestFile: strPtr("src/users_test.go"),
+			Status: "rejected", MutationScore: floatPtr(0.3), GeneratedCode: strPtr("package users\n"),
+			CreatedAt: base,
+		},
+	}
+
+	h := NewFileHistory(repoID, "src/users.go", "", attempts, nil)
+
+	if h.RepositoryID != repoID || h.Path != "src/users.go" {
+		t.Errorf("RepositoryID, Path = %v, %q", h.RepositoryID, h.Path)
+	}
+	if h.MutationRuns == nil {
+		t.Error("MutationRuns = nil, want an empty list for JSON")
+	}
+
+	s := h.Summary
+	if s.Attempts != 4 || s.Accepted != 1 || s.Rejected != 2 {
+		t.Errorf("Attempts, Accepted, Rejected = %d, %d, %d", s.Attempts, s.Accepted, s.Rejected)
+	}
+	if s.LastGeneratedAt == nil || !s.LastGeneratedAt.Equal(base.Add(72*time.Hour)) || s.LastStatus != "pending" {
+		t.Errorf("LastGeneratedAt, LastStatus = %v, %q", s.LastGeneratedAt, s.LastStatus)
+	}
+	if s.MutationScore == nil || *s.MutationScore != 0.62 {
+		t.Errorf("MutationScore = %v, want the latest known 0.62", s.MutationScore)
+	}
+	if s.QualityScore == nil || *s.QualityScore != 81 || *s.QualityGrade != "B" {
+		t.Errorf("QualityScore, QualityGrade = %v, %v", s.QualityScore, s.QualityGrade)
+	}
+
+	// Each attempt diffs against the previous one for the same test file
+	if !strings.Contains(h.Attempts[0].Diff, "-\tcheck(1)") || !strings.Contains(h.Attempts[0].Diff, "+\tcheck(2)") {
+		t.Errorf("third attempt diff:\n%s", h.Attempts[0].Diff)
+	}
+	if !strings.Contains(h.Attempts[2].Diff, "--- a/src/users_test.go") || !strings.Contains(h.Attempts[2].Diff, "+func TestGetUser") {
+		t.Errorf("second attempt diff:\n%s", h.Attempts[2].Diff)
+	}
+	if h.Attempts[1].Diff != "" || h.Attempts[3].Diff != "" {
+		t.Error("first attempts at a test should have no diff")
+	}
+}
+
+func TestNewFileHistory_MutationRun(t *testing.T) {
+	generated := time.Date(2026, 9, 1, 12, 0, 0, 0, time.UTC)
+	attempts := []GenerationAttempt{{Status: "accepted", MutationScore: floatPtr(0.5), CreatedAt: generated}}
+
+	older := []MutationRun{{TotalMutants: 10, Score: 0.9, CreatedAt: generated.Add(-time.Hour)}}
+	if h := NewFileHistory(uuid.New(), "a.go", "", attempts, older); *h.Summary.MutationScore != 0.5 {
+		t.Errorf("MutationScore = %v, want the test's score over an older run", *h.Summary.MutationScore)
+	}
+
+	newer := []MutationRun{{TotalMutants: 10, Score: 0.7, CreatedAt: generated.Add(time.Hour)}}
+	if h := NewFileHistory(uuid.New(), "a.go", "", attempts, newer); *h.Summary.MutationScore != 0.7 {
+		t.Errorf("MutationScore = %v, want the newer run's score", *h.Summary.MutationScore)
+	}
+}
+
+func TestNewFileHistory_Empty(t *testing.T) {
+	h := NewFileHistory(uuid.New(), "a.go", "Run", nil, nil)
+	data, err := json.Marshal(h)
+	if err != nil {
+		t.Fatalf("Marshal() error: %v", err)
+	}
+	if !strings.Contains(string(data), `"attempts":[]`) || !strings.Contains(string(data), `"function":"Run"`) {
+		t.Errorf("JSON = %s", data)
+	}
+	if strings.Contains(string(data), "last_generated_at") {
+		t.Errorf("JSON = %s, want no last_generated_at without attempts", data)
+	}
+}
+
+func TestGenerationAttempt_JSONOmitsCode(t *testing.T) {
+	data, _ := json.Marshal(GenerationAttempt{GeneratedCode: strPtr("secret code"), Diff: "+x"})
+	if strings.Contains(string(data), "secret code") {
+		t.Errorf("JSON = %s, want generated code left out", data)
+	}
+}
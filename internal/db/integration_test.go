@@ -343,6 +343,63 @@ func TestIntegration_CreateAndListTests(t *testing.T) {
 	}
 }
 
+func TestIntegration_LatestTestForFile(t *testing.T) {
+	testDB := testutil.RequireDB(t)
+
+	db := &DB{pool: testDB.Pool}
+	store := NewStore(db)
+	ctx := context.Background()
+
+	repo := &Repository{
+		URL:           "https://github.com/test/test-file-repo",
+		Name:          "test-file-repo",
+		Owner:         "test",
+		DefaultBranch: "main",
+	}
+	if err := store.CreateRepository(ctx, repo); err != nil {
+		t.Fatalf("CreateRepository() error: %v", err)
+	}
+
+	testFile := "pkg/math_test.go"
+	if got, err := store.LatestTestForFile(ctx, repo.ID, testFile); err != nil || got != nil {
+		t.Fatalf("LatestTestForFile() = %+v, %v, want nil", got, err)
+	}
+
+	// Two runs wrote the file; the later one wins
+	for _, code := range []string{"package math // v1", "package math // v2"} {
+		run := &GenerationRun{RepositoryID: repo.ID}
+		if err := store.CreateGenerationRun(ctx, run); err != nil {
+			t.Fatalf("CreateGenerationRun() error: %v", err)
+		}
+		code, hash := code, "hash:"+code
+		test := &GeneratedTest{
+			RunID:         run.ID,
+			Name:          "TestAdd",
+			Type:          "unit",
+			TargetFile:    "pkg/math.go",
+			DSL:           json.RawMessage(`{}`),
+			GeneratedCode: &code,
+			TestFile:      &testFile,
+			ContentHash:   &hash,
+		}
+		if err := store.CreateGeneratedTest(ctx, test); err != nil {
+			t.Fatalf("CreateGeneratedTest() error: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	got, err := store.LatestTestForFile(ctx, repo.ID, testFile)
+	if err != nil {
+		t.Fatalf("LatestTestForFile() error: %v", err)
+	}
+	if got == nil || got.GeneratedCode == nil || *got.GeneratedCode != "package math // v2" {
+		t.Errorf("LatestTestForFile() = %+v, want v2", got)
+	}
+	if got != nil && (got.ContentHash == nil || *got.ContentHash != "hash:package math // v2") {
+		t.Errorf("ContentHash = %v, want hash of v2", got.ContentHash)
+	}
+}
+
 func TestIntegration_GetNonExistentRepository(t *testing.T) {
 	testDB := testutil.RequireDB(t)
 
@@ -764,3 +821,60 @@ func TestIntegration_MutationRunWithGenerationRun(t *testing.T) {
 		}
 	}
 }
+
+func TestIntegration_UntestableTargets(t *testing.T) {
+	testDB := testutil.RequireDB(t)
+
+	db := &DB{pool: testDB.Pool}
+	store := NewStore(db)
+	ctx := context.Background()
+
+	repo := &Repository{
+		URL:           "https://github.com/test/untestable-test",
+		Name:          "untestable-test",
+		Owner:         "test",
+		DefaultBranch: "main",
+	}
+	if err := store.CreateRepository(ctx, repo); err != nil {
+		t.Fatalf("CreateRepository() error: %v", err)
+	}
+
+	// Failures accumulate per function
+	var target *UntestableTarget
+	for i := 0; i < 3; i++ {
+		var err error
+		target, err = store.RecordTargetFailure(ctx, repo.ID, "service.go", "Sync", "no test generated")
+		if err != nil {
+			t.Fatalf("RecordTargetFailure() error: %v", err)
+		}
+	}
+	if target.FailureCount != 3 || target.Status != UntestableFailing {
+		t.Errorf("after 3 failures: count = %d, status = %s", target.FailureCount, target.Status)
+	}
+
+	if err := store.MarkTargetReported(ctx, target.ID, 12, "https://github.com/test/untestable-test/issues/12"); err != nil {
+		t.Fatalf("MarkTargetReported() error: %v", err)
+	}
+	listed, err := store.ListUntestableTargets(ctx, repo.ID, 3)
+	if err != nil {
+		t.Fatalf("ListUntestableTargets() error: %v", err)
+	}
+	if len(listed) != 1 || listed[0].IssueNumber == nil || *listed[0].IssueNumber != 12 {
+		t.Fatalf("ListUntestableTargets() = %+v, want the reported target", listed)
+	}
+
+	// A success resolves it; a later failure starts a new count without the old issue
+	if err := store.ResolveTarget(ctx, repo.ID, "service.go", "Sync"); err != nil {
+		t.Fatalf("ResolveTarget() error: %v", err)
+	}
+	if listed, _ := store.ListUntestableTargets(ctx, repo.ID, 0); len(listed) != 0 {
+		t.Errorf("resolved target still listed: %+v", listed)
+	}
+	target, err = store.RecordTargetFailure(ctx, repo.ID, "service.go", "Sync", "LLM completion failed")
+	if err != nil {
+		t.Fatalf("RecordTargetFailure() error: %v", err)
+	}
+	if target.FailureCount != 1 || target.Status != UntestableFailing || target.IssueNumber != nil {
+		t.Errorf("after resolve: count = %d, status = %s, issue = %v", target.FailureCount, target.Status, target.IssueNumber)
+	}
+}
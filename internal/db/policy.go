@@ -0,0 +1,84 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// RepositoryPolicies are the stored pipeline policies that apply to a
+// repository: its organization's defaults and its own overrides
+type RepositoryPolicies struct {
+	RepositoryID   uuid.UUID
+	OrganizationID *uuid.UUID
+	Organization   json.RawMessage // nil without an organization
+	Repository     json.RawMessage
+}
+
+// GetOrganizationPolicy retrieves an organization's default policy, or nil
+// if the organization doesn't exist
+func (s *Store) GetOrganizationPolicy(ctx context.Context, orgID uuid.UUID) (json.RawMessage, error) {
+	var policy json.RawMessage
+	err := s.pool.QueryRow(ctx, `
+		SELECT policy FROM organizations WHERE id = $1
+	`, orgID).Scan(&policy)
+
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get organization policy: %w", err)
+	}
+
+	return policy, nil
+}
+
+// UpdateOrganizationPolicy replaces an organization's default policy
+func (s *Store) UpdateOrganizationPolicy(ctx context.Context, orgID uuid.UUID, policy json.RawMessage) error {
+	_, err := s.pool.Exec(ctx, `
+		UPDATE organizations SET policy = $2, updated_at = NOW() WHERE id = $1
+	`, orgID, policy)
+
+	if err != nil {
+		return fmt.Errorf("failed to update organization policy: %w", err)
+	}
+
+	return nil
+}
+
+// GetRepositoryPolicies retrieves the policies that apply to a repository,
+// or nil if it doesn't exist
+func (s *Store) GetRepositoryPolicies(ctx context.Context, repoID uuid.UUID) (*RepositoryPolicies, error) {
+	p := &RepositoryPolicies{}
+	err := s.pool.QueryRow(ctx, `
+		SELECT r.id, r.organization_id, o.policy, r.policy
+		FROM repositories r
+		LEFT JOIN organizations o ON o.id = r.organization_id
+		WHERE r.id = $1
+	`, repoID).Scan(&p.RepositoryID, &p.OrganizationID, &p.Organization, &p.Repository)
+
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repository policies: %w", err)
+	}
+
+	return p, nil
+}
+
+// UpdateRepositoryPolicy replaces a repository's policy overrides
+func (s *Store) UpdateRepositoryPolicy(ctx context.Context, repoID uuid.UUID, policy json.RawMessage) error {
+	_, err := s.pool.Exec(ctx, `
+		UPDATE repositories SET policy = $2, updated_at = NOW() WHERE id = $1
+	`, repoID, policy)
+
+	if err != nil {
+		return fmt.Errorf("failed to update repository policy: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,182 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Outcomes of a schedule's last run
+const (
+	ScheduleQueued         = "queued"          // a pipeline was started
+	ScheduleAlreadyRunning = "already_running" // the repository's previous pipeline hadn't finished
+	ScheduleFailed         = "failed"          // the pipeline couldn't be started
+)
+
+// Schedule re-runs a repository's pipeline on a cron expression
+type Schedule struct {
+	ID           uuid.UUID       `json:"id"`
+	RepositoryID uuid.UUID       `json:"repository_id"`
+	Cron         string          `json:"cron"`
+	Timezone     string          `json:"timezone"`
+	Enabled      bool            `json:"enabled"`
+	Options      json.RawMessage `json:"options"` // pipeline options, as POST /jobs/pipeline takes them
+	NextRunAt    time.Time       `json:"next_run_at"`
+	LastRunAt    *time.Time      `json:"last_run_at,omitempty"`
+	LastJobID    *uuid.UUID      `json:"last_job_id,omitempty"`
+	LastOutcome  *string         `json:"last_outcome,omitempty"`
+	LastError    *string         `json:"last_error,omitempty"`
+	CreatedAt    time.Time       `json:"created_at"`
+	UpdatedAt    time.Time       `json:"updated_at"`
+}
+
+const scheduleColumns = `id, repository_id, cron, timezone, enabled, options, next_run_at,
+	last_run_at, last_job_id, last_outcome, last_error, created_at, updated_at`
+
+func scanSchedule(row interface{ Scan(...any) error }, s *Schedule) error {
+	return row.Scan(&s.ID, &s.RepositoryID, &s.Cron, &s.Timezone, &s.Enabled, &s.Options, &s.NextRunAt,
+		&s.LastRunAt, &s.LastJobID, &s.LastOutcome, &s.LastError, &s.CreatedAt, &s.UpdatedAt)
+}
+
+// CreateSchedule adds a schedule to a repository
+func (s *Store) CreateSchedule(ctx context.Context, sched *Schedule) error {
+	if sched.Options == nil {
+		sched.Options = json.RawMessage(`{}`)
+	}
+	err := s.pool.QueryRow(ctx, `
+		INSERT INTO repository_schedules (repository_id, cron, timezone, enabled, options, next_run_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at, updated_at
+	`, sched.RepositoryID, sched.Cron, sched.Timezone, sched.Enabled, sched.Options, sched.NextRunAt,
+	).Scan(&sched.ID, &sched.CreatedAt, &sched.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create schedule: %w", err)
+	}
+	return nil
+}
+
+// GetSchedule retrieves a repository's schedule, or nil if it doesn't exist
+func (s *Store) GetSchedule(ctx context.Context, repoID, id uuid.UUID) (*Schedule, error) {
+	sched := &Schedule{}
+	err := scanSchedule(s.pool.QueryRow(ctx, `
+		SELECT `+scheduleColumns+`
+		FROM repository_schedules
+		WHERE id = $1 AND repository_id = $2
+	`, id, repoID), sched)
+
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schedule: %w", err)
+	}
+	return sched, nil
+}
+
+// ListSchedules lists a repository's schedules, oldest first
+func (s *Store) ListSchedules(ctx context.Context, repoID uuid.UUID) ([]Schedule, error) {
+	return s.querySchedules(ctx, `
+		SELECT `+scheduleColumns+`
+		FROM repository_schedules
+		WHERE repository_id = $1
+		ORDER BY created_at
+	`, repoID)
+}
+
+// ListDueSchedules lists the enabled schedules due to run at now, most
+// overdue first
+func (s *Store) ListDueSchedules(ctx context.Context, now time.Time, limit int) ([]Schedule, error) {
+	return s.querySchedules(ctx, `
+		SELECT `+scheduleColumns+`
+		FROM repository_schedules
+		WHERE enabled AND next_run_at <= $1
+		ORDER BY next_run_at
+		LIMIT $2
+	`, now, limit)
+}
+
+func (s *Store) querySchedules(ctx context.Context, query string, args ...any) ([]Schedule, error) {
+	rows, err := s.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list schedules: %w", err)
+	}
+	defer rows.Close()
+
+	var schedules []Schedule
+	for rows.Next() {
+		var sched Schedule
+		if err := scanSchedule(rows, &sched); err != nil {
+			return nil, fmt.Errorf("failed to scan schedule: %w", err)
+		}
+		schedules = append(schedules, sched)
+	}
+	return schedules, rows.Err()
+}
+
+// UpdateSchedule replaces a schedule's expression, time zone, options and
+// whether it's enabled, and when it next runs, reporting whether it existed
+func (s *Store) UpdateSchedule(ctx context.Context, sched *Schedule) (bool, error) {
+	if sched.Options == nil {
+		sched.Options = json.RawMessage(`{}`)
+	}
+	err := s.pool.QueryRow(ctx, `
+		UPDATE repository_schedules
+		SET cron = $3, timezone = $4, enabled = $5, options = $6, next_run_at = $7, updated_at = NOW()
+		WHERE id = $1 AND repository_id = $2
+		RETURNING updated_at
+	`, sched.ID, sched.RepositoryID, sched.Cron, sched.Timezone, sched.Enabled, sched.Options, sched.NextRunAt,
+	).Scan(&sched.UpdatedAt)
+
+	if err == pgx.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to update schedule: %w", err)
+	}
+	return true, nil
+}
+
+// ClaimSchedule moves a due schedule on to its next run, reporting whether
+// this caller did so. When several API servers find the same schedule due,
+// only the one that claims it starts its pipeline.
+func (s *Store) ClaimSchedule(ctx context.Context, id uuid.UUID, due, next time.Time) (bool, error) {
+	tag, err := s.pool.Exec(ctx, `
+		UPDATE repository_schedules
+		SET next_run_at = $3, last_run_at = NOW()
+		WHERE id = $1 AND enabled AND next_run_at = $2
+	`, id, due, next)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim schedule: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// RecordScheduleRun records what a claimed run did: the pipeline it started
+// or found running, and the error if it failed
+func (s *Store) RecordScheduleRun(ctx context.Context, id uuid.UUID, outcome string, jobID *uuid.UUID, runErr *string) error {
+	_, err := s.pool.Exec(ctx, `
+		UPDATE repository_schedules
+		SET last_outcome = $2, last_job_id = $3, last_error = $4
+		WHERE id = $1
+	`, id, outcome, jobID, runErr)
+	if err != nil {
+		return fmt.Errorf("failed to record schedule run: %w", err)
+	}
+	return nil
+}
+
+// DeleteSchedule removes a repository's schedule, reporting whether it
+// existed
+func (s *Store) DeleteSchedule(ctx context.Context, repoID, id uuid.UUID) (bool, error) {
+	tag, err := s.pool.Exec(ctx, `
+		DELETE FROM repository_schedules WHERE id = $1 AND repository_id = $2
+	`, id, repoID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete schedule: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
@@ -74,10 +74,14 @@ type GeneratedTest struct {
 	TargetFunction  *string          `json:"target_function,omitempty"`
 	DSL             json.RawMessage  `json:"dsl"`
 	GeneratedCode   *string          `json:"generated_code,omitempty"`
+	TestFile        *string          `json:"test_file,omitempty"`    // relative to the repository root
+	ContentHash     *string          `json:"content_hash,omitempty"` // of GeneratedCode as written
 	Framework       *string          `json:"framework,omitempty"`
 	Status          string           `json:"status"`
 	RejectionReason *string          `json:"rejection_reason,omitempty"`
 	MutationScore   *float64         `json:"mutation_score,omitempty"`
+	StaticCheck     *string          `json:"static_check,omitempty"`        // passed, repaired, failed or skipped
+	StaticOutput    *string          `json:"static_check_output,omitempty"` // checker errors, when it failed
 	Metadata        *json.RawMessage `json:"metadata,omitempty"`
 	CreatedAt       time.Time        `json:"created_at"`
 	UpdatedAt       time.Time        `json:"updated_at"`
@@ -106,10 +110,10 @@ func (s *Store) CreateRepository(ctx context.Context, repo *Repository) error {
 func (s *Store) GetRepository(ctx context.Context, id uuid.UUID) (*Repository, error) {
 	repo := &Repository{}
 	err := s.pool.QueryRow(ctx, `
-		SELECT id, url, name, owner, default_branch, language, last_commit_sha, status, created_at, updated_at
+		SELECT id, url, name, owner, default_branch, language, last_commit_sha, status, organization_id, created_by, created_at, updated_at
 		FROM repositories WHERE id = $1
 	`, id).Scan(&repo.ID, &repo.URL, &repo.Name, &repo.Owner, &repo.DefaultBranch, &repo.Language,
-		&repo.LastCommitSHA, &repo.Status, &repo.CreatedAt, &repo.UpdatedAt)
+		&repo.LastCommitSHA, &repo.Status, &repo.OrganizationID, &repo.CreatedBy, &repo.CreatedAt, &repo.UpdatedAt)
 
 	if err == pgx.ErrNoRows {
 		return nil, nil
@@ -125,10 +129,10 @@ func (s *Store) GetRepository(ctx context.Context, id uuid.UUID) (*Repository, e
 func (s *Store) GetRepositoryByURL(ctx context.Context, url string) (*Repository, error) {
 	repo := &Repository{}
 	err := s.pool.QueryRow(ctx, `
-		SELECT id, url, name, owner, default_branch, language, last_commit_sha, status, created_at, updated_at
+		SELECT id, url, name, owner, default_branch, language, last_commit_sha, status, organization_id, created_by, created_at, updated_at
 		FROM repositories WHERE url = $1
 	`, url).Scan(&repo.ID, &repo.URL, &repo.Name, &repo.Owner, &repo.DefaultBranch, &repo.Language,
-		&repo.LastCommitSHA, &repo.Status, &repo.CreatedAt, &repo.UpdatedAt)
+		&repo.LastCommitSHA, &repo.Status, &repo.OrganizationID, &repo.CreatedBy, &repo.CreatedAt, &repo.UpdatedAt)
 
 	if err == pgx.ErrNoRows {
 		return nil, nil
@@ -277,28 +281,79 @@ func (s *Store) UpdateGenerationRunStatus(ctx context.Context, id uuid.UUID, sta
 	return err
 }
 
-// CreateGeneratedTest creates a new generated test
+// UpdateGenerationRunSummary replaces the value under key in a run's
+// summary with what update returns for the current one (nil when unset).
+// The run is locked meanwhile, so jobs of the same run updating the summary
+// at once don't lose each other's changes.
+func (s *Store) UpdateGenerationRunSummary(ctx context.Context, id uuid.UUID, key string, update func(current json.RawMessage) (json.RawMessage, error)) error {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var raw []byte
+	err = tx.QueryRow(ctx, `SELECT summary FROM generation_runs WHERE id = $1 FOR UPDATE`, id).Scan(&raw)
+	if err == pgx.ErrNoRows {
+		return fmt.Errorf("run %s not found", id)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get run summary: %w", err)
+	}
+
+	summary := make(map[string]json.RawMessage)
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &summary); err != nil {
+			return fmt.Errorf("failed to parse run summary: %w", err)
+		}
+	}
+	value, err := update(summary[key])
+	if err != nil {
+		return err
+	}
+	summary[key] = value
+
+	data, err := json.Marshal(summary)
+	if err != nil {
+		return fmt.Errorf("failed to marshal run summary: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `UPDATE generation_runs SET summary = $2 WHERE id = $1`, id, data); err != nil {
+		return fmt.Errorf("failed to update run summary: %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+// CreateGeneratedTest creates a new generated test, pending unless it has
+// a status
 func (s *Store) CreateGeneratedTest(ctx context.Context, test *GeneratedTest) error {
 	test.ID = uuid.New()
-	test.Status = "pending"
+	if test.Status == "" {
+		test.Status = "pending"
+	}
 	test.CreatedAt = time.Now()
 	test.UpdatedAt = time.Now()
 
 	_, err := s.pool.Exec(ctx, `
-		INSERT INTO generated_tests (id, run_id, name, type, target_file, target_function, dsl, framework, status, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		INSERT INTO generated_tests (id, run_id, name, type, target_file, target_function, dsl, generated_code,
+		                             test_file, content_hash, framework, status, rejection_reason, static_check,
+		                             static_check_output, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
 	`, test.ID, test.RunID, test.Name, test.Type, test.TargetFile, test.TargetFunction,
-		test.DSL, test.Framework, test.Status, test.CreatedAt, test.UpdatedAt)
+		test.DSL, test.GeneratedCode, test.TestFile, test.ContentHash, test.Framework, test.Status, test.RejectionReason,
+		test.StaticCheck, test.StaticOutput, test.CreatedAt, test.UpdatedAt)
 
 	return err
 }
 
 // ListTestsByRun lists all tests for a run
-// ListTests returns all tests with optional filtering
-func (s *Store) ListTests(ctx context.Context, runID *uuid.UUID, status string, limit int) ([]GeneratedTest, error) {
+// ListTests returns all tests with optional filtering by status and static
+// check outcome
+func (s *Store) ListTests(ctx context.Context, runID *uuid.UUID, status, staticCheck string, limit int) ([]GeneratedTest, error) {
 	query := `
-		SELECT id, run_id, name, type, target_file, target_function, dsl, generated_code,
-		       framework, status, rejection_reason, mutation_score, metadata, created_at, updated_at
+		SELECT id, run_id, name, type, target_file, target_function, dsl, generated_code, test_file, content_hash,
+		       framework, status, rejection_reason, mutation_score, static_check, static_check_output, metadata,
+		       created_at, updated_at
 		FROM generated_tests
 		WHERE 1=1`
 	args := make([]interface{}, 0)
@@ -314,6 +369,11 @@ func (s *Store) ListTests(ctx context.Context, runID *uuid.UUID, status string,
 		args = append(args, status)
 		argNum++
 	}
+	if staticCheck != "" {
+		query += fmt.Sprintf(" AND static_check = $%d", argNum)
+		args = append(args, staticCheck)
+		argNum++
+	}
 	query += " ORDER BY created_at DESC"
 	if limit > 0 {
 		query += fmt.Sprintf(" LIMIT $%d", argNum)
@@ -330,8 +390,8 @@ func (s *Store) ListTests(ctx context.Context, runID *uuid.UUID, status string,
 	for rows.Next() {
 		var test GeneratedTest
 		if err := rows.Scan(&test.ID, &test.RunID, &test.Name, &test.Type, &test.TargetFile,
-			&test.TargetFunction, &test.DSL, &test.GeneratedCode, &test.Framework, &test.Status,
-			&test.RejectionReason, &test.MutationScore, &test.Metadata, &test.CreatedAt, &test.UpdatedAt); err != nil {
+			&test.TargetFunction, &test.DSL, &test.GeneratedCode, &test.TestFile, &test.ContentHash, &test.Framework, &test.Status,
+			&test.RejectionReason, &test.MutationScore, &test.StaticCheck, &test.StaticOutput, &test.Metadata, &test.CreatedAt, &test.UpdatedAt); err != nil {
 			return nil, fmt.Errorf("failed to scan test: %w", err)
 		}
 		tests = append(tests, test)
@@ -342,8 +402,9 @@ func (s *Store) ListTests(ctx context.Context, runID *uuid.UUID, status string,
 
 func (s *Store) ListTestsByRun(ctx context.Context, runID uuid.UUID) ([]GeneratedTest, error) {
 	rows, err := s.pool.Query(ctx, `
-		SELECT id, run_id, name, type, target_file, target_function, dsl, generated_code,
-		       framework, status, rejection_reason, mutation_score, metadata, created_at, updated_at
+		SELECT id, run_id, name, type, target_file, target_function, dsl, generated_code, test_file, content_hash,
+		       framework, status, rejection_reason, mutation_score, static_check, static_check_output, metadata,
+		       created_at, updated_at
 		FROM generated_tests
 		WHERE run_id = $1
 		ORDER BY created_at
@@ -357,8 +418,8 @@ func (s *Store) ListTestsByRun(ctx context.Context, runID uuid.UUID) ([]Generate
 	for rows.Next() {
 		var test GeneratedTest
 		if err := rows.Scan(&test.ID, &test.RunID, &test.Name, &test.Type, &test.TargetFile,
-			&test.TargetFunction, &test.DSL, &test.GeneratedCode, &test.Framework, &test.Status,
-			&test.RejectionReason, &test.MutationScore, &test.Metadata, &test.CreatedAt, &test.UpdatedAt); err != nil {
+			&test.TargetFunction, &test.DSL, &test.GeneratedCode, &test.TestFile, &test.ContentHash, &test.Framework, &test.Status,
+			&test.RejectionReason, &test.MutationScore, &test.StaticCheck, &test.StaticOutput, &test.Metadata, &test.CreatedAt, &test.UpdatedAt); err != nil {
 			return nil, fmt.Errorf("failed to scan test: %w", err)
 		}
 		tests = append(tests, test)
@@ -386,12 +447,13 @@ func (s *Store) DeleteRepository(ctx context.Context, id uuid.UUID) error {
 func (s *Store) GetTest(ctx context.Context, id uuid.UUID) (*GeneratedTest, error) {
 	test := &GeneratedTest{}
 	err := s.pool.QueryRow(ctx, `
-		SELECT id, run_id, name, type, target_file, target_function, dsl, generated_code,
-		       framework, status, rejection_reason, mutation_score, metadata, created_at, updated_at
+		SELECT id, run_id, name, type, target_file, target_function, dsl, generated_code, test_file, content_hash,
+		       framework, status, rejection_reason, mutation_score, static_check, static_check_output, metadata,
+		       created_at, updated_at
 		FROM generated_tests WHERE id = $1
 	`, id).Scan(&test.ID, &test.RunID, &test.Name, &test.Type, &test.TargetFile,
-		&test.TargetFunction, &test.DSL, &test.GeneratedCode, &test.Framework, &test.Status,
-		&test.RejectionReason, &test.MutationScore, &test.Metadata, &test.CreatedAt, &test.UpdatedAt)
+		&test.TargetFunction, &test.DSL, &test.GeneratedCode, &test.TestFile, &test.ContentHash, &test.Framework, &test.Status,
+		&test.RejectionReason, &test.MutationScore, &test.StaticCheck, &test.StaticOutput, &test.Metadata, &test.CreatedAt, &test.UpdatedAt)
 
 	if err == pgx.ErrNoRows {
 		return nil, nil
@@ -403,6 +465,33 @@ func (s *Store) GetTest(ctx context.Context, id uuid.UUID) (*GeneratedTest, erro
 	return test, nil
 }
 
+// LatestTestForFile returns the test QTest last wrote to a test file of a
+// repository, or nil if it never wrote one
+func (s *Store) LatestTestForFile(ctx context.Context, repoID uuid.UUID, testFile string) (*GeneratedTest, error) {
+	test := &GeneratedTest{}
+	err := s.pool.QueryRow(ctx, `
+		SELECT t.id, t.run_id, t.name, t.type, t.target_file, t.target_function, t.dsl, t.generated_code, t.test_file, t.content_hash,
+		       t.framework, t.status, t.rejection_reason, t.mutation_score, t.static_check, t.static_check_output, t.metadata,
+		       t.created_at, t.updated_at
+		FROM generated_tests t
+		JOIN generation_runs r ON r.id = t.run_id
+		WHERE r.repository_id = $1 AND t.test_file = $2 AND t.generated_code IS NOT NULL
+		ORDER BY t.created_at DESC
+		LIMIT 1
+	`, repoID, testFile).Scan(&test.ID, &test.RunID, &test.Name, &test.Type, &test.TargetFile,
+		&test.TargetFunction, &test.DSL, &test.GeneratedCode, &test.TestFile, &test.ContentHash, &test.Framework, &test.Status,
+		&test.RejectionReason, &test.MutationScore, &test.StaticCheck, &test.StaticOutput, &test.Metadata, &test.CreatedAt, &test.UpdatedAt)
+
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get test for file: %w", err)
+	}
+
+	return test, nil
+}
+
 // UpdateTestStatus updates the status of a generated test
 func (s *Store) UpdateTestStatus(ctx context.Context, id uuid.UUID, status string, rejectionReason *string) error {
 	_, err := s.pool.Exec(ctx, `
@@ -750,6 +839,34 @@ func (s *Store) CreateRepositoryForOrg(ctx context.Context, repo *Repository, or
 	return nil
 }
 
+// ListRepositoriesOutsideOrganizations lists repositories without an
+// organization
+func (s *Store) ListRepositoriesOutsideOrganizations(ctx context.Context, limit, offset int) ([]Repository, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT id, url, name, owner, default_branch, language, last_commit_sha, status, created_at, updated_at
+		FROM repositories
+		WHERE organization_id IS NULL
+		ORDER BY created_at DESC
+		LIMIT $1 OFFSET $2
+	`, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list repositories: %w", err)
+	}
+	defer rows.Close()
+
+	repos := make([]Repository, 0)
+	for rows.Next() {
+		var repo Repository
+		if err := rows.Scan(&repo.ID, &repo.URL, &repo.Name, &repo.Owner, &repo.DefaultBranch,
+			&repo.Language, &repo.LastCommitSHA, &repo.Status, &repo.CreatedAt, &repo.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan repository: %w", err)
+		}
+		repos = append(repos, repo)
+	}
+
+	return repos, nil
+}
+
 // ListRepositoriesByOrg lists repositories for an organization
 func (s *Store) ListRepositoriesByOrg(ctx context.Context, orgID uuid.UUID, limit, offset int) ([]Repository, error) {
 	rows, err := s.pool.Query(ctx, `
@@ -0,0 +1,128 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Untestable target statuses
+const (
+	UntestableFailing  = "failing"
+	UntestableReported = "reported"
+	UntestableResolved = "resolved"
+)
+
+// UntestableTarget is a function test generation keeps failing for
+type UntestableTarget struct {
+	ID            uuid.UUID       `json:"id"`
+	RepositoryID  uuid.UUID       `json:"repository_id"`
+	FilePath      string          `json:"file_path"`
+	FunctionName  string          `json:"function_name"`
+	FailureCount  int             `json:"failure_count"`
+	LastReason    *string         `json:"last_reason,omitempty"`
+	Findings      json.RawMessage `json:"findings,omitempty"`
+	Status        string          `json:"status"`
+	IssueNumber   *int            `json:"issue_number,omitempty"`
+	IssueURL      *string         `json:"issue_url,omitempty"`
+	FirstFailedAt time.Time       `json:"first_failed_at"`
+	LastFailedAt  time.Time       `json:"last_failed_at"`
+	UpdatedAt     time.Time       `json:"updated_at"`
+}
+
+const untestableColumns = `id, repository_id, file_path, function_name, failure_count, last_reason, findings,
+	status, issue_number, issue_url, first_failed_at, last_failed_at, updated_at`
+
+func scanUntestable(row interface{ Scan(...any) error }, t *UntestableTarget) error {
+	return row.Scan(&t.ID, &t.RepositoryID, &t.FilePath, &t.FunctionName, &t.FailureCount, &t.LastReason, &t.Findings,
+		&t.Status, &t.IssueNumber, &t.IssueURL, &t.FirstFailedAt, &t.LastFailedAt, &t.UpdatedAt)
+}
+
+// RecordTargetFailure counts a failed generation for a function and returns
+// the updated record. A resolved target that fails again starts over.
+func (s *Store) RecordTargetFailure(ctx context.Context, repoID uuid.UUID, filePath, function, reason string) (*UntestableTarget, error) {
+	t := &UntestableTarget{}
+	err := scanUntestable(s.pool.QueryRow(ctx, `
+		INSERT INTO untestable_targets (repository_id, file_path, function_name, failure_count, last_reason)
+		VALUES ($1, $2, $3, 1, $4)
+		ON CONFLICT (repository_id, file_path, function_name) DO UPDATE SET
+			failure_count = untestable_targets.failure_count + 1,
+			last_reason = EXCLUDED.last_reason,
+			status = CASE WHEN untestable_targets.status = 'resolved' THEN 'failing' ELSE untestable_targets.status END,
+			issue_number = CASE WHEN untestable_targets.status = 'resolved' THEN NULL ELSE untestable_targets.issue_number END,
+			issue_url = CASE WHEN untestable_targets.status = 'resolved' THEN NULL ELSE untestable_targets.issue_url END,
+			last_failed_at = NOW(),
+			updated_at = NOW()
+		RETURNING `+untestableColumns,
+		repoID, filePath, function, reason), t)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record target failure: %w", err)
+	}
+	return t, nil
+}
+
+// ResolveTarget marks a function as testable again after tests were
+// generated for it
+func (s *Store) ResolveTarget(ctx context.Context, repoID uuid.UUID, filePath, function string) error {
+	_, err := s.pool.Exec(ctx, `
+		UPDATE untestable_targets
+		SET failure_count = 0, status = 'resolved', updated_at = NOW()
+		WHERE repository_id = $1 AND file_path = $2 AND function_name = $3 AND status != 'resolved'
+	`, repoID, filePath, function)
+	if err != nil {
+		return fmt.Errorf("failed to resolve target: %w", err)
+	}
+	return nil
+}
+
+// UpdateTargetFindings stores the testability issues found for a target
+func (s *Store) UpdateTargetFindings(ctx context.Context, id uuid.UUID, findings json.RawMessage) error {
+	_, err := s.pool.Exec(ctx, `
+		UPDATE untestable_targets SET findings = $2, updated_at = NOW() WHERE id = $1
+	`, id, findings)
+	if err != nil {
+		return fmt.Errorf("failed to update target findings: %w", err)
+	}
+	return nil
+}
+
+// MarkTargetReported records the issue opened for a target
+func (s *Store) MarkTargetReported(ctx context.Context, id uuid.UUID, issueNumber int, issueURL string) error {
+	_, err := s.pool.Exec(ctx, `
+		UPDATE untestable_targets
+		SET status = 'reported', issue_number = $2, issue_url = $3, updated_at = NOW()
+		WHERE id = $1
+	`, id, issueNumber, issueURL)
+	if err != nil {
+		return fmt.Errorf("failed to mark target reported: %w", err)
+	}
+	return nil
+}
+
+// ListUntestableTargets lists a repository's unresolved targets that failed
+// at least minFailures times, most failures first
+func (s *Store) ListUntestableTargets(ctx context.Context, repoID uuid.UUID, minFailures int) ([]UntestableTarget, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT `+untestableColumns+`
+		FROM untestable_targets
+		WHERE repository_id = $1 AND status != 'resolved' AND failure_count >= $2
+		ORDER BY failure_count DESC, last_failed_at DESC
+	`, repoID, minFailures)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list untestable targets: %w", err)
+	}
+	defer rows.Close()
+
+	var targets []UntestableTarget
+	for rows.Next() {
+		var t UntestableTarget
+		if err := scanUntestable(rows, &t); err != nil {
+			return nil, fmt.Errorf("failed to scan untestable target: %w", err)
+		}
+		targets = append(targets, t)
+	}
+	return targets, rows.Err()
+}
@@ -12,7 +12,7 @@ import (
 // User represents a user account
 type User struct {
 	ID          uuid.UUID  `json:"id"`
-	GitHubID    int64      `json:"github_id"`
+	GitHubID    *int64     `json:"github_id,omitempty"` // nil for OIDC users
 	GitHubLogin string     `json:"github_login"`
 	Email       *string    `json:"email,omitempty"`
 	Name        *string    `json:"name,omitempty"`
@@ -134,7 +134,7 @@ func (s *Store) UpsertUserFromGitHub(ctx context.Context, githubID int64, login,
 
 	// Create new user
 	user := &User{
-		GitHubID:    githubID,
+		GitHubID:    &githubID,
 		GitHubLogin: login,
 		Email:       emailPtr,
 		Name:        namePtr,
@@ -148,6 +148,77 @@ func (s *Store) UpsertUserFromGitHub(ctx context.Context, githubID int64, login,
 	return user, nil
 }
 
+// GetUserByOIDC retrieves a user by the issuer and subject of their OIDC
+// identity
+func (s *Store) GetUserByOIDC(ctx context.Context, issuer, subject string) (*User, error) {
+	user := &User{}
+	err := s.pool.QueryRow(ctx, `
+		SELECT id, github_id, github_login, email, name, avatar_url, is_active, created_at, updated_at
+		FROM users
+		WHERE oidc_issuer = $1 AND oidc_subject = $2
+	`, issuer, subject).Scan(&user.ID, &user.GitHubID, &user.GitHubLogin, &user.Email, &user.Name, &user.AvatarURL, &user.IsActive, &user.CreatedAt, &user.UpdatedAt)
+
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user by oidc subject: %w", err)
+	}
+
+	return user, nil
+}
+
+// UpsertUserFromOIDC creates or updates a user from an OIDC token's claims.
+// The login is kept in github_login; new users get a personal organization.
+func (s *Store) UpsertUserFromOIDC(ctx context.Context, issuer, subject, login, email, name string) (*User, error) {
+	var emailPtr, namePtr *string
+	if email != "" {
+		emailPtr = &email
+	}
+	if name != "" {
+		namePtr = &name
+	}
+
+	existing, err := s.GetUserByOIDC(ctx, issuer, subject)
+	if err != nil {
+		return nil, err
+	}
+
+	if existing != nil {
+		_, err := s.pool.Exec(ctx, `
+			UPDATE users
+			SET github_login = $2, email = $3, name = $4, updated_at = $5
+			WHERE id = $1
+		`, existing.ID, login, emailPtr, namePtr, time.Now())
+		if err != nil {
+			return nil, fmt.Errorf("failed to update user: %w", err)
+		}
+		return s.GetUserByID(ctx, existing.ID)
+	}
+
+	user := &User{
+		ID:          uuid.New(),
+		GitHubLogin: login,
+		Email:       emailPtr,
+		Name:        namePtr,
+		IsActive:    true,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+
+	_, err = s.pool.Exec(ctx, `
+		INSERT INTO users (id, github_login, email, name, is_active, oidc_issuer, oidc_subject, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (oidc_issuer, oidc_subject) WHERE oidc_subject IS NOT NULL DO NOTHING
+	`, user.ID, user.GitHubLogin, user.Email, user.Name, user.IsActive, issuer, subject, user.CreatedAt, user.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+
+	// Another request may have created them first
+	return s.GetUserByOIDC(ctx, issuer, subject)
+}
+
 // UpdateUser updates a user
 func (s *Store) UpdateUser(ctx context.Context, user *User) error {
 	user.UpdatedAt = time.Now()
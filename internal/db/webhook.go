@@ -0,0 +1,76 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Webhook is a URL notified when a repository's jobs complete or fail
+type Webhook struct {
+	ID           uuid.UUID `json:"id"`
+	RepositoryID uuid.UUID `json:"repository_id"`
+	URL          string    `json:"url"`
+	Secret       string    `json:"-"`                // signs deliveries; only shown when created
+	Events       []string  `json:"events,omitempty"` // e.g. generation.completed; empty for all
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+const webhookColumns = `id, repository_id, url, secret, events, created_at`
+
+func scanWebhook(row interface{ Scan(...any) error }, h *Webhook) error {
+	return row.Scan(&h.ID, &h.RepositoryID, &h.URL, &h.Secret, &h.Events, &h.CreatedAt)
+}
+
+// CreateWebhook registers a webhook for a repository
+func (s *Store) CreateWebhook(ctx context.Context, h *Webhook) error {
+	if h.Events == nil {
+		h.Events = []string{}
+	}
+	err := s.pool.QueryRow(ctx, `
+		INSERT INTO repository_webhooks (repository_id, url, secret, events)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+	`, h.RepositoryID, h.URL, h.Secret, h.Events).Scan(&h.ID, &h.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create webhook: %w", err)
+	}
+	return nil
+}
+
+// ListWebhooks lists a repository's webhooks, oldest first
+func (s *Store) ListWebhooks(ctx context.Context, repoID uuid.UUID) ([]Webhook, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT `+webhookColumns+`
+		FROM repository_webhooks
+		WHERE repository_id = $1
+		ORDER BY created_at
+	`, repoID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+	defer rows.Close()
+
+	var hooks []Webhook
+	for rows.Next() {
+		var h Webhook
+		if err := scanWebhook(rows, &h); err != nil {
+			return nil, fmt.Errorf("failed to scan webhook: %w", err)
+		}
+		hooks = append(hooks, h)
+	}
+	return hooks, rows.Err()
+}
+
+// DeleteWebhook removes a repository's webhook, reporting whether it existed
+func (s *Store) DeleteWebhook(ctx context.Context, repoID, id uuid.UUID) (bool, error) {
+	tag, err := s.pool.Exec(ctx, `
+		DELETE FROM repository_webhooks WHERE id = $1 AND repository_id = $2
+	`, id, repoID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete webhook: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
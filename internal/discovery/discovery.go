@@ -0,0 +1,242 @@
+// Package discovery finds HTTP routes at runtime. Static supplements miss
+// routes registered dynamically (loops over config, plugins, generated
+// routers), so discovery boots the service in a sandboxed container and
+// asks it for its route table.
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/QTest-hq/qtest/pkg/model"
+	"github.com/rs/zerolog/log"
+)
+
+// SourceRuntime marks endpoints found by runtime discovery
+const SourceRuntime = "runtime"
+
+// Defaults for Config
+const (
+	DefaultStartupTimeout = 60 * time.Second
+	defaultPollInterval   = 500 * time.Millisecond
+)
+
+// Config describes how to boot a service for route discovery
+type Config struct {
+	Image          string            // container image with the service's toolchain
+	Command        string            // command that starts the service, run with sh -c
+	Port           int               // port the service listens on inside the container
+	Env            map[string]string // extra environment variables
+	StartupTimeout time.Duration     // how long to wait for the service to answer
+	Frameworks     []string          // frameworks to scrape routes for; all if empty
+}
+
+// Validate checks the config has what's needed to boot the service
+func (c *Config) Validate() error {
+	if c.Image == "" {
+		return fmt.Errorf("discovery image is required")
+	}
+	if c.Command == "" {
+		return fmt.Errorf("discovery command is required")
+	}
+	if c.Port <= 0 || c.Port > 65535 {
+		return fmt.Errorf("discovery port %d is invalid", c.Port)
+	}
+	return nil
+}
+
+// Result holds the routes a running service reported
+type Result struct {
+	Endpoints []model.Endpoint
+	Sources   []string // scrapers that found routes, e.g. "openapi"
+}
+
+// Discoverer boots services and scrapes their routes
+type Discoverer struct {
+	runtime      Runtime
+	client       *http.Client
+	pollInterval time.Duration
+}
+
+// NewDiscoverer creates a discoverer using the given container runtime
+func NewDiscoverer(runtime Runtime) *Discoverer {
+	return &Discoverer{
+		runtime:      runtime,
+		client:       &http.Client{Timeout: 5 * time.Second},
+		pollInterval: defaultPollInterval,
+	}
+}
+
+// Discover boots the service in repoPath, waits for it to answer and
+// scrapes its route table with every applicable scraper. The container is
+// always stopped before returning.
+func (d *Discoverer) Discover(ctx context.Context, repoPath string, cfg Config) (*Result, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if cfg.StartupTimeout == 0 {
+		cfg.StartupTimeout = DefaultStartupTimeout
+	}
+
+	scrapers := scrapersFor(cfg.Frameworks)
+	spec := ContainerSpec{
+		Image:   cfg.Image,
+		Command: cfg.Command,
+		Port:    cfg.Port,
+		Mount:   repoPath,
+		Env:     make(map[string]string),
+		Files:   make(map[string]string),
+	}
+	for k, v := range cfg.Env {
+		spec.Env[k] = v
+	}
+	for _, s := range scrapers {
+		s.prepare(&spec)
+	}
+
+	container, err := d.runtime.Start(ctx, spec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start service: %w", err)
+	}
+	defer func() {
+		stopCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := container.Stop(stopCtx); err != nil {
+			log.Warn().Err(err).Msg("failed to stop discovery container")
+		}
+	}()
+
+	if err := d.waitReady(ctx, container, cfg.StartupTimeout); err != nil {
+		logs, _ := container.Logs(ctx)
+		return nil, fmt.Errorf("%w\n%s", err, tail(logs, 20))
+	}
+
+	logs, err := container.Logs(ctx)
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to read discovery container logs")
+	}
+
+	result := &Result{}
+	seen := make(map[string]bool)
+	for _, s := range scrapers {
+		endpoints, err := s.scrape(ctx, d.client, container.BaseURL(), logs)
+		if err != nil {
+			log.Debug().Err(err).Str("scraper", s.name()).Msg("route scraper found nothing")
+			continue
+		}
+		if len(endpoints) == 0 {
+			continue
+		}
+		result.Sources = append(result.Sources, s.name())
+		for _, ep := range endpoints {
+			key := endpointKey(ep.Method, ep.Path)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			ep.Source = SourceRuntime
+			result.Endpoints = append(result.Endpoints, ep)
+		}
+	}
+	return result, nil
+}
+
+// waitReady polls the service until it answers any HTTP request or the
+// container exits
+func (d *Discoverer) waitReady(ctx context.Context, c Container, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(d.pollInterval)
+	defer ticker.Stop()
+	for {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.BaseURL()+"/", nil)
+		if err != nil {
+			return err
+		}
+		if resp, err := d.client.Do(req); err == nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+			return nil
+		}
+		if !c.Running(ctx) {
+			return fmt.Errorf("service exited before answering on port")
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("service didn't answer within %s", timeout)
+		case <-ticker.C:
+		}
+	}
+}
+
+// MergeEndpoints adds discovered endpoints the model doesn't already have,
+// matching by method and path with parameters in any style. Handlers are
+// linked to model functions by name where possible. It returns the number
+// of endpoints added.
+func MergeEndpoints(m *model.SystemModel, discovered []model.Endpoint) int {
+	existing := make(map[string]bool)
+	for _, ep := range m.Endpoints {
+		existing[endpointKey(ep.Method, ep.Path)] = true
+	}
+
+	functions := make(map[string]string) // name -> function ID
+	for _, fn := range m.Functions {
+		if _, ok := functions[fn.Name]; !ok {
+			functions[fn.Name] = fn.ID
+		}
+	}
+
+	added := 0
+	for _, ep := range discovered {
+		key := endpointKey(ep.Method, ep.Path)
+		if existing[key] {
+			continue
+		}
+		existing[key] = true
+
+		if id, ok := functions[shortName(ep.Handler)]; ok {
+			ep.Handler = id
+		}
+		if ep.ID == "" {
+			ep.ID = fmt.Sprintf("ep:runtime:%s:%s", ep.Method, ep.Path)
+		}
+		m.Endpoints = append(m.Endpoints, ep)
+		added++
+	}
+	return added
+}
+
+// endpointKey identifies a route regardless of parameter style, so
+// /users/:id, /users/{id} and /users/<int:id> are the same
+func endpointKey(method, path string) string {
+	path = pathParam.ReplaceAllString(path, "{}")
+	if len(path) > 1 {
+		path = strings.TrimSuffix(path, "/")
+	}
+	return strings.ToUpper(method) + " " + path
+}
+
+// shortName strips package or module qualifiers from a handler name, e.g.
+// main.getUser or app.routers.users.get_user
+func shortName(handler string) string {
+	handler = strings.TrimSuffix(handler, "-fm") // Go method values
+	if i := strings.LastIndex(handler, "."); i >= 0 {
+		return handler[i+1:]
+	}
+	return handler
+}
+
+// tail returns the last n lines of s
+func tail(s string, n int) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,272 @@
+package discovery
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/QTest-hq/qtest/pkg/model"
+)
+
+// fakeRuntime "starts" a service backed by an httptest server
+type fakeRuntime struct {
+	handler http.Handler
+	logs    string
+	spec    ContainerSpec
+	stopped bool
+}
+
+func (r *fakeRuntime) Start(ctx context.Context, spec ContainerSpec) (Container, error) {
+	r.spec = spec
+	return &fakeContainer{runtime: r, server: httptest.NewServer(r.handler)}, nil
+}
+
+type fakeContainer struct {
+	runtime *fakeRuntime
+	server  *httptest.Server
+}
+
+func (c *fakeContainer) BaseURL() string                          { return c.server.URL }
+func (c *fakeContainer) Logs(ctx context.Context) (string, error) { return c.runtime.logs, nil }
+func (c *fakeContainer) Running(ctx context.Context) bool         { return true }
+func (c *fakeContainer) Stop(ctx context.Context) error {
+	c.server.Close()
+	c.runtime.stopped = true
+	return nil
+}
+
+func testConfig(frameworks ...string) Config {
+	return Config{Image: "node:20", Command: "npm start", Port: 3000, Frameworks: frameworks}
+}
+
+func TestDiscover_OpenAPI(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/openapi.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{
+			"servers": [{"url": "/api"}],
+			"paths": {
+				"/users/{user_id}": {"get": {"operationId": "get_user"}, "delete": {}, "parameters": []},
+				"/users": {"post": {"operationId": "create_user"}}
+			}
+		}`))
+	})
+	rt := &fakeRuntime{handler: mux}
+
+	result, err := NewDiscoverer(rt).Discover(context.Background(), "/repo", testConfig("fastapi"))
+	if err != nil {
+		t.Fatalf("Discover() error = %v", err)
+	}
+	if !rt.stopped {
+		t.Error("container was not stopped")
+	}
+
+	got := make(map[string]model.Endpoint)
+	for _, ep := range result.Endpoints {
+		got[ep.Method+" "+ep.Path] = ep
+	}
+	if len(got) != 3 {
+		t.Fatalf("endpoints = %v, want 3", result.Endpoints)
+	}
+	ep, ok := got["GET /api/users/{user_id}"]
+	if !ok {
+		t.Fatalf("GET /api/users/{user_id} not found in %v", result.Endpoints)
+	}
+	if ep.Handler != "get_user" || ep.Source != SourceRuntime || ep.Framework != "fastapi" {
+		t.Errorf("endpoint = %+v", ep)
+	}
+	if len(ep.PathParams) != 1 || ep.PathParams[0] != "user_id" {
+		t.Errorf("PathParams = %v, want [user_id]", ep.PathParams)
+	}
+	if got["DELETE /api/users/{user_id}"].Handler != "anonymous" {
+		t.Errorf("handler without operationId = %s, want anonymous", got["DELETE /api/users/{user_id}"].Handler)
+	}
+	if len(result.Sources) != 1 || result.Sources[0] != "openapi" {
+		t.Errorf("Sources = %v, want [openapi]", result.Sources)
+	}
+}
+
+func TestDiscover_GinDebugRoutes(t *testing.T) {
+	rt := &fakeRuntime{
+		handler: http.NotFoundHandler(),
+		logs: `[GIN-debug] [WARNING] Running in "debug" mode.
+[GIN-debug] GET    /users/:id                --> main.getUser (3 handlers)
+[GIN-debug] POST   /users                    --> main.(*API).createUser-fm (3 handlers)
+[GIN-debug] Listening and serving HTTP on :8080
+`,
+	}
+
+	result, err := NewDiscoverer(rt).Discover(context.Background(), "/repo", testConfig("gin"))
+	if err != nil {
+		t.Fatalf("Discover() error = %v", err)
+	}
+	if rt.spec.Env["GIN_MODE"] != "debug" {
+		t.Errorf("GIN_MODE = %q, want debug", rt.spec.Env["GIN_MODE"])
+	}
+	if len(result.Endpoints) != 2 {
+		t.Fatalf("endpoints = %v, want 2", result.Endpoints)
+	}
+	if ep := result.Endpoints[0]; ep.Method != "GET" || ep.Path != "/users/:id" || ep.Handler != "main.getUser" {
+		t.Errorf("Endpoints[0] = %+v", ep)
+	}
+}
+
+func TestDiscover_ExpressPreload(t *testing.T) {
+	rt := &fakeRuntime{
+		handler: http.NotFoundHandler(),
+		logs: `server listening on 3000
+QTEST_ROUTE {"method":"GET","path":"/api/users/:id","handler":"getUser"}
+QTEST_ROUTE {"method":"GET","path":"/api/users/:id","handler":"getUser"}
+QTEST_ROUTE not json
+`,
+	}
+	cfg := testConfig("nestjs")
+	cfg.Env = map[string]string{"NODE_OPTIONS": "--max-old-space-size=512"}
+
+	result, err := NewDiscoverer(rt).Discover(context.Background(), "/repo", cfg)
+	if err != nil {
+		t.Fatalf("Discover() error = %v", err)
+	}
+	if rt.spec.Files["express-routes.js"] == "" {
+		t.Error("preload script not injected")
+	}
+	if opts := rt.spec.Env["NODE_OPTIONS"]; !strings.HasPrefix(opts, "--max-old-space-size=512 ") || !strings.Contains(opts, "--require /qtest/express-routes.js") {
+		t.Errorf("NODE_OPTIONS = %q", opts)
+	}
+	if len(result.Endpoints) != 1 {
+		t.Fatalf("endpoints = %v, want 1 (deduplicated)", result.Endpoints)
+	}
+	if ep := result.Endpoints[0]; ep.Framework != "nestjs" || ep.Handler != "getUser" {
+		t.Errorf("endpoint = %+v", ep)
+	}
+}
+
+func TestDiscover_NotReady(t *testing.T) {
+	rt := &stoppedRuntime{}
+	d := NewDiscoverer(rt)
+	d.pollInterval = time.Millisecond
+
+	cfg := testConfig()
+	cfg.StartupTimeout = time.Second
+	_, err := d.Discover(context.Background(), "/repo", cfg)
+	if err == nil || !strings.Contains(err.Error(), "exited") {
+		t.Errorf("error = %v, want service exited", err)
+	}
+	if !strings.Contains(err.Error(), "npm ERR!") {
+		t.Errorf("error should include the container logs: %v", err)
+	}
+}
+
+// stoppedRuntime starts a service that exits straight away
+type stoppedRuntime struct{}
+
+func (r *stoppedRuntime) Start(ctx context.Context, spec ContainerSpec) (Container, error) {
+	return stoppedContainer{}, nil
+}
+
+type stoppedContainer struct{}
+
+func (stoppedContainer) BaseURL() string { return "http://127.0.0.1:1" }
+func (stoppedContainer) Logs(ctx context.Context) (string, error) {
+	return "npm ERR! missing script: start", nil
+}
+func (stoppedContainer) Running(ctx context.Context) bool { return false }
+func (stoppedContainer) Stop(ctx context.Context) error   { return nil }
+
+func TestConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr bool
+	}{
+		{"valid", testConfig(), false},
+		{"no image", Config{Command: "npm start", Port: 3000}, true},
+		{"no command", Config{Image: "node:20", Port: 3000}, true},
+		{"bad port", Config{Image: "node:20", Command: "npm start"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMergeEndpoints(t *testing.T) {
+	m := &model.SystemModel{
+		Functions: []model.Function{{ID: "fn:getUser", Name: "getUser"}},
+		Endpoints: []model.Endpoint{{ID: "ep:1", Method: "GET", Path: "/users/:id", Handler: "getUser"}},
+	}
+	discovered := []model.Endpoint{
+		{Method: "GET", Path: "/users/{id}", Handler: "main.getUser", Source: SourceRuntime}, // same route
+		{Method: "GET", Path: "/plugins/reports/", Handler: "main.getUser", Source: SourceRuntime},
+		{Method: "POST", Path: "/plugins/reports", Handler: "anonymous", Source: SourceRuntime},
+	}
+
+	if added := MergeEndpoints(m, discovered); added != 2 {
+		t.Errorf("MergeEndpoints() = %d, want 2", added)
+	}
+	if len(m.Endpoints) != 3 {
+		t.Fatalf("len(Endpoints) = %d, want 3", len(m.Endpoints))
+	}
+	if m.Endpoints[1].Handler != "fn:getUser" {
+		t.Errorf("Handler = %s, want linked to fn:getUser", m.Endpoints[1].Handler)
+	}
+	if m.Endpoints[1].ID == "" || m.Endpoints[1].Source != SourceRuntime {
+		t.Errorf("Endpoints[1] = %+v", m.Endpoints[1])
+	}
+}
+
+func TestEndpointKey(t *testing.T) {
+	same := []string{"/users/:id", "/users/{id}", "/users/<int:id>", "/users/{user_id:int}/"}
+	for _, p := range same {
+		if got := endpointKey("get", p); got != "GET /users/{}" {
+			t.Errorf("endpointKey(%s) = %s, want GET /users/{}", p, got)
+		}
+	}
+	if endpointKey("GET", "/") != "GET /" {
+		t.Errorf("root path key = %s", endpointKey("GET", "/"))
+	}
+}
+
+func TestDockerRunArgs(t *testing.T) {
+	args := dockerRunArgs(ContainerSpec{
+		Image:   "python:3.12",
+		Command: "uvicorn app:app --host 0.0.0.0",
+		Port:    8000,
+		Mount:   "/repo",
+		Env:     map[string]string{"B": "2", "A": "1"},
+	}, "/tmp/qtest-files", "", "qtest-discovery")
+	joined := strings.Join(args, " ")
+
+	for _, want := range []string{
+		"--cap-drop ALL",
+		"--memory 1g",
+		"--network qtest-discovery",
+		"-v /repo:/src:ro",
+		"--tmpfs /app:exec",
+		"-w /app",
+		"-v /tmp/qtest-files:/qtest:ro",
+		"-e A=1 -e B=2",
+		"python:3.12 sh -c cp -R /src/. /app && uvicorn app:app --host 0.0.0.0",
+	} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("docker args missing %q: %s", want, joined)
+		}
+	}
+	if strings.Contains(joined, " -p ") {
+		t.Errorf("docker args publish a port: %s", joined)
+	}
+}
+
+func TestContainerURL(t *testing.T) {
+	if got, _ := containerURL("172.18.0.2\n", 8000); got != "http://172.18.0.2:8000" {
+		t.Errorf("containerURL() = %s", got)
+	}
+	if _, err := containerURL("\n", 8000); err == nil {
+		t.Error("expected error for a container without an address")
+	}
+}
@@ -0,0 +1,225 @@
+package discovery
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Paths inside the container
+const (
+	srcDir   = "/src"   // repository mount
+	appDir   = "/app"   // writable copy of the repository the service runs in
+	qtestDir = "/qtest" // files injected by scrapers
+)
+
+// defaultNetwork is the internal network discovery containers are attached to
+const defaultNetwork = "qtest-discovery"
+
+// ContainerSpec describes the container to boot the service in
+type ContainerSpec struct {
+	Image   string
+	Command string            // run with sh -c
+	Port    int               // container port to publish
+	Mount   string            // host directory mounted read-only, copied to /app
+	Env     map[string]string // environment variables
+	Files   map[string]string // name -> content, mounted read-only under /qtest
+}
+
+// Runtime starts containers
+type Runtime interface {
+	Start(ctx context.Context, spec ContainerSpec) (Container, error)
+}
+
+// Container is a running service
+type Container interface {
+	BaseURL() string // e.g. http://127.0.0.1:49153
+	Logs(ctx context.Context) (string, error)
+	Running(ctx context.Context) bool
+	Stop(ctx context.Context) error
+}
+
+// DockerRuntime runs containers with the docker CLI. Containers are
+// sandboxed: the repository is mounted read-only and copied into a tmpfs the
+// service runs from, all capabilities are dropped, memory and process counts
+// are capped and the container sits on an internal network, so the host can
+// reach its service but it can't reach anything else.
+type DockerRuntime struct {
+	Binary  string // docker CLI, default "docker"
+	Memory  string // memory limit, default "1g"
+	Network string // internal network, default "qtest-discovery"; created when missing
+}
+
+// NewDockerRuntime creates a Docker runtime with default limits
+func NewDockerRuntime() *DockerRuntime {
+	return &DockerRuntime{Binary: "docker", Memory: "1g", Network: defaultNetwork}
+}
+
+// Start boots a container for the spec and finds the host port its
+// service port is published on
+func (r *DockerRuntime) Start(ctx context.Context, spec ContainerSpec) (Container, error) {
+	network := r.Network
+	if network == "" {
+		network = defaultNetwork
+	}
+	if err := r.ensureNetwork(ctx, network); err != nil {
+		return nil, err
+	}
+
+	filesDir := ""
+	if len(spec.Files) > 0 {
+		dir, err := os.MkdirTemp("", "qtest-discovery-")
+		if err != nil {
+			return nil, err
+		}
+		for name, content := range spec.Files {
+			if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+				os.RemoveAll(dir)
+				return nil, err
+			}
+		}
+		filesDir = dir
+	}
+
+	out, err := r.run(ctx, dockerRunArgs(spec, filesDir, r.Memory, network)...)
+	if err != nil {
+		os.RemoveAll(filesDir)
+		return nil, err
+	}
+	c := &dockerContainer{runtime: r, id: strings.TrimSpace(out), filesDir: filesDir}
+
+	// Ports aren't published on internal networks; the host reaches the
+	// container at its address on the network's bridge
+	ip, err := r.run(ctx, "inspect", "-f", "{{range .NetworkSettings.Networks}}{{.IPAddress}}{{end}}", c.id)
+	if err != nil {
+		c.Stop(ctx)
+		return nil, fmt.Errorf("failed to find container address: %w", err)
+	}
+	c.baseURL, err = containerURL(ip, spec.Port)
+	if err != nil {
+		c.Stop(ctx)
+		return nil, err
+	}
+	return c, nil
+}
+
+// ensureNetwork creates the internal network containers run on. Containers
+// on it can't reach each other or anything outside the host.
+func (r *DockerRuntime) ensureNetwork(ctx context.Context, name string) error {
+	if _, err := r.run(ctx, "network", "inspect", name); err == nil {
+		return nil
+	}
+	_, err := r.run(ctx, "network", "create", "--internal",
+		"-o", "com.docker.network.bridge.enable_icc=false", name)
+	if err != nil {
+		// Another discovery may have created it meanwhile
+		if _, inspectErr := r.run(ctx, "network", "inspect", name); inspectErr == nil {
+			return nil
+		}
+		return fmt.Errorf("failed to create network %s: %w", name, err)
+	}
+	return nil
+}
+
+func (r *DockerRuntime) run(ctx context.Context, args ...string) (string, error) {
+	binary := r.Binary
+	if binary == "" {
+		binary = "docker"
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, binary, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("%s %s: %w: %s", binary, args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+// dockerRunArgs builds the docker run arguments for a spec
+func dockerRunArgs(spec ContainerSpec, filesDir, memory, network string) []string {
+	if memory == "" {
+		memory = "1g"
+	}
+	args := []string{
+		"run", "-d",
+		"--cap-drop", "ALL",
+		"--security-opt", "no-new-privileges",
+		"--memory", memory,
+		"--pids-limit", "512",
+		"--network", network,
+		"-v", spec.Mount + ":" + srcDir + ":ro",
+		"--tmpfs", appDir + ":exec",
+		"-w", appDir,
+	}
+	if filesDir != "" {
+		args = append(args, "-v", filesDir+":"+qtestDir+":ro")
+	}
+
+	// Sorted for stable arguments
+	keys := make([]string, 0, len(spec.Env))
+	for k := range spec.Env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		args = append(args, "-e", k+"="+spec.Env[k])
+	}
+
+	// The service runs from a copy so installs and builds can write to it.
+	// Ownership isn't preserved: chown needs a dropped capability.
+	command := "cp -R " + srcDir + "/. " + appDir + " && " + spec.Command
+	return append(args, spec.Image, "sh", "-c", command)
+}
+
+// containerURL turns docker inspect's address output (e.g. "172.18.0.2")
+// into a URL for the service port
+func containerURL(ipOutput string, port int) (string, error) {
+	ip := net.ParseIP(strings.TrimSpace(ipOutput))
+	if ip == nil {
+		return "", fmt.Errorf("container has no network address")
+	}
+	return "http://" + net.JoinHostPort(ip.String(), strconv.Itoa(port)), nil
+}
+
+type dockerContainer struct {
+	runtime  *DockerRuntime
+	id       string
+	baseURL  string
+	filesDir string
+}
+
+func (c *dockerContainer) BaseURL() string {
+	return c.baseURL
+}
+
+func (c *dockerContainer) Logs(ctx context.Context) (string, error) {
+	binary := c.runtime.Binary
+	if binary == "" {
+		binary = "docker"
+	}
+	// Services log to stdout and stderr alike
+	out, err := exec.CommandContext(ctx, binary, "logs", c.id).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("docker logs: %w", err)
+	}
+	return string(out), nil
+}
+
+func (c *dockerContainer) Running(ctx context.Context) bool {
+	out, err := c.runtime.run(ctx, "inspect", "-f", "{{.State.Running}}", c.id)
+	return err == nil && strings.TrimSpace(out) == "true"
+}
+
+func (c *dockerContainer) Stop(ctx context.Context) error {
+	defer os.RemoveAll(c.filesDir)
+	_, err := c.runtime.run(ctx, "rm", "-f", c.id)
+	return err
+}
@@ -0,0 +1,311 @@
+package discovery
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/QTest-hq/qtest/pkg/model"
+)
+
+// pathParam matches path parameters in the common styles: :id, {id} and
+// <id> (optionally typed, e.g. <int:id>)
+var pathParam = regexp.MustCompile(`:(\w+)|\{(\w+)[^}]*\}|<(?:\w+:)?(\w+)>`)
+
+// scraper reads a running service's route table
+type scraper interface {
+	name() string
+
+	// prepare adjusts the container before it starts, e.g. to inject
+	// instrumentation
+	prepare(spec *ContainerSpec)
+
+	// scrape returns the routes the service reports
+	scrape(ctx context.Context, client *http.Client, baseURL, logs string) ([]model.Endpoint, error)
+}
+
+// scrapersFor returns the scrapers for the given frameworks. The OpenAPI
+// scraper always runs since any framework can serve a spec; with no
+// frameworks every scraper runs.
+func scrapersFor(frameworks []string) []scraper {
+	all := len(frameworks) == 0
+	has := func(names ...string) string {
+		for _, f := range frameworks {
+			for _, name := range names {
+				if f == name {
+					return f
+				}
+			}
+		}
+		return ""
+	}
+
+	openAPIFramework := "openapi"
+	if len(frameworks) > 0 {
+		openAPIFramework = frameworks[0]
+	}
+	scrapers := []scraper{&openAPIScraper{framework: openAPIFramework}}
+	if f := has("gin"); all || f != "" {
+		scrapers = append(scrapers, &ginScraper{})
+	}
+	if f := has("express", "nestjs"); all || f != "" {
+		if f == "" {
+			f = "express"
+		}
+		scrapers = append(scrapers, &expressScraper{framework: f})
+	}
+	return scrapers
+}
+
+// openAPIPaths are where frameworks serve their OpenAPI or Swagger spec:
+// FastAPI, generic Swagger, springdoc and NestJS's SwaggerModule
+var openAPIPaths = []string{"/openapi.json", "/swagger.json", "/v3/api-docs", "/api-json", "/docs/openapi.json"}
+
+// openAPIScraper reads routes from the service's OpenAPI spec
+type openAPIScraper struct {
+	framework string
+}
+
+func (s *openAPIScraper) name() string { return "openapi" }
+
+func (s *openAPIScraper) prepare(spec *ContainerSpec) {}
+
+func (s *openAPIScraper) scrape(ctx context.Context, client *http.Client, baseURL, logs string) ([]model.Endpoint, error) {
+	for _, path := range openAPIPaths {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+path, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			continue
+		}
+		var spec openAPISpec
+		err = json.NewDecoder(resp.Body).Decode(&spec)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || err != nil || len(spec.Paths) == 0 {
+			continue
+		}
+		return spec.endpoints(s.framework), nil
+	}
+	return nil, fmt.Errorf("no OpenAPI spec served")
+}
+
+// openAPISpec is the part of an OpenAPI 3 or Swagger 2 document holding
+// routes
+type openAPISpec struct {
+	BasePath string `json:"basePath"` // Swagger 2
+	Servers  []struct {
+		URL string `json:"url"`
+	} `json:"servers"` // OpenAPI 3
+	Paths map[string]map[string]json.RawMessage `json:"paths"`
+}
+
+var openAPIMethods = map[string]bool{
+	"get": true, "put": true, "post": true, "delete": true,
+	"options": true, "head": true, "patch": true, "trace": true,
+}
+
+func (s *openAPISpec) endpoints(framework string) []model.Endpoint {
+	prefix := strings.TrimSuffix(s.BasePath, "/")
+	if len(s.Servers) > 0 && strings.HasPrefix(s.Servers[0].URL, "/") {
+		prefix = strings.TrimSuffix(s.Servers[0].URL, "/")
+	}
+
+	paths := make([]string, 0, len(s.Paths))
+	for p := range s.Paths {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	var endpoints []model.Endpoint
+	for _, p := range paths {
+		methods := make([]string, 0, len(s.Paths[p]))
+		for m := range s.Paths[p] {
+			if openAPIMethods[strings.ToLower(m)] {
+				methods = append(methods, m)
+			}
+		}
+		sort.Strings(methods)
+
+		for _, m := range methods {
+			var op struct {
+				OperationID string `json:"operationId"`
+			}
+			json.Unmarshal(s.Paths[p][m], &op)
+			endpoints = append(endpoints, newEndpoint(strings.ToUpper(m), prefix+p, op.OperationID, framework))
+		}
+	}
+	return endpoints
+}
+
+// ginRoute matches the route table Gin logs in debug mode:
+// [GIN-debug] GET    /users/:id   --> main.getUser (3 handlers)
+var ginRoute = regexp.MustCompile(`\[GIN-debug\]\s+([A-Z]+)\s+(/\S*)\s+-->\s+(\S+)`)
+
+// ginScraper reads the route table Gin prints at startup in debug mode
+type ginScraper struct{}
+
+func (s *ginScraper) name() string { return "gin" }
+
+func (s *ginScraper) prepare(spec *ContainerSpec) {
+	if _, ok := spec.Env["GIN_MODE"]; !ok {
+		spec.Env["GIN_MODE"] = "debug"
+	}
+}
+
+func (s *ginScraper) scrape(ctx context.Context, client *http.Client, baseURL, logs string) ([]model.Endpoint, error) {
+	var endpoints []model.Endpoint
+	scanner := bufio.NewScanner(strings.NewReader(logs))
+	for scanner.Scan() {
+		if m := ginRoute.FindStringSubmatch(scanner.Text()); m != nil {
+			endpoints = append(endpoints, newEndpoint(m[1], m[2], m[3], "gin"))
+		}
+	}
+	return endpoints, nil
+}
+
+// expressRouteMarker prefixes the route lines the preload script logs
+const expressRouteMarker = "QTEST_ROUTE "
+
+// expressScraper injects a preload script that logs the Express router's
+// route table when the app handles its first request (the readiness check)
+type expressScraper struct {
+	framework string
+}
+
+func (s *expressScraper) name() string { return "express" }
+
+func (s *expressScraper) prepare(spec *ContainerSpec) {
+	spec.Files["express-routes.js"] = expressPreload
+	opts := "--require " + qtestDir + "/express-routes.js"
+	if existing := spec.Env["NODE_OPTIONS"]; existing != "" {
+		opts = existing + " " + opts
+	}
+	spec.Env["NODE_OPTIONS"] = opts
+}
+
+func (s *expressScraper) scrape(ctx context.Context, client *http.Client, baseURL, logs string) ([]model.Endpoint, error) {
+	var endpoints []model.Endpoint
+	scanner := bufio.NewScanner(strings.NewReader(logs))
+	for scanner.Scan() {
+		line := scanner.Text()
+		i := strings.Index(line, expressRouteMarker)
+		if i < 0 {
+			continue
+		}
+		var route struct {
+			Method  string `json:"method"`
+			Path    string `json:"path"`
+			Handler string `json:"handler"`
+		}
+		if err := json.Unmarshal([]byte(line[i+len(expressRouteMarker):]), &route); err != nil || route.Path == "" {
+			continue
+		}
+		endpoints = append(endpoints, newEndpoint(route.Method, route.Path, route.Handler, s.framework))
+	}
+	return endpoints, nil
+}
+
+// newEndpoint builds a discovered endpoint with its path parameters
+func newEndpoint(method, path, handler, framework string) model.Endpoint {
+	if handler == "" {
+		handler = "anonymous"
+	}
+	ep := model.Endpoint{
+		Method:    strings.ToUpper(method),
+		Path:      path,
+		Handler:   handler,
+		Framework: framework,
+	}
+	for _, m := range pathParam.FindAllStringSubmatch(path, -1) {
+		for _, name := range m[1:] {
+			if name != "" {
+				ep.PathParams = append(ep.PathParams, name)
+			}
+		}
+	}
+	return ep
+}
+
+// expressPreload hooks Express when it's loaded: it records the paths
+// routers are mounted at and logs every route once the app handles its
+// first request. It works whether the app calls app.listen or is passed to
+// http.createServer (as NestJS does).
+const expressPreload = `// Injected by QTest route discovery
+const Module = require('module');
+const load = Module._load;
+let patched = false;
+
+Module._load = function (request) {
+  const exported = load.apply(this, arguments);
+  if (request === 'express' && !patched && exported && exported.application) {
+    patched = true;
+    patchExpress(exported);
+  }
+  return exported;
+};
+
+function patchExpress(express) {
+  const proto = express.Router.prototype && express.Router.prototype.use ? express.Router.prototype : express.Router;
+  const use = proto.use;
+  proto.use = function (path) {
+    const before = this.stack.length;
+    const result = use.apply(this, arguments);
+    if (typeof path === 'string') {
+      for (let i = before; i < this.stack.length; i++) this.stack[i].qtestPath = path;
+    }
+    return result;
+  };
+
+  const handle = express.application.handle;
+  express.application.handle = function () {
+    if (!this.parent && !this.qtestDumped) {
+      this.qtestDumped = true;
+      try { dump(this); } catch (e) { console.error('QTEST_ROUTE_ERROR ' + e.message); }
+    }
+    return handle.apply(this, arguments);
+  };
+}
+
+function dump(app) {
+  const router = app._router || app.router;
+  walk(router && router.stack, '');
+}
+
+function walk(stack, prefix) {
+  for (const layer of stack || []) {
+    if (layer.route) {
+      const handlers = layer.route.stack || [];
+      const last = handlers[handlers.length - 1];
+      const handler = (last && last.handle && last.handle.name) || '';
+      for (const method of Object.keys(layer.route.methods)) {
+        if (method === '_all') continue;
+        console.log('QTEST_ROUTE ' + JSON.stringify({ method: method.toUpperCase(), path: join(prefix, layer.route.path), handler }));
+      }
+    } else if (layer.handle && layer.handle.stack) {
+      walk(layer.handle.stack, join(prefix, mountPath(layer)));
+    }
+  }
+}
+
+function mountPath(layer) {
+  if (layer.qtestPath) return layer.qtestPath;
+  // Express 4 only keeps the mount path as a regexp, e.g. ^\/api\/?(?=\/|$)
+  const source = layer.regexp && !layer.regexp.fast_slash && layer.regexp.source;
+  const match = source && source.match(/^\^(.*?)\\\/\?\(\?=\\\/\|\$\)$/);
+  return match ? match[1].replace(/\\\//g, '/') : '';
+}
+
+function join(prefix, path) {
+  if (Array.isArray(path)) path = path[0];
+  if (typeof path !== 'string') path = String(path);
+  const joined = (prefix.replace(/\/$/, '') + '/' + path.replace(/^\//, '')).replace(/\/$/, '');
+  return joined || '/';
+}
+`
@@ -0,0 +1,55 @@
+package emitter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/QTest-hq/qtest/pkg/model"
+)
+
+// approxValues formats the expected value and tolerance of an "approx"
+// assertion as float literals
+func approxValues(a model.Assertion) (expected, eps string) {
+	expected = fmt.Sprintf("%v", a.Expected)
+	switch v := a.Expected.(type) {
+	case float64:
+		expected = floatLiteral(v)
+	case int:
+		expected = floatLiteral(float64(v))
+	case string:
+		if f, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+			expected = floatLiteral(f)
+		}
+	}
+	return expected, floatLiteral(a.Epsilon())
+}
+
+// floatLiteral formats f so it reads as a float in the target languages
+func floatLiteral(f float64) string {
+	s := strconv.FormatFloat(f, 'g', -1, 64)
+	if !strings.ContainsAny(s, ".eEn") {
+		s += ".0"
+	}
+	return s
+}
+
+// regexPattern returns the regular expression of a "matches" assertion
+func regexPattern(a model.Assertion) string {
+	if a.Expected == nil {
+		return ""
+	}
+	return fmt.Sprintf("%v", a.Expected)
+}
+
+// anyAssertion reports whether any of specs has an assertion of kind
+func anyAssertion(specs []model.TestSpec, kind string) bool {
+	for i := range specs {
+		for _, a := range specs[i].Assertions {
+			if a.Kind == kind {
+				return true
+			}
+		}
+	}
+	return false
+}
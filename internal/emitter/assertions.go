@@ -0,0 +1,209 @@
+package emitter
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Assertion libraries emitters can generate for, besides each language's
+// built-in style (the testing package, Jest's expect, plain assert)
+const (
+	AssertTestify  = "testify"  // testify/assert, with testify/require for setup
+	AssertRequire  = "require"  // testify/require throughout
+	AssertChai     = "chai"     // chai's expect
+	AssertAssertpy = "assertpy" // assertpy's assert_that
+)
+
+// builtinAssertions names each language's built-in assertion style
+var builtinAssertions = map[string]string{
+	"go":         "testing",
+	"javascript": "jest",
+	"python":     "assert",
+}
+
+// assertionLibraries lists the libraries each language's emitter supports
+var assertionLibraries = map[string][]string{
+	"go":         {AssertTestify, AssertRequire},
+	"javascript": {AssertChai},
+	"python":     {AssertAssertpy},
+}
+
+// AssertionLibraries returns the assertion libraries for a language, its
+// built-in style first
+func AssertionLibraries(language string) []string {
+	language = normalizeAssertionLanguage(language)
+	builtin, ok := builtinAssertions[language]
+	if !ok {
+		return nil
+	}
+	return append([]string{builtin}, assertionLibraries[language]...)
+}
+
+// SetAssertionLibrary makes em generate assertions with lib. An empty lib or
+// the language's built-in style name restores the default.
+func SetAssertionLibrary(em Emitter, lib string) error {
+	lib = strings.ToLower(strings.TrimSpace(lib))
+	language := normalizeAssertionLanguage(em.Language())
+	if lib == builtinAssertions[language] {
+		lib = ""
+	}
+
+	if lib != "" {
+		supported := false
+		for _, l := range assertionLibraries[language] {
+			if l == lib {
+				supported = true
+				break
+			}
+		}
+		if !supported {
+			if libs := AssertionLibraries(language); len(libs) > 0 {
+				return fmt.Errorf("assertion library %q not supported for %s (supported: %s)", lib, language, strings.Join(libs, ", "))
+			}
+			return fmt.Errorf("%s emitter doesn't support assertion libraries", em.Name())
+		}
+	}
+
+	switch e := em.(type) {
+	case *GoHTTPEmitter:
+		e.Assertions = lib
+	case *SupertestEmitter:
+		e.Assertions = lib
+	case *PytestEmitter:
+		e.Assertions = lib
+	default:
+		if lib != "" {
+			return fmt.Errorf("%s emitter doesn't support assertion libraries", em.Name())
+		}
+	}
+	return nil
+}
+
+// assertionDetectors say which files show a project uses an assertion
+// library: manifests at its root declaring the dependency, or test files
+// importing it
+var assertionDetectors = map[string][]struct {
+	lib       string
+	manifests []string
+	testFile  func(name string) bool
+	imports   []string
+}{
+	"go": {
+		// require first: a project using it usually imports both packages
+		{AssertRequire, nil, isGoTestFile, []string{`"github.com/stretchr/testify/require"`}},
+		{AssertTestify, []string{"go.mod"}, isGoTestFile, []string{`"github.com/stretchr/testify/assert"`, "github.com/stretchr/testify "}},
+	},
+	"javascript": {
+		{AssertChai, []string{"package.json"}, isJSTestFile, []string{`"chai"`, `require('chai')`, `from 'chai'`, `from "chai"`}},
+	},
+	"python": {
+		{AssertAssertpy, []string{"requirements.txt", "requirements-dev.txt", "pyproject.toml", "setup.py", "setup.cfg", "Pipfile"},
+			isPythonTestFile, []string{"assertpy"}},
+	},
+}
+
+// maxAssertionScanFiles caps how many test files detection reads
+const maxAssertionScanFiles = 200
+
+// DetectAssertionLibrary guesses the assertion library a project's tests
+// use from its dependencies and existing tests, or returns "" for the
+// language's built-in style
+func DetectAssertionLibrary(dir, language string) string {
+	detectors := assertionDetectors[normalizeAssertionLanguage(language)]
+	if len(detectors) == 0 {
+		return ""
+	}
+
+	// Existing tests say what the team uses, so they're checked first
+	counts := make(map[string]int)
+	scanned := 0
+	filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || scanned >= maxAssertionScanFiles {
+			return nil
+		}
+		if info.IsDir() {
+			name := info.Name()
+			if path != dir && (strings.HasPrefix(name, ".") || name == "node_modules" || name == "vendor" ||
+				name == "__pycache__" || name == "venv") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		var content string
+		for _, d := range detectors {
+			if !d.testFile(info.Name()) {
+				continue
+			}
+			if content == "" {
+				data, err := os.ReadFile(path)
+				if err != nil {
+					return nil
+				}
+				content = string(data)
+				scanned++
+			}
+			if containsAny(content, d.imports) {
+				counts[d.lib]++
+			}
+		}
+		return nil
+	})
+
+	best := ""
+	for _, d := range detectors {
+		if counts[d.lib] > counts[best] {
+			best = d.lib
+		}
+	}
+	if best != "" {
+		return best
+	}
+
+	// Otherwise a declared dependency
+	for _, d := range detectors {
+		for _, manifest := range d.manifests {
+			data, err := os.ReadFile(filepath.Join(dir, manifest))
+			if err == nil && containsAny(string(data), d.imports) {
+				return d.lib
+			}
+		}
+	}
+	return ""
+}
+
+// normalizeAssertionLanguage maps a language to the one its emitter targets
+func normalizeAssertionLanguage(language string) string {
+	if language == "typescript" {
+		return "javascript"
+	}
+	return language
+}
+
+func containsAny(s string, subs []string) bool {
+	for _, sub := range subs {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
+
+func isGoTestFile(name string) bool {
+	return strings.HasSuffix(name, "_test.go")
+}
+
+func isJSTestFile(name string) bool {
+	for _, suffix := range []string{".test.js", ".spec.js", ".test.ts", ".spec.ts"} {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+func isPythonTestFile(name string) bool {
+	return strings.HasSuffix(name, ".py") && (strings.HasPrefix(name, "test_") || strings.HasSuffix(name, "_test.py"))
+}
@@ -0,0 +1,68 @@
+package emitter
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	"github.com/QTest-hq/qtest/pkg/model"
+)
+
+// identifierPattern matches keys usable as a field name without quoting
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// expectedItemsJSON returns the items a collection assertion lists as a
+// JSON array
+func expectedItemsJSON(a model.Assertion) string {
+	items := a.ExpectedItems()
+	if items == nil {
+		items = []interface{}{}
+	}
+	data, _ := json.Marshal(items)
+	return string(data)
+}
+
+// sortDescription describes the order a "sorted" assertion expects, e.g.
+// "sorted by price in descending order"
+func sortDescription(a model.Assertion) string {
+	key, desc := a.SortKey()
+	order := "ascending"
+	if desc {
+		order = "descending"
+	}
+	if key == "" {
+		return fmt.Sprintf("sorted in %s order", order)
+	}
+	return fmt.Sprintf("sorted by %s in %s order", key, order)
+}
+
+// JSSortComparator returns a comparator ordering items, or their key field,
+// the way a "sorted" assertion expects. The spec adapters share it.
+func JSSortComparator(a model.Assertion) string {
+	key, desc := a.SortKey()
+	x, y := "a", "b"
+	if key != "" {
+		x, y = fmt.Sprintf("a[%q]", key), fmt.Sprintf("b[%q]", key)
+		if identifierPattern.MatchString(key) {
+			x, y = "a."+key, "b."+key
+		}
+	}
+	if desc {
+		return fmt.Sprintf("(a, b) => (%s < %s) - (%s > %s)", x, y, x, y)
+	}
+	return fmt.Sprintf("(a, b) => (%s > %s) - (%s < %s)", x, y, x, y)
+}
+
+// PythonSortArgs returns the key and reverse arguments to sorted() for a
+// "sorted" assertion
+func PythonSortArgs(a model.Assertion) string {
+	key, desc := a.SortKey()
+	var args string
+	if key != "" {
+		args += fmt.Sprintf(", key=lambda item: item[%q]", key)
+	}
+	if desc {
+		args += ", reverse=True"
+	}
+	return args
+}
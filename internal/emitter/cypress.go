@@ -3,6 +3,7 @@ package emitter
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/QTest-hq/qtest/pkg/model"
@@ -56,7 +57,11 @@ func (e *CypressEmitter) emitTest(spec model.TestSpec) (string, error) {
 
 	// Test function
 	testName := e.generateTestName(spec)
-	sb.WriteString(fmt.Sprintf("  it('%s', () => {\n", testName))
+	if timeout, retries := spec.ExecutionLimits(); timeout > 0 {
+		sb.WriteString(fmt.Sprintf("  it('%s', { retries: %d, defaultCommandTimeout: %d }, () => {\n", testName, retries, timeout*1000))
+	} else {
+		sb.WriteString(fmt.Sprintf("  it('%s', () => {\n", testName))
+	}
 
 	// Handle E2E actions from spec inputs
 	if spec.Level == model.LevelE2E {
@@ -260,6 +265,36 @@ func (e *CypressEmitter) emitAssertion(a model.Assertion) string {
 		}
 		return fmt.Sprintf("    cy.get('%s').should('contain', '%v');\n", e.formatSelector(a.Actual), a.Expected)
 
+	case model.AssertMatches:
+		pattern := fmt.Sprintf("new RegExp(%s)", strconv.Quote(regexPattern(a)))
+		if a.Actual == "title" {
+			return fmt.Sprintf("    cy.title().should('match', %s);\n", pattern)
+		}
+		if a.Actual == "url" {
+			return fmt.Sprintf("    cy.url().should('match', %s);\n", pattern)
+		}
+		return fmt.Sprintf("    cy.get('%s').invoke('text').should('match', %s);\n", e.formatSelector(a.Actual), pattern)
+
+	case model.AssertApprox:
+		expected, eps := approxValues(a)
+		return fmt.Sprintf("    cy.get('%s').invoke('text').then(Number).should('be.closeTo', %s, %s);\n",
+			e.formatSelector(a.Actual), expected, eps)
+
+	case model.AssertCookiePresent, model.AssertCookieEquals, model.AssertCookieMatches:
+		_, check, _ := a.ResponseCheck()
+		cookie := fmt.Sprintf("cy.getCookie(%s)", strconv.Quote(a.ResponseName()))
+		switch check {
+		case model.CheckPresent:
+			return fmt.Sprintf("    %s.should('exist');\n", cookie)
+		case model.CheckEquals:
+			return fmt.Sprintf("    %s.should('have.property', 'value', %s);\n", cookie, strconv.Quote(a.ExpectedString()))
+		default:
+			return fmt.Sprintf("    %s.its('value').should('match', new RegExp(%s));\n", cookie, strconv.Quote(regexPattern(a)))
+		}
+
+	case model.AssertHeaderPresent, model.AssertHeaderEquals, model.AssertHeaderMatches:
+		return "    " + pageHeaderTODO(a)
+
 	case "value":
 		return fmt.Sprintf("    cy.get('%s').should('have.value', '%v');\n", e.formatSelector(a.Actual), a.Expected)
 
@@ -44,12 +44,22 @@ func NewRegistry() *Registry {
 	r.Register(&GoHTTPEmitter{})
 	r.Register(&PytestEmitter{})
 	r.Register(&JUnitEmitter{})
+	r.Register(&XUnitEmitter{})
 	r.Register(&RSpecEmitter{})
 
+	// gRPC client emitters
+	r.Register(&GRPCGoEmitter{})
+	r.Register(&PytestGRPCEmitter{})
+
 	// E2E test emitters
 	r.Register(&PlaywrightEmitter{})
 	r.Register(&CypressEmitter{})
 
+	// BDD emitters (Gherkin features + step definitions)
+	r.Register(&GherkinEmitter{Runner: RunnerCucumber})
+	r.Register(&GherkinEmitter{Runner: RunnerGodog})
+	r.Register(&GherkinEmitter{Runner: RunnerBehave})
+
 	return r
 }
 
@@ -67,8 +77,17 @@ func (r *Registry) Get(name string) (Emitter, error) {
 	return e, nil
 }
 
+// languageDefaults picks the HTTP emitter for languages with several
+var languageDefaults = map[string]string{
+	"go":     "go-http",
+	"python": "pytest",
+}
+
 // GetForLanguage returns the default emitter for a language
 func (r *Registry) GetForLanguage(lang string) (Emitter, error) {
+	if e, ok := r.emitters[languageDefaults[lang]]; ok {
+		return e, nil
+	}
 	for _, e := range r.emitters {
 		if e.Language() == lang {
 			return e, nil
@@ -85,3 +104,14 @@ func (r *Registry) List() []string {
 	}
 	return names
 }
+
+// SetTimeout gives the API calls of each test em emits a deadline of
+// seconds, 0 for none. Emitters without deadlines ignore it.
+func SetTimeout(em Emitter, seconds int) {
+	switch e := em.(type) {
+	case *GoHTTPEmitter:
+		e.Timeout = seconds
+	case *GRPCGoEmitter:
+		e.Timeout = seconds
+	}
+}
@@ -1,6 +1,9 @@
 package emitter
 
 import (
+	"go/format"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -16,7 +19,7 @@ func TestNewRegistry(t *testing.T) {
 
 	// Check all emitters are registered
 	emitters := r.List()
-	expected := []string{"supertest", "go-http", "pytest", "junit", "rspec", "playwright", "cypress"}
+	expected := []string{"supertest", "go-http", "pytest", "junit", "xunit", "rspec", "grpc-go", "pytest-grpc", "playwright", "cypress", "cucumber", "godog", "behave"}
 
 	if len(emitters) != len(expected) {
 		t.Errorf("expected %d emitters, got %d", len(expected), len(emitters))
@@ -82,6 +85,13 @@ func TestRegistry_GetForLanguage(t *testing.T) {
 			t.Errorf("GetForLanguage(%s) returned emitter for %s, want %s", tt.language, e.Language(), tt.wantLanguage)
 		}
 	}
+
+	// HTTP emitters win over gRPC ones
+	for lang, want := range map[string]string{"go": "go-http", "python": "pytest"} {
+		if e, _ := r.GetForLanguage(lang); e.Name() != want {
+			t.Errorf("GetForLanguage(%s) = %s, want %s", lang, e.Name(), want)
+		}
+	}
 }
 
 // Helper to create a test spec
@@ -228,6 +238,37 @@ func TestGoHTTPEmitter_Emit(t *testing.T) {
 	}
 }
 
+func TestGoHTTPEmitter_Timeout(t *testing.T) {
+	slow := createAPITestSpec("POST", "/reports", "builds a report")
+	slow.Body = map[string]interface{}{"year": 2024}
+	slow.Tags = []string{model.TagSlow}
+	specs := []model.TestSpec{createAPITestSpec("GET", "/api/health", "should check health"), slow}
+
+	e := &GoHTTPEmitter{}
+	SetTimeout(e, 10)
+	code, err := e.Emit(specs)
+	if err != nil {
+		t.Fatalf("Emit() error: %v", err)
+	}
+	for _, want := range []string{
+		"\t\"context\"\n", "\t\"time\"\n",
+		"ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)",
+		`req, err := http.NewRequestWithContext(ctx, "GET", ts.URL+"/api/health", nil)`,
+		// A slow spec's deadline covers its timeout on every attempt
+		"context.WithTimeout(context.Background(), 90*time.Second)",
+		`req, err := http.NewRequestWithContext(ctx, "POST", ts.URL+"/reports", body)`,
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("Emit() missing %q:\n%s", want, code)
+		}
+	}
+
+	code, _ = (&GoHTTPEmitter{}).Emit(specs[:1])
+	if strings.Contains(code, "context") || strings.Contains(code, "\"time\"") {
+		t.Errorf("Emit() without a timeout shouldn't set a deadline:\n%s", code)
+	}
+}
+
 // Pytest Emitter Tests
 func TestPytestEmitter_Metadata(t *testing.T) {
 	e := &PytestEmitter{}
@@ -313,6 +354,110 @@ func TestJUnitEmitter_Emit(t *testing.T) {
 	}
 }
 
+func TestJUnitEmitter_PackageAndClass(t *testing.T) {
+	e := &JUnitEmitter{Package: "com.acme.shop", ClassName: "UnitTest"}
+	code, err := e.Emit([]model.TestSpec{createAPITestSpec("GET", "/orders", "lists orders")})
+	if err != nil {
+		t.Fatalf("Emit() error: %v", err)
+	}
+	if !strings.HasPrefix(code, "package com.acme.shop;\n") || !strings.Contains(code, "public class UnitTest {") {
+		t.Errorf("Emit() should use the package and class name:\n%s", code)
+	}
+	if e.FileName() != "UnitTest.java" {
+		t.Errorf("FileName() = %s, want UnitTest.java", e.FileName())
+	}
+	if (&JUnitEmitter{}).FileName() != "ApiTest.java" {
+		t.Errorf("default FileName() = %s, want ApiTest.java", (&JUnitEmitter{}).FileName())
+	}
+}
+
+// xUnit Emitter Tests
+func TestXUnitEmitter_Metadata(t *testing.T) {
+	e := &XUnitEmitter{}
+
+	if e.Name() != "xunit" {
+		t.Errorf("Name() = %s, want xunit", e.Name())
+	}
+	if e.Language() != "csharp" {
+		t.Errorf("Language() = %s, want csharp", e.Language())
+	}
+	if e.FileExtension() != "Tests.cs" {
+		t.Errorf("FileExtension() = %s, want Tests.cs", e.FileExtension())
+	}
+}
+
+func TestXUnitEmitter_Emit(t *testing.T) {
+	e := &XUnitEmitter{}
+	specs := []model.TestSpec{
+		createAPITestSpec("GET", "/api/products", "should get products"),
+		createAPITestSpec("GET", "/api/products", "should get products again"),
+	}
+
+	code, err := e.Emit(specs)
+	if err != nil {
+		t.Fatalf("Emit() error: %v", err)
+	}
+
+	expectations := []string{
+		"using Microsoft.AspNetCore.Mvc.Testing;",
+		"using Xunit;",
+		"public class ApiTests : IClassFixture<WebApplicationFactory<Program>>",
+		"[Fact(DisplayName = \"GET /api/products\")]",
+		"public async Task Get_api_products()",
+		"public async Task Get_api_products_2()",
+		"await _client.SendAsync(request)",
+		"Assert.Equal(200, (int)response.StatusCode);",
+	}
+
+	for _, exp := range expectations {
+		if !strings.Contains(code, exp) {
+			t.Errorf("Emit() missing expected content: %s", exp)
+		}
+	}
+}
+
+func TestXUnitEmitter_EmitSingle(t *testing.T) {
+	e := &XUnitEmitter{}
+	spec := model.TestSpec{
+		Method:     "POST",
+		Path:       "/api/orders/{id}",
+		PathParams: map[string]interface{}{"id": 7},
+		Body:       map[string]interface{}{"qty": 2},
+		Headers:    map[string]string{"Authorization": "Bearer t"},
+		Assertions: []model.Assertion{
+			{Kind: "status_code", Expected: 201},
+			{Kind: "equality", Actual: "body.customer.name", Expected: "Ann"},
+			{Kind: "not_null", Actual: "body.id"},
+			{Kind: "contains", Actual: "body", Expected: "Ann"},
+		},
+	}
+
+	code, err := e.EmitSingle(spec)
+	if err != nil {
+		t.Fatalf("EmitSingle() error: %v", err)
+	}
+
+	expectations := []string{
+		`new HttpRequestMessage(new HttpMethod("POST"), "/api/orders/7")`,
+		`request.Content = new StringContent("{\"qty\":2}", Encoding.UTF8, "application/json");`,
+		`request.Headers.TryAddWithoutValidation("Authorization", "Bearer t");`,
+		"using var json = JsonDocument.Parse(body);",
+		"Assert.Equal(201, (int)response.StatusCode);",
+		`Assert.Equal("\"Ann\"", Field(json.RootElement, "customer.name")?.GetRawText());`,
+		`Assert.True(Field(json.RootElement, "id") is { ValueKind: not JsonValueKind.Null });`,
+		`Assert.Contains("Ann", body);`,
+	}
+
+	for _, exp := range expectations {
+		if !strings.Contains(code, exp) {
+			t.Errorf("EmitSingle() missing expected content: %s\n%s", exp, code)
+		}
+	}
+	if strings.Contains(code, "EnsureSuccessStatusCode") {
+		t.Error("EmitSingle() should not add a default status check when one is asserted")
+	}
+}
+
 // RSpec Emitter Tests
 func TestRSpecEmitter_Metadata(t *testing.T) {
 	e := &RSpecEmitter{}
@@ -549,6 +694,7 @@ func TestEmitter_Assertions(t *testing.T) {
 		&PytestEmitter{},
 		&GoHTTPEmitter{},
 		&JUnitEmitter{},
+		&XUnitEmitter{},
 		&RSpecEmitter{},
 	}
 
@@ -607,6 +753,7 @@ func TestEmitters_EmptySpecs(t *testing.T) {
 		&PytestEmitter{},
 		&GoHTTPEmitter{},
 		&JUnitEmitter{},
+		&XUnitEmitter{},
 		&RSpecEmitter{},
 		&PlaywrightEmitter{},
 		&CypressEmitter{},
@@ -764,6 +911,11 @@ func TestGoHTTPEmitter_EmitAssertion(t *testing.T) {
 			assertion: model.Assertion{Kind: "not_null", Actual: "body"},
 			contains:  "TODO",
 		},
+		{
+			name:      "status below",
+			assertion: model.Assertion{Kind: "less_than", Actual: "status", Expected: 500},
+			contains:  "resp.StatusCode >= 500",
+		},
 		{
 			name:      "unknown kind",
 			assertion: model.Assertion{Kind: "custom_assertion"},
@@ -1017,3 +1169,821 @@ func TestJUnitEmitter_EmitAssertion(t *testing.T) {
 		})
 	}
 }
+
+// =============================================================================
+// GherkinEmitter Tests
+// =============================================================================
+
+func TestGherkinEmitter_Metadata(t *testing.T) {
+	e := &GherkinEmitter{Runner: RunnerGodog}
+
+	if e.Name() != "godog" {
+		t.Errorf("Name() = %s, want godog", e.Name())
+	}
+	if e.Language() != "gherkin" {
+		t.Errorf("Language() = %s, want gherkin", e.Language())
+	}
+	if e.FileExtension() != ".feature" {
+		t.Errorf("FileExtension() = %s, want .feature", e.FileExtension())
+	}
+
+	var _ StepDefinitionEmitter = e
+}
+
+func TestGherkinEmitter_Emit(t *testing.T) {
+	e := &GherkinEmitter{Runner: RunnerCucumber}
+	spec := createAPITestSpec("POST", "/users", "Create a user")
+	spec.Body = map[string]interface{}{"name": "alice"}
+	spec.Assertions = append(spec.Assertions, model.Assertion{Kind: "equality", Actual: "body.name", Expected: "alice"})
+
+	code, err := e.Emit([]model.TestSpec{spec, createE2ETestSpec("/login", "User logs in")})
+	if err != nil {
+		t.Fatalf("Emit() error: %v", err)
+	}
+
+	checks := []string{
+		"Feature: Generated tests",
+		"  Scenario: Create a user",
+		"    Given the API is available",
+		`    And the request header "Content-Type" is "application/json"`,
+		`    And the request body is {"name":"alice"}`,
+		`    When I send a POST request to "/users"`,
+		"    Then the response status should be 200",
+		`    And the response field "body" should not be null`,
+		`    And the response field "body.name" should equal "alice"`,
+		"  Scenario: User logs in",
+		`    Given I open "/login"`,
+		`    When I fill "#password" with "testpass"`,
+		`    Then "@success-message" should be visible`,
+	}
+	for _, check := range checks {
+		if !strings.Contains(code, check) {
+			t.Errorf("Emit() missing %q\n%s", check, code)
+		}
+	}
+
+	if strings.Count(code, "Feature:") != 1 {
+		t.Errorf("Emit() should produce exactly one Feature")
+	}
+}
+
+func TestGherkinEmitter_EmitSteps(t *testing.T) {
+	specs := []model.TestSpec{createAPITestSpec("GET", "/users", "List users")}
+
+	tests := []struct {
+		runner string
+		file   string
+		want   string
+	}{
+		{RunnerCucumber, "step_definitions/qtest_steps.js", "require('@cucumber/cucumber')"},
+		{RunnerGodog, "steps_test.go", "func InitializeScenario(ctx *godog.ScenarioContext)"},
+		{RunnerBehave, "steps/qtest_steps.py", "from behave import given, when, then"},
+	}
+
+	for _, tt := range tests {
+		e := &GherkinEmitter{Runner: tt.runner}
+		if e.StepsFileName() != tt.file {
+			t.Errorf("%s StepsFileName() = %s, want %s", tt.runner, e.StepsFileName(), tt.file)
+		}
+
+		code, err := e.EmitSteps(specs)
+		if err != nil {
+			t.Fatalf("%s EmitSteps() error: %v", tt.runner, err)
+		}
+		if !strings.Contains(code, tt.want) {
+			t.Errorf("%s EmitSteps() missing %q", tt.runner, tt.want)
+		}
+		if strings.Contains(code, "I click") {
+			t.Errorf("%s EmitSteps() should omit E2E steps for API-only specs", tt.runner)
+		}
+	}
+
+	if _, err := (&GherkinEmitter{Runner: "unknown"}).EmitSteps(specs); err == nil {
+		t.Error("EmitSteps() expected error for unknown runner")
+	}
+}
+
+// =============================================================================
+// Report Label Tests
+// =============================================================================
+
+func TestReportLabels(t *testing.T) {
+	spec := createAPITestSpec("GET", "/users/:id", "Get user by id")
+	spec.Priority = "high"
+	spec.Tags = []string{"smoke"}
+
+	got := map[string]string{}
+	for _, l := range ReportLabels(spec) {
+		got[l.Name] = l.Value
+	}
+
+	want := map[string]string{
+		"epic":     "API tests",
+		"feature":  "/users",
+		"story":    "Get user by id",
+		"severity": "critical",
+		"AS_ID":    "test-1",
+		"tag":      "smoke",
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("label %s = %q, want %q", k, got[k], v)
+		}
+	}
+}
+
+func TestEmitters_AllureAnnotations(t *testing.T) {
+	spec := createAPITestSpec("GET", "/users", "List users")
+
+	pytest, _ := (&PytestEmitter{Allure: true}).Emit([]model.TestSpec{spec})
+	for _, want := range []string{"import allure", `@allure.epic("API tests")`, `@allure.id("test-1")`} {
+		if !strings.Contains(pytest, want) {
+			t.Errorf("pytest output missing %q", want)
+		}
+	}
+
+	jest, _ := (&SupertestEmitter{Allure: true}).Emit([]model.TestSpec{spec})
+	if !strings.Contains(jest, `await allure.feature("/users");`) {
+		t.Errorf("supertest output missing allure feature call\n%s", jest)
+	}
+
+	plain, _ := (&PytestEmitter{}).Emit([]model.TestSpec{spec})
+	if strings.Contains(plain, "allure") {
+		t.Error("allure annotations should be opt-in")
+	}
+}
+
+// =============================================================================
+// Test Tagging Tests
+// =============================================================================
+
+func TestTagConfig_TagsFor(t *testing.T) {
+	spec := createAPITestSpec("GET", "/users", "List users")
+	spec.Tags = []string{"Slow", "api", "smoke-test"}
+
+	got := DefaultTagConfig().TagsFor(spec)
+	want := []string{"api", "generated", "slow", "smoke_test"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("TagsFor() = %v, want %v", got, want)
+	}
+
+	if tags := (TagConfig{}).TagsFor(spec); tags != nil {
+		t.Errorf("disabled TagConfig should return no tags, got %v", tags)
+	}
+}
+
+func TestEmitters_Tags(t *testing.T) {
+	fast := createAPITestSpec("GET", "/users", "List users")
+	slow := createAPITestSpec("POST", "/users", "Bulk import")
+	slow.Tags = []string{TagSlow}
+	specs := []model.TestSpec{fast, slow}
+
+	goCode, _ := (&GoHTTPEmitter{Tags: DefaultTagConfig()}).Emit(specs)
+	if !strings.HasPrefix(goCode, "//go:build api || generated || slow\n\npackage main") {
+		t.Errorf("go-http output missing build constraint\n%s", goCode[:80])
+	}
+	if strings.Count(goCode, "testing.Short()") != 1 {
+		t.Error("only the slow test should skip in -short mode")
+	}
+
+	pyCode, _ := (&PytestEmitter{Tags: DefaultTagConfig()}).Emit(specs)
+	for _, want := range []string{"@pytest.mark.api\n@pytest.mark.generated\n@pytest.mark.slow\n"} {
+		if !strings.Contains(pyCode, want) {
+			t.Errorf("pytest output missing %q", want)
+		}
+	}
+
+	jsCode, _ := (&SupertestEmitter{Tags: DefaultTagConfig()}).Emit(specs)
+	if !strings.Contains(jsCode, "describe('/users @api @generated'") {
+		t.Errorf("supertest describe should carry shared tags\n%s", jsCode)
+	}
+	if !strings.Contains(jsCode, "test('Bulk import @slow'") || !strings.Contains(jsCode, "test('List users',") {
+		t.Errorf("supertest tests should carry only their own extra tags\n%s", jsCode)
+	}
+
+	plain, _ := (&GoHTTPEmitter{}).Emit(specs)
+	if strings.Contains(plain, "//go:build") {
+		t.Error("tags should be opt-in")
+	}
+}
+
+// =============================================================================
+// Slow Test Timeout/Retry Tests
+// =============================================================================
+
+func TestEmitters_SlowTimeouts(t *testing.T) {
+	fast := createAPITestSpec("GET", "/users", "List users")
+	slow := createAPITestSpec("POST", "/imports", "Bulk import")
+	slow.Tags = []string{model.TagSlow}
+	slow.Body = map[string]interface{}{"file": "big.csv"}
+	specs := []model.TestSpec{fast, slow}
+
+	goCode, _ := (&GoHTTPEmitter{}).Emit(specs)
+	for _, want := range []string{"\t\"time\"\n", "client := &http.Client{Timeout: 30 * time.Second}", "for attempt := 0; attempt <= 2; attempt++ {", "req.Body, _ = req.GetBody()"} {
+		if !strings.Contains(goCode, want) {
+			t.Errorf("go-http output missing %q", want)
+		}
+	}
+	if fastOnly, _ := (&GoHTTPEmitter{}).Emit([]model.TestSpec{fast}); strings.Contains(fastOnly, `"time"`) {
+		t.Error("go-http should not import time without slow specs")
+	}
+
+	pyCode, _ := (&PytestEmitter{}).Emit(specs)
+	if !strings.Contains(pyCode, "@pytest.mark.timeout(30)\n@pytest.mark.flaky(reruns=2)\ndef ") || strings.Count(pyCode, "timeout(") != 1 {
+		t.Errorf("pytest output should mark only the slow test\n%s", pyCode)
+	}
+
+	jsCode, _ := (&SupertestEmitter{}).Emit(specs)
+	if !strings.Contains(jsCode, "jest.retryTimes(2);") || !strings.Contains(jsCode, "  }, 30000);") {
+		t.Errorf("supertest output missing timeout/retries\n%s", jsCode)
+	}
+
+	e2e := createE2ETestSpec("/upload", "Upload large file")
+	e2e.TimeoutSeconds = 60
+	e2e.Retries = 1
+
+	pwCode, _ := (&PlaywrightEmitter{}).Emit([]model.TestSpec{e2e})
+	if !strings.Contains(pwCode, "test.setTimeout(60000);") || !strings.Contains(pwCode, "test.describe.configure({ retries: 1 });") {
+		t.Errorf("playwright output missing timeout/retries\n%s", pwCode)
+	}
+
+	cyCode, _ := (&CypressEmitter{}).Emit([]model.TestSpec{e2e})
+	if !strings.Contains(cyCode, "{ retries: 1, defaultCommandTimeout: 60000 }") {
+		t.Errorf("cypress output missing timeout/retries\n%s", cyCode)
+	}
+}
+
+func TestEmitter_LessThanStatus(t *testing.T) {
+	a := model.Assertion{Kind: "less_than", Actual: "status", Expected: 500}
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"supertest", (&SupertestEmitter{}).emitAssertion(a), "expect(response.status).toBeLessThan(500);"},
+		{"pytest", (&PytestEmitter{}).emitAssertion(a), "assert response.status_code < 500"},
+		{"rspec", (&RSpecEmitter{}).emitAssertion(a), "expect(response.status).to be < 500"},
+	}
+	for _, tt := range tests {
+		if !strings.Contains(tt.got, tt.want) {
+			t.Errorf("%s less_than = %q, want %q", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestEmitter_ApproxAndMatches(t *testing.T) {
+	approx := model.Assertion{Kind: model.AssertApprox, Actual: "body.price", Expected: 9.99, Tolerance: 0.01}
+	matches := model.Assertion{Kind: model.AssertMatches, Actual: "body.created_at", Expected: `^\d{4}-`}
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"go approx", (&GoHTTPEmitter{}).emitAssertion(approx), `jsonField(bodyBytes, "price").(float64); math.Abs(got-9.99) > 0.01`},
+		{"go matches", (&GoHTTPEmitter{}).emitAssertion(matches), `regexp.MustCompile("^\\d{4}-").MatchString(got)`},
+		{"testify approx", (&GoHTTPEmitter{Assertions: AssertTestify}).emitAssertion(approx), `assert.InDelta(t, 9.99, jsonField(bodyBytes, "price"), 0.01)`},
+		{"testify matches", (&GoHTTPEmitter{Assertions: AssertTestify}).emitAssertion(matches), `assert.Regexp(t, "^\\d{4}-", jsonField(bodyBytes, "created_at"))`},
+		{"supertest approx", (&SupertestEmitter{}).emitAssertion(approx), "expect(Math.abs(response.body.price - 9.99)).toBeLessThanOrEqual(0.01);"},
+		{"supertest matches", (&SupertestEmitter{}).emitAssertion(matches), `expect(String(response.body.created_at)).toMatch(new RegExp("^\\d{4}-"));`},
+		{"chai approx", (&SupertestEmitter{Assertions: AssertChai}).emitAssertion(approx), "expect(response.body.price).to.be.closeTo(9.99, 0.01);"},
+		{"pytest approx", (&PytestEmitter{}).emitAssertion(approx), `assert response.json()["price"] == pytest.approx(9.99, abs=0.01)`},
+		{"pytest matches", (&PytestEmitter{}).emitAssertion(matches), `assert re.search("^\\d{4}-", str(response.json()["created_at"]))`},
+		{"assertpy approx", (&PytestEmitter{Assertions: AssertAssertpy}).emitAssertion(approx), `assert_that(response.json()["price"]).is_close_to(9.99, 0.01)`},
+		{"rspec approx", (&RSpecEmitter{}).emitAssertion(approx), "to be_within(0.01).of(9.99)"},
+		{"rspec matches", (&RSpecEmitter{}).emitAssertion(matches), `.to_s).to match(Regexp.new("^\\d{4}-"))`},
+		{"junit approx", (&JUnitEmitter{}).emitAssertion(approx), `jsonPath("$.price", org.hamcrest.Matchers.closeTo(9.99, 0.01))`},
+		{"junit matches", (&JUnitEmitter{}).emitAssertion(matches), `org.hamcrest.Matchers.matchesPattern("(?s).*(?:^\\d{4}-).*")`},
+		{"xunit approx", (&XUnitEmitter{}).emitAssertion(approx), `Assert.Equal(9.99, Field(json.RootElement, "price")!.Value.GetDouble(), 0.01);`},
+		{"xunit matches", (&XUnitEmitter{}).emitAssertion(matches), `Assert.Matches("^\\d{4}-", Field(json.RootElement, "created_at")?.ToString());`},
+		{"gherkin approx", (&GherkinEmitter{}).apiAssertionStep(approx), `Then the response field "body.price" should be approximately 9.99 within 0.01`},
+		{"gherkin matches", (&GherkinEmitter{}).apiAssertionStep(matches), `Then the response field "body.created_at" should match "^\\d{4}-"`},
+	}
+	for _, tt := range tests {
+		if !strings.Contains(tt.got, tt.want) {
+			t.Errorf("%s = %q, want %q", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestEmitter_Collections(t *testing.T) {
+	length := model.Assertion{Kind: model.AssertLength, Actual: "body.items", Expected: 2}
+	all := model.Assertion{Kind: model.AssertContainsAll, Actual: "body.tags", Expected: []interface{}{"a", "b"}}
+	subset := model.Assertion{Kind: model.AssertSubset, Actual: "body.tags", Expected: []interface{}{"a", "b", "c"}}
+	sorted := model.Assertion{Kind: model.AssertSorted, Actual: "body.items", Expected: "-price"}
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"go length", (&GoHTTPEmitter{}).emitAssertion(length), `if got := len(jsonList(jsonField(bodyBytes, "items"))); got != 2 {`},
+		{"go contains_all", (&GoHTTPEmitter{}).emitAssertion(all), "for _, want := range jsonList(jsonValue(`[\"a\",\"b\"]`)) {"},
+		{"go sorted", (&GoHTTPEmitter{}).emitAssertion(sorted), `if !jsonSorted(jsonList(jsonField(bodyBytes, "items")), "price", true) {`},
+		{"testify length", (&GoHTTPEmitter{Assertions: AssertTestify}).emitAssertion(length), `assert.Len(t, jsonList(jsonField(bodyBytes, "items")), 2)`},
+		{"testify subset", (&GoHTTPEmitter{Assertions: AssertTestify}).emitAssertion(subset), "assert.Subset(t, jsonList(jsonValue(`[\"a\",\"b\",\"c\"]`)), jsonList(jsonField(bodyBytes, \"tags\")))"},
+		{"supertest length", (&SupertestEmitter{}).emitAssertion(length), "expect(response.body.items).toHaveLength(2);"},
+		{"supertest contains_all", (&SupertestEmitter{}).emitAssertion(all), `expect(response.body.tags).toEqual(expect.arrayContaining(["a","b"]));`},
+		{"supertest sorted", (&SupertestEmitter{}).emitAssertion(sorted), "expect(response.body.items).toEqual([...response.body.items].sort((a, b) => (a.price < b.price) - (a.price > b.price)));"},
+		{"chai subset", (&SupertestEmitter{Assertions: AssertChai}).emitAssertion(subset), `expect(["a","b","c"]).to.deep.include.members(response.body.tags);`},
+		{"pytest contains_all", (&PytestEmitter{}).emitAssertion(all), `assert all(item in response.json()["tags"] for item in ["a", "b"])`},
+		{"pytest sorted", (&PytestEmitter{}).emitAssertion(sorted), `assert response.json()["items"] == sorted(response.json()["items"], key=lambda item: item["price"], reverse=True)`},
+		{"assertpy subset", (&PytestEmitter{Assertions: AssertAssertpy}).emitAssertion(subset), `assert_that(response.json()["tags"]).is_subset_of(["a", "b", "c"])`},
+		{"rspec subset", (&RSpecEmitter{}).emitAssertion(subset), "- ['a', 'b', 'c']).to be_empty"},
+		{"rspec sorted", (&RSpecEmitter{}).emitAssertion(sorted), "each_cons(2).all? { |a, b| (a['price'] <=> b['price']) >= 0 }).to be(true)"},
+		{"junit length", (&JUnitEmitter{}).emitAssertion(length), `jsonPath("$.items", org.hamcrest.Matchers.hasSize(2))`},
+		{"junit contains_all", (&JUnitEmitter{}).emitAssertion(all), `jsonPath("$.tags", org.hamcrest.Matchers.hasItems("a", "b"))`},
+		{"xunit subset", (&XUnitEmitter{}).emitAssertion(subset), `Assert.Subset(new HashSet<string> { "\"a\"", "\"b\"", "\"c\"" }, Field(json.RootElement, "tags")!.Value.EnumerateArray()`},
+		{"gherkin contains_all", (&GherkinEmitter{}).apiAssertionStep(all), `Then the response field "body.tags" should include all of ["a","b"]`},
+		{"gherkin sorted", (&GherkinEmitter{}).apiAssertionStep(sorted), `Then the response field "body.items" should be sorted by "price" in descending order`},
+	}
+	for _, tt := range tests {
+		if !strings.Contains(tt.got, tt.want) {
+			t.Errorf("%s = %q, want %q", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestEmitter_HeadersAndCookies(t *testing.T) {
+	header := model.Assertion{Kind: model.AssertHeaderEquals, Actual: "Content-Type", Expected: "application/json"}
+	present := model.Assertion{Kind: model.AssertHeaderPresent, Actual: "headers.ETag"}
+	cookie := model.Assertion{Kind: model.AssertCookieMatches, Actual: "session", Expected: "^[a-f0-9]+$"}
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"go header", (&GoHTTPEmitter{}).emitAssertion(header), `if got := resp.Header.Get("Content-Type"); got != "application/json" {`},
+		{"go present", (&GoHTTPEmitter{}).emitAssertion(present), `if len(resp.Header.Values("ETag")) == 0 {`},
+		{"go cookie", (&GoHTTPEmitter{}).emitAssertion(cookie), `if got := cookieValue(resp, "session"); !regexp.MustCompile("^[a-f0-9]+$").MatchString(got) {`},
+		{"testify header", (&GoHTTPEmitter{Assertions: AssertTestify}).emitAssertion(header), `assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))`},
+		{"supertest header", (&SupertestEmitter{}).emitAssertion(header), `expect(response.headers["content-type"]).toBe("application/json");`},
+		{"supertest cookie", (&SupertestEmitter{}).emitAssertion(cookie), `expect(cookieValue(response.headers, "session")).toMatch(new RegExp("^[a-f0-9]+$"));`},
+		{"chai present", (&SupertestEmitter{Assertions: AssertChai}).emitAssertion(present), `expect(response.headers["etag"]).to.exist;`},
+		{"pytest header", (&PytestEmitter{}).emitAssertion(header), `assert response.headers.get("Content-Type") == "application/json"`},
+		{"pytest cookie", (&PytestEmitter{}).emitAssertion(cookie), `assert re.search("^[a-f0-9]+$", response.cookies.get("session", ""))`},
+		{"assertpy present", (&PytestEmitter{Assertions: AssertAssertpy}).emitAssertion(present), `assert_that(response.headers.get("ETag")).is_not_none()`},
+		{"playwright cookie", (&PlaywrightEmitter{}).emitAssertion(cookie), `(await page.context().cookies()).find((c) => c.name === "session")?.value).toMatch(`},
+		{"playwright header", (&PlaywrightEmitter{}).emitAssertion(header), "// TODO: Assert header Content-Type equals application/json"},
+		{"cypress cookie", (&CypressEmitter{}).emitAssertion(cookie), `cy.getCookie("session").its('value').should('match', new RegExp("^[a-f0-9]+$"));`},
+		{"rspec header", (&RSpecEmitter{}).emitAssertion(header), `expect(response.headers["Content-Type"]).to eq("application/json")`},
+		{"rspec cookie", (&RSpecEmitter{}).emitAssertion(cookie), `expect(response.cookies["session"].to_s).to match(Regexp.new("^[a-f0-9]+$"))`},
+		{"junit header", (&JUnitEmitter{}).emitAssertion(header), `.andExpect(header().string("Content-Type", "application/json"))`},
+		{"junit present", (&JUnitEmitter{}).emitAssertion(present), `.andExpect(header().exists("ETag"))`},
+		{"junit cookie", (&JUnitEmitter{}).emitAssertion(cookie), `.andExpect(cookie().value("session", org.hamcrest.Matchers.matchesPattern("(?s).*(?:^[a-f0-9]+$).*")))`},
+		{"xunit header", (&XUnitEmitter{}).emitAssertion(header), `Assert.Equal("application/json", Header(response, "Content-Type"));`},
+		{"xunit cookie", (&XUnitEmitter{}).emitAssertion(cookie), `Assert.Matches("^[a-f0-9]+$", Cookie(response, "session") ?? "");`},
+		{"gherkin header", (&GherkinEmitter{}).apiAssertionStep(header), `Then the response header "Content-Type" should equal "application/json"`},
+		{"gherkin present", (&GherkinEmitter{}).apiAssertionStep(present), `Then the response header "ETag" should be set`},
+		{"gherkin cookie", (&GherkinEmitter{}).apiAssertionStep(cookie), `Then the response cookie "session" should match "^[a-f0-9]+$"`},
+	}
+	for _, tt := range tests {
+		if !strings.Contains(tt.got, tt.want) {
+			t.Errorf("%s = %q, want %q", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestEmitters_ResponseHelpers(t *testing.T) {
+	spec := createAPITestSpec("POST", "/login", "Log in")
+	spec.Assertions = append(spec.Assertions,
+		model.Assertion{Kind: model.AssertHeaderPresent, Actual: "Location"},
+		model.Assertion{Kind: model.AssertCookieEquals, Actual: "session", Expected: "abc"})
+	specs := []model.TestSpec{spec}
+
+	goCode, _ := (&GoHTTPEmitter{}).Emit(specs)
+	jsCode, _ := (&SupertestEmitter{}).Emit(specs)
+	csCode, _ := (&XUnitEmitter{}).Emit(specs)
+	for _, tt := range []struct {
+		name, code, want string
+	}{
+		{"go", goCode, "func cookieValue(resp *http.Response, name string) string"},
+		{"supertest", jsCode, "function cookieValue(headers, name)"},
+		{"xunit header", csCode, "private static string? Header(HttpResponseMessage response, string name)"},
+		{"xunit cookie", csCode, "private static string? Cookie(HttpResponseMessage response, string name)"},
+	} {
+		if !strings.Contains(tt.code, tt.want) {
+			t.Errorf("%s output missing %q\n%s", tt.name, tt.want, tt.code)
+		}
+	}
+	if _, err := format.Source([]byte(goCode)); err != nil {
+		t.Errorf("generated code doesn't parse: %v\n%s", err, goCode)
+	}
+
+	plain := []model.TestSpec{createAPITestSpec("GET", "/health", "Health")}
+	goPlain, _ := (&GoHTTPEmitter{}).Emit(plain)
+	jsPlain, _ := (&SupertestEmitter{}).Emit(plain)
+	csPlain, _ := (&XUnitEmitter{}).Emit(plain)
+	for _, code := range []string{goPlain, jsPlain, csPlain} {
+		if strings.Contains(code, "cookieValue") || strings.Contains(code, "Cookie(") {
+			t.Errorf("cookie helpers should only be emitted when used\n%s", code)
+		}
+	}
+}
+
+func TestEmitters_Latency(t *testing.T) {
+	spec := createAPITestSpec("POST", "/orders", "Create order")
+	spec.Headers = map[string]string{"Authorization": "Bearer token"}
+	spec.Assertions = append(spec.Assertions, model.Assertion{
+		Kind:     model.AssertLatency,
+		Expected: map[string]interface{}{"p50": float64(100), "p95": 250.5, "samples": float64(10)},
+	})
+	specs := []model.TestSpec{spec}
+
+	goCode, _ := (&GoHTTPEmitter{}).Emit(specs)
+	testifyCode, _ := (&GoHTTPEmitter{Assertions: AssertTestify}).Emit(specs)
+	jsCode, _ := (&SupertestEmitter{}).Emit(specs)
+	chaiCode, _ := (&SupertestEmitter{Assertions: AssertChai}).Emit(specs)
+	pyCode, _ := (&PytestEmitter{}).Emit(specs)
+	tests := []struct {
+		name, code, want string
+	}{
+		{"go", goCode, "checkLatency(t, req, 10, 100*time.Millisecond, 250500*time.Microsecond)"},
+		{"go helper", goCode, "func checkLatency(t *testing.T, req *http.Request, samples int, p50, p95 time.Duration) {"},
+		{"testify", testifyCode, "checkLatency(t, req, 10, 100*time.Millisecond, 250500*time.Microsecond)"},
+		{"supertest", jsCode, "await expectLatency(() => request(app).post('/orders').set('Authorization', 'Bearer token'), 10, { p50: 100, p95: 250.5 });"},
+		{"supertest helper", jsCode, "expect(percentile(p)).toBeLessThanOrEqual(max);"},
+		{"chai helper", chaiCode, "expect(percentile(p), name + ' latency').to.be.at.most(max);"},
+		{"pytest", pyCode, "assert_latency(lambda: client.send(response.request), 10, p50=100, p95=250.5)"},
+		{"pytest helper", pyCode, "def assert_latency(send, samples, p50=None, p95=None):"},
+		{"pytest imports", pyCode, "import math\nimport time\n"},
+	}
+	for _, tt := range tests {
+		if !strings.Contains(tt.code, tt.want) {
+			t.Errorf("%s output missing %q\n%s", tt.name, tt.want, tt.code)
+		}
+	}
+	for _, code := range []string{goCode, testifyCode} {
+		if _, err := format.Source([]byte(code)); err != nil {
+			t.Errorf("generated code doesn't parse: %v\n%s", err, code)
+		}
+	}
+
+	plain := []model.TestSpec{createAPITestSpec("GET", "/health", "Health")}
+	goPlain, _ := (&GoHTTPEmitter{}).Emit(plain)
+	jsPlain, _ := (&SupertestEmitter{}).Emit(plain)
+	pyPlain, _ := (&PytestEmitter{}).Emit(plain)
+	for _, code := range []string{goPlain, jsPlain, pyPlain} {
+		if strings.Contains(code, "atency") || strings.Contains(code, `"time"`) {
+			t.Errorf("latency helpers should only be emitted when used\n%s", code)
+		}
+	}
+}
+
+func TestGoHTTPEmitter_JSONFieldHelper(t *testing.T) {
+	spec := createAPITestSpec("GET", "/products/1", "Get product")
+	spec.Assertions = append(spec.Assertions,
+		model.Assertion{Kind: model.AssertApprox, Actual: "body.price", Expected: 9.99},
+		model.Assertion{Kind: model.AssertMatches, Actual: "body.sku", Expected: "^SKU-"})
+
+	code, err := (&GoHTTPEmitter{}).Emit([]model.TestSpec{spec})
+	if err != nil {
+		t.Fatalf("Emit() error: %v", err)
+	}
+	for _, want := range []string{`"fmt"`, `"math"`, `"regexp"`, `"strconv"`, "func jsonField(body []byte, path string) interface{}"} {
+		if !strings.Contains(code, want) {
+			t.Errorf("output missing %q\n%s", want, code)
+		}
+	}
+	if _, err := format.Source([]byte(code)); err != nil {
+		t.Errorf("generated code doesn't parse: %v\n%s", err, code)
+	}
+
+	plain, _ := (&GoHTTPEmitter{}).Emit([]model.TestSpec{createAPITestSpec("GET", "/health", "Health")})
+	if strings.Contains(plain, "jsonField") || strings.Contains(plain, `"math"`) {
+		t.Errorf("helper and imports should only be emitted when used\n%s", plain)
+	}
+}
+
+// =============================================================================
+// Assertion Library Tests
+// =============================================================================
+
+func TestSetAssertionLibrary(t *testing.T) {
+	goEm := &GoHTTPEmitter{}
+	if err := SetAssertionLibrary(goEm, "Require"); err != nil || goEm.Assertions != AssertRequire {
+		t.Errorf("SetAssertionLibrary(require) = %v, Assertions = %q", err, goEm.Assertions)
+	}
+	if err := SetAssertionLibrary(goEm, "testing"); err != nil || goEm.Assertions != "" {
+		t.Errorf("the built-in style should reset Assertions, got %q (err %v)", goEm.Assertions, err)
+	}
+	if err := SetAssertionLibrary(goEm, "chai"); err == nil {
+		t.Error("chai should be rejected for Go")
+	}
+	if err := SetAssertionLibrary(&SupertestEmitter{}, AssertChai); err != nil {
+		t.Errorf("SetAssertionLibrary(chai) error: %v", err)
+	}
+	if err := SetAssertionLibrary(&XUnitEmitter{}, "fluentassertions"); err == nil {
+		t.Error("emitters without assertion options should reject a library")
+	}
+	if err := SetAssertionLibrary(&XUnitEmitter{}, ""); err != nil {
+		t.Errorf("an empty library should always be accepted, got %v", err)
+	}
+}
+
+func TestEmitters_AssertionLibraries(t *testing.T) {
+	spec := createAPITestSpec("GET", "/users", "List users")
+	spec.Assertions = append(spec.Assertions,
+		model.Assertion{Kind: "equality", Actual: "body.name", Expected: "ann"},
+		model.Assertion{Kind: "less_than", Actual: "status", Expected: 500})
+
+	tests := []struct {
+		name    string
+		em      Emitter
+		want    []string
+		notWant []string
+	}{
+		{"testify", &GoHTTPEmitter{Assertions: AssertTestify},
+			[]string{`"github.com/stretchr/testify/assert"`, `"github.com/stretchr/testify/require"`,
+				"require.NoError(t, err", "assert.Equal(t, 200, resp.StatusCode)", "assert.Less(t, resp.StatusCode, 500)"},
+			[]string{"t.Errorf", "t.Fatalf"}},
+		{"require", &GoHTTPEmitter{Assertions: AssertRequire},
+			[]string{"require.Equal(t, 200, resp.StatusCode)", "require.Contains(t, string(bodyBytes), `\"ann\"`)"},
+			[]string{`"github.com/stretchr/testify/assert"`}},
+		{"go builtin", &GoHTTPEmitter{},
+			[]string{"t.Fatalf", "t.Errorf"},
+			[]string{"testify"}},
+		{"chai", &SupertestEmitter{Assertions: AssertChai},
+			[]string{"const { expect } = require('chai');", "expect(response.status).to.equal(200);",
+				`expect(response.body.name).to.deep.equal("ann");`, "to.be.below(500)"},
+			[]string{"toBe("}},
+		{"assertpy", &PytestEmitter{Assertions: AssertAssertpy},
+			[]string{"from assertpy import assert_that", "assert_that(response.status_code).is_equal_to(200)",
+				`assert_that(response.json()["name"]).is_equal_to("ann")`},
+			[]string{"    assert "}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, err := tt.em.Emit([]model.TestSpec{spec})
+			if err != nil {
+				t.Fatalf("Emit() error: %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(code, want) {
+					t.Errorf("output missing %q\n%s", want, code)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(code, notWant) {
+					t.Errorf("output should not contain %q\n%s", notWant, code)
+				}
+			}
+		})
+	}
+}
+
+func TestDetectAssertionLibrary(t *testing.T) {
+	write := func(t *testing.T, dir, name, content string) {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Run("go tests importing require", func(t *testing.T) {
+		dir := t.TempDir()
+		write(t, dir, "go.mod", "module x\n\nrequire github.com/stretchr/testify v1.9.0\n")
+		write(t, dir, "a_test.go", "import (\n\t\"github.com/stretchr/testify/assert\"\n\t\"github.com/stretchr/testify/require\"\n)\n")
+		if got := DetectAssertionLibrary(dir, "go"); got != AssertRequire {
+			t.Errorf("DetectAssertionLibrary() = %q, want require", got)
+		}
+	})
+
+	t.Run("go dependency without tests", func(t *testing.T) {
+		dir := t.TempDir()
+		write(t, dir, "go.mod", "module x\n\nrequire github.com/stretchr/testify v1.9.0\n")
+		if got := DetectAssertionLibrary(dir, "go"); got != AssertTestify {
+			t.Errorf("DetectAssertionLibrary() = %q, want testify", got)
+		}
+	})
+
+	t.Run("chai in typescript tests", func(t *testing.T) {
+		dir := t.TempDir()
+		write(t, dir, "test/users.spec.ts", "import { expect } from 'chai';\n")
+		write(t, dir, "node_modules/x/y.test.js", "require('chai')\n")
+		if got := DetectAssertionLibrary(dir, "typescript"); got != AssertChai {
+			t.Errorf("DetectAssertionLibrary() = %q, want chai", got)
+		}
+	})
+
+	t.Run("assertpy requirement", func(t *testing.T) {
+		dir := t.TempDir()
+		write(t, dir, "requirements-dev.txt", "pytest\nassertpy==1.1\n")
+		if got := DetectAssertionLibrary(dir, "python"); got != AssertAssertpy {
+			t.Errorf("DetectAssertionLibrary() = %q, want assertpy", got)
+		}
+	})
+
+	t.Run("plain project", func(t *testing.T) {
+		dir := t.TempDir()
+		write(t, dir, "test_app.py", "def test_ok():\n    assert True\n")
+		if got := DetectAssertionLibrary(dir, "python"); got != "" {
+			t.Errorf("DetectAssertionLibrary() = %q, want the built-in style", got)
+		}
+	})
+}
+
+func TestEmitters_GraphQL(t *testing.T) {
+	spec := model.TestSpec{
+		ID:    "gql-1",
+		Level: model.LevelAPI,
+		GraphQL: &model.GraphQLRequest{
+			Query:     "mutation($name: String!) { createUser(name: $name) { id } }",
+			Variables: map[string]interface{}{"name": "ann"},
+		},
+		Expected: map[string]interface{}{"status": 200},
+		Assertions: []model.Assertion{
+			{Kind: "not_null", Actual: "body.data.createUser"},
+		},
+	}
+
+	js, err := (&SupertestEmitter{}).Emit([]model.TestSpec{spec})
+	if err != nil {
+		t.Fatalf("SupertestEmitter.Emit() error: %v", err)
+	}
+	for _, want := range []string{
+		".post('/graphql')",
+		`"query"`,
+		`"variables"`,
+		"mutation createUser returns data",
+		"expect(response.body.errors).toBeUndefined();",
+	} {
+		if !strings.Contains(js, want) {
+			t.Errorf("supertest output missing %q:\n%s", want, js)
+		}
+	}
+
+	py, err := (&PytestEmitter{Assertions: AssertAssertpy}).Emit([]model.TestSpec{spec})
+	if err != nil {
+		t.Fatalf("PytestEmitter.Emit() error: %v", err)
+	}
+	for _, want := range []string{
+		"def test_graphql_mutation_create_user(",
+		"client.post(",
+		`"/graphql"`,
+		`assert_that(response.json()).does_not_contain_key("errors")`,
+	} {
+		if !strings.Contains(py, want) {
+			t.Errorf("pytest output missing %q:\n%s", want, py)
+		}
+	}
+}
+
+func TestGraphQLOperation(t *testing.T) {
+	tests := []struct {
+		req        model.GraphQLRequest
+		kind, name string
+	}{
+		{model.GraphQLRequest{Query: "{ users { id } }"}, "query", "users"},
+		{model.GraphQLRequest{Query: "query Users($n: Int) { all: users(first: $n) { id } }"}, "query", "users"},
+		{model.GraphQLRequest{Query: "mutation { addPet(name: \"x\") { id } }"}, "mutation", "addPet"},
+		{model.GraphQLRequest{Query: "query { a }", OperationName: "ListA"}, "query", "ListA"},
+	}
+	for _, tt := range tests {
+		kind, name := graphQLOperation(&tt.req)
+		if kind != tt.kind || name != tt.name {
+			t.Errorf("graphQLOperation(%q) = %s %s, want %s %s", tt.req.Query, kind, name, tt.kind, tt.name)
+		}
+	}
+}
+
+func TestGRPCGoEmitter_Emit(t *testing.T) {
+	call := model.GRPCCall{
+		Service:      "users.v1.UserService",
+		Method:       "GetUser",
+		RequestType:  "GetUserRequest",
+		Request:      map[string]interface{}{"user_id": "u1"},
+		Stubs:        "example.com/app/gen/users/v1",
+		Server:       "&server{}",
+		ServerModule: "main",
+	}
+	missing := call
+	missing.Code = "NOT_FOUND"
+
+	specs := []model.TestSpec{
+		{ID: "grpc-1", GRPC: &call, Assertions: []model.Assertion{{Kind: "equality", Actual: "response.user.user_id", Expected: "u1"}}},
+		{ID: "grpc-2", GRPC: &missing},
+	}
+	out, err := (&GRPCGoEmitter{}).Emit(specs)
+	if err != nil {
+		t.Fatalf("Emit() error: %v", err)
+	}
+	for _, want := range []string{
+		"package main",
+		`usersv1 "example.com/app/gen/users/v1"`,
+		`"google.golang.org/grpc/test/bufconn"`,
+		"func TestUserService_GetUser(t *testing.T) {",
+		"usersv1.RegisterUserServiceServer(s, &server{})",
+		"client := usersv1.NewUserServiceClient(conn)",
+		`UserId: "u1",`,
+		`if got := resp.GetUser().GetUserId(); got != "u1" {`,
+		"func TestUserService_GetUser_NotFound(t *testing.T) {",
+		"status.Code(err); got != codes.NotFound",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	// Without a known server, tests dial GRPC_TARGET
+	call.Server = ""
+	out, err = (&GRPCGoEmitter{}).Emit([]model.TestSpec{{ID: "grpc-1", GRPC: &call}})
+	if err != nil {
+		t.Fatalf("Emit() error: %v", err)
+	}
+	if !strings.Contains(out, `os.Getenv("GRPC_TARGET")`) || strings.Contains(out, "bufconn") {
+		t.Errorf("expected a GRPC_TARGET dialer:\n%s", out)
+	}
+
+	if _, err := (&GRPCGoEmitter{}).Emit([]model.TestSpec{createAPITestSpec("GET", "/users", "list")}); err == nil {
+		t.Error("Emit() of HTTP specs expected error")
+	}
+
+	// With a timeout, calls get a context with a deadline
+	out, err = (&GRPCGoEmitter{Timeout: 5}).Emit(specs)
+	if err != nil {
+		t.Fatalf("Emit() error: %v", err)
+	}
+	for _, want := range []string{
+		"\t\"time\"\n",
+		"ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)",
+		"resp, err := client.GetUser(ctx, req)",
+		"_, err := client.GetUser(ctx, req)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestPytestGRPCEmitter_Emit(t *testing.T) {
+	call := model.GRPCCall{
+		Service:      "users.v1.UserService",
+		Method:       "GetUser",
+		RequestType:  "GetUserRequest",
+		Request:      map[string]interface{}{"id": float64(7)},
+		Stubs:        "gen.users_pb2_grpc",
+		Server:       "UserServicer()",
+		ServerModule: "server",
+	}
+	missing := call
+	missing.Code = "NOT_FOUND"
+
+	specs := []model.TestSpec{
+		{ID: "grpc-1", GRPC: &call, Assertions: []model.Assertion{{Kind: "equality", Actual: "response.name", Expected: "ann"}}},
+		{ID: "grpc-2", GRPC: &missing},
+	}
+	out, err := (&PytestGRPCEmitter{}).Emit(specs)
+	if err != nil {
+		t.Fatalf("Emit() error: %v", err)
+	}
+	for _, want := range []string{
+		"from gen import users_pb2, users_pb2_grpc",
+		"from server import UserServicer",
+		"return users_pb2_grpc.add_UserServiceServicer_to_server",
+		"return UserServicer()",
+		"def test_get_user(grpc_stub):",
+		"request = users_pb2.GetUserRequest(id=7)",
+		`assert response.name == "ann"`,
+		"def test_get_user_not_found(grpc_stub):",
+		"assert exc_info.value.code() == grpc.StatusCode.NOT_FOUND",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	call.Server = ""
+	out, err = (&PytestGRPCEmitter{}).Emit([]model.TestSpec{{ID: "grpc-1", GRPC: &call}})
+	if err != nil {
+		t.Fatalf("Emit() error: %v", err)
+	}
+	for _, want := range []string{`os.environ.get("GRPC_TARGET", "localhost:50051")`, "stub = users_pb2_grpc.UserServiceStub(grpc_channel)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestGRPCNames(t *testing.T) {
+	if got := goStubsAlias("example.com/app/gen/users/v1"); got != "usersv1" {
+		t.Errorf("goStubsAlias() = %s, want usersv1", got)
+	}
+	if got := goStubsAlias("example.com/app/userpb"); got != "userpb" {
+		t.Errorf("goStubsAlias() = %s, want userpb", got)
+	}
+	if got := goFieldName("user_id"); got != "UserId" {
+		t.Errorf("goFieldName() = %s, want UserId", got)
+	}
+	for code, want := range map[string]string{"": "OK", "ok": "OK", "NOT_FOUND": "NotFound", "invalid_argument": "InvalidArgument"} {
+		if got := goStatusCode(code); got != want {
+			t.Errorf("goStatusCode(%q) = %s, want %s", code, got, want)
+		}
+	}
+	if got := responseField("response.user.name"); got != "user.name" {
+		t.Errorf("responseField() = %s, want user.name", got)
+	}
+}
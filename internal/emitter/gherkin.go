@@ -0,0 +1,1030 @@
+package emitter
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/QTest-hq/qtest/pkg/model"
+)
+
+// StepDefinitionEmitter is implemented by BDD emitters that produce step
+// definitions alongside their feature files
+type StepDefinitionEmitter interface {
+	Emitter
+
+	// StepsFileName returns the file name for generated step definitions
+	StepsFileName() string
+
+	// EmitSteps generates the step definitions backing the emitted features
+	EmitSteps(specs []model.TestSpec) (string, error)
+}
+
+// BDD runners supported by GherkinEmitter
+const (
+	RunnerCucumber = "cucumber"
+	RunnerGodog    = "godog"
+	RunnerBehave   = "behave"
+)
+
+// GherkinEmitter generates Gherkin .feature files for API and E2E specs,
+// with step definitions for cucumber-js, godog or behave
+type GherkinEmitter struct {
+	Runner string
+}
+
+func (e *GherkinEmitter) Name() string          { return e.Runner }
+func (e *GherkinEmitter) Language() string      { return "gherkin" }
+func (e *GherkinEmitter) Framework() string     { return e.Runner }
+func (e *GherkinEmitter) FileExtension() string { return ".feature" }
+
+// StepsFileName returns the step definitions file name for the runner
+func (e *GherkinEmitter) StepsFileName() string {
+	switch e.Runner {
+	case RunnerGodog:
+		return "steps_test.go"
+	case RunnerBehave:
+		return "steps/qtest_steps.py"
+	default:
+		return "step_definitions/qtest_steps.js"
+	}
+}
+
+// Emit generates a feature file. A .feature file holds a single Feature, so
+// path groups are rendered as comments ahead of their scenarios.
+func (e *GherkinEmitter) Emit(specs []model.TestSpec) (string, error) {
+	var sb strings.Builder
+
+	sb.WriteString("Feature: Generated tests\n")
+	sb.WriteString("  Scenarios generated by QTest from test specifications\n")
+
+	groups := e.groupByFeature(specs)
+	for _, name := range sortedKeys(groups) {
+		sb.WriteString(fmt.Sprintf("\n  # %s\n", name))
+
+		for _, spec := range groups[name] {
+			scenario, err := e.emitScenario(spec)
+			if err != nil {
+				continue
+			}
+			sb.WriteString("\n")
+			sb.WriteString(scenario)
+		}
+	}
+
+	return sb.String(), nil
+}
+
+// EmitSingle generates a single scenario
+func (e *GherkinEmitter) EmitSingle(spec model.TestSpec) (string, error) {
+	return e.emitScenario(spec)
+}
+
+func (e *GherkinEmitter) emitScenario(spec model.TestSpec) (string, error) {
+	var sb strings.Builder
+
+	for _, tag := range spec.Tags {
+		sb.WriteString(fmt.Sprintf("  @%s\n", strings.ReplaceAll(tag, " ", "_")))
+	}
+	sb.WriteString(fmt.Sprintf("  Scenario: %s\n", e.generateScenarioName(spec)))
+
+	var steps []string
+	if spec.Level == model.LevelE2E {
+		steps = e.e2eSteps(spec)
+	} else {
+		steps = e.apiSteps(spec)
+	}
+
+	// Gherkin reads better with "And" for repeated keywords
+	prev := ""
+	for _, step := range steps {
+		keyword, text, _ := strings.Cut(step, " ")
+		if keyword == prev {
+			keyword = "And"
+		} else {
+			prev = keyword
+		}
+		sb.WriteString(fmt.Sprintf("    %s %s\n", keyword, text))
+	}
+
+	return sb.String(), nil
+}
+
+func (e *GherkinEmitter) apiSteps(spec model.TestSpec) []string {
+	steps := []string{"Given the API is available"}
+
+	for _, key := range sortedKeys(spec.Headers) {
+		steps = append(steps, fmt.Sprintf("Given the request header %s is %s", quoteStep(key), quoteStep(spec.Headers[key])))
+	}
+
+	if spec.Body != nil && (spec.Method == "POST" || spec.Method == "PUT" || spec.Method == "PATCH") {
+		bodyJSON, _ := json.Marshal(spec.Body)
+		steps = append(steps, fmt.Sprintf("Given the request body is %s", string(bodyJSON)))
+	}
+
+	steps = append(steps, fmt.Sprintf("When I send a %s request to %s", strings.ToUpper(spec.Method), quoteStep(resolveSpecPath(spec))))
+
+	for _, a := range spec.Assertions {
+		if step := e.apiAssertionStep(a); step != "" {
+			steps = append(steps, step)
+		}
+	}
+
+	return steps
+}
+
+func (e *GherkinEmitter) apiAssertionStep(a model.Assertion) string {
+	expectedJSON, _ := json.Marshal(a.Expected)
+
+	switch a.Kind {
+	case "status_code":
+		return fmt.Sprintf("Then the response status should be %v", a.Expected)
+	case "equality":
+		return fmt.Sprintf("Then the response field %s should equal %s", quoteStep(a.Actual), string(expectedJSON))
+	case "contains":
+		return fmt.Sprintf("Then the response field %s should contain %s", quoteStep(a.Actual), string(expectedJSON))
+	case "not_null":
+		return fmt.Sprintf("Then the response field %s should not be null", quoteStep(a.Actual))
+	case model.AssertLength:
+		return fmt.Sprintf("Then the response field %s should have length %v", quoteStep(a.Actual), a.Expected)
+	case model.AssertContainsAll:
+		return fmt.Sprintf("Then the response field %s should include all of %s", quoteStep(a.Actual), expectedItemsJSON(a))
+	case model.AssertSubset:
+		return fmt.Sprintf("Then the response field %s should be a subset of %s", quoteStep(a.Actual), expectedItemsJSON(a))
+	case model.AssertSorted:
+		key, desc := a.SortKey()
+		order := "ascending"
+		if desc {
+			order = "descending"
+		}
+		if key != "" {
+			return fmt.Sprintf("Then the response field %s should be sorted by %s in %s order", quoteStep(a.Actual), quoteStep(key), order)
+		}
+		return fmt.Sprintf("Then the response field %s should be sorted in %s order", quoteStep(a.Actual), order)
+	case model.AssertApprox:
+		expected, eps := approxValues(a)
+		return fmt.Sprintf("Then the response field %s should be approximately %s within %s", quoteStep(a.Actual), expected, eps)
+	case model.AssertMatches:
+		patternJSON, _ := json.Marshal(regexPattern(a))
+		return fmt.Sprintf("Then the response field %s should match %s", quoteStep(a.Actual), string(patternJSON))
+	case model.AssertHeaderPresent, model.AssertHeaderEquals, model.AssertHeaderMatches,
+		model.AssertCookiePresent, model.AssertCookieEquals, model.AssertCookieMatches:
+		target, check, _ := a.ResponseCheck()
+		subject := fmt.Sprintf("Then the response %s %s", target, quoteStep(a.ResponseName()))
+		switch check {
+		case model.CheckPresent:
+			return subject + " should be set"
+		case model.CheckEquals:
+			valueJSON, _ := json.Marshal(a.ExpectedString())
+			return fmt.Sprintf("%s should equal %s", subject, string(valueJSON))
+		default:
+			patternJSON, _ := json.Marshal(regexPattern(a))
+			return fmt.Sprintf("%s should match %s", subject, string(patternJSON))
+		}
+	default:
+		return ""
+	}
+}
+
+func (e *GherkinEmitter) e2eSteps(spec model.TestSpec) []string {
+	var steps []string
+
+	if spec.Path != "" {
+		steps = append(steps, fmt.Sprintf("Given I open %s", quoteStep(spec.Path)))
+	}
+
+	if spec.Inputs != nil {
+		if url, ok := spec.Inputs["url"].(string); ok {
+			steps = append(steps, fmt.Sprintf("Given I open %s", quoteStep(url)))
+		}
+		if fills, ok := spec.Inputs["fill"].(map[string]interface{}); ok {
+			for _, selector := range sortedKeys(fills) {
+				steps = append(steps, fmt.Sprintf("When I fill %s with %s", quoteStep(selector), quoteStep(fmt.Sprintf("%v", fills[selector]))))
+			}
+		}
+		if selector, ok := spec.Inputs["click"].(string); ok {
+			steps = append(steps, fmt.Sprintf("When I click %s", quoteStep(selector)))
+		}
+		if custom, ok := spec.Inputs["steps"].([]interface{}); ok {
+			for _, step := range custom {
+				stepMap, ok := step.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				action, _ := stepMap["action"].(string)
+				selector, _ := stepMap["selector"].(string)
+				value, _ := stepMap["value"].(string)
+				switch action {
+				case "navigate", "goto":
+					if url, ok := stepMap["url"].(string); ok {
+						steps = append(steps, fmt.Sprintf("When I open %s", quoteStep(url)))
+					}
+				case "click":
+					steps = append(steps, fmt.Sprintf("When I click %s", quoteStep(selector)))
+				case "fill", "type":
+					steps = append(steps, fmt.Sprintf("When I fill %s with %s", quoteStep(selector), quoteStep(value)))
+				}
+			}
+		}
+	}
+
+	for _, a := range spec.Assertions {
+		switch a.Kind {
+		case "visible":
+			steps = append(steps, fmt.Sprintf("Then %s should be visible", quoteStep(a.Actual)))
+		case "hidden":
+			steps = append(steps, fmt.Sprintf("Then %s should be hidden", quoteStep(a.Actual)))
+		case "text", "equality":
+			steps = append(steps, fmt.Sprintf("Then %s should have text %s", quoteStep(a.Actual), quoteStep(fmt.Sprintf("%v", a.Expected))))
+		case "contains":
+			steps = append(steps, fmt.Sprintf("Then %s should contain text %s", quoteStep(a.Actual), quoteStep(fmt.Sprintf("%v", a.Expected))))
+		}
+	}
+
+	return steps
+}
+
+func (e *GherkinEmitter) generateScenarioName(spec model.TestSpec) string {
+	if spec.Description != "" {
+		return spec.Description
+	}
+	if spec.Level == model.LevelE2E {
+		return fmt.Sprintf("User visits %s", spec.Path)
+	}
+	return fmt.Sprintf("%s %s returns expected response", spec.Method, spec.Path)
+}
+
+func (e *GherkinEmitter) groupByFeature(specs []model.TestSpec) map[string][]model.TestSpec {
+	groups := make(map[string][]model.TestSpec)
+
+	for _, spec := range specs {
+		groupName := "API"
+		if spec.Level == model.LevelE2E {
+			groupName = "User journeys"
+		}
+		parts := strings.Split(strings.TrimPrefix(spec.Path, "/"), "/")
+		if len(parts) > 0 && parts[0] != "" {
+			groupName = "/" + parts[0]
+		}
+
+		groups[groupName] = append(groups[groupName], spec)
+	}
+
+	return groups
+}
+
+// EmitSteps generates step definitions for the configured runner
+func (e *GherkinEmitter) EmitSteps(specs []model.TestSpec) (string, error) {
+	hasE2E := false
+	for _, spec := range specs {
+		if spec.Level == model.LevelE2E {
+			hasE2E = true
+			break
+		}
+	}
+
+	switch e.Runner {
+	case RunnerCucumber:
+		return cucumberSteps(hasE2E), nil
+	case RunnerGodog:
+		return godogSteps(hasE2E), nil
+	case RunnerBehave:
+		return behaveSteps(hasE2E), nil
+	default:
+		return "", fmt.Errorf("unsupported BDD runner: %s", e.Runner)
+	}
+}
+
+func cucumberSteps(withE2E bool) string {
+	var sb strings.Builder
+
+	sb.WriteString(`const { Given, When, Then } = require('@cucumber/cucumber');
+const assert = require('assert');
+
+const BASE_URL = process.env.QTEST_BASE_URL || 'http://localhost:3000';
+
+function field(response, path) {
+  return path.split('.').reduce((obj, key) => (obj == null ? undefined : obj[key]), response);
+}
+
+function responseValue(response, target, name) {
+  if (target === 'header') {
+    return response.headers[name.toLowerCase()];
+  }
+  const cookie = response.cookies.find((c) => c.startsWith(name + '='));
+  return cookie === undefined ? undefined : cookie.split(';')[0].slice(name.length + 1);
+}
+
+Given('the API is available', function () {
+  this.headers = {};
+  this.body = undefined;
+});
+
+Given('the request header {string} is {string}', function (key, value) {
+  this.headers[key] = value;
+});
+
+Given(/^the request body is (.+)$/, function (body) {
+  this.body = body;
+});
+
+When(/^I send a (\w+) request to "([^"]*)"$/, async function (method, path) {
+  const res = await fetch(BASE_URL + path, { method, headers: this.headers, body: this.body });
+  const text = await res.text();
+  let body = text;
+  try { body = JSON.parse(text); } catch (e) {}
+  const cookies = res.headers.getSetCookie ? res.headers.getSetCookie() : [];
+  this.response = { status: res.status, headers: Object.fromEntries(res.headers), cookies, body };
+});
+
+Then('the response status should be {int}', function (status) {
+  assert.strictEqual(this.response.status, status);
+});
+
+Then(/^the response field "([^"]*)" should equal (.+)$/, function (path, expected) {
+  assert.deepStrictEqual(field(this.response, path), JSON.parse(expected));
+});
+
+Then(/^the response field "([^"]*)" should contain (.+)$/, function (path, expected) {
+  const actual = field(this.response, path);
+  const want = JSON.parse(expected);
+  assert.ok(typeof actual === 'string' ? actual.includes(want) : actual.some((v) => JSON.stringify(v) === JSON.stringify(want)));
+});
+
+Then('the response field {string} should not be null', function (path) {
+  assert.ok(field(this.response, path) != null);
+});
+
+Then(/^the response field "([^"]*)" should be approximately (\S+) within (\S+)$/, function (path, expected, eps) {
+  assert.ok(Math.abs(Number(field(this.response, path)) - Number(expected)) <= Number(eps));
+});
+
+Then(/^the response field "([^"]*)" should match (.+)$/, function (path, pattern) {
+  assert.match(String(field(this.response, path)), new RegExp(JSON.parse(pattern)));
+});
+
+Then(/^the response field "([^"]*)" should have length (\d+)$/, function (path, length) {
+  assert.strictEqual(field(this.response, path).length, Number(length));
+});
+
+Then(/^the response field "([^"]*)" should include all of (.+)$/, function (path, expected) {
+  const actual = field(this.response, path).map((v) => JSON.stringify(v));
+  for (const want of JSON.parse(expected)) {
+    assert.ok(actual.includes(JSON.stringify(want)), 'missing ' + JSON.stringify(want));
+  }
+});
+
+Then(/^the response field "([^"]*)" should be a subset of (.+)$/, function (path, expected) {
+  const allowed = JSON.parse(expected).map((v) => JSON.stringify(v));
+  for (const item of field(this.response, path)) {
+    assert.ok(allowed.includes(JSON.stringify(item)), 'unexpected ' + JSON.stringify(item));
+  }
+});
+
+Then(/^the response field "([^"]*)" should be sorted(?: by "([^"]*)")? in (ascending|descending) order$/, function (path, key, order) {
+  const values = field(this.response, path).map((v) => (key ? v[key] : v));
+  for (let i = 1; i < values.length; i++) {
+    const [a, b] = order === 'descending' ? [values[i], values[i - 1]] : [values[i - 1], values[i]];
+    assert.ok(a <= b, 'items ' + (i - 1) + ' and ' + i + ' are out of order');
+  }
+});
+
+Then(/^the response (header|cookie) "([^"]*)" should be set$/, function (target, name) {
+  assert.ok(responseValue(this.response, target, name) !== undefined, target + ' ' + name + ' is not set');
+});
+
+Then(/^the response (header|cookie) "([^"]*)" should equal (.+)$/, function (target, name, expected) {
+  assert.strictEqual(responseValue(this.response, target, name), JSON.parse(expected));
+});
+
+Then(/^the response (header|cookie) "([^"]*)" should match (.+)$/, function (target, name, pattern) {
+  assert.match(String(responseValue(this.response, target, name)), new RegExp(JSON.parse(pattern)));
+});
+`)
+
+	if withE2E {
+		sb.WriteString(`
+// E2E steps expect a Playwright page on the World (this.page)
+Given('I open {string}', async function (url) {
+  await this.page.goto(url);
+});
+
+When('I click {string}', async function (selector) {
+  await this.page.click(selector);
+});
+
+When('I fill {string} with {string}', async function (selector, value) {
+  await this.page.fill(selector, value);
+});
+
+Then('{string} should be visible', async function (selector) {
+  assert.ok(await this.page.isVisible(selector));
+});
+
+Then('{string} should be hidden', async function (selector) {
+  assert.ok(await this.page.isHidden(selector));
+});
+
+Then('{string} should have text {string}', async function (selector, text) {
+  assert.strictEqual((await this.page.textContent(selector)).trim(), text);
+});
+
+Then('{string} should contain text {string}', async function (selector, text) {
+  assert.ok((await this.page.textContent(selector)).includes(text));
+});
+`)
+	}
+
+	return sb.String()
+}
+
+func godogSteps(withE2E bool) string {
+	var sb strings.Builder
+
+	sb.WriteString(`package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/cucumber/godog"
+)
+
+type apiFeature struct {
+	baseURL  string
+	headers  map[string]string
+	body     string
+	status   int
+	header   http.Header
+	cookies  []*http.Cookie
+	response interface{}
+}
+
+func (a *apiFeature) theAPIIsAvailable() error {
+	a.baseURL = os.Getenv("QTEST_BASE_URL")
+	if a.baseURL == "" {
+		a.baseURL = "http://localhost:8080"
+	}
+	a.headers = map[string]string{}
+	a.body = ""
+	return nil
+}
+
+func (a *apiFeature) theRequestHeaderIs(key, value string) error {
+	a.headers[key] = value
+	return nil
+}
+
+func (a *apiFeature) theRequestBodyIs(body string) error {
+	a.body = body
+	return nil
+}
+
+func (a *apiFeature) iSendARequestTo(method, path string) error {
+	req, err := http.NewRequest(method, a.baseURL+path, strings.NewReader(a.body))
+	if err != nil {
+		return err
+	}
+	for k, v := range a.headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, _ := io.ReadAll(resp.Body)
+	a.status = resp.StatusCode
+	a.header = resp.Header
+	a.cookies = resp.Cookies()
+	var body interface{}
+	if err := json.Unmarshal(data, &body); err != nil {
+		body = string(data)
+	}
+	a.response = map[string]interface{}{"status": float64(resp.StatusCode), "body": body}
+	return nil
+}
+
+func (a *apiFeature) field(path string) interface{} {
+	var cur interface{} = a.response
+	for _, key := range strings.Split(path, ".") {
+		switch v := cur.(type) {
+		case map[string]interface{}:
+			cur = v[key]
+		case []interface{}:
+			i, err := strconv.Atoi(key)
+			if err != nil || i >= len(v) {
+				return nil
+			}
+			cur = v[i]
+		default:
+			return nil
+		}
+	}
+	return cur
+}
+
+func (a *apiFeature) theResponseStatusShouldBe(status int) error {
+	if a.status != status {
+		return fmt.Errorf("status = %d, want %d", a.status, status)
+	}
+	return nil
+}
+
+func (a *apiFeature) theResponseFieldShouldEqual(path, expected string) error {
+	var want interface{}
+	if err := json.Unmarshal([]byte(expected), &want); err != nil {
+		return err
+	}
+	if got := a.field(path); !reflect.DeepEqual(got, want) {
+		return fmt.Errorf("%s = %v, want %v", path, got, want)
+	}
+	return nil
+}
+
+func (a *apiFeature) theResponseFieldShouldContain(path, expected string) error {
+	var want interface{}
+	if err := json.Unmarshal([]byte(expected), &want); err != nil {
+		return err
+	}
+	switch got := a.field(path).(type) {
+	case string:
+		if s, ok := want.(string); ok && strings.Contains(got, s) {
+			return nil
+		}
+	case []interface{}:
+		for _, v := range got {
+			if reflect.DeepEqual(v, want) {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("%s does not contain %v", path, want)
+}
+
+func (a *apiFeature) theResponseFieldShouldNotBeNull(path string) error {
+	if a.field(path) == nil {
+		return fmt.Errorf("%s is null", path)
+	}
+	return nil
+}
+
+func (a *apiFeature) theResponseFieldShouldBeApproximately(path string, expected, eps float64) error {
+	got, ok := a.field(path).(float64)
+	if !ok || math.Abs(got-expected) > eps {
+		return fmt.Errorf("%s = %v, want %v within %v", path, a.field(path), expected, eps)
+	}
+	return nil
+}
+
+func (a *apiFeature) items(path string) ([]interface{}, error) {
+	items, ok := a.field(path).([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s is not a list", path)
+	}
+	return items, nil
+}
+
+func (a *apiFeature) theResponseFieldShouldHaveLength(path string, length int) error {
+	items, err := a.items(path)
+	if err != nil {
+		return err
+	}
+	if len(items) != length {
+		return fmt.Errorf("%s has %d items, want %d", path, len(items), length)
+	}
+	return nil
+}
+
+func (a *apiFeature) theResponseFieldShouldIncludeAllOf(path, expected string) error {
+	items, err := a.items(path)
+	if err != nil {
+		return err
+	}
+	var want []interface{}
+	if err := json.Unmarshal([]byte(expected), &want); err != nil {
+		return err
+	}
+	for _, w := range want {
+		if !containsItem(items, w) {
+			return fmt.Errorf("%s does not contain %v", path, w)
+		}
+	}
+	return nil
+}
+
+func (a *apiFeature) theResponseFieldShouldBeASubsetOf(path, expected string) error {
+	items, err := a.items(path)
+	if err != nil {
+		return err
+	}
+	var allowed []interface{}
+	if err := json.Unmarshal([]byte(expected), &allowed); err != nil {
+		return err
+	}
+	for _, item := range items {
+		if !containsItem(allowed, item) {
+			return fmt.Errorf("%s has unexpected item %v", path, item)
+		}
+	}
+	return nil
+}
+
+func (a *apiFeature) theResponseFieldShouldBeSorted(path, key, order string) error {
+	items, err := a.items(path)
+	if err != nil {
+		return err
+	}
+	for i := 1; i < len(items); i++ {
+		x, y := items[i-1], items[i]
+		if key != "" {
+			xm, _ := x.(map[string]interface{})
+			ym, _ := y.(map[string]interface{})
+			x, y = xm[key], ym[key]
+		}
+		if order == "descending" {
+			x, y = y, x
+		}
+		if lessValue(y, x) {
+			return fmt.Errorf("%s items %d and %d are out of order", path, i-1, i)
+		}
+	}
+	return nil
+}
+
+func containsItem(items []interface{}, want interface{}) bool {
+	for _, item := range items {
+		if reflect.DeepEqual(item, want) {
+			return true
+		}
+	}
+	return false
+}
+
+// lessValue orders JSON numbers and strings
+func lessValue(x, y interface{}) bool {
+	switch v := x.(type) {
+	case float64:
+		w, ok := y.(float64)
+		return ok && v < w
+	case string:
+		w, ok := y.(string)
+		return ok && v < w
+	}
+	return false
+}
+
+func (a *apiFeature) theResponseFieldShouldMatch(path, pattern string) error {
+	var expr string
+	if err := json.Unmarshal([]byte(pattern), &expr); err != nil {
+		return err
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return err
+	}
+	if got := fmt.Sprint(a.field(path)); !re.MatchString(got) {
+		return fmt.Errorf("%s = %q, want a match of %s", path, got, expr)
+	}
+	return nil
+}
+
+// responseValue returns a response header or cookie, and whether it is set
+func (a *apiFeature) responseValue(target, name string) (string, bool) {
+	if target == "header" {
+		values := a.header.Values(name)
+		return strings.Join(values, ", "), len(values) > 0
+	}
+	for _, c := range a.cookies {
+		if c.Name == name {
+			return c.Value, true
+		}
+	}
+	return "", false
+}
+
+func (a *apiFeature) theResponseValueShouldBeSet(target, name string) error {
+	if _, ok := a.responseValue(target, name); !ok {
+		return fmt.Errorf("%s %s is not set", target, name)
+	}
+	return nil
+}
+
+func (a *apiFeature) theResponseValueShouldEqual(target, name, expected string) error {
+	var want string
+	if err := json.Unmarshal([]byte(expected), &want); err != nil {
+		return err
+	}
+	if got, _ := a.responseValue(target, name); got != want {
+		return fmt.Errorf("%s %s = %q, want %q", target, name, got, want)
+	}
+	return nil
+}
+
+func (a *apiFeature) theResponseValueShouldMatch(target, name, pattern string) error {
+	var expr string
+	if err := json.Unmarshal([]byte(pattern), &expr); err != nil {
+		return err
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return err
+	}
+	if got, _ := a.responseValue(target, name); !re.MatchString(got) {
+		return fmt.Errorf("%s %s = %q, want a match of %s", target, name, got, expr)
+	}
+	return nil
+}
+
+func InitializeScenario(ctx *godog.ScenarioContext) {
+	a := &apiFeature{}
+	ctx.Before(func(c context.Context, sc *godog.Scenario) (context.Context, error) {
+		return c, a.theAPIIsAvailable()
+	})
+
+	ctx.Step(` + "`" + `^the API is available$` + "`" + `, a.theAPIIsAvailable)
+	ctx.Step(` + "`" + `^the request header "([^"]*)" is "([^"]*)"$` + "`" + `, a.theRequestHeaderIs)
+	ctx.Step(` + "`" + `^the request body is (.+)$` + "`" + `, a.theRequestBodyIs)
+	ctx.Step(` + "`" + `^I send a (\w+) request to "([^"]*)"$` + "`" + `, a.iSendARequestTo)
+	ctx.Step(` + "`" + `^the response status should be (\d+)$` + "`" + `, a.theResponseStatusShouldBe)
+	ctx.Step(` + "`" + `^the response field "([^"]*)" should equal (.+)$` + "`" + `, a.theResponseFieldShouldEqual)
+	ctx.Step(` + "`" + `^the response field "([^"]*)" should contain (.+)$` + "`" + `, a.theResponseFieldShouldContain)
+	ctx.Step(` + "`" + `^the response field "([^"]*)" should not be null$` + "`" + `, a.theResponseFieldShouldNotBeNull)
+	ctx.Step(` + "`" + `^the response field "([^"]*)" should be approximately (\S+) within (\S+)$` + "`" + `, a.theResponseFieldShouldBeApproximately)
+	ctx.Step(` + "`" + `^the response field "([^"]*)" should match (.+)$` + "`" + `, a.theResponseFieldShouldMatch)
+	ctx.Step(` + "`" + `^the response field "([^"]*)" should have length (\d+)$` + "`" + `, a.theResponseFieldShouldHaveLength)
+	ctx.Step(` + "`" + `^the response field "([^"]*)" should include all of (.+)$` + "`" + `, a.theResponseFieldShouldIncludeAllOf)
+	ctx.Step(` + "`" + `^the response field "([^"]*)" should be a subset of (.+)$` + "`" + `, a.theResponseFieldShouldBeASubsetOf)
+	ctx.Step(` + "`" + `^the response field "([^"]*)" should be sorted(?: by "([^"]*)")? in (ascending|descending) order$` + "`" + `, a.theResponseFieldShouldBeSorted)
+	ctx.Step(` + "`" + `^the response (header|cookie) "([^"]*)" should be set$` + "`" + `, a.theResponseValueShouldBeSet)
+	ctx.Step(` + "`" + `^the response (header|cookie) "([^"]*)" should equal (.+)$` + "`" + `, a.theResponseValueShouldEqual)
+	ctx.Step(` + "`" + `^the response (header|cookie) "([^"]*)" should match (.+)$` + "`" + `, a.theResponseValueShouldMatch)
+`)
+
+	if withE2E {
+		sb.WriteString(`
+	// Browser steps are not implemented for godog; drive them with a
+	// browser automation library of your choice
+	pending1 := func(string) error { return godog.ErrPending }
+	pending2 := func(string, string) error { return godog.ErrPending }
+	ctx.Step(` + "`" + `^I open "([^"]*)"$` + "`" + `, pending1)
+	ctx.Step(` + "`" + `^I click "([^"]*)"$` + "`" + `, pending1)
+	ctx.Step(` + "`" + `^I fill "([^"]*)" with "([^"]*)"$` + "`" + `, pending2)
+	ctx.Step(` + "`" + `^"([^"]*)" should be (visible|hidden)$` + "`" + `, pending2)
+	ctx.Step(` + "`" + `^"([^"]*)" should (?:have|contain) text "([^"]*)"$` + "`" + `, pending2)
+`)
+	}
+
+	sb.WriteString(`}
+
+func TestFeatures(t *testing.T) {
+	suite := godog.TestSuite{
+		ScenarioInitializer: InitializeScenario,
+		Options: &godog.Options{
+			Format:   "pretty",
+			Paths:    []string{"."},
+			TestingT: t,
+		},
+	}
+	if suite.Run() != 0 {
+		t.Fatal("feature tests failed")
+	}
+}
+`)
+
+	return sb.String()
+}
+
+func behaveSteps(withE2E bool) string {
+	var sb strings.Builder
+
+	sb.WriteString(`import json
+import os
+import re
+
+import parse
+import requests
+from behave import given, when, then
+from behave import register_type
+
+BASE_URL = os.environ.get("QTEST_BASE_URL", "http://localhost:8000")
+
+
+def _field(context, path):
+    cur = {"status": context.response.status_code, "body": context.body}
+    for key in path.split("."):
+        if isinstance(cur, list):
+            cur = cur[int(key)] if key.isdigit() and int(key) < len(cur) else None
+        elif isinstance(cur, dict):
+            cur = cur.get(key)
+        else:
+            return None
+    return cur
+
+
+@parse.with_pattern(r"header|cookie")
+def _parse_response_target(text):
+    return text
+
+
+register_type(ResponseTarget=_parse_response_target)
+
+
+def _response_value(context, target, name):
+    if target == "header":
+        return context.response.headers.get(name)
+    return context.response.cookies.get(name)
+
+
+@given("the API is available")
+def step_api_available(context):
+    context.headers = {}
+    context.request_body = None
+
+
+@given('the request header "{key}" is "{value}"')
+def step_request_header(context, key, value):
+    context.headers[key] = value
+
+
+@given("the request body is {body}")
+def step_request_body(context, body):
+    context.request_body = json.loads(body)
+
+
+@when('I send a {method} request to "{path}"')
+def step_send_request(context, method, path):
+    context.response = requests.request(
+        method, BASE_URL + path, headers=context.headers, json=context.request_body
+    )
+    try:
+        context.body = context.response.json()
+    except ValueError:
+        context.body = context.response.text
+
+
+@then("the response status should be {status:d}")
+def step_response_status(context, status):
+    assert context.response.status_code == status
+
+
+@then('the response field "{path}" should equal {expected}')
+def step_field_equals(context, path, expected):
+    assert _field(context, path) == json.loads(expected)
+
+
+@then('the response field "{path}" should contain {expected}')
+def step_field_contains(context, path, expected):
+    assert json.loads(expected) in _field(context, path)
+
+
+@then('the response field "{path}" should not be null')
+def step_field_not_null(context, path):
+    assert _field(context, path) is not None
+
+
+@then('the response field "{path}" should be approximately {expected:g} within {eps:g}')
+def step_field_approx(context, path, expected, eps):
+    assert abs(_field(context, path) - expected) <= eps
+
+
+@then('the response field "{path}" should match {pattern}')
+def step_field_matches(context, path, pattern):
+    assert re.search(json.loads(pattern), str(_field(context, path)))
+
+
+@then('the response field "{path}" should have length {length:d}')
+def step_field_length(context, path, length):
+    assert len(_field(context, path)) == length
+
+
+@then('the response field "{path}" should include all of {expected}')
+def step_field_includes_all(context, path, expected):
+    actual = _field(context, path)
+    assert all(item in actual for item in json.loads(expected))
+
+
+@then('the response field "{path}" should be a subset of {expected}')
+def step_field_subset(context, path, expected):
+    allowed = json.loads(expected)
+    assert all(item in allowed for item in _field(context, path))
+
+
+@then('the response field "{path}" should be sorted in {order} order')
+def step_field_sorted(context, path, order):
+    items = _field(context, path)
+    assert items == sorted(items, reverse=order == "descending")
+
+
+@then('the response field "{path}" should be sorted by "{key}" in {order} order')
+def step_field_sorted_by(context, path, key, order):
+    items = _field(context, path)
+    assert items == sorted(items, key=lambda item: item[key], reverse=order == "descending")
+
+
+@then('the response {target:ResponseTarget} "{name}" should be set')
+def step_response_value_set(context, target, name):
+    assert _response_value(context, target, name) is not None
+
+
+@then('the response {target:ResponseTarget} "{name}" should equal {expected}')
+def step_response_value_equals(context, target, name, expected):
+    assert _response_value(context, target, name) == json.loads(expected)
+
+
+@then('the response {target:ResponseTarget} "{name}" should match {pattern}')
+def step_response_value_matches(context, target, name, pattern):
+    assert re.search(json.loads(pattern), _response_value(context, target, name) or "")
+`)
+
+	if withE2E {
+		sb.WriteString(`
+
+# E2E steps expect a Playwright page on the context (context.page)
+@given('I open "{url}"')
+@when('I open "{url}"')
+def step_open(context, url):
+    context.page.goto(url)
+
+
+@when('I click "{selector}"')
+def step_click(context, selector):
+    context.page.click(selector)
+
+
+@when('I fill "{selector}" with "{value}"')
+def step_fill(context, selector, value):
+    context.page.fill(selector, value)
+
+
+@then('"{selector}" should be visible')
+def step_visible(context, selector):
+    assert context.page.is_visible(selector)
+
+
+@then('"{selector}" should be hidden')
+def step_hidden(context, selector):
+    assert context.page.is_hidden(selector)
+
+
+@then('"{selector}" should have text "{text}"')
+def step_text(context, selector, text):
+    assert context.page.text_content(selector).strip() == text
+
+
+@then('"{selector}" should contain text "{text}"')
+def step_contains_text(context, selector, text):
+    assert text in context.page.text_content(selector)
+`)
+	}
+
+	return sb.String()
+}
+
+// quoteStep wraps a step argument in double quotes. Embedded double quotes
+// become single quotes so the argument still matches "([^"]*)" patterns.
+func quoteStep(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `'`) + `"`
+}
+
+// resolveSpecPath substitutes path parameters and appends query parameters
+func resolveSpecPath(spec model.TestSpec) string {
+	path := spec.Path
+
+	for key, value := range spec.PathParams {
+		path = strings.Replace(path, ":"+key, fmt.Sprintf("%v", value), 1)
+		path = strings.Replace(path, "{"+key+"}", fmt.Sprintf("%v", value), 1)
+	}
+
+	if len(spec.QueryParams) > 0 {
+		params := make([]string, 0, len(spec.QueryParams))
+		for _, key := range sortedKeys(spec.QueryParams) {
+			params = append(params, fmt.Sprintf("%s=%v", key, spec.QueryParams[key]))
+		}
+		path += "?" + strings.Join(params, "&")
+	}
+
+	return path
+}
+
+// sortedKeys returns map keys in sorted order for deterministic output
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -3,13 +3,26 @@ package emitter
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/QTest-hq/qtest/pkg/model"
 )
 
 // GoHTTPEmitter generates Go net/http tests
-type GoHTTPEmitter struct{}
+type GoHTTPEmitter struct {
+	// Tags emits a build constraint for the file's test categories
+	Tags TagConfig
+
+	// Assertions is the assertion library: AssertTestify, AssertRequire or
+	// "" for t.Errorf checks
+	Assertions string
+
+	// Timeout is how many seconds a test's requests may take in all before
+	// their context is cancelled, so a hung handler fails the test instead
+	// of the run (0 = no deadline)
+	Timeout int
+}
 
 func (e *GoHTTPEmitter) Name() string          { return "go-http" }
 func (e *GoHTTPEmitter) Language() string      { return "go" }
@@ -20,34 +33,235 @@ func (e *GoHTTPEmitter) FileExtension() string { return "_test.go" }
 func (e *GoHTTPEmitter) Emit(specs []model.TestSpec) (string, error) {
 	var sb strings.Builder
 
+	// Build constraint so CI can select categories with -tags
+	sb.WriteString(e.Tags.goBuildConstraint(specs))
+
 	// Package declaration
 	sb.WriteString("package main\n\n")
 
-	// Imports
-	sb.WriteString(`import (
-	"encoding/json"
-	"io"
-	"net/http"
-	"net/http/httptest"
-	"strings"
-	"testing"
-)
-
-`)
-
 	// Generate tests
+	var tests strings.Builder
 	for _, spec := range specs {
 		testCode, err := e.emitTest(spec)
 		if err != nil {
 			continue
 		}
-		sb.WriteString(testCode)
+		tests.WriteString(testCode)
+		tests.WriteString("\n")
+	}
+	code := tests.String()
+
+	// Imports
+	usesJSONList := strings.Contains(code, "jsonList(")
+	usesJSONField := usesJSONList || strings.Contains(code, "jsonField(")
+	usesLatency := strings.Contains(code, "checkLatency(")
+	imports := []string{"encoding/json", "io", "net/http", "net/http/httptest", "strings", "testing"}
+	if strings.Contains(code, "context.") {
+		imports = append(imports, "context")
+	}
+	if anyTimeout(specs) || usesLatency || strings.Contains(code, "time.Second") {
+		imports = append(imports, "time")
+	}
+	if usesLatency {
+		imports = append(imports, "sort")
+	}
+	for _, pkg := range []string{"fmt", "math", "regexp"} {
+		if strings.Contains(code, pkg+".") {
+			imports = append(imports, pkg)
+		}
+	}
+	if usesJSONField {
+		imports = append(imports, "strconv")
+	}
+	if usesJSONList {
+		imports = append(imports, "reflect")
+	}
+	sort.Strings(imports)
+	sb.WriteString("import (\n")
+	for _, pkg := range imports {
+		sb.WriteString(fmt.Sprintf("\t%q\n", pkg))
+	}
+	// testify packages only when the tests use them
+	usesAssert := strings.Contains(code, "\tassert.")
+	usesRequire := strings.Contains(code, "\trequire.")
+	if usesAssert || usesRequire {
 		sb.WriteString("\n")
+		if usesAssert {
+			sb.WriteString("\t\"github.com/stretchr/testify/assert\"\n")
+		}
+		if usesRequire {
+			sb.WriteString("\t\"github.com/stretchr/testify/require\"\n")
+		}
+	}
+	sb.WriteString(")\n\n")
+
+	sb.WriteString(code)
+	if usesJSONField {
+		sb.WriteString(goJSONFieldHelper)
+	}
+	if usesJSONList {
+		sb.WriteString(goJSONListHelpers)
+	}
+	if strings.Contains(code, "responseCookie(") || strings.Contains(code, "cookieValue(") {
+		sb.WriteString(goCookieHelpers)
+	}
+	if usesLatency {
+		sb.WriteString(goLatencyHelper)
 	}
 
 	return sb.String(), nil
 }
 
+// goResponseValue returns the expression reading a response header or
+// cookie, "" when it isn't set
+func goResponseValue(target, name string) string {
+	if target == model.ResponseCookie {
+		return fmt.Sprintf("cookieValue(resp, %q)", name)
+	}
+	return fmt.Sprintf("resp.Header.Get(%q)", name)
+}
+
+// goResponseMissing returns the condition that a response header or cookie
+// isn't set
+func goResponseMissing(target, name string) string {
+	if target == model.ResponseCookie {
+		return fmt.Sprintf("responseCookie(resp, %q) == nil", name)
+	}
+	return fmt.Sprintf("len(resp.Header.Values(%q)) == 0", name)
+}
+
+// goCookieHelpers find the cookies a response sets
+const goCookieHelpers = `
+// responseCookie returns the cookie named name the response sets, or nil
+func responseCookie(resp *http.Response, name string) *http.Cookie {
+	for _, c := range resp.Cookies() {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// cookieValue returns the value of the cookie named name the response
+// sets, or "" when it sets none
+func cookieValue(resp *http.Response, name string) string {
+	if c := responseCookie(resp, name); c != nil {
+		return c.Value
+	}
+	return ""
+}
+`
+
+// goJSONFieldHelper reads a field of a JSON response for assertions that
+// compare it loosely
+const goJSONFieldHelper = `
+// jsonField returns the value at a dotted path of a JSON body, e.g.
+// "items.0.price", or the whole body for ""
+func jsonField(body []byte, path string) interface{} {
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err != nil {
+		return nil
+	}
+	if path == "" {
+		return v
+	}
+	for _, key := range strings.Split(path, ".") {
+		switch node := v.(type) {
+		case map[string]interface{}:
+			v = node[key]
+		case []interface{}:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil
+			}
+			v = node[i]
+		default:
+			return nil
+		}
+	}
+	return v
+}
+`
+
+// goJSONListHelpers check JSON arrays for collection assertions
+const goJSONListHelpers = `
+// jsonValue decodes an expected JSON value
+func jsonValue(s string) interface{} {
+	var v interface{}
+	json.Unmarshal([]byte(s), &v)
+	return v
+}
+
+// jsonList returns the items of a JSON array, or nil for other values
+func jsonList(v interface{}) []interface{} {
+	items, _ := v.([]interface{})
+	return items
+}
+
+// jsonContains reports whether a JSON array has an item equal to want
+func jsonContains(list interface{}, want interface{}) bool {
+	for _, item := range jsonList(list) {
+		if reflect.DeepEqual(item, want) {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonSorted reports whether a JSON array's items, or their key field,
+// are in order
+func jsonSorted(list interface{}, key string, desc bool) bool {
+	items := jsonList(list)
+	for i := 1; i < len(items); i++ {
+		a, b := items[i-1], items[i]
+		if key != "" {
+			a, b = jsonField(jsonMarshal(a), key), jsonField(jsonMarshal(b), key)
+		}
+		if desc {
+			a, b = b, a
+		}
+		if jsonLess(b, a) {
+			return false
+		}
+	}
+	return true
+}
+
+func jsonMarshal(v interface{}) []byte {
+	data, _ := json.Marshal(v)
+	return data
+}
+
+// jsonLess orders JSON numbers and strings
+func jsonLess(a, b interface{}) bool {
+	switch x := a.(type) {
+	case float64:
+		y, ok := b.(float64)
+		return ok && x < y
+	case string:
+		y, ok := b.(string)
+		return ok && x < y
+	}
+	return false
+}
+`
+
+// bodyFieldPath returns the jsonField path of an assertion's actual value,
+// e.g. "items.0.price" for body.items[0].price, reporting whether it's in
+// the body
+func bodyFieldPath(actual string) (string, bool) {
+	if actual == "body" {
+		return "", true
+	}
+	if !strings.HasPrefix(actual, "body.") {
+		return "", false
+	}
+	path := strings.TrimPrefix(actual, "body.")
+	path = strings.ReplaceAll(path, "[", ".")
+	path = strings.ReplaceAll(path, "]", "")
+	return path, true
+}
+
 // EmitSingle generates test code for a single spec
 func (e *GoHTTPEmitter) EmitSingle(spec model.TestSpec) (string, error) {
 	return e.emitTest(spec)
@@ -58,26 +272,34 @@ func (e *GoHTTPEmitter) emitTest(spec model.TestSpec) (string, error) {
 
 	testName := e.generateTestName(spec)
 	sb.WriteString(fmt.Sprintf("func %s(t *testing.T) {\n", testName))
+	if hasTag(e.Tags.TagsFor(spec), TagSlow) {
+		sb.WriteString("\tif testing.Short() {\n\t\tt.Skip(\"slow test skipped in -short mode\")\n\t}\n\n")
+	}
 
 	// Create test server (assumes handler is available)
 	sb.WriteString("\t// Create test server\n")
 	sb.WriteString("\tts := httptest.NewServer(http.DefaultServeMux)\n")
 	sb.WriteString("\tdefer ts.Close()\n\n")
 
-	// Build request
+	// Build request, with a deadline when configured
 	path := e.resolvePath(spec)
+	newRequest := "http.NewRequest("
+	if timeout := e.requestTimeout(spec); timeout > 0 {
+		sb.WriteString(fmt.Sprintf("\tctx, cancel := context.WithTimeout(context.Background(), %d*time.Second)\n", timeout))
+		sb.WriteString("\tdefer cancel()\n")
+		newRequest = "http.NewRequestWithContext(ctx, "
+	}
 
 	if spec.Body != nil && (spec.Method == "POST" || spec.Method == "PUT" || spec.Method == "PATCH") {
 		bodyJSON, _ := json.Marshal(spec.Body)
 		sb.WriteString(fmt.Sprintf("\tbody := strings.NewReader(`%s`)\n", string(bodyJSON)))
-		sb.WriteString(fmt.Sprintf("\treq, err := http.NewRequest(%q, ts.URL+%q, body)\n", spec.Method, path))
+		sb.WriteString(fmt.Sprintf("\treq, err := %s%q, ts.URL+%q, body)\n", newRequest, spec.Method, path))
 	} else {
-		sb.WriteString(fmt.Sprintf("\treq, err := http.NewRequest(%q, ts.URL+%q, nil)\n", spec.Method, path))
+		sb.WriteString(fmt.Sprintf("\treq, err := %s%q, ts.URL+%q, nil)\n", newRequest, spec.Method, path))
 	}
 
-	sb.WriteString("\tif err != nil {\n")
-	sb.WriteString("\t\tt.Fatalf(\"failed to create request: %v\", err)\n")
-	sb.WriteString("\t}\n\n")
+	sb.WriteString(e.fatalOnErr("failed to create request"))
+	sb.WriteString("\n")
 
 	// Add headers
 	if len(spec.Headers) > 0 {
@@ -87,11 +309,20 @@ func (e *GoHTTPEmitter) emitTest(spec model.TestSpec) (string, error) {
 		sb.WriteString("\n")
 	}
 
-	// Send request
-	sb.WriteString("\tresp, err := http.DefaultClient.Do(req)\n")
-	sb.WriteString("\tif err != nil {\n")
-	sb.WriteString("\t\tt.Fatalf(\"request failed: %v\", err)\n")
-	sb.WriteString("\t}\n")
+	// Send request, with a client timeout and retries for slow tests
+	if timeout, retries := spec.ExecutionLimits(); timeout > 0 {
+		sb.WriteString(fmt.Sprintf("\tclient := &http.Client{Timeout: %d * time.Second}\n", timeout))
+		sb.WriteString("\tvar resp *http.Response\n")
+		sb.WriteString(fmt.Sprintf("\tfor attempt := 0; attempt <= %d; attempt++ {\n", retries))
+		sb.WriteString("\t\tif req.GetBody != nil {\n\t\t\treq.Body, _ = req.GetBody()\n\t\t}\n")
+		sb.WriteString("\t\tresp, err = client.Do(req)\n")
+		sb.WriteString("\t\tif err == nil && resp.StatusCode < 500 {\n\t\t\tbreak\n\t\t}\n")
+		sb.WriteString(fmt.Sprintf("\t\tif err == nil && attempt < %d {\n\t\t\tresp.Body.Close()\n\t\t}\n", retries))
+		sb.WriteString("\t}\n")
+	} else {
+		sb.WriteString("\tresp, err := http.DefaultClient.Do(req)\n")
+	}
+	sb.WriteString(e.fatalOnErr("request failed"))
 	sb.WriteString("\tdefer resp.Body.Close()\n\n")
 
 	// Read body
@@ -108,7 +339,42 @@ func (e *GoHTTPEmitter) emitTest(spec model.TestSpec) (string, error) {
 	return sb.String(), nil
 }
 
+// requestTimeout returns the deadline in seconds of a test's requests. A
+// slow spec's own timeout applies to each attempt, so its deadline allows
+// for every retry.
+func (e *GoHTTPEmitter) requestTimeout(spec model.TestSpec) int {
+	if e.Timeout <= 0 {
+		return 0
+	}
+	if timeout, retries := spec.ExecutionLimits(); timeout > 0 {
+		return max(e.Timeout, timeout*(retries+1))
+	}
+	return e.Timeout
+}
+
+// anyTimeout reports whether any spec needs an explicit timeout
+func anyTimeout(specs []model.TestSpec) bool {
+	for i := range specs {
+		if timeout, _ := specs[i].ExecutionLimits(); timeout > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// fatalOnErr stops the test when a setup step returned an error
+func (e *GoHTTPEmitter) fatalOnErr(msg string) string {
+	if e.Assertions != "" {
+		return fmt.Sprintf("\trequire.NoError(t, err, %q)\n", msg)
+	}
+	return fmt.Sprintf("\tif err != nil {\n\t\tt.Fatalf(\"%s: %%v\", err)\n\t}\n", msg)
+}
+
 func (e *GoHTTPEmitter) emitAssertion(a model.Assertion) string {
+	if e.Assertions != "" {
+		return e.emitTestifyAssertion(a)
+	}
+
 	switch a.Kind {
 	case "status_code":
 		return fmt.Sprintf("\tif resp.StatusCode != %v {\n\t\tt.Errorf(\"expected status %v, got %%d\", resp.StatusCode)\n\t}\n", a.Expected, a.Expected)
@@ -123,6 +389,163 @@ func (e *GoHTTPEmitter) emitAssertion(a model.Assertion) string {
 	case "not_null":
 		return fmt.Sprintf("\t// TODO: Assert %s is not null\n", a.Actual)
 
+	case "less_than":
+		if a.Actual == "status" {
+			return fmt.Sprintf("\tif resp.StatusCode >= %v {\n\t\tt.Errorf(\"expected status below %v, got %%d\", resp.StatusCode)\n\t}\n", a.Expected, a.Expected)
+		}
+		return fmt.Sprintf("\t// TODO: Assert %s is less than %v\n", a.Actual, a.Expected)
+
+	case model.AssertLength, model.AssertContainsAll, model.AssertSubset, model.AssertSorted:
+		path, ok := bodyFieldPath(a.Actual)
+		if !ok {
+			return fmt.Sprintf("\t// TODO: Assert %s %s %v\n", a.Actual, a.Kind, a.Expected)
+		}
+		field := fmt.Sprintf("jsonField(bodyBytes, %q)", path)
+		switch a.Kind {
+		case model.AssertLength:
+			return fmt.Sprintf("\tif got := len(jsonList(%s)); got != %v {\n\t\tt.Errorf(\"%s has %%d items, want %v\", got)\n\t}\n",
+				field, a.Expected, a.Actual, a.Expected)
+		case model.AssertContainsAll:
+			return fmt.Sprintf("\tfor _, want := range jsonList(jsonValue(`%s`)) {\n\t\tif !jsonContains(%s, want) {\n\t\t\tt.Errorf(\"%s does not contain %%v\", want)\n\t\t}\n\t}\n",
+				expectedItemsJSON(a), field, a.Actual)
+		case model.AssertSubset:
+			return fmt.Sprintf("\tfor _, got := range jsonList(%s) {\n\t\tif !jsonContains(jsonValue(`%s`), got) {\n\t\t\tt.Errorf(\"%s has unexpected item %%v\", got)\n\t\t}\n\t}\n",
+				field, expectedItemsJSON(a), a.Actual)
+		default:
+			key, desc := a.SortKey()
+			return fmt.Sprintf("\tif !jsonSorted(jsonList(%s), %q, %t) {\n\t\tt.Errorf(\"%s is not %s\")\n\t}\n",
+				field, key, desc, a.Actual, sortDescription(a))
+		}
+
+	case model.AssertApprox:
+		path, ok := bodyFieldPath(a.Actual)
+		if !ok {
+			return fmt.Sprintf("\t// TODO: Assert %s is approximately %v\n", a.Actual, a.Expected)
+		}
+		expected, eps := approxValues(a)
+		return fmt.Sprintf("\tif got, _ := jsonField(bodyBytes, %q).(float64); math.Abs(got-%s) > %s {\n\t\tt.Errorf(\"%s = %%v, want %s ± %s\", got)\n\t}\n",
+			path, expected, eps, a.Actual, expected, eps)
+
+	case model.AssertMatches:
+		pattern := fmt.Sprintf("%q", regexPattern(a))
+		switch path, ok := bodyFieldPath(a.Actual); {
+		case !ok:
+			return fmt.Sprintf("\t// TODO: Assert %s matches %v\n", a.Actual, a.Expected)
+		case path == "":
+			return fmt.Sprintf("\tif !regexp.MustCompile(%s).Match(bodyBytes) {\n\t\tt.Errorf(\"body does not match %%s\", %s)\n\t}\n", pattern, pattern)
+		default:
+			return fmt.Sprintf("\tif got := fmt.Sprint(jsonField(bodyBytes, %q)); !regexp.MustCompile(%s).MatchString(got) {\n\t\tt.Errorf(\"%s = %%q, want a match of %%s\", got, %s)\n\t}\n",
+				path, pattern, a.Actual, pattern)
+		}
+
+	case model.AssertHeaderPresent, model.AssertHeaderEquals, model.AssertHeaderMatches,
+		model.AssertCookiePresent, model.AssertCookieEquals, model.AssertCookieMatches:
+		target, check, _ := a.ResponseCheck()
+		name := a.ResponseName()
+		got := goResponseValue(target, name)
+		switch check {
+		case model.CheckPresent:
+			return fmt.Sprintf("\tif %s {\n\t\tt.Errorf(\"expected %s %%s to be set\", %q)\n\t}\n", goResponseMissing(target, name), target, name)
+		case model.CheckEquals:
+			return fmt.Sprintf("\tif got := %s; got != %q {\n\t\tt.Errorf(\"%s %%s = %%q, want %%q\", %q, got, %q)\n\t}\n",
+				got, a.ExpectedString(), target, name, a.ExpectedString())
+		default:
+			pattern := fmt.Sprintf("%q", regexPattern(a))
+			return fmt.Sprintf("\tif got := %s; !regexp.MustCompile(%s).MatchString(got) {\n\t\tt.Errorf(\"%s %%s = %%q, want a match of %%s\", %q, got, %s)\n\t}\n",
+				got, pattern, target, name, pattern)
+		}
+
+	case model.AssertLatency:
+		return goLatencyCheck(a)
+
+	default:
+		return fmt.Sprintf("\t// Unknown assertion kind: %s\n", a.Kind)
+	}
+}
+
+// emitTestifyAssertion generates an assertion with testify's assert or
+// require package
+func (e *GoHTTPEmitter) emitTestifyAssertion(a model.Assertion) string {
+	pkg := "assert"
+	if e.Assertions == AssertRequire {
+		pkg = "require"
+	}
+
+	switch a.Kind {
+	case "status_code":
+		return fmt.Sprintf("\t%s.Equal(t, %v, resp.StatusCode)\n", pkg, a.Expected)
+
+	case "equality":
+		if a.Actual == "body" || strings.HasPrefix(a.Actual, "body.") {
+			expectedJSON, _ := json.Marshal(a.Expected)
+			return fmt.Sprintf("\t// Check body contains expected value\n\t%s.Contains(t, string(bodyBytes), `%s`)\n", pkg, string(expectedJSON))
+		}
+		return fmt.Sprintf("\t// TODO: Assert %s equals %v\n", a.Actual, a.Expected)
+
+	case "not_null":
+		return fmt.Sprintf("\t// TODO: Assert %s is not null\n", a.Actual)
+
+	case "less_than":
+		if a.Actual == "status" {
+			return fmt.Sprintf("\t%s.Less(t, resp.StatusCode, %v)\n", pkg, a.Expected)
+		}
+		return fmt.Sprintf("\t// TODO: Assert %s is less than %v\n", a.Actual, a.Expected)
+
+	case model.AssertLength, model.AssertContainsAll, model.AssertSubset, model.AssertSorted:
+		path, ok := bodyFieldPath(a.Actual)
+		if !ok {
+			return fmt.Sprintf("\t// TODO: Assert %s %s %v\n", a.Actual, a.Kind, a.Expected)
+		}
+		list := fmt.Sprintf("jsonList(jsonField(bodyBytes, %q))", path)
+		switch a.Kind {
+		case model.AssertLength:
+			return fmt.Sprintf("\t%s.Len(t, %s, %v)\n", pkg, list, a.Expected)
+		case model.AssertContainsAll:
+			return fmt.Sprintf("\t%s.Subset(t, %s, jsonList(jsonValue(`%s`)))\n", pkg, list, expectedItemsJSON(a))
+		case model.AssertSubset:
+			return fmt.Sprintf("\t%s.Subset(t, jsonList(jsonValue(`%s`)), %s)\n", pkg, expectedItemsJSON(a), list)
+		default:
+			key, desc := a.SortKey()
+			return fmt.Sprintf("\t%s.True(t, jsonSorted(%s, %q, %t), \"%s should be %s\")\n", pkg, list, key, desc, a.Actual, sortDescription(a))
+		}
+
+	case model.AssertApprox:
+		path, ok := bodyFieldPath(a.Actual)
+		if !ok {
+			return fmt.Sprintf("\t// TODO: Assert %s is approximately %v\n", a.Actual, a.Expected)
+		}
+		expected, eps := approxValues(a)
+		return fmt.Sprintf("\t%s.InDelta(t, %s, jsonField(bodyBytes, %q), %s)\n", pkg, expected, path, eps)
+
+	case model.AssertMatches:
+		pattern := fmt.Sprintf("%q", regexPattern(a))
+		switch path, ok := bodyFieldPath(a.Actual); {
+		case !ok:
+			return fmt.Sprintf("\t// TODO: Assert %s matches %v\n", a.Actual, a.Expected)
+		case path == "":
+			return fmt.Sprintf("\t%s.Regexp(t, %s, string(bodyBytes))\n", pkg, pattern)
+		default:
+			return fmt.Sprintf("\t%s.Regexp(t, %s, jsonField(bodyBytes, %q))\n", pkg, pattern, path)
+		}
+
+	case model.AssertHeaderPresent, model.AssertHeaderEquals, model.AssertHeaderMatches,
+		model.AssertCookiePresent, model.AssertCookieEquals, model.AssertCookieMatches:
+		target, check, _ := a.ResponseCheck()
+		name := a.ResponseName()
+		switch {
+		case check == model.CheckPresent && target == model.ResponseHeader:
+			return fmt.Sprintf("\t%s.NotEmpty(t, resp.Header.Values(%q), \"header %s should be set\")\n", pkg, name, name)
+		case check == model.CheckPresent:
+			return fmt.Sprintf("\t%s.NotNil(t, responseCookie(resp, %q), \"cookie %s should be set\")\n", pkg, name, name)
+		case check == model.CheckEquals:
+			return fmt.Sprintf("\t%s.Equal(t, %q, %s)\n", pkg, a.ExpectedString(), goResponseValue(target, name))
+		default:
+			return fmt.Sprintf("\t%s.Regexp(t, %q, %s)\n", pkg, regexPattern(a), goResponseValue(target, name))
+		}
+
+	case model.AssertLatency:
+		return goLatencyCheck(a)
+
 	default:
 		return fmt.Sprintf("\t// Unknown assertion kind: %s\n", a.Kind)
 	}
@@ -0,0 +1,63 @@
+package emitter
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/QTest-hq/qtest/pkg/model"
+)
+
+// DefaultGraphQLPath is where GraphQL specs without a path are sent
+const DefaultGraphQLPath = "/graphql"
+
+// graphQLSelection finds an operation's kind and first selected field:
+// "query Users { users { id } }" is query users
+var graphQLSelection = regexp.MustCompile(`^\s*(?:(query|mutation)\b[^{]*)?\{\s*(?:\w+\s*:\s*)?(\w+)`)
+
+// graphQLSpec rewrites a GraphQL spec as the request it's sent as: a POST
+// with the operation as its JSON body. Other specs are returned unchanged.
+func graphQLSpec(spec model.TestSpec) model.TestSpec {
+	if spec.GraphQL == nil {
+		return spec
+	}
+	spec.Method = "POST"
+	if spec.Path == "" {
+		spec.Path = DefaultGraphQLPath
+	}
+	spec.Body = spec.GraphQL
+	return spec
+}
+
+// graphQLOperation returns a GraphQL request's kind and name for test
+// names: its operationName, or else the first field it selects
+func graphQLOperation(req *model.GraphQLRequest) (kind, name string) {
+	kind = "query"
+	m := graphQLSelection.FindStringSubmatch(req.Query)
+	if m != nil && m[1] != "" {
+		kind = m[1]
+	}
+	switch {
+	case req.OperationName != "":
+		name = req.OperationName
+	case m != nil:
+		name = m[2]
+	default:
+		name = "operation"
+	}
+	return kind, name
+}
+
+// snakeCase converts a GraphQL name like createUser to create_user
+func snakeCase(name string) string {
+	var sb strings.Builder
+	for i, r := range name {
+		if r >= 'A' && r <= 'Z' {
+			if i > 0 {
+				sb.WriteByte('_')
+			}
+			r += 'a' - 'A'
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
@@ -0,0 +1,119 @@
+package emitter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/QTest-hq/qtest/pkg/model"
+)
+
+// DefaultGRPCTarget is where gRPC tests dial when the server under test
+// isn't known: GRPC_TARGET overrides it at test time
+const DefaultGRPCTarget = "localhost:50051"
+
+// grpcVersionDir matches Go package directories named for an API version
+var grpcVersionDir = regexp.MustCompile(`^v\d+(?:(?:alpha|beta)\d*)?$`)
+
+// grpcSpecs returns the specs that make gRPC calls
+func grpcSpecs(specs []model.TestSpec) []model.TestSpec {
+	var out []model.TestSpec
+	for _, spec := range specs {
+		if spec.GRPC != nil && spec.GRPC.Method != "" {
+			out = append(out, spec)
+		}
+	}
+	return out
+}
+
+// inProcess reports whether every call's server implementation is known,
+// so tests can serve it themselves rather than dial a running server
+func inProcess(specs []model.TestSpec) bool {
+	for _, spec := range specs {
+		if spec.GRPC.Server == "" {
+			return false
+		}
+	}
+	return len(specs) > 0
+}
+
+// shortService drops a service's package: users.v1.UserService is
+// UserService
+func shortService(service string) string {
+	return service[strings.LastIndex(service, ".")+1:]
+}
+
+// goStubsAlias names the import of a Go stubs package, adding the parent
+// directory to version directories: .../users/v1 is usersv1
+func goStubsAlias(importPath string) string {
+	parts := strings.Split(importPath, "/")
+	alias := parts[len(parts)-1]
+	if grpcVersionDir.MatchString(alias) && len(parts) > 1 {
+		alias = parts[len(parts)-2] + alias
+	}
+	return strings.NewReplacer("-", "", ".", "").Replace(alias)
+}
+
+// goFieldName converts a proto field name to the name protoc-gen-go gives
+// it: user_id is UserId
+func goFieldName(name string) string {
+	var sb strings.Builder
+	upper := true
+	for _, r := range name {
+		if r == '_' {
+			upper = true
+			continue
+		}
+		if upper && r >= 'a' && r <= 'z' {
+			r -= 'a' - 'A'
+		}
+		upper = false
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// goStatusCode converts a gRPC status name to its codes constant:
+// NOT_FOUND is NotFound
+func goStatusCode(code string) string {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code == "" || code == "OK" {
+		return "OK"
+	}
+	var sb strings.Builder
+	for _, part := range strings.Split(code, "_") {
+		if part != "" {
+			sb.WriteString(part[:1] + strings.ToLower(part[1:]))
+		}
+	}
+	return sb.String()
+}
+
+// expectsError reports whether a call is expected to fail
+func expectsError(call *model.GRPCCall) bool {
+	return goStatusCode(call.Code) != "OK"
+}
+
+// responseField returns the field an assertion checks on a gRPC
+// response, or "" for the response itself: response.user.name is
+// user.name
+func responseField(actual string) string {
+	for _, prefix := range []string{"response", "resp", "result", "body"} {
+		if actual == prefix {
+			return ""
+		}
+		if strings.HasPrefix(actual, prefix+".") {
+			return strings.TrimPrefix(actual, prefix+".")
+		}
+	}
+	return actual
+}
+
+// uniqueName returns name, or name with a counter once it's been used
+func uniqueName(used map[string]int, name string) string {
+	used[name]++
+	if n := used[name]; n > 1 {
+		return fmt.Sprintf("%s_%d", name, n)
+	}
+	return name
+}
@@ -0,0 +1,305 @@
+package emitter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/QTest-hq/qtest/pkg/model"
+)
+
+// GRPCGoEmitter generates grpc-go client tests. When specs name the
+// server implementation, tests serve it on an in-memory bufconn listener;
+// otherwise they dial a running server at GRPC_TARGET.
+type GRPCGoEmitter struct {
+	// Timeout is how many seconds a test's call may take before its
+	// context is cancelled (0 = no deadline)
+	Timeout int
+}
+
+func (e *GRPCGoEmitter) Name() string          { return "grpc-go" }
+func (e *GRPCGoEmitter) Language() string      { return "go" }
+func (e *GRPCGoEmitter) Framework() string     { return "testing" }
+func (e *GRPCGoEmitter) FileExtension() string { return "_test.go" }
+
+// Emit generates a complete test file from multiple specs. Specs without
+// a gRPC call are skipped.
+func (e *GRPCGoEmitter) Emit(specs []model.TestSpec) (string, error) {
+	specs = grpcSpecs(specs)
+	if len(specs) == 0 {
+		return "", fmt.Errorf("no gRPC specs to emit")
+	}
+	local := inProcess(specs)
+
+	// Tests live in the package registering the services
+	pkg := specs[0].GRPC.ServerModule
+	if pkg == "" {
+		pkg = "main"
+	}
+
+	var tests strings.Builder
+	used := make(map[string]int)
+	for _, spec := range specs {
+		tests.WriteString(e.emitTest(spec, uniqueName(used, e.generateTestName(spec)), local))
+		tests.WriteString("\n")
+	}
+	code := tests.String()
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("package %s\n\n", pkg))
+	sb.WriteString("import (\n\t\"context\"\n")
+	if strings.Contains(code, "fmt.") {
+		sb.WriteString("\t\"fmt\"\n")
+	}
+	if strings.Contains(code, "math.") {
+		sb.WriteString("\t\"math\"\n")
+	}
+	if local {
+		sb.WriteString("\t\"net\"\n")
+	} else {
+		sb.WriteString("\t\"os\"\n")
+	}
+	if strings.Contains(code, "regexp.") {
+		sb.WriteString("\t\"regexp\"\n")
+	}
+	sb.WriteString("\t\"testing\"\n")
+	if e.Timeout > 0 {
+		sb.WriteString("\t\"time\"\n")
+	}
+	sb.WriteString("\n\t\"google.golang.org/grpc\"\n")
+	if strings.Contains(code, "codes.") {
+		sb.WriteString("\t\"google.golang.org/grpc/codes\"\n")
+	}
+	sb.WriteString("\t\"google.golang.org/grpc/credentials/insecure\"\n")
+	if strings.Contains(code, "status.") {
+		sb.WriteString("\t\"google.golang.org/grpc/status\"\n")
+	}
+	if local {
+		sb.WriteString("\t\"google.golang.org/grpc/test/bufconn\"\n")
+	}
+
+	// Generated stubs, unless they're in the tests' own package
+	stubs := make(map[string]bool)
+	for _, spec := range specs {
+		if spec.GRPC.Stubs != "" {
+			stubs[spec.GRPC.Stubs] = true
+		}
+	}
+	if len(stubs) > 0 {
+		sb.WriteString("\n")
+		for _, path := range sortedKeys(stubs) {
+			sb.WriteString(fmt.Sprintf("\t%s %q\n", goStubsAlias(path), path))
+		}
+	}
+	sb.WriteString(")\n\n")
+
+	if local {
+		sb.WriteString(grpcGoBufconnHelper)
+	} else {
+		sb.WriteString(fmt.Sprintf(grpcGoDialHelper, DefaultGRPCTarget))
+	}
+	sb.WriteString("\n")
+	sb.WriteString(code)
+
+	return sb.String(), nil
+}
+
+// EmitSingle generates test code for a single spec
+func (e *GRPCGoEmitter) EmitSingle(spec model.TestSpec) (string, error) {
+	if spec.GRPC == nil {
+		return "", fmt.Errorf("spec %s has no gRPC call", spec.ID)
+	}
+	return e.emitTest(spec, e.generateTestName(spec), spec.GRPC.Server != ""), nil
+}
+
+const grpcGoBufconnHelper = `// dialBufconn serves the services register adds on an in-memory listener
+// and returns a client connection to them
+func dialBufconn(t *testing.T, register func(*grpc.Server)) *grpc.ClientConn {
+	t.Helper()
+	lis := bufconn.Listen(1024 * 1024)
+	srv := grpc.NewServer()
+	register(srv)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("failed to dial bufconn: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+`
+
+const grpcGoDialHelper = `// dialServer connects to the server under test at GRPC_TARGET
+func dialServer(t *testing.T) *grpc.ClientConn {
+	t.Helper()
+	target := os.Getenv("GRPC_TARGET")
+	if target == "" {
+		target = %q
+	}
+	conn, err := grpc.NewClient(target, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("failed to dial %%s: %%v", target, err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+`
+
+func (e *GRPCGoEmitter) emitTest(spec model.TestSpec, testName string, local bool) string {
+	call := spec.GRPC
+	service := shortService(call.Service)
+	qualifier := ""
+	if call.Stubs != "" {
+		qualifier = goStubsAlias(call.Stubs) + "."
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("func %s(t *testing.T) {\n", testName))
+	if spec.Description != "" {
+		sb.WriteString(fmt.Sprintf("\t// %s\n", spec.Description))
+	}
+	if local {
+		sb.WriteString("\tconn := dialBufconn(t, func(s *grpc.Server) {\n")
+		sb.WriteString(fmt.Sprintf("\t\t%sRegister%sServer(s, %s)\n", qualifier, service, call.Server))
+		sb.WriteString("\t})\n")
+	} else {
+		sb.WriteString("\tconn := dialServer(t)\n")
+	}
+	sb.WriteString(fmt.Sprintf("\tclient := %sNew%sClient(conn)\n\n", qualifier, service))
+
+	// Request message
+	requestType := call.RequestType
+	if requestType == "" {
+		requestType = call.Method + "Request"
+	}
+	sb.WriteString(fmt.Sprintf("\treq := &%s%s{", qualifier, requestType))
+	if len(call.Request) > 0 {
+		sb.WriteString("\n")
+		for _, name := range sortedKeys(call.Request) {
+			if literal, ok := goLiteral(call.Request[name]); ok {
+				sb.WriteString(fmt.Sprintf("\t\t%s: %s,\n", goFieldName(name), literal))
+			} else {
+				sb.WriteString(fmt.Sprintf("\t\t// TODO: set %s\n", goFieldName(name)))
+			}
+		}
+		sb.WriteString("\t")
+	}
+	sb.WriteString("}\n\n")
+
+	ctx := "context.Background()"
+	if e.Timeout > 0 {
+		sb.WriteString(fmt.Sprintf("\tctx, cancel := context.WithTimeout(context.Background(), %d*time.Second)\n", e.Timeout))
+		sb.WriteString("\tdefer cancel()\n")
+		ctx = "ctx"
+	}
+
+	if expectsError(call) {
+		sb.WriteString(fmt.Sprintf("\t_, err := client.%s(%s, req)\n", call.Method, ctx))
+		code := goStatusCode(call.Code)
+		sb.WriteString(fmt.Sprintf("\tif got := status.Code(err); got != codes.%s {\n", code))
+		sb.WriteString(fmt.Sprintf("\t\tt.Fatalf(\"expected status %s, got %%v (%%v)\", got, err)\n", code))
+		sb.WriteString("\t}\n")
+		sb.WriteString("}\n")
+		return sb.String()
+	}
+
+	sb.WriteString(fmt.Sprintf("\tresp, err := client.%s(%s, req)\n", call.Method, ctx))
+	sb.WriteString("\tif err != nil {\n")
+	sb.WriteString(fmt.Sprintf("\t\tt.Fatalf(\"%s failed: %%v\", err)\n", call.Method))
+	sb.WriteString("\t}\n")
+
+	var assertions strings.Builder
+	for _, a := range spec.Assertions {
+		assertions.WriteString(e.emitAssertion(a))
+	}
+	if assertions.Len() > 0 {
+		sb.WriteString("\n")
+		sb.WriteString(assertions.String())
+	} else {
+		sb.WriteString("\t_ = resp\n")
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+func (e *GRPCGoEmitter) emitAssertion(a model.Assertion) string {
+	// Status codes are checked by the call itself
+	if a.Kind == "status_code" || a.Actual == "status" || a.Actual == "code" {
+		return ""
+	}
+
+	field := responseField(a.Actual)
+	getter := "resp"
+	for _, part := range strings.Split(field, ".") {
+		if part != "" {
+			getter += ".Get" + goFieldName(part) + "()"
+		}
+	}
+
+	switch a.Kind {
+	case "equality":
+		literal, ok := goLiteral(a.Expected)
+		if !ok || field == "" {
+			return fmt.Sprintf("\t// TODO: Assert %s equals %v\n", a.Actual, a.Expected)
+		}
+		return fmt.Sprintf("\tif got := %s; got != %s {\n\t\tt.Errorf(\"%s = %%v, want %%v\", got, %s)\n\t}\n",
+			getter, literal, field, literal)
+
+	case "not_null":
+		if field == "" {
+			return "\tif resp == nil {\n\t\tt.Error(\"expected a response\")\n\t}\n"
+		}
+		return fmt.Sprintf("\t// TODO: Assert %s is set\n", a.Actual)
+
+	case model.AssertApprox:
+		if field == "" {
+			return fmt.Sprintf("\t// TODO: Assert %s is approximately %v\n", a.Actual, a.Expected)
+		}
+		expected, eps := approxValues(a)
+		return fmt.Sprintf("\tif got := %s; math.Abs(float64(got)-%s) > %s {\n\t\tt.Errorf(\"%s = %%v, want %%v ± %%v\", got, %s, %s)\n\t}\n",
+			getter, expected, eps, field, expected, eps)
+
+	case model.AssertMatches:
+		if field == "" {
+			return fmt.Sprintf("\t// TODO: Assert %s matches %v\n", a.Actual, a.Expected)
+		}
+		pattern := fmt.Sprintf("%q", regexPattern(a))
+		return fmt.Sprintf("\tif got := fmt.Sprint(%s); !regexp.MustCompile(%s).MatchString(got) {\n\t\tt.Errorf(\"%s = %%q, want a match of %%s\", got, %s)\n\t}\n",
+			getter, pattern, field, pattern)
+
+	default:
+		return fmt.Sprintf("\t// TODO: Assert %s %s %v\n", a.Actual, a.Kind, a.Expected)
+	}
+}
+
+// goLiteral writes a scalar request value as Go source. Messages and
+// lists aren't written, as their Go types aren't known.
+func goLiteral(v interface{}) (string, bool) {
+	switch val := v.(type) {
+	case string:
+		return fmt.Sprintf("%q", val), true
+	case bool:
+		return fmt.Sprintf("%t", val), true
+	case float64:
+		if val == float64(int64(val)) {
+			return fmt.Sprintf("%d", int64(val)), true
+		}
+		return fmt.Sprintf("%v", val), true
+	case int, int32, int64:
+		return fmt.Sprintf("%d", val), true
+	}
+	return "", false
+}
+
+func (e *GRPCGoEmitter) generateTestName(spec model.TestSpec) string {
+	name := fmt.Sprintf("Test%s_%s", shortService(spec.GRPC.Service), spec.GRPC.Method)
+	if code := goStatusCode(spec.GRPC.Code); code != "OK" {
+		name += "_" + code
+	}
+	return name
+}
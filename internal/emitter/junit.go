@@ -9,7 +9,10 @@ import (
 )
 
 // JUnitEmitter generates JUnit 5 tests for Java/Spring Boot
-type JUnitEmitter struct{}
+type JUnitEmitter struct {
+	Package   string // defaults to com.example.tests
+	ClassName string // defaults to ApiTest; the file must be named after it
+}
 
 func (e *JUnitEmitter) Name() string          { return "junit" }
 func (e *JUnitEmitter) Language() string      { return "java" }
@@ -20,8 +23,12 @@ func (e *JUnitEmitter) FileExtension() string { return "Test.java" }
 func (e *JUnitEmitter) Emit(specs []model.TestSpec) (string, error) {
 	var sb strings.Builder
 
-	// Package declaration (default)
-	sb.WriteString("package com.example.tests;\n\n")
+	// Package declaration
+	pkg := e.Package
+	if pkg == "" {
+		pkg = "com.example.tests"
+	}
+	sb.WriteString("package " + pkg + ";\n\n")
 
 	// Imports
 	sb.WriteString(`import org.junit.jupiter.api.Test;
@@ -42,7 +49,7 @@ import static org.junit.jupiter.api.Assertions.*;
 	// Class declaration
 	sb.WriteString("@SpringBootTest\n")
 	sb.WriteString("@AutoConfigureMockMvc\n")
-	sb.WriteString("public class ApiTest {\n\n")
+	sb.WriteString("public class " + e.className() + " {\n\n")
 
 	// MockMvc injection
 	sb.WriteString("    @Autowired\n")
@@ -64,6 +71,18 @@ import static org.junit.jupiter.api.Assertions.*;
 	return sb.String(), nil
 }
 
+// FileName returns the name of the file Emit's class goes in
+func (e *JUnitEmitter) FileName() string {
+	return e.className() + ".java"
+}
+
+func (e *JUnitEmitter) className() string {
+	if e.ClassName == "" {
+		return "ApiTest"
+	}
+	return e.ClassName
+}
+
 // EmitSingle generates test code for a single spec
 func (e *JUnitEmitter) EmitSingle(spec model.TestSpec) (string, error) {
 	return e.emitTest(spec)
@@ -171,6 +190,69 @@ func (e *JUnitEmitter) emitAssertion(a model.Assertion) string {
 	case "contains":
 		return fmt.Sprintf("                .andExpect(content().string(org.hamcrest.Matchers.containsString(\"%v\")))\n", a.Expected)
 
+	case model.AssertLength, model.AssertContainsAll, model.AssertSubset:
+		if !strings.HasPrefix(a.Actual, "body.") {
+			return fmt.Sprintf("        // TODO: Assert %s %s %v\n", a.Actual, a.Kind, a.Expected)
+		}
+		jsonPath := "$." + strings.TrimPrefix(a.Actual, "body.")
+		items := make([]string, 0)
+		for _, item := range a.ExpectedItems() {
+			items = append(items, e.javaLiteral(item))
+		}
+		switch a.Kind {
+		case model.AssertLength:
+			return fmt.Sprintf("                .andExpect(jsonPath(\"%s\", org.hamcrest.Matchers.hasSize(%v)))\n", jsonPath, a.Expected)
+		case model.AssertContainsAll:
+			return fmt.Sprintf("                .andExpect(jsonPath(\"%s\", org.hamcrest.Matchers.hasItems(%s)))\n", jsonPath, strings.Join(items, ", "))
+		default:
+			return fmt.Sprintf("                .andExpect(jsonPath(\"%s\", org.hamcrest.Matchers.everyItem(org.hamcrest.Matchers.isIn(java.util.List.of(%s)))))\n",
+				jsonPath, strings.Join(items, ", "))
+		}
+
+	case model.AssertSorted:
+		return fmt.Sprintf("        // TODO: Assert %s is %s\n", a.Actual, sortDescription(a))
+
+	case model.AssertApprox:
+		if strings.HasPrefix(a.Actual, "body.") {
+			jsonPath := "$." + strings.TrimPrefix(a.Actual, "body.")
+			expected, eps := approxValues(a)
+			return fmt.Sprintf("                .andExpect(jsonPath(\"%s\", org.hamcrest.Matchers.closeTo(%s, %s)))\n", jsonPath, expected, eps)
+		}
+		return fmt.Sprintf("        // TODO: Assert %s is approximately %v\n", a.Actual, a.Expected)
+
+	case model.AssertMatches:
+		// matchesPattern matches the whole string, so the pattern may match anywhere in it
+		pattern := e.escapeJavaString("(?s).*(?:" + regexPattern(a) + ").*")
+		if strings.HasPrefix(a.Actual, "body.") {
+			jsonPath := "$." + strings.TrimPrefix(a.Actual, "body.")
+			return fmt.Sprintf("                .andExpect(jsonPath(\"%s\", org.hamcrest.Matchers.matchesPattern(\"%s\")))\n", jsonPath, pattern)
+		}
+		if a.Actual == "body" {
+			return fmt.Sprintf("                .andExpect(content().string(org.hamcrest.Matchers.matchesPattern(\"%s\")))\n", pattern)
+		}
+		return fmt.Sprintf("        // TODO: Assert %s matches %v\n", a.Actual, a.Expected)
+
+	case model.AssertHeaderPresent, model.AssertHeaderEquals, model.AssertHeaderMatches,
+		model.AssertCookiePresent, model.AssertCookieEquals, model.AssertCookieMatches:
+		target, check, _ := a.ResponseCheck()
+		name := e.escapeJavaString(a.ResponseName())
+		switch {
+		case check == model.CheckPresent:
+			return fmt.Sprintf("                .andExpect(%s().exists(\"%s\"))\n", target, name)
+		case check == model.CheckEquals && target == model.ResponseHeader:
+			return fmt.Sprintf("                .andExpect(header().string(\"%s\", \"%s\"))\n", name, e.escapeJavaString(a.ExpectedString()))
+		case check == model.CheckEquals:
+			return fmt.Sprintf("                .andExpect(cookie().value(\"%s\", \"%s\"))\n", name, e.escapeJavaString(a.ExpectedString()))
+		default:
+			// matchesPattern matches the whole string, so the pattern may match anywhere in it
+			pattern := e.escapeJavaString("(?s).*(?:" + regexPattern(a) + ").*")
+			method := "string"
+			if target == model.ResponseCookie {
+				method = "value"
+			}
+			return fmt.Sprintf("                .andExpect(%s().%s(\"%s\", org.hamcrest.Matchers.matchesPattern(\"%s\")))\n", target, method, name, pattern)
+		}
+
 	default:
 		return fmt.Sprintf("        // Unknown assertion kind: %s\n", a.Kind)
 	}
@@ -220,6 +302,22 @@ func (e *JUnitEmitter) generateDisplayName(spec model.TestSpec) string {
 	return fmt.Sprintf("%s %s", spec.Method, spec.Path)
 }
 
+// javaLiteral writes a scalar expected value as Java source
+func (e *JUnitEmitter) javaLiteral(v interface{}) string {
+	switch val := v.(type) {
+	case string:
+		return "\"" + e.escapeJavaString(val) + "\""
+	case float64:
+		if val == float64(int64(val)) {
+			return fmt.Sprintf("%d", int64(val))
+		}
+		return fmt.Sprintf("%v", val)
+	case nil:
+		return "null"
+	}
+	return fmt.Sprintf("%v", v)
+}
+
 func (e *JUnitEmitter) escapeJavaString(s string) string {
 	s = strings.ReplaceAll(s, "\\", "\\\\")
 	s = strings.ReplaceAll(s, "\"", "\\\"")
@@ -0,0 +1,108 @@
+package emitter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/QTest-hq/qtest/pkg/model"
+)
+
+// ReportLabel is a name/value label attached to a test for reporting tools
+// such as Allure and ReportPortal
+type ReportLabel struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// ReportLabels derives reporting labels (epic, feature, story, severity,
+// id, tags) from a spec so that emitted code and exported results agree
+func ReportLabels(spec model.TestSpec) []ReportLabel {
+	labels := []ReportLabel{
+		{Name: "epic", Value: epicForLevel(spec.Level)},
+		{Name: "feature", Value: featureForSpec(spec)},
+	}
+
+	if spec.Description != "" {
+		labels = append(labels, ReportLabel{Name: "story", Value: spec.Description})
+	}
+	if spec.Priority != "" {
+		labels = append(labels, ReportLabel{Name: "severity", Value: severityForPriority(spec.Priority)})
+	}
+	if spec.ID != "" {
+		labels = append(labels, ReportLabel{Name: "AS_ID", Value: spec.ID})
+	}
+	for _, tag := range spec.Tags {
+		labels = append(labels, ReportLabel{Name: "tag", Value: tag})
+	}
+
+	return labels
+}
+
+func epicForLevel(level model.TestLevel) string {
+	switch level {
+	case model.LevelUnit:
+		return "Unit tests"
+	case model.LevelE2E:
+		return "E2E tests"
+	default:
+		return "API tests"
+	}
+}
+
+func featureForSpec(spec model.TestSpec) string {
+	if spec.FunctionName != "" {
+		return spec.FunctionName
+	}
+	parts := strings.Split(strings.TrimPrefix(spec.Path, "/"), "/")
+	if len(parts) > 0 && parts[0] != "" {
+		return "/" + parts[0]
+	}
+	if spec.TargetID != "" {
+		return spec.TargetID
+	}
+	return "General"
+}
+
+// severityForPriority maps planner priorities onto Allure severities
+func severityForPriority(priority string) string {
+	switch priority {
+	case "high":
+		return "critical"
+	case "low":
+		return "minor"
+	default:
+		return "normal"
+	}
+}
+
+// allurePytestDecorators renders allure-pytest decorators for a spec
+func allurePytestDecorators(spec model.TestSpec) string {
+	var sb strings.Builder
+	for _, l := range ReportLabels(spec) {
+		switch l.Name {
+		case "epic", "feature", "story":
+			sb.WriteString(fmt.Sprintf("@allure.%s(%q)\n", l.Name, l.Value))
+		case "severity":
+			sb.WriteString(fmt.Sprintf("@allure.severity(allure.severity_level.%s)\n", strings.ToUpper(l.Value)))
+		case "AS_ID":
+			sb.WriteString(fmt.Sprintf("@allure.id(%q)\n", l.Value))
+		case "tag":
+			sb.WriteString(fmt.Sprintf("@allure.tag(%q)\n", l.Value))
+		}
+	}
+	return sb.String()
+}
+
+// allureJestCalls renders allure-jest runtime calls for the start of a test body
+func allureJestCalls(spec model.TestSpec, indent string) string {
+	var sb strings.Builder
+	for _, l := range ReportLabels(spec) {
+		switch l.Name {
+		case "epic", "feature", "story", "severity", "tag":
+			sb.WriteString(fmt.Sprintf("%sawait allure.%s(%q);\n", indent, l.Name, l.Value))
+		case "AS_ID":
+			sb.WriteString(fmt.Sprintf("%sawait allure.allureId(%q);\n", indent, l.Value))
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,147 @@
+package emitter
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/QTest-hq/qtest/pkg/model"
+)
+
+// Latency assertions time an endpoint by sending its request again a
+// number of times, then check the p50 and p95 response times against a
+// budget. Each language gets a small helper that does the timing.
+
+// goLatencyCheck renders a latency assertion as a checkLatency call
+func goLatencyCheck(a model.Assertion) string {
+	budget := a.LatencyBudget()
+	return fmt.Sprintf("\tcheckLatency(t, req, %d, %s, %s)\n", budget.Samples, goMillis(budget.P50), goMillis(budget.P95))
+}
+
+// goMillis renders a budget in milliseconds as a time.Duration expression
+func goMillis(ms float64) string {
+	switch {
+	case ms <= 0:
+		return "0"
+	case ms == math.Trunc(ms):
+		return fmt.Sprintf("%d*time.Millisecond", int64(ms))
+	default:
+		return fmt.Sprintf("%d*time.Microsecond", int64(math.Round(ms*1000)))
+	}
+}
+
+// goLatencyHelper times a Go test's request
+const goLatencyHelper = `
+// checkLatency sends req samples times and fails the test when its p50 or
+// p95 response time is over budget; a zero budget isn't checked
+func checkLatency(t *testing.T, req *http.Request, samples int, p50, p95 time.Duration) {
+	t.Helper()
+	durations := make([]time.Duration, 0, samples)
+	for i := 0; i < samples; i++ {
+		if req.GetBody != nil {
+			req.Body, _ = req.GetBody()
+		}
+		start := time.Now()
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		durations = append(durations, time.Since(start))
+	}
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+
+	for _, check := range []struct {
+		percentile int
+		budget     time.Duration
+	}{{50, p50}, {95, p95}} {
+		if check.budget <= 0 {
+			continue
+		}
+		// Nearest-rank percentile
+		got := durations[(len(durations)*check.percentile+99)/100-1]
+		if got > check.budget {
+			t.Errorf("p%d latency over %d requests = %v, want at most %v", check.percentile, samples, got, check.budget)
+		}
+	}
+}
+`
+
+// jsLatencyCheck renders a latency assertion as an expectLatency call that
+// sends the request send builds
+func jsLatencyCheck(a model.Assertion, send, indent string) string {
+	budget := a.LatencyBudget()
+	var limits []string
+	if budget.P50 > 0 {
+		limits = append(limits, "p50: "+formatMillis(budget.P50))
+	}
+	if budget.P95 > 0 {
+		limits = append(limits, "p95: "+formatMillis(budget.P95))
+	}
+	return fmt.Sprintf("%sawait expectLatency(() => %s, %d, { %s });\n", indent, send, budget.Samples, strings.Join(limits, ", "))
+}
+
+// jsLatencyHelper times a request with Jest's expect or chai's
+func jsLatencyHelper(chai bool) string {
+	atMost := "expect(percentile(p)).toBeLessThanOrEqual(max);"
+	if chai {
+		atMost = "expect(percentile(p), name + ' latency').to.be.at.most(max);"
+	}
+	return `// expectLatency sends a request samples times and checks its p50 and p95
+// response times against budgets in milliseconds
+async function expectLatency(send, samples, budget) {
+  const durations = [];
+  for (let i = 0; i < samples; i++) {
+    const start = performance.now();
+    await send();
+    durations.push(performance.now() - start);
+  }
+  durations.sort((a, b) => a - b);
+  const percentile = (p) => durations[Math.ceil((p / 100) * samples) - 1];
+  for (const [name, max] of Object.entries(budget)) {
+    const p = Number(name.slice(1));
+    ` + atMost + `
+  }
+}
+
+`
+}
+
+// pythonLatencyCheck renders a latency assertion as an assert_latency call
+// that sends the test's request again
+func pythonLatencyCheck(a model.Assertion) string {
+	budget := a.LatencyBudget()
+	args := []string{"lambda: client.send(response.request)", strconv.Itoa(budget.Samples)}
+	if budget.P50 > 0 {
+		args = append(args, "p50="+formatMillis(budget.P50))
+	}
+	if budget.P95 > 0 {
+		args = append(args, "p95="+formatMillis(budget.P95))
+	}
+	return fmt.Sprintf("    assert_latency(%s)\n", strings.Join(args, ", "))
+}
+
+// pythonLatencyHelper times a pytest test's request
+const pythonLatencyHelper = `def assert_latency(send, samples, p50=None, p95=None):
+    """Send a request samples times and check its p50 and p95 response
+    times against budgets in milliseconds."""
+    durations = []
+    for _ in range(samples):
+        start = time.perf_counter()
+        send()
+        durations.append((time.perf_counter() - start) * 1000)
+    durations.sort()
+    for percentile, budget in ((50, p50), (95, p95)):
+        if budget is not None:
+            got = durations[math.ceil(percentile / 100 * samples) - 1]
+            assert got <= budget, f"p{percentile} latency over {samples} requests = {got:.1f}ms, want at most {budget}ms"
+
+
+`
+
+// formatMillis formats a budget in milliseconds as a number literal
+func formatMillis(ms float64) string {
+	return strconv.FormatFloat(ms, 'f', -1, 64)
+}
@@ -2,6 +2,7 @@ package emitter
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/QTest-hq/qtest/pkg/model"
@@ -29,6 +30,9 @@ func (e *PlaywrightEmitter) Emit(specs []model.TestSpec) (string, error) {
 
 	for groupName, groupSpecs := range groups {
 		sb.WriteString(fmt.Sprintf("test.describe('%s', () => {\n", groupName))
+		if retries := maxRetries(groupSpecs); retries > 0 {
+			sb.WriteString(fmt.Sprintf("  test.describe.configure({ retries: %d });\n\n", retries))
+		}
 
 		for _, spec := range groupSpecs {
 			testCode, err := e.emitTest(spec)
@@ -56,6 +60,9 @@ func (e *PlaywrightEmitter) emitTest(spec model.TestSpec) (string, error) {
 	// Test function
 	testName := e.generateTestName(spec)
 	sb.WriteString(fmt.Sprintf("  test('%s', async ({ page }) => {\n", testName))
+	if timeout, _ := spec.ExecutionLimits(); timeout > 0 {
+		sb.WriteString(fmt.Sprintf("    test.setTimeout(%d);\n", timeout*1000))
+	}
 
 	// Handle E2E actions from spec inputs
 	if spec.Level == model.LevelE2E {
@@ -207,6 +214,36 @@ func (e *PlaywrightEmitter) emitAssertion(a model.Assertion) string {
 		}
 		return fmt.Sprintf("    await expect(page.locator('%s')).toContainText('%v');\n", e.formatSelector(a.Actual), a.Expected)
 
+	case model.AssertMatches:
+		pattern := fmt.Sprintf("new RegExp(%s)", strconv.Quote(regexPattern(a)))
+		if a.Actual == "title" {
+			return fmt.Sprintf("    await expect(page).toHaveTitle(%s);\n", pattern)
+		}
+		if a.Actual == "url" {
+			return fmt.Sprintf("    await expect(page).toHaveURL(%s);\n", pattern)
+		}
+		return fmt.Sprintf("    await expect(page.locator('%s')).toHaveText(%s);\n", e.formatSelector(a.Actual), pattern)
+
+	case model.AssertApprox:
+		expected, eps := approxValues(a)
+		return fmt.Sprintf("    expect(Math.abs(Number(await page.locator('%s').textContent()) - %s)).toBeLessThanOrEqual(%s);\n",
+			e.formatSelector(a.Actual), expected, eps)
+
+	case model.AssertCookiePresent, model.AssertCookieEquals, model.AssertCookieMatches:
+		_, check, _ := a.ResponseCheck()
+		cookie := fmt.Sprintf("(await page.context().cookies()).find((c) => c.name === %s)", strconv.Quote(a.ResponseName()))
+		switch check {
+		case model.CheckPresent:
+			return fmt.Sprintf("    expect(%s).toBeDefined();\n", cookie)
+		case model.CheckEquals:
+			return fmt.Sprintf("    expect(%s?.value).toBe(%s);\n", cookie, strconv.Quote(a.ExpectedString()))
+		default:
+			return fmt.Sprintf("    expect(%s?.value).toMatch(new RegExp(%s));\n", cookie, strconv.Quote(regexPattern(a)))
+		}
+
+	case model.AssertHeaderPresent, model.AssertHeaderEquals, model.AssertHeaderMatches:
+		return "    " + pageHeaderTODO(a)
+
 	case "value":
 		return fmt.Sprintf("    await expect(page.locator('%s')).toHaveValue('%v');\n", e.formatSelector(a.Actual), a.Expected)
 
@@ -3,13 +3,24 @@ package emitter
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/QTest-hq/qtest/pkg/model"
 )
 
 // PytestEmitter generates pytest + httpx tests for Python APIs
-type PytestEmitter struct{}
+type PytestEmitter struct {
+	// Allure adds allure-pytest decorators (epic, feature, story, id) to each test
+	Allure bool
+
+	// Tags adds @pytest.mark markers for test categories
+	Tags TagConfig
+
+	// Assertions is the assertion library: AssertAssertpy or "" for plain
+	// assert statements
+	Assertions string
+}
 
 func (e *PytestEmitter) Name() string          { return "pytest" }
 func (e *PytestEmitter) Language() string      { return "python" }
@@ -25,11 +36,27 @@ func (e *PytestEmitter) Emit(specs []model.TestSpec) (string, error) {
 import httpx
 from fastapi.testclient import TestClient
 from main import app
-
+`)
+	if e.Allure {
+		sb.WriteString("import allure\n")
+	}
+	if e.Assertions == AssertAssertpy {
+		sb.WriteString("from assertpy import assert_that\n")
+	} else if anyAssertion(specs, model.AssertMatches) || anyAssertion(specs, model.AssertHeaderMatches) || anyAssertion(specs, model.AssertCookieMatches) {
+		sb.WriteString("import re\n")
+	}
+	usesLatency := anyAssertion(specs, model.AssertLatency)
+	if usesLatency {
+		sb.WriteString("import math\nimport time\n")
+	}
+	sb.WriteString(`
 client = TestClient(app)
 
 
 `)
+	if usesLatency {
+		sb.WriteString(pythonLatencyHelper)
+	}
 
 	// Generate tests
 	for _, spec := range specs {
@@ -51,8 +78,20 @@ func (e *PytestEmitter) EmitSingle(spec model.TestSpec) (string, error) {
 
 func (e *PytestEmitter) emitTest(spec model.TestSpec) (string, error) {
 	var sb strings.Builder
+	spec = graphQLSpec(spec)
 
 	testName := e.generateTestName(spec)
+	if e.Allure {
+		sb.WriteString(allurePytestDecorators(spec))
+	}
+	sb.WriteString(e.Tags.pytestMarkers(spec))
+	if timeout, retries := spec.ExecutionLimits(); timeout > 0 {
+		// pytest-timeout and pytest-rerunfailures
+		sb.WriteString(fmt.Sprintf("@pytest.mark.timeout(%d)\n", timeout))
+		if retries > 0 {
+			sb.WriteString(fmt.Sprintf("@pytest.mark.flaky(reruns=%d)\n", retries))
+		}
+	}
 	sb.WriteString(fmt.Sprintf("def %s():\n", testName))
 
 	// Add docstring
@@ -98,10 +137,23 @@ func (e *PytestEmitter) emitTest(spec model.TestSpec) (string, error) {
 		sb.WriteString(e.emitAssertion(assertion))
 	}
 
+	// GraphQL reports failures in the body with a 200 status
+	if spec.GraphQL != nil {
+		if e.Assertions == AssertAssertpy {
+			sb.WriteString("    assert_that(response.json()).does_not_contain_key(\"errors\")\n")
+		} else {
+			sb.WriteString("    assert \"errors\" not in response.json()\n")
+		}
+	}
+
 	return sb.String(), nil
 }
 
 func (e *PytestEmitter) emitAssertion(a model.Assertion) string {
+	if e.Assertions == AssertAssertpy {
+		return e.emitAssertpyAssertion(a)
+	}
+
 	switch a.Kind {
 	case "status_code":
 		return fmt.Sprintf("    assert response.status_code == %v\n", a.Expected)
@@ -120,11 +172,133 @@ func (e *PytestEmitter) emitAssertion(a model.Assertion) string {
 		path := e.parseBodyPath(a.Actual)
 		return fmt.Sprintf("    assert %s is not None\n", path)
 
+	case "less_than":
+		path := e.parseBodyPath(a.Actual)
+		return fmt.Sprintf("    assert %s < %v\n", path, a.Expected)
+
+	case model.AssertLength:
+		path := e.parseBodyPath(a.Actual)
+		return fmt.Sprintf("    assert len(%s) == %v\n", path, a.Expected)
+
+	case model.AssertContainsAll:
+		path := e.parseBodyPath(a.Actual)
+		return fmt.Sprintf("    assert all(item in %s for item in %s)\n", path, pythonLiteral(a.ExpectedItems()))
+
+	case model.AssertSubset:
+		path := e.parseBodyPath(a.Actual)
+		return fmt.Sprintf("    assert all(item in %s for item in %s)\n", pythonLiteral(a.ExpectedItems()), path)
+
+	case model.AssertSorted:
+		path := e.parseBodyPath(a.Actual)
+		return fmt.Sprintf("    assert %s == sorted(%s%s)\n", path, path, PythonSortArgs(a))
+
+	case model.AssertApprox:
+		path := e.parseBodyPath(a.Actual)
+		expected, eps := approxValues(a)
+		return fmt.Sprintf("    assert %s == pytest.approx(%s, abs=%s)\n", path, expected, eps)
+
+	case model.AssertMatches:
+		path := e.parseBodyPath(a.Actual)
+		return fmt.Sprintf("    assert re.search(%s, str(%s))\n", strconv.Quote(regexPattern(a)), path)
+
+	case model.AssertHeaderPresent, model.AssertHeaderEquals, model.AssertHeaderMatches,
+		model.AssertCookiePresent, model.AssertCookieEquals, model.AssertCookieMatches:
+		collection, name := pythonResponseCollection(a), strconv.Quote(a.ResponseName())
+		switch _, check, _ := a.ResponseCheck(); check {
+		case model.CheckPresent:
+			return fmt.Sprintf("    assert %s in %s\n", name, collection)
+		case model.CheckEquals:
+			return fmt.Sprintf("    assert %s.get(%s) == %s\n", collection, name, strconv.Quote(a.ExpectedString()))
+		default:
+			return fmt.Sprintf("    assert re.search(%s, %s.get(%s, \"\"))\n", strconv.Quote(regexPattern(a)), collection, name)
+		}
+
+	case model.AssertLatency:
+		return pythonLatencyCheck(a)
+
+	default:
+		return fmt.Sprintf("    # Unknown assertion kind: %s\n", a.Kind)
+	}
+}
+
+// emitAssertpyAssertion generates an assertion with assertpy's assert_that
+func (e *PytestEmitter) emitAssertpyAssertion(a model.Assertion) string {
+	switch a.Kind {
+	case "status_code":
+		return fmt.Sprintf("    assert_that(response.status_code).is_equal_to(%v)\n", a.Expected)
+
+	case "equality":
+		path := e.parseBodyPath(a.Actual)
+		expectedJSON, _ := json.Marshal(a.Expected)
+		return fmt.Sprintf("    assert_that(%s).is_equal_to(%s)\n", path, string(expectedJSON))
+
+	case "contains":
+		path := e.parseBodyPath(a.Actual)
+		expectedJSON, _ := json.Marshal(a.Expected)
+		return fmt.Sprintf("    assert_that(%s).contains(%s)\n", path, string(expectedJSON))
+
+	case "not_null":
+		path := e.parseBodyPath(a.Actual)
+		return fmt.Sprintf("    assert_that(%s).is_not_none()\n", path)
+
+	case "less_than":
+		path := e.parseBodyPath(a.Actual)
+		return fmt.Sprintf("    assert_that(%s).is_less_than(%v)\n", path, a.Expected)
+
+	case model.AssertLength:
+		path := e.parseBodyPath(a.Actual)
+		return fmt.Sprintf("    assert_that(%s).is_length(%v)\n", path, a.Expected)
+
+	case model.AssertContainsAll:
+		path := e.parseBodyPath(a.Actual)
+		return fmt.Sprintf("    assert_that(%s).contains(*%s)\n", path, pythonLiteral(a.ExpectedItems()))
+
+	case model.AssertSubset:
+		path := e.parseBodyPath(a.Actual)
+		return fmt.Sprintf("    assert_that(%s).is_subset_of(%s)\n", path, pythonLiteral(a.ExpectedItems()))
+
+	case model.AssertSorted:
+		path := e.parseBodyPath(a.Actual)
+		return fmt.Sprintf("    assert_that(%s).is_sorted(%s)\n", path, strings.TrimPrefix(PythonSortArgs(a), ", "))
+
+	case model.AssertApprox:
+		path := e.parseBodyPath(a.Actual)
+		expected, eps := approxValues(a)
+		return fmt.Sprintf("    assert_that(%s).is_close_to(%s, %s)\n", path, expected, eps)
+
+	case model.AssertMatches:
+		path := e.parseBodyPath(a.Actual)
+		return fmt.Sprintf("    assert_that(str(%s)).matches(%s)\n", path, strconv.Quote(regexPattern(a)))
+
+	case model.AssertHeaderPresent, model.AssertHeaderEquals, model.AssertHeaderMatches,
+		model.AssertCookiePresent, model.AssertCookieEquals, model.AssertCookieMatches:
+		collection, name := pythonResponseCollection(a), strconv.Quote(a.ResponseName())
+		switch _, check, _ := a.ResponseCheck(); check {
+		case model.CheckPresent:
+			return fmt.Sprintf("    assert_that(%s.get(%s)).is_not_none()\n", collection, name)
+		case model.CheckEquals:
+			return fmt.Sprintf("    assert_that(%s.get(%s)).is_equal_to(%s)\n", collection, name, strconv.Quote(a.ExpectedString()))
+		default:
+			return fmt.Sprintf("    assert_that(%s.get(%s, \"\")).matches(%s)\n", collection, name, strconv.Quote(regexPattern(a)))
+		}
+
+	case model.AssertLatency:
+		return pythonLatencyCheck(a)
+
 	default:
 		return fmt.Sprintf("    # Unknown assertion kind: %s\n", a.Kind)
 	}
 }
 
+// pythonResponseCollection returns the response's headers or cookies, which
+// a header or cookie assertion looks its name up in
+func pythonResponseCollection(a model.Assertion) string {
+	if target, _, _ := a.ResponseCheck(); target == model.ResponseCookie {
+		return "response.cookies"
+	}
+	return "response.headers"
+}
+
 func (e *PytestEmitter) parseBodyPath(actual string) string {
 	if actual == "status" {
 		return "response.status_code"
@@ -169,6 +343,12 @@ func (e *PytestEmitter) resolvePath(spec model.TestSpec) string {
 }
 
 func (e *PytestEmitter) generateTestName(spec model.TestSpec) string {
+	// Every GraphQL test shares one path, so it's named by its operation
+	if spec.GraphQL != nil {
+		kind, name := graphQLOperation(spec.GraphQL)
+		return fmt.Sprintf("test_graphql_%s_%s", kind, snakeCase(name))
+	}
+
 	// Convert path to valid Python function name
 	path := strings.ReplaceAll(spec.Path, "/", "_")
 	path = strings.ReplaceAll(path, ":", "")
@@ -0,0 +1,263 @@
+package emitter
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/QTest-hq/qtest/pkg/model"
+)
+
+// PytestGRPCEmitter generates pytest-grpc tests for Python gRPC services.
+// When specs name one servicer, pytest-grpc serves it in-process;
+// otherwise tests dial a running server at GRPC_TARGET.
+type PytestGRPCEmitter struct{}
+
+func (e *PytestGRPCEmitter) Name() string          { return "pytest-grpc" }
+func (e *PytestGRPCEmitter) Language() string      { return "python" }
+func (e *PytestGRPCEmitter) Framework() string     { return "pytest" }
+func (e *PytestGRPCEmitter) FileExtension() string { return "_test.py" }
+
+// Emit generates a complete test file from multiple specs. Specs without
+// a gRPC call are skipped.
+func (e *PytestGRPCEmitter) Emit(specs []model.TestSpec) (string, error) {
+	specs = grpcSpecs(specs)
+	if len(specs) == 0 {
+		return "", fmt.Errorf("no gRPC specs to emit")
+	}
+
+	// pytest-grpc serves a single servicer per module
+	local := inProcess(specs)
+	for _, spec := range specs[1:] {
+		if spec.GRPC.Service != specs[0].GRPC.Service {
+			local = false
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString("import pytest\nimport grpc\n")
+	if !local {
+		sb.WriteString("import os\n")
+	}
+	if anyAssertion(specs, model.AssertMatches) {
+		sb.WriteString("import re\n")
+	}
+
+	// Generated message and stub modules
+	stubs := make(map[string]bool)
+	for _, spec := range specs {
+		stubs[pythonStubsModule(spec.GRPC)] = true
+	}
+	for _, module := range sortedKeys(stubs) {
+		pkg, name := splitPythonModule(module)
+		messages := strings.TrimSuffix(name, "_grpc")
+		if pkg == "" {
+			sb.WriteString(fmt.Sprintf("import %s\nimport %s\n", messages, name))
+		} else {
+			sb.WriteString(fmt.Sprintf("from %s import %s, %s\n", pkg, messages, name))
+		}
+	}
+
+	first := specs[0].GRPC
+	if local {
+		servicer := pythonServicerClass(first.Server)
+		if first.ServerModule != "" && servicer != "" {
+			sb.WriteString(fmt.Sprintf("from %s import %s\n", first.ServerModule, servicer))
+		}
+		_, stubs := splitPythonModule(pythonStubsModule(first))
+		service := shortService(first.Service)
+		sb.WriteString(fmt.Sprintf(`
+
+@pytest.fixture(scope="module")
+def grpc_add_to_server():
+    return %[1]s.add_%[2]sServicer_to_server
+
+
+@pytest.fixture(scope="module")
+def grpc_servicer():
+    return %[3]s
+
+
+@pytest.fixture(scope="module")
+def grpc_stub_cls(grpc_channel):
+    return %[1]s.%[2]sStub
+`, stubs, service, first.Server))
+	} else {
+		sb.WriteString(fmt.Sprintf(`
+
+@pytest.fixture(scope="module")
+def grpc_channel():
+    """Connect to the server under test at GRPC_TARGET"""
+    channel = grpc.insecure_channel(os.environ.get("GRPC_TARGET", %q))
+    yield channel
+    channel.close()
+`, DefaultGRPCTarget))
+	}
+	sb.WriteString("\n\n")
+
+	used := make(map[string]int)
+	for _, spec := range specs {
+		sb.WriteString(e.emitTest(spec, uniqueName(used, e.generateTestName(spec)), local))
+		sb.WriteString("\n\n")
+	}
+
+	return sb.String(), nil
+}
+
+// EmitSingle generates test code for a single spec
+func (e *PytestGRPCEmitter) EmitSingle(spec model.TestSpec) (string, error) {
+	if spec.GRPC == nil {
+		return "", fmt.Errorf("spec %s has no gRPC call", spec.ID)
+	}
+	return e.emitTest(spec, e.generateTestName(spec), spec.GRPC.Server != ""), nil
+}
+
+func (e *PytestGRPCEmitter) emitTest(spec model.TestSpec, testName string, local bool) string {
+	call := spec.GRPC
+	_, stubs := splitPythonModule(pythonStubsModule(call))
+	messages := strings.TrimSuffix(stubs, "_grpc")
+
+	var sb strings.Builder
+	if local {
+		sb.WriteString(fmt.Sprintf("def %s(grpc_stub):\n", testName))
+	} else {
+		sb.WriteString(fmt.Sprintf("def %s(grpc_channel):\n", testName))
+	}
+	if spec.Description != "" {
+		sb.WriteString(fmt.Sprintf("    \"\"\"%s\"\"\"\n", spec.Description))
+	}
+	stub := "grpc_stub"
+	if !local {
+		stub = "stub"
+		sb.WriteString(fmt.Sprintf("    stub = %s.%sStub(grpc_channel)\n", stubs, shortService(call.Service)))
+	}
+
+	requestType := call.RequestType
+	if requestType == "" {
+		requestType = call.Method + "Request"
+	}
+	var args []string
+	for _, name := range sortedKeys(call.Request) {
+		args = append(args, fmt.Sprintf("%s=%s", name, pythonLiteral(call.Request[name])))
+	}
+	sb.WriteString(fmt.Sprintf("    request = %s.%s(%s)\n\n", messages, requestType, strings.Join(args, ", ")))
+
+	if expectsError(call) {
+		code := strings.ToUpper(strings.TrimSpace(call.Code))
+		sb.WriteString("    with pytest.raises(grpc.RpcError) as exc_info:\n")
+		sb.WriteString(fmt.Sprintf("        %s.%s(request)\n", stub, call.Method))
+		sb.WriteString(fmt.Sprintf("    assert exc_info.value.code() == grpc.StatusCode.%s\n", code))
+		return sb.String()
+	}
+
+	sb.WriteString(fmt.Sprintf("    response = %s.%s(request)\n", stub, call.Method))
+	for _, a := range spec.Assertions {
+		sb.WriteString(e.emitAssertion(a))
+	}
+	return sb.String()
+}
+
+func (e *PytestGRPCEmitter) emitAssertion(a model.Assertion) string {
+	// Status codes are checked by the call itself
+	if a.Kind == "status_code" || a.Actual == "status" || a.Actual == "code" {
+		return ""
+	}
+
+	target := "response"
+	if field := responseField(a.Actual); field != "" {
+		target += "." + field
+	}
+
+	switch a.Kind {
+	case "equality":
+		return fmt.Sprintf("    assert %s == %s\n", target, pythonLiteral(a.Expected))
+	case "not_null":
+		if target == "response" {
+			return "    assert response is not None\n"
+		}
+		// Unset message fields read as defaults rather than None
+		parent, field := target[:strings.LastIndex(target, ".")], target[strings.LastIndex(target, ".")+1:]
+		return fmt.Sprintf("    assert %s.HasField(%q)\n", parent, field)
+	case "contains":
+		return fmt.Sprintf("    assert %s in %s\n", pythonLiteral(a.Expected), target)
+	case model.AssertApprox:
+		expected, eps := approxValues(a)
+		return fmt.Sprintf("    assert %s == pytest.approx(%s, abs=%s)\n", target, expected, eps)
+	case model.AssertMatches:
+		return fmt.Sprintf("    assert re.search(%s, str(%s))\n", pythonLiteral(regexPattern(a)), target)
+	default:
+		return fmt.Sprintf("    # TODO: Assert %s %s %v\n", a.Actual, a.Kind, a.Expected)
+	}
+}
+
+// pythonLiteral writes a request or expected value as Python source
+func pythonLiteral(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return "None"
+	case bool:
+		if val {
+			return "True"
+		}
+		return "False"
+	case string:
+		data, _ := json.Marshal(val)
+		return string(data)
+	case float64:
+		if val == float64(int64(val)) {
+			return fmt.Sprintf("%d", int64(val))
+		}
+		return fmt.Sprintf("%v", val)
+	case []interface{}:
+		items := make([]string, len(val))
+		for i, item := range val {
+			items[i] = pythonLiteral(item)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case map[string]interface{}:
+		items := make([]string, 0, len(val))
+		for _, k := range sortedKeys(val) {
+			items = append(items, fmt.Sprintf("%q: %s", k, pythonLiteral(val[k])))
+		}
+		return "{" + strings.Join(items, ", ") + "}"
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// pythonStubsModule returns the *_pb2_grpc module a call's stubs live in,
+// guessing it from the service name when unknown
+func pythonStubsModule(call *model.GRPCCall) string {
+	if call.Stubs != "" {
+		return call.Stubs
+	}
+	service := strings.TrimSuffix(shortService(call.Service), "Service")
+	return snakeCase(service) + "_pb2_grpc"
+}
+
+// splitPythonModule splits a dotted module into its package and name
+func splitPythonModule(module string) (pkg, name string) {
+	if i := strings.LastIndex(module, "."); i >= 0 {
+		return module[:i], module[i+1:]
+	}
+	return "", module
+}
+
+// pythonServicerClass returns the class a servicer expression constructs:
+// UserServicer(db) is UserServicer
+func pythonServicerClass(server string) string {
+	if i := strings.Index(server, "("); i >= 0 {
+		server = server[:i]
+	}
+	if i := strings.Index(server, "."); i >= 0 {
+		server = server[:i]
+	}
+	return strings.TrimSpace(server)
+}
+
+func (e *PytestGRPCEmitter) generateTestName(spec model.TestSpec) string {
+	name := "test_" + snakeCase(spec.GRPC.Method)
+	if code := strings.ToLower(strings.TrimSpace(spec.GRPC.Code)); code != "" && code != "ok" {
+		name += "_" + code
+	}
+	return name
+}
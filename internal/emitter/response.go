@@ -0,0 +1,78 @@
+package emitter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/QTest-hq/qtest/pkg/model"
+)
+
+// anyResponseCheck reports whether any of specs asserts on a response
+// header or cookie, as target says
+func anyResponseCheck(specs []model.TestSpec, target string) bool {
+	for i := range specs {
+		for _, a := range specs[i].Assertions {
+			if t, _, ok := a.ResponseCheck(); ok && t == target {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// jsCookieHelper reads a cookie from the Set-Cookie headers of a Node
+// response
+const jsCookieHelper = `// cookieValue returns the value of the cookie the response sets with name,
+// or undefined when it sets none
+function cookieValue(headers, name) {
+  const cookie = [].concat(headers['set-cookie'] || []).find((c) => c.startsWith(name + '='));
+  return cookie === undefined ? undefined : cookie.split(';')[0].slice(name.length + 1);
+}
+
+`
+
+// jsResponseAssertion renders a header or cookie assertion with expect,
+// Jest's or chai's. headers is the expression of the response's headers,
+// whose names JavaScript clients lower-case.
+func jsResponseAssertion(a model.Assertion, headers, indent string, chai bool) string {
+	target, check, _ := a.ResponseCheck()
+	got := fmt.Sprintf("%s[%s]", headers, strconv.Quote(strings.ToLower(a.ResponseName())))
+	if target == model.ResponseCookie {
+		got = fmt.Sprintf("cookieValue(%s, %s)", headers, strconv.Quote(a.ResponseName()))
+	}
+
+	var matcher string
+	switch check {
+	case model.CheckPresent:
+		matcher = ".toBeDefined()"
+		if chai {
+			matcher = ".to.exist"
+		}
+	case model.CheckEquals:
+		matcher = fmt.Sprintf(".toBe(%s)", strconv.Quote(a.ExpectedString()))
+		if chai {
+			matcher = fmt.Sprintf(".to.equal(%s)", strconv.Quote(a.ExpectedString()))
+		}
+	default:
+		matcher = fmt.Sprintf(".toMatch(new RegExp(%s))", strconv.Quote(regexPattern(a)))
+		if chai {
+			matcher = fmt.Sprintf(".to.match(new RegExp(%s))", strconv.Quote(regexPattern(a)))
+		}
+	}
+	return fmt.Sprintf("%sexpect(%s)%s;\n", indent, got, matcher)
+}
+
+// pageHeaderTODO notes a header assertion browser tests can't make, since
+// the page doesn't expose the response's headers
+func pageHeaderTODO(a model.Assertion) string {
+	_, check, _ := a.ResponseCheck()
+	want := "is set"
+	switch check {
+	case model.CheckEquals:
+		want = "equals " + a.ExpectedString()
+	case model.CheckMatches:
+		want = "matches " + regexPattern(a)
+	}
+	return fmt.Sprintf("// TODO: Assert header %s %s (page tests don't see response headers)\n", a.ResponseName(), want)
+}
@@ -3,6 +3,7 @@ package emitter
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/QTest-hq/qtest/pkg/model"
@@ -117,6 +118,65 @@ func (e *RSpecEmitter) emitAssertion(a model.Assertion) string {
 		path := e.parseBodyPath(a.Actual)
 		return fmt.Sprintf("      expect(%s).not_to be_nil\n", path)
 
+	case "less_than":
+		path := e.parseBodyPath(a.Actual)
+		return fmt.Sprintf("      expect(%s).to be < %v\n", path, a.Expected)
+
+	case model.AssertLength:
+		path := e.parseBodyPath(a.Actual)
+		return fmt.Sprintf("      expect(%s.length).to eq(%v)\n", path, a.Expected)
+
+	case model.AssertContainsAll:
+		path := e.parseBodyPath(a.Actual)
+		items := e.formatRubyValue(a.ExpectedItems())
+		return fmt.Sprintf("      expect(%s).to include(*%s)\n", path, items)
+
+	case model.AssertSubset:
+		path := e.parseBodyPath(a.Actual)
+		items := e.formatRubyValue(a.ExpectedItems())
+		return fmt.Sprintf("      expect(%s - %s).to be_empty\n", path, items)
+
+	case model.AssertSorted:
+		path := e.parseBodyPath(a.Actual)
+		key, desc := a.SortKey()
+		x, y := "a", "b"
+		if key != "" {
+			x, y = fmt.Sprintf("a['%s']", key), fmt.Sprintf("b['%s']", key)
+		}
+		op := "<="
+		if desc {
+			op = ">="
+		}
+		return fmt.Sprintf("      expect(%s.each_cons(2).all? { |a, b| (%s <=> %s) %s 0 }).to be(true)\n", path, x, y, op)
+
+	case model.AssertApprox:
+		path := e.parseBodyPath(a.Actual)
+		expected, eps := approxValues(a)
+		return fmt.Sprintf("      expect(%s).to be_within(%s).of(%s)\n", path, eps, expected)
+
+	case model.AssertMatches:
+		path := e.parseBodyPath(a.Actual)
+		if a.Actual == "body" {
+			path = "response.body"
+		}
+		return fmt.Sprintf("      expect(%s.to_s).to match(Regexp.new(%s))\n", path, strconv.Quote(regexPattern(a)))
+
+	case model.AssertHeaderPresent, model.AssertHeaderEquals, model.AssertHeaderMatches,
+		model.AssertCookiePresent, model.AssertCookieEquals, model.AssertCookieMatches:
+		target, check, _ := a.ResponseCheck()
+		got := fmt.Sprintf("response.headers[%s]", strconv.Quote(a.ResponseName()))
+		if target == model.ResponseCookie {
+			got = fmt.Sprintf("response.cookies[%s]", strconv.Quote(a.ResponseName()))
+		}
+		switch check {
+		case model.CheckPresent:
+			return fmt.Sprintf("      expect(%s).not_to be_nil\n", got)
+		case model.CheckEquals:
+			return fmt.Sprintf("      expect(%s).to eq(%s)\n", got, strconv.Quote(a.ExpectedString()))
+		default:
+			return fmt.Sprintf("      expect(%s.to_s).to match(Regexp.new(%s))\n", got, strconv.Quote(regexPattern(a)))
+		}
+
 	case "type":
 		path := e.parseBodyPath(a.Actual)
 		rubyType := e.goTypeToRubyClass(fmt.Sprintf("%v", a.Expected))
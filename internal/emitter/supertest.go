@@ -3,13 +3,23 @@ package emitter
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/QTest-hq/qtest/pkg/model"
 )
 
 // SupertestEmitter generates Jest + Supertest tests for Express APIs
-type SupertestEmitter struct{}
+type SupertestEmitter struct {
+	// Allure adds allure-jest label calls (epic, feature, story, id) to each test
+	Allure bool
+
+	// Tags appends @tags to describe and test names for jest -t filtering
+	Tags TagConfig
+
+	// Assertions is the assertion library: AssertChai or "" for Jest's expect
+	Assertions string
+}
 
 func (e *SupertestEmitter) Name() string          { return "supertest" }
 func (e *SupertestEmitter) Language() string      { return "javascript" }
@@ -21,19 +31,36 @@ func (e *SupertestEmitter) Emit(specs []model.TestSpec) (string, error) {
 	var sb strings.Builder
 
 	// File header
-	sb.WriteString(`const request = require('supertest');
-const app = require('./app');
+	sb.WriteString("const request = require('supertest');\n")
+	if e.Assertions == AssertChai {
+		sb.WriteString("const { expect } = require('chai');\n")
+	}
+	sb.WriteString("const app = require('./app');\n\n")
+	if anyResponseCheck(specs, model.ResponseCookie) {
+		sb.WriteString(jsCookieHelper)
+	}
+	if anyAssertion(specs, model.AssertLatency) {
+		sb.WriteString(jsLatencyHelper(e.Assertions == AssertChai))
+	}
 
-`)
+	// jest-circus retries must be configured at the top of the file
+	if retries := maxRetries(specs); retries > 0 {
+		sb.WriteString(fmt.Sprintf("jest.retryTimes(%d);\n\n", retries))
+	}
 
 	// Group specs by path prefix for describe blocks
-	groups := e.groupByPath(specs)
+	normalized := make([]model.TestSpec, len(specs))
+	for i := range specs {
+		normalized[i] = graphQLSpec(specs[i])
+	}
+	groups := e.groupByPath(normalized)
 
 	for groupName, groupSpecs := range groups {
-		sb.WriteString(fmt.Sprintf("describe('%s', () => {\n", groupName))
+		groupTags := e.Tags.commonTags(groupSpecs)
+		sb.WriteString(fmt.Sprintf("describe('%s%s', () => {\n", groupName, jestSuffix(groupTags, nil)))
 
 		for _, spec := range groupSpecs {
-			testCode, err := e.emitTest(spec)
+			testCode, err := e.emitTaggedTest(spec, groupTags)
 			if err != nil {
 				continue
 			}
@@ -49,46 +76,87 @@ const app = require('./app');
 
 // EmitSingle generates test code for a single spec
 func (e *SupertestEmitter) EmitSingle(spec model.TestSpec) (string, error) {
-	return e.emitTest(spec)
+	return e.emitTaggedTest(spec, nil)
 }
 
-func (e *SupertestEmitter) emitTest(spec model.TestSpec) (string, error) {
+// emitTaggedTest emits a test, tagging it with any categories not already
+// present on the enclosing describe block
+func (e *SupertestEmitter) emitTaggedTest(spec model.TestSpec, describeTags []string) (string, error) {
 	var sb strings.Builder
+	spec = graphQLSpec(spec)
 
 	// Test function
-	testName := e.generateTestName(spec)
+	testName := e.generateTestName(spec) + jestSuffix(e.Tags.TagsFor(spec), describeTags)
 	sb.WriteString(fmt.Sprintf("  test('%s', async () => {\n", testName))
+	if e.Allure {
+		sb.WriteString(allureJestCalls(spec, "    "))
+	}
 
 	// Build the request
+	chain := e.requestChain(spec)
 	sb.WriteString("    const response = await request(app)\n")
-	sb.WriteString(fmt.Sprintf("      .%s('%s')\n", strings.ToLower(spec.Method), e.resolvePath(spec)))
-
-	// Add headers
-	if len(spec.Headers) > 0 {
-		for key, value := range spec.Headers {
-			sb.WriteString(fmt.Sprintf("      .set('%s', '%s')\n", key, value))
-		}
+	for _, call := range chain {
+		sb.WriteString("      " + call + "\n")
 	}
-
-	// Add request body for POST/PUT/PATCH
-	if spec.Body != nil && (spec.Method == "POST" || spec.Method == "PUT" || spec.Method == "PATCH") {
-		bodyJSON, _ := json.Marshal(spec.Body)
-		sb.WriteString(fmt.Sprintf("      .send(%s)\n", string(bodyJSON)))
-	}
-
 	sb.WriteString(";\n\n")
 
-	// Add assertions
+	// Add assertions; latency checks send the request again
 	for _, assertion := range spec.Assertions {
+		if assertion.Kind == model.AssertLatency {
+			sb.WriteString(jsLatencyCheck(assertion, "request(app)"+strings.Join(chain, ""), "    "))
+			continue
+		}
 		sb.WriteString(e.emitAssertion(assertion))
 	}
 
-	sb.WriteString("  });\n")
+	// GraphQL reports failures in the body with a 200 status
+	if spec.GraphQL != nil {
+		if e.Assertions == AssertChai {
+			sb.WriteString("    expect(response.body.errors).to.be.undefined;\n")
+		} else {
+			sb.WriteString("    expect(response.body.errors).toBeUndefined();\n")
+		}
+	}
+
+	if timeout, _ := spec.ExecutionLimits(); timeout > 0 {
+		sb.WriteString(fmt.Sprintf("  }, %d);\n", timeout*1000))
+	} else {
+		sb.WriteString("  });\n")
+	}
 
 	return sb.String(), nil
 }
 
+// requestChain returns the supertest calls that build a spec's request:
+// the method and path, its headers, and the body for POST/PUT/PATCH
+func (e *SupertestEmitter) requestChain(spec model.TestSpec) []string {
+	chain := []string{fmt.Sprintf(".%s('%s')", strings.ToLower(spec.Method), e.resolvePath(spec))}
+	for key, value := range spec.Headers {
+		chain = append(chain, fmt.Sprintf(".set('%s', '%s')", key, value))
+	}
+	if spec.Body != nil && (spec.Method == "POST" || spec.Method == "PUT" || spec.Method == "PATCH") {
+		bodyJSON, _ := json.Marshal(spec.Body)
+		chain = append(chain, fmt.Sprintf(".send(%s)", string(bodyJSON)))
+	}
+	return chain
+}
+
+// maxRetries returns the largest retry count requested by any spec
+func maxRetries(specs []model.TestSpec) int {
+	max := 0
+	for i := range specs {
+		if _, retries := specs[i].ExecutionLimits(); retries > max {
+			max = retries
+		}
+	}
+	return max
+}
+
 func (e *SupertestEmitter) emitAssertion(a model.Assertion) string {
+	if e.Assertions == AssertChai {
+		return e.emitChaiAssertion(a)
+	}
+
 	switch a.Kind {
 	case "status_code":
 		return fmt.Sprintf("    expect(response.status).toBe(%v);\n", a.Expected)
@@ -107,6 +175,97 @@ func (e *SupertestEmitter) emitAssertion(a model.Assertion) string {
 		path := e.parseBodyPath(a.Actual)
 		return fmt.Sprintf("    expect(%s).toBeDefined();\n", path)
 
+	case "less_than":
+		path := e.parseBodyPath(a.Actual)
+		return fmt.Sprintf("    expect(%s).toBeLessThan(%v);\n", path, a.Expected)
+
+	case model.AssertLength:
+		path := e.parseBodyPath(a.Actual)
+		return fmt.Sprintf("    expect(%s).toHaveLength(%v);\n", path, a.Expected)
+
+	case model.AssertContainsAll:
+		path := e.parseBodyPath(a.Actual)
+		return fmt.Sprintf("    expect(%s).toEqual(expect.arrayContaining(%s));\n", path, expectedItemsJSON(a))
+
+	case model.AssertSubset:
+		path := e.parseBodyPath(a.Actual)
+		return fmt.Sprintf("    expect(%s).toEqual(expect.arrayContaining(%s));\n", expectedItemsJSON(a), path)
+
+	case model.AssertSorted:
+		path := e.parseBodyPath(a.Actual)
+		return fmt.Sprintf("    expect(%s).toEqual([...%s].sort(%s));\n", path, path, JSSortComparator(a))
+
+	case model.AssertApprox:
+		path := e.parseBodyPath(a.Actual)
+		expected, eps := approxValues(a)
+		return fmt.Sprintf("    expect(Math.abs(%s - %s)).toBeLessThanOrEqual(%s);\n", path, expected, eps)
+
+	case model.AssertMatches:
+		path := e.parseBodyPath(a.Actual)
+		return fmt.Sprintf("    expect(String(%s)).toMatch(new RegExp(%s));\n", path, strconv.Quote(regexPattern(a)))
+
+	case model.AssertHeaderPresent, model.AssertHeaderEquals, model.AssertHeaderMatches,
+		model.AssertCookiePresent, model.AssertCookieEquals, model.AssertCookieMatches:
+		return jsResponseAssertion(a, "response.headers", "    ", false)
+
+	default:
+		return fmt.Sprintf("    // Unknown assertion kind: %s\n", a.Kind)
+	}
+}
+
+// emitChaiAssertion generates an assertion with chai's expect
+func (e *SupertestEmitter) emitChaiAssertion(a model.Assertion) string {
+	switch a.Kind {
+	case "status_code":
+		return fmt.Sprintf("    expect(response.status).to.equal(%v);\n", a.Expected)
+
+	case "equality":
+		path := e.parseBodyPath(a.Actual)
+		expectedJSON, _ := json.Marshal(a.Expected)
+		return fmt.Sprintf("    expect(%s).to.deep.equal(%s);\n", path, string(expectedJSON))
+
+	case "contains":
+		path := e.parseBodyPath(a.Actual)
+		expectedJSON, _ := json.Marshal(a.Expected)
+		return fmt.Sprintf("    expect(%s).to.deep.include(%s);\n", path, string(expectedJSON))
+
+	case "not_null":
+		path := e.parseBodyPath(a.Actual)
+		return fmt.Sprintf("    expect(%s).to.not.be.undefined;\n", path)
+
+	case "less_than":
+		path := e.parseBodyPath(a.Actual)
+		return fmt.Sprintf("    expect(%s).to.be.below(%v);\n", path, a.Expected)
+
+	case model.AssertLength:
+		path := e.parseBodyPath(a.Actual)
+		return fmt.Sprintf("    expect(%s).to.have.lengthOf(%v);\n", path, a.Expected)
+
+	case model.AssertContainsAll:
+		path := e.parseBodyPath(a.Actual)
+		return fmt.Sprintf("    expect(%s).to.deep.include.members(%s);\n", path, expectedItemsJSON(a))
+
+	case model.AssertSubset:
+		path := e.parseBodyPath(a.Actual)
+		return fmt.Sprintf("    expect(%s).to.deep.include.members(%s);\n", expectedItemsJSON(a), path)
+
+	case model.AssertSorted:
+		path := e.parseBodyPath(a.Actual)
+		return fmt.Sprintf("    expect(%s).to.deep.equal([...%s].sort(%s));\n", path, path, JSSortComparator(a))
+
+	case model.AssertApprox:
+		path := e.parseBodyPath(a.Actual)
+		expected, eps := approxValues(a)
+		return fmt.Sprintf("    expect(%s).to.be.closeTo(%s, %s);\n", path, expected, eps)
+
+	case model.AssertMatches:
+		path := e.parseBodyPath(a.Actual)
+		return fmt.Sprintf("    expect(String(%s)).to.match(new RegExp(%s));\n", path, strconv.Quote(regexPattern(a)))
+
+	case model.AssertHeaderPresent, model.AssertHeaderEquals, model.AssertHeaderMatches,
+		model.AssertCookiePresent, model.AssertCookieEquals, model.AssertCookieMatches:
+		return jsResponseAssertion(a, "response.headers", "    ", true)
+
 	default:
 		return fmt.Sprintf("    // Unknown assertion kind: %s\n", a.Kind)
 	}
@@ -154,6 +313,10 @@ func (e *SupertestEmitter) generateTestName(spec model.TestSpec) string {
 	if spec.Description != "" {
 		return spec.Description
 	}
+	if spec.GraphQL != nil {
+		kind, name := graphQLOperation(spec.GraphQL)
+		return fmt.Sprintf("%s %s returns data", kind, name)
+	}
 	return fmt.Sprintf("%s %s returns expected response", spec.Method, spec.Path)
 }
 
@@ -0,0 +1,160 @@
+package emitter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/QTest-hq/qtest/pkg/model"
+)
+
+// Well-known test categories
+const (
+	TagGenerated = "generated"
+	TagSlow      = model.TagSlow
+)
+
+// TagConfig controls how test categories (unit/api/e2e/slow/generated) are
+// emitted into generated code so CI pipelines can include or exclude them.
+// Go output uses build constraints, pytest uses markers and Jest uses
+// @tags in describe/test names.
+type TagConfig struct {
+	Enabled bool
+
+	// Default tags applied to every emitted test (e.g. "generated")
+	Default []string
+}
+
+// DefaultTagConfig returns tagging enabled with the "generated" tag
+func DefaultTagConfig() TagConfig {
+	return TagConfig{Enabled: true, Default: []string{TagGenerated}}
+}
+
+// TagsFor returns the sorted, de-duplicated tags for a spec: its level,
+// the configured defaults and any tags set by the planner
+func (c TagConfig) TagsFor(spec model.TestSpec) []string {
+	if !c.Enabled {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	var tags []string
+	add := func(tag string) {
+		tag = sanitizeTag(tag)
+		if tag != "" && !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+
+	if spec.Level != "" {
+		add(string(spec.Level))
+	}
+	for _, t := range c.Default {
+		add(t)
+	}
+	for _, t := range spec.Tags {
+		add(t)
+	}
+
+	sort.Strings(tags)
+	return tags
+}
+
+// commonTags returns the tags shared by every spec
+func (c TagConfig) commonTags(specs []model.TestSpec) []string {
+	if len(specs) == 0 {
+		return nil
+	}
+
+	counts := make(map[string]int)
+	for _, spec := range specs {
+		for _, t := range c.TagsFor(spec) {
+			counts[t]++
+		}
+	}
+
+	var common []string
+	for tag, n := range counts {
+		if n == len(specs) {
+			common = append(common, tag)
+		}
+	}
+	sort.Strings(common)
+	return common
+}
+
+// allTags returns the union of tags across specs
+func (c TagConfig) allTags(specs []model.TestSpec) []string {
+	seen := make(map[string]bool)
+	var all []string
+	for _, spec := range specs {
+		for _, t := range c.TagsFor(spec) {
+			if !seen[t] {
+				seen[t] = true
+				all = append(all, t)
+			}
+		}
+	}
+	sort.Strings(all)
+	return all
+}
+
+// goBuildConstraint renders a //go:build line that includes the file when
+// any of its tags is passed via -tags
+func (c TagConfig) goBuildConstraint(specs []model.TestSpec) string {
+	tags := c.allTags(specs)
+	if len(tags) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("//go:build %s\n\n", strings.Join(tags, " || "))
+}
+
+// pytestMarkers renders @pytest.mark decorators for a spec
+func (c TagConfig) pytestMarkers(spec model.TestSpec) string {
+	var sb strings.Builder
+	for _, t := range c.TagsFor(spec) {
+		sb.WriteString(fmt.Sprintf("@pytest.mark.%s\n", t))
+	}
+	return sb.String()
+}
+
+// jestSuffix renders " @tag" annotations, omitting tags already on the describe block
+func jestSuffix(tags, exclude []string) string {
+	skip := make(map[string]bool, len(exclude))
+	for _, t := range exclude {
+		skip[t] = true
+	}
+
+	var sb strings.Builder
+	for _, t := range tags {
+		if !skip[t] {
+			sb.WriteString(" @" + t)
+		}
+	}
+	return sb.String()
+}
+
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// sanitizeTag lowercases a tag and keeps only characters valid in Go build
+// tags and Python identifiers
+func sanitizeTag(tag string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToLower(strings.TrimSpace(tag)) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '_':
+			sb.WriteRune(r)
+		case r == '-' || r == ' ' || r == '.':
+			sb.WriteRune('_')
+		}
+	}
+	return sb.String()
+}
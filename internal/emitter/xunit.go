@@ -0,0 +1,334 @@
+package emitter
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/QTest-hq/qtest/pkg/model"
+)
+
+// XUnitEmitter generates xUnit tests for C#/ASP.NET Core, run in-process
+// against the app through WebApplicationFactory
+type XUnitEmitter struct{}
+
+func (e *XUnitEmitter) Name() string          { return "xunit" }
+func (e *XUnitEmitter) Language() string      { return "csharp" }
+func (e *XUnitEmitter) Framework() string     { return "xunit" }
+func (e *XUnitEmitter) FileExtension() string { return "Tests.cs" }
+
+// Emit generates a complete test file from multiple specs
+func (e *XUnitEmitter) Emit(specs []model.TestSpec) (string, error) {
+	var sb strings.Builder
+
+	// Usings
+	if anyAssertion(specs, model.AssertContainsAll) || anyAssertion(specs, model.AssertSubset) {
+		sb.WriteString("using System.Collections.Generic;\nusing System.Linq;\n")
+	}
+	sb.WriteString(`using System.Net.Http;
+using System.Text;
+using System.Text.Json;
+using System.Threading.Tasks;
+using Microsoft.AspNetCore.Mvc.Testing;
+using Xunit;
+
+`)
+
+	// Namespace (default)
+	sb.WriteString("namespace Api.Tests;\n\n")
+
+	// Class declaration with the app factory as a shared fixture
+	sb.WriteString("public class ApiTests : IClassFixture<WebApplicationFactory<Program>>\n")
+	sb.WriteString("{\n")
+	sb.WriteString("    private readonly HttpClient _client;\n\n")
+	sb.WriteString("    public ApiTests(WebApplicationFactory<Program> factory)\n")
+	sb.WriteString("    {\n")
+	sb.WriteString("        _client = factory.CreateClient();\n")
+	sb.WriteString("    }\n\n")
+
+	// Generate tests; C# methods in a class need distinct names
+	used := make(map[string]int)
+	for _, spec := range specs {
+		name := e.generateTestName(spec)
+		used[name]++
+		if n := used[name]; n > 1 {
+			name = fmt.Sprintf("%s_%d", name, n)
+		}
+		sb.WriteString(e.emitTest(spec, name))
+		sb.WriteString("\n")
+	}
+
+	// JSON field lookup used by body assertions
+	sb.WriteString(`    private static JsonElement? Field(JsonElement element, string path)
+    {
+        foreach (var name in path.Split('.'))
+        {
+            if (element.ValueKind != JsonValueKind.Object || !element.TryGetProperty(name, out element))
+            {
+                return null;
+            }
+        }
+        return element;
+    }
+`)
+	if anyResponseCheck(specs, model.ResponseHeader) {
+		sb.WriteString(csharpHeaderHelper)
+	}
+	if anyResponseCheck(specs, model.ResponseCookie) {
+		sb.WriteString(csharpCookieHelper)
+	}
+
+	// Close class
+	sb.WriteString("}\n")
+
+	return sb.String(), nil
+}
+
+// EmitSingle generates test code for a single spec
+func (e *XUnitEmitter) EmitSingle(spec model.TestSpec) (string, error) {
+	return e.emitTest(spec, e.generateTestName(spec)), nil
+}
+
+func (e *XUnitEmitter) emitTest(spec model.TestSpec, testName string) string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("    [Fact(DisplayName = \"%s\")]\n", e.escapeCSharpString(e.generateDisplayName(spec))))
+	sb.WriteString(fmt.Sprintf("    public async Task %s()\n", testName))
+	sb.WriteString("    {\n")
+
+	// Build the request
+	method := strings.ToUpper(spec.Method)
+	if method == "" {
+		method = "GET"
+	}
+	path := e.resolvePath(spec)
+	sb.WriteString(fmt.Sprintf("        var request = new HttpRequestMessage(new HttpMethod(\"%s\"), \"%s\");\n",
+		method, e.escapeCSharpString(path)))
+
+	// Add the body for body requests
+	if spec.Body != nil && (spec.Method == "POST" || spec.Method == "PUT" || spec.Method == "PATCH") {
+		bodyJSON, _ := json.Marshal(spec.Body)
+		sb.WriteString(fmt.Sprintf("        request.Content = new StringContent(\"%s\", Encoding.UTF8, \"application/json\");\n",
+			e.escapeCSharpString(string(bodyJSON))))
+	}
+
+	// Add headers
+	for key, value := range spec.Headers {
+		sb.WriteString(fmt.Sprintf("        request.Headers.TryAddWithoutValidation(\"%s\", \"%s\");\n",
+			e.escapeCSharpString(key), e.escapeCSharpString(value)))
+	}
+
+	sb.WriteString("\n        var response = await _client.SendAsync(request);\n")
+	sb.WriteString("        var body = await response.Content.ReadAsStringAsync();\n\n")
+
+	// Parse the body only when an assertion looks inside it
+	for _, a := range spec.Assertions {
+		switch a.Kind {
+		case "equality", "not_null", model.AssertApprox, model.AssertMatches,
+			model.AssertLength, model.AssertContainsAll, model.AssertSubset:
+		default:
+			continue
+		}
+		if strings.HasPrefix(a.Actual, "body.") {
+			sb.WriteString("        using var json = JsonDocument.Parse(body);\n")
+			break
+		}
+	}
+
+	// Default status assertion if none specified
+	hasStatusAssertion := false
+	for _, a := range spec.Assertions {
+		if a.Kind == "status_code" {
+			hasStatusAssertion = true
+			break
+		}
+	}
+	if !hasStatusAssertion {
+		sb.WriteString("        response.EnsureSuccessStatusCode();\n")
+	}
+
+	// Add assertions
+	for _, assertion := range spec.Assertions {
+		sb.WriteString(e.emitAssertion(assertion))
+	}
+
+	sb.WriteString("    }\n")
+
+	return sb.String()
+}
+
+func (e *XUnitEmitter) emitAssertion(a model.Assertion) string {
+	switch a.Kind {
+	case "status_code":
+		return fmt.Sprintf("        Assert.Equal(%v, (int)response.StatusCode);\n", a.Expected)
+
+	case "equality":
+		if strings.HasPrefix(a.Actual, "body.") {
+			// Compare raw JSON so strings, numbers and bools all work
+			field := strings.TrimPrefix(a.Actual, "body.")
+			expectedJSON, _ := json.Marshal(a.Expected)
+			return fmt.Sprintf("        Assert.Equal(\"%s\", Field(json.RootElement, \"%s\")?.GetRawText());\n",
+				e.escapeCSharpString(string(expectedJSON)), field)
+		}
+		return fmt.Sprintf("        // TODO: Assert %s equals %v\n", a.Actual, a.Expected)
+
+	case "not_null":
+		if strings.HasPrefix(a.Actual, "body.") {
+			field := strings.TrimPrefix(a.Actual, "body.")
+			return fmt.Sprintf("        Assert.True(Field(json.RootElement, \"%s\") is { ValueKind: not JsonValueKind.Null });\n", field)
+		}
+		return fmt.Sprintf("        // TODO: Assert %s is not null\n", a.Actual)
+
+	case "contains":
+		return fmt.Sprintf("        Assert.Contains(\"%s\", body);\n", e.escapeCSharpString(fmt.Sprintf("%v", a.Expected)))
+
+	case model.AssertLength:
+		if strings.HasPrefix(a.Actual, "body.") {
+			field := strings.TrimPrefix(a.Actual, "body.")
+			return fmt.Sprintf("        Assert.Equal(%v, Field(json.RootElement, \"%s\")!.Value.GetArrayLength());\n", a.Expected, field)
+		}
+		return fmt.Sprintf("        // TODO: Assert %s has length %v\n", a.Actual, a.Expected)
+
+	case model.AssertContainsAll, model.AssertSubset:
+		if strings.HasPrefix(a.Actual, "body.") {
+			// Compare items as raw JSON, like equality
+			field := strings.TrimPrefix(a.Actual, "body.")
+			items := make([]string, 0)
+			for _, item := range a.ExpectedItems() {
+				itemJSON, _ := json.Marshal(item)
+				items = append(items, "\""+e.escapeCSharpString(string(itemJSON))+"\"")
+			}
+			assert := "Superset"
+			if a.Kind == model.AssertSubset {
+				assert = "Subset"
+			}
+			return fmt.Sprintf("        Assert.%s(new HashSet<string> { %s }, Field(json.RootElement, \"%s\")!.Value.EnumerateArray().Select(item => item.GetRawText()).ToHashSet());\n",
+				assert, strings.Join(items, ", "), field)
+		}
+		return fmt.Sprintf("        // TODO: Assert %s %s %v\n", a.Actual, a.Kind, a.Expected)
+
+	case model.AssertSorted:
+		return fmt.Sprintf("        // TODO: Assert %s is %s\n", a.Actual, sortDescription(a))
+
+	case model.AssertApprox:
+		if strings.HasPrefix(a.Actual, "body.") {
+			field := strings.TrimPrefix(a.Actual, "body.")
+			expected, eps := approxValues(a)
+			return fmt.Sprintf("        Assert.Equal(%s, Field(json.RootElement, \"%s\")!.Value.GetDouble(), %s);\n", expected, field, eps)
+		}
+		return fmt.Sprintf("        // TODO: Assert %s is approximately %v\n", a.Actual, a.Expected)
+
+	case model.AssertMatches:
+		pattern := e.escapeCSharpString(regexPattern(a))
+		if strings.HasPrefix(a.Actual, "body.") {
+			field := strings.TrimPrefix(a.Actual, "body.")
+			return fmt.Sprintf("        Assert.Matches(\"%s\", Field(json.RootElement, \"%s\")?.ToString());\n", pattern, field)
+		}
+		if a.Actual == "body" {
+			return fmt.Sprintf("        Assert.Matches(\"%s\", body);\n", pattern)
+		}
+		return fmt.Sprintf("        // TODO: Assert %s matches %v\n", a.Actual, a.Expected)
+
+	case model.AssertHeaderPresent, model.AssertHeaderEquals, model.AssertHeaderMatches,
+		model.AssertCookiePresent, model.AssertCookieEquals, model.AssertCookieMatches:
+		target, check, _ := a.ResponseCheck()
+		got := fmt.Sprintf("Header(response, \"%s\")", e.escapeCSharpString(a.ResponseName()))
+		if target == model.ResponseCookie {
+			got = fmt.Sprintf("Cookie(response, \"%s\")", e.escapeCSharpString(a.ResponseName()))
+		}
+		switch check {
+		case model.CheckPresent:
+			return fmt.Sprintf("        Assert.NotNull(%s);\n", got)
+		case model.CheckEquals:
+			return fmt.Sprintf("        Assert.Equal(\"%s\", %s);\n", e.escapeCSharpString(a.ExpectedString()), got)
+		default:
+			return fmt.Sprintf("        Assert.Matches(\"%s\", %s ?? \"\");\n", e.escapeCSharpString(regexPattern(a)), got)
+		}
+
+	default:
+		return fmt.Sprintf("        // Unknown assertion kind: %s\n", a.Kind)
+	}
+}
+
+// csharpHeaderHelper reads a response header, which HttpClient keeps on the
+// response or, for Content-Type and the like, its content
+const csharpHeaderHelper = `
+    private static string? Header(HttpResponseMessage response, string name)
+    {
+        if (response.Headers.TryGetValues(name, out var values) || response.Content.Headers.TryGetValues(name, out values))
+        {
+            return string.Join(", ", values);
+        }
+        return null;
+    }
+`
+
+// csharpCookieHelper reads a cookie from the response's Set-Cookie headers
+const csharpCookieHelper = `
+    private static string? Cookie(HttpResponseMessage response, string name)
+    {
+        if (!response.Headers.TryGetValues("Set-Cookie", out var values))
+        {
+            return null;
+        }
+        foreach (var cookie in values)
+        {
+            if (cookie.StartsWith(name + "="))
+            {
+                return cookie.Substring(name.Length + 1).Split(';')[0];
+            }
+        }
+        return null;
+    }
+`
+
+func (e *XUnitEmitter) resolvePath(spec model.TestSpec) string {
+	path := spec.Path
+
+	// Replace path parameters
+	for key, value := range spec.PathParams {
+		placeholder := ":" + key
+		path = strings.Replace(path, placeholder, fmt.Sprintf("%v", value), 1)
+		placeholder = "{" + key + "}"
+		path = strings.Replace(path, placeholder, fmt.Sprintf("%v", value), 1)
+	}
+
+	// Add query parameters
+	if len(spec.QueryParams) > 0 {
+		params := make([]string, 0)
+		for key, value := range spec.QueryParams {
+			params = append(params, fmt.Sprintf("%s=%v", key, value))
+		}
+		path += "?" + strings.Join(params, "&")
+	}
+
+	return path
+}
+
+func (e *XUnitEmitter) generateTestName(spec model.TestSpec) string {
+	// Convert path to a valid C# method name
+	path := strings.ReplaceAll(spec.Path, "/", "_")
+	path = strings.ReplaceAll(path, ":", "")
+	path = strings.ReplaceAll(path, "{", "")
+	path = strings.ReplaceAll(path, "}", "")
+	path = strings.ReplaceAll(path, "-", "_")
+	path = strings.ReplaceAll(path, ".", "_")
+	path = strings.TrimPrefix(path, "_")
+	path = strings.TrimSuffix(path, "_")
+
+	if path == "" {
+		path = "Root"
+	}
+
+	return fmt.Sprintf("%s_%s", strings.Title(strings.ToLower(spec.Method)), path)
+}
+
+func (e *XUnitEmitter) generateDisplayName(spec model.TestSpec) string {
+	return fmt.Sprintf("%s %s", spec.Method, spec.Path)
+}
+
+func (e *XUnitEmitter) escapeCSharpString(s string) string {
+	s = strings.ReplaceAll(s, "\\", "\\\\")
+	s = strings.ReplaceAll(s, "\"", "\\\"")
+	return s
+}
@@ -0,0 +1,91 @@
+package generator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/QTest-hq/qtest/internal/llm"
+	"github.com/QTest-hq/qtest/internal/parser"
+	"github.com/rs/zerolog/log"
+)
+
+// ErrEmptyOutput is reported when the LLM's output held no test cases
+var ErrEmptyOutput = errors.New("LLM output has no test cases")
+
+// OutputError is a generation failure caused by what the LLM returned,
+// rather than by the call itself, so a stronger tier may do better
+type OutputError struct {
+	Tier   llm.Tier // tier that produced the output
+	Reason string   // short description, e.g. "malformed output"
+	Err    error
+}
+
+func (e *OutputError) Error() string {
+	return fmt.Sprintf("tier %d %s: %v", e.Tier, e.Reason, e.Err)
+}
+
+func (e *OutputError) Unwrap() error {
+	return e.Err
+}
+
+// malformedOutput reports output that couldn't be parsed as tests
+func malformedOutput(tier llm.Tier, err error) *OutputError {
+	return &OutputError{Tier: tier, Reason: "malformed output", Err: err}
+}
+
+// emptyOutput reports output that parsed but held no test cases
+func emptyOutput(tier llm.Tier) *OutputError {
+	return &OutputError{Tier: tier, Reason: "empty output", Err: ErrEmptyOutput}
+}
+
+// Escalation is a retry of a function's generation at a higher tier
+type Escalation struct {
+	From   llm.Tier
+	To     llm.Tier
+	Reason string // why the output at From was unusable
+}
+
+// generateEscalating generates a function's tests, retrying at the next
+// tier up while its output is unusable and the options allow escalations
+func (g *Generator) generateEscalating(ctx context.Context, fn *parser.Function, file *parser.ParsedFile, opts GenerateOptions) (*GeneratedTest, error) {
+	return escalate(ctx, fn, opts, func(opts GenerateOptions) (*GeneratedTest, error) {
+		if opts.UseIRSpec {
+			return g.GenerateWithIRSpec(ctx, fn, file, opts)
+		}
+		return g.generateTestForFunction(ctx, fn, file, opts)
+	})
+}
+
+// escalate calls generate, then again one tier up for each OutputError, up
+// to opts.MaxEscalations times and no higher than Tier3
+func escalate(ctx context.Context, fn *parser.Function, opts GenerateOptions, generate func(GenerateOptions) (*GeneratedTest, error)) (*GeneratedTest, error) {
+	for retries := 0; ; retries++ {
+		test, err := generate(opts)
+		var outErr *OutputError
+		if err == nil || !errors.As(err, &outErr) {
+			return test, err
+		}
+		if retries >= opts.MaxEscalations || outErr.Tier >= llm.Tier3 || ctx.Err() != nil {
+			return nil, err
+		}
+
+		esc := Escalation{From: outErr.Tier, To: outErr.Tier + 1, Reason: outErr.Reason}
+		log.Warn().
+			Str("function", fn.Name).
+			Int("from_tier", int(esc.From)).
+			Int("to_tier", int(esc.To)).
+			Str("reason", esc.Reason).
+			Int("retry", retries+1).
+			Int("max_retries", opts.MaxEscalations).
+			Msg("escalating generation to a higher tier")
+		if opts.OnEscalation != nil {
+			opts.OnEscalation(fn, esc)
+		}
+
+		// The escalated tier is used as is: selecting by size could send a
+		// small function straight back to tier 1
+		opts.Tier = esc.To
+		opts.SelectTier = false
+	}
+}
@@ -0,0 +1,84 @@
+package generator
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/QTest-hq/qtest/internal/llm"
+	"github.com/QTest-hq/qtest/internal/parser"
+)
+
+func TestEscalate(t *testing.T) {
+	fn := &parser.Function{Name: "Parse"}
+
+	// outputs fails with an OutputError at each tier until the one it
+	// succeeds at, recording the options of every attempt
+	outputs := func(succeedAt llm.Tier, calls *[]GenerateOptions) func(GenerateOptions) (*GeneratedTest, error) {
+		return func(opts GenerateOptions) (*GeneratedTest, error) {
+			*calls = append(*calls, opts)
+			if opts.Tier >= succeedAt {
+				return &GeneratedTest{Function: fn, Tier: opts.Tier}, nil
+			}
+			if opts.Tier == llm.Tier1 {
+				return nil, malformedOutput(opts.Tier, errors.New("bad json"))
+			}
+			return nil, emptyOutput(opts.Tier)
+		}
+	}
+
+	var calls []GenerateOptions
+	var escalations []Escalation
+	opts := GenerateOptions{
+		Tier:           llm.Tier1,
+		SelectTier:     true,
+		MaxEscalations: 2,
+		OnEscalation: func(_ *parser.Function, esc Escalation) {
+			escalations = append(escalations, esc)
+		},
+	}
+	test, err := escalate(context.Background(), fn, opts, outputs(llm.Tier3, &calls))
+	if err != nil {
+		t.Fatalf("escalate() error = %v", err)
+	}
+	if test.Tier != llm.Tier3 || len(calls) != 3 {
+		t.Errorf("tier = %d after %d calls, want tier 3 after 3", test.Tier, len(calls))
+	}
+	if calls[1].SelectTier {
+		t.Error("escalated attempts shouldn't select the tier by size")
+	}
+	want := []Escalation{
+		{From: llm.Tier1, To: llm.Tier2, Reason: "malformed output"},
+		{From: llm.Tier2, To: llm.Tier3, Reason: "empty output"},
+	}
+	if len(escalations) != len(want) || escalations[0] != want[0] || escalations[1] != want[1] {
+		t.Errorf("escalations = %+v, want %+v", escalations, want)
+	}
+
+	// Retries run out
+	calls = nil
+	opts.MaxEscalations = 1
+	_, err = escalate(context.Background(), fn, opts, outputs(llm.Tier3, &calls))
+	if !errors.Is(err, ErrEmptyOutput) || len(calls) != 2 {
+		t.Errorf("err = %v after %d calls, want empty output after 2", err, len(calls))
+	}
+
+	// Nothing above tier 3, and no retries by default
+	for _, opts := range []GenerateOptions{{Tier: llm.Tier3, MaxEscalations: 2}, {Tier: llm.Tier1}} {
+		calls = nil
+		if _, err := escalate(context.Background(), fn, opts, outputs(llm.Tier(4), &calls)); err == nil || len(calls) != 1 {
+			t.Errorf("tier %d, max %d: err = %v after %d calls, want a failure after 1", opts.Tier, opts.MaxEscalations, err, len(calls))
+		}
+	}
+
+	// Failed calls aren't escalated: the router already fails over
+	calls = nil
+	opts.MaxEscalations = 2
+	_, err = escalate(context.Background(), fn, opts, func(opts GenerateOptions) (*GeneratedTest, error) {
+		calls = append(calls, opts)
+		return nil, errors.New("LLM completion failed")
+	})
+	if err == nil || len(calls) != 1 {
+		t.Errorf("err = %v after %d calls, want a failure after 1", err, len(calls))
+	}
+}
@@ -2,6 +2,7 @@ package generator
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 
@@ -10,6 +11,7 @@ import (
 	"github.com/QTest-hq/qtest/pkg/dsl"
 	"github.com/QTest-hq/qtest/pkg/model"
 	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
 )
 
 // Generator generates tests from parsed code
@@ -32,8 +34,24 @@ type GenerateOptions struct {
 	TestType   dsl.TestType
 	Framework  string
 	MaxTests   int
-	TargetFile string // Optional: specific file to target
-	UseIRSpec  bool   // Use IRSpec JSON mode for structured output
+	TargetFile string   // Optional: specific file to target
+	Functions  []string // Optional: only generate for these functions, unexported ones included
+	UseIRSpec  bool     // Use IRSpec JSON mode for structured output
+
+	// SelectTier picks the tier per function by the context it needs,
+	// starting from Tier: larger functions get a tier with the context for
+	// them, trivial ones the fast tier
+	SelectTier bool
+
+	// OnFailure, if set, is called for each function generation failed for
+	OnFailure func(fn *parser.Function, err error)
+
+	// MaxEscalations is how many times a function whose output was
+	// malformed or empty is retried at the next tier up; 0 doesn't retry
+	MaxEscalations int
+
+	// OnEscalation, if set, is called for each retry at a higher tier
+	OnEscalation func(fn *parser.Function, esc Escalation)
 }
 
 // GeneratedTest represents a generated test with metadata
@@ -43,6 +61,12 @@ type GeneratedTest struct {
 	RawYAML   string
 	Function  *parser.Function
 	FileName  string
+
+	// Provenance of the LLM output, written into the test file header
+	Model      string
+	PromptHash string
+	Tier       llm.Tier      // tier the test was generated at
+	Attempts   []llm.Attempt // providers failed over through, if any
 }
 
 // GenerateForFile generates tests for all functions in a file
@@ -59,6 +83,11 @@ func (g *Generator) GenerateForFile(ctx context.Context, filePath string, opts G
 		Str("language", string(parsed.Language)).
 		Msg("parsed file")
 
+	only := make(map[string]bool, len(opts.Functions))
+	for _, name := range opts.Functions {
+		only[name] = true
+	}
+
 	// Generate tests for each function
 	tests := make([]GeneratedTest, 0)
 	for i, fn := range parsed.Functions {
@@ -66,8 +95,12 @@ func (g *Generator) GenerateForFile(ctx context.Context, filePath string, opts G
 			break
 		}
 
-		// Skip private functions for unit tests
-		if !fn.Exported && opts.TestType == dsl.TestTypeUnit {
+		if len(only) > 0 && !only[fn.Name] {
+			continue
+		}
+
+		// Skip private functions for unit tests, unless asked for by name
+		if !fn.Exported && opts.TestType == dsl.TestTypeUnit && !only[fn.Name] {
 			log.Debug().Str("function", fn.Name).Msg("skipping private function")
 			continue
 		}
@@ -79,15 +112,12 @@ func (g *Generator) GenerateForFile(ctx context.Context, filePath string, opts G
 			Bool("irspec", opts.UseIRSpec).
 			Msg("generating test")
 
-		var test *GeneratedTest
-		var err error
-		if opts.UseIRSpec {
-			test, err = g.GenerateWithIRSpec(ctx, &fn, parsed, opts)
-		} else {
-			test, err = g.generateTestForFunction(ctx, &fn, parsed, opts)
-		}
+		test, err := g.generateEscalating(ctx, &fn, parsed, opts)
 		if err != nil {
 			log.Warn().Err(err).Str("function", fn.Name).Msg("failed to generate test")
+			if opts.OnFailure != nil {
+				opts.OnFailure(&fn, err)
+			}
 			continue
 		}
 
@@ -122,7 +152,7 @@ func (g *Generator) generateTestForFunction(ctx context.Context, fn *parser.Func
 	)
 
 	// Call LLM
-	resp, err := g.llmRouter.Complete(ctx, &llm.Request{
+	req := &llm.Request{
 		Tier:   opts.Tier,
 		System: llm.SystemPromptTestGeneration,
 		Messages: []llm.Message{
@@ -130,7 +160,9 @@ func (g *Generator) generateTestForFunction(ctx context.Context, fn *parser.Func
 		},
 		Temperature: 0.3, // Lower temperature for more deterministic output
 		MaxTokens:   2000,
-	})
+	}
+	g.selectTier(req, fn, opts)
+	resp, err := g.llmRouter.Complete(ctx, req)
 	if err != nil {
 		return nil, fmt.Errorf("LLM completion failed: %w", err)
 	}
@@ -144,8 +176,16 @@ func (g *Generator) generateTestForFunction(ctx context.Context, fn *parser.Func
 		Str("raw_yaml", yamlContent).
 		Msg("LLM YAML response")
 
-	// Convert LLM output to DSL (handles multiple formats)
+	// Convert LLM output to DSL (handles multiple formats), salvaging the
+	// tests that parse if the output as a whole doesn't
+	var salvaged []SimpleTest
 	testDSL, err := ConvertToDSL(yamlContent, fn.Name, file.Path, string(file.Language))
+	if err != nil {
+		salvaged = g.salvageSimpleTests(ctx, req, resp, yamlContent, fn)
+	}
+	if len(salvaged) > 0 {
+		testDSL, err = convertSimpleListToDSL(salvaged, fn.Name, file.Path, string(file.Language))
+	}
 	if err != nil {
 		// Log full YAML content at debug level for troubleshooting
 		log.Debug().
@@ -158,7 +198,10 @@ func (g *Generator) generateTestForFunction(ctx context.Context, fn *parser.Func
 		if len(contentPreview) > 500 {
 			contentPreview = contentPreview[:500] + "... (truncated, see debug logs for full content)"
 		}
-		return nil, fmt.Errorf("failed to parse LLM response as test DSL: %w\n\nLLM Output:\n%s", err, contentPreview)
+		return nil, malformedOutput(req.Tier, fmt.Errorf("failed to parse LLM response as test DSL: %w\n\nLLM Output:\n%s", err, contentPreview))
+	}
+	if len(testDSL.Steps) == 0 {
+		return nil, emptyOutput(req.Tier)
 	}
 
 	// DEBUG: Log parsed DSL
@@ -191,15 +234,22 @@ func (g *Generator) generateTestForFunction(ctx context.Context, fn *parser.Func
 	testDSL.Target = dsl.TestTarget{
 		File:     file.Path,
 		Function: fn.Name,
+		Async:    fn.Async,
 	}
 
 	// Also convert to TestSpec with proper Assertions
 	var testSpecs []model.TestSpec
-	specs, specErr := ConvertToTestSpec(yamlContent, fn.Name, file.Path, string(file.Language))
+	var specs []model.TestSpec
+	var specErr error
+	if len(salvaged) > 0 {
+		specs, specErr = convertSimpleTestsToSpecs(salvaged, fn.Name, file.Path, string(file.Language))
+	} else {
+		specs, specErr = ConvertToTestSpec(yamlContent, fn.Name, file.Path, string(file.Language))
+	}
 	if specErr != nil {
 		log.Debug().Err(specErr).Str("function", fn.Name).Msg("failed to convert to TestSpec, using DSL only")
 	} else {
-		testSpecs = specs
+		testSpecs = markAsync(specs, fn)
 		log.Debug().
 			Str("function", fn.Name).
 			Int("specs", len(testSpecs)).
@@ -214,14 +264,35 @@ func (g *Generator) generateTestForFunction(ctx context.Context, fn *parser.Func
 	}
 
 	return &GeneratedTest{
-		DSL:       testDSL,
-		TestSpecs: testSpecs,
-		RawYAML:   yamlContent,
-		Function:  fn,
-		FileName:  file.Path,
+		DSL:        testDSL,
+		TestSpecs:  testSpecs,
+		RawYAML:    yamlContent,
+		Function:   fn,
+		FileName:   file.Path,
+		Model:      resp.Model,
+		PromptHash: llm.PromptHash(req),
+		Attempts:   resp.Attempts,
+		Tier:       req.Tier,
 	}, nil
 }
 
+// selectTier sets the request's tier for the function's size, when the
+// options ask for it
+func (g *Generator) selectTier(req *llm.Request, fn *parser.Function, opts GenerateOptions) {
+	if !opts.SelectTier {
+		return
+	}
+	req.Tier = g.llmRouter.SelectTier(req)
+	if req.Tier != opts.Tier {
+		log.Debug().
+			Str("function", fn.Name).
+			Int("tokens", llm.RequestTokens(req)).
+			Int("run_tier", int(opts.Tier)).
+			Int("tier", int(req.Tier)).
+			Msg("selected tier for function size")
+	}
+}
+
 // buildContext builds context from related functions
 func (g *Generator) buildContext(file *parser.ParsedFile, targetFn *parser.Function) string {
 	// For now, just list other function names in the file
@@ -279,7 +350,7 @@ func min(a, b int) int {
 }
 
 // GenerateWithIRSpec generates tests using the new IRSpec JSON format
-// This uses Ollama's JSON mode for structured, parseable output
+// The output is constrained to the IRSpec schema, and near misses repaired
 func (g *Generator) GenerateWithIRSpec(ctx context.Context, fn *parser.Function, file *parser.ParsedFile, opts GenerateOptions) (*GeneratedTest, error) {
 	// Read the file content to get the function body
 	content, err := os.ReadFile(file.Path)
@@ -299,15 +370,18 @@ func (g *Generator) GenerateWithIRSpec(ctx context.Context, fn *parser.Function,
 		string(file.Language),
 	)
 
-	// Call LLM with JSON mode enabled
-	resp, err := g.llmRouter.Complete(ctx, &llm.Request{
+	// Call LLM with output constrained to the IRSpec schema
+	req := &llm.Request{
 		Tier:     opts.Tier,
 		System:   llm.SystemPromptIRSpec,
 		Messages: []llm.Message{{Role: "user", Content: prompt}},
 		JSONMode: true, // Force JSON output
+		Schema:   json.RawMessage(model.IRSpecJSONSchema),
 		Temperature: 0.2,
 		MaxTokens:   3000,
-	})
+	}
+	g.selectTier(req, fn, opts)
+	resp, err := g.llmRouter.Complete(ctx, req)
 	if err != nil {
 		return nil, fmt.Errorf("LLM completion failed: %w", err)
 	}
@@ -319,9 +393,22 @@ func (g *Generator) GenerateWithIRSpec(ctx context.Context, fn *parser.Function,
 
 	// Parse and convert IRSpec to TestSpecs
 	converter := NewIRSpecConverter()
-	testSpecs, err := converter.ParseAndConvert(resp.Content)
+	testSpecs, repairs, err := converter.ParseAndConvertRepaired(resp.Content, fn.Name)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse IRSpec: %w\n\nLLM Output:\n%s", err, resp.Content)
+		testSpecs = g.salvageIRSpec(ctx, req, resp, fn)
+		if len(testSpecs) == 0 {
+			return nil, malformedOutput(req.Tier, fmt.Errorf("failed to parse IRSpec: %w\n\nLLM Output:\n%s", err, resp.Content))
+		}
+	}
+	if len(testSpecs) == 0 {
+		return nil, emptyOutput(req.Tier)
+	}
+	testSpecs = markAsync(testSpecs, fn)
+	if len(repairs) > 0 {
+		log.Debug().
+			Str("function", fn.Name).
+			Strs("repairs", repairs).
+			Msg("repaired IRSpec JSON")
 	}
 
 	log.Info().
@@ -331,16 +418,105 @@ func (g *Generator) GenerateWithIRSpec(ctx context.Context, fn *parser.Function,
 
 	// Convert TestSpecs to DSL for backward compatibility
 	testDSL := convertTestSpecsToDSL(testSpecs, fn.Name, file.Path, opts.TestType)
+	testDSL.Target.Async = fn.Async
 
 	return &GeneratedTest{
-		DSL:       testDSL,
-		TestSpecs: testSpecs,
-		RawYAML:   resp.Content, // Store JSON in RawYAML field for now
-		Function:  fn,
-		FileName:  file.Path,
+		DSL:        testDSL,
+		TestSpecs:  testSpecs,
+		RawYAML:    resp.Content, // Store JSON in RawYAML field for now
+		Function:   fn,
+		FileName:   file.Path,
+		Model:      resp.Model,
+		PromptHash: llm.PromptHash(req),
+		Attempts:   resp.Attempts,
+		Tier:       req.Tier,
 	}, nil
 }
 
+// salvageIRSpec keeps the valid test cases of a partly malformed IRSpec
+// response, asking again for only the ones that were dropped
+func (g *Generator) salvageIRSpec(ctx context.Context, req *llm.Request, resp *llm.Response, fn *parser.Function) []model.TestSpec {
+	converter := NewIRSpecConverter()
+	specs, salvage, err := converter.SalvageAndConvert(resp.Content, fn.Name)
+	if err != nil {
+		return nil
+	}
+	logSalvage(fn, salvage)
+
+	if salvage.Missing() > 0 {
+		retry, err := g.retryMissing(ctx, req, resp, salvage, "IRSpec JSON")
+		if err != nil {
+			log.Warn().Err(err).Str("function", fn.Name).Msg("retry for dropped test cases failed")
+			return specs
+		}
+		more, _, err := converter.ParseAndConvertRepaired(retry.Content, fn.Name)
+		if err != nil {
+			more, _, err = converter.SalvageAndConvert(retry.Content, fn.Name)
+		}
+		if err != nil {
+			log.Warn().Err(err).Str("function", fn.Name).Msg("retry for dropped test cases was malformed")
+			return specs
+		}
+		specs = append(specs, more...)
+	}
+	return specs
+}
+
+// salvageSimpleTests keeps the tests that parse of a partly malformed YAML
+// response, asking again for only the ones that were dropped
+func (g *Generator) salvageSimpleTests(ctx context.Context, req *llm.Request, resp *llm.Response, yamlContent string, fn *parser.Function) []SimpleTest {
+	tests, salvage := SalvageSimpleTests(yamlContent)
+	if len(tests) == 0 {
+		return nil
+	}
+	logSalvage(fn, salvage)
+
+	if salvage.Missing() > 0 {
+		retry, err := g.retryMissing(ctx, req, resp, salvage, "YAML")
+		if err != nil {
+			log.Warn().Err(err).Str("function", fn.Name).Msg("retry for dropped test cases failed")
+			return tests
+		}
+		content := llm.ParseDSLOutput(retry.Content)
+		var more SimpleTestList
+		if err := yaml.Unmarshal([]byte(content), &more); err != nil {
+			more, _ = SalvageSimpleTests(content)
+		}
+		tests = append(tests, more...)
+	}
+	return tests
+}
+
+// retryMissing asks the LLM, following up on its response, for the test
+// cases that were dropped from it
+func (g *Generator) retryMissing(ctx context.Context, req *llm.Request, resp *llm.Response, salvage *Salvage, format string) (*llm.Response, error) {
+	retry := *req
+	retry.Messages = append(append([]llm.Message{}, req.Messages...),
+		llm.Message{Role: "assistant", Content: resp.Content},
+		llm.Message{Role: "user", Content: llm.MissingTestsPrompt(salvage.Missing(), salvage.Kept, format)},
+	)
+	return g.llmRouter.Complete(ctx, &retry)
+}
+
+func logSalvage(fn *parser.Function, salvage *Salvage) {
+	log.Warn().
+		Str("function", fn.Name).
+		Int("kept", len(salvage.Kept)).
+		Strs("broken", salvage.Broken).
+		Msg("salvaged test cases from malformed LLM output")
+}
+
+// markAsync marks the specs of an async function, so adapters await its
+// result instead of asserting on a pending promise, coroutine or channel
+func markAsync(specs []model.TestSpec, fn *parser.Function) []model.TestSpec {
+	if fn.Async {
+		for i := range specs {
+			specs[i].Async = true
+		}
+	}
+	return specs
+}
+
 // convertTestSpecsToDSL converts TestSpecs back to DSL for backward compatibility
 func convertTestSpecsToDSL(specs []model.TestSpec, functionName, filePath string, testType dsl.TestType) *dsl.TestDSL {
 	testDSL := &dsl.TestDSL{
@@ -6,6 +6,7 @@ import (
 	"github.com/QTest-hq/qtest/internal/llm"
 	"github.com/QTest-hq/qtest/internal/parser"
 	"github.com/QTest-hq/qtest/pkg/dsl"
+	"github.com/QTest-hq/qtest/pkg/model"
 )
 
 func TestNewGenerator(t *testing.T) {
@@ -189,6 +190,20 @@ func TestExtractLines_EmptySlice(t *testing.T) {
 	}
 }
 
+func TestMarkAsync(t *testing.T) {
+	specs := markAsync([]model.TestSpec{{Description: "a"}, {Description: "b"}}, &parser.Function{Name: "fetchUser", Async: true})
+	for _, spec := range specs {
+		if !spec.Async {
+			t.Errorf("spec %q of an async function should be async", spec.Description)
+		}
+	}
+
+	specs = markAsync([]model.TestSpec{{Description: "a"}}, &parser.Function{Name: "add"})
+	if specs[0].Async {
+		t.Error("spec of a sync function shouldn't be async")
+	}
+}
+
 func TestMin(t *testing.T) {
 	tests := []struct {
 		a, b, want int
@@ -135,12 +135,26 @@ func (c *IRSpecConverter) convertAssertion(ir model.IRAssertion) model.Assertion
 		"greater_than": "greater_than",
 		"less_than":    "less_than",
 		"throws":       "throws",
+		"raises":       "throws",
 		"truthy":       "truthy",
 		"falsy":        "falsy",
 		"nil":          "nil",
 		"not_nil":      "not_nil",
 		"length":       "length",
 		"type_is":      "type_is",
+		"snapshot":     "snapshot",
+		"approx":       model.AssertApprox,
+		"matches":      model.AssertMatches,
+		"contains_all": model.AssertContainsAll,
+		"subset":       model.AssertSubset,
+		"sorted":       model.AssertSorted,
+
+		model.AssertHeaderPresent: model.AssertHeaderPresent,
+		model.AssertHeaderEquals:  model.AssertHeaderEquals,
+		model.AssertHeaderMatches: model.AssertHeaderMatches,
+		model.AssertCookiePresent: model.AssertCookiePresent,
+		model.AssertCookieEquals:  model.AssertCookieEquals,
+		model.AssertCookieMatches: model.AssertCookieMatches,
 	}
 
 	kind := ir.Type
@@ -148,11 +162,34 @@ func (c *IRSpecConverter) convertAssertion(ir model.IRAssertion) model.Assertion
 		kind = mapped
 	}
 
-	return model.Assertion{
-		Kind:     kind,
-		Actual:   ir.Actual,
-		Expected: ir.Expected,
+	assertion := model.Assertion{
+		Kind:         kind,
+		Actual:       ir.Actual,
+		Expected:     ir.Expected,
+		Tolerance:    ir.Tolerance,
+		ErrorType:    ir.ErrorType,
+		ErrorMessage: ir.ErrorMessage,
+	}
+	if assertion.ExpectsError() && assertion.ErrorType == "" && assertion.ErrorMessage == "" {
+		assertion.ErrorType, assertion.ErrorMessage = expectedError(ir.Expected)
 	}
+	return assertion
+}
+
+// expectedError reads what a throws assertion expects from its expected
+// value, which models often set instead of error_type or error_message:
+// an error name like ValueError or ErrNotFound, or else message text
+func expectedError(expected interface{}) (errType, message string) {
+	s, ok := expected.(string)
+	if !ok || s == "" {
+		return "", ""
+	}
+	name := strings.TrimPrefix(s[strings.LastIndex(s, ".")+1:], "*")
+	isIdent := !strings.ContainsAny(s, " \t'\"")
+	if isIdent && (strings.HasSuffix(name, "Error") || strings.HasSuffix(name, "Exception") || strings.HasPrefix(name, "Err")) {
+		return s, ""
+	}
+	return "", s
 }
 
 // formatTestName converts snake_case to human-readable format
@@ -186,6 +223,26 @@ func (c *IRSpecConverter) ParseAndConvert(jsonData string) ([]model.TestSpec, er
 	return c.ConvertToTestSpecs(suite)
 }
 
+// ParseAndConvertRepaired is ParseAndConvert, falling back to repairing
+// near-miss JSON with RepairIRSpec. It returns the repairs made, if any; the
+// error is the unrepaired output's when repairing doesn't help.
+func (c *IRSpecConverter) ParseAndConvertRepaired(jsonData, functionName string) ([]model.TestSpec, []string, error) {
+	specs, err := c.ParseAndConvert(jsonData)
+	if err == nil {
+		return specs, nil, nil
+	}
+
+	repaired, repairs, repairErr := RepairIRSpec(jsonData, functionName)
+	if repairErr != nil {
+		return nil, nil, err
+	}
+	specs, repairErr = c.ParseAndConvert(repaired)
+	if repairErr != nil {
+		return nil, nil, err
+	}
+	return specs, repairs, nil
+}
+
 // Validate validates an already-parsed IRTestSuite
 func (c *IRSpecConverter) Validate(suite *model.IRTestSuite) *ValidationResult {
 	return c.validator.Validate(suite)
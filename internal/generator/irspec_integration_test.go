@@ -362,3 +362,258 @@ func TestIRSpecConverter_DirectValidate(t *testing.T) {
 		t.Errorf("expected valid suite, got errors: %v", result.ErrorMessages())
 	}
 }
+
+// TestIRSpecPipeline_ThrowsAssertions tests that expected errors reach the
+// generated tests
+func TestIRSpecPipeline_ThrowsAssertions(t *testing.T) {
+	json := `{
+		"function_name": "parse_age",
+		"tests": [
+			{
+				"name": "rejects_negative",
+				"given": [{"name": "value", "value": -1, "type": "int"}],
+				"when": {"call": "parse_age($value)", "args": ["value"]},
+				"then": [{"type": "throws", "actual": "result", "error_type": "ValueError", "error_message": "must be positive"}]
+			},
+			{
+				"name": "rejects_text",
+				"given": [{"name": "value", "value": "abc", "type": "string"}],
+				"when": {"call": "parse_age($value)", "args": ["value"]},
+				"then": [{"type": "raises", "actual": "result", "expected": "TypeError"}]
+			}
+		]
+	}`
+
+	specs, err := NewIRSpecConverter().ParseAndConvert(json)
+	if err != nil {
+		t.Fatalf("ParseAndConvert failed: %v", err)
+	}
+	if a := specs[0].Assertions[0]; a.ErrorType != "ValueError" || a.ErrorMessage != "must be positive" {
+		t.Errorf("assertion = %+v", a)
+	}
+	if a := specs[1].Assertions[0]; a.Kind != "throws" || a.ErrorType != "TypeError" || a.ErrorMessage != "" {
+		t.Errorf("assertion with the error as expected = %+v", a)
+	}
+
+	code, err := adapters.NewPytestSpecAdapter().GenerateFromSpecs(specs, "ages.py")
+	if err != nil {
+		t.Fatalf("pytest generation failed: %v", err)
+	}
+	for _, want := range []string{
+		"with pytest.raises(ValueError) as excinfo:",
+		`assert "must be positive" in str(excinfo.value)`,
+		"with pytest.raises(TypeError):",
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("pytest code missing %q:\n%s", want, code)
+		}
+	}
+}
+
+func TestExtractAssertions_Errors(t *testing.T) {
+	tests := []struct {
+		name      string
+		in        interface{}
+		wantType  string
+		wantMsg   string
+		wantCount int
+	}{
+		{"any error", map[string]interface{}{"error": true}, "", "", 1},
+		{"no error", map[string]interface{}{"error": false}, "", "", 0},
+		{"error name", map[string]interface{}{"raises": "ZeroDivisionError"}, "ZeroDivisionError", "", 1},
+		{"error message", map[string]interface{}{"error": "division by zero"}, "", "division by zero", 1},
+		{"type and message", map[string]interface{}{"throws": map[string]interface{}{"type": "RangeError", "message": "too big"}}, "RangeError", "too big", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := extractAssertions(tt.in, "divide")
+			if len(got) != tt.wantCount {
+				t.Fatalf("extractAssertions() = %+v, want %d assertions", got, tt.wantCount)
+			}
+			if tt.wantCount == 0 {
+				return
+			}
+			if !got[0].ExpectsError() || got[0].ErrorType != tt.wantType || got[0].ErrorMessage != tt.wantMsg {
+				t.Errorf("extractAssertions() = %+v", got[0])
+			}
+		})
+	}
+}
+
+func TestIRSpecPipeline_ApproxAndMatches(t *testing.T) {
+	json := `{
+		"function_name": "average",
+		"tests": [
+			{
+				"name": "averages_thirds",
+				"given": [{"name": "values", "value": [1, 1, 1.0000001], "type": "array"}],
+				"when": {"call": "average($values)", "args": ["values"]},
+				"then": [{"type": "approx", "actual": "result", "expected": 1.0, "tolerance": 0.001}]
+			},
+			{
+				"name": "stamps_the_time",
+				"given": [{"name": "values", "value": [], "type": "array"}],
+				"when": {"call": "average($values)", "args": ["values"]},
+				"then": [{"type": "matches", "actual": "result", "expected": "^\\d{4}-\\d{2}-\\d{2}"}]
+			}
+		]
+	}`
+
+	specs, err := NewIRSpecConverter().ParseAndConvert(json)
+	if err != nil {
+		t.Fatalf("ParseAndConvert failed: %v", err)
+	}
+	if a := specs[0].Assertions[0]; a.Kind != model.AssertApprox || a.Tolerance != 0.001 {
+		t.Errorf("approx assertion = %+v", a)
+	}
+	if a := specs[1].Assertions[0]; a.Kind != model.AssertMatches || a.Expected != `^\d{4}-\d{2}-\d{2}` {
+		t.Errorf("matches assertion = %+v", a)
+	}
+
+	code, err := adapters.NewPytestSpecAdapter().GenerateFromSpecs(specs, "stats.py")
+	if err != nil {
+		t.Fatalf("pytest generation failed: %v", err)
+	}
+	for _, want := range []string{
+		"assert result == pytest.approx(1.0, abs=0.001)",
+		`assert re.search("^\\d{4}-\\d{2}-\\d{2}", str(result))`,
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("pytest code missing %q:\n%s", want, code)
+		}
+	}
+}
+
+func TestExtractAssertions_ApproxAndMatches(t *testing.T) {
+	got := extractAssertions(map[string]interface{}{
+		"approx":  map[string]interface{}{"value": 3.14, "tolerance": 0.01},
+		"matches": "^[a-f0-9-]{36}$",
+	}, "describe")
+	if len(got) != 2 {
+		t.Fatalf("extractAssertions() = %+v, want 2 assertions", got)
+	}
+	if got[0].Kind != model.AssertApprox || got[0].Expected != 3.14 || got[0].Tolerance != 0.01 {
+		t.Errorf("approx assertion = %+v", got[0])
+	}
+	if got[1].Kind != model.AssertMatches || got[1].Expected != "^[a-f0-9-]{36}$" {
+		t.Errorf("matches assertion = %+v", got[1])
+	}
+
+	if got := extractAssertions(map[string]interface{}{"approx": 2.5}, "half"); len(got) != 1 || got[0].Expected != 2.5 || got[0].Epsilon() != model.DefaultTolerance {
+		t.Errorf("bare approx = %+v", got)
+	}
+}
+
+func TestIRSpecPipeline_CollectionAssertions(t *testing.T) {
+	json := `{
+		"function_name": "list_users",
+		"tests": [
+			{
+				"name": "lists_admins_first",
+				"given": [{"name": "limit", "value": 3, "type": "int"}],
+				"when": {"call": "list_users($limit)", "args": ["limit"]},
+				"then": [
+					{"type": "length", "actual": "result", "expected": 3},
+					{"type": "contains_all", "actual": "result", "expected": ["ann", "bob"]},
+					{"type": "subset", "actual": "result", "expected": ["ann", "bob", "cy", "di"]},
+					{"type": "sorted", "actual": "result"}
+				]
+			}
+		]
+	}`
+
+	specs, err := NewIRSpecConverter().ParseAndConvert(json)
+	if err != nil {
+		t.Fatalf("ParseAndConvert failed: %v", err)
+	}
+	var kinds []string
+	for _, a := range specs[0].Assertions {
+		kinds = append(kinds, a.Kind)
+	}
+	if got := strings.Join(kinds, ","); got != "length,contains_all,subset,sorted" {
+		t.Errorf("kinds = %s", got)
+	}
+
+	code, err := adapters.NewJestSpecAdapter().GenerateFromSpecs(specs, "users.js")
+	if err != nil {
+		t.Fatalf("jest generation failed: %v", err)
+	}
+	for _, want := range []string{
+		"expect(result).toHaveLength(3);",
+		"expect(result).toEqual(expect.arrayContaining(['ann', 'bob']));",
+		"expect(['ann', 'bob', 'cy', 'di']).toEqual(expect.arrayContaining(result));",
+		"expect(result).toEqual([...result].sort((a, b) => (a > b) - (a < b)));",
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("jest code missing %q:\n%s", want, code)
+		}
+	}
+}
+
+func TestExtractAssertions_Collections(t *testing.T) {
+	got := extractAssertions(map[string]interface{}{
+		"contains_all": []interface{}{"a"},
+		"sorted":       "-score",
+	}, "rank")
+	if len(got) != 2 || got[0].Kind != model.AssertContainsAll || got[1].Kind != model.AssertSorted {
+		t.Fatalf("extractAssertions() = %+v", got)
+	}
+	if key, desc := got[1].SortKey(); key != "score" || !desc {
+		t.Errorf("SortKey() = %q, %v, want score, true", key, desc)
+	}
+}
+
+func TestIRSpecPipeline_HeaderAndCookieAssertions(t *testing.T) {
+	raw := `{
+		"function_name": "login",
+		"tests": [
+			{
+				"name": "sets_session",
+				"given": [{"name": "user", "value": "ann", "type": "string"}],
+				"when": {"call": "login($user)", "args": ["user"]},
+				"then": [
+					{"type": "header_equals", "actual": "Content-Type", "expected": "application/json"},
+					{"type": "has_header", "actual": "Location"},
+					{"type": "cookie_matches", "actual": "session", "expected": "^[a-f0-9]+$"}
+				]
+			}
+		]
+	}`
+
+	repaired, _, err := RepairIRSpec(raw, "login")
+	if err != nil {
+		t.Fatalf("RepairIRSpec failed: %v", err)
+	}
+	specs, err := NewIRSpecConverter().ParseAndConvert(repaired)
+	if err != nil {
+		t.Fatalf("ParseAndConvert failed: %v", err)
+	}
+	var kinds []string
+	for _, a := range specs[0].Assertions {
+		kinds = append(kinds, a.Kind)
+	}
+	if got := strings.Join(kinds, ","); got != "header_equals,header_present,cookie_matches" {
+		t.Errorf("kinds = %s", got)
+	}
+
+	missing := `{"function_name": "login", "tests": [{"name": "t", "given": [], "when": {"call": "login()"},
+		"then": [{"type": "cookie_equals", "actual": "session"}]}]}`
+	if _, err := NewIRSpecConverter().ParseAndConvert(missing); err == nil {
+		t.Error("cookie_equals without expected should fail validation")
+	}
+}
+
+func TestExtractAssertions_HeadersAndCookies(t *testing.T) {
+	got := extractAssertions(map[string]interface{}{
+		"headers": map[string]interface{}{"X-Request-Id": "abc", "Content-Type": "text/plain"},
+		"cookies": map[string]interface{}{"theme": "dark"},
+	}, "login")
+	var summary []string
+	for _, a := range got {
+		summary = append(summary, a.Kind+" "+a.Actual+"="+a.ExpectedString())
+	}
+	want := "header_equals Content-Type=text/plain,header_equals X-Request-Id=abc,cookie_equals theme=dark"
+	if strings.Join(summary, ",") != want {
+		t.Errorf("extractAssertions() = %s, want %s", strings.Join(summary, ","), want)
+	}
+}
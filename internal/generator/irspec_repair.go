@@ -0,0 +1,336 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// typeAliases maps type hints LLMs commonly use to IRSpec types
+var typeAliases = map[string]string{
+	"integer":  "int",
+	"number":   "float",
+	"double":   "float",
+	"boolean":  "bool",
+	"str":      "string",
+	"none":     "null",
+	"nil":      "null",
+	"list":     "array",
+	"slice":    "array",
+	"dict":     "object",
+	"map":      "object",
+	"struct":   "object",
+	"func":     "function",
+	"callback": "function",
+}
+
+// assertionAliases maps assertion types LLMs commonly use to IRSpec ones
+var assertionAliases = map[string]string{
+	"equal":            "equals",
+	"eq":               "equals",
+	"equality":         "equals",
+	"not_equal":        "not_equals",
+	"ne":               "not_equals",
+	"gt":               "greater_than",
+	"lt":               "less_than",
+	"raises":           "throws",
+	"error":            "throws",
+	"is_nil":           "nil",
+	"is_null":          "nil",
+	"null":             "nil",
+	"not_null":         "not_nil",
+	"true":             "truthy",
+	"false":            "falsy",
+	"has_length":       "length",
+	"is_type":          "type_is",
+	"instance_of":      "type_is",
+	"not_contain":      "not_contains",
+	"contain":          "contains",
+	"greater":          "greater_than",
+	"less":             "less_than",
+	"golden":           "snapshot",
+	"matches_snapshot": "snapshot",
+	"approximately":    "approx",
+	"approx_equal":     "approx",
+	"approx_equals":    "approx",
+	"close_to":         "approx",
+	"almost_equal":     "approx",
+	"regex":            "matches",
+	"match":            "matches",
+	"matches_regex":    "matches",
+	"len":              "length",
+	"size":             "length",
+	"length_equals":    "length",
+	"contains_all_of":  "contains_all",
+	"includes_all":     "contains_all",
+	"has_all":          "contains_all",
+	"subset_of":        "subset",
+	"is_subset":        "subset",
+	"is_sorted":        "sorted",
+	"sorted_by":        "sorted",
+	"ordered":          "sorted",
+	"has_header":       "header_present",
+	"header_exists":    "header_present",
+	"header":           "header_equals",
+	"header_equal":     "header_equals",
+	"header_eq":        "header_equals",
+	"header_match":     "header_matches",
+	"header_regex":     "header_matches",
+	"has_cookie":       "cookie_present",
+	"cookie_exists":    "cookie_present",
+	"sets_cookie":      "cookie_present",
+	"set_cookie":       "cookie_present",
+	"cookie":           "cookie_equals",
+	"cookie_equal":     "cookie_equals",
+	"cookie_eq":        "cookie_equals",
+	"cookie_match":     "cookie_matches",
+	"cookie_regex":     "cookie_matches",
+}
+
+// callVarPattern matches $name and ${name} references in a when.call
+var callVarPattern = regexp.MustCompile(`\$\{?([A-Za-z_][A-Za-z0-9_]*)\}?`)
+
+// RepairIRSpec fixes near-miss IRSpec JSON from an LLM: prose or fences
+// around it, trailing commas, truncated output, a bare tests array, a
+// missing function name, aliased types and assertions, and missing
+// when.args. It returns the repaired JSON and the repairs made, or an error
+// if the output can't be made into a JSON object.
+func RepairIRSpec(raw, functionName string) (string, []string, error) {
+	var repairs []string
+
+	text, fixes, err := repairSyntax(raw)
+	if err != nil {
+		return "", nil, err
+	}
+	repairs = append(repairs, fixes...)
+
+	var doc interface{}
+	if err := json.Unmarshal([]byte(text), &doc); err != nil {
+		return "", nil, fmt.Errorf("unrepairable IRSpec JSON: %w", err)
+	}
+
+	suite, ok := doc.(map[string]interface{})
+	if !ok {
+		tests, isArray := doc.([]interface{})
+		if !isArray {
+			return "", nil, fmt.Errorf("IRSpec is not a JSON object")
+		}
+		suite = map[string]interface{}{"tests": tests}
+		repairs = append(repairs, "wrapped tests array in a suite")
+	}
+	repairs = append(repairs, repairSuite(suite, functionName)...)
+
+	out, err := json.Marshal(suite)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to marshal repaired IRSpec: %w", err)
+	}
+	return string(out), repairs, nil
+}
+
+// repairSyntax extracts the JSON value from raw and makes it parseable
+func repairSyntax(raw string) (string, []string, error) {
+	var repairs []string
+
+	start := strings.IndexAny(raw, "{[")
+	if start < 0 {
+		return "", nil, fmt.Errorf("no JSON found in IRSpec output")
+	}
+	if strings.TrimSpace(raw[:start]) != "" {
+		repairs = append(repairs, "removed text before the JSON")
+	}
+
+	var (
+		out      strings.Builder
+		stack    []byte // closers for the open objects and arrays
+		inString bool
+		escaped  bool
+		// the output and open brackets after the last complete value, to
+		// cut back to if the output is truncated
+		lastLen   int
+		lastStack []byte
+		end       = -1
+	)
+	for i := start; i < len(raw) && end < 0; i++ {
+		ch := raw[i]
+		if inString {
+			out.WriteByte(ch)
+			switch {
+			case escaped:
+				escaped = false
+			case ch == '\\':
+				escaped = true
+			case ch == '"':
+				inString = false
+			}
+			continue
+		}
+
+		switch ch {
+		case '"':
+			inString = true
+		case '{':
+			stack = append(stack, '}')
+		case '[':
+			stack = append(stack, ']')
+		case '}', ']':
+			if len(stack) == 0 || stack[len(stack)-1] != ch {
+				return "", nil, fmt.Errorf("mismatched %q in IRSpec JSON", ch)
+			}
+			if trimTrailingComma(&out) {
+				repairs = appendOnce(repairs, "removed trailing commas")
+			}
+			stack = stack[:len(stack)-1]
+			if len(stack) == 0 {
+				end = i
+			}
+		}
+		out.WriteByte(ch)
+		if ch == '}' || ch == ']' {
+			lastLen = out.Len()
+			lastStack = append(lastStack[:0], stack...)
+		}
+	}
+
+	if end >= 0 {
+		if strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(raw[end+1:]), "```")) != "" {
+			repairs = append(repairs, "removed text after the JSON")
+		}
+		return out.String(), repairs, nil
+	}
+
+	// Truncated: drop the incomplete value and close what was left open
+	if lastLen == 0 {
+		return "", nil, fmt.Errorf("IRSpec JSON is truncated before any complete value")
+	}
+	text := out.String()[:lastLen]
+	text = strings.TrimRight(text, " \t\r\n,")
+	for i := len(lastStack) - 1; i >= 0; i-- {
+		text += string(lastStack[i])
+	}
+	repairs = append(repairs, "closed truncated JSON")
+	return text, repairs, nil
+}
+
+// trimTrailingComma removes a comma, and the whitespace after it, at the
+// end of out, reporting whether there was one
+func trimTrailingComma(out *strings.Builder) bool {
+	s := out.String()
+	trimmed := strings.TrimRight(s, " \t\r\n")
+	if !strings.HasSuffix(trimmed, ",") {
+		return false
+	}
+	out.Reset()
+	out.WriteString(strings.TrimSuffix(trimmed, ","))
+	return true
+}
+
+// repairSuite fixes the fields of a parsed IRSpec suite in place
+func repairSuite(suite map[string]interface{}, functionName string) []string {
+	var repairs []string
+
+	if _, ok := suite["tests"]; !ok {
+		for _, alias := range []string{"test_cases", "testCases", "cases"} {
+			if tests, ok := suite[alias]; ok {
+				suite["tests"] = tests
+				delete(suite, alias)
+				repairs = append(repairs, "renamed "+alias+" to tests")
+				break
+			}
+		}
+	}
+	if name, _ := suite["function_name"].(string); name == "" && functionName != "" {
+		suite["function_name"] = functionName
+		repairs = append(repairs, "set missing function_name")
+	}
+
+	tests, _ := suite["tests"].([]interface{})
+	complete := make([]interface{}, 0, len(tests))
+	for _, t := range tests {
+		tc, ok := t.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for _, r := range repairTestCase(tc) {
+			repairs = appendOnce(repairs, r)
+		}
+		if tc["when"] != nil && tc["then"] != nil {
+			complete = append(complete, tc)
+		}
+	}
+
+	// A truncated last test case is dropped rather than failing the suite
+	if len(complete) > 0 && len(complete) < len(tests) {
+		suite["tests"] = complete
+		repairs = append(repairs, fmt.Sprintf("dropped %d incomplete test cases", len(tests)-len(complete)))
+	}
+	return repairs
+}
+
+func repairTestCase(tc map[string]interface{}) []string {
+	var repairs []string
+
+	given, _ := tc["given"].([]interface{})
+	defined := make(map[string]bool, len(given))
+	for _, g := range given {
+		v, ok := g.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if name, ok := v["name"].(string); ok {
+			defined[name] = true
+		}
+		if typ, ok := v["type"].(string); ok {
+			if canonical, ok := typeAliases[strings.ToLower(typ)]; ok {
+				v["type"] = canonical
+				repairs = appendOnce(repairs, "normalized type hints")
+			}
+		}
+	}
+
+	if when, ok := tc["when"].(map[string]interface{}); ok {
+		args, _ := when["args"].([]interface{})
+		call, _ := when["call"].(string)
+		if len(args) == 0 && call != "" {
+			filled := make([]interface{}, 0)
+			for _, m := range callVarPattern.FindAllStringSubmatch(call, -1) {
+				if defined[m[1]] {
+					filled = append(filled, m[1])
+				}
+			}
+			if len(filled) > 0 {
+				when["args"] = filled
+				repairs = append(repairs, "filled when.args from the call")
+			}
+		}
+	}
+
+	then, _ := tc["then"].([]interface{})
+	if obj, ok := tc["then"].(map[string]interface{}); ok {
+		then = []interface{}{obj}
+		tc["then"] = then
+		repairs = append(repairs, "wrapped single assertion in a list")
+	}
+	for _, a := range then {
+		assertion, ok := a.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if typ, ok := assertion["type"].(string); ok {
+			if canonical, ok := assertionAliases[strings.ToLower(typ)]; ok {
+				assertion["type"] = canonical
+				repairs = appendOnce(repairs, "normalized assertion types")
+			}
+		}
+	}
+	return repairs
+}
+
+func appendOnce(list []string, s string) []string {
+	for _, existing := range list {
+		if existing == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
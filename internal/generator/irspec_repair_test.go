@@ -0,0 +1,121 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRepairIRSpec_Syntax(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+	}{
+		{
+			name: "prose and fences",
+			raw:  "Here are the tests:\n```json\n" + `{"function_name": "Add", "tests": [{"name": "t", "given": [], "when": {"call": "Add()"}, "then": [{"type": "truthy", "actual": "result"}]}]}` + "\n```\nLet me know!",
+		},
+		{
+			name: "trailing commas",
+			raw:  `{"function_name": "Add", "tests": [{"name": "t", "given": [], "when": {"call": "Add()",}, "then": [{"type": "truthy", "actual": "result"},],},]}`,
+		},
+		{
+			name: "truncated",
+			raw:  `{"function_name": "Add", "tests": [{"name": "t", "given": [], "when": {"call": "Add()"}, "then": [{"type": "truthy", "actual": "result"}]}, {"name": "cut", "given": [{"name": "a", "val`,
+		},
+	}
+
+	converter := NewIRSpecConverter()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := converter.ParseAndConvert(tt.raw); err == nil {
+				t.Fatal("expected the unrepaired output to fail")
+			}
+
+			repaired, repairs, err := RepairIRSpec(tt.raw, "Add")
+			if err != nil {
+				t.Fatalf("RepairIRSpec() error: %v", err)
+			}
+			if len(repairs) == 0 {
+				t.Error("expected repairs to be reported")
+			}
+			specs, err := converter.ParseAndConvert(repaired)
+			if err != nil {
+				t.Fatalf("repaired output failed: %v\n%s", err, repaired)
+			}
+			if len(specs) != 1 {
+				t.Errorf("len(specs) = %d, want 1", len(specs))
+			}
+		})
+	}
+}
+
+func TestRepairIRSpec_Fields(t *testing.T) {
+	raw := `[{"name": "add", "given": [{"name": "a", "value": 1, "type": "integer"}, {"name": "b", "value": 2, "type": "number"}],
+		"when": {"call": "Add($a, ${b})"},
+		"then": {"type": "equal", "actual": "result", "expected": 3}}]`
+
+	repaired, repairs, err := RepairIRSpec(raw, "Add")
+	if err != nil {
+		t.Fatalf("RepairIRSpec() error: %v", err)
+	}
+
+	suite, _, err := NewIRSpecConverter().ParseAndValidate(repaired)
+	if err != nil {
+		t.Fatalf("repaired output invalid: %v\n%s", err, repaired)
+	}
+	if suite.FunctionName != "Add" {
+		t.Errorf("FunctionName = %q, want Add", suite.FunctionName)
+	}
+	tc := suite.Tests[0]
+	if tc.Given[0].Type != "int" || tc.Given[1].Type != "float" {
+		t.Errorf("types = %q, %q, want int, float", tc.Given[0].Type, tc.Given[1].Type)
+	}
+	if strings.Join(tc.When.Args, ",") != "a,b" {
+		t.Errorf("args = %v, want [a b]", tc.When.Args)
+	}
+	if len(tc.Then) != 1 || tc.Then[0].Type != "equals" {
+		t.Errorf("then = %+v, want one equals assertion", tc.Then)
+	}
+	if len(repairs) != 6 {
+		t.Errorf("repairs = %v, want 6", repairs)
+	}
+}
+
+func TestRepairIRSpec_Unrepairable(t *testing.T) {
+	for _, raw := range []string{"", "no json here", `{"function_name": "F", "tests": [}`, `{"tests": [`} {
+		if _, _, err := RepairIRSpec(raw, "F"); err == nil {
+			t.Errorf("RepairIRSpec(%q) expected error", raw)
+		}
+	}
+}
+
+func TestParseAndConvertRepaired(t *testing.T) {
+	converter := NewIRSpecConverter()
+
+	valid := `{"function_name": "F", "tests": [{"name": "t", "given": [], "when": {"call": "F()"}, "then": [{"type": "truthy", "actual": "result"}]}]}`
+	specs, repairs, err := converter.ParseAndConvertRepaired(valid, "F")
+	if err != nil || len(specs) != 1 || repairs != nil {
+		t.Errorf("valid output: specs=%d repairs=%v err=%v", len(specs), repairs, err)
+	}
+
+	// Repairs don't invent assertion types the validator rejects
+	invalid := `{"function_name": "F", "tests": [{"name": "t", "given": [], "when": {"call": "F()"}, "then": [{"type": "invalid_type", "actual": "result"}]}]}`
+	if _, _, err := converter.ParseAndConvertRepaired(invalid, "F"); err == nil {
+		t.Error("expected error for an invalid assertion type")
+	}
+}
+
+func TestParseAndConvertRepaired_ApproxAliases(t *testing.T) {
+	raw := `{"function_name": "Mean", "tests": [{"name": "t", "given": [], "when": {"call": "Mean()"}, "then": [
+		{"type": "close_to", "actual": "result", "expected": 0.5, "tolerance": 0.01},
+		{"type": "regex", "actual": "result", "expected": "^0\\."}]}]}`
+
+	specs, _, err := NewIRSpecConverter().ParseAndConvertRepaired(raw, "Mean")
+	if err != nil {
+		t.Fatalf("ParseAndConvertRepaired() error: %v", err)
+	}
+	got := specs[0].Assertions
+	if got[0].Kind != "approx" || got[0].Tolerance != 0.01 || got[1].Kind != "matches" {
+		t.Errorf("assertions = %+v, want approx and matches", got)
+	}
+}
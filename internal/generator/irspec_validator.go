@@ -57,12 +57,26 @@ func NewIRSpecValidator() *IRSpecValidator {
 			"greater_than": true,
 			"less_than":    true,
 			"throws":       true,
+			"raises":       true,
 			"truthy":       true,
 			"falsy":        true,
 			"nil":          true,
 			"not_nil":      true,
 			"length":       true,
 			"type_is":      true,
+			"snapshot":     true,
+			"approx":       true,
+			"matches":      true,
+			"contains_all": true,
+			"subset":       true,
+			"sorted":       true,
+			// API response headers and cookies
+			model.AssertHeaderPresent: true,
+			model.AssertHeaderEquals:  true,
+			model.AssertHeaderMatches: true,
+			model.AssertCookiePresent: true,
+			model.AssertCookieEquals:  true,
+			model.AssertCookieMatches: true,
 		},
 	}
 }
@@ -285,9 +299,12 @@ func (v *IRSpecValidator) validateThen(then []model.IRAssertion, prefix string,
 // requiresExpected returns true if the assertion type needs an expected value
 func (v *IRSpecValidator) requiresExpected(assertionType string) bool {
 	switch assertionType {
-	case "equals", "not_equals", "contains", "not_contains", "greater_than", "less_than", "length", "type_is":
+	case "equals", "not_equals", "contains", "not_contains", "greater_than", "less_than", "length", "type_is",
+		"approx", "matches", "contains_all", "subset",
+		model.AssertHeaderEquals, model.AssertHeaderMatches, model.AssertCookieEquals, model.AssertCookieMatches:
 		return true
-	case "throws", "truthy", "falsy", "nil", "not_nil":
+	case "throws", "raises", "truthy", "falsy", "nil", "not_nil", "snapshot", "sorted",
+		model.AssertHeaderPresent, model.AssertCookiePresent:
 		return false
 	default:
 		return false
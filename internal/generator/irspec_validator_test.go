@@ -154,6 +154,31 @@ func TestIRSpecValidator_InvalidAssertionType(t *testing.T) {
 	}
 }
 
+func TestIRSpecValidator_SnapshotAssertion(t *testing.T) {
+	validator := NewIRSpecValidator()
+
+	suite := &model.IRTestSuite{
+		FunctionName: "Render",
+		Tests: []model.IRTestCase{
+			{
+				Name: "render_home",
+				Given: []model.IRVariable{
+					{Name: "page", Value: "home", Type: "string"},
+				},
+				When: model.IRAction{Call: "Render($page)", Args: []string{"page"}},
+				Then: []model.IRAssertion{
+					{Type: "snapshot", Actual: "result"},
+				},
+			},
+		},
+	}
+
+	result := validator.Validate(suite)
+	if !result.Valid {
+		t.Errorf("expected a snapshot assertion without expected to be valid, got %v", result.Errors)
+	}
+}
+
 func TestIRSpecValidator_InvalidTypeHint(t *testing.T) {
 	validator := NewIRSpecValidator()
 
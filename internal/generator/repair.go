@@ -0,0 +1,165 @@
+package generator
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/QTest-hq/qtest/internal/adapters"
+	"github.com/QTest-hq/qtest/internal/llm"
+	"github.com/QTest-hq/qtest/internal/validator"
+	"github.com/rs/zerolog/log"
+)
+
+// maxRepairOutput caps the failure output sent in a repair prompt
+const maxRepairOutput = 3000
+
+// TestRunner compiles and runs a test file; validator.Validator is one
+type TestRunner interface {
+	RunTests(ctx context.Context, testFile string) (*validator.TestResult, error)
+}
+
+// RepairResult is how a test file's repair loop ended
+type RepairResult struct {
+	Passed     bool
+	Iterations int    // repair prompts sent
+	LastError  string // failure of the last run, when the file didn't pass
+}
+
+// Repairer runs emitted test files and, while they fail to compile or pass,
+// sends the errors back to the LLM for a corrected file
+type Repairer struct {
+	complete      func(ctx context.Context, req *llm.Request) (*llm.Response, error)
+	maxIterations int
+}
+
+// NewRepairer creates a repairer that sends up to maxIterations repair
+// prompts per test file
+func NewRepairer(router *llm.Router, maxIterations int) *Repairer {
+	return &Repairer{
+		complete:      router.Complete,
+		maxIterations: maxIterations,
+	}
+}
+
+// Repair runs the test file written for test, in language, and repairs it
+// until it passes or the iterations run out. A file that still fails is
+// restored to what was written, for validation to report. Repairs keep the
+// file's provenance header.
+func (r *Repairer) Repair(ctx context.Context, runner TestRunner, test *GeneratedTest, testFile, language string) (*RepairResult, error) {
+	original, err := os.ReadFile(testFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read test file: %w", err)
+	}
+	prov, _ := adapters.ParseProvenance(string(original))
+	code := string(original)
+	functionCode := sourceOf(test)
+
+	result := &RepairResult{}
+	for {
+		run, err := runner.RunTests(ctx, testFile)
+		if err != nil {
+			restore(testFile, original, result)
+			return nil, fmt.Errorf("failed to run test: %w", err)
+		}
+		if run.Passed {
+			result.Passed = true
+			result.LastError = ""
+			return result, nil
+		}
+		result.LastError = failureText(run)
+		if result.Iterations >= r.maxIterations || ctx.Err() != nil {
+			break
+		}
+
+		result.Iterations++
+		log.Info().
+			Str("file", testFile).
+			Int("iteration", result.Iterations).
+			Int("max_iterations", r.maxIterations).
+			Msg("repairing failing test")
+
+		fixed, err := r.repairOnce(ctx, test.Tier, language, code, result.LastError, functionCode)
+		if err != nil {
+			log.Warn().Err(err).Str("file", testFile).Msg("test repair failed")
+			break
+		}
+		if prov != nil {
+			fixed = adapters.StampProvenance(fixed, testFile, *prov)
+		}
+		if err := os.WriteFile(testFile, []byte(fixed), 0644); err != nil {
+			return nil, fmt.Errorf("failed to write repaired test: %w", err)
+		}
+		code = fixed
+	}
+
+	restore(testFile, original, result)
+	return result, nil
+}
+
+// repairOnce asks the LLM for the test file corrected for failure
+func (r *Repairer) repairOnce(ctx context.Context, tier llm.Tier, language, code, failure, functionCode string) (string, error) {
+	if tier == 0 {
+		tier = llm.Tier2
+	}
+
+	resp, err := r.complete(ctx, &llm.Request{
+		Tier:        tier,
+		System:      llm.SystemPromptTestRepair,
+		Messages:    []llm.Message{{Role: "user", Content: llm.TestRepairPrompt(code, failure, functionCode, language)}},
+		Temperature: 0.2,
+		MaxTokens:   4096,
+	})
+	if err != nil {
+		return "", fmt.Errorf("LLM completion failed: %w", err)
+	}
+
+	fixed := llm.ParseCodeOutput(resp.Content)
+	if fixed == "" {
+		return "", fmt.Errorf("no code in LLM response")
+	}
+	return fixed + "\n", nil
+}
+
+// restore puts back the file as written when the repairs changed it
+func restore(testFile string, original []byte, result *RepairResult) {
+	if result.Iterations == 0 {
+		return
+	}
+	if err := os.WriteFile(testFile, original, 0644); err != nil {
+		log.Warn().Err(err).Str("file", testFile).Msg("failed to restore test after repair")
+	}
+}
+
+// failureText is a failed run's errors for a repair prompt: the parsed
+// failures, else the tail of the output, where compilers report
+func failureText(run *validator.TestResult) string {
+	var sb strings.Builder
+	for _, e := range run.Errors {
+		fmt.Fprintf(&sb, "%s: %s\n", e.TestName, e.Message)
+		if e.Expected != "" || e.Actual != "" {
+			fmt.Fprintf(&sb, "  expected: %s\n  actual: %s\n", e.Expected, e.Actual)
+		}
+	}
+	if sb.Len() > 0 {
+		return sb.String()
+	}
+	output := strings.TrimSpace(run.Output)
+	if len(output) > maxRepairOutput {
+		output = "..." + output[len(output)-maxRepairOutput:]
+	}
+	return output
+}
+
+// sourceOf returns the code of the function under test, or empty
+func sourceOf(test *GeneratedTest) string {
+	if test.Function == nil || test.FileName == "" {
+		return ""
+	}
+	content, err := os.ReadFile(test.FileName)
+	if err != nil {
+		return ""
+	}
+	return extractLines(splitLines(string(content)), test.Function.StartLine, test.Function.EndLine)
+}
@@ -0,0 +1,128 @@
+package generator
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/QTest-hq/qtest/internal/adapters"
+	"github.com/QTest-hq/qtest/internal/llm"
+	"github.com/QTest-hq/qtest/internal/parser"
+	"github.com/QTest-hq/qtest/internal/validator"
+)
+
+// fakeRunner passes a test file once it contains pass
+type fakeRunner struct {
+	pass string
+	runs int
+	err  error
+}
+
+func (r *fakeRunner) RunTests(ctx context.Context, testFile string) (*validator.TestResult, error) {
+	r.runs++
+	if r.err != nil {
+		return nil, r.err
+	}
+	code, err := os.ReadFile(testFile)
+	if err != nil {
+		return nil, err
+	}
+	if strings.Contains(string(code), r.pass) {
+		return &validator.TestResult{Passed: true}, nil
+	}
+	return &validator.TestResult{Output: "./add_test.go:5:9: undefined: Sum\nFAIL"}, nil
+}
+
+func TestRepairer_Repair(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "add.go")
+	if err := os.WriteFile(source, []byte("package add\n\nfunc Add(a, b int) int {\n\treturn a + b\n}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	testFile := filepath.Join(dir, "add_test.go")
+	written := adapters.StampProvenance("package add\n\nfunc TestAdd(t *testing.T) { Sum(1, 2) }\n", testFile, adapters.Provenance{RunID: "run1"})
+	test := &GeneratedTest{Function: &parser.Function{Name: "Add", StartLine: 3, EndLine: 5}, FileName: source, Tier: llm.Tier1}
+
+	reset := func() {
+		if err := os.WriteFile(testFile, []byte(written), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The LLM fixes the file on its second attempt
+	var prompts []*llm.Request
+	responses := []string{"```go\npackage add\n\nfunc TestAdd(t *testing.T) { Plus(1, 2) }\n```", "```go\npackage add\n\nfunc TestAdd(t *testing.T) { Add(1, 2) }\n```"}
+	r := &Repairer{maxIterations: 3, complete: func(ctx context.Context, req *llm.Request) (*llm.Response, error) {
+		prompts = append(prompts, req)
+		resp := responses[0]
+		responses = responses[1:]
+		return &llm.Response{Content: resp}, nil
+	}}
+
+	reset()
+	runner := &fakeRunner{pass: "Add(1, 2)"}
+	result, err := r.Repair(context.Background(), runner, test, testFile, "go")
+	if err != nil {
+		t.Fatalf("Repair() error = %v", err)
+	}
+	if !result.Passed || result.Iterations != 2 || runner.runs != 3 {
+		t.Errorf("result = %+v after %d runs, want passed after 2 iterations and 3 runs", result, runner.runs)
+	}
+	prompt := prompts[0].Messages[0].Content
+	for _, want := range []string{"undefined: Sum", "return a + b", "This go test file"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("repair prompt missing %q:\n%s", want, prompt)
+		}
+	}
+	if prompts[0].Tier != llm.Tier1 || prompts[0].System != llm.SystemPromptTestRepair {
+		t.Errorf("repair request tier = %d, system = %q", prompts[0].Tier, prompts[0].System)
+	}
+	content, _ := os.ReadFile(testFile)
+	if prov, _ := adapters.ParseProvenance(string(content)); prov == nil || prov.RunID != "run1" || !strings.Contains(string(content), "Add(1, 2)") {
+		t.Errorf("repaired file should keep its provenance:\n%s", content)
+	}
+
+	// Iterations run out: the file is restored for validation to report
+	reset()
+	r.maxIterations = 1
+	r.complete = func(ctx context.Context, req *llm.Request) (*llm.Response, error) {
+		return &llm.Response{Content: "```go\npackage add\n```"}, nil
+	}
+	result, err = r.Repair(context.Background(), &fakeRunner{pass: "never"}, test, testFile, "go")
+	if err != nil {
+		t.Fatalf("Repair() error = %v", err)
+	}
+	if result.Passed || result.Iterations != 1 || !strings.Contains(result.LastError, "undefined: Sum") {
+		t.Errorf("result = %+v, want a failure after 1 iteration", result)
+	}
+	if content, _ := os.ReadFile(testFile); string(content) != written {
+		t.Errorf("failed repair should restore the file, got:\n%s", content)
+	}
+
+	// A failed LLM call stops the loop; a file that can't be run isn't repaired
+	reset()
+	r.complete = func(ctx context.Context, req *llm.Request) (*llm.Response, error) {
+		return nil, errors.New("unavailable")
+	}
+	if result, err := r.Repair(context.Background(), &fakeRunner{pass: "never"}, test, testFile, "go"); err != nil || result.Passed || result.Iterations != 1 {
+		t.Errorf("result = %+v, err = %v", result, err)
+	}
+	if _, err := r.Repair(context.Background(), &fakeRunner{err: errors.New("unsupported language: rust")}, test, testFile, "rust"); err == nil {
+		t.Error("Repair() should report a file that can't be run")
+	}
+}
+
+func TestFailureText(t *testing.T) {
+	run := &validator.TestResult{Errors: []validator.TestError{{TestName: "TestAdd", Message: "wrong sum", Expected: "3", Actual: "4"}}}
+	if got := failureText(run); got != "TestAdd: wrong sum\n  expected: 3\n  actual: 4\n" {
+		t.Errorf("failureText() = %q", got)
+	}
+
+	long := strings.Repeat("x", maxRepairOutput) + "undefined: Sum"
+	if got := failureText(&validator.TestResult{Output: long}); !strings.HasSuffix(got, "undefined: Sum") || len(got) != maxRepairOutput+3 {
+		t.Errorf("failureText() should keep the tail of long output, got %d bytes", len(got))
+	}
+}
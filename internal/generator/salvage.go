@@ -0,0 +1,242 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/QTest-hq/qtest/pkg/model"
+	"gopkg.in/yaml.v3"
+)
+
+// Salvage is what a tolerant parse kept of a malformed LLM response
+type Salvage struct {
+	Kept   []string // names of the test cases kept
+	Broken []string // why each broken test case was dropped
+}
+
+// Missing is how many test cases were dropped
+func (s *Salvage) Missing() int {
+	return len(s.Broken)
+}
+
+var (
+	// testsArrayPattern finds the start of an IRSpec suite's tests array
+	testsArrayPattern = regexp.MustCompile(`"(?:tests|test_cases|testCases|cases)"\s*:\s*\[`)
+
+	// functionNamePattern finds an IRSpec suite's function name
+	functionNamePattern = regexp.MustCompile(`"function_name"\s*:\s*"([^"]*)"`)
+)
+
+// SalvageIRSpec parses the test cases of IRSpec JSON one at a time, keeping
+// those that are valid when the suite as a whole isn't, e.g. when one test
+// case has a syntax error or an invalid assertion, or the output was cut off
+// part way through one.
+func (c *IRSpecConverter) SalvageIRSpec(jsonData, functionName string) (*model.IRTestSuite, *Salvage, error) {
+	elements, ok := splitTestsArray(jsonData)
+	if !ok {
+		return nil, nil, fmt.Errorf("no IRSpec tests array found")
+	}
+
+	if m := functionNamePattern.FindStringSubmatch(jsonData); m != nil && m[1] != "" {
+		functionName = m[1]
+	}
+	suite := &model.IRTestSuite{FunctionName: functionName}
+	salvage := &Salvage{}
+
+	for i, element := range elements {
+		tc, err := c.parseTestCase(element, functionName)
+		if err != nil {
+			salvage.Broken = append(salvage.Broken, fmt.Sprintf("tests[%d]: %v", i, err))
+			continue
+		}
+		suite.Tests = append(suite.Tests, *tc)
+		salvage.Kept = append(salvage.Kept, tc.Name)
+	}
+
+	if len(suite.Tests) == 0 {
+		return nil, salvage, fmt.Errorf("no valid test cases in IRSpec output: %s", strings.Join(salvage.Broken, "; "))
+	}
+	return suite, salvage, nil
+}
+
+// parseTestCase parses and validates one element of a tests array
+func (c *IRSpecConverter) parseTestCase(element, functionName string) (*model.IRTestCase, error) {
+	repaired, _, err := RepairIRSpec("["+element+"]", functionName)
+	if err != nil {
+		return nil, err
+	}
+
+	var single model.IRTestSuite
+	if err := json.Unmarshal([]byte(repaired), &single); err != nil {
+		return nil, fmt.Errorf("invalid test case: %w", err)
+	}
+	if len(single.Tests) != 1 {
+		return nil, fmt.Errorf("incomplete test case")
+	}
+
+	result := c.validator.Validate(&single)
+	if !result.Valid {
+		return nil, fmt.Errorf("%s", strings.Join(result.ErrorMessages(), "; "))
+	}
+	return &single.Tests[0], nil
+}
+
+// SalvageAndConvert is SalvageIRSpec followed by conversion to TestSpecs
+func (c *IRSpecConverter) SalvageAndConvert(jsonData, functionName string) ([]model.TestSpec, *Salvage, error) {
+	suite, salvage, err := c.SalvageIRSpec(jsonData, functionName)
+	if err != nil {
+		return nil, salvage, err
+	}
+	specs, err := c.ConvertToTestSpecs(suite)
+	return specs, salvage, err
+}
+
+// splitTestsArray returns the raw elements of the tests array in IRSpec
+// JSON, or of the top-level array if the output is a bare list. The last
+// element is returned even if the output ends part way through it.
+func splitTestsArray(data string) ([]string, bool) {
+	start := -1
+	if loc := testsArrayPattern.FindStringIndex(data); loc != nil {
+		start = loc[1]
+	} else if i := strings.IndexAny(data, "{["); i >= 0 && data[i] == '[' {
+		start = i + 1
+	}
+	if start < 0 {
+		return nil, false
+	}
+
+	var (
+		elements []string
+		open     []byte // brackets open inside the current element
+		inString bool
+		escaped  bool
+		from     = start
+	)
+	add := func(end int) {
+		if element := strings.TrimSpace(data[from:end]); element != "" {
+			elements = append(elements, element)
+		}
+	}
+
+	for i := start; i < len(data); i++ {
+		ch := data[i]
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case ch == '\\':
+				escaped = true
+			case ch == '"':
+				inString = false
+			}
+			continue
+		}
+
+		switch ch {
+		case '"':
+			inString = true
+		case '{', '[':
+			open = append(open, ch)
+		case '}', ']':
+			if len(open) == 0 {
+				add(i) // the end of the tests array
+				return elements, true
+			}
+			// A mismatched bracket closes the ones left open inside it, so
+			// one broken test case doesn't swallow the rest
+			opener := byte('{')
+			if ch == ']' {
+				opener = '['
+			}
+			if j := strings.LastIndexByte(string(open), opener); j >= 0 {
+				open = open[:j]
+			} else {
+				open = open[:len(open)-1]
+			}
+		case ',':
+			if len(open) == 0 {
+				add(i)
+				from = i + 1
+			}
+		}
+	}
+
+	add(len(data)) // truncated
+	return elements, true
+}
+
+// SalvageSimpleTests parses the items of a YAML test list one at a time,
+// keeping those that parse when the list as a whole doesn't
+func SalvageSimpleTests(yamlContent string) ([]SimpleTest, *Salvage) {
+	salvage := &Salvage{}
+	var tests []SimpleTest
+
+	for i, item := range splitYAMLList(yamlContent) {
+		var parsed SimpleTestList
+		if err := yaml.Unmarshal([]byte(item), &parsed); err != nil {
+			salvage.Broken = append(salvage.Broken, fmt.Sprintf("tests[%d]: %v", i, err))
+			continue
+		}
+		if len(parsed) != 1 || parsed[0].Name == "" {
+			salvage.Broken = append(salvage.Broken, fmt.Sprintf("tests[%d]: not a named test", i))
+			continue
+		}
+		if parsed[0].Action == nil && parsed[0].GetAssertions() == nil {
+			salvage.Broken = append(salvage.Broken, fmt.Sprintf("tests[%d]: no action or assertions", i))
+			continue
+		}
+		tests = append(tests, parsed[0])
+		salvage.Kept = append(salvage.Kept, parsed[0].Name)
+	}
+	return tests, salvage
+}
+
+// splitYAMLList splits a YAML list, or the list under a "tests:" key, into
+// its items, each a one-item list
+func splitYAMLList(content string) []string {
+	lines := strings.Split(content, "\n")
+
+	// The list's indent is that of its first item
+	indent := -1
+	for _, line := range lines {
+		trimmed := strings.TrimLeft(line, " ")
+		if strings.HasPrefix(trimmed, "- ") || trimmed == "-" {
+			indent = len(line) - len(trimmed)
+			break
+		}
+	}
+	if indent < 0 {
+		return nil
+	}
+	prefix := strings.Repeat(" ", indent)
+
+	var (
+		items   []string
+		current []string
+	)
+	flush := func() {
+		if len(current) > 0 {
+			items = append(items, strings.Join(current, "\n"))
+		}
+		current = nil
+	}
+	for _, line := range lines {
+		isItem := strings.HasPrefix(line, prefix+"- ") || line == prefix+"-"
+		if isItem {
+			flush()
+		}
+		if current == nil && !isItem {
+			continue // before the first item
+		}
+		// Lines at or before the list's indent that aren't items end it
+		if !isItem && strings.TrimSpace(line) != "" && len(line)-len(strings.TrimLeft(line, " ")) <= indent {
+			flush()
+			continue
+		}
+		current = append(current, strings.TrimPrefix(line, prefix))
+	}
+	flush()
+	return items
+}
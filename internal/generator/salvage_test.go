@@ -0,0 +1,173 @@
+package generator
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/QTest-hq/qtest/internal/config"
+	"github.com/QTest-hq/qtest/internal/llm"
+	"github.com/QTest-hq/qtest/internal/parser"
+)
+
+const addTest = `{"name": "%s", "given": [{"name": "a", "value": 1, "type": "int"}], "when": {"call": "Add($a)", "args": ["a"]}, "then": [{"type": "equals", "actual": "result", "expected": 1}]}`
+
+func testCase(name string) string {
+	return strings.Replace(addTest, "%s", name, 1)
+}
+
+func TestSalvageIRSpec(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		kept   []string
+		broken int
+	}{
+		{
+			name:   "invalid assertion in one test case",
+			data:   `{"function_name": "Add", "tests": [` + testCase("one") + `, {"name": "bad", "given": [], "when": {"call": "Add()"}, "then": [{"type": "bogus", "actual": "result"}]}, ` + testCase("two") + `]}`,
+			kept:   []string{"one", "two"},
+			broken: 1,
+		},
+		{
+			name:   "syntax error in one test case",
+			data:   `{"function_name": "Add", "tests": [` + testCase("one") + `, {"name": "bad", given: [}, ` + testCase("two") + `]}`,
+			kept:   []string{"one", "two"},
+			broken: 1,
+		},
+		{
+			name:   "truncated in the last test case",
+			data:   `{"function_name": "Add", "tests": [` + testCase("one") + `, ` + testCase("two") + `, {"name": "three", "given": [{"name": "a", "val`,
+			kept:   []string{"one", "two"},
+			broken: 1,
+		},
+		{
+			name: "bare list",
+			data: `[` + testCase("one") + `]`,
+			kept: []string{"one"},
+		},
+	}
+
+	converter := NewIRSpecConverter()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			suite, salvage, err := converter.SalvageIRSpec(tt.data, "Add")
+			if err != nil {
+				t.Fatalf("SalvageIRSpec() error: %v", err)
+			}
+			if suite.FunctionName != "Add" {
+				t.Errorf("FunctionName = %q, want Add", suite.FunctionName)
+			}
+			if strings.Join(salvage.Kept, ",") != strings.Join(tt.kept, ",") {
+				t.Errorf("Kept = %v, want %v", salvage.Kept, tt.kept)
+			}
+			if salvage.Missing() != tt.broken {
+				t.Errorf("Broken = %v, want %d", salvage.Broken, tt.broken)
+			}
+		})
+	}
+}
+
+func TestSalvageIRSpec_NothingValid(t *testing.T) {
+	converter := NewIRSpecConverter()
+
+	if _, _, err := converter.SalvageIRSpec(`not json`, "Add"); err == nil {
+		t.Error("expected error without a tests array")
+	}
+	_, salvage, err := converter.SalvageIRSpec(`{"tests": [{"name": "bad"}]}`, "Add")
+	if err == nil {
+		t.Error("expected error without valid test cases")
+	}
+	if salvage == nil || salvage.Missing() != 1 {
+		t.Errorf("salvage = %+v, want the broken test case reported", salvage)
+	}
+}
+
+func TestSalvageSimpleTests(t *testing.T) {
+	content := `tests:
+  - name: adds positives
+    action: Add(1, 2)
+    assertions:
+      equals: 3
+  - name: broken
+    action: [unclosed
+  - name: adds zero
+    action: Add(0, 0)
+    assertions:
+      equals: 0
+  - just a string
+`
+	if _, err := ConvertToDSL(content, "Add", "calc.go", "go"); err == nil {
+		t.Fatal("expected the whole list to fail to parse")
+	}
+
+	tests, salvage := SalvageSimpleTests(content)
+	if len(tests) != 2 || tests[0].Name != "adds positives" || tests[1].Name != "adds zero" {
+		t.Errorf("tests = %+v, want the two valid ones", tests)
+	}
+	if salvage.Missing() != 2 {
+		t.Errorf("Broken = %v, want 2", salvage.Broken)
+	}
+}
+
+func TestGenerateWithIRSpec_RetriesMissing(t *testing.T) {
+	var requests []map[string]interface{}
+	responses := []string{
+		`{"function_name": "Add", "tests": [` + testCase("one") + `, ` + testCase("two") + `, {"name": "bad", "given": [], "when": {"call": "Add()"}, "then": [{"type": "bogus", "actual": "result"}]}]}`,
+		`{"function_name": "Add", "tests": [` + testCase("three") + `]}`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/chat" {
+			json.NewEncoder(w).Encode(map[string]interface{}{"models": []interface{}{}})
+			return
+		}
+		var req map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&req)
+		requests = append(requests, req)
+		content := responses[len(requests)-1]
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"model":   "test",
+			"message": map[string]string{"role": "assistant", "content": content},
+			"done":    true,
+		})
+	}))
+	defer server.Close()
+
+	router, err := llm.NewRouter(&config.Config{LLM: config.LLMConfig{
+		DefaultProvider: "ollama",
+		OllamaURL:       server.URL,
+		OllamaTier1:     "test",
+		OllamaTier2:     "test",
+	}})
+	if err != nil {
+		t.Fatalf("NewRouter() error: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "calc.go")
+	os.WriteFile(path, []byte("package calc\n\nfunc Add(a int) int { return a }\n"), 0644)
+	fn := &parser.Function{Name: "Add", StartLine: 3, EndLine: 3}
+	file := &parser.ParsedFile{Path: path, Language: parser.LanguageGo}
+
+	test, err := NewGenerator(router).GenerateWithIRSpec(context.Background(), fn, file, GenerateOptions{Tier: llm.Tier1})
+	if err != nil {
+		t.Fatalf("GenerateWithIRSpec() error: %v", err)
+	}
+	if len(test.TestSpecs) != 3 {
+		t.Errorf("len(TestSpecs) = %d, want 2 salvaged + 1 retried", len(test.TestSpecs))
+	}
+
+	if len(requests) != 2 {
+		t.Fatalf("requests = %d, want 2", len(requests))
+	}
+	messages, _ := requests[1]["messages"].([]interface{})
+	last, _ := messages[len(messages)-1].(map[string]interface{})
+	prompt, _ := last["content"].(string)
+	if !strings.Contains(prompt, "Generate 1 new test cases") || !strings.Contains(prompt, "one, two") {
+		t.Errorf("retry prompt = %q, want it to ask for the 1 dropped test case", prompt)
+	}
+}
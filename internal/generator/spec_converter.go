@@ -2,6 +2,7 @@ package generator
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/QTest-hq/qtest/pkg/model"
@@ -165,22 +166,11 @@ func extractAssertionsFromMap(m map[string]interface{}, result *[]model.Assertio
 		}
 	}
 
-	// Handle "error: true/message" format
-	if errVal, ok := m["error"]; ok {
-		switch e := errVal.(type) {
-		case bool:
-			if e {
-				*result = append(*result, model.Assertion{
-					Kind:   "not_null",
-					Actual: "error",
-				})
-			}
-		case string:
-			*result = append(*result, model.Assertion{
-				Kind:     "contains",
-				Actual:   "error",
-				Expected: e,
-			})
+	// Handle "error: true/name/message" and "error: {type, message}"
+	// formats, also spelled "raises" or "throws"
+	for _, key := range []string{"error", model.AssertRaises, model.AssertThrows} {
+		if assertion := errorAssertion(m[key]); assertion != nil {
+			*result = append(*result, *assertion)
 		}
 	}
 
@@ -193,6 +183,50 @@ func extractAssertionsFromMap(m map[string]interface{}, result *[]model.Assertio
 		})
 	}
 
+	// Handle "approx: value" and "approx: {value, tolerance}" formats
+	if approx, ok := m[model.AssertApprox]; ok {
+		*result = append(*result, approxAssertion(approx))
+	}
+
+	// Handle "matches: pattern" format
+	if pattern, ok := m[model.AssertMatches]; ok {
+		*result = append(*result, model.Assertion{
+			Kind:     model.AssertMatches,
+			Actual:   "result",
+			Expected: pattern,
+		})
+	}
+
+	// Handle "contains_all: [items]", "subset: [items]" and "sorted: key"
+	// formats
+	for _, kind := range []string{model.AssertContainsAll, model.AssertSubset, model.AssertSorted} {
+		if val, ok := m[kind]; ok {
+			*result = append(*result, model.Assertion{
+				Kind:     kind,
+				Actual:   "result",
+				Expected: val,
+			})
+		}
+	}
+
+	// Handle "headers: {name: value}" and "cookies: {name: value}" formats
+	for _, field := range []struct{ key, kind string }{
+		{"headers", model.AssertHeaderEquals},
+		{"cookies", model.AssertCookieEquals},
+	} {
+		values, ok := m[field.key].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for _, name := range responseNames(values) {
+			*result = append(*result, model.Assertion{
+				Kind:     field.kind,
+				Actual:   name,
+				Expected: values[name],
+			})
+		}
+	}
+
 	// Handle "type: typename" format
 	if typeVal, ok := m["type"]; ok {
 		*result = append(*result, model.Assertion{
@@ -204,7 +238,10 @@ func extractAssertionsFromMap(m map[string]interface{}, result *[]model.Assertio
 
 	// Handle property assertions like "length: 5" or "status: 200"
 	for key, val := range m {
-		if key == "result" || key == "expect" || key == "error" || key == "contains" || key == "type" {
+		if key == "result" || key == "expect" || key == "error" || key == model.AssertRaises || key == model.AssertThrows || key == "contains" || key == "type" ||
+			key == model.AssertApprox || key == model.AssertMatches ||
+			key == model.AssertContainsAll || key == model.AssertSubset || key == model.AssertSorted ||
+			key == "headers" || key == "cookies" {
 			continue
 		}
 		// This is a property assertion
@@ -216,6 +253,45 @@ func extractAssertionsFromMap(m map[string]interface{}, result *[]model.Assertio
 	}
 }
 
+// errorAssertion converts an expected error to a throws assertion: true
+// for any error, an error name or message, or a map of its type and message
+func errorAssertion(val interface{}) *model.Assertion {
+	assertion := &model.Assertion{Kind: model.AssertThrows, Actual: "result"}
+	switch e := val.(type) {
+	case bool:
+		if !e {
+			return nil
+		}
+	case string:
+		if e == "" || strings.EqualFold(e, "none") {
+			return nil
+		}
+		assertion.ErrorType, assertion.ErrorMessage = expectedError(e)
+	case map[string]interface{}:
+		assertion.ErrorType, _ = e["type"].(string)
+		assertion.ErrorMessage, _ = e["message"].(string)
+	default:
+		return nil
+	}
+	return assertion
+}
+
+// approxAssertion converts an expected value, or a map of its value and
+// tolerance, to an approx assertion
+func approxAssertion(val interface{}) model.Assertion {
+	assertion := model.Assertion{Kind: model.AssertApprox, Actual: "result", Expected: val}
+	if m, ok := val.(map[string]interface{}); ok {
+		assertion.Expected = m["value"]
+		switch tol := m["tolerance"].(type) {
+		case float64:
+			assertion.Tolerance = tol
+		case int:
+			assertion.Tolerance = float64(tol)
+		}
+	}
+	return assertion
+}
+
 // parseExpectAssertion parses expressions like "result == 5" into Assertion
 func parseExpectAssertion(expr string) *model.Assertion {
 	expr = strings.TrimSpace(expr)
@@ -350,3 +426,14 @@ func parseValue(s string) interface{} {
 	// Return as string
 	return s
 }
+
+// responseNames returns the header or cookie names of a YAML "headers" or
+// "cookies" map in order, so assertions come out the same each run
+func responseNames(values map[string]interface{}) []string {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
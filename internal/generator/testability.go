@@ -0,0 +1,66 @@
+package generator
+
+import (
+	"fmt"
+
+	"github.com/QTest-hq/qtest/internal/parser"
+	"github.com/QTest-hq/qtest/pkg/model"
+)
+
+// Testability issue kinds
+const (
+	IssueGlobalState = model.SmellGlobalState
+	IssueClock       = model.SmellClock
+	IssueEnvironment = model.SmellEnvironment
+	IssueRandomness  = model.SmellRandomness
+	IssueNetwork     = model.SmellNetwork
+	IssueDatabase    = model.SmellDatabase
+	IssueFilesystem  = model.SmellFilesystem
+	IssueProcess     = model.SmellProcess
+	IssueSize        = "size"
+	IssueParameters  = model.SmellParameters
+)
+
+// Size limits past which a function is hard to cover with a few tests
+const (
+	maxTestableLines  = 80
+	maxTestableParams = 6
+)
+
+// TestabilityIssue is a reason a function is hard to unit test, with a
+// refactoring that would make it easier
+type TestabilityIssue struct {
+	Kind       string `json:"kind"`
+	Line       int    `json:"line,omitempty"` // line in the source file
+	Evidence   string `json:"evidence,omitempty"`
+	Suggestion string `json:"suggestion"`
+}
+
+// DiagnoseTestability looks for the usual reasons a function resists unit
+// testing: hidden dependencies on the clock, environment, network, disk or
+// process, shared state (see model.BodySmells), and sheer size.
+func DiagnoseTestability(fn *parser.Function, language parser.Language) []TestabilityIssue {
+	var issues []TestabilityIssue
+	for _, smell := range model.BodySmells(fn.Body, fn.StartLine, string(language)) {
+		issues = append(issues, TestabilityIssue(smell))
+	}
+
+	if lines := fn.EndLine - fn.StartLine + 1; fn.StartLine > 0 && lines > maxTestableLines {
+		issues = append(issues, TestabilityIssue{
+			Kind:       IssueSize,
+			Line:       fn.StartLine,
+			Evidence:   fmt.Sprintf("%d lines", lines),
+			Suggestion: "Split it into smaller functions that each do one thing and can be tested alone.",
+		})
+	}
+	if len(fn.Parameters) > maxTestableParams {
+		issues = append(issues, TestabilityIssue{
+			Kind:       IssueParameters,
+			Line:       fn.StartLine,
+			Evidence:   fmt.Sprintf("%d parameters", len(fn.Parameters)),
+			Suggestion: "Group related parameters into a struct or options object.",
+		})
+	}
+
+	return issues
+}
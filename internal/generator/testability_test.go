@@ -0,0 +1,86 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/QTest-hq/qtest/internal/parser"
+)
+
+func TestDiagnoseTestability(t *testing.T) {
+	tests := []struct {
+		name     string
+		fn       parser.Function
+		language parser.Language
+		want     []string
+	}{
+		{
+			name: "go hidden dependencies",
+			fn: parser.Function{StartLine: 10, EndLine: 16, Body: `func Sync() error {
+	// time.Now() in a comment is ignored
+	if os.Getenv("SYNC_DISABLED") != "" {
+		return nil
+	}
+	resp, err := http.Get(endpoint + "?at=" + time.Now().String())
+	defer resp.Body.Close()`},
+			language: parser.LanguageGo,
+			want:     []string{IssueEnvironment, IssueClock, IssueNetwork},
+		},
+		{
+			name: "python globals and subprocess",
+			fn: parser.Function{Body: `def refresh():
+    global cache
+    cache = subprocess.check_output(["ls"])`},
+			language: parser.LanguagePython,
+			want:     []string{IssueGlobalState, IssueProcess},
+		},
+		{
+			name:     "typescript randomness",
+			fn:       parser.Function{Body: `export function id() { return Math.random().toString(36) }`},
+			language: parser.LanguageTypeScript,
+			want:     []string{IssueRandomness},
+		},
+		{
+			name: "size and parameters",
+			fn: parser.Function{StartLine: 1, EndLine: 200, Body: "func Big() {}",
+				Parameters: make([]parser.Parameter, 8)},
+			language: parser.LanguageGo,
+			want:     []string{IssueSize, IssueParameters},
+		},
+		{
+			name:     "pure function",
+			fn:       parser.Function{StartLine: 1, EndLine: 3, Body: "func Add(a, b int) int {\n\treturn a + b\n}"},
+			language: parser.LanguageGo,
+			want:     nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues := DiagnoseTestability(&tt.fn, tt.language)
+			var kinds []string
+			for _, issue := range issues {
+				kinds = append(kinds, issue.Kind)
+				if issue.Suggestion == "" {
+					t.Errorf("%s issue has no suggestion", issue.Kind)
+				}
+			}
+			if len(kinds) != len(tt.want) {
+				t.Fatalf("kinds = %v, want %v", kinds, tt.want)
+			}
+			for i := range kinds {
+				if kinds[i] != tt.want[i] {
+					t.Errorf("kinds = %v, want %v", kinds, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestDiagnoseTestability_Line(t *testing.T) {
+	fn := parser.Function{StartLine: 20, EndLine: 22, Body: "func Wait() {\n\ttime.Sleep(time.Second)\n}"}
+	issues := DiagnoseTestability(&fn, parser.LanguageGo)
+	if len(issues) != 1 || issues[0].Line != 21 || issues[0].Evidence != "time.Sleep(time.Second)" {
+		t.Errorf("issues = %+v, want a clock issue on line 21", issues)
+	}
+}
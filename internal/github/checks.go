@@ -0,0 +1,381 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// QualityGateName is the name of the check run QTest publishes, for branch
+// protection rules to require
+const QualityGateName = "QTest Quality Gate"
+
+// Check run limits: GitHub takes at most 50 annotations per request, and we
+// stop well before large reports flood the checks tab
+const (
+	annotationsPerRequest = 50
+	maxAnnotations        = 500
+)
+
+// Check run conclusions
+const (
+	ConclusionSuccess = "success"
+	ConclusionFailure = "failure"
+	ConclusionNeutral = "neutral"
+)
+
+// Annotation levels
+const (
+	AnnotationNotice  = "notice"
+	AnnotationWarning = "warning"
+	AnnotationFailure = "failure"
+)
+
+// CheckRun is a check run to create or update
+type CheckRun struct {
+	Name       string
+	HeadSHA    string
+	DetailsURL string
+	Conclusion string // success, failure or neutral; empty leaves it in progress
+	Output     CheckOutput
+}
+
+// CheckOutput is what a check run shows on its page
+type CheckOutput struct {
+	Title       string            `json:"title"`
+	Summary     string            `json:"summary"`
+	Text        string            `json:"text,omitempty"`
+	Annotations []CheckAnnotation `json:"annotations,omitempty"`
+}
+
+// CheckAnnotation marks lines of a file in a check run
+type CheckAnnotation struct {
+	Path      string `json:"path"` // relative to the repository root
+	StartLine int    `json:"start_line"`
+	EndLine   int    `json:"end_line"`
+	Level     string `json:"annotation_level"` // notice, warning or failure
+	Title     string `json:"title,omitempty"`
+	Message   string `json:"message"`
+}
+
+// CheckRunResponse is a created check run
+type CheckRunResponse struct {
+	ID         int64  `json:"id"`
+	HTMLURL    string `json:"html_url"`
+	Status     string `json:"status"`
+	Conclusion string `json:"conclusion"`
+}
+
+// CreateCheckRun creates a check run on a commit. GitHub accepts 50
+// annotations per request, so the rest are added with updates.
+func (s *PRService) CreateCheckRun(ctx context.Context, owner, repo string, run CheckRun) (*CheckRunResponse, error) {
+	annotations := run.Output.Annotations
+	first := annotations
+	if len(first) > annotationsPerRequest {
+		first = first[:annotationsPerRequest]
+	}
+
+	payload := map[string]interface{}{
+		"name":     run.Name,
+		"head_sha": run.HeadSHA,
+		"output":   checkOutputPayload(run.Output, first),
+	}
+	if run.DetailsURL != "" {
+		payload["details_url"] = run.DetailsURL
+	}
+	// The conclusion is set with the last batch, so the run isn't reported
+	// complete before all its annotations are in
+	if run.Conclusion != "" && len(annotations) <= annotationsPerRequest {
+		payload["status"] = "completed"
+		payload["conclusion"] = run.Conclusion
+	} else {
+		payload["status"] = "in_progress"
+	}
+
+	url := fmt.Sprintf("%s/repos/%s/%s/check-runs", s.baseURL, owner, repo)
+	var created CheckRunResponse
+	if err := s.sendCheckRun(ctx, "POST", url, payload, 201, &created); err != nil {
+		return nil, fmt.Errorf("failed to create check run: %w", err)
+	}
+
+	for i := annotationsPerRequest; i < len(annotations); i += annotationsPerRequest {
+		end := i + annotationsPerRequest
+		if end > len(annotations) {
+			end = len(annotations)
+		}
+		update := map[string]interface{}{"output": checkOutputPayload(run.Output, annotations[i:end])}
+		if end == len(annotations) && run.Conclusion != "" {
+			update["status"] = "completed"
+			update["conclusion"] = run.Conclusion
+		}
+
+		url := fmt.Sprintf("%s/repos/%s/%s/check-runs/%d", s.baseURL, owner, repo, created.ID)
+		if err := s.sendCheckRun(ctx, "PATCH", url, update, 200, &created); err != nil {
+			return nil, fmt.Errorf("failed to add check run annotations: %w", err)
+		}
+	}
+
+	return &created, nil
+}
+
+// checkOutputPayload is a check run's output with one batch of annotations
+func checkOutputPayload(output CheckOutput, annotations []CheckAnnotation) CheckOutput {
+	output.Annotations = annotations
+	return output
+}
+
+// sendCheckRun sends a check run request and decodes the run it returns
+func (s *PRService) sendCheckRun(ctx context.Context, method, url string, payload interface{}, wantStatus int, out *CheckRunResponse) error {
+	body, _ := json.Marshal(payload)
+
+	httpReq, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	s.setHeaders(httpReq)
+
+	resp, err := s.client.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != wantStatus {
+		return fmt.Errorf("%s - %s", resp.Status, string(respBody))
+	}
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
+
+// QualityGate holds the thresholds a QTest Quality Gate check enforces
+type QualityGate struct {
+	MinCoverage      float64 // percent of lines covered; 0 doesn't check coverage
+	MinMutationScore float64 // percent of mutants killed; 0 doesn't check mutation
+	MaxFlakeRate     float64 // percent of tests that flaked across repeated runs
+	CheckFlakeRate   bool    // whether MaxFlakeRate applies, so 0 tolerates no flaky test
+
+	// Baseline, when set, ratchets the gate: a metric fails only when it's
+	// worse than both its threshold and the baseline's, so a project short
+	// of a threshold passes as long as it doesn't regress
+	Baseline *QualityReport
+}
+
+// QualityReport holds what a run measured, for a quality gate to judge. A
+// metric that wasn't measured doesn't fail the gate.
+type QualityReport struct {
+	Coverage         float64 // percent
+	CoverageMeasured bool
+	MutationScore    float64 // percent
+	MutationMeasured bool
+	FlakeRate        float64 // percent
+	FlakeMeasured    bool
+	Files            []FileQuality
+}
+
+// FileQuality holds what a run measured for one source file
+type FileQuality struct {
+	Path             string // relative to the repository root
+	Coverage         float64
+	CoverageMeasured bool
+	UncoveredLines   []int
+	MutationScore    float64
+	MutationMeasured bool
+	Survivors        []ReviewComment // lines mutants survived on
+}
+
+// Evaluate judges a report against the gate, returning the conclusion and
+// the output of the check run. Files below a threshold get a failure
+// annotation; their uncovered lines and surviving mutants get warnings.
+func (g QualityGate) Evaluate(report QualityReport) (string, CheckOutput) {
+	var failures, lines []string
+	base := g.Baseline
+	if base == nil {
+		base = &QualityReport{}
+	}
+	// check judges a metric against its limit, and against the baseline's
+	// value when ratcheting; higher is better unless lowerIsBetter
+	check := func(name string, measured bool, value, limit float64, baseMeasured bool, baseValue float64, lowerIsBetter bool) {
+		bound, verb := "threshold", "below"
+		if lowerIsBetter {
+			bound, verb = "maximum", "above"
+		}
+		worse := func(a, b float64) bool {
+			if lowerIsBetter {
+				return a > b
+			}
+			return a < b
+		}
+
+		criteria := fmt.Sprintf("%s %.1f%%", bound, limit)
+		failed := measured && worse(value, limit)
+		failure := fmt.Sprintf("%s %.1f%% is %s %.1f%%", strings.ToLower(name), value, verb, limit)
+		if g.Baseline != nil && baseMeasured {
+			criteria += fmt.Sprintf(", baseline %.1f%%", baseValue)
+			failed = failed && worse(value, baseValue)
+			failure += fmt.Sprintf(" and baseline %.1f%%", baseValue)
+		}
+		switch {
+		case !measured:
+			lines = append(lines, fmt.Sprintf("- %s: not measured (%s)", name, criteria))
+		case failed:
+			failures = append(failures, failure)
+			lines = append(lines, fmt.Sprintf("- %s: **%.1f%%** ❌ (%s)", name, value, criteria))
+		default:
+			lines = append(lines, fmt.Sprintf("- %s: **%.1f%%** ✅ (%s)", name, value, criteria))
+		}
+	}
+	if g.MinCoverage > 0 {
+		check("Coverage", report.CoverageMeasured, report.Coverage, g.MinCoverage, base.CoverageMeasured, base.Coverage, false)
+	}
+	if g.MinMutationScore > 0 {
+		check("Mutation score", report.MutationMeasured, report.MutationScore, g.MinMutationScore, base.MutationMeasured, base.MutationScore, false)
+	}
+	if g.CheckFlakeRate {
+		check("Flake rate", report.FlakeMeasured, report.FlakeRate, g.MaxFlakeRate, base.FlakeMeasured, base.FlakeRate, true)
+	}
+
+	conclusion := ConclusionSuccess
+	title := "Quality gate passed"
+	switch {
+	case len(failures) > 0:
+		conclusion = ConclusionFailure
+		title = "Quality gate failed: " + strings.Join(failures, ", ")
+	case len(lines) == 0:
+		conclusion = ConclusionNeutral
+		title = "No quality thresholds set"
+	}
+
+	// GitHub rejects a check run without a summary
+	summary := "No coverage, mutation score or flake rate thresholds are configured."
+	if len(lines) > 0 {
+		summary = strings.Join(lines, "\n") + "\n"
+	}
+
+	output := CheckOutput{
+		Title:       title,
+		Summary:     summary,
+		Text:        fileQualityTable(report.Files),
+		Annotations: g.annotations(report.Files),
+	}
+	return conclusion, output
+}
+
+// annotations marks the files below the gate's thresholds, then their
+// uncovered lines and surviving mutants, up to maxAnnotations. A ratcheting
+// gate only warns about files below a threshold, since they needn't fail it.
+func (g QualityGate) annotations(files []FileQuality) []CheckAnnotation {
+	belowLevel := AnnotationFailure
+	if g.Baseline != nil {
+		belowLevel = AnnotationWarning
+	}
+
+	sorted := make([]FileQuality, len(files))
+	copy(sorted, files)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Path < sorted[j].Path })
+
+	var failing, details []CheckAnnotation
+	for _, f := range sorted {
+		if g.MinCoverage > 0 && f.CoverageMeasured && f.Coverage < g.MinCoverage {
+			failing = append(failing, CheckAnnotation{
+				Path: f.Path, StartLine: 1, EndLine: 1, Level: belowLevel,
+				Title:   "Coverage below threshold",
+				Message: fmt.Sprintf("Coverage %.1f%% is below %.1f%%", f.Coverage, g.MinCoverage),
+			})
+			for _, r := range lineRanges(f.UncoveredLines) {
+				details = append(details, CheckAnnotation{
+					Path: f.Path, StartLine: r[0], EndLine: r[1], Level: AnnotationWarning,
+					Title:   "Not covered",
+					Message: "No test runs these lines",
+				})
+			}
+		}
+		if g.MinMutationScore > 0 && f.MutationMeasured && f.MutationScore < g.MinMutationScore {
+			failing = append(failing, CheckAnnotation{
+				Path: f.Path, StartLine: 1, EndLine: 1, Level: belowLevel,
+				Title:   "Mutation score below threshold",
+				Message: fmt.Sprintf("Mutation score %.1f%% is below %.1f%%", f.MutationScore, g.MinMutationScore),
+			})
+		}
+		for _, s := range f.Survivors {
+			if s.Line <= 0 {
+				continue
+			}
+			details = append(details, CheckAnnotation{
+				Path: f.Path, StartLine: s.Line, EndLine: s.Line, Level: AnnotationWarning,
+				Title:   "Surviving mutant",
+				Message: s.Body,
+			})
+		}
+	}
+
+	annotations := append(failing, details...)
+	if len(annotations) > maxAnnotations {
+		annotations = annotations[:maxAnnotations]
+	}
+	return annotations
+}
+
+// fileQualityTable lists each file's measurements as a markdown table
+func fileQualityTable(files []FileQuality) string {
+	if len(files) == 0 {
+		return ""
+	}
+	sorted := make([]FileQuality, len(files))
+	copy(sorted, files)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Path < sorted[j].Path })
+
+	percent := func(measured bool, value float64) string {
+		if !measured {
+			return "-"
+		}
+		return fmt.Sprintf("%.1f%%", value)
+	}
+
+	var sb strings.Builder
+	sb.WriteString("| File | Coverage | Mutation score | Surviving mutants |\n")
+	sb.WriteString("|------|----------|----------------|-------------------|\n")
+	for _, f := range sorted {
+		sb.WriteString(fmt.Sprintf("| `%s` | %s | %s | %d |\n", f.Path,
+			percent(f.CoverageMeasured, f.Coverage), percent(f.MutationMeasured, f.MutationScore), len(f.Survivors)))
+	}
+	return sb.String()
+}
+
+// lineRanges groups line numbers into runs of consecutive lines
+func lineRanges(lines []int) [][2]int {
+	sorted := make([]int, len(lines))
+	copy(sorted, lines)
+	sort.Ints(sorted)
+
+	var ranges [][2]int
+	for _, l := range sorted {
+		if n := len(ranges); n > 0 && ranges[n-1][1]+1 >= l {
+			ranges[n-1][1] = l
+			continue
+		}
+		ranges = append(ranges, [2]int{l, l})
+	}
+	return ranges
+}
+
+// PublishQualityGate judges a report against the gate and publishes the
+// result as the QTest Quality Gate check run on a commit
+func (s *PRService) PublishQualityGate(ctx context.Context, owner, repo, headSHA, detailsURL string, gate QualityGate, report QualityReport) (*CheckRunResponse, error) {
+	conclusion, output := gate.Evaluate(report)
+	return s.CreateCheckRun(ctx, owner, repo, CheckRun{
+		Name:       QualityGateName,
+		HeadSHA:    headSHA,
+		DetailsURL: detailsURL,
+		Conclusion: conclusion,
+		Output:     output,
+	})
+}
@@ -0,0 +1,177 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestQualityGate_Evaluate(t *testing.T) {
+	report := QualityReport{
+		Coverage:         72,
+		CoverageMeasured: true,
+		MutationScore:    80,
+		MutationMeasured: true,
+		Files: []FileQuality{
+			{Path: "b.go", Coverage: 90, CoverageMeasured: true},
+			{
+				Path: "a.go", Coverage: 40, CoverageMeasured: true, UncoveredLines: []int{7, 3, 4, 5},
+				MutationScore: 50, MutationMeasured: true,
+				Survivors: []ReviewComment{{Path: "a.go", Line: 12, Body: "comparison: < -> <="}},
+			},
+		},
+	}
+
+	conclusion, output := QualityGate{MinCoverage: 80, MinMutationScore: 60}.Evaluate(report)
+	if conclusion != ConclusionFailure {
+		t.Errorf("conclusion = %s, want failure", conclusion)
+	}
+	if output.Title != "Quality gate failed: coverage 72.0% is below 80.0%" {
+		t.Errorf("title = %q", output.Title)
+	}
+	if !strings.Contains(output.Summary, "Mutation score: **80.0%** ✅") {
+		t.Errorf("summary = %q", output.Summary)
+	}
+	if !strings.Contains(output.Text, "| `a.go` | 40.0% | 50.0% | 1 |") {
+		t.Errorf("text = %q", output.Text)
+	}
+
+	var got []string
+	for _, a := range output.Annotations {
+		got = append(got, fmt.Sprintf("%s:%d-%d %s", a.Path, a.StartLine, a.EndLine, a.Level))
+	}
+	want := []string{"a.go:1-1 failure", "a.go:1-1 failure", "a.go:3-5 warning", "a.go:7-7 warning", "a.go:12-12 warning"}
+	if strings.Join(got, ", ") != strings.Join(want, ", ") {
+		t.Errorf("annotations = %v, want %v", got, want)
+	}
+}
+
+func TestQualityGate_Evaluate_Unmeasured(t *testing.T) {
+	report := QualityReport{MutationScore: 75, MutationMeasured: true}
+
+	conclusion, output := QualityGate{MinCoverage: 80, MinMutationScore: 60}.Evaluate(report)
+	if conclusion != ConclusionSuccess {
+		t.Errorf("conclusion = %s, want success when only an unmeasured metric is gated", conclusion)
+	}
+	if !strings.Contains(output.Summary, "Coverage: not measured") {
+		t.Errorf("summary = %q", output.Summary)
+	}
+
+	if conclusion, output := (QualityGate{}).Evaluate(report); conclusion != ConclusionNeutral || output.Summary == "" {
+		t.Errorf("no thresholds = %s, %q, want neutral with a summary", conclusion, output.Summary)
+	}
+}
+
+func TestPRService_CreateCheckRun_BatchesAnnotations(t *testing.T) {
+	var requests []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		body["method"] = r.Method
+		body["path"] = r.URL.Path
+		requests = append(requests, body)
+
+		if r.Method == "POST" {
+			w.WriteHeader(201)
+			w.Write([]byte(`{"id":99,"status":"in_progress"}`))
+			return
+		}
+		w.Write([]byte(`{"id":99,"status":"completed","conclusion":"failure","html_url":"https://github.com/o/r/runs/99"}`))
+	}))
+	defer server.Close()
+
+	svc := NewPRService("test-token")
+	svc.baseURL = server.URL
+
+	annotations := make([]CheckAnnotation, 120)
+	for i := range annotations {
+		annotations[i] = CheckAnnotation{Path: "a.go", StartLine: i + 1, EndLine: i + 1, Level: AnnotationWarning, Message: "x"}
+	}
+	run, err := svc.CreateCheckRun(context.Background(), "o", "r", CheckRun{
+		Name:       QualityGateName,
+		HeadSHA:    "abc123",
+		Conclusion: ConclusionFailure,
+		Output:     CheckOutput{Title: "t", Summary: "s", Annotations: annotations},
+	})
+	if err != nil {
+		t.Fatalf("CreateCheckRun() error = %v", err)
+	}
+	if run.Conclusion != ConclusionFailure || run.HTMLURL == "" {
+		t.Errorf("run = %+v", run)
+	}
+
+	if len(requests) != 3 {
+		t.Fatalf("requests = %d, want a create and two updates", len(requests))
+	}
+	if requests[0]["path"] != "/repos/o/r/check-runs" || requests[0]["status"] != "in_progress" || requests[0]["head_sha"] != "abc123" {
+		t.Errorf("create = %v", requests[0])
+	}
+	if requests[1]["path"] != "/repos/o/r/check-runs/99" || requests[1]["conclusion"] != nil {
+		t.Errorf("first update = %v", requests[1])
+	}
+	last := requests[2]
+	output, _ := last["output"].(map[string]interface{})
+	if last["conclusion"] != ConclusionFailure || len(output["annotations"].([]interface{})) != 20 {
+		t.Errorf("last update = %v", last)
+	}
+}
+
+func TestQualityGate_Evaluate_FlakeRate(t *testing.T) {
+	gate := QualityGate{MaxFlakeRate: 0, CheckFlakeRate: true}
+
+	conclusion, output := gate.Evaluate(QualityReport{FlakeRate: 2.5, FlakeMeasured: true})
+	if conclusion != ConclusionFailure || output.Title != "Quality gate failed: flake rate 2.5% is above 0.0%" {
+		t.Errorf("flaky = %s, %q, want failure", conclusion, output.Title)
+	}
+
+	conclusion, output = gate.Evaluate(QualityReport{FlakeMeasured: true})
+	if conclusion != ConclusionSuccess || !strings.Contains(output.Summary, "Flake rate: **0.0%** ✅") {
+		t.Errorf("stable = %s, %q, want success", conclusion, output.Summary)
+	}
+
+	if conclusion, _ := (QualityGate{}).Evaluate(QualityReport{FlakeRate: 50, FlakeMeasured: true}); conclusion != ConclusionNeutral {
+		t.Errorf("unchecked flake rate = %s, want neutral", conclusion)
+	}
+}
+
+func TestQualityGate_Evaluate_Ratchet(t *testing.T) {
+	baseline := &QualityReport{Coverage: 60, CoverageMeasured: true, FlakeRate: 4, FlakeMeasured: true}
+	gate := QualityGate{MinCoverage: 80, MinMutationScore: 70, MaxFlakeRate: 1, CheckFlakeRate: true, Baseline: baseline}
+
+	// Short of every threshold, but no worse than the baseline; mutation
+	// score has no baseline, so its threshold applies
+	report := QualityReport{
+		Coverage: 61, CoverageMeasured: true,
+		MutationScore: 75, MutationMeasured: true,
+		FlakeRate: 4, FlakeMeasured: true,
+		Files: []FileQuality{{Path: "a.go", Coverage: 50, CoverageMeasured: true}},
+	}
+	conclusion, output := gate.Evaluate(report)
+	if conclusion != ConclusionSuccess {
+		t.Errorf("conclusion = %s (%s), want success without a regression", conclusion, output.Title)
+	}
+	if !strings.Contains(output.Summary, "Coverage: **61.0%** ✅ (threshold 80.0%, baseline 60.0%)") {
+		t.Errorf("summary = %q", output.Summary)
+	}
+	if len(output.Annotations) != 1 || output.Annotations[0].Level != AnnotationWarning {
+		t.Errorf("annotations = %+v, want a warning for a.go", output.Annotations)
+	}
+
+	report.Coverage = 59
+	report.FlakeRate = 5
+	conclusion, output = gate.Evaluate(report)
+	want := "Quality gate failed: coverage 59.0% is below 80.0% and baseline 60.0%, flake rate 5.0% is above 1.0% and baseline 4.0%"
+	if conclusion != ConclusionFailure || output.Title != want {
+		t.Errorf("regression = %s, %q, want %q", conclusion, output.Title, want)
+	}
+
+	// Meeting the threshold passes even below the baseline
+	gate.Baseline = &QualityReport{Coverage: 95, CoverageMeasured: true}
+	if conclusion, _ := gate.Evaluate(QualityReport{Coverage: 85, CoverageMeasured: true}); conclusion != ConclusionSuccess {
+		t.Errorf("above threshold = %s, want success", conclusion)
+	}
+}
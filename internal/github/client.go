@@ -0,0 +1,442 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Retry and pacing defaults, following GitHub's REST API best practices
+const (
+	defaultMaxRetries       = 5
+	defaultInitialBackoff   = 1 * time.Second
+	defaultMaxBackoff       = 30 * time.Second
+	defaultSecondaryWait    = 60 * time.Second // when a secondary limit gives no hint
+	defaultMaxWait          = 15 * time.Minute // longest reset worth waiting for
+	defaultMinWriteInterval = 1 * time.Second  // spacing between mutating requests
+	defaultAttemptTimeout   = 30 * time.Second
+)
+
+// ErrRateLimited is returned when the rate limit resets later than the
+// transport is willing to wait
+var ErrRateLimited = errors.New("GitHub rate limit exceeded")
+
+// RateLimit is the primary rate limit state reported by the last response
+type RateLimit struct {
+	Limit     int       `json:"limit"`
+	Remaining int       `json:"remaining"`
+	Reset     time.Time `json:"reset"`
+	Resource  string    `json:"resource,omitempty"`
+}
+
+// RateLimitStats are the request budget metrics of a transport
+type RateLimitStats struct {
+	Requests         int64         `json:"requests"`          // attempts sent, including retries
+	Retries          int64         `json:"retries"`           // attempts after the first
+	PrimaryLimited   int64         `json:"primary_limited"`   // responses over the hourly budget
+	SecondaryLimited int64         `json:"secondary_limited"` // secondary (abuse) limit responses
+	ServerErrors     int64         `json:"server_errors"`     // 5xx responses
+	Waited           time.Duration `json:"waited"`            // time spent in backoff and rate limit waits
+	RateLimit        RateLimit     `json:"rate_limit"`        // most recent primary limit
+}
+
+// RateLimitTransport is an http.RoundTripper for the GitHub API. It retries
+// server errors with exponential backoff, waits out primary and secondary
+// rate limits instead of failing, and spaces out mutating requests, which
+// is what trips secondary limits during large multi-file commits. Limits
+// are tracked per host and token, so a shared transport serves several
+// tokens.
+type RateLimitTransport struct {
+	Base             http.RoundTripper
+	MaxRetries       int
+	InitialBackoff   time.Duration
+	MaxBackoff       time.Duration
+	MaxWait          time.Duration
+	MinWriteInterval time.Duration
+	AttemptTimeout   time.Duration
+
+	mu     sync.Mutex
+	states map[uint64]*limitState
+	stats  RateLimitStats
+
+	// now and sleep are replaceable in tests
+	now   func() time.Time
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// limitState is what the transport knows about one host and token
+type limitState struct {
+	blockedUntil time.Time // no requests before this (limit exhausted)
+	lastWrite    time.Time
+}
+
+// NewRateLimitTransport creates a transport with GitHub's recommended
+// defaults. A nil base uses http.DefaultTransport.
+func NewRateLimitTransport(base http.RoundTripper) *RateLimitTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &RateLimitTransport{
+		Base:             base,
+		MaxRetries:       defaultMaxRetries,
+		InitialBackoff:   defaultInitialBackoff,
+		MaxBackoff:       defaultMaxBackoff,
+		MaxWait:          defaultMaxWait,
+		MinWriteInterval: defaultMinWriteInterval,
+		AttemptTimeout:   defaultAttemptTimeout,
+		states:           make(map[uint64]*limitState),
+		now:              time.Now,
+		sleep:            sleepContext,
+	}
+}
+
+var (
+	sharedTransport     *RateLimitTransport
+	sharedETags         *ETagTransport
+	sharedTransportOnce sync.Once
+)
+
+// SharedTransport returns the process-wide GitHub transport, so every client
+// draws on one view of the rate limit
+func SharedTransport() *RateLimitTransport {
+	sharedTransportOnce.Do(func() {
+		sharedTransport = NewRateLimitTransport(nil)
+		sharedETags = NewETagTransport(sharedTransport)
+	})
+	return sharedTransport
+}
+
+// NewHTTPClient returns a client for the GitHub API using the shared
+// transports: conditional GETs in front of rate limiting. It has no overall
+// timeout since rate limit waits can be long; each attempt is bounded by the
+// transport's AttemptTimeout instead.
+func NewHTTPClient() *http.Client {
+	SharedTransport()
+	return &http.Client{Transport: sharedETags}
+}
+
+// NextPageURL returns the rel="next" URL of a paginated response's Link
+// header, or "" on the last page
+func NextPageURL(h http.Header) string {
+	for _, link := range strings.Split(h.Get("Link"), ",") {
+		parts := strings.Split(link, ";")
+		if len(parts) < 2 {
+			continue
+		}
+		for _, param := range parts[1:] {
+			if strings.TrimSpace(param) == `rel="next"` {
+				return strings.Trim(strings.TrimSpace(parts[0]), "<>")
+			}
+		}
+	}
+	return ""
+}
+
+// Stats returns the transport's request budget metrics
+func (t *RateLimitTransport) Stats() RateLimitStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.stats
+}
+
+// RoundTrip sends a request, retrying and waiting as needed
+func (t *RateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	key := stateKey(req)
+	write := req.Method != http.MethodGet && req.Method != http.MethodHead
+	idempotent := req.Method != http.MethodPost && req.Method != http.MethodPatch
+
+	// Requests with a body can only be retried if it can be replayed
+	maxRetries := t.MaxRetries
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		maxRetries = 0
+	}
+
+	backoff := t.InitialBackoff
+	secondaryWait := defaultSecondaryWait
+	for attempt := 0; ; attempt++ {
+		if err := t.waitTurn(ctx, key, write); err != nil {
+			return nil, err
+		}
+
+		resp, err := t.send(req, attempt)
+		if err != nil {
+			// A failed POST may have been applied, so only retry idempotent requests
+			if ctx.Err() != nil || !idempotent || attempt >= maxRetries {
+				return nil, err
+			}
+			log.Debug().Err(err).Str("url", req.URL.Path).Int("attempt", attempt+1).Msg("GitHub request failed, retrying")
+			if err := t.wait(ctx, backoff); err != nil {
+				return nil, err
+			}
+			backoff = t.nextBackoff(backoff)
+			continue
+		}
+
+		wait, retry := t.classify(resp, key, idempotent, &backoff, &secondaryWait)
+		if !retry || attempt >= maxRetries {
+			return resp, nil
+		}
+		if wait > t.MaxWait {
+			resp.Body.Close()
+			return nil, fmt.Errorf("%w: resets in %s", ErrRateLimited, wait.Round(time.Second))
+		}
+
+		log.Warn().
+			Int("status", resp.StatusCode).
+			Str("url", req.URL.Path).
+			Int("attempt", attempt+1).
+			Dur("wait", wait).
+			Msg("GitHub request limited, retrying")
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+
+		if err := t.wait(ctx, wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// send makes one attempt, bounded by AttemptTimeout until its body is closed
+func (t *RateLimitTransport) send(req *http.Request, attempt int) (*http.Response, error) {
+	ctx, cancel := context.WithTimeout(req.Context(), t.AttemptTimeout)
+	r := req.Clone(ctx)
+	if attempt > 0 && req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		r.Body = body
+	}
+
+	t.mu.Lock()
+	t.stats.Requests++
+	if attempt > 0 {
+		t.stats.Retries++
+	}
+	t.mu.Unlock()
+
+	resp, err := t.Base.RoundTrip(r)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// classify records a response's rate limit headers and decides whether to
+// retry it and after how long. Rate limited requests weren't applied and are
+// always retried; server errors only for idempotent requests.
+func (t *RateLimitTransport) classify(resp *http.Response, key uint64, idempotent bool, backoff, secondaryWait *time.Duration) (time.Duration, bool) {
+	limit, hasLimit := parseRateLimit(resp.Header)
+	retryAfter := parseRetryAfter(resp.Header)
+	limited := resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests
+	primary := hasLimit && limit.Remaining == 0
+
+	// Reading the body can be slow; other requests mustn't wait on it
+	secondary := limited && retryAfter <= 0 && !primary && isSecondaryLimit(resp)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if hasLimit {
+		t.stats.RateLimit = limit
+		if primary {
+			// Budget spent: hold further requests until it resets
+			t.block(key, limit.Reset)
+		}
+	}
+
+	switch {
+	case resp.StatusCode >= 500:
+		t.stats.ServerErrors++
+		if !idempotent || resp.StatusCode == http.StatusNotImplemented {
+			return 0, false
+		}
+		wait := *backoff
+		*backoff = t.nextBackoff(*backoff)
+		return wait, true
+
+	case !limited:
+		return 0, false
+
+	case retryAfter > 0:
+		// Secondary limit with an explicit wait
+		t.stats.SecondaryLimited++
+		t.block(key, t.now().Add(retryAfter))
+		return retryAfter, true
+
+	case primary:
+		t.stats.PrimaryLimited++
+		wait := limit.Reset.Sub(t.now())
+		if wait < time.Second {
+			wait = time.Second
+		}
+		return wait, true
+
+	case secondary:
+		// No hint: wait at least a minute, longer on repeats
+		t.stats.SecondaryLimited++
+		wait := *secondaryWait
+		*secondaryWait *= 2
+		t.block(key, t.now().Add(wait))
+		return wait, true
+	}
+
+	// A plain 403: permissions, not limits
+	return 0, false
+}
+
+// waitTurn holds a request while its host and token are blocked, and spaces
+// mutating requests MinWriteInterval apart
+func (t *RateLimitTransport) waitTurn(ctx context.Context, key uint64, write bool) error {
+	t.mu.Lock()
+	state := t.state(key)
+	now := t.now()
+	until := state.blockedUntil
+	if write {
+		if next := state.lastWrite.Add(t.MinWriteInterval); next.After(until) {
+			until = next
+		}
+	}
+	wait := until.Sub(now)
+	if write {
+		// Reserve the slot before releasing the lock
+		start := now
+		if wait > 0 {
+			start = until
+		}
+		state.lastWrite = start
+	}
+	t.mu.Unlock()
+
+	if wait <= 0 {
+		return nil
+	}
+	if wait > t.MaxWait {
+		return fmt.Errorf("%w: resets in %s", ErrRateLimited, wait.Round(time.Second))
+	}
+	return t.wait(ctx, wait)
+}
+
+func (t *RateLimitTransport) wait(ctx context.Context, d time.Duration) error {
+	t.mu.Lock()
+	t.stats.Waited += d
+	t.mu.Unlock()
+	return t.sleep(ctx, d)
+}
+
+func (t *RateLimitTransport) nextBackoff(d time.Duration) time.Duration {
+	d *= 2
+	if d > t.MaxBackoff {
+		d = t.MaxBackoff
+	}
+	return d
+}
+
+// block stops requests for a key until a time; callers hold t.mu
+func (t *RateLimitTransport) block(key uint64, until time.Time) {
+	state := t.state(key)
+	if until.After(state.blockedUntil) {
+		state.blockedUntil = until
+	}
+}
+
+// state returns the state for a key; callers hold t.mu
+func (t *RateLimitTransport) state(key uint64) *limitState {
+	if t.states == nil {
+		t.states = make(map[uint64]*limitState)
+	}
+	s, ok := t.states[key]
+	if !ok {
+		s = &limitState{}
+		t.states[key] = s
+	}
+	return s
+}
+
+// stateKey identifies a host and token without keeping the token around
+func stateKey(req *http.Request) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(req.URL.Host))
+	h.Write([]byte{0})
+	h.Write([]byte(req.Header.Get("Authorization")))
+	return h.Sum64()
+}
+
+func parseRateLimit(h http.Header) (RateLimit, bool) {
+	remaining, err := strconv.Atoi(h.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return RateLimit{}, false
+	}
+	limit, _ := strconv.Atoi(h.Get("X-RateLimit-Limit"))
+	rl := RateLimit{
+		Limit:     limit,
+		Remaining: remaining,
+		Resource:  h.Get("X-RateLimit-Resource"),
+	}
+	if reset, err := strconv.ParseInt(h.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		rl.Reset = time.Unix(reset, 0)
+	}
+	return rl, true
+}
+
+func parseRetryAfter(h http.Header) time.Duration {
+	secs, err := strconv.Atoi(h.Get("Retry-After"))
+	if err != nil || secs < 0 {
+		return 0
+	}
+	return time.Duration(secs) * time.Second
+}
+
+// isSecondaryLimit checks a 403/429 body for GitHub's secondary limit
+// message. The body is restored for the caller.
+func isSecondaryLimit(resp *http.Response) bool {
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(data), resp.Body), resp.Body}
+	if err != nil {
+		return false
+	}
+	msg := strings.ToLower(string(data))
+	return strings.Contains(msg, "secondary rate limit") || strings.Contains(msg, "abuse detection")
+}
+
+// cancelBody releases an attempt's context when its body is closed
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
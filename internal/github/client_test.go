@@ -0,0 +1,238 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeClockTransport returns a transport whose sleeps advance a fake clock
+// instead of blocking, and the list of waits it made
+func fakeClockTransport() (*RateLimitTransport, *[]time.Duration) {
+	clock := time.Unix(1_700_000_000, 0)
+	var waits []time.Duration
+
+	rt := NewRateLimitTransport(nil)
+	rt.now = func() time.Time { return clock }
+	rt.sleep = func(_ context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		clock = clock.Add(d)
+		return nil
+	}
+	return rt, &waits
+}
+
+// sequenceServer replies with the given handlers in order, repeating the last
+func sequenceServer(t *testing.T, handlers ...http.HandlerFunc) (*httptest.Server, *int) {
+	t.Helper()
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h := handlers[len(handlers)-1]
+		if calls < len(handlers) {
+			h = handlers[calls]
+		}
+		calls++
+		h(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func ok(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("X-RateLimit-Limit", "5000")
+	w.Header().Set("X-RateLimit-Remaining", "4999")
+	w.WriteHeader(http.StatusOK)
+}
+
+func TestRateLimitTransport_SecondaryLimit(t *testing.T) {
+	tests := []struct {
+		name     string
+		limited  http.HandlerFunc
+		wantWait time.Duration
+	}{
+		{"retry-after header", func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Retry-After", "7")
+			w.WriteHeader(http.StatusForbidden)
+		}, 7 * time.Second},
+		{"message only", func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"message":"You have exceeded a secondary rate limit."}`))
+		}, time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, calls := sequenceServer(t, tt.limited, ok)
+			rt, waits := fakeClockTransport()
+			client := &http.Client{Transport: rt}
+
+			resp, err := client.Get(server.URL + "/repos/o/r")
+			if err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != http.StatusOK || *calls != 2 {
+				t.Errorf("status = %d after %d calls, want 200 after 2", resp.StatusCode, *calls)
+			}
+			if len(*waits) != 1 || (*waits)[0] != tt.wantWait {
+				t.Errorf("waits = %v, want [%v]", *waits, tt.wantWait)
+			}
+			stats := rt.Stats()
+			if stats.SecondaryLimited != 1 || stats.Retries != 1 || stats.Requests != 2 {
+				t.Errorf("stats = %+v", stats)
+			}
+		})
+	}
+}
+
+func TestRateLimitTransport_PrimaryLimit(t *testing.T) {
+	rt, waits := fakeClockTransport()
+	reset := rt.now().Add(90 * time.Second)
+
+	exhausted := func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+		w.WriteHeader(http.StatusOK)
+	}
+	server, calls := sequenceServer(t, exhausted, ok)
+	client := &http.Client{Transport: rt}
+
+	// The last request of the budget succeeds...
+	resp, err := client.Get(server.URL + "/a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	// ...and the next one waits for the reset before going out
+	resp, err = client.Get(server.URL + "/b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if *calls != 2 || len(*waits) != 1 || (*waits)[0] != 90*time.Second {
+		t.Errorf("calls = %d, waits = %v, want 2 calls and a 90s wait", *calls, *waits)
+	}
+	if got := rt.Stats().RateLimit.Remaining; got != 4999 {
+		t.Errorf("RateLimit.Remaining = %d, want 4999", got)
+	}
+}
+
+func TestRateLimitTransport_ResetTooFar(t *testing.T) {
+	rt, _ := fakeClockTransport()
+	rt.MaxWait = time.Minute
+	reset := rt.now().Add(time.Hour)
+
+	server, calls := sequenceServer(t, func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+		w.WriteHeader(http.StatusForbidden)
+	})
+
+	_, err := (&http.Client{Transport: rt}).Get(server.URL)
+	if !errors.Is(err, ErrRateLimited) {
+		t.Errorf("error = %v, want ErrRateLimited", err)
+	}
+	if *calls != 1 {
+		t.Errorf("calls = %d, want 1", *calls)
+	}
+}
+
+func TestRateLimitTransport_ServerErrors(t *testing.T) {
+	badGateway := func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusBadGateway) }
+
+	t.Run("idempotent retried with backoff", func(t *testing.T) {
+		server, calls := sequenceServer(t, badGateway, badGateway, ok)
+		rt, waits := fakeClockTransport()
+
+		resp, err := (&http.Client{Transport: rt}).Get(server.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK || *calls != 3 {
+			t.Errorf("status = %d after %d calls", resp.StatusCode, *calls)
+		}
+		if len(*waits) != 2 || (*waits)[0] != time.Second || (*waits)[1] != 2*time.Second {
+			t.Errorf("waits = %v, want [1s 2s]", *waits)
+		}
+	})
+
+	t.Run("post not retried", func(t *testing.T) {
+		server, calls := sequenceServer(t, badGateway, ok)
+		rt, _ := fakeClockTransport()
+
+		resp, err := (&http.Client{Transport: rt}).Post(server.URL, "application/json", strings.NewReader("{}"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusBadGateway || *calls != 1 {
+			t.Errorf("status = %d after %d calls, want 502 after 1", resp.StatusCode, *calls)
+		}
+	})
+}
+
+func TestRateLimitTransport_PlainForbidden(t *testing.T) {
+	server, calls := sequenceServer(t, func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"message":"Resource not accessible by integration"}`))
+	})
+	rt, _ := fakeClockTransport()
+
+	resp, err := (&http.Client{Transport: rt}).Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if *calls != 1 || !strings.Contains(string(body), "not accessible") {
+		t.Errorf("calls = %d, body = %q; want one call with the body intact", *calls, body)
+	}
+}
+
+func TestRateLimitTransport_ReplaysBodyAndPacesWrites(t *testing.T) {
+	var bodies []string
+	record := func(status int) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			data, _ := io.ReadAll(r.Body)
+			bodies = append(bodies, string(data))
+			if status == http.StatusTooManyRequests {
+				w.Header().Set("Retry-After", "2")
+			}
+			w.WriteHeader(status)
+		}
+	}
+	server, _ := sequenceServer(t, record(http.StatusTooManyRequests), record(http.StatusCreated))
+	rt, waits := fakeClockTransport()
+	client := &http.Client{Transport: rt}
+
+	for _, path := range []string{"/a", "/b"} {
+		req, _ := http.NewRequest(http.MethodPut, server.URL+path, strings.NewReader(`{"content":"x"}`))
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+
+	if len(bodies) != 3 || bodies[1] != `{"content":"x"}` {
+		t.Errorf("bodies = %q, want the body replayed on retry", bodies)
+	}
+	// Retry-After, then the write interval before the second PUT
+	if len(*waits) != 2 || (*waits)[0] != 2*time.Second || (*waits)[1] != time.Second {
+		t.Errorf("waits = %v, want [2s 1s]", *waits)
+	}
+}
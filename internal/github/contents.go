@@ -0,0 +1,268 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// maxSiblingFiles caps the source files fetched from a remote file's
+// directory
+const maxSiblingFiles = 20
+
+// contextFiles are root files fetched with a remote source file, so its
+// tests get the module path, dependencies and compiler settings
+var contextFiles = []string{
+	"go.mod", "go.sum",
+	"package.json", "tsconfig.json",
+	"pyproject.toml", "setup.py", "requirements.txt",
+	"Cargo.toml",
+}
+
+// errNotFound is returned for a file the repository doesn't have
+var errNotFound = errors.New("not found")
+
+// RemoteFile is a source file in a GitHub repository, or at a raw URL
+type RemoteFile struct {
+	Owner string
+	Repo  string
+	Path  string // relative to the repository root
+	Ref   string // branch, tag or commit; "" for the default branch
+	URL   string // raw URL of a file outside GitHub, which has no Owner or Repo
+}
+
+// ParseRemoteFile parses owner/repo:path@ref (the ref is optional), a
+// github.com file URL or any other http(s) URL. It returns nil for anything
+// else, such as a local path.
+func ParseRemoteFile(spec string) (*RemoteFile, error) {
+	if strings.HasPrefix(spec, "https://") || strings.HasPrefix(spec, "http://") {
+		return parseFileURL(spec)
+	}
+
+	repo, rest, ok := strings.Cut(spec, ":")
+	owner, name, _ := strings.Cut(repo, "/")
+	if !ok || owner == "" || name == "" || strings.ContainsAny(name, "/\\") {
+		return nil, nil
+	}
+	filePath, ref := rest, ""
+	if i := strings.LastIndex(rest, "@"); i >= 0 {
+		filePath, ref = rest[:i], rest[i+1:]
+	}
+	filePath = strings.Trim(filePath, "/")
+	if filePath == "" {
+		return nil, fmt.Errorf("no file path in %s", spec)
+	}
+	return &RemoteFile{Owner: owner, Repo: name, Path: filePath, Ref: ref}, nil
+}
+
+// parseFileURL parses a github.com blob or raw.githubusercontent.com URL
+// into its repository, ref and path. Other URLs are fetched as they are.
+func parseFileURL(rawURL string) (*RemoteFile, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse URL: %w", err)
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+
+	switch u.Host {
+	case "github.com":
+		// owner/repo/blob/ref/path...
+		if len(parts) < 5 || (parts[2] != "blob" && parts[2] != "raw") {
+			return nil, fmt.Errorf("not a link to a file: %s", rawURL)
+		}
+		return &RemoteFile{Owner: parts[0], Repo: parts[1], Ref: parts[3], Path: strings.Join(parts[4:], "/")}, nil
+	case "raw.githubusercontent.com":
+		// owner/repo/ref/path...
+		if len(parts) < 4 {
+			return nil, fmt.Errorf("not a link to a file: %s", rawURL)
+		}
+		return &RemoteFile{Owner: parts[0], Repo: parts[1], Ref: parts[2], Path: strings.Join(parts[3:], "/")}, nil
+	}
+
+	name := path.Base(u.Path)
+	if name == "." || name == "/" {
+		return nil, fmt.Errorf("not a link to a file: %s", rawURL)
+	}
+	return &RemoteFile{Path: name, URL: rawURL}, nil
+}
+
+// String returns the file in the form ParseRemoteFile accepts
+func (f *RemoteFile) String() string {
+	if f.URL != "" {
+		return f.URL
+	}
+	s := fmt.Sprintf("%s/%s:%s", f.Owner, f.Repo, f.Path)
+	if f.Ref != "" {
+		s += "@" + f.Ref
+	}
+	return s
+}
+
+// FetchRemoteFile downloads a remote source file into dir, at its path in
+// the repository, and returns the local path. A file from GitHub comes with
+// minimal context through the contents API, without cloning: the root
+// manifests (go.mod, package.json, ...) and up to maxSiblingFiles other
+// source files from its directory.
+func (s *PRService) FetchRemoteFile(ctx context.Context, f *RemoteFile, dir string) (string, error) {
+	if f.URL != "" {
+		// Not GitHub: the token mustn't go to another host
+		data, err := s.get(ctx, f.URL, "")
+		if err != nil {
+			return "", fmt.Errorf("failed to fetch %s: %w", f.URL, err)
+		}
+		return writeFetched(dir, f.Path, data)
+	}
+
+	data, err := s.GetFileContent(ctx, f.Owner, f.Repo, f.Path, f.Ref)
+	if err != nil {
+		return "", err
+	}
+	local, err := writeFetched(dir, f.Path, data)
+	if err != nil {
+		return "", err
+	}
+
+	for _, name := range contextFiles {
+		data, err := s.GetFileContent(ctx, f.Owner, f.Repo, name, f.Ref)
+		if errors.Is(err, errNotFound) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		if _, err := writeFetched(dir, name, data); err != nil {
+			return "", err
+		}
+	}
+
+	siblings, err := s.siblingFiles(ctx, f)
+	if err != nil {
+		return "", err
+	}
+	for _, sibling := range siblings {
+		data, err := s.GetFileContent(ctx, f.Owner, f.Repo, sibling, f.Ref)
+		if err != nil {
+			return "", err
+		}
+		if _, err := writeFetched(dir, sibling, data); err != nil {
+			return "", err
+		}
+	}
+	return local, nil
+}
+
+// GetFileContent returns the content of a file in a repository at ref, or
+// at the default branch when ref is empty
+func (s *PRService) GetFileContent(ctx context.Context, owner, repo, filePath, ref string) ([]byte, error) {
+	u := fmt.Sprintf("%s/repos/%s/%s/contents/%s", s.baseURL, owner, repo, filePath)
+	if ref != "" {
+		u += "?ref=" + url.QueryEscape(ref)
+	}
+
+	data, err := s.get(ctx, u, "application/vnd.github.raw+json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s from %s/%s: %w", filePath, owner, repo, err)
+	}
+	return data, nil
+}
+
+// siblingFiles lists the other source files in a remote file's directory
+// with its extension, leaving out tests
+func (s *PRService) siblingFiles(ctx context.Context, f *RemoteFile) ([]string, error) {
+	dir := path.Dir(f.Path)
+	if dir == "." {
+		dir = ""
+	}
+	u := fmt.Sprintf("%s/repos/%s/%s/contents/%s", s.baseURL, f.Owner, f.Repo, dir)
+	if f.Ref != "" {
+		u += "?ref=" + url.QueryEscape(f.Ref)
+	}
+
+	data, err := s.get(ctx, u, "application/vnd.github+json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s in %s/%s: %w", dir, f.Owner, f.Repo, err)
+	}
+	var entries []struct {
+		Type string `json:"type"`
+		Path string `json:"path"`
+	}
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse directory listing: %w", err)
+	}
+
+	ext := path.Ext(f.Path)
+	var siblings []string
+	for _, e := range entries {
+		if e.Type != "file" || e.Path == f.Path || path.Ext(e.Path) != ext || isTestName(path.Base(e.Path)) {
+			continue
+		}
+		if len(siblings) == maxSiblingFiles {
+			break
+		}
+		siblings = append(siblings, e.Path)
+	}
+	return siblings, nil
+}
+
+// get GETs a URL. Requests with an accept media type are to the GitHub
+// API and carry the token; others are sent without it.
+func (s *PRService) get(ctx context.Context, u, accept string) ([]byte, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	if accept != "" {
+		s.setHeaders(httpReq)
+		httpReq.Header.Set("Accept", accept)
+		if s.token == "" {
+			httpReq.Header.Del("Authorization")
+		}
+	}
+
+	resp, err := s.client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, errNotFound
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("%s", resp.Status)
+	}
+	return data, nil
+}
+
+// writeFetched writes a fetched file under dir at its repository path
+func writeFetched(dir, filePath string, data []byte) (string, error) {
+	local := filepath.Join(dir, filepath.FromSlash(filePath))
+	if !strings.HasPrefix(local, filepath.Clean(dir)+string(filepath.Separator)) {
+		return "", fmt.Errorf("file path escapes the download directory: %s", filePath)
+	}
+	if err := os.MkdirAll(filepath.Dir(local), 0755); err != nil {
+		return "", fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := os.WriteFile(local, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", filePath, err)
+	}
+	return local, nil
+}
+
+// isTestName reports whether a file name is a test file's
+func isTestName(name string) bool {
+	return strings.HasSuffix(name, "_test.go") || strings.HasPrefix(name, "test_") ||
+		strings.HasSuffix(name, "_test.py") || strings.Contains(name, ".test.") || strings.Contains(name, ".spec.")
+}
@@ -0,0 +1,109 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseRemoteFile(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    *RemoteFile
+		wantErr bool
+	}{
+		{"octo/calc:pkg/calc.go@v1.2.0", &RemoteFile{Owner: "octo", Repo: "calc", Path: "pkg/calc.go", Ref: "v1.2.0"}, false},
+		{"octo/calc:pkg/calc.go", &RemoteFile{Owner: "octo", Repo: "calc", Path: "pkg/calc.go"}, false},
+		{"https://github.com/octo/calc/blob/main/pkg/calc.go", &RemoteFile{Owner: "octo", Repo: "calc", Path: "pkg/calc.go", Ref: "main"}, false},
+		{"https://raw.githubusercontent.com/octo/calc/abc123/calc.py", &RemoteFile{Owner: "octo", Repo: "calc", Path: "calc.py", Ref: "abc123"}, false},
+		{"https://example.com/src/calc.ts?raw=1", &RemoteFile{Path: "calc.ts", URL: "https://example.com/src/calc.ts?raw=1"}, false},
+		{"./pkg/calc.go", nil, false},
+		{"C:\\src\\calc.go", nil, false},
+		{"octo/calc:@main", nil, true},
+		{"https://github.com/octo/calc", nil, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseRemoteFile(tt.spec)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseRemoteFile(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			continue
+		}
+		if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+			t.Errorf("ParseRemoteFile(%q) = %+v, want %+v", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestFetchRemoteFile(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("ref"); got != "v1" {
+			t.Errorf("ref = %q, want v1", got)
+		}
+		switch r.URL.Path {
+		case "/repos/octo/calc/contents/pkg/calc.go":
+			w.Write([]byte("package calc\n"))
+		case "/repos/octo/calc/contents/pkg/util.go":
+			w.Write([]byte("package calc\n\n// util\n"))
+		case "/repos/octo/calc/contents/go.mod":
+			w.Write([]byte("module example.com/calc\n"))
+		case "/repos/octo/calc/contents/pkg":
+			json.NewEncoder(w).Encode([]map[string]string{
+				{"type": "file", "path": "pkg/calc.go"},
+				{"type": "file", "path": "pkg/util.go"},
+				{"type": "file", "path": "pkg/calc_test.go"},
+				{"type": "file", "path": "pkg/README.md"},
+				{"type": "dir", "path": "pkg/internal"},
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	svc := NewPRService("")
+	svc.baseURL = server.URL
+	dir := t.TempDir()
+
+	local, err := svc.FetchRemoteFile(context.Background(), &RemoteFile{Owner: "octo", Repo: "calc", Path: "pkg/calc.go", Ref: "v1"}, dir)
+	if err != nil {
+		t.Fatalf("FetchRemoteFile() error = %v", err)
+	}
+	if want := filepath.Join(dir, "pkg", "calc.go"); local != want {
+		t.Errorf("local = %s, want %s", local, want)
+	}
+	for _, name := range []string{"pkg/calc.go", "pkg/util.go", "go.mod"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("%s not fetched: %v", name, err)
+		}
+	}
+	for _, name := range []string{"pkg/calc_test.go", "pkg/README.md"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			t.Errorf("%s fetched, want it left out", name)
+		}
+	}
+}
+
+func TestFetchRemoteFile_RawURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "" {
+			t.Error("token sent to a host other than GitHub")
+		}
+		w.Write([]byte("def add(a, b):\n    return a + b\n"))
+	}))
+	defer server.Close()
+
+	svc := NewPRService("secret")
+	local, err := svc.FetchRemoteFile(context.Background(), &RemoteFile{Path: "calc.py", URL: server.URL + "/calc.py"}, t.TempDir())
+	if err != nil {
+		t.Fatalf("FetchRemoteFile() error = %v", err)
+	}
+	if data, _ := os.ReadFile(local); !strings.Contains(string(data), "def add") {
+		t.Errorf("content = %q", data)
+	}
+}
@@ -0,0 +1,243 @@
+package github
+
+import (
+	"bytes"
+	"container/list"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Conditional request defaults. The cache is shared by every client in the
+// process, so it's bounded by total size as well as entries.
+const (
+	defaultETagEntries       = 1000
+	defaultETagMaxEntryBytes = 64 << 10 // largest body worth caching
+	defaultETagMaxTotalBytes = 8 << 20
+)
+
+// ETagStats are the conditional request metrics of a transport
+type ETagStats struct {
+	Hits    int64 `json:"hits"`    // 304s served from the cache; free against the rate limit
+	Misses  int64 `json:"misses"`  // conditional requests that returned new content
+	Entries int   `json:"entries"` // cached responses
+	Bytes   int64 `json:"bytes"`   // size of the cached responses
+}
+
+// ETagTransport makes GET requests conditional. It remembers the ETag and
+// Last-Modified of successful responses and sends them back as If-None-Match
+// and If-Modified-Since; a 304 reply is answered from the cache. GitHub
+// doesn't count 304s against the rate limit, which matters on installations
+// that re-read the same resources on every webhook.
+type ETagTransport struct {
+	Base          http.RoundTripper
+	MaxEntries    int
+	MaxEntryBytes int // larger bodies aren't cached
+	MaxTotalBytes int // least recently used entries are evicted past this
+
+	mu      sync.Mutex
+	entries map[uint64]*list.Element
+	lru     *list.List // front = most recently used
+	bytes   int64
+	stats   ETagStats
+}
+
+// etagEntry is a cached response
+type etagEntry struct {
+	key          uint64
+	etag         string
+	lastModified string
+	header       http.Header
+	body         []byte
+}
+
+// size approximates the memory an entry holds
+func (e *etagEntry) size() int64 {
+	n := len(e.etag) + len(e.lastModified) + len(e.body)
+	for name, values := range e.header {
+		n += len(name)
+		for _, v := range values {
+			n += len(v)
+		}
+	}
+	return int64(n)
+}
+
+// NewETagTransport creates a conditional request cache in front of base. A
+// nil base uses http.DefaultTransport.
+func NewETagTransport(base http.RoundTripper) *ETagTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &ETagTransport{
+		Base:          base,
+		MaxEntries:    defaultETagEntries,
+		MaxEntryBytes: defaultETagMaxEntryBytes,
+		MaxTotalBytes: defaultETagMaxTotalBytes,
+		entries:       make(map[uint64]*list.Element),
+		lru:           list.New(),
+	}
+}
+
+// Stats returns the transport's conditional request metrics
+func (t *ETagTransport) Stats() ETagStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	stats := t.stats
+	stats.Entries = t.lru.Len()
+	stats.Bytes = t.bytes
+	return stats
+}
+
+// RoundTrip sends GETs conditionally and passes everything else through
+func (t *ETagTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet || req.Header.Get("If-None-Match") != "" || req.Header.Get("If-Modified-Since") != "" {
+		return t.Base.RoundTrip(req)
+	}
+
+	key := etagKey(req)
+	cached := t.get(key)
+	if cached != nil {
+		req = req.Clone(req.Context())
+		if cached.etag != "" {
+			req.Header.Set("If-None-Match", cached.etag)
+		}
+		if cached.lastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.lastModified)
+		}
+	}
+
+	resp, err := t.Base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		resp.Body.Close()
+		t.count(true)
+		return cachedResponse(req, resp, cached), nil
+	}
+	if cached != nil {
+		t.count(false)
+	}
+
+	if resp.StatusCode == http.StatusOK {
+		return t.store(key, resp)
+	}
+	return resp, nil
+}
+
+// store caches a 200 response that carries a validator and returns it with
+// its body intact
+func (t *ETagTransport) store(key uint64, resp *http.Response) (*http.Response, error) {
+	etag := resp.Header.Get("ETag")
+	lastModified := resp.Header.Get("Last-Modified")
+	if etag == "" && lastModified == "" {
+		return resp, nil
+	}
+
+	// Read one byte past the limit to tell whether the body fits
+	data, err := io.ReadAll(io.LimitReader(resp.Body, int64(t.MaxEntryBytes)+1))
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	if len(data) > t.MaxEntryBytes {
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(data), resp.Body), resp.Body}
+		return resp, nil
+	}
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+
+	t.put(&etagEntry{
+		key:          key,
+		etag:         etag,
+		lastModified: lastModified,
+		header:       resp.Header.Clone(),
+		body:         data,
+	})
+	return resp, nil
+}
+
+func (t *ETagTransport) get(key uint64) *etagEntry {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	el, ok := t.entries[key]
+	if !ok {
+		return nil
+	}
+	t.lru.MoveToFront(el)
+	return el.Value.(*etagEntry)
+}
+
+func (t *ETagTransport) put(entry *etagEntry) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.entries == nil {
+		t.entries = make(map[uint64]*list.Element)
+		t.lru = list.New()
+	}
+	if el, ok := t.entries[entry.key]; ok {
+		t.bytes -= el.Value.(*etagEntry).size()
+		el.Value = entry
+		t.lru.MoveToFront(el)
+	} else {
+		t.entries[entry.key] = t.lru.PushFront(entry)
+	}
+	t.bytes += entry.size()
+	for t.lru.Len() > t.MaxEntries || (t.MaxTotalBytes > 0 && t.bytes > int64(t.MaxTotalBytes)) {
+		oldest := t.lru.Back()
+		t.lru.Remove(oldest)
+		delete(t.entries, oldest.Value.(*etagEntry).key)
+		t.bytes -= oldest.Value.(*etagEntry).size()
+	}
+}
+
+func (t *ETagTransport) count(hit bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if hit {
+		t.stats.Hits++
+	} else {
+		t.stats.Misses++
+	}
+}
+
+// cachedResponse answers a 304 with the cached 200, keeping the fresh
+// response's rate limit headers
+func cachedResponse(req *http.Request, notModified *http.Response, entry *etagEntry) *http.Response {
+	header := entry.header.Clone()
+	for name, values := range notModified.Header {
+		if strings.HasPrefix(name, "X-Ratelimit-") {
+			header[name] = values
+		}
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", http.StatusOK, http.StatusText(http.StatusOK)),
+		StatusCode:    http.StatusOK,
+		Proto:         notModified.Proto,
+		ProtoMajor:    notModified.ProtoMajor,
+		ProtoMinor:    notModified.ProtoMinor,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(entry.body)),
+		ContentLength: int64(len(entry.body)),
+		Request:       req,
+	}
+}
+
+// etagKey identifies a URL as seen by a token; responses differ per user
+func etagKey(req *http.Request) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(req.URL.String()))
+	h.Write([]byte{0})
+	h.Write([]byte(req.Header.Get("Authorization")))
+	h.Write([]byte{0})
+	h.Write([]byte(req.Header.Get("Accept")))
+	return h.Sum64()
+}
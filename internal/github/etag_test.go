@@ -0,0 +1,133 @@
+package github
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestETagTransport_ConditionalGet(t *testing.T) {
+	var conditional []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conditional = append(conditional, r.Header.Get("If-None-Match"))
+		w.Header().Set("X-RateLimit-Remaining", "4000")
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(`{"default_branch":"main"}`))
+	}))
+	defer server.Close()
+
+	rt := NewETagTransport(nil)
+	client := &http.Client{Transport: rt}
+
+	var bodies []string
+	for i := 0; i < 2; i++ {
+		req, _ := http.NewRequest(http.MethodGet, server.URL+"/repos/o/r", nil)
+		req.Header.Set("Authorization", "Bearer token")
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("request %d status = %d, want 200", i, resp.StatusCode)
+		}
+		if resp.Header.Get("X-RateLimit-Remaining") != "4000" {
+			t.Errorf("request %d missing fresh rate limit header", i)
+		}
+		bodies = append(bodies, string(data))
+	}
+
+	if conditional[0] != "" || conditional[1] != `"v1"` {
+		t.Errorf("If-None-Match = %q, want none then the ETag", conditional)
+	}
+	if bodies[1] != bodies[0] {
+		t.Errorf("cached body = %q, want %q", bodies[1], bodies[0])
+	}
+	if stats := rt.Stats(); stats.Hits != 1 || stats.Entries != 1 {
+		t.Errorf("stats = %+v, want 1 hit and 1 entry", stats)
+	}
+}
+
+func TestETagTransport_PerTokenAndEviction(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") != "" {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"x"`)
+		w.Write([]byte("[]"))
+	}))
+	defer server.Close()
+
+	rt := NewETagTransport(nil)
+	rt.MaxEntries = 1
+	client := &http.Client{Transport: rt}
+
+	get := func(path, token string) {
+		req, _ := http.NewRequest(http.MethodGet, server.URL+path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+
+	get("/a", "one")
+	get("/a", "two") // another user's view is not shared
+	if rt.Stats().Hits != 0 {
+		t.Error("response cached across tokens")
+	}
+	get("/b", "two") // evicts /a
+	get("/a", "two")
+	if stats := rt.Stats(); stats.Hits != 0 || stats.Entries != 1 {
+		t.Errorf("stats = %+v, want no hits after eviction", stats)
+	}
+}
+
+func TestETagTransport_ByteLimits(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"x"`)
+		w.Write(bytes.Repeat([]byte("a"), len(r.URL.Path)*100))
+	}))
+	defer server.Close()
+
+	rt := NewETagTransport(nil)
+	rt.MaxEntryBytes = 1000
+	rt.MaxTotalBytes = 2000
+	client := &http.Client{Transport: rt}
+
+	get := func(path string) {
+		resp, err := client.Get(server.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if len(body) != len(path)*100 {
+			t.Errorf("%s body = %d bytes, want %d", path, len(body), len(path)*100)
+		}
+	}
+
+	get("/too-long-to-cache") // 1700 bytes, over the entry limit
+	if stats := rt.Stats(); stats.Entries != 0 {
+		t.Errorf("stats = %+v, want the large body uncached", stats)
+	}
+
+	// Three 800 byte bodies don't fit in 2000 bytes; the oldest is evicted
+	for _, path := range []string{"/aaaaaaa", "/bbbbbbb", "/ccccccc"} {
+		get(path)
+	}
+	if stats := rt.Stats(); stats.Entries != 2 || stats.Bytes > 2000 {
+		t.Errorf("stats = %+v, want 2 entries within 2000 bytes", stats)
+	}
+}
@@ -393,6 +393,223 @@ func TestPRService_GetLatestCommitSHA(t *testing.T) {
 	})
 }
 
+func TestPRService_ListBranches_Paginated(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("per_page") != "100" {
+			t.Errorf("per_page = %q, want 100", r.URL.Query().Get("per_page"))
+		}
+		switch r.URL.Query().Get("page") {
+		case "":
+			w.Header().Set("Link", fmt.Sprintf(`<%s/repos/o/r/branches?per_page=100&page=2>; rel="next", <%s/repos/o/r/branches?per_page=100&page=2>; rel="last"`, server.URL, server.URL))
+			json.NewEncoder(w).Encode([]map[string]interface{}{{"name": "main"}, {"name": "dev"}})
+		case "2":
+			json.NewEncoder(w).Encode([]map[string]interface{}{{"name": "qtest/generated-tests", "commit": map[string]string{"sha": "abc"}}})
+		}
+	}))
+	defer server.Close()
+
+	svc := NewPRService("test-token")
+	svc.baseURL = server.URL
+
+	branches, err := svc.ListBranches(context.Background(), "o", "r")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(branches) != 3 || branches[2].Name != "qtest/generated-tests" || branches[2].Commit.SHA != "abc" {
+		t.Errorf("branches = %+v, want both pages", branches)
+	}
+}
+
+func TestPRService_ListPRs_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(404)
+	}))
+	defer server.Close()
+
+	svc := NewPRService("test-token")
+	svc.baseURL = server.URL
+
+	if _, err := svc.ListPRs(context.Background(), "o", "r", "all"); err == nil {
+		t.Error("expected error, got nil")
+	}
+}
+
+func TestPRService_Configure(t *testing.T) {
+	bodies := make(map[string]map[string]interface{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		bodies[r.URL.Path] = body
+
+		switch r.URL.Path {
+		case "/repos/o/r/issues/7/labels":
+			w.WriteHeader(200)
+		case "/repos/o/r/issues/7/assignees", "/repos/o/r/pulls/7/requested_reviewers":
+			w.WriteHeader(201)
+		case "/graphql":
+			w.Write([]byte(`{"data":{"enablePullRequestAutoMerge":{"clientMutationId":null}}}`))
+		default:
+			w.WriteHeader(404)
+		}
+	}))
+	defer server.Close()
+
+	svc := NewPRService("test-token")
+	svc.baseURL = server.URL
+
+	err := svc.Configure(context.Background(), "o", "r", &PRResponse{Number: 7, NodeID: "PR_kw"}, PRSettings{
+		Labels:      []string{"tests"},
+		Assignees:   []string{"alice"},
+		Reviewers:   []string{"bob", "my-org/qa"},
+		AutoMerge:   true,
+		MergeMethod: "rebase",
+	})
+	if err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	if got := fmt.Sprint(bodies["/repos/o/r/pulls/7/requested_reviewers"]); got != "map[reviewers:[bob] team_reviewers:[qa]]" {
+		t.Errorf("reviewers body = %s", got)
+	}
+	if got := fmt.Sprint(bodies["/repos/o/r/issues/7/labels"]["labels"]); got != "[tests]" {
+		t.Errorf("labels = %s", got)
+	}
+	vars, _ := bodies["/graphql"]["variables"].(map[string]interface{})
+	if vars["id"] != "PR_kw" || vars["method"] != "REBASE" {
+		t.Errorf("graphql variables = %v", vars)
+	}
+}
+
+func TestPRService_Configure_JoinsErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/graphql" {
+			w.Write([]byte(`{"errors":[{"message":"Auto merge is not allowed for this repository"}]}`))
+			return
+		}
+		w.WriteHeader(422)
+	}))
+	defer server.Close()
+
+	svc := NewPRService("test-token")
+	svc.baseURL = server.URL
+
+	err := svc.Configure(context.Background(), "o", "r", &PRResponse{Number: 7, NodeID: "PR_kw"}, PRSettings{
+		Labels:    []string{"tests"},
+		AutoMerge: true,
+	})
+	if err == nil {
+		t.Fatal("Configure() error = nil")
+	}
+	if !strings.Contains(err.Error(), "failed to add labels") || !strings.Contains(err.Error(), "not allowed") {
+		t.Errorf("error = %v, want both failures", err)
+	}
+}
+
+func TestPRService_EnableAutoMerge_NoNodeID(t *testing.T) {
+	svc := NewPRService("test-token")
+	if err := svc.EnableAutoMerge(context.Background(), "", ""); err == nil {
+		t.Error("EnableAutoMerge() without node ID should fail")
+	}
+}
+
+func TestPRService_CreateIssue(t *testing.T) {
+	var got map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/repos/o/r/issues" {
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(201)
+		w.Write([]byte(`{"number":9,"html_url":"https://github.com/o/r/issues/9","state":"open"}`))
+	}))
+	defer server.Close()
+
+	svc := NewPRService("test-token")
+	svc.baseURL = server.URL
+
+	issue, err := svc.CreateIssue(context.Background(), IssueRequest{
+		Owner: "o", Repo: "r", Title: "t", Body: "b", Labels: []string{"qtest:untestable"},
+	})
+	if err != nil {
+		t.Fatalf("CreateIssue() error = %v", err)
+	}
+	if issue.Number != 9 || fmt.Sprint(got["labels"]) != "[qtest:untestable]" {
+		t.Errorf("issue = %+v, request = %v", issue, got)
+	}
+}
+
+func TestPRService_FindOpenIssue(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("labels") != "qtest:untestable" {
+			t.Errorf("labels = %q", r.URL.Query().Get("labels"))
+		}
+		w.Write([]byte(`[
+			{"number":1,"title":"wanted","pull_request":{}},
+			{"number":2,"title":"other"},
+			{"number":3,"title":"wanted"}
+		]`))
+	}))
+	defer server.Close()
+
+	svc := NewPRService("test-token")
+	svc.baseURL = server.URL
+
+	issue, err := svc.FindOpenIssue(context.Background(), "o", "r", "qtest:untestable", "wanted")
+	if err != nil {
+		t.Fatalf("FindOpenIssue() error = %v", err)
+	}
+	if issue == nil || issue.Number != 3 {
+		t.Errorf("FindOpenIssue() = %+v, want issue 3 (not the PR)", issue)
+	}
+
+	if issue, _ := svc.FindOpenIssue(context.Background(), "o", "r", "qtest:untestable", "missing"); issue != nil {
+		t.Errorf("FindOpenIssue(missing) = %+v, want nil", issue)
+	}
+}
+
+func TestGenerateUntestableIssueBody(t *testing.T) {
+	body := GenerateUntestableIssueBody(UntestableTemplate{
+		File:       "svc/sync.go",
+		Function:   "Sync",
+		Language:   "go",
+		Attempts:   3,
+		LastReason: "no test generated",
+		Findings: []UntestableFinding{
+			{Kind: "clock", Line: 12, Evidence: "t := time.Now()", Suggestion: "Inject a clock."},
+		},
+	})
+
+	for _, want := range []string{"3 times", "no test generated", "**clock** (line 12)", "- [ ] Inject a clock."} {
+		if !strings.Contains(body, want) {
+			t.Errorf("body missing %q:\n%s", want, body)
+		}
+	}
+
+	empty := GenerateUntestableIssueBody(UntestableTemplate{File: "a.go", Function: "F", Attempts: 3})
+	if !strings.Contains(empty, "No specific cause") || strings.Contains(empty, "Suggested refactorings") {
+		t.Errorf("body without findings:\n%s", empty)
+	}
+}
+
+func TestNextPageURL(t *testing.T) {
+	tests := []struct {
+		link string
+		want string
+	}{
+		{`<https://api.github.com/x?page=2>; rel="next", <https://api.github.com/x?page=5>; rel="last"`, "https://api.github.com/x?page=2"},
+		{`<https://api.github.com/x?page=1>; rel="prev", <https://api.github.com/x?page=1>; rel="first"`, ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		h := http.Header{}
+		h.Set("Link", tt.link)
+		if got := NextPageURL(h); got != tt.want {
+			t.Errorf("NextPageURL(%q) = %q, want %q", tt.link, got, tt.want)
+		}
+	}
+}
+
 // Test CommitFile
 func TestPRService_CommitFile(t *testing.T) {
 	t.Run("new file", func(t *testing.T) {
@@ -449,6 +666,53 @@ func TestPRService_CommitFile(t *testing.T) {
 	})
 }
 
+// Test DeleteFile
+func TestPRService_DeleteFile(t *testing.T) {
+	t.Run("existing file", func(t *testing.T) {
+		var deleted map[string]interface{}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == "GET" {
+				json.NewEncoder(w).Encode(map[string]string{"sha": "existing-sha"})
+				return
+			}
+			if r.Method == "DELETE" {
+				json.NewDecoder(r.Body).Decode(&deleted)
+				w.WriteHeader(200)
+				return
+			}
+		}))
+		defer server.Close()
+
+		svc := NewPRService("test-token")
+		svc.baseURL = server.URL
+
+		err := svc.DeleteFile(context.Background(), "owner", "repo", "feature",
+			"test/file_test.go", "Remove stale test")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if deleted["sha"] != "existing-sha" || deleted["branch"] != "feature" {
+			t.Errorf("delete payload = %v", deleted)
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(404)
+		}))
+		defer server.Close()
+
+		svc := NewPRService("test-token")
+		svc.baseURL = server.URL
+
+		err := svc.DeleteFile(context.Background(), "owner", "repo", "feature",
+			"test/file_test.go", "Remove stale test")
+		if err == nil {
+			t.Error("expected error for missing file")
+		}
+	})
+}
+
 // =============================================================================
 // RepoService Tests
 // =============================================================================
@@ -870,6 +1134,19 @@ func TestGeneratePRBody_EmptyFiles(t *testing.T) {
 	}
 }
 
+func TestGeneratePRBody_Dependencies(t *testing.T) {
+	tmpl := PRTemplate{TestCount: 1, Files: []string{"tests/test_app.py"}}
+	if strings.Contains(GeneratePRBody(tmpl), "## Test Dependencies") {
+		t.Error("Test Dependencies section without dependencies")
+	}
+
+	tmpl.Dependencies = []string{"pyproject.toml: httpx>=0.27, pytest>=8.0"}
+	body := GeneratePRBody(tmpl)
+	if !strings.Contains(body, "## Test Dependencies") || !strings.Contains(body, "- pyproject.toml: httpx>=0.27, pytest>=8.0\n") {
+		t.Errorf("body should list the declared dependencies:\n%s", body)
+	}
+}
+
 func TestGeneratePRBody_ManyFiles(t *testing.T) {
 	files := make([]string, 10)
 	for i := 0; i < 10; i++ {
@@ -1066,3 +1343,81 @@ func TestParseRepoURL_TooManySegments(t *testing.T) {
 		t.Errorf("Owner = %s, want owner", info.Owner)
 	}
 }
+
+func TestGeneratePRBody_Risk(t *testing.T) {
+	tmpl := PRTemplate{TestCount: 1, Files: []string{"users_test.go"}}
+	if body := GeneratePRBody(tmpl); strings.Contains(body, "## Risk Analysis") {
+		t.Error("PR body without risk data shouldn't have a risk section")
+	}
+
+	tmpl.Risk = &PRRisk{
+		CoveredEndpoints:  []string{"GET /users/:id"},
+		MutationSurvivors: []string{"`users.go:42` comparison: `<` -> `<=`"},
+		DashboardURL:      "https://qtest.example.com/repos/r1/runs/run1",
+	}
+	for i := 0; i < maxRiskItems+2; i++ {
+		tmpl.Risk.CriticalGaps = append(tmpl.Risk.CriticalGaps, fmt.Sprintf("Parse%d (config.go)", i))
+	}
+	body := GeneratePRBody(tmpl)
+
+	for _, want := range []string{
+		"## Risk Analysis\n\n**Critical endpoints now covered** (1)\n\n- `GET /users/:id`\n",
+		"**Remaining critical gaps** (12)\n\n- `Parse0 (config.go)`\n",
+		"- ...and 2 more\n",
+		"**Mutation survivors in touched files** (1)\n\n- `users.go:42` comparison: `<` -> `<=`\n",
+		"[View this run on the dashboard](https://qtest.example.com/repos/r1/runs/run1)",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("PR body missing %q:\n%s", want, body)
+		}
+	}
+	if strings.Contains(body, "Parse10") {
+		t.Errorf("PR body should cap the gaps at %d:\n%s", maxRiskItems, body)
+	}
+	if strings.Index(body, "## Risk Analysis") > strings.Index(body, "## Test Files") {
+		t.Error("risk section should come before the test files")
+	}
+}
+
+func TestRenderPRBody(t *testing.T) {
+	tmpl := PRTemplate{
+		TestCount: 2,
+		Files:     []string{"a_test.go", "b_test.go"},
+		Risk:      &PRRisk{CriticalGaps: []string{"Charge (billing.go)"}},
+		Metrics:   PRMetrics{RunID: "run1", TestsPassed: true, MutationScore: 87.5, LLMCostUSD: 0.42},
+	}
+
+	if body, err := RenderPRBody(tmpl, ""); err != nil || body != GeneratePRBody(tmpl) {
+		t.Errorf("RenderPRBody() without a template should be the default body, err = %v", err)
+	}
+
+	text := `JIRA: QA-1
+Run {{.Metrics.RunID}}: {{.TestCount}} tests in {{join .Files ", "}}
+{{if .Metrics.TestsPassed}}verified{{end}}, mutation score {{printf "%.1f" .Metrics.MutationScore}}%, cost ${{printf "%.2f" .Metrics.LLMCostUSD}}
+{{.RiskSection}}`
+	body, err := RenderPRBody(tmpl, text)
+	if err != nil {
+		t.Fatalf("RenderPRBody() error = %v", err)
+	}
+	for _, want := range []string{
+		"JIRA: QA-1\nRun run1: 2 tests in a_test.go, b_test.go\n",
+		"verified, mutation score 87.5%, cost $0.42\n",
+		"## Risk Analysis\n\n**Remaining critical gaps** (1)",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("PR body missing %q:\n%s", want, body)
+		}
+	}
+	if body, _ := RenderPRBody(tmpl, "{{.DefaultBody}}\nReviewed-by: QA"); !strings.HasPrefix(body, "## Summary") {
+		t.Errorf("DefaultBody should render the default body:\n%s", body)
+	}
+
+	for _, bad := range []string{"{{.TestCount", "{{.Coverage}}", "{{.Metrics.Score}}"} {
+		if err := ValidatePRBodyTemplate(bad); err == nil {
+			t.Errorf("ValidatePRBodyTemplate(%q) should fail", bad)
+		}
+	}
+	if err := ValidatePRBodyTemplate("{{.Risk.DashboardURL}}"); err != nil {
+		t.Errorf("ValidatePRBodyTemplate() error = %v", err)
+	}
+}
@@ -0,0 +1,169 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// IssueRequest represents an issue creation request
+type IssueRequest struct {
+	Owner  string
+	Repo   string
+	Title  string
+	Body   string
+	Labels []string
+}
+
+// Issue represents a GitHub issue
+type Issue struct {
+	Number      int       `json:"number"`
+	HTMLURL     string    `json:"html_url"`
+	State       string    `json:"state"`
+	Title       string    `json:"title"`
+	PullRequest *struct{} `json:"pull_request,omitempty"` // set when the issue is a PR
+}
+
+// CreateIssue opens a new issue
+func (s *PRService) CreateIssue(ctx context.Context, req IssueRequest) (*Issue, error) {
+	url := fmt.Sprintf("%s/repos/%s/%s/issues", s.baseURL, req.Owner, req.Repo)
+
+	payload := map[string]interface{}{
+		"title": req.Title,
+		"body":  req.Body,
+	}
+	if len(req.Labels) > 0 {
+		payload["labels"] = req.Labels
+	}
+
+	body, _ := json.Marshal(payload)
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	s.setHeaders(httpReq)
+
+	resp, err := s.client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(resp.Body)
+
+	if resp.StatusCode != 201 {
+		return nil, fmt.Errorf("failed to create issue: %s - %s", resp.Status, string(respBody))
+	}
+
+	var issue Issue
+	if err := json.Unmarshal(respBody, &issue); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return &issue, nil
+}
+
+// FindOpenIssue finds an open issue with a label and title, or returns nil.
+// Pull requests are skipped.
+func (s *PRService) FindOpenIssue(ctx context.Context, owner, repo, label, title string) (*Issue, error) {
+	u := fmt.Sprintf("%s/repos/%s/%s/issues?state=open&labels=%s&per_page=%d",
+		s.baseURL, owner, repo, url.QueryEscape(label), perPage)
+
+	var found *Issue
+	err := s.listPages(ctx, u, func(data []byte) error {
+		var page []Issue
+		if err := json.Unmarshal(data, &page); err != nil {
+			return err
+		}
+		for i := range page {
+			if found == nil && page[i].PullRequest == nil && page[i].Title == title {
+				found = &page[i]
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list issues: %w", err)
+	}
+	return found, nil
+}
+
+// UntestableFinding is a reason code is hard to test, for an issue body
+type UntestableFinding struct {
+	Kind       string
+	Line       int
+	Evidence   string
+	Suggestion string
+}
+
+// UntestableTemplate generates an issue body for a function test
+// generation keeps failing for
+type UntestableTemplate struct {
+	File       string
+	Function   string
+	Language   string
+	Attempts   int
+	LastReason string
+	Findings   []UntestableFinding
+}
+
+// UntestableIssueTitle is the title of the issue for a function, also used
+// to find an already open one
+func UntestableIssueTitle(file, function string) string {
+	return fmt.Sprintf("QTest: `%s` in %s is hard to test", function, file)
+}
+
+// GenerateUntestableIssueBody generates the issue description body
+func GenerateUntestableIssueBody(tmpl UntestableTemplate) string {
+	var sb strings.Builder
+
+	sb.WriteString("## Summary\n\n")
+	sb.WriteString(fmt.Sprintf("QTest failed to generate tests for `%s` in `%s` %d times in a row.\n\n",
+		tmpl.Function, tmpl.File, tmpl.Attempts))
+	if tmpl.LastReason != "" {
+		sb.WriteString(fmt.Sprintf("Last failure: %s\n\n", tmpl.LastReason))
+	}
+
+	sb.WriteString("## Why it's hard to test\n\n")
+	if len(tmpl.Findings) == 0 {
+		sb.WriteString("No specific cause was detected. The function may depend on state set up elsewhere, ")
+		sb.WriteString("or its behaviour may be hard to observe from its inputs and outputs.\n\n")
+	} else {
+		for _, f := range tmpl.Findings {
+			kind := strings.ReplaceAll(f.Kind, "_", " ")
+			if f.Line > 0 {
+				sb.WriteString(fmt.Sprintf("- **%s** (line %d)", kind, f.Line))
+			} else {
+				sb.WriteString(fmt.Sprintf("- **%s**", kind))
+			}
+			if f.Evidence != "" {
+				sb.WriteString(fmt.Sprintf(": `%s`", strings.ReplaceAll(f.Evidence, "`", "'")))
+			}
+			sb.WriteString("\n")
+		}
+		sb.WriteString("\n")
+
+		sb.WriteString("## Suggested refactorings\n\n")
+		for _, f := range tmpl.Findings {
+			sb.WriteString(fmt.Sprintf("- [ ] %s\n", f.Suggestion))
+		}
+		sb.WriteString("\n")
+	}
+
+	if tmpl.Language != "" {
+		sb.WriteString(fmt.Sprintf("- **Language**: %s\n", tmpl.Language))
+	}
+	sb.WriteString("- **Generated by**: [QTest](https://github.com/QTest-hq/qtest)\n\n")
+
+	sb.WriteString("---\n")
+	sb.WriteString("*This issue was automatically opened by QTest*\n")
+
+	return sb.String()
+}
@@ -4,11 +4,18 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"strings"
-	"time"
+	"text/template"
+)
+
+// Pagination limits for list endpoints
+const (
+	perPage  = 100 // GitHub's maximum page size
+	maxPages = 50
 )
 
 // PRService handles GitHub Pull Request operations
@@ -22,11 +29,37 @@ type PRService struct {
 func NewPRService(token string) *PRService {
 	return &PRService{
 		token:   token,
-		client:  &http.Client{Timeout: 30 * time.Second},
+		client:  NewHTTPClient(),
 		baseURL: "https://api.github.com",
 	}
 }
 
+// RateLimitStats returns the request budget metrics of the service's
+// transport, shared by every GitHub client in the process
+func (s *PRService) RateLimitStats() RateLimitStats {
+	rt := s.client.Transport
+	for rt != nil {
+		switch t := rt.(type) {
+		case *RateLimitTransport:
+			return t.Stats()
+		case *ETagTransport:
+			rt = t.Base
+		default:
+			rt = nil
+		}
+	}
+	return RateLimitStats{}
+}
+
+// ETagStats returns the conditional request metrics of the service's
+// transport
+func (s *PRService) ETagStats() ETagStats {
+	if t, ok := s.client.Transport.(*ETagTransport); ok {
+		return t.Stats()
+	}
+	return ETagStats{}
+}
+
 // PRRequest represents a pull request creation request
 type PRRequest struct {
 	Owner      string
@@ -42,6 +75,7 @@ type PRRequest struct {
 // PRResponse represents a created pull request
 type PRResponse struct {
 	ID        int    `json:"id"`
+	NodeID    string `json:"node_id"` // GraphQL ID, used for auto-merge
 	Number    int    `json:"number"`
 	HTMLURL   string `json:"html_url"`
 	State     string `json:"state"`
@@ -49,6 +83,15 @@ type PRResponse struct {
 	CreatedAt string `json:"created_at"`
 }
 
+// PRSettings are applied to a pull request after it is opened
+type PRSettings struct {
+	Labels      []string
+	Assignees   []string
+	Reviewers   []string // user logins, or org/team-slug for teams
+	AutoMerge   bool     // merge once required checks pass
+	MergeMethod string   // merge, squash (default) or rebase
+}
+
 // BranchRequest represents a branch creation request
 type BranchRequest struct {
 	Owner  string
@@ -144,31 +187,140 @@ func (s *PRService) CreatePR(ctx context.Context, req PRRequest) (*PRResponse, e
 	return &prResp, nil
 }
 
-// FindPR finds an existing PR for the given head and base branches
-func (s *PRService) FindPR(ctx context.Context, owner, repo, head, base string) (*PRResponse, error) {
-	url := fmt.Sprintf("%s/repos/%s/%s/pulls?head=%s:%s&base=%s&state=open",
-		s.baseURL, owner, repo, owner, head, base)
+// Configure applies labels, assignees, reviewers and auto-merge to a pull
+// request. Every setting is attempted; the errors of those that failed are
+// joined.
+func (s *PRService) Configure(ctx context.Context, owner, repo string, pr *PRResponse, settings PRSettings) error {
+	var errs []error
+	if len(settings.Labels) > 0 {
+		url := fmt.Sprintf("%s/repos/%s/%s/issues/%d/labels", s.baseURL, owner, repo, pr.Number)
+		if err := s.post(ctx, url, map[string]interface{}{"labels": settings.Labels}, 200); err != nil {
+			errs = append(errs, fmt.Errorf("failed to add labels: %w", err))
+		}
+	}
 
-	httpReq, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if len(settings.Assignees) > 0 {
+		url := fmt.Sprintf("%s/repos/%s/%s/issues/%d/assignees", s.baseURL, owner, repo, pr.Number)
+		if err := s.post(ctx, url, map[string]interface{}{"assignees": settings.Assignees}, 201); err != nil {
+			errs = append(errs, fmt.Errorf("failed to add assignees: %w", err))
+		}
+	}
+
+	if len(settings.Reviewers) > 0 {
+		users, teams := splitReviewers(settings.Reviewers)
+		payload := map[string]interface{}{"reviewers": users, "team_reviewers": teams}
+		url := fmt.Sprintf("%s/repos/%s/%s/pulls/%d/requested_reviewers", s.baseURL, owner, repo, pr.Number)
+		if err := s.post(ctx, url, payload, 201); err != nil {
+			errs = append(errs, fmt.Errorf("failed to request reviewers: %w", err))
+		}
+	}
+
+	if settings.AutoMerge {
+		if err := s.EnableAutoMerge(ctx, pr.NodeID, settings.MergeMethod); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// EnableAutoMerge turns on auto-merge for a pull request, so GitHub merges
+// it once its required checks pass. Auto-merge must be allowed in the
+// repository settings. It is only available through GraphQL.
+func (s *PRService) EnableAutoMerge(ctx context.Context, nodeID, mergeMethod string) error {
+	if nodeID == "" {
+		return fmt.Errorf("failed to enable auto-merge: pull request has no node ID")
+	}
+	method := strings.ToUpper(mergeMethod)
+	if method == "" {
+		method = "SQUASH"
+	}
+
+	payload := map[string]interface{}{
+		"query": `mutation($id: ID!, $method: PullRequestMergeMethod!) {
+  enablePullRequestAutoMerge(input: {pullRequestId: $id, mergeMethod: $method}) { clientMutationId }
+}`,
+		"variables": map[string]string{"id": nodeID, "method": method},
+	}
+	body, _ := json.Marshal(payload)
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", s.baseURL+"/graphql", bytes.NewReader(body))
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	s.setHeaders(httpReq)
 
 	resp, err := s.client.Do(httpReq)
 	if err != nil {
-		return nil, err
+		return fmt.Errorf("failed to enable auto-merge: %w", err)
 	}
 	defer resp.Body.Close()
 
+	respBody, _ := io.ReadAll(resp.Body)
 	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("failed to find PR: %s", resp.Status)
+		return fmt.Errorf("failed to enable auto-merge: %s - %s", resp.Status, string(respBody))
 	}
 
-	var prs []PRResponse
-	if err := json.NewDecoder(resp.Body).Decode(&prs); err != nil {
-		return nil, err
+	// GraphQL reports failures in the body with a 200
+	var result struct {
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(respBody, &result); err == nil && len(result.Errors) > 0 {
+		return fmt.Errorf("failed to enable auto-merge: %s", result.Errors[0].Message)
+	}
+
+	return nil
+}
+
+// post sends a JSON POST and checks for the expected status
+func (s *PRService) post(ctx context.Context, url string, payload interface{}, wantStatus int) error {
+	body, _ := json.Marshal(payload)
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	s.setHeaders(httpReq)
+
+	resp, err := s.client.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != wantStatus {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("%s - %s", resp.Status, string(respBody))
+	}
+
+	return nil
+}
+
+// splitReviewers separates user logins from org/team-slug team reviewers
+func splitReviewers(reviewers []string) (users, teams []string) {
+	users, teams = []string{}, []string{}
+	for _, r := range reviewers {
+		if i := strings.Index(r, "/"); i >= 0 {
+			teams = append(teams, r[i+1:])
+		} else {
+			users = append(users, r)
+		}
+	}
+	return users, teams
+}
+
+// FindPR finds an existing PR for the given head and base branches
+func (s *PRService) FindPR(ctx context.Context, owner, repo, head, base string) (*PRResponse, error) {
+	url := fmt.Sprintf("%s/repos/%s/%s/pulls?head=%s:%s&base=%s&state=open&per_page=%d",
+		s.baseURL, owner, repo, owner, head, base, perPage)
+
+	prs, err := s.listPRs(ctx, url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find PR: %w", err)
 	}
 
 	if len(prs) == 0 {
@@ -178,6 +330,101 @@ func (s *PRService) FindPR(ctx context.Context, owner, repo, head, base string)
 	return &prs[0], nil
 }
 
+// ListPRs lists a repository's pull requests in a state ("open", "closed"
+// or "all"), across every page
+func (s *PRService) ListPRs(ctx context.Context, owner, repo, state string) ([]PRResponse, error) {
+	if state == "" {
+		state = "open"
+	}
+	url := fmt.Sprintf("%s/repos/%s/%s/pulls?state=%s&per_page=%d", s.baseURL, owner, repo, state, perPage)
+
+	prs, err := s.listPRs(ctx, url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list PRs: %w", err)
+	}
+	return prs, nil
+}
+
+func (s *PRService) listPRs(ctx context.Context, url string) ([]PRResponse, error) {
+	var prs []PRResponse
+	err := s.listPages(ctx, url, func(data []byte) error {
+		var page []PRResponse
+		if err := json.Unmarshal(data, &page); err != nil {
+			return err
+		}
+		prs = append(prs, page...)
+		return nil
+	})
+	return prs, err
+}
+
+// Branch is a repository branch
+type Branch struct {
+	Name      string `json:"name"`
+	Protected bool   `json:"protected"`
+	Commit    struct {
+		SHA string `json:"sha"`
+	} `json:"commit"`
+}
+
+// ListBranches lists a repository's branches, across every page
+func (s *PRService) ListBranches(ctx context.Context, owner, repo string) ([]Branch, error) {
+	url := fmt.Sprintf("%s/repos/%s/%s/branches?per_page=%d", s.baseURL, owner, repo, perPage)
+
+	var branches []Branch
+	err := s.listPages(ctx, url, func(data []byte) error {
+		var page []Branch
+		if err := json.Unmarshal(data, &page); err != nil {
+			return err
+		}
+		branches = append(branches, page...)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list branches: %w", err)
+	}
+	return branches, nil
+}
+
+// listPages GETs a list endpoint and follows its Link headers, passing each
+// page's body to add. It stops after maxPages pages.
+func (s *PRService) listPages(ctx context.Context, url string, add func(data []byte) error) error {
+	for page := 0; url != ""; page++ {
+		if page >= maxPages {
+			return fmt.Errorf("more than %d pages", maxPages)
+		}
+
+		httpReq, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+		if err != nil {
+			return err
+		}
+
+		s.setHeaders(httpReq)
+
+		resp, err := s.client.Do(httpReq)
+		if err != nil {
+			return err
+		}
+
+		data, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return err
+		}
+
+		if resp.StatusCode != 200 {
+			return fmt.Errorf("%s", resp.Status)
+		}
+
+		if err := add(data); err != nil {
+			return fmt.Errorf("failed to parse response: %w", err)
+		}
+
+		url = NextPageURL(resp.Header)
+	}
+	return nil
+}
+
 // CommitFile commits a single file to a branch
 func (s *PRService) CommitFile(ctx context.Context, owner, repo, branch, path, content, message string) error {
 	// Get the current file SHA if it exists
@@ -218,6 +465,45 @@ func (s *PRService) CommitFile(ctx context.Context, owner, repo, branch, path, c
 	return nil
 }
 
+// DeleteFile deletes a single file from a branch
+func (s *PRService) DeleteFile(ctx context.Context, owner, repo, branch, path, message string) error {
+	existingSHA, err := s.getFileSHA(ctx, owner, repo, branch, path)
+	if err != nil {
+		return fmt.Errorf("failed to look up file: %w", err)
+	}
+	if existingSHA == "" {
+		return fmt.Errorf("file not found on %s: %s", branch, path)
+	}
+
+	url := fmt.Sprintf("%s/repos/%s/%s/contents/%s", s.baseURL, owner, repo, path)
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"message": message,
+		"sha":     existingSHA,
+		"branch":  branch,
+	})
+
+	httpReq, err := http.NewRequestWithContext(ctx, "DELETE", url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	s.setHeaders(httpReq)
+
+	resp, err := s.client.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to delete file: %s - %s", resp.Status, string(respBody))
+	}
+
+	return nil
+}
+
 // getFileSHA gets the SHA of an existing file
 func (s *PRService) getFileSHA(ctx context.Context, owner, repo, branch, path string) (string, error) {
 	url := fmt.Sprintf("%s/repos/%s/%s/contents/%s?ref=%s", s.baseURL, owner, repo, path, branch)
@@ -366,8 +652,38 @@ type PRTemplate struct {
 	Files         []string
 	Framework     string
 	Language      string
+	Risk          *PRRisk // optional risk analysis of the run
+	Metrics       PRMetrics
+
+	// Dependencies summarises the test dependencies the PR declares, one
+	// line per file, e.g. "package.json: jest@^29.7.0"
+	Dependencies []string
+}
+
+// PRMetrics are figures from the run a PR was opened for, for custom PR
+// body templates
+type PRMetrics struct {
+	RunID          string
+	TestsPassed    bool // the tests passed verification before the PR
+	TargetsCovered int
+	TargetsFailed  int
+	MutationScore  float64 // percent of mutants killed; 0 without mutation testing
+	MutantsLived   int
+	LLMCalls       int
+	LLMCostUSD     float64
 }
 
+// PRRisk summarises how a run's tests change the repository's risk
+type PRRisk struct {
+	CoveredEndpoints  []string // critical endpoints the tests now cover
+	CriticalGaps      []string // critical targets still without tests
+	MutationSurvivors []string // surviving mutants in the files the tests touch
+	DashboardURL      string   // the run on the QTest dashboard
+}
+
+// maxRiskItems caps each list in the risk section so large runs stay readable
+const maxRiskItems = 10
+
 // GeneratePRBody generates the PR description body
 func GeneratePRBody(tmpl PRTemplate) string {
 	var sb strings.Builder
@@ -379,11 +695,21 @@ func GeneratePRBody(tmpl PRTemplate) string {
 		sb.WriteString(fmt.Sprintf("Estimated coverage improvement: **+%.1f%%**\n\n", tmpl.CoverageDelta))
 	}
 
+	writeRiskSection(&sb, tmpl.Risk)
+
 	sb.WriteString("## Test Files\n\n")
 	for _, f := range tmpl.Files {
 		sb.WriteString(fmt.Sprintf("- `%s`\n", f))
 	}
 
+	if len(tmpl.Dependencies) > 0 {
+		sb.WriteString("\n## Test Dependencies\n\n")
+		sb.WriteString("The tests need these dependencies, which this PR declares:\n\n")
+		for _, d := range tmpl.Dependencies {
+			sb.WriteString(fmt.Sprintf("- %s\n", d))
+		}
+	}
+
 	sb.WriteString("\n## Details\n\n")
 	sb.WriteString(fmt.Sprintf("- **Language**: %s\n", tmpl.Language))
 	sb.WriteString(fmt.Sprintf("- **Framework**: %s\n", tmpl.Framework))
@@ -399,3 +725,89 @@ func GeneratePRBody(tmpl PRTemplate) string {
 
 	return sb.String()
 }
+
+// DefaultBody is the body GeneratePRBody writes, for custom templates that
+// only add to it
+func (t PRTemplate) DefaultBody() string {
+	return GeneratePRBody(t)
+}
+
+// RiskSection is the risk analysis of the default body, or empty
+func (t PRTemplate) RiskSection() string {
+	var sb strings.Builder
+	writeRiskSection(&sb, t.Risk)
+	return sb.String()
+}
+
+// prBodyFuncs are the functions custom PR body templates can call
+var prBodyFuncs = template.FuncMap{
+	"join": strings.Join,
+}
+
+// ParsePRBodyTemplate parses a custom PR body: a Go text/template executed
+// with the PRTemplate, whose fields and Metrics it can use
+func ParsePRBodyTemplate(text string) (*template.Template, error) {
+	t, err := template.New("pr-body").Funcs(prBodyFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid PR body template: %w", err)
+	}
+	return t, nil
+}
+
+// ValidatePRBodyTemplate checks a custom PR body parses and only uses
+// fields a PRTemplate has
+func ValidatePRBodyTemplate(text string) error {
+	_, err := RenderPRBody(PRTemplate{Risk: &PRRisk{}}, text)
+	return err
+}
+
+// RenderPRBody generates the PR body from a custom template, or the default
+// body when there is none
+func RenderPRBody(tmpl PRTemplate, text string) (string, error) {
+	if strings.TrimSpace(text) == "" {
+		return GeneratePRBody(tmpl), nil
+	}
+	t, err := ParsePRBodyTemplate(text)
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	if err := t.Execute(&sb, tmpl); err != nil {
+		return "", fmt.Errorf("failed to render PR body template: %w", err)
+	}
+	return sb.String(), nil
+}
+
+// writeRiskSection writes the risk analysis, leaving out what's unknown
+func writeRiskSection(sb *strings.Builder, risk *PRRisk) {
+	if risk == nil || (len(risk.CoveredEndpoints) == 0 && len(risk.CriticalGaps) == 0 &&
+		len(risk.MutationSurvivors) == 0 && risk.DashboardURL == "") {
+		return
+	}
+
+	sb.WriteString("## Risk Analysis\n\n")
+	writeRiskList(sb, "Critical endpoints now covered", risk.CoveredEndpoints, true)
+	writeRiskList(sb, "Remaining critical gaps", risk.CriticalGaps, true)
+	writeRiskList(sb, "Mutation survivors in touched files", risk.MutationSurvivors, false)
+	if risk.DashboardURL != "" {
+		sb.WriteString(fmt.Sprintf("[View this run on the dashboard](%s)\n\n", risk.DashboardURL))
+	}
+}
+
+func writeRiskList(sb *strings.Builder, title string, items []string, code bool) {
+	if len(items) == 0 {
+		return
+	}
+	sb.WriteString(fmt.Sprintf("**%s** (%d)\n\n", title, len(items)))
+	for i, item := range items {
+		if i == maxRiskItems {
+			sb.WriteString(fmt.Sprintf("- ...and %d more\n", len(items)-maxRiskItems))
+			break
+		}
+		if code {
+			item = "`" + item + "`"
+		}
+		sb.WriteString("- " + item + "\n")
+	}
+	sb.WriteString("\n")
+}
@@ -0,0 +1,189 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// maxReviewComments caps the inline comments of one review so large runs
+// don't bury the pull request
+const maxReviewComments = 50
+
+// ReviewComment is an inline comment on a line of a pull request's head
+type ReviewComment struct {
+	Path string // relative to the repository root
+	Line int
+	Body string
+}
+
+// PRFile is a file a pull request changes
+type PRFile struct {
+	Filename string `json:"filename"`
+	Status   string `json:"status"`
+	Patch    string `json:"patch"`
+}
+
+// ListPRFiles lists the files a pull request changes, across every page
+func (s *PRService) ListPRFiles(ctx context.Context, owner, repo string, number int) ([]PRFile, error) {
+	url := fmt.Sprintf("%s/repos/%s/%s/pulls/%d/files?per_page=%d", s.baseURL, owner, repo, number, perPage)
+
+	var files []PRFile
+	err := s.listPages(ctx, url, func(data []byte) error {
+		var page []PRFile
+		if err := json.Unmarshal(data, &page); err != nil {
+			return err
+		}
+		files = append(files, page...)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list PR files: %w", err)
+	}
+	return files, nil
+}
+
+// PostReview posts a review on a pull request with a summary body and
+// inline comments. The review only comments; it neither approves nor
+// requests changes.
+func (s *PRService) PostReview(ctx context.Context, owner, repo string, number int, body string, comments []ReviewComment) error {
+	payloadComments := make([]map[string]interface{}, 0, len(comments))
+	for _, c := range comments {
+		payloadComments = append(payloadComments, map[string]interface{}{
+			"path": c.Path,
+			"line": c.Line,
+			"side": "RIGHT",
+			"body": c.Body,
+		})
+	}
+	payload := map[string]interface{}{
+		"event":    "COMMENT",
+		"body":     body,
+		"comments": payloadComments,
+	}
+
+	url := fmt.Sprintf("%s/repos/%s/%s/pulls/%d/reviews", s.baseURL, owner, repo, number)
+	if err := s.post(ctx, url, payload, 200); err != nil {
+		return fmt.Errorf("failed to post review: %w", err)
+	}
+	return nil
+}
+
+// PostQualityReview reviews a pull request with comments on the source
+// lines where mutants survived or coverage gaps remain. GitHub only accepts
+// inline comments on lines the pull request's diff shows, so the others are
+// listed in the review's body. It returns how many comments went inline.
+func (s *PRService) PostQualityReview(ctx context.Context, owner, repo string, number int, comments []ReviewComment) (int, error) {
+	if len(comments) == 0 {
+		return 0, nil
+	}
+
+	files, err := s.ListPRFiles(ctx, owner, repo, number)
+	if err != nil {
+		return 0, err
+	}
+	inDiff := make(map[string]map[int]bool, len(files))
+	for _, f := range files {
+		inDiff[f.Filename] = diffLines(f.Patch)
+	}
+
+	inline, rest := splitReviewComments(comments, inDiff)
+	if err := s.PostReview(ctx, owner, repo, number, qualityReviewBody(len(comments), rest), inline); err != nil {
+		return 0, err
+	}
+	return len(inline), nil
+}
+
+// splitReviewComments separates the comments on lines in the diff, which
+// can be posted inline, from the rest. Inline comments beyond
+// maxReviewComments join the rest.
+func splitReviewComments(comments []ReviewComment, inDiff map[string]map[int]bool) (inline, rest []ReviewComment) {
+	for _, c := range comments {
+		if len(inline) < maxReviewComments && inDiff[c.Path][c.Line] {
+			inline = append(inline, c)
+		} else {
+			rest = append(rest, c)
+		}
+	}
+	return inline, rest
+}
+
+// diffLines returns the lines of the new file a unified diff patch shows:
+// added lines and their context, which are the lines GitHub accepts review
+// comments on
+func diffLines(patch string) map[int]bool {
+	lines := make(map[int]bool)
+	line := 0
+	for _, l := range strings.Split(patch, "\n") {
+		switch {
+		case strings.HasPrefix(l, "@@"):
+			line = hunkStart(l)
+		case line == 0:
+			// Before the first hunk
+		case strings.HasPrefix(l, "-"), strings.HasPrefix(l, `\`):
+			// Removed lines and "\ No newline at end of file" aren't in the
+			// new file
+		default:
+			lines[line] = true
+			line++
+		}
+	}
+	return lines
+}
+
+// hunkStart returns the first new-file line of a hunk header such as
+// "@@ -1,4 +1,6 @@", or 0 when it can't be parsed
+func hunkStart(header string) int {
+	for _, field := range strings.Fields(header) {
+		if !strings.HasPrefix(field, "+") {
+			continue
+		}
+		start, _, _ := strings.Cut(field[1:], ",")
+		n, err := strconv.Atoi(start)
+		if err != nil {
+			return 0
+		}
+		return n
+	}
+	return 0
+}
+
+// qualityReviewBody summarises a quality review, listing the comments that
+// couldn't go inline by file and line
+func qualityReviewBody(total int, rest []ReviewComment) string {
+	var sb strings.Builder
+	sb.WriteString("## QTest Quality Review\n\n")
+	sb.WriteString(fmt.Sprintf("QTest found **%d** places the generated tests don't fully check: "+
+		"lines where mutants survived or coverage gaps remain.\n", total))
+	if len(rest) == 0 {
+		return sb.String()
+	}
+
+	sorted := make([]ReviewComment, len(rest))
+	copy(sorted, rest)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Path != sorted[j].Path {
+			return sorted[i].Path < sorted[j].Path
+		}
+		return sorted[i].Line < sorted[j].Line
+	})
+
+	sb.WriteString("\nThese lines are outside this PR's diff, or past the inline comment limit:\n\n")
+	for i, c := range sorted {
+		if i == maxReviewComments {
+			sb.WriteString(fmt.Sprintf("- ...and %d more\n", len(sorted)-i))
+			break
+		}
+		sb.WriteString(fmt.Sprintf("- `%s:%d` %s\n", c.Path, c.Line, firstLine(c.Body)))
+	}
+	return sb.String()
+}
+
+// firstLine returns the first line of s
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
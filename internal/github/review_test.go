@@ -0,0 +1,91 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDiffLines(t *testing.T) {
+	patch := "@@ -1,3 +1,4 @@\n package calc\n-func Old() {}\n+func New() {}\n+\n func Add() {}\n@@ -20,2 +21,1 @@\n-x\n y\n\\ No newline at end of file"
+	lines := diffLines(patch)
+	for _, n := range []int{1, 2, 3, 4, 21} {
+		if !lines[n] {
+			t.Errorf("line %d should be in the diff", n)
+		}
+	}
+	if lines[5] || lines[22] || len(lines) != 5 {
+		t.Errorf("diffLines() = %v, want lines 1-4 and 21", lines)
+	}
+}
+
+func TestSplitReviewComments(t *testing.T) {
+	inDiff := map[string]map[int]bool{"calc.go": {3: true}}
+	comments := []ReviewComment{
+		{Path: "calc.go", Line: 3, Body: "a"},
+		{Path: "calc.go", Line: 9, Body: "b"},
+		{Path: "other.go", Line: 3, Body: "c"},
+	}
+	inline, rest := splitReviewComments(comments, inDiff)
+	if len(inline) != 1 || inline[0].Body != "a" || len(rest) != 2 {
+		t.Errorf("splitReviewComments() = %v, %v", inline, rest)
+	}
+}
+
+func TestPRService_PostQualityReview(t *testing.T) {
+	var review map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/o/r/pulls/7/files":
+			w.Write([]byte(`[{"filename":"calc.go","status":"modified","patch":"@@ -1,2 +1,3 @@\n package calc\n+// added\n func Add() {}"}]`))
+		case "/repos/o/r/pulls/7/reviews":
+			json.NewDecoder(r.Body).Decode(&review)
+			w.WriteHeader(200)
+		default:
+			w.WriteHeader(404)
+		}
+	}))
+	defer server.Close()
+
+	svc := NewPRService("test-token")
+	svc.baseURL = server.URL
+
+	inline, err := svc.PostQualityReview(context.Background(), "o", "r", 7, []ReviewComment{
+		{Path: "calc.go", Line: 2, Body: "**Surviving mutant** (arithmetic): + -> -"},
+		{Path: "util.go", Line: 10, Body: "**Coverage gap**: no test was generated for `Pad`."},
+	})
+	if err != nil {
+		t.Fatalf("PostQualityReview() error = %v", err)
+	}
+	if inline != 1 {
+		t.Errorf("inline = %d, want 1", inline)
+	}
+
+	if review["event"] != "COMMENT" {
+		t.Errorf("event = %v, want COMMENT", review["event"])
+	}
+	comments, _ := review["comments"].([]interface{})
+	if len(comments) != 1 {
+		t.Fatalf("comments = %v, want 1", review["comments"])
+	}
+	c := comments[0].(map[string]interface{})
+	if c["path"] != "calc.go" || c["line"] != float64(2) || c["side"] != "RIGHT" {
+		t.Errorf("comment = %v", c)
+	}
+	body, _ := review["body"].(string)
+	if !strings.Contains(body, "**2** places") || !strings.Contains(body, "`util.go:10` **Coverage gap**") {
+		t.Errorf("review body = %q", body)
+	}
+}
+
+func TestPRService_PostQualityReview_NoComments(t *testing.T) {
+	svc := NewPRService("test-token")
+	svc.baseURL = "http://127.0.0.1:0"
+
+	if inline, err := svc.PostQualityReview(context.Background(), "o", "r", 7, nil); err != nil || inline != 0 {
+		t.Errorf("PostQualityReview(nil) = %d, %v, want no request", inline, err)
+	}
+}
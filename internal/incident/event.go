@@ -0,0 +1,95 @@
+// Package incident turns production error events into reproduction tests.
+// It reads Sentry events and OpenTelemetry exception spans, finds the
+// function or endpoint the stack trace implicates in the system model, and
+// builds a test spec that replays the failing input, so every incident can
+// leave a regression test behind.
+package incident
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Event sources
+const (
+	SourceSentry = "sentry"
+	SourceOTel   = "otel"
+)
+
+// Event is an error reported from production
+type Event struct {
+	ID        string
+	Source    string // sentry or otel
+	Type      string // exception class, e.g. ZeroDivisionError
+	Message   string
+	Frames    []Frame // innermost (where the error was raised) first
+	Request   *Request
+	Timestamp time.Time
+}
+
+// Frame is one stack frame
+type Frame struct {
+	File     string
+	Function string
+	Line     int
+	InApp    bool                   // application code rather than a library
+	Vars     map[string]interface{} // local variables, when the SDK captured them
+}
+
+// Request is the HTTP request being handled when the error happened
+type Request struct {
+	Method  string
+	Path    string
+	Route   string // route template, when the tracer recorded it (http.route)
+	Query   map[string]string
+	Headers map[string]string
+	Body    interface{} // decoded JSON, form values or raw text
+}
+
+// Title summarizes the event, e.g. "KeyError: 'email'"
+func (e *Event) Title() string {
+	switch {
+	case e.Type != "" && e.Message != "":
+		return e.Type + ": " + e.Message
+	case e.Type != "":
+		return e.Type
+	default:
+		return e.Message
+	}
+}
+
+// Parse reads an event from a Sentry event (or webhook payload) or an
+// OTLP/JSON trace export, detecting which it is
+func Parse(data []byte) (*Event, error) {
+	var probe map[string]json.RawMessage
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return nil, fmt.Errorf("failed to parse event: %w", err)
+	}
+	if _, ok := probe["resourceSpans"]; ok {
+		return ParseOTel(data)
+	}
+	return ParseSentry(data)
+}
+
+// appFrames returns the in-app frames, or all frames when the source
+// didn't mark any as in-app
+func (e *Event) appFrames() []Frame {
+	var frames []Frame
+	for _, f := range e.Frames {
+		if f.InApp {
+			frames = append(frames, f)
+		}
+	}
+	if len(frames) == 0 {
+		return e.Frames
+	}
+	return frames
+}
+
+// splitPath separates a URL path from its query string
+func splitPath(target string) (string, map[string]string) {
+	path, rawQuery, _ := strings.Cut(target, "?")
+	return path, parseQuery(rawQuery)
+}
@@ -0,0 +1,150 @@
+package incident
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// otelExport is the part of an OTLP/JSON trace export qtest reads, as
+// written by the collector's file exporter or an OTLP/HTTP JSON request
+type otelExport struct {
+	ResourceSpans []struct {
+		ScopeSpans []struct {
+			Spans []otelSpan `json:"spans"`
+		} `json:"scopeSpans"`
+	} `json:"resourceSpans"`
+}
+
+type otelSpan struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	ParentSpanID      string          `json:"parentSpanId"`
+	Kind              int             `json:"kind"`
+	StartTimeUnixNano json.RawMessage `json:"startTimeUnixNano"` // string per the JSON mapping
+	Attributes        []otelAttribute `json:"attributes"`
+	Events            []struct {
+		Name         string          `json:"name"`
+		TimeUnixNano json.RawMessage `json:"timeUnixNano"`
+		Attributes   []otelAttribute `json:"attributes"`
+	} `json:"events"`
+}
+
+type otelAttribute struct {
+	Key   string `json:"key"`
+	Value struct {
+		StringValue *string         `json:"stringValue"`
+		IntValue    json.RawMessage `json:"intValue"`
+		BoolValue   *bool           `json:"boolValue"`
+	} `json:"value"`
+}
+
+// otelSpanKindServer marks spans for requests a service handled
+const otelSpanKindServer = 2
+
+// ParseOTel reads the first exception recorded in an OTLP/JSON trace
+// export. The request comes from the span that recorded the exception or,
+// failing that, the server span of the same trace.
+func ParseOTel(data []byte) (*Event, error) {
+	var export otelExport
+	if err := json.Unmarshal(data, &export); err != nil {
+		return nil, fmt.Errorf("failed to parse OTLP export: %w", err)
+	}
+
+	var spans []otelSpan
+	for _, rs := range export.ResourceSpans {
+		for _, ss := range rs.ScopeSpans {
+			spans = append(spans, ss.Spans...)
+		}
+	}
+
+	for _, span := range spans {
+		for _, ev := range span.Events {
+			if ev.Name != "exception" {
+				continue
+			}
+			attrs := attributeMap(ev.Attributes)
+			e := &Event{
+				ID:        span.TraceID + "-" + span.SpanID,
+				Source:    SourceOTel,
+				Type:      attrs["exception.type"],
+				Message:   attrs["exception.message"],
+				Frames:    ParseStackTrace(attrs["exception.stacktrace"]),
+				Timestamp: unixNano(ev.TimeUnixNano),
+			}
+			e.Request = otelRequest(span)
+			if e.Request == nil {
+				for _, other := range spans {
+					if other.TraceID == span.TraceID && other.Kind == otelSpanKindServer {
+						if e.Request = otelRequest(other); e.Request != nil {
+							break
+						}
+					}
+				}
+			}
+			return e, nil
+		}
+	}
+	return nil, fmt.Errorf("no exception event found in the trace export")
+}
+
+// otelRequest reads the HTTP request from a span's attributes, under
+// either the current or the pre-1.20 semantic convention names
+func otelRequest(span otelSpan) *Request {
+	attrs := attributeMap(span.Attributes)
+	method := first(attrs, "http.request.method", "http.method")
+	target := first(attrs, "url.path", "http.target")
+	if method == "" || target == "" {
+		return nil
+	}
+
+	path, query := splitPath(target)
+	if q := parseQuery(attrs["url.query"]); len(q) > 0 {
+		query = q
+	}
+	req := &Request{
+		Method: strings.ToUpper(method),
+		Path:   path,
+		Route:  attrs["http.route"],
+		Query:  query,
+	}
+	if body := attrs["http.request.body"]; body != "" {
+		req.Body = decodeBody(json.RawMessage(strconv.Quote(body)))
+	}
+	return req
+}
+
+func attributeMap(attrs []otelAttribute) map[string]string {
+	out := make(map[string]string, len(attrs))
+	for _, a := range attrs {
+		switch {
+		case a.Value.StringValue != nil:
+			out[a.Key] = *a.Value.StringValue
+		case len(a.Value.IntValue) > 0:
+			out[a.Key] = strings.Trim(string(a.Value.IntValue), `"`)
+		case a.Value.BoolValue != nil:
+			out[a.Key] = strconv.FormatBool(*a.Value.BoolValue)
+		}
+	}
+	return out
+}
+
+func first(attrs map[string]string, keys ...string) string {
+	for _, k := range keys {
+		if v := attrs[k]; v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// unixNano reads an OTLP timestamp, encoded as a string or a number
+func unixNano(raw json.RawMessage) time.Time {
+	n, err := strconv.ParseInt(strings.Trim(string(raw), `"`), 10, 64)
+	if err != nil || n == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, n).UTC()
+}
@@ -0,0 +1,80 @@
+package incident
+
+import (
+	"testing"
+)
+
+const otelTrace = `{"resourceSpans": [{"scopeSpans": [{"spans": [
+  {"traceId": "5b8efff798038103d269b633813fc60c", "spanId": "aaaa", "kind": 2,
+   "attributes": [
+     {"key": "http.request.method", "value": {"stringValue": "GET"}},
+     {"key": "url.path", "value": {"stringValue": "/orders/17"}},
+     {"key": "url.query", "value": {"stringValue": "expand=items"}},
+     {"key": "http.route", "value": {"stringValue": "/orders/{id}"}},
+     {"key": "http.response.status_code", "value": {"intValue": "500"}}
+   ]},
+  {"traceId": "5b8efff798038103d269b633813fc60c", "spanId": "bbbb", "parentSpanId": "aaaa", "kind": 1,
+   "events": [{"name": "exception", "timeUnixNano": "1760000000000000000", "attributes": [
+     {"key": "exception.type", "value": {"stringValue": "ZeroDivisionError"}},
+     {"key": "exception.message", "value": {"stringValue": "division by zero"}},
+     {"key": "exception.stacktrace", "value": {"stringValue": "Traceback (most recent call last):\n  File \"/app/orders.py\", line 10, in get_order\n    return total(order)\n  File \"/app/pricing.py\", line 5, in average\n    return s / n\nZeroDivisionError: division by zero"}}
+   ]}]}
+]}]}]}`
+
+func TestParseOTel(t *testing.T) {
+	e, err := ParseOTel([]byte(otelTrace))
+	if err != nil {
+		t.Fatalf("ParseOTel() error: %v", err)
+	}
+	if e.Source != SourceOTel || e.ID != "5b8efff798038103d269b633813fc60c-bbbb" {
+		t.Errorf("Source, ID = %q, %q", e.Source, e.ID)
+	}
+	if e.Title() != "ZeroDivisionError: division by zero" {
+		t.Errorf("Title() = %q", e.Title())
+	}
+	if e.Timestamp.Unix() != 1760000000 {
+		t.Errorf("Timestamp = %v", e.Timestamp)
+	}
+	if len(e.Frames) != 2 || e.Frames[0].Function != "average" {
+		t.Errorf("Frames = %+v, want average innermost", e.Frames)
+	}
+
+	// The exception's span has no HTTP attributes, so the server span is used
+	req := e.Request
+	if req == nil {
+		t.Fatal("Request = nil, want the server span's request")
+	}
+	if req.Method != "GET" || req.Path != "/orders/17" || req.Route != "/orders/{id}" {
+		t.Errorf("Request = %+v", req)
+	}
+	if req.Query["expand"] != "items" {
+		t.Errorf("Query = %v", req.Query)
+	}
+}
+
+func TestParseOTel_LegacyAttributes(t *testing.T) {
+	data := `{"resourceSpans": [{"scopeSpans": [{"spans": [
+	  {"traceId": "t1", "spanId": "s1", "kind": 2,
+	   "attributes": [
+	     {"key": "http.method", "value": {"stringValue": "post"}},
+	     {"key": "http.target", "value": {"stringValue": "/login?next=/home"}}
+	   ],
+	   "events": [{"name": "exception", "attributes": [
+	     {"key": "exception.type", "value": {"stringValue": "TypeError"}}
+	   ]}]}
+	]}]}]}`
+	e, err := ParseOTel([]byte(data))
+	if err != nil {
+		t.Fatalf("ParseOTel() error: %v", err)
+	}
+	if e.Request == nil || e.Request.Method != "POST" || e.Request.Path != "/login" || e.Request.Query["next"] != "/home" {
+		t.Errorf("Request = %+v", e.Request)
+	}
+}
+
+func TestParseOTel_NoException(t *testing.T) {
+	data := `{"resourceSpans": [{"scopeSpans": [{"spans": [{"traceId": "t1", "spanId": "s1"}]}]}]}`
+	if _, err := ParseOTel([]byte(data)); err == nil {
+		t.Error("expected error for a trace with no exception event")
+	}
+}
@@ -0,0 +1,249 @@
+package incident
+
+import (
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/QTest-hq/qtest/internal/capture"
+	"github.com/QTest-hq/qtest/pkg/model"
+)
+
+// Tags on reproduction specs
+const (
+	TagIncident   = "incident"
+	TagRegression = "regression"
+)
+
+// serverErrorStatus is the status a reproduced request must stay below:
+// the incident was an unhandled error, so any 5xx means it's still there
+const serverErrorStatus = 500
+
+// receiverParams are implicit parameters with no captured input
+var receiverParams = map[string]bool{"self": true, "cls": true, "this": true}
+
+// Reproduction is a test spec that replays an incident
+type Reproduction struct {
+	Event    *Event
+	Spec     model.TestSpec
+	Function *model.Function // implicated function, if found in the model
+	Endpoint *model.Endpoint // endpoint that received the request, if known
+	Frame    *Frame          // stack frame matched to Function
+}
+
+// Reproduce builds a spec that fails while the incident's bug is present.
+// When the event carries the HTTP request, the spec replays it against the
+// endpoint and asserts there's no server error. Otherwise it calls the
+// implicated function with the arguments captured in its stack frame, so
+// the test fails by raising the same error. Payloads go through the
+// sanitizer, since they come from production.
+func Reproduce(e *Event, m *model.SystemModel, s *capture.Sanitizer) (*Reproduction, error) {
+	if s == nil {
+		s = capture.NewSanitizer()
+	}
+	r := &Reproduction{Event: e}
+	r.Function, r.Frame = Locate(e, m)
+
+	switch {
+	case e.Request != nil:
+		r.Spec = r.apiSpec(m, s)
+	case r.Function != nil:
+		spec, err := r.unitSpec(s)
+		if err != nil {
+			return nil, err
+		}
+		r.Spec = spec
+	default:
+		return nil, fmt.Errorf("can't reproduce %q: the event has no request and no stack frame matches a function in the model", e.Title())
+	}
+
+	r.Spec.ID = "spec:incident:" + shortID(e.ID)
+	r.Spec.Description = "reproduces " + description(e.Title(), s)
+	r.Spec.Tags = []string{TagIncident, TagRegression}
+	r.Spec.Priority = "high"
+	return r, nil
+}
+
+func (r *Reproduction) apiSpec(m *model.SystemModel, s *capture.Sanitizer) model.TestSpec {
+	req := r.Event.Request
+	template, params, endpointID := capture.MatchEndpoint(m.Endpoints, req.Method, req.Path)
+	if endpointID == "" && req.Route != "" {
+		template, params, _ = capture.MatchEndpoint([]model.Endpoint{{Path: req.Route}}, req.Method, req.Path)
+	}
+	for i := range m.Endpoints {
+		if m.Endpoints[i].ID == endpointID && endpointID != "" {
+			r.Endpoint = &m.Endpoints[i]
+		}
+	}
+
+	spec := model.TestSpec{
+		Level:      model.LevelAPI,
+		TargetKind: "endpoint",
+		TargetID:   endpointID,
+		Method:     req.Method,
+		Path:       template,
+		Assertions: []model.Assertion{{Kind: "less_than", Actual: "status", Expected: serverErrorStatus}},
+	}
+	for name, value := range params {
+		if spec.PathParams == nil {
+			spec.PathParams = make(map[string]interface{})
+		}
+		spec.PathParams[name] = s.String(name, value)
+	}
+	for name, value := range req.Query {
+		if spec.QueryParams == nil {
+			spec.QueryParams = make(map[string]interface{})
+		}
+		spec.QueryParams[name] = s.String(name, value)
+	}
+	if ct := req.Headers["Content-Type"]; ct != "" {
+		spec.Headers = s.Headers(http.Header{"Content-Type": {ct}})
+	}
+	if req.Body != nil {
+		spec.Body = s.Value("", req.Body)
+	}
+	return spec
+}
+
+func (r *Reproduction) unitSpec(s *capture.Sanitizer) (model.TestSpec, error) {
+	fn := r.Function
+	spec := model.TestSpec{
+		Level:        model.LevelUnit,
+		TargetKind:   "function",
+		TargetID:     fn.ID,
+		FunctionName: fn.Name,
+		Async:        fn.Async,
+		Assertions:   []model.Assertion{}, // raising the error fails the test
+	}
+
+	var missing []string
+	for _, p := range fn.Parameters {
+		if receiverParams[p.Name] {
+			continue
+		}
+		value, ok := r.Frame.Vars[p.Name]
+		if !ok {
+			if !p.Optional && p.Default == "" {
+				missing = append(missing, p.Name)
+			}
+			continue
+		}
+		if spec.Inputs == nil {
+			spec.Inputs = make(map[string]interface{})
+			spec.InputTypes = make(map[string]string)
+		}
+		spec.Inputs[p.Name] = s.Value(p.Name, frameValue(value))
+		if p.Type != "" {
+			spec.InputTypes[p.Name] = p.Type
+		}
+		spec.ArgOrder = append(spec.ArgOrder, p.Name)
+	}
+	if len(missing) > 0 {
+		return spec, fmt.Errorf("can't reproduce %q in %s: the stack frame didn't capture %s (enable local variable capture in the SDK)",
+			r.Event.Title(), fn.Name, strings.Join(missing, ", "))
+	}
+	return spec, nil
+}
+
+// Locate finds the function the stack trace implicates: the innermost
+// application frame that matches a model function by name and file
+func Locate(e *Event, m *model.SystemModel) (*model.Function, *Frame) {
+	frames := e.appFrames()
+	for i := range frames {
+		f := &frames[i]
+		name := functionName(f.Function)
+		if name == "" {
+			continue
+		}
+
+		var best *model.Function
+		bestScore := 0
+		for j := range m.Functions {
+			fn := &m.Functions[j]
+			if fn.Name != name {
+				continue
+			}
+			score := pathSuffixMatch(fn.File, f.File)
+			if score > 0 && f.Line > 0 && f.Line >= fn.StartLine && f.Line <= fn.EndLine {
+				score += 100 // the frame's line falls inside the function
+			}
+			if score > bestScore {
+				best, bestScore = fn, score
+			}
+		}
+		if best != nil {
+			return best, f
+		}
+	}
+	return nil, nil
+}
+
+// functionName strips module, class and receiver qualifiers from a frame's
+// function, e.g. main.(*Service).GetUser, com.acme.UserService.getUser or
+// Object.getUser. Anonymous functions have no name.
+func functionName(function string) string {
+	name := function
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+	if name == "<anonymous>" || name == "<lambda>" || name == "<module>" || strings.HasPrefix(name, "func") && strings.TrimLeft(name[4:], "0123456789") == "" {
+		return ""
+	}
+	return name
+}
+
+// pathSuffixMatch counts the trailing path segments two files share, so
+// /app/services/users.py matches services/users.py with a score of 2
+func pathSuffixMatch(a, b string) int {
+	as := strings.Split(filepath.ToSlash(a), "/")
+	bs := strings.Split(filepath.ToSlash(b), "/")
+	n := 0
+	for i, j := len(as)-1, len(bs)-1; i >= 0 && j >= 0 && as[i] == bs[j] && as[i] != ""; i, j = i-1, j-1 {
+		n++
+	}
+	return n
+}
+
+// TargetName describes what the reproduction exercises, for output
+func (r *Reproduction) TargetName() string {
+	if r.Spec.Level == model.LevelAPI {
+		return r.Spec.Method + " " + r.Spec.Path
+	}
+	if r.Function.Class != "" {
+		return r.Function.Class + "." + r.Function.Name
+	}
+	return r.Function.Name
+}
+
+// shortID shortens an event ID for spec IDs and file names, keeping only
+// letters and digits
+func shortID(id string) string {
+	id = strings.Map(func(r rune) rune {
+		if r < 128 && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			return r
+		}
+		return -1
+	}, id)
+	if len(id) > 12 {
+		return id[:12]
+	}
+	if id == "" {
+		return "unknown"
+	}
+	return id
+}
+
+// quoteChars would need escaping in every emitter's test names
+var quoteChars = strings.NewReplacer("'", "", `"`, "", "`", "", `\`, "")
+
+// description makes a test name from an error title. Messages often quote
+// user input, so the title is sanitized, unquoted and shortened.
+func description(title string, s *capture.Sanitizer) string {
+	title = strings.Join(strings.Fields(quoteChars.Replace(s.String("message", title))), " ")
+	if len(title) > 80 {
+		title = title[:77] + "..."
+	}
+	return title
+}
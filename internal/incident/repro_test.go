@@ -0,0 +1,201 @@
+package incident
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/QTest-hq/qtest/internal/capture"
+	"github.com/QTest-hq/qtest/pkg/model"
+)
+
+func testModel() *model.SystemModel {
+	return &model.SystemModel{
+		Functions: []model.Function{
+			{ID: "fn:old", Name: "register", File: "legacy/users.py", StartLine: 1, EndLine: 20},
+			{
+				ID: "fn:register", Name: "register", Class: "UserService", File: "app/services/users.py", StartLine: 30, EndLine: 60,
+				Parameters: []model.Parameter{
+					{Name: "self"},
+					{Name: "payload", Type: "dict"},
+					{Name: "notify", Type: "bool"},
+					{Name: "retries", Type: "int", Default: "3"},
+				},
+			},
+			{ID: "fn:create", Name: "create_user", File: "app/routes.py", StartLine: 10, EndLine: 15},
+		},
+		Endpoints: []model.Endpoint{
+			{ID: "ep:create-user", Method: "POST", Path: "/users", Handler: "fn:create"},
+			{ID: "ep:get-user", Method: "GET", Path: "/users/:id"},
+		},
+	}
+}
+
+func TestReproduce_Endpoint(t *testing.T) {
+	e := &Event{
+		ID:      "9a1b2c3d-4e5f-6071-8293",
+		Type:    "KeyError",
+		Message: `'email' for "ann@corp.com"`,
+		Request: &Request{
+			Method:  "POST",
+			Path:    "/users",
+			Query:   map[string]string{"token": "abc123"},
+			Headers: map[string]string{"Content-Type": "application/json", "Authorization": "Bearer secret"},
+			Body:    map[string]interface{}{"name": "Ann", "email": "ann@corp.com", "password": "hunter2"},
+		},
+	}
+	r, err := Reproduce(e, testModel(), capture.NewSeededSanitizer(1))
+	if err != nil {
+		t.Fatalf("Reproduce() error: %v", err)
+	}
+
+	spec := r.Spec
+	if spec.Level != model.LevelAPI || spec.TargetID != "ep:create-user" || r.Endpoint == nil {
+		t.Errorf("Level, TargetID = %q, %q, want the matched endpoint", spec.Level, spec.TargetID)
+	}
+	if spec.ID != "spec:incident:9a1b2c3d4e5f" {
+		t.Errorf("ID = %q", spec.ID)
+	}
+	if len(spec.Assertions) != 1 || spec.Assertions[0].Kind != "less_than" || spec.Assertions[0].Expected != serverErrorStatus {
+		t.Errorf("Assertions = %+v, want status below 500", spec.Assertions)
+	}
+	if len(spec.Tags) != 2 || spec.Tags[0] != TagIncident || spec.Tags[1] != TagRegression {
+		t.Errorf("Tags = %v", spec.Tags)
+	}
+
+	if _, ok := spec.Headers["Authorization"]; ok || spec.Headers["Content-Type"] != "application/json" {
+		t.Errorf("Headers = %v, want only Content-Type", spec.Headers)
+	}
+	if spec.QueryParams["token"] != capture.Redacted {
+		t.Errorf("QueryParams = %v, want the token redacted", spec.QueryParams)
+	}
+	body := spec.Body.(map[string]interface{})
+	if body["password"] != capture.Redacted || body["email"] == "ann@corp.com" {
+		t.Errorf("Body = %v, want production values sanitized", body)
+	}
+
+	if strings.ContainsAny(spec.Description, `'"`) || strings.Contains(spec.Description, "ann@corp.com") {
+		t.Errorf("Description = %q, want it unquoted and sanitized", spec.Description)
+	}
+	if !strings.HasPrefix(spec.Description, "reproduces KeyError: email for ") {
+		t.Errorf("Description = %q", spec.Description)
+	}
+}
+
+func TestReproduce_RouteFallback(t *testing.T) {
+	e := &Event{ID: "t1", Type: "ZeroDivisionError", Request: &Request{Method: "GET", Path: "/orders/17", Route: "/orders/{id}"}}
+	r, err := Reproduce(e, testModel(), nil)
+	if err != nil {
+		t.Fatalf("Reproduce() error: %v", err)
+	}
+	if r.Spec.Path != "/orders/{id}" || r.Spec.PathParams["id"] != "17" || r.Spec.TargetID != "" {
+		t.Errorf("Path, PathParams, TargetID = %q, %v, %q", r.Spec.Path, r.Spec.PathParams, r.Spec.TargetID)
+	}
+	if r.TargetName() != "GET /orders/{id}" {
+		t.Errorf("TargetName() = %q", r.TargetName())
+	}
+}
+
+func TestReproduce_Function(t *testing.T) {
+	e := &Event{
+		ID:   "abc",
+		Type: "KeyError",
+		Frames: []Frame{
+			{File: "/srv/app/services/users.py", Function: "register", Line: 42, InApp: true,
+				Vars: map[string]interface{}{"self": "<UserService>", "payload": "{'name': 'Ann', 'email': 'ann@corp.com'}", "notify": "True"}},
+			{File: "/srv/app/routes.py", Function: "create_user", Line: 12, InApp: true},
+		},
+	}
+	r, err := Reproduce(e, testModel(), capture.NewSeededSanitizer(1))
+	if err != nil {
+		t.Fatalf("Reproduce() error: %v", err)
+	}
+
+	spec := r.Spec
+	if spec.Level != model.LevelUnit || spec.TargetID != "fn:register" || spec.FunctionName != "register" {
+		t.Errorf("Level, TargetID, FunctionName = %q, %q, %q", spec.Level, spec.TargetID, spec.FunctionName)
+	}
+	if r.TargetName() != "UserService.register" {
+		t.Errorf("TargetName() = %q", r.TargetName())
+	}
+	if strings.Join(spec.ArgOrder, ",") != "payload,notify" {
+		t.Errorf("ArgOrder = %v, want the receiver and defaulted params skipped", spec.ArgOrder)
+	}
+	if spec.Inputs["notify"] != true || spec.InputTypes["payload"] != "dict" {
+		t.Errorf("Inputs = %v, InputTypes = %v", spec.Inputs, spec.InputTypes)
+	}
+	payload := spec.Inputs["payload"].(map[string]interface{})
+	if payload["email"] == "ann@corp.com" || payload["name"] == nil {
+		t.Errorf("payload = %v, want the email sanitized", payload)
+	}
+	if spec.Assertions == nil || len(spec.Assertions) != 0 {
+		t.Errorf("Assertions = %v, want none", spec.Assertions)
+	}
+}
+
+func TestReproduce_MissingVars(t *testing.T) {
+	e := &Event{ID: "abc", Type: "KeyError", Frames: []Frame{
+		{File: "app/services/users.py", Function: "UserService.register", Line: 42, InApp: true},
+	}}
+	_, err := Reproduce(e, testModel(), nil)
+	if err == nil || !strings.Contains(err.Error(), "payload, notify") {
+		t.Errorf("Reproduce() error = %v, want the missing params listed", err)
+	}
+}
+
+func TestReproduce_NothingToReplay(t *testing.T) {
+	e := &Event{ID: "abc", Message: "boom", Frames: []Frame{{File: "lib/other.py", Function: "helper", InApp: true}}}
+	if _, err := Reproduce(e, testModel(), nil); err == nil {
+		t.Error("expected error when there's no request and no matching function")
+	}
+}
+
+func TestLocate(t *testing.T) {
+	m := testModel()
+	tests := []struct {
+		name   string
+		frames []Frame
+		want   string
+	}{
+		{"path and line", []Frame{{File: "/srv/app/services/users.py", Function: "register", Line: 42, InApp: true}}, "fn:register"},
+		{"path only", []Frame{{File: "legacy/users.py", Function: "register", InApp: true}}, "fn:old"},
+		{"skips library and anonymous frames", []Frame{
+			{File: "flask/app.py", Function: "register", InApp: false},
+			{File: "app/routes.py", Function: "<lambda>", InApp: true},
+			{File: "app/routes.py", Function: "create_user", Line: 12, InApp: true},
+		}, "fn:create"},
+		{"no match", []Frame{{File: "other.py", Function: "register", InApp: true}}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fn, frame := Locate(&Event{Frames: tt.frames}, m)
+			got := ""
+			if fn != nil {
+				got = fn.ID
+				if frame == nil {
+					t.Error("frame = nil for a located function")
+				}
+			}
+			if got != tt.want {
+				t.Errorf("Locate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFunctionName(t *testing.T) {
+	tests := map[string]string{
+		"main.(*Service).GetUser":      "GetUser",
+		"com.acme.UserService.getUser": "getUser",
+		"Object.getUser":               "getUser",
+		"get_user":                     "get_user",
+		"<lambda>":                     "",
+		"<anonymous>":                  "",
+		"main.main.func1":              "",
+		"app.funcs":                    "funcs",
+	}
+	for in, want := range tests {
+		if got := functionName(in); got != want {
+			t.Errorf("functionName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,270 @@
+package incident
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sentryEvent is the part of a Sentry event qtest reads
+type sentryEvent struct {
+	EventID   string          `json:"event_id"`
+	Message   json.RawMessage `json:"message"` // string, or {formatted: ...}
+	Timestamp json.RawMessage `json:"timestamp"`
+	Exception struct {
+		Values []struct {
+			Type       string `json:"type"`
+			Value      string `json:"value"`
+			Stacktrace *struct {
+				Frames []sentryFrame `json:"frames"`
+			} `json:"stacktrace"`
+		} `json:"values"`
+	} `json:"exception"`
+	Request *struct {
+		Method      string          `json:"method"`
+		URL         string          `json:"url"`
+		QueryString json.RawMessage `json:"query_string"` // "a=1" or [["a", "1"]]
+		Data        json.RawMessage `json:"data"`
+		Headers     json.RawMessage `json:"headers"` // object or [[name, value]]
+	} `json:"request"`
+}
+
+type sentryFrame struct {
+	Filename string                 `json:"filename"`
+	AbsPath  string                 `json:"abs_path"`
+	Function string                 `json:"function"`
+	Module   string                 `json:"module"`
+	Lineno   int                    `json:"lineno"`
+	InApp    bool                   `json:"in_app"`
+	Vars     map[string]interface{} `json:"vars"`
+}
+
+// ParseSentry reads a Sentry event, either as exported from the event's
+// JSON view or wrapped in an issue alert webhook payload
+func ParseSentry(data []byte) (*Event, error) {
+	data = unwrapSentryWebhook(data)
+
+	var raw sentryEvent
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse Sentry event: %w", err)
+	}
+
+	e := &Event{ID: raw.EventID, Source: SourceSentry, Message: sentryMessage(raw.Message)}
+	e.Timestamp = sentryTimestamp(raw.Timestamp)
+
+	// The last exception is the one raised; earlier ones are its causes
+	values := raw.Exception.Values
+	for i := len(values) - 1; i >= 0; i-- {
+		v := values[i]
+		if e.Type == "" {
+			e.Type, e.Message = v.Type, v.Value
+		}
+		if v.Stacktrace == nil || len(v.Stacktrace.Frames) == 0 {
+			continue
+		}
+		// Sentry lists frames outermost first
+		frames := v.Stacktrace.Frames
+		for j := len(frames) - 1; j >= 0; j-- {
+			f := frames[j]
+			file := f.Filename
+			if file == "" {
+				file = f.AbsPath
+			}
+			e.Frames = append(e.Frames, Frame{
+				File:     file,
+				Function: f.Function,
+				Line:     f.Lineno,
+				InApp:    f.InApp,
+				Vars:     f.Vars,
+			})
+		}
+		break
+	}
+
+	if raw.Request != nil && raw.Request.URL != "" {
+		e.Request = &Request{
+			Method:  strings.ToUpper(raw.Request.Method),
+			Headers: pairs(raw.Request.Headers),
+			Body:    decodeBody(raw.Request.Data),
+		}
+		if u, err := url.Parse(raw.Request.URL); err == nil {
+			e.Request.Path = u.Path
+			e.Request.Query = parseQuery(u.RawQuery)
+		}
+		if q := sentryQuery(raw.Request.QueryString); len(q) > 0 {
+			e.Request.Query = q
+		}
+		if e.Request.Method == "" {
+			e.Request.Method = "GET"
+		}
+	}
+
+	if e.Type == "" && e.Message == "" {
+		return nil, fmt.Errorf("not a Sentry error event: no exception or message")
+	}
+	return e, nil
+}
+
+// unwrapSentryWebhook returns the event from an alert webhook payload
+// ({"data": {"event": {...}}} or {"event": {...}}), or data unchanged
+func unwrapSentryWebhook(data []byte) []byte {
+	var wrapper struct {
+		Data *struct {
+			Event json.RawMessage `json:"event"`
+		} `json:"data"`
+		Event json.RawMessage `json:"event"`
+	}
+	if json.Unmarshal(data, &wrapper) != nil {
+		return data
+	}
+	if wrapper.Data != nil && len(wrapper.Data.Event) > 0 {
+		return wrapper.Data.Event
+	}
+	if len(wrapper.Event) > 0 && wrapper.Event[0] == '{' {
+		return wrapper.Event
+	}
+	return data
+}
+
+func sentryMessage(raw json.RawMessage) string {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+	var m struct {
+		Formatted string `json:"formatted"`
+		Message   string `json:"message"`
+	}
+	if json.Unmarshal(raw, &m) == nil {
+		if m.Formatted != "" {
+			return m.Formatted
+		}
+		return m.Message
+	}
+	return ""
+}
+
+// sentryTimestamp accepts RFC 3339 strings and Unix seconds
+func sentryTimestamp(raw json.RawMessage) time.Time {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		t, _ := time.Parse(time.RFC3339Nano, s)
+		return t
+	}
+	var f float64
+	if json.Unmarshal(raw, &f) == nil && f > 0 {
+		return time.Unix(0, int64(f*float64(time.Second))).UTC()
+	}
+	return time.Time{}
+}
+
+func sentryQuery(raw json.RawMessage) map[string]string {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return parseQuery(s)
+	}
+	return pairs(raw)
+}
+
+// pairs reads a {name: value} object or a [[name, value], ...] list
+func pairs(raw json.RawMessage) map[string]string {
+	if len(raw) == 0 {
+		return nil
+	}
+	var obj map[string]interface{}
+	if json.Unmarshal(raw, &obj) == nil {
+		out := make(map[string]string, len(obj))
+		for k, v := range obj {
+			out[k] = fmt.Sprint(v)
+		}
+		return out
+	}
+	var list [][]interface{}
+	if json.Unmarshal(raw, &list) == nil {
+		out := make(map[string]string, len(list))
+		for _, p := range list {
+			if len(p) == 2 {
+				out[fmt.Sprint(p[0])] = fmt.Sprint(p[1])
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+func parseQuery(rawQuery string) map[string]string {
+	values, err := url.ParseQuery(rawQuery)
+	if err != nil || len(values) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(values))
+	for k, v := range values {
+		out[k] = v[0]
+	}
+	return out
+}
+
+// decodeBody reads a request body recorded as JSON, as a JSON-encoded
+// string or as form text
+func decodeBody(raw json.RawMessage) interface{} {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil
+	}
+	var body interface{}
+	if json.Unmarshal(raw, &body) != nil {
+		return nil
+	}
+	s, ok := body.(string)
+	if !ok {
+		return body
+	}
+	var nested interface{}
+	if json.Unmarshal([]byte(s), &nested) == nil {
+		return nested
+	}
+	if form, err := url.ParseQuery(s); err == nil && strings.Contains(s, "=") {
+		out := make(map[string]interface{}, len(form))
+		for k, v := range form {
+			out[k] = v[0]
+		}
+		return out
+	}
+	return s
+}
+
+// frameValue converts a captured local variable to a test input. SDKs
+// record most values as their repr ('abc', 42, None, True), so those are
+// turned back into plain values.
+func frameValue(v interface{}) interface{} {
+	s, ok := v.(string)
+	if !ok {
+		return v
+	}
+	switch s {
+	case "None", "null", "nil", "undefined", "<nil>":
+		return nil
+	case "True", "true":
+		return true
+	case "False", "false":
+		return false
+	}
+	if n, err := strconv.ParseFloat(s, 64); err == nil {
+		return n
+	}
+	if len(s) >= 2 && (s[0] == '\'' && s[len(s)-1] == '\'' || s[0] == '"' && s[len(s)-1] == '"') {
+		return s[1 : len(s)-1]
+	}
+	var decoded interface{}
+	if json.Unmarshal([]byte(s), &decoded) == nil {
+		return decoded
+	}
+	// Python dict and list reprs are close enough to JSON
+	pyJSON := strings.NewReplacer("'", `"`, "None", "null", "True", "true", "False", "false").Replace(s)
+	if json.Unmarshal([]byte(pyJSON), &decoded) == nil {
+		return decoded
+	}
+	return s
+}
@@ -0,0 +1,136 @@
+package incident
+
+import (
+	"reflect"
+	"testing"
+)
+
+const sentryWebhook = `{
+  "action": "triggered",
+  "data": {
+    "event": {
+      "event_id": "9a1b2c3d4e5f60718293a4b5c6d7e8f9",
+      "timestamp": 1760000000.5,
+      "exception": {"values": [
+        {"type": "ValueError", "value": "bad input"},
+        {"type": "KeyError", "value": "'email'", "stacktrace": {"frames": [
+          {"filename": "flask/app.py", "function": "dispatch_request", "lineno": 880, "in_app": false},
+          {"filename": "app/routes.py", "function": "create_user", "lineno": 12, "in_app": true},
+          {"filename": "app/services/users.py", "function": "register", "lineno": 42, "in_app": true,
+           "vars": {"self": "<UserService>", "payload": "{'name': 'Ann'}", "notify": "True"}}
+        ]}}
+      ]},
+      "request": {
+        "method": "post",
+        "url": "https://api.example.com/users?invite=abc",
+        "query_string": [["invite", "xyz"]],
+        "data": "{\"name\": \"Ann\"}",
+        "headers": [["Content-Type", "application/json"], ["Authorization", "Bearer secret"]]
+      }
+    }
+  }
+}`
+
+func TestParseSentry_Webhook(t *testing.T) {
+	e, err := ParseSentry([]byte(sentryWebhook))
+	if err != nil {
+		t.Fatalf("ParseSentry() error: %v", err)
+	}
+	if e.Source != SourceSentry || e.ID != "9a1b2c3d4e5f60718293a4b5c6d7e8f9" {
+		t.Errorf("Source, ID = %q, %q", e.Source, e.ID)
+	}
+	if e.Title() != "KeyError: 'email'" {
+		t.Errorf("Title() = %q, want the last exception", e.Title())
+	}
+	if e.Timestamp.Unix() != 1760000000 {
+		t.Errorf("Timestamp = %v", e.Timestamp)
+	}
+
+	if len(e.Frames) != 3 {
+		t.Fatalf("len(Frames) = %d, want 3", len(e.Frames))
+	}
+	if f := e.Frames[0]; f.Function != "register" || f.File != "app/services/users.py" || f.Line != 42 || !f.InApp {
+		t.Errorf("Frames[0] = %+v, want the innermost frame", f)
+	}
+	if e.Frames[0].Vars["payload"] != "{'name': 'Ann'}" {
+		t.Errorf("Vars = %v", e.Frames[0].Vars)
+	}
+
+	req := e.Request
+	if req == nil {
+		t.Fatal("Request = nil")
+	}
+	if req.Method != "POST" || req.Path != "/users" {
+		t.Errorf("Method, Path = %q, %q", req.Method, req.Path)
+	}
+	if req.Query["invite"] != "xyz" {
+		t.Errorf("Query = %v, want query_string to win over the URL", req.Query)
+	}
+	if req.Headers["Content-Type"] != "application/json" {
+		t.Errorf("Headers = %v", req.Headers)
+	}
+	if !reflect.DeepEqual(req.Body, map[string]interface{}{"name": "Ann"}) {
+		t.Errorf("Body = %#v", req.Body)
+	}
+}
+
+func TestParseSentry_NotAnError(t *testing.T) {
+	if _, err := ParseSentry([]byte(`{"event_id": "abc"}`)); err == nil {
+		t.Error("expected error for an event with no exception or message")
+	}
+}
+
+func TestParse_DetectsSource(t *testing.T) {
+	e, err := Parse([]byte(`{"event_id": "abc", "message": {"formatted": "boom"}}`))
+	if err != nil || e.Source != SourceSentry || e.Message != "boom" {
+		t.Errorf("Parse(sentry) = %+v, %v", e, err)
+	}
+	e, err = Parse([]byte(otelTrace))
+	if err != nil || e.Source != SourceOTel {
+		t.Errorf("Parse(otel) = %+v, %v", e, err)
+	}
+	if _, err := Parse([]byte(`not json`)); err == nil {
+		t.Error("expected error for invalid JSON")
+	}
+}
+
+func TestDecodeBody(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want interface{}
+	}{
+		{`{"a": 1}`, map[string]interface{}{"a": float64(1)}},
+		{`"{\"a\": 1}"`, map[string]interface{}{"a": float64(1)}},
+		{`"a=1&b=2"`, map[string]interface{}{"a": "1", "b": "2"}},
+		{`"plain text"`, "plain text"},
+		{`null`, nil},
+		{``, nil},
+	}
+	for _, tt := range tests {
+		if got := decodeBody([]byte(tt.raw)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("decodeBody(%s) = %#v, want %#v", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestFrameValue(t *testing.T) {
+	tests := []struct {
+		in   interface{}
+		want interface{}
+	}{
+		{"None", nil},
+		{"True", true},
+		{"42", float64(42)},
+		{"'ann'", "ann"},
+		{`"ann"`, "ann"},
+		{"{'name': 'Ann', 'admin': False}", map[string]interface{}{"name": "Ann", "admin": false}},
+		{"[1, 2]", []interface{}{float64(1), float64(2)}},
+		{"<User 42>", "<User 42>"},
+		{float64(3), float64(3)},
+	}
+	for _, tt := range tests {
+		if got := frameValue(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("frameValue(%#v) = %#v, want %#v", tt.in, got, tt.want)
+		}
+	}
+}
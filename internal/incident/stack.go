@@ -0,0 +1,73 @@
+package incident
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// File "/app/users.py", line 42, in get_user
+	pythonFrame = regexp.MustCompile(`^\s*File "([^"]+)", line (\d+), in (\S+)`)
+	// at getUser (/app/src/users.js:42:13) or at /app/src/users.js:42:13
+	nodeFrame = regexp.MustCompile(`^\s*at (?:(?:async )?(\S+) \()?(.+?):(\d+):\d+\)?$`)
+	// at com.acme.UserService.getUser(UserService.java:42), with an optional
+	// module prefix (java.base/java.lang.Thread.run)
+	javaFrame = regexp.MustCompile(`^\s*at ([\w$.<>/@]+)\(([^:()]+):(\d+)\)`)
+	// main.(*Service).GetUser(0xc000010000)
+	//         /app/service.go:42 +0x1d
+	goFunc = regexp.MustCompile(`^(\S+)\([^()]*\)$`)
+	goFile = regexp.MustCompile(`^\s+(\S+\.go):(\d+)`)
+)
+
+// libraryPaths mark frames from dependencies or the runtime
+var libraryPaths = []string{"site-packages/", "dist-packages/", "node_modules/", "node:internal", "/usr/lib/", "/usr/local/go/", "/go/pkg/mod/", "GOROOT/", "java.", "javax.", "sun.", "jdk."}
+
+// ParseStackTrace reads a stack trace as printed by Python, Node.js, Java
+// or Go, returning frames innermost first
+func ParseStackTrace(trace string) []Frame {
+	lines := strings.Split(strings.ReplaceAll(trace, "\r\n", "\n"), "\n")
+
+	var frames []Frame
+	python := false
+	for i, line := range lines {
+		if m := pythonFrame.FindStringSubmatch(line); m != nil {
+			python = true
+			frames = append(frames, newFrame(m[1], m[3], m[2]))
+			continue
+		}
+		if m := javaFrame.FindStringSubmatch(line); m != nil {
+			frames = append(frames, newFrame(m[2], m[1], m[3]))
+			continue
+		}
+		if m := nodeFrame.FindStringSubmatch(line); m != nil {
+			frames = append(frames, newFrame(m[2], m[1], m[3]))
+			continue
+		}
+		if m := goFunc.FindStringSubmatch(line); m != nil && i+1 < len(lines) {
+			if f := goFile.FindStringSubmatch(lines[i+1]); f != nil {
+				frames = append(frames, newFrame(f[1], m[1], f[2]))
+			}
+		}
+	}
+
+	// Python tracebacks print the innermost call last
+	if python {
+		for i, j := 0, len(frames)-1; i < j; i, j = i+1, j-1 {
+			frames[i], frames[j] = frames[j], frames[i]
+		}
+	}
+	return frames
+}
+
+func newFrame(file, function, line string) Frame {
+	n, _ := strconv.Atoi(line)
+	f := Frame{File: file, Function: function, Line: n, InApp: true}
+	for _, lib := range libraryPaths {
+		if strings.Contains(file, lib) || strings.HasPrefix(function, lib) {
+			f.InApp = false
+			break
+		}
+	}
+	return f
+}
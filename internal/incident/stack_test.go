@@ -0,0 +1,77 @@
+package incident
+
+import (
+	"testing"
+)
+
+func TestParseStackTrace(t *testing.T) {
+	tests := []struct {
+		name  string
+		trace string
+		want  []Frame
+	}{
+		{
+			name: "python",
+			trace: `Traceback (most recent call last):
+  File "/usr/lib/python3.11/site-packages/flask/app.py", line 880, in dispatch_request
+  File "/app/users.py", line 42, in get_user
+    return USERS[user_id]
+KeyError: 7`,
+			want: []Frame{
+				{File: "/app/users.py", Function: "get_user", Line: 42, InApp: true},
+				{File: "/usr/lib/python3.11/site-packages/flask/app.py", Function: "dispatch_request", Line: 880},
+			},
+		},
+		{
+			name: "node",
+			trace: `TypeError: Cannot read properties of undefined (reading 'id')
+    at getUser (/app/src/users.js:42:13)
+    at async Layer.handle (/app/node_modules/express/lib/router/layer.js:95:5)
+    at /app/src/server.js:10:3`,
+			want: []Frame{
+				{File: "/app/src/users.js", Function: "getUser", Line: 42, InApp: true},
+				{File: "/app/node_modules/express/lib/router/layer.js", Function: "Layer.handle", Line: 95},
+				{File: "/app/src/server.js", Line: 10, InApp: true},
+			},
+		},
+		{
+			name: "java",
+			trace: `java.lang.NullPointerException
+	at com.acme.UserService.getUser(UserService.java:42)
+	at java.base/java.lang.Thread.run(Thread.java:833)`,
+			want: []Frame{
+				{File: "UserService.java", Function: "com.acme.UserService.getUser", Line: 42, InApp: true},
+				{File: "Thread.java", Function: "java.base/java.lang.Thread.run", Line: 833},
+			},
+		},
+		{
+			name: "go",
+			trace: `panic: runtime error: index out of range [3] with length 3
+
+goroutine 1 [running]:
+main.(*Service).GetUser(0xc000010000, 0x3)
+	/app/service.go:42 +0x1d
+main.main()
+	/app/main.go:10 +0x25`,
+			want: []Frame{
+				{File: "/app/service.go", Function: "main.(*Service).GetUser", Line: 42, InApp: true},
+				{File: "/app/main.go", Function: "main.main", Line: 10, InApp: true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseStackTrace(tt.trace)
+			if len(got) != len(tt.want) {
+				t.Fatalf("ParseStackTrace() = %+v, want %d frames", got, len(tt.want))
+			}
+			for i := range tt.want {
+				if got[i].File != tt.want[i].File || got[i].Function != tt.want[i].Function ||
+					got[i].Line != tt.want[i].Line || got[i].InApp != tt.want[i].InApp {
+					t.Errorf("frame %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,315 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// Outcomes for each repository submitted in a batch
+const (
+	BatchQueued         = "queued"          // a pipeline was started
+	BatchDuplicate      = "duplicate"       // same repository as an earlier entry
+	BatchAlreadyRunning = "already_running" // a pipeline was already in progress; JobID tracks it
+	BatchInvalid        = "invalid"         // the URL isn't a supported repository URL
+	BatchFailed         = "failed"          // the repository or pipeline couldn't be created
+)
+
+// Batch statuses
+const (
+	BatchStatusRunning   = "running"
+	BatchStatusCompleted = "completed"
+	BatchStatusFailed    = "failed" // finished, with at least one failed pipeline
+)
+
+// Batch is a set of repositories submitted together with shared pipeline
+// options
+type Batch struct {
+	ID             uuid.UUID       `json:"id"`
+	OrganizationID *uuid.UUID      `json:"organization_id,omitempty"` // nil when submitted without credentials
+	Options        json.RawMessage `json:"options"`
+	Status         string          `json:"status,omitempty"`
+	Summary        BatchSummary    `json:"summary"`
+	Items          []BatchItem     `json:"items,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+// BatchItem is one submitted repository and the pipeline tracking it
+type BatchItem struct {
+	Position     int        `json:"position"`
+	URL          string     `json:"url"`
+	RepositoryID *uuid.UUID `json:"repository_id,omitempty"`
+	JobID        *uuid.UUID `json:"job_id,omitempty"` // root of the pipeline's job chain
+	Outcome      string     `json:"outcome"`
+	Error        *string    `json:"error,omitempty"`
+	Status       JobStatus  `json:"status,omitempty"` // of the whole job chain
+	Stage        JobType    `json:"stage,omitempty"`  // latest job in the chain
+}
+
+// BatchSummary counts a batch's items by outcome and pipeline status
+type BatchSummary struct {
+	Total     int `json:"total"`
+	Queued    int `json:"queued"`
+	Skipped   int `json:"skipped"` // duplicates and invalid URLs
+	Pending   int `json:"pending"`
+	Running   int `json:"running"`
+	Completed int `json:"completed"`
+	Failed    int `json:"failed"` // including pipelines that couldn't be started
+}
+
+// chainJob is a job in a batch item's pipeline
+type chainJob struct {
+	Type   JobType
+	Status JobStatus
+}
+
+// CreateBatch inserts a batch before its pipelines are started
+func (r *Repository) CreateBatch(ctx context.Context, batch *Batch) error {
+	if batch.ID == uuid.Nil {
+		batch.ID = uuid.New()
+	}
+	if batch.CreatedAt.IsZero() {
+		batch.CreatedAt = time.Now()
+	}
+	if len(batch.Options) == 0 {
+		batch.Options = json.RawMessage("{}")
+	}
+
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO repository_batches (id, organization_id, options, created_at) VALUES ($1, $2, $3, $4)
+	`, batch.ID, batch.OrganizationID, batch.Options, batch.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create batch: %w", err)
+	}
+	return nil
+}
+
+// AddBatchItems records what happened to each repository of a batch
+func (r *Repository) AddBatchItems(ctx context.Context, batchID uuid.UUID, items []BatchItem) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, item := range items {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO repository_batch_items (batch_id, position, url, repository_id, job_id, outcome, error)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+		`, batchID, item.Position, item.URL, item.RepositoryID, item.JobID, item.Outcome, item.Error)
+		if err != nil {
+			return fmt.Errorf("failed to add batch item: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetBatch retrieves a batch with the current status of each pipeline, or
+// nil if it doesn't exist
+func (r *Repository) GetBatch(ctx context.Context, id uuid.UUID) (*Batch, error) {
+	batch := &Batch{}
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, organization_id, options, created_at FROM repository_batches WHERE id = $1
+	`, id).Scan(&batch.ID, &batch.OrganizationID, &batch.Options, &batch.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get batch: %w", err)
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT position, url, repository_id, job_id, outcome, error
+		FROM repository_batch_items
+		WHERE batch_id = $1
+		ORDER BY position
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list batch items: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var item BatchItem
+		if err := rows.Scan(&item.Position, &item.URL, &item.RepositoryID, &item.JobID, &item.Outcome, &item.Error); err != nil {
+			return nil, fmt.Errorf("failed to scan batch item: %w", err)
+		}
+		batch.Items = append(batch.Items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	chains, err := r.batchChains(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	batch.summarize(chains)
+	return batch, nil
+}
+
+// batchChains loads every job descending from each batch item's root job,
+// oldest first, keyed by item position
+func (r *Repository) batchChains(ctx context.Context, batchID uuid.UUID) (map[int][]chainJob, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		WITH RECURSIVE chain AS (
+			SELECT i.position, j.id, j.type, j.status, j.created_at
+			FROM repository_batch_items i
+			JOIN jobs j ON j.id = i.job_id
+			WHERE i.batch_id = $1
+			UNION ALL
+			SELECT c.position, j.id, j.type, j.status, j.created_at
+			FROM jobs j
+			JOIN chain c ON j.parent_job_id = c.id
+		)
+		SELECT position, type, status FROM chain ORDER BY position, created_at
+	`, batchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load batch jobs: %w", err)
+	}
+	defer rows.Close()
+
+	chains := make(map[int][]chainJob)
+	for rows.Next() {
+		var position int
+		var j chainJob
+		if err := rows.Scan(&position, &j.Type, &j.Status); err != nil {
+			return nil, fmt.Errorf("failed to scan batch job: %w", err)
+		}
+		chains[position] = append(chains[position], j)
+	}
+	return chains, rows.Err()
+}
+
+// ListBatches lists recent batches, newest first, with their item counts
+// by outcome; pipeline statuses need GetBatch
+func (r *Repository) ListBatches(ctx context.Context, limit int) ([]*Batch, error) {
+	return r.listBatches(ctx, "", limit)
+}
+
+// ListBatchesForOrganizations lists the recent batches submitted for any of
+// the organizations, like ListBatches
+func (r *Repository) ListBatchesForOrganizations(ctx context.Context, orgIDs []uuid.UUID, limit int) ([]*Batch, error) {
+	return r.listBatches(ctx, "WHERE b.organization_id = ANY($2)", limit, pq.Array(orgIDs))
+}
+
+// ListBatchesOutsideOrganizations lists the recent batches submitted
+// without an organization, like ListBatches
+func (r *Repository) ListBatchesOutsideOrganizations(ctx context.Context, limit int) ([]*Batch, error) {
+	return r.listBatches(ctx, "WHERE b.organization_id IS NULL", limit)
+}
+
+// listBatches lists recent batches matching where, whose arguments follow
+// the limit
+func (r *Repository) listBatches(ctx context.Context, where string, limit int, args ...interface{}) ([]*Batch, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT b.id, b.organization_id, b.options, b.created_at,
+		       COUNT(i.position),
+		       COUNT(i.position) FILTER (WHERE i.outcome IN ('queued', 'already_running')),
+		       COUNT(i.position) FILTER (WHERE i.outcome IN ('duplicate', 'invalid'))
+		FROM repository_batches b
+		LEFT JOIN repository_batch_items i ON i.batch_id = b.id
+		`+where+`
+		GROUP BY b.id
+		ORDER BY b.created_at DESC
+		LIMIT $1
+	`, append([]interface{}{limit}, args...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list batches: %w", err)
+	}
+	defer rows.Close()
+
+	batches := make([]*Batch, 0)
+	for rows.Next() {
+		b := &Batch{}
+		if err := rows.Scan(&b.ID, &b.OrganizationID, &b.Options, &b.CreatedAt, &b.Summary.Total, &b.Summary.Queued, &b.Summary.Skipped); err != nil {
+			return nil, fmt.Errorf("failed to scan batch: %w", err)
+		}
+		batches = append(batches, b)
+	}
+	return batches, rows.Err()
+}
+
+// summarize sets each item's pipeline status from its job chain and counts
+// the batch's items
+func (b *Batch) summarize(chains map[int][]chainJob) {
+	b.Summary = BatchSummary{Total: len(b.Items)}
+	for i := range b.Items {
+		item := &b.Items[i]
+		switch item.Outcome {
+		case BatchQueued, BatchAlreadyRunning:
+			b.Summary.Queued++
+		case BatchFailed:
+			b.Summary.Failed++
+			continue
+		default:
+			b.Summary.Skipped++
+			continue
+		}
+
+		item.Status, item.Stage = chainStatus(chains[item.Position])
+		switch item.Status {
+		case StatusPending:
+			b.Summary.Pending++
+		case StatusRunning:
+			b.Summary.Running++
+		case StatusCompleted:
+			b.Summary.Completed++
+		case StatusFailed, StatusCancelled:
+			b.Summary.Failed++
+		}
+	}
+
+	switch {
+	case b.Summary.Pending+b.Summary.Running > 0:
+		b.Status = BatchStatusRunning
+	case b.Summary.Failed > 0:
+		b.Status = BatchStatusFailed
+	default:
+		b.Status = BatchStatusCompleted
+	}
+}
+
+// chainStatus reduces a pipeline's jobs (oldest first) to one status and
+// the stage it reached. Workers create a job's successors before completing
+// it, so a chain with no unfinished jobs is done.
+func chainStatus(chain []chainJob) (JobStatus, JobType) {
+	if len(chain) == 0 {
+		return StatusPending, "" // the root job was deleted or not yet visible
+	}
+
+	var pending, running, completed, failed, cancelled int
+	for _, j := range chain {
+		switch j.Status {
+		case StatusPending:
+			pending++
+		case StatusRunning, StatusRetrying:
+			running++
+		case StatusCompleted:
+			completed++
+		case StatusFailed:
+			failed++
+		case StatusCancelled:
+			cancelled++
+		}
+	}
+
+	stage := chain[len(chain)-1].Type
+	switch {
+	case running > 0 || pending > 0 && completed > 0:
+		return StatusRunning, stage
+	case pending > 0:
+		return StatusPending, stage
+	case failed > 0:
+		return StatusFailed, stage
+	case cancelled > 0:
+		return StatusCancelled, stage
+	default:
+		return StatusCompleted, stage
+	}
+}
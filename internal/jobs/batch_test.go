@@ -0,0 +1,78 @@
+package jobs
+
+import "testing"
+
+func TestChainStatus(t *testing.T) {
+	tests := []struct {
+		name      string
+		chain     []chainJob
+		wantState JobStatus
+		wantStage JobType
+	}{
+		{"not started", nil, StatusPending, ""},
+		{"queued", []chainJob{{JobTypeIngestion, StatusPending}}, StatusPending, JobTypeIngestion},
+		{"between stages", []chainJob{
+			{JobTypeIngestion, StatusCompleted},
+			{JobTypeModeling, StatusPending},
+		}, StatusRunning, JobTypeModeling},
+		{"retrying", []chainJob{
+			{JobTypeIngestion, StatusCompleted},
+			{JobTypeModeling, StatusRetrying},
+		}, StatusRunning, JobTypeModeling},
+		{"failed", []chainJob{
+			{JobTypeIngestion, StatusCompleted},
+			{JobTypeModeling, StatusFailed},
+		}, StatusFailed, JobTypeModeling},
+		{"cancelled", []chainJob{{JobTypeIngestion, StatusCancelled}}, StatusCancelled, JobTypeIngestion},
+		{"done", []chainJob{
+			{JobTypeIngestion, StatusCompleted},
+			{JobTypeModeling, StatusCompleted},
+			{JobTypePlanning, StatusCompleted},
+			{JobTypeGeneration, StatusCompleted},
+		}, StatusCompleted, JobTypeGeneration},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, stage := chainStatus(tt.chain)
+			if status != tt.wantState || stage != tt.wantStage {
+				t.Errorf("chainStatus() = %s, %s, want %s, %s", status, stage, tt.wantState, tt.wantStage)
+			}
+		})
+	}
+}
+
+func TestBatchSummarize(t *testing.T) {
+	b := &Batch{Items: []BatchItem{
+		{Position: 0, Outcome: BatchQueued},
+		{Position: 1, Outcome: BatchDuplicate},
+		{Position: 2, Outcome: BatchAlreadyRunning},
+		{Position: 3, Outcome: BatchInvalid},
+		{Position: 4, Outcome: BatchFailed},
+	}}
+	b.summarize(map[int][]chainJob{
+		0: {{JobTypeIngestion, StatusCompleted}, {JobTypeModeling, StatusCompleted}},
+		2: {{JobTypeIngestion, StatusRunning}},
+	})
+
+	want := BatchSummary{Total: 5, Queued: 2, Skipped: 2, Running: 1, Completed: 1, Failed: 1}
+	if b.Summary != want {
+		t.Errorf("Summary = %+v, want %+v", b.Summary, want)
+	}
+	if b.Status != BatchStatusRunning {
+		t.Errorf("Status = %s, want running", b.Status)
+	}
+	if b.Items[0].Status != StatusCompleted || b.Items[0].Stage != JobTypeModeling {
+		t.Errorf("Items[0] = %s at %s, want completed at modeling", b.Items[0].Status, b.Items[0].Stage)
+	}
+	if b.Items[1].Status != "" {
+		t.Errorf("duplicate item has status %s", b.Items[1].Status)
+	}
+
+	b.summarize(map[int][]chainJob{
+		0: {{JobTypeIngestion, StatusCompleted}},
+		2: {{JobTypeIngestion, StatusCompleted}},
+	})
+	if b.Status != BatchStatusFailed {
+		t.Errorf("Status = %s, want failed when a pipeline couldn't start", b.Status)
+	}
+}
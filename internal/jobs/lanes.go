@@ -0,0 +1,63 @@
+package jobs
+
+import "fmt"
+
+// Lane is a job scheduling lane. Workers take interactive jobs, such as a
+// developer waiting on tests for one file from the API or an IDE, before
+// other jobs of the same type, and batch pipelines last.
+type Lane string
+
+// Scheduling lanes
+const (
+	LaneInteractive Lane = "interactive"
+	LaneDefault     Lane = "default"
+	LaneBatch       Lane = "batch"
+)
+
+// Job priorities of the lanes; a job's chain inherits its priority
+const (
+	PriorityInteractive = 100
+	PriorityDefault     = 0
+	PriorityBatch       = -10
+)
+
+// ParseLane parses a lane name; an empty name is the default lane
+func ParseLane(name string) (Lane, error) {
+	switch Lane(name) {
+	case "", LaneDefault:
+		return LaneDefault, nil
+	case LaneInteractive, LaneBatch:
+		return Lane(name), nil
+	default:
+		return "", fmt.Errorf("unknown lane %q: must be interactive, default or batch", name)
+	}
+}
+
+// Priority returns the priority of jobs in the lane
+func (l Lane) Priority() int {
+	switch l {
+	case LaneInteractive:
+		return PriorityInteractive
+	case LaneBatch:
+		return PriorityBatch
+	default:
+		return PriorityDefault
+	}
+}
+
+// LaneForPriority returns the lane a job priority falls in
+func LaneForPriority(priority int) Lane {
+	switch {
+	case priority >= PriorityInteractive:
+		return LaneInteractive
+	case priority <= PriorityBatch:
+		return LaneBatch
+	default:
+		return LaneDefault
+	}
+}
+
+// Lane returns the lane the job is scheduled in
+func (j *Job) Lane() Lane {
+	return LaneForPriority(j.Priority)
+}
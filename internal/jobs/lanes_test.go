@@ -0,0 +1,76 @@
+package jobs
+
+import "testing"
+
+func TestParseLane(t *testing.T) {
+	tests := []struct {
+		name     string
+		want     Lane
+		priority int
+		wantErr  bool
+	}{
+		{"", LaneDefault, PriorityDefault, false},
+		{"default", LaneDefault, PriorityDefault, false},
+		{"interactive", LaneInteractive, PriorityInteractive, false},
+		{"batch", LaneBatch, PriorityBatch, false},
+		{"urgent", "", 0, true},
+	}
+
+	for _, tt := range tests {
+		lane, err := ParseLane(tt.name)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseLane(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if lane != tt.want {
+			t.Errorf("ParseLane(%q) = %q, want %q", tt.name, lane, tt.want)
+		}
+		if !tt.wantErr && lane.Priority() != tt.priority {
+			t.Errorf("%s.Priority() = %d, want %d", lane, lane.Priority(), tt.priority)
+		}
+	}
+}
+
+func TestLaneForPriority(t *testing.T) {
+	tests := []struct {
+		priority int
+		want     Lane
+	}{
+		{PriorityInteractive, LaneInteractive},
+		{PriorityInteractive + 5, LaneInteractive},
+		{10, LaneDefault},
+		{0, LaneDefault},
+		{-1, LaneDefault},
+		{PriorityBatch, LaneBatch},
+		{-50, LaneBatch},
+	}
+
+	for _, tt := range tests {
+		if got := LaneForPriority(tt.priority); got != tt.want {
+			t.Errorf("LaneForPriority(%d) = %q, want %q", tt.priority, got, tt.want)
+		}
+		job := &Job{Priority: tt.priority}
+		if got := job.Lane(); got != tt.want {
+			t.Errorf("Job{Priority: %d}.Lane() = %q, want %q", tt.priority, got, tt.want)
+		}
+	}
+}
+
+func TestLanePriorityRange(t *testing.T) {
+	for _, priority := range []int{PriorityInteractive, PriorityDefault, PriorityBatch, 50, -5, -100, 1000} {
+		lane := LaneForPriority(priority)
+		lo, hi := lane.priorityRange()
+		if priority < lo || priority > hi {
+			t.Errorf("priority %d in lane %s outside its range [%d, %d]", priority, lane, lo, hi)
+		}
+	}
+}
+
+func TestConcurrencyLimits_IsZero(t *testing.T) {
+	if !(ConcurrencyLimits{PerLane: map[Lane]int{LaneBatch: 0}}).IsZero() {
+		t.Error("zero limits should be no limits")
+	}
+	if (ConcurrencyLimits{PerLane: map[Lane]int{LaneBatch: 2}}).IsZero() {
+		t.Error("a lane limit is a limit")
+	}
+}
@@ -0,0 +1,129 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrConcurrencyLimit is returned when claiming a job whose repository or
+// lane already runs as many jobs as its limit allows. The job stays pending.
+var ErrConcurrencyLimit = errors.New("concurrency limit reached")
+
+// ConcurrencyLimits caps the jobs running at once across all workers, so
+// one large repository or a flood of batch work can't take the whole pool.
+// 0 is no limit.
+type ConcurrencyLimits struct {
+	PerRepository int
+	PerLane       map[Lane]int
+}
+
+// IsZero reports whether no limit is set
+func (l ConcurrencyLimits) IsZero() bool {
+	if l.PerRepository > 0 {
+		return false
+	}
+	for _, n := range l.PerLane {
+		if n > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// priorityRange returns the lowest and highest priority of jobs in a lane
+func (l Lane) priorityRange() (int, int) {
+	switch l {
+	case LaneInteractive:
+		return PriorityInteractive, math.MaxInt32
+	case LaneBatch:
+		return math.MinInt32, PriorityBatch
+	default:
+		return PriorityBatch + 1, PriorityInteractive - 1
+	}
+}
+
+// ClaimWithinLimits claims a job like Claim, unless its repository or lane
+// is at its limit, when it returns ErrConcurrencyLimit. Claims counting the
+// same repository or lane are serialized with advisory locks, so workers
+// racing for the last slot can't both take it.
+func (r *Repository) ClaimWithinLimits(ctx context.Context, jobID uuid.UUID, workerID string, lockDuration time.Duration, limits ConcurrencyLimits) (*Job, error) {
+	if limits.IsZero() {
+		return r.Claim(ctx, jobID, workerID, lockDuration)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	var repoID *uuid.UUID
+	var priority int
+	err = tx.QueryRowContext(ctx, `
+		SELECT repository_id, priority FROM jobs
+		WHERE id = $1
+		  AND (status = 'pending' OR (status = 'running' AND locked_until < $2))
+		FOR UPDATE
+	`, jobID, now).Scan(&repoID, &priority)
+	if err == sql.ErrNoRows {
+		return nil, nil // Job already claimed or not pending
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock job: %w", err)
+	}
+
+	// Lock the repository before the lane, always, so claims can't deadlock
+	if limits.PerRepository > 0 && repoID != nil {
+		running, err := r.countRunning(ctx, tx, "repository:"+repoID.String(), now,
+			`repository_id = $2`, *repoID)
+		if err != nil {
+			return nil, err
+		}
+		if running >= limits.PerRepository {
+			return nil, fmt.Errorf("repository %s has %d running jobs: %w", repoID, running, ErrConcurrencyLimit)
+		}
+	}
+	lane := LaneForPriority(priority)
+	if max := limits.PerLane[lane]; max > 0 {
+		lo, hi := lane.priorityRange()
+		running, err := r.countRunning(ctx, tx, "lane:"+string(lane), now,
+			`priority BETWEEN $2 AND $3`, lo, hi)
+		if err != nil {
+			return nil, err
+		}
+		if running >= max {
+			return nil, fmt.Errorf("%s lane has %d running jobs: %w", lane, running, ErrConcurrencyLimit)
+		}
+	}
+
+	job, err := r.claimTx(ctx, tx, jobID, workerID, now, lockDuration)
+	if err != nil || job == nil {
+		return job, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit: %w", err)
+	}
+	return job, nil
+}
+
+// countRunning takes the transaction's advisory lock on key and counts the
+// running jobs matching where, whose arguments start at $2
+func (r *Repository) countRunning(ctx context.Context, tx *sql.Tx, key string, now time.Time, where string, args ...interface{}) (int, error) {
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, "qtest-jobs:"+key); err != nil {
+		return 0, fmt.Errorf("failed to lock %s: %w", key, err)
+	}
+
+	var running int
+	query := `SELECT COUNT(*) FROM jobs WHERE status = 'running' AND locked_until >= $1 AND ` + where
+	if err := tx.QueryRowContext(ctx, query, append([]interface{}{now}, args...)...).Scan(&running); err != nil {
+		return 0, fmt.Errorf("failed to count running jobs: %w", err)
+	}
+	return running, nil
+}
@@ -27,11 +27,20 @@ func NewPipeline(repo *Repository, nats *qtestnats.Client) *Pipeline {
 
 // StartIngestion starts the ingestion pipeline for a repository
 func (p *Pipeline) StartIngestion(ctx context.Context, payload IngestionPayload) (*Job, error) {
+	return p.startIngestion(ctx, payload, nil, PriorityDefault)
+}
+
+// startIngestion creates the ingestion job, under repositoryID when the
+// repository is already known
+func (p *Pipeline) startIngestion(ctx context.Context, payload IngestionPayload, repositoryID *uuid.UUID, priority int) (*Job, error) {
 	job, err := NewJob(JobTypeIngestion, payload)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create job: %w", err)
 	}
 
+	job.RepositoryID = repositoryID
+	job.Priority = priority
+
 	if err := p.repo.Create(ctx, job); err != nil {
 		return nil, fmt.Errorf("failed to persist job: %w", err)
 	}
@@ -60,9 +69,15 @@ func (p *Pipeline) StartFullPipeline(ctx context.Context, repoURL string, option
 		LLMTier:     options.LLMTier,
 		RunMutation: options.RunMutation,
 		CreatePR:    options.CreatePR,
+		PR:          options.PR,
+		BatchID:     options.BatchID,
+		// Policy, read by workers from the chain root
+		CoverageTarget: options.CoverageTarget,
+		Providers:      options.Providers,
+		OnlyUntested:   options.OnlyUntested,
 	}
 
-	job, err := p.StartIngestion(ctx, payload)
+	job, err := p.startIngestion(ctx, payload, options.RepositoryID, options.Lane.Priority())
 	if err != nil {
 		return nil, err
 	}
@@ -74,6 +89,7 @@ func (p *Pipeline) StartFullPipeline(ctx context.Context, repoURL string, option
 		Int("llm_tier", options.LLMTier).
 		Bool("run_mutation", options.RunMutation).
 		Bool("create_pr", options.CreatePR).
+		Str("lane", string(job.Lane())).
 		Msg("started full pipeline")
 
 	return job, nil
@@ -81,12 +97,21 @@ func (p *Pipeline) StartFullPipeline(ctx context.Context, repoURL string, option
 
 // PipelineOptions configures pipeline execution
 type PipelineOptions struct {
-	Branch      string   // Git branch to use
-	MaxTests    int      // Maximum tests to generate
-	LLMTier     int      // LLM tier (1=fast, 2=balanced, 3=thorough)
-	TestLevels  []string // "unit", "api", "e2e"
-	RunMutation bool     // Whether to run mutation testing after generation
-	CreatePR    bool     // Whether to create a PR at the end
+	Branch      string     // Git branch to use
+	MaxTests    int        // Maximum tests to generate
+	LLMTier     int        // LLM tier (1=fast, 2=balanced, 3=thorough)
+	TestLevels  []string   // "unit", "api", "e2e"
+	RunMutation bool       // Whether to run mutation testing after generation
+	CreatePR    bool       // Whether to create a PR at the end
+	PR          *PROptions // Draft, labels, reviewers and auto-merge for that PR
+
+	CoverageTarget float64  // Coverage percentage the repository aims for, recorded on its runs
+	Providers      []string // LLM providers the pipeline may send code to; empty allows any
+	OnlyUntested   bool     // Only generate tests for targets without one, as scheduled runs do
+
+	RepositoryID *uuid.UUID // Known repository, so the pipeline's jobs list under it from the start
+	BatchID      *uuid.UUID // Batch the pipeline was submitted in
+	Lane         Lane       // Scheduling lane of the pipeline's jobs; empty is the default lane
 }
 
 // ChainJob creates a child job linked to a parent
@@ -103,12 +128,20 @@ func (p *Pipeline) ChainJob(ctx context.Context, parentID uuid.UUID, jobType Job
 	if err != nil {
 		return nil, fmt.Errorf("failed to get parent job: %w", err)
 	}
+	if parent != nil && parent.Status == StatusCancelled {
+		return nil, ErrJobCancelled
+	}
 	if parent != nil && parent.RepositoryID != nil {
 		job.RepositoryID = parent.RepositoryID
 	}
 	if parent != nil && parent.GenerationRunID != nil {
 		job.GenerationRunID = parent.GenerationRunID
 	}
+	// Stay in the parent's lane, so an interactive request isn't queued
+	// behind batch work halfway through
+	if parent != nil {
+		job.Priority = parent.Priority
+	}
 
 	if err := p.repo.Create(ctx, job); err != nil {
 		return nil, fmt.Errorf("failed to persist job: %w", err)
@@ -178,6 +211,7 @@ func (p *Pipeline) CreateGenerationJob(ctx context.Context, parentID uuid.UUID,
 		RepositoryID:    repoID,
 		GenerationRunID: runID,
 		PlanID:          planID,
+		MaxTests:        opts.MaxTests,
 		LLMTier:         opts.LLMTier,
 		RunMutation:     opts.RunMutation,
 		CreatePR:        opts.CreatePR,
@@ -198,6 +232,9 @@ type ValidationJobOptions struct {
 	MaxFixAttempts int    // Max attempts to fix a failing test
 	RunMutation    bool   // Whether to run mutation testing after validation
 	CreatePR       bool   // Whether to create a PR at the end
+	Shards         int    // Max validation jobs to split the tests across (0/1 = one job)
+	MinShardSize   int    // Min tests per shard (default 10)
+	CacheDir       string // Result cache directory (empty = no cache)
 }
 
 // CreateValidationJob creates a validation job after generation completes
@@ -218,6 +255,7 @@ func (p *Pipeline) CreateValidationJob(ctx context.Context, parentID uuid.UUID,
 		MaxFixAttempts:  maxFixAttempts,
 		RunMutation:     opts.RunMutation,
 		CreatePR:        opts.CreatePR,
+		CacheDir:        opts.CacheDir,
 	}
 
 	job, err := p.ChainJob(ctx, parentID, JobTypeValidation, payload)
@@ -229,6 +267,86 @@ func (p *Pipeline) CreateValidationJob(ctx context.Context, parentID uuid.UUID,
 	return job, nil
 }
 
+// CreateValidationShards splits a run's tests across up to opts.Shards
+// validation jobs, which worker replicas process in parallel. The last shard
+// to finish chains the integration job for the whole run.
+func (p *Pipeline) CreateValidationShards(ctx context.Context, parentID uuid.UUID, repoID, runID uuid.UUID, testIDs, testPaths []string, workspacePath, language string, opts ValidationJobOptions) ([]*Job, error) {
+	shards := ShardTests(len(testPaths), opts.Shards, opts.MinShardSize)
+	if len(shards) <= 1 {
+		job, err := p.CreateValidationJob(ctx, parentID, repoID, runID, testIDs, testPaths, workspacePath, language, opts)
+		if err != nil {
+			return nil, err
+		}
+		return []*Job{job}, nil
+	}
+
+	maxFixAttempts := opts.MaxFixAttempts
+	if maxFixAttempts == 0 {
+		maxFixAttempts = 3
+	}
+
+	created := make([]*Job, 0, len(shards))
+	for i, shard := range shards {
+		payload := ValidationPayload{
+			RepositoryID:    repoID,
+			GenerationRunID: runID,
+			TestFilePaths:   testPaths[shard[0]:shard[1]],
+			WorkspacePath:   workspacePath,
+			Language:        language,
+			AutoFix:         opts.AutoFix,
+			MaxFixAttempts:  maxFixAttempts,
+			RunMutation:     opts.RunMutation,
+			CreatePR:        opts.CreatePR,
+			ShardIndex:      i,
+			ShardCount:      len(shards),
+			CacheDir:        opts.CacheDir,
+		}
+		if len(testIDs) >= shard[1] {
+			payload.TestIDs = testIDs[shard[0]:shard[1]]
+		}
+
+		job, err := p.ChainJob(ctx, parentID, JobTypeValidation, payload)
+		if err != nil {
+			return created, fmt.Errorf("failed to create validation shard %d/%d: %w", i+1, len(shards), err)
+		}
+		job.GenerationRunID = &runID
+		created = append(created, job)
+	}
+
+	return created, nil
+}
+
+// ShardTests splits n tests into at most maxShards contiguous [start, end)
+// ranges of at least minSize tests each
+func ShardTests(n, maxShards, minSize int) [][2]int {
+	if n == 0 {
+		return nil
+	}
+	if minSize <= 0 {
+		minSize = 10
+	}
+	shards := n / minSize
+	if maxShards < shards {
+		shards = maxShards
+	}
+	if shards < 1 {
+		shards = 1
+	}
+
+	ranges := make([][2]int, 0, shards)
+	start := 0
+	for i := 0; i < shards; i++ {
+		// Spread the remainder over the first shards
+		size := n / shards
+		if i < n%shards {
+			size++
+		}
+		ranges = append(ranges, [2]int{start, start + size})
+		start += size
+	}
+	return ranges
+}
+
 // CreateIntegrationJob creates an integration job after generation completes
 func (p *Pipeline) CreateIntegrationJob(ctx context.Context, parentID uuid.UUID, repoID, runID uuid.UUID, testPaths []string, createPR bool) (*Job, error) {
 	payload := IntegrationPayload{
@@ -259,6 +377,11 @@ func (p *Pipeline) CreateMutationJob(ctx context.Context, parentID uuid.UUID, re
 	return job, nil
 }
 
+// Publish notifies workers of a job created outside the pipeline
+func (p *Pipeline) Publish(ctx context.Context, job *Job) error {
+	return p.publishJob(ctx, job)
+}
+
 // publishJob publishes a job notification to NATS
 func (p *Pipeline) publishJob(ctx context.Context, job *Job) error {
 	if p.nats == nil {
@@ -276,10 +399,14 @@ func (p *Pipeline) publishJob(ctx context.Context, job *Job) error {
 		return fmt.Errorf("failed to encode message: %w", err)
 	}
 
-	subject := qtestnats.SubjectForJobType(string(job.Type))
+	// Jobs that run a single toolchain go to workers that have it
+	subject := qtestnats.SubjectForToolchain(string(job.Type), job.Toolchain())
 	if subject == "" {
 		return fmt.Errorf("unknown job type: %s", job.Type)
 	}
+	if job.Lane() == LaneInteractive {
+		subject = qtestnats.InteractiveSubject(subject)
+	}
 
 	_, err = p.nats.Publish(ctx, subject, data)
 	return err
@@ -312,6 +439,73 @@ type JobStatusReport struct {
 	Children []*Job `json:"children,omitempty"`
 }
 
+// PipelineReport is a pipeline's jobs, from the root job it started with
+type PipelineReport struct {
+	Root   *Job
+	Jobs   []*Job    // the whole chain, root first, oldest first
+	Status JobStatus // the pipeline's overall status
+	Stage  JobType   // the latest job's type
+}
+
+// GetPipeline returns the pipeline started by a root job
+func (p *Pipeline) GetPipeline(ctx context.Context, rootID uuid.UUID) (*PipelineReport, error) {
+	chain, err := p.repo.GetChain(ctx, rootID)
+	if err != nil {
+		return nil, err
+	}
+	if len(chain) == 0 || chain[0].ID != rootID {
+		return nil, fmt.Errorf("pipeline not found")
+	}
+	if chain[0].ParentJobID != nil {
+		return nil, fmt.Errorf("job %s is not the root of a pipeline", rootID)
+	}
+
+	report := &PipelineReport{Root: chain[0], Jobs: chain}
+	report.Status, report.Stage = PipelineStatus(chain)
+	return report, nil
+}
+
+// CancelPipeline cancels every unfinished job of a pipeline and returns
+// how many there were. Workers running its jobs are told to stop.
+func (p *Pipeline) CancelPipeline(ctx context.Context, rootID uuid.UUID) (int, error) {
+	chain, err := p.repo.GetChain(ctx, rootID)
+	if err != nil {
+		return 0, err
+	}
+	n, err := p.repo.CancelChain(ctx, rootID)
+	if err != nil {
+		return 0, err
+	}
+	for _, job := range chain {
+		if job.Status == StatusRunning {
+			p.PublishCancel(job.ID)
+		}
+	}
+	return n, nil
+}
+
+// PublishCancel tells the worker running a cancelled job to abort it. It
+// does nothing without NATS; the worker then stops when it next extends the
+// job's lock.
+func (p *Pipeline) PublishCancel(jobID uuid.UUID) {
+	if p.nats == nil || !p.nats.IsConnected() {
+		return
+	}
+	if err := p.nats.Conn().Publish(qtestnats.CancelSubject(jobID.String()), nil); err != nil {
+		log.Warn().Err(err).Str("job_id", jobID.String()).Msg("failed to publish job cancellation")
+	}
+}
+
+// PipelineStatus reduces a pipeline's jobs, oldest first, to one status and
+// the stage it reached
+func PipelineStatus(chain []*Job) (JobStatus, JobType) {
+	jobs := make([]chainJob, len(chain))
+	for i, j := range chain {
+		jobs[i] = chainJob{Type: j.Type, Status: j.Status}
+	}
+	return chainStatus(jobs)
+}
+
 // RetryFailedJobs requeues all jobs in retrying status
 func (p *Pipeline) RetryFailedJobs(ctx context.Context) (int, error) {
 	jobs, err := p.repo.ListByStatus(ctx, StatusRetrying, 100)
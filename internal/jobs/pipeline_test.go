@@ -165,3 +165,30 @@ func TestPipelineOptions_LLMTiers(t *testing.T) {
 		})
 	}
 }
+
+func TestShardTests(t *testing.T) {
+	tests := []struct {
+		n, maxShards, minSize int
+		want                  [][2]int
+	}{
+		{0, 4, 10, nil},
+		{5, 4, 10, [][2]int{{0, 5}}},
+		{25, 4, 10, [][2]int{{0, 13}, {13, 25}}},
+		{100, 3, 10, [][2]int{{0, 34}, {34, 67}, {67, 100}}},
+		{100, 0, 10, [][2]int{{0, 100}}},
+	}
+
+	for _, tt := range tests {
+		got := ShardTests(tt.n, tt.maxShards, tt.minSize)
+		if len(got) != len(tt.want) {
+			t.Errorf("ShardTests(%d, %d, %d) = %v, want %v", tt.n, tt.maxShards, tt.minSize, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("ShardTests(%d, %d, %d) = %v, want %v", tt.n, tt.maxShards, tt.minSize, got, tt.want)
+				break
+			}
+		}
+	}
+}
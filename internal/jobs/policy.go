@@ -0,0 +1,101 @@
+package jobs
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/QTest-hq/qtest/internal/llm"
+)
+
+// Policy is pipeline configuration an organization sets as defaults for its
+// repositories. A repository's own policy overrides it field by field, and
+// options given when a pipeline starts override both.
+type Policy struct {
+	LLMTier        int        `json:"llm_tier,omitempty"`        // 1=fast, 2=balanced, 3=thorough
+	CoverageTarget float64    `json:"coverage_target,omitempty"` // percent
+	Providers      []string   `json:"providers,omitempty"`       // LLM providers source code may be sent to; empty allows any
+	PR             *PROptions `json:"pr,omitempty"`              // for pipelines that create a PR without their own options
+}
+
+// ParsePolicy decodes a stored policy; an empty one sets nothing
+func ParsePolicy(data []byte) (*Policy, error) {
+	p := &Policy{}
+	if len(data) == 0 {
+		return p, nil
+	}
+	if err := json.Unmarshal(data, p); err != nil {
+		return nil, fmt.Errorf("invalid policy: %w", err)
+	}
+	return p, nil
+}
+
+// Validate checks the policy for values a pipeline can't use
+func (p *Policy) Validate() error {
+	if p.LLMTier < 0 || p.LLMTier > 3 {
+		return fmt.Errorf("invalid llm_tier %d: must be 1, 2 or 3", p.LLMTier)
+	}
+	if p.CoverageTarget < 0 || p.CoverageTarget > 100 {
+		return fmt.Errorf("invalid coverage_target %g: must be a percentage", p.CoverageTarget)
+	}
+	for _, provider := range p.Providers {
+		switch llm.Provider(provider) {
+		case llm.ProviderOllama, llm.ProviderAnthropic, llm.ProviderOpenAI:
+		default:
+			return fmt.Errorf("unknown provider %q: must be ollama, anthropic or openai", provider)
+		}
+	}
+	if err := p.PR.Validate(); err != nil {
+		return fmt.Errorf("invalid pr options: %w", err)
+	}
+	return nil
+}
+
+// Merge returns the policy with the fields override sets replacing its own
+func (p *Policy) Merge(override *Policy) *Policy {
+	merged := *p
+	if override == nil {
+		return &merged
+	}
+	if override.LLMTier != 0 {
+		merged.LLMTier = override.LLMTier
+	}
+	if override.CoverageTarget != 0 {
+		merged.CoverageTarget = override.CoverageTarget
+	}
+	if len(override.Providers) > 0 {
+		merged.Providers = override.Providers
+	}
+	if override.PR != nil {
+		merged.PR = override.PR
+	}
+	return &merged
+}
+
+// Apply fills in the pipeline options the caller left unset
+func (p *Policy) Apply(opts *PipelineOptions) {
+	if opts.LLMTier == 0 {
+		opts.LLMTier = p.LLMTier
+	}
+	if opts.CoverageTarget == 0 {
+		opts.CoverageTarget = p.CoverageTarget
+	}
+	if len(opts.Providers) == 0 {
+		opts.Providers = p.Providers
+	}
+	if opts.CreatePR && opts.PR == nil {
+		opts.PR = p.PR
+	}
+}
+
+// AllowedProviders returns the LLM providers a pipeline may use, or nil
+// when any is allowed
+func AllowedProviders(providers []string) []llm.Provider {
+	if len(providers) == 0 {
+		return nil
+	}
+	allowed := make([]llm.Provider, 0, len(providers))
+	for _, p := range providers {
+		allowed = append(allowed, llm.Provider(p))
+	}
+	return allowed
+}
@@ -0,0 +1,84 @@
+package jobs
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPolicy_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  Policy
+		wantErr bool
+	}{
+		{"empty", Policy{}, false},
+		{"full", Policy{LLMTier: 2, CoverageTarget: 80, Providers: []string{"ollama"}, PR: &PROptions{Labels: []string{"qtest"}}}, false},
+		{"tier too high", Policy{LLMTier: 4}, true},
+		{"coverage over 100", Policy{CoverageTarget: 120}, true},
+		{"unknown provider", Policy{Providers: []string{"gemini"}}, true},
+		{"invalid pr options", Policy{PR: &PROptions{Draft: true, AutoMerge: true}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.policy.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPolicy_Merge(t *testing.T) {
+	org := &Policy{LLMTier: 2, CoverageTarget: 80, Providers: []string{"ollama"}, PR: &PROptions{Labels: []string{"qtest"}}}
+	repo := &Policy{CoverageTarget: 90, PR: &PROptions{Draft: true}}
+
+	got := org.Merge(repo)
+	want := &Policy{LLMTier: 2, CoverageTarget: 90, Providers: []string{"ollama"}, PR: &PROptions{Draft: true}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Merge() = %+v, want %+v", got, want)
+	}
+	if org.CoverageTarget != 80 {
+		t.Error("Merge() modified the organization policy")
+	}
+	if !reflect.DeepEqual(org.Merge(nil), org) {
+		t.Error("Merge(nil) changed the policy")
+	}
+}
+
+func TestPolicy_Apply(t *testing.T) {
+	policy := &Policy{LLMTier: 3, CoverageTarget: 75, Providers: []string{"ollama"}, PR: &PROptions{Labels: []string{"qtest"}}}
+
+	opts := PipelineOptions{CreatePR: true}
+	policy.Apply(&opts)
+	if opts.LLMTier != 3 || opts.CoverageTarget != 75 || len(opts.Providers) != 1 || opts.PR != policy.PR {
+		t.Errorf("Apply() = %+v, want the policy's defaults", opts)
+	}
+
+	// Options given when the pipeline starts win
+	own := &PROptions{Draft: true}
+	opts = PipelineOptions{LLMTier: 1, CreatePR: true, PR: own}
+	policy.Apply(&opts)
+	if opts.LLMTier != 1 || opts.PR != own {
+		t.Errorf("Apply() = %+v, want the pipeline's own options kept", opts)
+	}
+
+	// PR options only apply to pipelines that open a PR
+	opts = PipelineOptions{}
+	policy.Apply(&opts)
+	if opts.PR != nil {
+		t.Error("Apply() set PR options without create_pr")
+	}
+}
+
+func TestParsePolicy(t *testing.T) {
+	p, err := ParsePolicy(nil)
+	if err != nil || !reflect.DeepEqual(p, &Policy{}) {
+		t.Errorf("ParsePolicy(nil) = %+v, %v", p, err)
+	}
+	p, err = ParsePolicy([]byte(`{"llm_tier": 2, "providers": ["ollama"]}`))
+	if err != nil || p.LLMTier != 2 || len(p.Providers) != 1 {
+		t.Errorf("ParsePolicy() = %+v, %v", p, err)
+	}
+	if _, err := ParsePolicy([]byte(`{`)); err == nil {
+		t.Error("ParsePolicy() accepted invalid JSON")
+	}
+}
@@ -0,0 +1,34 @@
+package jobs
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ProgressEvent reports a running job's progress, or a change of its
+// status, to clients following the job live. Workers publish them on NATS.
+type ProgressEvent struct {
+	JobID   uuid.UUID `json:"job_id"`
+	JobType JobType   `json:"job_type"`
+	Status  JobStatus `json:"status"`
+	Phase   string    `json:"phase,omitempty"` // empty for status changes
+	Current int       `json:"current,omitempty"`
+	Total   int       `json:"total,omitempty"`
+	Message string    `json:"message,omitempty"`
+	Time    time.Time `json:"time"`
+}
+
+// NewStatusEvent returns the event reporting a job's current status
+func NewStatusEvent(job *Job) ProgressEvent {
+	e := ProgressEvent{JobID: job.ID, JobType: job.Type, Status: job.Status, Time: time.Now().UTC()}
+	if job.ErrorMessage != nil {
+		e.Message = *job.ErrorMessage
+	}
+	return e
+}
+
+// Finished reports whether a job in this status will not run again
+func (s JobStatus) Finished() bool {
+	return s == StatusCompleted || s == StatusFailed || s == StatusCancelled
+}
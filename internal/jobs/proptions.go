@@ -0,0 +1,90 @@
+package jobs
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/QTest-hq/qtest/internal/github"
+)
+
+// Merge methods accepted for auto-merge
+const (
+	MergeMethodMerge  = "merge"
+	MergeMethodSquash = "squash"
+	MergeMethodRebase = "rebase"
+)
+
+// Commit strategies for the tests on a PR's branch
+const (
+	CommitStrategySingle     = "single"      // one commit with every test file
+	CommitStrategyPerPackage = "per-package" // a commit per package under test
+)
+
+// PROptions shape the pull request the integration worker opens, so it fits
+// into a team's existing review automation
+type PROptions struct {
+	Draft       bool     `json:"draft,omitempty"`
+	Labels      []string `json:"labels,omitempty"`
+	Assignees   []string `json:"assignees,omitempty"`
+	Reviewers   []string `json:"reviewers,omitempty"`    // user logins, or org/team-slug for teams
+	AutoMerge   bool     `json:"auto_merge,omitempty"`   // merge once checks pass; only enabled if the tests passed
+	MergeMethod string   `json:"merge_method,omitempty"` // merge, squash (default) or rebase
+
+	// CommitStrategy is how the tests are committed to the PR's branch:
+	// single (default) or per-package, which makes them easier to review
+	// and bisect
+	CommitStrategy string `json:"commit_strategy,omitempty"`
+
+	// BodyTemplate replaces the default PR description; see
+	// github.RenderPRBody for what it's executed with
+	BodyTemplate string `json:"body_template,omitempty"`
+
+	// TestDependencies declares the dependencies the tests need, such as
+	// jest or pytest, in package.json, pyproject.toml or a requirements
+	// file on the PR's branch
+	TestDependencies bool `json:"test_dependencies,omitempty"`
+}
+
+// Validate checks the options for values GitHub would reject
+func (o *PROptions) Validate() error {
+	if o == nil {
+		return nil
+	}
+
+	switch strings.ToLower(o.MergeMethod) {
+	case "", MergeMethodMerge, MergeMethodSquash, MergeMethodRebase:
+	default:
+		return fmt.Errorf("invalid merge method %q: must be merge, squash or rebase", o.MergeMethod)
+	}
+	if o.MergeMethod != "" && !o.AutoMerge {
+		return fmt.Errorf("merge method requires auto-merge")
+	}
+	if o.Draft && o.AutoMerge {
+		return fmt.Errorf("draft pull requests can't be auto-merged")
+	}
+
+	for _, label := range o.Labels {
+		if strings.TrimSpace(label) == "" {
+			return fmt.Errorf("empty label")
+		}
+	}
+	for _, login := range o.Assignees {
+		if login == "" || strings.Contains(login, "/") {
+			return fmt.Errorf("invalid assignee %q", login)
+		}
+	}
+	for _, reviewer := range o.Reviewers {
+		if reviewer == "" || strings.Count(reviewer, "/") > 1 || strings.HasPrefix(reviewer, "/") || strings.HasSuffix(reviewer, "/") {
+			return fmt.Errorf("invalid reviewer %q: use a login or org/team-slug", reviewer)
+		}
+	}
+	switch o.CommitStrategy {
+	case "", CommitStrategySingle, CommitStrategyPerPackage:
+	default:
+		return fmt.Errorf("invalid commit strategy %q: must be single or per-package", o.CommitStrategy)
+	}
+	if err := github.ValidatePRBodyTemplate(o.BodyTemplate); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package jobs
+
+import "testing"
+
+func TestPROptions_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    *PROptions
+		wantErr bool
+	}{
+		{"nil", nil, false},
+		{"empty", &PROptions{}, false},
+		{"full", &PROptions{Labels: []string{"tests"}, Assignees: []string{"alice"}, Reviewers: []string{"bob", "org/qa"}, AutoMerge: true, MergeMethod: "Squash"}, false},
+		{"draft", &PROptions{Draft: true, Reviewers: []string{"bob"}}, false},
+		{"unknown merge method", &PROptions{AutoMerge: true, MergeMethod: "fast-forward"}, true},
+		{"merge method without auto-merge", &PROptions{MergeMethod: "merge"}, true},
+		{"draft auto-merge", &PROptions{Draft: true, AutoMerge: true}, true},
+		{"blank label", &PROptions{Labels: []string{" "}}, true},
+		{"team assignee", &PROptions{Assignees: []string{"org/qa"}}, true},
+		{"malformed team", &PROptions{Reviewers: []string{"org/"}}, true},
+		{"nested team", &PROptions{Reviewers: []string{"org/a/b"}}, true},
+		{"per-package commits", &PROptions{CommitStrategy: CommitStrategyPerPackage}, false},
+		{"unknown commit strategy", &PROptions{CommitStrategy: "per-file"}, true},
+		{"body template", &PROptions{BodyTemplate: "Adds {{.TestCount}} tests ({{printf \"%.0f\" .Metrics.MutationScore}}% mutation score)"}, false},
+		{"malformed body template", &PROptions{BodyTemplate: "{{.TestCount"}, true},
+		{"unknown body template field", &PROptions{BodyTemplate: "{{.Coverage}}"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.opts.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -5,13 +5,19 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"github.com/rs/zerolog/log"
 )
 
+// ErrJobCancelled is returned when finishing, or chaining from, a job that
+// was cancelled while it ran
+var ErrJobCancelled = errors.New("job was cancelled")
+
 // Repository handles job persistence
 type Repository struct {
 	db *sql.DB
@@ -79,8 +85,22 @@ func (r *Repository) Claim(ctx context.Context, jobID uuid.UUID, workerID string
 	}
 	defer tx.Rollback()
 
+	job, err := r.claimTx(ctx, tx, jobID, workerID, time.Now(), lockDuration)
+	if err != nil || job == nil {
+		return job, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit: %w", err)
+	}
+
+	return job, nil
+}
+
+// claimTx claims a job within a transaction, returning nil if it's already
+// claimed or not pending
+func (r *Repository) claimTx(ctx context.Context, tx *sql.Tx, jobID uuid.UUID, workerID string, now time.Time, lockDuration time.Duration) (*Job, error) {
 	// Try to claim the job with optimistic locking
-	now := time.Now()
 	lockedUntil := now.Add(lockDuration)
 
 	query := `
@@ -96,7 +116,7 @@ func (r *Repository) Claim(ctx context.Context, jobID uuid.UUID, workerID string
 	`
 
 	job := &Job{}
-	err = tx.QueryRowContext(ctx, query,
+	err := tx.QueryRowContext(ctx, query,
 		StatusRunning, workerID, lockedUntil, now, jobID,
 	).Scan(
 		&job.ID, &job.Type, &job.Status, &job.Priority,
@@ -117,10 +137,6 @@ func (r *Repository) Claim(ctx context.Context, jobID uuid.UUID, workerID string
 		log.Warn().Err(err).Msg("failed to record job history")
 	}
 
-	if err := tx.Commit(); err != nil {
-		return nil, fmt.Errorf("failed to commit: %w", err)
-	}
-
 	return job, nil
 }
 
@@ -142,12 +158,15 @@ func (r *Repository) Complete(ctx context.Context, jobID uuid.UUID, result inter
 		UPDATE jobs
 		SET status = $1, result = $2, completed_at = $3, updated_at = $3,
 			locked_until = NULL
-		WHERE id = $4
+		WHERE id = $4 AND status <> 'cancelled'
 		RETURNING worker_id
 	`
 
 	var workerID *string
 	err = tx.QueryRowContext(ctx, query, StatusCompleted, resultBytes, now, jobID).Scan(&workerID)
+	if errors.Is(err, sql.ErrNoRows) && r.isCancelled(ctx, tx, jobID) {
+		return ErrJobCancelled
+	}
 	if err != nil {
 		return fmt.Errorf("failed to complete job: %w", err)
 	}
@@ -183,12 +202,16 @@ func (r *Repository) Fail(ctx context.Context, jobID uuid.UUID, errMsg string, e
 	// Check if can retry
 	var retryCount, maxRetries int
 	var workerID *string
+	var status JobStatus
 	err = tx.QueryRowContext(ctx,
-		"SELECT retry_count, max_retries, worker_id FROM jobs WHERE id = $1", jobID,
-	).Scan(&retryCount, &maxRetries, &workerID)
+		"SELECT retry_count, max_retries, worker_id, status FROM jobs WHERE id = $1", jobID,
+	).Scan(&retryCount, &maxRetries, &workerID, &status)
 	if err != nil {
 		return fmt.Errorf("failed to get job retry info: %w", err)
 	}
+	if status == StatusCancelled {
+		return ErrJobCancelled
+	}
 
 	now := time.Now()
 	newStatus := StatusFailed
@@ -219,6 +242,67 @@ func (r *Repository) Fail(ctx context.Context, jobID uuid.UUID, errMsg string, e
 	return tx.Commit()
 }
 
+// Checkpoint saves partial results of a running job, so a worker that picks
+// the job up after an interruption can resume from them. Only the worker
+// holding the job can checkpoint it.
+func (r *Repository) Checkpoint(ctx context.Context, jobID uuid.UUID, workerID string, result interface{}) error {
+	resultBytes, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to marshal checkpoint: %w", err)
+	}
+
+	query := `
+		UPDATE jobs
+		SET result = $1, updated_at = $2
+		WHERE id = $3 AND worker_id = $4 AND status = 'running'
+	`
+
+	res, err := r.db.ExecContext(ctx, query, resultBytes, time.Now(), jobID, workerID)
+	if err != nil {
+		return fmt.Errorf("failed to checkpoint job: %w", err)
+	}
+
+	rows, _ := res.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("job not running on worker %s", workerID)
+	}
+
+	return nil
+}
+
+// Release returns a running job to pending without counting a retry, e.g.
+// when its worker shuts down. Its checkpointed result is kept.
+func (r *Repository) Release(ctx context.Context, jobID uuid.UUID, workerID string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE jobs
+		SET status = $1, updated_at = $2, worker_id = NULL,
+			started_at = NULL, locked_until = NULL
+		WHERE id = $3 AND worker_id = $4 AND status = 'running'
+	`
+
+	result, err := tx.ExecContext(ctx, query, StatusPending, time.Now(), jobID, workerID)
+	if err != nil {
+		return fmt.Errorf("failed to release job: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("job not running on worker %s", workerID)
+	}
+
+	if err := r.recordHistory(ctx, tx, jobID, string(StatusRunning), string(StatusPending), workerID); err != nil {
+		log.Warn().Err(err).Msg("failed to record job history")
+	}
+
+	return tx.Commit()
+}
+
 // Retry requeues a failed job for retry
 func (r *Repository) Retry(ctx context.Context, jobID uuid.UUID) error {
 	tx, err := r.db.BeginTx(ctx, nil)
@@ -251,7 +335,9 @@ func (r *Repository) Retry(ctx context.Context, jobID uuid.UUID) error {
 	return tx.Commit()
 }
 
-// Cancel cancels a pending job
+// Cancel cancels a pending, retrying or running job, releasing a running
+// job's lock. The worker running it stops when told over NATS or when it
+// next extends its lock.
 func (r *Repository) Cancel(ctx context.Context, jobID uuid.UUID) error {
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -265,11 +351,13 @@ func (r *Repository) Cancel(ctx context.Context, jobID uuid.UUID) error {
 		return fmt.Errorf("failed to get job status: %w", err)
 	}
 
-	if prevStatus != string(StatusPending) && prevStatus != string(StatusRetrying) {
-		return fmt.Errorf("can only cancel pending or retrying jobs")
+	switch JobStatus(prevStatus) {
+	case StatusPending, StatusRetrying, StatusRunning:
+	default:
+		return fmt.Errorf("can only cancel pending, retrying or running jobs")
 	}
 
-	query := `UPDATE jobs SET status = $1, updated_at = $2 WHERE id = $3`
+	query := `UPDATE jobs SET status = $1, locked_until = NULL, updated_at = $2 WHERE id = $3`
 	_, err = tx.ExecContext(ctx, query, StatusCancelled, time.Now(), jobID)
 	if err != nil {
 		return fmt.Errorf("failed to cancel job: %w", err)
@@ -282,6 +370,91 @@ func (r *Repository) Cancel(ctx context.Context, jobID uuid.UUID) error {
 	return tx.Commit()
 }
 
+// CancelChain cancels a job and every unfinished job descending from it,
+// running ones included. A worker running one stops when it next extends
+// its lock. It returns the number of jobs cancelled.
+func (r *Repository) CancelChain(ctx context.Context, rootID uuid.UUID) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+		WITH RECURSIVE chain AS (
+			SELECT id, status FROM jobs WHERE id = $1
+			UNION ALL
+			SELECT j.id, j.status FROM jobs j JOIN chain c ON j.parent_job_id = c.id
+		)
+		SELECT id, status FROM chain WHERE status IN ('pending', 'retrying', 'running')
+	`, rootID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load job chain: %w", err)
+	}
+	type unfinished struct {
+		id     uuid.UUID
+		status string
+	}
+	var open []unfinished
+	for rows.Next() {
+		var u unfinished
+		if err := rows.Scan(&u.id, &u.status); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan job: %w", err)
+		}
+		open = append(open, u)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to load job chain: %w", err)
+	}
+
+	now := time.Now()
+	for _, u := range open {
+		_, err := tx.ExecContext(ctx,
+			`UPDATE jobs SET status = $1, locked_until = NULL, updated_at = $2 WHERE id = $3`,
+			StatusCancelled, now, u.id)
+		if err != nil {
+			return 0, fmt.Errorf("failed to cancel job: %w", err)
+		}
+		if err := r.recordHistory(ctx, tx, u.id, u.status, string(StatusCancelled), "api"); err != nil {
+			log.Warn().Err(err).Msg("failed to record job history")
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit: %w", err)
+	}
+	return len(open), nil
+}
+
+// GetChain returns a job and every job descending from it, oldest first
+func (r *Repository) GetChain(ctx context.Context, rootID uuid.UUID) ([]*Job, error) {
+	query := `
+		WITH RECURSIVE chain AS (
+			SELECT id FROM jobs WHERE id = $1
+			UNION ALL
+			SELECT j.id FROM jobs j JOIN chain c ON j.parent_job_id = c.id
+		)
+		SELECT id, type, status, priority, repository_id, generation_run_id,
+			   parent_job_id, payload, result, error_message, error_details,
+			   retry_count, max_retries, created_at, updated_at, started_at,
+			   completed_at, locked_until, worker_id
+		FROM jobs
+		WHERE id IN (SELECT id FROM chain)
+		ORDER BY created_at
+	`
+
+	return r.queryJobs(ctx, query, rootID)
+}
+
+// isCancelled reports whether a job is cancelled
+func (r *Repository) isCancelled(ctx context.Context, tx *sql.Tx, jobID uuid.UUID) bool {
+	var status JobStatus
+	err := tx.QueryRowContext(ctx, "SELECT status FROM jobs WHERE id = $1", jobID).Scan(&status)
+	return err == nil && status == StatusCancelled
+}
+
 // ListByRepository returns jobs for a repository
 func (r *Repository) ListByRepository(ctx context.Context, repoID uuid.UUID, limit int) ([]*Job, error) {
 	query := `
@@ -314,7 +487,10 @@ func (r *Repository) ListByStatus(ctx context.Context, status JobStatus, limit i
 	return r.queryJobs(ctx, query, status, limit)
 }
 
-// ListPendingByType returns pending jobs of a specific type
+// ListPendingByType returns pending jobs of a specific type, highest
+// priority first. Within a priority repositories take turns, oldest job
+// first, so one repository's backlog doesn't queue everyone else's jobs
+// behind it.
 func (r *Repository) ListPendingByType(ctx context.Context, jobType JobType, limit int) ([]*Job, error) {
 	query := `
 		SELECT id, type, status, priority, repository_id, generation_run_id,
@@ -323,7 +499,9 @@ func (r *Repository) ListPendingByType(ctx context.Context, jobType JobType, lim
 			   completed_at, locked_until, worker_id
 		FROM jobs
 		WHERE type = $1 AND status = 'pending'
-		ORDER BY priority DESC, created_at
+		ORDER BY priority DESC,
+				 ROW_NUMBER() OVER (PARTITION BY priority, repository_id ORDER BY created_at),
+				 created_at
 		LIMIT $2
 	`
 
@@ -345,6 +523,47 @@ func (r *Repository) ListRecent(ctx context.Context, limit int) ([]*Job, error)
 	return r.queryJobs(ctx, query, limit)
 }
 
+// ListForOrganizations returns the most recent jobs of the organizations'
+// repositories, optionally only those with a status or of a type
+func (r *Repository) ListForOrganizations(ctx context.Context, orgIDs []uuid.UUID, status JobStatus, jobType JobType, limit int) ([]*Job, error) {
+	query := `
+		SELECT j.id, j.type, j.status, j.priority, j.repository_id, j.generation_run_id,
+			   j.parent_job_id, j.payload, j.result, j.error_message, j.error_details,
+			   j.retry_count, j.max_retries, j.created_at, j.updated_at, j.started_at,
+			   j.completed_at, j.locked_until, j.worker_id
+		FROM jobs j
+		JOIN repositories r ON r.id = j.repository_id
+		WHERE r.organization_id = ANY($1)
+		  AND ($2 = '' OR j.status = $2)
+		  AND ($3 = '' OR j.type = $3)
+		ORDER BY j.created_at DESC
+		LIMIT $4
+	`
+
+	return r.queryJobs(ctx, query, pq.Array(orgIDs), string(status), string(jobType), limit)
+}
+
+// ListOutsideOrganizations returns the most recent jobs outside any
+// organization, of repositories without one or of no repository, like
+// ListForOrganizations
+func (r *Repository) ListOutsideOrganizations(ctx context.Context, status JobStatus, jobType JobType, limit int) ([]*Job, error) {
+	query := `
+		SELECT j.id, j.type, j.status, j.priority, j.repository_id, j.generation_run_id,
+			   j.parent_job_id, j.payload, j.result, j.error_message, j.error_details,
+			   j.retry_count, j.max_retries, j.created_at, j.updated_at, j.started_at,
+			   j.completed_at, j.locked_until, j.worker_id
+		FROM jobs j
+		LEFT JOIN repositories r ON r.id = j.repository_id
+		WHERE r.organization_id IS NULL
+		  AND ($1 = '' OR j.status = $1)
+		  AND ($2 = '' OR j.type = $2)
+		ORDER BY j.created_at DESC
+		LIMIT $3
+	`
+
+	return r.queryJobs(ctx, query, string(status), string(jobType), limit)
+}
+
 // GetChildJobs returns all child jobs of a parent job
 func (r *Repository) GetChildJobs(ctx context.Context, parentID uuid.UUID) ([]*Job, error) {
 	query := `
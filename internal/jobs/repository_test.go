@@ -32,7 +32,7 @@ func TestNewRepository_WithNilDB(t *testing.T) {
 // - Complete: Marks a job as completed with result JSON
 // - Fail: Marks a job as failed, sets to retrying if retries remain
 // - Retry: Requeues a retrying job back to pending
-// - Cancel: Cancels a pending or retrying job
+// - Cancel: Cancels a pending, retrying or running job, releasing its lock
 // - ListByRepository: Lists jobs for a repository ID
 // - ListByStatus: Lists jobs by status
 // - ListPendingByType: Lists pending jobs of a specific type
@@ -0,0 +1,149 @@
+package jobs
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/QTest-hq/qtest/internal/vcs"
+)
+
+// Toolchains a job can be routed by; see qtestnats.RoutedToolchains
+const (
+	ToolchainGo     = "go"
+	ToolchainNode   = "node"
+	ToolchainPython = "python"
+)
+
+// Tools workers report and jobs require
+const (
+	ToolGo          = "go"
+	ToolNode        = "node"
+	ToolPython      = "python"
+	ToolPytest      = "pytest"
+	ToolGoMutesting = "go-mutesting"
+	ToolStryker     = "stryker"
+	ToolHg          = "hg" // ingesting Mercurial repositories
+)
+
+// ToolchainTools returns the tools a worker needs to run a job type for a
+// toolchain
+func ToolchainTools(jobType JobType, toolchain string) []string {
+	switch toolchain {
+	case ToolchainGo:
+		if jobType == JobTypeMutation {
+			return []string{ToolGo, ToolGoMutesting}
+		}
+		return []string{ToolGo}
+	case ToolchainNode:
+		if jobType == JobTypeMutation {
+			return []string{ToolNode, ToolStryker}
+		}
+		return []string{ToolNode}
+	case ToolchainPython:
+		if jobType == JobTypeMutation {
+			return []string{ToolPython}
+		}
+		return []string{ToolPython, ToolPytest}
+	}
+	return nil
+}
+
+// ToolchainForLanguage maps a language name to its toolchain
+func ToolchainForLanguage(language string) string {
+	switch strings.ToLower(language) {
+	case "go", "golang":
+		return ToolchainGo
+	case "javascript", "typescript", "js", "ts":
+		return ToolchainNode
+	case "python", "py":
+		return ToolchainPython
+	}
+	return ""
+}
+
+// ToolchainForFile returns the toolchain for a source or test file
+func ToolchainForFile(path string) string {
+	switch filepath.Ext(path) {
+	case ".go":
+		return ToolchainGo
+	case ".js", ".jsx", ".ts", ".tsx", ".mjs", ".cjs":
+		return ToolchainNode
+	case ".py":
+		return ToolchainPython
+	}
+	return ""
+}
+
+// Toolchains returns the toolchains a job runs, sorted. Jobs that don't run
+// language tooling, or whose payload can't be read, return nil.
+func (j *Job) Toolchains() []string {
+	var files []string
+	switch j.Type {
+	case JobTypeValidation:
+		var payload ValidationPayload
+		if err := j.GetPayload(&payload); err != nil {
+			return nil
+		}
+		if tc := ToolchainForLanguage(payload.Language); tc != "" {
+			return []string{tc}
+		}
+		files = payload.TestFilePaths
+	case JobTypeMutation:
+		var payload MutationPayload
+		if err := j.GetPayload(&payload); err != nil {
+			return nil
+		}
+		files = []string{payload.SourceFilePath}
+	case JobTypeIntegration:
+		var payload IntegrationPayload
+		if err := j.GetPayload(&payload); err != nil {
+			return nil
+		}
+		files = payload.TestFilePaths
+	default:
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	var toolchains []string
+	for _, f := range files {
+		if tc := ToolchainForFile(f); tc != "" && !seen[tc] {
+			seen[tc] = true
+			toolchains = append(toolchains, tc)
+		}
+	}
+	sort.Strings(toolchains)
+	return toolchains
+}
+
+// Toolchain returns the toolchain a job is routed by: its only toolchain,
+// or "" for jobs spanning several or none
+func (j *Job) Toolchain() string {
+	if tcs := j.Toolchains(); len(tcs) == 1 {
+		return tcs[0]
+	}
+	return ""
+}
+
+// RequiredTools returns the tools a worker needs to run a job, sorted
+func (j *Job) RequiredTools() []string {
+	seen := make(map[string]bool)
+	var tools []string
+	for _, tc := range j.Toolchains() {
+		for _, tool := range ToolchainTools(j.Type, tc) {
+			if !seen[tool] {
+				seen[tool] = true
+				tools = append(tools, tool)
+			}
+		}
+	}
+	if j.Type == JobTypeIngestion {
+		var payload IngestionPayload
+		if err := j.GetPayload(&payload); err == nil && vcs.Detect(payload.RepositoryURL) == vcs.KindHg {
+			tools = append(tools, ToolHg)
+		}
+	}
+	sort.Strings(tools)
+	return tools
+}
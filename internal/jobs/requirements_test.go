@@ -0,0 +1,49 @@
+package jobs
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestJob_RequiredTools(t *testing.T) {
+	tests := []struct {
+		name      string
+		jobType   JobType
+		payload   interface{}
+		toolchain string
+		tools     []string
+	}{
+		{"validation by language", JobTypeValidation,
+			ValidationPayload{Language: "typescript", TestFilePaths: []string{"a_test.go"}},
+			ToolchainNode, []string{ToolNode}},
+		{"validation by files", JobTypeValidation,
+			ValidationPayload{TestFilePaths: []string{"tests/test_a.py"}},
+			ToolchainPython, []string{ToolPytest, ToolPython}},
+		{"go mutation", JobTypeMutation,
+			MutationPayload{SourceFilePath: "pkg/a.go"},
+			ToolchainGo, []string{ToolGo, ToolGoMutesting}},
+		{"mixed integration", JobTypeIntegration,
+			IntegrationPayload{TestFilePaths: []string{"a_test.go", "web/a.test.ts", "README.md"}},
+			"", []string{ToolGo, ToolNode}},
+		{"generation", JobTypeGeneration, GenerationPayload{}, "", nil},
+		{"git ingestion", JobTypeIngestion,
+			IngestionPayload{RepositoryURL: "https://github.com/owner/repo"}, "", nil},
+		{"mercurial ingestion", JobTypeIngestion,
+			IngestionPayload{RepositoryURL: "hg+https://hg.example.com/repo"}, "", []string{ToolHg}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job, err := NewJob(tt.jobType, tt.payload)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := job.Toolchain(); got != tt.toolchain {
+				t.Errorf("Toolchain() = %q, want %q", got, tt.toolchain)
+			}
+			if got := job.RequiredTools(); !reflect.DeepEqual(got, tt.tools) {
+				t.Errorf("RequiredTools() = %v, want %v", got, tt.tools)
+			}
+		})
+	}
+}
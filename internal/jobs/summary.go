@@ -0,0 +1,30 @@
+package jobs
+
+import "github.com/QTest-hq/qtest/internal/llm"
+
+// Keys the parts of a generation run's summary are kept under
+const (
+	RunSummaryLLMUsage    = "llm_usage"
+	RunSummaryEscalations = "escalations"
+)
+
+// RunSummary is what the jobs of a generation run record in its summary
+type RunSummary struct {
+	// LLMUsage totals the tokens and estimated cost of the run's LLM calls,
+	// for generation and auto-fix
+	LLMUsage *llm.UsageSummary `json:"llm_usage,omitempty"`
+
+	// Escalations are the targets retried at a higher tier after their
+	// output was malformed or empty
+	Escalations []Escalation `json:"escalations,omitempty"`
+}
+
+// Escalation is a target's generation being retried at a higher tier
+type Escalation struct {
+	File      string `json:"file"`
+	Function  string `json:"function"`
+	FromTier  int    `json:"from_tier"`
+	ToTier    int    `json:"to_tier"`
+	Reason    string `json:"reason"`
+	Recovered bool   `json:"recovered"` // a test was generated in the end
+}
@@ -67,6 +67,16 @@ type IngestionPayload struct {
 	LLMTier     int  `json:"llm_tier,omitempty"`
 	RunMutation bool `json:"run_mutation,omitempty"`
 	CreatePR    bool `json:"create_pr,omitempty"`
+	// PR options, read by the integration worker from the chain root
+	PR *PROptions `json:"pr,omitempty"`
+	// Batch the pipeline was submitted in, if any
+	BatchID *uuid.UUID `json:"batch_id,omitempty"`
+	// Repository policy, read by workers from the chain root
+	CoverageTarget float64  `json:"coverage_target,omitempty"`
+	Providers      []string `json:"providers,omitempty"`
+	// Only plan targets without a generated test, so a run over code that
+	// hasn't changed generates nothing and opens no PR
+	OnlyUntested bool `json:"only_untested,omitempty"`
 }
 
 // ModelingPayload is the payload for modeling jobs
@@ -75,6 +85,8 @@ type ModelingPayload struct {
 	WorkspacePath string    `json:"workspace_path"`
 	IncludePaths  []string  `json:"include_paths,omitempty"`
 	ExcludePaths  []string  `json:"exclude_paths,omitempty"`
+	// Supplements not to run, by name, on top of .qtest.yaml's
+	DisableSupplements []string `json:"disable_supplements,omitempty"`
 	// Pipeline options (propagated through chain)
 	MaxTests    int  `json:"max_tests,omitempty"`
 	LLMTier     int  `json:"llm_tier,omitempty"`
@@ -100,6 +112,7 @@ type GenerationPayload struct {
 	GenerationRunID uuid.UUID `json:"generation_run_id"`
 	PlanID          uuid.UUID `json:"plan_id"`
 	IntentIDs       []string  `json:"intent_ids,omitempty"` // Specific intents to generate
+	MaxTests        int       `json:"max_tests,omitempty"`  // Max tests across the whole run (0 = plan size)
 	LLMTier         int       `json:"llm_tier,omitempty"`   // 1=fast, 2=balanced, 3=thorough
 	RunMutation     bool      `json:"run_mutation"`         // Whether to run mutation testing
 	CreatePR        bool      `json:"create_pr"`            // Whether to create a PR at the end
@@ -126,15 +139,20 @@ type ValidationPayload struct {
 	// Pipeline continuation
 	RunMutation bool `json:"run_mutation"` // Whether to run mutation testing after validation
 	CreatePR    bool `json:"create_pr"`    // Whether to create a PR at the end
+	// Sharding: a run's tests may be split across several validation jobs
+	ShardIndex int    `json:"shard_index,omitempty"`
+	ShardCount int    `json:"shard_count,omitempty"`
+	CacheDir   string `json:"cache_dir,omitempty"` // Result cache directory (empty = no cache)
 }
 
 // IntegrationPayload is the payload for integration jobs
 type IntegrationPayload struct {
-	RepositoryID    uuid.UUID `json:"repository_id"`
-	GenerationRunID uuid.UUID `json:"generation_run_id"`
-	TestFilePaths   []string  `json:"test_file_paths"`
-	TargetBranch    string    `json:"target_branch,omitempty"`
-	CreatePR        bool      `json:"create_pr"`
+	RepositoryID    uuid.UUID  `json:"repository_id"`
+	GenerationRunID uuid.UUID  `json:"generation_run_id"`
+	TestFilePaths   []string   `json:"test_file_paths"`
+	TargetBranch    string     `json:"target_branch,omitempty"`
+	CreatePR        bool       `json:"create_pr"`
+	PR              *PROptions `json:"pr,omitempty"` // overrides the pipeline's PR options
 }
 
 // IngestionResult is the result of an ingestion job
@@ -161,6 +179,25 @@ type PlanningResult struct {
 	UnitTests  int       `json:"unit_tests"`
 	APITests   int       `json:"api_tests"`
 	E2ETests   int       `json:"e2e_tests"`
+
+	// Targets are the plan's intents resolved to source locations, in
+	// priority order. Generation only reads the files they name.
+	Targets []PlanTarget `json:"targets,omitempty"`
+
+	// AlreadyTested counts the targets left out of an only-untested plan
+	// because they have a generated test
+	AlreadyTested int `json:"already_tested,omitempty"`
+}
+
+// PlanTarget is a planned test intent resolved to the function it covers
+type PlanTarget struct {
+	IntentID string `json:"intent_id"`
+	Level    string `json:"level"`
+	Priority string `json:"priority"`
+	File     string `json:"file"`
+	Function string `json:"function"`
+	Line     int    `json:"line,omitempty"`
+	Endpoint string `json:"endpoint,omitempty"` // e.g. "GET /users/:id" for API intents
 }
 
 // GenerationResult is the result of a generation job
@@ -168,6 +205,26 @@ type GenerationResult struct {
 	TestsGenerated int      `json:"tests_generated"`
 	TestFilePaths  []string `json:"test_file_paths"`
 	FailedIntents  []string `json:"failed_intents,omitempty"`
+	CoveredIntents []string `json:"covered_intents,omitempty"` // plan intents a test was generated for
+	RepairedTests  int      `json:"repaired_tests,omitempty"`  // failing tests the repair loop fixed
+	RejectedTests  int      `json:"rejected_tests,omitempty"`  // tests not kept because they failed static checks
+
+	// Test files edited by hand since QTest generated them. They're left
+	// as they are, with the regenerated tests merged into a proposal file
+	PendingMerges []PendingMerge `json:"pending_merges,omitempty"`
+
+	// Checkpoint state, saved after each source file while the job runs so
+	// an interrupted job resumes after the last completed file
+	TestIDs        []string `json:"test_ids,omitempty"`
+	CompletedFiles []string `json:"completed_files,omitempty"`
+	Language       string   `json:"language,omitempty"`
+}
+
+// PendingMerge is a merge proposal for a hand-edited test file
+type PendingMerge struct {
+	TestFile  string `json:"test_file"`
+	MergeFile string `json:"merge_file"`
+	Conflicts int    `json:"conflicts"`
 }
 
 // MutationResult is the result of a mutation testing job
@@ -177,6 +234,16 @@ type MutationResult struct {
 	MutantsLived   int     `json:"mutants_lived"`
 	MutationScore  float64 `json:"mutation_score"`
 	ReportFilePath string  `json:"report_file_path,omitempty"`
+
+	SourceFile string           `json:"source_file,omitempty"`
+	Survivors  []MutantSurvivor `json:"survivors,omitempty"`
+}
+
+// MutantSurvivor is a mutant the tests didn't kill
+type MutantSurvivor struct {
+	Line        int    `json:"line"`
+	Type        string `json:"type"`
+	Description string `json:"description"`
 }
 
 // ValidationResult is the result of a validation job
@@ -185,6 +252,9 @@ type ValidationResult struct {
 	PassedTests    int                 `json:"passed_tests"`
 	FailedTests    int                 `json:"failed_tests"`
 	FixedTests     int                 `json:"fixed_tests"`
+	CachedTests    int                 `json:"cached_tests"` // Passed from the result cache without running
+	ShardIndex     int                 `json:"shard_index,omitempty"`
+	ShardCount     int                 `json:"shard_count,omitempty"`
 	ValidationTime time.Duration       `json:"validation_time"`
 	Results        []TestValidationRes `json:"results"`
 }
@@ -206,6 +276,29 @@ type IntegrationResult struct {
 	PRNumber        int    `json:"pr_number,omitempty"`
 	PRURL           string `json:"pr_url,omitempty"`
 	BranchName      string `json:"branch_name,omitempty"`
+
+	// Bazel BUILD or Nx project.json files given targets for the tests
+	BuildFiles []string `json:"build_files,omitempty"`
+
+	// Test dependencies declared for the tests, one summary per file
+	// changed, with PROptions.TestDependencies
+	TestDependencies []string `json:"test_dependencies,omitempty"`
+
+	// Verification run, one entry per runner invocation
+	TestsPassed bool            `json:"tests_passed"`
+	TestRuns    []TestRunResult `json:"test_runs,omitempty"`
+}
+
+// TestRunResult is the outcome of one test runner invocation, e.g. a Go
+// package, a pytest directory or a jest project
+type TestRunResult struct {
+	Runner     string   `json:"runner"`
+	Language   string   `json:"language"`
+	Target     string   `json:"target"`
+	Files      int      `json:"files"`
+	Passed     bool     `json:"passed"`
+	Failures   []string `json:"failures,omitempty"` // failing test names
+	DurationMs int64    `json:"duration_ms"`
 }
 
 // NewJob creates a new job with defaults
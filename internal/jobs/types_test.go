@@ -361,9 +361,10 @@ func TestJob_SetResult_InvalidType(t *testing.T) {
 }
 
 func TestJob_GetResult_InvalidJSON(t *testing.T) {
+	invalid := json.RawMessage(`{invalid}`)
 	job := &Job{
 		ID:     uuid.New(),
-		Result: json.RawMessage(`{invalid}`),
+		Result: &invalid,
 	}
 	var result IngestionResult
 	err := job.GetResult(&result)
@@ -377,10 +378,12 @@ func TestJob_GetResult_NilResult(t *testing.T) {
 		ID:     uuid.New(),
 		Result: nil,
 	}
-	var result IngestionResult
-	err := job.GetResult(&result)
-	if err == nil {
-		t.Error("GetResult() should error for nil result")
+	result := IngestionResult{FileCount: 3}
+	if err := job.GetResult(&result); err != nil {
+		t.Errorf("GetResult() error = %v, want nil for a job without a result", err)
+	}
+	if result.FileCount != 3 {
+		t.Error("GetResult() should leave the value unchanged for nil result")
 	}
 }
 
@@ -469,6 +472,8 @@ func TestJob_Fields(t *testing.T) {
 	workerID := "worker-1"
 	errMsg := "test error"
 	now := time.Now()
+	result := json.RawMessage(`{}`)
+	details := json.RawMessage(`{"code": 500}`)
 
 	job := &Job{
 		ID:              uuid.New(),
@@ -479,9 +484,9 @@ func TestJob_Fields(t *testing.T) {
 		GenerationRunID: &runID,
 		ParentJobID:     &parentID,
 		Payload:         json.RawMessage(`{}`),
-		Result:          json.RawMessage(`{}`),
+		Result:          &result,
 		ErrorMessage:    &errMsg,
-		ErrorDetails:    json.RawMessage(`{"code": 500}`),
+		ErrorDetails:    &details,
 		RetryCount:      2,
 		MaxRetries:      5,
 		CreatedAt:       now,
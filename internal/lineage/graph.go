@@ -0,0 +1,340 @@
+// Package lineage records how generated tests came to be: which plan intent
+// produced which spec, which file the spec was emitted to, and what execution
+// and mutation runs made of it. The graph is persisted as JSON next to the
+// tests so users can trace why a test exists and which gap it closes.
+package lineage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/QTest-hq/qtest/internal/workspace"
+	"github.com/QTest-hq/qtest/pkg/model"
+)
+
+// DefaultPath is where the lineage graph is stored relative to a repository
+const DefaultPath = ".qtest/lineage.json"
+
+// NodeKind identifies a stage of the pipeline
+type NodeKind string
+
+const (
+	KindIntent    NodeKind = "intent"
+	KindSpec      NodeKind = "spec"
+	KindFile      NodeKind = "file"
+	KindExecution NodeKind = "execution"
+	KindMutation  NodeKind = "mutation"
+)
+
+// Edge relations
+const (
+	RelSpecifiedBy = "specified_by" // intent -> spec
+	RelEmittedTo   = "emitted_to"   // spec -> file
+	RelExecutedAs  = "executed_as"  // spec -> execution result
+	RelMutatedBy   = "mutated_by"   // spec -> mutation result
+)
+
+// Node is one artifact in the lineage graph
+type Node struct {
+	ID        string            `json:"id"`
+	Kind      NodeKind          `json:"kind"`
+	Ref       string            `json:"ref"` // intent/spec ID, file path or test ID
+	LineageID string            `json:"lineage_id,omitempty"`
+	Label     string            `json:"label,omitempty"`
+	Attrs     map[string]string `json:"attrs,omitempty"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+// Edge links two nodes
+type Edge struct {
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Relation string `json:"relation"`
+}
+
+// Graph is the persisted lineage graph
+type Graph struct {
+	Version string           `json:"version"`
+	Nodes   map[string]*Node `json:"nodes"`
+	Edges   []Edge           `json:"edges"`
+}
+
+// New creates an empty graph
+func New() *Graph {
+	return &Graph{Version: "1.0", Nodes: make(map[string]*Node)}
+}
+
+// Load reads a graph from disk. A missing file yields an empty graph.
+func Load(path string) (*Graph, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return New(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lineage graph: %w", err)
+	}
+
+	g := New()
+	if err := json.Unmarshal(data, g); err != nil {
+		return nil, fmt.Errorf("invalid lineage graph: %w", err)
+	}
+	if g.Nodes == nil {
+		g.Nodes = make(map[string]*Node)
+	}
+	return g, nil
+}
+
+// Save writes the graph to disk, creating parent directories
+func (g *Graph) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create lineage directory: %w", err)
+	}
+
+	sort.Slice(g.Edges, func(i, j int) bool {
+		if g.Edges[i].From != g.Edges[j].From {
+			return g.Edges[i].From < g.Edges[j].From
+		}
+		return g.Edges[i].To < g.Edges[j].To
+	})
+
+	data, err := json.MarshalIndent(g, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal lineage graph: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write lineage graph: %w", err)
+	}
+	return nil
+}
+
+// AddPlan records every intent in a plan
+func (g *Graph) AddPlan(plan *model.TestPlan) {
+	for _, intent := range plan.Intents {
+		lineageID := intent.LineageID
+		if lineageID == "" {
+			lineageID = model.LineageIDFor(intent.ID)
+		}
+		g.upsert(KindIntent, intent.ID, lineageID, intent.Reason, map[string]string{
+			"level":    string(intent.Level),
+			"target":   intent.TargetID,
+			"priority": intent.Priority,
+		})
+	}
+}
+
+// AddSpecs records specs and links them to their intents
+func (g *Graph) AddSpecs(set *model.TestSpecSet) {
+	for _, spec := range set.Specs {
+		node := g.upsert(KindSpec, spec.ID, spec.LineageID, spec.Description, map[string]string{
+			"level":  string(spec.Level),
+			"target": spec.TargetID,
+		})
+		if intent := g.findLineage(KindIntent, spec.LineageID); intent != nil {
+			g.link(intent.ID, node.ID, RelSpecifiedBy)
+		}
+	}
+}
+
+// AddEmittedFile records that specs were emitted into a test file
+func (g *Graph) AddEmittedFile(path string, specs []model.TestSpec) {
+	file := g.upsert(KindFile, filepath.ToSlash(path), "", filepath.Base(path), nil)
+	for _, spec := range specs {
+		specNode := g.upsert(KindSpec, spec.ID, spec.LineageID, spec.Description, nil)
+		g.link(specNode.ID, file.ID, RelEmittedTo)
+	}
+}
+
+// AddExecution records test results, linking each to the spec it ran
+func (g *Graph) AddExecution(report *workspace.ExecutionReport) {
+	for _, r := range report.Tests {
+		spec := g.specFor(r.ID, r.Name)
+		lineageID := ""
+		if spec != nil {
+			lineageID = spec.LineageID
+		}
+
+		ref := r.ID
+		if ref == "" {
+			ref = r.File + "#" + r.Name
+		}
+		attrs := map[string]string{
+			"status":      r.Status,
+			"duration_ms": fmt.Sprintf("%d", r.DurationMs),
+		}
+		if r.Error != "" {
+			attrs["error"] = r.Error
+		}
+
+		node := g.upsert(KindExecution, ref, lineageID, r.Name, attrs)
+		node.UpdatedAt = report.ExecutedAt
+		if spec != nil {
+			g.link(spec.ID, node.ID, RelExecutedAs)
+		}
+	}
+}
+
+// AddMutation records per-test mutation scores and surviving mutants
+func (g *Graph) AddMutation(report *workspace.MutationReport) {
+	survivors := make(map[string][]string)
+	for _, s := range report.Survivors {
+		survivors[s.TestThatShouldCatch] = append(survivors[s.TestThatShouldCatch],
+			fmt.Sprintf("%s %s: %s -> %s", s.Location, s.Operator, s.Original, s.Mutated))
+	}
+
+	for _, t := range report.ByTest {
+		spec := g.specFor(t.TestID, "")
+		lineageID := ""
+		if spec != nil {
+			lineageID = spec.LineageID
+		}
+
+		attrs := map[string]string{
+			"score":  fmt.Sprintf("%.1f", t.Score),
+			"killed": fmt.Sprintf("%d/%d", t.Killed, t.MutantsTested),
+		}
+		if s := survivors[t.TestID]; len(s) > 0 {
+			attrs["survivors"] = strings.Join(s, "; ")
+		}
+
+		node := g.upsert(KindMutation, t.TestID, lineageID, t.TestID, attrs)
+		node.UpdatedAt = report.ExecutedAt
+		if spec != nil {
+			g.link(spec.ID, node.ID, RelMutatedBy)
+		}
+	}
+}
+
+// Trace returns every node connected to the query, which may be a lineage
+// ID, an intent/spec/test ID, a file path or a test name. Nodes are ordered
+// by pipeline stage.
+func (g *Graph) Trace(query string) ([]*Node, error) {
+	var start []*Node
+	for _, n := range g.Nodes {
+		if n.LineageID == query || n.Ref == query || n.ID == query || n.Label == query ||
+			(n.Kind == KindFile && strings.HasSuffix(n.Ref, filepath.ToSlash(query))) {
+			start = append(start, n)
+		}
+	}
+	if len(start) == 0 {
+		return nil, fmt.Errorf("no lineage found for %q", query)
+	}
+
+	adjacent := make(map[string][]string)
+	for _, e := range g.Edges {
+		adjacent[e.From] = append(adjacent[e.From], e.To)
+		adjacent[e.To] = append(adjacent[e.To], e.From)
+	}
+
+	// Walk edges, but don't fan out through files (a file holds many
+	// unrelated specs) unless the file itself was queried
+	fileQuery := len(start) == 1 && start[0].Kind == KindFile
+	seen := make(map[string]bool)
+	queue := make([]string, 0, len(start))
+	for _, n := range start {
+		seen[n.ID] = true
+		queue = append(queue, n.ID)
+	}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if g.Nodes[id].Kind == KindFile && !(fileQuery && id == start[0].ID) {
+			continue
+		}
+		for _, next := range adjacent[id] {
+			if !seen[next] && g.Nodes[next] != nil {
+				seen[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	nodes := make([]*Node, 0, len(seen))
+	for id := range seen {
+		nodes = append(nodes, g.Nodes[id])
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		if kindOrder[nodes[i].Kind] != kindOrder[nodes[j].Kind] {
+			return kindOrder[nodes[i].Kind] < kindOrder[nodes[j].Kind]
+		}
+		return nodes[i].Ref < nodes[j].Ref
+	})
+	return nodes, nil
+}
+
+var kindOrder = map[NodeKind]int{
+	KindIntent:    0,
+	KindSpec:      1,
+	KindFile:      2,
+	KindExecution: 3,
+	KindMutation:  4,
+}
+
+// upsert creates or updates a node, keeping existing values for empty fields
+func (g *Graph) upsert(kind NodeKind, ref, lineageID, label string, attrs map[string]string) *Node {
+	id := string(kind) + "/" + ref
+	n, ok := g.Nodes[id]
+	if !ok {
+		n = &Node{ID: id, Kind: kind, Ref: ref}
+		g.Nodes[id] = n
+	}
+	if lineageID != "" {
+		n.LineageID = lineageID
+	}
+	if label != "" {
+		n.Label = label
+	}
+	if len(attrs) > 0 {
+		if n.Attrs == nil {
+			n.Attrs = make(map[string]string)
+		}
+		for k, v := range attrs {
+			if v != "" {
+				n.Attrs[k] = v
+			}
+		}
+	}
+	n.UpdatedAt = time.Now()
+	return n
+}
+
+func (g *Graph) link(from, to, relation string) {
+	for _, e := range g.Edges {
+		if e.From == from && e.To == to && e.Relation == relation {
+			return
+		}
+	}
+	g.Edges = append(g.Edges, Edge{From: from, To: to, Relation: relation})
+}
+
+func (g *Graph) findLineage(kind NodeKind, lineageID string) *Node {
+	if lineageID == "" {
+		return nil
+	}
+	for _, n := range g.Nodes {
+		if n.Kind == kind && n.LineageID == lineageID {
+			return n
+		}
+	}
+	return nil
+}
+
+// specFor finds the spec a result belongs to, by spec ID then description
+func (g *Graph) specFor(testID, name string) *Node {
+	if n, ok := g.Nodes[string(KindSpec)+"/"+testID]; ok && testID != "" {
+		return n
+	}
+	if name == "" {
+		return nil
+	}
+	for _, n := range g.Nodes {
+		if n.Kind == KindSpec && n.Label == name {
+			return n
+		}
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package lineage
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/QTest-hq/qtest/internal/workspace"
+	"github.com/QTest-hq/qtest/pkg/model"
+)
+
+func sampleGraph() *Graph {
+	g := New()
+	g.AddPlan(&model.TestPlan{Intents: []model.TestIntent{
+		{ID: "intent:api:e1", Level: model.LevelAPI, TargetID: "e1", Reason: "API endpoint: GET /users", LineageID: "ln-users"},
+		{ID: "intent:api:e2", Level: model.LevelAPI, TargetID: "e2", Reason: "API endpoint: POST /orders", LineageID: "ln-orders"},
+	}})
+	g.AddSpecs(&model.TestSpecSet{Specs: []model.TestSpec{
+		{ID: "spec-users", Level: model.LevelAPI, Description: "lists users", LineageID: "ln-users"},
+		{ID: "spec-orders", Level: model.LevelAPI, Description: "creates order", LineageID: "ln-orders"},
+	}})
+	g.AddEmittedFile("tests/api.test.js", []model.TestSpec{
+		{ID: "spec-users", LineageID: "ln-users"},
+		{ID: "spec-orders", LineageID: "ln-orders"},
+	})
+	g.AddExecution(&workspace.ExecutionReport{
+		ExecutedAt: time.Now(),
+		Tests: []workspace.TestResult{
+			{ID: "spec-users", Name: "lists users", Status: "passed", DurationMs: 12},
+			{Name: "creates order", File: "tests/api.test.js", Status: "failed", Error: "expected 201"},
+		},
+	})
+	g.AddMutation(&workspace.MutationReport{
+		ByTest:    []workspace.TestMutations{{TestID: "spec-users", MutantsTested: 4, Killed: 3, Score: 75}},
+		Survivors: []workspace.SurvivedMutant{{Operator: "negate", Location: "users.go:10", Original: "==", Mutated: "!=", TestThatShouldCatch: "spec-users"}},
+	})
+	return g
+}
+
+func kinds(nodes []*Node) map[NodeKind]int {
+	out := map[NodeKind]int{}
+	for _, n := range nodes {
+		out[n.Kind]++
+	}
+	return out
+}
+
+func TestGraph_TraceByLineageID(t *testing.T) {
+	nodes, err := sampleGraph().Trace("ln-users")
+	if err != nil {
+		t.Fatalf("Trace() error = %v", err)
+	}
+
+	got := kinds(nodes)
+	want := map[NodeKind]int{KindIntent: 1, KindSpec: 1, KindFile: 1, KindExecution: 1, KindMutation: 1}
+	for k, n := range want {
+		if got[k] != n {
+			t.Errorf("%s nodes = %d, want %d (all: %v)", k, got[k], n, got)
+		}
+	}
+
+	if nodes[0].Kind != KindIntent || nodes[0].Label != "API endpoint: GET /users" {
+		t.Errorf("first node = %+v, want the originating intent", nodes[0])
+	}
+	for _, n := range nodes {
+		if n.Kind == KindMutation && n.Attrs["survivors"] == "" {
+			t.Error("mutation node should list surviving mutants")
+		}
+	}
+}
+
+func TestGraph_TraceDoesNotCrossFiles(t *testing.T) {
+	nodes, err := sampleGraph().Trace("spec-orders")
+	if err != nil {
+		t.Fatalf("Trace() error = %v", err)
+	}
+	for _, n := range nodes {
+		if n.LineageID == "ln-users" {
+			t.Errorf("trace leaked into another lineage via shared file: %s", n.ID)
+		}
+	}
+	if kinds(nodes)[KindExecution] != 1 {
+		t.Error("result matched by test name should be linked to its spec")
+	}
+}
+
+func TestGraph_TraceFile(t *testing.T) {
+	nodes, err := sampleGraph().Trace("api.test.js")
+	if err != nil {
+		t.Fatalf("Trace() error = %v", err)
+	}
+	if got := kinds(nodes); got[KindSpec] != 2 || got[KindIntent] != 2 {
+		t.Errorf("file trace = %v, want both specs and intents", got)
+	}
+}
+
+func TestGraph_SaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".qtest", "lineage.json")
+
+	g := sampleGraph()
+	if err := g.Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(loaded.Nodes) != len(g.Nodes) || len(loaded.Edges) != len(g.Edges) {
+		t.Errorf("loaded %d nodes/%d edges, want %d/%d", len(loaded.Nodes), len(loaded.Edges), len(g.Nodes), len(g.Edges))
+	}
+
+	// Re-recording is idempotent
+	loaded.AddEmittedFile("tests/api.test.js", []model.TestSpec{{ID: "spec-users"}})
+	if len(loaded.Edges) != len(g.Edges) {
+		t.Error("re-recording the same link should not add edges")
+	}
+
+	if _, err := New().Trace("missing"); err == nil {
+		t.Error("expected error for unknown query")
+	}
+}
+
+func TestLoad_Missing(t *testing.T) {
+	g, err := Load(filepath.Join(t.TempDir(), "nope.json"))
+	if err != nil || len(g.Nodes) != 0 {
+		t.Errorf("Load(missing) = %v, %v; want empty graph", g, err)
+	}
+}
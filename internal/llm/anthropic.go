@@ -64,8 +64,26 @@ type anthropicRequest struct {
 	Messages      []anthropicMessage `json:"messages"`
 	Temperature   float64            `json:"temperature,omitempty"`
 	StopSequences []string           `json:"stop_sequences,omitempty"`
+	Tools         []anthropicTool    `json:"tools,omitempty"`
+	ToolChoice    *anthropicChoice   `json:"tool_choice,omitempty"`
 }
 
+// anthropicTool is a tool the model may call; structured output is a call
+// to a tool whose input schema is the output's
+type anthropicTool struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	InputSchema json.RawMessage `json:"input_schema"`
+}
+
+type anthropicChoice struct {
+	Type string `json:"type"`
+	Name string `json:"name,omitempty"`
+}
+
+// outputTool is the tool a schema-constrained response is returned through
+const outputTool = "output"
+
 type anthropicMessage struct {
 	Role    string `json:"role"`
 	Content string `json:"content"`
@@ -77,8 +95,10 @@ type anthropicResponse struct {
 	Type    string `json:"type"`
 	Role    string `json:"role"`
 	Content []struct {
-		Type string `json:"type"`
-		Text string `json:"text"`
+		Type  string          `json:"type"`
+		Text  string          `json:"text"`
+		Name  string          `json:"name"`
+		Input json.RawMessage `json:"input"`
 	} `json:"content"`
 	Model      string `json:"model"`
 	StopReason string `json:"stop_reason"`
@@ -90,6 +110,9 @@ type anthropicResponse struct {
 
 func (c *AnthropicClient) Complete(ctx context.Context, req *Request) (*Response, error) {
 	model, ok := c.models[req.Tier]
+	if req.Model != "" {
+		model, ok = req.Model, true
+	}
 	if !ok {
 		return nil, fmt.Errorf("no model configured for tier %d", req.Tier)
 	}
@@ -118,6 +141,17 @@ func (c *AnthropicClient) Complete(ctx context.Context, req *Request) (*Response
 		StopSequences: req.Stop,
 	}
 
+	// Force output matching the schema by making the model answer through a
+	// tool that takes it as input
+	if len(req.Schema) > 0 {
+		anthropicReq.Tools = []anthropicTool{{
+			Name:        outputTool,
+			Description: "Return the output. Its input is the complete response.",
+			InputSchema: req.Schema,
+		}}
+		anthropicReq.ToolChoice = &anthropicChoice{Type: "tool", Name: outputTool}
+	}
+
 	// Serialize request
 	body, err := json.Marshal(anthropicReq)
 	if err != nil {
@@ -167,16 +201,8 @@ func (c *AnthropicClient) Complete(ctx context.Context, req *Request) (*Response
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
-	// Extract text content
-	var content string
-	for _, c := range anthropicResp.Content {
-		if c.Type == "text" {
-			content += c.Text
-		}
-	}
-
 	return &Response{
-		Content:      content,
+		Content:      anthropicResp.text(),
 		Model:        anthropicResp.Model,
 		Provider:     ProviderAnthropic,
 		InputTokens:  anthropicResp.Usage.InputTokens,
@@ -184,3 +210,20 @@ func (c *AnthropicClient) Complete(ctx context.Context, req *Request) (*Response
 		FinishReason: anthropicResp.StopReason,
 	}, nil
 }
+
+// text returns the response's content: the output tool's input when the
+// model answered through it, else its text
+func (r *anthropicResponse) text() string {
+	var content string
+	for _, c := range r.Content {
+		switch c.Type {
+		case "tool_use":
+			if c.Name == outputTool {
+				return string(c.Input)
+			}
+		case "text":
+			content += c.Text
+		}
+	}
+	return content
+}
@@ -1,6 +1,7 @@
 package llm
 
 import (
+	"encoding/json"
 	"testing"
 )
 
@@ -245,3 +246,23 @@ func TestAnthropicResponse_Fields(t *testing.T) {
 		t.Errorf("Role = %s, want assistant", resp.Role)
 	}
 }
+
+func TestAnthropicResponse_Text(t *testing.T) {
+	var resp anthropicResponse
+	body := `{"content": [
+		{"type": "text", "text": "Calling the tool"},
+		{"type": "tool_use", "name": "output", "input": {"function_name": "Add", "tests": []}}
+	]}`
+	if err := json.Unmarshal([]byte(body), &resp); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if got := resp.text(); got != `{"function_name": "Add", "tests": []}` {
+		t.Errorf("text() = %q, want the tool input", got)
+	}
+
+	resp = anthropicResponse{}
+	json.Unmarshal([]byte(`{"content": [{"type": "text", "text": "a"}, {"type": "text", "text": "b"}]}`), &resp)
+	if got := resp.text(); got != "ab" {
+		t.Errorf("text() = %q, want ab", got)
+	}
+}
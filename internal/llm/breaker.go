@@ -0,0 +1,191 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ErrCircuitOpen is returned when a request's providers were skipped
+// because their circuits are open
+var ErrCircuitOpen = errors.New("circuit open")
+
+// errProviderUnavailable counts a failed availability check as a failure
+var errProviderUnavailable = errors.New("provider not available")
+
+// maxRetryTokens is how many retries a provider's budget can save up
+const maxRetryTokens = 10
+
+type breakerState int
+
+const (
+	breakerClosed   breakerState = iota // requests pass
+	breakerOpen                         // requests fail fast
+	breakerHalfOpen                     // one probe request passes
+)
+
+func (s breakerState) String() string {
+	switch s {
+	case breakerOpen:
+		return "open"
+	case breakerHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// breaker is a provider's circuit breaker and retry budget. A nil breaker
+// lets every request and retry through.
+type breaker struct {
+	provider  Provider
+	threshold int           // failed attempts in a row that open the circuit
+	cooldown  time.Duration // how long it stays open before a probe
+	ratio     float64       // retry tokens earned per request; 0 is unlimited
+	now       func() time.Time
+
+	mu       sync.Mutex
+	state    breakerState
+	failures int
+	openedAt time.Time
+	probing  bool // the half-open probe is in flight
+	tokens   float64
+}
+
+// newBreaker returns a breaker for provider, or nil when threshold is 0
+// and retries aren't budgeted
+func newBreaker(provider Provider, threshold int, cooldown time.Duration, budgetPercent int) *breaker {
+	if threshold <= 0 && budgetPercent <= 0 {
+		return nil
+	}
+	return &breaker{
+		provider:  provider,
+		threshold: threshold,
+		cooldown:  cooldown,
+		ratio:     float64(budgetPercent) / 100,
+		now:       time.Now,
+		tokens:    maxRetryTokens,
+	}
+}
+
+// allow reports whether a request may be sent to the provider. Once the
+// circuit has been open for the cooldown, one request is let through to
+// probe whether the provider has recovered.
+func (b *breaker) allow() bool {
+	if b == nil {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case breakerOpen:
+		if b.now().Sub(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.state = breakerHalfOpen
+		b.probing = true
+		log.Info().Str("provider", string(b.provider)).Msg("circuit half-open, probing provider")
+		return true
+	case breakerHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	}
+
+	if b.ratio > 0 {
+		b.tokens += b.ratio
+		if b.tokens > maxRetryTokens {
+			b.tokens = maxRetryTokens
+		}
+	}
+	return true
+}
+
+// allowRetry reports whether a failed request may be retried: the circuit
+// must be closed and the retry budget not spent
+func (b *breaker) allowRetry() bool {
+	if b == nil {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state != breakerClosed {
+		return false
+	}
+	if b.ratio <= 0 {
+		return true
+	}
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// record records an attempt's outcome. Only errors worth retrying count as
+// failures; others, such as a bad request or a cancelled context, say
+// nothing about the provider's health.
+func (b *breaker) record(ctx context.Context, err error) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch {
+	case err == nil:
+		if b.state != breakerClosed {
+			log.Info().Str("provider", string(b.provider)).Msg("circuit closed, provider recovered")
+		}
+		b.state = breakerClosed
+		b.failures = 0
+		b.probing = false
+	case ctx.Err() != nil || !isRetryableError(err):
+		b.probing = false
+	default:
+		b.failures++
+		b.probing = false
+		if b.threshold <= 0 {
+			return
+		}
+		if b.state == breakerHalfOpen || (b.state == breakerClosed && b.failures >= b.threshold) {
+			if b.state == breakerClosed {
+				log.Warn().
+					Err(err).
+					Str("provider", string(b.provider)).
+					Int("failures", b.failures).
+					Dur("cooldown", b.cooldown).
+					Msg("circuit open, failing fast")
+			}
+			b.state = breakerOpen
+			b.openedAt = b.now()
+		}
+	}
+}
+
+// isOpen reports whether requests are being kept from the provider
+func (b *breaker) isOpen() bool {
+	if b == nil {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state != breakerClosed
+}
+
+// String returns the breaker's state
+func (b *breaker) String() string {
+	if b == nil {
+		return breakerClosed.String()
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state.String()
+}
@@ -0,0 +1,186 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock is a settable time source for breakers
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time { return c.t }
+
+func newTestBreaker(threshold, budgetPercent int) (*breaker, *fakeClock) {
+	clock := &fakeClock{t: time.Unix(0, 0)}
+	b := newBreaker(ProviderOllama, threshold, time.Minute, budgetPercent)
+	b.now = clock.now
+	return b, clock
+}
+
+func TestNewBreaker_Disabled(t *testing.T) {
+	b := newBreaker(ProviderOllama, 0, time.Minute, 0)
+	assert.Nil(t, b)
+	assert.True(t, b.allow())
+	assert.True(t, b.allowRetry())
+	b.record(context.Background(), errors.New("timeout"))
+	assert.Equal(t, "closed", b.String())
+}
+
+func TestBreaker_OpensAndRecovers(t *testing.T) {
+	ctx := context.Background()
+	b, clock := newTestBreaker(3, 0)
+	timeout := errors.New("request timeout")
+
+	for i := 0; i < 2; i++ {
+		require.True(t, b.allow())
+		b.record(ctx, timeout)
+	}
+	assert.Equal(t, "closed", b.String())
+
+	// A success resets the count
+	b.record(ctx, nil)
+	for i := 0; i < 3; i++ {
+		b.record(ctx, timeout)
+	}
+	assert.Equal(t, "open", b.String())
+	assert.False(t, b.allow(), "open circuit should fail fast")
+	assert.False(t, b.allowRetry(), "open circuit should not retry")
+
+	// After the cooldown, one probe goes through
+	clock.t = clock.t.Add(time.Minute)
+	assert.True(t, b.allow())
+	assert.Equal(t, "half-open", b.String())
+	assert.False(t, b.allow(), "only one probe at a time")
+	assert.False(t, b.allowRetry(), "probes are not retried")
+
+	// A failed probe reopens it for another cooldown
+	b.record(ctx, timeout)
+	assert.Equal(t, "open", b.String())
+	assert.False(t, b.allow())
+
+	clock.t = clock.t.Add(time.Minute)
+	require.True(t, b.allow())
+	b.record(ctx, nil)
+	assert.Equal(t, "closed", b.String())
+	assert.True(t, b.allow())
+}
+
+func TestBreaker_IgnoresNonProviderErrors(t *testing.T) {
+	b, clock := newTestBreaker(1, 0)
+
+	b.record(context.Background(), errors.New("400 bad request"))
+	assert.Equal(t, "closed", b.String())
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	b.record(cancelled, errors.New("deadline exceeded"))
+	assert.Equal(t, "closed", b.String())
+
+	// A probe ending in a bad request lets the next request probe
+	b.record(context.Background(), errors.New("timeout"))
+	clock.t = clock.t.Add(time.Minute)
+	require.True(t, b.allow())
+	b.record(context.Background(), errors.New("400 bad request"))
+	assert.True(t, b.allow())
+}
+
+func TestBreaker_RetryBudget(t *testing.T) {
+	b, _ := newTestBreaker(0, 50)
+
+	// The budget starts full
+	for i := 0; i < maxRetryTokens; i++ {
+		require.True(t, b.allowRetry(), "retry %d", i)
+	}
+	assert.False(t, b.allowRetry(), "budget should be spent")
+
+	// Each request earns half a retry
+	b.allow()
+	assert.False(t, b.allowRetry())
+	b.allow()
+	assert.True(t, b.allowRetry())
+}
+
+func TestRouter_Complete_SkipsOpenCircuit(t *testing.T) {
+	ollama := newMockClient(ProviderOllama, true).withErrors(errors.New("request timeout"))
+	anthropic := newMockClient(ProviderAnthropic, true)
+
+	router := &Router{
+		config: &RouterConfig{DefaultProvider: ProviderOllama},
+		clients: map[Provider]Client{
+			ProviderOllama:    ollama,
+			ProviderAnthropic: anthropic,
+		},
+		fallbacks: []Provider{ProviderOllama, ProviderAnthropic},
+		breakers: map[Provider]*breaker{
+			ProviderOllama: newBreaker(ProviderOllama, 1, time.Hour, 0),
+		},
+	}
+
+	// The first timeout opens the circuit, so it isn't retried
+	resp, err := router.Complete(context.Background(), &Request{Tier: Tier1})
+	require.NoError(t, err)
+	assert.Equal(t, ProviderAnthropic, resp.Provider)
+	assert.Equal(t, 1, ollama.callCount)
+	assert.Equal(t, "open", router.CircuitStates()[ProviderOllama])
+	assert.Equal(t, "closed", router.CircuitStates()[ProviderAnthropic])
+
+	// Later requests skip it
+	_, err = router.Complete(context.Background(), &Request{Tier: Tier1})
+	require.NoError(t, err)
+	assert.Equal(t, 1, ollama.callCount)
+	assert.Equal(t, 2, anthropic.callCount)
+}
+
+// tierClient serves only some tiers
+type tierClient struct {
+	name  Provider
+	tiers map[Tier]bool
+}
+
+func (c *tierClient) Name() Provider  { return c.name }
+func (c *tierClient) Available() bool { return true }
+
+func (c *tierClient) Complete(ctx context.Context, req *Request) (*Response, error) {
+	if !c.tiers[req.Tier] {
+		return nil, fmt.Errorf("no model configured for tier %d", req.Tier)
+	}
+	return &Response{Content: "ok", Model: fmt.Sprintf("tier%d", req.Tier), Provider: c.name}, nil
+}
+
+func TestRouter_Complete_FallsBackToTier(t *testing.T) {
+	open := newBreaker(ProviderOllama, 1, time.Hour, 0)
+	open.record(context.Background(), errors.New("timeout"))
+
+	router := &Router{
+		config: &RouterConfig{DefaultProvider: ProviderOllama},
+		clients: map[Provider]Client{
+			ProviderOllama: newMockClient(ProviderOllama, true),
+			ProviderOpenAI: &tierClient{name: ProviderOpenAI, tiers: map[Tier]bool{Tier3: true}},
+		},
+		fallbacks: []Provider{ProviderOllama, ProviderOpenAI},
+		breakers:  map[Provider]*breaker{ProviderOllama: open},
+	}
+
+	resp, err := router.Complete(context.Background(), &Request{Tier: Tier1})
+	require.NoError(t, err)
+	assert.Equal(t, "tier3", resp.Model)
+
+	// Without open circuits, a tier's failure is returned as is
+	router.breakers = nil
+	router.clients[ProviderOllama] = newMockClient(ProviderOllama, false)
+	_, err = router.Complete(context.Background(), &Request{Tier: Tier1})
+	require.Error(t, err)
+	assert.False(t, errors.Is(err, ErrCircuitOpen))
+}
+
+func TestFallbackTiers(t *testing.T) {
+	assert.Equal(t, []Tier{Tier2, Tier3}, fallbackTiers(Tier1))
+	assert.Equal(t, []Tier{Tier3, Tier1}, fallbackTiers(Tier2))
+	assert.Equal(t, []Tier{Tier2, Tier1}, fallbackTiers(Tier3))
+}
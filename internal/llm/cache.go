@@ -213,6 +213,7 @@ func (r *CachedRouter) Complete(ctx context.Context, req *Request) (*Response, e
 	// Check cache first
 	if cached, ok := r.cache.Get(ctx, cacheKey); ok {
 		cached.Cached = true
+		RunUsageFrom(ctx).Record(req.Tier, cached)
 		return cached, nil
 	}
 
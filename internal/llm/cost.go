@@ -0,0 +1,165 @@
+package llm
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// tokenPrice is a model's price in USD per million input and output tokens
+type tokenPrice struct {
+	Input  float64
+	Output float64
+}
+
+// anthropicPrices are Anthropic's list prices for the models the tiers use
+var anthropicPrices = map[string]tokenPrice{
+	"claude-3-haiku-20240307":    {Input: 0.25, Output: 1.25},
+	"claude-3-5-haiku-20241022":  {Input: 0.80, Output: 4},
+	"claude-3-5-sonnet-20241022": {Input: 3, Output: 15},
+	"claude-3-opus-20240229":     {Input: 15, Output: 75},
+}
+
+// anthropicFamilyPrices price Anthropic models not listed above by family
+var anthropicFamilyPrices = map[string]tokenPrice{
+	"haiku":  {Input: 0.80, Output: 4},
+	"sonnet": {Input: 3, Output: 15},
+	"opus":   {Input: 15, Output: 75},
+}
+
+// EstimateCost estimates the cost in USD of a call from its token counts.
+// Only Anthropic models are priced: Ollama runs locally, and the price of
+// an OpenAI-compatible endpoint depends on who hosts it.
+func EstimateCost(provider Provider, model string, inputTokens, outputTokens int) float64 {
+	if provider != ProviderAnthropic {
+		return 0
+	}
+	price, ok := anthropicPrices[model]
+	if !ok {
+		for family, p := range anthropicFamilyPrices {
+			if strings.Contains(model, family) {
+				price, ok = p, true
+				break
+			}
+		}
+	}
+	if !ok {
+		return 0
+	}
+	return (float64(inputTokens)*price.Input + float64(outputTokens)*price.Output) / 1e6
+}
+
+// UsageSummary totals the tokens and estimated cost of a set of LLM calls
+type UsageSummary struct {
+	Calls        int          `json:"calls"`
+	CachedCalls  int          `json:"cached_calls,omitempty"` // served from the response cache
+	InputTokens  int          `json:"input_tokens"`
+	OutputTokens int          `json:"output_tokens"`
+	CostUSD      float64      `json:"cost_usd"`
+	Models       []ModelUsage `json:"models,omitempty"`
+}
+
+// ModelUsage is the share of a summary's calls that went to one model
+type ModelUsage struct {
+	Provider     Provider `json:"provider"`
+	Model        string   `json:"model"`
+	Tier         Tier     `json:"tier"`
+	Calls        int      `json:"calls"`
+	InputTokens  int      `json:"input_tokens"`
+	OutputTokens int      `json:"output_tokens"`
+	CostUSD      float64  `json:"cost_usd"`
+}
+
+// Add adds other's calls to the summary
+func (s *UsageSummary) Add(other UsageSummary) {
+	s.Calls += other.Calls
+	s.CachedCalls += other.CachedCalls
+	s.InputTokens += other.InputTokens
+	s.OutputTokens += other.OutputTokens
+	s.CostUSD += other.CostUSD
+	for _, m := range other.Models {
+		s.addModel(m)
+	}
+	sort.Slice(s.Models, func(i, j int) bool {
+		if s.Models[i].CostUSD != s.Models[j].CostUSD {
+			return s.Models[i].CostUSD > s.Models[j].CostUSD
+		}
+		return s.Models[i].InputTokens+s.Models[i].OutputTokens > s.Models[j].InputTokens+s.Models[j].OutputTokens
+	})
+}
+
+func (s *UsageSummary) addModel(usage ModelUsage) {
+	for i := range s.Models {
+		m := &s.Models[i]
+		if m.Provider == usage.Provider && m.Model == usage.Model && m.Tier == usage.Tier {
+			m.Calls += usage.Calls
+			m.InputTokens += usage.InputTokens
+			m.OutputTokens += usage.OutputTokens
+			m.CostUSD += usage.CostUSD
+			return
+		}
+	}
+	s.Models = append(s.Models, usage)
+}
+
+// RunUsage collects the usage of the calls made for one generation run. The
+// router records every call whose context carries one.
+type RunUsage struct {
+	mu      sync.Mutex
+	summary UsageSummary
+}
+
+// Record adds a call answered by resp at tier
+func (u *RunUsage) Record(tier Tier, resp *Response) {
+	if u == nil || resp == nil {
+		return
+	}
+	call := UsageSummary{Calls: 1}
+	if resp.Cached {
+		call.CachedCalls = 1
+	} else {
+		cost := EstimateCost(resp.Provider, resp.Model, resp.InputTokens, resp.OutputTokens)
+		call.InputTokens = resp.InputTokens
+		call.OutputTokens = resp.OutputTokens
+		call.CostUSD = cost
+		call.Models = []ModelUsage{{
+			Provider:     resp.Provider,
+			Model:        resp.Model,
+			Tier:         tier,
+			Calls:        1,
+			InputTokens:  resp.InputTokens,
+			OutputTokens: resp.OutputTokens,
+			CostUSD:      cost,
+		}}
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.summary.Add(call)
+}
+
+// Summary returns the usage recorded so far
+func (u *RunUsage) Summary() UsageSummary {
+	if u == nil {
+		return UsageSummary{}
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	summary := u.summary
+	summary.Models = append([]ModelUsage(nil), u.summary.Models...)
+	return summary
+}
+
+type runUsageKey struct{}
+
+// WithRunUsage returns a context whose LLM calls are recorded in usage
+func WithRunUsage(ctx context.Context, usage *RunUsage) context.Context {
+	return context.WithValue(ctx, runUsageKey{}, usage)
+}
+
+// RunUsageFrom returns the usage ctx's calls are recorded in, or nil
+func RunUsageFrom(ctx context.Context) *RunUsage {
+	usage, _ := ctx.Value(runUsageKey{}).(*RunUsage)
+	return usage
+}
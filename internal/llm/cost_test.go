@@ -0,0 +1,85 @@
+package llm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEstimateCost(t *testing.T) {
+	assert.InDelta(t, 18.0, EstimateCost(ProviderAnthropic, "claude-3-5-sonnet-20241022", 1e6, 1e6), 1e-9)
+	assert.InDelta(t, 0.00125+0.0025, EstimateCost(ProviderAnthropic, "claude-3-haiku-20240307", 5000, 2000), 1e-9)
+	// Unlisted models are priced by family
+	assert.InDelta(t, 90.0, EstimateCost(ProviderAnthropic, "claude-opus-next", 1e6, 1e6), 1e-9)
+	assert.Zero(t, EstimateCost(ProviderAnthropic, "unknown", 1e6, 1e6))
+	assert.Zero(t, EstimateCost(ProviderOllama, "qwen2.5-coder:7b", 1e6, 1e6))
+}
+
+func TestRunUsage_Router(t *testing.T) {
+	ollama := newMockClient(ProviderOllama, true).withResponses(
+		&Response{Model: "qwen2.5-coder:7b", Provider: ProviderOllama, InputTokens: 800, OutputTokens: 200},
+	)
+	anthropic := newMockClient(ProviderAnthropic, true).withResponses(
+		&Response{Model: "claude-3-5-sonnet-20241022", Provider: ProviderAnthropic, InputTokens: 10000, OutputTokens: 2000},
+		&Response{Model: "claude-3-5-sonnet-20241022", Provider: ProviderAnthropic, InputTokens: 5000, OutputTokens: 1000},
+	)
+	router := &Router{
+		config: &RouterConfig{
+			TierProviders: map[Tier]Provider{Tier1: ProviderOllama, Tier3: ProviderAnthropic},
+			TierModels: map[Tier]map[Provider]string{
+				Tier1: {ProviderOllama: "qwen2.5-coder:7b"},
+				Tier3: {ProviderAnthropic: "claude-3-5-sonnet-20241022"},
+			},
+		},
+		clients:   map[Provider]Client{ProviderOllama: ollama, ProviderAnthropic: anthropic},
+		fallbacks: []Provider{ProviderOllama, ProviderAnthropic},
+	}
+
+	usage := &RunUsage{}
+	ctx := WithRunUsage(context.Background(), usage)
+	for _, tier := range []Tier{Tier1, Tier3, Tier3} {
+		_, err := router.Complete(ctx, &Request{Tier: tier})
+		require.NoError(t, err)
+	}
+	// Calls without a run aren't recorded
+	_, err := router.Complete(context.Background(), &Request{Tier: Tier1})
+	require.NoError(t, err)
+
+	summary := usage.Summary()
+	assert.Equal(t, 3, summary.Calls)
+	assert.Equal(t, 15800, summary.InputTokens)
+	assert.Equal(t, 3200, summary.OutputTokens)
+	assert.InDelta(t, 0.045+0.045, summary.CostUSD, 1e-9)
+	require.Len(t, summary.Models, 2)
+	assert.Equal(t, ProviderAnthropic, summary.Models[0].Provider)
+	assert.Equal(t, 2, summary.Models[0].Calls)
+	assert.Equal(t, Tier3, summary.Models[0].Tier)
+	assert.Equal(t, 1000, summary.Models[1].InputTokens+summary.Models[1].OutputTokens)
+}
+
+func TestUsageSummary_Add(t *testing.T) {
+	var total UsageSummary
+	total.Add(UsageSummary{Calls: 2, InputTokens: 100, CostUSD: 0.5, Models: []ModelUsage{
+		{Provider: ProviderAnthropic, Model: "m", Tier: Tier3, Calls: 2, InputTokens: 100, CostUSD: 0.5},
+	}})
+	total.Add(UsageSummary{Calls: 2, CachedCalls: 1, InputTokens: 50, CostUSD: 0.25, Models: []ModelUsage{
+		{Provider: ProviderAnthropic, Model: "m", Tier: Tier3, Calls: 1, InputTokens: 50, CostUSD: 0.25},
+	}})
+
+	assert.Equal(t, 4, total.Calls)
+	assert.Equal(t, 1, total.CachedCalls)
+	assert.Equal(t, 150, total.InputTokens)
+	require.Len(t, total.Models, 1)
+	assert.Equal(t, 3, total.Models[0].Calls)
+	assert.InDelta(t, 0.75, total.Models[0].CostUSD, 1e-9)
+
+	// Cached responses cost nothing, and a nil RunUsage ignores calls
+	usage := &RunUsage{}
+	usage.Record(Tier2, &Response{Provider: ProviderAnthropic, Model: "claude-3-5-sonnet-20241022", InputTokens: 1000, Cached: true})
+	assert.Equal(t, UsageSummary{Calls: 1, CachedCalls: 1}, usage.Summary())
+	var none *RunUsage
+	none.Record(Tier1, &Response{})
+	assert.Zero(t, none.Summary().Calls)
+}
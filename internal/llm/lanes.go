@@ -0,0 +1,77 @@
+package llm
+
+import "context"
+
+// interactiveKey marks a context as serving an interactive request
+type interactiveKey struct{}
+
+// WithInteractive marks ctx as serving an interactive request, such as a
+// developer waiting on tests for one file. Its LLM requests may use the
+// slots the router holds back from pipelines.
+func WithInteractive(ctx context.Context) context.Context {
+	return context.WithValue(ctx, interactiveKey{}, true)
+}
+
+// IsInteractive reports whether ctx serves an interactive request
+func IsInteractive(ctx context.Context) bool {
+	interactive, _ := ctx.Value(interactiveKey{}).(bool)
+	return interactive
+}
+
+// laneGate limits the requests in flight, reserving some for interactive
+// requests so they don't queue behind a pipeline's
+type laneGate struct {
+	all   chan struct{} // a slot for every request
+	batch chan struct{} // non-interactive requests also hold one of these
+}
+
+// newLaneGate returns a gate for concurrency requests, reserve of them
+// interactive only, or nil when concurrency is unlimited
+func newLaneGate(concurrency, reserve int) *laneGate {
+	if concurrency <= 0 {
+		return nil
+	}
+	// Pipelines keep at least one slot
+	if reserve >= concurrency {
+		reserve = concurrency - 1
+	}
+	if reserve < 0 {
+		reserve = 0
+	}
+	return &laneGate{
+		all:   make(chan struct{}, concurrency),
+		batch: make(chan struct{}, concurrency-reserve),
+	}
+}
+
+// acquire waits for a request slot, returning the function that frees it
+func (g *laneGate) acquire(ctx context.Context) (func(), error) {
+	if g == nil {
+		return func() {}, nil
+	}
+
+	interactive := IsInteractive(ctx)
+	if !interactive {
+		select {
+		case g.batch <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	select {
+	case g.all <- struct{}{}:
+	case <-ctx.Done():
+		if !interactive {
+			<-g.batch
+		}
+		return nil, ctx.Err()
+	}
+
+	return func() {
+		<-g.all
+		if !interactive {
+			<-g.batch
+		}
+	}, nil
+}
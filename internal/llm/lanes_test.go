@@ -0,0 +1,60 @@
+package llm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLaneGate_ReservesInteractiveSlots(t *testing.T) {
+	gate := newLaneGate(2, 1)
+	ctx := context.Background()
+
+	releaseBatch, err := gate.acquire(ctx)
+	require.NoError(t, err)
+
+	// The second slot is held back for interactive requests
+	waitCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	_, err = gate.acquire(waitCtx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	releaseInteractive, err := gate.acquire(WithInteractive(ctx))
+	require.NoError(t, err)
+
+	// Both slots are taken now, even for interactive requests
+	waitCtx, cancel = context.WithTimeout(WithInteractive(ctx), 20*time.Millisecond)
+	defer cancel()
+	_, err = gate.acquire(waitCtx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	releaseInteractive()
+	releaseBatch()
+
+	release, err := gate.acquire(ctx)
+	require.NoError(t, err)
+	release()
+}
+
+func TestNewLaneGate(t *testing.T) {
+	assert.Nil(t, newLaneGate(0, 1), "no limit without a concurrency")
+
+	// Pipelines keep a slot even if the reserve covers them all
+	gate := newLaneGate(2, 5)
+	release, err := gate.acquire(context.Background())
+	require.NoError(t, err)
+	release()
+
+	var unlimited *laneGate
+	release, err = unlimited.acquire(context.Background())
+	require.NoError(t, err)
+	release()
+}
+
+func TestIsInteractive(t *testing.T) {
+	assert.False(t, IsInteractive(context.Background()))
+	assert.True(t, IsInteractive(WithInteractive(context.Background())))
+}
@@ -7,7 +7,10 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"sort"
 	"time"
+
+	"github.com/rs/zerolog/log"
 )
 
 // OllamaClient implements the Client interface for Ollama
@@ -15,6 +18,8 @@ type OllamaClient struct {
 	baseURL    string
 	httpClient *http.Client
 	models     map[Tier]string
+	keepAlive  string // how long Ollama keeps a model loaded; empty is Ollama's default
+	loads      *loadTracker
 }
 
 // NewOllamaClient creates a new Ollama client
@@ -25,9 +30,16 @@ func NewOllamaClient(baseURL string, models map[Tier]string) *OllamaClient {
 			Timeout: 5 * time.Minute, // LLM calls can be slow
 		},
 		models: models,
+		loads:  newLoadTracker(),
 	}
 }
 
+// SetKeepAlive sets how long Ollama keeps a model loaded after each request,
+// as a duration such as "30m", or "-1" for as long as Ollama runs
+func (c *OllamaClient) SetKeepAlive(keepAlive string) {
+	c.keepAlive = keepAlive
+}
+
 func (c *OllamaClient) Name() Provider {
 	return ProviderOllama
 }
@@ -52,11 +64,12 @@ func (c *OllamaClient) Available() bool {
 
 // ollamaRequest represents the Ollama API request format
 type ollamaRequest struct {
-	Model    string          `json:"model"`
-	Messages []ollamaMessage `json:"messages"`
-	Stream   bool            `json:"stream"`
-	Format   string          `json:"format,omitempty"` // "json" for structured output
-	Options  *ollamaOptions  `json:"options,omitempty"`
+	Model     string          `json:"model"`
+	Messages  []ollamaMessage `json:"messages"`
+	Stream    bool            `json:"stream"`
+	Format    json.RawMessage `json:"format,omitempty"` // "json", or a JSON schema, for structured output
+	Options   *ollamaOptions  `json:"options,omitempty"`
+	KeepAlive string          `json:"keep_alive,omitempty"`
 }
 
 type ollamaMessage struct {
@@ -78,10 +91,14 @@ type ollamaResponse struct {
 	DoneReason      string        `json:"done_reason,omitempty"`
 	PromptEvalCount int           `json:"prompt_eval_count,omitempty"`
 	EvalCount       int           `json:"eval_count,omitempty"`
+	LoadDuration    int64         `json:"load_duration,omitempty"` // nanoseconds spent loading the model
 }
 
 func (c *OllamaClient) Complete(ctx context.Context, req *Request) (*Response, error) {
 	model, ok := c.models[req.Tier]
+	if req.Model != "" {
+		model, ok = req.Model, true
+	}
 	if !ok {
 		return nil, fmt.Errorf("no model configured for tier %d", req.Tier)
 	}
@@ -107,14 +124,17 @@ func (c *OllamaClient) Complete(ctx context.Context, req *Request) (*Response, e
 
 	// Build request
 	ollamaReq := ollamaRequest{
-		Model:    model,
-		Messages: messages,
-		Stream:   false,
+		Model:     model,
+		Messages:  messages,
+		Stream:    false,
+		KeepAlive: c.keepAlive,
 	}
 
-	// Enable JSON mode if requested
-	if req.JSONMode {
-		ollamaReq.Format = "json"
+	// Enable JSON mode if requested, constrained to the schema if given
+	if len(req.Schema) > 0 {
+		ollamaReq.Format = req.Schema
+	} else if req.JSONMode {
+		ollamaReq.Format = json.RawMessage(`"json"`)
 	}
 
 	// Add options if specified
@@ -172,6 +192,8 @@ func (c *OllamaClient) Complete(ctx context.Context, req *Request) (*Response, e
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
+	c.loads.record(model, time.Duration(ollamaResp.LoadDuration))
+
 	return &Response{
 		Content:      ollamaResp.Message.Content,
 		Model:        ollamaResp.Model,
@@ -212,3 +234,87 @@ func (c *OllamaClient) ListModels(ctx context.Context) ([]string, error) {
 
 	return models, nil
 }
+
+// Preload loads each tier's model into memory without generating anything,
+// returning the first error
+func (c *OllamaClient) Preload(ctx context.Context) error {
+	var firstErr error
+	for _, model := range c.tierModels() {
+		if err := c.preloadModel(ctx, model); err != nil {
+			log.Warn().Err(err).Str("model", model).Msg("failed to preload ollama model")
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
+
+// preloadModel sends a generate request without a prompt, which makes
+// Ollama load the model and keep it for the keep-alive period
+func (c *OllamaClient) preloadModel(ctx context.Context, model string) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"model":      model,
+		"stream":     false,
+		"keep_alive": c.keepAlive,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/api/generate", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	start := time.Now()
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("ollama returned status %d: %s", resp.StatusCode, string(bodyBytes))
+	}
+
+	var result struct {
+		LoadDuration int64 `json:"load_duration"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	load := time.Duration(result.LoadDuration)
+	c.loads.record(model, load)
+	log.Debug().Str("model", model).Dur("load", load).Dur("elapsed", time.Since(start)).Msg("preloaded ollama model")
+	return nil
+}
+
+// tierModels returns the distinct models of the configured tiers
+func (c *OllamaClient) tierModels() []string {
+	tiers := make([]Tier, 0, len(c.models))
+	for tier := range c.models {
+		tiers = append(tiers, tier)
+	}
+	sort.Slice(tiers, func(i, j int) bool { return tiers[i] < tiers[j] })
+
+	seen := make(map[string]bool)
+	var models []string
+	for _, tier := range tiers {
+		model := c.models[tier]
+		if model == "" || seen[model] {
+			continue
+		}
+		seen[model] = true
+		models = append(models, model)
+	}
+	return models
+}
+
+// ModelLoads returns model load latency statistics, by model
+func (c *OllamaClient) ModelLoads() map[string]ModelLoadStats {
+	return c.loads.snapshot()
+}
@@ -223,6 +223,41 @@ func TestOllamaClient_Complete_WithOptions(t *testing.T) {
 	}
 }
 
+func TestOllamaClient_Complete_Format(t *testing.T) {
+	var receivedReq ollamaRequest
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&receivedReq)
+		json.NewEncoder(w).Encode(ollamaResponse{Message: ollamaMessage{Content: "{}"}})
+	}))
+	defer server.Close()
+
+	client := NewOllamaClient(server.URL, map[Tier]string{Tier1: "model"})
+
+	schema := `{"type":"object","required":["tests"]}`
+	tests := []struct {
+		name string
+		req  *Request
+		want string
+	}{
+		{"plain", &Request{Tier: Tier1}, ""},
+		{"json mode", &Request{Tier: Tier1, JSONMode: true}, `"json"`},
+		{"schema", &Request{Tier: Tier1, JSONMode: true, Schema: json.RawMessage(schema)}, schema},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			receivedReq = ollamaRequest{}
+			if _, err := client.Complete(context.Background(), tt.req); err != nil {
+				t.Fatalf("Complete() error: %v", err)
+			}
+			if string(receivedReq.Format) != tt.want {
+				t.Errorf("Format = %s, want %s", receivedReq.Format, tt.want)
+			}
+		})
+	}
+}
+
 func TestOllamaClient_ListModels(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path != "/api/tags" {
@@ -274,3 +309,81 @@ func TestOllamaClient_ListModels_Error(t *testing.T) {
 		t.Error("ListModels() should return error on server error")
 	}
 }
+
+func TestOllamaClient_Preload(t *testing.T) {
+	var loaded []string
+	var keepAlive string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/generate" {
+			t.Errorf("Path = %s, want /api/generate", r.URL.Path)
+		}
+		var req map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&req)
+		loaded = append(loaded, req["model"].(string))
+		keepAlive, _ = req["keep_alive"].(string)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"model":         req["model"],
+			"done":          true,
+			"load_duration": int64(3 * time.Second),
+		})
+	}))
+	defer server.Close()
+
+	client := NewOllamaClient(server.URL, map[Tier]string{Tier1: "small", Tier2: "large", Tier3: "small"})
+	client.SetKeepAlive("1h")
+
+	if err := client.Preload(context.Background()); err != nil {
+		t.Fatalf("Preload() error: %v", err)
+	}
+	if len(loaded) != 2 || loaded[0] != "small" || loaded[1] != "large" {
+		t.Errorf("loaded = %v, want [small large]", loaded)
+	}
+	if keepAlive != "1h" {
+		t.Errorf("keep_alive = %q, want 1h", keepAlive)
+	}
+
+	stats := client.ModelLoads()
+	if stats["large"].Loads != 1 || stats["large"].LastMs != 3000 {
+		t.Errorf("ModelLoads()[large] = %+v, want one 3000ms load", stats["large"])
+	}
+}
+
+func TestOllamaClient_Complete_RecordsColdLoads(t *testing.T) {
+	load := 4 * time.Second
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ollamaRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.KeepAlive != "30m" {
+			t.Errorf("KeepAlive = %q, want 30m", req.KeepAlive)
+		}
+		json.NewEncoder(w).Encode(ollamaResponse{
+			Model:        "model",
+			Message:      ollamaMessage{Role: "assistant", Content: "ok"},
+			Done:         true,
+			LoadDuration: int64(load),
+		})
+	}))
+	defer server.Close()
+
+	client := NewOllamaClient(server.URL, map[Tier]string{Tier1: "model"})
+	client.SetKeepAlive("30m")
+	req := &Request{Tier: Tier1, Messages: []Message{{Role: "user", Content: "hi"}}}
+
+	if _, err := client.Complete(context.Background(), req); err != nil {
+		t.Fatalf("Complete() error: %v", err)
+	}
+	load = 2 * time.Second
+	if _, err := client.Complete(context.Background(), req); err != nil {
+		t.Fatalf("Complete() error: %v", err)
+	}
+	// A loaded model reports a few milliseconds, which isn't a cold load
+	load = 5 * time.Millisecond
+	if _, err := client.Complete(context.Background(), req); err != nil {
+		t.Fatalf("Complete() error: %v", err)
+	}
+
+	got := client.ModelLoads()["model"]
+	if got.Loads != 2 || got.MaxMs != 4000 || got.LastMs != 2000 || got.AvgMs != 3000 {
+		t.Errorf("ModelLoads()[model] = %+v, want 2 loads, max 4000, last 2000, avg 3000", got)
+	}
+}
@@ -0,0 +1,196 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// OpenAIEndpoint is an OpenAI-compatible chat completions API serving a
+// tier: OpenAI itself, vLLM, LM Studio, OpenRouter or Azure OpenAI
+type OpenAIEndpoint struct {
+	BaseURL string // e.g. https://api.openai.com/v1 or http://localhost:8000/v1
+	Model   string
+	APIKey  string // empty for local servers that don't need one
+
+	// APIVersion is set for Azure OpenAI, which takes it as a query
+	// parameter and the key in an api-key header
+	APIVersion string
+}
+
+// OpenAIClient implements the Client interface for OpenAI-compatible APIs
+type OpenAIClient struct {
+	httpClient *http.Client
+	endpoints  map[Tier]OpenAIEndpoint
+	fallback   OpenAIEndpoint // for requests naming a model for a tier without an endpoint
+}
+
+// NewOpenAIClient creates a client for OpenAI-compatible endpoints, one per tier
+func NewOpenAIClient(endpoints map[Tier]OpenAIEndpoint) *OpenAIClient {
+	return &OpenAIClient{
+		httpClient: &http.Client{
+			Timeout: 5 * time.Minute,
+		},
+		endpoints: endpoints,
+	}
+}
+
+// SetDefaultEndpoint sets the URL and key used for requests that name a
+// model for a tier without an endpoint of its own
+func (c *OpenAIClient) SetDefaultEndpoint(ep OpenAIEndpoint) {
+	c.fallback = ep
+}
+
+func (c *OpenAIClient) Name() Provider {
+	return ProviderOpenAI
+}
+
+func (c *OpenAIClient) Available() bool {
+	for _, ep := range c.endpoints {
+		if ep.BaseURL != "" && ep.Model != "" {
+			return true
+		}
+	}
+	return c.fallback.BaseURL != ""
+}
+
+// openaiRequest represents the chat completions request format
+type openaiRequest struct {
+	Model          string        `json:"model"`
+	Messages       []Message     `json:"messages"`
+	MaxTokens      int           `json:"max_tokens,omitempty"`
+	Temperature    float64       `json:"temperature,omitempty"`
+	Stop           []string      `json:"stop,omitempty"`
+	ResponseFormat *openaiFormat `json:"response_format,omitempty"`
+}
+
+type openaiFormat struct {
+	Type       string            `json:"type"`
+	JSONSchema *openaiJSONSchema `json:"json_schema,omitempty"`
+}
+
+type openaiJSONSchema struct {
+	Name   string          `json:"name"`
+	Schema json.RawMessage `json:"schema"`
+}
+
+// openaiResponse represents the chat completions response format
+type openaiResponse struct {
+	Model   string `json:"model"`
+	Choices []struct {
+		Message struct {
+			Content string `json:"content"`
+		} `json:"message"`
+		FinishReason string `json:"finish_reason"`
+	} `json:"choices"`
+	Usage struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage"`
+}
+
+func (c *OpenAIClient) Complete(ctx context.Context, req *Request) (*Response, error) {
+	ep, ok := c.endpoints[req.Tier]
+	if !ok {
+		ep = c.fallback
+	}
+	if req.Model != "" {
+		ep.Model = req.Model
+	}
+	if ep.Model == "" || ep.BaseURL == "" {
+		return nil, fmt.Errorf("no model configured for tier %d", req.Tier)
+	}
+
+	messages := make([]Message, 0, len(req.Messages)+1)
+	if req.System != "" {
+		messages = append(messages, Message{Role: "system", Content: req.System})
+	}
+	messages = append(messages, req.Messages...)
+
+	openaiReq := openaiRequest{
+		Model:       ep.Model,
+		Messages:    messages,
+		MaxTokens:   req.MaxTokens,
+		Temperature: req.Temperature,
+		Stop:        req.Stop,
+	}
+	if len(req.Schema) > 0 {
+		openaiReq.ResponseFormat = &openaiFormat{
+			Type:       "json_schema",
+			JSONSchema: &openaiJSONSchema{Name: outputTool, Schema: req.Schema},
+		}
+	} else if req.JSONMode {
+		openaiReq.ResponseFormat = &openaiFormat{Type: "json_object"}
+	}
+
+	body, err := json.Marshal(openaiReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", ep.completionsURL(), bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if ep.APIKey != "" {
+		if ep.APIVersion != "" {
+			httpReq.Header.Set("api-key", ep.APIKey)
+		} else {
+			httpReq.Header.Set("Authorization", "Bearer "+ep.APIKey)
+		}
+	}
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("request cancelled: %w", ctx.Err())
+		}
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("openai API error (status %d): %s", resp.StatusCode, sanitizeErrorBody(string(bodyBytes)))
+	}
+
+	var openaiResp openaiResponse
+	if err := json.NewDecoder(resp.Body).Decode(&openaiResp); err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("decoding interrupted: %w", ctx.Err())
+		}
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if len(openaiResp.Choices) == 0 {
+		return nil, fmt.Errorf("openai API returned no choices")
+	}
+
+	model := openaiResp.Model
+	if model == "" {
+		model = ep.Model
+	}
+	return &Response{
+		Content:      openaiResp.Choices[0].Message.Content,
+		Model:        model,
+		Provider:     ProviderOpenAI,
+		InputTokens:  openaiResp.Usage.PromptTokens,
+		OutputTokens: openaiResp.Usage.CompletionTokens,
+		FinishReason: openaiResp.Choices[0].FinishReason,
+	}, nil
+}
+
+// completionsURL is the endpoint's chat completions URL
+func (ep OpenAIEndpoint) completionsURL() string {
+	u := strings.TrimRight(ep.BaseURL, "/") + "/chat/completions"
+	if ep.APIVersion != "" {
+		u += "?api-version=" + url.QueryEscape(ep.APIVersion)
+	}
+	return u
+}
@@ -0,0 +1,160 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestOpenAIClient_Complete(t *testing.T) {
+	var (
+		gotPath string
+		gotAuth string
+		gotReq  openaiRequest
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotAuth = r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&gotReq)
+		w.Write([]byte(`{"model": "served-model", "choices": [{"message": {"role": "assistant", "content": "hello"}, "finish_reason": "stop"}], "usage": {"prompt_tokens": 12, "completion_tokens": 3}}`))
+	}))
+	defer server.Close()
+
+	client := NewOpenAIClient(map[Tier]OpenAIEndpoint{
+		Tier1: {BaseURL: server.URL + "/v1/", Model: "qwen2.5-coder", APIKey: "sk-test"},
+	})
+	if !client.Available() {
+		t.Error("Available() = false with a configured endpoint")
+	}
+
+	resp, err := client.Complete(context.Background(), &Request{
+		Tier:     Tier1,
+		System:   "be brief",
+		Messages: []Message{{Role: "user", Content: "hi"}},
+		JSONMode: true,
+	})
+	if err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+
+	if gotPath != "/v1/chat/completions" {
+		t.Errorf("path = %s, want /v1/chat/completions", gotPath)
+	}
+	if gotAuth != "Bearer sk-test" {
+		t.Errorf("Authorization = %q, want bearer key", gotAuth)
+	}
+	if gotReq.Model != "qwen2.5-coder" || len(gotReq.Messages) != 2 || gotReq.Messages[0].Role != "system" {
+		t.Errorf("request = %+v, want the tier model and the system prompt first", gotReq)
+	}
+	if gotReq.ResponseFormat == nil || gotReq.ResponseFormat.Type != "json_object" {
+		t.Errorf("response_format = %+v, want json_object", gotReq.ResponseFormat)
+	}
+
+	if resp.Content != "hello" || resp.Model != "served-model" || resp.Provider != ProviderOpenAI {
+		t.Errorf("resp = %+v", resp)
+	}
+	if resp.InputTokens != 12 || resp.OutputTokens != 3 || resp.FinishReason != "stop" {
+		t.Errorf("usage = %d/%d %s, want 12/3 stop", resp.InputTokens, resp.OutputTokens, resp.FinishReason)
+	}
+}
+
+func TestOpenAIClient_Complete_Azure(t *testing.T) {
+	var gotURL, gotKey, gotAuth string
+	var gotReq openaiRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotURL = r.URL.String()
+		gotKey = r.Header.Get("api-key")
+		gotAuth = r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&gotReq)
+		w.Write([]byte(`{"choices": [{"message": {"content": "{}"}}]}`))
+	}))
+	defer server.Close()
+
+	client := NewOpenAIClient(map[Tier]OpenAIEndpoint{
+		Tier3: {BaseURL: server.URL + "/openai/deployments/gpt4o", Model: "gpt-4o", APIKey: "azure-key", APIVersion: "2024-06-01"},
+	})
+	resp, err := client.Complete(context.Background(), &Request{
+		Tier:     Tier3,
+		Messages: []Message{{Role: "user", Content: "hi"}},
+		Schema:   json.RawMessage(`{"type": "object"}`),
+	})
+	if err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+
+	if gotURL != "/openai/deployments/gpt4o/chat/completions?api-version=2024-06-01" {
+		t.Errorf("URL = %s", gotURL)
+	}
+	if gotKey != "azure-key" || gotAuth != "" {
+		t.Errorf("api-key = %q, Authorization = %q, want the key in api-key only", gotKey, gotAuth)
+	}
+	if f := gotReq.ResponseFormat; f == nil || f.Type != "json_schema" || f.JSONSchema == nil || string(f.JSONSchema.Schema) != `{"type":"object"}` {
+		t.Errorf("response_format = %+v, want the request schema", f)
+	}
+	if resp.Model != "gpt-4o" {
+		t.Errorf("Model = %s, want the configured one when the response has none", resp.Model)
+	}
+}
+
+func TestOpenAIClient_Complete_Errors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error": "bad key sk-abcdefghijklmnopqrstuvwxyz"}`))
+	}))
+	defer server.Close()
+
+	client := NewOpenAIClient(map[Tier]OpenAIEndpoint{
+		Tier1: {BaseURL: server.URL, Model: "m"},
+	})
+
+	if _, err := client.Complete(context.Background(), &Request{Tier: Tier2}); err == nil {
+		t.Error("expected error for a tier without an endpoint")
+	}
+
+	_, err := client.Complete(context.Background(), &Request{Tier: Tier1})
+	if err == nil {
+		t.Fatal("expected error for a 401")
+	}
+	if isRetryableError(err) {
+		t.Errorf("401 error %q should not be retried", err)
+	}
+	if strings.Contains(err.Error(), "sk-abcdefghijklmnopqrstuvwxyz") {
+		t.Errorf("error %q should have the key redacted", err)
+	}
+}
+
+func TestOpenAIClient_Available_NoEndpoints(t *testing.T) {
+	if NewOpenAIClient(nil).Available() {
+		t.Error("Available() = true without endpoints")
+	}
+}
+
+func TestOpenAIClient_Complete_ModelOverride(t *testing.T) {
+	var gotReq openaiRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&gotReq)
+		w.Write([]byte(`{"choices": [{"message": {"content": "ok"}}]}`))
+	}))
+	defer server.Close()
+
+	// A tier without an endpoint uses the default one for a named model
+	client := NewOpenAIClient(nil)
+	if client.Available() {
+		t.Error("Available() = true without endpoints")
+	}
+	client.SetDefaultEndpoint(OpenAIEndpoint{BaseURL: server.URL})
+
+	if _, err := client.Complete(context.Background(), &Request{Tier: Tier2}); err == nil {
+		t.Error("expected error without a model")
+	}
+	resp, err := client.Complete(context.Background(), &Request{Tier: Tier2, Model: "gpt-4o-mini"})
+	if err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+	if gotReq.Model != "gpt-4o-mini" || resp.Model != "gpt-4o-mini" {
+		t.Errorf("model = %s/%s, want the request's", gotReq.Model, resp.Model)
+	}
+}
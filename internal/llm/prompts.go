@@ -1,6 +1,8 @@
 package llm
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"strings"
 
@@ -31,7 +33,12 @@ RULES:
 - Variable names in "given" should be lowercase (a, b, input, expected)
 - "when.call" uses $varname syntax to reference variables
 - "then.actual" is usually "result" for the function return value
-- "then.type" must be one of: equals, not_equals, contains, greater_than, less_than, throws, truthy, falsy, nil, not_nil
+- "then.type" must be one of: equals, not_equals, contains, greater_than, less_than, throws, truthy, falsy, nil, not_nil, snapshot, approx, matches, length, contains_all, subset, sorted, header_present, header_equals, header_matches, cookie_present, cookie_equals, cookie_matches
+- For error cases use "throws" with "error_type" (the exception class or Go error, e.g. ValueError, TypeError, ErrNotFound) and "error_message" (text the message contains) when known, instead of "expected"
+- Use "approx" with "tolerance" for floating-point results instead of "equals", and "matches" with a regular expression in "expected" for values that vary between runs, like timestamps and generated IDs
+- For list results use "length", "contains_all" (items the list must include), "subset" (items the list may only contain) and "sorted" ("expected" is the key items are ordered by, "-key" for descending, or omitted) rather than "equals" on the whole list when its order or extra items don't matter
+- For API responses use "header_present", "header_equals" and "header_matches" with "actual" naming the header (e.g. "Content-Type"), and "cookie_present", "cookie_equals" and "cookie_matches" with "actual" naming a cookie the response sets
+- Use "snapshot" (no "expected") only for large structured results, like rendered output or API payloads, that are impractical to spell out
 - Use "tags" to categorize: happy_path, edge_case, boundary, error_handling
 - CRITICAL: ALL variables used in "when.args" MUST be defined in "given". For handler functions with req/res parameters (Express.js, FastAPI, etc.), define mock objects like: {"name": "req", "value": {"body": {...}}, "type": "object"}
 
@@ -61,6 +68,18 @@ const SystemPromptCritic = `You are a test quality expert. Analyze the given tes
 
 Respond with JSON: {"quality": "high"|"medium"|"low", "issues": [...], "suggestions": [...]}`
 
+// SystemPromptTestRepair is the system prompt for repairing a generated
+// test file that failed to compile or pass
+const SystemPromptTestRepair = `You are an expert software engineer repairing a generated test file that fails to compile or pass.
+
+RULES:
+- Fix compile errors, imports, types, setup and call signatures
+- When an assertion fails, check it against the code under test: correct the expected value only if the test misread the code
+- Do NOT remove test cases or assertions to make the file pass
+- Keep the file's test framework, package and naming
+
+Output ONLY the complete corrected test file in a single code block.`
+
 // IRSpecGenerationPrompt creates a prompt for generating tests in IRSpec JSON format
 // This is the new structured output approach using Ollama's JSON mode
 func IRSpecGenerationPrompt(functionCode, functionName, fileName, language string) string {
@@ -123,6 +142,57 @@ Output ONLY the YAML, no explanation.`,
 		context)
 }
 
+// MissingTestsPrompt asks again for the test cases dropped from a response
+// that was partly malformed, without repeating the ones kept
+func MissingTestsPrompt(missing int, kept []string, format string) string {
+	keptList := "none"
+	if len(kept) > 0 {
+		keptList = strings.Join(kept, ", ")
+	}
+	return fmt.Sprintf(`%d of the test cases in your response were malformed and could not be used.
+
+Generate %d new test cases for the same function, in the same %s format. Do not repeat these test cases, which were kept: %s
+
+Output ONLY the %s, no explanation.`, missing, missing, format, keptList, format)
+}
+
+// TestRepairPrompt asks for a test file repaired for the compiler or
+// assertion errors it failed with
+func TestRepairPrompt(testCode, failure, functionCode, language string) string {
+	return fmt.Sprintf(`This %s test file fails:
+
+`+"```"+`
+%s
+`+"```"+`
+
+Errors:
+`+"```"+`
+%s
+`+"```"+`
+
+Code under test:
+`+"```"+`
+%s
+`+"```"+`
+
+Output the complete corrected test file.`, language, testCode, failure, functionCode)
+}
+
+// PromptHash identifies the prompt a request sends: its system prompt and
+// messages, but not the tier or sampling settings. It's recorded with
+// generated tests so output can be traced back to the prompt that made it.
+func PromptHash(req *Request) string {
+	h := sha256.New()
+	h.Write([]byte(req.System))
+	for _, m := range req.Messages {
+		h.Write([]byte{0})
+		h.Write([]byte(m.Role))
+		h.Write([]byte{0})
+		h.Write([]byte(m.Content))
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
 // ParseDSLOutput extracts YAML content from LLM response
 func ParseDSLOutput(response string) string {
 	// Remove markdown code blocks if present
@@ -141,3 +211,21 @@ func ParseDSLOutput(response string) string {
 
 	return strings.TrimSpace(response)
 }
+
+// ParseCodeOutput extracts the code from an LLM response: the first fenced
+// code block, or the whole response when it has none
+func ParseCodeOutput(response string) string {
+	response = strings.TrimSpace(response)
+	start := strings.Index(response, "```")
+	if start == -1 {
+		return response
+	}
+	body := response[start+3:]
+	if nl := strings.Index(body, "\n"); nl != -1 {
+		body = body[nl+1:] // drop the language tag
+	}
+	if end := strings.Index(body, "```"); end != -1 {
+		body = body[:end]
+	}
+	return strings.TrimSpace(body)
+}
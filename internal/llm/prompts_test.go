@@ -171,3 +171,72 @@ func TestSystemPromptConstants(t *testing.T) {
 		t.Error("SystemPromptCritic should mention JSON output format")
 	}
 }
+
+func TestPromptHash(t *testing.T) {
+	req := &Request{
+		Tier:     Tier1,
+		System:   "system",
+		Messages: []Message{{Role: "user", Content: "write tests"}},
+	}
+
+	hash := PromptHash(req)
+	if len(hash) != 16 {
+		t.Errorf("len(PromptHash) = %d, want 16", len(hash))
+	}
+
+	// Sampling settings don't change the prompt
+	same := *req
+	same.Tier = Tier3
+	same.Temperature = 0.9
+	if PromptHash(&same) != hash {
+		t.Error("PromptHash should ignore tier and temperature")
+	}
+
+	changed := *req
+	changed.Messages = []Message{{Role: "user", Content: "write more tests"}}
+	if PromptHash(&changed) == hash {
+		t.Error("PromptHash should change with the messages")
+	}
+}
+
+func TestMissingTestsPrompt(t *testing.T) {
+	prompt := MissingTestsPrompt(2, []string{"add_positive", "add_zero"}, "IRSpec JSON")
+
+	for _, want := range []string{"Generate 2 new test cases", "IRSpec JSON", "add_positive, add_zero"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt missing %q:\n%s", want, prompt)
+		}
+	}
+	if !strings.Contains(MissingTestsPrompt(1, nil, "YAML"), "kept: none") {
+		t.Error("prompt should say no test cases were kept")
+	}
+}
+
+func TestTestRepairPrompt(t *testing.T) {
+	prompt := TestRepairPrompt("func TestAdd(t *testing.T) {}", "undefined: Sum", "func Add(a, b int) int", "go")
+
+	for _, want := range []string{"This go test file fails", "func TestAdd", "undefined: Sum", "func Add(a, b int) int"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt missing %q:\n%s", want, prompt)
+		}
+	}
+}
+
+func TestParseCodeOutput(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		want     string
+	}{
+		{"fenced", "Here you go:\n```go\npackage x\n\nfunc A() {}\n```\nDone.", "package x\n\nfunc A() {}"},
+		{"unfenced", "  package x\n", "package x"},
+		{"unterminated", "```python\ndef test_a():\n    pass\n", "def test_a():\n    pass"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseCodeOutput(tt.response); got != tt.want {
+				t.Errorf("ParseCodeOutput() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -24,7 +24,11 @@ const (
 type Router struct {
 	config    *RouterConfig
 	clients   map[Provider]Client
-	fallbacks []Provider // Fallback order
+	fallbacks []Provider        // Fallback order
+	allowed   map[Provider]bool // nil allows every provider
+	gate      *laneGate         // nil when requests aren't limited
+	selector  *TierSelector     // picks tiers by request size
+	breakers  map[Provider]*breaker
 }
 
 // NewRouter creates a new LLM router from config
@@ -32,6 +36,15 @@ func NewRouter(cfg *config.Config) (*Router, error) {
 	r := &Router{
 		clients:   make(map[Provider]Client),
 		fallbacks: []Provider{ProviderOllama, ProviderAnthropic, ProviderOpenAI},
+		gate:      newLaneGate(cfg.Lanes.LLMConcurrency, cfg.Lanes.LLMInteractiveReserve),
+		selector: &TierSelector{
+			Context: map[Tier]int{
+				Tier1: cfg.LLM.Tier1Context,
+				Tier2: cfg.LLM.Tier2Context,
+				Tier3: cfg.LLM.Tier3Context,
+			},
+			SmallTokens: cfg.LLM.SmallTargetTokens,
+		},
 	}
 
 	// Build router config from application config
@@ -39,6 +52,34 @@ func NewRouter(cfg *config.Config) (*Router, error) {
 		DefaultProvider: Provider(cfg.LLM.DefaultProvider),
 		Providers:       make(map[Provider]ProviderConfig),
 		TierModels:      make(map[Tier]map[Provider]string),
+		TierProviders:   make(map[Tier]Provider),
+		TierChains:      make(map[Tier][]Route),
+	}
+	for tier, provider := range map[Tier]string{
+		Tier1: cfg.LLM.Tier1Provider,
+		Tier2: cfg.LLM.Tier2Provider,
+		Tier3: cfg.LLM.Tier3Provider,
+	} {
+		if provider != "" {
+			r.config.TierProviders[tier] = Provider(provider)
+		}
+	}
+
+	// Failover chains, and whether one names an OpenAI-compatible model
+	chainsOpenAI := false
+	for tier, chain := range map[Tier]string{
+		Tier1: cfg.LLM.Tier1Chain,
+		Tier2: cfg.LLM.Tier2Chain,
+		Tier3: cfg.LLM.Tier3Chain,
+	} {
+		steps, err := config.ParseChain(chain)
+		if err != nil {
+			return nil, fmt.Errorf("tier %d chain: %w", tier, err)
+		}
+		for _, step := range steps {
+			r.config.TierChains[tier] = append(r.config.TierChains[tier], Route{Provider: Provider(step.Provider), Model: step.Model})
+			chainsOpenAI = chainsOpenAI || (step.Provider == string(ProviderOpenAI) && step.Model != "")
+		}
 	}
 
 	// Configure Ollama (always enabled if URL is set)
@@ -53,7 +94,9 @@ func NewRouter(cfg *config.Config) (*Router, error) {
 			Tier2: cfg.LLM.OllamaTier2,
 		}
 
-		r.clients[ProviderOllama] = NewOllamaClient(cfg.LLM.OllamaURL, ollamaModels)
+		ollama := NewOllamaClient(cfg.LLM.OllamaURL, ollamaModels)
+		ollama.SetKeepAlive(cfg.LLM.OllamaKeepAlive)
+		r.clients[ProviderOllama] = ollama
 
 		// Add to tier models
 		r.config.TierModels[Tier1] = map[Provider]string{
@@ -84,68 +127,242 @@ func NewRouter(cfg *config.Config) (*Router, error) {
 		r.config.TierModels[Tier3][ProviderAnthropic] = cfg.LLM.AnthropicTier3
 	}
 
+	// Configure OpenAI-compatible endpoints for the tiers given a model
+	openaiEndpoints := make(map[Tier]OpenAIEndpoint)
+	for tier, ep := range map[Tier]config.OpenAIEndpoint{
+		Tier1: cfg.LLM.OpenAITier1,
+		Tier2: cfg.LLM.OpenAITier2,
+		Tier3: cfg.LLM.OpenAITier3,
+	} {
+		if ep.Model == "" || ep.URL == "" {
+			continue
+		}
+		openaiEndpoints[tier] = OpenAIEndpoint{
+			BaseURL:    ep.URL,
+			Model:      ep.Model,
+			APIKey:     ep.APIKey,
+			APIVersion: ep.APIVersion,
+		}
+		if r.config.TierModels[tier] == nil {
+			r.config.TierModels[tier] = make(map[Provider]string)
+		}
+		r.config.TierModels[tier][ProviderOpenAI] = ep.Model
+	}
+	if len(openaiEndpoints) > 0 || (chainsOpenAI && cfg.LLM.OpenAIURL != "") {
+		r.config.Providers[ProviderOpenAI] = ProviderConfig{
+			Enabled: true,
+			BaseURL: cfg.LLM.OpenAIURL,
+			APIKey:  cfg.LLM.OpenAIKey,
+		}
+		openai := NewOpenAIClient(openaiEndpoints)
+		openai.SetDefaultEndpoint(OpenAIEndpoint{
+			BaseURL: cfg.LLM.OpenAIURL,
+			APIKey:  cfg.LLM.OpenAIKey,
+		})
+		r.clients[ProviderOpenAI] = openai
+	}
+
 	// Validate at least one provider is configured
 	if len(r.clients) == 0 {
 		return nil, fmt.Errorf("no LLM providers configured")
 	}
 
+	r.breakers = make(map[Provider]*breaker, len(r.clients))
+	for provider := range r.clients {
+		r.breakers[provider] = newBreaker(provider, cfg.LLM.BreakerThreshold, cfg.LLM.BreakerCooldown, cfg.LLM.RetryBudget)
+	}
+
 	return r, nil
 }
 
-// Complete sends a completion request, routing to appropriate provider with retry logic
-func (r *Router) Complete(ctx context.Context, req *Request) (*Response, error) {
-	// Get providers that support this tier
-	providers := r.getProvidersForTier(req.Tier)
+// WithProviders returns a router that only sends requests to the given
+// providers, for repositories whose policy keeps code away from the others.
+// With no providers it returns the router unchanged.
+func (r *Router) WithProviders(providers []Provider) *Router {
 	if len(providers) == 0 {
+		return r
+	}
+	restricted := *r
+	restricted.allowed = make(map[Provider]bool, len(providers))
+	for _, p := range providers {
+		restricted.allowed[p] = true
+	}
+	return &restricted
+}
+
+// Complete sends a completion request, routing to appropriate provider with retry logic.
+// When the circuits of the tier's providers are open, it falls back to the
+// other tiers.
+func (r *Router) Complete(ctx context.Context, req *Request) (*Response, error) {
+	// Get the providers, in order, that support this tier
+	routes := r.getRoutesForTier(req.Tier)
+	if len(routes) == 0 {
 		return nil, fmt.Errorf("no providers available for tier %d", req.Tier)
 	}
 
-	// Try each provider in order
-	var lastErr error
-	for _, provider := range providers {
+	// Wait for a request slot; interactive requests have some to themselves
+	release, err := r.gate.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	var attempts []Attempt
+	resp, err := r.completeTier(ctx, req, routes, &attempts)
+	if err == nil || !errors.Is(err, ErrCircuitOpen) {
+		return withAttempts(resp, attempts), err
+	}
+
+	for _, tier := range fallbackTiers(req.Tier) {
+		log.Info().
+			Int("tier", int(req.Tier)).
+			Int("fallback_tier", int(tier)).
+			Msg("circuits open, falling back to another tier")
+
+		fallback := *req
+		fallback.Tier = tier
+		resp, tierErr := r.completeTier(ctx, &fallback, r.getRoutesForTier(tier), &attempts)
+		if tierErr == nil {
+			return withAttempts(resp, attempts), nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+	}
+	return nil, err
+}
+
+// withAttempts records on resp the attempts it took, if it took more than one
+func withAttempts(resp *Response, attempts []Attempt) *Response {
+	if resp != nil && len(attempts) > 1 {
+		resp.Attempts = attempts
+	}
+	return resp
+}
+
+// completeTier tries each of routes in turn, skipping those whose circuits
+// are open, and appends each to attempts. Its error wraps ErrCircuitOpen if
+// any were skipped.
+func (r *Router) completeTier(ctx context.Context, req *Request, routes []Route, attempts *[]Attempt) (*Response, error) {
+	var (
+		lastErr error
+		skipped bool
+	)
+	for _, route := range routes {
+		provider := route.Provider
 		client, ok := r.clients[provider]
 		if !ok {
 			continue
 		}
 
+		attempt := Attempt{Provider: provider, Model: route.Model, Tier: req.Tier}
+		if attempt.Model == "" {
+			attempt.Model = r.config.TierModels[req.Tier][provider]
+		}
+		failed := func(err error) {
+			attempt.Error = err.Error()
+			*attempts = append(*attempts, attempt)
+		}
+
+		b := r.breakers[provider]
+		if !b.allow() {
+			log.Debug().Str("provider", string(provider)).Msg("circuit open, skipping provider")
+			skipped = true
+			failed(ErrCircuitOpen)
+			continue
+		}
+
 		if !client.Available() {
 			log.Debug().Str("provider", string(provider)).Msg("provider not available, trying next")
+			b.record(ctx, errProviderUnavailable)
+			failed(errProviderUnavailable)
 			continue
 		}
 
 		log.Debug().
 			Str("provider", string(provider)).
+			Str("model", route.Model).
 			Int("tier", int(req.Tier)).
 			Msg("routing request to provider")
 
+		routed := req
+		if route.Model != "" {
+			withModel := *req
+			withModel.Model = route.Model
+			routed = &withModel
+		}
+
 		// Try with retries
-		resp, err := r.completeWithRetry(ctx, client, provider, req)
+		resp, err := r.completeWithRetry(ctx, client, provider, routed)
 		if err != nil {
 			log.Warn().
 				Err(err).
 				Str("provider", string(provider)).
+				Str("model", route.Model).
 				Msg("provider failed after retries, trying next")
 			lastErr = err
+			failed(err)
+			if b.isOpen() {
+				skipped = true
+			}
 			continue
 		}
 
+		if resp.Model != "" {
+			attempt.Model = resp.Model
+		}
+		*attempts = append(*attempts, attempt)
+		RunUsageFrom(ctx).Record(req.Tier, resp)
 		return resp, nil
 	}
 
-	if lastErr != nil {
+	switch {
+	case skipped && lastErr != nil:
+		return nil, fmt.Errorf("all providers failed, last error: %w (%w)", lastErr, ErrCircuitOpen)
+	case skipped:
+		return nil, fmt.Errorf("no available providers for tier %d: %w", req.Tier, ErrCircuitOpen)
+	case lastErr != nil:
 		return nil, fmt.Errorf("all providers failed, last error: %w", lastErr)
 	}
-
 	return nil, fmt.Errorf("no available providers for tier %d", req.Tier)
 }
 
+// fallbackTiers is the order other tiers are tried in when a tier's
+// circuits are open: the tiers above it, then those below
+func fallbackTiers(tier Tier) []Tier {
+	var tiers []Tier
+	for t := tier + 1; t <= Tier3; t++ {
+		tiers = append(tiers, t)
+	}
+	for t := tier - 1; t >= Tier1; t-- {
+		tiers = append(tiers, t)
+	}
+	return tiers
+}
+
+// CircuitStates returns the state of each provider's circuit breaker:
+// closed, open or half-open
+func (r *Router) CircuitStates() map[Provider]string {
+	states := make(map[Provider]string, len(r.clients))
+	for provider := range r.clients {
+		states[provider] = r.breakers[provider].String()
+	}
+	return states
+}
+
 // completeWithRetry attempts completion with exponential backoff retry
 func (r *Router) completeWithRetry(ctx context.Context, client Client, provider Provider, req *Request) (*Response, error) {
 	var lastErr error
 	backoff := initialBackoff
+	b := r.breakers[provider]
 
 	for attempt := 0; attempt <= defaultMaxRetries; attempt++ {
 		if attempt > 0 {
+			// Fail fast once the circuit opens or the retry budget is spent
+			if !b.allowRetry() {
+				return nil, fmt.Errorf("not retrying, circuit %s or retry budget spent: %w", b, lastErr)
+			}
+
 			log.Debug().
 				Str("provider", string(provider)).
 				Int("attempt", attempt+1).
@@ -167,6 +384,7 @@ func (r *Router) completeWithRetry(ctx context.Context, client Client, provider
 		}
 
 		resp, err := client.Complete(ctx, req)
+		b.record(ctx, err)
 		if err == nil {
 			return resp, nil
 		}
@@ -233,8 +451,10 @@ func isRetryableError(err error) bool {
 		return true
 	}
 
-	// 4xx client errors are NOT retryable (except 429)
-	if strings.Contains(errStr, "400") ||
+	// 4xx client errors are NOT retryable (except 429), nor is a tier a
+	// provider has no model for
+	if strings.Contains(errStr, "no model configured") ||
+		strings.Contains(errStr, "400") ||
 		strings.Contains(errStr, "401") ||
 		strings.Contains(errStr, "403") ||
 		strings.Contains(errStr, "404") {
@@ -245,20 +465,51 @@ func isRetryableError(err error) bool {
 	return true
 }
 
+// getRoutesForTier returns the routes to try for a tier, in order: its
+// failover chain if it has one, else its providers
+func (r *Router) getRoutesForTier(tier Tier) []Route {
+	if chain := r.config.TierChains[tier]; len(chain) > 0 {
+		routes := make([]Route, 0, len(chain))
+		for _, route := range chain {
+			if r.clients[route.Provider] == nil {
+				continue
+			}
+			if r.allowed != nil && !r.allowed[route.Provider] {
+				continue
+			}
+			routes = append(routes, route)
+		}
+		return routes
+	}
+
+	providers := r.getProvidersForTier(tier)
+	routes := make([]Route, 0, len(providers))
+	for _, provider := range providers {
+		routes = append(routes, Route{Provider: provider})
+	}
+	return routes
+}
+
 // getProvidersForTier returns providers that can handle the given tier, in priority order
 func (r *Router) getProvidersForTier(tier Tier) []Provider {
 	providers := make([]Provider, 0)
 
-	// Check configured tier models first
+	// The provider the tier is mapped to goes first
+	preferred, hasPreferred := r.config.TierProviders[tier]
+	if hasPreferred && r.clients[preferred] != nil {
+		providers = append(providers, preferred)
+	}
+
+	// Check configured tier models next
 	if tierModels, ok := r.config.TierModels[tier]; ok {
 		// Add default provider first if it supports this tier
-		if _, hasDefault := tierModels[r.config.DefaultProvider]; hasDefault {
+		if _, hasDefault := tierModels[r.config.DefaultProvider]; hasDefault && r.config.DefaultProvider != preferred {
 			providers = append(providers, r.config.DefaultProvider)
 		}
 
 		// Add other providers for this tier
 		for provider := range tierModels {
-			if provider != r.config.DefaultProvider {
+			if provider != r.config.DefaultProvider && provider != preferred {
 				providers = append(providers, provider)
 			}
 		}
@@ -278,6 +529,16 @@ func (r *Router) getProvidersForTier(tier Tier) []Provider {
 		}
 	}
 
+	if r.allowed != nil {
+		permitted := providers[:0]
+		for _, p := range providers {
+			if r.allowed[p] {
+				permitted = append(permitted, p)
+			}
+		}
+		providers = permitted
+	}
+
 	return providers
 }
 
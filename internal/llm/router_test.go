@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/QTest-hq/qtest/internal/config"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -93,6 +94,7 @@ func TestIsRetryableError(t *testing.T) {
 		{"401_unauthorized", errors.New("401 unauthorized"), false},
 		{"403_forbidden", errors.New("403 forbidden"), false},
 		{"404_not_found", errors.New("404 not found"), false},
+		{"no_model_for_tier", errors.New("no model configured for tier 3"), false},
 		{"unknown_error", errors.New("some unknown error"), true},
 	}
 
@@ -158,6 +160,43 @@ func TestRouter_Complete_ProviderUnavailable_Fallback(t *testing.T) {
 	assert.Equal(t, 1, availableClient.callCount)
 }
 
+func TestRouter_WithProviders(t *testing.T) {
+	ollama := newMockClient(ProviderOllama, true)
+	anthropic := newMockClient(ProviderAnthropic, true)
+
+	router := &Router{
+		config: &RouterConfig{
+			DefaultProvider: ProviderAnthropic,
+			TierModels: map[Tier]map[Provider]string{
+				Tier1: {
+					ProviderOllama:    "model1",
+					ProviderAnthropic: "model2",
+				},
+			},
+		},
+		clients: map[Provider]Client{
+			ProviderOllama:    ollama,
+			ProviderAnthropic: anthropic,
+		},
+		fallbacks: []Provider{ProviderOllama, ProviderAnthropic},
+	}
+
+	assert.Same(t, router, router.WithProviders(nil))
+
+	resp, err := router.WithProviders([]Provider{ProviderOllama}).Complete(context.Background(), &Request{Tier: Tier1})
+	require.NoError(t, err)
+	assert.Equal(t, ProviderOllama, resp.Provider)
+	assert.Equal(t, 0, anthropic.callCount)
+
+	// The original router is unrestricted
+	resp, err = router.Complete(context.Background(), &Request{Tier: Tier1})
+	require.NoError(t, err)
+	assert.Equal(t, ProviderAnthropic, resp.Provider)
+
+	_, err = router.WithProviders([]Provider{ProviderOpenAI}).Complete(context.Background(), &Request{Tier: Tier1})
+	assert.Error(t, err)
+}
+
 func TestRouter_Complete_NoProviders(t *testing.T) {
 	router := &Router{
 		config: &RouterConfig{
@@ -314,6 +353,138 @@ func TestRouter_GetProvidersForTier(t *testing.T) {
 	})
 }
 
+func TestRouter_GetProvidersForTier_TierProviders(t *testing.T) {
+	router := &Router{
+		config: &RouterConfig{
+			DefaultProvider: ProviderOllama,
+			TierModels: map[Tier]map[Provider]string{
+				Tier1: {ProviderOllama: "model1", ProviderOpenAI: "model2"},
+			},
+			TierProviders: map[Tier]Provider{
+				Tier1: ProviderOpenAI,
+				Tier2: ProviderAnthropic, // not configured
+			},
+		},
+		clients: map[Provider]Client{
+			ProviderOllama: newMockClient(ProviderOllama, true),
+			ProviderOpenAI: newMockClient(ProviderOpenAI, true),
+		},
+		fallbacks: []Provider{ProviderOllama, ProviderAnthropic, ProviderOpenAI},
+	}
+
+	assert.Equal(t, []Provider{ProviderOpenAI, ProviderOllama}, router.getProvidersForTier(Tier1))
+	assert.Equal(t, []Provider{ProviderOllama, ProviderOpenAI}, router.getProvidersForTier(Tier2))
+}
+
+func TestNewRouter_OpenAI(t *testing.T) {
+	cfg := &config.Config{LLM: config.LLMConfig{
+		DefaultProvider: "ollama",
+		OllamaURL:       "http://localhost:11434",
+		OllamaTier1:     "qwen",
+		OpenAITier2:     config.OpenAIEndpoint{URL: "http://localhost:8000/v1", Model: "vllm-model"},
+		OpenAITier3:     config.OpenAIEndpoint{Model: "no-url"},
+		Tier2Provider:   "openai",
+	}}
+
+	router, err := NewRouter(cfg)
+	require.NoError(t, err)
+
+	client, ok := router.clients[ProviderOpenAI].(*OpenAIClient)
+	require.True(t, ok, "OpenAI client should be configured")
+	assert.Equal(t, "vllm-model", client.endpoints[Tier2].Model)
+	assert.NotContains(t, client.endpoints, Tier3)
+	assert.Equal(t, "vllm-model", router.config.TierModels[Tier2][ProviderOpenAI])
+	assert.Equal(t, ProviderOpenAI, router.getProvidersForTier(Tier2)[0])
+}
+
+// modelClient records the model each request named
+type modelClient struct {
+	name   Provider
+	err    error
+	models []string
+}
+
+func (c *modelClient) Name() Provider  { return c.name }
+func (c *modelClient) Available() bool { return true }
+
+func (c *modelClient) Complete(ctx context.Context, req *Request) (*Response, error) {
+	c.models = append(c.models, req.Model)
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &Response{Content: "ok", Model: req.Model, Provider: c.name}, nil
+}
+
+func TestRouter_Complete_TierChain(t *testing.T) {
+	ollama := &modelClient{name: ProviderOllama, err: errors.New("401 unauthorized")}
+	openai := &modelClient{name: ProviderOpenAI}
+	anthropic := &modelClient{name: ProviderAnthropic}
+
+	router := &Router{
+		config: &RouterConfig{
+			DefaultProvider: ProviderAnthropic,
+			TierChains: map[Tier][]Route{
+				Tier2: {
+					{Provider: ProviderOllama, Model: "deepseek-coder-v2:16b"},
+					{Provider: Provider("gemini"), Model: "not-configured"},
+					{Provider: ProviderOpenAI, Model: "gpt-4o-mini"},
+					{Provider: ProviderAnthropic, Model: "claude-3-haiku-20240307"},
+				},
+			},
+		},
+		clients: map[Provider]Client{
+			ProviderOllama:    ollama,
+			ProviderOpenAI:    openai,
+			ProviderAnthropic: anthropic,
+		},
+		fallbacks: []Provider{ProviderOllama, ProviderAnthropic, ProviderOpenAI},
+	}
+
+	resp, err := router.Complete(context.Background(), &Request{Tier: Tier2})
+	require.NoError(t, err)
+	assert.Equal(t, ProviderOpenAI, resp.Provider)
+	assert.Equal(t, []string{"deepseek-coder-v2:16b"}, ollama.models)
+	assert.Equal(t, []string{"gpt-4o-mini"}, openai.models)
+	assert.Empty(t, anthropic.models)
+
+	require.Len(t, resp.Attempts, 2)
+	assert.Equal(t, Attempt{Provider: ProviderOllama, Model: "deepseek-coder-v2:16b", Tier: Tier2, Error: "401 unauthorized"}, resp.Attempts[0])
+	assert.Equal(t, Attempt{Provider: ProviderOpenAI, Model: "gpt-4o-mini", Tier: Tier2}, resp.Attempts[1])
+
+	// Tiers without a chain use their providers, and answering first
+	// records no attempts
+	ollama.err = nil
+	resp, err = router.Complete(context.Background(), &Request{Tier: Tier1})
+	require.NoError(t, err)
+	assert.Equal(t, ProviderOllama, resp.Provider)
+	assert.Equal(t, "", ollama.models[1], "no model override without a chain")
+	assert.Nil(t, resp.Attempts)
+
+	// A policy restricting providers applies to chains too
+	resp, err = router.WithProviders([]Provider{ProviderAnthropic}).Complete(context.Background(), &Request{Tier: Tier2})
+	require.NoError(t, err)
+	assert.Equal(t, "claude-3-haiku-20240307", resp.Model)
+	assert.Len(t, openai.models, 1)
+}
+
+func TestNewRouter_TierChain(t *testing.T) {
+	router, err := NewRouter(&config.Config{LLM: config.LLMConfig{
+		DefaultProvider: "ollama",
+		OllamaURL:       "http://localhost:11434",
+		OpenAIURL:       "https://api.openai.com/v1",
+		Tier2Chain:      "ollama:deepseek-coder-v2:16b,openai:gpt-4o-mini",
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, []Route{
+		{Provider: ProviderOllama, Model: "deepseek-coder-v2:16b"},
+		{Provider: ProviderOpenAI, Model: "gpt-4o-mini"},
+	}, router.getRoutesForTier(Tier2))
+	assert.NotNil(t, router.clients[ProviderOpenAI], "a chain naming an OpenAI model should configure the client")
+
+	_, err = NewRouter(&config.Config{LLM: config.LLMConfig{OllamaURL: "x", Tier1Chain: "bard:x"}})
+	assert.Error(t, err)
+}
+
 func TestRouter_HealthCheck_Available(t *testing.T) {
 	client := newMockClient(ProviderOllama, true)
 
@@ -0,0 +1,65 @@
+package llm
+
+// EstimateTokens roughly counts the tokens in text, at 4 characters per
+// token
+func EstimateTokens(text string) int {
+	return (len(text) + 3) / 4
+}
+
+// RequestTokens estimates the context a request needs: its prompt plus the
+// output it allows
+func RequestTokens(req *Request) int {
+	return EstimateTokens(req.System) + messageTokens(req) + req.MaxTokens
+}
+
+// messageTokens estimates the tokens of a request's messages, which hold
+// the target; the system prompt is the same for every target
+func messageTokens(req *Request) int {
+	tokens := 0
+	for _, m := range req.Messages {
+		tokens += EstimateTokens(m.Content)
+	}
+	return tokens
+}
+
+// TierSelector picks the tier for each request by the context it needs,
+// instead of one tier for a whole run: large targets move to a tier whose
+// models have the context for them, trivial ones to the fast tier
+type TierSelector struct {
+	// Context is the context window of each tier's models, in tokens; a
+	// tier without one is assumed to fit anything
+	Context map[Tier]int
+
+	// SmallTokens is the size of a target, in tokens of the request's
+	// messages, up to which Tier1 is used whatever the requested tier; 0
+	// never moves a request down
+	SmallTokens int
+}
+
+// Select returns the tier for a request whose messages are target tokens
+// and which needs tokens of context in all, given the tier it asked for
+func (s *TierSelector) Select(base Tier, target, tokens int) Tier {
+	if s == nil {
+		return base
+	}
+	if s.SmallTokens > 0 && target <= s.SmallTokens && s.fits(Tier1, tokens) {
+		return Tier1
+	}
+	for tier := base; tier <= Tier3; tier++ {
+		if s.fits(tier, tokens) {
+			return tier
+		}
+	}
+	return Tier3 // nothing fits; the largest context truncates least
+}
+
+func (s *TierSelector) fits(tier Tier, tokens int) bool {
+	window, ok := s.Context[tier]
+	return !ok || window <= 0 || tokens <= window
+}
+
+// SelectTier returns the tier req should be sent at for the context it
+// needs, or its own tier when the router doesn't select tiers
+func (r *Router) SelectTier(req *Request) Tier {
+	return r.selector.Select(req.Tier, messageTokens(req), RequestTokens(req))
+}
@@ -0,0 +1,70 @@
+package llm
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTierSelector_Select(t *testing.T) {
+	s := &TierSelector{
+		Context:     map[Tier]int{Tier1: 8000, Tier2: 16000, Tier3: 200000},
+		SmallTokens: 300,
+	}
+
+	tests := []struct {
+		name   string
+		base   Tier
+		target int
+		tokens int
+		want   Tier
+	}{
+		{"trivial target moves down", Tier3, 100, 4000, Tier1},
+		{"fits the run's tier", Tier2, 2000, 6000, Tier2},
+		{"too large for tier 1", Tier1, 9000, 12000, Tier2},
+		{"too large for tier 2", Tier1, 15000, 20000, Tier3},
+		{"never moves below a larger base", Tier2, 1000, 4000, Tier2},
+		{"nothing fits", Tier1, 250000, 260000, Tier3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, s.Select(tt.base, tt.target, tt.tokens))
+		})
+	}
+}
+
+func TestTierSelector_NoLimits(t *testing.T) {
+	var unset *TierSelector
+	assert.Equal(t, Tier2, unset.Select(Tier2, 10, 1000000))
+
+	// Tiers without a context window fit anything, and no small threshold
+	// keeps small targets at the run's tier
+	s := &TierSelector{Context: map[Tier]int{Tier1: 1000}}
+	assert.Equal(t, Tier2, s.Select(Tier1, 5000, 5000))
+	assert.Equal(t, Tier3, s.Select(Tier3, 10, 100))
+}
+
+func TestRouter_SelectTier(t *testing.T) {
+	router := &Router{selector: &TierSelector{
+		Context:     map[Tier]int{Tier1: 2000, Tier2: 16000},
+		SmallTokens: 300,
+	}}
+
+	small := &Request{
+		Tier:      Tier2,
+		System:    strings.Repeat("s", 4000), // the system prompt isn't the target
+		Messages:  []Message{{Role: "user", Content: "func A() int { return 1 }"}},
+		MaxTokens: 500,
+	}
+	assert.Equal(t, Tier1, router.SelectTier(small))
+
+	large := &Request{
+		Tier:      Tier1,
+		Messages:  []Message{{Role: "user", Content: strings.Repeat("x", 40000)}},
+		MaxTokens: 3000,
+	}
+	assert.Equal(t, 13000, RequestTokens(large))
+	assert.Equal(t, Tier2, router.SelectTier(large))
+}
@@ -1,6 +1,9 @@
 package llm
 
-import "context"
+import (
+	"context"
+	"encoding/json"
+)
 
 // Provider represents an LLM provider
 type Provider string
@@ -29,6 +32,15 @@ type Request struct {
 	Temperature float64
 	Stop        []string
 	JSONMode    bool // Force JSON output (supported by Ollama)
+
+	// Schema is a JSON schema the output must match. Ollama constrains its
+	// output to it; Anthropic is made to answer through a tool taking it
+	// as input. It implies JSONMode.
+	Schema json.RawMessage
+
+	// Model overrides the provider's model for the tier; the router sets
+	// it for each step of a tier's failover chain
+	Model string
 }
 
 // Message represents a chat message
@@ -46,6 +58,26 @@ type Response struct {
 	OutputTokens int
 	FinishReason string
 	Cached       bool // True if response was served from cache
+
+	// Attempts lists the providers the request went to, ending with the
+	// one that answered, when it failed over; nil when the first answered
+	Attempts []Attempt
+}
+
+// Attempt is a provider a request was sent to, or skipped, on its way
+// through a tier's providers
+type Attempt struct {
+	Provider Provider `json:"provider"`
+	Model    string   `json:"model,omitempty"`
+	Tier     Tier     `json:"tier"`
+	Error    string   `json:"error,omitempty"` // empty for the one that answered
+}
+
+// Route is a step in a tier's failover chain: a provider and the model to
+// use on it, or its tier model when empty
+type Route struct {
+	Provider Provider
+	Model    string
 }
 
 // Client is the interface for LLM providers
@@ -60,6 +92,8 @@ type RouterConfig struct {
 	DefaultProvider Provider
 	Providers       map[Provider]ProviderConfig
 	TierModels      map[Tier]map[Provider]string
+	TierProviders   map[Tier]Provider // provider each tier tries first
+	TierChains      map[Tier][]Route  // ordered failover chain per tier, overriding the above
 }
 
 // ProviderConfig holds provider-specific configuration
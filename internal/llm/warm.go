@@ -0,0 +1,133 @@
+package llm
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// coldLoad is the load time above which a request counts as having waited
+// for the model to be loaded; requests to a loaded model report a few
+// milliseconds
+const coldLoad = time.Second
+
+// ModelLoadStats describes how long a model took to load when it wasn't in
+// memory
+type ModelLoadStats struct {
+	Loads  int       `json:"loads"` // cold loads seen
+	LastMs int64     `json:"last_ms"`
+	MaxMs  int64     `json:"max_ms"`
+	AvgMs  int64     `json:"avg_ms"`
+	LastAt time.Time `json:"last_at"`
+}
+
+// loadTracker records cold model loads
+type loadTracker struct {
+	mu     sync.Mutex
+	models map[string]*modelLoads
+}
+
+type modelLoads struct {
+	count int
+	last  time.Duration
+	max   time.Duration
+	total time.Duration
+	at    time.Time
+}
+
+func newLoadTracker() *loadTracker {
+	return &loadTracker{models: make(map[string]*modelLoads)}
+}
+
+// record notes a request's model load time, if it was a cold load
+func (t *loadTracker) record(model string, load time.Duration) {
+	if load < coldLoad {
+		return
+	}
+	log.Info().Str("model", model).Dur("load", load).Msg("ollama model loaded")
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	m := t.models[model]
+	if m == nil {
+		m = &modelLoads{}
+		t.models[model] = m
+	}
+	m.count++
+	m.last = load
+	m.total += load
+	m.at = time.Now()
+	if load > m.max {
+		m.max = load
+	}
+}
+
+func (t *loadTracker) snapshot() map[string]ModelLoadStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	stats := make(map[string]ModelLoadStats, len(t.models))
+	for model, m := range t.models {
+		stats[model] = ModelLoadStats{
+			Loads:  m.count,
+			LastMs: m.last.Milliseconds(),
+			MaxMs:  m.max.Milliseconds(),
+			AvgMs:  (m.total / time.Duration(m.count)).Milliseconds(),
+			LastAt: m.at,
+		}
+	}
+	return stats
+}
+
+// preloader is a client that can load its models ahead of requests
+type preloader interface {
+	Preload(ctx context.Context) error
+	ModelLoads() map[string]ModelLoadStats
+}
+
+// Preload loads the models of the configured tiers for providers that keep
+// models in memory, so the first request after a start doesn't wait for them
+func (r *Router) Preload(ctx context.Context) {
+	for provider, client := range r.clients {
+		p, ok := client.(preloader)
+		if !ok {
+			continue
+		}
+		start := time.Now()
+		if err := p.Preload(ctx); err != nil {
+			log.Warn().Err(err).Str("provider", string(provider)).Msg("failed to preload models")
+			continue
+		}
+		log.Info().Str("provider", string(provider)).Dur("elapsed", time.Since(start)).Msg("preloaded models")
+	}
+}
+
+// KeepWarm preloads the models every interval until ctx is done, so they
+// aren't unloaded while workers are idle
+func (r *Router) KeepWarm(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.Preload(ctx)
+		}
+	}
+}
+
+// ModelLoads returns model load latency statistics, by model
+func (r *Router) ModelLoads() map[string]ModelLoadStats {
+	stats := make(map[string]ModelLoadStats)
+	for _, client := range r.clients {
+		if p, ok := client.(preloader); ok {
+			for model, s := range p.ModelLoads() {
+				stats[model] = s
+			}
+		}
+	}
+	return stats
+}
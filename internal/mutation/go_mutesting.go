@@ -39,6 +39,11 @@ func (t *GoMutestingTool) IsAvailable(ctx context.Context) bool {
 	return err == nil
 }
 
+// Supports reports whether the source file is Go
+func (t *GoMutestingTool) Supports(sourceFile string) bool {
+	return filepath.Ext(sourceFile) == ".go"
+}
+
 // Run executes mutation testing on the source file
 func (t *GoMutestingTool) Run(ctx context.Context, sourceFile, testFile string, cfg MutationConfig) (*Result, error) {
 	start := time.Now()
@@ -252,6 +257,11 @@ func (t *SimpleMutationTool) IsAvailable(ctx context.Context) bool {
 	return true
 }
 
+// Supports reports whether the source file is Go, as the tool runs go test
+func (t *SimpleMutationTool) Supports(sourceFile string) bool {
+	return filepath.Ext(sourceFile) == ".go"
+}
+
 // Run performs simple mutation testing by running tests multiple times
 // This is a fallback when go-mutesting is not available
 func (t *SimpleMutationTool) Run(ctx context.Context, sourceFile, testFile string, cfg MutationConfig) (*Result, error) {
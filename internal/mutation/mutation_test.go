@@ -4,7 +4,9 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"os"
+	"strings"
 	"testing"
 	"time"
 )
@@ -433,3 +435,57 @@ func TestStatusClass(t *testing.T) {
 		})
 	}
 }
+
+func TestRunner_ToolFor(t *testing.T) {
+	goTool := NewSimpleMutationTool()
+	rustTool := &RustMutationTool{CargoPath: "true"} // always available
+	runner := NewRunner(goTool, rustTool)
+
+	if got := runner.ToolFor(context.Background(), "calc.go"); got != goTool {
+		t.Errorf("ToolFor(calc.go) = %v, want simple", got)
+	}
+	if got := runner.ToolFor(context.Background(), "src/lib.rs"); got != rustTool {
+		t.Errorf("ToolFor(lib.rs) = %v, want rust-mutate", got)
+	}
+	if got := runner.ToolFor(context.Background(), "app.py"); got != nil {
+		t.Errorf("ToolFor(app.py) = %v, want none", got.Name())
+	}
+}
+
+func TestGenerateRustMutants(t *testing.T) {
+	source := `use std::fmt;
+
+pub fn add(a: i32, b: i32) -> i32 {
+    // a + b in a comment
+    let label = "x + y";
+    a + b
+}
+
+pub fn is_small<T: Into<u32>>(n: T) -> bool {
+    n.into() < 10 && true
+}
+
+#[cfg(test)]
+mod tests {
+    fn check() { assert!(1 + 1 == 2); }
+}`
+
+	mutants := generateRustMutants(source, 0)
+
+	var got []string
+	for _, m := range mutants {
+		got = append(got, fmt.Sprintf("%d:%s=>%s", m.line, strings.TrimSpace(m.from), strings.TrimSpace(m.to)))
+	}
+	want := []string{"6:+=>-", "10:<=>>=", "10:&&=>||", "10:true=>false"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("mutants = %v, want %v", got, want)
+	}
+
+	if !strings.Contains(mutants[0].source, "    a - b\n") || !strings.Contains(mutants[0].source, `"x + y"`) {
+		t.Errorf("mutant source should change only the code on line 6:\n%s", mutants[0].source)
+	}
+
+	if limited := generateRustMutants(source, 2); len(limited) != 3 {
+		t.Errorf("len(mutants) with 2 per fn = %d, want 3", len(limited))
+	}
+}
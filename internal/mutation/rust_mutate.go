@@ -0,0 +1,323 @@
+package mutation
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// minRustMutantTimeout is the least time a mutant gets: each one is an
+// incremental cargo build as well as a test run
+const minRustMutantTimeout = 30 * time.Second
+
+// rustOperators are the textual mutations applied to Rust source. Binary
+// operators are matched with the spaces rustfmt puts around them, which keeps
+// generics, references and return arrows from matching.
+var rustOperators = []struct {
+	from, to string
+}{
+	{" + ", " - "},
+	{" - ", " + "},
+	{" * ", " / "},
+	{" / ", " * "},
+	{" == ", " != "},
+	{" != ", " == "},
+	{" < ", " >= "},
+	{" > ", " <= "},
+	{" <= ", " > "},
+	{" >= ", " < "},
+	{" && ", " || "},
+	{" || ", " && "},
+	{"true", "false"},
+	{"false", "true"},
+}
+
+// rustFnPattern matches the line a fn item starts on
+var rustFnPattern = regexp.MustCompile(`^\s*(pub(\([^)]*\))?\s+)?(const\s+)?(async\s+)?(unsafe\s+)?fn\s+\w+`)
+
+// rustMutant is one mutation of a Rust source file
+type rustMutant struct {
+	line     int // 1-based
+	from, to string
+	source   string // the whole mutated file
+}
+
+// RustMutationTool mutates Rust source files in place and runs cargo test
+// against each mutant. It needs only cargo, not cargo-mutants.
+type RustMutationTool struct {
+	// CargoPath is the path to cargo (default: cargo in PATH)
+	CargoPath string
+}
+
+// NewRustMutationTool creates a new Rust mutation tool
+func NewRustMutationTool() *RustMutationTool {
+	return &RustMutationTool{
+		CargoPath: "cargo",
+	}
+}
+
+// Name returns the tool name
+func (t *RustMutationTool) Name() string {
+	return "rust-mutate"
+}
+
+// IsAvailable checks if cargo is installed
+func (t *RustMutationTool) IsAvailable(ctx context.Context) bool {
+	cmd := exec.CommandContext(ctx, t.CargoPath, "--version")
+	return cmd.Run() == nil
+}
+
+// Supports reports whether the source file is Rust
+func (t *RustMutationTool) Supports(sourceFile string) bool {
+	return filepath.Ext(sourceFile) == ".rs"
+}
+
+// Run mutates the source file one mutant at a time and runs the tests
+// against each. The original file is restored before returning.
+func (t *RustMutationTool) Run(ctx context.Context, sourceFile, testFile string, cfg MutationConfig) (*Result, error) {
+	start := time.Now()
+
+	result := &Result{
+		SourceFile: sourceFile,
+		TestFile:   testFile,
+	}
+
+	if cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.Timeout)
+		defer cancel()
+	}
+
+	crateDir := findCargoRoot(sourceFile)
+	if crateDir == "" {
+		result.Error = fmt.Sprintf("no Cargo.toml found above %s", sourceFile)
+		return result, nil
+	}
+
+	info, err := os.Stat(sourceFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat source file: %w", err)
+	}
+	original, err := os.ReadFile(sourceFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read source file: %w", err)
+	}
+
+	// Run tests normally first to ensure they pass
+	if output, err := t.cargoTest(ctx, crateDir, testFile); err != nil {
+		result.Duration = time.Since(start)
+		result.Error = fmt.Sprintf("tests must pass before mutation testing: %s", output)
+		return result, nil
+	}
+
+	defer func() {
+		if err := os.WriteFile(sourceFile, original, info.Mode()); err != nil {
+			log.Error().Err(err).Str("file", sourceFile).Msg("failed to restore source file after mutation testing")
+		}
+	}()
+
+	perMutant := cfg.TimeoutPerMutant
+	if perMutant < minRustMutantTimeout {
+		perMutant = minRustMutantTimeout
+	}
+
+	for i, m := range generateRustMutants(string(original), cfg.MaxMutantsPerFunction) {
+		if ctx.Err() != nil {
+			result.Error = "mutation testing timed out"
+			break
+		}
+
+		mutant := Mutant{
+			ID:          fmt.Sprintf("mutant-%d", i+1),
+			Description: fmt.Sprintf("Replaced %s with %s", strings.TrimSpace(m.from), strings.TrimSpace(m.to)),
+			Line:        m.line,
+			Original:    strings.TrimSpace(m.from),
+			Mutated:     strings.TrimSpace(m.to),
+		}
+		mutant.Type = inferMutationType(mutant.Description)
+
+		if err := os.WriteFile(sourceFile, []byte(m.source), info.Mode()); err != nil {
+			return nil, fmt.Errorf("failed to write mutant: %w", err)
+		}
+
+		mutantCtx, cancel := context.WithTimeout(ctx, perMutant)
+		output, err := t.cargoTest(mutantCtx, crateDir, testFile)
+		timedOut := mutantCtx.Err() == context.DeadlineExceeded && ctx.Err() == nil
+		cancel()
+
+		switch {
+		case err == nil:
+			mutant.Status = StatusSurvived
+			result.Survived++
+			result.Total++
+		case timedOut:
+			mutant.Status = StatusTimeout
+			result.Timeout++
+			result.Total++
+		case ctx.Err() != nil:
+			continue // cut short by the overall timeout; reported above
+		case strings.Contains(output, "could not compile") || strings.Contains(output, "error[E"):
+			// The mutant doesn't build, e.g. - on an unsigned type, so it
+			// says nothing about the tests
+			mutant.Status = StatusError
+		default:
+			mutant.Status = StatusKilled
+			result.Killed++
+			result.Total++
+		}
+		result.Mutants = append(result.Mutants, mutant)
+	}
+
+	result.Duration = time.Since(start)
+	if result.Total > 0 {
+		result.Score = float64(result.Killed) / float64(result.Total)
+	}
+
+	log.Info().
+		Str("source", sourceFile).
+		Int("total", result.Total).
+		Int("killed", result.Killed).
+		Int("survived", result.Survived).
+		Float64("score", result.Score).
+		Dur("duration", result.Duration).
+		Msg("mutation testing complete")
+
+	return result, nil
+}
+
+// cargoTest runs the crate's tests, only the integration test target when
+// testFile is one
+func (t *RustMutationTool) cargoTest(ctx context.Context, crateDir, testFile string) (string, error) {
+	args := []string{"test", "--quiet"}
+	if rel, err := filepath.Rel(filepath.Join(crateDir, "tests"), testFile); err == nil &&
+		!strings.HasPrefix(rel, "..") && !strings.Contains(filepath.ToSlash(rel), "/") {
+		args = append(args, "--test", strings.TrimSuffix(rel, ".rs"))
+	}
+
+	cmd := exec.CommandContext(ctx, t.CargoPath, args...)
+	cmd.Dir = crateDir
+	output, err := cmd.CombinedOutput()
+	return string(output), err
+}
+
+// findCargoRoot returns the nearest directory above path holding a
+// Cargo.toml, or "" if there is none
+func findCargoRoot(path string) string {
+	dir := filepath.Dir(path)
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "Cargo.toml")); err == nil {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// generateRustMutants returns one mutant per operator occurrence in the
+// source, at most maxPerFn for each fn (0 for no limit). Comments, string
+// literals, attributes and the #[cfg(test)] module are left alone.
+func generateRustMutants(source string, maxPerFn int) []rustMutant {
+	lines := strings.Split(source, "\n")
+	var mutants []rustMutant
+
+	fnCount := 0
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "#[cfg(test)]") {
+			break // unit tests conventionally close the file
+		}
+		if rustFnPattern.MatchString(line) {
+			fnCount = 0
+		}
+		if trimmed == "" || strings.HasPrefix(trimmed, "//") || strings.HasPrefix(trimmed, "#") ||
+			strings.HasPrefix(trimmed, "use ") || strings.HasPrefix(trimmed, "mod ") {
+			continue
+		}
+
+		code := rustCodeMask(line)
+		for _, op := range rustOperators {
+			for _, at := range rustOperatorIndexes(line, code, op.from) {
+				if maxPerFn > 0 && fnCount >= maxPerFn {
+					break
+				}
+				mutated := make([]string, len(lines))
+				copy(mutated, lines)
+				mutated[i] = line[:at] + op.to + line[at+len(op.from):]
+
+				mutants = append(mutants, rustMutant{
+					line:   i + 1,
+					from:   op.from,
+					to:     op.to,
+					source: strings.Join(mutated, "\n"),
+				})
+				fnCount++
+			}
+		}
+	}
+
+	return mutants
+}
+
+// rustCodeMask reports for each byte of a line whether it's code, as opposed
+// to a string or char literal or a trailing comment
+func rustCodeMask(line string) []bool {
+	mask := make([]bool, len(line))
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+			continue
+		case c == '"':
+			quote = c
+			continue
+		case c == '/' && i+1 < len(line) && line[i+1] == '/':
+			return mask
+		}
+		mask[i] = true
+	}
+	return mask
+}
+
+// rustOperatorIndexes returns where op occurs in code. Word operators
+// (true, false) must stand alone, not be part of an identifier.
+func rustOperatorIndexes(line string, code []bool, op string) []int {
+	var indexes []int
+	for start := 0; ; {
+		at := strings.Index(line[start:], op)
+		if at < 0 {
+			return indexes
+		}
+		at += start
+		start = at + len(op)
+
+		if !code[at] || !code[at+len(op)-1] {
+			continue
+		}
+		if isIdentByte(op[0]) {
+			if (at > 0 && isIdentByte(line[at-1])) || (at+len(op) < len(line) && isIdentByte(line[at+len(op)])) {
+				continue
+			}
+		}
+		indexes = append(indexes, at)
+	}
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
@@ -3,6 +3,7 @@ package mutation
 import (
 	"context"
 	"fmt"
+	"path/filepath"
 )
 
 // Tool defines the interface for mutation testing tools
@@ -17,6 +18,21 @@ type Tool interface {
 	IsAvailable(ctx context.Context) bool
 }
 
+// FileTool is a Tool that only handles some languages' source files
+type FileTool interface {
+	Tool
+
+	// Supports reports whether the tool can mutate the source file
+	Supports(sourceFile string) bool
+}
+
+// supports reports whether a tool handles a source file; tools that aren't
+// FileTools handle any
+func supports(t Tool, sourceFile string) bool {
+	ft, ok := t.(FileTool)
+	return !ok || ft.Supports(sourceFile)
+}
+
 // Runner orchestrates mutation testing across different tools
 type Runner struct {
 	tools []Tool
@@ -38,22 +54,25 @@ func (r *Runner) Run(ctx context.Context, sourceFile, testFile string, cfg Mutat
 		return nil, fmt.Errorf("no mutation testing tools configured")
 	}
 
-	// Find an available tool
-	var tool Tool
-	for _, t := range r.tools {
-		if t.IsAvailable(ctx) {
-			tool = t
-			break
-		}
-	}
-
+	tool := r.ToolFor(ctx, sourceFile)
 	if tool == nil {
-		return nil, fmt.Errorf("no mutation testing tool available")
+		return nil, fmt.Errorf("no mutation testing tool available for %s", filepath.Base(sourceFile))
 	}
 
 	return tool.Run(ctx, sourceFile, testFile, cfg)
 }
 
+// ToolFor returns the first available tool that handles the source file,
+// or nil if there is none
+func (r *Runner) ToolFor(ctx context.Context, sourceFile string) Tool {
+	for _, t := range r.tools {
+		if supports(t, sourceFile) && t.IsAvailable(ctx) {
+			return t
+		}
+	}
+	return nil
+}
+
 // GetAvailableTools returns all available tools
 func (r *Runner) GetAvailableTools(ctx context.Context) []Tool {
 	var available []Tool
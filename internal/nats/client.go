@@ -138,6 +138,10 @@ func (c *Client) CreateStream(ctx context.Context, cfg StreamConfig) (jetstream.
 	return stream, nil
 }
 
+// MaxDeliver is how many times a consumer delivers a message before
+// dropping it. NAKs count as deliveries.
+const MaxDeliver = 5
+
 // CreateConsumer creates a durable consumer for a stream
 func (c *Client) CreateConsumer(ctx context.Context, streamName, consumerName string, filterSubject string) (jetstream.Consumer, error) {
 	c.mu.RLock()
@@ -154,7 +158,7 @@ func (c *Client) CreateConsumer(ctx context.Context, streamName, consumerName st
 		FilterSubject: filterSubject,
 		AckPolicy:     jetstream.AckExplicitPolicy,
 		AckWait:       5 * time.Minute, // Time to process before redelivery
-		MaxDeliver:    5,               // Max redelivery attempts
+		MaxDeliver:    MaxDeliver,      // Max deliveries, NAKs included
 		MaxAckPending: 100,             // Max unacked messages
 	}
 
@@ -3,6 +3,7 @@ package nats
 
 import (
 	"context"
+	"strings"
 	"time"
 )
 
@@ -24,8 +25,34 @@ const (
 	SubjectJobValidation  = "jobs.validation"
 	SubjectJobMutation    = "jobs.mutation"
 	SubjectJobIntegration = "jobs.integration"
+
+	// subjectInteractivePrefix prefixes the subjects of interactive jobs,
+	// which workers take before others of the same type
+	subjectInteractivePrefix = "jobs.interactive."
+
+	// SubjectWorkerCapabilities carries worker capability reports. It is a
+	// core NATS subject outside the jobs stream.
+	SubjectWorkerCapabilities = "workers.capabilities"
+
+	// subjectProgressPrefix prefixes the core NATS subjects job progress
+	// events are published on, one per job, outside the jobs stream
+	subjectProgressPrefix = "progress."
+
+	// subjectCancelPrefix prefixes the core NATS subjects that tell the
+	// worker running a job to stop it, one per job
+	subjectCancelPrefix = "control.cancel."
+
+	// SubjectCancelAll matches every job's cancel subject
+	SubjectCancelAll = subjectCancelPrefix + "*"
 )
 
+// RoutedJobTypes are published per toolchain, e.g. jobs.validation.go, so
+// only workers with that toolchain consume them
+var RoutedJobTypes = []string{"validation", "mutation", "integration"}
+
+// RoutedToolchains are the toolchains routed job types are split by
+var RoutedToolchains = []string{"go", "node", "python"}
+
 // Consumer names
 const (
 	ConsumerIngestion   = "ingestion-worker"
@@ -59,10 +86,11 @@ func (c *Client) SetupStreams(ctx context.Context) error {
 	}
 
 	// Create consumers for each worker type
-	consumers := []struct {
+	type consumer struct {
 		name    string
 		subject string
-	}{
+	}
+	consumers := []consumer{
 		{ConsumerIngestion, SubjectJobIngestion},
 		{ConsumerModeling, SubjectJobModeling},
 		{ConsumerPlanning, SubjectJobPlanning},
@@ -72,15 +100,65 @@ func (c *Client) SetupStreams(ctx context.Context) error {
 		{ConsumerIntegration, SubjectJobIntegration},
 	}
 
+	// Toolchain consumers for routed job types
+	for _, jobType := range RoutedJobTypes {
+		for _, toolchain := range RoutedToolchains {
+			consumers = append(consumers, consumer{ConsumerForToolchain(jobType, toolchain), SubjectForToolchain(jobType, toolchain)})
+		}
+	}
+
+	// Each consumer has an interactive twin that workers drain first
 	for _, cons := range consumers {
 		if _, err := c.CreateConsumer(ctx, StreamJobs, cons.name, cons.subject); err != nil {
 			return err
 		}
+		if _, err := c.CreateConsumer(ctx, StreamJobs, InteractiveConsumer(cons.name), InteractiveSubject(cons.subject)); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
+// InteractiveSubject returns the interactive lane's subject for a job
+// subject, e.g. jobs.interactive.generation for jobs.generation
+func InteractiveSubject(subject string) string {
+	if subject == "" {
+		return ""
+	}
+	return subjectInteractivePrefix + strings.TrimPrefix(subject, "jobs.")
+}
+
+// InteractiveConsumer returns the interactive lane's consumer name for a
+// job consumer
+func InteractiveConsumer(name string) string {
+	if name == "" {
+		return ""
+	}
+	return "interactive-" + name
+}
+
+// ProgressSubject returns the subject a job's progress events are
+// published on, e.g. progress.<job id>
+func ProgressSubject(jobID string) string {
+	return subjectProgressPrefix + jobID
+}
+
+// CancelSubject returns the subject a job's cancellation is published on,
+// e.g. control.cancel.<job id>
+func CancelSubject(jobID string) string {
+	return subjectCancelPrefix + jobID
+}
+
+// CancelledJobID returns the job ID of a cancel subject, or "" for other
+// subjects
+func CancelledJobID(subject string) string {
+	if !strings.HasPrefix(subject, subjectCancelPrefix) {
+		return ""
+	}
+	return strings.TrimPrefix(subject, subjectCancelPrefix)
+}
+
 // SubjectForJobType returns the NATS subject for a job type
 func SubjectForJobType(jobType string) string {
 	switch jobType {
@@ -124,3 +202,34 @@ func ConsumerForJobType(jobType string) string {
 		return ""
 	}
 }
+
+// SubjectForToolchain returns the subject for a job type routed to a
+// toolchain, or the job type's subject when toolchain is empty or the job
+// type isn't routed
+func SubjectForToolchain(jobType, toolchain string) string {
+	base := SubjectForJobType(jobType)
+	if base == "" || toolchain == "" || !isRouted(jobType) {
+		return base
+	}
+	return base + "." + toolchain
+}
+
+// ConsumerForToolchain returns the consumer name for a job type routed to a
+// toolchain, or the job type's consumer when toolchain is empty or the job
+// type isn't routed
+func ConsumerForToolchain(jobType, toolchain string) string {
+	base := ConsumerForJobType(jobType)
+	if base == "" || toolchain == "" || !isRouted(jobType) {
+		return base
+	}
+	return base + "-" + toolchain
+}
+
+func isRouted(jobType string) bool {
+	for _, t := range RoutedJobTypes {
+		if t == jobType {
+			return true
+		}
+	}
+	return false
+}
@@ -1,6 +1,7 @@
 package nats
 
 import (
+	"strings"
 	"testing"
 	"time"
 )
@@ -189,3 +190,75 @@ func TestDefaultStreamConfig_MaxAge(t *testing.T) {
 		t.Errorf("MaxAge = %v, want %v (7 days)", cfg.MaxAge, expected)
 	}
 }
+
+func TestSubjectForToolchain(t *testing.T) {
+	tests := []struct {
+		jobType   string
+		toolchain string
+		subject   string
+		consumer  string
+	}{
+		{"validation", "go", "jobs.validation.go", "validation-worker-go"},
+		{"mutation", "node", "jobs.mutation.node", "mutation-worker-node"},
+		{"integration", "", SubjectJobIntegration, ConsumerIntegration},
+		{"generation", "go", SubjectJobGeneration, ConsumerGeneration},
+		{"unknown", "go", "", ""},
+	}
+
+	for _, tt := range tests {
+		if got := SubjectForToolchain(tt.jobType, tt.toolchain); got != tt.subject {
+			t.Errorf("SubjectForToolchain(%s, %s) = %s, want %s", tt.jobType, tt.toolchain, got, tt.subject)
+		}
+		if got := ConsumerForToolchain(tt.jobType, tt.toolchain); got != tt.consumer {
+			t.Errorf("ConsumerForToolchain(%s, %s) = %s, want %s", tt.jobType, tt.toolchain, got, tt.consumer)
+		}
+	}
+}
+
+func TestInteractiveSubject(t *testing.T) {
+	tests := []struct {
+		subject  string
+		consumer string
+		want     string
+		wantCons string
+	}{
+		{SubjectJobGeneration, ConsumerGeneration, "jobs.interactive.generation", "interactive-generation-worker"},
+		{"jobs.validation.go", "validation-worker-go", "jobs.interactive.validation.go", "interactive-validation-worker-go"},
+		{"", "", "", ""},
+	}
+
+	for _, tt := range tests {
+		if got := InteractiveSubject(tt.subject); got != tt.want {
+			t.Errorf("InteractiveSubject(%q) = %q, want %q", tt.subject, got, tt.want)
+		}
+		if got := InteractiveConsumer(tt.consumer); got != tt.wantCons {
+			t.Errorf("InteractiveConsumer(%q) = %q, want %q", tt.consumer, got, tt.wantCons)
+		}
+	}
+}
+
+func TestProgressSubject(t *testing.T) {
+	got := ProgressSubject("6f1c2e7a-0000-4000-8000-000000000000")
+	if got != "progress.6f1c2e7a-0000-4000-8000-000000000000" {
+		t.Errorf("ProgressSubject() = %q", got)
+	}
+	// Progress events must stay out of the work queue stream
+	if strings.HasPrefix(got, strings.TrimSuffix(SubjectJobsAll, ">")) {
+		t.Errorf("ProgressSubject() = %q is in the jobs stream", got)
+	}
+}
+
+func TestCancelSubject(t *testing.T) {
+	id := "6f1c2e7a-0000-4000-8000-000000000000"
+	got := CancelSubject(id)
+	if got != "control.cancel."+id {
+		t.Errorf("CancelSubject() = %q", got)
+	}
+	if CancelledJobID(got) != id || CancelledJobID(ProgressSubject(id)) != "" {
+		t.Errorf("CancelledJobID() doesn't invert CancelSubject()")
+	}
+	// Cancellations must stay out of the work queue stream
+	if strings.HasPrefix(got, strings.TrimSuffix(SubjectJobsAll, ">")) {
+		t.Errorf("CancelSubject() = %q is in the jobs stream", got)
+	}
+}
@@ -0,0 +1,98 @@
+// Package netguard keeps requests the server makes to URLs its users give
+// it, like webhook deliveries and archive downloads, away from loopback,
+// private and link-local addresses, which users mustn't reach through the
+// server
+package netguard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// ErrBlockedAddress is returned for hosts at loopback, private, link-local
+// or unspecified addresses
+var ErrBlockedAddress = errors.New("address isn't public")
+
+// maxRedirects matches http.Client's default
+const maxRedirects = 10
+
+// Blocked reports whether an address is one requests can't be made to
+func Blocked(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast()
+}
+
+// CheckHost resolves a host, returning ErrBlockedAddress when any of its
+// addresses is blocked
+func CheckHost(ctx context.Context, host string) error {
+	if ip := net.ParseIP(host); ip != nil {
+		if Blocked(ip) {
+			return fmt.Errorf("%w: %s", ErrBlockedAddress, host)
+		}
+		return nil
+	}
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", host, err)
+	}
+	for _, addr := range addrs {
+		if Blocked(addr.IP) {
+			return fmt.Errorf("%w: %s resolves to %s", ErrBlockedAddress, host, addr.IP)
+		}
+	}
+	return nil
+}
+
+// CheckURL checks a URL's host with CheckHost
+func CheckURL(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("failed to parse URL: %w", err)
+	}
+	return CheckHost(ctx, u.Hostname())
+}
+
+// DialControl refuses connections to blocked addresses, for net.Dialer's
+// Control. It checks the address actually dialed, so a host re-resolving
+// to a blocked address after CheckURL passed still isn't reached.
+func DialControl(network, address string, c syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || Blocked(ip) {
+		return fmt.Errorf("%w: %s", ErrBlockedAddress, host)
+	}
+	return nil
+}
+
+// CheckRedirect is an http.Client CheckRedirect that follows redirects
+// only to http(s) URLs at public addresses
+func CheckRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxRedirects {
+		return fmt.Errorf("stopped after %d redirects", maxRedirects)
+	}
+	if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+		return fmt.Errorf("redirect to unsupported scheme %q", req.URL.Scheme)
+	}
+	return CheckHost(req.Context(), req.URL.Hostname())
+}
+
+// NewTransport returns a transport that only dials public addresses.
+// Proxies aren't used: the dialer must see the request's own address.
+func NewTransport(timeout time.Duration) *http.Transport {
+	dialer := &net.Dialer{Timeout: timeout, Control: DialControl}
+	return &http.Transport{
+		DialContext:         dialer.DialContext,
+		TLSHandshakeTimeout: timeout,
+		MaxIdleConns:        10,
+		IdleConnTimeout:     90 * time.Second,
+	}
+}
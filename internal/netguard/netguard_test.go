@@ -0,0 +1,47 @@
+package netguard
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestCheckURL(t *testing.T) {
+	ctx := context.Background()
+	for _, u := range []string{"https://203.0.113.10/hook", "http://[2001:db8::1]:8080/hook"} {
+		if err := CheckURL(ctx, u); err != nil {
+			t.Errorf("CheckURL(%q) error = %v", u, err)
+		}
+	}
+	for _, u := range []string{
+		"http://127.0.0.1:9000/hook",
+		"http://localhost/hook",
+		"http://169.254.169.254/latest/meta-data",
+		"http://10.1.2.3/hook",
+		"http://192.168.0.1/hook",
+		"http://[::1]/hook",
+		"http://[fe80::1]/hook",
+		"http://0.0.0.0/hook",
+	} {
+		if err := CheckURL(ctx, u); !errors.Is(err, ErrBlockedAddress) {
+			t.Errorf("CheckURL(%q) error = %v, want ErrBlockedAddress", u, err)
+		}
+	}
+}
+
+func TestCheckRedirect(t *testing.T) {
+	redirect := func(rawURL string) error {
+		req, _ := http.NewRequest(http.MethodGet, rawURL, nil)
+		return CheckRedirect(req, []*http.Request{{}})
+	}
+	if err := redirect("https://203.0.113.10/archive.tar.gz"); err != nil {
+		t.Errorf("redirect to a public address error = %v", err)
+	}
+	if err := redirect("http://169.254.169.254/latest/meta-data"); !errors.Is(err, ErrBlockedAddress) {
+		t.Errorf("redirect to metadata address error = %v, want ErrBlockedAddress", err)
+	}
+	if err := redirect("file:///etc/passwd"); err == nil {
+		t.Error("redirect to a file URL followed")
+	}
+}
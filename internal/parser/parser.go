@@ -8,9 +8,11 @@ import (
 	"strings"
 
 	sitter "github.com/smacker/go-tree-sitter"
+	"github.com/smacker/go-tree-sitter/csharp"
 	"github.com/smacker/go-tree-sitter/golang"
 	"github.com/smacker/go-tree-sitter/javascript"
 	"github.com/smacker/go-tree-sitter/python"
+	"github.com/smacker/go-tree-sitter/rust"
 )
 
 // Parser parses source code files using tree-sitter
@@ -18,6 +20,8 @@ type Parser struct {
 	goParser *sitter.Parser
 	pyParser *sitter.Parser
 	jsParser *sitter.Parser
+	rsParser *sitter.Parser
+	csParser *sitter.Parser
 }
 
 // NewParser creates a new parser with all language support
@@ -31,10 +35,18 @@ func NewParser() *Parser {
 	jsParser := sitter.NewParser()
 	jsParser.SetLanguage(javascript.GetLanguage())
 
+	rsParser := sitter.NewParser()
+	rsParser.SetLanguage(rust.GetLanguage())
+
+	csParser := sitter.NewParser()
+	csParser.SetLanguage(csharp.GetLanguage())
+
 	return &Parser{
 		goParser: goParser,
 		pyParser: pyParser,
 		jsParser: jsParser,
+		rsParser: rsParser,
+		csParser: csParser,
 	}
 }
 
@@ -63,6 +75,10 @@ func (p *Parser) ParseContent(ctx context.Context, filePath, content string, lan
 		parser = p.pyParser
 	case LanguageJavaScript, LanguageTypeScript:
 		parser = p.jsParser // Use JS parser for TS as well (basic support)
+	case LanguageRust:
+		parser = p.rsParser
+	case LanguageCSharp:
+		parser = p.csParser
 	default:
 		return nil, fmt.Errorf("unsupported language: %s", lang)
 	}
@@ -89,6 +105,10 @@ func (p *Parser) ParseContent(ctx context.Context, filePath, content string, lan
 		p.extractPythonFunctions(tree.RootNode(), []byte(content), parsed)
 	case LanguageJavaScript, LanguageTypeScript:
 		p.extractJSFunctions(tree.RootNode(), []byte(content), parsed)
+	case LanguageRust:
+		p.extractRustFunctions(tree.RootNode(), []byte(content), parsed)
+	case LanguageCSharp:
+		p.extractCSharpFunctions(tree.RootNode(), []byte(content), parsed)
 	}
 
 	return parsed, nil
@@ -136,6 +156,7 @@ func (p *Parser) parseGoFunction(node *sitter.Node, source []byte) *Function {
 			fn.Body = child.Content(source)
 		}
 	}
+	fn.Async = isGoChannelResult(node.ChildByFieldName("result"))
 
 	return fn
 }
@@ -183,10 +204,29 @@ func (p *Parser) parseGoMethod(node *sitter.Node, source []byte) *Function {
 	if bodyNode != nil {
 		fn.Body = bodyNode.Content(source)
 	}
+	fn.Async = isGoChannelResult(node.ChildByFieldName("result"))
 
 	return fn
 }
 
+// isGoChannelResult reports whether a Go function's result is a single
+// channel, the Go way of returning a result that arrives later
+func isGoChannelResult(result *sitter.Node) bool {
+	if result == nil {
+		return false
+	}
+	if result.Type() == "parameter_list" {
+		if result.NamedChildCount() != 1 {
+			return false
+		}
+		result = result.NamedChild(0).ChildByFieldName("type")
+		if result == nil {
+			return false
+		}
+	}
+	return result.Type() == "channel_type"
+}
+
 func (p *Parser) parseGoParameters(node *sitter.Node, source []byte) []Parameter {
 	params := make([]Parameter, 0)
 
@@ -378,6 +418,8 @@ func (p *Parser) parseJSFunction(node *sitter.Node, source []byte) *Function {
 		fn.Parameters = p.parseJSParameters(paramsNode, source)
 	}
 
+	fn.Async = isJSAsync(node)
+
 	return fn
 }
 
@@ -400,6 +442,8 @@ func (p *Parser) parseJSArrowFunction(node, parent *sitter.Node, source []byte)
 		fn.Parameters = p.parseJSParameters(paramsNode, source)
 	}
 
+	fn.Async = isJSAsync(node)
+
 	return fn
 }
 
@@ -420,9 +464,22 @@ func (p *Parser) parseJSMethod(node *sitter.Node, source []byte) *Function {
 		fn.Parameters = p.parseJSParameters(paramsNode, source)
 	}
 
+	fn.Async = isJSAsync(node)
+
 	return fn
 }
 
+// isJSAsync reports whether a JS/TS function, arrow function or method is
+// declared async
+func isJSAsync(node *sitter.Node) bool {
+	for i := 0; i < int(node.ChildCount()); i++ {
+		if node.Child(i).Type() == "async" {
+			return true
+		}
+	}
+	return false
+}
+
 func (p *Parser) parseJSParameters(node *sitter.Node, source []byte) []Parameter {
 	params := make([]Parameter, 0)
 
@@ -449,6 +506,342 @@ func (p *Parser) parseJSParameters(node *sitter.Node, source []byte) []Parameter
 	return params
 }
 
+// extractRustFunctions extracts functions and impl blocks from Rust source.
+// Unit tests (#[test] functions and #[cfg(test)] modules) are skipped.
+func (p *Parser) extractRustFunctions(node *sitter.Node, source []byte, parsed *ParsedFile) {
+	cursor := sitter.NewTreeCursor(node)
+	defer cursor.Close()
+
+	classes := make(map[string]int) // type name -> index in parsed.Classes
+
+	p.walkTree(cursor, source, func(n *sitter.Node) {
+		if n.Type() != "function_item" || isRustTestCode(n, source) {
+			return
+		}
+
+		fn := p.parseRustFunction(n, source)
+		if fn == nil {
+			return
+		}
+
+		impl := rustEnclosingImpl(n)
+		if impl != nil {
+			if impl.Parent() != nil && impl.Parent().Type() == "block" {
+				return // impl inside a function body
+			}
+			if typeNode := impl.ChildByFieldName("type"); typeNode != nil {
+				fn.Class = rustTypeName(typeNode.Content(source))
+			}
+			// Trait methods are as visible as the trait, not their own pub
+			if impl.ChildByFieldName("trait") != nil {
+				fn.Exported = true
+			}
+		} else if n.Parent() != nil && n.Parent().Type() == "block" {
+			return // nested helper fn, not callable from outside
+		}
+
+		fn.ID = fmt.Sprintf("%s:%d:%s", parsed.Path, fn.StartLine, fn.Name)
+		parsed.Functions = append(parsed.Functions, *fn)
+
+		if fn.Class == "" {
+			return
+		}
+		idx, ok := classes[fn.Class]
+		if !ok {
+			idx = len(parsed.Classes)
+			classes[fn.Class] = idx
+			parsed.Classes = append(parsed.Classes, Class{
+				ID:        fmt.Sprintf("%s:%d:%s", parsed.Path, int(impl.StartPoint().Row)+1, fn.Class),
+				Name:      fn.Class,
+				StartLine: int(impl.StartPoint().Row) + 1,
+				EndLine:   int(impl.EndPoint().Row) + 1,
+				Methods:   make([]Function, 0),
+			})
+		}
+		cls := &parsed.Classes[idx]
+		cls.Methods = append(cls.Methods, *fn)
+		if end := int(impl.EndPoint().Row) + 1; end > cls.EndLine {
+			cls.EndLine = end
+		}
+		if fn.Exported {
+			cls.Exported = true
+		}
+	})
+}
+
+func (p *Parser) parseRustFunction(node *sitter.Node, source []byte) *Function {
+	fn := &Function{
+		StartLine:  int(node.StartPoint().Row) + 1,
+		EndLine:    int(node.EndPoint().Row) + 1,
+		Parameters: make([]Parameter, 0),
+	}
+
+	nameNode := node.ChildByFieldName("name")
+	if nameNode == nil {
+		return nil
+	}
+	fn.Name = nameNode.Content(source)
+
+	for i := 0; i < int(node.ChildCount()); i++ {
+		child := node.Child(i)
+		switch child.Type() {
+		case "visibility_modifier":
+			// pub(crate) and pub(super) aren't reachable from integration tests
+			fn.Exported = child.Content(source) == "pub"
+		case "function_modifiers":
+			fn.Async = strings.Contains(child.Content(source), "async")
+		}
+	}
+
+	if paramsNode := node.ChildByFieldName("parameters"); paramsNode != nil {
+		fn.Parameters = p.parseRustParameters(paramsNode, source)
+	}
+	if returnNode := node.ChildByFieldName("return_type"); returnNode != nil {
+		fn.ReturnType = returnNode.Content(source)
+	}
+	if bodyNode := node.ChildByFieldName("body"); bodyNode != nil {
+		fn.Body = bodyNode.Content(source)
+	}
+
+	return fn
+}
+
+func (p *Parser) parseRustParameters(node *sitter.Node, source []byte) []Parameter {
+	params := make([]Parameter, 0)
+
+	for i := 0; i < int(node.ChildCount()); i++ {
+		child := node.Child(i)
+		if child.Type() != "parameter" {
+			continue // self_parameter, punctuation
+		}
+		var param Parameter
+		if patternNode := child.ChildByFieldName("pattern"); patternNode != nil {
+			param.Name = strings.TrimPrefix(patternNode.Content(source), "mut ")
+		}
+		if typeNode := child.ChildByFieldName("type"); typeNode != nil {
+			param.Type = typeNode.Content(source)
+		}
+		if param.Name != "" {
+			params = append(params, param)
+		}
+	}
+
+	return params
+}
+
+// rustEnclosingImpl returns the impl block a function is a method of, or nil
+func rustEnclosingImpl(node *sitter.Node) *sitter.Node {
+	parent := node.Parent()
+	if parent == nil || parent.Type() != "declaration_list" {
+		return nil
+	}
+	if impl := parent.Parent(); impl != nil && impl.Type() == "impl_item" {
+		return impl
+	}
+	return nil
+}
+
+// isRustTestCode reports whether a node is a #[test] function or sits in a
+// #[cfg(test)] module
+func isRustTestCode(node *sitter.Node, source []byte) bool {
+	for n := node; n != nil; n = n.Parent() {
+		if n.Type() != "function_item" && n.Type() != "mod_item" {
+			continue
+		}
+		for attr := n.PrevNamedSibling(); attr != nil && attr.Type() == "attribute_item"; attr = attr.PrevNamedSibling() {
+			text := strings.ReplaceAll(attr.Content(source), " ", "")
+			if text == "#[test]" || strings.HasSuffix(text, "::test]") || strings.Contains(text, "cfg(test)") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// rustTypeName strips generics and references from an impl's type,
+// e.g. "Stack<T>" -> "Stack"
+func rustTypeName(typ string) string {
+	typ = strings.TrimLeft(typ, "&")
+	if i := strings.Index(typ, "<"); i >= 0 {
+		typ = typ[:i]
+	}
+	return strings.TrimSpace(typ)
+}
+
+// extractCSharpFunctions extracts classes, structs and records and their
+// methods from C# source. Test methods ([Fact], [Test], ...) are skipped.
+func (p *Parser) extractCSharpFunctions(node *sitter.Node, source []byte, parsed *ParsedFile) {
+	cursor := sitter.NewTreeCursor(node)
+	defer cursor.Close()
+
+	p.walkTree(cursor, source, func(n *sitter.Node) {
+		switch n.Type() {
+		case "class_declaration", "struct_declaration", "record_declaration":
+		default:
+			return
+		}
+
+		cls := p.parseCSharpClass(n, source, parsed.Path)
+		if cls == nil {
+			return
+		}
+
+		body := n.ChildByFieldName("body")
+		if body == nil {
+			parsed.Classes = append(parsed.Classes, *cls)
+			return
+		}
+		for i := 0; i < int(body.NamedChildCount()); i++ {
+			child := body.NamedChild(i)
+			if child.Type() != "method_declaration" || isCSharpTestMethod(child, source) {
+				continue
+			}
+			fn := p.parseCSharpMethod(child, source)
+			if fn == nil {
+				continue
+			}
+			fn.Class = cls.Name
+			fn.ID = fmt.Sprintf("%s:%d:%s", parsed.Path, fn.StartLine, fn.Name)
+			parsed.Functions = append(parsed.Functions, *fn)
+			cls.Methods = append(cls.Methods, *fn)
+		}
+		parsed.Classes = append(parsed.Classes, *cls)
+	})
+}
+
+func (p *Parser) parseCSharpClass(node *sitter.Node, source []byte, filePath string) *Class {
+	nameNode := node.ChildByFieldName("name")
+	if nameNode == nil {
+		return nil
+	}
+
+	cls := &Class{
+		Name:      nameNode.Content(source),
+		StartLine: int(node.StartPoint().Row) + 1,
+		EndLine:   int(node.EndPoint().Row) + 1,
+		Methods:   make([]Function, 0),
+		Exported:  hasCSharpModifier(node, source, "public"),
+	}
+	cls.ID = fmt.Sprintf("%s:%d:%s", filePath, cls.StartLine, cls.Name)
+
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		child := node.NamedChild(i)
+		if child.Type() != "base_list" {
+			continue
+		}
+		for j := 0; j < int(child.NamedChildCount()); j++ {
+			base := child.NamedChild(j)
+			// A record's base carries its primary constructor arguments
+			name, _, _ := strings.Cut(base.Content(source), "(")
+			name = strings.TrimSpace(name)
+			// By convention interfaces are IName; a class has one base class
+			if isCSharpInterfaceName(name) || cls.Extends != "" {
+				cls.Implements = append(cls.Implements, name)
+			} else {
+				cls.Extends = name
+			}
+		}
+	}
+
+	return cls
+}
+
+func (p *Parser) parseCSharpMethod(node *sitter.Node, source []byte) *Function {
+	nameNode := node.ChildByFieldName("name")
+	if nameNode == nil {
+		return nil
+	}
+
+	fn := &Function{
+		Name:       nameNode.Content(source),
+		StartLine:  int(node.StartPoint().Row) + 1,
+		EndLine:    int(node.EndPoint().Row) + 1,
+		Parameters: make([]Parameter, 0),
+		Exported:   hasCSharpModifier(node, source, "public"),
+		Async:      hasCSharpModifier(node, source, "async"),
+	}
+
+	if paramsNode := node.ChildByFieldName("parameters"); paramsNode != nil {
+		fn.Parameters = p.parseCSharpParameters(paramsNode, source)
+	}
+	if returnNode := node.ChildByFieldName("returns"); returnNode != nil {
+		fn.ReturnType = returnNode.Content(source)
+	} else if returnNode := node.ChildByFieldName("type"); returnNode != nil {
+		fn.ReturnType = returnNode.Content(source)
+	}
+	if bodyNode := node.ChildByFieldName("body"); bodyNode != nil {
+		fn.Body = bodyNode.Content(source)
+	}
+
+	return fn
+}
+
+func (p *Parser) parseCSharpParameters(node *sitter.Node, source []byte) []Parameter {
+	params := make([]Parameter, 0)
+
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		child := node.NamedChild(i)
+		if child.Type() != "parameter" {
+			continue
+		}
+		var param Parameter
+		if nameNode := child.ChildByFieldName("name"); nameNode != nil {
+			param.Name = nameNode.Content(source)
+		}
+		if typeNode := child.ChildByFieldName("type"); typeNode != nil {
+			param.Type = typeNode.Content(source)
+		}
+		if _, def, ok := strings.Cut(child.Content(source), "="); ok {
+			param.Default = strings.TrimSpace(def)
+			param.Optional = true
+		}
+		if param.Name != "" {
+			params = append(params, param)
+		}
+	}
+
+	return params
+}
+
+// hasCSharpModifier reports whether a declaration has the given modifier
+func hasCSharpModifier(node *sitter.Node, source []byte, modifier string) bool {
+	for i := 0; i < int(node.ChildCount()); i++ {
+		child := node.Child(i)
+		if child.Type() == "modifier" && child.Content(source) == modifier {
+			return true
+		}
+	}
+	return false
+}
+
+// isCSharpTestMethod reports whether a method is an xUnit, NUnit or MSTest test
+func isCSharpTestMethod(node *sitter.Node, source []byte) bool {
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		child := node.NamedChild(i)
+		if child.Type() != "attribute_list" {
+			continue
+		}
+		for j := 0; j < int(child.NamedChildCount()); j++ {
+			attr := child.NamedChild(j)
+			nameNode := attr.ChildByFieldName("name")
+			if nameNode == nil {
+				continue
+			}
+			switch strings.TrimSuffix(nameNode.Content(source), "Attribute") {
+			case "Fact", "Theory", "Test", "TestCase", "TestMethod", "DataTestMethod":
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isCSharpInterfaceName reports whether a base type is named like an
+// interface, e.g. IDisposable or IRepository<T>
+func isCSharpInterfaceName(name string) bool {
+	return len(name) > 1 && name[0] == 'I' && name[1] >= 'A' && name[1] <= 'Z'
+}
+
 // walkTree walks the tree and calls fn for each node
 func (p *Parser) walkTree(cursor *sitter.TreeCursor, source []byte, fn func(*sitter.Node)) {
 	for {
@@ -483,11 +876,38 @@ func DetectLanguage(path string) Language {
 		return LanguageTypeScript
 	case ".java":
 		return LanguageJava
+	case ".rs":
+		return LanguageRust
+	case ".cs":
+		return LanguageCSharp
 	default:
 		return LanguageUnknown
 	}
 }
 
+// Extension returns the usual file extension of the language's source
+// files, or "" for an unknown language
+func (l Language) Extension() string {
+	switch l {
+	case LanguageGo:
+		return ".go"
+	case LanguagePython:
+		return ".py"
+	case LanguageJavaScript:
+		return ".js"
+	case LanguageTypeScript:
+		return ".ts"
+	case LanguageJava:
+		return ".java"
+	case LanguageRust:
+		return ".rs"
+	case LanguageCSharp:
+		return ".cs"
+	default:
+		return ""
+	}
+}
+
 // ParseDirectory parses all source files in a directory
 func (p *Parser) ParseDirectory(ctx context.Context, dir string) ([]*ParsedFile, error) {
 	var files []*ParsedFile
@@ -502,7 +922,8 @@ func (p *Parser) ParseDirectory(ctx context.Context, dir string) ([]*ParsedFile,
 			name := info.Name()
 			// Skip hidden directories and common non-source directories
 			if strings.HasPrefix(name, ".") || name == "node_modules" ||
-				name == "vendor" || name == "__pycache__" || name == "testdata" {
+				name == "vendor" || name == "__pycache__" || name == "testdata" ||
+				isCargoTarget(path) || isDotnetOutput(path) {
 				return filepath.SkipDir
 			}
 			return nil
@@ -512,7 +933,9 @@ func (p *Parser) ParseDirectory(ctx context.Context, dir string) ([]*ParsedFile,
 		base := info.Name()
 		if strings.HasSuffix(base, "_test.go") || strings.HasPrefix(base, "test_") ||
 			strings.HasSuffix(base, ".test.ts") || strings.HasSuffix(base, ".test.js") ||
-			strings.HasSuffix(base, ".spec.ts") || strings.HasSuffix(base, ".spec.js") {
+			strings.HasSuffix(base, ".spec.ts") || strings.HasSuffix(base, ".spec.js") ||
+			(strings.HasSuffix(base, ".rs") && filepath.Base(filepath.Dir(path)) == "tests") ||
+			strings.HasSuffix(base, "Tests.cs") || strings.HasSuffix(base, "Test.cs") {
 			return nil
 		}
 
@@ -539,3 +962,21 @@ func (p *Parser) ParseDirectory(ctx context.Context, dir string) ([]*ParsedFile,
 
 	return files, nil
 }
+
+// isCargoTarget reports whether dir is a Cargo crate's build output directory
+func isCargoTarget(dir string) bool {
+	if filepath.Base(dir) != "target" {
+		return false
+	}
+	_, err := os.Stat(filepath.Join(filepath.Dir(dir), "Cargo.toml"))
+	return err == nil
+}
+
+// isDotnetOutput reports whether dir is a .NET project's bin or obj directory
+func isDotnetOutput(dir string) bool {
+	if name := filepath.Base(dir); name != "bin" && name != "obj" {
+		return false
+	}
+	projects, _ := filepath.Glob(filepath.Join(filepath.Dir(dir), "*.csproj"))
+	return len(projects) > 0
+}
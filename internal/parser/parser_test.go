@@ -14,6 +14,8 @@ func TestNewParser(t *testing.T) {
 	assert.NotNil(t, p.goParser)
 	assert.NotNil(t, p.pyParser)
 	assert.NotNil(t, p.jsParser)
+	assert.NotNil(t, p.rsParser)
+	assert.NotNil(t, p.csParser)
 }
 
 func TestDetectLanguage(t *testing.T) {
@@ -29,6 +31,8 @@ func TestDetectLanguage(t *testing.T) {
 		{"app.ts", LanguageTypeScript},
 		{"app.tsx", LanguageTypeScript},
 		{"Main.java", LanguageJava},
+		{"src/lib.rs", LanguageRust},
+		{"Controllers/OrdersController.cs", LanguageCSharp},
 		{"README.md", LanguageUnknown},
 		{"Makefile", LanguageUnknown},
 		{"/path/to/file.go", LanguageGo},
@@ -44,6 +48,13 @@ func TestDetectLanguage(t *testing.T) {
 	}
 }
 
+func TestLanguage_Extension(t *testing.T) {
+	for _, lang := range []Language{LanguageGo, LanguagePython, LanguageJavaScript, LanguageTypeScript, LanguageJava, LanguageRust, LanguageCSharp} {
+		assert.Equal(t, lang, DetectLanguage("file"+lang.Extension()), string(lang))
+	}
+	assert.Equal(t, "", LanguageUnknown.Extension())
+}
+
 func TestParser_ParseContent_Go_SimpleFunction(t *testing.T) {
 	p := NewParser()
 	content := `package main
@@ -338,6 +349,44 @@ func TestParser_ParseContent_Python_AsyncFunction(t *testing.T) {
 	assert.True(t, parsed.Functions[0].Async)
 }
 
+func TestParser_ParseContent_TypeScript_AsyncFunctions(t *testing.T) {
+	p := NewParser()
+	content := `export async function fetchUser(id: string) { return db.find(id); }
+export const save = async (user: User) => db.save(user);
+export function add(a: number, b: number): number { return a + b; }
+class Repo {
+  async count() { return 0; }
+}
+`
+	parsed, err := p.ParseContent(context.Background(), "users.ts", content, LanguageTypeScript)
+	require.NoError(t, err)
+
+	async := make(map[string]bool)
+	for _, fn := range parsed.Functions {
+		async[fn.Name] = fn.Async
+	}
+	assert.Equal(t, map[string]bool{"fetchUser": true, "save": true, "add": false, "count": true}, async)
+}
+
+func TestParser_ParseContent_Go_ChannelResult(t *testing.T) {
+	p := NewParser()
+	content := `package jobs
+
+func Start(n int) <-chan int { return nil }
+func (w *Worker) Results() (results chan Result) { return nil }
+func Run(n int) (<-chan int, error) { return nil, nil }
+func Sum(a, b int) int { return a + b }
+`
+	parsed, err := p.ParseContent(context.Background(), "jobs.go", content, LanguageGo)
+	require.NoError(t, err)
+
+	async := make(map[string]bool)
+	for _, fn := range parsed.Functions {
+		async[fn.Name] = fn.Async
+	}
+	assert.Equal(t, map[string]bool{"Start": true, "Results": true, "Run": false, "Sum": false}, async)
+}
+
 func TestParser_ParseContent_ContextCancellation(t *testing.T) {
 	p := NewParser()
 	ctx, cancel := context.WithCancel(context.Background())
@@ -369,6 +418,8 @@ func TestLanguageConstants(t *testing.T) {
 	assert.Equal(t, Language("javascript"), LanguageJavaScript)
 	assert.Equal(t, Language("typescript"), LanguageTypeScript)
 	assert.Equal(t, Language("java"), LanguageJava)
+	assert.Equal(t, Language("rust"), LanguageRust)
+	assert.Equal(t, Language("csharp"), LanguageCSharp)
 	assert.Equal(t, Language("unknown"), LanguageUnknown)
 }
 
@@ -418,3 +469,185 @@ func TestParser_ParseContent_Python_MethodID(t *testing.T) {
 	// Method ID format: file:line:class.method
 	assert.Contains(t, method.ID, "MyClass.method")
 }
+
+func TestParser_ParseContent_Rust_Functions(t *testing.T) {
+	p := NewParser()
+	content := `pub fn add(a: i32, mut b: i32) -> i32 {
+    a + b
+}
+
+fn helper() {}
+
+pub(crate) fn internal() {}
+
+pub async fn fetch(url: &str) -> Result<String, Error> {
+    todo!()
+}
+`
+	parsed, err := p.ParseContent(context.Background(), "src/lib.rs", content, LanguageRust)
+	require.NoError(t, err)
+	require.Len(t, parsed.Functions, 4)
+
+	add := parsed.Functions[0]
+	assert.Equal(t, "add", add.Name)
+	assert.True(t, add.Exported)
+	assert.Equal(t, "i32", add.ReturnType)
+	require.Len(t, add.Parameters, 2)
+	assert.Equal(t, Parameter{Name: "a", Type: "i32"}, add.Parameters[0])
+	assert.Equal(t, Parameter{Name: "b", Type: "i32"}, add.Parameters[1])
+	assert.Equal(t, "src/lib.rs:1:add", add.ID)
+
+	assert.False(t, parsed.Functions[1].Exported, "private fn")
+	assert.False(t, parsed.Functions[2].Exported, "pub(crate) fn")
+	assert.True(t, parsed.Functions[3].Async)
+}
+
+func TestParser_ParseContent_Rust_ImplBlocks(t *testing.T) {
+	p := NewParser()
+	content := `pub struct Stack<T> {
+    items: Vec<T>,
+}
+
+impl<T> Stack<T> {
+    pub fn push(&mut self, item: T) {
+        self.items.push(item);
+    }
+
+    fn grow(&mut self) {}
+}
+
+impl<T> Default for Stack<T> {
+    fn default() -> Self {
+        Stack { items: Vec::new() }
+    }
+}
+`
+	parsed, err := p.ParseContent(context.Background(), "src/stack.rs", content, LanguageRust)
+	require.NoError(t, err)
+	require.Len(t, parsed.Functions, 3)
+
+	push := parsed.Functions[0]
+	assert.Equal(t, "push", push.Name)
+	assert.Equal(t, "Stack", push.Class)
+	assert.True(t, push.Exported)
+	require.Len(t, push.Parameters, 1, "self is not a parameter")
+	assert.Equal(t, "item", push.Parameters[0].Name)
+
+	assert.False(t, parsed.Functions[1].Exported)
+	assert.True(t, parsed.Functions[2].Exported, "trait methods are public through the trait")
+
+	require.Len(t, parsed.Classes, 1, "impl blocks for one type are merged")
+	cls := parsed.Classes[0]
+	assert.Equal(t, "Stack", cls.Name)
+	assert.True(t, cls.Exported)
+	assert.Len(t, cls.Methods, 3)
+	assert.Equal(t, 5, cls.StartLine)
+	assert.Equal(t, 17, cls.EndLine)
+}
+
+func TestParser_ParseContent_Rust_SkipsTests(t *testing.T) {
+	p := NewParser()
+	content := `pub fn add(a: i32, b: i32) -> i32 {
+    fn inner() {}
+    a + b
+}
+
+#[test]
+fn standalone_test() {}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn adds() {
+        assert_eq!(add(1, 2), 3);
+    }
+
+    fn fixture() {}
+}
+`
+	parsed, err := p.ParseContent(context.Background(), "src/lib.rs", content, LanguageRust)
+	require.NoError(t, err)
+	require.Len(t, parsed.Functions, 1)
+	assert.Equal(t, "add", parsed.Functions[0].Name)
+}
+
+func TestParser_ParseContent_CSharp_Classes(t *testing.T) {
+	p := NewParser()
+	content := `using Microsoft.AspNetCore.Mvc;
+
+namespace Shop.Api.Controllers;
+
+[ApiController]
+public class OrdersController : ControllerBase, IDisposable
+{
+    private readonly IOrderRepository _orders;
+
+    public OrdersController(IOrderRepository orders) { _orders = orders; }
+
+    [HttpGet("{id}")]
+    public async Task<ActionResult<Order>> Get(int id, bool expand = false)
+    {
+        return Ok(await _orders.Find(id));
+    }
+
+    private static int Clamp(int value) => Math.Max(0, value);
+
+    public void Dispose() {}
+}
+
+internal struct Money
+{
+    public decimal Add(decimal a, decimal b) { return a + b; }
+}
+`
+	parsed, err := p.ParseContent(context.Background(), "Controllers/OrdersController.cs", content, LanguageCSharp)
+	require.NoError(t, err)
+	require.Len(t, parsed.Functions, 4, "constructors are not functions")
+
+	get := parsed.Functions[0]
+	assert.Equal(t, "Get", get.Name)
+	assert.Equal(t, "OrdersController", get.Class)
+	assert.True(t, get.Exported)
+	assert.True(t, get.Async)
+	assert.Equal(t, "Task<ActionResult<Order>>", get.ReturnType)
+	require.Len(t, get.Parameters, 2)
+	assert.Equal(t, "id", get.Parameters[0].Name)
+	assert.Equal(t, "int", get.Parameters[0].Type)
+	assert.True(t, get.Parameters[1].Optional)
+	assert.Equal(t, "false", get.Parameters[1].Default)
+
+	assert.False(t, parsed.Functions[1].Exported)
+
+	require.Len(t, parsed.Classes, 2)
+	cls := parsed.Classes[0]
+	assert.Equal(t, "OrdersController", cls.Name)
+	assert.True(t, cls.Exported)
+	assert.Equal(t, "ControllerBase", cls.Extends)
+	assert.Equal(t, []string{"IDisposable"}, cls.Implements)
+	assert.Len(t, cls.Methods, 3)
+
+	assert.Equal(t, "Money", parsed.Classes[1].Name)
+	assert.False(t, parsed.Classes[1].Exported)
+}
+
+func TestParser_ParseContent_CSharp_SkipsTests(t *testing.T) {
+	p := NewParser()
+	content := `public class CalculatorTests
+{
+    [Fact]
+    public void Adds() {}
+
+    [Theory]
+    [InlineData(1)]
+    public void AddsMany(int n) {}
+
+    public Calculator Create() => new Calculator();
+}
+`
+	parsed, err := p.ParseContent(context.Background(), "CalculatorSpecs.cs", content, LanguageCSharp)
+	require.NoError(t, err)
+	require.Len(t, parsed.Functions, 1)
+	assert.Equal(t, "Create", parsed.Functions[0].Name)
+}
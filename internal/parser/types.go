@@ -9,6 +9,8 @@ const (
 	LanguageJavaScript Language = "javascript"
 	LanguageTypeScript Language = "typescript"
 	LanguageJava       Language = "java"
+	LanguageRust       Language = "rust"
+	LanguageCSharp     Language = "csharp"
 	LanguageUnknown    Language = "unknown"
 )
 
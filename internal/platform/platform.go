@@ -0,0 +1,87 @@
+// Package platform runs host tools the same way on Unix and Windows
+package platform
+
+import (
+	"context"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+)
+
+// goos is the host OS; a variable so tests can take the other branch
+var goos = runtime.GOOS
+
+// IsWindows reports whether the host is Windows
+func IsWindows() bool {
+	return goos == "windows"
+}
+
+// scriptTools are the Node tools installed as .cmd scripts on Windows,
+// which only cmd.exe can run
+var scriptTools = map[string]bool{
+	"npm":     true,
+	"npx":     true,
+	"yarn":    true,
+	"pnpm":    true,
+	"jest":    true,
+	"vitest":  true,
+	"stryker": true,
+	"tsc":     true,
+	"eslint":  true,
+}
+
+// isScript reports whether name, a tool or a path to one, is a Node script
+// needing cmd.exe on this host
+func isScript(name string) bool {
+	return IsWindows() && filepath.Ext(name) == "" && scriptTools[filepath.Base(name)]
+}
+
+// Args returns the command line that runs name with args on this host. On
+// Windows, Node's scripts run as name.cmd through cmd /C.
+func Args(name string, args ...string) []string {
+	if isScript(name) {
+		return append([]string{"cmd", "/C", name + ".cmd"}, args...)
+	}
+	return append([]string{name}, args...)
+}
+
+// Command returns a command that runs name with args on this host
+func Command(ctx context.Context, name string, args ...string) *exec.Cmd {
+	argv := Args(name, args...)
+	return exec.CommandContext(ctx, argv[0], argv[1:]...)
+}
+
+// LookPath finds the executable Command runs for name
+func LookPath(name string) (string, error) {
+	if isScript(name) {
+		return exec.LookPath(name + ".cmd")
+	}
+	return exec.LookPath(name)
+}
+
+// Python returns the Python interpreter to run: python3 where it's
+// installed, else python. Windows installs only python; its python3 is
+// usually the Store's placeholder.
+func Python() string {
+	if !IsWindows() {
+		if _, err := exec.LookPath("python3"); err == nil {
+			return "python3"
+		}
+	}
+	return "python"
+}
+
+// GitArgs returns args for git with the settings a Windows checkout needs:
+// no line-ending conversion, so files match the repository byte for byte,
+// and paths past 260 characters. Elsewhere args are unchanged.
+func GitArgs(args ...string) []string {
+	if !IsWindows() {
+		return args
+	}
+	return append([]string{"-c", "core.autocrlf=false", "-c", "core.longpaths=true"}, args...)
+}
+
+// Git returns a git command for args
+func Git(ctx context.Context, args ...string) *exec.Cmd {
+	return exec.CommandContext(ctx, "git", GitArgs(args...)...)
+}
@@ -0,0 +1,70 @@
+package platform
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// setGOOS makes the package behave as on os for the test
+func setGOOS(t *testing.T, os string) {
+	t.Helper()
+	orig := goos
+	goos = os
+	t.Cleanup(func() { goos = orig })
+}
+
+func TestArgs(t *testing.T) {
+	tsc := filepath.Join("node_modules", ".bin", "tsc")
+	tests := []struct {
+		goos string
+		name string
+		args []string
+		want []string
+	}{
+		{"linux", "npx", []string{"jest", "--ci"}, []string{"npx", "jest", "--ci"}},
+		{"windows", "npx", []string{"jest", "--ci"}, []string{"cmd", "/C", "npx.cmd", "jest", "--ci"}},
+		{"windows", tsc, []string{"--noEmit"}, []string{"cmd", "/C", tsc + ".cmd", "--noEmit"}},
+		{"windows", "go", []string{"test", "./..."}, []string{"go", "test", "./..."}},
+		{"windows", "npm.cmd", []string{"test"}, []string{"npm.cmd", "test"}},
+		{"darwin", "stryker", nil, []string{"stryker"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.goos+"/"+tt.name, func(t *testing.T) {
+			setGOOS(t, tt.goos)
+			if got := Args(tt.name, tt.args...); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Args() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCommand(t *testing.T) {
+	setGOOS(t, "windows")
+	cmd := Command(context.Background(), "npm", "test")
+	if want := []string{"cmd", "/C", "npm.cmd", "test"}; !reflect.DeepEqual(cmd.Args, want) {
+		t.Errorf("Command().Args = %q, want %q", cmd.Args, want)
+	}
+}
+
+func TestGitArgs(t *testing.T) {
+	setGOOS(t, "linux")
+	if got := GitArgs("clone", "url"); !reflect.DeepEqual(got, []string{"clone", "url"}) {
+		t.Errorf("GitArgs() on linux = %q", got)
+	}
+
+	setGOOS(t, "windows")
+	want := []string{"-c", "core.autocrlf=false", "-c", "core.longpaths=true", "clone", "url"}
+	if got := GitArgs("clone", "url"); !reflect.DeepEqual(got, want) {
+		t.Errorf("GitArgs() on windows = %q, want %q", got, want)
+	}
+}
+
+func TestPython(t *testing.T) {
+	setGOOS(t, "windows")
+	if got := Python(); got != "python" {
+		t.Errorf("Python() on windows = %s, want python", got)
+	}
+}
@@ -0,0 +1,145 @@
+// Package reporting exports test execution results to external reporting
+// stacks such as Allure and ReportPortal
+package reporting
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/QTest-hq/qtest/internal/emitter"
+	"github.com/QTest-hq/qtest/internal/workspace"
+	"github.com/QTest-hq/qtest/pkg/model"
+	"github.com/google/uuid"
+)
+
+// AllureResult is a single test result in the Allure 2 results format
+type AllureResult struct {
+	UUID          string                `json:"uuid"`
+	HistoryID     string                `json:"historyId"`
+	TestCaseID    string                `json:"testCaseId,omitempty"`
+	FullName      string                `json:"fullName"`
+	Name          string                `json:"name"`
+	Status        string                `json:"status"` // passed, failed, broken, skipped
+	StatusDetails *AllureStatusDetails  `json:"statusDetails,omitempty"`
+	Stage         string                `json:"stage"`
+	Start         int64                 `json:"start"`
+	Stop          int64                 `json:"stop"`
+	Labels        []emitter.ReportLabel `json:"labels"`
+}
+
+// AllureStatusDetails carries failure details for a result
+type AllureStatusDetails struct {
+	Message string `json:"message,omitempty"`
+	Trace   string `json:"trace,omitempty"`
+}
+
+// SpecIndex maps spec IDs to specs so results can be labelled consistently
+// with the annotations emitted into test code
+type SpecIndex map[string]model.TestSpec
+
+// NewSpecIndex builds a SpecIndex from a spec set (nil-safe)
+func NewSpecIndex(set *model.TestSpecSet) SpecIndex {
+	idx := make(SpecIndex)
+	if set == nil {
+		return idx
+	}
+	for _, spec := range set.Specs {
+		idx[spec.ID] = spec
+	}
+	return idx
+}
+
+// labelsFor returns labels for a result, preferring the originating spec
+func (idx SpecIndex) labelsFor(r workspace.TestResult) []emitter.ReportLabel {
+	if spec, ok := idx[r.ID]; ok {
+		return emitter.ReportLabels(spec)
+	}
+	labels := []emitter.ReportLabel{{Name: "feature", Value: r.Target}}
+	if r.File != "" {
+		labels = append(labels, emitter.ReportLabel{Name: "suite", Value: r.File})
+	}
+	return labels
+}
+
+// ToAllureResults converts an execution report into Allure results. Tests are
+// laid out back to back from the report's execution time.
+func ToAllureResults(report *workspace.ExecutionReport, specs SpecIndex) []AllureResult {
+	results := make([]AllureResult, 0, len(report.Tests))
+	start := report.ExecutedAt.UnixMilli()
+
+	for _, t := range report.Tests {
+		stop := start + int64(t.DurationMs)
+
+		fullName := t.Name
+		if t.File != "" {
+			fullName = t.File + "#" + t.Name
+		}
+
+		result := AllureResult{
+			UUID:       uuid.New().String(),
+			HistoryID:  historyID(fullName),
+			TestCaseID: t.ID,
+			FullName:   fullName,
+			Name:       t.Name,
+			Status:     allureStatus(t.Status),
+			Stage:      "finished",
+			Start:      start,
+			Stop:       stop,
+			Labels:     append(specs.labelsFor(t), emitter.ReportLabel{Name: "framework", Value: "qtest"}),
+		}
+		if t.Error != "" || t.StackTrace != "" {
+			result.StatusDetails = &AllureStatusDetails{Message: t.Error, Trace: t.StackTrace}
+		}
+
+		results = append(results, result)
+		start = stop
+	}
+
+	return results
+}
+
+// WriteAllureResults writes each result as <uuid>-result.json into dir,
+// the layout expected by `allure generate`
+func WriteAllureResults(dir string, results []AllureResult) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create allure results directory: %w", err)
+	}
+
+	for _, r := range results {
+		data, err := json.MarshalIndent(r, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal allure result: %w", err)
+		}
+		path := filepath.Join(dir, r.UUID+"-result.json")
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+	}
+
+	return nil
+}
+
+func allureStatus(status string) string {
+	switch status {
+	case "passed", "failed", "skipped":
+		return status
+	default:
+		return "broken"
+	}
+}
+
+// historyID keeps retries of the same test grouped across Allure runs
+func historyID(fullName string) string {
+	sum := sha256.Sum256([]byte(fullName))
+	return hex.EncodeToString(sum[:16])
+}
+
+// millis converts a time to Unix milliseconds
+func millis(t time.Time) int64 {
+	return t.UnixMilli()
+}
@@ -0,0 +1,134 @@
+package reporting
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/QTest-hq/qtest/internal/workspace"
+	"github.com/QTest-hq/qtest/pkg/model"
+)
+
+func sampleReport() *workspace.ExecutionReport {
+	return &workspace.ExecutionReport{
+		Version:    "1.0",
+		ExecutedAt: time.UnixMilli(1700000000000),
+		Summary:    workspace.ExecutionSummary{Total: 2, Passed: 1, Failed: 1},
+		Tests: []workspace.TestResult{
+			{ID: "spec-1", Name: "GET /users returns 200", File: "api.test.js", Target: "/users", Status: "passed", DurationMs: 120},
+			{ID: "spec-2", Name: "POST /users rejects empty body", File: "api.test.js", Target: "/users", Status: "failed", DurationMs: 80, Error: "expected 400, got 500"},
+		},
+	}
+}
+
+func sampleSpecs() SpecIndex {
+	return NewSpecIndex(&model.TestSpecSet{Specs: []model.TestSpec{
+		{ID: "spec-1", Level: model.LevelAPI, Method: "GET", Path: "/users", Description: "List users", Priority: "high"},
+	}})
+}
+
+func TestToAllureResults(t *testing.T) {
+	results := ToAllureResults(sampleReport(), sampleSpecs())
+
+	if len(results) != 2 {
+		t.Fatalf("len(results) = %d, want 2", len(results))
+	}
+
+	first := results[0]
+	if first.Status != "passed" || first.Start != 1700000000000 || first.Stop != 1700000000120 {
+		t.Errorf("unexpected first result timing/status: %+v", first)
+	}
+	if results[1].Start != first.Stop {
+		t.Errorf("second result should start when the first stops")
+	}
+	if results[1].StatusDetails == nil || results[1].StatusDetails.Message != "expected 400, got 500" {
+		t.Errorf("failed result should carry status details")
+	}
+
+	labels := map[string]string{}
+	for _, l := range first.Labels {
+		labels[l.Name] = l.Value
+	}
+	if labels["epic"] != "API tests" || labels["feature"] != "/users" || labels["severity"] != "critical" || labels["AS_ID"] != "spec-1" {
+		t.Errorf("spec-derived labels missing: %v", labels)
+	}
+}
+
+func TestWriteAllureResults(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "allure-results")
+	results := ToAllureResults(sampleReport(), nil)
+
+	if err := WriteAllureResults(dir, results); err != nil {
+		t.Fatalf("WriteAllureResults() error: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, results[0].UUID+"-result.json"))
+	if err != nil {
+		t.Fatalf("result file not written: %v", err)
+	}
+
+	var decoded AllureResult
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("invalid result JSON: %v", err)
+	}
+	if decoded.HistoryID == "" || decoded.FullName != "api.test.js#GET /users returns 200" {
+		t.Errorf("unexpected decoded result: %+v", decoded)
+	}
+}
+
+func TestToReportPortalLaunch(t *testing.T) {
+	launch := ToReportPortalLaunch("nightly", sampleReport(), sampleSpecs())
+
+	if launch.Name != "nightly" || len(launch.Items) != 2 {
+		t.Fatalf("unexpected launch: %+v", launch)
+	}
+	if launch.EndTime != 1700000000200 {
+		t.Errorf("EndTime = %d, want 1700000000200", launch.EndTime)
+	}
+	if launch.Items[1].Status != "failed" || !strings.Contains(launch.Items[1].Log, "expected 400") {
+		t.Errorf("failed item not reported correctly: %+v", launch.Items[1])
+	}
+}
+
+func TestReportPortalClient_Publish(t *testing.T) {
+	var calls []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Method+" "+r.URL.Path)
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/api/v1/demo/launch":
+			w.Write([]byte(`{"id":"launch-1"}`))
+		case r.Method == "POST" && r.URL.Path == "/api/v1/demo/item":
+			w.Write([]byte(`{"id":"item-` + string(rune('0'+len(calls))) + `"}`))
+		default:
+			w.Write([]byte(`{}`))
+		}
+	}))
+	defer server.Close()
+
+	client := NewReportPortalClient(server.URL+"/", "demo", "secret")
+	id, err := client.Publish(t.Context(), ToReportPortalLaunch("run", sampleReport(), nil))
+	if err != nil {
+		t.Fatalf("Publish() error: %v", err)
+	}
+	if id != "launch-1" {
+		t.Errorf("launch id = %s, want launch-1", id)
+	}
+
+	// launch + 2x(start, finish) + 1 log + finish launch
+	if len(calls) != 7 {
+		t.Errorf("expected 7 API calls, got %d: %v", len(calls), calls)
+	}
+	if calls[len(calls)-1] != "PUT /api/v1/demo/launch/launch-1/finish" {
+		t.Errorf("last call = %s, want launch finish", calls[len(calls)-1])
+	}
+}
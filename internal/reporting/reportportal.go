@@ -0,0 +1,204 @@
+package reporting
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/QTest-hq/qtest/internal/workspace"
+)
+
+// RPAttribute is a ReportPortal key/value attribute
+type RPAttribute struct {
+	Key   string `json:"key,omitempty"`
+	Value string `json:"value"`
+}
+
+// RPLaunch is a ReportPortal launch built from an execution report
+type RPLaunch struct {
+	Name        string        `json:"name"`
+	Description string        `json:"description,omitempty"`
+	StartTime   int64         `json:"startTime"`
+	EndTime     int64         `json:"endTime"`
+	Attributes  []RPAttribute `json:"attributes,omitempty"`
+	Items       []RPItem      `json:"items"`
+}
+
+// RPItem is a single test item within a launch
+type RPItem struct {
+	Name       string        `json:"name"`
+	CodeRef    string        `json:"codeRef,omitempty"`
+	TestCaseID string        `json:"testCaseId,omitempty"`
+	StartTime  int64         `json:"startTime"`
+	EndTime    int64         `json:"endTime"`
+	Status     string        `json:"status"` // passed, failed, skipped
+	Attributes []RPAttribute `json:"attributes,omitempty"`
+	Log        string        `json:"log,omitempty"`
+}
+
+// ToReportPortalLaunch converts an execution report into a ReportPortal launch
+func ToReportPortalLaunch(name string, report *workspace.ExecutionReport, specs SpecIndex) *RPLaunch {
+	start := millis(report.ExecutedAt)
+	launch := &RPLaunch{
+		Name:        name,
+		Description: fmt.Sprintf("QTest generated suite: %d passed, %d failed, %d skipped", report.Summary.Passed, report.Summary.Failed, report.Summary.Skipped),
+		StartTime:   start,
+		Attributes:  []RPAttribute{{Key: "generator", Value: "qtest"}},
+		Items:       make([]RPItem, 0, len(report.Tests)),
+	}
+
+	cursor := start
+	for _, t := range report.Tests {
+		item := RPItem{
+			Name:       t.Name,
+			CodeRef:    t.File,
+			TestCaseID: t.ID,
+			StartTime:  cursor,
+			EndTime:    cursor + int64(t.DurationMs),
+			Status:     rpStatus(t.Status),
+		}
+		for _, l := range specs.labelsFor(t) {
+			item.Attributes = append(item.Attributes, RPAttribute{Key: l.Name, Value: l.Value})
+		}
+		if t.Error != "" {
+			item.Log = strings.TrimSpace(t.Error + "\n" + t.StackTrace)
+		}
+
+		launch.Items = append(launch.Items, item)
+		cursor = item.EndTime
+	}
+	launch.EndTime = cursor
+
+	return launch
+}
+
+func rpStatus(status string) string {
+	switch status {
+	case "passed", "skipped":
+		return status
+	default:
+		return "failed"
+	}
+}
+
+// ReportPortalClient publishes launches through the ReportPortal v1 API
+type ReportPortalClient struct {
+	endpoint string
+	project  string
+	token    string
+	client   *http.Client
+}
+
+// NewReportPortalClient creates a client for a ReportPortal project
+func NewReportPortalClient(endpoint, project, token string) *ReportPortalClient {
+	return &ReportPortalClient{
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		project:  project,
+		token:    token,
+		client:   &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Publish starts a launch, reports every item and finishes the launch.
+// It returns the launch UUID assigned by ReportPortal.
+func (c *ReportPortalClient) Publish(ctx context.Context, launch *RPLaunch) (string, error) {
+	var started struct {
+		ID string `json:"id"`
+	}
+	err := c.do(ctx, "POST", "/launch", map[string]interface{}{
+		"name":        launch.Name,
+		"description": launch.Description,
+		"startTime":   launch.StartTime,
+		"attributes":  launch.Attributes,
+		"mode":        "DEFAULT",
+	}, &started)
+	if err != nil {
+		return "", fmt.Errorf("failed to start launch: %w", err)
+	}
+	launchID := started.ID
+
+	for _, item := range launch.Items {
+		var itemResp struct {
+			ID string `json:"id"`
+		}
+		err := c.do(ctx, "POST", "/item", map[string]interface{}{
+			"name":       item.Name,
+			"startTime":  item.StartTime,
+			"type":       "STEP",
+			"launchUuid": launchID,
+			"codeRef":    item.CodeRef,
+			"testCaseId": item.TestCaseID,
+			"attributes": item.Attributes,
+		}, &itemResp)
+		if err != nil {
+			return launchID, fmt.Errorf("failed to start item %s: %w", item.Name, err)
+		}
+
+		if item.Log != "" {
+			err := c.do(ctx, "POST", "/log/entry", map[string]interface{}{
+				"launchUuid": launchID,
+				"itemUuid":   itemResp.ID,
+				"time":       item.EndTime,
+				"level":      "error",
+				"message":    item.Log,
+			}, nil)
+			if err != nil {
+				return launchID, fmt.Errorf("failed to log item %s: %w", item.Name, err)
+			}
+		}
+
+		err = c.do(ctx, "PUT", "/item/"+itemResp.ID, map[string]interface{}{
+			"endTime":    item.EndTime,
+			"status":     item.Status,
+			"launchUuid": launchID,
+		}, nil)
+		if err != nil {
+			return launchID, fmt.Errorf("failed to finish item %s: %w", item.Name, err)
+		}
+	}
+
+	err = c.do(ctx, "PUT", "/launch/"+launchID+"/finish", map[string]interface{}{
+		"endTime": launch.EndTime,
+	}, nil)
+	if err != nil {
+		return launchID, fmt.Errorf("failed to finish launch: %w", err)
+	}
+
+	return launchID, nil
+}
+
+func (c *ReportPortalClient) do(ctx context.Context, method, path string, payload interface{}, out interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("%s/api/v1/%s%s", c.endpoint, c.project, path)
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("%s - %s", resp.Status, string(respBody))
+	}
+
+	if out != nil {
+		return json.NewDecoder(resp.Body).Decode(out)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCron_Next(t *testing.T) {
+	from := time.Date(2025, 3, 14, 10, 17, 42, 0, time.UTC) // a Friday
+
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2025, 3, 14, 10, 18, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2025, 3, 14, 10, 30, 0, 0, time.UTC)},
+		{"30 2 * * *", time.Date(2025, 3, 15, 2, 30, 0, 0, time.UTC)},
+		{"@daily", time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2025, 3, 16, 0, 0, 0, 0, time.UTC)},
+		{"0 3 * * mon-fri", time.Date(2025, 3, 17, 3, 0, 0, 0, time.UTC)},
+		{"0 9 * * 7", time.Date(2025, 3, 16, 9, 0, 0, 0, time.UTC)},
+		{"0 0 1 jan,jul *", time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		// Both day fields restricted: the 1st or any Monday
+		{"0 0 1 * 1", time.Date(2025, 3, 17, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+	for _, tt := range tests {
+		c, err := Parse(tt.expr)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tt.expr, err)
+		}
+		if got := c.Next(from); !got.Equal(tt.want) {
+			t.Errorf("Parse(%q).Next() = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestCron_NextInLocation(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("time zone database not available")
+	}
+	c, err := Parse("0 2 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := c.Next(time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC).In(loc))
+	if want := time.Date(2025, 6, 2, 6, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("Next() = %v, want 02:00 New York (%v)", got, want)
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"* * * foo *",
+		"@nightly",
+	} {
+		if _, err := Parse(expr); err == nil {
+			t.Errorf("Parse(%q) succeeded, want an error", expr)
+		}
+	}
+}
+
+func TestNextRun(t *testing.T) {
+	from := time.Date(2025, 3, 14, 10, 17, 0, 0, time.UTC)
+	if got, err := NextRun("@hourly", "", from); err != nil || !got.Equal(time.Date(2025, 3, 14, 11, 0, 0, 0, time.UTC)) {
+		t.Errorf("NextRun(@hourly) = %v, %v", got, err)
+	}
+	if _, err := NextRun("@daily", "Mars/Olympus", from); err == nil {
+		t.Error("NextRun accepted an unknown time zone")
+	}
+	if _, err := NextRun("0 0 31 4 *", "", from); err == nil {
+		t.Error("NextRun accepted a schedule that never runs")
+	}
+}
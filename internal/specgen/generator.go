@@ -15,6 +15,14 @@ import (
 type Generator struct {
 	router *llm.Router
 	tier   llm.Tier
+
+	// Conventions, if set, is a summary of the repository's testing
+	// conventions that leads every prompt
+	Conventions string
+
+	// OnExchange, if set, is called with each intent's LLM request, the
+	// response and the spec parsed from it, or the error, for debugging
+	OnExchange func(intent model.TestIntent, req *llm.Request, resp *llm.Response, spec *model.TestSpec, err error)
 }
 
 // NewGenerator creates a new spec generator
@@ -34,7 +42,7 @@ func (g *Generator) GenerateSpec(ctx context.Context, intent model.TestIntent, s
 	prompt := g.buildPrompt(intent, fragment)
 
 	// Call LLM
-	resp, err := g.router.Complete(ctx, &llm.Request{
+	req := &llm.Request{
 		Tier:   g.tier,
 		System: systemPromptSpecGen,
 		Messages: []llm.Message{
@@ -42,20 +50,38 @@ func (g *Generator) GenerateSpec(ctx context.Context, intent model.TestIntent, s
 		},
 		Temperature: 0.2, // Low temperature for structured output
 		MaxTokens:   2000,
-	})
+	}
+	resp, err := g.router.Complete(ctx, req)
 	if err != nil {
-		return nil, fmt.Errorf("LLM completion failed: %w", err)
+		err = fmt.Errorf("LLM completion failed: %w", err)
+		g.exchange(intent, req, nil, nil, err)
+		return nil, err
 	}
 
 	// Parse response
 	spec, err := g.parseSpecResponse(resp.Content, intent)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse spec: %w", err)
+		err = fmt.Errorf("failed to parse spec: %w", err)
+		g.exchange(intent, req, resp, nil, err)
+		return nil, err
+	}
+	if ep, ok := fragment["endpoint"].(model.Endpoint); ok && ep.GRPC != nil {
+		fillGRPCCall(spec, ep)
+	}
+	if fn, ok := fragment["function"].(model.Function); ok && fn.Async {
+		spec.Async = true
 	}
 
+	g.exchange(intent, req, resp, spec, nil)
 	return spec, nil
 }
 
+func (g *Generator) exchange(intent model.TestIntent, req *llm.Request, resp *llm.Response, spec *model.TestSpec, err error) {
+	if g.OnExchange != nil {
+		g.OnExchange(intent, req, resp, spec, err)
+	}
+}
+
 // GenerateSpecs generates specs for multiple intents
 func (g *Generator) GenerateSpecs(ctx context.Context, plan *model.TestPlan, sysModel *model.SystemModel) (*model.TestSpecSet, error) {
 	specSet := &model.TestSpecSet{
@@ -150,6 +176,10 @@ func (g *Generator) buildPrompt(intent model.TestIntent, fragment map[string]int
 
 	var sb strings.Builder
 
+	if g.Conventions != "" {
+		sb.WriteString(g.Conventions)
+		sb.WriteString("\n")
+	}
 	sb.WriteString("Generate a test specification for the following target.\n\n")
 
 	sb.WriteString("## System Model Fragment\n```json\n")
@@ -162,6 +192,14 @@ func (g *Generator) buildPrompt(intent model.TestIntent, fragment map[string]int
 
 	if intent.Level == model.LevelAPI {
 		sb.WriteString(apiTestGuidance)
+		if ep, ok := fragment["endpoint"].(model.Endpoint); ok && ep.GraphQL != nil {
+			sb.WriteString("\n\n")
+			sb.WriteString(graphQLTestGuidance)
+		}
+		if ep, ok := fragment["endpoint"].(model.Endpoint); ok && ep.GRPC != nil {
+			sb.WriteString("\n\n")
+			sb.WriteString(grpcTestGuidance)
+		}
 	} else {
 		sb.WriteString(unitTestGuidance)
 	}
@@ -206,10 +244,56 @@ func (g *Generator) parseSpecResponse(response string, intent model.TestIntent)
 	if spec.Priority == "" {
 		spec.Priority = intent.Priority
 	}
+	if spec.GraphQL != nil && spec.Method == "" {
+		spec.Method = "POST"
+	}
+	spec.LineageID = intent.LineageID
+	if spec.LineageID == "" {
+		spec.LineageID = model.LineageIDFor(intent.ID)
+	}
+
+	// Carry planner tags (e.g. "slow") through to emitters
+	for _, tag := range intent.Tags {
+		if !containsString(spec.Tags, tag) {
+			spec.Tags = append(spec.Tags, tag)
+		}
+	}
 
 	return &spec, nil
 }
 
+// fillGRPCCall sets the parts of a gRPC spec the model already knows, so
+// emitters don't depend on the LLM copying them: the method called, its
+// stubs, and the server serving it
+func fillGRPCCall(spec *model.TestSpec, ep model.Endpoint) {
+	if spec.GRPC == nil {
+		spec.GRPC = &model.GRPCCall{}
+	}
+	call := spec.GRPC
+	call.Service = ep.GRPC.FullService()
+	call.Method = ep.GRPC.Method
+	call.RequestType = ep.GRPC.RequestType
+	call.Stubs = ep.GRPC.Stubs
+	call.Server = ep.GRPC.Server
+	call.ServerModule = ep.GRPC.ServerModule
+
+	if spec.Method == "" {
+		spec.Method = ep.Method
+	}
+	if spec.Path == "" {
+		spec.Path = ep.Path
+	}
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
 const systemPromptSpecGen = `You are an expert test engineer. Your task is to generate test specifications in JSON format.
 
 IMPORTANT:
@@ -239,6 +323,12 @@ JSON Schema for TestSpec:
   "headers": { "Authorization": "Bearer token" },
   "body": { "field": "value" },
 
+  // For GraphQL operations (sent as a POST to path, instead of body):
+  "graphql": { "query": "query { user(id: $id) { id } }", "variables": { "id": "1" } },
+
+  // For gRPC methods (instead of body; code is the expected status):
+  "grpc": { "method": "GetUser", "request": { "user_id": "1" }, "code": "OK" | "NOT_FOUND" },
+
   // Expected outcomes:
   "expected": {
     "status": 200,
@@ -253,7 +343,11 @@ JSON Schema for TestSpec:
   ],
 
   "priority": "high" | "medium" | "low",
-  "tags": ["tag1", "tag2"]
+  "tags": ["tag1", "tag2"],
+
+  // Only for slow tests (external calls, large fixtures):
+  "timeout_seconds": 30,
+  "retries": 2
 }`
 
 const apiTestGuidance = `## API Test Guidelines
@@ -267,6 +361,23 @@ const apiTestGuidance = `## API Test Guidelines
   - Response body key fields
   - Error handling (if testing error cases)`
 
+const graphQLTestGuidance = `## GraphQL Test Guidelines
+- The endpoint is a GraphQL operation: set "graphql" instead of "body"
+- Write a query or mutation selecting the operation's field with its
+  arguments as variables, and a few scalar fields of its return type
+- Use "POST" and the endpoint's path
+- GraphQL answers errors with status 200, so assert:
+  - Status code 200
+  - body.data.<field> is not_null`
+
+const grpcTestGuidance = `## gRPC Test Guidelines
+- The endpoint is a gRPC method: set "grpc" instead of "body"
+- Fill "grpc.request" with the request message's fields, using their
+  proto names
+- Set "grpc.code" to the expected status: "OK", or e.g. "NOT_FOUND",
+  "INVALID_ARGUMENT" for error cases
+- Assert fields of the response message as response.<field>`
+
 const unitTestGuidance = `## Unit Test Guidelines
 - Test with typical inputs first
 - Include edge cases (empty, zero, negative if applicable)
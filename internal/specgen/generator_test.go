@@ -1,9 +1,15 @@
 package specgen
 
 import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
 	"strings"
 	"testing"
 
+	"github.com/QTest-hq/qtest/internal/config"
 	"github.com/QTest-hq/qtest/internal/llm"
 	"github.com/QTest-hq/qtest/pkg/model"
 )
@@ -208,6 +214,21 @@ func TestBuildPrompt_Unit(t *testing.T) {
 	}
 }
 
+func TestBuildPrompt_Conventions(t *testing.T) {
+	gen := NewGenerator(nil, llm.Tier1)
+	intent := model.TestIntent{ID: "test-1", Level: model.LevelUnit, TargetKind: "function", TargetID: "fn1"}
+
+	if prompt := gen.buildPrompt(intent, nil); strings.Contains(prompt, "Conventions") {
+		t.Error("Prompt shouldn't have a conventions section unless they're known")
+	}
+
+	gen.Conventions = "## Repository Testing Conventions\n- Assertions: testify/require (3 files)\n"
+	prompt := gen.buildPrompt(intent, nil)
+	if !strings.HasPrefix(prompt, gen.Conventions) {
+		t.Errorf("Prompt should lead with the conventions:\n%s", prompt)
+	}
+}
+
 func TestParseSpecResponse_Valid(t *testing.T) {
 	gen := NewGenerator(nil, llm.Tier1)
 
@@ -351,3 +372,173 @@ func TestUnitTestGuidanceExists(t *testing.T) {
 		t.Error("Unit guidance should mention Unit")
 	}
 }
+
+func TestParseSpecResponse_CarriesIntentTags(t *testing.T) {
+	g := &Generator{}
+	intent := model.TestIntent{ID: "intent:unit:f1", Level: model.LevelUnit, Tags: []string{model.TagSlow}}
+
+	spec, err := g.parseSpecResponse(`{"description": "fetches rates", "tags": ["network", "slow"]}`, intent)
+	if err != nil {
+		t.Fatalf("parseSpecResponse() error: %v", err)
+	}
+
+	if len(spec.Tags) != 2 || !spec.IsSlow() {
+		t.Errorf("Tags = %v, want [network slow]", spec.Tags)
+	}
+}
+
+func TestParseSpecResponse_CarriesLineageID(t *testing.T) {
+	g := &Generator{}
+
+	spec, err := g.parseSpecResponse(`{"description": "adds"}`, model.TestIntent{ID: "intent:unit:f1", LineageID: "ln-abc"})
+	if err != nil {
+		t.Fatalf("parseSpecResponse() error: %v", err)
+	}
+	if spec.LineageID != "ln-abc" {
+		t.Errorf("LineageID = %q, want ln-abc", spec.LineageID)
+	}
+
+	spec, _ = g.parseSpecResponse(`{"description": "adds"}`, model.TestIntent{ID: "intent:unit:f1"})
+	if spec.LineageID != model.LineageIDFor("intent:unit:f1") {
+		t.Errorf("LineageID = %q, want derived from intent ID", spec.LineageID)
+	}
+}
+
+func TestGenerateSpec_OnExchange(t *testing.T) {
+	responses := []string{`{"description": "lists users"}`, `not json`}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/chat" {
+			json.NewEncoder(w).Encode(map[string]interface{}{"models": []interface{}{}})
+			return
+		}
+		content := responses[0]
+		responses = responses[1:]
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"model":   "test",
+			"message": map[string]string{"role": "assistant", "content": content},
+			"done":    true,
+		})
+	}))
+	defer server.Close()
+
+	router, err := llm.NewRouter(&config.Config{LLM: config.LLMConfig{
+		DefaultProvider: "ollama",
+		OllamaURL:       server.URL,
+		OllamaTier1:     "test",
+	}})
+	if err != nil {
+		t.Fatalf("NewRouter() error: %v", err)
+	}
+
+	type exchange struct {
+		req  *llm.Request
+		resp *llm.Response
+		spec *model.TestSpec
+		err  error
+	}
+	var exchanges []exchange
+	gen := NewGenerator(router, llm.Tier1)
+	gen.OnExchange = func(_ model.TestIntent, req *llm.Request, resp *llm.Response, spec *model.TestSpec, err error) {
+		exchanges = append(exchanges, exchange{req, resp, spec, err})
+	}
+
+	intent := model.TestIntent{ID: "i1", TargetKind: "function", TargetID: "fn1"}
+	sysModel := &model.SystemModel{Functions: []model.Function{{ID: "fn1", Name: "ListUsers"}}}
+	if _, err := gen.GenerateSpec(context.Background(), intent, sysModel); err != nil {
+		t.Fatalf("GenerateSpec() error: %v", err)
+	}
+	if _, err := gen.GenerateSpec(context.Background(), intent, sysModel); err == nil {
+		t.Fatal("expected a parse error")
+	}
+
+	if len(exchanges) != 2 {
+		t.Fatalf("exchanges = %d, want 2", len(exchanges))
+	}
+	if ok := exchanges[0]; ok.spec == nil || ok.err != nil || ok.req.System != systemPromptSpecGen {
+		t.Errorf("first exchange = %+v, want the request and parsed spec", ok)
+	}
+	if bad := exchanges[1]; bad.spec != nil || bad.err == nil || bad.resp == nil || bad.resp.Content != "not json" {
+		t.Errorf("second exchange = %+v, want the raw response and parse error", bad)
+	}
+}
+
+func TestBuildPrompt_GraphQL(t *testing.T) {
+	gen := NewGenerator(nil, llm.Tier1)
+	intent := model.TestIntent{ID: "test-1", Level: model.LevelAPI, TargetKind: "endpoint", TargetID: "ep1"}
+
+	rest := gen.buildPrompt(intent, map[string]interface{}{
+		"endpoint": model.Endpoint{ID: "ep1", Method: "GET", Path: "/users"},
+	})
+	if strings.Contains(rest, "GraphQL Test Guidelines") {
+		t.Error("REST endpoints shouldn't get GraphQL guidance")
+	}
+
+	gql := gen.buildPrompt(intent, map[string]interface{}{
+		"endpoint": model.Endpoint{ID: "ep1", Method: "POST", Path: "/graphql",
+			GraphQL: &model.GraphQLOperation{Kind: "query", Field: "users"}},
+	})
+	if !strings.Contains(gql, "GraphQL Test Guidelines") {
+		t.Error("GraphQL endpoints should get GraphQL guidance")
+	}
+}
+
+func TestParseSpecResponse_GraphQL(t *testing.T) {
+	g := &Generator{}
+
+	spec, err := g.parseSpecResponse(`{"path": "/graphql", "graphql": {"query": "{ users { id } }"}}`, model.TestIntent{ID: "i1"})
+	if err != nil {
+		t.Fatalf("parseSpecResponse() error: %v", err)
+	}
+	if spec.GraphQL == nil || spec.GraphQL.Query != "{ users { id } }" {
+		t.Errorf("GraphQL = %+v, want the query parsed", spec.GraphQL)
+	}
+	if spec.Method != "POST" {
+		t.Errorf("Method = %q, want POST for GraphQL specs", spec.Method)
+	}
+}
+
+func TestBuildPrompt_GRPC(t *testing.T) {
+	gen := NewGenerator(nil, llm.Tier1)
+	intent := model.TestIntent{ID: "test-1", Level: model.LevelAPI, TargetKind: "endpoint", TargetID: "ep1"}
+
+	prompt := gen.buildPrompt(intent, map[string]interface{}{
+		"endpoint": model.Endpoint{ID: "ep1", Method: "POST", Path: "/users.v1.UserService/GetUser",
+			GRPC: &model.GRPCMethod{Package: "users.v1", Service: "UserService", Method: "GetUser"}},
+	})
+	if !strings.Contains(prompt, "gRPC Test Guidelines") {
+		t.Error("gRPC endpoints should get gRPC guidance")
+	}
+}
+
+func TestFillGRPCCall(t *testing.T) {
+	ep := model.Endpoint{
+		Method: "POST",
+		Path:   "/users.v1.UserService/GetUser",
+		GRPC: &model.GRPCMethod{
+			Package: "users.v1", Service: "UserService", Method: "GetUser", RequestType: "GetUserRequest",
+			Stubs: "example.com/gen/users/v1", Server: "&server{}", ServerModule: "main",
+		},
+	}
+
+	// The LLM's request and code are kept; what the model knows wins
+	spec := &model.TestSpec{GRPC: &model.GRPCCall{Method: "Get", Request: map[string]interface{}{"user_id": "1"}, Code: "NOT_FOUND"}}
+	fillGRPCCall(spec, ep)
+	want := model.GRPCCall{
+		Service: "users.v1.UserService", Method: "GetUser", RequestType: "GetUserRequest",
+		Request: map[string]interface{}{"user_id": "1"}, Code: "NOT_FOUND",
+		Stubs: "example.com/gen/users/v1", Server: "&server{}", ServerModule: "main",
+	}
+	if !reflect.DeepEqual(*spec.GRPC, want) {
+		t.Errorf("GRPC = %+v, want %+v", *spec.GRPC, want)
+	}
+	if spec.Method != "POST" || spec.Path != ep.Path {
+		t.Errorf("Method, Path = %s %s, want the endpoint's", spec.Method, spec.Path)
+	}
+
+	// Specs without a call get one
+	spec = &model.TestSpec{}
+	fillGRPCCall(spec, ep)
+	if spec.GRPC == nil || spec.GRPC.Method != "GetUser" {
+		t.Errorf("GRPC = %+v, want a GetUser call", spec.GRPC)
+	}
+}
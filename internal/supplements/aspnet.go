@@ -0,0 +1,206 @@
+package supplements
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/QTest-hq/qtest/pkg/model"
+)
+
+// aspNetCoreSignals suggest a project uses ASP.NET Core
+var aspNetCoreSignals = []signal{
+	{suffixes: []string{".csproj"}, text: []string{"Microsoft.NET.Sdk.Web", "Microsoft.AspNetCore"}, weight: weightDependency, label: "project uses the ASP.NET Core SDK"},
+	{suffixes: []string{".cs"}, text: []string{"using Microsoft.AspNetCore", "[ApiController"}, weight: weightImport, label: "imports Microsoft.AspNetCore"},
+	{suffixes: []string{".cs"}, text: []string{"[HttpGet", "[HttpPost", "[Route(", ": ControllerBase"}, weight: weightAnnotation, label: "ASP.NET Core routing attribute"},
+}
+
+var (
+	// [HttpGet], [HttpGet("{id}")], [HttpPost(template: "x", Name = "y")]
+	aspNetVerbPattern = regexp.MustCompile(`\bHttp(Get|Post|Put|Patch|Delete|Head|Options)(?:Attribute)?\b(?:\s*\(\s*(?:template\s*:\s*)?"([^"]*)")?`)
+	// [Route("api/[controller]")]
+	aspNetRoutePattern = regexp.MustCompile(`\bRoute(?:Attribute)?\s*\(\s*(?:template\s*:\s*)?"([^"]*)"`)
+	aspNetClassPattern = regexp.MustCompile(`\bclass\s+(\w+)`)
+	// public async Task<ActionResult<Order>> Get(int id)
+	aspNetActionPattern = regexp.MustCompile(`^(?:public|internal|protected)\s.*?(\w+)\s*(?:<[^>()]*>)?\s*\(`)
+	// app.MapGet("/orders/{id}", handler)
+	aspNetMinimalPattern = regexp.MustCompile(`\.Map(Get|Post|Put|Patch|Delete)\s*\(\s*"([^"]*)"`)
+	// {id:int}, {id?}, {*slug}
+	aspNetParamPattern = regexp.MustCompile(`\{\*{0,2}(\w+)[^}]*\}`)
+)
+
+// AspNetCoreSupplement detects ASP.NET Core controller actions and minimal
+// API routes (C#)
+type AspNetCoreSupplement struct{}
+
+func (s *AspNetCoreSupplement) Name() string {
+	return "aspnetcore"
+}
+
+// Detect checks if the project uses ASP.NET Core
+func (s *AspNetCoreSupplement) Detect(files []string) bool {
+	return s.DetectScored(files).Confidence > 0
+}
+
+// DetectScored returns the evidence that the project uses ASP.NET Core
+func (s *AspNetCoreSupplement) DetectScored(files []string) model.FrameworkDetection {
+	return detectSignals(s.Name(), files, aspNetCoreSignals)
+}
+
+// Analyze finds ASP.NET Core endpoints and adds them to the model
+func (s *AspNetCoreSupplement) Analyze(m *model.SystemModel) error {
+	// Collect all C# files
+	var csFiles []string
+	for _, mod := range m.Modules {
+		for _, f := range mod.Files {
+			if strings.HasSuffix(f, ".cs") {
+				csFiles = append(csFiles, f)
+			}
+		}
+	}
+
+	type verb struct {
+		method string
+		path   string
+		line   int
+	}
+
+	for _, filePath := range csFiles {
+		file, err := os.Open(filePath)
+		if err != nil {
+			continue
+		}
+
+		scanner := bufio.NewScanner(file)
+		lineNum := 0
+
+		var controller, basePath string
+		var pendingVerbs []verb
+		var pendingRoute *string // [Route] not yet attached to a class or action
+
+		for scanner.Scan() {
+			lineNum++
+			trimmed := strings.TrimSpace(scanner.Text())
+			if strings.HasPrefix(trimmed, "//") {
+				continue
+			}
+
+			// Minimal APIs: app.MapGet("/path", ...)
+			if matches := aspNetMinimalPattern.FindStringSubmatch(trimmed); len(matches) >= 3 {
+				m.Endpoints = append(m.Endpoints, aspNetEndpoint(filePath, strings.ToUpper(matches[1]), matches[2], "anonymous", lineNum))
+				continue
+			}
+
+			// Attributes, possibly several on a line or before the declaration
+			if strings.HasPrefix(trimmed, "[") {
+				end := strings.LastIndex(trimmed, "]")
+				if end < 0 {
+					end = len(trimmed) - 1
+				}
+				attrs := trimmed[:end+1]
+				for _, matches := range aspNetVerbPattern.FindAllStringSubmatch(attrs, -1) {
+					pendingVerbs = append(pendingVerbs, verb{strings.ToUpper(matches[1]), matches[2], lineNum})
+				}
+				if matches := aspNetRoutePattern.FindStringSubmatch(attrs); len(matches) >= 2 {
+					route := matches[1]
+					pendingRoute = &route
+				}
+				trimmed = strings.TrimSpace(trimmed[end+1:])
+				if trimmed == "" {
+					continue
+				}
+			}
+
+			// A class takes the [Route] above it as its prefix
+			if matches := aspNetClassPattern.FindStringSubmatch(trimmed); len(matches) >= 2 {
+				controller = matches[1]
+				basePath = ""
+				if pendingRoute != nil {
+					basePath = *pendingRoute
+				}
+				pendingVerbs, pendingRoute = nil, nil
+				continue
+			}
+
+			matches := aspNetActionPattern.FindStringSubmatch(trimmed)
+			if len(matches) < 2 {
+				continue
+			}
+			action := matches[1]
+
+			// An action with only [Route] answers every verb; it's skipped
+			// as there's no one method to test
+			for _, v := range pendingVerbs {
+				template := v.path
+				if template == "" && pendingRoute != nil {
+					template = *pendingRoute
+				}
+				path := aspNetJoinRoute(basePath, template)
+				path = aspNetReplaceTokens(path, controller, action)
+
+				handler := action
+				if controller != "" {
+					handler = controller + "." + action
+				}
+				m.Endpoints = append(m.Endpoints, aspNetEndpoint(filePath, v.method, path, handler, v.line))
+			}
+			pendingVerbs, pendingRoute = nil, nil
+		}
+		file.Close()
+	}
+
+	return nil
+}
+
+// aspNetEndpoint builds an endpoint, dropping route constraints so
+// {id:int} becomes {id}
+func aspNetEndpoint(filePath, method, path, handler string, line int) model.Endpoint {
+	path = aspNetParamPattern.ReplaceAllString(path, "{$1}")
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+
+	endpoint := model.Endpoint{
+		ID:        fmt.Sprintf("ep:%s:%s:%d", filepath.Base(filePath), method, line),
+		Method:    method,
+		Path:      path,
+		Handler:   handler,
+		File:      filePath,
+		Line:      line,
+		Framework: "aspnetcore",
+	}
+
+	// Extract path parameters (e.g., {id}, {orderId})
+	for _, pm := range aspNetParamPattern.FindAllStringSubmatch(path, -1) {
+		endpoint.PathParams = append(endpoint.PathParams, pm[1])
+	}
+
+	return endpoint
+}
+
+// aspNetJoinRoute combines a controller's route with an action's. Action
+// templates starting with / or ~/ replace the controller's.
+func aspNetJoinRoute(base, template string) string {
+	if strings.HasPrefix(template, "/") || strings.HasPrefix(template, "~/") {
+		return strings.TrimPrefix(template, "~")
+	}
+	base = strings.Trim(base, "/")
+	template = strings.Trim(template, "/")
+	switch {
+	case base == "":
+		return "/" + template
+	case template == "":
+		return "/" + base
+	default:
+		return "/" + base + "/" + template
+	}
+}
+
+// aspNetReplaceTokens fills in the [controller] and [action] route tokens
+func aspNetReplaceTokens(path, controller, action string) string {
+	path = strings.ReplaceAll(path, "[controller]", strings.TrimSuffix(controller, "Controller"))
+	return strings.ReplaceAll(path, "[action]", action)
+}
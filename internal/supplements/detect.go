@@ -0,0 +1,102 @@
+package supplements
+
+import (
+	"bufio"
+	"os"
+	"strings"
+
+	"github.com/QTest-hq/qtest/pkg/model"
+)
+
+// Evidence weights: how strongly a signal suggests a framework
+const (
+	weightDependency = 0.9 // declared in a manifest (go.mod, package.json, ...)
+	weightImport     = 0.7 // imported, or marked by an annotation only it uses
+	weightProject    = 0.6 // framework project file, e.g. Django's manage.py
+	weightAnnotation = 0.4 // decorator or annotation other frameworks share
+)
+
+// signal is text in a file that suggests a framework is in use
+type signal struct {
+	suffixes []string // files it applies to
+	text     []string // any of these on a line matches
+	fold     bool     // match case-insensitively
+	weight   float64
+	label    string // describes the evidence
+}
+
+// matches reports whether any of s.text is on line
+func (s signal) matches(line string) bool {
+	if s.fold {
+		line = strings.ToLower(line)
+	}
+	for _, t := range s.text {
+		if s.fold {
+			t = strings.ToLower(t)
+		}
+		if strings.Contains(line, t) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s signal) appliesTo(file string) bool {
+	for _, suffix := range s.suffixes {
+		if strings.HasSuffix(file, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// detectSignals scans files for a framework's signals and combines what it
+// finds into a detection. Each signal counts once per file, at the first
+// line it matches.
+func detectSignals(framework string, files []string, signals []signal) model.FrameworkDetection {
+	var evidence []model.DetectionEvidence
+	for _, f := range files {
+		var applicable []signal
+		for _, s := range signals {
+			if s.appliesTo(f) {
+				applicable = append(applicable, s)
+			}
+		}
+		if len(applicable) > 0 {
+			evidence = append(evidence, scanFile(f, applicable)...)
+		}
+	}
+	return model.NewFrameworkDetection(framework, evidence)
+}
+
+// scanFile returns the evidence for signals in one file
+func scanFile(path string, signals []signal) []model.DetectionEvidence {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	var evidence []model.DetectionEvidence
+	found := make([]bool, len(signals))
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := scanner.Text()
+		for i, s := range signals {
+			if found[i] || !s.matches(line) {
+				continue
+			}
+			found[i] = true
+			evidence = append(evidence, model.DetectionEvidence{
+				File:   path,
+				Line:   lineNum,
+				Signal: s.label,
+				Weight: s.weight,
+			})
+		}
+	}
+	return evidence
+}
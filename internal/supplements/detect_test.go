@@ -0,0 +1,72 @@
+package supplements
+
+import (
+	"testing"
+)
+
+func TestDetectScored_Evidence(t *testing.T) {
+	tmpDir := t.TempDir()
+	pkg := createFile(t, tmpDir, "package.json", "{\n  \"dependencies\": {\n    \"express\": \"^4.0.0\"\n  }\n}")
+	app := createFile(t, tmpDir, "app.js", "// server\nconst express = require('express');\n")
+
+	d := (&ExpressSupplement{}).DetectScored([]string{app, pkg})
+	if d.Framework != "express" {
+		t.Errorf("Framework = %s, want express", d.Framework)
+	}
+	if len(d.Evidence) != 2 {
+		t.Fatalf("len(Evidence) = %d, want 2: %+v", len(d.Evidence), d.Evidence)
+	}
+
+	// Strongest first: the dependency, then the import
+	if d.Evidence[0].File != pkg || d.Evidence[0].Line != 3 || d.Evidence[0].Weight != weightDependency {
+		t.Errorf("Evidence[0] = %+v, want package.json:3", d.Evidence[0])
+	}
+	if d.Evidence[1].File != app || d.Evidence[1].Line != 2 {
+		t.Errorf("Evidence[1] = %+v, want app.js:2", d.Evidence[1])
+	}
+
+	// 1 - (1-0.9)(1-0.7)
+	if d.Confidence != 0.97 {
+		t.Errorf("Confidence = %v, want 0.97", d.Confidence)
+	}
+}
+
+func TestDetectScored_WeakSignal(t *testing.T) {
+	tmpDir := t.TempDir()
+	ctrl := createFile(t, tmpDir, "users.controller.ts", "@Controller('users')\nexport class UsersController {}\n")
+
+	d := (&NestJSSupplement{}).DetectScored([]string{ctrl})
+	if d.Confidence != weightAnnotation {
+		t.Errorf("Confidence = %v, want %v for a shared decorator alone", d.Confidence, weightAnnotation)
+	}
+
+	// The same decorator with the framework import is much more convincing
+	ctrl = createFile(t, tmpDir, "users.controller.ts", "import { Controller } from '@nestjs/common';\n@Controller('users')\nexport class UsersController {}\n")
+	if got := (&NestJSSupplement{}).DetectScored([]string{ctrl}).Confidence; got <= weightImport {
+		t.Errorf("Confidence = %v, want more than %v", got, weightImport)
+	}
+}
+
+func TestDetectScored_None(t *testing.T) {
+	tmpDir := t.TempDir()
+	main := createFile(t, tmpDir, "main.go", "package main\n\nimport \"net/http\"\n")
+
+	d := (&GinSupplement{}).DetectScored([]string{main, "missing.go"})
+	if d.Confidence != 0 || len(d.Evidence) != 0 {
+		t.Errorf("DetectScored() = %+v, want no evidence", d)
+	}
+	if (&GinSupplement{}).Detect([]string{main}) {
+		t.Error("Detect() = true without evidence")
+	}
+}
+
+func TestSignal_Fold(t *testing.T) {
+	s := signal{text: []string{"django"}, fold: true}
+	if !s.matches("Django==4.2") {
+		t.Error("case-insensitive signal should match Django")
+	}
+	s.fold = false
+	if s.matches("Django==4.2") {
+		t.Error("case-sensitive signal should not match Django")
+	}
+}
@@ -11,6 +11,13 @@ import (
 	"github.com/QTest-hq/qtest/pkg/model"
 )
 
+// djangoSignals suggest a project uses Django or Django REST framework
+var djangoSignals = []signal{
+	{suffixes: []string{"requirements.txt", "pyproject.toml"}, text: []string{"django"}, fold: true, weight: weightDependency, label: "depends on django"},
+	{suffixes: []string{"manage.py"}, text: []string{"django"}, weight: weightProject, label: "Django manage.py"},
+	{suffixes: []string{".py"}, text: []string{"from django", "from rest_framework"}, weight: weightImport, label: "imports django"},
+}
+
 // DjangoSupplement detects Django and Django REST Framework endpoints (Python)
 type DjangoSupplement struct{}
 
@@ -20,47 +27,12 @@ func (s *DjangoSupplement) Name() string {
 
 // Detect checks if the project uses Django/DRF
 func (s *DjangoSupplement) Detect(files []string) bool {
-	for _, f := range files {
-		// Check for requirements.txt with Django
-		if strings.HasSuffix(f, "requirements.txt") {
-			content, err := os.ReadFile(f)
-			if err == nil {
-				str := string(content)
-				if strings.Contains(str, "django") || strings.Contains(str, "Django") ||
-					strings.Contains(str, "djangorestframework") {
-					return true
-				}
-			}
-		}
-		// Check for pyproject.toml with Django
-		if strings.HasSuffix(f, "pyproject.toml") {
-			content, err := os.ReadFile(f)
-			if err == nil {
-				str := string(content)
-				if strings.Contains(str, "django") || strings.Contains(str, "djangorestframework") {
-					return true
-				}
-			}
-		}
-		// Check for manage.py (Django project indicator)
-		if strings.HasSuffix(f, "manage.py") {
-			content, err := os.ReadFile(f)
-			if err == nil && strings.Contains(string(content), "django") {
-				return true
-			}
-		}
-		// Check for Django/DRF imports in Python files
-		if strings.HasSuffix(f, ".py") {
-			content, err := os.ReadFile(f)
-			if err == nil {
-				str := string(content)
-				if strings.Contains(str, "from django") || strings.Contains(str, "from rest_framework") {
-					return true
-				}
-			}
-		}
-	}
-	return false
+	return s.DetectScored(files).Confidence > 0
+}
+
+// DetectScored returns the evidence that the project uses Django/DRF
+func (s *DjangoSupplement) DetectScored(files []string) model.FrameworkDetection {
+	return detectSignals(s.Name(), files, djangoSignals)
 }
 
 // Analyze finds Django endpoints and adds them to the model
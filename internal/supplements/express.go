@@ -11,6 +11,12 @@ import (
 	"github.com/QTest-hq/qtest/pkg/model"
 )
 
+// expressSignals suggest a project uses Express.js
+var expressSignals = []signal{
+	{suffixes: []string{"package.json"}, text: []string{"\"express\""}, weight: weightDependency, label: "package.json depends on express"},
+	{suffixes: []string{".js", ".ts"}, text: []string{"require('express')", "require(\"express\")", "from 'express'", "from \"express\""}, weight: weightImport, label: "imports express"},
+}
+
 // ExpressSupplement detects Express.js routes and middleware
 type ExpressSupplement struct{}
 
@@ -20,29 +26,12 @@ func (s *ExpressSupplement) Name() string {
 
 // Detect checks if the project uses Express.js
 func (s *ExpressSupplement) Detect(files []string) bool {
-	for _, f := range files {
-		// Check for package.json with express dependency
-		if strings.HasSuffix(f, "package.json") {
-			content, err := os.ReadFile(f)
-			if err == nil && strings.Contains(string(content), "\"express\"") {
-				return true
-			}
-		}
-		// Check for express imports in JS/TS files
-		if strings.HasSuffix(f, ".js") || strings.HasSuffix(f, ".ts") {
-			content, err := os.ReadFile(f)
-			if err == nil {
-				contentStr := string(content)
-				if strings.Contains(contentStr, "require('express')") ||
-					strings.Contains(contentStr, "require(\"express\")") ||
-					strings.Contains(contentStr, "from 'express'") ||
-					strings.Contains(contentStr, "from \"express\"") {
-					return true
-				}
-			}
-		}
-	}
-	return false
+	return s.DetectScored(files).Confidence > 0
+}
+
+// DetectScored returns the evidence that the project uses Express.js
+func (s *ExpressSupplement) DetectScored(files []string) model.FrameworkDetection {
+	return detectSignals(s.Name(), files, expressSignals)
 }
 
 // Analyze finds Express routes and adds them to the model
@@ -11,6 +11,12 @@ import (
 	"github.com/QTest-hq/qtest/pkg/model"
 )
 
+// fastapiSignals suggest a project uses FastAPI
+var fastapiSignals = []signal{
+	{suffixes: []string{"requirements.txt", "pyproject.toml"}, text: []string{"fastapi"}, fold: true, weight: weightDependency, label: "depends on fastapi"},
+	{suffixes: []string{".py"}, text: []string{"from fastapi import", "import fastapi"}, weight: weightImport, label: "imports fastapi"},
+}
+
 // FastAPISupplement detects FastAPI routes (Python)
 type FastAPISupplement struct{}
 
@@ -20,27 +26,12 @@ func (s *FastAPISupplement) Name() string {
 
 // Detect checks if the project uses FastAPI
 func (s *FastAPISupplement) Detect(files []string) bool {
-	for _, f := range files {
-		// Check for requirements.txt or pyproject.toml with fastapi
-		if strings.HasSuffix(f, "requirements.txt") || strings.HasSuffix(f, "pyproject.toml") {
-			content, err := os.ReadFile(f)
-			if err == nil && strings.Contains(strings.ToLower(string(content)), "fastapi") {
-				return true
-			}
-		}
-		// Check for fastapi imports in Python files
-		if strings.HasSuffix(f, ".py") {
-			content, err := os.ReadFile(f)
-			if err == nil {
-				contentStr := string(content)
-				if strings.Contains(contentStr, "from fastapi import") ||
-					strings.Contains(contentStr, "import fastapi") {
-					return true
-				}
-			}
-		}
-	}
-	return false
+	return s.DetectScored(files).Confidence > 0
+}
+
+// DetectScored returns the evidence that the project uses FastAPI
+func (s *FastAPISupplement) DetectScored(files []string) model.FrameworkDetection {
+	return detectSignals(s.Name(), files, fastapiSignals)
 }
 
 // Analyze finds FastAPI routes and adds them to the model
@@ -11,6 +11,12 @@ import (
 	"github.com/QTest-hq/qtest/pkg/model"
 )
 
+// ginSignals suggest a project uses Gin
+var ginSignals = []signal{
+	{suffixes: []string{"go.mod"}, text: []string{"github.com/gin-gonic/gin"}, weight: weightDependency, label: "go.mod requires github.com/gin-gonic/gin"},
+	{suffixes: []string{".go"}, text: []string{"\"github.com/gin-gonic/gin\""}, weight: weightImport, label: "imports github.com/gin-gonic/gin"},
+}
+
 // GinSupplement detects Gin routes (Go)
 type GinSupplement struct{}
 
@@ -20,23 +26,12 @@ func (s *GinSupplement) Name() string {
 
 // Detect checks if the project uses Gin
 func (s *GinSupplement) Detect(files []string) bool {
-	for _, f := range files {
-		// Check for go.mod with gin-gonic
-		if strings.HasSuffix(f, "go.mod") {
-			content, err := os.ReadFile(f)
-			if err == nil && strings.Contains(string(content), "github.com/gin-gonic/gin") {
-				return true
-			}
-		}
-		// Check for gin imports in Go files
-		if strings.HasSuffix(f, ".go") {
-			content, err := os.ReadFile(f)
-			if err == nil && strings.Contains(string(content), "\"github.com/gin-gonic/gin\"") {
-				return true
-			}
-		}
-	}
-	return false
+	return s.DetectScored(files).Confidence > 0
+}
+
+// DetectScored returns the evidence that the project uses Gin
+func (s *GinSupplement) DetectScored(files []string) model.FrameworkDetection {
+	return detectSignals(s.Name(), files, ginSignals)
 }
 
 // Analyze finds Gin routes and adds them to the model
@@ -0,0 +1,451 @@
+package supplements
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/QTest-hq/qtest/pkg/model"
+)
+
+// graphQLSignals suggest a project serves GraphQL
+var graphQLSignals = []signal{
+	{suffixes: []string{"package.json"}, text: []string{`"graphql"`, `"@apollo/server"`, `"apollo-server`, `"graphql-yoga"`}, weight: weightDependency, label: "package.json depends on a GraphQL server"},
+	{suffixes: []string{"go.mod"}, text: []string{"github.com/99designs/gqlgen", "github.com/graphql-go/graphql"}, weight: weightDependency, label: "go.mod requires a GraphQL server"},
+	{suffixes: []string{"requirements.txt", "pyproject.toml"}, text: []string{"graphene", "strawberry-graphql", "ariadne"}, fold: true, weight: weightDependency, label: "depends on a GraphQL server"},
+	{suffixes: []string{".js", ".ts"}, text: []string{"@apollo/server", "apollo-server", "graphql-yoga", "express-graphql", "graphql-http", "from 'graphql'", `from "graphql"`, "require('graphql')"}, weight: weightImport, label: "imports a GraphQL server"},
+	{suffixes: []string{".go"}, text: []string{`"github.com/99designs/gqlgen/graphql`, `"github.com/graphql-go/graphql"`}, weight: weightImport, label: "imports a GraphQL server"},
+	{suffixes: []string{".py"}, text: []string{"import graphene", "import strawberry", "from ariadne"}, weight: weightImport, label: "imports a GraphQL server"},
+	{suffixes: []string{".js", ".ts", ".py"}, text: []string{"type Query", "type Mutation", "typeDefs", "@strawberry.type", "graphene.ObjectType"}, weight: weightAnnotation, label: "GraphQL schema definition"},
+}
+
+// graphQLSchemaExts are the extensions of GraphQL schema files
+var graphQLSchemaExts = []string{".graphql", ".graphqls", ".gql"}
+
+var (
+	// Descriptions, strings and comments, removed before parsing SDL
+	sdlBlockString = regexp.MustCompile(`(?s)""".*?"""`)
+	sdlString      = regexp.MustCompile(`"(?:[^"\\\n]|\\.)*"`)
+	sdlComment     = regexp.MustCompile(`#[^\n]*`)
+	sdlDirective   = regexp.MustCompile(`@\w+(?:\s*\([^)]*\))?`)
+
+	// type Query {, extend type Mutation implements Node {, enum Role {
+	sdlDefinition = regexp.MustCompile(`\b(?:extend\s+)?(type|input|interface|enum|schema)\b\s*(\w*)[^{}]*\{`)
+	// users(first: Int = 10, after: String): [User!]!
+	sdlField = regexp.MustCompile(`(\w+)\s*(?:\(([^)]*)\))?\s*:\s*([\[\]\w!]+)`)
+	sdlArg   = regexp.MustCompile(`(\w+)\s*:\s*([\[\]\w!]+)(?:\s*=\s*([^,\s)]+))?`)
+	// query: RootQuery in a schema block
+	sdlRootType = regexp.MustCompile(`\b(query|mutation|subscription)\s*:\s*(\w+)`)
+
+	// SDL embedded in source: JS template literals and Python triple-quoted
+	// strings holding a Query or Mutation type
+	embeddedSDL      = regexp.MustCompile("(?s)`([^`]*)`|\"\"\"(.*?)\"\"\"|'''(.*?)'''")
+	embeddedSDLMark  = regexp.MustCompile(`\b(?:type|extend\s+type)\s+(?:Query|Mutation)\b|\bschema\s*\{`)
+	templateInterpol = regexp.MustCompile(`\$\{[^}]*\}`)
+
+	// Code-first Python schemas: graphene ObjectTypes and strawberry types
+	pyGraphQLClass    = regexp.MustCompile(`^class\s+(\w+)\s*\(([^)]*)\)\s*:|^class\s+(\w+)\s*:`)
+	pyGrapheneField   = regexp.MustCompile(`^\s+(\w+)\s*=\s*(?:graphene\.)?(?:Field|List|NonNull|String|Int|Float|Boolean|ID|\w+\.Field)\s*\(`)
+	pyStrawberryField = regexp.MustCompile(`^\s+(\w+)\s*:\s*[^=]+=\s*strawberry\.(?:field|mutation)\s*\(`)
+	pyDef             = regexp.MustCompile(`^\s+(?:async\s+)?def\s+(\w+)\s*\(`)
+
+	// Where the server is mounted: '/graphql', "/api/graphql", "graphql/"
+	// (Django), or gqlgen's http.Handle("/query", srv)
+	graphQLRoutePattern  = regexp.MustCompile(`(?i)['"](/[\w/.-]*graphql/?|[\w/.-]*graphql/)['"]`)
+	gqlgenHandlerPattern = regexp.MustCompile(`Handle\(\s*"(/[^"]*)"\s*,\s*srv\s*\)`)
+)
+
+// GraphQLSupplement detects GraphQL servers (graphql-js, Apollo, gqlgen,
+// graphene, strawberry) and adds their queries and mutations as endpoints
+// on the server's route
+type GraphQLSupplement struct{}
+
+func (s *GraphQLSupplement) Name() string {
+	return "graphql"
+}
+
+// Detect checks if the project serves GraphQL
+func (s *GraphQLSupplement) Detect(files []string) bool {
+	return s.DetectScored(files).Confidence > 0
+}
+
+// DetectScored returns the evidence that the project serves GraphQL
+func (s *GraphQLSupplement) DetectScored(files []string) model.FrameworkDetection {
+	return detectSignals(s.Name(), files, graphQLSignals)
+}
+
+// graphQLSchema is what the schemas in a project define
+type graphQLSchema struct {
+	roots      map[string]string // root type name -> query, mutation or subscription
+	operations []graphQLField
+	types      map[string]*model.TypeDef
+}
+
+type graphQLField struct {
+	kind string // query or mutation
+	op   model.GraphQLOperation
+	file string
+	line int
+}
+
+// Analyze parses the project's GraphQL schemas and adds an endpoint for
+// every query and mutation
+func (s *GraphQLSupplement) Analyze(m *model.SystemModel) error {
+	schema := &graphQLSchema{
+		roots: map[string]string{"Query": "query", "Mutation": "mutation", "Subscription": "subscription"},
+		types: make(map[string]*model.TypeDef),
+	}
+
+	var sourceFiles []string
+	dirs := make(map[string]bool)
+	for _, mod := range m.Modules {
+		for _, f := range mod.Files {
+			sourceFiles = append(sourceFiles, f)
+			dirs[filepath.Dir(f)] = true
+		}
+	}
+
+	// Schema files next to the sources (gqlgen, Apollo, Ariadne)
+	var dirList []string
+	for dir := range dirs {
+		dirList = append(dirList, dir)
+	}
+	sort.Strings(dirList)
+	for _, dir := range dirList {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if entry.IsDir() || !hasAnySuffix(entry.Name(), graphQLSchemaExts) {
+				continue
+			}
+			path := filepath.Join(dir, entry.Name())
+			if content, err := os.ReadFile(path); err == nil {
+				schema.parseSDL(string(content), path, 1)
+			}
+		}
+	}
+
+	// SDL embedded in source, and code-first Python schemas
+	route := ""
+	for _, f := range sourceFiles {
+		content, err := os.ReadFile(f)
+		if err != nil {
+			continue
+		}
+		text := string(content)
+
+		if strings.HasSuffix(f, ".js") || strings.HasSuffix(f, ".ts") || strings.HasSuffix(f, ".py") {
+			for _, loc := range embeddedSDL.FindAllStringSubmatchIndex(text, -1) {
+				for g := 2; g < len(loc); g += 2 {
+					if loc[g] < 0 {
+						continue
+					}
+					sdl := text[loc[g]:loc[g+1]]
+					if !embeddedSDLMark.MatchString(sdl) {
+						continue
+					}
+					sdl = templateInterpol.ReplaceAllString(sdl, "")
+					schema.parseSDL(sdl, f, 1+strings.Count(text[:loc[g]], "\n"))
+				}
+			}
+		}
+		if strings.HasSuffix(f, ".py") {
+			schema.parsePythonCodeFirst(text, f)
+		}
+
+		if route == "" {
+			if match := gqlgenHandlerPattern.FindStringSubmatch(text); match != nil {
+				route = match[1]
+			} else if match := graphQLRoutePattern.FindStringSubmatch(text); match != nil {
+				route = match[1]
+			}
+		}
+	}
+
+	if route == "" {
+		route = "/graphql"
+	}
+	if !strings.HasPrefix(route, "/") {
+		route = "/" + route
+	}
+
+	// Resolvers found by name: users, resolve_users, queryResolver.Users
+	functions := make(map[string]string)
+	for _, fn := range m.Functions {
+		key := normalizeResolverName(fn.Name)
+		if _, ok := functions[key]; !ok {
+			functions[key] = fn.Name
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, f := range schema.operations {
+		id := fmt.Sprintf("ep:graphql:%s:%s", f.kind, f.op.Field)
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		handler := f.op.Field
+		if name, ok := functions[normalizeResolverName(f.op.Field)]; ok {
+			handler = name
+		}
+		op := f.op
+		op.Kind = f.kind
+		m.Endpoints = append(m.Endpoints, model.Endpoint{
+			ID:           id,
+			Method:       "POST",
+			Path:         route,
+			Handler:      handler,
+			File:         f.file,
+			Line:         f.line,
+			ResponseBody: namedGraphQLType(op.ReturnType),
+			Framework:    "graphql",
+			GraphQL:      &op,
+		})
+	}
+
+	// Object, input and enum types the source doesn't already define
+	existing := make(map[string]bool)
+	for _, t := range m.Types {
+		existing[t.Name] = true
+	}
+	names := make([]string, 0, len(schema.types))
+	for name := range schema.types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if existing[name] || schema.roots[name] != "" {
+			continue
+		}
+		m.Types = append(m.Types, *schema.types[name])
+	}
+
+	return nil
+}
+
+// parseSDL adds the definitions in a GraphQL SDL document. line is the
+// line sdl starts on in file.
+func (s *graphQLSchema) parseSDL(sdl, file string, line int) {
+	// Blank out what isn't definitions, keeping line numbers
+	blank := func(match string) string { return strings.Repeat("\n", strings.Count(match, "\n")) }
+	sdl = sdlBlockString.ReplaceAllStringFunc(sdl, blank)
+	sdl = sdlString.ReplaceAllString(sdl, "")
+	sdl = sdlComment.ReplaceAllString(sdl, "")
+	sdl = sdlDirective.ReplaceAllStringFunc(sdl, blank)
+
+	// Root types renamed by a schema block apply to the whole document
+	for _, loc := range sdlDefinition.FindAllStringSubmatchIndex(sdl, -1) {
+		if sdl[loc[2]:loc[3]] == "schema" {
+			body := sdl[loc[1]:matchingBrace(sdl, loc[1])]
+			for _, root := range sdlRootType.FindAllStringSubmatch(body, -1) {
+				s.roots[root[2]] = root[1]
+			}
+		}
+	}
+
+	for _, loc := range sdlDefinition.FindAllStringSubmatchIndex(sdl, -1) {
+		keyword, name := sdl[loc[2]:loc[3]], sdl[loc[4]:loc[5]]
+		if keyword == "schema" || name == "" {
+			continue
+		}
+		bodyStart := loc[1]
+		body := sdl[bodyStart:matchingBrace(sdl, bodyStart)]
+		bodyLine := line + strings.Count(sdl[:bodyStart], "\n")
+
+		if keyword == "enum" {
+			s.addType(name, model.TypeKindEnum, file, line+strings.Count(sdl[:loc[0]], "\n"), nil)
+			continue
+		}
+
+		var fields []model.Field
+		for _, fm := range sdlField.FindAllStringSubmatchIndex(body, -1) {
+			fieldName, returnType := body[fm[2]:fm[3]], body[fm[6]:fm[7]]
+			kind := s.roots[name]
+			if keyword != "type" || kind == "" {
+				fields = append(fields, model.Field{Name: fieldName, Type: returnType, Exported: true})
+				continue
+			}
+			if kind == "subscription" {
+				continue // needs a websocket, not a POST
+			}
+
+			op := model.GraphQLOperation{Field: fieldName, ReturnType: returnType}
+			if fm[4] >= 0 {
+				for _, am := range sdlArg.FindAllStringSubmatch(body[fm[4]:fm[5]], -1) {
+					op.Args = append(op.Args, model.Parameter{
+						Name:     am[1],
+						Type:     am[2],
+						Optional: !strings.HasSuffix(am[2], "!") || am[3] != "",
+						Default:  am[3],
+					})
+				}
+			}
+			s.operations = append(s.operations, graphQLField{
+				kind: kind,
+				op:   op,
+				file: file,
+				line: bodyLine + strings.Count(body[:fm[0]], "\n"),
+			})
+		}
+
+		if s.roots[name] == "" {
+			kind := model.TypeKindStruct
+			if keyword == "interface" {
+				kind = model.TypeKindInterface
+			}
+			s.addType(name, kind, file, line+strings.Count(sdl[:loc[0]], "\n"), fields)
+		}
+	}
+}
+
+// parsePythonCodeFirst adds the queries and mutations of graphene and
+// strawberry schemas, whose field names are camel-cased as those
+// libraries do by default
+func (s *graphQLSchema) parsePythonCodeFirst(text, file string) {
+	lines := strings.Split(text, "\n")
+	kind := ""
+	strawberryType, strawberryField := false, false
+
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+
+		if !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t") {
+			kind = ""
+			if strings.HasPrefix(trimmed, "@strawberry.type") {
+				strawberryType = true
+				continue
+			}
+			if m := pyGraphQLClass.FindStringSubmatch(trimmed); m != nil {
+				name, bases := m[1], m[2]
+				if name == "" {
+					name = m[3]
+				}
+				if strings.Contains(bases, "ObjectType") || strawberryType {
+					kind = rootKindFor(name)
+				}
+			}
+			strawberryType = false
+			continue
+		}
+		if kind == "" {
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, "@strawberry.field") || strings.HasPrefix(trimmed, "@strawberry.mutation") {
+			strawberryField = true
+			continue
+		}
+
+		field := ""
+		if m := pyGrapheneField.FindStringSubmatch(line); m != nil {
+			field = m[1]
+		} else if m := pyStrawberryField.FindStringSubmatch(line); m != nil {
+			field = m[1]
+		} else if m := pyDef.FindStringSubmatch(line); m != nil && strawberryField {
+			field = m[1]
+		}
+		if !strings.HasPrefix(trimmed, "@") {
+			strawberryField = false
+		}
+		if field == "" || strings.HasPrefix(field, "_") {
+			continue
+		}
+
+		s.operations = append(s.operations, graphQLField{
+			kind: kind,
+			op:   model.GraphQLOperation{Field: camelCase(field)},
+			file: file,
+			line: i + 1,
+		})
+	}
+}
+
+func (s *graphQLSchema) addType(name string, kind model.TypeKind, file string, line int, fields []model.Field) {
+	if t, ok := s.types[name]; ok {
+		t.Fields = append(t.Fields, fields...) // extend type
+		return
+	}
+	s.types[name] = &model.TypeDef{
+		ID:       fmt.Sprintf("type:graphql:%s", name),
+		Name:     name,
+		Kind:     kind,
+		File:     file,
+		Line:     line,
+		Fields:   fields,
+		Exported: true,
+	}
+}
+
+// rootKindFor says whether a code-first class is a query or mutation root
+func rootKindFor(class string) string {
+	switch {
+	case strings.HasSuffix(class, "Query"):
+		return "query"
+	case strings.HasSuffix(class, "Mutation"):
+		return "mutation"
+	}
+	return ""
+}
+
+// matchingBrace returns the index of the } closing the block whose body
+// starts at start, or the end of s
+func matchingBrace(s string, start int) int {
+	depth := 1
+	for i := start; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(s)
+}
+
+// namedGraphQLType strips list and non-null markers: [User!]! is User
+func namedGraphQLType(t string) string {
+	return strings.Trim(t, "[]!")
+}
+
+// normalizeResolverName reduces a field or resolver name to compare them:
+// users, Users, resolve_users and resolveUsers are all "users"
+func normalizeResolverName(name string) string {
+	name = strings.ToLower(strings.ReplaceAll(name, "_", ""))
+	if strings.HasPrefix(name, "resolve") && len(name) > len("resolve") {
+		name = strings.TrimPrefix(name, "resolve")
+	}
+	return name
+}
+
+// camelCase converts a snake_case Python name to camelCase
+func camelCase(name string) string {
+	parts := strings.Split(name, "_")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+func hasAnySuffix(s string, suffixes []string) bool {
+	for _, suffix := range suffixes {
+		if strings.HasSuffix(s, suffix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,478 @@
+package supplements
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/QTest-hq/qtest/pkg/model"
+)
+
+// grpcSignals suggest a project serves gRPC
+var grpcSignals = []signal{
+	{suffixes: []string{"go.mod"}, text: []string{"google.golang.org/grpc"}, weight: weightDependency, label: "go.mod requires grpc-go"},
+	{suffixes: []string{"requirements.txt", "pyproject.toml"}, text: []string{"grpcio"}, fold: true, weight: weightDependency, label: "depends on grpcio"},
+	{suffixes: []string{".go"}, text: []string{`"google.golang.org/grpc"`}, weight: weightImport, label: "imports grpc-go"},
+	{suffixes: []string{".py"}, text: []string{"import grpc", "from grpc import"}, weight: weightImport, label: "imports grpcio"},
+	{suffixes: []string{".go", ".py"}, text: []string{"grpc.NewServer(", "grpc.server("}, weight: weightAnnotation, label: "starts a gRPC server"},
+}
+
+var (
+	// Comments, removed before parsing proto files
+	protoLineComment  = regexp.MustCompile(`//[^\n]*`)
+	protoBlockComment = regexp.MustCompile(`(?s)/\*.*?\*/`)
+
+	protoPackage    = regexp.MustCompile(`\bpackage\s+([\w.]+)\s*;`)
+	protoGoPackage  = regexp.MustCompile(`option\s+go_package\s*=\s*"([^"]+)"`)
+	protoDefinition = regexp.MustCompile(`\b(message|enum|service)\s+(\w+)\s*\{`)
+	// rpc GetUser (GetUserRequest) returns (stream User)
+	protoRPC = regexp.MustCompile(`\brpc\s+(\w+)\s*\(\s*(stream\s+)?([\w.]+)\s*\)\s*returns\s*\(\s*(stream\s+)?([\w.]+)\s*\)`)
+	// repeated string tags = 3;
+	protoField  = regexp.MustCompile(`(?m)^\s*(repeated\s+|optional\s+)?(map\s*<[^>]+>|[\w.]+)\s+(\w+)\s*=\s*\d+`)
+	protoNested = regexp.MustCompile(`\b(?:message|enum)\s+\w+\s*\{`)
+
+	// pb.RegisterUserServiceServer(s, &server{}) in Go, and
+	// pb_grpc.add_UserServiceServicer_to_server(UserServicer(), server) in Python
+	goGRPCRegister = regexp.MustCompile(`(?:\b(\w+)\.)?\bRegister(\w+)Server\(`)
+	pyGRPCRegister = regexp.MustCompile(`(?:\b([\w.]+)\.)?\badd_(\w+)Servicer_to_server\(`)
+
+	goPackageClause = regexp.MustCompile(`(?m)^package\s+(\w+)`)
+	goImportSpec    = regexp.MustCompile(`(?m)^\s*(?:import\s+)?(\w+\s+)?"([^"]+)"`)
+	pyImportModule  = regexp.MustCompile(`(?m)^[ \t]*import\s+([\w.]+)(?:\s+as\s+(\w+))?`)
+	pyFromImport    = regexp.MustCompile(`(?m)^[ \t]*from\s+([\w.]+)\s+import\s+([\w, \t]+)`)
+)
+
+// GRPCSupplement detects gRPC servers (grpc-go, grpcio) and adds the unary
+// methods of the services they register, parsed from the project's proto
+// files, as endpoints
+type GRPCSupplement struct{}
+
+func (s *GRPCSupplement) Name() string {
+	return "grpc"
+}
+
+// Detect checks if the project serves gRPC
+func (s *GRPCSupplement) Detect(files []string) bool {
+	return s.DetectScored(files).Confidence > 0
+}
+
+// DetectScored returns the evidence that the project serves gRPC
+func (s *GRPCSupplement) DetectScored(files []string) model.FrameworkDetection {
+	return detectSignals(s.Name(), files, grpcSignals)
+}
+
+// protoService is a service defined in a proto file
+type protoService struct {
+	pkg       string
+	goPackage string
+	name      string
+	file      string
+	rpcs      []protoRPCDef
+}
+
+type protoRPCDef struct {
+	name, request, response string
+	streaming               bool
+	line                    int
+}
+
+// grpcRegistration is where source registers a service implementation
+type grpcRegistration struct {
+	stubs  string // Go import path or Python module of the generated code
+	server string // implementation as written, e.g. &server{}
+	module string // Go package or Python module doing the registering
+}
+
+// Analyze parses the project's proto files and adds an endpoint for every
+// unary method of a service the source registers
+func (s *GRPCSupplement) Analyze(m *model.SystemModel) error {
+	var sourceFiles []string
+	for _, mod := range m.Modules {
+		sourceFiles = append(sourceFiles, mod.Files...)
+	}
+	if len(sourceFiles) == 0 {
+		return nil
+	}
+
+	// Registrations in source, by service name
+	registered := make(map[string]grpcRegistration)
+	for _, f := range sourceFiles {
+		content, err := os.ReadFile(f)
+		if err != nil {
+			continue
+		}
+		text := string(content)
+		switch {
+		case strings.HasSuffix(f, ".go") && !strings.HasSuffix(f, ".pb.go"):
+			findGoGRPCRegistrations(text, registered)
+		case strings.HasSuffix(f, ".py") && !strings.HasSuffix(f, "_pb2_grpc.py"):
+			findPythonGRPCRegistrations(text, pythonModuleName(f), registered)
+		}
+	}
+	if len(registered) == 0 {
+		return nil
+	}
+
+	var services []protoService
+	types := make(map[string]*model.TypeDef)
+	for _, path := range findProtoFiles(projectRoot(commonDir(sourceFiles))) {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		services = append(services, parseProto(string(content), path, types)...)
+	}
+
+	// Implementations found by method name, preferring methods
+	handlers := make(map[string]string)
+	for _, fn := range m.Functions {
+		if _, ok := handlers[fn.Name]; !ok || fn.Class != "" {
+			handlers[fn.Name] = fn.ID
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, svc := range services {
+		reg, ok := registered[svc.name]
+		if !ok {
+			continue // a client of someone else's service
+		}
+		if reg.stubs == "" && svc.goPackage != "" {
+			reg.stubs = strings.SplitN(svc.goPackage, ";", 2)[0]
+		}
+
+		for _, rpc := range svc.rpcs {
+			if rpc.streaming {
+				continue // needs a stream, not a single call
+			}
+			method := &model.GRPCMethod{
+				Package:      svc.pkg,
+				Service:      svc.name,
+				Method:       rpc.name,
+				RequestType:  rpc.request,
+				ResponseType: rpc.response,
+				Stubs:        reg.stubs,
+				Server:       reg.server,
+				ServerModule: reg.module,
+			}
+			path := fmt.Sprintf("/%s/%s", method.FullService(), rpc.name)
+			id := "ep:grpc:" + strings.TrimPrefix(path, "/")
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+
+			handler := rpc.name
+			if fnID, ok := handlers[rpc.name]; ok {
+				handler = fnID
+			}
+			m.Endpoints = append(m.Endpoints, model.Endpoint{
+				ID:           id,
+				Method:       "POST",
+				Path:         path,
+				Handler:      handler,
+				File:         svc.file,
+				Line:         rpc.line,
+				RequestBody:  rpc.request,
+				ResponseBody: rpc.response,
+				Framework:    "grpc",
+				GRPC:         method,
+			})
+		}
+	}
+
+	// Messages and enums the source doesn't already define
+	existing := make(map[string]bool)
+	for _, t := range m.Types {
+		existing[t.Name] = true
+	}
+	names := make([]string, 0, len(types))
+	for name := range types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !existing[name] {
+			m.Types = append(m.Types, *types[name])
+		}
+	}
+
+	return nil
+}
+
+// findGoGRPCRegistrations records the services Go source registers and
+// the package of the generated code it registers them through
+func findGoGRPCRegistrations(text string, registered map[string]grpcRegistration) {
+	locs := goGRPCRegister.FindAllStringSubmatchIndex(text, -1)
+	if len(locs) == 0 {
+		return
+	}
+
+	imports := make(map[string]string)
+	for _, im := range goImportSpec.FindAllStringSubmatch(text, -1) {
+		alias := strings.TrimSpace(im[1])
+		if alias == "" {
+			alias = im[2][strings.LastIndex(im[2], "/")+1:]
+		}
+		imports[alias] = im[2]
+	}
+	pkg := ""
+	if match := goPackageClause.FindStringSubmatch(text); match != nil {
+		pkg = match[1]
+	}
+
+	for _, loc := range locs {
+		// Skip the generated func RegisterXServer definition
+		if start := strings.LastIndex(text[:loc[0]], "\n") + 1; strings.HasPrefix(strings.TrimSpace(text[start:loc[0]]), "func") {
+			continue
+		}
+		args := callArgs(text, loc[1])
+		if len(args) < 2 {
+			continue
+		}
+		reg := grpcRegistration{server: args[1], module: pkg}
+		if loc[2] >= 0 {
+			reg.stubs = imports[text[loc[2]:loc[3]]]
+		}
+		registered[text[loc[4]:loc[5]]] = reg
+	}
+}
+
+// findPythonGRPCRegistrations records the servicers Python source adds to
+// a server and the *_pb2_grpc module it adds them through
+func findPythonGRPCRegistrations(text, module string, registered map[string]grpcRegistration) {
+	locs := pyGRPCRegister.FindAllStringSubmatchIndex(text, -1)
+	if len(locs) == 0 {
+		return
+	}
+
+	// Local names of imported modules, and where imported functions live
+	modules := make(map[string]string)
+	for _, im := range pyImportModule.FindAllStringSubmatch(text, -1) {
+		local := im[1]
+		if im[2] != "" {
+			local = im[2]
+		}
+		modules[local] = im[1]
+	}
+	for _, im := range pyFromImport.FindAllStringSubmatch(text, -1) {
+		for _, name := range strings.Split(im[2], ",") {
+			fields := strings.Fields(name)
+			if len(fields) == 0 {
+				continue
+			}
+			if strings.HasPrefix(fields[0], "add_") {
+				modules[fields[0]] = im[1] // from x_pb2_grpc import add_...
+			} else {
+				modules[fields[len(fields)-1]] = im[1] + "." + fields[0]
+			}
+		}
+	}
+
+	for _, loc := range locs {
+		if start := strings.LastIndex(text[:loc[0]], "\n") + 1; strings.HasPrefix(strings.TrimSpace(text[start:loc[0]]), "def") {
+			continue
+		}
+		args := callArgs(text, loc[1])
+		if len(args) < 1 {
+			continue
+		}
+		name := text[loc[4]:loc[5]]
+		reg := grpcRegistration{server: args[0], module: module}
+		if loc[2] >= 0 {
+			qualifier := text[loc[2]:loc[3]]
+			reg.stubs = qualifier
+			if full, ok := modules[qualifier]; ok {
+				reg.stubs = full
+			}
+		} else {
+			reg.stubs = modules["add_"+name+"Servicer_to_server"]
+		}
+		registered[name] = reg
+	}
+}
+
+// callArgs splits the arguments of the call whose ( ends at start
+func callArgs(text string, start int) []string {
+	var args []string
+	depth, argStart := 0, start
+	for i := start; i < len(text); i++ {
+		switch text[i] {
+		case '(', '{', '[':
+			depth++
+		case ')', '}', ']':
+			if depth == 0 {
+				if arg := strings.TrimSpace(text[argStart:i]); arg != "" {
+					args = append(args, arg)
+				}
+				return args
+			}
+			depth--
+		case ',':
+			if depth == 0 {
+				args = append(args, strings.TrimSpace(text[argStart:i]))
+				argStart = i + 1
+			}
+		}
+	}
+	return args
+}
+
+// parseProto returns the services in a proto file and adds its top-level
+// messages and enums to types
+func parseProto(proto, file string, types map[string]*model.TypeDef) []protoService {
+	blank := func(match string) string { return strings.Repeat("\n", strings.Count(match, "\n")) }
+	proto = protoBlockComment.ReplaceAllStringFunc(proto, blank)
+	proto = protoLineComment.ReplaceAllString(proto, "")
+
+	pkg, goPackage := "", ""
+	if match := protoPackage.FindStringSubmatch(proto); match != nil {
+		pkg = match[1]
+	}
+	if match := protoGoPackage.FindStringSubmatch(proto); match != nil {
+		goPackage = match[1]
+	}
+
+	var services []protoService
+	end := 0
+	for _, loc := range protoDefinition.FindAllStringSubmatchIndex(proto, -1) {
+		if loc[0] < end {
+			continue // nested in the previous definition
+		}
+		keyword, name := proto[loc[2]:loc[3]], proto[loc[4]:loc[5]]
+		end = matchingBrace(proto, loc[1])
+		body := proto[loc[1]:end]
+		line := 1 + strings.Count(proto[:loc[0]], "\n")
+
+		switch keyword {
+		case "service":
+			svc := protoService{pkg: pkg, goPackage: goPackage, name: name, file: file}
+			for _, rm := range protoRPC.FindAllStringSubmatchIndex(body, -1) {
+				svc.rpcs = append(svc.rpcs, protoRPCDef{
+					name:      body[rm[2]:rm[3]],
+					request:   protoTypeName(body[rm[6]:rm[7]]),
+					response:  protoTypeName(body[rm[10]:rm[11]]),
+					streaming: rm[4] >= 0 || rm[8] >= 0,
+					line:      line + strings.Count(proto[loc[0]:loc[1]+rm[0]], "\n"),
+				})
+			}
+			services = append(services, svc)
+
+		case "enum":
+			addProtoType(types, name, model.TypeKindEnum, file, line, nil)
+
+		case "message":
+			// Nested definitions are their own types, not fields
+			for {
+				nested := protoNested.FindStringIndex(body)
+				if nested == nil {
+					break
+				}
+				body = body[:nested[0]] + body[min(matchingBrace(body, nested[1])+1, len(body)):]
+			}
+			var fields []model.Field
+			for _, fm := range protoField.FindAllStringSubmatch(body, -1) {
+				fieldType := protoTypeName(fm[2])
+				if strings.HasPrefix(fm[1], "repeated") {
+					fieldType += "[]"
+				}
+				fields = append(fields, model.Field{Name: fm[3], Type: fieldType, Exported: true})
+			}
+			addProtoType(types, name, model.TypeKindStruct, file, line, fields)
+		}
+	}
+	return services
+}
+
+func addProtoType(types map[string]*model.TypeDef, name string, kind model.TypeKind, file string, line int, fields []model.Field) {
+	if _, ok := types[name]; ok {
+		return
+	}
+	types[name] = &model.TypeDef{
+		ID:       fmt.Sprintf("type:proto:%s", name),
+		Name:     name,
+		Kind:     kind,
+		File:     file,
+		Line:     line,
+		Fields:   fields,
+		Exported: true,
+	}
+}
+
+// protoTypeName drops a type's package: google.protobuf.Empty is Empty
+func protoTypeName(t string) string {
+	if strings.HasPrefix(t, "map") {
+		return strings.Join(strings.Fields(t), "")
+	}
+	return t[strings.LastIndex(t, ".")+1:]
+}
+
+// maxProtoScanFiles caps how many files the proto search visits
+const maxProtoScanFiles = 5000
+
+// findProtoFiles returns the .proto files under root, skipping vendored
+// and hidden directories
+func findProtoFiles(root string) []string {
+	var files []string
+	visited := 0
+	filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if visited++; visited > maxProtoScanFiles {
+			return filepath.SkipAll
+		}
+		if info.IsDir() {
+			name := info.Name()
+			if path != root && (strings.HasPrefix(name, ".") || name == "node_modules" || name == "vendor" ||
+				name == "third_party" || name == "venv" || name == "__pycache__") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasSuffix(path, ".proto") {
+			files = append(files, path)
+		}
+		return nil
+	})
+	return files
+}
+
+// commonDir returns the deepest directory containing all files
+func commonDir(files []string) string {
+	dir := filepath.Dir(files[0])
+	for _, f := range files[1:] {
+		for dir != "." && dir != string(filepath.Separator) && !strings.HasPrefix(f, dir+string(filepath.Separator)) {
+			dir = filepath.Dir(dir)
+		}
+	}
+	return dir
+}
+
+// projectRootMarkers are files found at the root of a project
+var projectRootMarkers = []string{"go.mod", ".git", "pyproject.toml", "setup.py", "requirements.txt", "package.json"}
+
+// projectRoot returns the nearest directory at or above dir holding a
+// project manifest or .git, as protos often sit beside the source rather
+// than in it. It returns dir when there's none.
+func projectRoot(dir string) string {
+	for d := dir; ; d = filepath.Dir(d) {
+		for _, marker := range projectRootMarkers {
+			if _, err := os.Stat(filepath.Join(d, marker)); err == nil {
+				return d
+			}
+		}
+		if parent := filepath.Dir(d); parent == d {
+			return dir
+		}
+	}
+}
+
+// pythonModuleName is the module a Python file is imported as from its
+// own directory
+func pythonModuleName(file string) string {
+	return strings.TrimSuffix(filepath.Base(file), ".py")
+}
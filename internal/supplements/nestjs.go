@@ -11,6 +11,13 @@ import (
 	"github.com/QTest-hq/qtest/pkg/model"
 )
 
+// nestjsSignals suggest a project uses NestJS
+var nestjsSignals = []signal{
+	{suffixes: []string{"package.json"}, text: []string{"@nestjs/"}, weight: weightDependency, label: "package.json depends on @nestjs"},
+	{suffixes: []string{".ts"}, text: []string{"@nestjs/common"}, weight: weightImport, label: "imports @nestjs/common"},
+	{suffixes: []string{".ts"}, text: []string{"@Controller"}, weight: weightAnnotation, label: "@Controller decorator"},
+}
+
 // NestJSSupplement detects NestJS routes (TypeScript)
 type NestJSSupplement struct{}
 
@@ -20,27 +27,12 @@ func (s *NestJSSupplement) Name() string {
 
 // Detect checks if the project uses NestJS
 func (s *NestJSSupplement) Detect(files []string) bool {
-	for _, f := range files {
-		// Check for package.json with @nestjs
-		if strings.HasSuffix(f, "package.json") {
-			content, err := os.ReadFile(f)
-			if err == nil && strings.Contains(string(content), "@nestjs/") {
-				return true
-			}
-		}
-		// Check for NestJS decorators in TS files
-		if strings.HasSuffix(f, ".ts") {
-			content, err := os.ReadFile(f)
-			if err == nil {
-				str := string(content)
-				if strings.Contains(str, "@Controller") ||
-					strings.Contains(str, "@nestjs/common") {
-					return true
-				}
-			}
-		}
-	}
-	return false
+	return s.DetectScored(files).Confidence > 0
+}
+
+// DetectScored returns the evidence that the project uses NestJS
+func (s *NestJSSupplement) DetectScored(files []string) model.FrameworkDetection {
+	return detectSignals(s.Name(), files, nestjsSignals)
 }
 
 // Analyze finds NestJS routes and adds them to the model
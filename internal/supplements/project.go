@@ -0,0 +1,47 @@
+package supplements
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/QTest-hq/qtest/internal/config"
+	"github.com/QTest-hq/qtest/pkg/model"
+)
+
+// ProjectOptions returns the model build options for the repository at
+// dir: the built-in supplements, the ones declared in its .qtest.yaml, and
+// its OpenAPI spec, registered last so it fills in what the others found.
+// Supplements named in disable_supplements are disabled. Settings that
+// can't be loaded are left out and reported in the error, so callers can
+// warn and build with the rest.
+func ProjectOptions(dir string) (model.BuildOptions, error) {
+	registry := NewRegistry()
+	opts := model.BuildOptions{Supplements: registry.GetAll()}
+
+	cfg, err := config.LoadProjectConfig(dir)
+	if err != nil {
+		return opts, fmt.Errorf("failed to load project config: %w", err)
+	}
+
+	var errs []error
+	if err := registry.RegisterRules(cfg.Supplements); err != nil {
+		errs = append(errs, fmt.Errorf("failed to register custom supplements: %w", err))
+	}
+	opts.Supplements = registry.GetAll()
+	opts.Disabled = cfg.DisableSupplements
+
+	if cfg.OpenAPI != "" {
+		specPath := cfg.OpenAPI
+		if !filepath.IsAbs(specPath) {
+			specPath = filepath.Join(dir, specPath)
+		}
+		if importer, err := model.LoadOpenAPI(specPath); err != nil {
+			errs = append(errs, fmt.Errorf("failed to load OpenAPI spec: %w", err))
+		} else {
+			opts.Supplements = append(opts.Supplements, importer)
+		}
+	}
+
+	return opts, errors.Join(errs...)
+}
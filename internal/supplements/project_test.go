@@ -0,0 +1,91 @@
+package supplements
+
+import (
+	"context"
+	"testing"
+
+	"github.com/QTest-hq/qtest/internal/parser"
+	"github.com/QTest-hq/qtest/pkg/model"
+)
+
+const ginRoutes = `package main
+
+import "github.com/gin-gonic/gin"
+
+func main() {
+	r := gin.Default()
+	r.GET("/users/:id", getUser)
+	r.POST("/users", createUser)
+	r.Run(":8080")
+}
+`
+
+func TestProjectOptions_BuildSystemModel(t *testing.T) {
+	dir := t.TempDir()
+	createFile(t, dir, "main.go", ginRoutes)
+
+	build := func() *model.SystemModel {
+		opts, err := ProjectOptions(dir)
+		if err != nil {
+			t.Fatalf("ProjectOptions() error = %v", err)
+		}
+		m, err := model.BuildSystemModelFromParser(context.Background(), parser.NewParser(), dir, "app", "main", "", opts)
+		if err != nil {
+			t.Fatalf("BuildSystemModelFromParser() error = %v", err)
+		}
+		return m
+	}
+
+	if m := build(); len(m.Endpoints) != 2 {
+		t.Errorf("endpoints = %d, want the 2 gin routes", len(m.Endpoints))
+	}
+
+	// Custom supplements and the OpenAPI spec in .qtest.yaml are included,
+	// and disabled supplements don't run
+	createFile(t, dir, "openapi.yaml", `openapi: 3.0.0
+info: {title: app, version: "1"}
+paths:
+  /health:
+    get:
+      responses:
+        "200": {description: ok}
+`)
+	createFile(t, dir, ".qtest.yaml", `supplements:
+  - name: acme-router
+    files: ["**/*.go"]
+    detect: ['"acme\.io/router"']
+    routes:
+      - pattern: 'r\.Fetch\("(?P<path>[^"]+)"'
+openapi: openapi.yaml
+disable_supplements: [gin]
+`)
+	opts, err := ProjectOptions(dir)
+	if err != nil {
+		t.Fatalf("ProjectOptions() error = %v", err)
+	}
+	var names []string
+	for _, s := range opts.Supplements {
+		names = append(names, s.Name())
+	}
+	if len(names) < 2 || names[len(names)-2] != "acme-router" || len(opts.Disabled) != 1 || opts.Disabled[0] != "gin" {
+		t.Errorf("supplements = %v, disabled = %v", names, opts.Disabled)
+	}
+
+	m := build()
+	if len(m.Endpoints) != 1 || m.Endpoints[0].Path != "/health" {
+		t.Errorf("endpoints = %+v, want only /health from the OpenAPI spec", m.Endpoints)
+	}
+}
+
+func TestProjectOptions_InvalidConfig(t *testing.T) {
+	dir := t.TempDir()
+	createFile(t, dir, ".qtest.yaml", "openapi: missing.yaml\n")
+
+	opts, err := ProjectOptions(dir)
+	if err == nil {
+		t.Fatal("ProjectOptions() should report the missing OpenAPI spec")
+	}
+	if len(opts.Supplements) != len(NewRegistry().GetAll()) {
+		t.Errorf("supplements = %d, want the built-in ones", len(opts.Supplements))
+	}
+}
@@ -6,6 +6,9 @@
 package supplements
 
 import (
+	"fmt"
+
+	"github.com/QTest-hq/qtest/internal/config"
 	"github.com/QTest-hq/qtest/pkg/model"
 )
 
@@ -27,6 +30,9 @@ func NewRegistry() *Registry {
 	r.Register(&SpringBootSupplement{})
 	r.Register(&DjangoSupplement{})
 	r.Register(&NestJSSupplement{})
+	r.Register(&AspNetCoreSupplement{})
+	r.Register(&GraphQLSupplement{})
+	r.Register(&GRPCSupplement{})
 
 	return r
 }
@@ -36,6 +42,23 @@ func (r *Registry) Register(s model.Supplement) {
 	r.supplements = append(r.supplements, s)
 }
 
+// RegisterRules adds the supplements declared in a project's .qtest.yaml
+func (r *Registry) RegisterRules(rules []config.SupplementConfig) error {
+	for _, rule := range rules {
+		for _, s := range r.supplements {
+			if s.Name() == rule.Name {
+				return fmt.Errorf("supplement %s is already registered", rule.Name)
+			}
+		}
+		s, err := NewRuleSupplement(rule)
+		if err != nil {
+			return err
+		}
+		r.Register(s)
+	}
+	return nil
+}
+
 // GetAll returns all registered supplements
 func (r *Registry) GetAll() []model.Supplement {
 	return r.supplements
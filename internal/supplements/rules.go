@@ -0,0 +1,252 @@
+package supplements
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/QTest-hq/qtest/internal/config"
+	"github.com/QTest-hq/qtest/pkg/model"
+)
+
+// weightDeclared is the weight of a framework the user declared without
+// detect patterns: they told us it's there
+const weightDeclared = 1.0
+
+// pathParamPattern matches path parameters in the common styles: :id, {id}
+// and <id> (optionally typed, e.g. <int:id>)
+var pathParamPattern = regexp.MustCompile(`:(\w+)|\{(\w+)[^}]*\}|<(?:\w+:)?(\w+)>`)
+
+// RuleSupplement is a supplement declared in .qtest.yaml: file globs, regexes
+// that show the framework is used and regexes that find its routes
+type RuleSupplement struct {
+	name   string
+	files  []string
+	detect []*regexp.Regexp
+	routes []routeRule
+}
+
+type routeRule struct {
+	pattern *regexp.Regexp
+	method  string
+	methods map[string]string // lower-cased captured text -> HTTP method
+}
+
+// NewRuleSupplement compiles a supplement declared in the project config
+func NewRuleSupplement(cfg config.SupplementConfig) (*RuleSupplement, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	s := &RuleSupplement{name: cfg.Name, files: cfg.Files}
+	for _, pattern := range cfg.Detect {
+		s.detect = append(s.detect, regexp.MustCompile(pattern))
+	}
+	for _, r := range cfg.Routes {
+		rule := routeRule{
+			pattern: regexp.MustCompile(r.Pattern),
+			method:  strings.ToUpper(r.Method),
+			methods: make(map[string]string),
+		}
+		if rule.method == "" {
+			rule.method = "GET"
+		}
+		for from, to := range r.Methods {
+			rule.methods[strings.ToLower(from)] = strings.ToUpper(to)
+		}
+		s.routes = append(s.routes, rule)
+	}
+	return s, nil
+}
+
+func (s *RuleSupplement) Name() string {
+	return s.name
+}
+
+// Detect checks if the project uses the declared framework
+func (s *RuleSupplement) Detect(files []string) bool {
+	return s.DetectScored(files).Confidence > 0
+}
+
+// DetectScored returns the evidence that the project uses the declared
+// framework: lines matching its detect patterns, or the files its globs
+// match if it has none
+func (s *RuleSupplement) DetectScored(files []string) model.FrameworkDetection {
+	var evidence []model.DetectionEvidence
+	for _, f := range s.matchingFiles(files) {
+		if len(s.detect) == 0 {
+			evidence = append(evidence, model.DetectionEvidence{
+				File:   f,
+				Signal: "declared in .qtest.yaml",
+				Weight: weightDeclared,
+			})
+			break
+		}
+		evidence = append(evidence, s.scanDetect(f)...)
+	}
+	return model.NewFrameworkDetection(s.name, evidence)
+}
+
+// Analyze finds routes matching the rules and adds them to the model
+func (s *RuleSupplement) Analyze(m *model.SystemModel) error {
+	var all []string
+	for _, mod := range m.Modules {
+		all = append(all, mod.Files...)
+	}
+
+	for _, filePath := range s.matchingFiles(all) {
+		file, err := os.Open(filePath)
+		if err != nil {
+			continue
+		}
+
+		scanner := bufio.NewScanner(file)
+		lineNum := 0
+		for scanner.Scan() {
+			lineNum++
+			line := scanner.Text()
+			for _, rule := range s.routes {
+				if endpoint, ok := rule.match(line); ok {
+					endpoint.ID = fmt.Sprintf("ep:%s:%s:%d", filepath.Base(filePath), endpoint.Method, lineNum)
+					endpoint.File = filePath
+					endpoint.Line = lineNum
+					endpoint.Framework = s.name
+					m.Endpoints = append(m.Endpoints, endpoint)
+					break
+				}
+			}
+		}
+		file.Close()
+	}
+
+	return nil
+}
+
+// match applies a route rule to a line
+func (r routeRule) match(line string) (model.Endpoint, bool) {
+	matches := r.pattern.FindStringSubmatch(line)
+	if matches == nil {
+		return model.Endpoint{}, false
+	}
+	group := func(name string) string {
+		if i := r.pattern.SubexpIndex(name); i >= 0 {
+			return matches[i]
+		}
+		return ""
+	}
+
+	path := group("path")
+	if path == "" {
+		return model.Endpoint{}, false
+	}
+
+	method := r.method
+	if captured := group("method"); captured != "" {
+		if mapped, ok := r.methods[strings.ToLower(captured)]; ok {
+			method = mapped
+		} else {
+			method = strings.ToUpper(captured)
+		}
+	}
+
+	handler := group("handler")
+	if handler == "" {
+		handler = "anonymous"
+	}
+
+	endpoint := model.Endpoint{
+		Method:  method,
+		Path:    path,
+		Handler: handler,
+	}
+	for _, pm := range pathParamPattern.FindAllStringSubmatch(path, -1) {
+		for _, name := range pm[1:] {
+			if name != "" {
+				endpoint.PathParams = append(endpoint.PathParams, name)
+			}
+		}
+	}
+	return endpoint, true
+}
+
+// matchingFiles returns the files matching any of the supplement's globs
+func (s *RuleSupplement) matchingFiles(files []string) []string {
+	var matched []string
+	for _, f := range files {
+		for _, glob := range s.files {
+			if matchGlob(glob, f) {
+				matched = append(matched, f)
+				break
+			}
+		}
+	}
+	return matched
+}
+
+// scanDetect returns the lines of a file matching the detect patterns
+func (s *RuleSupplement) scanDetect(path string) []model.DetectionEvidence {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	var evidence []model.DetectionEvidence
+	found := make([]bool, len(s.detect))
+	scanner := bufio.NewScanner(file)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		for i, re := range s.detect {
+			if found[i] || !re.MatchString(scanner.Text()) {
+				continue
+			}
+			found[i] = true
+			evidence = append(evidence, model.DetectionEvidence{
+				File:   path,
+				Line:   lineNum,
+				Signal: "matches " + re.String(),
+				Weight: weightDependency,
+			})
+		}
+	}
+	return evidence
+}
+
+// matchGlob matches a slash-separated glob against the end of a path, so
+// "routes/*.go" matches any routes directory. "**" matches any number of
+// directories.
+func matchGlob(glob, path string) bool {
+	pattern := strings.Split(filepath.ToSlash(glob), "/")
+	parts := strings.Split(filepath.ToSlash(path), "/")
+	for start := 0; start < len(parts); start++ {
+		if matchSegments(pattern, parts[start:]) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchSegments(pattern, parts []string) bool {
+	if len(pattern) == 0 {
+		return len(parts) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(parts); i++ {
+			if matchSegments(pattern[1:], parts[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(parts) == 0 {
+		return false
+	}
+	if ok, _ := filepath.Match(pattern[0], parts[0]); !ok {
+		return false
+	}
+	return matchSegments(pattern[1:], parts[1:])
+}
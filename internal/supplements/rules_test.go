@@ -0,0 +1,154 @@
+package supplements
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/QTest-hq/qtest/internal/config"
+	"github.com/QTest-hq/qtest/pkg/model"
+)
+
+func acmeRules() config.SupplementConfig {
+	return config.SupplementConfig{
+		Name:   "acme-router",
+		Files:  []string{"**/*.go"},
+		Detect: []string{`"acme\.io/router"`},
+		Routes: []config.RouteRule{
+			{
+				Pattern: `r\.(?P<method>Fetch|Store|Drop)\("(?P<path>[^"]+)",\s*(?P<handler>[\w.]+)`,
+				Methods: map[string]string{"fetch": "GET", "Store": "post"},
+			},
+			{Pattern: `@health\("(?P<path>[^"]+)"\)`},
+		},
+	}
+}
+
+func TestRuleSupplement_Analyze(t *testing.T) {
+	tmpDir := t.TempDir()
+	routes := createFile(t, tmpDir, "routes.go", `package api
+
+import "acme.io/router"
+
+func Register(r *router.Router) {
+	r.Fetch("/users/{id}", handlers.GetUser)
+	r.Store("/users", handlers.CreateUser)
+	r.Drop("/users/:id", handlers.DeleteUser)
+	// @health("/healthz")
+}
+`)
+	other := createFile(t, tmpDir, "app.js", `r.Fetch("/not/go", handler)`)
+
+	s, err := NewRuleSupplement(acmeRules())
+	if err != nil {
+		t.Fatalf("NewRuleSupplement() error = %v", err)
+	}
+	if s.Name() != "acme-router" {
+		t.Errorf("Name() = %s", s.Name())
+	}
+
+	m := &model.SystemModel{Modules: []model.Module{{Files: []string{routes, other}}}}
+	if err := s.Analyze(m); err != nil {
+		t.Fatalf("Analyze() error = %v", err)
+	}
+
+	want := []struct {
+		method, path, handler string
+		params                []string
+	}{
+		{"GET", "/users/{id}", "handlers.GetUser", []string{"id"}},
+		{"POST", "/users", "handlers.CreateUser", nil},
+		{"DROP", "/users/:id", "handlers.DeleteUser", []string{"id"}},
+		{"GET", "/healthz", "anonymous", nil},
+	}
+	if len(m.Endpoints) != len(want) {
+		t.Fatalf("len(Endpoints) = %d, want %d: %+v", len(m.Endpoints), len(want), m.Endpoints)
+	}
+	for i, w := range want {
+		ep := m.Endpoints[i]
+		if ep.Method != w.method || ep.Path != w.path || ep.Handler != w.handler {
+			t.Errorf("Endpoints[%d] = %s %s -> %s, want %s %s -> %s", i, ep.Method, ep.Path, ep.Handler, w.method, w.path, w.handler)
+		}
+		if len(ep.PathParams) != len(w.params) || (len(w.params) > 0 && ep.PathParams[0] != w.params[0]) {
+			t.Errorf("Endpoints[%d].PathParams = %v, want %v", i, ep.PathParams, w.params)
+		}
+		if ep.Framework != "acme-router" || ep.File != routes {
+			t.Errorf("Endpoints[%d] framework/file = %s/%s", i, ep.Framework, ep.File)
+		}
+	}
+	if m.Endpoints[0].Line != 6 {
+		t.Errorf("Endpoints[0].Line = %d, want 6", m.Endpoints[0].Line)
+	}
+}
+
+func TestRuleSupplement_DetectScored(t *testing.T) {
+	tmpDir := t.TempDir()
+	routes := createFile(t, tmpDir, "routes.go", "package api\n\nimport \"acme.io/router\"\n")
+	plain := createFile(t, tmpDir, "main.go", "package main\n")
+
+	s, _ := NewRuleSupplement(acmeRules())
+	d := s.DetectScored([]string{plain, routes})
+	if d.Confidence != weightDependency || len(d.Evidence) != 1 || d.Evidence[0].Line != 3 {
+		t.Errorf("DetectScored() = %+v, want one match at line 3", d)
+	}
+	if s.Detect([]string{plain}) {
+		t.Error("Detect() = true without a detect match")
+	}
+
+	// Without detect patterns, the declaration itself is the evidence
+	rules := acmeRules()
+	rules.Detect = nil
+	s, _ = NewRuleSupplement(rules)
+	if d := s.DetectScored([]string{plain}); d.Confidence != 1 {
+		t.Errorf("Confidence = %v, want 1 for a declared framework", d.Confidence)
+	}
+	if s.Detect([]string{filepath.Join(tmpDir, "app.py")}) {
+		t.Error("Detect() = true with no files matching the globs")
+	}
+}
+
+func TestNewRuleSupplement_Invalid(t *testing.T) {
+	rules := acmeRules()
+	rules.Routes[0].Pattern = `r\.Fetch\("[^"]+"`
+	if _, err := NewRuleSupplement(rules); err == nil {
+		t.Error("expected error for a route pattern without a path group")
+	}
+}
+
+func TestRegistry_RegisterRules(t *testing.T) {
+	r := NewRegistry()
+	if err := r.RegisterRules([]config.SupplementConfig{acmeRules()}); err != nil {
+		t.Fatalf("RegisterRules() error = %v", err)
+	}
+	if len(r.GetAll()) != 10 {
+		t.Errorf("len(GetAll()) = %d, want 10", len(r.GetAll()))
+	}
+
+	// Names must be unique, including against built-ins
+	dup := acmeRules()
+	dup.Name = "gin"
+	if err := r.RegisterRules([]config.SupplementConfig{dup}); err == nil {
+		t.Error("expected error for a name clash with a built-in supplement")
+	}
+}
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		glob string
+		path string
+		want bool
+	}{
+		{"*.go", "/repo/api/routes.go", true},
+		{"**/*.go", "/repo/api/routes.go", true},
+		{"api/*.go", "/repo/api/routes.go", true},
+		{"api/*.go", "/repo/api/v1/routes.go", false},
+		{"api/**/*.go", "/repo/api/v1/routes.go", true},
+		{"api/**/*.go", "/repo/api/routes.go", true},
+		{"routes/*.ts", "/repo/api/routes.go", false},
+		{"*.go", "/repo/app.js", false},
+	}
+	for _, tt := range tests {
+		if got := matchGlob(tt.glob, tt.path); got != tt.want {
+			t.Errorf("matchGlob(%q, %q) = %v, want %v", tt.glob, tt.path, got, tt.want)
+		}
+	}
+}
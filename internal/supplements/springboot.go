@@ -11,6 +11,13 @@ import (
 	"github.com/QTest-hq/qtest/pkg/model"
 )
 
+// springBootSignals suggest a project uses Spring Boot
+var springBootSignals = []signal{
+	{suffixes: []string{"pom.xml", "build.gradle", "build.gradle.kts"}, text: []string{"spring-boot"}, weight: weightDependency, label: "build depends on spring-boot"},
+	{suffixes: []string{".java"}, text: []string{"@RestController"}, weight: weightImport, label: "@RestController annotation"},
+	{suffixes: []string{".java"}, text: []string{"@Controller", "@RequestMapping"}, weight: weightAnnotation, label: "Spring MVC annotation"},
+}
+
 // SpringBootSupplement detects Spring Boot REST endpoints (Java)
 type SpringBootSupplement struct{}
 
@@ -20,34 +27,12 @@ func (s *SpringBootSupplement) Name() string {
 
 // Detect checks if the project uses Spring Boot
 func (s *SpringBootSupplement) Detect(files []string) bool {
-	for _, f := range files {
-		// Check for pom.xml with spring-boot
-		if strings.HasSuffix(f, "pom.xml") {
-			content, err := os.ReadFile(f)
-			if err == nil && strings.Contains(string(content), "spring-boot") {
-				return true
-			}
-		}
-		// Check for build.gradle with spring-boot
-		if strings.HasSuffix(f, "build.gradle") || strings.HasSuffix(f, "build.gradle.kts") {
-			content, err := os.ReadFile(f)
-			if err == nil && strings.Contains(string(content), "spring-boot") {
-				return true
-			}
-		}
-		// Check for Spring annotations in Java files
-		if strings.HasSuffix(f, ".java") {
-			content, err := os.ReadFile(f)
-			if err == nil {
-				if strings.Contains(string(content), "@RestController") ||
-					strings.Contains(string(content), "@Controller") ||
-					strings.Contains(string(content), "@RequestMapping") {
-					return true
-				}
-			}
-		}
-	}
-	return false
+	return s.DetectScored(files).Confidence > 0
+}
+
+// DetectScored returns the evidence that the project uses Spring Boot
+func (s *SpringBootSupplement) DetectScored(files []string) model.FrameworkDetection {
+	return detectSignals(s.Name(), files, springBootSignals)
 }
 
 // Analyze finds Spring Boot REST endpoints and adds them to the model
@@ -3,6 +3,7 @@ package supplements
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/QTest-hq/qtest/pkg/model"
@@ -19,7 +20,7 @@ func TestNewRegistry(t *testing.T) {
 	}
 
 	supplements := r.GetAll()
-	expectedCount := 6 // Express, FastAPI, Gin, SpringBoot, Django, NestJS
+	expectedCount := 9 // Express, FastAPI, Gin, SpringBoot, Django, NestJS, ASP.NET Core, GraphQL, gRPC
 
 	if len(supplements) != expectedCount {
 		t.Errorf("expected %d supplements, got %d", expectedCount, len(supplements))
@@ -30,7 +31,7 @@ func TestRegistry_GetAll(t *testing.T) {
 	r := NewRegistry()
 	supplements := r.GetAll()
 
-	expectedNames := []string{"express", "fastapi", "gin", "springboot", "django", "nestjs"}
+	expectedNames := []string{"express", "fastapi", "gin", "springboot", "django", "nestjs", "aspnetcore", "graphql", "grpc"}
 
 	for _, expName := range expectedNames {
 		found := false
@@ -700,6 +701,338 @@ public class UserController {
 	}
 }
 
+// =============================================================================
+// ASP.NET Core Supplement Tests
+// =============================================================================
+
+func TestAspNetCoreSupplement_Name(t *testing.T) {
+	s := &AspNetCoreSupplement{}
+	if s.Name() != "aspnetcore" {
+		t.Errorf("Name() = %s, want aspnetcore", s.Name())
+	}
+}
+
+func TestAspNetCoreSupplement_Detect(t *testing.T) {
+	s := &AspNetCoreSupplement{}
+	tmpDir := createTempDir(t)
+	defer os.RemoveAll(tmpDir)
+
+	tests := []struct {
+		name     string
+		filename string
+		content  string
+		want     bool
+	}{
+		{
+			name:     "csproj with web sdk",
+			filename: "Api.csproj",
+			content:  `<Project Sdk="Microsoft.NET.Sdk.Web"></Project>`,
+			want:     true,
+		},
+		{
+			name:     "cs file with ApiController",
+			filename: "OrdersController.cs",
+			content:  "[ApiController]\npublic class OrdersController : ControllerBase {}",
+			want:     true,
+		},
+		{
+			name:     "cs file with HttpGet",
+			filename: "HealthController.cs",
+			content:  "[HttpGet]\npublic string Ping() => \"pong\";",
+			want:     true,
+		},
+		{
+			name:     "cs file without aspnet",
+			filename: "Calculator.cs",
+			content:  "public class Calculator { public int Add(int a, int b) => a + b; }",
+			want:     false,
+		},
+		{
+			name:     "csproj console app",
+			filename: "Tool.csproj",
+			content:  `<Project Sdk="Microsoft.NET.Sdk"></Project>`,
+			want:     false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := createFile(t, tmpDir, tt.filename, tt.content)
+			got := s.Detect([]string{file})
+			if got != tt.want {
+				t.Errorf("Detect() = %v, want %v", got, tt.want)
+			}
+			os.Remove(file)
+		})
+	}
+}
+
+func TestAspNetCoreSupplement_Analyze(t *testing.T) {
+	s := &AspNetCoreSupplement{}
+	tmpDir := createTempDir(t)
+	defer os.RemoveAll(tmpDir)
+
+	controllerCode := `
+using Microsoft.AspNetCore.Mvc;
+
+namespace Shop.Api.Controllers;
+
+[ApiController]
+[Route("api/[controller]")]
+public class OrdersController : ControllerBase
+{
+    public OrdersController(IOrderRepository orders) { }
+
+    [HttpGet]
+    public async Task<ActionResult<IEnumerable<Order>>> List() => Ok();
+
+    [HttpGet("{id:int}")]
+    public ActionResult<Order> Get(int id) => Ok();
+
+    [HttpPost]
+    [ProducesResponseType(201)]
+    public IActionResult Create([FromBody] Order order) => Created("", order);
+
+    [HttpPut("{id}"), Authorize]
+    public IActionResult Update(int id, Order order) => NoContent();
+
+    [HttpDelete("/admin/orders/{id}")]
+    public IActionResult Delete(int id) => NoContent();
+
+    [Route("[action]")]
+    [HttpGet]
+    public IActionResult Summary() => Ok();
+
+    private void Helper() { }
+}
+`
+	programCode := `
+var app = WebApplication.CreateBuilder(args).Build();
+app.MapGet("/health", () => "ok");
+app.Run();
+`
+	controllerFile := createFile(t, tmpDir, "OrdersController.cs", controllerCode)
+	programFile := createFile(t, tmpDir, "Program.cs", programCode)
+
+	m := &model.SystemModel{
+		Modules: []model.Module{
+			{Files: []string{controllerFile, programFile}},
+		},
+	}
+
+	if err := s.Analyze(m); err != nil {
+		t.Fatalf("Analyze() error: %v", err)
+	}
+
+	want := []struct{ method, path, handler string }{
+		{"GET", "/api/Orders", "OrdersController.List"},
+		{"GET", "/api/Orders/{id}", "OrdersController.Get"},
+		{"POST", "/api/Orders", "OrdersController.Create"},
+		{"PUT", "/api/Orders/{id}", "OrdersController.Update"},
+		{"DELETE", "/admin/orders/{id}", "OrdersController.Delete"},
+		{"GET", "/api/Orders/Summary", "OrdersController.Summary"},
+		{"GET", "/health", "anonymous"},
+	}
+	if len(m.Endpoints) != len(want) {
+		t.Fatalf("Analyze() found %d endpoints, want %d: %+v", len(m.Endpoints), len(want), m.Endpoints)
+	}
+	for i, w := range want {
+		ep := m.Endpoints[i]
+		if ep.Method != w.method || ep.Path != w.path || ep.Handler != w.handler {
+			t.Errorf("endpoint %d = %s %s (%s), want %s %s (%s)", i, ep.Method, ep.Path, ep.Handler, w.method, w.path, w.handler)
+		}
+		if ep.Framework != "aspnetcore" {
+			t.Errorf("endpoint framework should be aspnetcore, got %s", ep.Framework)
+		}
+	}
+	if params := m.Endpoints[1].PathParams; len(params) != 1 || params[0] != "id" {
+		t.Errorf("PathParams = %v, want [id]", params)
+	}
+}
+
+// =============================================================================
+// GraphQL Supplement Tests
+// =============================================================================
+
+func TestGraphQLSupplement_Name(t *testing.T) {
+	s := &GraphQLSupplement{}
+	if s.Name() != "graphql" {
+		t.Errorf("Name() = %s, want graphql", s.Name())
+	}
+}
+
+func TestGraphQLSupplement_Detect(t *testing.T) {
+	s := &GraphQLSupplement{}
+	tmpDir := createTempDir(t)
+	defer os.RemoveAll(tmpDir)
+
+	tests := []struct {
+		name     string
+		filename string
+		content  string
+		want     bool
+	}{
+		{"apollo server", "index.js", "const { ApolloServer } = require('@apollo/server');", true},
+		{"gqlgen", "server.go", `import "github.com/99designs/gqlgen/graphql/handler"`, true},
+		{"graphene", "schema.py", "import graphene\n\nclass Query(graphene.ObjectType):\n    pass", true},
+		{"plain express", "app.js", "const express = require('express');", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := createFile(t, tmpDir, tt.filename, tt.content)
+			got := s.Detect([]string{file})
+			if got != tt.want {
+				t.Errorf("Detect() = %v, want %v", got, tt.want)
+			}
+			os.Remove(file)
+		})
+	}
+}
+
+func TestGraphQLSupplement_Analyze_SchemaFile(t *testing.T) {
+	s := &GraphQLSupplement{}
+	tmpDir := createTempDir(t)
+	defer os.RemoveAll(tmpDir)
+
+	schema := `
+"""A person using the app"""
+type User {
+  id: ID!
+  name: String @deprecated(reason: "use fullName")
+  role: Role
+}
+
+enum Role { ADMIN MEMBER }
+
+type Query {
+  # All users, newest first
+  users(first: Int = 10, after: String): [User!]!
+  user(id: ID!): User
+}
+
+type Mutation {
+  createUser(
+    name: String!
+    role: Role
+  ): User!
+}
+
+type Subscription {
+  userCreated: User
+}
+`
+	server := `
+srv := handler.NewDefaultServer(generated.NewExecutableSchema(cfg))
+http.Handle("/query", srv)
+`
+	resolver := `
+func (r *queryResolver) Users(ctx context.Context, first *int, after *string) ([]*model.User, error) {
+	return nil, nil
+}
+`
+	createFile(t, tmpDir, "schema.graphqls", schema)
+	serverFile := createFile(t, tmpDir, "server.go", server)
+	resolverFile := createFile(t, tmpDir, "schema.resolvers.go", resolver)
+
+	m := &model.SystemModel{
+		Modules:   []model.Module{{Files: []string{serverFile, resolverFile}}},
+		Functions: []model.Function{{ID: "fn:Users", Name: "Users"}},
+	}
+	if err := s.Analyze(m); err != nil {
+		t.Fatalf("Analyze() error: %v", err)
+	}
+
+	want := []struct{ kind, field, handler string }{
+		{"query", "users", "Users"},
+		{"query", "user", "user"},
+		{"mutation", "createUser", "createUser"},
+	}
+	if len(m.Endpoints) != len(want) {
+		t.Fatalf("Analyze() found %d endpoints, want %d: %+v", len(m.Endpoints), len(want), m.Endpoints)
+	}
+	for i, w := range want {
+		ep := m.Endpoints[i]
+		if ep.Method != "POST" || ep.Path != "/query" || ep.Framework != "graphql" {
+			t.Errorf("endpoint %d = %s %s (%s), want POST /query (graphql)", i, ep.Method, ep.Path, ep.Framework)
+		}
+		if ep.GraphQL == nil || ep.GraphQL.Kind != w.kind || ep.GraphQL.Field != w.field || ep.Handler != w.handler {
+			t.Errorf("endpoint %d = %+v (handler %s), want %s %s (handler %s)", i, ep.GraphQL, ep.Handler, w.kind, w.field, w.handler)
+		}
+	}
+
+	users := m.Endpoints[0].GraphQL
+	if users.ReturnType != "[User!]!" || m.Endpoints[0].ResponseBody != "User" {
+		t.Errorf("users returns %q (response body %q), want [User!]! (User)", users.ReturnType, m.Endpoints[0].ResponseBody)
+	}
+	if len(users.Args) != 2 || users.Args[0].Name != "first" || users.Args[0].Default != "10" || !users.Args[0].Optional {
+		t.Errorf("users args = %+v, want optional first = 10 and after", users.Args)
+	}
+	if args := m.Endpoints[2].GraphQL.Args; len(args) != 2 || args[0].Optional {
+		t.Errorf("createUser args = %+v, want required name and role", args)
+	}
+	if m.Endpoints[1].Line != 14 {
+		t.Errorf("user line = %d, want 14", m.Endpoints[1].Line)
+	}
+
+	types := make(map[string]model.TypeDef)
+	for _, td := range m.Types {
+		types[td.Name] = td
+	}
+	if len(types) != 2 || len(types["User"].Fields) != 3 || types["Role"].Kind != model.TypeKindEnum {
+		t.Errorf("Types = %+v, want User with 3 fields and the Role enum", m.Types)
+	}
+}
+
+func TestGraphQLSupplement_Analyze_Embedded(t *testing.T) {
+	s := &GraphQLSupplement{}
+	tmpDir := createTempDir(t)
+	defer os.RemoveAll(tmpDir)
+
+	apollo := "const typeDefs = gql`\n  type Book { title: String }\n  type Query {\n    books: [Book]\n  }\n`;\n" +
+		"const help = `see the docs`;\n" +
+		"app.use('/api/graphql', expressMiddleware(server));\n"
+	graphene := `import graphene
+
+class Query(graphene.ObjectType):
+    all_users = graphene.List(UserType)
+
+    def resolve_all_users(self, info):
+        return []
+
+class CreateUser(graphene.Mutation):
+    ok = graphene.Boolean()
+
+class Mutation(graphene.ObjectType):
+    create_user = CreateUser.Field()
+`
+	apolloFile := createFile(t, tmpDir, "server.js", apollo)
+	grapheneFile := createFile(t, tmpDir, "schema.py", graphene)
+
+	m := &model.SystemModel{
+		Modules: []model.Module{{Files: []string{apolloFile, grapheneFile}}},
+	}
+	if err := s.Analyze(m); err != nil {
+		t.Fatalf("Analyze() error: %v", err)
+	}
+
+	var got []string
+	for _, ep := range m.Endpoints {
+		got = append(got, ep.Path+" "+ep.Describe())
+	}
+	want := []string{
+		"/api/graphql GraphQL query books",
+		"/api/graphql GraphQL query allUsers",
+		"/api/graphql GraphQL mutation createUser",
+	}
+	if strings.Join(got, ", ") != strings.Join(want, ", ") {
+		t.Errorf("endpoints = %v, want %v", got, want)
+	}
+	if m.Endpoints[0].Line != 4 {
+		t.Errorf("books line = %d, want 4", m.Endpoints[0].Line)
+	}
+}
+
 // =============================================================================
 // Django Supplement Tests
 // =============================================================================
@@ -1033,6 +1366,194 @@ export class UsersController {
 	}
 }
 
+// =============================================================================
+// gRPC Supplement Tests
+// =============================================================================
+
+const usersProto = `syntax = "proto3";
+
+package users.v1;
+
+option go_package = "example.com/app/gen/users/v1;usersv1";
+
+/* Users of the app */
+message User {
+  string id = 1;
+  string name = 2;
+  repeated string tags = 3;
+  Role role = 4;
+  message Address { string city = 1; }
+  Address address = 5;
+}
+
+enum Role {
+  ROLE_UNSPECIFIED = 0;
+  ROLE_ADMIN = 1;
+}
+
+message GetUserRequest { string id = 1; }
+
+service UserService {
+  // Fetches one user
+  rpc GetUser(GetUserRequest) returns (User);
+  rpc CreateUser (User) returns (User) {}
+  rpc WatchUsers(GetUserRequest) returns (stream User);
+}
+
+service AuditService {
+  rpc Log(User) returns (User);
+}
+`
+
+func TestGRPCSupplement_Detect(t *testing.T) {
+	s := &GRPCSupplement{}
+	tmpDir := createTempDir(t)
+	defer os.RemoveAll(tmpDir)
+
+	tests := []struct {
+		name     string
+		filename string
+		content  string
+		want     bool
+	}{
+		{"grpc-go", "main.go", "import \"google.golang.org/grpc\"\n\ns := grpc.NewServer()", true},
+		{"grpcio", "server.py", "import grpc\nserver = grpc.server(pool)", true},
+		{"plain http", "main.go", `import "net/http"`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := createFile(t, tmpDir, tt.filename, tt.content)
+			if got := s.Detect([]string{file}); got != tt.want {
+				t.Errorf("Detect() = %v, want %v", got, tt.want)
+			}
+			os.Remove(file)
+		})
+	}
+}
+
+func TestGRPCSupplement_Analyze_Go(t *testing.T) {
+	s := &GRPCSupplement{}
+	tmpDir := createTempDir(t)
+	defer os.RemoveAll(tmpDir)
+
+	os.MkdirAll(filepath.Join(tmpDir, "proto", "users", "v1"), 0755)
+	createFile(t, tmpDir, "proto/users/v1/users.proto", usersProto)
+	createFile(t, tmpDir, "go.mod", "module example.com/app\n")
+	os.MkdirAll(filepath.Join(tmpDir, "cmd"), 0755)
+	mainFile := createFile(t, tmpDir, "cmd/main.go", `package main
+
+import (
+	"google.golang.org/grpc"
+
+	usersv1 "example.com/app/gen/users/v1"
+)
+
+func main() {
+	s := grpc.NewServer()
+	usersv1.RegisterUserServiceServer(s, &userServer{store: newStore()})
+	s.Serve(lis)
+}
+`)
+
+	m := &model.SystemModel{
+		Modules: []model.Module{{Files: []string{mainFile}}},
+		Functions: []model.Function{
+			{ID: "fn:client:GetUser", Name: "GetUser"},
+			{ID: "fn:userServer:GetUser", Name: "GetUser", Class: "userServer"},
+		},
+	}
+	if err := s.Analyze(m); err != nil {
+		t.Fatalf("Analyze() error: %v", err)
+	}
+
+	// Streaming methods and unregistered services are left out
+	if len(m.Endpoints) != 2 {
+		t.Fatalf("Analyze() found %d endpoints, want 2: %+v", len(m.Endpoints), m.Endpoints)
+	}
+	get := m.Endpoints[0]
+	if get.Method != "POST" || get.Path != "/users.v1.UserService/GetUser" || get.Framework != "grpc" {
+		t.Errorf("endpoint = %s %s (%s), want POST /users.v1.UserService/GetUser (grpc)", get.Method, get.Path, get.Framework)
+	}
+	if get.Handler != "fn:userServer:GetUser" || get.RequestBody != "GetUserRequest" || get.ResponseBody != "User" || get.Line != 26 {
+		t.Errorf("endpoint = %+v", get)
+	}
+	want := model.GRPCMethod{
+		Package: "users.v1", Service: "UserService", Method: "GetUser", RequestType: "GetUserRequest", ResponseType: "User",
+		Stubs: "example.com/app/gen/users/v1", Server: "&userServer{store: newStore()}", ServerModule: "main",
+	}
+	if get.GRPC == nil || *get.GRPC != want {
+		t.Errorf("GRPC = %+v, want %+v", get.GRPC, want)
+	}
+	if get.Describe() != "gRPC users.v1.UserService/GetUser" {
+		t.Errorf("Describe() = %q", get.Describe())
+	}
+
+	types := make(map[string]model.TypeDef)
+	for _, td := range m.Types {
+		types[td.Name] = td
+	}
+	user := types["User"]
+	if len(user.Fields) != 5 || user.Fields[2].Type != "string[]" || user.Fields[4].Name != "address" {
+		t.Errorf("User = %+v, want 5 fields with tags string[] and no nested Address fields", user)
+	}
+	if types["Role"].Kind != model.TypeKindEnum || types["GetUserRequest"].ID != "type:proto:GetUserRequest" {
+		t.Errorf("Types = %+v", m.Types)
+	}
+}
+
+func TestGRPCSupplement_Analyze_Python(t *testing.T) {
+	s := &GRPCSupplement{}
+	tmpDir := createTempDir(t)
+	defer os.RemoveAll(tmpDir)
+
+	createFile(t, tmpDir, "users.proto", usersProto)
+	serverFile := createFile(t, tmpDir, "server.py", `import grpc
+from concurrent import futures
+from gen import users_pb2_grpc
+
+
+class UserServicer(users_pb2_grpc.UserServiceServicer):
+    def GetUser(self, request, context):
+        return users_pb2.User(id=request.id)
+
+
+def serve():
+    server = grpc.server(futures.ThreadPoolExecutor(max_workers=4))
+    users_pb2_grpc.add_UserServiceServicer_to_server(UserServicer(), server)
+    server.start()
+`)
+
+	m := &model.SystemModel{Modules: []model.Module{{Files: []string{serverFile}}}}
+	if err := s.Analyze(m); err != nil {
+		t.Fatalf("Analyze() error: %v", err)
+	}
+	if len(m.Endpoints) != 2 {
+		t.Fatalf("Analyze() found %d endpoints, want 2", len(m.Endpoints))
+	}
+	g := m.Endpoints[0].GRPC
+	if g.Stubs != "gen.users_pb2_grpc" || g.Server != "UserServicer()" || g.ServerModule != "server" {
+		t.Errorf("GRPC = %+v, want stubs gen.users_pb2_grpc served by server.UserServicer()", g)
+	}
+}
+
+func TestGRPCSupplement_Analyze_NoRegistration(t *testing.T) {
+	s := &GRPCSupplement{}
+	tmpDir := createTempDir(t)
+	defer os.RemoveAll(tmpDir)
+
+	createFile(t, tmpDir, "users.proto", usersProto)
+	clientFile := createFile(t, tmpDir, "client.go", "package main\n\nfunc main() { usersv1.NewUserServiceClient(conn) }\n")
+
+	m := &model.SystemModel{Modules: []model.Module{{Files: []string{clientFile}}}}
+	if err := s.Analyze(m); err != nil {
+		t.Fatalf("Analyze() error: %v", err)
+	}
+	if len(m.Endpoints) != 0 || len(m.Types) != 0 {
+		t.Errorf("a client-only project got endpoints %+v and types %+v", m.Endpoints, m.Types)
+	}
+}
+
 // =============================================================================
 // Helper Functions
 // =============================================================================
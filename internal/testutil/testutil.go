@@ -149,6 +149,8 @@ func setupSchema(ctx context.Context, pool *pgxpool.Pool) error {
 		target_function TEXT,
 		dsl JSONB NOT NULL,
 		generated_code TEXT,
+		test_file TEXT,
+		content_hash TEXT,
 		framework TEXT,
 		status TEXT NOT NULL DEFAULT 'pending',
 		rejection_reason TEXT,
@@ -0,0 +1,117 @@
+package validator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// CachedResult is a stored validation outcome
+type CachedResult struct {
+	Key      string    `json:"key"`
+	TestFile string    `json:"test_file"`
+	Status   string    `json:"status"`
+	Output   string    `json:"output,omitempty"`
+	CachedAt time.Time `json:"cached_at"`
+}
+
+// ResultCache stores validation results keyed by the content hashes of a
+// test file and its source file. A test whose file and source are unchanged
+// since its last successful validation doesn't need to run again. Entries
+// are one file each, so concurrent workers can share a directory.
+type ResultCache struct {
+	dir string
+}
+
+// NewResultCache creates a cache in dir. An empty dir disables caching.
+func NewResultCache(dir string) *ResultCache {
+	return &ResultCache{dir: dir}
+}
+
+// Key returns the cache key for a test and its source file. A key that
+// didn't cover the source would replay results after it changed, so a
+// source that's unknown or can't be read is an error, and the test isn't
+// cached.
+func (c *ResultCache) Key(testFile, sourceFile string) (string, error) {
+	if sourceFile == "" {
+		return "", fmt.Errorf("no source file for %s", testFile)
+	}
+	testHash, err := hashContent(testFile)
+	if err != nil {
+		return "", err
+	}
+	sourceHash, err := hashContent(sourceFile)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(testHash + ":" + sourceHash))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Get returns the cached result for a key
+func (c *ResultCache) Get(key string) (*CachedResult, bool) {
+	if c.dir == "" || key == "" {
+		return nil, false
+	}
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return nil, false
+	}
+	var res CachedResult
+	if err := json.Unmarshal(data, &res); err != nil || res.Key != key {
+		return nil, false
+	}
+	return &res, true
+}
+
+// Put stores a result under its key
+func (c *ResultCache) Put(res CachedResult) error {
+	if c.dir == "" || res.Key == "" {
+		return nil
+	}
+	dir := filepath.Dir(c.path(res.Key))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	if res.CachedAt.IsZero() {
+		res.CachedAt = time.Now()
+	}
+	data, err := json.Marshal(res)
+	if err != nil {
+		return fmt.Errorf("failed to marshal cached result: %w", err)
+	}
+
+	// Write then rename so readers never see a partial entry
+	tmp, err := os.CreateTemp(dir, res.Key+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write cached result: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write cached result: %w", err)
+	}
+	tmp.Close()
+	if err := os.Rename(tmp.Name(), c.path(res.Key)); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write cached result: %w", err)
+	}
+	return nil
+}
+
+func (c *ResultCache) path(key string) string {
+	return filepath.Join(c.dir, key[:2], key+".json")
+}
+
+func hashContent(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
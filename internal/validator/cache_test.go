@@ -0,0 +1,65 @@
+package validator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResultCache(t *testing.T) {
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "calc_test.go")
+	sourceFile := filepath.Join(dir, "calc.go")
+	os.WriteFile(testFile, []byte("package calc // test v1"), 0644)
+	os.WriteFile(sourceFile, []byte("package calc // source v1"), 0644)
+
+	cache := NewResultCache(filepath.Join(dir, "cache"))
+	key, err := cache.Key(testFile, sourceFile)
+	if err != nil {
+		t.Fatalf("Key() error = %v", err)
+	}
+	if _, ok := cache.Get(key); ok {
+		t.Fatal("empty cache returned a hit")
+	}
+
+	if err := cache.Put(CachedResult{Key: key, TestFile: testFile, Status: "validated"}); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if got, ok := cache.Get(key); !ok || got.Status != "validated" {
+		t.Errorf("Get() = %+v, %v; want validated hit", got, ok)
+	}
+
+	// Changing the source invalidates the entry
+	os.WriteFile(sourceFile, []byte("package calc // source v2"), 0644)
+	changed, _ := cache.Key(testFile, sourceFile)
+	if changed == key {
+		t.Error("source change should change the key")
+	}
+	if _, ok := cache.Get(changed); ok {
+		t.Error("stale entry returned after source change")
+	}
+}
+
+func TestResultCache_Disabled(t *testing.T) {
+	cache := NewResultCache("")
+	if err := cache.Put(CachedResult{Key: "abc", Status: "validated"}); err != nil {
+		t.Errorf("Put() on disabled cache error = %v", err)
+	}
+	if _, ok := cache.Get("abc"); ok {
+		t.Error("disabled cache returned a hit")
+	}
+}
+
+func TestResultCache_MissingSource(t *testing.T) {
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "calc_qtest_test.go")
+	os.WriteFile(testFile, []byte("package calc"), 0644)
+
+	cache := NewResultCache(filepath.Join(dir, "cache"))
+	if key, err := cache.Key(testFile, filepath.Join(dir, "calc_qtest.go")); err == nil {
+		t.Errorf("Key() with a missing source = %q, want an error", key)
+	}
+	if key, err := cache.Key(testFile, ""); err == nil {
+		t.Errorf("Key() without a source = %q, want an error", key)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
@@ -13,6 +14,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/QTest-hq/qtest/internal/platform"
 	"github.com/rs/zerolog/log"
 )
 
@@ -187,8 +189,7 @@ func (c *CoverageChecker) parsePythonCoverageJSON(jsonFile string, result *Cover
 		} `json:"files"`
 	}
 
-	cmd := exec.Command("cat", jsonFile)
-	output, err := cmd.Output()
+	output, err := os.ReadFile(jsonFile)
 	if err != nil {
 		return
 	}
@@ -217,7 +218,7 @@ func (c *CoverageChecker) runJSCoverage(ctx context.Context, testFile string) (*
 	testDir := filepath.Dir(testFile)
 
 	// Run jest with coverage
-	cmd := exec.CommandContext(ctx, "npx", "jest", testFile, "--coverage", "--coverageReporters=json-summary")
+	cmd := platform.Command(ctx, "npx", "jest", filepath.ToSlash(testFile), "--coverage", "--coverageReporters=json-summary")
 	cmd.Dir = testDir
 
 	output, _ := cmd.CombinedOutput()
@@ -247,8 +248,7 @@ func (c *CoverageChecker) parseJSCoverageJSON(jsonFile string, result *CoverageR
 		} `json:"functions"`
 	}
 
-	cmd := exec.Command("cat", jsonFile)
-	output, err := cmd.Output()
+	output, err := os.ReadFile(jsonFile)
 	if err != nil {
 		return
 	}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"strings"
 
+	"github.com/QTest-hq/qtest/internal/adapters"
 	"github.com/QTest-hq/qtest/internal/llm"
 	"github.com/rs/zerolog/log"
 )
@@ -45,6 +46,9 @@ func (f *Fixer) FixTest(ctx context.Context, testFile string, result *TestResult
 	currentCode := string(code)
 	fixResult := &FixResult{Attempts: 0}
 
+	// Fixes of a QTest file stay QTest's
+	prov, _ := adapters.ParseProvenance(currentCode)
+
 	for attempt := 1; attempt <= f.maxRetries; attempt++ {
 		fixResult.Attempts = attempt
 
@@ -57,6 +61,10 @@ func (f *Fixer) FixTest(ctx context.Context, testFile string, result *TestResult
 			continue
 		}
 
+		if prov != nil {
+			fixedCode = adapters.StampProvenance(fixedCode, testFile, *prov)
+		}
+
 		// Write fixed code
 		if err := os.WriteFile(testFile, []byte(fixedCode), 0644); err != nil {
 			return nil, fmt.Errorf("failed to write fixed test: %w", err)
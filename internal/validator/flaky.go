@@ -0,0 +1,202 @@
+package validator
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// FlakeReport is the outcome of running a suite several times. A test that
+// passed in some runs and failed in others is flaky; one that always fails
+// is just failing.
+type FlakeReport struct {
+	Language string        `json:"language"`
+	Runs     int           `json:"runs"`
+	Tests    int           `json:"tests"`
+	Flaky    []FlakyTest   `json:"flaky"`
+	Rate     float64       `json:"flake_rate"` // percent of tests
+	Duration time.Duration `json:"duration"`
+}
+
+// FlakyTest is a test with inconsistent results
+type FlakyTest struct {
+	Name   string `json:"name"`
+	Passed int    `json:"passed"`
+	Failed int    `json:"failed"`
+}
+
+// DetectFlakes runs a project's whole suite runs times and compares each
+// test's results across the runs
+func DetectFlakes(ctx context.Context, workDir, language string, runs int) (*FlakeReport, error) {
+	if runs < 2 {
+		return nil, fmt.Errorf("flake detection needs at least 2 runs, got %d", runs)
+	}
+
+	start := time.Now()
+	outcomes := make([]map[string]bool, 0, runs)
+	for i := 0; i < runs; i++ {
+		run, err := runSuiteOnce(ctx, workDir, language)
+		if err != nil {
+			return nil, fmt.Errorf("failed to run tests (run %d): %w", i+1, err)
+		}
+		if len(run) == 0 {
+			return nil, fmt.Errorf("no test results in run %d", i+1)
+		}
+		log.Debug().Int("run", i+1).Int("tests", len(run)).Msg("flake detection run complete")
+		outcomes = append(outcomes, run)
+	}
+
+	report := TallyFlakes(outcomes)
+	report.Language = language
+	report.Duration = time.Since(start)
+	return report, nil
+}
+
+// TallyFlakes compares the results of repeated runs, each a map of test
+// name to whether it passed. A test missing from a run isn't counted for it.
+func TallyFlakes(runs []map[string]bool) *FlakeReport {
+	passed := make(map[string]int)
+	failed := make(map[string]int)
+	for _, run := range runs {
+		for name, ok := range run {
+			if ok {
+				passed[name]++
+			} else {
+				failed[name]++
+			}
+		}
+	}
+
+	report := &FlakeReport{Runs: len(runs), Flaky: []FlakyTest{}}
+	seen := make(map[string]bool)
+	for _, counts := range []map[string]int{passed, failed} {
+		for name := range counts {
+			seen[name] = true
+		}
+	}
+	report.Tests = len(seen)
+	for name := range seen {
+		if passed[name] > 0 && failed[name] > 0 {
+			report.Flaky = append(report.Flaky, FlakyTest{Name: name, Passed: passed[name], Failed: failed[name]})
+		}
+	}
+	sort.Slice(report.Flaky, func(i, j int) bool { return report.Flaky[i].Name < report.Flaky[j].Name })
+	if report.Tests > 0 {
+		report.Rate = 100 * float64(len(report.Flaky)) / float64(report.Tests)
+	}
+	return report
+}
+
+// runSuiteOnce runs the project's tests without caching and returns each
+// test's result
+func runSuiteOnce(ctx context.Context, workDir, language string) (map[string]bool, error) {
+	switch language {
+	case "go":
+		output, _, err := executeCommand(ctx, workDir, []string{"go", "test", "-json", "-count=1", "./..."})
+		if err != nil {
+			return nil, err
+		}
+		return parseGoTestJSON(output), nil
+	case "python":
+		output, _, err := executeCommand(ctx, workDir, []string{"python", "-m", "pytest", "-v", "-p", "no:cacheprovider"})
+		if err != nil {
+			return nil, err
+		}
+		return parsePytestOutcomes(output), nil
+	case "javascript", "typescript":
+		resultsDir, err := os.MkdirTemp("", "qtest-flaky-*")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create temp dir: %w", err)
+		}
+		defer os.RemoveAll(resultsDir)
+
+		resultsFile := filepath.Join(resultsDir, "results.json")
+		if _, _, err := executeCommand(ctx, workDir, []string{"npx", "jest", "--ci", "--json", "--outputFile=" + resultsFile}); err != nil {
+			return nil, err
+		}
+		data, err := os.ReadFile(resultsFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read jest results: %w", err)
+		}
+		return parseJestJSON(data)
+	default:
+		return nil, fmt.Errorf("unsupported language for flake detection: %s", language)
+	}
+}
+
+// parseGoTestJSON reads the results of `go test -json`, naming tests by
+// package
+func parseGoTestJSON(output string) map[string]bool {
+	results := make(map[string]bool)
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var event struct {
+			Action  string
+			Package string
+			Test    string
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil || event.Test == "" {
+			continue
+		}
+		switch event.Action {
+		case "pass":
+			results[event.Package+"."+event.Test] = true
+		case "fail":
+			results[event.Package+"."+event.Test] = false
+		}
+	}
+	return results
+}
+
+var pytestOutcomePattern = regexp.MustCompile(`^(\S+::\S+) (PASSED|FAILED|ERROR)`)
+
+// parsePytestOutcomes reads the results of `pytest -v`
+func parsePytestOutcomes(output string) map[string]bool {
+	results := make(map[string]bool)
+	for _, line := range strings.Split(output, "\n") {
+		if m := pytestOutcomePattern.FindStringSubmatch(strings.TrimSpace(line)); m != nil {
+			results[m[1]] = m[2] == "PASSED"
+		}
+	}
+	return results
+}
+
+// parseJestJSON reads the results jest writes with --json, naming tests by
+// file
+func parseJestJSON(data []byte) (map[string]bool, error) {
+	var out struct {
+		TestResults []struct {
+			Name             string `json:"name"`
+			AssertionResults []struct {
+				FullName string `json:"fullName"`
+				Status   string `json:"status"`
+			} `json:"assertionResults"`
+		} `json:"testResults"`
+	}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, fmt.Errorf("failed to parse jest results: %w", err)
+	}
+
+	results := make(map[string]bool)
+	for _, file := range out.TestResults {
+		for _, a := range file.AssertionResults {
+			switch a.Status {
+			case "passed":
+				results[file.Name+" > "+a.FullName] = true
+			case "failed":
+				results[file.Name+" > "+a.FullName] = false
+			}
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,99 @@
+package validator
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTallyFlakes(t *testing.T) {
+	report := TallyFlakes([]map[string]bool{
+		{"TestStable": true, "TestBroken": false, "TestFlaky": true, "TestRace": false},
+		{"TestStable": true, "TestBroken": false, "TestFlaky": false, "TestRace": true},
+		{"TestStable": true, "TestBroken": false, "TestFlaky": true},
+	})
+
+	if report.Runs != 3 || report.Tests != 4 {
+		t.Errorf("runs, tests = %d, %d, want 3, 4", report.Runs, report.Tests)
+	}
+	if len(report.Flaky) != 2 || report.Flaky[0] != (FlakyTest{Name: "TestFlaky", Passed: 2, Failed: 1}) || report.Flaky[1].Name != "TestRace" {
+		t.Errorf("flaky = %+v, want TestFlaky and TestRace", report.Flaky)
+	}
+	if report.Rate != 50 {
+		t.Errorf("rate = %v, want 50", report.Rate)
+	}
+}
+
+func TestParseTestOutcomes(t *testing.T) {
+	goOut := `{"Action":"run","Package":"example.com/m/a","Test":"TestA"}
+{"Action":"pass","Package":"example.com/m/a","Test":"TestA","Elapsed":0}
+{"Action":"fail","Package":"example.com/m/a","Test":"TestB","Elapsed":0}
+{"Action":"fail","Package":"example.com/m/a","Elapsed":0}
+`
+	got := parseGoTestJSON(goOut)
+	if len(got) != 2 || !got["example.com/m/a.TestA"] || got["example.com/m/a.TestB"] {
+		t.Errorf("go = %v", got)
+	}
+
+	pyOut := "tests/test_api.py::test_get PASSED   [ 50%]\ntests/test_api.py::test_post FAILED   [100%]\n"
+	got = parsePytestOutcomes(pyOut)
+	if len(got) != 2 || !got["tests/test_api.py::test_get"] || got["tests/test_api.py::test_post"] {
+		t.Errorf("pytest = %v", got)
+	}
+
+	jestOut := `{"testResults":[{"name":"/app/sum.test.js","assertionResults":[
+		{"fullName":"sum adds","status":"passed"},
+		{"fullName":"sum overflows","status":"failed"},
+		{"fullName":"sum later","status":"pending"}]}]}`
+	got, err := parseJestJSON([]byte(jestOut))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || !got["/app/sum.test.js > sum adds"] || got["/app/sum.test.js > sum overflows"] {
+		t.Errorf("jest = %v", got)
+	}
+}
+
+func TestDetectFlakes_Go(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs go test")
+	}
+	root := t.TempDir()
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("go.mod", "module example.com/flaky\n\ngo 1.21\n")
+	// TestToggle fails every other run, tracked by a file next to it
+	write("flaky_test.go", `package flaky
+
+import (
+	"os"
+	"testing"
+)
+
+func TestStable(t *testing.T) {}
+
+func TestToggle(t *testing.T) {
+	if _, err := os.Stat("ran"); err == nil {
+		os.Remove("ran")
+		t.Fatal("second run")
+	}
+	os.WriteFile("ran", nil, 0644)
+}
+`)
+
+	report, err := DetectFlakes(context.Background(), root, "go", 2)
+	if err != nil {
+		t.Fatalf("DetectFlakes: %v", err)
+	}
+	if report.Tests != 2 || len(report.Flaky) != 1 || report.Flaky[0].Name != "example.com/flaky.TestToggle" || report.Rate != 50 {
+		t.Errorf("report = %+v, want TestToggle flaky", report)
+	}
+
+	if _, err := DetectFlakes(context.Background(), root, "go", 1); err == nil {
+		t.Error("expected an error for a single run")
+	}
+}
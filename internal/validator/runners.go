@@ -0,0 +1,346 @@
+package validator
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/QTest-hq/qtest/internal/platform"
+	"github.com/rs/zerolog/log"
+)
+
+// SuiteRunner runs the tests of one language/framework. A run is split into
+// groups (Go packages, pytest directories, jest projects) so mixed
+// repositories run every file with the right tool.
+type SuiteRunner interface {
+	// Name identifies the runner, e.g. "go test"
+	Name() string
+
+	// Language is the language the runner handles
+	Language() string
+
+	// Matches reports whether the runner handles a test file
+	Matches(testFile string) bool
+
+	// Groups splits the runner's files into separate invocations
+	Groups(workDir string, testFiles []string) []RunGroup
+
+	// ParseErrors extracts failures from a group's output
+	ParseErrors(output string) []TestError
+}
+
+// RunGroup is a single test command
+type RunGroup struct {
+	Target string   `json:"target"` // package, directory or project, relative to the work dir
+	Dir    string   `json:"-"`      // directory the command runs in
+	Cmd    []string `json:"command"`
+	Files  []string `json:"files"`
+}
+
+// GroupResult is the outcome of one group
+type GroupResult struct {
+	Runner   string `json:"runner"`
+	Language string `json:"language"`
+	RunGroup
+	TestResult
+}
+
+// SuiteResult merges the results of every group in a run
+type SuiteResult struct {
+	Passed      bool          `json:"passed"`
+	Groups      []GroupResult `json:"groups"`
+	Unsupported []string      `json:"unsupported,omitempty"` // files no runner handles
+	Duration    time.Duration `json:"duration"`
+}
+
+// Errors returns the failures of every group
+func (s *SuiteResult) Errors() []TestError {
+	var errs []TestError
+	for _, g := range s.Groups {
+		errs = append(errs, g.Errors...)
+	}
+	return errs
+}
+
+// Output concatenates group outputs under a header per group
+func (s *SuiteResult) Output() string {
+	var sb strings.Builder
+	for _, g := range s.Groups {
+		status := "PASS"
+		if !g.Passed {
+			status = "FAIL"
+		}
+		sb.WriteString(fmt.Sprintf("=== %s %s (%s)\n", status, g.Target, g.Runner))
+		sb.WriteString(g.Output)
+		if !strings.HasSuffix(g.Output, "\n") {
+			sb.WriteString("\n")
+		}
+	}
+	if len(s.Unsupported) > 0 {
+		sb.WriteString(fmt.Sprintf("=== SKIP no runner for: %s\n", strings.Join(s.Unsupported, ", ")))
+	}
+	return sb.String()
+}
+
+// RunnerRegistry dispatches test files to the runner for their language
+type RunnerRegistry struct {
+	runners []SuiteRunner
+
+	// execute runs a command and returns its output and exit code;
+	// replaceable in tests
+	execute func(ctx context.Context, dir string, args []string) (string, int, error)
+}
+
+// NewRunnerRegistry creates a registry with the go test, pytest and jest
+// runners
+func NewRunnerRegistry() *RunnerRegistry {
+	r := &RunnerRegistry{execute: executeCommand}
+	r.Register(&goTestRunner{})
+	r.Register(&pytestRunner{})
+	r.Register(&jestRunner{})
+	return r
+}
+
+// Register adds a runner. Earlier runners take precedence for files
+// matched by several.
+func (r *RunnerRegistry) Register(runner SuiteRunner) {
+	r.runners = append(r.runners, runner)
+}
+
+// RunAll runs the test files with their runners and merges the results.
+// Files without a runner are reported but don't fail the run.
+func (r *RunnerRegistry) RunAll(ctx context.Context, workDir string, testFiles []string) *SuiteResult {
+	start := time.Now()
+	result := &SuiteResult{Passed: true}
+
+	byRunner := make(map[SuiteRunner][]string)
+	for _, file := range testFiles {
+		runner := r.runnerFor(file)
+		if runner == nil {
+			result.Unsupported = append(result.Unsupported, file)
+			continue
+		}
+		byRunner[runner] = append(byRunner[runner], file)
+	}
+
+	for _, runner := range r.runners {
+		files := byRunner[runner]
+		if len(files) == 0 {
+			continue
+		}
+
+		for _, group := range runner.Groups(workDir, files) {
+			if ctx.Err() != nil {
+				result.Passed = false
+				break
+			}
+			gr := r.runGroup(ctx, runner, group)
+			result.Groups = append(result.Groups, gr)
+			if !gr.Passed {
+				result.Passed = false
+			}
+		}
+	}
+
+	result.Duration = time.Since(start)
+	log.Info().
+		Bool("passed", result.Passed).
+		Int("groups", len(result.Groups)).
+		Int("unsupported", len(result.Unsupported)).
+		Dur("duration", result.Duration).
+		Msg("test suite run complete")
+
+	return result
+}
+
+func (r *RunnerRegistry) runnerFor(file string) SuiteRunner {
+	for _, runner := range r.runners {
+		if runner.Matches(file) {
+			return runner
+		}
+	}
+	return nil
+}
+
+func (r *RunnerRegistry) runGroup(ctx context.Context, runner SuiteRunner, group RunGroup) GroupResult {
+	start := time.Now()
+	log.Debug().Str("runner", runner.Name()).Str("target", group.Target).Msg("running tests")
+
+	output, exitCode, err := r.execute(ctx, group.Dir, group.Cmd)
+	if err != nil && exitCode == 0 {
+		// The command couldn't start, e.g. the tool isn't installed
+		exitCode = -1
+		output += err.Error()
+	}
+
+	gr := GroupResult{
+		Runner:   runner.Name(),
+		Language: runner.Language(),
+		RunGroup: group,
+		TestResult: TestResult{
+			Passed:   exitCode == 0,
+			TestFile: strings.Join(group.Files, ","),
+			Output:   output,
+			Duration: time.Since(start),
+			ExitCode: exitCode,
+		},
+	}
+	if !gr.Passed {
+		gr.Errors = runner.ParseErrors(output)
+	}
+	return gr
+}
+
+func executeCommand(ctx context.Context, dir string, args []string) (string, int, error) {
+	cmd := platform.Command(ctx, args[0], args[1:]...)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		return string(output), exitErr.ExitCode(), nil
+	}
+	return string(output), 0, err
+}
+
+// goTestRunner runs `go test` once per package, from the package's module
+type goTestRunner struct{}
+
+func (goTestRunner) Name() string     { return "go test" }
+func (goTestRunner) Language() string { return "go" }
+
+func (goTestRunner) Matches(testFile string) bool {
+	return strings.HasSuffix(testFile, "_test.go")
+}
+
+func (goTestRunner) Groups(workDir string, testFiles []string) []RunGroup {
+	return groupFiles(workDir, testFiles, filepath.Dir, func(dir string, files []string) RunGroup {
+		module := findUp(dir, workDir, "go.mod")
+		pkg := "./" + filepath.ToSlash(relPath(module, dir))
+		return RunGroup{
+			Target: relPath(workDir, dir),
+			Dir:    module,
+			Cmd:    []string{"go", "test", "-v", pkg},
+			Files:  files,
+		}
+	})
+}
+
+func (goTestRunner) ParseErrors(output string) []TestError {
+	return parseGoTestErrors(output)
+}
+
+// pytestRunner runs pytest once per test directory
+type pytestRunner struct{}
+
+func (pytestRunner) Name() string     { return "pytest" }
+func (pytestRunner) Language() string { return "python" }
+
+func (pytestRunner) Matches(testFile string) bool {
+	return filepath.Ext(testFile) == ".py"
+}
+
+func (pytestRunner) Groups(workDir string, testFiles []string) []RunGroup {
+	return groupFiles(workDir, testFiles, filepath.Dir, func(dir string, files []string) RunGroup {
+		cmd := []string{"python", "-m", "pytest", "-v", "--tb=short"}
+		for _, f := range files {
+			cmd = append(cmd, relPath(workDir, f))
+		}
+		return RunGroup{
+			Target: relPath(workDir, dir),
+			Dir:    workDir,
+			Cmd:    cmd,
+			Files:  files,
+		}
+	})
+}
+
+func (pytestRunner) ParseErrors(output string) []TestError {
+	return parsePytestErrors(output)
+}
+
+// jestRunner runs jest once per project, i.e. the nearest package.json
+type jestRunner struct{}
+
+func (jestRunner) Name() string     { return "jest" }
+func (jestRunner) Language() string { return "javascript" }
+
+func (jestRunner) Matches(testFile string) bool {
+	switch filepath.Ext(testFile) {
+	case ".js", ".jsx", ".ts", ".tsx", ".mjs", ".cjs":
+		return true
+	}
+	return false
+}
+
+func (jestRunner) Groups(workDir string, testFiles []string) []RunGroup {
+	project := func(file string) string {
+		return findUp(filepath.Dir(file), workDir, "package.json")
+	}
+	return groupFiles(workDir, testFiles, project, func(dir string, files []string) RunGroup {
+		cmd := []string{"npx", "jest", "--ci"}
+		for _, f := range files {
+			cmd = append(cmd, relPath(dir, f))
+		}
+		return RunGroup{
+			Target: relPath(workDir, dir),
+			Dir:    dir,
+			Cmd:    cmd,
+			Files:  files,
+		}
+	})
+}
+
+func (jestRunner) ParseErrors(output string) []TestError {
+	return parseJestErrors(output)
+}
+
+// groupFiles buckets files by key and builds a group per bucket, in key order
+func groupFiles(workDir string, files []string, key func(string) string, build func(string, []string) RunGroup) []RunGroup {
+	buckets := make(map[string][]string)
+	for _, f := range files {
+		if !filepath.IsAbs(f) {
+			f = filepath.Join(workDir, f)
+		}
+		k := key(f)
+		buckets[k] = append(buckets[k], f)
+	}
+
+	keys := make([]string, 0, len(buckets))
+	for k := range buckets {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	groups := make([]RunGroup, 0, len(keys))
+	for _, k := range keys {
+		sort.Strings(buckets[k])
+		groups = append(groups, build(k, buckets[k]))
+	}
+	return groups
+}
+
+// findUp returns the nearest directory from dir up to root containing
+// marker, or root if none does
+func findUp(dir, root, marker string) string {
+	root = filepath.Clean(root)
+	for d := filepath.Clean(dir); ; d = filepath.Dir(d) {
+		if _, err := os.Stat(filepath.Join(d, marker)); err == nil {
+			return d
+		}
+		if d == root || d == filepath.Dir(d) || !strings.HasPrefix(d, root) {
+			return root
+		}
+	}
+}
+
+func relPath(base, path string) string {
+	rel, err := filepath.Rel(base, path)
+	if err != nil {
+		return path
+	}
+	return filepath.ToSlash(rel)
+}
@@ -0,0 +1,110 @@
+package validator
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func touch(t *testing.T, root, rel string) string {
+	t.Helper()
+	path := filepath.Join(root, rel)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRunnerRegistry_RunAll_MixedRepo(t *testing.T) {
+	root := t.TempDir()
+	touch(t, root, "go.mod")
+	touch(t, root, "web/package.json")
+	files := []string{
+		touch(t, root, "pkg/a/a_test.go"),
+		touch(t, root, "pkg/a/b_test.go"),
+		touch(t, root, "pkg/b/c_test.go"),
+		touch(t, root, "tests/test_api.py"),
+		touch(t, root, "web/src/app.test.ts"),
+		touch(t, root, "docs/notes.md"),
+	}
+
+	type call struct {
+		dir  string
+		args string
+	}
+	var calls []call
+	registry := NewRunnerRegistry()
+	registry.execute = func(_ context.Context, dir string, args []string) (string, int, error) {
+		calls = append(calls, call{dir, strings.Join(args, " ")})
+		if strings.Contains(args[len(args)-1], "pkg/b") {
+			return "--- FAIL: TestC (0.00s)\nFAIL\n", 1, nil
+		}
+		return "ok\n", 0, nil
+	}
+
+	result := registry.RunAll(context.Background(), root, files)
+
+	want := []call{
+		{root, "go test -v ./pkg/a"},
+		{root, "go test -v ./pkg/b"},
+		{root, "python -m pytest -v --tb=short tests/test_api.py"},
+		{filepath.Join(root, "web"), "npx jest --ci src/app.test.ts"},
+	}
+	if len(calls) != len(want) {
+		t.Fatalf("calls = %v, want %v", calls, want)
+	}
+	for i := range want {
+		if calls[i] != want[i] {
+			t.Errorf("call %d = %v, want %v", i, calls[i], want[i])
+		}
+	}
+
+	if result.Passed {
+		t.Error("suite should fail when one group fails")
+	}
+	if len(result.Groups) != 4 || len(result.Groups[0].Files) != 2 {
+		t.Errorf("groups = %+v", result.Groups)
+	}
+	if errs := result.Errors(); len(errs) != 1 || errs[0].TestName != "TestC" {
+		t.Errorf("Errors() = %+v, want TestC", errs)
+	}
+	if len(result.Unsupported) != 1 {
+		t.Errorf("Unsupported = %v, want docs/notes.md", result.Unsupported)
+	}
+	if out := result.Output(); !strings.Contains(out, "=== FAIL pkg/b (go test)") {
+		t.Errorf("Output() missing failing group header:\n%s", out)
+	}
+}
+
+func TestRunnerRegistry_GoModulePerPackage(t *testing.T) {
+	root := t.TempDir()
+	touch(t, root, "services/api/go.mod")
+	file := touch(t, root, "services/api/handlers/h_test.go")
+
+	groups := (goTestRunner{}).Groups(root, []string{file})
+	if len(groups) != 1 {
+		t.Fatalf("len(groups) = %d, want 1", len(groups))
+	}
+	g := groups[0]
+	if g.Dir != filepath.Join(root, "services/api") || g.Cmd[len(g.Cmd)-1] != "./handlers" {
+		t.Errorf("group = %+v, want run from nested module", g)
+	}
+}
+
+func TestRunnerRegistry_MissingTool(t *testing.T) {
+	root := t.TempDir()
+	registry := NewRunnerRegistry()
+	registry.execute = func(context.Context, string, []string) (string, int, error) {
+		return "", 0, os.ErrNotExist
+	}
+
+	result := registry.RunAll(context.Background(), root, []string{touch(t, root, "test_x.py")})
+	if result.Passed || result.Groups[0].ExitCode != -1 {
+		t.Errorf("missing tool should fail the group: %+v", result.Groups[0])
+	}
+}
@@ -0,0 +1,137 @@
+package validator
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/QTest-hq/qtest/internal/platform"
+	"github.com/rs/zerolog/log"
+)
+
+// staticCheckTimeout bounds one static check; tsc on a large project is slow
+const staticCheckTimeout = 2 * time.Minute
+
+// StaticResult is the outcome of a test file's static checks
+type StaticResult struct {
+	Passed  bool   `json:"passed"`
+	Skipped bool   `json:"skipped,omitempty"` // no check for the language, or its tool isn't installed
+	Tool    string `json:"tool,omitempty"`    // command that checked the file
+	Output  string `json:"output,omitempty"`  // the tool's errors, when the file failed
+}
+
+// StaticChecker compiles or type-checks a test file without running it:
+// go vet for Go, py_compile for Python, tsc --noEmit for TypeScript and
+// node --check for JavaScript
+type StaticChecker struct {
+	workDir  string
+	language string
+}
+
+// NewStaticChecker creates a static checker for tests in language, with
+// workDir the project root (where tsconfig.json is looked for)
+func NewStaticChecker(workDir, language string) *StaticChecker {
+	return &StaticChecker{
+		workDir:  workDir,
+		language: language,
+	}
+}
+
+// Check runs the static checks on testFile. Go files are run through
+// goimports first, when it's installed, to fix their imports.
+func (c *StaticChecker) Check(ctx context.Context, testFile string) (*StaticResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, staticCheckTimeout)
+	defer cancel()
+
+	switch c.language {
+	case "go":
+		return c.checkGo(ctx, testFile)
+	case "python":
+		python := platform.Python()
+		return runStatic(ctx, python+" -m py_compile", c.workDir, python, "-m", "py_compile", testFile)
+	case "typescript":
+		return c.checkTypeScript(ctx, testFile)
+	case "javascript":
+		return runStatic(ctx, "node --check", c.workDir, "node", "--check", testFile)
+	default:
+		return &StaticResult{Passed: true, Skipped: true}, nil
+	}
+}
+
+// checkGo fixes the file's imports and vets its package
+func (c *StaticChecker) checkGo(ctx context.Context, testFile string) (*StaticResult, error) {
+	dir := filepath.Dir(testFile)
+	if _, err := exec.LookPath("goimports"); err == nil {
+		res, err := runStatic(ctx, "goimports", dir, "goimports", "-w", testFile)
+		if err != nil || !res.Passed {
+			return res, err
+		}
+	}
+	return runStatic(ctx, "go vet", dir, "go", "vet", ".")
+}
+
+// checkTypeScript type-checks the project with its own tsc and tsconfig.json
+// and keeps the errors reported in testFile. Without a tsconfig.json the
+// compiler options, and so the errors, would be guesses, and the check is
+// skipped.
+func (c *StaticChecker) checkTypeScript(ctx context.Context, testFile string) (*StaticResult, error) {
+	if _, err := os.Stat(filepath.Join(c.workDir, "tsconfig.json")); err != nil {
+		return &StaticResult{Passed: true, Skipped: true, Tool: "tsc --noEmit"}, nil
+	}
+	tsc := filepath.Join(c.workDir, "node_modules", ".bin", "tsc")
+	res, err := runStatic(ctx, "tsc --noEmit", c.workDir, tsc, "--noEmit", "--pretty", "false", "-p", ".")
+	if err != nil || res.Passed || res.Skipped {
+		return res, err
+	}
+
+	rel, relErr := filepath.Rel(c.workDir, testFile)
+	if relErr != nil {
+		rel = testFile
+	}
+	res.Output = tscErrorsFor(res.Output, filepath.ToSlash(rel))
+	res.Passed = res.Output == ""
+	return res, nil
+}
+
+// tscErrorsFor keeps the lines of tsc output reported in file, with the
+// indented lines that continue them
+func tscErrorsFor(output, file string) string {
+	var kept []string
+	keep := false
+	for _, line := range strings.Split(output, "\n") {
+		if line == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, " ") {
+			keep = strings.HasPrefix(filepath.ToSlash(line), file+"(")
+		}
+		if keep {
+			kept = append(kept, line)
+		}
+	}
+	return strings.Join(kept, "\n")
+}
+
+// runStatic runs a static check command in dir. A check whose tool isn't
+// installed is skipped rather than failed.
+func runStatic(ctx context.Context, tool, dir, name string, args ...string) (*StaticResult, error) {
+	if _, err := platform.LookPath(name); err != nil {
+		log.Debug().Str("tool", tool).Msg("static check tool not installed, skipping")
+		return &StaticResult{Passed: true, Skipped: true, Tool: tool}, nil
+	}
+
+	cmd := platform.Command(ctx, name, args...)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		if _, ok := err.(*exec.ExitError); !ok || ctx.Err() != nil {
+			return nil, fmt.Errorf("failed to run %s: %w", tool, err)
+		}
+		return &StaticResult{Tool: tool, Output: strings.TrimSpace(string(output))}, nil
+	}
+	return &StaticResult{Passed: true, Tool: tool}, nil
+}
@@ -0,0 +1,90 @@
+package validator
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStaticChecker_Go(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not installed")
+	}
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/calc\n\ngo 1.21\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "calc.go"), []byte("package calc\n\nfunc Add(a, b int) int { return a + b }\n"), 0644)
+	testFile := filepath.Join(dir, "calc_test.go")
+	checker := NewStaticChecker(dir, "go")
+
+	os.WriteFile(testFile, []byte("package calc\n\nimport \"testing\"\n\nfunc TestAdd(t *testing.T) {\n\tif Add(1, 2) != 3 {\n\t\tt.Error(\"wrong\")\n\t}\n}\n"), 0644)
+	res, err := checker.Check(context.Background(), testFile)
+	if err != nil || !res.Passed {
+		t.Fatalf("Check() = %+v, %v, want passed", res, err)
+	}
+
+	os.WriteFile(testFile, []byte("package calc\n\nimport \"testing\"\n\nfunc TestAdd(t *testing.T) {\n\tif Add(1) != 3 {\n\t}\n}\n"), 0644)
+	res, err = checker.Check(context.Background(), testFile)
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if res.Passed || !strings.Contains(res.Output, "not enough arguments") {
+		t.Errorf("Check() = %+v, want failure about arguments", res)
+	}
+}
+
+func TestStaticChecker_Python(t *testing.T) {
+	if _, err := exec.LookPath("python3"); err != nil {
+		t.Skip("python3 not installed")
+	}
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "test_calc.py")
+	checker := NewStaticChecker(dir, "python")
+
+	os.WriteFile(testFile, []byte("def test_add():\n    assert 1 + 2 == 3\n"), 0644)
+	if res, err := checker.Check(context.Background(), testFile); err != nil || !res.Passed {
+		t.Fatalf("Check() = %+v, %v, want passed", res, err)
+	}
+
+	os.WriteFile(testFile, []byte("def test_add(:\n    assert 1 + 2 == 3\n"), 0644)
+	res, err := checker.Check(context.Background(), testFile)
+	if err != nil || res.Passed || !strings.Contains(res.Output, "SyntaxError") {
+		t.Errorf("Check() = %+v, %v, want SyntaxError", res, err)
+	}
+}
+
+func TestStaticChecker_Skipped(t *testing.T) {
+	dir := t.TempDir()
+
+	res, err := NewStaticChecker(dir, "rust").Check(context.Background(), filepath.Join(dir, "tests", "calc.rs"))
+	if err != nil || !res.Passed || !res.Skipped {
+		t.Errorf("rust Check() = %+v, %v, want skipped", res, err)
+	}
+
+	// No tsconfig.json to check against
+	res, err = NewStaticChecker(dir, "typescript").Check(context.Background(), filepath.Join(dir, "calc.test.ts"))
+	if err != nil || !res.Passed || !res.Skipped {
+		t.Errorf("typescript Check() = %+v, %v, want skipped", res, err)
+	}
+}
+
+func TestTscErrorsFor(t *testing.T) {
+	output := `src/calc.test.ts(3,10): error TS2554: Expected 2 arguments, but got 1.
+src/other.ts(1,1): error TS2304: Cannot find name 'x'.
+src/calc.test.ts(5,3): error TS2322: Type 'string' is not assignable to type 'number'.
+  Types of property 'a' are incompatible.
+src/other.ts(2,1): error TS2304: Cannot find name 'y'.
+`
+	got := tscErrorsFor(output, "src/calc.test.ts")
+	want := `src/calc.test.ts(3,10): error TS2554: Expected 2 arguments, but got 1.
+src/calc.test.ts(5,3): error TS2322: Type 'string' is not assignable to type 'number'.
+  Types of property 'a' are incompatible.`
+	if got != want {
+		t.Errorf("tscErrorsFor() = %q, want %q", got, want)
+	}
+	if got := tscErrorsFor(output, "src/none.test.ts"); got != "" {
+		t.Errorf("tscErrorsFor() = %q, want none", got)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/QTest-hq/qtest/internal/platform"
 	"github.com/rs/zerolog/log"
 )
 
@@ -56,7 +57,7 @@ func (v *Validator) RunTests(ctx context.Context, testFile string) (*TestResult,
 	case "javascript", "typescript":
 		// Try npm test, jest, or npx jest
 		runner = "jest"
-		cmd = exec.CommandContext(ctx, "npx", "jest", testFile, "--json", "--testLocationInResults")
+		cmd = platform.Command(ctx, "npx", "jest", filepath.ToSlash(testFile), "--json", "--testLocationInResults")
 	case "python":
 		runner = "pytest"
 		cmd = exec.CommandContext(ctx, "pytest", testFile, "-v", "--tb=short")
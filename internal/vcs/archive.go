@@ -0,0 +1,282 @@
+package vcs
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/QTest-hq/qtest/internal/netguard"
+)
+
+// Limits on archives, so a source can't fill a worker's disk
+const (
+	maxArchiveBytes   = 1 << 30
+	maxExtractedBytes = 4 << 30
+)
+
+// publicClient downloads archives when Archive.Client is nil. Archive URLs
+// come from API users, so it only reaches public addresses, after
+// redirects too.
+var publicClient = &http.Client{
+	Transport:     netguard.NewTransport(30 * time.Second),
+	CheckRedirect: netguard.CheckRedirect,
+}
+
+// Archive fetches a tarball or zip file over http or https and extracts it.
+// An archive holds one revision, so ref is ignored and the revision is the
+// archive's SHA-256.
+type Archive struct {
+	// Client downloads archives. When nil, a client that refuses loopback,
+	// private and link-local addresses is used.
+	Client *http.Client
+
+	// AllowFile lets file URLs name archives on the local disk, for local
+	// callers. Workers leave it unset, so sources submitted through the API
+	// can't read their files.
+	AllowFile bool
+}
+
+func (Archive) Kind() string { return KindArchive }
+
+func (a Archive) Fetch(ctx context.Context, rawURL, ref, dest string) (string, error) {
+	file, err := os.CreateTemp("", "qtest-archive-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create archive file: %w", err)
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	sum, err := a.download(ctx, trimPrefix(rawURL), file)
+	if err != nil {
+		return "", err
+	}
+	if err := extract(file, dest); err != nil {
+		return "", fmt.Errorf("failed to extract archive: %w", err)
+	}
+	return sum, nil
+}
+
+// download copies an archive to w, returning its SHA-256
+func (a Archive) download(ctx context.Context, rawURL string, w io.Writer) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse archive URL: %w", err)
+	}
+
+	var body io.ReadCloser
+	switch u.Scheme {
+	case "http", "https":
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+		if err != nil {
+			return "", fmt.Errorf("failed to create request: %w", err)
+		}
+		client := a.Client
+		if client == nil {
+			client = publicClient
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return "", fmt.Errorf("failed to download archive: %w", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return "", fmt.Errorf("failed to download archive: %s", resp.Status)
+		}
+		body = resp.Body
+	case "file":
+		if !a.AllowFile {
+			return "", fmt.Errorf("file archive URLs aren't allowed")
+		}
+		f, err := os.Open(u.Path)
+		if err != nil {
+			return "", fmt.Errorf("failed to open archive: %w", err)
+		}
+		body = f
+	default:
+		return "", fmt.Errorf("unsupported archive URL scheme %q", u.Scheme)
+	}
+	defer body.Close()
+
+	hash := sha256.New()
+	n, err := io.Copy(io.MultiWriter(w, hash), io.LimitReader(body, maxArchiveBytes+1))
+	if err != nil {
+		return "", fmt.Errorf("failed to download archive: %w", err)
+	}
+	if n > maxArchiveBytes {
+		return "", fmt.Errorf("archive is larger than %d bytes", maxArchiveBytes)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// extract extracts an archive into dest, telling its format from its first
+// bytes. A single top-level directory, as release tarballs have, is
+// stripped.
+func extract(file *os.File, dest string) error {
+	magic := make([]byte, 4)
+	n, err := file.ReadAt(magic, 0)
+	if err != nil && err != io.EOF {
+		return err
+	}
+	magic = magic[:n]
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	staging, err := os.MkdirTemp(filepath.Dir(dest), ".extract-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(staging)
+
+	e := &extractor{dir: staging}
+	switch {
+	case bytes.HasPrefix(magic, []byte("PK\x03\x04")):
+		info, err := file.Stat()
+		if err != nil {
+			return err
+		}
+		err = e.zip(file, info.Size())
+	case bytes.HasPrefix(magic, []byte{0x1f, 0x8b}):
+		gz, gzErr := gzip.NewReader(file)
+		if gzErr != nil {
+			return gzErr
+		}
+		defer gz.Close()
+		err = e.tar(gz)
+	case bytes.HasPrefix(magic, []byte("BZh")):
+		err = e.tar(bzip2.NewReader(file))
+	default:
+		err = e.tar(file)
+	}
+	if err != nil {
+		return err
+	}
+
+	root := staging
+	if entries, err := os.ReadDir(staging); err == nil && len(entries) == 1 && entries[0].IsDir() {
+		root = filepath.Join(staging, entries[0].Name())
+	}
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if err := os.Rename(filepath.Join(root, entry.Name()), filepath.Join(dest, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// extractor writes an archive's directories and regular files under dir.
+// Links and special files are skipped, so nothing extracted points outside
+// dir.
+type extractor struct {
+	dir     string
+	written int64
+}
+
+func (e *extractor) tar(r io.Reader) error {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			err = e.mkdir(hdr.Name)
+		case tar.TypeReg:
+			err = e.file(hdr.Name, hdr.FileInfo().Mode(), tr)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (e *extractor) zip(r io.ReaderAt, size int64) error {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return err
+	}
+	for _, f := range zr.File {
+		switch {
+		case f.FileInfo().IsDir():
+			err = e.mkdir(f.Name)
+		case f.Mode().IsRegular():
+			err = e.zipFile(f)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *extractor) zipFile(f *zip.File) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return e.file(f.Name, f.Mode(), rc)
+}
+
+// path returns where an entry is extracted, rejecting names outside dir
+func (e *extractor) path(name string) (string, error) {
+	if !filepath.IsLocal(filepath.FromSlash(name)) {
+		return "", fmt.Errorf("archive entry %q is outside the archive", name)
+	}
+	return filepath.Join(e.dir, filepath.FromSlash(name)), nil
+}
+
+func (e *extractor) mkdir(name string) error {
+	path, err := e.path(name)
+	if err != nil {
+		return err
+	}
+	return os.MkdirAll(path, 0755)
+}
+
+func (e *extractor) file(name string, mode fs.FileMode, r io.Reader) error {
+	path, err := e.path(name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode.Perm()|0600)
+	if err != nil {
+		return err
+	}
+	n, err := io.Copy(f, io.LimitReader(r, maxExtractedBytes-e.written+1))
+	e.written += n
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if e.written > maxExtractedBytes {
+		return fmt.Errorf("archive extracts to more than %d bytes", maxExtractedBytes)
+	}
+	return nil
+}
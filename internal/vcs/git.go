@@ -0,0 +1,27 @@
+package vcs
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/QTest-hq/qtest/internal/platform"
+)
+
+// Git fetches a shallow clone of a git repository
+type Git struct{}
+
+func (Git) Kind() string { return KindGit }
+
+func (Git) Fetch(ctx context.Context, url, ref, dest string) (string, error) {
+	args := []string{"clone", "--depth", "1"}
+	if ref != "" {
+		args = append(args, "-b", ref)
+	}
+	// -- ends the options, so a URL can't be read as one
+	args = append(args, "--", trimPrefix(url), dest)
+
+	if output, err := platform.Git(ctx, args...).CombinedOutput(); err != nil {
+		return "", fmt.Errorf("git clone failed: %s: %w", string(output), err)
+	}
+	return Revision(ctx, dest), nil
+}
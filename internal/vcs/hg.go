@@ -0,0 +1,27 @@
+package vcs
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/QTest-hq/qtest/internal/platform"
+)
+
+// Hg fetches a clone of a Mercurial repository, which needs hg installed
+type Hg struct{}
+
+func (Hg) Kind() string { return KindHg }
+
+func (Hg) Fetch(ctx context.Context, url, ref, dest string) (string, error) {
+	args := []string{"clone", "--noninteractive"}
+	if ref != "" {
+		args = append(args, "--updaterev", ref)
+	}
+	// -- ends the options, so a URL can't be read as one
+	args = append(args, "--", trimPrefix(url), dest)
+
+	if output, err := platform.Command(ctx, "hg", args...).CombinedOutput(); err != nil {
+		return "", fmt.Errorf("hg clone failed: %s: %w", string(output), err)
+	}
+	return Revision(ctx, dest), nil
+}
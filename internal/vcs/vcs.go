@@ -0,0 +1,141 @@
+// Package vcs fetches the source code QTest analyzes, from git or Mercurial
+// repositories or from archives
+package vcs
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/QTest-hq/qtest/internal/platform"
+)
+
+// Kinds of source
+const (
+	KindGit     = "git"
+	KindHg      = "hg"
+	KindArchive = "archive" // tarball or zip file
+)
+
+// VCS fetches source code into a directory
+type VCS interface {
+	Kind() string
+
+	// Fetch fetches the source at url into dest, an empty directory, and
+	// returns the revision fetched. ref is a branch, tag or revision; the
+	// default one when empty.
+	Fetch(ctx context.Context, url, ref, dest string) (string, error)
+}
+
+// prefixes name a URL's kind of source, as in hg+https://host/repo
+var prefixes = []struct {
+	prefix string
+	kind   string
+}{
+	{"git+", KindGit},
+	{"hg+", KindHg},
+	{"hg::", KindHg},
+	{"archive+", KindArchive},
+}
+
+// archiveExtensions are the archive formats Fetch extracts
+var archiveExtensions = []string{".tar.gz", ".tgz", ".tar.bz2", ".tbz2", ".tar", ".zip"}
+
+// Valid reports whether kind is a kind of source
+func Valid(kind string) bool {
+	return kind == KindGit || kind == KindHg || kind == KindArchive
+}
+
+// Detect returns the kind of source a URL names: the one its prefix names,
+// archive for archive file names, and git otherwise
+func Detect(rawURL string) string {
+	for _, p := range prefixes {
+		if strings.HasPrefix(rawURL, p.prefix) {
+			return p.kind
+		}
+	}
+	if archiveExtension(rawURL) != "" {
+		return KindArchive
+	}
+	return KindGit
+}
+
+// Qualify prefixes a URL with kind when Detect wouldn't tell it, so the
+// kind is kept wherever the URL is
+func Qualify(kind, rawURL string) string {
+	if Detect(rawURL) == kind {
+		return rawURL
+	}
+	return kind + "+" + trimPrefix(rawURL)
+}
+
+// For returns the VCS for kind, or for the kind of url when kind is empty
+func For(kind, rawURL string) (VCS, error) {
+	if kind == "" {
+		kind = Detect(rawURL)
+	}
+	switch kind {
+	case KindGit:
+		return Git{}, nil
+	case KindHg:
+		return Hg{}, nil
+	case KindArchive:
+		return Archive{}, nil
+	}
+	return nil, fmt.Errorf("unknown vcs %q", kind)
+}
+
+// TrimURL strips a URL's kind prefix and archive extension, leaving the
+// path the source is named after
+func TrimURL(rawURL string) string {
+	rawURL = trimPrefix(rawURL)
+	if u, err := url.Parse(rawURL); err == nil && u.Scheme != "" {
+		u.RawQuery, u.Fragment = "", ""
+		rawURL = u.String()
+	}
+	return strings.TrimSuffix(rawURL, archiveExtension(rawURL))
+}
+
+// Revision returns the revision checked out in dir, or "" when it isn't a
+// checkout
+func Revision(ctx context.Context, dir string) string {
+	var output []byte
+	var err error
+	if _, statErr := os.Stat(filepath.Join(dir, ".hg")); statErr == nil {
+		output, err = platform.Command(ctx, "hg", "--cwd", dir, "log", "-r", ".", "--template", "{node}").Output()
+	} else {
+		output, err = platform.Git(ctx, "-C", dir, "rev-parse", "HEAD").Output()
+	}
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// trimPrefix strips a URL's kind prefix
+func trimPrefix(rawURL string) string {
+	for _, p := range prefixes {
+		if strings.HasPrefix(rawURL, p.prefix) {
+			return strings.TrimPrefix(rawURL, p.prefix)
+		}
+	}
+	return rawURL
+}
+
+// archiveExtension returns the archive extension of a URL's path, if any
+func archiveExtension(rawURL string) string {
+	name := rawURL
+	if u, err := url.Parse(rawURL); err == nil && u.Scheme != "" {
+		name = u.Path
+	}
+	lower := strings.ToLower(name)
+	for _, ext := range archiveExtensions {
+		if strings.HasSuffix(lower, ext) {
+			return name[len(name)-len(ext):]
+		}
+	}
+	return ""
+}
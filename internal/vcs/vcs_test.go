@@ -0,0 +1,210 @@
+package vcs
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/QTest-hq/qtest/internal/netguard"
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://github.com/owner/repo", KindGit},
+		{"git@github.com:owner/repo.git", KindGit},
+		{"hg+https://hg.example.com/repo", KindHg},
+		{"hg::https://hg.example.com/repo", KindHg},
+		{"https://example.com/releases/project-1.2.tar.gz", KindArchive},
+		{"https://example.com/project.ZIP?token=abc", KindArchive},
+		{"archive+https://example.com/download?id=3", KindArchive},
+		{"git+https://example.com/project.tar", KindGit},
+	}
+	for _, tt := range tests {
+		if got := Detect(tt.url); got != tt.want {
+			t.Errorf("Detect(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestQualify(t *testing.T) {
+	if got := Qualify(KindHg, "https://hg.example.com/repo"); got != "hg+https://hg.example.com/repo" {
+		t.Errorf("Qualify(hg) = %q", got)
+	}
+	if got := Qualify(KindGit, "https://github.com/owner/repo"); got != "https://github.com/owner/repo" {
+		t.Errorf("Qualify(git) = %q, want the URL unchanged", got)
+	}
+	if got := Qualify(KindGit, "hg+https://example.com/repo"); got != "git+https://example.com/repo" {
+		t.Errorf("Qualify(git) of hg URL = %q", got)
+	}
+}
+
+func TestTrimURL(t *testing.T) {
+	tests := map[string]string{
+		"hg+https://hg.example.com/team/repo":                  "https://hg.example.com/team/repo",
+		"https://example.com/releases/project-1.2.tar.gz?dl=1": "https://example.com/releases/project-1.2",
+		"https://github.com/owner/repo":                        "https://github.com/owner/repo",
+	}
+	for url, want := range tests {
+		if got := TrimURL(url); got != want {
+			t.Errorf("TrimURL(%q) = %q, want %q", url, got, want)
+		}
+	}
+}
+
+func TestArchiveFetch_Tarball(t *testing.T) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	writeTar(t, tw, "project-1.2/", "")
+	writeTar(t, tw, "project-1.2/main.go", "package main\n")
+	writeTar(t, tw, "project-1.2/pkg/util.go", "package pkg\n")
+	tw.Close()
+	gz.Close()
+	archive := buf.Bytes()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(archive)
+	}))
+	defer server.Close()
+
+	dest := t.TempDir()
+	revision, err := Archive{Client: server.Client()}.Fetch(context.Background(), server.URL+"/project-1.2.tar.gz", "", dest)
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+
+	sum := sha256.Sum256(archive)
+	if revision != hex.EncodeToString(sum[:]) {
+		t.Errorf("revision = %q, want the archive's SHA-256", revision)
+	}
+	// The top-level directory is stripped
+	if data, err := os.ReadFile(filepath.Join(dest, "pkg", "util.go")); err != nil || string(data) != "package pkg\n" {
+		t.Errorf("pkg/util.go = %q, %v", data, err)
+	}
+}
+
+func TestArchiveFetch_Zip(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, _ := zw.Create("app.py")
+	w.Write([]byte("print('hi')\n"))
+	w, _ = zw.Create("tests/test_app.py")
+	w.Write([]byte("def test(): pass\n"))
+	zw.Close()
+
+	path := filepath.Join(t.TempDir(), "src.zip")
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	dest := t.TempDir()
+	if _, err := (Archive{AllowFile: true}).Fetch(context.Background(), "file://"+filepath.ToSlash(path), "", dest); err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	for _, name := range []string{"app.py", filepath.Join("tests", "test_app.py")} {
+		if _, err := os.Stat(filepath.Join(dest, name)); err != nil {
+			t.Errorf("%s not extracted: %v", name, err)
+		}
+	}
+}
+
+func TestArchiveFetch_RejectsFileURLs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secret.tar")
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := (Archive{}).Fetch(context.Background(), "file://"+filepath.ToSlash(path), "", t.TempDir()); err == nil {
+		t.Error("Fetch() of a file URL should fail unless AllowFile is set")
+	}
+}
+
+func TestArchiveFetch_RejectsPrivateAddresses(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	defer server.Close()
+
+	_, err := Archive{}.Fetch(context.Background(), server.URL+"/project.tar.gz", "", t.TempDir())
+	if !errors.Is(err, netguard.ErrBlockedAddress) {
+		t.Errorf("Fetch() of a loopback URL error = %v, want ErrBlockedAddress", err)
+	}
+	if requests != 0 {
+		t.Error("loopback server reached")
+	}
+}
+
+func TestArchiveFetch_RejectsEntriesOutsideArchive(t *testing.T) {
+	for _, name := range []string{"../escape.txt", "/etc/escape.txt", "src/../../escape.txt"} {
+		var buf bytes.Buffer
+		tw := tar.NewWriter(&buf)
+		writeTar(t, tw, "main.go", "package main\n")
+		writeTar(t, tw, name, "escaped")
+		tw.Close()
+
+		path := filepath.Join(t.TempDir(), "src.tar")
+		if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		parent := t.TempDir()
+		dest := filepath.Join(parent, "workspace")
+		os.Mkdir(dest, 0755)
+		_, err := Archive{AllowFile: true}.Fetch(context.Background(), "file://"+filepath.ToSlash(path), "", dest)
+		if err == nil || !strings.Contains(err.Error(), "outside the archive") {
+			t.Errorf("%s: error = %v, want entry rejected", name, err)
+		}
+		if _, err := os.Stat(filepath.Join(parent, "escape.txt")); err == nil {
+			t.Errorf("%s: file written outside the workspace", name)
+		}
+	}
+}
+
+func TestArchiveFetch_SkipsLinks(t *testing.T) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	writeTar(t, tw, "main.go", "package main\n")
+	tw.WriteHeader(&tar.Header{Name: "passwd", Typeflag: tar.TypeSymlink, Linkname: "/etc/passwd"})
+	tw.Close()
+
+	path := filepath.Join(t.TempDir(), "src.tar")
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	dest := t.TempDir()
+	if _, err := (Archive{AllowFile: true}).Fetch(context.Background(), "archive+file://"+filepath.ToSlash(path), "", dest); err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if _, err := os.Lstat(filepath.Join(dest, "passwd")); err == nil {
+		t.Error("symlink extracted")
+	}
+}
+
+func writeTar(t *testing.T, tw *tar.Writer, name, content string) {
+	t.Helper()
+	hdr := &tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}
+	if strings.HasSuffix(name, "/") {
+		hdr.Typeflag, hdr.Mode = tar.TypeDir, 0755
+	}
+	if err := tw.WriteHeader(hdr); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tw.Write([]byte(content)); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,180 @@
+// Package watch watches a repository for source changes so tests can be
+// regenerated as files are edited
+package watch
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/rs/zerolog/log"
+)
+
+// DefaultDebounce is how long edits must settle before a batch is delivered
+const DefaultDebounce = 500 * time.Millisecond
+
+// Watcher watches a directory tree, delivering the files changed in it in
+// batches once edits have settled
+type Watcher struct {
+	root     string
+	debounce time.Duration
+	fs       *fsnotify.Watcher
+	match    func(path string) bool
+}
+
+// New starts watching root and the directories under it for changes to the
+// files match accepts; a nil match accepts all
+func New(root string, debounce time.Duration, match func(path string) bool) (*Watcher, error) {
+	if debounce <= 0 {
+		debounce = DefaultDebounce
+	}
+
+	fs, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create watcher: %w", err)
+	}
+
+	w := &Watcher{root: root, debounce: debounce, fs: fs, match: match}
+	if _, err := w.addTree(root); err != nil {
+		fs.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+// Close stops watching
+func (w *Watcher) Close() error {
+	return w.fs.Close()
+}
+
+// Run delivers batches of changed files to handle until ctx is done. Edits
+// made while handle runs are collected into the next batch.
+func (w *Watcher) Run(ctx context.Context, handle func(ctx context.Context, files []string)) error {
+	batches := make(chan []string)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for batch := range batches {
+			handle(ctx, batch)
+		}
+	}()
+	defer func() {
+		close(batches)
+		<-done
+	}()
+
+	pending := make(map[string]bool)
+	timer := time.NewTimer(w.debounce)
+	timer.Stop()
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case event, ok := <-w.fs.Events:
+			if !ok {
+				return nil
+			}
+			if w.collect(event, pending) {
+				timer.Reset(w.debounce)
+			}
+
+		case err, ok := <-w.fs.Errors:
+			if !ok {
+				return nil
+			}
+			log.Warn().Err(err).Msg("file watcher error")
+
+		case <-timer.C:
+			if len(pending) == 0 {
+				continue
+			}
+			batch := make([]string, 0, len(pending))
+			for path := range pending {
+				batch = append(batch, path)
+			}
+			sort.Strings(batch)
+
+			// Keep collecting while the last batch is still being handled
+			select {
+			case batches <- batch:
+				pending = make(map[string]bool)
+			default:
+				timer.Reset(w.debounce)
+			}
+		}
+	}
+}
+
+// collect adds the files an event changed to pending, reporting whether
+// there were any
+func (w *Watcher) collect(event fsnotify.Event, pending map[string]bool) bool {
+	if !event.Has(fsnotify.Create) && !event.Has(fsnotify.Write) {
+		return false // removed or renamed away; nothing to regenerate
+	}
+
+	info, err := os.Stat(event.Name)
+	if err != nil {
+		return false
+	}
+
+	// A new directory: watch it, and take the files already written to it
+	if info.IsDir() {
+		files, err := w.addTree(event.Name)
+		if err != nil {
+			log.Warn().Err(err).Str("dir", event.Name).Msg("failed to watch directory")
+		}
+		for _, f := range files {
+			pending[f] = true
+		}
+		return len(files) > 0
+	}
+
+	if !w.matches(event.Name) {
+		return false
+	}
+	pending[event.Name] = true
+	return true
+}
+
+// addTree watches dir and the directories under it, returning the matching
+// files in them
+func (w *Watcher) addTree(dir string) ([]string, error) {
+	var files []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if !info.IsDir() {
+			if w.matches(path) {
+				files = append(files, path)
+			}
+			return nil
+		}
+		if path != w.root && SkipDir(info.Name()) {
+			return filepath.SkipDir
+		}
+		if err := w.fs.Add(path); err != nil {
+			return fmt.Errorf("failed to watch %s: %w", path, err)
+		}
+		return nil
+	})
+	return files, err
+}
+
+func (w *Watcher) matches(path string) bool {
+	return w.match == nil || w.match(path)
+}
+
+// SkipDir reports whether a directory is never watched: hidden and
+// dependency directories
+func SkipDir(name string) bool {
+	return strings.HasPrefix(name, ".") || name == "node_modules" || name == "vendor" || name == "__pycache__"
+}
@@ -0,0 +1,104 @@
+package watch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func goFiles(path string) bool {
+	return strings.HasSuffix(path, ".go") && !strings.HasSuffix(path, "_test.go")
+}
+
+func startWatcher(t *testing.T, root string) <-chan []string {
+	t.Helper()
+
+	w, err := New(root, 50*time.Millisecond, goFiles)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	t.Cleanup(func() { w.Close() })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	batches := make(chan []string, 10)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		w.Run(ctx, func(_ context.Context, files []string) {
+			batches <- files
+		})
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	return batches
+}
+
+func nextBatch(t *testing.T, batches <-chan []string) []string {
+	t.Helper()
+	select {
+	case batch := <-batches:
+		return batch
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for a batch")
+		return nil
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWatcher_DebouncesEdits(t *testing.T) {
+	root := t.TempDir()
+	batches := startWatcher(t, root)
+
+	a := filepath.Join(root, "a.go")
+	b := filepath.Join(root, "b.go")
+	for i := 0; i < 5; i++ {
+		writeFile(t, a, strings.Repeat("x", i))
+	}
+	writeFile(t, b, "package b")
+	writeFile(t, filepath.Join(root, "a_test.go"), "package a")
+	writeFile(t, filepath.Join(root, "notes.txt"), "ignored")
+
+	batch := nextBatch(t, batches)
+	if strings.Join(batch, ",") != a+","+b {
+		t.Errorf("batch = %v, want [%s %s]", batch, a, b)
+	}
+
+	select {
+	case extra := <-batches:
+		t.Errorf("unexpected extra batch %v", extra)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestWatcher_NewDirectories(t *testing.T) {
+	root := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, "node_modules"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	batches := startWatcher(t, root)
+
+	writeFile(t, filepath.Join(root, "node_modules", "dep.go"), "package dep")
+
+	dir := filepath.Join(root, "pkg", "sub")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(dir, "c.go")
+	writeFile(t, file, "package sub")
+
+	batch := nextBatch(t, batches)
+	if len(batch) != 1 || batch[0] != file {
+		t.Errorf("batch = %v, want [%s]", batch, file)
+	}
+}
@@ -0,0 +1,288 @@
+// Package webhook notifies the webhooks registered for a repository when its
+// ingestion, generation and mutation jobs complete or fail
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	"github.com/QTest-hq/qtest/internal/db"
+	"github.com/QTest-hq/qtest/internal/jobs"
+	"github.com/QTest-hq/qtest/internal/netguard"
+)
+
+// Headers sent with each delivery
+const (
+	HeaderEvent     = "X-QTest-Event"
+	HeaderDelivery  = "X-QTest-Delivery"
+	HeaderSignature = "X-QTest-Signature" // "sha256=" and the hex HMAC-SHA256 of the body
+)
+
+// deliveryAttempts is how many times a delivery is tried before it's given
+// up; only network errors, 429s and 5xxs are retried
+const deliveryAttempts = 3
+
+// deliveryTimeout bounds each delivery attempt
+const deliveryTimeout = 10 * time.Second
+
+// notifiedTypes are the job types whose outcomes are delivered
+var notifiedTypes = []jobs.JobType{jobs.JobTypeIngestion, jobs.JobTypeGeneration, jobs.JobTypeMutation}
+
+// Payload is the JSON body POSTed to a webhook
+type Payload struct {
+	Event           string          `json:"event"` // e.g. generation.completed
+	JobID           uuid.UUID       `json:"job_id"`
+	JobType         jobs.JobType    `json:"job_type"`
+	Status          jobs.JobStatus  `json:"status"`
+	RepositoryID    uuid.UUID       `json:"repository_id"`
+	Repository      string          `json:"repository,omitempty"` // the repository's URL
+	GenerationRunID *uuid.UUID      `json:"generation_run_id,omitempty"`
+	Error           string          `json:"error,omitempty"`
+	Result          json.RawMessage `json:"result,omitempty"`  // the job's result
+	Summary         json.RawMessage `json:"summary,omitempty"` // the generation run's summary
+	Time            time.Time       `json:"time"`
+}
+
+// Store is the part of db.Store the notifier reads
+type Store interface {
+	ListWebhooks(ctx context.Context, repoID uuid.UUID) ([]db.Webhook, error)
+	GetRepository(ctx context.Context, id uuid.UUID) (*db.Repository, error)
+	GetRepositoryByURL(ctx context.Context, url string) (*db.Repository, error)
+	GetGenerationRun(ctx context.Context, id uuid.UUID) (*db.GenerationRun, error)
+}
+
+// Notifier delivers job outcomes to webhooks
+type Notifier struct {
+	store   Store
+	client  *http.Client
+	backoff time.Duration // before the first retry, doubling after
+	wg      sync.WaitGroup
+}
+
+// NewNotifier creates a notifier reading webhooks from store
+func NewNotifier(store Store) *Notifier {
+	return &Notifier{
+		store:   store,
+		client:  &http.Client{Timeout: deliveryTimeout, Transport: netguard.NewTransport(deliveryTimeout)},
+		backoff: 2 * time.Second,
+	}
+}
+
+// Event is the event name of a job outcome, e.g. generation.completed
+func Event(jobType jobs.JobType, status jobs.JobStatus) string {
+	return string(jobType) + "." + string(status)
+}
+
+// Events lists the event names webhooks can subscribe to
+func Events() []string {
+	var events []string
+	for _, t := range notifiedTypes {
+		events = append(events, Event(t, jobs.StatusCompleted), Event(t, jobs.StatusFailed))
+	}
+	return events
+}
+
+// Sign returns the signature header value of a delivery body
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// NewSecret generates a signing secret for a new webhook
+func NewSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// JobFinished notifies the webhooks of a job's repository that the job
+// completed or failed. Deliveries run in the background; jobs of other
+// types or still in progress are ignored.
+func (n *Notifier) JobFinished(ctx context.Context, job *jobs.Job) {
+	if n == nil || !notified(job) {
+		return
+	}
+
+	repo := n.repository(ctx, job)
+	if repo == nil {
+		return
+	}
+	hooks, err := n.store.ListWebhooks(ctx, repo.ID)
+	if err != nil {
+		log.Warn().Err(err).Str("job_id", job.ID.String()).Msg("failed to list webhooks")
+		return
+	}
+
+	payload := n.payload(ctx, job, repo)
+	body, err := json.Marshal(payload)
+	if err != nil {
+		log.Warn().Err(err).Str("job_id", job.ID.String()).Msg("failed to encode webhook payload")
+		return
+	}
+
+	for _, hook := range hooks {
+		if !subscribed(hook, payload.Event) {
+			continue
+		}
+		n.wg.Add(1)
+		go func(hook db.Webhook) {
+			defer n.wg.Done()
+			n.deliver(context.WithoutCancel(ctx), hook, payload.Event, body)
+		}(hook)
+	}
+}
+
+// notified reports whether a job's outcome is delivered to webhooks
+func notified(job *jobs.Job) bool {
+	if job.Status != jobs.StatusCompleted && job.Status != jobs.StatusFailed {
+		return false
+	}
+	for _, t := range notifiedTypes {
+		if job.Type == t {
+			return true
+		}
+	}
+	return false
+}
+
+// subscribed reports whether a webhook receives an event
+func subscribed(hook db.Webhook, event string) bool {
+	if len(hook.Events) == 0 {
+		return true
+	}
+	for _, e := range hook.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Wait waits for deliveries in progress
+func (n *Notifier) Wait() {
+	if n != nil {
+		n.wg.Wait()
+	}
+}
+
+// repository finds a job's repository. Ingestion jobs may not have one
+// until they complete, so their result or repository URL is used.
+func (n *Notifier) repository(ctx context.Context, job *jobs.Job) *db.Repository {
+	var repo *db.Repository
+	var err error
+	switch {
+	case job.RepositoryID != nil:
+		repo, err = n.store.GetRepository(ctx, *job.RepositoryID)
+	case job.Type == jobs.JobTypeIngestion:
+		var result jobs.IngestionResult
+		if job.Result != nil && json.Unmarshal(*job.Result, &result) == nil && result.RepositoryID != uuid.Nil {
+			repo, err = n.store.GetRepository(ctx, result.RepositoryID)
+			break
+		}
+		var payload jobs.IngestionPayload
+		if job.GetPayload(&payload) == nil && payload.RepositoryURL != "" {
+			repo, err = n.store.GetRepositoryByURL(ctx, payload.RepositoryURL)
+		}
+	}
+	if err != nil {
+		log.Warn().Err(err).Str("job_id", job.ID.String()).Msg("failed to find the job's repository for webhooks")
+	}
+	return repo
+}
+
+func (n *Notifier) payload(ctx context.Context, job *jobs.Job, repo *db.Repository) *Payload {
+	p := &Payload{
+		Event:           Event(job.Type, job.Status),
+		JobID:           job.ID,
+		JobType:         job.Type,
+		Status:          job.Status,
+		RepositoryID:    repo.ID,
+		Repository:      repo.URL,
+		GenerationRunID: job.GenerationRunID,
+		Time:            time.Now().UTC(),
+	}
+	if job.ErrorMessage != nil {
+		p.Error = *job.ErrorMessage
+	}
+	if job.Result != nil {
+		p.Result = *job.Result
+	}
+	if job.GenerationRunID != nil {
+		run, err := n.store.GetGenerationRun(ctx, *job.GenerationRunID)
+		if err != nil {
+			log.Warn().Err(err).Str("job_id", job.ID.String()).Msg("failed to get run summary for webhooks")
+		} else if run != nil && run.Summary != nil {
+			p.Summary = *run.Summary
+		}
+	}
+	return p
+}
+
+// deliver POSTs a payload to a webhook, retrying failures that may pass
+func (n *Notifier) deliver(ctx context.Context, hook db.Webhook, event string, body []byte) {
+	logger := log.With().Str("webhook_id", hook.ID.String()).Str("event", event).Logger()
+	deliveryID := uuid.New().String()
+	backoff := n.backoff
+
+	for attempt := 1; ; attempt++ {
+		retry, err := n.post(ctx, hook, event, deliveryID, body)
+		if err == nil {
+			logger.Debug().Int("attempt", attempt).Msg("delivered webhook")
+			return
+		}
+		if !retry || attempt == deliveryAttempts {
+			logger.Warn().Err(err).Int("attempts", attempt).Msg("webhook delivery failed")
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// post makes one delivery attempt, reporting whether a failure is worth
+// retrying
+func (n *Notifier) post(ctx context.Context, hook db.Webhook, event, deliveryID string, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "QTest-Webhook")
+	req.Header.Set(HeaderEvent, event)
+	req.Header.Set(HeaderDelivery, deliveryID)
+	req.Header.Set(HeaderSignature, Sign(hook.Secret, body))
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return !errors.Is(err, netguard.ErrBlockedAddress), fmt.Errorf("failed to post webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("webhook returned %s", resp.Status)
+}
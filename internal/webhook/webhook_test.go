@@ -0,0 +1,234 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/QTest-hq/qtest/internal/db"
+	"github.com/QTest-hq/qtest/internal/jobs"
+)
+
+type fakeStore struct {
+	repo  *db.Repository
+	hooks []db.Webhook
+	runs  map[uuid.UUID]*db.GenerationRun
+}
+
+func (s *fakeStore) ListWebhooks(ctx context.Context, repoID uuid.UUID) ([]db.Webhook, error) {
+	if s.repo == nil || repoID != s.repo.ID {
+		return nil, nil
+	}
+	return s.hooks, nil
+}
+
+func (s *fakeStore) GetRepository(ctx context.Context, id uuid.UUID) (*db.Repository, error) {
+	if s.repo != nil && id == s.repo.ID {
+		return s.repo, nil
+	}
+	return nil, nil
+}
+
+func (s *fakeStore) GetRepositoryByURL(ctx context.Context, url string) (*db.Repository, error) {
+	if s.repo != nil && url == s.repo.URL {
+		return s.repo, nil
+	}
+	return nil, nil
+}
+
+func (s *fakeStore) GetGenerationRun(ctx context.Context, id uuid.UUID) (*db.GenerationRun, error) {
+	return s.runs[id], nil
+}
+
+type delivery struct {
+	header http.Header
+	body   []byte
+}
+
+// receiver records deliveries, answering with statuses in turn
+func receiver(t *testing.T, statuses ...int) (*httptest.Server, func() []delivery) {
+	t.Helper()
+	var mu sync.Mutex
+	var got []delivery
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		status := http.StatusOK
+		if len(got) < len(statuses) {
+			status = statuses[len(got)]
+		}
+		got = append(got, delivery{header: r.Header.Clone(), body: body})
+		mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, func() []delivery {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]delivery(nil), got...)
+	}
+}
+
+func newTestNotifier(store Store) *Notifier {
+	n := NewNotifier(store)
+	n.backoff = time.Millisecond
+	n.client = &http.Client{Timeout: deliveryTimeout} // receivers are on loopback
+	return n
+}
+
+func finishedJob(jobType jobs.JobType, status jobs.JobStatus, repoID *uuid.UUID) *jobs.Job {
+	return &jobs.Job{ID: uuid.New(), Type: jobType, Status: status, RepositoryID: repoID}
+}
+
+func TestNotifier_SignedDelivery(t *testing.T) {
+	srv, deliveries := receiver(t)
+	repo := &db.Repository{ID: uuid.New(), URL: "https://github.com/acme/shop"}
+	runID := uuid.New()
+	summary := json.RawMessage(`{"tests_generated":12}`)
+	store := &fakeStore{
+		repo:  repo,
+		hooks: []db.Webhook{{ID: uuid.New(), RepositoryID: repo.ID, URL: srv.URL, Secret: "s3cret"}},
+		runs:  map[uuid.UUID]*db.GenerationRun{runID: {ID: runID, Summary: &summary}},
+	}
+
+	n := newTestNotifier(store)
+	job := finishedJob(jobs.JobTypeGeneration, jobs.StatusCompleted, &repo.ID)
+	job.GenerationRunID = &runID
+	n.JobFinished(context.Background(), job)
+	n.Wait()
+
+	got := deliveries()
+	if len(got) != 1 {
+		t.Fatalf("got %d deliveries, want 1", len(got))
+	}
+	d := got[0]
+	if d.header.Get(HeaderEvent) != "generation.completed" {
+		t.Errorf("%s = %q", HeaderEvent, d.header.Get(HeaderEvent))
+	}
+	if d.header.Get(HeaderSignature) != Sign("s3cret", d.body) {
+		t.Errorf("%s = %q, doesn't match the body", HeaderSignature, d.header.Get(HeaderSignature))
+	}
+
+	var payload Payload
+	if err := json.Unmarshal(d.body, &payload); err != nil {
+		t.Fatalf("invalid payload: %v", err)
+	}
+	if payload.JobID != job.ID || payload.RepositoryID != repo.ID || payload.Repository != repo.URL {
+		t.Errorf("payload = %+v", payload)
+	}
+	if string(payload.Summary) != `{"tests_generated":12}` {
+		t.Errorf("payload summary = %s, want the run summary", payload.Summary)
+	}
+}
+
+func TestNotifier_Filtering(t *testing.T) {
+	srv, deliveries := receiver(t)
+	repo := &db.Repository{ID: uuid.New(), URL: "https://github.com/acme/shop"}
+	store := &fakeStore{
+		repo: repo,
+		hooks: []db.Webhook{
+			{ID: uuid.New(), URL: srv.URL, Events: []string{"mutation.failed"}},
+		},
+	}
+	n := newTestNotifier(store)
+
+	ctx := context.Background()
+	n.JobFinished(ctx, finishedJob(jobs.JobTypeMutation, jobs.StatusCompleted, &repo.ID)) // not subscribed
+	n.JobFinished(ctx, finishedJob(jobs.JobTypeMutation, jobs.StatusRunning, &repo.ID))   // not finished
+	n.JobFinished(ctx, finishedJob(jobs.JobTypePlanning, jobs.StatusFailed, &repo.ID))    // not notified
+	failed := finishedJob(jobs.JobTypeMutation, jobs.StatusFailed, &repo.ID)
+	msg := "mutation tool crashed"
+	failed.ErrorMessage = &msg
+	n.JobFinished(ctx, failed)
+	n.Wait()
+
+	got := deliveries()
+	if len(got) != 1 {
+		t.Fatalf("got %d deliveries, want 1", len(got))
+	}
+	var payload Payload
+	json.Unmarshal(got[0].body, &payload)
+	if payload.Event != "mutation.failed" || payload.Error != msg {
+		t.Errorf("payload = %+v", payload)
+	}
+}
+
+func TestNotifier_Retries(t *testing.T) {
+	repo := &db.Repository{ID: uuid.New()}
+
+	tests := []struct {
+		name     string
+		statuses []int
+		want     int
+	}{
+		{"server error retried", []int{500, 502}, 3},
+		{"gives up", []int{503, 503, 503, 503}, deliveryAttempts},
+		{"client error not retried", []int{400}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, deliveries := receiver(t, tt.statuses...)
+			n := newTestNotifier(&fakeStore{repo: repo, hooks: []db.Webhook{{ID: uuid.New(), URL: srv.URL}}})
+			n.JobFinished(context.Background(), finishedJob(jobs.JobTypeIngestion, jobs.StatusCompleted, &repo.ID))
+			n.Wait()
+			if got := len(deliveries()); got != tt.want {
+				t.Errorf("got %d attempts, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNotifier_IngestionRepository(t *testing.T) {
+	var count atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count.Add(1)
+	}))
+	defer srv.Close()
+
+	repo := &db.Repository{ID: uuid.New(), URL: "https://github.com/acme/shop"}
+	n := newTestNotifier(&fakeStore{repo: repo, hooks: []db.Webhook{{ID: uuid.New(), URL: srv.URL}}})
+
+	// Completed ingestion reports the repository it created
+	completed := finishedJob(jobs.JobTypeIngestion, jobs.StatusCompleted, nil)
+	result := json.RawMessage(`{"repository_id":"` + repo.ID.String() + `"}`)
+	completed.Result = &result
+	n.JobFinished(context.Background(), completed)
+
+	// Failed ingestion only has the URL it was asked for
+	failed := finishedJob(jobs.JobTypeIngestion, jobs.StatusFailed, nil)
+	failed.Payload = json.RawMessage(`{"repository_url":"` + repo.URL + `"}`)
+	n.JobFinished(context.Background(), failed)
+
+	n.Wait()
+	if count.Load() != 2 {
+		t.Errorf("got %d deliveries, want 2", count.Load())
+	}
+}
+
+func TestEvents(t *testing.T) {
+	events := Events()
+	if len(events) != 6 || events[0] != "ingestion.completed" || events[1] != "ingestion.failed" {
+		t.Errorf("Events() = %v", events)
+	}
+}
+
+func TestNotifier_RefusesBlockedAddresses(t *testing.T) {
+	srv, deliveries := receiver(t)
+	repo := &db.Repository{ID: uuid.New()}
+	n := NewNotifier(&fakeStore{repo: repo, hooks: []db.Webhook{{ID: uuid.New(), URL: srv.URL}}})
+	n.backoff = time.Millisecond
+
+	n.JobFinished(context.Background(), finishedJob(jobs.JobTypeIngestion, jobs.StatusCompleted, &repo.ID))
+	n.Wait()
+	if got := len(deliveries()); got != 0 {
+		t.Errorf("got %d deliveries to a loopback address, want none", got)
+	}
+}
@@ -3,30 +3,84 @@ package worker
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/nats-io/nats.go"
 	"github.com/nats-io/nats.go/jetstream"
 	"github.com/rs/zerolog/log"
 
 	"github.com/QTest-hq/qtest/internal/config"
 	"github.com/QTest-hq/qtest/internal/jobs"
+	"github.com/QTest-hq/qtest/internal/llm"
 	qtestnats "github.com/QTest-hq/qtest/internal/nats"
+	"github.com/QTest-hq/qtest/internal/webhook"
 )
 
 // BaseWorker provides common functionality for all workers
 type BaseWorker struct {
-	cfg        *config.Config
-	workerID   string
-	jobType    jobs.JobType
-	repo       *jobs.Repository
-	nats       *qtestnats.Client
-	pipeline   *jobs.Pipeline
-	consumer   jetstream.Consumer
-	handler    JobHandler
-	pollPeriod time.Duration
-	lockTime   time.Duration
+	cfg         *config.Config
+	workerID    string
+	jobType     jobs.JobType
+	repo        *jobs.Repository
+	nats        *qtestnats.Client
+	requeue     publisher // republishes messages deferred too often
+	pipeline    *jobs.Pipeline
+	webhooks    *webhook.Notifier
+	consumers   []jetstream.Consumer
+	interactive []jetstream.Consumer // interactive lane twins of consumers
+	handler     JobHandler
+	caps        *Capabilities
+	pollPeriod  time.Duration
+	lockTime    time.Duration
+
+	// maxStreak is how many interactive jobs in a row the worker takes
+	// before another lane's; streak counts them
+	maxStreak int
+	streak    int
+
+	// limits caps running jobs per repository and lane, across workers
+	limits jobs.ConcurrencyLimits
+
+	// running holds the cancel functions of the jobs being processed, for
+	// cancellations published over NATS
+	mu      sync.Mutex
+	running map[uuid.UUID]func()
+}
+
+// pendingBatch is how many pending jobs a polling worker looks at to find
+// one it can run
+const pendingBatch = 10
+
+// flushTimeout bounds checkpoint and release writes made after the worker's
+// context is cancelled
+const flushTimeout = 10 * time.Second
+
+// errInterrupted is returned for jobs released back to the queue because
+// the worker is shutting down
+var errInterrupted = errors.New("worker shutting down, job released")
+
+// interactiveWait is how long a worker checks its interactive consumers
+// for a job before its other consumers
+const interactiveWait = 100 * time.Millisecond
+
+// unsupportedDelay is how long a job this worker can't run waits before
+// NATS redelivers it, to a capable worker. However many incapable workers
+// see it first, it's requeued rather than dropped.
+const unsupportedDelay = 30 * time.Second
+
+// limitedDelay is how long a job whose repository or lane is at its
+// concurrency limit waits before NATS redelivers it
+const limitedDelay = 10 * time.Second
+
+// publisher publishes job messages; *qtestnats.Client
+type publisher interface {
+	Publish(ctx context.Context, subject string, data []byte) (*jetstream.PubAck, error)
 }
 
 // JobHandler is the function type for processing jobs
@@ -41,6 +95,11 @@ type BaseWorkerConfig struct {
 	NATS       *qtestnats.Client
 	Pipeline   *jobs.Pipeline
 	Handler    JobHandler
+	Webhooks   *webhook.Notifier // notified when jobs complete or fail; may be nil
+
+	// Capabilities restricts the worker to jobs whose tools are installed;
+	// nil accepts every job
+	Capabilities *Capabilities
 }
 
 // NewBaseWorker creates a new base worker
@@ -50,16 +109,44 @@ func NewBaseWorker(cfg BaseWorkerConfig) *BaseWorker {
 		workerID = fmt.Sprintf("%s-%s", cfg.JobType, uuid.New().String()[:8])
 	}
 
-	return &BaseWorker{
+	maxStreak := 0
+	var limits jobs.ConcurrencyLimits
+	if cfg.Config != nil {
+		maxStreak = cfg.Config.Lanes.InteractiveStreak
+		limits = concurrencyLimits(cfg.Config.Lanes)
+	}
+
+	w := &BaseWorker{
 		cfg:        cfg.Config,
 		workerID:   workerID,
 		jobType:    cfg.JobType,
 		repo:       cfg.Repository,
 		nats:       cfg.NATS,
 		pipeline:   cfg.Pipeline,
+		webhooks:   cfg.Webhooks,
 		handler:    cfg.Handler,
+		caps:       cfg.Capabilities,
 		pollPeriod: 5 * time.Second,
 		lockTime:   5 * time.Minute,
+		maxStreak:  maxStreak,
+		limits:     limits,
+		running:    make(map[uuid.UUID]func()),
+	}
+	if cfg.NATS != nil {
+		w.requeue = cfg.NATS
+	}
+	return w
+}
+
+// concurrencyLimits reads the running job limits from the lanes config
+func concurrencyLimits(cfg config.LanesConfig) jobs.ConcurrencyLimits {
+	return jobs.ConcurrencyLimits{
+		PerRepository: cfg.RepositoryConcurrency,
+		PerLane: map[jobs.Lane]int{
+			jobs.LaneInteractive: cfg.InteractiveConcurrency,
+			jobs.LaneDefault:     cfg.DefaultConcurrency,
+			jobs.LaneBatch:       cfg.BatchConcurrency,
+		},
 	}
 }
 
@@ -70,15 +157,37 @@ func (w *BaseWorker) Run(ctx context.Context) error {
 		Str("job_type", string(w.jobType)).
 		Logger()
 
-	// Try to set up NATS consumer
+	// Try to set up NATS consumers: the job type's, plus one per toolchain
+	// this worker can run
 	if w.nats != nil && w.nats.IsConnected() {
-		consumerName := qtestnats.ConsumerForJobType(string(w.jobType))
-		consumer, err := w.nats.JetStream().Consumer(ctx, qtestnats.StreamJobs, consumerName)
+		for _, consumerName := range w.consumerNames() {
+			consumer, err := w.nats.JetStream().Consumer(ctx, qtestnats.StreamJobs, consumerName)
+			if err != nil {
+				logger.Warn().Err(err).Str("consumer", consumerName).Msg("failed to get consumer")
+				continue
+			}
+			w.consumers = append(w.consumers, consumer)
+
+			interactiveName := qtestnats.InteractiveConsumer(consumerName)
+			interactive, err := w.nats.JetStream().Consumer(ctx, qtestnats.StreamJobs, interactiveName)
+			if err != nil {
+				logger.Warn().Err(err).Str("consumer", interactiveName).Msg("failed to get consumer")
+				continue
+			}
+			w.interactive = append(w.interactive, interactive)
+		}
+		if len(w.consumers) == 0 {
+			logger.Warn().Msg("no NATS consumers, falling back to polling")
+		} else {
+			logger.Info().Int("consumers", len(w.consumers)).Msg("connected to NATS consumers")
+		}
+
+		// Stop running jobs as soon as they're cancelled
+		sub, err := w.nats.Conn().Subscribe(qtestnats.SubjectCancelAll, w.handleCancel)
 		if err != nil {
-			logger.Warn().Err(err).Msg("failed to get consumer, falling back to polling")
+			logger.Warn().Err(err).Msg("failed to subscribe to job cancellations")
 		} else {
-			w.consumer = consumer
-			logger.Info().Msg("connected to NATS consumer")
+			defer sub.Unsubscribe()
 		}
 	}
 
@@ -101,7 +210,7 @@ func (w *BaseWorker) Run(ctx context.Context) error {
 // processNext fetches and processes the next available job
 func (w *BaseWorker) processNext(ctx context.Context) error {
 	// Try NATS first if available
-	if w.consumer != nil {
+	if len(w.consumers) > 0 {
 		return w.processFromNATS(ctx)
 	}
 
@@ -109,20 +218,69 @@ func (w *BaseWorker) processNext(ctx context.Context) error {
 	return w.processFromDB(ctx)
 }
 
-// processFromNATS fetches jobs via NATS JetStream
+// consumerNames returns the consumers this worker reads: the job type's and,
+// for routed job types, those of the toolchains it can run
+func (w *BaseWorker) consumerNames() []string {
+	jobType := string(w.jobType)
+	names := []string{qtestnats.ConsumerForJobType(jobType)}
+
+	toolchains := qtestnats.RoutedToolchains
+	if w.caps != nil {
+		toolchains = w.caps.Toolchains(w.jobType)
+	}
+	for _, tc := range toolchains {
+		if name := qtestnats.ConsumerForToolchain(jobType, tc); name != names[0] {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// processFromNATS fetches jobs via NATS JetStream: an interactive job if one
+// is waiting and the streak limit allows, otherwise waiting on each consumer
+// in turn
 func (w *BaseWorker) processFromNATS(ctx context.Context) error {
+	if !w.streakFull() {
+		for _, consumer := range w.interactive {
+			ran, err := w.fetchFromConsumer(ctx, consumer, interactiveWait)
+			if err != nil || ran {
+				return err
+			}
+		}
+	}
+
+	ran := false
+	wait := w.pollPeriod / time.Duration(len(w.consumers))
+	for _, consumer := range w.consumers {
+		got, err := w.fetchFromConsumer(ctx, consumer, wait)
+		if err != nil {
+			return err
+		}
+		ran = ran || got
+	}
+	if !ran {
+		// Nothing else is waiting, interactive jobs may go on
+		w.streak = 0
+	}
+	return nil
+}
+
+// fetchFromConsumer runs the next job from consumer, reporting whether it
+// ran one
+func (w *BaseWorker) fetchFromConsumer(ctx context.Context, consumer jetstream.Consumer, wait time.Duration) (bool, error) {
 	// Fetch with timeout
-	fetchCtx, cancel := context.WithTimeout(ctx, w.pollPeriod)
+	fetchCtx, cancel := context.WithTimeout(ctx, wait)
 	defer cancel()
 
-	msgs, err := w.consumer.Fetch(1, jetstream.FetchMaxWait(w.pollPeriod))
+	msgs, err := consumer.Fetch(1, jetstream.FetchMaxWait(wait))
 	if err != nil {
 		if err == context.DeadlineExceeded || fetchCtx.Err() != nil {
-			return nil // Normal timeout, no jobs available
+			return false, nil // Normal timeout, no jobs available
 		}
-		return fmt.Errorf("failed to fetch from NATS: %w", err)
+		return false, fmt.Errorf("failed to fetch from NATS: %w", err)
 	}
 
+	ran := false
 	for msg := range msgs.Messages() {
 		jobMsg, err := jobs.DecodeJobMessage(msg.Data())
 		if err != nil {
@@ -131,8 +289,23 @@ func (w *BaseWorker) processFromNATS(ctx context.Context) error {
 			continue
 		}
 
+		// Leave jobs needing tools this worker lacks to capable workers
+		if w.caps != nil {
+			pending, err := w.repo.GetByID(ctx, jobMsg.JobID)
+			if err == nil && pending != nil && !w.caps.Supports(pending) {
+				w.logUnsupported(pending)
+				w.deferMessage(ctx, msg, unsupportedDelay)
+				continue
+			}
+		}
+
 		// Claim the job from DB
-		job, err := w.repo.Claim(ctx, jobMsg.JobID, w.workerID, w.lockTime)
+		job, err := w.repo.ClaimWithinLimits(ctx, jobMsg.JobID, w.workerID, w.lockTime, w.limits)
+		if errors.Is(err, jobs.ErrConcurrencyLimit) {
+			w.logLimited(jobMsg.JobID, err)
+			w.deferMessage(ctx, msg, limitedDelay)
+			continue
+		}
 		if err != nil {
 			log.Error().Err(err).Str("job_id", jobMsg.JobID.String()).Msg("failed to claim job")
 			msg.Nak()
@@ -146,7 +319,13 @@ func (w *BaseWorker) processFromNATS(ctx context.Context) error {
 		}
 
 		// Process the job
+		ran = true
 		if err := w.processJob(ctx, job); err != nil {
+			if errors.Is(err, errInterrupted) {
+				// Redeliver so another worker resumes it
+				msg.Nak()
+				continue
+			}
 			log.Error().Err(err).Str("job_id", job.ID.String()).Msg("job processing failed")
 		}
 
@@ -154,33 +333,64 @@ func (w *BaseWorker) processFromNATS(ctx context.Context) error {
 	}
 
 	if msgs.Error() != nil && msgs.Error() != context.DeadlineExceeded {
-		return msgs.Error()
+		return ran, msgs.Error()
 	}
 
-	return nil
+	return ran, nil
+}
+
+// deferMessage hands a job's message back to NATS to deliver again after
+// delay. NATS counts a NAK as a delivery and drops the message after
+// MaxDeliver of them, leaving the job pending for good, so on its last
+// delivery the message is republished as a new one instead.
+func (w *BaseWorker) deferMessage(ctx context.Context, msg jetstream.Msg, delay time.Duration) {
+	meta, err := msg.Metadata()
+	if err != nil || meta.NumDelivered < qtestnats.MaxDeliver || w.requeue == nil {
+		msg.NakWithDelay(delay)
+		return
+	}
+	if _, err := w.requeue.Publish(ctx, msg.Subject(), msg.Data()); err != nil {
+		log.Error().Err(err).Str("subject", msg.Subject()).Msg("failed to requeue job message")
+		msg.NakWithDelay(delay)
+		return
+	}
+	msg.Ack()
 }
 
 // processFromDB polls the database for pending jobs
 func (w *BaseWorker) processFromDB(ctx context.Context) error {
 	// Get pending jobs
-	pendingJobs, err := w.repo.ListPendingByType(ctx, w.jobType, 1)
+	pendingJobs, err := w.repo.ListPendingByType(ctx, w.jobType, pendingBatch)
 	if err != nil {
 		return fmt.Errorf("failed to list pending jobs: %w", err)
 	}
 
+	// Skip jobs needing tools this worker lacks
+	supported := pendingJobs[:0]
+	for _, pending := range pendingJobs {
+		if w.caps.Supports(pending) {
+			supported = append(supported, pending)
+		} else {
+			w.logUnsupported(pending)
+		}
+	}
+	pendingJobs = w.schedule(supported)
+
 	if len(pendingJobs) == 0 {
 		// No jobs, wait before polling again
-		select {
-		case <-ctx.Done():
-			return nil
-		case <-time.After(w.pollPeriod):
-			return nil
-		}
+		w.waitPoll(ctx)
+		return nil
 	}
 
+	limited := false
 	for _, pending := range pendingJobs {
 		// Try to claim the job
-		job, err := w.repo.Claim(ctx, pending.ID, w.workerID, w.lockTime)
+		job, err := w.repo.ClaimWithinLimits(ctx, pending.ID, w.workerID, w.lockTime, w.limits)
+		if errors.Is(err, jobs.ErrConcurrencyLimit) {
+			w.logLimited(pending.ID, err)
+			limited = true
+			continue
+		}
 		if err != nil {
 			log.Warn().Err(err).Str("job_id", pending.ID.String()).Msg("failed to claim job")
 			continue
@@ -191,14 +401,73 @@ func (w *BaseWorker) processFromDB(ctx context.Context) error {
 			continue
 		}
 
-		if err := w.processJob(ctx, job); err != nil {
+		if err := w.processJob(ctx, job); err != nil && !errors.Is(err, errInterrupted) {
 			log.Error().Err(err).Str("job_id", job.ID.String()).Msg("job processing failed")
 		}
+		return nil
 	}
 
+	if limited {
+		// The jobs left wait for running ones to finish
+		w.waitPoll(ctx)
+	}
 	return nil
 }
 
+// waitPoll waits a poll period, or until ctx is done
+func (w *BaseWorker) waitPoll(ctx context.Context) {
+	select {
+	case <-ctx.Done():
+	case <-time.After(w.pollPeriod):
+	}
+}
+
+// schedule orders pending jobs, which come highest priority first, so jobs
+// from other lanes go ahead of interactive ones once the streak limit is
+// reached
+func (w *BaseWorker) schedule(pending []*jobs.Job) []*jobs.Job {
+	if !w.streakFull() {
+		return pending
+	}
+
+	ordered := make([]*jobs.Job, 0, len(pending))
+	var interactive []*jobs.Job
+	for _, job := range pending {
+		if job.Lane() == jobs.LaneInteractive {
+			interactive = append(interactive, job)
+		} else {
+			ordered = append(ordered, job)
+		}
+	}
+	if len(ordered) == 0 {
+		// Nothing else is waiting, interactive jobs may go on
+		w.streak = 0
+	}
+	return append(ordered, interactive...)
+}
+
+// streakFull reports whether the worker has taken as many interactive jobs
+// in a row as it may
+func (w *BaseWorker) streakFull() bool {
+	return w.maxStreak > 0 && w.streak >= w.maxStreak
+}
+
+func (w *BaseWorker) logUnsupported(job *jobs.Job) {
+	log.Debug().
+		Str("worker_id", w.workerID).
+		Str("job_id", job.ID.String()).
+		Strs("missing_tools", w.caps.Missing(job.RequiredTools())).
+		Msg("skipping job, required tools not installed")
+}
+
+func (w *BaseWorker) logLimited(jobID uuid.UUID, err error) {
+	log.Debug().
+		Str("worker_id", w.workerID).
+		Str("job_id", jobID.String()).
+		Err(err).
+		Msg("leaving job pending, concurrency limit reached")
+}
+
 // processJob executes the job handler with proper error handling
 func (w *BaseWorker) processJob(ctx context.Context, job *jobs.Job) error {
 	logger := log.With().
@@ -207,15 +476,35 @@ func (w *BaseWorker) processJob(ctx context.Context, job *jobs.Job) error {
 		Str("job_type", string(job.Type)).
 		Logger()
 
-	logger.Info().Msg("processing job")
+	logger.Info().Str("lane", string(job.Lane())).Msg("processing job")
+
+	if job.Lane() == jobs.LaneInteractive {
+		w.streak++
+	} else {
+		w.streak = 0
+	}
 
 	// Create a context with timeout based on lock time
 	jobCtx, cancel := context.WithTimeout(ctx, w.lockTime-30*time.Second)
 	defer cancel()
+	if job.Lane() == jobs.LaneInteractive {
+		// Its LLM requests may use the slots held back from pipelines
+		jobCtx = llm.WithInteractive(jobCtx)
+	}
 
-	// Start lock extension goroutine
+	// Start lock extension goroutine, which stops the handler if the job
+	// is cancelled; a cancellation over NATS stops it sooner
 	done := make(chan struct{})
-	go w.extendLockPeriodically(ctx, job.ID, done)
+	var cancelled atomic.Bool
+	onCancel := func() {
+		cancelled.Store(true)
+		cancel()
+	}
+	w.trackRunning(job.ID, onCancel)
+	defer w.untrackRunning(job.ID)
+	go w.extendLockPeriodically(ctx, job.ID, done, onCancel)
+
+	w.publishStatus(job, jobs.StatusRunning, "")
 
 	// Execute the handler
 	err := w.handler(jobCtx, job)
@@ -223,20 +512,139 @@ func (w *BaseWorker) processJob(ctx context.Context, job *jobs.Job) error {
 	// Stop lock extension
 	close(done)
 
+	if cancelled.Load() || errors.Is(err, jobs.ErrJobCancelled) {
+		logger.Info().Msg("job cancelled")
+		w.publishStatus(job, jobs.StatusCancelled, "")
+		return nil
+	}
+
+	if err != nil && ctx.Err() != nil {
+		// Shutting down: the handler has checkpointed what it finished, so
+		// put the job back for another worker instead of failing it
+		logger.Warn().Err(err).Msg("job interrupted by shutdown, releasing")
+		releaseCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), flushTimeout)
+		defer cancel()
+		if releaseErr := w.repo.Release(releaseCtx, job.ID, w.workerID); releaseErr != nil {
+			logger.Error().Err(releaseErr).Msg("failed to release job")
+		}
+		w.publishStatus(job, jobs.StatusPending, "released by a worker shutting down")
+		return errInterrupted
+	}
+
 	if err != nil {
 		logger.Error().Err(err).Msg("job failed")
 		if failErr := w.repo.Fail(ctx, job.ID, err.Error(), nil); failErr != nil {
 			logger.Error().Err(failErr).Msg("failed to mark job as failed")
 		}
+		w.publishFailure(ctx, job, err)
+		w.notifyWebhooks(ctx, job)
 		return err
 	}
 
 	logger.Info().Msg("job completed")
+	w.publishStatus(job, jobs.StatusCompleted, "")
+	w.notifyWebhooks(ctx, job)
 	return nil
 }
 
-// extendLockPeriodically extends the lock while job is processing
-func (w *BaseWorker) extendLockPeriodically(ctx context.Context, jobID uuid.UUID, done chan struct{}) {
+// notifyWebhooks delivers a finished job's outcome to its repository's
+// webhooks, reading the job back for its result and final status; a failed
+// job left to retry isn't delivered
+func (w *BaseWorker) notifyWebhooks(ctx context.Context, job *jobs.Job) {
+	if w.webhooks == nil || w.repo == nil {
+		return
+	}
+	finished, err := w.repo.GetByID(ctx, job.ID)
+	if err != nil || finished == nil {
+		return
+	}
+	w.webhooks.JobFinished(ctx, finished)
+}
+
+// Progress publishes a running job's progress, e.g. the file generation
+// is on, for clients following the job. It does nothing without NATS.
+func (w *BaseWorker) Progress(job *jobs.Job, phase string, current, total int, message string) {
+	w.publishProgress(jobs.ProgressEvent{
+		JobID:   job.ID,
+		JobType: job.Type,
+		Status:  jobs.StatusRunning,
+		Phase:   phase,
+		Current: current,
+		Total:   total,
+		Message: message,
+		Time:    time.Now().UTC(),
+	})
+}
+
+// publishStatus publishes a job's change of status
+func (w *BaseWorker) publishStatus(job *jobs.Job, status jobs.JobStatus, message string) {
+	w.publishProgress(jobs.ProgressEvent{
+		JobID:   job.ID,
+		JobType: job.Type,
+		Status:  status,
+		Message: message,
+		Time:    time.Now().UTC(),
+	})
+}
+
+// publishFailure publishes the status a failed job was left in, which is
+// pending again while it has retries left
+func (w *BaseWorker) publishFailure(ctx context.Context, job *jobs.Job, err error) {
+	if w.nats == nil || !w.nats.IsConnected() {
+		return
+	}
+	status := jobs.StatusFailed
+	if failed, getErr := w.repo.GetByID(ctx, job.ID); getErr == nil && failed != nil {
+		status = failed.Status
+	}
+	w.publishStatus(job, status, err.Error())
+}
+
+func (w *BaseWorker) publishProgress(event jobs.ProgressEvent) {
+	if w == nil || w.nats == nil || !w.nats.IsConnected() {
+		return
+	}
+	data, err := json.Marshal(event)
+	if err == nil {
+		err = w.nats.Conn().Publish(qtestnats.ProgressSubject(event.JobID.String()), data)
+	}
+	if err != nil {
+		log.Debug().Err(err).Str("job_id", event.JobID.String()).Msg("failed to publish job progress")
+	}
+}
+
+// trackRunning registers the function that stops a running job
+func (w *BaseWorker) trackRunning(jobID uuid.UUID, stop func()) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.running[jobID] = stop
+}
+
+func (w *BaseWorker) untrackRunning(jobID uuid.UUID) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	delete(w.running, jobID)
+}
+
+// handleCancel stops a job this worker is running when its cancellation
+// is published; cancellations of other workers' jobs are ignored
+func (w *BaseWorker) handleCancel(msg *nats.Msg) {
+	jobID, err := uuid.Parse(qtestnats.CancelledJobID(msg.Subject))
+	if err != nil {
+		return
+	}
+	w.mu.Lock()
+	stop := w.running[jobID]
+	w.mu.Unlock()
+	if stop != nil {
+		log.Info().Str("worker_id", w.workerID).Str("job_id", jobID.String()).Msg("job cancelled, stopping")
+		stop()
+	}
+}
+
+// extendLockPeriodically extends the lock while job is processing, calling
+// onCancel and stopping if the job was cancelled
+func (w *BaseWorker) extendLockPeriodically(ctx context.Context, jobID uuid.UUID, done chan struct{}, onCancel func()) {
 	ticker := time.NewTicker(w.lockTime / 2)
 	defer ticker.Stop()
 
@@ -248,12 +656,28 @@ func (w *BaseWorker) extendLockPeriodically(ctx context.Context, jobID uuid.UUID
 			return
 		case <-ticker.C:
 			if err := w.repo.ExtendLock(ctx, jobID, w.workerID, w.lockTime); err != nil {
+				if job, getErr := w.repo.GetByID(ctx, jobID); getErr == nil && job != nil && job.Status == jobs.StatusCancelled {
+					onCancel()
+					return
+				}
 				log.Warn().Err(err).Str("job_id", jobID.String()).Msg("failed to extend lock")
 			}
 		}
 	}
 }
 
+// Checkpoint saves a running job's partial result, to resume from if the job
+// is interrupted. It writes even after ctx is cancelled, so handlers can
+// flush progress on shutdown.
+func (w *BaseWorker) Checkpoint(ctx context.Context, job *jobs.Job, result interface{}) error {
+	if w.repo == nil {
+		return nil
+	}
+	flushCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), flushTimeout)
+	defer cancel()
+	return w.repo.Checkpoint(flushCtx, job.ID, w.workerID, result)
+}
+
 // WorkerID returns the worker's unique ID
 func (w *BaseWorker) WorkerID() string {
 	return w.workerID
@@ -264,6 +688,11 @@ func (w *BaseWorker) JobType() jobs.JobType {
 	return w.jobType
 }
 
+// Capabilities returns the worker's detected tools, or nil if not detected
+func (w *BaseWorker) Capabilities() *Capabilities {
+	return w.caps
+}
+
 // SetPollPeriod sets the polling interval
 func (w *BaseWorker) SetPollPeriod(d time.Duration) {
 	w.pollPeriod = d
@@ -283,3 +712,39 @@ func (w *BaseWorker) Repository() *jobs.Repository {
 func (w *BaseWorker) Pipeline() *jobs.Pipeline {
 	return w.pipeline
 }
+
+// getIngestionPayload retrieves the ingestion payload at the root of the job
+// chain, or nil if there is none
+func (w *BaseWorker) getIngestionPayload(ctx context.Context, job *jobs.Job) *jobs.IngestionPayload {
+	current := job
+	for current.ParentJobID != nil {
+		parent, err := w.Repository().GetByID(ctx, *current.ParentJobID)
+		if err != nil || parent == nil {
+			break
+		}
+
+		if parent.Type == jobs.JobTypeIngestion {
+			var payload jobs.IngestionPayload
+			if err := parent.GetPayload(&payload); err == nil {
+				return &payload
+			}
+			break
+		}
+		current = parent
+	}
+	return nil
+}
+
+// policyRouter restricts router to the LLM providers the policy of the job
+// chain's repository allows
+func (w *BaseWorker) policyRouter(ctx context.Context, job *jobs.Job, router *llm.Router) *llm.Router {
+	if router == nil {
+		return nil
+	}
+	ingestion := w.getIngestionPayload(ctx, job)
+	if ingestion == nil || len(ingestion.Providers) == 0 {
+		return router
+	}
+	log.Debug().Strs("providers", ingestion.Providers).Msg("restricting LLM providers by policy")
+	return router.WithProviders(jobs.AllowedProviders(ingestion.Providers))
+}
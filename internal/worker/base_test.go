@@ -1,12 +1,18 @@
 package worker
 
 import (
+	"context"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/google/uuid"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+
 	"github.com/QTest-hq/qtest/internal/config"
 	"github.com/QTest-hq/qtest/internal/jobs"
+	qtestnats "github.com/QTest-hq/qtest/internal/nats"
 )
 
 func TestNewBaseWorker(t *testing.T) {
@@ -183,3 +189,185 @@ func TestBaseWorkerConfig_Defaults(t *testing.T) {
 		t.Error("pipeline should be nil when not provided")
 	}
 }
+
+func TestBaseWorker_ScheduleInteractiveStreak(t *testing.T) {
+	cfg := &config.Config{Lanes: config.LanesConfig{InteractiveStreak: 2}}
+	base := NewBaseWorker(BaseWorkerConfig{Config: cfg, JobType: jobs.JobTypeGeneration})
+
+	interactive := &jobs.Job{Priority: jobs.PriorityInteractive}
+	batch := &jobs.Job{Priority: jobs.PriorityBatch}
+	pending := []*jobs.Job{interactive, batch}
+
+	if got := base.schedule(pending); got[0] != interactive {
+		t.Error("interactive job should run first")
+	}
+
+	// After two interactive jobs in a row the batch job gets a turn
+	base.streak = 2
+	if got := base.schedule(pending); got[0] != batch || got[1] != interactive {
+		t.Errorf("schedule() with a full streak = %v, want batch job first", got)
+	}
+
+	// With nothing else waiting interactive jobs go on
+	if got := base.schedule([]*jobs.Job{interactive}); got[0] != interactive {
+		t.Error("interactive job should run when nothing else is waiting")
+	}
+	if base.streak != 0 {
+		t.Errorf("streak = %d, want 0 after nothing else was waiting", base.streak)
+	}
+}
+
+func TestBaseWorker_ScheduleUnlimited(t *testing.T) {
+	base := NewBaseWorker(BaseWorkerConfig{JobType: jobs.JobTypeGeneration})
+	base.streak = 100
+
+	interactive := &jobs.Job{Priority: jobs.PriorityInteractive}
+	batch := &jobs.Job{Priority: jobs.PriorityBatch}
+	if got := base.schedule([]*jobs.Job{interactive, batch}); got[0] != interactive {
+		t.Error("interactive jobs should always run first without a streak limit")
+	}
+}
+
+func TestNewBaseWorker_ConcurrencyLimits(t *testing.T) {
+	cfg := &config.Config{Lanes: config.LanesConfig{RepositoryConcurrency: 4, BatchConcurrency: 2}}
+	base := NewBaseWorker(BaseWorkerConfig{Config: cfg, JobType: jobs.JobTypeGeneration})
+
+	if base.limits.PerRepository != 4 || base.limits.PerLane[jobs.LaneBatch] != 2 || base.limits.PerLane[jobs.LaneInteractive] != 0 {
+		t.Errorf("limits = %+v, want 4 per repository and 2 batch jobs", base.limits)
+	}
+	if base.limits.IsZero() {
+		t.Error("limits should be set")
+	}
+	if !NewBaseWorker(BaseWorkerConfig{JobType: jobs.JobTypeGeneration}).limits.IsZero() {
+		t.Error("a worker without config should have no limits")
+	}
+}
+
+func TestBaseWorker_HandleCancel(t *testing.T) {
+	base := NewBaseWorker(BaseWorkerConfig{JobType: jobs.JobTypeGeneration})
+	running, other := uuid.New(), uuid.New()
+	stopped := 0
+	base.trackRunning(running, func() { stopped++ })
+
+	base.handleCancel(&nats.Msg{Subject: qtestnats.CancelSubject(other.String())})
+	base.handleCancel(&nats.Msg{Subject: qtestnats.CancelSubject("not-a-job")})
+	if stopped != 0 {
+		t.Fatal("cancelling another worker's job should not stop this one's")
+	}
+
+	base.handleCancel(&nats.Msg{Subject: qtestnats.CancelSubject(running.String())})
+	if stopped != 1 {
+		t.Errorf("stopped = %d, want the running job stopped", stopped)
+	}
+
+	base.untrackRunning(running)
+	base.handleCancel(&nats.Msg{Subject: qtestnats.CancelSubject(running.String())})
+	if stopped != 1 {
+		t.Error("a finished job should not be stopped again")
+	}
+}
+
+func TestProgress_WithoutNATS(t *testing.T) {
+	job, _ := jobs.NewJob(jobs.JobTypeGeneration, nil)
+
+	// Publishing progress is best effort: no NATS, or no worker in tests
+	// that build workers by hand, is a no-op
+	NewBaseWorker(BaseWorkerConfig{JobType: jobs.JobTypeGeneration}).Progress(job, "generation", 1, 2, "a.go")
+	var w *BaseWorker
+	w.Progress(job, "generation", 1, 2, "a.go")
+}
+
+// fakeQueue stands in for a JetStream consumer: NAKed messages are
+// delivered again until MaxDeliver, then dropped
+type fakeQueue struct {
+	pending []*fakeMsg
+	dropped int
+}
+
+func (q *fakeQueue) Publish(ctx context.Context, subject string, data []byte) (*jetstream.PubAck, error) {
+	q.pending = append(q.pending, &fakeMsg{queue: q, subject: subject, data: data})
+	return &jetstream.PubAck{}, nil
+}
+
+// next delivers the oldest pending message
+func (q *fakeQueue) next() *fakeMsg {
+	if len(q.pending) == 0 {
+		return nil
+	}
+	msg := q.pending[0]
+	q.pending = q.pending[1:]
+	msg.delivered++
+	return msg
+}
+
+type fakeMsg struct {
+	jetstream.Msg
+	queue     *fakeQueue
+	subject   string
+	data      []byte
+	delivered uint64
+}
+
+func (m *fakeMsg) Metadata() (*jetstream.MsgMetadata, error) {
+	return &jetstream.MsgMetadata{NumDelivered: m.delivered}, nil
+}
+func (m *fakeMsg) Subject() string { return m.subject }
+func (m *fakeMsg) Data() []byte    { return m.data }
+func (m *fakeMsg) Ack() error      { return nil }
+
+func (m *fakeMsg) NakWithDelay(time.Duration) error {
+	if m.delivered >= qtestnats.MaxDeliver {
+		m.queue.dropped++
+	} else {
+		m.queue.pending = append(m.queue.pending, m)
+	}
+	return nil
+}
+
+func TestBaseWorker_DeferMessagePastMaxDeliver(t *testing.T) {
+	queue := &fakeQueue{}
+	base := NewBaseWorker(BaseWorkerConfig{JobType: jobs.JobTypeGeneration})
+	base.requeue = queue
+	ctx := context.Background()
+	queue.Publish(ctx, qtestnats.SubjectJobGeneration, []byte(`{"job_id":"1"}`))
+
+	// The job's repository stays at its limit for three times MaxDeliver
+	// deliveries
+	for i := 0; i < 3*qtestnats.MaxDeliver; i++ {
+		msg := queue.next()
+		if msg == nil {
+			t.Fatalf("message gone after %d deliveries", i)
+		}
+		if msg.delivered > qtestnats.MaxDeliver {
+			t.Fatalf("message delivered %d times, past MaxDeliver", msg.delivered)
+		}
+		base.deferMessage(ctx, msg, limitedDelay)
+	}
+	if queue.dropped != 0 || len(queue.pending) != 1 {
+		t.Errorf("dropped = %d, pending = %d, want the job still queued once", queue.dropped, len(queue.pending))
+	}
+	if string(queue.pending[0].data) != `{"job_id":"1"}` || queue.pending[0].subject != qtestnats.SubjectJobGeneration {
+		t.Errorf("requeued %s on %s, want the original message", queue.pending[0].data, queue.pending[0].subject)
+	}
+}
+
+func TestBaseWorker_DeferMessageUntilCapableWorker(t *testing.T) {
+	queue := &fakeQueue{}
+	incapable := NewBaseWorker(BaseWorkerConfig{JobType: jobs.JobTypeIngestion})
+	incapable.requeue = queue
+	ctx := context.Background()
+	queue.Publish(ctx, qtestnats.SubjectJobIngestion, []byte(`{"job_id":"hg"}`))
+
+	// Workers without hg fetch the job more often than MaxDeliver before
+	// one with hg does
+	for i := 0; i < 2*qtestnats.MaxDeliver; i++ {
+		msg := queue.next()
+		if msg == nil {
+			t.Fatalf("message gone after %d deliveries", i)
+		}
+		incapable.deferMessage(ctx, msg, unsupportedDelay)
+	}
+	if msg := queue.next(); msg == nil || queue.dropped != 0 {
+		t.Errorf("dropped = %d, want the job left for a capable worker", queue.dropped)
+	}
+}
@@ -0,0 +1,120 @@
+package worker
+
+import (
+	"context"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/QTest-hq/qtest/internal/jobs"
+	"github.com/QTest-hq/qtest/internal/llm"
+	qtestnats "github.com/QTest-hq/qtest/internal/nats"
+	"github.com/QTest-hq/qtest/internal/platform"
+)
+
+// Capabilities are the language toolchains and tools installed on a worker
+// host. Workers only consume jobs whose tools they have.
+type Capabilities struct {
+	Hostname   string            `json:"hostname"`
+	Tools      map[string]string `json:"tools"` // tool name -> version ("" if unknown)
+	DetectedAt time.Time         `json:"detected_at"`
+}
+
+// toolProbe is a command whose success shows a tool is installed
+type toolProbe struct {
+	tool string
+	cmd  []string
+}
+
+var toolProbes = []toolProbe{
+	{jobs.ToolGo, []string{"go", "version"}},
+	{jobs.ToolNode, []string{"node", "--version"}},
+	{jobs.ToolPython, []string{"python", "--version"}},
+	{jobs.ToolPytest, []string{"python", "-m", "pytest", "--version"}},
+	{jobs.ToolGoMutesting, []string{"go-mutesting", "--help"}},
+	{jobs.ToolStryker, []string{"stryker", "--version"}},
+	{jobs.ToolHg, []string{"hg", "--version"}},
+}
+
+// runProbe runs a probe command and returns its output; replaceable in tests
+var runProbe = func(ctx context.Context, args []string) (string, error) {
+	if _, err := platform.LookPath(args[0]); err != nil {
+		return "", err
+	}
+	output, err := platform.Command(ctx, args[0], args[1:]...).CombinedOutput()
+	return string(output), err
+}
+
+var versionPattern = regexp.MustCompile(`v?\d+\.\d+(\.\d+)?`)
+
+// DetectCapabilities probes the host for the tools jobs need
+func DetectCapabilities(ctx context.Context) *Capabilities {
+	hostname, _ := os.Hostname()
+	caps := &Capabilities{
+		Hostname:   hostname,
+		Tools:      make(map[string]string),
+		DetectedAt: time.Now(),
+	}
+
+	for _, probe := range toolProbes {
+		probeCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		output, err := runProbe(probeCtx, probe.cmd)
+		cancel()
+		if err != nil {
+			continue
+		}
+		caps.Tools[probe.tool] = strings.TrimPrefix(versionPattern.FindString(output), "v")
+	}
+
+	return caps
+}
+
+// Has reports whether a tool is installed
+func (c *Capabilities) Has(tool string) bool {
+	_, ok := c.Tools[tool]
+	return ok
+}
+
+// Missing returns the tools not installed, in order
+func (c *Capabilities) Missing(tools []string) []string {
+	var missing []string
+	for _, tool := range tools {
+		if !c.Has(tool) {
+			missing = append(missing, tool)
+		}
+	}
+	return missing
+}
+
+// Supports reports whether a job's required tools are all installed. Nil
+// capabilities (not detected) support every job.
+func (c *Capabilities) Supports(job *jobs.Job) bool {
+	return c == nil || len(c.Missing(job.RequiredTools())) == 0
+}
+
+// Toolchains returns the toolchains a job type can run with, sorted
+func (c *Capabilities) Toolchains(jobType jobs.JobType) []string {
+	var toolchains []string
+	for _, tc := range qtestnats.RoutedToolchains {
+		if len(c.Missing(jobs.ToolchainTools(jobType, tc))) == 0 {
+			toolchains = append(toolchains, tc)
+		}
+	}
+	sort.Strings(toolchains)
+	return toolchains
+}
+
+// CapabilityReport is what a worker pool advertises on
+// qtestnats.SubjectWorkerCapabilities
+type CapabilityReport struct {
+	*Capabilities
+	WorkerType string              `json:"worker_type"`
+	Toolchains map[string][]string `json:"toolchains"` // job type -> toolchains it consumes
+	ReportedAt time.Time           `json:"reported_at"`
+
+	// ModelLoads is how long LLM models took to load when they weren't in
+	// memory, by model
+	ModelLoads map[string]llm.ModelLoadStats `json:"model_loads,omitempty"`
+}
@@ -0,0 +1,93 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/QTest-hq/qtest/internal/jobs"
+)
+
+func TestDetectCapabilities(t *testing.T) {
+	outputs := map[string]string{
+		"go version":                 "go version go1.22.1 linux/amd64\n",
+		"node --version":             "v20.11.0\n",
+		"python --version":           "Python 3.11.8\n",
+		"python -m pytest --version": "pytest 8.0.2\n",
+	}
+	orig := runProbe
+	runProbe = func(_ context.Context, args []string) (string, error) {
+		out, ok := outputs[strings.Join(args, " ")]
+		if !ok {
+			return "", errors.New("executable file not found")
+		}
+		return out, nil
+	}
+	defer func() { runProbe = orig }()
+
+	caps := DetectCapabilities(context.Background())
+
+	want := map[string]string{
+		jobs.ToolGo:     "1.22.1",
+		jobs.ToolNode:   "20.11.0",
+		jobs.ToolPython: "3.11.8",
+		jobs.ToolPytest: "8.0.2",
+	}
+	if !reflect.DeepEqual(caps.Tools, want) {
+		t.Errorf("Tools = %v, want %v", caps.Tools, want)
+	}
+
+	// No go-mutesting or stryker: validation runs everywhere, mutation nowhere
+	if got := caps.Toolchains(jobs.JobTypeValidation); !reflect.DeepEqual(got, []string{"go", "node", "python"}) {
+		t.Errorf("validation toolchains = %v", got)
+	}
+	if got := caps.Toolchains(jobs.JobTypeMutation); !reflect.DeepEqual(got, []string{"python"}) {
+		t.Errorf("mutation toolchains = %v", got)
+	}
+}
+
+func TestCapabilities_Supports(t *testing.T) {
+	caps := &Capabilities{Tools: map[string]string{jobs.ToolGo: "1.22"}}
+
+	goJob, _ := jobs.NewJob(jobs.JobTypeValidation, jobs.ValidationPayload{Language: "go"})
+	pyJob, _ := jobs.NewJob(jobs.JobTypeValidation, jobs.ValidationPayload{Language: "python"})
+	mutJob, _ := jobs.NewJob(jobs.JobTypeMutation, jobs.MutationPayload{SourceFilePath: "a.go"})
+
+	if !caps.Supports(goJob) {
+		t.Error("go validation should be supported")
+	}
+	if caps.Supports(pyJob) {
+		t.Error("python validation should not be supported without python")
+	}
+	if got := caps.Missing(mutJob.RequiredTools()); !reflect.DeepEqual(got, []string{jobs.ToolGoMutesting}) {
+		t.Errorf("Missing() = %v, want go-mutesting", got)
+	}
+
+	var undetected *Capabilities
+	if !undetected.Supports(pyJob) {
+		t.Error("nil capabilities should support every job")
+	}
+}
+
+func TestBaseWorker_ConsumerNames(t *testing.T) {
+	caps := &Capabilities{Tools: map[string]string{jobs.ToolGo: "1.22", jobs.ToolGoMutesting: ""}}
+
+	tests := []struct {
+		jobType jobs.JobType
+		caps    *Capabilities
+		want    []string
+	}{
+		{jobs.JobTypeMutation, caps, []string{"mutation-worker", "mutation-worker-go"}},
+		{jobs.JobTypeValidation, nil, []string{"validation-worker", "validation-worker-go", "validation-worker-node", "validation-worker-python"}},
+		{jobs.JobTypePlanning, caps, []string{"planning-worker"}},
+	}
+
+	for _, tt := range tests {
+		w := NewBaseWorker(BaseWorkerConfig{JobType: tt.jobType, Capabilities: tt.caps})
+		if got := w.consumerNames(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s consumerNames() = %v, want %v", tt.jobType, got, tt.want)
+		}
+	}
+}
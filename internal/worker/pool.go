@@ -3,7 +3,10 @@ package worker
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/rs/zerolog/log"
 
@@ -12,6 +15,7 @@ import (
 	"github.com/QTest-hq/qtest/internal/jobs"
 	"github.com/QTest-hq/qtest/internal/llm"
 	qtestnats "github.com/QTest-hq/qtest/internal/nats"
+	"github.com/QTest-hq/qtest/internal/webhook"
 )
 
 // WorkerType represents the type of worker
@@ -39,8 +43,17 @@ type Pool struct {
 	db         *sql.DB
 	store      *db.Store
 	llmRouter  *llm.Router
+	caps       *Capabilities
+	webhooks   *webhook.Notifier
 }
 
+// capabilityInterval is how often a pool re-advertises its capabilities
+const capabilityInterval = time.Minute
+
+// shutdownGrace is how long Run waits on shutdown for workers to flush and
+// release their jobs; it fits Kubernetes' default 30s termination grace
+const shutdownGrace = 20 * time.Second
+
 // Worker is the interface all workers must implement
 type Worker interface {
 	Name() string
@@ -55,6 +68,10 @@ type PoolConfig struct {
 	NATS       *qtestnats.Client
 	Store      *db.Store    // Database store for domain operations
 	LLMRouter  *llm.Router  // LLM router for test generation
+
+	// Capabilities are the host's tools, from DetectCapabilities. Workers
+	// only take jobs they have the tools for; nil takes every job.
+	Capabilities *Capabilities
 }
 
 // NewPool creates a new worker pool
@@ -67,6 +84,11 @@ func NewPool(cfg PoolConfig) (*Pool, error) {
 		nats:       cfg.NATS,
 		store:      cfg.Store,
 		llmRouter:  cfg.LLMRouter,
+		caps:       cfg.Capabilities,
+	}
+
+	if cfg.Store != nil {
+		p.webhooks = webhook.NewNotifier(cfg.Store)
 	}
 
 	// Initialize job repository if DB is available
@@ -121,6 +143,9 @@ func (p *Pool) addWorker(jobType jobs.JobType) {
 		Repository: p.repo,
 		NATS:       p.nats,
 		Pipeline:   p.pipeline,
+		Webhooks:   p.webhooks,
+
+		Capabilities: p.caps,
 	}
 
 	base := NewBaseWorker(baseCfg)
@@ -163,11 +188,22 @@ func (p *Pool) Run(ctx context.Context) error {
 		}
 	}
 
+	if p.caps != nil && p.nats != nil && p.nats.IsConnected() {
+		go p.advertise(ctx)
+	}
+
+	if p.llmRouter != nil && p.cfg != nil && p.usesLLM() {
+		go p.warmModels(ctx)
+	}
+
 	errCh := make(chan error, len(p.workers))
+	var wg sync.WaitGroup
 
 	// Start all workers
 	for _, w := range p.workers {
+		wg.Add(1)
 		go func(worker Worker) {
+			defer wg.Done()
 			log.Info().Str("worker", worker.Name()).Msg("starting worker")
 			if err := worker.Run(ctx); err != nil {
 				errCh <- fmt.Errorf("worker %s failed: %w", worker.Name(), err)
@@ -179,10 +215,96 @@ func (p *Pool) Run(ctx context.Context) error {
 	select {
 	case <-ctx.Done():
 		log.Info().Msg("context cancelled, stopping workers")
-		return nil
 	case err := <-errCh:
 		return err
 	}
+
+	// Give in-flight jobs time to checkpoint and release, and their
+	// webhooks time to be delivered
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		p.webhooks.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		log.Info().Msg("all workers stopped")
+	case <-time.After(shutdownGrace):
+		log.Warn().Dur("grace", shutdownGrace).Msg("workers still running after shutdown grace period")
+	}
+	return nil
+}
+
+// CapabilityReport describes the pool's tools and the toolchains each of its
+// job types consumes
+func (p *Pool) CapabilityReport() *CapabilityReport {
+	if p.caps == nil {
+		return nil
+	}
+
+	report := &CapabilityReport{
+		Capabilities: p.caps,
+		WorkerType:   string(p.workerType),
+		Toolchains:   make(map[string][]string),
+		ReportedAt:   time.Now(),
+	}
+	for _, w := range p.workers {
+		if b, ok := w.(interface{ JobType() jobs.JobType }); ok {
+			report.Toolchains[string(b.JobType())] = p.caps.Toolchains(b.JobType())
+		}
+	}
+	if p.llmRouter != nil {
+		report.ModelLoads = p.llmRouter.ModelLoads()
+	}
+	return report
+}
+
+// usesLLM reports whether any of the pool's workers make LLM requests
+func (p *Pool) usesLLM() bool {
+	for _, w := range p.workers {
+		if b, ok := w.(interface{ JobType() jobs.JobType }); ok {
+			switch b.JobType() {
+			case jobs.JobTypeGeneration, jobs.JobTypeValidation:
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// warmModels loads the LLM tier models as the pool starts and keeps them
+// loaded while it idles, as configured
+func (p *Pool) warmModels(ctx context.Context) {
+	if p.cfg.LLM.OllamaPreload {
+		p.llmRouter.Preload(ctx)
+	}
+	if p.cfg.LLM.OllamaPingInterval > 0 {
+		p.llmRouter.KeepWarm(ctx, p.cfg.LLM.OllamaPingInterval)
+	}
+}
+
+// advertise publishes the pool's capability report on start and then
+// periodically, so schedulers and dashboards see which tools are available
+func (p *Pool) advertise(ctx context.Context) {
+	ticker := time.NewTicker(capabilityInterval)
+	defer ticker.Stop()
+
+	for {
+		data, err := json.Marshal(p.CapabilityReport())
+		if err == nil {
+			err = p.nats.Conn().Publish(qtestnats.SubjectWorkerCapabilities, data)
+		}
+		if err != nil {
+			log.Warn().Err(err).Msg("failed to advertise worker capabilities")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
 }
 
 // Pipeline returns the job pipeline manager
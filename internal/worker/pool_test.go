@@ -1,7 +1,9 @@
 package worker
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"github.com/QTest-hq/qtest/internal/config"
 	"github.com/QTest-hq/qtest/internal/jobs"
@@ -23,9 +25,9 @@ func TestNewPool_AllWorkers(t *testing.T) {
 		t.Fatal("pool should not be nil")
 	}
 
-	// Should have 6 workers (one for each job type)
-	if len(pool.workers) != 6 {
-		t.Errorf("len(workers) = %d, want 6", len(pool.workers))
+	// Should have 7 workers (one for each job type)
+	if len(pool.workers) != 7 {
+		t.Errorf("len(workers) = %d, want 7", len(pool.workers))
 	}
 }
 
@@ -190,3 +192,38 @@ func TestPool_AddWorker(t *testing.T) {
 		t.Errorf("len(workers) = %d, want 6", len(pool.workers))
 	}
 }
+
+// slowStopWorker takes a while to return after its context is cancelled,
+// like a worker flushing a checkpoint
+type slowStopWorker struct {
+	stopped chan struct{}
+}
+
+func (w *slowStopWorker) Name() string { return "slow" }
+
+func (w *slowStopWorker) Run(ctx context.Context) error {
+	<-ctx.Done()
+	time.Sleep(50 * time.Millisecond)
+	close(w.stopped)
+	return nil
+}
+
+func TestPool_Run_WaitsForWorkersOnShutdown(t *testing.T) {
+	w := &slowStopWorker{stopped: make(chan struct{})}
+	pool := &Pool{workers: []Worker{w}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+
+	if err := pool.Run(ctx); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	select {
+	case <-w.stopped:
+	default:
+		t.Error("Run returned before the worker finished stopping")
+	}
+}
@@ -0,0 +1,227 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	"github.com/QTest-hq/qtest/internal/config"
+	"github.com/QTest-hq/qtest/internal/db"
+	"github.com/QTest-hq/qtest/internal/generator"
+	"github.com/QTest-hq/qtest/internal/github"
+	"github.com/QTest-hq/qtest/internal/jobs"
+	"github.com/QTest-hq/qtest/internal/parser"
+)
+
+// maxReasonLength bounds the failure reason stored per target; LLM errors
+// can carry the whole model output
+const maxReasonLength = 300
+
+// untestableStore is the part of db.Store that tracks untestable targets
+type untestableStore interface {
+	RecordTargetFailure(ctx context.Context, repoID uuid.UUID, filePath, function, reason string) (*db.UntestableTarget, error)
+	ResolveTarget(ctx context.Context, repoID uuid.UUID, filePath, function string) error
+	UpdateTargetFindings(ctx context.Context, id uuid.UUID, findings json.RawMessage) error
+	MarkTargetReported(ctx context.Context, id uuid.UUID, issueNumber int, issueURL string) error
+}
+
+// issueService opens GitHub issues; *github.PRService implements it
+type issueService interface {
+	FindOpenIssue(ctx context.Context, owner, repo, label, title string) (*github.Issue, error)
+	CreateIssue(ctx context.Context, req github.IssueRequest) (*github.Issue, error)
+}
+
+// untestableTracker records generation outcomes per plan target. Targets
+// that fail cfg.Threshold times in a row are diagnosed for testability
+// problems and, if enabled, reported as GitHub issues instead of being
+// skipped silently.
+type untestableTracker struct {
+	store     untestableStore
+	cfg       config.UntestableConfig
+	repoID    uuid.UUID
+	workspace string
+
+	// Issues go to owner/repo; issues is nil when they're disabled
+	issues issueService
+	owner  string
+	repo   string
+}
+
+// newUntestableTracker returns a tracker for a generation job, or nil when
+// there is no store to track in
+func (w *GenerationWorker) newUntestableTracker(ctx context.Context, job *jobs.Job, repoID uuid.UUID, workspacePath string) *untestableTracker {
+	if w.store == nil || repoID == uuid.Nil {
+		return nil
+	}
+
+	t := &untestableTracker{
+		store:     w.store,
+		cfg:       config.UntestableConfig{Threshold: 3, IssueLabel: "qtest:untestable"},
+		repoID:    repoID,
+		workspace: workspacePath,
+	}
+	if w.cfg == nil {
+		return t
+	}
+	t.cfg = w.cfg.Untestable
+
+	if t.cfg.CreateIssues && w.cfg.GitHubToken != "" {
+		if ingestion := w.getIngestionPayload(ctx, job); ingestion != nil && ingestion.RepositoryURL != "" {
+			t.repo, t.owner = extractRepoInfo(ingestion.RepositoryURL)
+			t.issues = github.NewPRService(w.cfg.GitHubToken)
+		}
+	}
+	return t
+}
+
+// succeeded clears a target's failures after tests were generated for it
+func (t *untestableTracker) succeeded(ctx context.Context, target jobs.PlanTarget) {
+	if t == nil {
+		return
+	}
+	if err := t.store.ResolveTarget(ctx, t.repoID, target.File, target.Function); err != nil {
+		log.Warn().Err(err).Str("function", target.Function).Msg("failed to resolve target")
+	}
+}
+
+// failed counts a failed generation for a target and reports it once it
+// crosses the threshold
+func (t *untestableTracker) failed(ctx context.Context, target jobs.PlanTarget, reason string) {
+	if t == nil {
+		return
+	}
+
+	record, err := t.store.RecordTargetFailure(ctx, t.repoID, target.File, target.Function, failureReason(reason))
+	if err != nil {
+		log.Warn().Err(err).Str("function", target.Function).Msg("failed to record target failure")
+		return
+	}
+	if t.cfg.Threshold <= 0 || record.FailureCount < t.cfg.Threshold || record.Status != db.UntestableFailing {
+		return
+	}
+
+	language, findings := t.diagnose(ctx, target)
+	if data, err := json.Marshal(findings); err == nil {
+		if err := t.store.UpdateTargetFindings(ctx, record.ID, data); err != nil {
+			log.Warn().Err(err).Msg("failed to store testability findings")
+		}
+	}
+
+	log.Warn().
+		Str("file", target.File).
+		Str("function", target.Function).
+		Int("failures", record.FailureCount).
+		Int("findings", len(findings)).
+		Msg("target looks untestable")
+
+	if t.issues == nil {
+		return
+	}
+	issue, err := t.report(ctx, target, record, language, findings)
+	if err != nil {
+		log.Warn().Err(err).Str("function", target.Function).Msg("failed to open untestable target issue")
+		return
+	}
+	if err := t.store.MarkTargetReported(ctx, record.ID, issue.Number, issue.HTMLURL); err != nil {
+		log.Warn().Err(err).Msg("failed to mark target reported")
+	}
+}
+
+// diagnose parses the target's source and looks for testability problems
+func (t *untestableTracker) diagnose(ctx context.Context, target jobs.PlanTarget) (parser.Language, []generator.TestabilityIssue) {
+	path := target.File
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(t.workspace, path)
+	}
+
+	parsed, err := parser.NewParser().ParseFile(ctx, path)
+	if err != nil {
+		log.Debug().Err(err).Str("file", path).Msg("could not parse untestable target")
+		return "", nil
+	}
+	if fn := findFunction(parsed, target.Function); fn != nil {
+		return parsed.Language, generator.DiagnoseTestability(fn, parsed.Language)
+	}
+	return parsed.Language, nil
+}
+
+// report opens an issue for a target, or reuses one already open
+func (t *untestableTracker) report(ctx context.Context, target jobs.PlanTarget, record *db.UntestableTarget, language parser.Language, findings []generator.TestabilityIssue) (*github.Issue, error) {
+	title := github.UntestableIssueTitle(target.File, target.Function)
+
+	existing, err := t.issues.FindOpenIssue(ctx, t.owner, t.repo, t.cfg.IssueLabel, title)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return existing, nil
+	}
+
+	tmpl := github.UntestableTemplate{
+		File:     target.File,
+		Function: target.Function,
+		Language: string(language),
+		Attempts: record.FailureCount,
+	}
+	if record.LastReason != nil {
+		tmpl.LastReason = *record.LastReason
+	}
+	for _, f := range findings {
+		tmpl.Findings = append(tmpl.Findings, github.UntestableFinding{
+			Kind:       f.Kind,
+			Line:       f.Line,
+			Evidence:   f.Evidence,
+			Suggestion: f.Suggestion,
+		})
+	}
+
+	req := github.IssueRequest{
+		Owner: t.owner,
+		Repo:  t.repo,
+		Title: title,
+		Body:  github.GenerateUntestableIssueBody(tmpl),
+	}
+	if t.cfg.IssueLabel != "" {
+		req.Labels = []string{t.cfg.IssueLabel}
+	}
+
+	issue, err := t.issues.CreateIssue(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	log.Info().Int("number", issue.Number).Str("url", issue.HTMLURL).Msg("opened untestable target issue")
+	return issue, nil
+}
+
+// findFunction finds a function or method by name in a parsed file
+func findFunction(file *parser.ParsedFile, name string) *parser.Function {
+	for i := range file.Functions {
+		if file.Functions[i].Name == name {
+			return &file.Functions[i]
+		}
+	}
+	for i := range file.Classes {
+		for j := range file.Classes[i].Methods {
+			if file.Classes[i].Methods[j].Name == name {
+				return &file.Classes[i].Methods[j]
+			}
+		}
+	}
+	return nil
+}
+
+// failureReason reduces an error message to its first line, bounded
+func failureReason(reason string) string {
+	if i := strings.IndexByte(reason, '\n'); i >= 0 {
+		reason = reason[:i]
+	}
+	reason = strings.TrimSpace(reason)
+	if len(reason) > maxReasonLength {
+		reason = reason[:maxReasonLength] + "..."
+	}
+	return reason
+}
@@ -0,0 +1,166 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/QTest-hq/qtest/internal/config"
+	"github.com/QTest-hq/qtest/internal/db"
+	"github.com/QTest-hq/qtest/internal/github"
+	"github.com/QTest-hq/qtest/internal/jobs"
+)
+
+// fakeUntestableStore counts failures in memory
+type fakeUntestableStore struct {
+	targets  map[string]*db.UntestableTarget
+	findings json.RawMessage
+}
+
+func newFakeUntestableStore() *fakeUntestableStore {
+	return &fakeUntestableStore{targets: make(map[string]*db.UntestableTarget)}
+}
+
+func (s *fakeUntestableStore) RecordTargetFailure(_ context.Context, repoID uuid.UUID, filePath, function, reason string) (*db.UntestableTarget, error) {
+	key := filePath + ":" + function
+	t, ok := s.targets[key]
+	if !ok || t.Status == db.UntestableResolved {
+		t = &db.UntestableTarget{ID: uuid.New(), RepositoryID: repoID, FilePath: filePath, FunctionName: function, Status: db.UntestableFailing}
+		s.targets[key] = t
+	}
+	t.FailureCount++
+	t.LastReason = &reason
+	snapshot := *t
+	return &snapshot, nil
+}
+
+func (s *fakeUntestableStore) ResolveTarget(_ context.Context, _ uuid.UUID, filePath, function string) error {
+	if t, ok := s.targets[filePath+":"+function]; ok {
+		t.Status = db.UntestableResolved
+		t.FailureCount = 0
+	}
+	return nil
+}
+
+func (s *fakeUntestableStore) UpdateTargetFindings(_ context.Context, _ uuid.UUID, findings json.RawMessage) error {
+	s.findings = findings
+	return nil
+}
+
+func (s *fakeUntestableStore) MarkTargetReported(_ context.Context, id uuid.UUID, number int, url string) error {
+	for _, t := range s.targets {
+		if t.ID == id {
+			t.Status = db.UntestableReported
+			t.IssueNumber = &number
+		}
+	}
+	return nil
+}
+
+// fakeIssues records the issues opened
+type fakeIssues struct {
+	created []github.IssueRequest
+	open    *github.Issue
+}
+
+func (f *fakeIssues) FindOpenIssue(_ context.Context, _, _, _, _ string) (*github.Issue, error) {
+	return f.open, nil
+}
+
+func (f *fakeIssues) CreateIssue(_ context.Context, req github.IssueRequest) (*github.Issue, error) {
+	f.created = append(f.created, req)
+	return &github.Issue{Number: 40 + len(f.created), HTMLURL: "https://github.com/o/r/issues/41"}, nil
+}
+
+func TestUntestableTracker_ReportsAfterThreshold(t *testing.T) {
+	workspace := t.TempDir()
+	source := "package svc\n\nimport \"os\"\n\nfunc Sync() string {\n\treturn os.Getenv(\"SYNC_URL\")\n}\n"
+	if err := os.WriteFile(filepath.Join(workspace, "sync.go"), []byte(source), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	store := newFakeUntestableStore()
+	issues := &fakeIssues{}
+	tracker := &untestableTracker{
+		store:     store,
+		cfg:       config.UntestableConfig{Threshold: 3, CreateIssues: true, IssueLabel: "qtest:untestable"},
+		repoID:    uuid.New(),
+		workspace: workspace,
+		issues:    issues,
+		owner:     "o",
+		repo:      "r",
+	}
+	target := jobs.PlanTarget{File: "sync.go", Function: "Sync"}
+	ctx := context.Background()
+
+	tracker.failed(ctx, target, "LLM completion failed: timeout\n\nLLM Output:\n...")
+	tracker.failed(ctx, target, "no test generated")
+	if len(issues.created) != 0 {
+		t.Fatalf("issue opened before the threshold")
+	}
+
+	tracker.failed(ctx, target, "no test generated")
+	if len(issues.created) != 1 {
+		t.Fatalf("issues opened = %d, want 1", len(issues.created))
+	}
+	req := issues.created[0]
+	if req.Title != github.UntestableIssueTitle("sync.go", "Sync") || len(req.Labels) != 1 {
+		t.Errorf("issue request = %+v", req)
+	}
+	if !strings.Contains(req.Body, "environment") || !strings.Contains(string(store.findings), "environment") {
+		t.Errorf("environment finding missing from body or store:\n%s", req.Body)
+	}
+
+	// Reported targets aren't reported again
+	tracker.failed(ctx, target, "no test generated")
+	if len(issues.created) != 1 {
+		t.Errorf("issues opened = %d after a further failure, want 1", len(issues.created))
+	}
+
+	// A success starts the count over
+	tracker.succeeded(ctx, target)
+	tracker.failed(ctx, target, "no test generated")
+	if got := store.targets["sync.go:Sync"].FailureCount; got != 1 {
+		t.Errorf("FailureCount after success = %d, want 1", got)
+	}
+}
+
+func TestUntestableTracker_ReusesOpenIssue(t *testing.T) {
+	store := newFakeUntestableStore()
+	issues := &fakeIssues{open: &github.Issue{Number: 7, HTMLURL: "https://github.com/o/r/issues/7"}}
+	tracker := &untestableTracker{
+		store:  store,
+		cfg:    config.UntestableConfig{Threshold: 1, CreateIssues: true},
+		repoID: uuid.New(),
+		issues: issues,
+	}
+
+	tracker.failed(context.Background(), jobs.PlanTarget{File: "missing.go", Function: "F"}, "no test generated")
+
+	if len(issues.created) != 0 {
+		t.Errorf("created %d issues, want the open one reused", len(issues.created))
+	}
+	if n := store.targets["missing.go:F"].IssueNumber; n == nil || *n != 7 {
+		t.Errorf("IssueNumber = %v, want 7", n)
+	}
+}
+
+func TestUntestableTracker_Nil(t *testing.T) {
+	var tracker *untestableTracker
+	tracker.failed(context.Background(), jobs.PlanTarget{}, "x")
+	tracker.succeeded(context.Background(), jobs.PlanTarget{})
+}
+
+func TestFailureReason(t *testing.T) {
+	if got := failureReason("failed to parse IRSpec: bad json\n\nLLM Output:\n{...}"); got != "failed to parse IRSpec: bad json" {
+		t.Errorf("failureReason() = %q", got)
+	}
+	if got := failureReason(strings.Repeat("x", 500)); len(got) != maxReasonLength+3 {
+		t.Errorf("len(failureReason(long)) = %d", len(got))
+	}
+}
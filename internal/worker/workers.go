@@ -4,9 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/url"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
@@ -14,14 +15,19 @@ import (
 	"github.com/rs/zerolog/log"
 
 	"github.com/QTest-hq/qtest/internal/adapters"
+	"github.com/QTest-hq/qtest/internal/buildsys"
 	"github.com/QTest-hq/qtest/internal/config"
 	"github.com/QTest-hq/qtest/internal/db"
 	"github.com/QTest-hq/qtest/internal/generator"
+	"github.com/QTest-hq/qtest/internal/github"
 	"github.com/QTest-hq/qtest/internal/jobs"
 	"github.com/QTest-hq/qtest/internal/llm"
 	"github.com/QTest-hq/qtest/internal/mutation"
 	"github.com/QTest-hq/qtest/internal/parser"
+	"github.com/QTest-hq/qtest/internal/platform"
+	"github.com/QTest-hq/qtest/internal/supplements"
 	"github.com/QTest-hq/qtest/internal/validator"
+	"github.com/QTest-hq/qtest/internal/vcs"
 	"github.com/QTest-hq/qtest/pkg/dsl"
 	"github.com/QTest-hq/qtest/pkg/model"
 )
@@ -94,22 +100,18 @@ func (w *IngestionWorker) handleJob(ctx context.Context, job *jobs.Job) error {
 		return fmt.Errorf("failed to create workspace: %w", err)
 	}
 
-	// Git clone
-	args := []string{"clone", "--depth", "1"}
-	if payload.Branch != "" {
-		args = append(args, "-b", payload.Branch)
+	// Fetch the source with the VCS its URL names: git, hg or an archive
+	source, err := vcs.For("", payload.RepositoryURL)
+	if err != nil {
+		w.updateRepoStatus(ctx, repo.ID, "failed", nil)
+		return err
 	}
-	args = append(args, payload.RepositoryURL, workspacePath)
-
-	cmd := exec.CommandContext(ctx, "git", args...)
-	if output, err := cmd.CombinedOutput(); err != nil {
+	commitSHA, err := source.Fetch(ctx, payload.RepositoryURL, payload.Branch, workspacePath)
+	if err != nil {
 		w.updateRepoStatus(ctx, repo.ID, "failed", nil)
-		return fmt.Errorf("git clone failed: %s: %w", string(output), err)
+		return err
 	}
 
-	// Get commit SHA
-	commitSHA := getCommitSHA(ctx, workspacePath)
-
 	// Detect language and count files
 	var fileCount int
 	var language string
@@ -132,6 +134,11 @@ func (w *IngestionWorker) handleJob(ctx context.Context, job *jobs.Job) error {
 				language = "typescript"
 			}
 			fileCount++
+		case ".rs":
+			if language == "" {
+				language = "rust"
+			}
+			fileCount++
 		}
 		return nil
 	})
@@ -179,6 +186,8 @@ func (w *IngestionWorker) updateRepoStatus(ctx context.Context, repoID uuid.UUID
 
 // extractRepoInfo extracts repository name and owner from URL
 func extractRepoInfo(url string) (name, owner string) {
+	url = vcs.TrimURL(url)
+
 	// Handle SSH URLs: git@github.com:owner/repo.git
 	if strings.HasPrefix(url, "git@") {
 		parts := strings.Split(url, ":")
@@ -202,14 +211,9 @@ func extractRepoInfo(url string) (name, owner string) {
 	return "unknown", "unknown"
 }
 
-// getCommitSHA gets the current commit SHA from the repository
+// getCommitSHA gets the current commit SHA from the repository, git or hg
 func getCommitSHA(ctx context.Context, workspacePath string) string {
-	cmd := exec.CommandContext(ctx, "git", "-C", workspacePath, "rev-parse", "HEAD")
-	output, err := cmd.Output()
-	if err != nil {
-		return ""
-	}
-	return strings.TrimSpace(string(output))
+	return vcs.Revision(ctx, workspacePath)
 }
 
 // ModelingWorker builds system models from parsed code
@@ -244,8 +248,15 @@ func (w *ModelingWorker) handleJob(ctx context.Context, job *jobs.Job) error {
 	repoName := filepath.Base(payload.WorkspacePath)
 	commitSHA := getCommitSHA(ctx, payload.WorkspacePath)
 
+	// Run framework supplements so the model has the repository's endpoints
+	opts, err := supplements.ProjectOptions(payload.WorkspacePath)
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to load project supplements, continuing without them")
+	}
+	opts.Disabled = append(opts.Disabled, payload.DisableSupplements...)
+
 	// Build rich SystemModel using pkg/model
-	sysModel, err := model.BuildSystemModelFromParser(ctx, p, payload.WorkspacePath, repoName, "main", commitSHA)
+	sysModel, err := model.BuildSystemModelFromParser(ctx, p, payload.WorkspacePath, repoName, "main", commitSHA, opts)
 	if err != nil {
 		log.Warn().Err(err).Msg("failed to build system model, falling back to basic parsing")
 		// Fall back to basic parsing if model building fails
@@ -330,7 +341,7 @@ func (w *ModelingWorker) handleJobLegacy(ctx context.Context, job *jobs.Job, pay
 		}
 
 		ext := filepath.Ext(path)
-		if ext != ".go" && ext != ".py" && ext != ".ts" && ext != ".js" {
+		if ext != ".go" && ext != ".py" && ext != ".ts" && ext != ".js" && ext != ".rs" {
 			return nil
 		}
 
@@ -530,6 +541,7 @@ func (w *PlanningWorker) handleJob(ctx context.Context, job *jobs.Job) error {
 
 	// Fall back to simple calculation if planner didn't work
 	var result jobs.PlanningResult
+	upToDate := false
 	if testPlan != nil {
 		result = jobs.PlanningResult{
 			PlanID:     uuid.New(),
@@ -537,7 +549,9 @@ func (w *PlanningWorker) handleJob(ctx context.Context, job *jobs.Job) error {
 			UnitTests:  testPlan.UnitTests,
 			APITests:   testPlan.APITests,
 			E2ETests:   testPlan.E2ETests,
+			Targets:    planTargets(testPlan, sysModel),
 		}
+		upToDate = w.dropTestedTargets(ctx, job, payload.RepositoryID, &result)
 	} else {
 		// Fallback: simple percentage split
 		maxTests := payload.MaxTests
@@ -557,6 +571,11 @@ func (w *PlanningWorker) handleJob(ctx context.Context, job *jobs.Job) error {
 		return fmt.Errorf("failed to complete job: %w", err)
 	}
 
+	if upToDate {
+		log.Info().Int("already_tested", result.AlreadyTested).Msg("no untested targets, skipping generation")
+		return nil
+	}
+
 	// Chain to generation job with pipeline options
 	if w.Pipeline() != nil {
 		runID := uuid.New()
@@ -571,7 +590,7 @@ func (w *PlanningWorker) handleJob(ctx context.Context, job *jobs.Job) error {
 				ID:           runID,
 				RepositoryID: payload.RepositoryID,
 				Status:       "pending",
-				Config:       []byte(`{}`),
+				Config:       w.runConfig(ctx, job, tier),
 			}
 			if err := w.store.CreateGenerationRun(ctx, genRun); err != nil {
 				log.Warn().Err(err).Msg("failed to create generation run record")
@@ -597,12 +616,133 @@ func (w *PlanningWorker) handleJob(ctx context.Context, job *jobs.Job) error {
 	return nil
 }
 
+// dropTestedTargets leaves the targets that already have a generated test
+// out of the plan of a run that only wants untested ones, reporting whether
+// none are left
+func (w *PlanningWorker) dropTestedTargets(ctx context.Context, job *jobs.Job, repoID uuid.UUID, result *jobs.PlanningResult) bool {
+	ingestion := w.getIngestionPayload(ctx, job)
+	if ingestion == nil || !ingestion.OnlyUntested || w.store == nil {
+		return false
+	}
+	tested, err := w.store.ListTestedTargets(ctx, repoID)
+	if err != nil {
+		// Generating a test twice beats missing new code
+		log.Warn().Err(err).Msg("failed to list tested targets, planning all of them")
+		return false
+	}
+
+	targets := untestedTargets(result.Targets, tested, w.getWorkspacePath(ctx, job))
+	result.AlreadyTested = len(result.Targets) - len(targets)
+	result.Targets = targets
+	result.TotalTests = len(targets)
+	return len(targets) == 0
+}
+
+// untestedTargets returns the targets without a tested function in the
+// same file. Tested files are recorded at their path in the workspace of
+// the run that tested them, so they match a target's path relative to the
+// current workspace as a suffix.
+func untestedTargets(targets []jobs.PlanTarget, tested []db.TestedTarget, workspacePath string) []jobs.PlanTarget {
+	byFunction := make(map[string][]string)
+	for _, t := range tested {
+		byFunction[t.Function] = append(byFunction[t.Function], filepath.ToSlash(filepath.Clean(t.File)))
+	}
+
+	untested := make([]jobs.PlanTarget, 0, len(targets))
+	for _, t := range targets {
+		file := t.File
+		if workspacePath != "" && filepath.IsAbs(file) {
+			if rel, err := filepath.Rel(workspacePath, file); err == nil && !strings.HasPrefix(rel, "..") {
+				file = rel
+			}
+		}
+		file = filepath.ToSlash(filepath.Clean(file))
+
+		found := false
+		for _, f := range byFunction[t.Function] {
+			if f == file || strings.HasSuffix(f, "/"+file) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			untested = append(untested, t)
+		}
+	}
+	return untested
+}
+
+// planTargets resolves a plan's intents to the functions they cover.
+// Endpoint intents resolve to their handler.
+func planTargets(plan *model.TestPlan, sysModel *model.SystemModel) []jobs.PlanTarget {
+	targets := make([]jobs.PlanTarget, 0, len(plan.Intents))
+	for _, intent := range plan.Intents {
+		fnID := intent.TargetID
+		var endpoint string
+		if ep := sysModel.GetEndpoint(intent.TargetID); ep != nil {
+			fnID = ep.Handler
+			endpoint = ep.Describe()
+		}
+		fn := sysModel.GetFunction(fnID)
+		if fn == nil {
+			log.Debug().Str("intent", intent.ID).Str("target", intent.TargetID).Msg("plan target has no source function")
+			continue
+		}
+
+		targets = append(targets, jobs.PlanTarget{
+			IntentID: intent.ID,
+			Level:    string(intent.Level),
+			Priority: intent.Priority,
+			File:     fn.File,
+			Function: fn.Name,
+			Line:     fn.StartLine,
+			Endpoint: endpoint,
+		})
+	}
+	return targets
+}
+
+// getWorkspacePath returns the workspace the chain's ingestion cloned into
+func (w *PlanningWorker) getWorkspacePath(ctx context.Context, job *jobs.Job) string {
+	current := job
+	for current.ParentJobID != nil {
+		parent, err := w.Repository().GetByID(ctx, *current.ParentJobID)
+		if err != nil || parent == nil {
+			break
+		}
+
+		if parent.Type == jobs.JobTypeIngestion {
+			var result jobs.IngestionResult
+			if err := parent.GetResult(&result); err == nil {
+				return result.WorkspacePath
+			}
+		}
+		current = parent
+	}
+	return ""
+}
+
+// runConfig records the policy a generation run is planned under
+func (w *PlanningWorker) runConfig(ctx context.Context, job *jobs.Job, tier int) []byte {
+	policy := jobs.Policy{LLMTier: tier}
+	if ingestion := w.getIngestionPayload(ctx, job); ingestion != nil {
+		policy.CoverageTarget = ingestion.CoverageTarget
+		policy.Providers = ingestion.Providers
+	}
+	data, err := json.Marshal(policy)
+	if err != nil {
+		return []byte(`{}`)
+	}
+	return data
+}
+
 // GenerationWorker generates tests using LLM
 type GenerationWorker struct {
 	*BaseWorker
-	cfg   *config.Config
-	store *db.Store
-	gen   *generator.Generator
+	cfg       *config.Config
+	store     *db.Store
+	gen       *generator.Generator
+	llmRouter *llm.Router
 }
 
 func NewGenerationWorker(base *BaseWorker, cfg *config.Config, store *db.Store, llmRouter *llm.Router) *GenerationWorker {
@@ -610,7 +750,7 @@ func NewGenerationWorker(base *BaseWorker, cfg *config.Config, store *db.Store,
 	if llmRouter != nil {
 		gen = generator.NewGenerator(llmRouter)
 	}
-	w := &GenerationWorker{BaseWorker: base, cfg: cfg, store: store, gen: gen}
+	w := &GenerationWorker{BaseWorker: base, cfg: cfg, store: store, gen: gen, llmRouter: llmRouter}
 	base.handler = w.handleJob
 	return w
 }
@@ -662,80 +802,268 @@ func (w *GenerationWorker) handleJob(ctx context.Context, job *jobs.Job) error {
 		tier = llm.Tier1 // Default to fast tier
 	}
 
-	// Generate tests for source files in workspace
-	var testFilePaths []string
-	var testIDs []string
-	var failedIntents []string
-	var language string
-	testsGenerated := 0
+	// Record the tokens and cost of the LLM calls against the run
+	usage := &llm.RunUsage{}
+	ctx = llm.WithRunUsage(ctx, usage)
+	defer persistRunUsage(context.WithoutCancel(ctx), w.store, payload.GenerationRunID, usage)
 
-	err := filepath.Walk(workspacePath, func(path string, info os.FileInfo, walkErr error) error {
-		if walkErr != nil || info.IsDir() {
-			return nil
-		}
+	// Targets retried at a higher tier, recorded in the run's summary
+	var escalations []jobs.Escalation
+	defer func() {
+		persistEscalations(context.WithoutCancel(ctx), w.store, payload.GenerationRunID, escalations)
+	}()
 
-		ext := filepath.Ext(path)
-		if ext != ".go" && ext != ".py" && ext != ".ts" && ext != ".js" {
-			return nil
-		}
+	// Keep the code away from providers the repository's policy doesn't allow
+	gen := w.gen
+	router := w.policyRouter(ctx, job, w.llmRouter)
+	if router != w.llmRouter {
+		gen = generator.NewGenerator(router)
+	}
 
-		// Detect language from first file
-		if language == "" {
-			switch ext {
-			case ".go":
-				language = "go"
-			case ".py":
-				language = "python"
-			case ".ts", ".js":
-				language = "typescript"
-			}
+	// Run each test as it's written, sending failures back to the LLM
+	var repairer *generator.Repairer
+	if w.cfg != nil && w.cfg.RepairIterations > 0 && router != nil {
+		repairer = generator.NewRepairer(router, w.cfg.RepairIterations)
+	}
+
+	// Resume from the checkpoint of an interrupted attempt, if any
+	var progress jobs.GenerationResult
+	if err := job.GetResult(&progress); err != nil {
+		log.Warn().Err(err).Msg("ignoring unreadable generation checkpoint")
+		progress = jobs.GenerationResult{}
+	}
+	testFilePaths := progress.TestFilePaths
+	pendingMerges := progress.PendingMerges
+	testIDs := progress.TestIDs
+	failedIntents := progress.FailedIntents
+	coveredIntents := progress.CoveredIntents
+	repairedTests := progress.RepairedTests
+	rejectedTests := progress.RejectedTests
+	language := progress.Language
+	testsGenerated := progress.TestsGenerated
+	completed := make(map[string]bool, len(progress.CompletedFiles))
+	for _, f := range progress.CompletedFiles {
+		completed[f] = true
+	}
+	if len(completed) > 0 {
+		log.Info().Int("files", len(completed)).Int("tests", testsGenerated).Msg("resuming generation from checkpoint")
+	}
+
+	// checkpoint marks a file done and saves progress, so a restarted
+	// worker skips it
+	checkpoint := func(file string) {
+		if file != "" {
+			completed[file] = true
+			progress.CompletedFiles = append(progress.CompletedFiles, file)
+		}
+		progress.TestsGenerated = testsGenerated
+		progress.TestFilePaths = testFilePaths
+		progress.PendingMerges = pendingMerges
+		progress.TestIDs = testIDs
+		progress.FailedIntents = failedIntents
+		progress.CoveredIntents = coveredIntents
+		progress.RepairedTests = repairedTests
+		progress.RejectedTests = rejectedTests
+		progress.Language = language
+		if err := w.Checkpoint(ctx, job, progress); err != nil {
+			log.Warn().Err(err).Msg("failed to checkpoint generation")
 		}
+	}
 
-		// Skip test files
-		if strings.HasSuffix(path, "_test.go") || strings.HasSuffix(path, "_test.py") ||
-			strings.HasSuffix(path, ".test.ts") || strings.HasSuffix(path, ".test.js") ||
-			strings.HasSuffix(path, ".spec.ts") || strings.HasSuffix(path, ".spec.js") {
-			return nil
+	// MaxTests is a budget for the whole run, not per file
+	budget := func(perFile int) int {
+		if payload.MaxTests <= 0 {
+			return perFile
 		}
+		left := payload.MaxTests - testsGenerated
+		if perFile > 0 && perFile < left {
+			return perFile
+		}
+		return left
+	}
+	exhausted := func() bool {
+		return payload.MaxTests > 0 && testsGenerated >= payload.MaxTests
+	}
+
+	// Why each function failed in the last generate call
+	var fnErrors map[string]error
+
+	// Header identifying this run's test files as QTest's
+	provenance := adapters.Provenance{
+		RunID:        payload.GenerationRunID.String(),
+		SourceCommit: getCommitSHA(ctx, workspacePath),
+	}
 
-		log.Debug().Str("file", path).Msg("generating tests for file")
+	generate := func(path string, functions []string, perFile int) ([]generator.GeneratedTest, error) {
+		if language == "" {
+			language = languageForPath(path)
+		}
+		log.Debug().Str("file", path).Strs("functions", functions).Msg("generating tests for file")
 
 		// Generate tests for this file using IRSpec (structured JSON output)
-		tests, genErr := w.gen.GenerateForFile(ctx, path, generator.GenerateOptions{
-			Tier:      tier,
-			TestType:  dsl.TestTypeUnit,
-			MaxTests:  5,        // Limit per file
-			UseIRSpec: true,     // Use IRSpec for structured output
-		})
-		if genErr != nil {
-			log.Warn().Err(genErr).Str("file", path).Msg("failed to generate tests")
-			failedIntents = append(failedIntents, path)
-			return nil
+		fnErrors = make(map[string]error)
+		var escalated []jobs.Escalation
+		opts := generateOptions(tier, budget(perFile), functions, w.escalationRetries())
+		opts.OnFailure = func(fn *parser.Function, err error) {
+			fnErrors[fn.Name] = err
+		}
+		opts.OnEscalation = func(fn *parser.Function, esc generator.Escalation) {
+			escalated = append(escalated, jobs.Escalation{
+				File:     workspaceRel(workspacePath, path),
+				Function: fn.Name,
+				FromTier: int(esc.From),
+				ToTier:   int(esc.To),
+				Reason:   esc.Reason,
+			})
+		}
+		tests, err := gen.GenerateForFile(ctx, path, opts)
+		if err != nil {
+			return nil, err
 		}
+		escalations = append(escalations, recoveredEscalations(escalated, tests)...)
 
 		// Convert generated tests to code and write to files
 		for _, test := range tests {
-			testPath, writeErr := w.writeTestFile(path, test, workspacePath)
+			prov := provenance
+			prov.Model = test.Model
+			prov.PromptHash = test.PromptHash
+			prov.Attempts = test.Attempts
+			if test.Function != nil {
+				prov.Targets = []string{adapters.TargetName(test.Function.Class, test.Function.Name)}
+			}
+			if rel, err := filepath.Rel(workspacePath, path); err == nil {
+				prov.Source = rel
+			}
+			written, writeErr := w.writeTestFile(ctx, path, test, workspacePath, payload.RepositoryID, prov)
 			if writeErr != nil {
 				log.Warn().Err(writeErr).Msg("failed to write test file")
 				failedIntents = append(failedIntents, test.Function.Name)
 				continue
 			}
-			testFilePaths = append(testFilePaths, testPath)
+			if written.Path == "" {
+				// Edited by hand: leave it for the user to merge
+				pendingMerges = append(pendingMerges, jobs.PendingMerge{
+					TestFile:  strings.TrimSuffix(written.MergePath, adapters.MergeSuffix),
+					MergeFile: written.MergePath,
+					Conflicts: written.Conflicts,
+				})
+				continue
+			}
+			check := w.checkTestFile(ctx, repairer, &test, written, workspacePath)
+			if check.Status == staticFailed {
+				failedIntents = append(failedIntents, test.Function.Name)
+				rejectedTests++
+				w.persistGeneratedTest(ctx, payload.GenerationRunID, test, written.Path, workspacePath, check)
+				continue
+			}
+			if repairer != nil && repairTest(ctx, repairer, &test, written.Path, workspacePath) {
+				repairedTests++
+			}
+			testFilePaths = append(testFilePaths, written.Path)
 			testsGenerated++
 
 			// Persist to database and collect ID
-			testID := w.persistGeneratedTest(ctx, payload.GenerationRunID, test, testPath)
+			testID := w.persistGeneratedTest(ctx, payload.GenerationRunID, test, written.Path, workspacePath, check)
 			if testID != "" {
 				testIDs = append(testIDs, testID)
 			}
 		}
+		return tests, nil
+	}
 
-		return nil
-	})
+	if targets := w.getPlanTargets(ctx, job, payload); len(targets) > 0 {
+		// Only read the files the plan targets, in priority order
+		files, byFile := groupPlanTargets(targets, workspacePath)
+		log.Info().Int("targets", len(targets)).Int("files", len(files)).Msg("generating tests for planned targets")
 
-	if err != nil {
-		return fmt.Errorf("failed to walk workspace: %w", err)
+		// Count outcomes per target so ones that keep failing get reported
+		tracker := w.newUntestableTracker(ctx, job, payload.RepositoryID, workspacePath)
+
+		for i, file := range files {
+			if exhausted() || ctx.Err() != nil {
+				break
+			}
+			if completed[file] {
+				continue
+			}
+			w.Progress(job, "generation", i+1, len(files), workspaceRel(workspacePath, file))
+			fileTargets := byFile[file]
+			var functions []string
+			for _, t := range fileTargets {
+				functions = append(functions, t.Function)
+			}
+
+			tests, genErr := generate(file, functions, 0)
+			if genErr != nil && ctx.Err() != nil {
+				break // Interrupted: the file is redone on resume
+			}
+			if genErr != nil {
+				log.Warn().Err(genErr).Str("file", file).Msg("failed to generate tests")
+				for _, t := range fileTargets {
+					failedIntents = append(failedIntents, t.IntentID)
+					tracker.failed(ctx, t, genErr.Error())
+				}
+				checkpoint(file)
+				continue
+			}
+
+			covered := make(map[string]bool, len(tests))
+			for _, test := range tests {
+				covered[test.Function.Name] = true
+			}
+			for _, t := range fileTargets {
+				switch {
+				case covered[t.Function]:
+					coveredIntents = append(coveredIntents, t.IntentID)
+					tracker.succeeded(ctx, t)
+				case exhausted() && fnErrors[t.Function] == nil:
+					// Not attempted: the run's test budget ran out
+				default:
+					failedIntents = append(failedIntents, t.IntentID)
+					reason := "no test generated"
+					if err := fnErrors[t.Function]; err != nil {
+						reason = err.Error()
+					}
+					tracker.failed(ctx, t, reason)
+				}
+			}
+			checkpoint(file)
+		}
+	} else {
+		// No stored plan: fall back to every source file in the workspace
+		log.Warn().Str("plan_id", payload.PlanID.String()).Msg("no plan targets found, generating for all source files")
+
+		err := filepath.Walk(workspacePath, func(path string, info os.FileInfo, walkErr error) error {
+			if exhausted() || ctx.Err() != nil {
+				return filepath.SkipAll
+			}
+			if walkErr != nil || info.IsDir() {
+				return nil
+			}
+			if languageForPath(path) == "" || isTestFile(path) || completed[path] {
+				return nil
+			}
+			w.Progress(job, "generation", len(completed)+1, 0, workspaceRel(workspacePath, path))
+
+			if _, genErr := generate(path, nil, 5); genErr != nil { // Limit per file
+				if ctx.Err() != nil {
+					return filepath.SkipAll
+				}
+				log.Warn().Err(genErr).Str("file", path).Msg("failed to generate tests")
+				failedIntents = append(failedIntents, path)
+			}
+			checkpoint(path)
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to walk workspace: %w", err)
+		}
+	}
+
+	// Interrupted (shutdown or timeout): keep what's done for the next attempt
+	if ctx.Err() != nil {
+		checkpoint("")
+		return fmt.Errorf("generation interrupted after %d files: %w", len(completed), ctx.Err())
 	}
 
 	// Update generation run status
@@ -753,6 +1081,10 @@ func (w *GenerationWorker) handleJob(ctx context.Context, job *jobs.Job) error {
 		TestsGenerated: testsGenerated,
 		TestFilePaths:  testFilePaths,
 		FailedIntents:  failedIntents,
+		CoveredIntents: coveredIntents,
+		RepairedTests:  repairedTests,
+		RejectedTests:  rejectedTests,
+		PendingMerges:  pendingMerges,
 	}
 
 	if err := w.Repository().Complete(ctx, job.ID, result); err != nil {
@@ -768,7 +1100,12 @@ func (w *GenerationWorker) handleJob(ctx context.Context, job *jobs.Job) error {
 			RunMutation:    payload.RunMutation,
 			CreatePR:       payload.CreatePR,
 		}
-		_, err := w.Pipeline().CreateValidationJob(
+		if w.cfg != nil {
+			opts.Shards = w.cfg.Validation.Shards
+			opts.MinShardSize = w.cfg.Validation.MinShardSize
+			opts.CacheDir = w.cfg.Validation.CacheDir
+		}
+		_, err := w.Pipeline().CreateValidationShards(
 			ctx,
 			job.ID,
 			payload.RepositoryID,
@@ -793,6 +1130,193 @@ func (w *GenerationWorker) handleJob(ctx context.Context, job *jobs.Job) error {
 	return nil
 }
 
+// persistRunUsage adds the LLM usage a job recorded to its run's summary
+func persistRunUsage(ctx context.Context, store *db.Store, runID uuid.UUID, usage *llm.RunUsage) {
+	summary := usage.Summary()
+	if store == nil || summary.Calls == 0 {
+		return
+	}
+
+	err := store.UpdateGenerationRunSummary(ctx, runID, jobs.RunSummaryLLMUsage, func(current json.RawMessage) (json.RawMessage, error) {
+		var total llm.UsageSummary
+		if len(current) > 0 {
+			if err := json.Unmarshal(current, &total); err != nil {
+				return nil, fmt.Errorf("failed to parse LLM usage: %w", err)
+			}
+		}
+		total.Add(summary)
+		return json.Marshal(total)
+	})
+	if err != nil {
+		log.Warn().Err(err).Str("run_id", runID.String()).Msg("failed to record LLM usage")
+		return
+	}
+
+	log.Info().
+		Str("run_id", runID.String()).
+		Int("calls", summary.Calls).
+		Int("input_tokens", summary.InputTokens).
+		Int("output_tokens", summary.OutputTokens).
+		Float64("cost_usd", summary.CostUSD).
+		Msg("recorded LLM usage")
+}
+
+// Outcomes of a generated test's static checks
+const (
+	staticPassed   = "passed"
+	staticRepaired = "repaired"
+	staticFailed   = "failed"
+	staticSkipped  = "skipped"
+)
+
+// staticOutcome is how a test file's static checks went; an empty Status
+// means they weren't run
+type staticOutcome struct {
+	Status string
+	Output string // checker errors, when the file failed
+}
+
+// checkTestFile compiles or type-checks a written test file, repairing it
+// with the LLM when it fails. A file that still fails is rejected: put back
+// to the generated code it replaced, or removed if it was new.
+func (w *GenerationWorker) checkTestFile(ctx context.Context, repairer *generator.Repairer, test *generator.GeneratedTest, written *adapters.WriteResult, workspacePath string) staticOutcome {
+	if w.cfg == nil || !w.cfg.StaticChecks {
+		return staticOutcome{}
+	}
+
+	language := languageForPath(written.Path)
+	if filepath.Ext(written.Path) == ".js" {
+		language = "javascript"
+	}
+	checker := validator.NewStaticChecker(workspacePath, language)
+	res, err := checker.Check(ctx, written.Path)
+	if err != nil {
+		log.Debug().Err(err).Str("file", written.Path).Msg("could not run static checks")
+		return staticOutcome{}
+	}
+	switch {
+	case res.Skipped:
+		return staticOutcome{Status: staticSkipped}
+	case res.Passed:
+		return staticOutcome{Status: staticPassed}
+	}
+
+	output := res.Output
+	if repairer != nil {
+		repaired, err := repairer.Repair(ctx, staticRunner{checker}, test, written.Path, language)
+		if err == nil && repaired.Passed {
+			log.Info().Str("file", written.Path).Str("tool", res.Tool).Msg("repaired test that failed static checks")
+			return staticOutcome{Status: staticRepaired}
+		}
+		if err == nil {
+			output = repaired.LastError
+		}
+	}
+
+	log.Warn().Str("file", written.Path).Str("tool", res.Tool).Str("errors", output).Msg("rejecting test that failed static checks")
+	rejectTestFile(written)
+	return staticOutcome{Status: staticFailed, Output: output}
+}
+
+// staticRunner lets the repairer treat a static check as a test run
+type staticRunner struct {
+	checker *validator.StaticChecker
+}
+
+func (r staticRunner) RunTests(ctx context.Context, testFile string) (*validator.TestResult, error) {
+	res, err := r.checker.Check(ctx, testFile)
+	if err != nil {
+		return nil, err
+	}
+	return &validator.TestResult{Passed: res.Passed, TestFile: testFile, Output: res.Output}, nil
+}
+
+// rejectTestFile undoes writing a rejected test file
+func rejectTestFile(written *adapters.WriteResult) {
+	var err error
+	if written.Previous != "" {
+		err = os.WriteFile(written.Path, []byte(written.Previous), 0644)
+	} else {
+		err = os.Remove(written.Path)
+	}
+	if err != nil {
+		log.Warn().Err(err).Str("file", written.Path).Msg("failed to undo rejected test file")
+	}
+}
+
+// repairTest runs a written test file and repairs it if it fails, reporting
+// whether a repair made it pass. Go tests run in their package's directory,
+// so other packages' failures aren't fed back.
+func repairTest(ctx context.Context, repairer *generator.Repairer, test *generator.GeneratedTest, testPath, workspacePath string) bool {
+	language := languageForPath(testPath)
+	workDir := workspacePath
+	if language == "go" {
+		workDir = filepath.Dir(testPath)
+	}
+
+	result, err := repairer.Repair(ctx, validator.NewValidator(workDir, language), test, testPath, language)
+	if err != nil {
+		log.Debug().Err(err).Str("file", testPath).Msg("could not run test for repair")
+		return false
+	}
+	if !result.Passed {
+		log.Warn().
+			Str("file", testPath).
+			Int("iterations", result.Iterations).
+			Str("error", result.LastError).
+			Msg("test still fails after repair")
+		return false
+	}
+	if result.Iterations > 0 {
+		log.Info().Str("file", testPath).Int("iterations", result.Iterations).Msg("repaired failing test")
+	}
+	return result.Iterations > 0
+}
+
+// escalationRetries is how many times a target with unusable output is
+// retried at a higher tier
+func (w *GenerationWorker) escalationRetries() int {
+	if w.cfg == nil {
+		return 0
+	}
+	return w.cfg.LLM.EscalationRetries
+}
+
+// recoveredEscalations marks the escalations of functions a test was
+// generated for in the end as recovered
+func recoveredEscalations(escalations []jobs.Escalation, tests []generator.GeneratedTest) []jobs.Escalation {
+	generated := make(map[string]bool, len(tests))
+	for _, test := range tests {
+		if test.Function != nil {
+			generated[test.Function.Name] = true
+		}
+	}
+	for i := range escalations {
+		escalations[i].Recovered = generated[escalations[i].Function]
+	}
+	return escalations
+}
+
+// persistEscalations adds the escalations a job made to its run's summary
+func persistEscalations(ctx context.Context, store *db.Store, runID uuid.UUID, escalations []jobs.Escalation) {
+	if store == nil || len(escalations) == 0 {
+		return
+	}
+
+	err := store.UpdateGenerationRunSummary(ctx, runID, jobs.RunSummaryEscalations, func(current json.RawMessage) (json.RawMessage, error) {
+		var all []jobs.Escalation
+		if len(current) > 0 {
+			if err := json.Unmarshal(current, &all); err != nil {
+				return nil, fmt.Errorf("failed to parse escalations: %w", err)
+			}
+		}
+		return json.Marshal(append(all, escalations...))
+	})
+	if err != nil {
+		log.Warn().Err(err).Str("run_id", runID.String()).Msg("failed to record escalations")
+	}
+}
+
 // deriveSourcePath converts a test file path back to its source file path
 func deriveSourcePath(testPath string) string {
 	dir := filepath.Dir(testPath)
@@ -822,7 +1346,29 @@ func deriveSourcePath(testPath string) string {
 		return ""
 	}
 
-	return filepath.Join(dir, sourceName)
+	// Files QTest wrote beside a human's test test the same source:
+	// foo_qtest.go -> foo.go, foo.qtest.ts -> foo.ts
+	ext := filepath.Ext(sourceName)
+	stem := strings.TrimSuffix(sourceName, ext)
+	for _, marker := range []string{"_qtest", ".qtest"} {
+		stem = strings.TrimSuffix(stem, marker)
+	}
+	return filepath.Join(dir, stem+ext)
+}
+
+// generateOptions are the options a file's tests are generated with. Plan
+// targets are named in functions, which are generated even when unexported,
+// as the handlers endpoint intents resolve to usually are.
+func generateOptions(tier llm.Tier, maxTests int, functions []string, maxEscalations int) generator.GenerateOptions {
+	return generator.GenerateOptions{
+		Tier:           tier,
+		SelectTier:     true, // Per function, by the context it needs
+		TestType:       dsl.TestTypeUnit,
+		MaxTests:       maxTests,
+		Functions:      functions,
+		UseIRSpec:      true, // Use IRSpec for structured output
+		MaxEscalations: maxEscalations,
+	}
 }
 
 // getWorkspacePath retrieves workspace path from the job chain
@@ -846,29 +1392,115 @@ func (w *GenerationWorker) getWorkspacePath(ctx context.Context, job *jobs.Job)
 	return ""
 }
 
-// writeTestFile writes generated test to a file
-func (w *GenerationWorker) writeTestFile(sourcePath string, test generator.GeneratedTest, workspacePath string) (string, error) {
-	// Determine test file path based on source file
-	dir := filepath.Dir(sourcePath)
-	base := filepath.Base(sourcePath)
-	ext := filepath.Ext(base)
-	name := strings.TrimSuffix(base, ext)
+// getPlanTargets loads the targets stored by the planning job that created
+// this run's plan, filtered to payload.IntentIDs when set
+func (w *GenerationWorker) getPlanTargets(ctx context.Context, job *jobs.Job, payload jobs.GenerationPayload) []jobs.PlanTarget {
+	current := job
+	for current.ParentJobID != nil {
+		parent, err := w.Repository().GetByID(ctx, *current.ParentJobID)
+		if err != nil || parent == nil {
+			break
+		}
 
-	var testFileName string
-	switch ext {
-	case ".go":
-		testFileName = name + "_test.go"
-	case ".py":
-		testFileName = "test_" + name + ".py"
-	case ".ts":
-		testFileName = name + ".test.ts"
-	case ".js":
-		testFileName = name + ".test.js"
-	default:
-		testFileName = name + "_test" + ext
+		if parent.Type == jobs.JobTypePlanning {
+			var result jobs.PlanningResult
+			if err := parent.GetResult(&result); err != nil || result.PlanID != payload.PlanID {
+				return nil
+			}
+			if len(payload.IntentIDs) == 0 {
+				return result.Targets
+			}
+
+			wanted := make(map[string]bool, len(payload.IntentIDs))
+			for _, id := range payload.IntentIDs {
+				wanted[id] = true
+			}
+			var targets []jobs.PlanTarget
+			for _, t := range result.Targets {
+				if wanted[t.IntentID] {
+					targets = append(targets, t)
+				}
+			}
+			return targets
+		}
+		current = parent
+	}
+	return nil
+}
+
+// groupPlanTargets groups targets by source file, resolved against the
+// workspace. Files keep the order of their first target.
+func groupPlanTargets(targets []jobs.PlanTarget, workspacePath string) ([]string, map[string][]jobs.PlanTarget) {
+	var files []string
+	byFile := make(map[string][]jobs.PlanTarget)
+	for _, t := range targets {
+		path := t.File
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(workspacePath, path)
+		}
+		if _, ok := byFile[path]; !ok {
+			files = append(files, path)
+		}
+		byFile[path] = append(byFile[path], t)
+	}
+	return files, byFile
+}
+
+// languageForPath returns the language of a supported source file, or ""
+func languageForPath(path string) string {
+	switch filepath.Ext(path) {
+	case ".go":
+		return "go"
+	case ".py":
+		return "python"
+	case ".ts", ".js":
+		return "typescript"
+	case ".rs":
+		return "rust"
+	}
+	return ""
+}
+
+// isTestFile reports whether a path is an existing test file
+func isTestFile(path string) bool {
+	return strings.HasSuffix(path, "_test.go") || strings.HasSuffix(path, "_test.py") ||
+		strings.HasSuffix(path, ".test.ts") || strings.HasSuffix(path, ".test.js") ||
+		strings.HasSuffix(path, ".spec.ts") || strings.HasSuffix(path, ".spec.js") ||
+		(strings.HasSuffix(path, ".rs") && filepath.Base(filepath.Dir(path)) == "tests")
+}
+
+// writeTestFile writes generated test to a file, stamped with its
+// provenance. Test files a human wrote are left alone, and ones edited
+// since QTest wrote them get a merge proposal instead of being overwritten.
+func (w *GenerationWorker) writeTestFile(ctx context.Context, sourcePath string, test generator.GeneratedTest, workspacePath string, repoID uuid.UUID, prov adapters.Provenance) (*adapters.WriteResult, error) {
+	// Determine test file path based on source file
+	dir := filepath.Dir(sourcePath)
+	base := filepath.Base(sourcePath)
+	ext := filepath.Ext(base)
+	name := strings.TrimSuffix(base, ext)
+
+	var testFileName string
+	switch ext {
+	case ".go":
+		testFileName = name + "_test.go"
+	case ".py":
+		testFileName = "test_" + name + ".py"
+	case ".ts":
+		testFileName = name + ".test.ts"
+	case ".js":
+		testFileName = name + ".test.js"
+	default:
+		testFileName = name + "_test" + ext
 	}
 
 	testPath := filepath.Join(dir, testFileName)
+	if ext == ".rs" {
+		// Integration tests live in the crate's tests/ directory
+		testPath = adapters.RustTestPath(sourcePath)
+		if err := os.MkdirAll(filepath.Dir(testPath), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create tests directory: %w", err)
+		}
+	}
 
 	// Get appropriate adapter for code generation
 	var testCode string
@@ -897,25 +1529,74 @@ func (w *GenerationWorker) writeTestFile(sourcePath string, test generator.Gener
 	case ".ts", ".js":
 		adapter := adapters.NewJestAdapter()
 		testCode, err = adapter.Generate(test.DSL)
+	case ".rs":
+		if len(test.TestSpecs) > 0 {
+			testCode, err = adapters.NewRustSpecAdapter().GenerateFromSpecs(test.TestSpecs, sourcePath)
+		} else {
+			testCode, err = adapters.NewRustAdapter().Generate(test.DSL)
+		}
 	default:
-		return "", fmt.Errorf("unsupported file type: %s", ext)
+		return nil, fmt.Errorf("unsupported file type: %s", ext)
 	}
 
 	if err != nil {
-		return "", fmt.Errorf("failed to generate test code: %w", err)
+		return nil, fmt.Errorf("failed to generate test code: %w", err)
 	}
 
 	// Write test file
-	if err := os.WriteFile(testPath, []byte(testCode), 0644); err != nil {
-		return "", fmt.Errorf("failed to write test file: %w", err)
+	written, err := adapters.WriteGeneratedFile(testPath, testCode, prov, w.lastWritten(ctx, repoID, workspacePath, testPath))
+	if err != nil {
+		return nil, fmt.Errorf("failed to write test file: %w", err)
+	}
+
+	switch {
+	case written.MergePath != "":
+		log.Warn().
+			Str("path", testPath).
+			Str("merge", written.MergePath).
+			Int("conflicts", written.Conflicts).
+			Msg("test file was edited by hand, wrote merge proposal instead")
+		log.Debug().Str("diff", written.Diff).Msg("merge proposal diff")
+	case written.Path != testPath:
+		log.Info().Str("path", testPath).Msg("test file written by hand, writing generated tests next to it")
+		fallthrough
+	default:
+		log.Info().Str("path", written.Path).Msg("wrote test file")
+	}
+	return written, nil
+}
+
+// lastWritten looks up what QTest last wrote to a test file of the
+// repository, so edits can be detected even without a provenance header
+func (w *GenerationWorker) lastWritten(ctx context.Context, repoID uuid.UUID, workspacePath, testPath string) adapters.WriteOptions {
+	if w.store == nil || repoID == uuid.Nil {
+		return adapters.WriteOptions{}
+	}
+	rel, err := filepath.Rel(workspacePath, testPath)
+	if err != nil {
+		return adapters.WriteOptions{}
 	}
 
-	log.Info().Str("path", testPath).Msg("wrote test file")
-	return testPath, nil
+	last, err := w.store.LatestTestForFile(ctx, repoID, rel)
+	if err != nil {
+		log.Warn().Err(err).Str("file", rel).Msg("failed to look up previously generated test")
+		return adapters.WriteOptions{}
+	}
+	if last == nil || last.GeneratedCode == nil {
+		return adapters.WriteOptions{}
+	}
+
+	opts := adapters.WriteOptions{Base: *last.GeneratedCode}
+	if last.ContentHash != nil {
+		opts.BaseHash = *last.ContentHash
+	}
+	return opts
 }
 
-// persistGeneratedTest saves the generated test to the database and returns its ID
-func (w *GenerationWorker) persistGeneratedTest(ctx context.Context, runID uuid.UUID, test generator.GeneratedTest, testPath string) string {
+// persistGeneratedTest saves the generated test, with the outcome of its
+// static checks, to the database and returns its ID. A test rejected by the
+// checks is saved as rejected, without code, since none was kept.
+func (w *GenerationWorker) persistGeneratedTest(ctx context.Context, runID uuid.UUID, test generator.GeneratedTest, testPath, workspacePath string, check staticOutcome) string {
 	if w.store == nil {
 		return ""
 	}
@@ -937,6 +1618,25 @@ func (w *GenerationWorker) persistGeneratedTest(ctx context.Context, runID uuid.
 		Framework:      &framework,
 		Status:         "pending",
 	}
+	if check.Status != "" {
+		dbTest.StaticCheck = &check.Status
+	}
+	if rel, err := filepath.Rel(workspacePath, testPath); err == nil {
+		dbTest.TestFile = &rel
+	}
+
+	if check.Status == staticFailed {
+		reason := "compile_error"
+		dbTest.Status = "rejected"
+		dbTest.RejectionReason = &reason
+		dbTest.StaticOutput = &check.Output
+	} else if code, err := os.ReadFile(testPath); err == nil {
+		// Remember what was written, to recognise hand edits on regeneration
+		content := string(code)
+		hash := adapters.ContentHash(content)
+		dbTest.GeneratedCode = &content
+		dbTest.ContentHash = &hash
+	}
 
 	// Store IRSpec JSON in metadata for traceability
 	if test.RawYAML != "" {
@@ -969,6 +1669,8 @@ func detectFramework(testPath string) string {
 		return "pytest"
 	case strings.HasSuffix(testPath, ".ts"), strings.HasSuffix(testPath, ".js"):
 		return "jest"
+	case strings.HasSuffix(testPath, ".rs"):
+		return "cargo"
 	default:
 		return "unknown"
 	}
@@ -987,6 +1689,7 @@ func NewMutationWorker(base *BaseWorker, store *db.Store, cfg *config.Config) *M
 	runner := mutation.NewRunner(
 		mutation.NewGoMutestingTool(),
 		mutation.NewSimpleMutationTool(), // Fallback
+		mutation.NewRustMutationTool(),
 	)
 
 	w := &MutationWorker{
@@ -1044,6 +1747,12 @@ func (w *MutationWorker) handleJob(ctx context.Context, job *jobs.Job) error {
 		MutantsKilled: mutResult.Killed,
 		MutantsLived:  mutResult.Survived,
 		MutationScore: mutResult.Score,
+		SourceFile:    payload.SourceFilePath,
+	}
+	for _, m := range mutResult.Mutants {
+		if m.Status == mutation.StatusSurvived {
+			result.Survivors = append(result.Survivors, jobs.MutantSurvivor{Line: m.Line, Type: m.Type, Description: m.Description})
+		}
 	}
 
 	// Generate report file if there are results
@@ -1180,10 +1889,21 @@ func (w *ValidationWorker) handleJob(ctx context.Context, job *jobs.Job) error {
 
 	startTime := time.Now()
 	results := make([]jobs.TestValidationRes, 0, len(payload.TestFilePaths))
-	var passedTests, failedTests, fixedTests int
+	var passedTests, failedTests, fixedTests, cachedTests int
 
 	// Create validator for the language
 	v := validator.NewValidator(payload.WorkspacePath, payload.Language)
+	cache := validator.NewResultCache(payload.CacheDir)
+
+	// Auto-fix calls count towards the run's LLM usage
+	usage := &llm.RunUsage{}
+	ctx = llm.WithRunUsage(ctx, usage)
+	defer persistRunUsage(context.WithoutCancel(ctx), w.store, payload.GenerationRunID, usage)
+
+	var fixRouter *llm.Router
+	if payload.AutoFix {
+		fixRouter = w.policyRouter(ctx, job, w.llmRouter)
+	}
 
 	// Process each test file
 	for i, testFile := range payload.TestFilePaths {
@@ -1194,12 +1914,33 @@ func (w *ValidationWorker) handleJob(ctx context.Context, job *jobs.Job) error {
 
 		testStart := time.Now()
 		log.Debug().Str("file", testFile).Msg("validating test file")
+		w.Progress(job, "validation", i+1, len(payload.TestFilePaths), testFile)
 
 		res := jobs.TestValidationRes{
 			TestID:   testID,
 			TestFile: testFile,
 		}
 
+		// Skip tests that passed before with the same test and source
+		// content. Tests whose source can't be hashed always run.
+		sourcePath := deriveSourcePath(testFile)
+		cacheKey, err := cache.Key(testFile, sourcePath)
+		if err != nil {
+			log.Debug().Err(err).Str("file", testFile).Msg("validation result not cacheable")
+		}
+		if cached, ok := cache.Get(cacheKey); err == nil && ok {
+			res.Status = cached.Status
+			res.Output = cached.Output
+			res.ValidationMs = time.Since(testStart).Milliseconds()
+			results = append(results, res)
+			passedTests++
+			cachedTests++
+
+			w.updateTestStatus(ctx, testID, cached.Status, "")
+			log.Debug().Str("file", testFile).Msg("validation result cached")
+			continue
+		}
+
 		// Run the test
 		testResult, err := v.RunTests(ctx, testFile)
 		if err != nil {
@@ -1221,6 +1962,7 @@ func (w *ValidationWorker) handleJob(ctx context.Context, job *jobs.Job) error {
 			res.ValidationMs = time.Since(testStart).Milliseconds()
 			results = append(results, res)
 			passedTests++
+			w.cacheResult(cache, testFile, sourcePath, testResult.Output)
 
 			// Update test status in database
 			w.updateTestStatus(ctx, testID, "validated", "")
@@ -1231,13 +1973,13 @@ func (w *ValidationWorker) handleJob(ctx context.Context, job *jobs.Job) error {
 		res.Status = "test_failure"
 		res.ErrorMessage = v.FormatErrorsForLLM(testResult)
 
-		if payload.AutoFix && w.llmRouter != nil {
+		if payload.AutoFix && fixRouter != nil {
 			maxAttempts := payload.MaxFixAttempts
 			if maxAttempts == 0 {
 				maxAttempts = 3
 			}
 
-			fixer := validator.NewFixer(w.llmRouter, llm.Tier2)
+			fixer := validator.NewFixer(fixRouter, llm.Tier2)
 			fixResult, fixErr := fixer.FixTest(ctx, testFile, testResult, v)
 			res.FixAttempts = fixResult.Attempts
 
@@ -1249,6 +1991,7 @@ func (w *ValidationWorker) handleJob(ctx context.Context, job *jobs.Job) error {
 
 				// Update test status in database
 				w.updateTestStatus(ctx, testID, "fixed", "")
+				w.cacheResult(cache, testFile, sourcePath, fixResult.Explanation)
 				log.Info().Str("file", testFile).Int("attempts", fixResult.Attempts).Msg("test fixed")
 			} else {
 				failedTests++
@@ -1272,6 +2015,9 @@ func (w *ValidationWorker) handleJob(ctx context.Context, job *jobs.Job) error {
 		PassedTests:    passedTests,
 		FailedTests:    failedTests,
 		FixedTests:     fixedTests,
+		CachedTests:    cachedTests,
+		ShardIndex:     payload.ShardIndex,
+		ShardCount:     payload.ShardCount,
 		ValidationTime: time.Since(startTime),
 		Results:        results,
 	}
@@ -1281,6 +2027,9 @@ func (w *ValidationWorker) handleJob(ctx context.Context, job *jobs.Job) error {
 		Int("passed", result.PassedTests).
 		Int("failed", result.FailedTests).
 		Int("fixed", result.FixedTests).
+		Int("cached", result.CachedTests).
+		Int("shard", payload.ShardIndex).
+		Int("shards", payload.ShardCount).
 		Dur("duration", result.ValidationTime).
 		Msg("validation completed")
 
@@ -1289,12 +2038,7 @@ func (w *ValidationWorker) handleJob(ctx context.Context, job *jobs.Job) error {
 	}
 
 	// Collect validated test file paths for chaining
-	var validatedPaths []string
-	for _, r := range results {
-		if r.Status == "validated" || r.Status == "fixed" {
-			validatedPaths = append(validatedPaths, r.TestFile)
-		}
-	}
+	validatedPaths := validatedTestPaths(result)
 
 	// Chain to mutation jobs if requested and tests passed
 	if w.Pipeline() != nil && payload.RunMutation && len(validatedPaths) > 0 {
@@ -1311,6 +2055,15 @@ func (w *ValidationWorker) handleJob(ctx context.Context, job *jobs.Job) error {
 		}
 	}
 
+	// A sharded run is integrated once, by the last shard to finish
+	if payload.ShardCount > 1 {
+		paths, last := w.collectShards(ctx, job, payload)
+		if !last {
+			return nil
+		}
+		validatedPaths = paths
+	}
+
 	// Chain to integration job if tests were validated
 	if w.Pipeline() != nil && len(validatedPaths) > 0 {
 		_, err := w.Pipeline().CreateIntegrationJob(ctx, job.ID, payload.RepositoryID, payload.GenerationRunID, validatedPaths, payload.CreatePR)
@@ -1322,6 +2075,88 @@ func (w *ValidationWorker) handleJob(ctx context.Context, job *jobs.Job) error {
 	return nil
 }
 
+// cacheResult records a passing test so unchanged re-runs can skip it
+func (w *ValidationWorker) cacheResult(cache *validator.ResultCache, testFile, sourcePath, output string) {
+	// Re-hash: fixes rewrite the test file
+	key, err := cache.Key(testFile, sourcePath)
+	if err != nil {
+		return
+	}
+	if err := cache.Put(validator.CachedResult{Key: key, TestFile: testFile, Status: "validated", Output: output}); err != nil {
+		log.Warn().Err(err).Str("file", testFile).Msg("failed to cache validation result")
+	}
+}
+
+// collectShards returns the validated tests of every shard of a run once all
+// shards have completed. Only the shard that completed last gets last=true,
+// so the run is integrated exactly once.
+func (w *ValidationWorker) collectShards(ctx context.Context, job *jobs.Job, payload jobs.ValidationPayload) ([]string, bool) {
+	if job.ParentJobID == nil {
+		return nil, false
+	}
+	siblings, err := w.Repository().GetChildJobs(ctx, *job.ParentJobID)
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to list validation shards")
+		return nil, false
+	}
+
+	var shards []*jobs.Job
+	for _, s := range siblings {
+		if s.Type != jobs.JobTypeValidation {
+			continue
+		}
+		var p jobs.ValidationPayload
+		if err := s.GetPayload(&p); err != nil || p.GenerationRunID != payload.GenerationRunID || p.ShardCount != payload.ShardCount {
+			continue
+		}
+		if s.Status != jobs.StatusCompleted || s.CompletedAt == nil {
+			log.Debug().Int("shard", payload.ShardIndex).Msg("waiting for other validation shards")
+			return nil, false
+		}
+		shards = append(shards, s)
+	}
+	if len(shards) < payload.ShardCount {
+		return nil, false
+	}
+
+	sort.Slice(shards, func(i, j int) bool {
+		if !shards[i].CompletedAt.Equal(*shards[j].CompletedAt) {
+			return shards[i].CompletedAt.Before(*shards[j].CompletedAt)
+		}
+		return shards[i].ID.String() < shards[j].ID.String()
+	})
+	if shards[len(shards)-1].ID != job.ID {
+		return nil, false
+	}
+
+	results := make([]jobs.ValidationResult, 0, len(shards))
+	for _, s := range shards {
+		var r jobs.ValidationResult
+		if err := s.GetResult(&r); err == nil {
+			results = append(results, r)
+		}
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].ShardIndex < results[j].ShardIndex })
+
+	var paths []string
+	for _, r := range results {
+		paths = append(paths, validatedTestPaths(r)...)
+	}
+	log.Info().Int("shards", len(shards)).Int("validated", len(paths)).Msg("all validation shards completed")
+	return paths, true
+}
+
+// validatedTestPaths returns the tests that passed or were fixed
+func validatedTestPaths(result jobs.ValidationResult) []string {
+	var paths []string
+	for _, r := range result.Results {
+		if r.Status == "validated" || r.Status == "fixed" {
+			paths = append(paths, r.TestFile)
+		}
+	}
+	return paths
+}
+
 // updateTestStatus updates a test's validation status in the database
 func (w *ValidationWorker) updateTestStatus(ctx context.Context, testID, status, errorMsg string) {
 	if w.store == nil || testID == "" {
@@ -1472,10 +2307,14 @@ func (w *IntegrationWorker) handleJob(ctx context.Context, job *jobs.Job) error
 		return w.Repository().Complete(ctx, job.ID, result)
 	}
 
+	// Monorepo build systems only run tests their build files declare
+	buildFiles := registerBuildTargets(workspacePath, validFiles)
+
 	// Run tests to verify they compile/pass
-	testsPassed, testOutput := w.runTests(ctx, workspacePath, validFiles)
+	suite := w.runTests(ctx, workspacePath, validFiles)
+	testsPassed := suite.Passed
 	if !testsPassed {
-		log.Warn().Str("output", testOutput).Msg("some tests failed verification")
+		log.Warn().Str("output", suite.Output()).Msg("some tests failed verification")
 		// Continue with integration but mark tests as needing review
 	}
 
@@ -1484,6 +2323,11 @@ func (w *IntegrationWorker) handleJob(ctx context.Context, job *jobs.Job) error
 
 	result := jobs.IntegrationResult{
 		FilesIntegrated: len(validFiles),
+		TestsPassed:     testsPassed,
+		TestRuns:        testRunResults(suite),
+	}
+	for _, f := range buildFiles {
+		result.BuildFiles = append(result.BuildFiles, workspaceRel(workspacePath, f))
 	}
 
 	// Create branch and prepare for PR if requested
@@ -1491,10 +2335,21 @@ func (w *IntegrationWorker) handleJob(ctx context.Context, job *jobs.Job) error
 		branchName := fmt.Sprintf("qtest/tests-%s", job.ID.String()[:8])
 		result.BranchName = branchName
 
-		if err := w.createBranch(ctx, workspacePath, branchName, validFiles); err != nil {
+		opts := prOptions(payload, w.getIngestionPayload(ctx, job))
+		branchFiles := append(append([]string(nil), validFiles...), buildFiles...)
+		if opts.TestDependencies {
+			depFiles, summaries := declareTestDependencies(workspacePath, validFiles)
+			branchFiles = append(branchFiles, depFiles...)
+			result.TestDependencies = summaries
+		}
+		strategy := opts.CommitStrategy
+		if err := w.createBranch(ctx, workspacePath, branchName, branchFiles, strategy); err != nil {
 			log.Warn().Err(err).Msg("failed to create branch")
 		} else {
 			log.Info().Str("branch", branchName).Msg("created branch with test files")
+			if err := w.openPR(ctx, job, payload, workspacePath, validFiles, &result); err != nil {
+				log.Warn().Err(err).Msg("failed to open pull request")
+			}
 		}
 	}
 
@@ -1505,6 +2360,67 @@ func (w *IntegrationWorker) handleJob(ctx context.Context, job *jobs.Job) error
 	return nil
 }
 
+// registerBuildTargets adds targets for the tests to the build files of a
+// Bazel or Nx monorepo and returns the files changed. Failing to is logged;
+// the tests are still integrated.
+func registerBuildTargets(workspacePath string, testFiles []string) []string {
+	res, err := buildsys.Register(workspacePath, testFiles)
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to register tests with the build system")
+		return nil
+	}
+	if res.System != buildsys.SystemNone {
+		log.Info().
+			Str("build_system", string(res.System)).
+			Int("changed", len(res.Changed)).
+			Strs("skipped", res.Skipped).
+			Msg("registered tests with the build system")
+	}
+	return res.Changed
+}
+
+// declareTestDependencies declares the dependencies the tests need, such as
+// jest or pytest, in the workspace's package.json and Python dependency
+// files. It returns the files changed and a summary of each change.
+// Failing to is logged; the tests are still integrated.
+func declareTestDependencies(workspacePath string, testFiles []string) ([]string, []string) {
+	var files, summaries []string
+	apply := func(file, summary string, write func() error) {
+		if rel, err := filepath.Rel(workspacePath, file); err != nil || strings.HasPrefix(rel, "..") {
+			return // never edit files outside the repository
+		}
+		if err := write(); err != nil {
+			log.Warn().Err(err).Str("file", file).Msg("failed to declare test dependencies")
+			return
+		}
+		files = append(files, file)
+		summaries = append(summaries, summary)
+	}
+
+	nodeSetups, err := buildsys.CheckNode(testFiles)
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to check the tests' Node dependencies")
+	}
+	for _, s := range nodeSetups {
+		if len(s.DevDependencies) > 0 || s.TestScript != "" || s.TSJestConfig {
+			apply(s.PackageJSON, s.Summary(workspacePath), s.Apply)
+		}
+	}
+
+	pythonSetups, err := buildsys.CheckPython(testFiles)
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to check the tests' Python dependencies")
+	}
+	for _, s := range pythonSetups {
+		apply(s.File, s.Summary(workspacePath), s.Apply)
+	}
+
+	if len(files) > 0 {
+		log.Info().Strs("changes", summaries).Msg("declared test dependencies")
+	}
+	return files, summaries
+}
+
 // getWorkspacePath retrieves workspace path from the job chain
 func (w *IntegrationWorker) getWorkspacePath(ctx context.Context, job *jobs.Job) string {
 	current := job
@@ -1525,39 +2441,443 @@ func (w *IntegrationWorker) getWorkspacePath(ctx context.Context, job *jobs.Job)
 	return ""
 }
 
-// runTests runs the generated tests to verify they work
-func (w *IntegrationWorker) runTests(ctx context.Context, workspacePath string, testFiles []string) (bool, string) {
-	// Detect language from test files
-	if len(testFiles) == 0 {
-		return true, ""
+// openPR pushes the integration branch and opens a pull request with the
+// job's PR options. Options on the integration payload win over those the
+// pipeline was started with. Without a GitHub token the branch stays local.
+func (w *IntegrationWorker) openPR(ctx context.Context, job *jobs.Job, payload jobs.IntegrationPayload, workspacePath string, files []string, result *jobs.IntegrationResult) error {
+	var token string
+	if w.cfg != nil {
+		token = w.cfg.GitHubToken
+	}
+	if token == "" {
+		log.Info().Str("branch", result.BranchName).Msg("no GitHub token configured, leaving branch local")
+		return nil
 	}
 
-	ext := filepath.Ext(testFiles[0])
-	var cmd *exec.Cmd
+	ingestion := w.getIngestionPayload(ctx, job)
+	if ingestion == nil || ingestion.RepositoryURL == "" {
+		return fmt.Errorf("could not determine repository URL")
+	}
+	opts := prOptions(payload, ingestion)
 
-	switch ext {
-	case ".go":
-		// Run go test on the workspace
-		cmd = exec.CommandContext(ctx, "go", "test", "-v", "./...")
-		cmd.Dir = workspacePath
-	case ".py":
-		// Run pytest
-		cmd = exec.CommandContext(ctx, "python", "-m", "pytest", "-v")
-		cmd.Dir = workspacePath
-	case ".ts", ".js":
-		// Run npm test (assumes package.json exists)
-		cmd = exec.CommandContext(ctx, "npm", "test")
-		cmd.Dir = workspacePath
-	default:
-		return true, "unknown test framework"
+	name, owner := extractRepoInfo(ingestion.RepositoryURL)
+	if err := pushBranch(ctx, workspacePath, ingestion.RepositoryURL, owner, name, result.BranchName, token); err != nil {
+		return err
+	}
+
+	prService := github.NewPRService(token)
+
+	base := payload.TargetBranch
+	if base == "" {
+		base = ingestion.Branch
+	}
+	if base == "" {
+		base = "main"
+		if defaultBranch, err := prService.GetDefaultBranch(ctx, owner, name); err == nil {
+			base = defaultBranch
+		}
+	}
+
+	relFiles := make([]string, 0, len(files))
+	for _, f := range files {
+		if rel, err := filepath.Rel(workspacePath, f); err == nil {
+			f = rel
+		}
+		relFiles = append(relFiles, f)
+	}
+
+	tmpl := github.PRTemplate{TestCount: len(files), Files: relFiles, Dependencies: result.TestDependencies}
+	report := w.prReport(ctx, job, payload, workspacePath)
+	tmpl.Risk, tmpl.Metrics = report.risk, report.metrics
+	tmpl.Metrics.TestsPassed = result.TestsPassed
+
+	pr, err := prService.CreatePR(ctx, github.PRRequest{
+		Owner:      owner,
+		Repo:       name,
+		Title:      fmt.Sprintf("Add %d generated tests", len(files)),
+		Body:       prBody(tmpl, workspacePath, opts),
+		Head:       result.BranchName,
+		Base:       base,
+		Draft:      opts.Draft,
+		Maintainer: true,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create PR: %w", err)
+	}
+	result.PRNumber = pr.Number
+	result.PRURL = pr.HTMLURL
+
+	log.Info().
+		Int("number", pr.Number).
+		Str("url", pr.HTMLURL).
+		Bool("draft", opts.Draft).
+		Msg("pull request created")
+
+	// Never queue a merge for tests that didn't pass here
+	autoMerge := opts.AutoMerge && result.TestsPassed
+	if opts.AutoMerge && !autoMerge {
+		log.Warn().Int("number", pr.Number).Msg("tests failed verification, not enabling auto-merge")
+	}
+
+	// The PR is open; settings that fail are reported but don't fail the job
+	if err := prService.Configure(ctx, owner, name, pr, github.PRSettings{
+		Labels:      opts.Labels,
+		Assignees:   opts.Assignees,
+		Reviewers:   opts.Reviewers,
+		AutoMerge:   autoMerge,
+		MergeMethod: opts.MergeMethod,
+	}); err != nil {
+		log.Warn().Err(err).Int("number", pr.Number).Msg("failed to apply PR options")
+	}
+
+	// Point reviewers at the lines the tests still don't check
+	if inline, err := prService.PostQualityReview(ctx, owner, name, pr.Number, report.comments); err != nil {
+		log.Warn().Err(err).Int("number", pr.Number).Msg("failed to post quality review")
+	} else if len(report.comments) > 0 {
+		log.Info().Int("number", pr.Number).Int("comments", len(report.comments)).Int("inline", inline).Msg("posted quality review")
+	}
+
+	publishQualityGate(ctx, prService, owner, name, workspacePath, result.BranchName, report)
+
+	return nil
+}
+
+// publishQualityGate publishes the QTest Quality Gate check run on the PR's
+// head commit when the repository's .qtest.yaml turns it on. Failures are
+// logged; the PR is already open.
+func publishQualityGate(ctx context.Context, prService *github.PRService, owner, name, workspacePath, branch string, report prRunReport) {
+	project, err := config.LoadProjectConfig(workspacePath)
+	if err != nil || !project.PR.QualityGate {
+		return
+	}
+
+	cmd := platform.Git(ctx, "rev-parse", branch)
+	cmd.Dir = workspacePath
+	out, err := cmd.Output()
+	if err != nil {
+		log.Warn().Err(err).Str("branch", branch).Msg("failed to resolve the PR's head commit for the quality gate")
+		return
 	}
 
-	output, err := cmd.CombinedOutput()
+	gate := github.QualityGate{MinCoverage: project.Coverage.Threshold, MinMutationScore: project.Mutation.Threshold}
+	check, err := prService.PublishQualityGate(ctx, owner, name, strings.TrimSpace(string(out)), report.risk.DashboardURL, gate, report.quality)
 	if err != nil {
-		return false, string(output)
+		log.Warn().Err(err).Msg("failed to publish quality gate check run")
+		return
+	}
+	log.Info().Str("conclusion", check.Conclusion).Str("url", check.HTMLURL).Msg("published quality gate")
+}
+
+// prOptions returns an integration's PR options: its own, else those the
+// pipeline was started with
+func prOptions(payload jobs.IntegrationPayload, ingestion *jobs.IngestionPayload) *jobs.PROptions {
+	if payload.PR != nil {
+		return payload.PR
+	}
+	if ingestion != nil && ingestion.PR != nil {
+		return ingestion.PR
+	}
+	return &jobs.PROptions{}
+}
+
+// prRunReport is what a run's PR reports about it
+type prRunReport struct {
+	risk     *github.PRRisk
+	metrics  github.PRMetrics
+	comments []github.ReviewComment
+	quality  github.QualityReport
+}
+
+// prReport gathers the risk analysis, metrics, review comments and quality
+// measurements for a run's PR from its job chain: the plan, the intents
+// generation covered, the mutation jobs finished so far, and the run's LLM
+// usage
+func (w *IntegrationWorker) prReport(ctx context.Context, job *jobs.Job, payload jobs.IntegrationPayload, workspacePath string) prRunReport {
+	var plan jobs.PlanningResult
+	var gen jobs.GenerationResult
+	var genJob *jobs.Job
+	for current := job; current.ParentJobID != nil; {
+		parent, err := w.Repository().GetByID(ctx, *current.ParentJobID)
+		if err != nil || parent == nil {
+			break
+		}
+		switch parent.Type {
+		case jobs.JobTypeGeneration:
+			genJob = parent
+			if err := parent.GetResult(&gen); err != nil {
+				log.Debug().Err(err).Msg("failed to read generation result")
+			}
+		case jobs.JobTypePlanning:
+			if err := parent.GetResult(&plan); err != nil {
+				log.Debug().Err(err).Msg("failed to read planning result")
+			}
+		}
+		current = parent
+	}
+
+	// Mutation jobs hang off the run's validation shards
+	var mutations []jobs.MutationResult
+	if genJob != nil {
+		shards, err := w.Repository().GetChildJobs(ctx, genJob.ID)
+		if err != nil {
+			log.Debug().Err(err).Msg("failed to list validation jobs")
+		}
+		for _, shard := range shards {
+			children, err := w.Repository().GetChildJobs(ctx, shard.ID)
+			if err != nil {
+				continue
+			}
+			for _, child := range children {
+				var result jobs.MutationResult
+				if child.Type == jobs.JobTypeMutation && child.Status == jobs.StatusCompleted && child.GetResult(&result) == nil {
+					mutations = append(mutations, result)
+				}
+			}
+		}
+	}
+
+	var dashboardURL string
+	if w.cfg != nil && w.cfg.DashboardURL != "" {
+		dashboardURL = fmt.Sprintf("%s/repos/%s/runs/%s", strings.TrimRight(w.cfg.DashboardURL, "/"), payload.RepositoryID, payload.GenerationRunID)
+	}
+
+	var summary jobs.RunSummary
+	if w.store != nil {
+		if run, err := w.store.GetGenerationRun(ctx, payload.GenerationRunID); err == nil && run != nil && run.Summary != nil {
+			if err := json.Unmarshal(*run.Summary, &summary); err != nil {
+				log.Debug().Err(err).Msg("failed to read run summary")
+			}
+		}
+	}
+
+	metrics := runMetrics(gen, mutations, summary)
+	metrics.RunID = payload.GenerationRunID.String()
+	return prRunReport{
+		risk:     riskSummary(plan.Targets, gen.CoveredIntents, mutations, workspacePath, dashboardURL),
+		metrics:  metrics,
+		comments: reviewComments(plan.Targets, gen.CoveredIntents, mutations, workspacePath),
+		quality:  qualityReport(mutations, workspacePath),
+	}
+}
+
+// qualityReport totals a run's mutation results per source file for the
+// quality gate. Coverage isn't measured by the pipeline.
+func qualityReport(mutations []jobs.MutationResult, workspacePath string) github.QualityReport {
+	var report github.QualityReport
+	index := make(map[string]int)
+	var totals, kills []int
+	var total, killed int
+	for _, m := range mutations {
+		if m.MutantsTotal == 0 {
+			continue
+		}
+		file := filepath.ToSlash(workspaceRel(workspacePath, m.SourceFile))
+		i, ok := index[file]
+		if !ok {
+			i = len(report.Files)
+			index[file] = i
+			report.Files = append(report.Files, github.FileQuality{Path: file, MutationMeasured: true})
+			totals, kills = append(totals, 0), append(kills, 0)
+		}
+		totals[i] += m.MutantsTotal
+		kills[i] += m.MutantsKilled
+		total += m.MutantsTotal
+		killed += m.MutantsKilled
+		for _, s := range m.Survivors {
+			report.Files[i].Survivors = append(report.Files[i].Survivors, github.ReviewComment{
+				Path: file,
+				Line: s.Line,
+				Body: fmt.Sprintf("%s: %s", s.Type, s.Description),
+			})
+		}
 	}
+	for i := range report.Files {
+		report.Files[i].MutationScore = 100 * float64(kills[i]) / float64(totals[i])
+	}
+	if total > 0 {
+		report.MutationMeasured = true
+		report.MutationScore = 100 * float64(killed) / float64(total)
+	}
+	return report
+}
 
-	return true, string(output)
+// runMetrics totals a run's figures for its PR
+func runMetrics(gen jobs.GenerationResult, mutations []jobs.MutationResult, summary jobs.RunSummary) github.PRMetrics {
+	metrics := github.PRMetrics{
+		TargetsCovered: len(gen.CoveredIntents),
+		TargetsFailed:  len(gen.FailedIntents),
+	}
+	var total, killed int
+	for _, m := range mutations {
+		total += m.MutantsTotal
+		killed += m.MutantsKilled
+		metrics.MutantsLived += m.MutantsLived
+	}
+	if total > 0 {
+		metrics.MutationScore = 100 * float64(killed) / float64(total)
+	}
+	if summary.LLMUsage != nil {
+		metrics.LLMCalls = summary.LLMUsage.Calls
+		metrics.LLMCostUSD = summary.LLMUsage.CostUSD
+	}
+	return metrics
+}
+
+// prBody renders a run's PR description with the repository's template from
+// .qtest.yaml, else the one in its PR options. A template that fails to
+// render falls back to the default body, so the PR still opens.
+func prBody(tmpl github.PRTemplate, workspacePath string, opts *jobs.PROptions) string {
+	text := opts.BodyTemplate
+	if project, err := config.LoadProjectConfig(workspacePath); err != nil {
+		log.Warn().Err(err).Msg("failed to load project config for the PR body")
+	} else if project.PR.BodyTemplate != "" {
+		text = project.PR.BodyTemplate
+	}
+
+	body, err := github.RenderPRBody(tmpl, text)
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to render custom PR body, using the default")
+		return github.GeneratePRBody(tmpl)
+	}
+	return body
+}
+
+// riskSummary builds a PR's risk analysis. High priority API targets a test
+// was generated for are covered endpoints; other high priority targets
+// without one are gaps.
+func riskSummary(targets []jobs.PlanTarget, coveredIntents []string, mutations []jobs.MutationResult, workspacePath, dashboardURL string) *github.PRRisk {
+	risk := &github.PRRisk{DashboardURL: dashboardURL}
+
+	covered := make(map[string]bool, len(coveredIntents))
+	for _, id := range coveredIntents {
+		covered[id] = true
+	}
+	for _, t := range targets {
+		if t.Priority != "high" {
+			continue
+		}
+		if covered[t.IntentID] {
+			if t.Endpoint != "" {
+				risk.CoveredEndpoints = append(risk.CoveredEndpoints, t.Endpoint)
+			}
+			continue
+		}
+		if t.Endpoint != "" {
+			risk.CriticalGaps = append(risk.CriticalGaps, t.Endpoint)
+		} else {
+			risk.CriticalGaps = append(risk.CriticalGaps, fmt.Sprintf("%s (%s)", t.Function, workspaceRel(workspacePath, t.File)))
+		}
+	}
+
+	for _, m := range mutations {
+		file := workspaceRel(workspacePath, m.SourceFile)
+		for _, s := range m.Survivors {
+			risk.MutationSurvivors = append(risk.MutationSurvivors, fmt.Sprintf("`%s:%d` %s: %s", file, s.Line, s.Type, s.Description))
+		}
+	}
+
+	return risk
+}
+
+// reviewComments builds the PR's inline review comments: one on each line a
+// mutant survived on, and one on each planned target no test was generated
+// for
+func reviewComments(targets []jobs.PlanTarget, coveredIntents []string, mutations []jobs.MutationResult, workspacePath string) []github.ReviewComment {
+	var comments []github.ReviewComment
+	for _, m := range mutations {
+		file := filepath.ToSlash(workspaceRel(workspacePath, m.SourceFile))
+		for _, s := range m.Survivors {
+			if s.Line <= 0 || filepath.IsAbs(file) {
+				continue
+			}
+			comments = append(comments, github.ReviewComment{
+				Path: file,
+				Line: s.Line,
+				Body: fmt.Sprintf("**Surviving mutant** (%s): %s\n\nNo test fails when this line is changed this way. Consider an assertion that tells the two apart.", s.Type, s.Description),
+			})
+		}
+	}
+
+	covered := make(map[string]bool, len(coveredIntents))
+	for _, id := range coveredIntents {
+		covered[id] = true
+	}
+	for _, t := range targets {
+		file := filepath.ToSlash(workspaceRel(workspacePath, t.File))
+		if covered[t.IntentID] || t.Line <= 0 || filepath.IsAbs(file) {
+			continue
+		}
+		name := t.Function
+		if t.Endpoint != "" {
+			name = t.Endpoint
+		}
+		body := fmt.Sprintf("**Coverage gap**: no test was generated for `%s`.", name)
+		if t.Priority != "" {
+			body = fmt.Sprintf("**Coverage gap** (%s priority): no test was generated for `%s`.", t.Priority, name)
+		}
+		comments = append(comments, github.ReviewComment{Path: file, Line: t.Line, Body: body})
+	}
+	return comments
+}
+
+// workspaceRel returns path relative to the workspace when it's inside it
+func workspaceRel(workspacePath, path string) string {
+	if !filepath.IsAbs(path) {
+		return path
+	}
+	if rel, err := filepath.Rel(workspacePath, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return path
+}
+
+// pushBranch pushes a branch to the repository over HTTPS with a token. The
+// token is scrubbed from any error output.
+func pushBranch(ctx context.Context, workspacePath, repoURL, owner, name, branch, token string) error {
+	remote := pushURL(repoURL, owner, name, token)
+	cmd := platform.Git(ctx, "push", remote, fmt.Sprintf("%s:refs/heads/%s", branch, branch))
+	cmd.Dir = workspacePath
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to push branch: %s: %w", strings.ReplaceAll(string(output), token, "***"), err)
+	}
+	return nil
+}
+
+// pushURL returns an HTTPS remote authenticated with token. SSH URLs are
+// pushed through github.com.
+func pushURL(repoURL, owner, name, token string) string {
+	u, err := url.Parse(repoURL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") {
+		u = &url.URL{Scheme: "https", Host: "github.com", Path: fmt.Sprintf("/%s/%s.git", owner, name)}
+	}
+	u.User = url.UserPassword("x-access-token", token)
+	return u.String()
+}
+
+// runTests runs the generated tests to verify they work. Each file goes to
+// the runner for its language, so mixed repositories are fully covered.
+func (w *IntegrationWorker) runTests(ctx context.Context, workspacePath string, testFiles []string) *validator.SuiteResult {
+	return validator.NewRunnerRegistry().RunAll(ctx, workspacePath, testFiles)
+}
+
+// testRunResults converts a suite result into per-group job results
+func testRunResults(suite *validator.SuiteResult) []jobs.TestRunResult {
+	runs := make([]jobs.TestRunResult, 0, len(suite.Groups))
+	for _, g := range suite.Groups {
+		run := jobs.TestRunResult{
+			Runner:     g.Runner,
+			Language:   g.Language,
+			Target:     g.Target,
+			Files:      len(g.Files),
+			Passed:     g.Passed,
+			DurationMs: g.Duration.Milliseconds(),
+		}
+		for _, e := range g.Errors {
+			run.Failures = append(run.Failures, e.TestName)
+		}
+		runs = append(runs, run)
+	}
+	return runs
 }
 
 // updateTestStatuses updates the status of generated tests in the database
@@ -1589,36 +2909,122 @@ func (w *IntegrationWorker) updateTestStatuses(ctx context.Context, runID uuid.U
 	}
 }
 
-// createBranch creates a git branch with the test files
-func (w *IntegrationWorker) createBranch(ctx context.Context, workspacePath, branchName string, testFiles []string) error {
+// createBranch creates a git branch with the test files, committed in one
+// commit or, with the per-package strategy, a commit per package
+func (w *IntegrationWorker) createBranch(ctx context.Context, workspacePath, branchName string, testFiles []string, strategy string) error {
 	// Create and checkout new branch
-	cmd := exec.CommandContext(ctx, "git", "checkout", "-b", branchName)
+	cmd := platform.Git(ctx, "checkout", "-b", branchName)
 	cmd.Dir = workspacePath
 	if output, err := cmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("failed to create branch: %s: %w", string(output), err)
 	}
 
-	// Add test files
-	for _, testFile := range testFiles {
-		relPath, err := filepath.Rel(workspacePath, testFile)
-		if err != nil {
-			relPath = testFile
+	if strategy != jobs.CommitStrategyPerPackage {
+		relFiles := make([]string, 0, len(testFiles))
+		for _, f := range testFiles {
+			relFiles = append(relFiles, workspaceRel(workspacePath, f))
+		}
+		return commitFiles(ctx, workspacePath, relFiles, "Add generated tests\n\nGenerated by QTest")
+	}
+
+	commits := packageCommits(workspacePath, testFiles)
+	for _, c := range commits {
+		if err := commitFiles(ctx, workspacePath, c.Files, c.message()); err != nil {
+			return fmt.Errorf("failed to commit tests for %s: %w", c.Package, err)
 		}
+	}
+	log.Info().Int("commits", len(commits)).Msg("committed tests per package")
+	return nil
+}
 
-		cmd := exec.CommandContext(ctx, "git", "add", relPath)
+// commitFiles commits the files, given relative to the workspace
+func commitFiles(ctx context.Context, workspacePath string, files []string, message string) error {
+	for _, file := range files {
+		cmd := platform.Git(ctx, "add", "--", file)
 		cmd.Dir = workspacePath
 		if output, err := cmd.CombinedOutput(); err != nil {
-			log.Warn().Str("file", relPath).Str("output", string(output)).Msg("failed to add file")
+			log.Warn().Str("file", file).Str("output", string(output)).Msg("failed to add file")
 		}
 	}
 
-	// Commit the changes
-	commitMsg := fmt.Sprintf("Add generated tests\n\nGenerated by QTest")
-	cmd = exec.CommandContext(ctx, "git", "commit", "-m", commitMsg)
+	cmd := platform.Git(ctx, "commit", "-m", message)
 	cmd.Dir = workspacePath
 	if output, err := cmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("failed to commit: %s: %w", string(output), err)
 	}
-
 	return nil
 }
+
+// maxCommitTargets caps the targets a commit message lists
+const maxCommitTargets = 20
+
+// testCommit is the generated tests for one package, committed together
+type testCommit struct {
+	Package string   // directory of the code under test, relative to the workspace
+	Files   []string // test files, relative to the workspace
+	Targets []string // functions under test
+}
+
+// packageCommits groups test files by the package of the code they test,
+// from their provenance headers, falling back to the test file's own
+// directory. Commits are in package order.
+func packageCommits(workspacePath string, testFiles []string) []testCommit {
+	byPackage := make(map[string]*testCommit)
+	for _, file := range testFiles {
+		rel := workspaceRel(workspacePath, file)
+		pkg := filepath.Dir(rel)
+
+		var targets []string
+		if content, err := os.ReadFile(filepath.Join(workspacePath, rel)); err == nil {
+			if prov, err := adapters.ParseProvenance(string(content)); err == nil && prov != nil {
+				if prov.Source != "" {
+					pkg = filepath.Dir(prov.Source)
+				}
+				targets = prov.Targets
+			}
+		}
+
+		pkg = filepath.ToSlash(pkg)
+		c, ok := byPackage[pkg]
+		if !ok {
+			c = &testCommit{Package: pkg}
+			byPackage[pkg] = c
+		}
+		c.Files = append(c.Files, rel)
+		c.Targets = append(c.Targets, targets...)
+	}
+
+	commits := make([]testCommit, 0, len(byPackage))
+	for _, c := range byPackage {
+		commits = append(commits, *c)
+	}
+	sort.Slice(commits, func(i, j int) bool { return commits[i].Package < commits[j].Package })
+	return commits
+}
+
+// message describes the commit: the package in the subject, the targets
+// covered in the body
+func (c testCommit) message() string {
+	pkg := c.Package
+	if pkg == "." {
+		pkg = "the root package"
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Add generated tests for %s\n\n", pkg)
+	if len(c.Targets) > 0 {
+		targets := append([]string(nil), c.Targets...)
+		sort.Strings(targets)
+		fmt.Fprintf(&sb, "Targets covered (%d):\n", len(targets))
+		for i, target := range targets {
+			if i == maxCommitTargets {
+				fmt.Fprintf(&sb, "- ...and %d more\n", len(targets)-maxCommitTargets)
+				break
+			}
+			fmt.Fprintf(&sb, "- %s\n", target)
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString("Generated by QTest")
+	return sb.String()
+}
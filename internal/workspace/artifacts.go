@@ -159,10 +159,17 @@ func (a *ArtifactManager) GenerateExecutionReport(results []TestResult, duration
 		report.Summary.PassRate = float64(report.Summary.Passed) / float64(report.Summary.Total) * 100
 	}
 
-	// Save artifact
+	// Save artifact, and JUnit XML for CI test reporters
 	if err := a.saveArtifact("execution.json", report); err != nil {
 		return nil, err
 	}
+	junit, err := ExportJUnit(report)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.saveRawArtifact("junit.xml", junit); err != nil {
+		return nil, err
+	}
 
 	return report, nil
 }
@@ -189,6 +196,7 @@ type FileCoverage struct {
 	CoveredLines    int     `json:"covered_lines"`
 	CoveragePercent float64 `json:"coverage_percent"`
 	UncoveredLines  []int   `json:"uncovered_lines"`
+	HitLines        []int   `json:"hit_lines,omitempty"` // lines run at least once, when the tool reports them
 }
 
 // GenerateCoverageReport creates the coverage report artifact
@@ -213,14 +221,44 @@ func (a *ArtifactManager) GenerateCoverageReport(files []FileCoverage) (*Coverag
 		report.Summary.CoveragePercent = float64(report.Summary.CoveredLines) / float64(report.Summary.TotalLines) * 100
 	}
 
-	// Save artifact
+	// Save artifact, and Cobertura and LCOV for CI coverage reporters
 	if err := a.saveArtifact("coverage.json", report); err != nil {
 		return nil, err
 	}
+	cobertura, err := ExportCobertura(report)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.saveRawArtifact("cobertura.xml", cobertura); err != nil {
+		return nil, err
+	}
+	if err := a.saveRawArtifact("lcov.info", ExportLCOV(report)); err != nil {
+		return nil, err
+	}
 
 	return report, nil
 }
 
+// GenerateSourceView creates the coverage-annotated source view artifact,
+// as JSON and as HTML for reviewers
+func (a *ArtifactManager) GenerateSourceView(report *CoverageReport) (*SourceView, error) {
+	view, err := BuildSourceView(a.ws.RepoPath, report)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.saveArtifact("source-view.json", view); err != nil {
+		return nil, err
+	}
+	page, err := ExportSourceViewHTML(view)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.saveRawArtifact("source-view.html", page); err != nil {
+		return nil, err
+	}
+	return view, nil
+}
+
 // MutationReport represents mutation testing results
 type MutationReport struct {
 	Version         string           `json:"version"`
@@ -367,14 +405,18 @@ func (a *ArtifactManager) LoadArtifact(name string, v interface{}) error {
 
 // saveArtifact saves an artifact to disk
 func (a *ArtifactManager) saveArtifact(name string, v interface{}) error {
-	if err := a.Init(); err != nil {
-		return err
-	}
-
 	data, err := json.MarshalIndent(v, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal artifact: %w", err)
 	}
+	return a.saveRawArtifact(name, data)
+}
+
+// saveRawArtifact saves an artifact that's already encoded
+func (a *ArtifactManager) saveRawArtifact(name string, data []byte) error {
+	if err := a.Init(); err != nil {
+		return err
+	}
 
 	path := filepath.Join(a.artifactDir, name)
 	if err := os.WriteFile(path, data, 0644); err != nil {
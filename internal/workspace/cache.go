@@ -0,0 +1,262 @@
+package workspace
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/QTest-hq/qtest/internal/parser"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// parseCacheVersion invalidates entries written in another format
+	parseCacheVersion = 1
+
+	// parseCacheMaxAge is how long an entry is kept without being used
+	parseCacheMaxAge = 30 * 24 * time.Hour
+
+	// cacheRepoRoot stands in for the repository's root in cached paths
+	cacheRepoRoot = "$REPO"
+)
+
+// ParseCache stores parsed source files keyed by the SHA256 of their
+// repository-relative path and content, so re-runs over large repositories
+// only parse files that changed. It's shared by the workspaces under a
+// base directory, as `qtest generate` creates a workspace per run.
+type ParseCache struct {
+	dir    string
+	hits   int
+	misses int
+}
+
+// CacheEntry is a cached parse of one source file. Paths are stored
+// relative to the repository, so entries apply to any copy of it.
+type CacheEntry struct {
+	Version int                `json:"version"`
+	File    *parser.ParsedFile `json:"file"`
+
+	// Fingerprints of the file's exported targets, by target ID
+	Fingerprints map[string]*Fingerprint `json:"fingerprints,omitempty"`
+}
+
+// ParseCacheDir returns the directory the parse cache of a workspace base
+// directory is stored in
+func ParseCacheDir(cfg *WorkspaceConfig) string {
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+	return filepath.Join(cfg.BaseDir, ".cache", "parse")
+}
+
+// NewParseCache returns the parse cache shared by a workspace and its
+// siblings. Workspaces without a directory get a cache that stores nothing.
+func NewParseCache(ws *Workspace) *ParseCache {
+	if ws == nil || ws.path == "" {
+		return &ParseCache{}
+	}
+	return &ParseCache{dir: ParseCacheDir(&WorkspaceConfig{BaseDir: filepath.Dir(ws.path)})}
+}
+
+// CacheKey returns the key a source file of a repository is cached under
+func CacheKey(repoPath, path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	h.Write([]byte(relToRepo(repoPath, path)))
+	h.Write([]byte{0})
+	h.Write(data)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Get returns the cached entry for a key with its paths rooted at repoPath
+func (c *ParseCache) Get(key, repoPath string) (*CacheEntry, bool) {
+	if c.dir == "" {
+		c.misses++
+		return nil, false
+	}
+
+	path := c.entryPath(key)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		c.misses++
+		return nil, false
+	}
+
+	var entry CacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.Version != parseCacheVersion || entry.File == nil {
+		c.misses++
+		return nil, false
+	}
+	c.hits++
+
+	// Entries are pruned by when they were last used
+	now := time.Now()
+	os.Chtimes(path, now, now)
+
+	return rebaseEntry(&entry, cacheRepoRoot, repoPath), true
+}
+
+// Put caches a file of the repository at repoPath under a key
+func (c *ParseCache) Put(key, repoPath string, parsed *parser.ParsedFile, fingerprints map[string]*Fingerprint) error {
+	if c.dir == "" {
+		return nil
+	}
+
+	entry := rebaseEntry(&CacheEntry{
+		Version:      parseCacheVersion,
+		File:         parsed,
+		Fingerprints: fingerprints,
+	}, repoPath, cacheRepoRoot)
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal cache entry: %w", err)
+	}
+
+	path := c.entryPath(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	return nil
+}
+
+// Prune removes entries that haven't been used within maxAge, returning
+// how many were removed
+func (c *ParseCache) Prune(maxAge time.Duration) (int, error) {
+	if c.dir == "" {
+		return 0, nil
+	}
+
+	cutoff := time.Now().Add(-maxAge)
+	removed := 0
+	err := filepath.Walk(c.dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if info.IsDir() || info.ModTime().After(cutoff) {
+			return nil
+		}
+		if err := os.Remove(path); err == nil {
+			removed++
+		}
+		return nil
+	})
+	return removed, err
+}
+
+// Clear removes every cached entry
+func (c *ParseCache) Clear() error {
+	if c.dir == "" {
+		return nil
+	}
+	if err := os.RemoveAll(c.dir); err != nil {
+		return fmt.Errorf("failed to clear parse cache: %w", err)
+	}
+	return nil
+}
+
+// ClearParseCache removes the parse cache of a workspace base directory
+func ClearParseCache(cfg *WorkspaceConfig) error {
+	return (&ParseCache{dir: ParseCacheDir(cfg)}).Clear()
+}
+
+// Stats returns the cache hits and misses since it was created
+func (c *ParseCache) Stats() (hits, misses int) {
+	return c.hits, c.misses
+}
+
+// entryPath shards entries by the key's first byte, keeping directories
+// small in large repositories
+func (c *ParseCache) entryPath(key string) string {
+	return filepath.Join(c.dir, key[:2], key+".json")
+}
+
+// rebaseEntry returns a copy of an entry with paths under one repository
+// root moved to another
+func rebaseEntry(entry *CacheEntry, from, to string) *CacheEntry {
+	from += string(filepath.Separator)
+	to += string(filepath.Separator)
+	rebase := func(s string) string {
+		if strings.HasPrefix(s, from) {
+			return to + strings.TrimPrefix(s, from)
+		}
+		return s
+	}
+	functions := func(fns []parser.Function) []parser.Function {
+		out := make([]parser.Function, len(fns))
+		for i, fn := range fns {
+			fn.ID = rebase(fn.ID)
+			out[i] = fn
+		}
+		return out
+	}
+
+	file := *entry.File
+	file.Path = rebase(file.Path)
+	file.Functions = functions(file.Functions)
+	file.Classes = make([]parser.Class, len(entry.File.Classes))
+	for i, class := range entry.File.Classes {
+		class.ID = rebase(class.ID)
+		class.Methods = functions(class.Methods)
+		file.Classes[i] = class
+	}
+
+	out := &CacheEntry{Version: entry.Version, File: &file}
+	if entry.Fingerprints != nil {
+		out.Fingerprints = make(map[string]*Fingerprint, len(entry.Fingerprints))
+		for id, fp := range entry.Fingerprints {
+			out.Fingerprints[rebase(id)] = fp
+		}
+	}
+	return out
+}
+
+// parseCached parses a source file of the repository at repoPath, or
+// returns its cached parse when the file hasn't changed
+func parseCached(ctx context.Context, p *parser.Parser, cache *ParseCache, repoPath, path string) (*parser.ParsedFile, map[string]*Fingerprint, error) {
+	key, err := CacheKey(repoPath, path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if entry, ok := cache.Get(key, repoPath); ok {
+		return entry.File, entry.Fingerprints, nil
+	}
+
+	parsed, err := p.ParseFile(ctx, path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fingerprints := make(map[string]*Fingerprint)
+	for _, fn := range parsed.Functions {
+		if fn.Exported {
+			fingerprints[targetID(fn, path)] = fingerprintFunction(fn)
+		}
+	}
+	if err := cache.Put(key, repoPath, parsed, fingerprints); err != nil {
+		log.Debug().Err(err).Str("file", path).Msg("failed to cache parsed file")
+	}
+	return parsed, fingerprints, nil
+}
+
+// pruneParseCache drops entries unused for parseCacheMaxAge after a parse
+func pruneParseCache(cache *ParseCache) {
+	if _, err := cache.Prune(parseCacheMaxAge); err != nil {
+		log.Debug().Err(err).Msg("failed to prune parse cache")
+	}
+}
@@ -0,0 +1,144 @@
+package workspace
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/QTest-hq/qtest/internal/parser"
+)
+
+func TestParseCache_Runner(t *testing.T) {
+	base := t.TempDir()
+	newWorkspace := func(id string) *Workspace {
+		ws := &Workspace{
+			ID:       id,
+			RepoPath: filepath.Join(base, id, "repo"),
+			path:     filepath.Join(base, id),
+			State:    &WorkspaceState{Targets: make(map[string]*TargetState)},
+		}
+		os.MkdirAll(ws.RepoPath, 0755)
+		return ws
+	}
+	write := func(ws *Workspace, name, content string) {
+		if err := os.WriteFile(filepath.Join(ws.RepoPath, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	parse := func(ws *Workspace) (int, int) {
+		r := NewRunner(ws, nil, "", nil)
+		if err := r.parse(context.Background()); err != nil {
+			t.Fatalf("parse() error = %v", err)
+		}
+		return r.cache.Stats()
+	}
+
+	ws := newWorkspace("ws1")
+	write(ws, "math.go", "package math\n\nfunc Add(a, b int) int { return a + b }\n")
+	write(ws, "strs.go", "package math\n\nfunc Upper(s string) string { return s }\n")
+
+	if hits, misses := parse(ws); hits != 0 || misses != 2 {
+		t.Errorf("first parse: hits = %d, misses = %d, want 0 and 2", hits, misses)
+	}
+	if hits, misses := parse(ws); hits != 2 || misses != 0 {
+		t.Errorf("second parse: hits = %d, misses = %d, want 2 and 0", hits, misses)
+	}
+
+	// Only the changed file is parsed again
+	write(ws, "math.go", "package math\n\nfunc Add(a, b int) int { return b + a }\n")
+	if hits, misses := parse(ws); hits != 1 || misses != 1 {
+		t.Errorf("after change: hits = %d, misses = %d, want 1 and 1", hits, misses)
+	}
+
+	// Another workspace of the same repository reuses the cache, with
+	// targets in its own checkout
+	other := newWorkspace("ws2")
+	write(other, "math.go", "package math\n\nfunc Add(a, b int) int { return b + a }\n")
+	write(other, "strs.go", "package math\n\nfunc Upper(s string) string { return s }\n")
+	if hits, misses := parse(other); hits != 2 || misses != 0 {
+		t.Errorf("other workspace: hits = %d, misses = %d, want 2 and 0", hits, misses)
+	}
+	if len(other.State.Targets) != 2 {
+		t.Errorf("targets = %d, want 2", len(other.State.Targets))
+	}
+	for id, target := range other.State.Targets {
+		if !strings.HasPrefix(id, other.RepoPath) || target.File == "" || target.Fingerprint == nil {
+			t.Errorf("target %s = %+v, want one in %s with a fingerprint", id, target, other.RepoPath)
+		}
+	}
+
+	if err := ClearParseCache(&WorkspaceConfig{BaseDir: base}); err != nil {
+		t.Fatalf("ClearParseCache() error = %v", err)
+	}
+	if hits, misses := parse(ws); hits != 0 || misses != 2 {
+		t.Errorf("after clear: hits = %d, misses = %d, want 0 and 2", hits, misses)
+	}
+}
+
+func TestParseCache_Get(t *testing.T) {
+	cache := NewParseCache(&Workspace{path: filepath.Join(t.TempDir(), "ws")})
+
+	repo := t.TempDir()
+	src := filepath.Join(repo, "a.go")
+	os.WriteFile(src, []byte("package a\n"), 0644)
+	key, err := CacheKey(repo, src)
+	if err != nil {
+		t.Fatalf("CacheKey() error = %v", err)
+	}
+	if len(key) != 64 {
+		t.Errorf("key = %q, want a SHA256", key)
+	}
+
+	if _, ok := cache.Get(key, repo); ok {
+		t.Error("empty cache shouldn't hit")
+	}
+
+	parsed := &parser.ParsedFile{
+		Path:      src,
+		Functions: []parser.Function{{ID: src + ":3:Add", Name: "Add"}},
+		Classes:   []parser.Class{{ID: src + ":9:Calc", Methods: []parser.Function{{ID: src + ":10:Sum"}}}},
+	}
+	if err := cache.Put(key, repo, parsed, map[string]*Fingerprint{src + ":3:Add": {Params: 2}}); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	entry, ok := cache.Get(key, "/elsewhere")
+	if !ok {
+		t.Fatal("Get() missed a cached entry")
+	}
+	want := filepath.Join("/elsewhere", "a.go")
+	if entry.File.Path != want || entry.File.Functions[0].ID != want+":3:Add" ||
+		entry.File.Classes[0].Methods[0].ID != want+":10:Sum" || entry.Fingerprints[want+":3:Add"] == nil {
+		t.Errorf("entry wasn't rebased to /elsewhere: %+v", entry.File)
+	}
+	if parsed.Path != src {
+		t.Errorf("Put() changed the parsed file's path to %s", parsed.Path)
+	}
+
+	// Entries that can't be read are misses
+	path := cache.entryPath(key)
+	os.WriteFile(path, []byte("{not json"), 0644)
+	if _, ok := cache.Get(key, repo); ok {
+		t.Error("corrupt entry shouldn't hit")
+	}
+	os.WriteFile(path, []byte(`{"version":0,"file":{"Path":"a.go"}}`), 0644)
+	if _, ok := cache.Get(key, repo); ok {
+		t.Error("entry from another version shouldn't hit")
+	}
+
+	// Unused entries are pruned
+	old := time.Now().Add(-2 * parseCacheMaxAge)
+	os.Chtimes(path, old, old)
+	if removed, err := cache.Prune(parseCacheMaxAge); err != nil || removed != 1 {
+		t.Errorf("Prune() = %d, %v, want 1 removed", removed, err)
+	}
+
+	// Workspaces without a directory don't cache
+	noop := NewParseCache(&Workspace{})
+	noop.Put(key, repo, parsed, nil)
+	if _, ok := noop.Get(key, repo); ok {
+		t.Error("cache without a directory shouldn't hit")
+	}
+}
@@ -0,0 +1,272 @@
+package workspace
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Changelog files, relative to the repository root. The markdown file is for
+// reviewers; the JSON feed is for tooling and holds the state needed to diff
+// the next run.
+const (
+	ChangelogFile     = "TESTS_CHANGELOG.md"
+	ChangelogFeedFile = ".qtest/changelog.json"
+)
+
+// ChangelogEntry summarizes how one generation run changed the test suite
+type ChangelogEntry struct {
+	RunAt         time.Time    `json:"run_at"`
+	WorkspaceID   string       `json:"workspace_id,omitempty"`
+	CommitSHA     string       `json:"commit_sha,omitempty"` // source commit the tests were generated against
+	Added         []string     `json:"added,omitempty"`
+	Removed       []string     `json:"removed,omitempty"`
+	Regenerated   []string     `json:"regenerated,omitempty"`
+	Coverage      *MetricDelta `json:"coverage,omitempty"`
+	MutationScore *MetricDelta `json:"mutation_score,omitempty"`
+}
+
+// MetricDelta is a before/after percentage. Before is nil on the first run.
+type MetricDelta struct {
+	Before *float64 `json:"before,omitempty"`
+	After  float64  `json:"after"`
+}
+
+// ChangelogFeed is the persisted history, newest entry first
+type ChangelogFeed struct {
+	Entries []ChangelogEntry `json:"entries"`
+
+	// State at the end of the last run
+	Files         map[string]string `json:"files"` // test file -> content hash
+	Coverage      *float64          `json:"coverage,omitempty"`
+	MutationScore *float64          `json:"mutation_score,omitempty"`
+}
+
+// LoadChangelogFeed reads the feed from a repository, returning an empty
+// feed if none exists yet
+func LoadChangelogFeed(repoPath string) (*ChangelogFeed, error) {
+	feed := &ChangelogFeed{Files: make(map[string]string)}
+
+	data, err := os.ReadFile(filepath.Join(repoPath, ChangelogFeedFile))
+	if os.IsNotExist(err) {
+		return feed, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read changelog feed: %w", err)
+	}
+	if err := json.Unmarshal(data, feed); err != nil {
+		return nil, fmt.Errorf("invalid changelog feed: %w", err)
+	}
+	if feed.Files == nil {
+		feed.Files = make(map[string]string)
+	}
+	return feed, nil
+}
+
+// Record diffs the test files written by this run (and every file tracked
+// from earlier runs) against the feed's state and prepends an entry. It
+// returns nil when the suite and metrics are unchanged.
+func (f *ChangelogFeed) Record(repoPath string, written []string, coverage, mutationScore *float64, entry ChangelogEntry) *ChangelogEntry {
+	current := make(map[string]string, len(f.Files))
+
+	// Files from earlier runs that still exist
+	for rel := range f.Files {
+		if hash, err := hashFile(filepath.Join(repoPath, rel)); err == nil {
+			current[rel] = hash
+		} else {
+			entry.Removed = append(entry.Removed, rel)
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, path := range written {
+		rel := relToRepo(repoPath, path)
+		if seen[rel] {
+			continue
+		}
+		seen[rel] = true
+
+		hash, err := hashFile(filepath.Join(repoPath, rel))
+		if err != nil {
+			continue
+		}
+		prev, existed := f.Files[rel]
+		switch {
+		case !existed:
+			entry.Added = append(entry.Added, rel)
+		case prev != hash:
+			entry.Regenerated = append(entry.Regenerated, rel)
+		}
+		current[rel] = hash
+	}
+
+	entry.Coverage = metricDelta(f.Coverage, coverage)
+	entry.MutationScore = metricDelta(f.MutationScore, mutationScore)
+
+	f.Files = current
+	if coverage != nil {
+		f.Coverage = coverage
+	}
+	if mutationScore != nil {
+		f.MutationScore = mutationScore
+	}
+
+	if len(entry.Added) == 0 && len(entry.Removed) == 0 && len(entry.Regenerated) == 0 &&
+		entry.Coverage == nil && entry.MutationScore == nil {
+		return nil
+	}
+
+	sort.Strings(entry.Added)
+	sort.Strings(entry.Removed)
+	sort.Strings(entry.Regenerated)
+	f.Entries = append([]ChangelogEntry{entry}, f.Entries...)
+	return &f.Entries[0]
+}
+
+// Save writes the JSON feed and regenerates TESTS_CHANGELOG.md
+func (f *ChangelogFeed) Save(repoPath string) error {
+	feedPath := filepath.Join(repoPath, ChangelogFeedFile)
+	if err := os.MkdirAll(filepath.Dir(feedPath), 0755); err != nil {
+		return fmt.Errorf("failed to create changelog directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal changelog feed: %w", err)
+	}
+	if err := os.WriteFile(feedPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write changelog feed: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(repoPath, ChangelogFile), []byte(f.Markdown()), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", ChangelogFile, err)
+	}
+	return nil
+}
+
+// Markdown renders the changelog, newest run first
+func (f *ChangelogFeed) Markdown() string {
+	var sb strings.Builder
+	sb.WriteString("# Tests Changelog\n\n")
+	sb.WriteString("Generated by QTest after each test generation run. Newest runs first.\n")
+
+	for _, e := range f.Entries {
+		sb.WriteString(fmt.Sprintf("\n## %s", e.RunAt.UTC().Format("2006-01-02 15:04 UTC")))
+		if e.CommitSHA != "" {
+			sha := e.CommitSHA
+			if len(sha) > 7 {
+				sha = sha[:7]
+			}
+			sb.WriteString(fmt.Sprintf(" (%s)", sha))
+		}
+		sb.WriteString("\n\n")
+
+		sb.WriteString(fmt.Sprintf("%d added, %d regenerated, %d removed\n",
+			len(e.Added), len(e.Regenerated), len(e.Removed)))
+		if e.Coverage != nil {
+			sb.WriteString(fmt.Sprintf("\n- Coverage: %s\n", e.Coverage))
+		}
+		if e.MutationScore != nil {
+			if e.Coverage == nil {
+				sb.WriteString("\n")
+			}
+			sb.WriteString(fmt.Sprintf("- Mutation score: %s\n", e.MutationScore))
+		}
+
+		writeFileList(&sb, "Added", e.Added)
+		writeFileList(&sb, "Regenerated", e.Regenerated)
+		writeFileList(&sb, "Removed", e.Removed)
+	}
+
+	return sb.String()
+}
+
+// String renders "61.2% → 68.4% (+7.2)", or just the value on the first run
+func (d *MetricDelta) String() string {
+	if d.Before == nil {
+		return fmt.Sprintf("%.1f%%", d.After)
+	}
+	return fmt.Sprintf("%.1f%% → %.1f%% (%+.1f)", *d.Before, d.After, d.After-*d.Before)
+}
+
+// RecordChangelog updates the repository's changelog for a finished run.
+// Coverage and mutation scores are read from the workspace artifacts when
+// present.
+func RecordChangelog(ws *Workspace, written []string) (*ChangelogEntry, error) {
+	feed, err := LoadChangelogFeed(ws.RepoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var coverage, mutationScore *float64
+	artifacts := NewArtifactManager(ws)
+	var cov CoverageReport
+	if err := artifacts.LoadArtifact("coverage.json", &cov); err == nil {
+		coverage = &cov.Summary.CoveragePercent
+	}
+	var mut MutationReport
+	if err := artifacts.LoadArtifact("mutation.json", &mut); err == nil {
+		mutationScore = &mut.Summary.MutationScore
+	}
+
+	entry := feed.Record(ws.RepoPath, written, coverage, mutationScore, ChangelogEntry{
+		RunAt:       time.Now(),
+		WorkspaceID: ws.ID,
+		CommitSHA:   ws.CommitSHA,
+	})
+	if entry == nil {
+		return nil, nil
+	}
+
+	if err := feed.Save(ws.RepoPath); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+func writeFileList(sb *strings.Builder, title string, files []string) {
+	if len(files) == 0 {
+		return
+	}
+	sb.WriteString(fmt.Sprintf("\n### %s\n\n", title))
+	for _, f := range files {
+		sb.WriteString(fmt.Sprintf("- `%s`\n", f))
+	}
+}
+
+// metricDelta returns nil when the metric is unknown or unchanged
+func metricDelta(before, after *float64) *MetricDelta {
+	if after == nil || (before != nil && *before == *after) {
+		return nil
+	}
+	d := &MetricDelta{After: *after}
+	if before != nil {
+		b := *before
+		d.Before = &b
+	}
+	return d
+}
+
+func relToRepo(repoPath, path string) string {
+	if filepath.IsAbs(path) {
+		if rel, err := filepath.Rel(repoPath, path); err == nil {
+			path = rel
+		}
+	}
+	return filepath.ToSlash(path)
+}
+
+func hashFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8]), nil
+}
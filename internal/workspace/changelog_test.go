@@ -0,0 +1,102 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeTestFile(t *testing.T, dir, rel, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, rel)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestChangelogFeed_Record(t *testing.T) {
+	repo := t.TempDir()
+	feed, err := LoadChangelogFeed(repo)
+	if err != nil {
+		t.Fatalf("LoadChangelogFeed() error = %v", err)
+	}
+
+	a := writeTestFile(t, repo, "pkg/a_test.go", "v1")
+	b := writeTestFile(t, repo, "pkg/b_test.go", "v1")
+	cov := 40.0
+
+	first := feed.Record(repo, []string{a, b}, &cov, nil, ChangelogEntry{RunAt: time.Now()})
+	if first == nil || len(first.Added) != 2 {
+		t.Fatalf("first run = %+v, want 2 added", first)
+	}
+	if first.Coverage == nil || first.Coverage.Before != nil || first.Coverage.After != 40 {
+		t.Errorf("first coverage = %+v, want after-only 40", first.Coverage)
+	}
+
+	// Second run: regenerate a, delete b, add c, coverage up
+	writeTestFile(t, repo, "pkg/a_test.go", "v2")
+	os.Remove(b)
+	c := writeTestFile(t, repo, "pkg/c_test.go", "v1")
+	cov2 := 55.5
+
+	second := feed.Record(repo, []string{a, c}, &cov2, nil, ChangelogEntry{RunAt: time.Now()})
+	if second == nil {
+		t.Fatal("second run should produce an entry")
+	}
+	if strings.Join(second.Added, ",") != "pkg/c_test.go" ||
+		strings.Join(second.Regenerated, ",") != "pkg/a_test.go" ||
+		strings.Join(second.Removed, ",") != "pkg/b_test.go" {
+		t.Errorf("second run = %+v", second)
+	}
+	if got := second.Coverage.String(); got != "40.0% → 55.5% (+15.5)" {
+		t.Errorf("coverage delta = %q", got)
+	}
+	if len(feed.Entries) != 2 || feed.Entries[0].Added[0] != "pkg/c_test.go" {
+		t.Error("entries should be newest first")
+	}
+
+	// Nothing changed
+	if entry := feed.Record(repo, []string{a}, &cov2, nil, ChangelogEntry{RunAt: time.Now()}); entry != nil {
+		t.Errorf("unchanged run produced entry %+v", entry)
+	}
+}
+
+func TestRecordChangelog(t *testing.T) {
+	repo := t.TempDir()
+	ws := &Workspace{ID: "ws1", RepoPath: repo, CommitSHA: "abcdef1234567", path: t.TempDir()}
+
+	am := NewArtifactManager(ws)
+	if _, err := am.GenerateMutationReport(MutationSummary{MutationScore: 72.5}, nil, nil, time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	path := writeTestFile(t, repo, "api.test.js", "test('x')")
+	entry, err := RecordChangelog(ws, []string{path})
+	if err != nil {
+		t.Fatalf("RecordChangelog() error = %v", err)
+	}
+	if entry == nil || entry.MutationScore == nil || entry.MutationScore.After != 72.5 {
+		t.Fatalf("entry = %+v, want mutation score 72.5", entry)
+	}
+
+	md, err := os.ReadFile(filepath.Join(repo, ChangelogFile))
+	if err != nil {
+		t.Fatalf("changelog not written: %v", err)
+	}
+	for _, want := range []string{"# Tests Changelog", "(abcdef1)", "1 added, 0 regenerated, 0 removed", "- Mutation score: 72.5%", "- `api.test.js`"} {
+		if !strings.Contains(string(md), want) {
+			t.Errorf("changelog missing %q:\n%s", want, md)
+		}
+	}
+
+	feed, err := LoadChangelogFeed(repo)
+	if err != nil || len(feed.Entries) != 1 || feed.Files["api.test.js"] == "" {
+		t.Errorf("feed not persisted: %+v, %v", feed, err)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"strings"
 
 	"github.com/QTest-hq/qtest/internal/codecov"
+	"github.com/QTest-hq/qtest/internal/platform"
 	"github.com/rs/zerolog/log"
 )
 
@@ -46,18 +47,42 @@ func (c *CoverageCollector) CollectAll(ctx context.Context) (*CoverageReport, er
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate coverage report: %w", err)
 	}
+	if _, err := c.artifacts.GenerateSourceView(report); err != nil {
+		log.Warn().Err(err).Msg("failed to generate source view")
+	}
 
 	return report, nil
 }
 
+// CoverageReportFromCodecov converts a collected coverage report to the
+// workspace format, with paths relative to repoPath
+func CoverageReportFromCodecov(report *codecov.CoverageReport, repoPath string) *CoverageReport {
+	return &CoverageReport{
+		Version:     "1.0",
+		GeneratedAt: report.Timestamp,
+		Tool:        detectCoverageTool(report.Language),
+		Summary: CoverageSummary{
+			TotalLines:      report.TotalLines,
+			CoveredLines:    report.CoveredLines,
+			CoveragePercent: report.Percentage,
+			ByPackage:       make(map[string]float64),
+		},
+		Files: convertCodecovFiles(report.Files, repoPath),
+	}
+}
+
 // convertCodecovFiles converts codecov.FileCoverage to workspace.FileCoverage
 func convertCodecovFiles(codecovFiles []codecov.FileCoverage, repoPath string) []FileCoverage {
 	files := make([]FileCoverage, 0, len(codecovFiles))
+	modulePath := goModulePath(repoPath)
 	for _, cf := range codecovFiles {
-		// Make path relative to repo if needed
+		// Make path relative to repo if needed; Go coverage profiles name
+		// files by import path
 		relPath := cf.Path
 		if strings.HasPrefix(cf.Path, repoPath) {
 			relPath = cf.Path[len(repoPath)+1:]
+		} else if modulePath != "" && strings.HasPrefix(cf.Path, modulePath+"/") {
+			relPath = strings.TrimPrefix(cf.Path, modulePath+"/")
 		}
 
 		files = append(files, FileCoverage{
@@ -66,6 +91,7 @@ func convertCodecovFiles(codecovFiles []codecov.FileCoverage, repoPath string) [
 			CoveredLines:    cf.CoveredLines,
 			CoveragePercent: cf.Percentage,
 			UncoveredLines:  cf.UncoveredLines,
+			HitLines:        cf.HitLines,
 		})
 	}
 	return files
@@ -91,7 +117,7 @@ func (c *CoverageCollector) CollectForFile(ctx context.Context, testFile string)
 func (c *CoverageCollector) collectGoFileCoverage(ctx context.Context, testFile string) (*FileCoverage, error) {
 	dir := filepath.Dir(testFile)
 	relDir, _ := filepath.Rel(c.ws.RepoPath, dir)
-	pkg := "./" + relDir
+	pkg := "./" + filepath.ToSlash(relDir)
 
 	coverFile := filepath.Join(c.ws.Path(), "artifacts", "coverage_single.out")
 
@@ -148,7 +174,7 @@ func (c *CoverageCollector) collectPythonFileCoverage(ctx context.Context, testF
 func (c *CoverageCollector) collectJestFileCoverage(ctx context.Context, testFile string) (*FileCoverage, error) {
 	relPath, _ := filepath.Rel(c.ws.RepoPath, testFile)
 
-	cmd := exec.CommandContext(ctx, "npx", "jest", "--coverage", relPath)
+	cmd := platform.Command(ctx, "npx", "jest", "--coverage", filepath.ToSlash(relPath))
 	cmd.Dir = c.ws.RepoPath
 
 	var stdout bytes.Buffer
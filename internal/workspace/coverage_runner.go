@@ -275,6 +275,7 @@ func (r *CoverageRunner) emitTests(specs []model.TestSpec) (string, error) {
 	if err != nil {
 		return "", err
 	}
+	useProjectAssertions(em, r.ws.RepoPath)
 
 	// Generate code
 	code, err := em.Emit(specs)
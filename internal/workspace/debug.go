@@ -0,0 +1,78 @@
+package workspace
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/QTest-hq/qtest/internal/llm"
+)
+
+// PromptDebugDir is the artifacts subdirectory prompt debugging records
+// are written to, one file per target
+const PromptDebugDir = "prompts"
+
+// PromptDebug records one target's generation for debugging: the exact
+// prompt sent, the raw response, what was parsed from it, and what went
+// wrong
+type PromptDebug struct {
+	Target    string        `json:"target"`
+	Name      string        `json:"name,omitempty"`
+	CreatedAt time.Time     `json:"created_at"`
+	Tier      int           `json:"tier"`
+	Provider  string        `json:"provider,omitempty"`
+	Model     string        `json:"model,omitempty"`
+	System    string        `json:"system"`
+	Messages  []llm.Message `json:"messages"`
+	Response  string        `json:"response,omitempty"`
+	Parsed    interface{}   `json:"parsed,omitempty"`
+	Errors    []string      `json:"errors,omitempty"`
+}
+
+// NewPromptDebug starts a record of the request sent for a target
+func NewPromptDebug(target, name string, req *llm.Request) *PromptDebug {
+	return &PromptDebug{
+		Target:    target,
+		Name:      name,
+		CreatedAt: time.Now(),
+		Tier:      int(req.Tier),
+		System:    req.System,
+		Messages:  req.Messages,
+	}
+}
+
+// SetResponse records the raw response
+func (d *PromptDebug) SetResponse(resp *llm.Response) {
+	if resp == nil {
+		return
+	}
+	d.Provider = string(resp.Provider)
+	d.Model = resp.Model
+	d.Response = resp.Content
+}
+
+// AddError records a generation, parsing or validation error
+func (d *PromptDebug) AddError(err error) {
+	if err != nil {
+		d.Errors = append(d.Errors, err.Error())
+	}
+}
+
+// unsafeFileChars matches characters kept out of artifact file names
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// SavePromptDebug writes a target's debugging record to
+// artifacts/prompts/<target>.json, replacing the one from an earlier run
+func (a *ArtifactManager) SavePromptDebug(d *PromptDebug) (string, error) {
+	if err := os.MkdirAll(filepath.Join(a.artifactDir, PromptDebugDir), 0755); err != nil {
+		return "", fmt.Errorf("failed to create prompt debug directory: %w", err)
+	}
+
+	name := filepath.Join(PromptDebugDir, unsafeFileChars.ReplaceAllString(d.Target, "_")+".json")
+	if err := a.saveArtifact(name, d); err != nil {
+		return "", err
+	}
+	return filepath.Join(a.artifactDir, name), nil
+}
@@ -0,0 +1,59 @@
+package workspace
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/QTest-hq/qtest/internal/llm"
+)
+
+func TestArtifactManager_SavePromptDebug(t *testing.T) {
+	am := NewArtifactManager(&Workspace{path: t.TempDir()})
+
+	req := &llm.Request{
+		Tier:     llm.Tier2,
+		System:   "You are a test generator",
+		Messages: []llm.Message{{Role: "user", Content: "Generate tests for Add"}},
+	}
+	d := NewPromptDebug("calc.go:Add", "Add", req)
+	d.SetResponse(&llm.Response{Content: "tests: [", Model: "qwen", Provider: llm.ProviderOllama})
+	d.AddError(errors.New("failed to parse DSL"))
+	d.AddError(nil)
+
+	path, err := am.SavePromptDebug(d)
+	if err != nil {
+		t.Fatalf("SavePromptDebug() error = %v", err)
+	}
+	if want := filepath.Join(am.artifactDir, PromptDebugDir, "calc.go_Add.json"); path != want {
+		t.Errorf("path = %s, want %s", path, want)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	var saved PromptDebug
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if saved.System != req.System || len(saved.Messages) != 1 || saved.Messages[0].Content != "Generate tests for Add" {
+		t.Errorf("prompt = %q %+v, want the request's", saved.System, saved.Messages)
+	}
+	if saved.Tier != 2 || saved.Model != "qwen" || saved.Response != "tests: [" {
+		t.Errorf("saved = %+v, want tier, model and raw response", saved)
+	}
+	if len(saved.Errors) != 1 || saved.Errors[0] != "failed to parse DSL" {
+		t.Errorf("Errors = %v, want the parse error only", saved.Errors)
+	}
+}
+
+func TestPromptDebug_SetResponseNil(t *testing.T) {
+	d := NewPromptDebug("t", "", &llm.Request{})
+	d.SetResponse(nil)
+	if d.Response != "" || d.Model != "" {
+		t.Errorf("SetResponse(nil) recorded %+v", d)
+	}
+}
@@ -0,0 +1,284 @@
+package workspace
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Formats execution and coverage reports export to, for CI systems
+const (
+	FormatJUnit     = "junit"
+	FormatCobertura = "cobertura"
+	FormatLCOV      = "lcov"
+)
+
+// JUnit XML, as Jenkins, GitLab, Azure Pipelines and GitHub Actions test
+// reporters read it
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr,omitempty"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	File      string        `xml:"file,attr,omitempty"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *struct{}     `xml:"skipped,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// ExportJUnit renders an execution report as JUnit XML, with a test suite
+// for each test file
+func ExportJUnit(report *ExecutionReport) ([]byte, error) {
+	suites := junitTestSuites{
+		Tests:    report.Summary.Total,
+		Failures: report.Summary.Failed,
+		Skipped:  report.Summary.Skipped,
+	}
+
+	byFile := make(map[string]*junitTestSuite)
+	durations := make(map[string]time.Duration)
+	var files []string
+	for _, t := range report.Tests {
+		suite, ok := byFile[t.File]
+		if !ok {
+			name := t.File
+			if name == "" {
+				name = "qtest"
+			}
+			suite = &junitTestSuite{Name: name}
+			if !report.ExecutedAt.IsZero() {
+				suite.Timestamp = report.ExecutedAt.UTC().Format("2006-01-02T15:04:05")
+			}
+			byFile[t.File] = suite
+			files = append(files, t.File)
+		}
+
+		duration := time.Duration(t.DurationMs) * time.Millisecond
+		durations[t.File] += duration
+		tc := junitTestCase{
+			Name:      t.Name,
+			Classname: suite.Name,
+			File:      t.File,
+			Time:      formatSeconds(duration),
+		}
+		switch t.Status {
+		case "failed":
+			tc.Failure = &junitFailure{Message: firstLine(t.Error), Text: strings.TrimSpace(t.Error + "\n" + t.StackTrace)}
+			suite.Failures++
+		case "skipped":
+			tc.Skipped = &struct{}{}
+			suite.Skipped++
+		}
+		suite.Tests++
+		suite.Cases = append(suite.Cases, tc)
+	}
+
+	// The run's duration is in whole seconds, so it's at least the tests'
+	total := time.Duration(report.DurationSeconds) * time.Second
+	var sum time.Duration
+	sort.Strings(files)
+	for _, file := range files {
+		suite := byFile[file]
+		suite.Time = formatSeconds(durations[file])
+		suites.Suites = append(suites.Suites, *suite)
+		sum += durations[file]
+	}
+	suites.Time = formatSeconds(max(total, sum))
+
+	return marshalXML(suites, "")
+}
+
+// Cobertura XML, as GitLab, Azure Pipelines and Jenkins coverage
+// reporters read it
+type coberturaCoverage struct {
+	XMLName      xml.Name           `xml:"coverage"`
+	LineRate     string             `xml:"line-rate,attr"`
+	BranchRate   string             `xml:"branch-rate,attr"`
+	LinesCovered int                `xml:"lines-covered,attr"`
+	LinesValid   int                `xml:"lines-valid,attr"`
+	Version      string             `xml:"version,attr"`
+	Timestamp    int64              `xml:"timestamp,attr"`
+	Sources      []string           `xml:"sources>source"`
+	Packages     []coberturaPackage `xml:"packages>package"`
+}
+
+type coberturaPackage struct {
+	Name       string           `xml:"name,attr"`
+	LineRate   string           `xml:"line-rate,attr"`
+	BranchRate string           `xml:"branch-rate,attr"`
+	Classes    []coberturaClass `xml:"classes>class"`
+}
+
+type coberturaClass struct {
+	Name       string          `xml:"name,attr"`
+	Filename   string          `xml:"filename,attr"`
+	LineRate   string          `xml:"line-rate,attr"`
+	BranchRate string          `xml:"branch-rate,attr"`
+	Lines      []coberturaLine `xml:"lines>line"`
+}
+
+type coberturaLine struct {
+	Number int `xml:"number,attr"`
+	Hits   int `xml:"hits,attr"`
+}
+
+// ExportCobertura renders a coverage report as Cobertura XML, with a
+// package for each directory. Reports only record which lines aren't
+// covered, so those are the lines listed; the rates count every line.
+func ExportCobertura(report *CoverageReport) ([]byte, error) {
+	coverage := coberturaCoverage{
+		LineRate:     lineRate(report.Summary.CoveredLines, report.Summary.TotalLines),
+		BranchRate:   "0",
+		LinesCovered: report.Summary.CoveredLines,
+		LinesValid:   report.Summary.TotalLines,
+		Version:      report.Tool,
+		Timestamp:    report.GeneratedAt.Unix(),
+		Sources:      []string{"."},
+	}
+
+	byDir := make(map[string]*coberturaPackage)
+	var dirs []string
+	covered := make(map[string]int)
+	total := make(map[string]int)
+	for _, f := range sortedFiles(report.Files) {
+		dir := path.Dir(toSlash(f.Path))
+		pkg, ok := byDir[dir]
+		if !ok {
+			pkg = &coberturaPackage{Name: strings.ReplaceAll(dir, "/", "."), BranchRate: "0"}
+			byDir[dir] = pkg
+			dirs = append(dirs, dir)
+		}
+		covered[dir] += f.CoveredLines
+		total[dir] += f.TotalLines
+
+		class := coberturaClass{
+			Name:       strings.TrimSuffix(path.Base(toSlash(f.Path)), path.Ext(f.Path)),
+			Filename:   toSlash(f.Path),
+			LineRate:   lineRate(f.CoveredLines, f.TotalLines),
+			BranchRate: "0",
+		}
+		for _, line := range sortedLines(f.UncoveredLines) {
+			class.Lines = append(class.Lines, coberturaLine{Number: line})
+		}
+		pkg.Classes = append(pkg.Classes, class)
+	}
+
+	for _, dir := range dirs {
+		pkg := byDir[dir]
+		pkg.LineRate = lineRate(covered[dir], total[dir])
+		coverage.Packages = append(coverage.Packages, *pkg)
+	}
+
+	return marshalXML(coverage, `<!DOCTYPE coverage SYSTEM "http://cobertura.sourceforge.net/xml/coverage-04.dtd">`+"\n")
+}
+
+// ExportLCOV renders a coverage report as an LCOV tracefile. Like the
+// Cobertura export, it lists the uncovered lines and counts every line.
+func ExportLCOV(report *CoverageReport) []byte {
+	var b bytes.Buffer
+	for _, f := range sortedFiles(report.Files) {
+		b.WriteString("TN:\n")
+		b.WriteString("SF:" + toSlash(f.Path) + "\n")
+		for _, line := range sortedLines(f.UncoveredLines) {
+			b.WriteString(fmt.Sprintf("DA:%d,0\n", line))
+		}
+		b.WriteString(fmt.Sprintf("LF:%d\n", f.TotalLines))
+		b.WriteString(fmt.Sprintf("LH:%d\n", f.CoveredLines))
+		b.WriteString("end_of_record\n")
+	}
+	return b.Bytes()
+}
+
+// ExportCoverage renders a coverage report in a CI format: cobertura or lcov
+func ExportCoverage(report *CoverageReport, format string) ([]byte, error) {
+	switch format {
+	case FormatCobertura:
+		return ExportCobertura(report)
+	case FormatLCOV:
+		return ExportLCOV(report), nil
+	default:
+		return nil, fmt.Errorf("unknown coverage format %q (use cobertura or lcov)", format)
+	}
+}
+
+// marshalXML renders v as an indented XML document
+func marshalXML(v interface{}, doctype string) ([]byte, error) {
+	data, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal XML: %w", err)
+	}
+	return []byte(xml.Header + doctype + string(data) + "\n"), nil
+}
+
+// formatSeconds formats a duration as seconds with millisecond precision
+func formatSeconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', 3, 64)
+}
+
+// lineRate is the fraction of lines covered, 1 when there are none
+func lineRate(covered, total int) string {
+	if total == 0 {
+		return "1"
+	}
+	return strconv.FormatFloat(float64(covered)/float64(total), 'f', 4, 64)
+}
+
+// sortedFiles returns files sorted by path, so exports are stable
+func sortedFiles(files []FileCoverage) []FileCoverage {
+	sorted := make([]FileCoverage, len(files))
+	copy(sorted, files)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Path < sorted[j].Path })
+	return sorted
+}
+
+// sortedLines returns line numbers sorted and without repeats
+func sortedLines(lines []int) []int {
+	sorted := make([]int, len(lines))
+	copy(sorted, lines)
+	sort.Ints(sorted)
+	unique := sorted[:0]
+	for i, l := range sorted {
+		if i == 0 || l != sorted[i-1] {
+			unique = append(unique, l)
+		}
+	}
+	return unique
+}
+
+// toSlash uses forward slashes in a path, as CI tools expect
+func toSlash(p string) string {
+	return strings.ReplaceAll(p, `\`, "/")
+}
+
+// firstLine returns the first line of s
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
@@ -0,0 +1,168 @@
+package workspace
+
+import (
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/QTest-hq/qtest/internal/codecov"
+)
+
+func TestExportJUnit(t *testing.T) {
+	report := &ExecutionReport{
+		ExecutedAt:      time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		DurationSeconds: 2,
+		Summary:         ExecutionSummary{Total: 3, Passed: 1, Failed: 1, Skipped: 1},
+		Tests: []TestResult{
+			{Name: "TestAdd", File: "calc_test.go", Status: "passed", DurationMs: 1500},
+			{Name: "TestDiv", File: "calc_test.go", Status: "failed", DurationMs: 250, Error: "expected 2, got 3\nmore", StackTrace: "calc_test.go:12"},
+			{Name: "test_login", File: "tests/test_auth.py", Status: "skipped"},
+		},
+	}
+
+	data, err := ExportJUnit(report)
+	if err != nil {
+		t.Fatalf("ExportJUnit() error = %v", err)
+	}
+	var got junitTestSuites
+	if err := xml.Unmarshal(data, &got); err != nil {
+		t.Fatalf("ExportJUnit() isn't valid XML: %v\n%s", err, data)
+	}
+
+	if got.Tests != 3 || got.Failures != 1 || got.Skipped != 1 || got.Time != "2.000" {
+		t.Errorf("testsuites = %+v, want 3 tests, 1 failure, 1 skipped in 2.000s", got)
+	}
+	if len(got.Suites) != 2 || got.Suites[0].Name != "calc_test.go" || got.Suites[1].Name != "tests/test_auth.py" {
+		t.Fatalf("expected a suite per test file, got %+v", got.Suites)
+	}
+	calc := got.Suites[0]
+	if calc.Tests != 2 || calc.Failures != 1 || calc.Time != "1.750" || calc.Timestamp != "2026-01-02T03:04:05" {
+		t.Errorf("calc suite = %+v", calc)
+	}
+	failure := calc.Cases[1].Failure
+	if failure == nil || failure.Message != "expected 2, got 3" || !strings.Contains(failure.Text, "calc_test.go:12") {
+		t.Errorf("failure = %+v, want the error's first line and the stack trace", failure)
+	}
+	if calc.Cases[0].Failure != nil || got.Suites[1].Cases[0].Skipped == nil {
+		t.Errorf("passing and skipped cases marked wrong: %+v", got.Suites)
+	}
+}
+
+func coverageFixture() *CoverageReport {
+	return &CoverageReport{
+		GeneratedAt: time.Unix(1700000000, 0),
+		Tool:        "go cover",
+		Summary:     CoverageSummary{TotalLines: 20, CoveredLines: 15},
+		Files: []FileCoverage{
+			{Path: "pricing/tax.go", TotalLines: 10, CoveredLines: 10},
+			{Path: "pricing/discount.go", TotalLines: 8, CoveredLines: 4, UncoveredLines: []int{9, 7, 7, 8}},
+			{Path: "main.go", TotalLines: 2, CoveredLines: 1, UncoveredLines: []int{3}},
+		},
+	}
+}
+
+func TestExportCobertura(t *testing.T) {
+	data, err := ExportCobertura(coverageFixture())
+	if err != nil {
+		t.Fatalf("ExportCobertura() error = %v", err)
+	}
+	if !strings.Contains(string(data), "<!DOCTYPE coverage") {
+		t.Errorf("ExportCobertura() missing the DTD:\n%s", data)
+	}
+	var got coberturaCoverage
+	if err := xml.Unmarshal(data, &got); err != nil {
+		t.Fatalf("ExportCobertura() isn't valid XML: %v\n%s", err, data)
+	}
+
+	if got.LineRate != "0.7500" || got.LinesValid != 20 || got.LinesCovered != 15 || got.Timestamp != 1700000000 {
+		t.Errorf("coverage = %+v", got)
+	}
+	if len(got.Packages) != 2 || got.Packages[0].Name != "." || got.Packages[1].Name != "pricing" {
+		t.Fatalf("expected a package per directory, got %+v", got.Packages)
+	}
+	pricing := got.Packages[1]
+	if pricing.LineRate != "0.7778" || len(pricing.Classes) != 2 {
+		t.Errorf("pricing package = %+v", pricing)
+	}
+	discount := pricing.Classes[0]
+	if discount.Name != "discount" || discount.Filename != "pricing/discount.go" || discount.LineRate != "0.5000" {
+		t.Errorf("discount class = %+v", discount)
+	}
+	if len(discount.Lines) != 3 || discount.Lines[0].Number != 7 || discount.Lines[2].Number != 9 || discount.Lines[0].Hits != 0 {
+		t.Errorf("expected uncovered lines 7-9 with no hits, got %+v", discount.Lines)
+	}
+}
+
+func TestExportLCOV(t *testing.T) {
+	got := string(ExportLCOV(coverageFixture()))
+	want := `TN:
+SF:main.go
+DA:3,0
+LF:2
+LH:1
+end_of_record
+TN:
+SF:pricing/discount.go
+DA:7,0
+DA:8,0
+DA:9,0
+LF:8
+LH:4
+end_of_record
+TN:
+SF:pricing/tax.go
+LF:10
+LH:10
+end_of_record
+`
+	if got != want {
+		t.Errorf("ExportLCOV() =\n%s\nwant\n%s", got, want)
+	}
+
+	if _, err := ExportCoverage(coverageFixture(), "junit"); err == nil {
+		t.Error("ExportCoverage() should reject a format that isn't for coverage")
+	}
+}
+
+func TestCoverageReportFromCodecov(t *testing.T) {
+	repo := t.TempDir()
+	os.WriteFile(filepath.Join(repo, "go.mod"), []byte("module example.com/shop\n"), 0644)
+
+	report := CoverageReportFromCodecov(&codecov.CoverageReport{
+		Timestamp:    time.Unix(1700000000, 0),
+		Language:     "go",
+		TotalLines:   8,
+		CoveredLines: 4,
+		Percentage:   50,
+		Files: []codecov.FileCoverage{
+			{Path: "example.com/shop/pricing/discount.go", TotalLines: 8, CoveredLines: 4, UncoveredLines: []int{7}},
+		},
+	}, repo)
+
+	if report.Tool != "go cover" || report.Summary.CoveredLines != 4 || report.Summary.CoveragePercent != 50 {
+		t.Errorf("report = %+v", report)
+	}
+	if len(report.Files) != 1 || report.Files[0].Path != "pricing/discount.go" {
+		t.Errorf("expected the import path made relative to the module, got %+v", report.Files)
+	}
+}
+
+func TestArtifactManager_SavesCIFormats(t *testing.T) {
+	am := NewArtifactManager(&Workspace{path: t.TempDir(), Language: "go"})
+	if _, err := am.GenerateExecutionReport([]TestResult{{Name: "TestAdd", Status: "passed"}}, time.Second); err != nil {
+		t.Fatalf("GenerateExecutionReport() error = %v", err)
+	}
+	if _, err := am.GenerateCoverageReport(coverageFixture().Files); err != nil {
+		t.Fatalf("GenerateCoverageReport() error = %v", err)
+	}
+
+	artifacts := strings.Join(am.ListArtifacts(), " ")
+	for _, want := range []string{"junit.xml", "cobertura.xml", "lcov.info"} {
+		if !strings.Contains(artifacts, want) {
+			t.Errorf("artifacts %q missing %s", artifacts, want)
+		}
+	}
+}
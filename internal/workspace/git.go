@@ -204,6 +204,23 @@ func (g *GitManager) CommitAll(message string) (string, error) {
 	return commit.String(), nil
 }
 
+// HeadSHA returns the commit currently checked out
+func (g *GitManager) HeadSHA() (string, error) {
+	if g.repo == nil {
+		var err error
+		g.repo, err = git.PlainOpen(g.ws.RepoPath)
+		if err != nil {
+			return "", fmt.Errorf("failed to open repo: %w", err)
+		}
+	}
+
+	head, err := g.repo.Head()
+	if err != nil {
+		return "", fmt.Errorf("failed to get HEAD: %w", err)
+	}
+	return head.Hash().String(), nil
+}
+
 // Push pushes commits to remote
 func (g *GitManager) Push(ctx context.Context) error {
 	if g.repo == nil {
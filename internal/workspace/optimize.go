@@ -0,0 +1,222 @@
+package workspace
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Reasons a test file is dropped from an optimized suite
+const (
+	DropRedundant     = "redundant"      // kept tests cover all of its lines
+	DropOverBudget    = "over budget"    // covers lines, but doesn't fit the budget
+	DropTargetReached = "target reached" // covers lines the coverage target doesn't need
+)
+
+// OptimizeOptions bounds a suite optimization
+type OptimizeOptions struct {
+	Budget         time.Duration // total runtime of the kept tests; 0 is unbounded
+	CoverageTarget float64       // percent of the suite's lines to keep; 0 is 100
+}
+
+// SuiteOptimization is a subset of the generated tests chosen to keep
+// their coverage within a runtime budget, and what was dropped
+type SuiteOptimization struct {
+	Version          string          `json:"version"`
+	GeneratedAt      time.Time       `json:"generated_at"`
+	BudgetMs         int64           `json:"budget_ms,omitempty"`
+	CoverageTarget   float64         `json:"coverage_target"`
+	Lines            int             `json:"lines"`             // lines the whole suite covers
+	KeptLines        int             `json:"kept_lines"`        // lines the kept tests cover
+	CoverageRetained float64         `json:"coverage_retained"` // percent of Lines
+	RuntimeMs        int64           `json:"runtime_ms"`
+	KeptRuntimeMs    int64           `json:"kept_runtime_ms"`
+	Kept             []OptimizedTest `json:"kept"`
+	Dropped          []OptimizedTest `json:"dropped"`
+}
+
+// OptimizedTest is a generated test file with its cost and coverage
+type OptimizedTest struct {
+	File       string `json:"file"`
+	DurationMs int64  `json:"duration_ms"`
+	Estimated  bool   `json:"estimated,omitempty"` // no timing; the median was used
+	Lines      int    `json:"lines"`               // lines it covers
+	Unique     int    `json:"unique"`              // lines no other kept test covers
+	Reason     string `json:"reason,omitempty"`    // why it was dropped
+}
+
+// OptimizeSuite picks generated test files covering the lines attributed
+// to them in a source view, greedily by new lines per millisecond of runtime,
+// until the coverage target is met or nothing else fits the budget.
+// Runtimes come from the execution report; files without one are charged
+// the median.
+func OptimizeSuite(view *SourceView, exec *ExecutionReport, opts OptimizeOptions) (*SuiteOptimization, error) {
+	if view == nil {
+		return nil, fmt.Errorf("source view is required")
+	}
+	if opts.CoverageTarget <= 0 || opts.CoverageTarget > 100 {
+		opts.CoverageTarget = 100
+	}
+
+	// Lines each test file covers, across every target it tests
+	files := make(map[string]map[string]bool)
+	for _, t := range view.Tests {
+		if files[t.File] == nil {
+			files[t.File] = make(map[string]bool)
+		}
+	}
+	all := make(map[string]bool)
+	for _, f := range view.Files {
+		for _, line := range f.Lines {
+			for _, idx := range line.CoveredBy {
+				if idx < 0 || idx >= len(view.Tests) {
+					continue
+				}
+				key := fmt.Sprintf("%s:%d", f.Path, line.Number)
+				files[view.Tests[idx].File][key] = true
+				all[key] = true
+			}
+		}
+	}
+
+	tests := make([]*OptimizedTest, 0, len(files))
+	for file, lines := range files {
+		tests = append(tests, &OptimizedTest{File: file, Lines: len(lines)})
+	}
+	sort.Slice(tests, func(i, j int) bool { return tests[i].File < tests[j].File })
+	chargeRuntimes(tests, exec)
+
+	result := &SuiteOptimization{
+		Version:        "1.0",
+		GeneratedAt:    time.Now(),
+		BudgetMs:       opts.Budget.Milliseconds(),
+		CoverageTarget: opts.CoverageTarget,
+		Lines:          len(all),
+		Kept:           []OptimizedTest{},
+		Dropped:        []OptimizedTest{},
+	}
+	for _, t := range tests {
+		result.RuntimeMs += t.DurationMs
+	}
+
+	covered := make(map[string]bool)
+	kept := make(map[string]bool)
+	newLines := func(t *OptimizedTest) int {
+		n := 0
+		for key := range files[t.File] {
+			if !covered[key] {
+				n++
+			}
+		}
+		return n
+	}
+	for float64(len(covered)) < opts.CoverageTarget/100*float64(len(all)) {
+		var best *OptimizedTest
+		var bestGain, bestRate float64
+		for _, t := range tests {
+			if kept[t.File] {
+				continue
+			}
+			if opts.Budget > 0 && result.KeptRuntimeMs+t.DurationMs > opts.Budget.Milliseconds() {
+				continue
+			}
+			gain := float64(newLines(t))
+			if gain == 0 {
+				continue
+			}
+			// New lines per millisecond, charging instant tests 1ms
+			rate := gain / float64(max(t.DurationMs, 1))
+			if best == nil || rate > bestRate || (rate == bestRate && gain > bestGain) {
+				best, bestGain, bestRate = t, gain, rate
+			}
+		}
+		if best == nil {
+			break
+		}
+		kept[best.File] = true
+		result.KeptRuntimeMs += best.DurationMs
+		for key := range files[best.File] {
+			covered[key] = true
+		}
+	}
+	result.KeptLines = len(covered)
+	if result.Lines > 0 {
+		result.CoverageRetained = float64(result.KeptLines) / float64(result.Lines) * 100
+	} else {
+		result.CoverageRetained = 100
+	}
+
+	// How many lines each test alone contributes, given the final choice
+	owners := make(map[string]int)
+	for _, t := range tests {
+		if kept[t.File] {
+			for key := range files[t.File] {
+				owners[key]++
+			}
+		}
+	}
+	for _, t := range tests {
+		if kept[t.File] {
+			for key := range files[t.File] {
+				if owners[key] == 1 {
+					t.Unique++
+				}
+			}
+			result.Kept = append(result.Kept, *t)
+			continue
+		}
+		t.Unique = newLines(t)
+		switch {
+		case t.Unique == 0:
+			t.Reason = DropRedundant
+		case opts.Budget > 0 && result.KeptRuntimeMs+t.DurationMs > opts.Budget.Milliseconds():
+			t.Reason = DropOverBudget
+		default:
+			t.Reason = DropTargetReached
+		}
+		result.Dropped = append(result.Dropped, *t)
+	}
+	return result, nil
+}
+
+// chargeRuntimes sums the execution report's test durations into the test
+// files they ran in. Files it doesn't mention are charged the median.
+func chargeRuntimes(tests []*OptimizedTest, exec *ExecutionReport) {
+	var known []int64
+	for _, t := range tests {
+		found := false
+		if exec != nil {
+			for _, r := range exec.Tests {
+				if r.File != "" && sameTestFile(r.File, t.File) {
+					t.DurationMs += int64(r.DurationMs)
+					found = true
+				}
+			}
+		}
+		if found {
+			known = append(known, t.DurationMs)
+		} else {
+			t.Estimated = true
+		}
+	}
+	if len(known) == 0 {
+		return
+	}
+	sort.Slice(known, func(i, j int) bool { return known[i] < known[j] })
+	median := known[len(known)/2]
+	for _, t := range tests {
+		if t.Estimated {
+			t.DurationMs = median
+		}
+	}
+}
+
+// sameTestFile reports whether an execution report's path names a source
+// view's test file, which is relative to the repository. The report's may
+// be absolute.
+func sameTestFile(reported, file string) bool {
+	reported, file = toSlash(filepath.Clean(reported)), toSlash(filepath.Clean(file))
+	return reported == file || strings.HasSuffix(reported, "/"+file)
+}
@@ -0,0 +1,84 @@
+package workspace
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOptimizeSuite(t *testing.T) {
+	// fast_test.go and slow_test.go both cover lines 1-4; edge_test.go adds 5
+	view := &SourceView{
+		Tests: []SourceViewTest{
+			{File: "calc/fast_test.go", Target: "Add"},
+			{File: "calc/slow_test.go", Target: "Add"},
+			{File: "calc/edge_test.go", Target: "Div"},
+			{File: "calc/untimed_test.go", Target: "Div"},
+		},
+		Files: []SourceFileView{{Path: "calc/calc.go", Lines: []SourceLine{
+			{Number: 1, CoveredBy: []int{0, 1}},
+			{Number: 2, CoveredBy: []int{0, 1}},
+			{Number: 3, CoveredBy: []int{0, 1}},
+			{Number: 4, CoveredBy: []int{0, 1}},
+			{Number: 5, CoveredBy: []int{2}},
+			{Number: 6},
+		}}},
+	}
+	exec := &ExecutionReport{Tests: []TestResult{
+		{Name: "TestAdd", File: "/repo/calc/fast_test.go", DurationMs: 100},
+		{Name: "TestAddSlow", File: "calc/slow_test.go", DurationMs: 4000},
+		{Name: "TestDiv", File: "calc/edge_test.go", DurationMs: 1000},
+		{Name: "TestDivZero", File: "calc/edge_test.go", DurationMs: 1000},
+	}}
+
+	result, err := OptimizeSuite(view, exec, OptimizeOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Lines != 5 || result.KeptLines != 5 || result.CoverageRetained != 100 {
+		t.Errorf("lines = %d, kept %d (%.1f%%), want all 5", result.Lines, result.KeptLines, result.CoverageRetained)
+	}
+	if len(result.Kept) != 2 || result.Kept[0].File != "calc/edge_test.go" || result.Kept[1].File != "calc/fast_test.go" {
+		t.Fatalf("kept = %+v, want edge and fast", result.Kept)
+	}
+	if result.KeptRuntimeMs != 2100 || result.Kept[0].DurationMs != 2000 || result.Kept[1].Unique != 4 {
+		t.Errorf("kept = %+v (%dms)", result.Kept, result.KeptRuntimeMs)
+	}
+	reasons := make(map[string]OptimizedTest)
+	for _, d := range result.Dropped {
+		reasons[d.File] = d
+	}
+	if reasons["calc/slow_test.go"].Reason != DropRedundant || reasons["calc/untimed_test.go"].Reason != DropRedundant {
+		t.Errorf("dropped = %+v, want slow and untimed redundant", result.Dropped)
+	}
+	if u := reasons["calc/untimed_test.go"]; !u.Estimated || u.DurationMs != 2000 {
+		t.Errorf("untimed = %+v, want the median charged", u)
+	}
+
+	// A budget too small for edge_test.go drops it and its line
+	result, err = OptimizeSuite(view, exec, OptimizeOptions{Budget: time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Kept) != 1 || result.KeptLines != 4 || result.CoverageRetained != 80 {
+		t.Errorf("budgeted = %+v, want fast only at 80%%", result)
+	}
+	for _, d := range result.Dropped {
+		if d.File == "calc/edge_test.go" && (d.Reason != DropOverBudget || d.Unique != 1) {
+			t.Errorf("edge = %+v, want it over budget", d)
+		}
+	}
+
+	// A lower coverage target stops before the costlier line
+	result, err = OptimizeSuite(view, exec, OptimizeOptions{CoverageTarget: 80})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Kept) != 1 || result.Kept[0].File != "calc/fast_test.go" {
+		t.Errorf("target 80 kept = %+v, want fast only", result.Kept)
+	}
+	for _, d := range result.Dropped {
+		if d.File == "calc/edge_test.go" && d.Reason != DropTargetReached {
+			t.Errorf("edge = %+v, want target reached", d)
+		}
+	}
+}
@@ -0,0 +1,438 @@
+package workspace
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/QTest-hq/qtest/internal/parser"
+)
+
+// DefaultRenameThreshold is the minimum similarity for a new function to be
+// treated as the renamed or moved version of a target with tests
+const DefaultRenameThreshold = 0.75
+
+// Weights of the two similarity components
+const (
+	signatureWeight = 0.3
+	bodyWeight      = 0.7
+)
+
+// Fingerprint captures what identifies a function independently of its name
+// and location: its signature and the tokens of its body
+type Fingerprint struct {
+	Signature string   `json:"signature"`        // "(int, int) int"
+	Params    int      `json:"params"`           // parameter count
+	Tokens    []string `json:"tokens,omitempty"` // sorted unique body tokens, name excluded
+}
+
+// Rename records a target whose tests were re-linked to a moved or renamed
+// function
+type Rename struct {
+	OldID       string  `json:"old_id"`
+	NewID       string  `json:"new_id"`
+	OldName     string  `json:"old_name"`
+	NewName     string  `json:"new_name"`
+	Class       string  `json:"class,omitempty"` // receiver or class of a renamed method
+	OldFile     string  `json:"old_file"`
+	NewFile     string  `json:"new_file"`
+	Score       float64 `json:"score"`
+	TestFile    string  `json:"test_file"`
+	TestUpdated bool    `json:"test_updated,omitempty"` // test file rewritten for the new name or module
+}
+
+var tokenPattern = regexp.MustCompile(`[A-Za-z_][A-Za-z0-9_]*|\d+(?:\.\d+)?|"[^"\n]*"|'[^'\n]*'|[^\sA-Za-z0-9_]`)
+
+func fingerprintFunction(fn parser.Function) *Fingerprint {
+	types := make([]string, len(fn.Parameters))
+	for i, p := range fn.Parameters {
+		types[i] = p.Type
+	}
+	fp := &Fingerprint{
+		Signature: fmt.Sprintf("(%s) %s", strings.Join(types, ", "), fn.ReturnType),
+		Params:    len(fn.Parameters),
+	}
+
+	seen := make(map[string]bool)
+	for _, tok := range tokenPattern.FindAllString(fn.Body, -1) {
+		if tok == fn.Name || seen[tok] {
+			continue
+		}
+		seen[tok] = true
+		fp.Tokens = append(fp.Tokens, tok)
+	}
+	sort.Strings(fp.Tokens)
+	return fp
+}
+
+// Similarity scores two fingerprints from 0 to 1, weighting body tokens
+// (Jaccard index) over the signature
+func (f *Fingerprint) Similarity(other *Fingerprint) float64 {
+	var sig float64
+	switch {
+	case f.Signature == other.Signature:
+		sig = 1
+	case f.Params == other.Params:
+		sig = 0.5
+	}
+
+	var body float64
+	if len(f.Tokens) == 0 && len(other.Tokens) == 0 {
+		body = 1
+	} else {
+		shared := 0
+		for i, j := 0, 0; i < len(f.Tokens) && j < len(other.Tokens); {
+			switch {
+			case f.Tokens[i] == other.Tokens[j]:
+				shared++
+				i++
+				j++
+			case f.Tokens[i] < other.Tokens[j]:
+				i++
+			default:
+				j++
+			}
+		}
+		body = float64(shared) / float64(len(f.Tokens)+len(other.Tokens)-shared)
+	}
+
+	return signatureWeight*sig + bodyWeight*body
+}
+
+// RelinkTargets reconciles targets after a re-parse. seen holds the IDs
+// found by the parse. Unseen targets with tests are matched against new
+// pending targets by fingerprint; a match moves the test state onto the new
+// target. Unseen targets without tests belong to deleted functions and are
+// dropped. Unmatched targets with tests are kept so revalidation can report
+// them as orphaned.
+func (ws *Workspace) RelinkTargets(seen map[string]bool, threshold float64) []Rename {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	var orphans, candidates []*TargetState
+	for id, target := range ws.State.Targets {
+		if seen[id] {
+			if target.Status == StatusPending && target.TestFile == "" && target.Fingerprint != nil {
+				candidates = append(candidates, target)
+			}
+			continue
+		}
+		switch {
+		case target.TestFile == "":
+			delete(ws.State.Targets, id)
+		case target.Fingerprint != nil:
+			orphans = append(orphans, target)
+		}
+	}
+
+	type pair struct {
+		orphan, candidate *TargetState
+		score             float64
+	}
+	var pairs []pair
+	for _, o := range orphans {
+		for _, c := range candidates {
+			if score := o.Fingerprint.Similarity(c.Fingerprint); score >= threshold {
+				pairs = append(pairs, pair{o, c, score})
+			}
+		}
+	}
+	sort.SliceStable(pairs, func(i, j int) bool {
+		if pairs[i].score != pairs[j].score {
+			return pairs[i].score > pairs[j].score
+		}
+		return pairs[i].orphan.ID+pairs[i].candidate.ID < pairs[j].orphan.ID+pairs[j].candidate.ID
+	})
+
+	// Greedy one-to-one matching, best score first. Within a group of equal
+	// scores, a target that could match more than one function is ambiguous
+	// and left alone.
+	used := make(map[*TargetState]bool)
+	var matched []pair
+	for i := 0; i < len(pairs); {
+		j := i
+		for j < len(pairs) && math.Abs(pairs[j].score-pairs[i].score) < 1e-9 {
+			j++
+		}
+		group := pairs[i:j]
+		i = j
+
+		counts := make(map[*TargetState]int)
+		for _, p := range group {
+			if !used[p.orphan] && !used[p.candidate] {
+				counts[p.orphan]++
+				counts[p.candidate]++
+			}
+		}
+		for _, p := range group {
+			if used[p.orphan] || used[p.candidate] {
+				continue
+			}
+			if counts[p.orphan] == 1 && counts[p.candidate] == 1 {
+				matched = append(matched, p)
+			}
+		}
+		for _, p := range group {
+			if counts[p.orphan] > 0 || counts[p.candidate] > 0 {
+				used[p.orphan] = true
+				used[p.candidate] = true
+			}
+		}
+	}
+
+	var renames []Rename
+	for _, p := range matched {
+		old, target := p.orphan, p.candidate
+		target.Status = old.Status
+		target.Covered = old.Covered
+		target.SpecID = old.SpecID
+		target.TestFile = old.TestFile
+		target.CommitSHA = old.CommitSHA
+		target.Error = old.Error
+		target.GeneratedAt = old.GeneratedAt
+		target.DSL = old.DSL
+		delete(ws.State.Targets, old.ID)
+
+		renames = append(renames, Rename{
+			OldID:    old.ID,
+			NewID:    target.ID,
+			OldName:  old.Name,
+			NewName:  target.Name,
+			Class:    old.Class,
+			OldFile:  old.File,
+			NewFile:  target.File,
+			Score:    math.Round(p.score*100) / 100,
+			TestFile: target.TestFile,
+		})
+	}
+
+	ws.State.TotalTargets = len(ws.State.Targets)
+	sort.Slice(renames, func(i, j int) bool { return renames[i].NewID < renames[j].NewID })
+	return renames
+}
+
+// UpdateTestReferences rewrites a re-linked test file so it refers to the
+// function's new name and module. It returns whether the file changed.
+func UpdateTestReferences(repoPath string, rn Rename) (bool, error) {
+	data, err := os.ReadFile(rn.TestFile)
+	if err != nil {
+		return false, fmt.Errorf("failed to read test file: %w", err)
+	}
+	content := string(data)
+	updated := content
+
+	if rn.OldName != rn.NewName {
+		updated = renameReferences(updated, rn)
+	}
+
+	if rn.OldFile != rn.NewFile {
+		lang := parser.DetectLanguage(rn.NewFile)
+		oldRef := moduleRef(repoPath, rn.TestFile, rn.OldFile, lang)
+		newRef := moduleRef(repoPath, rn.TestFile, rn.NewFile, lang)
+		if oldRef != "" && newRef != "" && oldRef != newRef {
+			updated = replaceModuleRef(updated, oldRef, newRef, lang)
+		}
+	}
+
+	if updated == content {
+		return false, nil
+	}
+	if err := os.WriteFile(rn.TestFile, []byte(updated), 0644); err != nil {
+		return false, fmt.Errorf("failed to write test file: %w", err)
+	}
+	return true, nil
+}
+
+// renameReferences renames the target in a test: test functions like
+// TestOld, Test_Old and test_old, imports of the name, unqualified calls and
+// calls qualified by the target's package, module, class or a variable
+// holding an instance of its class. Anything else with the same name, like
+// errors.New or http.Get, belongs to something else and is left alone.
+func renameReferences(content string, rn Rename) string {
+	qualifiers := referenceQualifiers(content, rn)
+	imports := importSpans(content)
+	names := regexp.MustCompile(`\b(Test_?|test_)?` + regexp.QuoteMeta(rn.OldName) + `\b`)
+
+	var b strings.Builder
+	last := 0
+	for _, m := range names.FindAllStringSubmatchIndex(content, -1) {
+		start, end := m[0], m[1]
+		if start > 0 && (isIdentByte(content[start-1]) || content[start-1] == '$') {
+			continue
+		}
+		if end < len(content) && content[end] == '$' {
+			continue
+		}
+		if m[2] < 0 && !isReference(content, start, end, qualifiers, imports) {
+			continue
+		}
+		b.WriteString(content[last:start])
+		if m[2] >= 0 {
+			b.WriteString(content[m[2]:m[3]])
+		}
+		b.WriteString(rn.NewName)
+		last = end
+	}
+	b.WriteString(content[last:])
+	return b.String()
+}
+
+// isReference reports whether the name at content[start:end] refers to the
+// target: it is imported, or called unqualified or through a qualifier
+func isReference(content string, start, end int, qualifiers map[string]bool, imports [][]int) bool {
+	for _, span := range imports {
+		if start >= span[0] && end <= span[1] {
+			return true
+		}
+	}
+
+	i := start - 1
+	for i >= 0 && (content[i] == ' ' || content[i] == '\t') {
+		i--
+	}
+	if i < 0 || content[i] != '.' {
+		return isCall(content, end)
+	}
+	j := i - 1
+	for j >= 0 && (content[j] == ' ' || content[j] == '\t') {
+		j--
+	}
+	k := j
+	for k >= 0 && (isIdentByte(content[k]) || content[k] == '$') {
+		k--
+	}
+	return k < j && qualifiers[content[k+1:j+1]]
+}
+
+// isCall reports whether the name ending at end is called
+func isCall(content string, end int) bool {
+	rest := strings.TrimLeft(content[end:], " \t")
+	return strings.HasPrefix(rest, "(")
+}
+
+// referenceQualifiers returns the names that qualify a reference to the
+// target: its Go package, Python or JS module, class, and variables bound
+// to an instance of the class
+func referenceQualifiers(content string, rn Rename) map[string]bool {
+	qualifiers := make(map[string]bool)
+	for _, file := range []string{rn.OldFile, rn.NewFile} {
+		if file == "" {
+			continue
+		}
+		if parser.DetectLanguage(file) == parser.LanguageGo {
+			qualifiers[filepath.Base(filepath.Dir(file))] = true
+		} else {
+			qualifiers[strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))] = true
+		}
+	}
+
+	// import * as ns from '...' and import pkg as alias
+	for _, m := range namespaceImport.FindAllStringSubmatch(content, -1) {
+		qualifiers[m[1]+m[2]] = true
+	}
+
+	if rn.Class != "" {
+		qualifiers[rn.Class] = true
+		class := regexp.QuoteMeta(rn.Class)
+		bindings := []*regexp.Regexp{
+			regexp.MustCompile(`([A-Za-z_$][\w$]*)\s*(?::=|=)\s*(?:&|new\s+)?(?:[\w$]+\.)?(?:New)?` + class + `\s*[({]`),
+			regexp.MustCompile(`\bvar\s+(\w+)\s+\*?(?:\w+\.)?` + class + `\b`),
+			regexp.MustCompile(`([A-Za-z_$][\w$]*)\s*:\s*\*?(?:[\w$]+\.)?` + class + `\b`),
+		}
+		for _, re := range bindings {
+			for _, m := range re.FindAllStringSubmatch(content, -1) {
+				qualifiers[m[1]] = true
+			}
+		}
+	}
+	return qualifiers
+}
+
+var (
+	namespaceImport = regexp.MustCompile(`import\s+\*\s+as\s+([A-Za-z_$][\w$]*)|(?m)^\s*import\s+[\w.]+\s+as\s+(\w+)`)
+	importStatement = regexp.MustCompile(`import\s*\{[^}]*\}\s*from|(?:const|let|var)\s*\{[^}]*\}\s*=\s*require\s*\(|(?m)^\s*from\s+\S+\s+import\s+(?:\([^)]*\)|[^\n]*)`)
+)
+
+// importSpans returns the spans of statements importing names from a
+// module, where the target's name is referenced unqualified
+func importSpans(content string) [][]int {
+	return importStatement.FindAllStringIndex(content, -1)
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// moduleRef returns how a test file imports a source file: a relative path
+// for JS/TS, a dotted module for Python and a package import path for Go
+func moduleRef(repoPath, testFile, sourceFile string, lang parser.Language) string {
+	switch lang {
+	case parser.LanguageJavaScript, parser.LanguageTypeScript:
+		rel, err := filepath.Rel(filepath.Dir(testFile), sourceFile)
+		if err != nil {
+			return ""
+		}
+		rel = filepath.ToSlash(strings.TrimSuffix(rel, filepath.Ext(rel)))
+		if !strings.HasPrefix(rel, ".") {
+			rel = "./" + rel
+		}
+		return rel
+
+	case parser.LanguagePython:
+		rel, err := filepath.Rel(repoPath, sourceFile)
+		if err != nil {
+			return ""
+		}
+		rel = strings.TrimSuffix(filepath.ToSlash(rel), ".py")
+		return strings.ReplaceAll(rel, "/", ".")
+
+	case parser.LanguageGo:
+		module := goModulePath(repoPath)
+		if module == "" {
+			return ""
+		}
+		rel, err := filepath.Rel(repoPath, filepath.Dir(sourceFile))
+		if err != nil {
+			return ""
+		}
+		if rel == "." {
+			return module
+		}
+		return module + "/" + filepath.ToSlash(rel)
+	}
+	return ""
+}
+
+func replaceModuleRef(content, oldRef, newRef string, lang parser.Language) string {
+	if lang == parser.LanguagePython {
+		imports := regexp.MustCompile(`\b(from|import)(\s+)` + regexp.QuoteMeta(oldRef) + `\b`)
+		return imports.ReplaceAllString(content, "${1}${2}"+newRef)
+	}
+	for _, q := range []string{`"`, `'`} {
+		content = strings.ReplaceAll(content, q+oldRef+q, q+newRef+q)
+	}
+	return content
+}
+
+// goModulePath reads the module path from the repository's go.mod
+func goModulePath(repoPath string) string {
+	f, err := os.Open(filepath.Join(repoPath, "go.mod"))
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "module ") {
+			return strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, "module")), `"`)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,246 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/QTest-hq/qtest/internal/parser"
+)
+
+const computeTotalBody = `func ComputeTotal(items []Item, tax float64) float64 {
+	sum := 0.0
+	for _, it := range items {
+		sum += it.Price * float64(it.Qty)
+	}
+	return sum * (1 + tax)
+}`
+
+func goFunc(name, body string) parser.Function {
+	return parser.Function{
+		Name:       name,
+		Exported:   true,
+		StartLine:  1,
+		Parameters: []parser.Parameter{{Name: "items", Type: "[]Item"}, {Name: "tax", Type: "float64"}},
+		ReturnType: "float64",
+		Body:       body,
+	}
+}
+
+func TestFingerprint_Similarity(t *testing.T) {
+	original := fingerprintFunction(goFunc("ComputeTotal", computeTotalBody))
+	renamed := fingerprintFunction(goFunc("OrderTotal",
+		strings.Replace(computeTotalBody, "ComputeTotal", "OrderTotal", 1)))
+	unrelated := fingerprintFunction(parser.Function{
+		Name:       "Greet",
+		Parameters: []parser.Parameter{{Name: "name", Type: "string"}},
+		ReturnType: "string",
+		Body:       `func Greet(name string) string { return "hello " + name }`,
+	})
+
+	if got := original.Similarity(renamed); got != 1 {
+		t.Errorf("renamed similarity = %v, want 1", got)
+	}
+	if got := original.Similarity(unrelated); got >= DefaultRenameThreshold {
+		t.Errorf("unrelated similarity = %v, want < %v", got, DefaultRenameThreshold)
+	}
+}
+
+func TestWorkspace_RelinkTargets(t *testing.T) {
+	ws := &Workspace{State: &WorkspaceState{Targets: make(map[string]*TargetState)}}
+
+	ws.AddTargets([]parser.Function{
+		goFunc("ComputeTotal", computeTotalBody),
+		{Name: "Removed", Exported: true, StartLine: 20, Body: "func Removed() {}"},
+	}, "billing/total.go")
+	old := ws.State.Targets["billing/total.go:1:ComputeTotal"]
+	old.Status = StatusCompleted
+	old.Covered = true
+	old.TestFile = "billing/total_test.go"
+
+	// ComputeTotal renamed and moved; Removed deleted
+	moved := goFunc("OrderTotal", strings.Replace(computeTotalBody, "ComputeTotal", "OrderTotal", 1))
+	moved.StartLine = 12
+	seen := make(map[string]bool)
+	for _, id := range ws.AddTargets([]parser.Function{moved}, "orders/total.go") {
+		seen[id] = true
+	}
+
+	renames := ws.RelinkTargets(seen, DefaultRenameThreshold)
+	if len(renames) != 1 {
+		t.Fatalf("len(renames) = %d, want 1", len(renames))
+	}
+	rn := renames[0]
+	if rn.OldName != "ComputeTotal" || rn.NewName != "OrderTotal" || rn.NewFile != "orders/total.go" {
+		t.Errorf("rename = %+v", rn)
+	}
+
+	target := ws.State.Targets["orders/total.go:12:OrderTotal"]
+	if target.Status != StatusCompleted || !target.Covered || target.TestFile != "billing/total_test.go" {
+		t.Errorf("test state not moved to new target: %+v", target)
+	}
+	if len(ws.State.Targets) != 1 || ws.State.TotalTargets != 1 {
+		t.Errorf("Targets = %d (total %d), want only the re-linked target", len(ws.State.Targets), ws.State.TotalTargets)
+	}
+}
+
+func TestWorkspace_RelinkTargets_Ambiguous(t *testing.T) {
+	ws := &Workspace{State: &WorkspaceState{Targets: make(map[string]*TargetState)}}
+	ws.AddTargets([]parser.Function{goFunc("ComputeTotal", computeTotalBody)}, "a.go")
+	for _, target := range ws.State.Targets {
+		target.Status = StatusCompleted
+		target.TestFile = "a_test.go"
+	}
+
+	// Two identical copies: neither should claim the tests
+	seen := make(map[string]bool)
+	for _, file := range []string{"b.go", "c.go"} {
+		for _, id := range ws.AddTargets([]parser.Function{goFunc("Total", computeTotalBody)}, file) {
+			seen[id] = true
+		}
+	}
+
+	if renames := ws.RelinkTargets(seen, DefaultRenameThreshold); len(renames) != 0 {
+		t.Errorf("ambiguous match re-linked: %+v", renames)
+	}
+	if _, ok := ws.State.Targets["a.go:1:ComputeTotal"]; !ok {
+		t.Error("unmatched target with tests should be kept")
+	}
+}
+
+func TestUpdateTestReferences(t *testing.T) {
+	repo := t.TempDir()
+	if err := os.WriteFile(filepath.Join(repo, "go.mod"), []byte("module example.com/shop\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	testFile := writeTestFile(t, repo, "billing/total_test.go", `package billing_test
+
+import (
+	"testing"
+
+	"example.com/shop/billing"
+)
+
+func TestComputeTotal(t *testing.T) {
+	if billing.ComputeTotal(nil, 0) != 0 {
+		t.Fatal("ComputeTotalish is unrelated")
+	}
+}
+`)
+
+	changed, err := UpdateTestReferences(repo, Rename{
+		OldName:  "ComputeTotal",
+		NewName:  "OrderTotal",
+		OldFile:  filepath.Join(repo, "billing/total.go"),
+		NewFile:  filepath.Join(repo, "orders/total.go"),
+		TestFile: testFile,
+	})
+	if err != nil || !changed {
+		t.Fatalf("UpdateTestReferences() = %v, %v", changed, err)
+	}
+
+	data, _ := os.ReadFile(testFile)
+	got := string(data)
+	for _, want := range []string{`"example.com/shop/orders"`, "func TestOrderTotal(", "billing.OrderTotal(", "ComputeTotalish"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("updated test missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestUpdateTestReferences_OtherPackageSelector(t *testing.T) {
+	repo := t.TempDir()
+	testFile := writeTestFile(t, repo, "store/store_test.go", `package store
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	s := New()
+	if s == nil {
+		t.Fatal(errors.New("no store"))
+	}
+	http.Get("http://example.com")
+	if store.New() == nil {
+		t.Fatal("qualified")
+	}
+}
+`)
+
+	changed, err := UpdateTestReferences(repo, Rename{
+		OldName:  "New",
+		NewName:  "NewStore",
+		OldFile:  filepath.Join(repo, "store/store.go"),
+		NewFile:  filepath.Join(repo, "store/store.go"),
+		TestFile: testFile,
+	})
+	if err != nil || !changed {
+		t.Fatalf("UpdateTestReferences() = %v, %v", changed, err)
+	}
+
+	data, _ := os.ReadFile(testFile)
+	got := string(data)
+	for _, want := range []string{"func TestNewStore(", "s := NewStore()", "store.NewStore()", "errors.New(", "http.Get("} {
+		if !strings.Contains(got, want) {
+			t.Errorf("updated test missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestUpdateTestReferences_Method(t *testing.T) {
+	repo := t.TempDir()
+	testFile := writeTestFile(t, repo, "tests/test_cache.py", `from app.cache import Cache
+import requests
+
+
+def test_get():
+    c = Cache()
+    assert c.get("a") is None
+    assert requests.get("http://example.com").ok
+    m = {}
+    assert m.get("a") is None
+`)
+
+	changed, err := UpdateTestReferences(repo, Rename{
+		OldName:  "get",
+		NewName:  "lookup",
+		Class:    "Cache",
+		OldFile:  filepath.Join(repo, "app/cache.py"),
+		NewFile:  filepath.Join(repo, "app/cache.py"),
+		TestFile: testFile,
+	})
+	if err != nil || !changed {
+		t.Fatalf("UpdateTestReferences() = %v, %v", changed, err)
+	}
+
+	data, _ := os.ReadFile(testFile)
+	got := string(data)
+	for _, want := range []string{"def test_lookup(", `c.lookup("a")`, "requests.get(", `m.get("a")`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("updated test missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestModuleRef(t *testing.T) {
+	repo := "/repo"
+	tests := []struct {
+		lang   parser.Language
+		source string
+		want   string
+	}{
+		{parser.LanguageTypeScript, "/repo/src/lib/math.ts", "../lib/math"},
+		{parser.LanguageTypeScript, "/repo/src/app/math.ts", "./math"},
+		{parser.LanguagePython, "/repo/pkg/util/math.py", "pkg.util.math"},
+	}
+	for _, tt := range tests {
+		if got := moduleRef(repo, "/repo/src/app/math.test.ts", tt.source, tt.lang); got != tt.want {
+			t.Errorf("moduleRef(%s) = %q, want %q", tt.source, got, tt.want)
+		}
+	}
+}
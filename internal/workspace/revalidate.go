@@ -0,0 +1,187 @@
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// RevalidationStatus is the outcome for one previously accepted test
+type RevalidationStatus string
+
+const (
+	RevalidationPassed      RevalidationStatus = "passed"
+	RevalidationBroken      RevalidationStatus = "broken"      // fails on HEAD, target still exists
+	RevalidationOrphaned    RevalidationStatus = "orphaned"    // target no longer in the source
+	RevalidationRegenerated RevalidationStatus = "regenerated" // broken, regenerated and passing again
+)
+
+// RevalidationResult describes one re-run test
+type RevalidationResult struct {
+	TargetID string             `json:"target_id"`
+	Target   string             `json:"target"`
+	TestFile string             `json:"test_file"`
+	Status   RevalidationStatus `json:"status"`
+	Error    string             `json:"error,omitempty"`
+	Output   string             `json:"output,omitempty"`
+}
+
+// RevalidationReport is saved as the revalidation.json artifact
+type RevalidationReport struct {
+	Version    string               `json:"version"`
+	ExecutedAt time.Time            `json:"executed_at"`
+	BaseSHA    string               `json:"base_sha"` // commit the tests were generated against
+	HeadSHA    string               `json:"head_sha"` // commit they were re-run on
+	Summary    RevalidationSummary  `json:"summary"`
+	Results    []RevalidationResult `json:"results"`
+}
+
+type RevalidationSummary struct {
+	Total       int `json:"total"`
+	Passed      int `json:"passed"`
+	Broken      int `json:"broken"`
+	Orphaned    int `json:"orphaned"`
+	Regenerated int `json:"regenerated"`
+}
+
+// Revalidator re-runs a workspace's accepted tests against the current
+// checkout (e.g. after dependency upgrades or refactors) and optionally
+// regenerates broken ones for the same target.
+type Revalidator struct {
+	runner     *Runner
+	regenerate bool
+
+	// Hooks, replaceable in tests
+	validate       func(ctx context.Context, target *TargetState) ValidationResult
+	locate         func(ctx context.Context, target *TargetState) bool
+	regenerateTest func(ctx context.Context, target *TargetState) (string, error)
+}
+
+// NewRevalidator creates a revalidator. With regenerate set, broken tests are
+// regenerated from their original target and re-run.
+func NewRevalidator(runner *Runner, regenerate bool) *Revalidator {
+	validator := NewTestValidator(runner.ws)
+	return &Revalidator{
+		runner:         runner,
+		regenerate:     regenerate,
+		validate:       validator.ValidateTest,
+		locate:         runner.targetExists,
+		regenerateTest: runner.generateTest,
+	}
+}
+
+// Run revalidates every completed target and saves the report artifact
+func (v *Revalidator) Run(ctx context.Context) (*RevalidationReport, error) {
+	ws := v.runner.ws
+	report := &RevalidationReport{
+		Version:    "1.0",
+		ExecutedAt: time.Now(),
+		BaseSHA:    ws.CommitSHA,
+	}
+	if head, err := v.runner.git.HeadSHA(); err == nil {
+		report.HeadSHA = head
+	} else {
+		log.Warn().Err(err).Msg("could not resolve HEAD")
+	}
+
+	for _, target := range ws.State.Targets {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if target.Status != StatusCompleted || target.TestFile == "" {
+			continue
+		}
+
+		result := v.revalidate(ctx, target)
+		report.Results = append(report.Results, result)
+
+		report.Summary.Total++
+		switch result.Status {
+		case RevalidationPassed:
+			report.Summary.Passed++
+		case RevalidationBroken:
+			report.Summary.Broken++
+		case RevalidationOrphaned:
+			report.Summary.Orphaned++
+		case RevalidationRegenerated:
+			report.Summary.Regenerated++
+		}
+	}
+
+	if err := v.runner.artifacts.saveArtifact("revalidation.json", report); err != nil {
+		return nil, err
+	}
+	if err := ws.Save(); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+func (v *Revalidator) revalidate(ctx context.Context, target *TargetState) RevalidationResult {
+	result := RevalidationResult{
+		TargetID: target.ID,
+		Target:   target.Name,
+		TestFile: target.TestFile,
+	}
+
+	if _, err := os.Stat(target.TestFile); err == nil {
+		run := v.validate(ctx, target)
+		if run.Passed {
+			result.Status = RevalidationPassed
+			return result
+		}
+		result.Error = run.Error
+		result.Output = run.Output
+	} else {
+		result.Error = "test file missing"
+	}
+
+	if !v.locate(ctx, target) {
+		result.Status = RevalidationOrphaned
+		return result
+	}
+
+	result.Status = RevalidationBroken
+	if !v.regenerate {
+		return result
+	}
+
+	testFile, err := v.regenerateTest(ctx, target)
+	if err != nil {
+		result.Error = fmt.Sprintf("regeneration failed: %v", err)
+		return result
+	}
+	if testFile == "" {
+		return result
+	}
+
+	target.TestFile = testFile
+	now := time.Now()
+	target.GeneratedAt = &now
+	result.TestFile = testFile
+
+	run := v.validate(ctx, target)
+	if !run.Passed {
+		result.Error = fmt.Sprintf("regenerated test still fails: %s", run.Error)
+		result.Output = run.Output
+		return result
+	}
+
+	result.Status = RevalidationRegenerated
+	result.Error = ""
+	result.Output = ""
+	return result
+}
+
+// targetExists reports whether a target's function is still in its source file
+func (r *Runner) targetExists(ctx context.Context, target *TargetState) bool {
+	parsed, err := r.parser.ParseFile(ctx, target.File)
+	if err != nil {
+		return false
+	}
+	return locateFunction(parsed.Functions, target) != nil
+}
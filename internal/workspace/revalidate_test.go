@@ -0,0 +1,121 @@
+package workspace
+
+import (
+	"context"
+	"testing"
+
+	"github.com/QTest-hq/qtest/internal/parser"
+)
+
+func TestLocateFunction(t *testing.T) {
+	functions := []parser.Function{
+		{Name: "Add", StartLine: 10},
+		{Name: "Sub", StartLine: 20},
+		{Name: "Dup", StartLine: 30},
+		{Name: "Dup", StartLine: 40},
+	}
+
+	target := &TargetState{Name: "Add", Line: 10}
+	if fn := locateFunction(functions, target); fn == nil || fn.StartLine != 10 {
+		t.Errorf("exact match = %v, want line 10", fn)
+	}
+
+	moved := &TargetState{Name: "Sub", Line: 5}
+	if fn := locateFunction(functions, moved); fn == nil || moved.Line != 20 {
+		t.Errorf("moved function not relocated: %v, line %d", fn, moved.Line)
+	}
+
+	if fn := locateFunction(functions, &TargetState{Name: "Dup", Line: 1}); fn != nil {
+		t.Error("ambiguous name should not match")
+	}
+	if fn := locateFunction(functions, &TargetState{Name: "Gone", Line: 10}); fn != nil {
+		t.Error("removed function should not match")
+	}
+}
+
+func TestRevalidator_Run(t *testing.T) {
+	repo := t.TempDir()
+	ws := &Workspace{
+		ID:       "ws-test",
+		RepoPath: repo,
+		path:     t.TempDir(),
+		State:    &WorkspaceState{Targets: make(map[string]*TargetState)},
+	}
+
+	add := func(name string) *TargetState {
+		target := &TargetState{
+			ID:       name,
+			Name:     name,
+			Status:   StatusCompleted,
+			TestFile: writeTestFile(t, repo, name+"_test.go", "package x"),
+		}
+		ws.State.Targets[name] = target
+		return target
+	}
+	add("passing")
+	add("removed")
+	add("broken")
+	add("fixable")
+	ws.State.Targets["pending"] = &TargetState{ID: "pending", Status: StatusPending}
+
+	run := func(regenerate bool) *RevalidationReport {
+		v := NewRevalidator(NewRunner(ws, nil, "", nil), regenerate)
+		v.validate = func(_ context.Context, target *TargetState) ValidationResult {
+			passed := target.Name == "passing" || target.TestFile == "regenerated_test.go"
+			return ValidationResult{Passed: passed, Error: "assertion failed"}
+		}
+		v.locate = func(_ context.Context, target *TargetState) bool {
+			return target.Name != "removed"
+		}
+		v.regenerateTest = func(_ context.Context, target *TargetState) (string, error) {
+			if target.Name == "fixable" {
+				return "regenerated_test.go", nil
+			}
+			return "", nil
+		}
+
+		report, err := v.Run(context.Background())
+		if err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		return report
+	}
+
+	statuses := func(report *RevalidationReport) map[string]RevalidationStatus {
+		got := make(map[string]RevalidationStatus)
+		for _, r := range report.Results {
+			got[r.Target] = r.Status
+		}
+		return got
+	}
+
+	report := run(false)
+	want := map[string]RevalidationStatus{
+		"passing": RevalidationPassed,
+		"removed": RevalidationOrphaned,
+		"broken":  RevalidationBroken,
+		"fixable": RevalidationBroken,
+	}
+	got := statuses(report)
+	for name, status := range want {
+		if got[name] != status {
+			t.Errorf("%s = %s, want %s", name, got[name], status)
+		}
+	}
+	if report.Summary.Total != 4 || report.Summary.Broken != 2 || report.Summary.Orphaned != 1 {
+		t.Errorf("Summary = %+v", report.Summary)
+	}
+
+	var saved RevalidationReport
+	if err := NewArtifactManager(ws).LoadArtifact("revalidation.json", &saved); err != nil {
+		t.Fatalf("revalidation.json not saved: %v", err)
+	}
+
+	report = run(true)
+	if got := statuses(report); got["fixable"] != RevalidationRegenerated || got["broken"] != RevalidationBroken {
+		t.Errorf("regenerate statuses = %v", got)
+	}
+	if ws.State.Targets["fixable"].TestFile != "regenerated_test.go" {
+		t.Error("regenerated test file not recorded on target")
+	}
+}
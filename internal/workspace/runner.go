@@ -12,6 +12,7 @@ import (
 
 	"github.com/QTest-hq/qtest/internal/adapters"
 	"github.com/QTest-hq/qtest/internal/config"
+	"github.com/QTest-hq/qtest/internal/conventions"
 	"github.com/QTest-hq/qtest/internal/github"
 	"github.com/QTest-hq/qtest/internal/llm"
 	"github.com/QTest-hq/qtest/internal/parser"
@@ -28,9 +29,15 @@ type Runner struct {
 	llmRouter  *llm.Router
 	adapters   *adapters.Registry
 	artifacts  *ArtifactManager
+	cache      *ParseCache
 	cfg        *RunConfig
 	projectCfg *config.ProjectConfig
 	startTime  time.Time
+	renames    []Rename // targets re-linked by the last parse
+
+	// Testing conventions of the repository, detected once per run
+	conventions     *conventions.Report
+	conventionsOnce sync.Once
 
 	// Callbacks for progress reporting
 	OnProgress func(current, total int, target *TargetState)
@@ -41,19 +48,23 @@ type Runner struct {
 // RunConfig holds configuration for a generation run
 type RunConfig struct {
 	Tier          llm.Tier
-	CommitEach    bool     // Commit after each test
-	BranchName    string   // Branch for tests
-	TestDir       string   // Directory for test files
-	DryRun        bool     // Don't write files
-	MaxConcurrent int      // Max parallel generations
-	FilePatterns  []string // Files to include
-	ValidateTests bool     // Run tests after generation
-	MaxTests      int      // Max tests to generate (0=unlimited)
-	CreatePR      bool     // Create a PR after generation
-	PRDraft       bool     // Create PR as draft
-	PRTitle       string   // Custom PR title
-	GitHubOwner   string   // GitHub repo owner
-	GitHubRepo    string   // GitHub repo name
+	CommitEach    bool          // Commit after each test
+	BranchName    string        // Branch for tests
+	TestDir       string        // Directory for test files
+	DryRun        bool          // Don't write files
+	MaxConcurrent int           // Max parallel generations
+	FilePatterns  []string      // Files to include
+	ValidateTests bool          // Run tests after generation
+	MaxTests      int           // Max tests to generate (0=unlimited)
+	CreatePR      bool          // Create a PR after generation
+	PRDraft       bool          // Create PR as draft
+	PRTitle       string        // Custom PR title
+	GitHubOwner   string        // GitHub repo owner
+	GitHubRepo    string        // GitHub repo name
+	DebugPrompts  bool          // Write each target's prompt and response to artifacts/prompts
+	ParallelTests bool          // Mark Go tests of pure functions t.Parallel()
+	TestTimeout   time.Duration // Deadline for each API call in Go tests (0 = none)
+	Chaos         bool          // Also test code calling HTTP APIs against downstream timeouts, 5xx and malformed responses
 }
 
 // DefaultRunConfig returns sensible defaults
@@ -65,7 +76,9 @@ func DefaultRunConfig() *RunConfig {
 		TestDir:       "", // Same directory as source
 		DryRun:        false,
 		MaxConcurrent: 1, // 1 = sequential, >1 = parallel workers
-		FilePatterns:  []string{"*.go", "*.py", "*.ts", "*.js"},
+		FilePatterns:  []string{"*.go", "*.py", "*.ts", "*.js", "*.rs"},
+		ParallelTests: true,
+		TestTimeout:   30 * time.Second,
 	}
 }
 
@@ -82,6 +95,7 @@ func NewRunner(ws *Workspace, llmRouter *llm.Router, gitToken string, cfg *RunCo
 		llmRouter: llmRouter,
 		adapters:  adapters.NewRegistry(),
 		artifacts: NewArtifactManager(ws),
+		cache:     NewParseCache(ws),
 		cfg:       cfg,
 	}
 }
@@ -197,6 +211,12 @@ func (r *Runner) parse(ctx context.Context) error {
 
 		// Check extension
 		ext := filepath.Ext(path)
+
+		// Skip Rust integration tests and cargo build output
+		if ext == ".rs" && (filepath.Base(filepath.Dir(path)) == "tests" ||
+			strings.Contains(filepath.ToSlash(path), "/target/")) {
+			return nil
+		}
 		for _, pattern := range r.cfg.FilePatterns {
 			if strings.HasSuffix(pattern, ext) {
 				files = append(files, path)
@@ -219,9 +239,10 @@ func (r *Runner) parse(ctx context.Context) error {
 
 	log.Info().Int("files", len(uniqueFiles)).Msg("found source files")
 
-	// Parse each file
+	// Parse each file, reusing cached parses of unchanged files
+	seenTargets := make(map[string]bool)
 	for _, file := range uniqueFiles {
-		parsed, err := r.parser.ParseFile(ctx, file)
+		parsed, fingerprints, err := parseCached(ctx, r.parser, r.cache, r.ws.RepoPath, file)
 		if err != nil {
 			log.Debug().Err(err).Str("file", file).Msg("skipping file")
 			continue
@@ -233,7 +254,9 @@ func (r *Runner) parse(ctx context.Context) error {
 		}
 
 		// Add functions as targets
-		r.ws.AddTargets(parsed.Functions, file)
+		for _, id := range r.ws.addTargets(parsed.Functions, file, fingerprints) {
+			seenTargets[id] = true
+		}
 
 		log.Debug().
 			Str("file", file).
@@ -241,12 +264,51 @@ func (r *Runner) parse(ctx context.Context) error {
 			Msg("parsed file")
 	}
 
+	r.renames = r.relinkTargets(seenTargets)
+	pruneParseCache(r.cache)
+
 	r.ws.SetPhase(PhasePlanning)
-	log.Info().Int("targets", r.ws.State.TotalTargets).Msg("found testable targets")
+	hits, _ := r.cache.Stats()
+	log.Info().Int("targets", r.ws.State.TotalTargets).Int("cached_files", hits).Msg("found testable targets")
 
 	return r.ws.Save()
 }
 
+// Remodel re-parses an existing workspace's checkout, e.g. after a refactor.
+// Tests of functions that were renamed or moved are re-linked to the new
+// targets and their test files updated; the re-links are returned.
+func (r *Runner) Remodel(ctx context.Context) ([]Rename, error) {
+	phase := r.ws.State.Phase
+	if err := r.parse(ctx); err != nil {
+		return nil, fmt.Errorf("parse failed: %w", err)
+	}
+	if phase != PhaseInit && phase != "" {
+		r.ws.SetPhase(phase)
+	}
+	return r.renames, r.ws.Save()
+}
+
+// relinkTargets re-links tests of moved or renamed functions after a parse
+// and rewrites their references
+func (r *Runner) relinkTargets(seen map[string]bool) []Rename {
+	renames := r.ws.RelinkTargets(seen, DefaultRenameThreshold)
+	for i := range renames {
+		rn := &renames[i]
+		updated, err := UpdateTestReferences(r.ws.RepoPath, *rn)
+		if err != nil {
+			log.Warn().Err(err).Str("test", rn.TestFile).Msg("failed to update test references")
+		}
+		rn.TestUpdated = updated
+
+		log.Info().
+			Str("from", rn.OldID).
+			Str("to", rn.NewID).
+			Float64("score", rn.Score).
+			Msg("re-linked tests to moved target")
+	}
+	return renames
+}
+
 // Run executes the incremental generation
 func (r *Runner) Run(ctx context.Context) error {
 	r.ws.SetPhase(PhaseGenerating)
@@ -477,8 +539,18 @@ func (r *Runner) runParallel(ctx context.Context, workers int, total int, proces
 	return r.ws.Save()
 }
 
+// testingConventions returns the repository's testing conventions,
+// detecting them on first use
+func (r *Runner) testingConventions() *conventions.Report {
+	r.conventionsOnce.Do(func() {
+		r.conventions = conventions.Detect(r.ws.RepoPath)
+		log.Info().Int("test_files", r.conventions.TestFiles).Msg("detected testing conventions")
+	})
+	return r.conventions
+}
+
 // generateTest generates a test for a single target
-func (r *Runner) generateTest(ctx context.Context, target *TargetState) (string, error) {
+func (r *Runner) generateTest(ctx context.Context, target *TargetState) (_ string, err error) {
 	// Read the source file
 	content, err := os.ReadFile(target.File)
 	if err != nil {
@@ -493,14 +565,7 @@ func (r *Runner) generateTest(ctx context.Context, target *TargetState) (string,
 	}
 
 	// Find the target function
-	var targetFn *parser.Function
-	for _, fn := range parsed.Functions {
-		if fn.Name == target.Name && fn.StartLine == target.Line {
-			targetFn = &fn
-			break
-		}
-	}
-
+	targetFn := locateFunction(parsed.Functions, target)
 	if targetFn == nil {
 		return "", fmt.Errorf("function not found: %s", target.Name)
 	}
@@ -513,25 +578,42 @@ func (r *Runner) generateTest(ctx context.Context, target *TargetState) (string,
 		funcCode.WriteString("\n")
 	}
 
-	// Create prompt and generate
-	prompt := llm.TestGenerationPrompt(
+	// Create prompt, led by the repository's conventions, and generate
+	prompt := r.testingConventions().Prepend(llm.TestGenerationPrompt(
 		funcCode.String(),
 		targetFn.Name,
 		target.File,
 		string(lang),
 		"",
-	)
+	))
 
-	resp, err := r.llmRouter.Complete(ctx, &llm.Request{
+	req := &llm.Request{
 		Tier:        r.cfg.Tier,
 		System:      llm.SystemPromptTestGeneration,
 		Messages:    []llm.Message{{Role: "user", Content: prompt}},
 		Temperature: 0.3,
 		MaxTokens:   2000,
-	})
+	}
+
+	// Record what was sent, received and made of it for --debug-prompts
+	var debug *PromptDebug
+	if r.cfg.DebugPrompts {
+		debug = NewPromptDebug(target.ID, target.Name, req)
+		defer func() {
+			debug.AddError(err)
+			if _, saveErr := r.artifacts.SavePromptDebug(debug); saveErr != nil {
+				log.Warn().Err(saveErr).Str("target", target.ID).Msg("failed to save prompt debug record")
+			}
+		}()
+	}
+
+	resp, err := r.llmRouter.Complete(ctx, req)
 	if err != nil {
 		return "", fmt.Errorf("LLM error: %w", err)
 	}
+	if debug != nil {
+		debug.SetResponse(resp)
+	}
 
 	// Parse DSL
 	yamlContent := llm.ParseDSLOutput(resp.Content)
@@ -539,6 +621,9 @@ func (r *Runner) generateTest(ctx context.Context, target *TargetState) (string,
 	if err := yaml.Unmarshal([]byte(yamlContent), &testDSL); err != nil {
 		return "", fmt.Errorf("invalid DSL: %w", err)
 	}
+	if debug != nil {
+		debug.Parsed = testDSL
+	}
 
 	// Store DSL in target
 	dslJSON, _ := yaml.Marshal(testDSL)
@@ -558,6 +643,9 @@ func (r *Runner) generateTest(ctx context.Context, target *TargetState) (string,
 	// Generate test code
 	testCode, err := adapter.Generate(&testDSL)
 	if err != nil {
+		if debug != nil {
+			debug.AddError(fmt.Errorf("code generation failed, saved DSL instead: %w", err))
+		}
 		// Fall back to DSL
 		return r.saveDSL(target, yamlContent)
 	}
@@ -568,11 +656,46 @@ func (r *Runner) generateTest(ctx context.Context, target *TargetState) (string,
 		return "", err
 	}
 
-	if err := os.WriteFile(testFile, []byte(testCode), 0644); err != nil {
+	written, err := adapters.WriteGeneratedFile(testFile, testCode, adapters.Provenance{
+		RunID:        r.ws.ID,
+		Model:        resp.Model,
+		PromptHash:   llm.PromptHash(req),
+		SourceCommit: r.ws.CommitSHA,
+		Source:       target.File,
+		Targets:      []string{adapters.TargetName(targetFn.Class, targetFn.Name)},
+		Attempts:     resp.Attempts,
+	}, adapters.WriteOptions{})
+	if err != nil {
 		return "", err
 	}
+	if written.MergePath != "" {
+		return "", fmt.Errorf("%s was edited by hand, regenerated tests merged into %s (%d conflicts)",
+			testFile, written.MergePath, written.Conflicts)
+	}
+	return written.Path, nil
+}
+
+// locateFunction finds a target's function, preferring an exact name+line
+// match. If the function has moved within the file (e.g. after a refactor)
+// a unique name match is used and the target's line is updated.
+func locateFunction(functions []parser.Function, target *TargetState) *parser.Function {
+	var byName []int
+	for i := range functions {
+		if functions[i].Name != target.Name {
+			continue
+		}
+		if functions[i].StartLine == target.Line {
+			return &functions[i]
+		}
+		byName = append(byName, i)
+	}
 
-	return testFile, nil
+	if len(byName) != 1 {
+		return nil
+	}
+	fn := &functions[byName[0]]
+	target.Line = fn.StartLine
+	return fn
 }
 
 // saveDSL saves just the DSL when no adapter is available
@@ -600,6 +723,10 @@ func (r *Runner) getTestFilePath(sourceFile string, adapter adapters.Adapter) st
 		dir = filepath.Join(r.ws.RepoPath, r.cfg.TestDir, relDir)
 	}
 
+	if adapter != nil && adapter.Framework() == adapters.FrameworkCargo && r.cfg.TestDir == "" {
+		// Integration tests live in the crate's tests/ directory
+		return adapters.RustTestPath(sourceFile)
+	}
 	if adapter != nil {
 		return filepath.Join(dir, name+adapter.TestFileSuffix()+adapter.FileExtension())
 	}
@@ -694,12 +821,21 @@ func (r *Runner) CreatePR(ctx context.Context) (*github.PRResponse, error) {
 	}
 
 	// Generate PR body
-	body := github.GeneratePRBody(github.PRTemplate{
+	tmpl := github.PRTemplate{
 		TestCount: completedCount,
 		Files:     testFiles,
 		Framework: r.detectFramework(),
 		Language:  r.ws.Language,
-	})
+	}
+	var bodyTemplate string
+	if r.projectCfg != nil {
+		bodyTemplate = r.projectCfg.PR.BodyTemplate
+	}
+	body, err := github.RenderPRBody(tmpl, bodyTemplate)
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to render custom PR body, using the default")
+		body = github.GeneratePRBody(tmpl)
+	}
 
 	// Create the PR
 	pr, err := prService.CreatePR(ctx, github.PRRequest{
@@ -724,6 +860,36 @@ func (r *Runner) CreatePR(ctx context.Context) (*github.PRResponse, error) {
 	return pr, nil
 }
 
+// UpdateChangelog records this run in TESTS_CHANGELOG.md and commits it
+// alongside the tests. Returns nil when the suite didn't change.
+func (r *Runner) UpdateChangelog() (*ChangelogEntry, error) {
+	if r.cfg.DryRun {
+		return nil, nil
+	}
+
+	var written []string
+	for _, target := range r.ws.State.Targets {
+		if target.Status == StatusCompleted && target.TestFile != "" {
+			written = append(written, target.TestFile)
+		}
+	}
+
+	return commitChangelog(r.ws, r.git, written)
+}
+
+// commitChangelog records a changelog entry and commits it
+func commitChangelog(ws *Workspace, git *GitManager, written []string) (*ChangelogEntry, error) {
+	entry, err := RecordChangelog(ws, written)
+	if err != nil || entry == nil {
+		return entry, err
+	}
+
+	if _, err := git.CommitAll("Update " + ChangelogFile); err != nil {
+		log.Warn().Err(err).Msg("failed to commit tests changelog")
+	}
+	return entry, nil
+}
+
 // detectFramework returns the detected test framework
 func (r *Runner) detectFramework() string {
 	switch r.ws.Language {
@@ -735,6 +901,8 @@ func (r *Runner) detectFramework() string {
 		return "Jest"
 	case "java":
 		return "JUnit 5"
+	case "rust":
+		return "cargo test"
 	case "ruby":
 		return "RSpec"
 	default:
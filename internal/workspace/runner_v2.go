@@ -5,10 +5,16 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/QTest-hq/qtest/internal/adapters"
+	"github.com/QTest-hq/qtest/internal/buildsys"
+	"github.com/QTest-hq/qtest/internal/config"
+	"github.com/QTest-hq/qtest/internal/conventions"
 	"github.com/QTest-hq/qtest/internal/emitter"
 	"github.com/QTest-hq/qtest/internal/llm"
 	"github.com/QTest-hq/qtest/internal/parser"
@@ -25,12 +31,17 @@ type RunnerV2 struct {
 	parser    *parser.Parser
 	llmRouter *llm.Router
 	emitters  *emitter.Registry
+	cache     *ParseCache
 	cfg       *RunConfig
 
 	// Pipeline state
 	sysModel *model.SystemModel
 	testPlan *model.TestPlan
 	specSet  *model.TestSpecSet
+	written  []string // test files written this run
+
+	// Testing conventions of the repository, prepended to each prompt
+	conventions *conventions.Report
 
 	// Callbacks
 	OnProgress func(phase string, current, total int, message string)
@@ -50,6 +61,7 @@ func NewRunnerV2(ws *Workspace, llmRouter *llm.Router, gitToken string, cfg *Run
 		parser:    parser.NewParser(),
 		llmRouter: llmRouter,
 		emitters:  emitter.NewRegistry(),
+		cache:     NewParseCache(ws),
 		cfg:       cfg,
 	}
 }
@@ -103,15 +115,22 @@ func (r *RunnerV2) Initialize(ctx context.Context) error {
 func (r *RunnerV2) buildSystemModel(ctx context.Context) error {
 	adapter := model.NewParserAdapter(r.ws.Name, r.ws.BaseBranch, r.ws.CommitSHA)
 
-	// Register supplements
-	registry := supplements.NewRegistry()
-	for _, supp := range registry.GetAll() {
+	// Register supplements, including ones declared in .qtest.yaml and its
+	// OpenAPI spec
+	opts, err := supplements.ProjectOptions(r.ws.RepoPath)
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to load project supplements, continuing without them")
+	}
+	for _, supp := range opts.Supplements {
 		adapter.RegisterSupplement(supp)
 	}
+	for _, name := range opts.Disabled {
+		adapter.OverrideFramework(name, false)
+	}
 
 	// Walk and parse files
 	fileCount := 0
-	err := filepath.Walk(r.ws.RepoPath, func(path string, info os.FileInfo, err error) error {
+	err = filepath.Walk(r.ws.RepoPath, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return nil
 		}
@@ -137,8 +156,8 @@ func (r *RunnerV2) buildSystemModel(ctx context.Context) error {
 			return nil
 		}
 
-		// Parse file
-		parsed, err := r.parser.ParseFile(ctx, path)
+		// Parse file, or reuse its cached parse
+		parsed, _, err := parseCached(ctx, r.parser, r.cache, r.ws.RepoPath, path)
 		if err != nil {
 			return nil
 		}
@@ -160,7 +179,9 @@ func (r *RunnerV2) buildSystemModel(ctx context.Context) error {
 		return err
 	}
 
-	log.Info().Int("files", fileCount).Msg("parsed repository")
+	hits, _ := r.cache.Stats()
+	log.Info().Int("files", fileCount).Int("cached", hits).Msg("parsed repository")
+	pruneParseCache(r.cache)
 
 	// Build model (runs supplements)
 	sysModel, err := adapter.Build()
@@ -222,8 +243,18 @@ func (r *RunnerV2) Run(ctx context.Context) error {
 		}
 	}
 
+	// Summarise the repository's testing conventions once for every prompt
+	if r.conventions == nil {
+		r.conventions = conventions.Detect(r.ws.RepoPath)
+		log.Info().Int("test_files", r.conventions.TestFiles).Msg("detected testing conventions")
+	}
+
 	// Create spec generator
 	specGen := specgen.NewGenerator(r.llmRouter, r.cfg.Tier)
+	specGen.Conventions = r.conventions.Summary()
+	if r.cfg.DebugPrompts {
+		specGen.OnExchange = r.savePromptDebug
+	}
 
 	// Initialize spec set if needed
 	if r.specSet == nil {
@@ -373,6 +404,8 @@ func (r *RunnerV2) Run(ctx context.Context) error {
 // emitTests generates test code from specs
 func (r *RunnerV2) emitTests(level model.TestLevel) error {
 	specs := r.specSet.FilterByLevel(level)
+	specs = r.emitGRPCTests(specs, level)
+	specs = r.emitGoUnitTests(specs, level)
 	if len(specs) == 0 {
 		return nil
 	}
@@ -388,6 +421,8 @@ func (r *RunnerV2) emitTests(level model.TestLevel) error {
 		em, err = r.emitters.Get("pytest")
 	case "go":
 		em, err = r.emitters.Get("go-http")
+	case "java":
+		em, err = r.emitters.Get("junit")
 	default:
 		em, err = r.emitters.Get("supertest") // Default
 	}
@@ -395,26 +430,21 @@ func (r *RunnerV2) emitTests(level model.TestLevel) error {
 	if err != nil {
 		return err
 	}
-
-	// Generate code
-	code, err := em.Emit(specs)
-	if err != nil {
-		return err
-	}
+	useProjectAssertions(em, r.ws.RepoPath)
+	emitter.SetTimeout(em, r.testTimeout())
 
 	// Determine output path
-	testDir := filepath.Join(r.ws.RepoPath, "tests")
-	if r.cfg.TestDir != "" {
-		testDir = filepath.Join(r.ws.RepoPath, r.cfg.TestDir)
+	testFile := r.testFile(em, level)
+	if err := os.MkdirAll(filepath.Dir(testFile), 0755); err != nil {
+		return err
 	}
 
-	if err := os.MkdirAll(testDir, 0755); err != nil {
+	// Generate code
+	code, err := em.Emit(specs)
+	if err != nil {
 		return err
 	}
 
-	filename := string(level) + em.FileExtension()
-	testFile := filepath.Join(testDir, filename)
-
 	if err := os.WriteFile(testFile, []byte(code), 0644); err != nil {
 		return err
 	}
@@ -440,6 +470,8 @@ func (r *RunnerV2) emitTests(level model.TestLevel) error {
 
 // emitNewTests appends new test specs to existing test file
 func (r *RunnerV2) emitNewTests(specs []model.TestSpec, level model.TestLevel) error {
+	specs = r.emitGRPCTests(specs, level)
+	specs = r.emitGoUnitTests(specs, level)
 	if len(specs) == 0 {
 		return nil
 	}
@@ -455,6 +487,8 @@ func (r *RunnerV2) emitNewTests(specs []model.TestSpec, level model.TestLevel) e
 		em, err = r.emitters.Get("pytest")
 	case "go":
 		em, err = r.emitters.Get("go-http")
+	case "java":
+		em, err = r.emitters.Get("junit")
 	default:
 		em, err = r.emitters.Get("supertest")
 	}
@@ -462,26 +496,21 @@ func (r *RunnerV2) emitNewTests(specs []model.TestSpec, level model.TestLevel) e
 	if err != nil {
 		return err
 	}
-
-	// Generate code for new tests
-	code, err := em.Emit(specs)
-	if err != nil {
-		return err
-	}
+	useProjectAssertions(em, r.ws.RepoPath)
+	emitter.SetTimeout(em, r.testTimeout())
 
 	// Determine output path
-	testDir := filepath.Join(r.ws.RepoPath, "tests")
-	if r.cfg.TestDir != "" {
-		testDir = filepath.Join(r.ws.RepoPath, r.cfg.TestDir)
+	testFile := r.testFile(em, level)
+	if err := os.MkdirAll(filepath.Dir(testFile), 0755); err != nil {
+		return err
 	}
 
-	if err := os.MkdirAll(testDir, 0755); err != nil {
+	// Generate code for new tests
+	code, err := em.Emit(specs)
+	if err != nil {
 		return err
 	}
 
-	filename := string(level) + em.FileExtension()
-	testFile := filepath.Join(testDir, filename)
-
 	// Check if file exists - if so, append; otherwise create
 	if _, err := os.Stat(testFile); err == nil {
 		// File exists - append new tests
@@ -493,10 +522,14 @@ func (r *RunnerV2) emitNewTests(specs []model.TestSpec, level model.TestLevel) e
 
 		// For JS/TS, we need to append test blocks without the imports
 		// For Python, append test functions
-		newCode := extractTestBlocks(code, r.ws.Language)
-
-		// Append to file
-		combined := string(existing) + "\n// === New tests added incrementally ===\n\n" + newCode
+		// For Java, the methods go inside the existing class
+		var combined string
+		if r.ws.Language == "java" {
+			combined = appendJavaTests(string(existing), code)
+		} else {
+			newCode := extractTestBlocks(code, r.ws.Language)
+			combined = string(existing) + "\n// === New tests added incrementally ===\n\n" + newCode
+		}
 		if err := os.WriteFile(testFile, []byte(combined), 0644); err != nil {
 			return err
 		}
@@ -517,6 +550,8 @@ func (r *RunnerV2) emitNewTests(specs []model.TestSpec, level model.TestLevel) e
 			Msg("created test file")
 	}
 
+	r.written = append(r.written, testFile)
+
 	// Commit if configured
 	if r.cfg.CommitEach && !r.cfg.DryRun {
 		if _, err := r.git.CommitTest(testFile, fmt.Sprintf("add %d new %s tests", len(specs), level)); err != nil {
@@ -531,6 +566,15 @@ func (r *RunnerV2) emitNewTests(specs []model.TestSpec, level model.TestLevel) e
 	return nil
 }
 
+// UpdateChangelog records this run in TESTS_CHANGELOG.md and commits it
+// alongside the tests. Returns nil when the suite didn't change.
+func (r *RunnerV2) UpdateChangelog() (*ChangelogEntry, error) {
+	if r.cfg.DryRun {
+		return nil, nil
+	}
+	return commitChangelog(r.ws, r.git, r.written)
+}
+
 // extractTestBlocks extracts test blocks without imports/setup
 func extractTestBlocks(code string, language string) string {
 	lines := strings.Split(code, "\n")
@@ -615,6 +659,11 @@ func (r *RunnerV2) saveArtifacts() error {
 		os.WriteFile(filepath.Join(artifactsDir, "specs.json"), data, 0644)
 	}
 
+	if r.conventions != nil {
+		data, _ := json.MarshalIndent(r.conventions, "", "  ")
+		os.WriteFile(filepath.Join(artifactsDir, "conventions.json"), data, 0644)
+	}
+
 	return nil
 }
 
@@ -640,6 +689,12 @@ func (r *RunnerV2) loadArtifacts() error {
 		json.Unmarshal(data, r.specSet)
 	}
 
+	// Load conventions
+	if data, err := os.ReadFile(filepath.Join(artifactsDir, "conventions.json")); err == nil {
+		r.conventions = &conventions.Report{}
+		json.Unmarshal(data, r.conventions)
+	}
+
 	if r.sysModel == nil || r.testPlan == nil {
 		return fmt.Errorf("artifacts not found, run Initialize first")
 	}
@@ -647,6 +702,20 @@ func (r *RunnerV2) loadArtifacts() error {
 	return nil
 }
 
+// savePromptDebug writes an intent's LLM exchange to artifacts/prompts
+func (r *RunnerV2) savePromptDebug(intent model.TestIntent, req *llm.Request, resp *llm.Response, spec *model.TestSpec, err error) {
+	debug := NewPromptDebug(intent.ID, intent.Reason, req)
+	debug.SetResponse(resp)
+	if spec != nil {
+		debug.Parsed = spec
+	}
+	debug.AddError(err)
+
+	if _, saveErr := NewArtifactManager(r.ws).SavePromptDebug(debug); saveErr != nil {
+		log.Warn().Err(saveErr).Str("intent", intent.ID).Msg("failed to save prompt debug record")
+	}
+}
+
 func (r *RunnerV2) reportProgress(phase string, current, total int, message string) {
 	if r.OnProgress != nil {
 		r.OnProgress(phase, current, total, message)
@@ -665,7 +734,7 @@ func (r *RunnerV2) Pause() {
 
 func isSupportedExt(ext string) bool {
 	switch ext {
-	case ".go", ".py", ".js", ".jsx", ".ts", ".tsx":
+	case ".go", ".py", ".js", ".jsx", ".ts", ".tsx", ".rs":
 		return true
 	}
 	return false
@@ -773,3 +842,271 @@ func convertToModelParsedFile(pf *parser.ParsedFile) *model.ParsedFile {
 
 	return result
 }
+
+// grpcEmitters are the gRPC client emitters by language
+var grpcEmitters = map[string]string{
+	"go":     "grpc-go",
+	"python": "pytest-grpc",
+}
+
+// emitGRPCTests writes the gRPC specs among specs to their own
+// <level>_grpc test file and returns the rest. gRPC specs for a language
+// without a gRPC emitter are dropped with a warning.
+func (r *RunnerV2) emitGRPCTests(specs []model.TestSpec, level model.TestLevel) []model.TestSpec {
+	var grpcSpecs, rest []model.TestSpec
+	for _, spec := range specs {
+		if spec.GRPC != nil {
+			grpcSpecs = append(grpcSpecs, spec)
+		} else {
+			rest = append(rest, spec)
+		}
+	}
+	if len(grpcSpecs) == 0 {
+		return specs
+	}
+
+	name, ok := grpcEmitters[r.ws.Language]
+	if !ok {
+		log.Warn().Str("language", r.ws.Language).Int("specs", len(grpcSpecs)).Msg("no gRPC emitter for language, skipping gRPC tests")
+		return rest
+	}
+	em, err := r.emitters.Get(name)
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to get gRPC emitter")
+		return rest
+	}
+	emitter.SetTimeout(em, r.testTimeout())
+	code, err := em.Emit(grpcSpecs)
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to emit gRPC tests")
+		return rest
+	}
+
+	testDir := filepath.Join(r.ws.RepoPath, "tests")
+	if r.cfg.TestDir != "" {
+		testDir = filepath.Join(r.ws.RepoPath, r.cfg.TestDir)
+	}
+	if err := os.MkdirAll(testDir, 0755); err != nil {
+		log.Warn().Err(err).Msg("failed to create test directory")
+		return rest
+	}
+	testFile := filepath.Join(testDir, string(level)+"_grpc"+em.FileExtension())
+	if err := os.WriteFile(testFile, []byte(code), 0644); err != nil {
+		log.Warn().Err(err).Msg("failed to write gRPC tests")
+		return rest
+	}
+
+	log.Info().
+		Str("file", testFile).
+		Int("tests", len(grpcSpecs)).
+		Msg("emitted gRPC tests")
+	r.written = append(r.written, testFile)
+
+	if r.cfg.CommitEach && !r.cfg.DryRun {
+		if _, err := r.git.CommitTest(testFile, fmt.Sprintf("%s gRPC tests", level)); err != nil {
+			log.Warn().Err(err).Msg("failed to commit tests")
+		}
+	}
+	if r.OnComplete != nil {
+		r.OnComplete(testFile, len(grpcSpecs))
+	}
+	return rest
+}
+
+// emitGoUnitTests writes Go unit specs as tests that call their function
+// or method directly, in a _test.go file beside its source where go test
+// finds it. It returns the specs whose target it couldn't find.
+func (r *RunnerV2) emitGoUnitTests(specs []model.TestSpec, level model.TestLevel) []model.TestSpec {
+	if r.ws.Language != "go" || level != model.LevelUnit || r.sysModel == nil {
+		return specs
+	}
+
+	functions := make(map[string]model.Function, len(r.sysModel.Functions))
+	for _, fn := range r.sysModel.Functions {
+		functions[fn.ID] = fn
+	}
+	// sourceFile finds the source file of a spec's function
+	sourceFile := func(spec model.TestSpec) (string, bool) {
+		fn, ok := functions[spec.TargetID]
+		if !ok || fn.File == "" {
+			return "", false
+		}
+		file := fn.File
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(r.ws.RepoPath, file)
+		}
+		return file, true
+	}
+
+	var rest []model.TestSpec
+	touched := make(map[string]bool)
+	for _, spec := range specs {
+		if file, ok := sourceFile(spec); ok {
+			touched[file] = true
+		} else {
+			rest = append(rest, spec)
+		}
+	}
+	if len(touched) == 0 {
+		return specs
+	}
+
+	// Each file's tests are generated from all its specs, so new specs add
+	// to the tests already written rather than replacing them
+	byFile := make(map[string][]model.TestSpec)
+	for _, spec := range r.specSet.FilterByLevel(level) {
+		file, ok := sourceFile(spec)
+		if !ok || !touched[file] {
+			continue
+		}
+		if spec.FunctionName == "" {
+			fn := functions[spec.TargetID]
+			spec.FunctionName = adapters.TargetName(fn.Class, fn.Name)
+		}
+		byFile[file] = append(byFile[file], spec)
+	}
+
+	// Tests of pure functions run in parallel
+	pure := make(map[string]bool)
+	if r.cfg.ParallelTests {
+		for _, fn := range r.sysModel.Functions {
+			if fn.Pure {
+				pure[fn.ID] = true
+			}
+		}
+	}
+
+	modulePath := goModulePath(r.ws.RepoPath)
+	files := make([]string, 0, len(byFile))
+	for file := range byFile {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	for _, file := range files {
+		fileSpecs := byFile[file]
+		relFile, _ := filepath.Rel(r.ws.RepoPath, file)
+
+		adapter := adapters.NewGoSpecAdapter()
+		adapter.Pure = pure
+		adapter.Chaos = r.cfg.Chaos
+		if modulePath != "" {
+			adapter.ImportPath = path.Join(modulePath, filepath.ToSlash(filepath.Dir(relFile)))
+		}
+		code, err := adapter.GenerateFromSpecs(fileSpecs, file)
+		if err != nil {
+			log.Warn().Err(err).Str("file", relFile).Msg("failed to generate unit tests")
+			continue
+		}
+
+		targets := make([]string, 0, len(fileSpecs))
+		seen := make(map[string]bool)
+		for _, spec := range fileSpecs {
+			if !seen[spec.FunctionName] {
+				seen[spec.FunctionName] = true
+				targets = append(targets, spec.FunctionName)
+			}
+		}
+		testFile := strings.TrimSuffix(file, ".go") + "_test.go"
+		written, err := adapters.WriteGeneratedFile(testFile, code, adapters.Provenance{
+			RunID:        r.ws.ID,
+			SourceCommit: r.ws.CommitSHA,
+			Source:       relFile,
+			Targets:      targets,
+		}, adapters.WriteOptions{})
+		if err != nil {
+			log.Warn().Err(err).Str("file", testFile).Msg("failed to write unit tests")
+			continue
+		}
+		if written.MergePath != "" {
+			log.Warn().Str("file", testFile).Str("merge", written.MergePath).Int("conflicts", written.Conflicts).
+				Msg("unit tests were edited by hand, regenerated tests merged into proposal")
+			continue
+		}
+
+		log.Info().
+			Str("file", written.Path).
+			Int("tests", len(fileSpecs)).
+			Msg("emitted unit tests")
+		r.written = append(r.written, written.Path)
+
+		if r.cfg.CommitEach && !r.cfg.DryRun {
+			if _, err := r.git.CommitTest(written.Path, fmt.Sprintf("unit tests for %s", relFile)); err != nil {
+				log.Warn().Err(err).Msg("failed to commit tests")
+			}
+		}
+		if r.OnComplete != nil {
+			r.OnComplete(written.Path, len(fileSpecs))
+		}
+	}
+	return rest
+}
+
+// testTimeout returns the deadline for each test's API calls in whole
+// seconds, rounded up
+func (r *RunnerV2) testTimeout() int {
+	return int((r.cfg.TestTimeout + time.Second - 1) / time.Second)
+}
+
+// testFile returns the file a level's tests go in. JUnit tests go in the
+// package of the application under src/test/java, where Maven and Gradle
+// run them, and the project gets the test dependencies it lacks.
+func (r *RunnerV2) testFile(em emitter.Emitter, level model.TestLevel) string {
+	testDir := filepath.Join(r.ws.RepoPath, "tests")
+	if r.cfg.TestDir != "" {
+		testDir = filepath.Join(r.ws.RepoPath, r.cfg.TestDir)
+	}
+
+	junit, ok := em.(*emitter.JUnitEmitter)
+	if !ok {
+		return filepath.Join(testDir, string(level)+em.FileExtension())
+	}
+
+	junit.ClassName = strings.ToUpper(string(level[:1])) + string(level[1:]) + "Test"
+	if project := buildsys.DetectJava(r.ws.RepoPath); project != nil {
+		if r.cfg.TestDir == "" {
+			testDir = project.TestDir()
+		}
+		if added, err := project.AddTestDependencies(); err != nil {
+			log.Warn().Err(err).Msg("failed to add test dependencies")
+		} else if len(added) > 0 {
+			log.Info().Str("build_file", project.BuildFile).Strs("added", added).Msg("added test dependencies")
+		}
+	}
+	if pkg, ok := buildsys.JavaTestPackage(testDir); ok && pkg != "" {
+		junit.Package = pkg
+	}
+	return filepath.Join(testDir, junit.FileName())
+}
+
+// appendJavaTests adds the test methods of a generated JUnit class to the
+// end of an existing one
+func appendJavaTests(existing, code string) string {
+	start := strings.Index(code, "private MockMvc mockMvc;")
+	end := strings.LastIndex(code, "}")
+	closing := strings.LastIndex(existing, "}")
+	if start < 0 || end < start || closing < 0 {
+		return existing
+	}
+	methods := strings.TrimSpace(code[start+len("private MockMvc mockMvc;") : end])
+	if methods == "" {
+		return existing
+	}
+	head := strings.TrimRight(existing[:closing], " \t\n")
+	return head + "\n\n    // === New tests added incrementally ===\n\n    " + methods + "\n" + existing[closing:]
+}
+
+// useProjectAssertions sets em's assertion library from .qtest.yaml, or
+// from the repo's existing tests when it doesn't choose one
+func useProjectAssertions(em emitter.Emitter, repoPath string) {
+	lib := ""
+	if projectCfg, err := config.LoadProjectConfig(repoPath); err == nil {
+		lib = projectCfg.Framework.Assertions[em.Language()]
+	}
+	if lib == "" {
+		lib = emitter.DetectAssertionLibrary(repoPath, em.Language())
+	}
+	if err := emitter.SetAssertionLibrary(em, lib); err != nil {
+		log.Warn().Err(err).Msg("ignoring configured assertion library")
+	}
+}
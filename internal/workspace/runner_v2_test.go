@@ -0,0 +1,95 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/QTest-hq/qtest/internal/emitter"
+	"github.com/QTest-hq/qtest/pkg/model"
+)
+
+func TestAppendJavaTests(t *testing.T) {
+	em := &emitter.JUnitEmitter{Package: "com.acme", ClassName: "ApiTest"}
+	existing, _ := em.Emit([]model.TestSpec{{Level: model.LevelAPI, Method: "GET", Path: "/orders"}})
+	code, _ := em.Emit([]model.TestSpec{{Level: model.LevelAPI, Method: "POST", Path: "/orders"}})
+
+	got := appendJavaTests(existing, code)
+	if strings.Count(got, "public class ApiTest") != 1 || strings.Count(got, "private MockMvc mockMvc;") != 1 {
+		t.Fatalf("appendJavaTests() should merge into one class:\n%s", got)
+	}
+	if !strings.Contains(got, "testGet_orders") || !strings.Contains(got, "testPost_orders") {
+		t.Errorf("appendJavaTests() lost a test:\n%s", got)
+	}
+	if !strings.HasSuffix(strings.TrimSpace(got), "}") || strings.Index(got, "testPost_orders") > strings.LastIndex(got, "}") {
+		t.Errorf("new tests should be inside the class:\n%s", got)
+	}
+}
+
+func TestEmitGoUnitTests(t *testing.T) {
+	repo := t.TempDir()
+	os.WriteFile(filepath.Join(repo, "go.mod"), []byte("module example.com/shop\n\ngo 1.21\n"), 0644)
+	os.MkdirAll(filepath.Join(repo, "pricing"), 0755)
+	os.WriteFile(filepath.Join(repo, "pricing", "discount.go"), []byte(`package pricing
+
+func Discount(total int) int { return total / 10 }
+
+type Cart struct{ Items []int }
+
+func (c *Cart) Total() int { return 0 }
+`), 0644)
+
+	discount := model.Function{ID: "pricing/discount.go:3:Discount", Name: "Discount", File: "pricing/discount.go", Exported: true, Pure: true}
+	method := model.Function{ID: "pricing/discount.go:7:Total", Name: "Total", File: "pricing/discount.go", Class: "Cart", Exported: true}
+	specs := []model.TestSpec{
+		{
+			TargetID:    discount.ID,
+			Level:       model.LevelUnit,
+			Description: "takes ten percent off",
+			Inputs:      map[string]interface{}{"total": float64(100)},
+			ArgOrder:    []string{"total"},
+			Assertions:  []model.Assertion{{Kind: "equality", Actual: "result", Expected: float64(10)}},
+		},
+		{TargetID: method.ID, Level: model.LevelUnit, Description: "sums the cart"},
+		{TargetID: "pricing/gone.go:1:Gone", Level: model.LevelUnit, Description: "was removed"},
+	}
+	r := &RunnerV2{
+		ws:       &Workspace{RepoPath: repo, Language: "go"},
+		cfg:      &RunConfig{ParallelTests: true},
+		sysModel: &model.SystemModel{Functions: []model.Function{discount, method}},
+		specSet:  &model.TestSpecSet{Specs: specs},
+	}
+
+	rest := r.emitGoUnitTests(specs, model.LevelUnit)
+	if len(rest) != 1 || rest[0].TargetID != "pricing/gone.go:1:Gone" {
+		t.Errorf("emitGoUnitTests() should leave the spec of an unknown function, got %+v", rest)
+	}
+	testFile := filepath.Join(repo, "pricing", "discount_test.go")
+	data, err := os.ReadFile(testFile)
+	if err != nil {
+		t.Fatalf("expected %s to be written: %v", testFile, err)
+	}
+	code := string(data)
+	for _, want := range []string{
+		"package pricing_test", `"example.com/shop/pricing"`, "result := pricing.Discount(total)",
+		"func TestCart_Total(t *testing.T) {", "cart := &pricing.Cart{}", "result := cart.Total()",
+		"func TestDiscount(t *testing.T) {\n\tt.Parallel()",
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("generated tests missing %q\n%s", want, code)
+		}
+	}
+	// Only the pure function's tests run in parallel
+	if n := strings.Count(code, "t.Parallel()"); n != 2 {
+		t.Errorf("t.Parallel() called %d times, want 2 for Discount and its case\n%s", n, code)
+	}
+	if len(r.written) != 1 || r.written[0] != testFile {
+		t.Errorf("written = %v, want [%s]", r.written, testFile)
+	}
+
+	// Other languages and levels are left to the HTTP emitters
+	if got := r.emitGoUnitTests(specs, model.LevelAPI); len(got) != len(specs) {
+		t.Errorf("emitGoUnitTests() at the API level should emit nothing, left %d of %d", len(got), len(specs))
+	}
+}
@@ -0,0 +1,244 @@
+package workspace
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/QTest-hq/qtest/internal/adapters"
+	"github.com/QTest-hq/qtest/internal/parser"
+	"github.com/rs/zerolog/log"
+)
+
+// Line coverage statuses in a source view
+const (
+	LineCovered   = "covered"
+	LineUncovered = "uncovered"
+	LinePartial   = "partial" // some of the line's statements ran
+)
+
+// SourceView is the source annotated with coverage: each file's lines,
+// whether tests ran them and which generated tests exercise them
+type SourceView struct {
+	Version     string           `json:"version"`
+	GeneratedAt time.Time        `json:"generated_at"`
+	Tests       []SourceViewTest `json:"tests"` // lines refer to these by index
+	Files       []SourceFileView `json:"files"`
+}
+
+// SourceViewTest is a generated test file and a function it tests
+type SourceViewTest struct {
+	File   string `json:"file"`   // relative to the repository
+	Target string `json:"target"` // Class.Method for methods
+}
+
+// SourceFileView is one annotated source file
+type SourceFileView struct {
+	Path            string       `json:"path"`
+	CoveragePercent float64      `json:"coverage_percent"`
+	Lines           []SourceLine `json:"lines"`
+}
+
+// SourceLine is a line of source with its coverage
+type SourceLine struct {
+	Number    int    `json:"number"`
+	Text      string `json:"text"`
+	Status    string `json:"status,omitempty"`     // empty for lines without statements, or when unknown
+	CoveredBy []int  `json:"covered_by,omitempty"` // indexes into SourceView.Tests
+}
+
+// BuildSourceView annotates the files of a coverage report with it.
+// Generated tests are found by their provenance headers. A line that ran
+// is credited to the generated tests of the functions containing it; other
+// tests may have run it too.
+func BuildSourceView(repoPath string, report *CoverageReport) (*SourceView, error) {
+	view := &SourceView{
+		Version:     "1.0",
+		GeneratedAt: time.Now(),
+		Tests:       []SourceViewTest{},
+		Files:       []SourceFileView{},
+	}
+
+	// Generated tests by source file and target
+	tested, err := findGeneratedTests(repoPath, view)
+	if err != nil {
+		return nil, err
+	}
+
+	p := parser.NewParser()
+	for _, f := range sortedFiles(report.Files) {
+		content, err := os.ReadFile(filepath.Join(repoPath, f.Path))
+		if err != nil {
+			log.Debug().Err(err).Str("file", f.Path).Msg("skipping source view of unreadable file")
+			continue
+		}
+
+		hit := lineSet(f.HitLines)
+		missed := lineSet(f.UncoveredLines)
+		spans := functionSpans(p, f.Path, string(content))
+		tests := tested[toSlash(f.Path)]
+
+		file := SourceFileView{Path: toSlash(f.Path), CoveragePercent: f.CoveragePercent}
+		for i, text := range strings.Split(strings.TrimSuffix(string(content), "\n"), "\n") {
+			line := SourceLine{Number: i + 1, Text: strings.TrimSuffix(text, "\r")}
+			switch {
+			case hit[line.Number] && missed[line.Number]:
+				line.Status = LinePartial
+			case hit[line.Number]:
+				line.Status = LineCovered
+			case missed[line.Number]:
+				line.Status = LineUncovered
+			}
+			if line.Status == LineCovered || line.Status == LinePartial {
+				line.CoveredBy = coveringTests(line.Number, spans, tests)
+			}
+			file.Lines = append(file.Lines, line)
+		}
+		view.Files = append(view.Files, file)
+	}
+	return view, nil
+}
+
+// findGeneratedTests adds the generated tests under repoPath to the view,
+// returning their indexes by source file and target
+func findGeneratedTests(repoPath string, view *SourceView) (map[string]map[string][]int, error) {
+	tested := make(map[string]map[string][]int)
+	err := filepath.Walk(repoPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() {
+			name := info.Name()
+			if path != repoPath && (strings.HasPrefix(name, ".") || name == "node_modules" || name == "vendor" || name == "__pycache__") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if parser.DetectLanguage(path) == parser.LanguageUnknown {
+			return nil
+		}
+		_, prov, err := adapters.FileOwnership(path)
+		if err != nil || prov == nil || prov.Source == "" {
+			return nil
+		}
+
+		testFile, _ := filepath.Rel(repoPath, path)
+		source := prov.Source
+		if filepath.IsAbs(source) {
+			source, _ = filepath.Rel(repoPath, source)
+		}
+		source = toSlash(source)
+		if tested[source] == nil {
+			tested[source] = make(map[string][]int)
+		}
+		for _, target := range prov.Targets {
+			tested[source][target] = append(tested[source][target], len(view.Tests))
+			view.Tests = append(view.Tests, SourceViewTest{File: toSlash(testFile), Target: target})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find generated tests: %w", err)
+	}
+	return tested, nil
+}
+
+// functionSpan is the lines of a function, named as provenance targets are
+type functionSpan struct {
+	name       string
+	start, end int
+}
+
+// functionSpans parses the functions and methods of a source file
+func functionSpans(p *parser.Parser, path, content string) []functionSpan {
+	parsed, err := p.ParseContent(context.Background(), path, content, parser.DetectLanguage(path))
+	if err != nil {
+		return nil
+	}
+	var spans []functionSpan
+	for _, fn := range parsed.Functions {
+		spans = append(spans, functionSpan{adapters.TargetName(fn.Class, fn.Name), fn.StartLine, fn.EndLine})
+	}
+	for _, cls := range parsed.Classes {
+		for _, m := range cls.Methods {
+			spans = append(spans, functionSpan{adapters.TargetName(cls.Name, m.Name), m.StartLine, m.EndLine})
+		}
+	}
+	return spans
+}
+
+// coveringTests returns the generated tests of the functions containing a
+// line
+func coveringTests(line int, spans []functionSpan, tests map[string][]int) []int {
+	var covering []int
+	for _, span := range spans {
+		if line >= span.start && line <= span.end {
+			covering = append(covering, tests[span.name]...)
+		}
+	}
+	return sortedLines(covering)
+}
+
+// lineSet indexes line numbers
+func lineSet(lines []int) map[int]bool {
+	set := make(map[int]bool, len(lines))
+	for _, l := range lines {
+		set[l] = true
+	}
+	return set
+}
+
+// ExportSourceViewHTML renders a source view as a standalone HTML page
+func ExportSourceViewHTML(view *SourceView) ([]byte, error) {
+	tmpl, err := template.New("source-view").Funcs(template.FuncMap{
+		"test": func(i int) SourceViewTest { return view.Tests[i] },
+	}).Parse(sourceViewTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, view); err != nil {
+		return nil, fmt.Errorf("failed to execute template: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+const sourceViewTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>QTest Source Coverage</title>
+<style>
+  body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", sans-serif; margin: 2rem; color: #1f2328; }
+  summary { cursor: pointer; font-weight: 600; padding: 0.4rem 0; }
+  table { border-collapse: collapse; width: 100%; font-family: ui-monospace, SFMono-Regular, Menlo, monospace; font-size: 12px; }
+  td { padding: 0 0.5rem; vertical-align: top; white-space: pre; }
+  td.num { color: #8c959f; text-align: right; user-select: none; }
+  td.tests { color: #57606a; font-family: sans-serif; white-space: normal; }
+  tr.covered td.num { background: #dafbe1; }
+  tr.partial td.num { background: #fff8c5; }
+  tr.uncovered td.num, tr.uncovered td.code { background: #ffebe9; }
+  .pct { color: #57606a; font-weight: normal; }
+  .legend span { padding: 0 0.5rem; margin-right: 0.5rem; }
+</style>
+</head>
+<body>
+<h1>Source Coverage</h1>
+<p class="legend"><span style="background:#dafbe1">covered</span><span style="background:#fff8c5">partly covered</span><span style="background:#ffebe9">not covered</span> Generated at {{.GeneratedAt.Format "2006-01-02 15:04:05"}}</p>
+{{range .Files}}
+<details open>
+<summary>{{.Path}} <span class="pct">{{printf "%.1f" .CoveragePercent}}%</span></summary>
+<table>
+{{range .Lines}}<tr{{with .Status}} class="{{.}}"{{end}}><td class="num">{{.Number}}</td><td class="code">{{.Text}}</td><td class="tests">{{range $i, $t := .CoveredBy}}{{if $i}}, {{end}}{{with test $t}}<span title="tests {{.Target}}">{{.File}}</span>{{end}}{{end}}</td></tr>
+{{end}}</table>
+</details>
+{{end}}
+</body>
+</html>
+`
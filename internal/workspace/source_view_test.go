@@ -0,0 +1,90 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/QTest-hq/qtest/internal/adapters"
+)
+
+func TestBuildSourceView(t *testing.T) {
+	repo := t.TempDir()
+	os.MkdirAll(filepath.Join(repo, "pricing"), 0755)
+	os.WriteFile(filepath.Join(repo, "pricing", "discount.go"), []byte(`package pricing
+
+func Discount(total int) int {
+	if total < 0 {
+		return 0
+	}
+	return total / 10
+}
+
+func Tax(total int) int { return total / 5 }
+`), 0644)
+	_, err := adapters.WriteGeneratedFile(filepath.Join(repo, "pricing", "discount_test.go"), "package pricing\n", adapters.Provenance{
+		Source:  "pricing/discount.go",
+		Targets: []string{"Discount"},
+	}, adapters.WriteOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := &CoverageReport{Files: []FileCoverage{{
+		Path:            "pricing/discount.go",
+		CoveragePercent: 60,
+		HitLines:        []int{3, 4, 7, 10},
+		UncoveredLines:  []int{5, 10},
+	}}}
+	view, err := BuildSourceView(repo, report)
+	if err != nil {
+		t.Fatalf("BuildSourceView() error = %v", err)
+	}
+	if len(view.Tests) != 1 || view.Tests[0] != (SourceViewTest{File: "pricing/discount_test.go", Target: "Discount"}) {
+		t.Fatalf("Tests = %+v, want the generated test of Discount", view.Tests)
+	}
+	if len(view.Files) != 1 || len(view.Files[0].Lines) != 10 {
+		t.Fatalf("Files = %+v, want discount.go's 10 lines", view.Files)
+	}
+
+	lines := view.Files[0].Lines
+	for _, tt := range []struct {
+		number    int
+		status    string
+		generated bool
+	}{
+		{1, "", false},
+		{4, LineCovered, true},
+		{5, LineUncovered, false},
+		{7, LineCovered, true},
+		{10, LinePartial, false}, // Tax has no generated test
+	} {
+		line := lines[tt.number-1]
+		if line.Status != tt.status {
+			t.Errorf("line %d status = %q, want %q", tt.number, line.Status, tt.status)
+		}
+		if got := len(line.CoveredBy) > 0; got != tt.generated {
+			t.Errorf("line %d covered by %v, want generated tests: %v", tt.number, line.CoveredBy, tt.generated)
+		}
+	}
+	if lines[3].Text != "\tif total < 0 {" {
+		t.Errorf("line 4 text = %q", lines[3].Text)
+	}
+
+	page, err := ExportSourceViewHTML(view)
+	if err != nil {
+		t.Fatalf("ExportSourceViewHTML() error = %v", err)
+	}
+	html := string(page)
+	for _, want := range []string{
+		"<summary>pricing/discount.go",
+		`<tr class="uncovered"><td class="num">5</td>`,
+		`<span title="tests Discount">pricing/discount_test.go</span>`,
+		"if total &lt; 0 {",
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("HTML missing %q", want)
+		}
+	}
+}
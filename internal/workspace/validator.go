@@ -12,6 +12,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/QTest-hq/qtest/internal/platform"
 	"github.com/rs/zerolog/log"
 )
 
@@ -106,6 +107,8 @@ func (v *TestValidator) ValidateTest(ctx context.Context, target *TargetState) V
 		cmd = v.pythonTestCommand(ctx, target.TestFile)
 	case ".js", ".ts":
 		cmd = v.jestTestCommand(ctx, target.TestFile)
+	case ".rs":
+		cmd = v.cargoTestCommand(ctx, target.TestFile)
 	default:
 		result.Error = fmt.Sprintf("unsupported test file type: %s", ext)
 		return result
@@ -115,7 +118,9 @@ func (v *TestValidator) ValidateTest(ctx context.Context, target *TargetState) V
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
-	cmd.Dir = v.ws.RepoPath
+	if cmd.Dir == "" {
+		cmd.Dir = v.ws.RepoPath
+	}
 
 	err := cmd.Run()
 	result.Duration = time.Since(startTime)
@@ -146,7 +151,7 @@ func (v *TestValidator) goTestCommand(ctx context.Context, testFile string) *exe
 	// Get the package path from the test file
 	dir := filepath.Dir(testFile)
 	relDir, _ := filepath.Rel(v.ws.RepoPath, dir)
-	pkg := "./" + relDir
+	pkg := "./" + filepath.ToSlash(relDir)
 
 	return exec.CommandContext(ctx, "go", "test", "-v", "-json", pkg, "-run", extractTestName(testFile))
 }
@@ -160,7 +165,16 @@ func (v *TestValidator) pythonTestCommand(ctx context.Context, testFile string)
 // jestTestCommand creates a command to run Jest tests
 func (v *TestValidator) jestTestCommand(ctx context.Context, testFile string) *exec.Cmd {
 	relPath, _ := filepath.Rel(v.ws.RepoPath, testFile)
-	return exec.CommandContext(ctx, "npx", "jest", "--verbose", relPath)
+	return platform.Command(ctx, "npx", "jest", "--verbose", filepath.ToSlash(relPath))
+}
+
+// cargoTestCommand creates a command to run a Rust integration test from
+// its crate, the parent of the tests/ directory
+func (v *TestValidator) cargoTestCommand(ctx context.Context, testFile string) *exec.Cmd {
+	name := strings.TrimSuffix(filepath.Base(testFile), ".rs")
+	cmd := exec.CommandContext(ctx, "cargo", "test", "--test", name)
+	cmd.Dir = filepath.Dir(filepath.Dir(testFile))
+	return cmd
 }
 
 // parseTestOutput parses test output to extract counts
@@ -172,6 +186,8 @@ func (v *TestValidator) parseTestOutput(ext string, output string, result *Valid
 		v.parsePytestOutput(output, result)
 	case ".js", ".ts":
 		v.parseJestOutput(output, result)
+	case ".rs":
+		v.parseCargoTestOutput(output, result)
 	}
 }
 
@@ -235,6 +251,22 @@ func (v *TestValidator) parsePytestOutput(output string, result *ValidationResul
 	result.TestCount = result.PassCount + result.FailCount + result.SkipCount
 }
 
+// parseCargoTestOutput parses cargo test output, which has a summary like
+// "test result: ok. 5 passed; 0 failed; 1 ignored; ..." per test binary
+func (v *TestValidator) parseCargoTestOutput(output string, result *ValidationResult) {
+	re := regexp.MustCompile(`test result: \w+\. (\d+) passed; (\d+) failed; (\d+) ignored`)
+	for _, matches := range re.FindAllStringSubmatch(output, -1) {
+		passed, _ := strconv.Atoi(matches[1])
+		failed, _ := strconv.Atoi(matches[2])
+		ignored, _ := strconv.Atoi(matches[3])
+		result.PassCount += passed
+		result.FailCount += failed
+		result.SkipCount += ignored
+	}
+
+	result.TestCount = result.PassCount + result.FailCount + result.SkipCount
+}
+
 // parseJestOutput parses Jest output
 func (v *TestValidator) parseJestOutput(output string, result *ValidationResult) {
 	// Look for summary like "Tests: 5 passed, 2 failed, 7 total"
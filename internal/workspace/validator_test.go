@@ -189,3 +189,22 @@ func TestExtractTestName(t *testing.T) {
 		})
 	}
 }
+
+func TestParseCargoTestOutput(t *testing.T) {
+	output := `running 2 tests
+test adds ... ok
+test overflows ... FAILED
+
+test result: FAILED. 1 passed; 1 failed; 1 ignored; 0 measured; 0 filtered out; finished in 0.00s
+
+running 3 tests
+test result: ok. 3 passed; 0 failed; 0 ignored; 0 measured; 0 filtered out; finished in 0.00s
+`
+	var result ValidationResult
+	(&TestValidator{}).parseCargoTestOutput(output, &result)
+
+	if result.PassCount != 4 || result.FailCount != 1 || result.SkipCount != 1 || result.TestCount != 6 {
+		t.Errorf("counts = %d passed, %d failed, %d skipped, %d total; want 4, 1, 1, 6",
+			result.PassCount, result.FailCount, result.SkipCount, result.TestCount)
+	}
+}
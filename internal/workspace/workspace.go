@@ -44,6 +44,10 @@ type WorkspaceState struct {
 	CurrentIndex int                     `json:"current_index"`
 	StartedAt    *time.Time              `json:"started_at,omitempty"`
 	PausedAt     *time.Time              `json:"paused_at,omitempty"`
+
+	// Files maps source files, relative to the repository, to the content
+	// hash tests were last generated for
+	Files map[string]string `json:"files,omitempty"`
 }
 
 // Phase represents the current phase of generation
@@ -65,7 +69,8 @@ type TargetState struct {
 	ID          string          `json:"id"` // Unique ID: file:line:name
 	File        string          `json:"file"`
 	Name        string          `json:"name"`
-	Type        string          `json:"type"` // function, method, class
+	Class       string          `json:"class,omitempty"` // Receiver or class, for methods
+	Type        string          `json:"type"`            // function, method, class
 	Line        int             `json:"line"`
 	Status      TargetStatus    `json:"status"`
 	Covered     bool            `json:"covered"`           // Whether tests have been generated
@@ -75,6 +80,9 @@ type TargetState struct {
 	Error       string          `json:"error,omitempty"`
 	GeneratedAt *time.Time      `json:"generated_at,omitempty"`
 	DSL         json.RawMessage `json:"dsl,omitempty"`
+
+	// Fingerprint identifies the function across renames and moves
+	Fingerprint *Fingerprint `json:"fingerprint,omitempty"`
 }
 
 // TargetStatus represents the status of a target
@@ -196,31 +204,60 @@ func (ws *Workspace) SetPhase(phase Phase) {
 	ws.State.Phase = phase
 }
 
-// AddTargets adds functions/methods to be processed
-func (ws *Workspace) AddTargets(functions []parser.Function, filePath string) {
+// AddTargets adds functions/methods to be processed and returns their IDs.
+// Targets that already exist keep their state, so re-parsing a workspace
+// doesn't discard generated tests.
+func (ws *Workspace) AddTargets(functions []parser.Function, filePath string) []string {
+	return ws.addTargets(functions, filePath, nil)
+}
+
+// addTargets adds targets, taking their fingerprints from fingerprints
+// when present rather than computing them
+func (ws *Workspace) addTargets(functions []parser.Function, filePath string, fingerprints map[string]*Fingerprint) []string {
 	ws.mu.Lock()
 	defer ws.mu.Unlock()
 
+	var ids []string
 	for _, fn := range functions {
 		if !fn.Exported {
 			continue // Skip private functions
 		}
 
-		id := fn.ID
-		if id == "" {
-			id = fmt.Sprintf("%s:%d:%s", filePath, fn.StartLine, fn.Name)
+		id := targetID(fn, filePath)
+		ids = append(ids, id)
+
+		fp := fingerprints[id]
+		if fp == nil {
+			fp = fingerprintFunction(fn)
+		}
+
+		if existing, ok := ws.State.Targets[id]; ok {
+			existing.Fingerprint = fp
+			existing.Class = fn.Class
+			continue
 		}
 
 		ws.State.Targets[id] = &TargetState{
-			ID:     id,
-			File:   filePath,
-			Name:   fn.Name,
-			Type:   "function",
-			Line:   fn.StartLine,
-			Status: StatusPending,
+			ID:          id,
+			File:        filePath,
+			Name:        fn.Name,
+			Class:       fn.Class,
+			Type:        "function",
+			Line:        fn.StartLine,
+			Status:      StatusPending,
+			Fingerprint: fp,
 		}
 		ws.State.TotalTargets++
 	}
+	return ids
+}
+
+// targetID returns a function's target ID: file:line:name
+func targetID(fn parser.Function, filePath string) string {
+	if fn.ID != "" {
+		return fn.ID
+	}
+	return fmt.Sprintf("%s:%d:%s", filePath, fn.StartLine, fn.Name)
 }
 
 // GetNextTarget returns the next pending target
@@ -282,6 +319,38 @@ func (ws *Workspace) UpdateTargetCovered(id string, specID string) {
 	target.GeneratedAt = &now
 }
 
+// FileChanged reports whether a source file's content differs from when
+// tests were last generated for it, returning its current hash
+func (ws *Workspace) FileChanged(path string) (string, bool, error) {
+	hash, err := hashFile(path)
+	if err != nil {
+		return "", false, err
+	}
+
+	ws.mu.RLock()
+	defer ws.mu.RUnlock()
+	return hash, ws.State.Files[relToRepo(ws.RepoPath, path)] != hash, nil
+}
+
+// HasFile reports whether a hash has been recorded for a source file
+func (ws *Workspace) HasFile(path string) bool {
+	ws.mu.RLock()
+	defer ws.mu.RUnlock()
+	_, ok := ws.State.Files[relToRepo(ws.RepoPath, path)]
+	return ok
+}
+
+// MarkFileGenerated records the content hash tests were generated for
+func (ws *Workspace) MarkFileGenerated(path, hash string) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	if ws.State.Files == nil {
+		ws.State.Files = make(map[string]string)
+	}
+	ws.State.Files[relToRepo(ws.RepoPath, path)] = hash
+}
+
 // Progress returns current progress as a percentage
 func (ws *Workspace) Progress() float64 {
 	ws.mu.RLock()
@@ -594,3 +594,43 @@ func contains(s, substr string) bool {
 	}
 	return false
 }
+
+func TestWorkspace_FileChanged(t *testing.T) {
+	repo := t.TempDir()
+	ws := &Workspace{RepoPath: repo, State: &WorkspaceState{}}
+
+	path := filepath.Join(repo, "pkg", "calc.go")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("package pkg"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	hash, changed, err := ws.FileChanged(path)
+	if err != nil {
+		t.Fatalf("FileChanged() error: %v", err)
+	}
+	if !changed || ws.HasFile(path) {
+		t.Error("a file never generated for should be changed and unknown")
+	}
+
+	ws.MarkFileGenerated(path, hash)
+	if ws.State.Files["pkg/calc.go"] != hash {
+		t.Errorf("Files = %v, want pkg/calc.go recorded relative to the repo", ws.State.Files)
+	}
+	if _, changed, _ := ws.FileChanged(path); changed {
+		t.Error("unchanged file reported as changed")
+	}
+
+	if err := os.WriteFile(path, []byte("package pkg\n\nfunc Add() {}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, changed, _ := ws.FileChanged(path); !changed {
+		t.Error("edited file not reported as changed")
+	}
+
+	if _, _, err := ws.FileChanged(filepath.Join(repo, "missing.go")); err == nil {
+		t.Error("expected error for a missing file")
+	}
+}
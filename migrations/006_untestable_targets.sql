@@ -0,0 +1,33 @@
+-- Migration 006: Track targets that generation keeps failing for
+-- A row per function; the dashboard lists them, and once the
+-- failure count passes the configured threshold a GitHub issue can be opened.
+
+CREATE TABLE IF NOT EXISTS untestable_targets (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    repository_id UUID NOT NULL REFERENCES repositories(id) ON DELETE CASCADE,
+    file_path TEXT NOT NULL,
+    function_name TEXT NOT NULL,
+
+    -- Failures since the last successful generation
+    failure_count INTEGER NOT NULL DEFAULT 0,
+    last_reason TEXT,
+    findings JSONB DEFAULT '[]'::jsonb,  -- testability issues found in the source
+
+    status TEXT NOT NULL DEFAULT 'failing',  -- 'failing', 'reported', 'resolved'
+
+    -- Issue opened for the target, if any
+    issue_number INTEGER,
+    issue_url TEXT,
+
+    first_failed_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    last_failed_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT unique_untestable_target UNIQUE (repository_id, file_path, function_name),
+    CONSTRAINT valid_untestable_status CHECK (status IN ('failing', 'reported', 'resolved'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_untestable_targets_repo ON untestable_targets(repository_id);
+CREATE INDEX IF NOT EXISTS idx_untestable_targets_status ON untestable_targets(status);
+
+COMMENT ON TABLE untestable_targets IS 'Functions test generation repeatedly failed for, with refactoring hints';
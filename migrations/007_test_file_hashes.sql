@@ -0,0 +1,10 @@
+-- Migration 007: Remember what QTest wrote to each test file
+-- With the written content and its hash, regeneration can tell when a user
+-- edited a generated test (even if they dropped its provenance header) and
+-- merge instead of overwriting it.
+
+ALTER TABLE generated_tests
+ADD COLUMN IF NOT EXISTS test_file TEXT,      -- path relative to the repository root
+ADD COLUMN IF NOT EXISTS content_hash TEXT;   -- hash of generated_code without its header
+
+CREATE INDEX IF NOT EXISTS idx_generated_tests_test_file ON generated_tests(test_file);
@@ -0,0 +1,6 @@
+-- Migration 008: Look up generation history by file
+-- The file history API lists every test generated for a source file (or
+-- written to a test file, already indexed by 007).
+
+CREATE INDEX IF NOT EXISTS idx_generated_tests_target_file ON generated_tests(target_file, target_function);
+CREATE INDEX IF NOT EXISTS idx_mutation_runs_source_file ON mutation_runs(repository_id, source_file);
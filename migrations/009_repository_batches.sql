@@ -0,0 +1,25 @@
+-- Migration 009: Batch repository submission
+-- Platform teams onboard many repositories at once with shared pipeline
+-- options; each batch records what happened to every submitted URL and the
+-- root job of the pipeline started for it.
+
+CREATE TABLE IF NOT EXISTS repository_batches (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    options JSONB NOT NULL DEFAULT '{}',  -- Pipeline options shared by every repository
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS repository_batch_items (
+    batch_id UUID NOT NULL REFERENCES repository_batches(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,  -- Index in the submitted list
+    url TEXT NOT NULL,
+    repository_id UUID REFERENCES repositories(id) ON DELETE SET NULL,
+    job_id UUID REFERENCES jobs(id) ON DELETE SET NULL,  -- Root of the pipeline's job chain
+    outcome TEXT NOT NULL,  -- 'queued', 'duplicate', 'already_running', 'invalid', 'failed'
+    error TEXT,
+
+    PRIMARY KEY (batch_id, position),
+    CONSTRAINT valid_batch_outcome CHECK (outcome IN ('queued', 'duplicate', 'already_running', 'invalid', 'failed'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_repository_batches_created ON repository_batches(created_at DESC);
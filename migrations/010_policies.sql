@@ -0,0 +1,10 @@
+-- Migration 010: Organization default policies
+-- Pipeline defaults (LLM tier, coverage target, allowed LLM providers, PR
+-- options) set once per organization and inherited by its repositories,
+-- which can override any of them.
+
+ALTER TABLE organizations
+ADD COLUMN IF NOT EXISTS policy JSONB NOT NULL DEFAULT '{}'::jsonb;
+
+ALTER TABLE repositories
+ADD COLUMN IF NOT EXISTS policy JSONB NOT NULL DEFAULT '{}'::jsonb;
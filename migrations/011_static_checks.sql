@@ -0,0 +1,10 @@
+-- Migration 011: Static checks of generated tests
+-- Each generated test is compiled or type-checked (go vet, py_compile,
+-- tsc --noEmit) before it's kept. The outcome is recorded so tests can be
+-- filtered by it.
+
+ALTER TABLE generated_tests
+ADD COLUMN IF NOT EXISTS static_check VARCHAR(20),    -- passed, repaired, failed or skipped
+ADD COLUMN IF NOT EXISTS static_check_output TEXT;    -- the checker's errors, when it failed
+
+CREATE INDEX IF NOT EXISTS idx_generated_tests_static_check ON generated_tests(static_check);
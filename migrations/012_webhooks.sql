@@ -0,0 +1,16 @@
+-- Migration 012: Repository webhooks
+-- URLs notified with a signed POST when a repository's ingestion,
+-- generation or mutation jobs complete or fail.
+
+CREATE TABLE IF NOT EXISTS repository_webhooks (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    repository_id UUID NOT NULL REFERENCES repositories(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    secret TEXT NOT NULL,                          -- HMAC-SHA256 key for the X-QTest-Signature header
+    events TEXT[] NOT NULL DEFAULT '{}',           -- e.g. 'generation.completed'; empty for all
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_repository_webhooks_repo ON repository_webhooks(repository_id);
+
+COMMENT ON TABLE repository_webhooks IS 'URLs notified when a repository''s jobs complete or fail';
@@ -0,0 +1,6 @@
+-- Migration 013: Job concurrency limits
+-- Workers count a repository's and a lane's running jobs before claiming
+-- one, when REPOSITORY_MAX_CONCURRENCY or LANE_*_CONCURRENCY is set.
+
+CREATE INDEX IF NOT EXISTS idx_jobs_running_repository ON jobs(repository_id) WHERE status = 'running';
+CREATE INDEX IF NOT EXISTS idx_jobs_running_priority ON jobs(priority) WHERE status = 'running';
@@ -0,0 +1,27 @@
+-- Migration 014: Recurring pipeline runs
+-- A repository's schedules re-run its pipeline on a cron expression, e.g.
+-- nightly. Scheduled runs only generate tests for targets without one, so a
+-- PR is only opened when new untested code turned up.
+
+CREATE TABLE IF NOT EXISTS repository_schedules (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    repository_id UUID NOT NULL REFERENCES repositories(id) ON DELETE CASCADE,
+    cron TEXT NOT NULL,                   -- five fields or a macro such as @daily
+    timezone TEXT NOT NULL DEFAULT 'UTC', -- the cron expression's time zone
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    options JSONB NOT NULL DEFAULT '{}',  -- pipeline options, as POST /jobs/pipeline takes them
+    next_run_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    last_run_at TIMESTAMP WITH TIME ZONE,
+    last_job_id UUID REFERENCES jobs(id) ON DELETE SET NULL,  -- root of the last pipeline started
+    last_outcome TEXT,                    -- 'queued', 'already_running' or 'failed'
+    last_error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT valid_schedule_outcome CHECK (last_outcome IS NULL OR last_outcome IN ('queued', 'already_running', 'failed'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_repository_schedules_repo ON repository_schedules(repository_id);
+CREATE INDEX IF NOT EXISTS idx_repository_schedules_due ON repository_schedules(next_run_at) WHERE enabled;
+
+COMMENT ON TABLE repository_schedules IS 'Cron schedules re-running a repository''s pipeline';
@@ -0,0 +1,62 @@
+-- Migration 015: API authentication
+-- The API server authenticates requests with API keys or OIDC tokens from
+-- the company's identity provider, besides GitHub sessions, and scopes
+-- repositories, runs and jobs to the caller's organizations.
+
+-- =============================================
+-- OIDC USERS
+-- =============================================
+
+-- Users signing in through OIDC have no GitHub account; github_login holds
+-- their preferred username
+ALTER TABLE users ALTER COLUMN github_id DROP NOT NULL;
+
+ALTER TABLE users
+ADD COLUMN IF NOT EXISTS oidc_issuer TEXT,
+ADD COLUMN IF NOT EXISTS oidc_subject TEXT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_oidc ON users(oidc_issuer, oidc_subject)
+    WHERE oidc_subject IS NOT NULL;
+
+-- Personal organizations take the user's login as their slug, suffixed
+-- when another organization already has it
+CREATE OR REPLACE FUNCTION create_personal_organization()
+RETURNS TRIGGER AS $$
+DECLARE
+    org_slug TEXT := NEW.github_login;
+BEGIN
+    IF EXISTS (SELECT 1 FROM organizations WHERE slug = org_slug) THEN
+        org_slug := org_slug || '-' || left(NEW.id::text, 8);
+    END IF;
+
+    INSERT INTO organizations (name, slug, owner_id, is_personal)
+    VALUES (NEW.github_login || '''s Workspace', org_slug, NEW.id, true);
+
+    INSERT INTO organization_members (organization_id, user_id, role)
+    SELECT id, NEW.id, 'owner'
+    FROM organizations
+    WHERE owner_id = NEW.id AND is_personal = true;
+
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+-- =============================================
+-- API KEYS
+-- =============================================
+
+-- Keys are looked up by the hash of the presented key
+CREATE UNIQUE INDEX IF NOT EXISTS idx_api_keys_hash ON api_keys(key_hash);
+
+-- =============================================
+-- BATCHES
+-- =============================================
+
+-- Organization a batch was submitted for; NULL for batches submitted
+-- without credentials
+ALTER TABLE repository_batches
+ADD COLUMN IF NOT EXISTS organization_id UUID REFERENCES organizations(id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS idx_repository_batches_org ON repository_batches(organization_id);
+
+COMMENT ON COLUMN users.oidc_subject IS 'Subject of the OIDC identity, unique per issuer';
@@ -33,6 +33,7 @@ type TestTarget struct {
 	Method   string   `json:"method,omitempty" yaml:"method,omitempty"`
 	Endpoint string   `json:"endpoint,omitempty" yaml:"endpoint,omitempty"`
 	Tags     []string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Async    bool     `json:"async,omitempty" yaml:"async,omitempty"` // the function's result must be awaited
 }
 
 // Lifecycle defines setup and teardown behavior
@@ -127,6 +128,7 @@ const (
 	ActionType_      ActionType = "type"       // E2E: type text
 	ActionWait       ActionType = "wait"       // E2E: wait for condition
 	ActionScreenshot ActionType = "screenshot" // E2E: take screenshot
+	ActionSnapshot   ActionType = "snapshot"   // Compare a value with its stored snapshot
 )
 
 // Expected defines the expected outcome of a step
@@ -26,6 +26,17 @@ func (a *ParserAdapter) RegisterSupplement(s Supplement) {
 	a.builder.RegisterSupplement(s)
 }
 
+// SetMinConfidence sets the confidence a framework needs for its supplement
+// to run
+func (a *ParserAdapter) SetMinConfidence(c float64) {
+	a.builder.SetMinConfidence(c)
+}
+
+// OverrideFramework forces a framework's supplement on or off
+func (a *ParserAdapter) OverrideFramework(name string, enabled bool) {
+	a.builder.OverrideFramework(name, enabled)
+}
+
 // ParsedFile represents the output from the Tree-sitter parser.
 // This mirrors the structure in internal/parser/types.go
 type ParsedFile struct {
@@ -235,7 +246,7 @@ type FileParser interface {
 // isSourceFile checks if a file extension is a supported source file
 func isSourceFile(ext string) bool {
 	switch ext {
-	case ".go", ".py", ".js", ".jsx", ".ts", ".tsx", ".java":
+	case ".go", ".py", ".js", ".jsx", ".ts", ".tsx", ".java", ".rs", ".cs":
 		return true
 	default:
 		return false
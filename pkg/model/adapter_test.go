@@ -228,6 +228,8 @@ func TestIsSourceFile(t *testing.T) {
 		{".ts", true},
 		{".tsx", true},
 		{".java", true},
+		{".rs", true},
+		{".cs", true},
 		{".txt", false},
 		{".md", false},
 		{".json", false},
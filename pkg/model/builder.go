@@ -11,8 +11,10 @@ import (
 
 // Builder constructs a SystemModel from parsed files
 type Builder struct {
-	model       *SystemModel
-	supplements []Supplement
+	model         *SystemModel
+	supplements   []Supplement
+	minConfidence float64
+	overrides     map[string]bool // framework -> forced on (true) or off (false)
 }
 
 // Supplement is the interface that framework-specific analyzers implement.
@@ -48,7 +50,9 @@ func NewBuilder(repo, branch, commitSHA string) *Builder {
 			TestTargets: make([]TestTarget, 0),
 			Languages:   make([]string, 0),
 		},
-		supplements: make([]Supplement, 0),
+		supplements:   make([]Supplement, 0),
+		minConfidence: DefaultMinConfidence,
+		overrides:     make(map[string]bool),
 	}
 }
 
@@ -57,6 +61,18 @@ func (b *Builder) RegisterSupplement(s Supplement) {
 	b.supplements = append(b.supplements, s)
 }
 
+// SetMinConfidence sets the confidence a framework detection needs for its
+// supplement to run
+func (b *Builder) SetMinConfidence(c float64) {
+	b.minConfidence = c
+}
+
+// OverrideFramework forces a framework's supplement to run (enabled) or
+// not, whatever the detection found. It lets users correct misdetections.
+func (b *Builder) OverrideFramework(name string, enabled bool) {
+	b.overrides[name] = enabled
+}
+
 // AddParsedFile adds a parsed file to the model
 func (b *Builder) AddParsedFile(path, language string, functions []ParsedFunction, classes []ParsedClass) {
 	// Track language
@@ -111,6 +127,7 @@ func (b *Builder) AddParsedFile(path, language string, functions []ParsedFunctio
 			Decorators: fn.Decorators,
 			Exported:   fn.Exported,
 			Async:      fn.Async,
+			Pure:       EstimatePure(fn.Body, language),
 			Body:       fn.Body,
 			DocComment: fn.DocComment,
 			LOC:        fn.EndLine - fn.StartLine + 1,
@@ -151,6 +168,7 @@ func (b *Builder) AddParsedFile(path, language string, functions []ParsedFunctio
 				Class:      cls.Name,
 				Exported:   method.Exported,
 				Async:      method.Async,
+				Pure:       EstimatePure(method.Body, language),
 				Body:       method.Body,
 				LOC:        method.EndLine - method.StartLine + 1,
 			})
@@ -168,12 +186,20 @@ func (b *Builder) Build() (*SystemModel, error) {
 		allFiles = append(allFiles, mod.Files...)
 	}
 
-	// Run applicable supplements
+	// Run applicable supplements; several can apply to one repository or
+	// module
 	for _, supp := range b.supplements {
-		if supp.Detect(allFiles) {
-			if err := supp.Analyze(b.model); err != nil {
-				return nil, fmt.Errorf("supplement %s failed: %w", supp.Name(), err)
-			}
+		d := b.detectFramework(supp, allFiles)
+		if d.Confidence == 0 && !d.Forced {
+			continue
+		}
+		assignFrameworkModules(b.model, &d)
+		b.model.Frameworks = append(b.model.Frameworks, d)
+		if d.Skipped {
+			continue
+		}
+		if err := supp.Analyze(b.model); err != nil {
+			return nil, fmt.Errorf("supplement %s failed: %w", supp.Name(), err)
 		}
 	}
 
@@ -186,6 +212,29 @@ func (b *Builder) Build() (*SystemModel, error) {
 	return b.model, nil
 }
 
+// detectFramework runs a supplement's detection and applies the confidence
+// threshold and user overrides
+func (b *Builder) detectFramework(supp Supplement, files []string) FrameworkDetection {
+	var d FrameworkDetection
+	if scored, ok := supp.(ScoredSupplement); ok {
+		d = scored.DetectScored(files)
+	} else if supp.Detect(files) {
+		d.Confidence = 1
+	}
+	d.Framework = supp.Name()
+
+	enabled, overridden := b.overrides[d.Framework]
+	switch {
+	case overridden && enabled:
+		d.Forced = true
+	case overridden:
+		d.Skipped = true
+	case d.Confidence < b.minConfidence:
+		d.Skipped = true
+	}
+	return d
+}
+
 // computeRiskScores calculates risk scores for all functions
 func (b *Builder) computeRiskScores() {
 	for _, fn := range b.model.Functions {
@@ -220,7 +269,7 @@ func (b *Builder) computeRiskScores() {
 		}
 
 		// Overall score (weighted average)
-		score.Score = score.Complexity*0.5 + score.Centrality*0.3 + score.Churn*0.2
+		score.Score = score.Complexity*RiskWeightComplexity + score.Centrality*RiskWeightCentrality + score.Churn*RiskWeightChurn
 
 		b.model.RiskScores[fn.ID] = score
 	}
@@ -237,7 +286,7 @@ func (b *Builder) generateTestTargets() {
 			Kind:       TargetKindAPI,
 			EndpointID: ep.ID,
 			Priority:   priority,
-			Reason:     fmt.Sprintf("API endpoint: %s %s", ep.Method, ep.Path),
+			Reason:     "API endpoint: " + ep.Describe(),
 		})
 		priority++
 	}
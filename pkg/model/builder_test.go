@@ -256,6 +256,104 @@ func TestBuilder_Build_SupplementFails(t *testing.T) {
 	}
 }
 
+func TestBuilder_Build_FrameworkDetection(t *testing.T) {
+	b := NewBuilder("repo", "main", "sha")
+
+	express := &mockScoredSupplement{mockSupplement: mockSupplement{name: "express"}, evidence: []DetectionEvidence{
+		{File: "api/app.js", Line: 1, Signal: "imports express", Weight: 0.7},
+	}}
+	nest := &mockScoredSupplement{mockSupplement: mockSupplement{name: "nestjs"}, evidence: []DetectionEvidence{
+		{File: "api/users.controller.ts", Line: 3, Signal: "@Controller decorator", Weight: 0.2},
+	}}
+	gin := &mockScoredSupplement{mockSupplement: mockSupplement{name: "gin"}}
+	b.RegisterSupplement(express)
+	b.RegisterSupplement(nest)
+	b.RegisterSupplement(gin)
+
+	b.AddParsedFile("api/app.js", "javascript", nil, nil)
+	b.AddParsedFile("api/users.controller.ts", "typescript", nil, nil)
+
+	m, err := b.Build()
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+
+	if !express.analyzed {
+		t.Error("express should run")
+	}
+	if nest.analyzed {
+		t.Error("nestjs is below the confidence threshold and shouldn't run")
+	}
+	if gin.analyzed {
+		t.Error("gin has no evidence and shouldn't run")
+	}
+
+	// Frameworks without evidence aren't reported; weak ones are, as skipped
+	if len(m.Frameworks) != 2 {
+		t.Fatalf("len(Frameworks) = %d, want 2: %+v", len(m.Frameworks), m.Frameworks)
+	}
+	if m.Frameworks[0].Confidence != 0.7 || m.Frameworks[0].Skipped {
+		t.Errorf("express detection = %+v", m.Frameworks[0])
+	}
+	if !m.Frameworks[1].Skipped {
+		t.Errorf("nestjs detection = %+v, want skipped", m.Frameworks[1])
+	}
+	if len(m.GetFrameworks()) != 1 {
+		t.Errorf("GetFrameworks() = %+v, want express only", m.GetFrameworks())
+	}
+	if len(m.Modules) != 1 || len(m.Modules[0].Frameworks) != 1 || m.Modules[0].Frameworks[0] != "express" {
+		t.Errorf("module frameworks = %+v, want [express]", m.Modules[0].Frameworks)
+	}
+}
+
+func TestBuilder_Build_FrameworkOverrides(t *testing.T) {
+	b := NewBuilder("repo", "main", "sha")
+
+	express := &mockScoredSupplement{mockSupplement: mockSupplement{name: "express"}, evidence: []DetectionEvidence{
+		{File: "api/app.js", Weight: 0.9},
+	}}
+	nest := &mockScoredSupplement{mockSupplement: mockSupplement{name: "nestjs"}}
+	b.RegisterSupplement(express)
+	b.RegisterSupplement(nest)
+	b.OverrideFramework("express", false)
+	b.OverrideFramework("nestjs", true)
+
+	b.AddParsedFile("api/app.js", "javascript", nil, nil)
+	m, err := b.Build()
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+
+	if express.analyzed {
+		t.Error("express was disabled and shouldn't run")
+	}
+	if !nest.analyzed {
+		t.Error("nestjs was forced on and should run")
+	}
+	if len(m.Frameworks) != 2 || !m.Frameworks[0].Skipped || !m.Frameworks[1].Forced {
+		t.Errorf("Frameworks = %+v", m.Frameworks)
+	}
+}
+
+func TestNewFrameworkDetection(t *testing.T) {
+	d := NewFrameworkDetection("gin", []DetectionEvidence{
+		{File: "a.go", Weight: 0.5},
+		{File: "go.mod", Weight: 0.9},
+		{File: "b.go", Weight: 0.5},
+	})
+
+	// 1 - 0.5*0.1*0.5
+	if d.Confidence != 0.98 {
+		t.Errorf("Confidence = %v, want 0.98", d.Confidence)
+	}
+	if d.Evidence[0].File != "go.mod" {
+		t.Errorf("Evidence[0] = %s, want strongest (go.mod) first", d.Evidence[0].File)
+	}
+	if got := NewFrameworkDetection("gin", nil).Confidence; got != 0 {
+		t.Errorf("Confidence without evidence = %v, want 0", got)
+	}
+}
+
 func TestBuilder_ComputeRiskScores_LOC(t *testing.T) {
 	b := NewBuilder("repo", "main", "sha")
 
@@ -449,6 +547,15 @@ func (m *mockSupplement) Analyze(model *SystemModel) error {
 	return nil
 }
 
+type mockScoredSupplement struct {
+	mockSupplement
+	evidence []DetectionEvidence
+}
+
+func (m *mockScoredSupplement) DetectScored(files []string) FrameworkDetection {
+	return NewFrameworkDetection(m.name, m.evidence)
+}
+
 var errMock = &mockError{}
 
 type mockError struct{}
@@ -0,0 +1,101 @@
+package model
+
+import (
+	"math"
+	"path/filepath"
+	"sort"
+)
+
+// DefaultMinConfidence is the confidence a framework detection needs for
+// its supplement to run
+const DefaultMinConfidence = 0.3
+
+// DetectionEvidence is one signal that a framework is in use
+type DetectionEvidence struct {
+	File   string  `json:"file"`
+	Line   int     `json:"line,omitempty"`
+	Signal string  `json:"signal"` // what matched, e.g. an import or a dependency
+	Weight float64 `json:"weight"` // how strongly it suggests the framework, 0-1
+}
+
+// FrameworkDetection is a framework found in a repository, with how sure
+// QTest is and why
+type FrameworkDetection struct {
+	Framework  string              `json:"framework"`
+	Confidence float64             `json:"confidence"`        // 0-1
+	Modules    []string            `json:"modules,omitempty"` // module IDs the evidence is in
+	Evidence   []DetectionEvidence `json:"evidence,omitempty"`
+	Forced     bool                `json:"forced,omitempty"`  // enabled by the user regardless of evidence
+	Skipped    bool                `json:"skipped,omitempty"` // disabled by the user or below the threshold
+}
+
+// ScoredSupplement is a Supplement that reports how confident its detection
+// is and the evidence for it. Supplements that only implement Detect count
+// as fully confident when they detect.
+type ScoredSupplement interface {
+	Supplement
+
+	// DetectScored inspects files and returns the evidence found for the
+	// framework. Confidence is 0 if there is none.
+	DetectScored(files []string) FrameworkDetection
+}
+
+// NewFrameworkDetection combines evidence for a framework into a detection.
+// Each piece of evidence is treated as independent, so confidence grows with
+// more of it but never reaches 1 unless a signal is certain.
+func NewFrameworkDetection(framework string, evidence []DetectionEvidence) FrameworkDetection {
+	d := FrameworkDetection{Framework: framework}
+
+	doubt := 1.0
+	for _, e := range evidence {
+		doubt *= 1 - math.Min(math.Max(e.Weight, 0), 1)
+	}
+	d.Confidence = math.Round((1-doubt)*100) / 100
+
+	// Strongest evidence first
+	sorted := append([]DetectionEvidence(nil), evidence...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Weight > sorted[j].Weight })
+	d.Evidence = sorted
+	return d
+}
+
+// GetFrameworks returns the frameworks detected with enough confidence for
+// their supplements to run
+func (m *SystemModel) GetFrameworks() []FrameworkDetection {
+	var active []FrameworkDetection
+	for _, d := range m.Frameworks {
+		if !d.Skipped {
+			active = append(active, d)
+		}
+	}
+	return active
+}
+
+// assignFrameworkModules records on each module the frameworks whose
+// evidence is in its files, so a module can use several
+func assignFrameworkModules(m *SystemModel, d *FrameworkDetection) {
+	for i := range m.Modules {
+		mod := &m.Modules[i]
+		if !hasEvidenceIn(mod, d.Evidence) {
+			continue
+		}
+		d.Modules = append(d.Modules, mod.ID)
+		if !d.Skipped {
+			mod.Frameworks = append(mod.Frameworks, d.Framework)
+		}
+	}
+}
+
+func hasEvidenceIn(mod *Module, evidence []DetectionEvidence) bool {
+	for _, e := range evidence {
+		for _, f := range mod.Files {
+			if f == e.File {
+				return true
+			}
+		}
+		if mod.Path != "" && filepath.Dir(e.File) == mod.Path {
+			return true // e.g. a manifest next to the module's sources
+		}
+	}
+	return false
+}
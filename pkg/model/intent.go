@@ -1,5 +1,12 @@
 package model
 
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
 // TestLevel represents the test pyramid level
 type TestLevel string
 
@@ -13,11 +20,36 @@ const (
 // This is the output of planning, before LLM generation
 type TestIntent struct {
 	ID         string    `json:"id"`
-	Level      TestLevel `json:"level"`       // unit/api/e2e
-	TargetKind string    `json:"target_kind"` // "function" | "endpoint"
-	TargetID   string    `json:"target_id"`   // refers into SystemModel
-	Priority   string    `json:"priority"`    // "high" | "medium" | "low"
-	Reason     string    `json:"reason"`      // why this test is needed
+	Level      TestLevel `json:"level"`                // unit/api/e2e
+	TargetKind string    `json:"target_kind"`          // "function" | "endpoint"
+	TargetID   string    `json:"target_id"`            // refers into SystemModel
+	Priority   string    `json:"priority"`             // "high" | "medium" | "low"
+	Reason     string    `json:"reason"`               // why this test is needed
+	Tags       []string  `json:"tags,omitempty"`       // e.g. "slow" for external calls or big fixtures
+	LineageID  string    `json:"lineage_id,omitempty"` // follows the intent through spec, code and results
+
+	Rationale *IntentRationale `json:"rationale,omitempty"` // factors behind the priority
+}
+
+// IntentRationale records the factors the planner weighed for an intent, so
+// users can see why it was prioritised and tune the planner
+type IntentRationale struct {
+	Rank        int     `json:"rank"`         // position in the plan, 1 = first
+	RiskScore   float64 `json:"risk_score"`   // weighted sum of the components below
+	Complexity  float64 `json:"complexity"`   // from the target's size
+	Centrality  float64 `json:"centrality"`   // from how many functions call it
+	Churn       float64 `json:"churn"`        // from how often it changes
+	CoverageGap bool    `json:"coverage_gap"` // no existing tests cover the target
+	LOC         int     `json:"loc,omitempty"`
+	Callers     int     `json:"callers,omitempty"`
+	Rule        string  `json:"rule"` // how the priority was decided
+}
+
+// LineageIDFor derives the stable lineage ID for an intent, so regenerating
+// a plan keeps the same IDs for the same targets
+func LineageIDFor(intentID string) string {
+	sum := sha256.Sum256([]byte(intentID))
+	return "ln-" + hex.EncodeToString(sum[:6])
 }
 
 // TestPlan is a collection of test intents with metadata
@@ -29,6 +61,46 @@ type TestPlan struct {
 	APITests   int          `json:"api_tests"`
 	E2ETests   int          `json:"e2e_tests"`
 	Intents    []TestIntent `json:"intents"`
+
+	Statistics map[string]int `json:"stats,omitempty"`   // Stats() when the plan was made
+	Scoring    *PlanScoring   `json:"scoring,omitempty"` // how intents were prioritised
+}
+
+// PlanScoring records the weights and thresholds a plan was prioritised with
+type PlanScoring struct {
+	ComplexityWeight    float64 `json:"complexity_weight"`
+	CentralityWeight    float64 `json:"centrality_weight"`
+	ChurnWeight         float64 `json:"churn_weight"`
+	HighRiskThreshold   float64 `json:"high_risk_threshold"`
+	MediumRiskThreshold float64 `json:"medium_risk_threshold"`
+}
+
+// FindIntent returns the intent with the given ID or lineage ID, or the
+// only one whose ID contains ref
+func (p *TestPlan) FindIntent(ref string) (*TestIntent, error) {
+	var matches []*TestIntent
+	for i := range p.Intents {
+		intent := &p.Intents[i]
+		if intent.ID == ref || intent.LineageID == ref {
+			return intent, nil
+		}
+		if strings.Contains(intent.ID, ref) {
+			matches = append(matches, intent)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("no intent matches %q", ref)
+	case 1:
+		return matches[0], nil
+	default:
+		ids := make([]string, 0, len(matches))
+		for _, m := range matches {
+			ids = append(ids, m.ID)
+		}
+		return nil, fmt.Errorf("%q matches %d intents: %s", ref, len(matches), strings.Join(ids, ", "))
+	}
 }
 
 // Stats returns test plan statistics
@@ -44,6 +116,24 @@ func (p *TestPlan) Stats() map[string]int {
 	}
 }
 
+// rank numbers the explained intents in plan order
+func (p *TestPlan) rank() {
+	for i := range p.Intents {
+		if p.Intents[i].Rationale != nil {
+			p.Intents[i].Rationale.Rank = i + 1
+		}
+	}
+}
+
+// assignLineage gives every intent without one its lineage ID
+func (p *TestPlan) assignLineage() {
+	for i := range p.Intents {
+		if p.Intents[i].LineageID == "" {
+			p.Intents[i].LineageID = LineageIDFor(p.Intents[i].ID)
+		}
+	}
+}
+
 func (p *TestPlan) countByPriority(priority string) int {
 	count := 0
 	for _, i := range p.Intents {
@@ -183,3 +183,23 @@ func TestTestIntent_ToTestSpec(t *testing.T) {
 		t.Errorf("Priority should be high/medium/low, got %s", intent.Priority)
 	}
 }
+
+func TestTestPlan_FindIntent(t *testing.T) {
+	plan := &TestPlan{Intents: []TestIntent{
+		{ID: "intent:unit:users.go:10:Create", LineageID: "ln-aaa"},
+		{ID: "intent:unit:users.go:20:Delete", LineageID: "ln-bbb"},
+	}}
+
+	for _, ref := range []string{"intent:unit:users.go:20:Delete", "ln-bbb", "Delete"} {
+		intent, err := plan.FindIntent(ref)
+		if err != nil || intent.LineageID != "ln-bbb" {
+			t.Errorf("FindIntent(%q) = %v, %v, want the Delete intent", ref, intent, err)
+		}
+	}
+	if _, err := plan.FindIntent("users.go"); err == nil {
+		t.Error("FindIntent() should reject a reference matching several intents")
+	}
+	if _, err := plan.FindIntent("Update"); err == nil {
+		t.Error("FindIntent() should reject a reference matching no intent")
+	}
+}
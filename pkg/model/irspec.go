@@ -73,19 +73,33 @@ type IRAction struct {
 type IRAssertion struct {
 	// Type is the assertion kind
 	// Supported: "equals", "not_equals", "contains", "not_contains",
-	//            "greater_than", "less_than", "throws", "truthy", "falsy",
-	//            "nil", "not_nil", "length", "type_is"
+	//            "greater_than", "less_than", "throws" (or "raises"), "truthy", "falsy",
+	//            "nil", "not_nil", "length", "type_is", "snapshot", "approx", "matches",
+	//            "contains_all", "subset", "sorted", and for API responses
+	//            "header_present", "header_equals", "header_matches",
+	//            "cookie_present", "cookie_equals", "cookie_matches"
 	Type string `json:"type"`
 
 	// Actual is what we're checking (usually "result" or an expression)
 	// Special values: "result" (function return), "error" (exception)
 	Actual string `json:"actual"`
 
-	// Expected is the expected value (for equality-type assertions)
+	// Expected is the expected value (for equality-type assertions), a
+	// regular expression for "matches", the items for "contains_all" and
+	// "subset", or the key ("-key" for descending) for "sorted". Header and
+	// cookie assertions name the header or cookie in Actual.
 	Expected interface{} `json:"expected,omitempty"`
 
+	// Tolerance is how far an "approx" value may be from Expected
+	Tolerance float64 `json:"tolerance,omitempty"`
+
 	// Message is an optional custom error message
 	Message string `json:"message,omitempty"`
+
+	// ErrorType and ErrorMessage are what a "throws" assertion expects the
+	// call to fail with, e.g. "ValueError" and "must be positive"
+	ErrorType    string `json:"error_type,omitempty"`
+	ErrorMessage string `json:"error_message,omitempty"`
 }
 
 // IRSpecJSONSchema returns the JSON schema for prompting LLMs
@@ -155,14 +169,26 @@ const IRSpecJSONSchema = `{
               "properties": {
                 "type": {
                   "type": "string",
-                  "enum": ["equals", "not_equals", "contains", "greater_than", "less_than", "throws", "truthy", "falsy", "nil", "not_nil"]
+                  "enum": ["equals", "not_equals", "contains", "greater_than", "less_than", "throws", "truthy", "falsy", "nil", "not_nil", "snapshot", "approx", "matches", "length", "contains_all", "subset", "sorted", "header_present", "header_equals", "header_matches", "cookie_present", "cookie_equals", "cookie_matches"]
                 },
                 "actual": {
                   "type": "string",
                   "description": "What to check (usually 'result' for function return value)"
                 },
                 "expected": {
-                  "description": "Expected value for comparison"
+                  "description": "Expected value for comparison; for matches, a regular expression; for contains_all and subset, an array of items; for sorted, the key items are ordered by ('-key' for descending), or omitted; for header_* and cookie_* assertions, the value or pattern while actual names the header or cookie"
+                },
+                "tolerance": {
+                  "type": "number",
+                  "description": "For approx: how far the result may be from expected (e.g., 0.001)"
+                },
+                "error_type": {
+                  "type": "string",
+                  "description": "For throws: the error the call fails with (e.g., 'ValueError', 'TypeError', 'ErrNotFound')"
+                },
+                "error_message": {
+                  "type": "string",
+                  "description": "For throws: text the error message contains"
                 }
               }
             }
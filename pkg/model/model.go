@@ -4,7 +4,10 @@
 // uses to generate the test pyramid.
 package model
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
 
 // SystemModel is the universal intermediate representation of a codebase.
 // It's language-agnostic and represents everything needed to generate
@@ -31,6 +34,9 @@ type SystemModel struct {
 
 	// Languages detected
 	Languages []string `json:"languages"`
+
+	// Frameworks detected by supplements, with confidence and evidence
+	Frameworks []FrameworkDetection `json:"frameworks,omitempty"`
 }
 
 // Module represents a logical grouping (package, namespace, folder)
@@ -40,6 +46,8 @@ type Module struct {
 	Path     string   `json:"path"` // File system path
 	Language string   `json:"language"`
 	Files    []string `json:"files"` // File paths in this module
+
+	Frameworks []string `json:"frameworks,omitempty"` // Frameworks detected in this module
 }
 
 // Function represents any callable unit (function, method, lambda)
@@ -138,6 +146,59 @@ type Endpoint struct {
 	// Framework
 	Framework  string   `json:"framework"` // express, fastapi, gin, etc.
 	Middleware []string `json:"middleware,omitempty"`
+
+	// How it was found: "" for source analysis, "runtime" for discovery
+	Source string `json:"source,omitempty"`
+
+	// Set for GraphQL operations, which all share the server's one route
+	GraphQL *GraphQLOperation `json:"graphql,omitempty"`
+
+	// Set for gRPC methods, whose path is the /package.Service/Method
+	// they're called on
+	GRPC *GRPCMethod `json:"grpc,omitempty"`
+}
+
+// GraphQLOperation is a query or mutation field of a GraphQL schema
+type GraphQLOperation struct {
+	Kind       string      `json:"kind"`  // query, mutation
+	Field      string      `json:"field"` // root field, e.g. users
+	Args       []Parameter `json:"args,omitempty"`
+	ReturnType string      `json:"return_type,omitempty"` // in SDL form, e.g. [User!]!
+}
+
+// GRPCMethod is a unary RPC of a registered gRPC service
+type GRPCMethod struct {
+	Package      string `json:"package,omitempty"` // proto package, e.g. users.v1
+	Service      string `json:"service"`           // e.g. UserService
+	Method       string `json:"method"`
+	RequestType  string `json:"request_type"`
+	ResponseType string `json:"response_type"`
+
+	// Where the service is registered, for client tests against it
+	Stubs        string `json:"stubs,omitempty"`         // generated code: Go import path or Python *_pb2_grpc module
+	Server       string `json:"server,omitempty"`        // implementation as registered, e.g. &userServer{}
+	ServerModule string `json:"server_module,omitempty"` // Go package or Python module registering it
+}
+
+// FullService returns the service's fully qualified name, e.g.
+// users.v1.UserService
+func (g GRPCMethod) FullService() string {
+	if g.Package == "" {
+		return g.Service
+	}
+	return g.Package + "." + g.Service
+}
+
+// Describe names the endpoint for people: its method and path, a GraphQL
+// operation's kind and field, or a gRPC service and method
+func (e Endpoint) Describe() string {
+	if e.GraphQL != nil {
+		return fmt.Sprintf("GraphQL %s %s", e.GraphQL.Kind, e.GraphQL.Field)
+	}
+	if e.GRPC != nil {
+		return fmt.Sprintf("gRPC %s/%s", e.GRPC.FullService(), e.GRPC.Method)
+	}
+	return fmt.Sprintf("%s %s", e.Method, e.Path)
 }
 
 // Event represents an event handler (message queue, webhook, etc.)
@@ -168,6 +229,13 @@ type RiskScore struct {
 	HasTests   bool    `json:"has_tests"`  // Existing test coverage
 }
 
+// Weights of the risk score components
+const (
+	RiskWeightComplexity = 0.5
+	RiskWeightCentrality = 0.3
+	RiskWeightChurn      = 0.2
+)
+
 // TestTarget represents a prioritized item to generate tests for
 type TestTarget struct {
 	ID         string     `json:"id"`
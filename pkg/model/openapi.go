@@ -0,0 +1,503 @@
+package model
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// OpenAPIImporter merges an OpenAPI 3 or Swagger 2 document (YAML or JSON)
+// into the model: its operations become endpoints and its schemas become
+// types. It's registered like a supplement, after the framework ones, so it
+// fills in routes static analysis found and adds the ones it missed.
+type OpenAPIImporter struct {
+	path string
+	doc  openAPIDocument
+}
+
+// openAPIDocument is the part of an OpenAPI 3 or Swagger 2 document
+// describing routes and schemas
+type openAPIDocument struct {
+	OpenAPI string `yaml:"openapi"`
+	Swagger string `yaml:"swagger"`
+	Info    struct {
+		Title   string `yaml:"title"`
+		Version string `yaml:"version"`
+	} `yaml:"info"`
+	BasePath string `yaml:"basePath"` // Swagger 2
+	Servers  []struct {
+		URL string `yaml:"url"`
+	} `yaml:"servers"` // OpenAPI 3
+	Paths map[string]yaml.Node `yaml:"paths"`
+
+	// Reusable objects: components in OpenAPI 3, top-level in Swagger 2
+	Components struct {
+		Schemas       map[string]*openAPISchema     `yaml:"schemas"`
+		Parameters    map[string]openAPIParameter   `yaml:"parameters"`
+		RequestBodies map[string]openAPIRequestBody `yaml:"requestBodies"`
+		Responses     map[string]openAPIResponse    `yaml:"responses"`
+	} `yaml:"components"`
+	Definitions map[string]*openAPISchema   `yaml:"definitions"`
+	Parameters  map[string]openAPIParameter `yaml:"parameters"`
+	Responses   map[string]openAPIResponse  `yaml:"responses"`
+}
+
+type openAPIPathItem struct {
+	Parameters []openAPIParameter `yaml:"parameters"`
+	Get        *openAPIOperation  `yaml:"get"`
+	Put        *openAPIOperation  `yaml:"put"`
+	Post       *openAPIOperation  `yaml:"post"`
+	Delete     *openAPIOperation  `yaml:"delete"`
+	Options    *openAPIOperation  `yaml:"options"`
+	Head       *openAPIOperation  `yaml:"head"`
+	Patch      *openAPIOperation  `yaml:"patch"`
+	Trace      *openAPIOperation  `yaml:"trace"`
+}
+
+type openAPIOperation struct {
+	OperationID string                     `yaml:"operationId"`
+	Parameters  []openAPIParameter         `yaml:"parameters"`
+	RequestBody *openAPIRequestBody        `yaml:"requestBody"`
+	Responses   map[string]openAPIResponse `yaml:"responses"`
+}
+
+type openAPIParameter struct {
+	Ref    string         `yaml:"$ref"`
+	Name   string         `yaml:"name"`
+	In     string         `yaml:"in"` // path, query, header, cookie; body in Swagger 2
+	Schema *openAPISchema `yaml:"schema"`
+}
+
+type openAPIRequestBody struct {
+	Ref     string                      `yaml:"$ref"`
+	Content map[string]openAPIMediaType `yaml:"content"`
+}
+
+type openAPIResponse struct {
+	Ref     string                      `yaml:"$ref"`
+	Content map[string]openAPIMediaType `yaml:"content"`
+	Schema  *openAPISchema              `yaml:"schema"` // Swagger 2
+}
+
+type openAPIMediaType struct {
+	Schema *openAPISchema `yaml:"schema"`
+}
+
+type openAPISchema struct {
+	Ref        string                    `yaml:"$ref"`
+	Type       string                    `yaml:"type"`
+	Format     string                    `yaml:"format"`
+	Items      *openAPISchema            `yaml:"items"`
+	Properties map[string]*openAPISchema `yaml:"properties"`
+	Required   []string                  `yaml:"required"`
+	Enum       []interface{}             `yaml:"enum"`
+	AllOf      []*openAPISchema          `yaml:"allOf"`
+}
+
+// openAPIPathParam matches a templated path segment, e.g. {userId}
+var openAPIPathParam = regexp.MustCompile(`\{(\w+)\}`)
+
+// routeParam matches path parameters in the styles frameworks use: :id,
+// {id} and <id> (optionally typed, e.g. <int:id>)
+var routeParam = regexp.MustCompile(`:(\w+)|\{(\w+)[^}]*\}|<(?:\w+:)?(\w+)>`)
+
+// LoadOpenAPI reads an OpenAPI 3 or Swagger 2 document
+func LoadOpenAPI(path string) (*OpenAPIImporter, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read OpenAPI spec: %w", err)
+	}
+
+	var doc openAPIDocument
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse OpenAPI spec %s: %w", path, err)
+	}
+	if doc.OpenAPI == "" && doc.Swagger == "" {
+		return nil, fmt.Errorf("%s is not an OpenAPI or Swagger document", path)
+	}
+
+	return &OpenAPIImporter{path: path, doc: doc}, nil
+}
+
+func (o *OpenAPIImporter) Name() string {
+	return "openapi"
+}
+
+// Detect always applies: the spec was given explicitly
+func (o *OpenAPIImporter) Detect(files []string) bool {
+	return true
+}
+
+// DetectScored reports the spec itself as certain evidence
+func (o *OpenAPIImporter) DetectScored(files []string) FrameworkDetection {
+	signal := "OpenAPI spec"
+	if o.doc.Info.Title != "" {
+		signal = fmt.Sprintf("OpenAPI spec %q", o.doc.Info.Title)
+	}
+	return NewFrameworkDetection(o.Name(), []DetectionEvidence{{File: o.path, Signal: signal, Weight: 1}})
+}
+
+// Analyze merges the spec's endpoints and schemas into the model
+func (o *OpenAPIImporter) Analyze(m *SystemModel) error {
+	existing := make(map[string]int) // route key -> index in m.Endpoints
+	for i, ep := range m.Endpoints {
+		existing[routeKey(ep.Method, ep.Path)] = i
+	}
+
+	functions := make(map[string]string) // name -> function ID
+	for _, fn := range m.Functions {
+		if _, ok := functions[fn.Name]; !ok {
+			functions[fn.Name] = fn.ID
+		}
+	}
+
+	for _, ep := range o.Endpoints() {
+		if i, ok := existing[routeKey(ep.Method, ep.Path)]; ok {
+			mergeEndpoint(&m.Endpoints[i], ep)
+			continue
+		}
+		if id, ok := functions[ep.Handler]; ok {
+			ep.Handler = id
+		}
+		existing[routeKey(ep.Method, ep.Path)] = len(m.Endpoints)
+		m.Endpoints = append(m.Endpoints, ep)
+	}
+
+	// Types from source are more precise, so schemas only add missing ones
+	types := make(map[string]bool)
+	for _, t := range m.Types {
+		types[t.Name] = true
+	}
+	for _, t := range o.Types() {
+		if !types[t.Name] {
+			m.Types = append(m.Types, t)
+		}
+	}
+
+	return nil
+}
+
+// Endpoints returns the spec's operations as endpoints, sorted by path and
+// method
+func (o *OpenAPIImporter) Endpoints() []Endpoint {
+	prefix := o.basePath()
+
+	paths := make([]string, 0, len(o.doc.Paths))
+	for p := range o.doc.Paths {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	var endpoints []Endpoint
+	for _, p := range paths {
+		node := o.doc.Paths[p]
+		var item openAPIPathItem
+		if err := node.Decode(&item); err != nil {
+			continue
+		}
+
+		for _, op := range []struct {
+			method string
+			op     *openAPIOperation
+		}{
+			{"DELETE", item.Delete}, {"GET", item.Get}, {"HEAD", item.Head}, {"OPTIONS", item.Options},
+			{"PATCH", item.Patch}, {"POST", item.Post}, {"PUT", item.Put}, {"TRACE", item.Trace},
+		} {
+			if op.op == nil {
+				continue
+			}
+			endpoints = append(endpoints, o.endpoint(op.method, prefix+p, node.Line, item.Parameters, op.op))
+		}
+	}
+	return endpoints
+}
+
+func (o *OpenAPIImporter) endpoint(method, path string, line int, shared []openAPIParameter, op *openAPIOperation) Endpoint {
+	handler := op.OperationID
+	if handler == "" {
+		handler = "anonymous"
+	}
+	ep := Endpoint{
+		ID:        fmt.Sprintf("ep:openapi:%s:%s", method, path),
+		Method:    method,
+		Path:      path,
+		Handler:   handler,
+		File:      o.path,
+		Line:      line,
+		Framework: "openapi",
+		Source:    "openapi",
+	}
+
+	// Operation parameters override path-level ones of the same name
+	params := make(map[string]openAPIParameter)
+	var order []string
+	for _, p := range append(append([]openAPIParameter(nil), shared...), op.Parameters...) {
+		p = o.resolveParameter(p)
+		key := p.In + ":" + p.Name
+		if _, ok := params[key]; !ok {
+			order = append(order, key)
+		}
+		params[key] = p
+	}
+	for _, key := range order {
+		p := params[key]
+		switch p.In {
+		case "path":
+			ep.PathParams = appendUnique(ep.PathParams, p.Name)
+		case "query":
+			ep.QueryParams = appendUnique(ep.QueryParams, p.Name)
+		case "body":
+			ep.RequestBody = schemaTypeName(p.Schema)
+		}
+	}
+	// Templated segments the spec forgot to declare
+	for _, pm := range openAPIPathParam.FindAllStringSubmatch(path, -1) {
+		ep.PathParams = appendUnique(ep.PathParams, pm[1])
+	}
+
+	if op.RequestBody != nil {
+		ep.RequestBody = mediaTypeName(o.resolveRequestBody(*op.RequestBody).Content)
+	}
+	ep.ResponseBody = o.responseTypeName(op.Responses)
+
+	return ep
+}
+
+// Types returns the spec's schemas as type definitions, sorted by name
+func (o *OpenAPIImporter) Types() []TypeDef {
+	schemas := o.doc.Components.Schemas
+	if len(schemas) == 0 {
+		schemas = o.doc.Definitions
+	}
+
+	names := make([]string, 0, len(schemas))
+	for name := range schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var types []TypeDef
+	for _, name := range names {
+		s := schemas[name]
+		if s == nil {
+			continue
+		}
+		t := TypeDef{
+			ID:       "type:openapi:" + name,
+			Name:     name,
+			Kind:     TypeKindStruct,
+			File:     o.path,
+			Exported: true,
+		}
+		switch {
+		case len(s.Enum) > 0:
+			t.Kind = TypeKindEnum
+		case s.Type != "" && s.Type != "object":
+			t.Kind = TypeKindAlias
+		}
+
+		// allOf composes the properties of its parts
+		props, required := s.Properties, s.Required
+		for _, part := range s.AllOf {
+			if part == nil {
+				continue
+			}
+			if part.Ref != "" && t.Extends == "" {
+				t.Extends = refName(part.Ref)
+			}
+			if len(part.Properties) > 0 {
+				merged := make(map[string]*openAPISchema, len(props)+len(part.Properties))
+				for k, v := range props {
+					merged[k] = v
+				}
+				for k, v := range part.Properties {
+					merged[k] = v
+				}
+				props = merged
+				required = append(required, part.Required...)
+			}
+		}
+
+		fieldNames := make([]string, 0, len(props))
+		for field := range props {
+			fieldNames = append(fieldNames, field)
+		}
+		sort.Strings(fieldNames)
+		for _, field := range fieldNames {
+			f := Field{Name: field, Type: schemaTypeName(props[field]), Exported: true}
+			for _, r := range required {
+				if r == field {
+					f.Tags = "required"
+					break
+				}
+			}
+			t.Fields = append(t.Fields, f)
+		}
+		types = append(types, t)
+	}
+	return types
+}
+
+// basePath is the path every route is served under: Swagger 2's basePath
+// or the path of the first OpenAPI 3 server
+func (o *OpenAPIImporter) basePath() string {
+	base := o.doc.BasePath
+	if len(o.doc.Servers) > 0 {
+		base = o.doc.Servers[0].URL
+		if u, err := url.Parse(base); err == nil && u.Host != "" {
+			base = u.Path
+		}
+	}
+	if !strings.HasPrefix(base, "/") {
+		return ""
+	}
+	return strings.TrimSuffix(base, "/")
+}
+
+// responseTypeName is the body type of the first success response, or of
+// the default response
+func (o *OpenAPIImporter) responseTypeName(responses map[string]openAPIResponse) string {
+	codes := make([]string, 0, len(responses))
+	for code := range responses {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	for _, code := range append(codes, "default") {
+		if !strings.HasPrefix(code, "2") && code != "default" {
+			continue
+		}
+		r, ok := responses[code]
+		if !ok {
+			continue
+		}
+		r = o.resolveResponse(r)
+		if r.Schema != nil {
+			return schemaTypeName(r.Schema)
+		}
+		if name := mediaTypeName(r.Content); name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+func (o *OpenAPIImporter) resolveParameter(p openAPIParameter) openAPIParameter {
+	if p.Ref == "" {
+		return p
+	}
+	if resolved, ok := o.doc.Components.Parameters[refName(p.Ref)]; ok {
+		return resolved
+	}
+	if resolved, ok := o.doc.Parameters[refName(p.Ref)]; ok {
+		return resolved
+	}
+	return p
+}
+
+func (o *OpenAPIImporter) resolveRequestBody(b openAPIRequestBody) openAPIRequestBody {
+	if b.Ref == "" {
+		return b
+	}
+	if resolved, ok := o.doc.Components.RequestBodies[refName(b.Ref)]; ok {
+		return resolved
+	}
+	return b
+}
+
+func (o *OpenAPIImporter) resolveResponse(r openAPIResponse) openAPIResponse {
+	if r.Ref == "" {
+		return r
+	}
+	if resolved, ok := o.doc.Components.Responses[refName(r.Ref)]; ok {
+		return resolved
+	}
+	if resolved, ok := o.doc.Responses[refName(r.Ref)]; ok {
+		return resolved
+	}
+	return r
+}
+
+// mediaTypeName is the schema type of a body, preferring JSON
+func mediaTypeName(content map[string]openAPIMediaType) string {
+	if mt, ok := content["application/json"]; ok {
+		return schemaTypeName(mt.Schema)
+	}
+	types := make([]string, 0, len(content))
+	for t := range content {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	for _, t := range types {
+		if name := schemaTypeName(content[t].Schema); name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+// schemaTypeName names a schema's type: the referenced schema, Name[] for
+// arrays, or the JSON type
+func schemaTypeName(s *openAPISchema) string {
+	switch {
+	case s == nil:
+		return ""
+	case s.Ref != "":
+		return refName(s.Ref)
+	case s.Type == "array":
+		if item := schemaTypeName(s.Items); item != "" {
+			return item + "[]"
+		}
+		return "array"
+	case len(s.AllOf) == 1:
+		return schemaTypeName(s.AllOf[0])
+	case s.Type == "" && len(s.Properties) > 0:
+		return "object"
+	default:
+		return s.Type
+	}
+}
+
+// refName is the last segment of a $ref, e.g. User for
+// #/components/schemas/User
+func refName(ref string) string {
+	return ref[strings.LastIndex(ref, "/")+1:]
+}
+
+// mergeEndpoint fills in what the spec knows about a route source analysis
+// already found
+func mergeEndpoint(ep *Endpoint, spec Endpoint) {
+	for _, q := range spec.QueryParams {
+		ep.QueryParams = appendUnique(ep.QueryParams, q)
+	}
+	if ep.RequestBody == "" {
+		ep.RequestBody = spec.RequestBody
+	}
+	if ep.ResponseBody == "" {
+		ep.ResponseBody = spec.ResponseBody
+	}
+}
+
+// routeKey identifies a route regardless of parameter style, so
+// /users/:id, /users/{id} and /users/<int:id> are the same
+func routeKey(method, path string) string {
+	path = routeParam.ReplaceAllString(path, "{}")
+	if len(path) > 1 {
+		path = strings.TrimSuffix(path, "/")
+	}
+	return strings.ToUpper(method) + " " + path
+}
+
+func appendUnique(list []string, s string) []string {
+	for _, existing := range list {
+		if existing == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
@@ -0,0 +1,257 @@
+package model
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const openAPI3Spec = `openapi: 3.0.3
+info:
+  title: Orders
+  version: "1.0"
+servers:
+  - url: https://api.example.com/v1
+paths:
+  /orders:
+    get:
+      operationId: listOrders
+      parameters:
+        - $ref: '#/components/parameters/Limit'
+        - name: status
+          in: query
+          schema: {type: string}
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                type: array
+                items: {$ref: '#/components/schemas/Order'}
+    post:
+      operationId: createOrder
+      requestBody:
+        $ref: '#/components/requestBodies/NewOrder'
+      responses:
+        "201":
+          $ref: '#/components/responses/Created'
+  /orders/{orderId}:
+    parameters:
+      - name: orderId
+        in: path
+        required: true
+        schema: {type: string}
+    delete:
+      responses:
+        "204": {description: deleted}
+components:
+  parameters:
+    Limit:
+      name: limit
+      in: query
+      schema: {type: integer}
+  requestBodies:
+    NewOrder:
+      content:
+        application/json:
+          schema: {$ref: '#/components/schemas/NewOrder'}
+  responses:
+    Created:
+      description: created
+      content:
+        application/json:
+          schema: {$ref: '#/components/schemas/Order'}
+  schemas:
+    NewOrder:
+      type: object
+      required: [item]
+      properties:
+        item: {type: string}
+        quantity: {type: integer}
+    Order:
+      allOf:
+        - $ref: '#/components/schemas/NewOrder'
+        - type: object
+          properties:
+            id: {type: string}
+    Status:
+      type: string
+      enum: [open, shipped]
+`
+
+const swagger2Spec = `{
+  "swagger": "2.0",
+  "info": {"title": "Pets", "version": "1"},
+  "basePath": "/api",
+  "paths": {
+    "/pets": {
+      "post": {
+        "operationId": "addPet",
+        "parameters": [{"name": "pet", "in": "body", "schema": {"$ref": "#/definitions/Pet"}}],
+        "responses": {"200": {"description": "ok", "schema": {"$ref": "#/definitions/Pet"}}}
+      }
+    }
+  },
+  "definitions": {
+    "Pet": {"type": "object", "properties": {"name": {"type": "string"}}}
+  }
+}`
+
+func writeSpec(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadOpenAPI_Errors(t *testing.T) {
+	if _, err := LoadOpenAPI(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("LoadOpenAPI() of a missing file should fail")
+	}
+	if _, err := LoadOpenAPI(writeSpec(t, "config.yaml", "name: not a spec\n")); err == nil {
+		t.Error("LoadOpenAPI() of a document without openapi or swagger should fail")
+	}
+}
+
+func TestOpenAPIImporter_Endpoints(t *testing.T) {
+	importer, err := LoadOpenAPI(writeSpec(t, "openapi.yaml", openAPI3Spec))
+	if err != nil {
+		t.Fatalf("LoadOpenAPI() error: %v", err)
+	}
+
+	endpoints := importer.Endpoints()
+	if len(endpoints) != 3 {
+		t.Fatalf("len(endpoints) = %d, want 3: %+v", len(endpoints), endpoints)
+	}
+
+	list := endpoints[0]
+	if list.Method != "GET" || list.Path != "/v1/orders" || list.Handler != "listOrders" {
+		t.Errorf("endpoints[0] = %s %s -> %s, want GET /v1/orders -> listOrders", list.Method, list.Path, list.Handler)
+	}
+	if strings.Join(list.QueryParams, ",") != "limit,status" {
+		t.Errorf("QueryParams = %v, want [limit status]", list.QueryParams)
+	}
+	if list.ResponseBody != "Order[]" || list.Source != "openapi" || list.Line == 0 {
+		t.Errorf("endpoints[0] = %+v", list)
+	}
+
+	create := endpoints[1]
+	if create.Method != "POST" || create.RequestBody != "NewOrder" || create.ResponseBody != "Order" {
+		t.Errorf("endpoints[1] = %+v, want POST with NewOrder -> Order", create)
+	}
+
+	del := endpoints[2]
+	if del.Method != "DELETE" || del.Handler != "anonymous" || strings.Join(del.PathParams, ",") != "orderId" {
+		t.Errorf("endpoints[2] = %+v, want anonymous DELETE with path param orderId", del)
+	}
+}
+
+func TestOpenAPIImporter_Types(t *testing.T) {
+	importer, err := LoadOpenAPI(writeSpec(t, "openapi.yaml", openAPI3Spec))
+	if err != nil {
+		t.Fatalf("LoadOpenAPI() error: %v", err)
+	}
+
+	types := make(map[string]TypeDef)
+	for _, td := range importer.Types() {
+		types[td.Name] = td
+	}
+
+	newOrder := types["NewOrder"]
+	if newOrder.Kind != TypeKindStruct || len(newOrder.Fields) != 2 || newOrder.Fields[0].Name != "item" || newOrder.Fields[0].Tags != "required" {
+		t.Errorf("NewOrder = %+v", newOrder)
+	}
+	if order := types["Order"]; order.Extends != "NewOrder" || len(order.Fields) != 1 || order.Fields[0].Name != "id" {
+		t.Errorf("Order = %+v, want extends NewOrder with field id", order)
+	}
+	if status := types["Status"]; status.Kind != TypeKindEnum {
+		t.Errorf("Status kind = %s, want enum", status.Kind)
+	}
+}
+
+func TestOpenAPIImporter_Swagger2(t *testing.T) {
+	importer, err := LoadOpenAPI(writeSpec(t, "swagger.json", swagger2Spec))
+	if err != nil {
+		t.Fatalf("LoadOpenAPI() error: %v", err)
+	}
+
+	endpoints := importer.Endpoints()
+	if len(endpoints) != 1 {
+		t.Fatalf("len(endpoints) = %d, want 1", len(endpoints))
+	}
+	ep := endpoints[0]
+	if ep.Path != "/api/pets" || ep.RequestBody != "Pet" || ep.ResponseBody != "Pet" {
+		t.Errorf("endpoint = %+v, want /api/pets taking and returning Pet", ep)
+	}
+	if types := importer.Types(); len(types) != 1 || types[0].Name != "Pet" {
+		t.Errorf("Types() = %+v, want Pet from definitions", types)
+	}
+}
+
+func TestOpenAPIImporter_Analyze(t *testing.T) {
+	importer, err := LoadOpenAPI(writeSpec(t, "openapi.yaml", openAPI3Spec))
+	if err != nil {
+		t.Fatalf("LoadOpenAPI() error: %v", err)
+	}
+
+	m := &SystemModel{
+		Functions: []Function{{ID: "fn:createOrder", Name: "createOrder"}},
+		Types:     []TypeDef{{ID: "type:Order", Name: "Order"}},
+		Endpoints: []Endpoint{{ID: "ep:1", Method: "GET", Path: "/v1/orders/", Handler: "list", Framework: "express"}},
+	}
+	if err := importer.Analyze(m); err != nil {
+		t.Fatalf("Analyze() error: %v", err)
+	}
+
+	if len(m.Endpoints) != 3 {
+		t.Fatalf("len(Endpoints) = %d, want 3 (one merged)", len(m.Endpoints))
+	}
+	merged := m.Endpoints[0]
+	if merged.Handler != "list" || merged.Framework != "express" || merged.ResponseBody != "Order[]" || len(merged.QueryParams) != 2 {
+		t.Errorf("merged endpoint = %+v, want source fields kept and spec fields filled in", merged)
+	}
+	if m.Endpoints[1].Handler != "fn:createOrder" {
+		t.Errorf("Handler = %q, want the matching function's ID", m.Endpoints[1].Handler)
+	}
+
+	orders := 0
+	for _, td := range m.Types {
+		if td.Name == "Order" {
+			orders++
+		}
+	}
+	if orders != 1 || len(m.Types) != 3 {
+		t.Errorf("Types = %+v, want the source Order kept plus NewOrder and Status", m.Types)
+	}
+}
+
+func TestOpenAPIImporter_BuildsTestTargets(t *testing.T) {
+	importer, err := LoadOpenAPI(writeSpec(t, "openapi.yaml", openAPI3Spec))
+	if err != nil {
+		t.Fatalf("LoadOpenAPI() error: %v", err)
+	}
+
+	adapter := NewParserAdapter("repo", "main", "sha")
+	adapter.RegisterSupplement(importer)
+	m, err := adapter.Build()
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+
+	apiTargets := 0
+	for _, target := range m.TestTargets {
+		if target.Kind == TargetKindAPI {
+			apiTargets++
+		}
+	}
+	if apiTargets != 3 {
+		t.Errorf("API test targets = %d, want 3 without any framework supplement", apiTargets)
+	}
+	if len(m.Frameworks) != 1 || m.Frameworks[0].Framework != "openapi" || m.Frameworks[0].Confidence != 1 {
+		t.Errorf("Frameworks = %+v, want openapi detected", m.Frameworks)
+	}
+}
@@ -105,9 +105,26 @@ func ConvertParsedFile(pf *parser.ParsedFile) *ParsedFile {
 	}
 }
 
-// BuildSystemModelFromParser builds a SystemModel by parsing files in a directory
-func BuildSystemModelFromParser(ctx context.Context, p *parser.Parser, workspacePath, repoName, branch, commitSHA string) (*SystemModel, error) {
+// BuildOptions configures BuildSystemModelFromParser
+type BuildOptions struct {
+	// Supplements run on the parsed files, in order
+	Supplements []Supplement
+
+	// Disabled names supplements that don't run, whatever their detection
+	// finds
+	Disabled []string
+}
+
+// BuildSystemModelFromParser builds a SystemModel by parsing files in a
+// directory and running the supplements in opts on them
+func BuildSystemModelFromParser(ctx context.Context, p *parser.Parser, workspacePath, repoName, branch, commitSHA string, opts BuildOptions) (*SystemModel, error) {
 	adapter := NewParserAdapter(repoName, branch, commitSHA)
+	for _, s := range opts.Supplements {
+		adapter.RegisterSupplement(s)
+	}
+	for _, name := range opts.Disabled {
+		adapter.OverrideFramework(name, false)
+	}
 
 	// Use the parser's directory walking capability
 	files, err := p.ParseDirectory(ctx, workspacePath)
@@ -3,6 +3,7 @@ package model
 import (
 	"fmt"
 	"sort"
+	"strings"
 )
 
 // PlannerConfig configures the test planner
@@ -49,6 +50,7 @@ func (p *Planner) Plan(model *SystemModel) (*TestPlan, error) {
 		Repository: model.Repository,
 		Intents:    make([]TestIntent, 0),
 	}
+	callers := callerCounts(model)
 
 	// 1. Generate API test intents for all endpoints (highest priority)
 	for _, ep := range model.Endpoints {
@@ -58,8 +60,12 @@ func (p *Planner) Plan(model *SystemModel) (*TestPlan, error) {
 			TargetKind: "endpoint",
 			TargetID:   ep.ID,
 			Priority:   "high", // API endpoints are always high priority
-			Reason:     fmt.Sprintf("API endpoint: %s %s", ep.Method, ep.Path),
+			Reason:     "API endpoint: " + ep.Describe(),
 		}
+		handler := handlerFor(model, ep)
+		intent.Rationale = riskRationale(model, handler, callers)
+		intent.Rationale.Rule = "API endpoints are always high priority"
+		markSlow(&intent, handler)
 		plan.Intents = append(plan.Intents, intent)
 		plan.APITests++
 	}
@@ -89,12 +95,7 @@ func (p *Planner) Plan(model *SystemModel) (*TestPlan, error) {
 
 	// Generate intents for functions
 	for _, sf := range scoredFuncs {
-		priority := "low"
-		if sf.score >= p.config.HighRiskThreshold {
-			priority = "high"
-		} else if sf.score >= p.config.MediumRiskThreshold {
-			priority = "medium"
-		}
+		priority, rule := p.priorityFor(sf.score)
 
 		// Skip functions that are likely endpoint handlers (already covered by API tests)
 		isHandler := false
@@ -120,7 +121,10 @@ func (p *Planner) Plan(model *SystemModel) (*TestPlan, error) {
 			TargetID:   sf.fn.ID,
 			Priority:   priority,
 			Reason:     reason,
+			Rationale:  riskRationale(model, &sf.fn, callers),
 		}
+		intent.Rationale.Rule = rule
+		markSlow(&intent, &sf.fn)
 		plan.Intents = append(plan.Intents, intent)
 		plan.UnitTests++
 	}
@@ -144,7 +148,7 @@ func (p *Planner) Plan(model *SystemModel) (*TestPlan, error) {
 		}
 	}
 
-	plan.TotalTests = len(plan.Intents)
+	p.finish(plan)
 
 	return plan, nil
 }
@@ -161,6 +165,7 @@ func (p *Planner) PlanWithPyramid(model *SystemModel, targetTotal int) (*TestPla
 		Repository: model.Repository,
 		Intents:    make([]TestIntent, 0),
 	}
+	callers := callerCounts(model)
 
 	// Add API tests (up to target)
 	apiCount := 0
@@ -174,8 +179,12 @@ func (p *Planner) PlanWithPyramid(model *SystemModel, targetTotal int) (*TestPla
 			TargetKind: "endpoint",
 			TargetID:   ep.ID,
 			Priority:   "high",
-			Reason:     fmt.Sprintf("API endpoint: %s %s", ep.Method, ep.Path),
+			Reason:     "API endpoint: " + ep.Describe(),
 		}
+		handler := handlerFor(model, ep)
+		intent.Rationale = riskRationale(model, handler, callers)
+		intent.Rationale.Rule = "API endpoints are always high priority"
+		markSlow(&intent, handler)
 		plan.Intents = append(plan.Intents, intent)
 		apiCount++
 	}
@@ -208,12 +217,7 @@ func (p *Planner) PlanWithPyramid(model *SystemModel, targetTotal int) (*TestPla
 			score = rs.Score
 		}
 
-		priority := "low"
-		if score >= p.config.HighRiskThreshold {
-			priority = "high"
-		} else if score >= p.config.MediumRiskThreshold {
-			priority = "medium"
-		}
+		priority, rule := p.priorityFor(score)
 
 		intent := TestIntent{
 			ID:         fmt.Sprintf("intent:unit:%s", fn.ID),
@@ -222,7 +226,10 @@ func (p *Planner) PlanWithPyramid(model *SystemModel, targetTotal int) (*TestPla
 			TargetID:   fn.ID,
 			Priority:   priority,
 			Reason:     fmt.Sprintf("Exported function (risk: %.2f)", score),
+			Rationale:  riskRationale(model, &fn, callers),
 		}
+		intent.Rationale.Rule = rule
+		markSlow(&intent, &fn)
 		plan.Intents = append(plan.Intents, intent)
 		unitCount++
 	}
@@ -231,7 +238,124 @@ func (p *Planner) PlanWithPyramid(model *SystemModel, targetTotal int) (*TestPla
 	// E2E tests would be added here when we have flow detection
 	plan.E2ETests = 0
 
-	plan.TotalTests = len(plan.Intents)
+	p.finish(plan)
 
 	return plan, nil
 }
+
+// finish counts a plan's intents, ranks them, and records how they were
+// scored
+func (p *Planner) finish(plan *TestPlan) {
+	plan.TotalTests = len(plan.Intents)
+	plan.rank()
+	plan.assignLineage()
+	plan.Statistics = plan.Stats()
+	plan.Scoring = &PlanScoring{
+		ComplexityWeight:    RiskWeightComplexity,
+		CentralityWeight:    RiskWeightCentrality,
+		ChurnWeight:         RiskWeightChurn,
+		HighRiskThreshold:   p.config.HighRiskThreshold,
+		MediumRiskThreshold: p.config.MediumRiskThreshold,
+	}
+}
+
+// priorityFor maps a risk score to a priority and the rule that decided it
+func (p *Planner) priorityFor(score float64) (string, string) {
+	switch {
+	case score >= p.config.HighRiskThreshold:
+		return "high", fmt.Sprintf("risk %.2f >= high threshold %.2f", score, p.config.HighRiskThreshold)
+	case score >= p.config.MediumRiskThreshold:
+		return "medium", fmt.Sprintf("risk %.2f >= medium threshold %.2f", score, p.config.MediumRiskThreshold)
+	default:
+		return "low", fmt.Sprintf("risk %.2f < medium threshold %.2f", score, p.config.MediumRiskThreshold)
+	}
+}
+
+// riskRationale explains a function's risk from its score components; fn
+// may be nil for endpoints whose handler isn't in the model
+func riskRationale(model *SystemModel, fn *Function, callers map[string]int) *IntentRationale {
+	r := &IntentRationale{CoverageGap: true}
+	if fn == nil {
+		return r
+	}
+	rs := model.RiskScores[fn.ID]
+	r.RiskScore = rs.Score
+	r.Complexity = rs.Complexity
+	r.Centrality = rs.Centrality
+	r.Churn = rs.Churn
+	r.CoverageGap = !rs.HasTests
+	r.LOC = fn.LOC
+	r.Callers = callers[fn.ID]
+	return r
+}
+
+// callerCounts counts the call graph edges into each function
+func callerCounts(model *SystemModel) map[string]int {
+	counts := make(map[string]int)
+	for _, edge := range model.CallGraph {
+		counts[edge.Callee]++
+	}
+	return counts
+}
+
+// slowIndicators are source fragments suggesting a test will be slow:
+// outbound network calls, large fixtures on disk, or explicit sleeps
+var slowIndicators = map[string]string{
+	"http.Get(":          "external API call",
+	"http.Post(":         "external API call",
+	"http.DefaultClient": "external API call",
+	"requests.":          "external API call",
+	"httpx.":             "external API call",
+	"aiohttp.":           "external API call",
+	"fetch(":             "external API call",
+	"axios.":             "external API call",
+	"grpc.Dial":          "external API call",
+	"sql.Open(":          "database connection",
+	"pgx.Connect":        "database connection",
+	"psycopg2.connect":   "database connection",
+	"ioutil.ReadFile(":   "file fixture",
+	"os.ReadFile(":       "file fixture",
+	"fs.readFileSync(":   "file fixture",
+	"open(":              "file fixture",
+	"time.Sleep(":        "explicit sleep",
+	"time.sleep(":        "explicit sleep",
+	"setTimeout(":        "explicit sleep",
+}
+
+// SlowReason returns why a function's tests are likely to be slow, or ""
+func SlowReason(fn *Function) string {
+	if fn == nil || fn.Body == "" {
+		return ""
+	}
+
+	var reasons []string
+	seen := make(map[string]bool)
+	for indicator, reason := range slowIndicators {
+		if strings.Contains(fn.Body, indicator) && !seen[reason] {
+			seen[reason] = true
+			reasons = append(reasons, reason)
+		}
+	}
+	sort.Strings(reasons)
+	return strings.Join(reasons, ", ")
+}
+
+// markSlow tags an intent as slow when its target looks slow to exercise
+func markSlow(intent *TestIntent, fn *Function) {
+	reason := SlowReason(fn)
+	if reason == "" {
+		return
+	}
+	intent.Tags = append(intent.Tags, TagSlow)
+	intent.Reason = fmt.Sprintf("%s [slow: %s]", intent.Reason, reason)
+}
+
+// handlerFor returns the function handling an endpoint, if it is in the model
+func handlerFor(model *SystemModel, ep Endpoint) *Function {
+	for i := range model.Functions {
+		if model.Functions[i].Name == ep.Handler || model.Functions[i].ID == ep.Handler {
+			return &model.Functions[i]
+		}
+	}
+	return nil
+}
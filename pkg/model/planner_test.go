@@ -1,6 +1,7 @@
 package model
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -438,3 +439,142 @@ func TestPlanner_PlanWithPyramid_EmptyModel(t *testing.T) {
 }
 
 // Note: TestIntent_Fields and TestLevel_Constants are defined in intent_test.go
+
+func TestPlanner_MarksSlowTargets(t *testing.T) {
+	m := &SystemModel{
+		Functions: []Function{
+			{ID: "f1", Name: "FetchRates", Exported: true, Body: "resp, err := http.Get(url)"},
+			{ID: "f2", Name: "Add", Exported: true, Body: "return a + b"},
+			{ID: "f3", Name: "listUsers", Body: "rows, _ := sql.Open(driver, dsn)"},
+		},
+		Endpoints: []Endpoint{
+			{ID: "e1", Method: "GET", Path: "/users", Handler: "listUsers"},
+		},
+	}
+
+	plan, err := NewPlanner(DefaultPlannerConfig()).Plan(m)
+	if err != nil {
+		t.Fatalf("Plan() error: %v", err)
+	}
+
+	slow := map[string]bool{}
+	for _, intent := range plan.Intents {
+		for _, tag := range intent.Tags {
+			if tag == TagSlow {
+				slow[intent.TargetID] = true
+				if !strings.Contains(intent.Reason, "[slow:") {
+					t.Errorf("slow intent %s should explain why: %s", intent.ID, intent.Reason)
+				}
+			}
+		}
+	}
+
+	if !slow["f1"] || !slow["e1"] || slow["f2"] {
+		t.Errorf("unexpected slow targets: %v", slow)
+	}
+}
+
+func TestPlanner_AssignsLineageIDs(t *testing.T) {
+	m := &SystemModel{
+		Functions: []Function{{ID: "f1", Name: "Add", Exported: true}},
+		Endpoints: []Endpoint{{ID: "e1", Method: "GET", Path: "/users", Handler: "listUsers"}},
+	}
+
+	first, _ := NewPlanner(DefaultPlannerConfig()).Plan(m)
+	second, _ := NewPlanner(DefaultPlannerConfig()).Plan(m)
+
+	seen := map[string]bool{}
+	for i, intent := range first.Intents {
+		if !strings.HasPrefix(intent.LineageID, "ln-") {
+			t.Errorf("intent %s has no lineage ID", intent.ID)
+		}
+		if seen[intent.LineageID] {
+			t.Errorf("duplicate lineage ID %s", intent.LineageID)
+		}
+		seen[intent.LineageID] = true
+		if second.Intents[i].LineageID != intent.LineageID {
+			t.Errorf("lineage ID for %s not stable across runs", intent.ID)
+		}
+	}
+}
+
+func TestPlanner_Plan_GraphQLReason(t *testing.T) {
+	planner := NewPlanner(DefaultPlannerConfig())
+
+	m := &SystemModel{
+		Endpoints: []Endpoint{
+			{ID: "ep:graphql:query:users", Method: "POST", Path: "/graphql",
+				GraphQL: &GraphQLOperation{Kind: "query", Field: "users"}},
+		},
+	}
+
+	plan, err := planner.Plan(m)
+	if err != nil {
+		t.Fatalf("Plan() error: %v", err)
+	}
+	if len(plan.Intents) != 1 {
+		t.Fatalf("len(Intents) = %d, want 1", len(plan.Intents))
+	}
+	if want := "API endpoint: GraphQL query users"; plan.Intents[0].Reason != want {
+		t.Errorf("Reason = %q, want %q", plan.Intents[0].Reason, want)
+	}
+}
+
+func TestPlanner_Plan_Rationale(t *testing.T) {
+	planner := NewPlanner(DefaultPlannerConfig())
+
+	model := &SystemModel{
+		Functions: []Function{
+			{ID: "fn1", Name: "Parse", Exported: true, LOC: 30},
+			{ID: "fn2", Name: "getUser", Exported: true, LOC: 12},
+		},
+		Endpoints: []Endpoint{{ID: "ep1", Method: "GET", Path: "/users/:id", Handler: "getUser"}},
+		CallGraph: []CallEdge{{Caller: "fn2", Callee: "fn1"}, {Caller: "fn3", Callee: "fn1"}},
+		RiskScores: map[string]RiskScore{
+			"fn1": {FunctionID: "fn1", Score: 0.39, Complexity: 0.6, Centrality: 0.3},
+			"fn2": {FunctionID: "fn2", Score: 0.15, Complexity: 0.3, HasTests: true},
+		},
+	}
+
+	plan, err := planner.Plan(model)
+	if err != nil {
+		t.Fatalf("Plan() error: %v", err)
+	}
+	if len(plan.Intents) != 2 {
+		t.Fatalf("got %d intents, want 2", len(plan.Intents))
+	}
+
+	api := plan.Intents[0].Rationale
+	if api == nil || api.Rank != 1 || api.Rule != "API endpoints are always high priority" || api.CoverageGap || api.LOC != 12 {
+		t.Errorf("API rationale = %+v, want rank 1 from the covered getUser handler", api)
+	}
+
+	unit := plan.Intents[1].Rationale
+	if unit == nil {
+		t.Fatal("unit intent has no rationale")
+	}
+	if unit.Rank != 2 || unit.RiskScore != 0.39 || unit.Complexity != 0.6 || unit.Centrality != 0.3 ||
+		unit.LOC != 30 || unit.Callers != 2 || !unit.CoverageGap {
+		t.Errorf("unit rationale = %+v", unit)
+	}
+	if want := "risk 0.39 < medium threshold 0.40"; unit.Rule != want {
+		t.Errorf("Rule = %q, want %q", unit.Rule, want)
+	}
+
+	if plan.Scoring == nil || plan.Scoring.ComplexityWeight != RiskWeightComplexity || plan.Scoring.HighRiskThreshold != 0.7 {
+		t.Errorf("Scoring = %+v", plan.Scoring)
+	}
+	if plan.Statistics["high"] != 1 || plan.Statistics["low"] != 1 {
+		t.Errorf("Statistics = %v", plan.Statistics)
+	}
+
+	pyramid, err := planner.PlanWithPyramid(model, 10)
+	if err != nil {
+		t.Fatalf("PlanWithPyramid() error: %v", err)
+	}
+	for _, intent := range pyramid.Intents {
+		if intent.Rationale == nil || intent.Rationale.Rule == "" {
+			t.Errorf("pyramid intent %s has no rationale", intent.ID)
+		}
+	}
+}
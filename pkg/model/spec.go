@@ -1,10 +1,235 @@
 package model
 
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
 // Assertion represents a single test assertion
 type Assertion struct {
-	Kind     string      `json:"kind" yaml:"kind"`         // "equality", "contains", "not_null", "status_code", "expression"
-	Actual   string      `json:"actual" yaml:"actual"`     // "result", "status", "body.id", "response.data[0].name"
-	Expected interface{} `json:"expected" yaml:"expected"` // expected value
+	Kind     string      `json:"kind" yaml:"kind"`         // "equality", "contains", "not_null", "status_code", "expression", "throws", "approx", "matches", "length", "contains_all", "subset", "sorted", "header_*", "cookie_*", "latency"
+	Actual   string      `json:"actual" yaml:"actual"`     // "result", "status", "body.id", "response.data[0].name", a header or cookie name
+	Expected interface{} `json:"expected" yaml:"expected"` // expected value; for "matches", a regular expression; for "sorted", the sort key
+
+	// How far an "approx" value may be from Expected; 0 for DefaultTolerance
+	Tolerance float64 `json:"tolerance,omitempty" yaml:"tolerance,omitempty"`
+
+	// What a "throws" or "raises" assertion expects the call to fail with;
+	// either may be empty
+	ErrorType    string `json:"error_type,omitempty" yaml:"error_type,omitempty"`       // e.g. ValueError, TypeError, ErrNotFound
+	ErrorMessage string `json:"error_message,omitempty" yaml:"error_message,omitempty"` // a substring of the error's message
+}
+
+// Assertion kinds for calls expected to fail. "raises" is the Python
+// spelling of "throws"; each framework renders them its own way.
+const (
+	AssertThrows = "throws"
+	AssertRaises = "raises"
+)
+
+// Assertion kinds for values that can't be compared exactly: a number
+// within a tolerance of Expected, like a float result, and a string
+// matching the regular expression in Expected, like a timestamp or ID
+const (
+	AssertApprox  = "approx"
+	AssertMatches = "matches"
+)
+
+// DefaultTolerance is the tolerance of "approx" assertions that don't set one
+const DefaultTolerance = 1e-9
+
+// Epsilon returns how far an "approx" value may be from Expected
+func (a Assertion) Epsilon() float64 {
+	if a.Tolerance > 0 {
+		return a.Tolerance
+	}
+	return DefaultTolerance
+}
+
+// Assertion kinds for collections: its length, items it must include
+// (Expected lists them), items it may only draw from (Expected lists
+// them), and its order (Expected is the key items are sorted by, "" for
+// the items themselves, prefixed with "-" for descending order)
+const (
+	AssertLength      = "length"
+	AssertContainsAll = "contains_all"
+	AssertSubset      = "subset"
+	AssertSorted      = "sorted"
+)
+
+// SortKey returns the field a "sorted" assertion orders items by, "" for
+// the items themselves, and whether the order is descending
+func (a Assertion) SortKey() (key string, descending bool) {
+	key, _ = a.Expected.(string)
+	switch {
+	case key == "desc" || key == "descending":
+		return "", true
+	case key == "asc" || key == "ascending":
+		return "", false
+	case strings.HasPrefix(key, "-"):
+		return strings.TrimPrefix(key, "-"), true
+	}
+	return key, false
+}
+
+// ExpectedItems returns the items a "contains_all" or "subset" assertion
+// lists, or Expected itself when it isn't a list
+func (a Assertion) ExpectedItems() []interface{} {
+	switch v := a.Expected.(type) {
+	case []interface{}:
+		return v
+	case []string:
+		items := make([]interface{}, len(v))
+		for i, s := range v {
+			items[i] = s
+		}
+		return items
+	case nil:
+		return nil
+	}
+	return []interface{}{a.Expected}
+}
+
+// Assertion kinds for an API response's headers and the cookies it sets.
+// Actual names the header or cookie; Expected is the value for "equals"
+// and a regular expression for "matches".
+const (
+	AssertHeaderPresent = "header_present"
+	AssertHeaderEquals  = "header_equals"
+	AssertHeaderMatches = "header_matches"
+	AssertCookiePresent = "cookie_present"
+	AssertCookieEquals  = "cookie_equals"
+	AssertCookieMatches = "cookie_matches"
+)
+
+// Response assertion targets and checks, as ResponseCheck returns them
+const (
+	ResponseHeader = "header"
+	ResponseCookie = "cookie"
+
+	CheckPresent = "present"
+	CheckEquals  = "equals"
+	CheckMatches = "matches"
+)
+
+// ResponseCheck splits a header or cookie assertion into its target,
+// ResponseHeader or ResponseCookie, and its check: CheckPresent,
+// CheckEquals or CheckMatches. ok is false for other kinds.
+func (a Assertion) ResponseCheck() (target, check string, ok bool) {
+	target, check, ok = strings.Cut(a.Kind, "_")
+	if !ok || (target != ResponseHeader && target != ResponseCookie) {
+		return "", "", false
+	}
+	switch check {
+	case CheckPresent, CheckEquals, CheckMatches:
+		return target, check, true
+	}
+	return "", "", false
+}
+
+// ResponseName returns the header or cookie a response assertion checks:
+// Actual without a "headers." or "cookies." prefix
+func (a Assertion) ResponseName() string {
+	name := strings.TrimSpace(a.Actual)
+	for _, prefix := range []string{"headers.", "header.", "cookies.", "cookie."} {
+		if strings.HasPrefix(strings.ToLower(name), prefix) {
+			return name[len(prefix):]
+		}
+	}
+	return name
+}
+
+// ExpectedString returns Expected as text, "" when it's unset
+func (a Assertion) ExpectedString() string {
+	if a.Expected == nil {
+		return ""
+	}
+	return fmt.Sprintf("%v", a.Expected)
+}
+
+// AssertLatency checks an API endpoint's response times. Expected is the
+// p95 budget in milliseconds (or a duration such as "300ms"), or a map of
+// "p50" and "p95" budgets and the number of "samples" to time.
+const AssertLatency = "latency"
+
+// DefaultLatencySamples is how many requests a latency assertion times when
+// it doesn't say
+const DefaultLatencySamples = 20
+
+// LatencyBudget is what a latency assertion allows. Budgets are in
+// milliseconds; 0 doesn't check that percentile.
+type LatencyBudget struct {
+	P50     float64
+	P95     float64
+	Samples int
+}
+
+// LatencyBudget reads a latency assertion's budgets from Expected
+func (a Assertion) LatencyBudget() LatencyBudget {
+	budget := LatencyBudget{Samples: DefaultLatencySamples}
+	if m, ok := a.Expected.(map[string]interface{}); ok {
+		budget.P50 = latencyMillis(m["p50"])
+		budget.P95 = latencyMillis(m["p95"])
+		if n := int(latencyMillis(m["samples"])); n > 0 {
+			budget.Samples = n
+		}
+		return budget
+	}
+	budget.P95 = latencyMillis(a.Expected)
+	return budget
+}
+
+// latencyMillis reads a budget as milliseconds: a number, or a string that's
+// a number or a duration like "250ms"
+func latencyMillis(v interface{}) float64 {
+	switch n := v.(type) {
+	case float64:
+		return n
+	case int:
+		return float64(n)
+	case int64:
+		return float64(n)
+	case string:
+		if f, err := strconv.ParseFloat(n, 64); err == nil {
+			return f
+		}
+		if d, err := time.ParseDuration(n); err == nil {
+			return float64(d) / float64(time.Millisecond)
+		}
+	}
+	return 0
+}
+
+// ExpectsError reports whether the assertion expects the call to fail
+func (a Assertion) ExpectsError() bool {
+	switch a.Kind {
+	case AssertThrows, AssertRaises, "error":
+		return true
+	}
+	return false
+}
+
+// GraphQLRequest is the body of a GraphQL request
+type GraphQLRequest struct {
+	Query         string                 `json:"query" yaml:"query"`
+	Variables     map[string]interface{} `json:"variables,omitempty" yaml:"variables,omitempty"`
+	OperationName string                 `json:"operationName,omitempty" yaml:"operation_name,omitempty"`
+}
+
+// GRPCCall is the unary RPC a gRPC test makes
+type GRPCCall struct {
+	Service     string                 `json:"service" yaml:"service"` // fully qualified, e.g. users.v1.UserService
+	Method      string                 `json:"method" yaml:"method"`
+	RequestType string                 `json:"request_type,omitempty" yaml:"request_type,omitempty"`
+	Request     map[string]interface{} `json:"request,omitempty" yaml:"request,omitempty"` // request message fields, by proto name
+	Code        string                 `json:"code,omitempty" yaml:"code,omitempty"`       // expected status, e.g. NOT_FOUND; OK if empty
+
+	// Where the service is registered, copied from the model's GRPCMethod
+	Stubs        string `json:"stubs,omitempty" yaml:"stubs,omitempty"`
+	Server       string `json:"server,omitempty" yaml:"server,omitempty"`
+	ServerModule string `json:"server_module,omitempty" yaml:"server_module,omitempty"`
 }
 
 // TestSpec represents a complete test specification
@@ -18,9 +243,10 @@ type TestSpec struct {
 
 	// For function tests
 	FunctionName string                 `json:"function_name,omitempty" yaml:"function_name,omitempty"`
-	Inputs       map[string]interface{} `json:"inputs,omitempty" yaml:"inputs,omitempty"`       // function args (name -> value)
+	Inputs       map[string]interface{} `json:"inputs,omitempty" yaml:"inputs,omitempty"`           // function args (name -> value)
 	InputTypes   map[string]string      `json:"input_types,omitempty" yaml:"input_types,omitempty"` // type hints (name -> type)
 	ArgOrder     []string               `json:"arg_order,omitempty" yaml:"arg_order,omitempty"`     // ordered argument names
+	Async        bool                   `json:"async,omitempty" yaml:"async,omitempty"`             // the call's result must be awaited: a promise, coroutine or Go channel
 
 	// For API tests
 	Method      string                 `json:"method,omitempty" yaml:"method,omitempty"`           // GET, POST, etc.
@@ -30,13 +256,57 @@ type TestSpec struct {
 	Headers     map[string]string      `json:"headers,omitempty" yaml:"headers,omitempty"`
 	Body        interface{}            `json:"body,omitempty" yaml:"body,omitempty"` // request body
 
+	// For GraphQL tests: the operation, POSTed to Path (default /graphql)
+	GraphQL *GraphQLRequest `json:"graphql,omitempty" yaml:"graphql,omitempty"`
+
+	// For gRPC tests: the RPC, called through a client of its service
+	GRPC *GRPCCall `json:"grpc,omitempty" yaml:"grpc,omitempty"`
+
 	// Expected outcomes
 	Expected   map[string]interface{} `json:"expected,omitempty" yaml:"expected,omitempty"` // status, body, etc.
 	Assertions []Assertion            `json:"assertions" yaml:"assertions"`
 
 	// Metadata
-	Tags     []string `json:"tags,omitempty" yaml:"tags,omitempty"`
-	Priority string   `json:"priority,omitempty" yaml:"priority,omitempty"`
+	Tags      []string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Priority  string   `json:"priority,omitempty" yaml:"priority,omitempty"`
+	LineageID string   `json:"lineage_id,omitempty" yaml:"lineage_id,omitempty"` // copied from the originating intent
+
+	// Execution hints for inherently slow tests (0 = framework default)
+	TimeoutSeconds int `json:"timeout_seconds,omitempty" yaml:"timeout_seconds,omitempty"`
+	Retries        int `json:"retries,omitempty" yaml:"retries,omitempty"`
+}
+
+// Defaults applied to tests tagged "slow" that don't set their own limits
+const (
+	TagSlow                   = "slow"
+	DefaultSlowTimeoutSeconds = 30
+	DefaultSlowRetries        = 2
+)
+
+// IsSlow reports whether the spec is tagged slow
+func (s *TestSpec) IsSlow() bool {
+	for _, tag := range s.Tags {
+		if tag == TagSlow {
+			return true
+		}
+	}
+	return false
+}
+
+// ExecutionLimits returns the timeout (seconds) and retry count emitters
+// should apply. Slow specs fall back to the slow defaults; other specs
+// return zeros, meaning the framework default.
+func (s *TestSpec) ExecutionLimits() (timeoutSeconds, retries int) {
+	timeoutSeconds, retries = s.TimeoutSeconds, s.Retries
+	if s.IsSlow() {
+		if timeoutSeconds == 0 {
+			timeoutSeconds = DefaultSlowTimeoutSeconds
+		}
+		if retries == 0 {
+			retries = DefaultSlowRetries
+		}
+	}
+	return timeoutSeconds, retries
 }
 
 // TestSpecSet is a collection of test specs
@@ -293,3 +293,67 @@ func TestTestLevel_Constants(t *testing.T) {
 		}
 	}
 }
+
+func TestTestSpec_ExecutionLimits(t *testing.T) {
+	tests := []struct {
+		name        string
+		spec        TestSpec
+		wantTimeout int
+		wantRetries int
+	}{
+		{"default", TestSpec{}, 0, 0},
+		{"slow defaults", TestSpec{Tags: []string{TagSlow}}, DefaultSlowTimeoutSeconds, DefaultSlowRetries},
+		{"slow override", TestSpec{Tags: []string{TagSlow}, TimeoutSeconds: 120}, 120, DefaultSlowRetries},
+		{"explicit only", TestSpec{TimeoutSeconds: 10, Retries: 1}, 10, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			timeout, retries := tt.spec.ExecutionLimits()
+			if timeout != tt.wantTimeout || retries != tt.wantRetries {
+				t.Errorf("ExecutionLimits() = (%d, %d), want (%d, %d)", timeout, retries, tt.wantTimeout, tt.wantRetries)
+			}
+		})
+	}
+}
+
+func TestAssertion_ResponseCheck(t *testing.T) {
+	tests := []struct {
+		assertion     Assertion
+		target, check string
+		name          string
+		ok            bool
+	}{
+		{Assertion{Kind: AssertHeaderEquals, Actual: "headers.Content-Type"}, ResponseHeader, CheckEquals, "Content-Type", true},
+		{Assertion{Kind: AssertCookiePresent, Actual: "session"}, ResponseCookie, CheckPresent, "session", true},
+		{Assertion{Kind: AssertCookieMatches, Actual: "Cookies.theme"}, ResponseCookie, CheckMatches, "theme", true},
+		{Assertion{Kind: AssertMatches, Actual: "body.id"}, "", "", "body.id", false},
+		{Assertion{Kind: "header_contains", Actual: "Vary"}, "", "", "Vary", false},
+	}
+	for _, tt := range tests {
+		target, check, ok := tt.assertion.ResponseCheck()
+		if target != tt.target || check != tt.check || ok != tt.ok {
+			t.Errorf("ResponseCheck(%s) = %q, %q, %v, want %q, %q, %v", tt.assertion.Kind, target, check, ok, tt.target, tt.check, tt.ok)
+		}
+		if name := tt.assertion.ResponseName(); name != tt.name {
+			t.Errorf("ResponseName(%s) = %q, want %q", tt.assertion.Actual, name, tt.name)
+		}
+	}
+}
+
+func TestAssertion_LatencyBudget(t *testing.T) {
+	tests := []struct {
+		expected interface{}
+		want     LatencyBudget
+	}{
+		{float64(250), LatencyBudget{P95: 250, Samples: DefaultLatencySamples}},
+		{"1.5s", LatencyBudget{P95: 1500, Samples: DefaultLatencySamples}},
+		{map[string]interface{}{"p50": float64(100), "p95": "300ms", "samples": float64(5)}, LatencyBudget{P50: 100, P95: 300, Samples: 5}},
+		{nil, LatencyBudget{Samples: DefaultLatencySamples}},
+	}
+	for _, tt := range tests {
+		if got := (Assertion{Kind: AssertLatency, Expected: tt.expected}).LatencyBudget(); got != tt.want {
+			t.Errorf("LatencyBudget(%v) = %+v, want %+v", tt.expected, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,512 @@
+package model
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Testability smell kinds
+const (
+	SmellGlobalState      = "global_state"
+	SmellClock            = "clock"
+	SmellEnvironment      = "environment"
+	SmellRandomness       = "randomness"
+	SmellNetwork          = "network"
+	SmellDatabase         = "database"
+	SmellFilesystem       = "filesystem"
+	SmellProcess          = "process"
+	SmellLongFunction     = "long_function"
+	SmellComplex          = "complex"
+	SmellParameters       = "parameters"
+	SmellConstructorIO    = "constructor_io"
+	SmellMissingInterface = "missing_interface"
+)
+
+// TestabilitySmell is a reason code is hard to unit test, with a refactoring
+// that would make it easier
+type TestabilitySmell struct {
+	Kind       string `json:"kind"`
+	Line       int    `json:"line,omitempty"` // line in the source file
+	Evidence   string `json:"evidence,omitempty"`
+	Suggestion string `json:"suggestion"`
+}
+
+// TestabilityConfig tunes the testability analysis
+type TestabilityConfig struct {
+	MaxLOC        int // Longer functions are flagged (default: 50)
+	MaxBranches   int // More branches are flagged (default: 10)
+	MaxParameters int // More parameters are flagged (default: 5)
+}
+
+// DefaultTestabilityConfig returns default testability thresholds
+func DefaultTestabilityConfig() TestabilityConfig {
+	return TestabilityConfig{
+		MaxLOC:        50,
+		MaxBranches:   10,
+		MaxParameters: 5,
+	}
+}
+
+// TestabilityTarget is a function or type with testability smells
+type TestabilityTarget struct {
+	ID       string             `json:"id"`
+	Name     string             `json:"name"`
+	Kind     string             `json:"kind"` // function, type
+	File     string             `json:"file"`
+	Line     int                `json:"line"`
+	Exported bool               `json:"exported"`
+	Score    float64            `json:"score"`    // higher = fix first
+	Priority string             `json:"priority"` // high, medium, low
+	Smells   []TestabilitySmell `json:"smells"`
+}
+
+// TestabilityReport lists a model's hard-to-test targets, worst first
+type TestabilityReport struct {
+	Repository string              `json:"repository"`
+	Analyzed   int                 `json:"analyzed"` // functions and types looked at
+	Targets    []TestabilityTarget `json:"targets"`
+	Summary    map[string]int      `json:"summary"` // smell kind -> targets with it
+}
+
+// smellWeights rank smells by how much they get in the way of a unit test
+var smellWeights = map[string]float64{
+	SmellConstructorIO:    3,
+	SmellGlobalState:      3,
+	SmellDatabase:         2.5,
+	SmellNetwork:          2.5,
+	SmellProcess:          2.5,
+	SmellMissingInterface: 2,
+	SmellFilesystem:       2,
+	SmellClock:            1.5,
+	SmellRandomness:       1.5,
+	SmellEnvironment:      1.5,
+	SmellLongFunction:     1.5,
+	SmellComplex:          1.5,
+	SmellParameters:       1,
+}
+
+// ioSmells are the smells that mean a function does I/O
+var ioSmells = map[string]bool{
+	SmellNetwork:    true,
+	SmellDatabase:   true,
+	SmellFilesystem: true,
+	SmellProcess:    true,
+}
+
+// bodyRule flags a line of a function body
+type bodyRule struct {
+	kind       string
+	pattern    *regexp.Regexp
+	suggestion string
+}
+
+var bodyRules = map[string][]bodyRule{
+	"go": {
+		{SmellClock, regexp.MustCompile(`\btime\.(Now|Since|Until|Sleep|After|Tick)\(`),
+			"Pass the current time in, or inject a clock (e.g. a `now func() time.Time` field)."},
+		{SmellEnvironment, regexp.MustCompile(`\bos\.(Getenv|LookupEnv|Environ)\(`),
+			"Read configuration once at startup and pass the values in as parameters or struct fields."},
+		{SmellRandomness, regexp.MustCompile(`\brand\.\w+\(`),
+			"Accept a *rand.Rand or a seed so tests can make the output deterministic."},
+		{SmellNetwork, regexp.MustCompile(`\bhttp\.(Get|Post|Head|PostForm|DefaultClient)\b|\bnet\.Dial`),
+			"Depend on an interface or an injected *http.Client so tests can substitute a fake."},
+		{SmellDatabase, regexp.MustCompile(`\bsql\.Open\(|\.(Query|QueryRow|Exec)(Context)?\(`),
+			"Move queries behind a repository interface and pass it in."},
+		{SmellFilesystem, regexp.MustCompile(`\bos\.(Open|Create|ReadFile|WriteFile|Remove|RemoveAll|Mkdir|MkdirAll)\(|\bioutil\.`),
+			"Take an io.Reader/io.Writer or an fs.FS instead of opening paths directly."},
+		{SmellProcess, regexp.MustCompile(`\bos\.Exit\(|\blog\.Fatal|\bexec\.Command`),
+			"Return an error instead of exiting, and wrap external commands behind an interface."},
+	},
+	"python": {
+		{SmellClock, regexp.MustCompile(`\b(datetime\.(now|utcnow|today)|time\.(time|sleep))\(`),
+			"Pass the current time in, or inject a clock callable."},
+		{SmellEnvironment, regexp.MustCompile(`\bos\.(environ|getenv)\b`),
+			"Read configuration once and pass the values in as arguments."},
+		{SmellRandomness, regexp.MustCompile(`\brandom\.\w+\(|\buuid\.uuid4\(`),
+			"Accept a random.Random instance or a seed so tests are deterministic."},
+		{SmellNetwork, regexp.MustCompile(`\brequests\.(get|post|put|delete|patch)\(|\burlopen\(|\bsocket\.`),
+			"Inject the HTTP session or client so tests can pass a fake."},
+		{SmellDatabase, regexp.MustCompile(`\b(connect|cursor)\(\)|\.execute\(`),
+			"Move queries behind a repository object and pass it in."},
+		{SmellFilesystem, regexp.MustCompile(`\bopen\(|\bos\.(remove|makedirs|mkdir)\(|\bshutil\.`),
+			"Accept a file object or path-like argument instead of opening files internally."},
+		{SmellProcess, regexp.MustCompile(`\bsys\.exit\(|\bsubprocess\.`),
+			"Raise an exception instead of exiting, and wrap subprocess calls behind a function you can replace."},
+		{SmellGlobalState, regexp.MustCompile(`^\s*global\s+\w+`),
+			"Keep the state on an object and pass it in instead of mutating module globals."},
+	},
+	"typescript": jsBodyRules,
+	"javascript": jsBodyRules,
+}
+
+var jsBodyRules = []bodyRule{
+	{SmellClock, regexp.MustCompile(`\b(Date\.now\(|new Date\(\)|setTimeout\(|setInterval\()`),
+		"Pass the current time in, or inject a clock function."},
+	{SmellEnvironment, regexp.MustCompile(`\bprocess\.env\b`),
+		"Read configuration once and pass the values in as arguments."},
+	{SmellRandomness, regexp.MustCompile(`\bMath\.random\(|\bcrypto\.randomUUID\(`),
+		"Inject the random source so tests are deterministic."},
+	{SmellNetwork, regexp.MustCompile(`\bfetch\(|\baxios\.|\bnew WebSocket\(`),
+		"Inject the HTTP client so tests can pass a fake."},
+	{SmellFilesystem, regexp.MustCompile(`\bfs\.\w+\(`),
+		"Accept the data or a stream instead of reading files directly."},
+	{SmellProcess, regexp.MustCompile(`\bprocess\.exit\(|\bchild_process\b|\bexecSync\(`),
+		"Throw instead of exiting, and wrap child processes behind a function you can replace."},
+	{SmellGlobalState, regexp.MustCompile(`\b(window|globalThis|global)\.\w+\s*=[^=]`),
+		"Keep the state in a module or object that tests can reset, instead of globals."},
+}
+
+// branchPattern counts decision points, a cheap stand-in for cyclomatic
+// complexity that works across languages
+var branchPattern = regexp.MustCompile(`\b(if|elif|for|while|case|catch|except)\b|&&|\|\||\?\?`)
+
+// concreteDependencies are infrastructure types that should be hidden
+// behind an interface when passed in
+var concreteDependencies = regexp.MustCompile(`^\*?(sql\.(DB|Tx|Conn)|http\.Client|pgxpool\.Pool|pgx\.Conn|redis\.Client|nats\.Conn|os\.File|mongo\.(Client|Database|Collection)|gorm\.DB)$`)
+
+// serviceName matches type names of collaborators rather than data, which
+// tests want to replace with fakes
+var serviceName = regexp.MustCompile(`(Store|Service|Client|Repository|Repo|Manager|Gateway|Provider|Cache|Queue|Pool|Publisher|Sender|Runner|Executor)$`)
+
+// BodySmells scans a function body for hidden dependencies on the clock,
+// environment, network, disk, process and shared state. Each kind is
+// reported once, at its first occurrence; startLine is the body's first
+// line in the file, or 0 if unknown.
+func BodySmells(body string, startLine int, language string) []TestabilitySmell {
+	rules := bodyRules[language]
+	var smells []TestabilitySmell
+	seen := make(map[string]bool)
+
+	for i, line := range strings.Split(body, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "//") || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		for _, rule := range rules {
+			if seen[rule.kind] || !rule.pattern.MatchString(line) {
+				continue
+			}
+			seen[rule.kind] = true
+			smell := TestabilitySmell{
+				Kind:       rule.kind,
+				Evidence:   trimEvidence(trimmed),
+				Suggestion: rule.suggestion,
+			}
+			if startLine > 0 {
+				smell.Line = startLine + i
+			}
+			smells = append(smells, smell)
+		}
+	}
+	return smells
+}
+
+// sharedStatePattern matches code coordinating with other goroutines or
+// threads, which means state outside the function
+var sharedStatePattern = regexp.MustCompile(`(^|[\s;{])go\s+\w|\bsync\.|\batomic\.|\bthreading\.`)
+
+// EstimatePure reports whether a function body looks free of side
+// effects: it has none of the hidden dependencies BodySmells finds and
+// shares no state with concurrent code. Unknown bodies aren't pure.
+func EstimatePure(body, language string) bool {
+	if strings.TrimSpace(body) == "" || bodyRules[language] == nil {
+		return false
+	}
+	return len(BodySmells(body, 0, language)) == 0 && !sharedStatePattern.MatchString(body)
+}
+
+// AnalyzeTestability looks for testability smells in every function and
+// type of the model and returns the affected targets, worst first. Targets
+// are scored by their smells and their risk, so heavily used code that is
+// hard to test comes first.
+func AnalyzeTestability(m *SystemModel, cfg TestabilityConfig) *TestabilityReport {
+	report := &TestabilityReport{
+		Repository: m.Repository,
+		Summary:    make(map[string]int),
+	}
+
+	languages := make(map[string]string, len(m.Modules))
+	for _, mod := range m.Modules {
+		languages[mod.ID] = mod.Language
+	}
+
+	// Service types with methods are concrete dependencies when passed by
+	// pointer
+	concrete := make(map[string]bool)
+	interfaces := make(map[string]bool)
+	for _, t := range m.Types {
+		if t.Kind == TypeKindInterface {
+			interfaces[t.Name] = true
+		}
+	}
+	for _, fn := range m.Functions {
+		if fn.Class != "" && !interfaces[fn.Class] && serviceName.MatchString(fn.Class) {
+			concrete[fn.Class] = true
+		}
+	}
+
+	for _, fn := range m.Functions {
+		if isTestFile(fn.File) {
+			continue
+		}
+		report.Analyzed++
+
+		language := languages[fn.Module]
+		if language == "" {
+			language = languageForFile(fn.File)
+		}
+		smells := functionSmells(fn, language, cfg, concrete)
+		if len(smells) == 0 {
+			continue
+		}
+		report.addTarget(TestabilityTarget{
+			ID:       fn.ID,
+			Name:     qualifiedName(fn),
+			Kind:     "function",
+			File:     fn.File,
+			Line:     fn.StartLine,
+			Exported: fn.Exported,
+			Smells:   smells,
+		}, m.RiskScores[fn.ID].Score)
+	}
+
+	for _, t := range m.Types {
+		if t.Kind == TypeKindInterface || isTestFile(t.File) {
+			continue
+		}
+		report.Analyzed++
+
+		smells := typeSmells(t, concrete)
+		if len(smells) == 0 {
+			continue
+		}
+		report.addTarget(TestabilityTarget{
+			ID:       t.ID,
+			Name:     t.Name,
+			Kind:     "type",
+			File:     t.File,
+			Line:     t.Line,
+			Exported: t.Exported,
+			Smells:   smells,
+		}, 0)
+	}
+
+	sort.SliceStable(report.Targets, func(i, j int) bool {
+		if report.Targets[i].Score != report.Targets[j].Score {
+			return report.Targets[i].Score > report.Targets[j].Score
+		}
+		return report.Targets[i].ID < report.Targets[j].ID
+	})
+	return report
+}
+
+// addTarget scores a target and adds it to the report
+func (r *TestabilityReport) addTarget(target TestabilityTarget, risk float64) {
+	score := 0.0
+	for _, s := range target.Smells {
+		score += smellWeights[s.Kind]
+		r.Summary[s.Kind]++
+	}
+	// Public and risky code is worth fixing first
+	if target.Exported {
+		score *= 1.25
+	}
+	score *= 1 + risk
+
+	target.Score = float64(int(score*100+0.5)) / 100
+	switch {
+	case target.Score >= 6:
+		target.Priority = "high"
+	case target.Score >= 3:
+		target.Priority = "medium"
+	default:
+		target.Priority = "low"
+	}
+	r.Targets = append(r.Targets, target)
+}
+
+// functionSmells finds the smells of one function
+func functionSmells(fn Function, language string, cfg TestabilityConfig, concrete map[string]bool) []TestabilitySmell {
+	body := BodySmells(fn.Body, fn.StartLine, language)
+
+	var smells []TestabilitySmell
+	if isConstructor(fn, language) {
+		// I/O in a constructor makes every test of the type pay for it
+		var io []string
+		for _, s := range body {
+			if ioSmells[s.Kind] {
+				io = append(io, s.Kind)
+			}
+		}
+		if len(io) > 0 {
+			smells = append(smells, TestabilitySmell{
+				Kind:     SmellConstructorIO,
+				Line:     fn.StartLine,
+				Evidence: fmt.Sprintf("%s does %s I/O", fn.Name, strings.Join(io, ", ")),
+				Suggestion: fmt.Sprintf("Move the I/O out of %s: open connections and files in the caller "+
+					"and pass the ready dependencies in, so tests can construct it with fakes.", fn.Name),
+			})
+			for _, s := range body {
+				if !ioSmells[s.Kind] {
+					smells = append(smells, s)
+				}
+			}
+		} else {
+			smells = append(smells, body...)
+		}
+	} else {
+		smells = append(smells, body...)
+	}
+
+	if language == "go" && fn.Name == "init" && fn.Class == "" {
+		smells = append(smells, TestabilitySmell{
+			Kind:       SmellGlobalState,
+			Line:       fn.StartLine,
+			Evidence:   "init()",
+			Suggestion: "Replace init() with an explicit constructor or setup function that tests can call, or skip.",
+		})
+	}
+
+	if cfg.MaxLOC > 0 && fn.LOC > cfg.MaxLOC {
+		smells = append(smells, TestabilitySmell{
+			Kind:     SmellLongFunction,
+			Line:     fn.StartLine,
+			Evidence: fmt.Sprintf("%d lines", fn.LOC),
+			Suggestion: fmt.Sprintf("Split %s into smaller functions that each do one thing; "+
+				"test the pieces directly and keep %s as a thin coordinator.", fn.Name, fn.Name),
+		})
+	}
+
+	if branches := len(branchPattern.FindAllString(fn.Body, -1)); cfg.MaxBranches > 0 && branches > cfg.MaxBranches {
+		smells = append(smells, TestabilitySmell{
+			Kind:       SmellComplex,
+			Line:       fn.StartLine,
+			Evidence:   fmt.Sprintf("%d branches", branches),
+			Suggestion: "Extract the decision logic into pure helper functions, or replace condition chains with a lookup table, so each path can be tested alone.",
+		})
+	}
+
+	if cfg.MaxParameters > 0 && len(fn.Parameters) > cfg.MaxParameters {
+		smells = append(smells, TestabilitySmell{
+			Kind:       SmellParameters,
+			Line:       fn.StartLine,
+			Evidence:   fmt.Sprintf("%d parameters", len(fn.Parameters)),
+			Suggestion: "Group related parameters into a struct or options object with sensible defaults.",
+		})
+	}
+
+	for _, p := range fn.Parameters {
+		if dep := concreteDependency(p.Type, concrete); dep != "" {
+			smells = append(smells, TestabilitySmell{
+				Kind:     SmellMissingInterface,
+				Line:     fn.StartLine,
+				Evidence: fmt.Sprintf("%s %s", p.Name, p.Type),
+				Suggestion: fmt.Sprintf("Accept a small interface with just the methods %s uses from %s, "+
+					"instead of the concrete %s, so tests can pass a fake.", fn.Name, dep, p.Type),
+			})
+			break // one is enough to make the point
+		}
+	}
+
+	return smells
+}
+
+// typeSmells finds fields that pin a type to concrete dependencies
+func typeSmells(t TypeDef, concrete map[string]bool) []TestabilitySmell {
+	var deps []string
+	for _, f := range t.Fields {
+		if concreteDependency(f.Type, concrete) != "" {
+			deps = append(deps, fmt.Sprintf("%s %s", f.Name, f.Type))
+		}
+	}
+	if len(deps) == 0 {
+		return nil
+	}
+	return []TestabilitySmell{{
+		Kind:     SmellMissingInterface,
+		Line:     t.Line,
+		Evidence: strings.Join(deps, "; "),
+		Suggestion: fmt.Sprintf("Declare interfaces for the dependencies %s holds and store those, "+
+			"so its methods can be tested with fakes.", t.Name),
+	}}
+}
+
+// concreteDependency returns the name of the concrete type a parameter or
+// field depends on, or "" if it's fine to pass in a test
+func concreteDependency(typ string, concrete map[string]bool) string {
+	typ = strings.TrimSpace(typ)
+	if typ == "" {
+		return ""
+	}
+	if concreteDependencies.MatchString(typ) {
+		return strings.TrimPrefix(typ, "*")
+	}
+
+	// Go pointers to local service types: *Store, *db.Store
+	if !strings.HasPrefix(typ, "*") {
+		return ""
+	}
+	name := strings.TrimPrefix(typ, "*")
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+	if concrete[name] {
+		return name
+	}
+	return ""
+}
+
+// isConstructor reports whether a function builds an object
+func isConstructor(fn Function, language string) bool {
+	switch language {
+	case "go":
+		return fn.Class == "" && strings.HasPrefix(fn.Name, "New")
+	case "python":
+		return fn.Name == "__init__"
+	case "typescript", "javascript":
+		return fn.Name == "constructor"
+	}
+	return false
+}
+
+// qualifiedName names a method by its class
+func qualifiedName(fn Function) string {
+	if fn.Class != "" {
+		return fn.Class + "." + fn.Name
+	}
+	return fn.Name
+}
+
+// isTestFile reports whether a path is a test file
+func isTestFile(path string) bool {
+	base := filepath.Base(path)
+	return strings.HasSuffix(base, "_test.go") || strings.HasPrefix(base, "test_") ||
+		strings.HasSuffix(base, "_test.py") || strings.Contains(base, ".test.") || strings.Contains(base, ".spec.")
+}
+
+// languageForFile guesses a language from a file extension
+func languageForFile(path string) string {
+	switch filepath.Ext(path) {
+	case ".go":
+		return "go"
+	case ".py":
+		return "python"
+	case ".ts", ".tsx":
+		return "typescript"
+	case ".js", ".jsx", ".mjs", ".cjs":
+		return "javascript"
+	}
+	return ""
+}
+
+// trimEvidence shortens a source line for display
+func trimEvidence(line string) string {
+	const max = 120
+	if len(line) <= max {
+		return line
+	}
+	return line[:max] + "..."
+}
@@ -0,0 +1,202 @@
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func testabilityModel() *SystemModel {
+	return &SystemModel{
+		Repository: "demo",
+		Modules: []Module{
+			{ID: "mod:app", Language: "go"},
+			{ID: "mod:web", Language: "typescript"},
+		},
+		Functions: []Function{
+			{
+				ID: "fn:NewServer", Name: "NewServer", Module: "mod:app", File: "app/server.go",
+				StartLine: 10, EndLine: 20, LOC: 11, Exported: true,
+				Parameters: []Parameter{{Name: "db", Type: "*sql.DB"}},
+				Body:       "func NewServer(db *sql.DB) *Server {\n\tdata, _ := os.ReadFile(\"config.json\")\n\treturn &Server{}\n}",
+			},
+			{
+				ID: "fn:Add", Name: "Add", Module: "mod:app", File: "app/math.go",
+				StartLine: 1, EndLine: 3, LOC: 3, Exported: true,
+				Body: "func Add(a, b int) int {\n\treturn a + b\n}",
+			},
+			{
+				ID: "fn:Sync", Name: "Sync", Module: "mod:app", File: "app/sync.go",
+				StartLine: 5, EndLine: 100, LOC: 96,
+				Parameters: []Parameter{{Name: "store", Type: "*db.Store"}},
+				Body:       "func sync(store *db.Store) {\n\tstart := time.Now()\n}",
+			},
+			{
+				ID: "fn:Store.Get", Name: "Get", Class: "Store", Module: "mod:app", File: "db/store.go",
+				StartLine: 1, EndLine: 3, LOC: 3, Exported: true,
+			},
+			{
+				ID: "fn:init", Name: "init", Module: "mod:app", File: "app/init.go",
+				StartLine: 1, EndLine: 3, LOC: 3,
+			},
+			{
+				ID: "fn:track", Name: "track", Module: "mod:web", File: "web/track.ts",
+				StartLine: 1, EndLine: 3, LOC: 3,
+				Body: "function track() {\n  window.lastSeen = Date.now();\n}",
+			},
+			{
+				ID: "fn:TestAdd", Name: "TestAdd", Module: "mod:app", File: "app/math_test.go",
+				StartLine: 1, EndLine: 200, LOC: 200,
+			},
+		},
+		Types: []TypeDef{
+			{
+				ID: "type:Server", Name: "Server", Kind: TypeKindClass, File: "app/server.ts", Line: 1,
+				Fields: []Field{{Name: "client", Type: "*http.Client"}},
+			},
+			{
+				ID: "type:Reader", Name: "Reader", Kind: TypeKindInterface, File: "app/reader.go",
+				Fields: []Field{{Name: "db", Type: "*sql.DB"}},
+			},
+		},
+		RiskScores: map[string]RiskScore{
+			"fn:Sync": {Score: 1},
+		},
+	}
+}
+
+func smellKinds(target TestabilityTarget) []string {
+	var kinds []string
+	for _, s := range target.Smells {
+		kinds = append(kinds, s.Kind)
+	}
+	return kinds
+}
+
+func findTarget(report *TestabilityReport, id string) *TestabilityTarget {
+	for i := range report.Targets {
+		if report.Targets[i].ID == id {
+			return &report.Targets[i]
+		}
+	}
+	return nil
+}
+
+func TestAnalyzeTestability(t *testing.T) {
+	report := AnalyzeTestability(testabilityModel(), DefaultTestabilityConfig())
+
+	tests := []struct {
+		id   string
+		want []string
+	}{
+		{"fn:NewServer", []string{SmellConstructorIO, SmellMissingInterface}},
+		{"fn:Sync", []string{SmellClock, SmellLongFunction, SmellMissingInterface}},
+		{"fn:init", []string{SmellGlobalState}},
+		{"fn:track", []string{SmellClock, SmellGlobalState}},
+		{"type:Server", []string{SmellMissingInterface}},
+	}
+	for _, tt := range tests {
+		target := findTarget(report, tt.id)
+		if target == nil {
+			t.Errorf("%s not reported", tt.id)
+			continue
+		}
+		if got := smellKinds(*target); strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("%s smells = %v, want %v", tt.id, got, tt.want)
+		}
+		for _, s := range target.Smells {
+			if s.Suggestion == "" {
+				t.Errorf("%s %s has no suggestion", tt.id, s.Kind)
+			}
+		}
+	}
+
+	for _, id := range []string{"fn:Add", "fn:Store.Get", "fn:TestAdd", "type:Reader"} {
+		if findTarget(report, id) != nil {
+			t.Errorf("%s should not be reported", id)
+		}
+	}
+
+	// Test files are skipped
+	if report.Analyzed != 7 {
+		t.Errorf("Analyzed = %d, want 7", report.Analyzed)
+	}
+	if report.Summary[SmellMissingInterface] != 3 {
+		t.Errorf("Summary[missing_interface] = %d, want 3", report.Summary[SmellMissingInterface])
+	}
+}
+
+func TestAnalyzeTestability_Ordering(t *testing.T) {
+	report := AnalyzeTestability(testabilityModel(), DefaultTestabilityConfig())
+
+	for i := 1; i < len(report.Targets); i++ {
+		if report.Targets[i-1].Score < report.Targets[i].Score {
+			t.Fatalf("targets not sorted by score: %v before %v", report.Targets[i-1].Score, report.Targets[i].Score)
+		}
+	}
+
+	// Risk doubles Sync's score, putting it first
+	if report.Targets[0].ID != "fn:Sync" {
+		t.Errorf("first target = %s, want fn:Sync", report.Targets[0].ID)
+	}
+	if report.Targets[0].Priority != "high" {
+		t.Errorf("priority = %s, want high", report.Targets[0].Priority)
+	}
+}
+
+func TestAnalyzeTestability_Thresholds(t *testing.T) {
+	cfg := DefaultTestabilityConfig()
+	cfg.MaxLOC = 0 // disabled
+
+	report := AnalyzeTestability(testabilityModel(), cfg)
+	target := findTarget(report, "fn:Sync")
+	if target == nil {
+		t.Fatal("fn:Sync not reported")
+	}
+	for _, s := range target.Smells {
+		if s.Kind == SmellLongFunction {
+			t.Error("long_function reported with MaxLOC disabled")
+		}
+	}
+}
+
+func TestEstimatePure(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		language string
+		want     bool
+	}{
+		{"arithmetic", "func f(a, b int) int {\n\treturn a + b\n}", "go", true},
+		{"clock", "func f() int64 {\n\treturn time.Now().Unix()\n}", "go", false},
+		{"goroutine", "func f(ch chan int) {\n\tgo work(ch)\n}", "go", false},
+		{"lock", "func (c *C) f() {\n\tc.mu.Lock()\n\tsync.OnceFunc(g)()\n}", "go", false},
+		{"python file", "def f(p):\n    return open(p).read()", "python", false},
+		{"python math", "def f(x):\n    return x * 2", "python", true},
+		{"no body", "", "go", false},
+		{"unknown language", "x = 1", "cobol", false},
+	}
+	for _, tt := range tests {
+		if got := EstimatePure(tt.body, tt.language); got != tt.want {
+			t.Errorf("EstimatePure(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBodySmells(t *testing.T) {
+	body := "func f() {\n\t// time.Now() in a comment\n\tx := os.Getenv(\"X\")\n\ty := os.Getenv(\"Y\")\n\tt := time.Now()\n}"
+
+	smells := BodySmells(body, 10, "go")
+	if len(smells) != 2 {
+		t.Fatalf("len(smells) = %d, want 2: %+v", len(smells), smells)
+	}
+	if smells[0].Kind != SmellEnvironment || smells[0].Line != 12 {
+		t.Errorf("smells[0] = %s at %d, want environment at 12", smells[0].Kind, smells[0].Line)
+	}
+	if smells[1].Kind != SmellClock || smells[1].Line != 14 {
+		t.Errorf("smells[1] = %s at %d, want clock at 14", smells[1].Kind, smells[1].Line)
+	}
+
+	if got := BodySmells(body, 0, "cobol"); len(got) != 0 {
+		t.Errorf("unknown language smells = %v, want none", got)
+	}
+}